@@ -0,0 +1,493 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupMethod identifies how a CNPG cluster takes backups
+// +kubebuilder:validation:Enum=barmanObjectStore;plugin;volumeSnapshot
+type BackupMethod string
+
+const (
+	// BackupMethodBarmanObjectStore is the in-tree barman object store (spec.backup.barmanObjectStore)
+	BackupMethodBarmanObjectStore BackupMethod = "barmanObjectStore"
+	// BackupMethodPlugin is a CNPG-I plugin such as barman-cloud (spec.plugins)
+	BackupMethodPlugin BackupMethod = "plugin"
+	// BackupMethodVolumeSnapshot is Kubernetes volume snapshots (spec.backup.volumeSnapshot)
+	BackupMethodVolumeSnapshot BackupMethod = "volumeSnapshot"
+)
+
+// BackupScheduleConfig defines when backups are expected to happen
+type BackupScheduleConfig struct {
+	// Schedule is the expected backup cadence as a cron expression. Both the standard
+	// five-field format and CNPG's six-field format with seconds are accepted, as are
+	// @hourly, @daily, @weekly and @monthly
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// GracePeriodMinutes is how long after a scheduled time a backup may still complete
+	// before it is considered missed
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=60
+	// +optional
+	GracePeriodMinutes int32 `json:"gracePeriodMinutes,omitempty"`
+}
+
+// BackupMethodCheck defines expectations for a single backup method
+type BackupMethodCheck struct {
+	// Method is the backup method to check
+	Method BackupMethod `json:"method"`
+
+	// Required alerts when a matched cluster does not have this method configured
+	// +optional
+	Required bool `json:"required,omitempty"`
+
+	// MaxAgeHours is the maximum age of the last completed backup taken with this method.
+	// Set to 0 to disable the per-method age check
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxAgeHours int32 `json:"maxAgeHours,omitempty"`
+}
+
+// ArchiveLagConfig defines WAL archive lag monitoring based on pg_stat_archiver
+type ArchiveLagConfig struct {
+	// Enabled queries pg_stat_archiver on each primary. Requires the exec command runner
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MaxSegments is the number of completed WAL segments waiting to be archived before alerting.
+	// Set to 0 to disable
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=16
+	// +optional
+	MaxSegments int32 `json:"maxSegments,omitempty"`
+
+	// MaxSeconds is how long segments may wait to be archived before alerting.
+	// Set to 0 to disable
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=900
+	// +optional
+	MaxSeconds int32 `json:"maxSeconds,omitempty"`
+}
+
+// ObjectStoreProbeConfig defines the periodic object store reachability probe
+type ObjectStoreProbeConfig struct {
+	// Enabled validates the object store configuration and its credential Secrets of
+	// each cluster backing up to an object store
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// IntervalMinutes is how often each cluster's object store is probed
+	// +kubebuilder:validation:Minimum=5
+	// +kubebuilder:default=60
+	// +optional
+	IntervalMinutes int32 `json:"intervalMinutes,omitempty"`
+
+	// Image runs barman-cloud-backup-list against the bucket in a short-lived Job with
+	// the configured credentials, e.g. the barman-cloud plugin sidecar image. When empty,
+	// only the configuration and credential Secrets are validated
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// TimeoutSeconds bounds how long a probe Job may run
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:default=120
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// RestoreVerificationConfig defines periodic restore tests of the latest backup
+// +kubebuilder:validation:XValidation:rule="!has(self.enabled) || !self.enabled || (has(self.targetNamespace) && self.targetNamespace != '')",message="targetNamespace is required when restore verification is enabled"
+type RestoreVerificationConfig struct {
+	// Enabled periodically recovers each cluster from its object store into a throwaway
+	// CNPG cluster, runs the smoke check and deletes the cluster again
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// IntervalHours is how often each cluster is restore-tested
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=168
+	// +optional
+	IntervalHours int32 `json:"intervalHours,omitempty"`
+
+	// TargetNamespace is an isolated namespace for the recovery clusters. Credential
+	// Secrets and ObjectStores are copied there for the duration of the test.
+	// Required when restore verification is enabled
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// SmokeSQL is run against the recovered database and must succeed
+	// +kubebuilder:default="SELECT 1"
+	// +optional
+	SmokeSQL string `json:"smokeSQL,omitempty"`
+
+	// TimeoutMinutes bounds how long recovery may take before the test fails
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=60
+	// +optional
+	TimeoutMinutes int32 `json:"timeoutMinutes,omitempty"`
+}
+
+// BackupPolicySpec defines the desired state of BackupPolicy
+type BackupPolicySpec struct {
+	// Selector is a label selector for matching CNPG clusters
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// ExcludeClusters is a list of clusters to exclude even if they match the selector
+	// +optional
+	ExcludeClusters []ClusterReference `json:"excludeClusters,omitempty"`
+
+	// RPOTargetMinutes is the recovery point objective. When continuous archiving is not
+	// working, the recovery point is the last backup and its age is compared to this target.
+	// Set to 0 to disable
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=0
+	// +optional
+	RPOTargetMinutes int32 `json:"rpoTargetMinutes,omitempty"`
+
+	// MaxBackupAgeHours is the maximum age of the last successful backup before alerting.
+	// Set to 0 to disable backup age monitoring
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=24
+	// +optional
+	MaxBackupAgeHours int32 `json:"maxBackupAgeHours,omitempty"`
+
+	// MaxRecoveryPointAgeHours is the maximum age of the first recovery point before alerting.
+	// Set to 0 to disable recovery point age monitoring
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=168
+	// +optional
+	MaxRecoveryPointAgeHours int32 `json:"maxRecoveryPointAgeHours,omitempty"`
+
+	// InitialGracePeriodHours is how long after a cluster is created a missing first
+	// backup is reported as pending instead of as an issue, so new clusters do not alert
+	// before their first scheduled backup had a chance to run. Set to 0 to disable
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=24
+	// +optional
+	InitialGracePeriodHours int32 `json:"initialGracePeriodHours,omitempty"`
+
+	// MinRecoveryWindowDays is how far back point-in-time recovery must reach, e.g. 14
+	// for "must be recoverable back 14 days". Clusters whose first recovery point is
+	// more recent are reported as not compliant. Set to 0 to disable
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=0
+	// +optional
+	MinRecoveryWindowDays int32 `json:"minRecoveryWindowDays,omitempty"`
+
+	// RequireContinuousArchiving alerts if WAL archiving is not working
+	// +kubebuilder:default=true
+	// +optional
+	RequireContinuousArchiving bool `json:"requireContinuousArchiving,omitempty"`
+
+	// AlertOnNoBackupConfigured alerts if a cluster has no backup configured
+	// +kubebuilder:default=true
+	// +optional
+	AlertOnNoBackupConfigured bool `json:"alertOnNoBackupConfigured,omitempty"`
+
+	// RequireScheduledBackup alerts when a cluster with backups configured has no active
+	// ScheduledBackup, or when its ScheduledBackups allow gaps longer than MaxBackupAgeHours
+	// +kubebuilder:default=true
+	// +optional
+	RequireScheduledBackup bool `json:"requireScheduledBackup,omitempty"`
+
+	// Schedule defines the expected backup cadence
+	// +optional
+	Schedule BackupScheduleConfig `json:"schedule,omitempty"`
+
+	// ArchiveLag defines WAL archive lag thresholds. The lag is detected well before
+	// CNPG flips the ContinuousArchiving condition
+	// +optional
+	ArchiveLag ArchiveLagConfig `json:"archiveLag,omitempty"`
+
+	// ObjectStoreProbe periodically checks that object stores are reachable with the
+	// configured credentials, so misconfiguration is reported before the first failed backup
+	// +optional
+	ObjectStoreProbe ObjectStoreProbeConfig `json:"objectStoreProbe,omitempty"`
+
+	// RestoreVerification periodically verifies that the latest backup can be restored
+	// +optional
+	RestoreVerification RestoreVerificationConfig `json:"restoreVerification,omitempty"`
+
+	// Methods defines per-method expectations
+	// +listType=map
+	// +listMapKey=method
+	// +optional
+	Methods []BackupMethodCheck `json:"methods,omitempty"`
+
+	// Alerting defines alerting settings
+	// +optional
+	Alerting AlertingConfig `json:"alerting,omitempty"`
+
+	// AlertAfterFailures is the number of consecutive evaluations that must find issues
+	// with a cluster before it is alerted on, so transient failures do not page
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	AlertAfterFailures int32 `json:"alertAfterFailures,omitempty"`
+}
+
+// BackupMethodStatus contains the observed state of a single backup method for a cluster
+type BackupMethodStatus struct {
+	// Method is the backup method
+	Method BackupMethod `json:"method"`
+
+	// Configured indicates the method is configured on the cluster
+	Configured bool `json:"configured"`
+
+	// LastBackupTime is when the last completed backup with this method finished
+	// +optional
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// LastFailedBackupTime is when the last failed backup with this method was recorded
+	// +optional
+	LastFailedBackupTime *metav1.Time `json:"lastFailedBackupTime,omitempty"`
+
+	// FirstRecoverabilityPoint is the oldest point in time recovery with this method is possible
+	// +optional
+	FirstRecoverabilityPoint *metav1.Time `json:"firstRecoverabilityPoint,omitempty"`
+}
+
+// BackupHealth is the overall backup health of a cluster
+type BackupHealth string
+
+const (
+	// BackupHealthHealthy means all checks pass
+	BackupHealthHealthy BackupHealth = "Healthy"
+	// BackupHealthDegraded means at least one non-critical check failed
+	BackupHealthDegraded BackupHealth = "Degraded"
+	// BackupHealthCritical means backups are missing or WAL archiving is broken
+	BackupHealthCritical BackupHealth = "Critical"
+	// BackupHealthPendingFirstBackup means a new cluster has not completed its first
+	// backup yet and is still within initialGracePeriodHours
+	BackupHealthPendingFirstBackup BackupHealth = "PendingFirstBackup"
+)
+
+// ObjectStoreProbePhase is the outcome of an object store probe
+// +kubebuilder:validation:Enum=Running;Passed;Failed
+type ObjectStoreProbePhase string
+
+const (
+	// ObjectStoreProbeRunning means a probe Job has been started and has not finished
+	ObjectStoreProbeRunning ObjectStoreProbePhase = "Running"
+	// ObjectStoreProbePassed means the object store is correctly configured and reachable
+	ObjectStoreProbePassed ObjectStoreProbePhase = "Passed"
+	// ObjectStoreProbeFailed means the configuration is invalid or the store is unreachable
+	ObjectStoreProbeFailed ObjectStoreProbePhase = "Failed"
+)
+
+// ObjectStoreProbeStatus is the result of the last object store probe of a cluster
+type ObjectStoreProbeStatus struct {
+	// Source identifies the probed configuration, e.g. ObjectStore/minio or Cluster/pg-main
+	// +optional
+	Source string `json:"source,omitempty"`
+
+	// Phase is the probe outcome
+	Phase ObjectStoreProbePhase `json:"phase"`
+
+	// Message describes the outcome
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Job is the name of the running probe Job
+	// +optional
+	Job string `json:"job,omitempty"`
+
+	// LastProbeTime is when the probe last completed
+	// +optional
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+}
+
+// RestoreTestSummary is the outcome of the most recent finished restore test of a cluster
+type RestoreTestSummary struct {
+	// Event is the name of the restore-test StorageEvent
+	Event string `json:"event"`
+
+	// Succeeded indicates the backup was restored and passed the smoke check
+	Succeeded bool `json:"succeeded"`
+
+	// CompletionTime is when the restore test finished
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message describes the outcome
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// BackupTargetOverrides are the backup targets a cluster overrides through annotations
+type BackupTargetOverrides struct {
+	// MaxBackupAgeHours is the cluster's backup.cnpg.supporttools.io/max-age-hours
+	// +optional
+	MaxBackupAgeHours *int32 `json:"maxBackupAgeHours,omitempty"`
+
+	// RPOTargetMinutes is the cluster's backup.cnpg.supporttools.io/rpo-target-minutes
+	// +optional
+	RPOTargetMinutes *int32 `json:"rpoTargetMinutes,omitempty"`
+}
+
+// ClusterBackupHealth contains the backup health of a cluster matched by a BackupPolicy
+type ClusterBackupHealth struct {
+	// Name is the cluster name
+	Name string `json:"name"`
+
+	// Namespace is the cluster namespace
+	Namespace string `json:"namespace"`
+
+	// Health is the overall backup health
+	Health BackupHealth `json:"health"`
+
+	// Issues lists the failed checks
+	// +optional
+	Issues []string `json:"issues,omitempty"`
+
+	// ConsecutiveFailures is the number of consecutive evaluations that found issues
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// PendingFirstBackup indicates the cluster has no successful backup yet and is
+	// within initialGracePeriodHours of its creation
+	// +optional
+	PendingFirstBackup bool `json:"pendingFirstBackup,omitempty"`
+
+	// TargetOverrides are the policy targets the cluster overrides through annotations
+	// +optional
+	TargetOverrides *BackupTargetOverrides `json:"targetOverrides,omitempty"`
+
+	// LastBackupTime is the timestamp of the last successful backup
+	// +optional
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// FirstRecoverabilityPoint is the oldest point in time recovery is possible
+	// +optional
+	FirstRecoverabilityPoint *metav1.Time `json:"firstRecoverabilityPoint,omitempty"`
+
+	// ContinuousArchivingWorking indicates if WAL archiving is working
+	// +optional
+	ContinuousArchivingWorking bool `json:"continuousArchivingWorking,omitempty"`
+
+	// RecoveryWindowHours is how far back point-in-time recovery currently reaches
+	// +optional
+	RecoveryWindowHours *int32 `json:"recoveryWindowHours,omitempty"`
+
+	// RecoveryWindowCompliant reports whether the recovery window meets minRecoveryWindowDays.
+	// Unset when no requirement is declared or the window is unknown
+	// +optional
+	RecoveryWindowCompliant *bool `json:"recoveryWindowCompliant,omitempty"`
+
+	// RecoveryPointAgeMinutes is the estimated data loss window if the cluster were lost now
+	// +optional
+	RecoveryPointAgeMinutes *int32 `json:"recoveryPointAgeMinutes,omitempty"`
+
+	// ArchiveLagSegments is the number of completed WAL segments not yet archived
+	// +optional
+	ArchiveLagSegments *int32 `json:"archiveLagSegments,omitempty"`
+
+	// ArchiveLagSeconds is how long WAL segments have been waiting to be archived
+	// +optional
+	ArchiveLagSeconds *int32 `json:"archiveLagSeconds,omitempty"`
+
+	// NextExpectedBackup is the next time a backup is expected according to the schedule
+	// +optional
+	NextExpectedBackup *metav1.Time `json:"nextExpectedBackup,omitempty"`
+
+	// ScheduledBackups are the active (not suspended) ScheduledBackups targeting the cluster
+	// +optional
+	ScheduledBackups []string `json:"scheduledBackups,omitempty"`
+
+	// ObjectStoreProbe is the result of the last object store probe
+	// +optional
+	ObjectStoreProbe *ObjectStoreProbeStatus `json:"objectStoreProbe,omitempty"`
+
+	// LastRestoreTest is the outcome of the most recent finished restore test
+	// +optional
+	LastRestoreTest *RestoreTestSummary `json:"lastRestoreTest,omitempty"`
+
+	// Methods contains per-method status
+	// +optional
+	Methods []BackupMethodStatus `json:"methods,omitempty"`
+
+	// LastChecked is when the cluster was last evaluated
+	LastChecked metav1.Time `json:"lastChecked"`
+}
+
+// BackupPolicyStatus defines the observed state of BackupPolicy
+type BackupPolicyStatus struct {
+	// Conditions represent the current state of the BackupPolicy
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Clusters is the backup health of each matched cluster
+	// +optional
+	Clusters []ClusterBackupHealth `json:"clusters,omitempty"`
+
+	// HealthyClusters is the number of matched clusters with healthy backups
+	// +optional
+	HealthyClusters int32 `json:"healthyClusters,omitempty"`
+
+	// UnhealthyClusters is the number of matched clusters with backup issues
+	// +optional
+	UnhealthyClusters int32 `json:"unhealthyClusters,omitempty"`
+
+	// LastEvaluated is the timestamp of the last policy evaluation
+	// +optional
+	LastEvaluated *metav1.Time `json:"lastEvaluated,omitempty"`
+
+	// ObservedGeneration is the generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastAlertSummary is when the scheduled alert summary was last due
+	// +optional
+	LastAlertSummary *metav1.Time `json:"lastAlertSummary,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=bp
+// +kubebuilder:printcolumn:name="Healthy",type="integer",JSONPath=".status.healthyClusters"
+// +kubebuilder:printcolumn:name="Unhealthy",type="integer",JSONPath=".status.unhealthyClusters"
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule.schedule"
+// +kubebuilder:printcolumn:name="RPO",type="integer",JSONPath=".spec.rpoTargetMinutes",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// BackupPolicy is the Schema for the backuppolicies API
+type BackupPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupPolicySpec   `json:"spec,omitempty"`
+	Status BackupPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackupPolicyList contains a list of BackupPolicy
+type BackupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupPolicy{}, &BackupPolicyList{})
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterConnectionPhase is the state of a connection to a downstream Kubernetes cluster
+// +kubebuilder:validation:Enum=Connected;Failed;Suspended
+type ClusterConnectionPhase string
+
+const (
+	// ClusterConnectionConnected means the kubeconfig is valid and CNPG clusters can be listed
+	ClusterConnectionConnected ClusterConnectionPhase = "Connected"
+	// ClusterConnectionFailed means the kubeconfig is missing or invalid, or the cluster is unreachable
+	ClusterConnectionFailed ClusterConnectionPhase = "Failed"
+	// ClusterConnectionSuspended means the connection is not used
+	ClusterConnectionSuspended ClusterConnectionPhase = "Suspended"
+)
+
+// ClusterConnectionConditionReady reports whether the downstream cluster is reachable
+const ClusterConnectionConditionReady = "Ready"
+
+// KubeconfigSecretReference points to a kubeconfig stored in a Secret
+type KubeconfigSecretReference struct {
+	// Name of the Secret in the ClusterConnection's namespace
+	Name string `json:"name"`
+
+	// Key in the Secret holding the kubeconfig
+	// +kubebuilder:default=kubeconfig
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// ClusterConnectionSpec defines the desired state of ClusterConnection
+type ClusterConnectionSpec struct {
+	// KubeconfigSecretRef references the kubeconfig used to reach the downstream cluster.
+	// The credentials need read access to CNPG clusters, pods, PVCs and nodes/proxy there
+	KubeconfigSecretRef KubeconfigSecretReference `json:"kubeconfigSecretRef"`
+
+	// ClusterID identifies the downstream cluster in alerts and metrics, e.g. the Rancher
+	// cluster ID. Defaults to the value of the manager's cluster ID label on this object
+	// (management.cattle.io/cluster-name unless configured otherwise)
+	// +optional
+	ClusterID string `json:"clusterID,omitempty"`
+
+	// ClusterName is the human-readable name of the downstream cluster in alerts and metrics.
+	// Defaults to the manager's cluster name label on this object
+	// (management.cattle.io/cluster-display-name), then to the ClusterConnection name
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// Suspend stops using the connection without deleting it
+	// +kubebuilder:default=false
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// ClusterConnectionStatus defines the observed state of ClusterConnection
+type ClusterConnectionStatus struct {
+	// Conditions represent the current state of the ClusterConnection
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Phase is the connection state
+	// +optional
+	Phase ClusterConnectionPhase `json:"phase,omitempty"`
+
+	// Message describes the connection state
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Server is the API server address from the kubeconfig
+	// +optional
+	Server string `json:"server,omitempty"`
+
+	// ClusterID is the resolved ID of the downstream cluster
+	// +optional
+	ClusterID string `json:"clusterID,omitempty"`
+
+	// ClusterName is the resolved name of the downstream cluster
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// ClusterCount is the number of CNPG clusters found in the downstream cluster
+	// +optional
+	ClusterCount int32 `json:"clusterCount,omitempty"`
+
+	// LastConnected is when the downstream cluster was last reached
+	// +optional
+	LastConnected *metav1.Time `json:"lastConnected,omitempty"`
+
+	// ObservedGeneration is the generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=cc
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".status.clusterName"
+// +kubebuilder:printcolumn:name="Clusters",type="integer",JSONPath=".status.clusterCount"
+// +kubebuilder:printcolumn:name="Server",type="string",JSONPath=".status.server",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterConnection is the Schema for the clusterconnections API. It gives the manager
+// access to CNPG clusters running in another Kubernetes cluster
+type ClusterConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterConnectionSpec   `json:"spec,omitempty"`
+	Status ClusterConnectionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterConnectionList contains a list of ClusterConnection
+type ClusterConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterConnection{}, &ClusterConnectionList{})
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ManagerConfigName is the name of the singleton ManagerConfig the manager reads
+const ManagerConfigName = "default"
+
+// ManagerConfigConditionReady reports whether the ManagerConfig is in effect
+const ManagerConfigConditionReady = "Ready"
+
+// DefaultAlertingConfig defines the alert channels used by policies without their own
+type DefaultAlertingConfig struct {
+	// Channels are used by StoragePolicies and BackupPolicies that configure no channels.
+	// Slack and PagerDuty secrets must be given as namespace/name
+	// +optional
+	Channels []AlertChannel `json:"channels,omitempty"`
+}
+
+// ManagerConfigSpec defines operator-wide defaults. Policy fields that are left unset
+// take these values, so policies only need to state what differs from the defaults
+type ManagerConfigSpec struct {
+	// DryRun puts every policy in dry-run mode, in addition to the --dry-run flag
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Thresholds are the storage usage thresholds StoragePolicies leave unset
+	// +optional
+	Thresholds ThresholdsConfig `json:"thresholds,omitempty"`
+
+	// ExpansionCooldownMinutes is the expansion cooldown of StoragePolicies that leave
+	// expansion.cooldownMinutes unset
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ExpansionCooldownMinutes int32 `json:"expansionCooldownMinutes,omitempty"`
+
+	// WALCleanupCooldownMinutes is the WAL cleanup cooldown of StoragePolicies that leave
+	// walCleanup.cooldownMinutes unset
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	WALCleanupCooldownMinutes int32 `json:"walCleanupCooldownMinutes,omitempty"`
+
+	// Alerting defines the default alert channels
+	// +optional
+	Alerting DefaultAlertingConfig `json:"alerting,omitempty"`
+}
+
+// ManagerConfigStatus defines the observed state of ManagerConfig
+type ManagerConfigStatus struct {
+	// Conditions represent the current state of the ManagerConfig
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the generation in effect
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=mgrconfig
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="the ManagerConfig must be named default"
+// +kubebuilder:printcolumn:name="Dry Run",type="boolean",JSONPath=".spec.dryRun"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ManagerConfig is the Schema for the managerconfigs API. The manager reads the single
+// ManagerConfig named default and applies it as the defaults of every policy
+type ManagerConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagerConfigSpec   `json:"spec,omitempty"`
+	Status ManagerConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ManagerConfigList contains a list of ManagerConfig
+type ManagerConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ManagerConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ManagerConfig{}, &ManagerConfigList{})
+}
@@ -22,7 +22,7 @@ import (
 )
 
 // EventType defines the type of storage event
-// +kubebuilder:validation:Enum=expansion;wal-cleanup;alert;circuit-breaker
+// +kubebuilder:validation:Enum=expansion;wal-cleanup;alert;circuit-breaker;restore-test;storage-class-migration;volume-attributes-change
 type EventType string
 
 const (
@@ -34,6 +34,12 @@ const (
 	EventTypeAlert EventType = "alert"
 	// EventTypeCircuitBreaker represents a circuit breaker state change
 	EventTypeCircuitBreaker EventType = "circuit-breaker"
+	// EventTypeRestoreTest represents a restore verification of the latest backup
+	EventTypeRestoreTest EventType = "restore-test"
+	// EventTypeStorageClassMigration represents moving the volumes of a cluster to another StorageClass
+	EventTypeStorageClassMigration EventType = "storage-class-migration"
+	// EventTypeVolumeAttributesChange represents moving the PVCs of a cluster to another VolumeAttributesClass
+	EventTypeVolumeAttributesChange EventType = "volume-attributes-change"
 )
 
 // TriggerType defines what triggered the storage event
@@ -51,12 +57,53 @@ const (
 	TriggerTypeAutomatic TriggerType = "automatic"
 )
 
-// PolicyReference identifies a specific StoragePolicy
+// VolumeType selects the instance volumes of a cluster
+// +kubebuilder:validation:Enum=data;wal;all
+type VolumeType string
+
+const (
+	// VolumeTypeData is the PGDATA volume of each instance
+	VolumeTypeData VolumeType = "data"
+	// VolumeTypeWAL is the separate WAL volume of each instance (spec.walStorage)
+	VolumeTypeWAL VolumeType = "wal"
+	// VolumeTypeAll is every volume of each instance: data, WAL and tablespaces
+	VolumeTypeAll VolumeType = "all"
+)
+
+// ExpansionTarget selects the PVCs of a cluster an expansion resizes. The zero value
+// targets the data and WAL volumes
+type ExpansionTarget struct {
+	// Tablespace limits an expansion to the PVCs of one declarative tablespace
+	// +optional
+	Tablespace string `json:"tablespace,omitempty"`
+
+	// Volume limits an expansion to the data or the WAL PVCs, or with all extends it to
+	// the tablespace PVCs of each instance
+	// +optional
+	Volume VolumeType `json:"volume,omitempty"`
+}
+
+// PolicyKind is the kind of policy that created a storage event
+// +kubebuilder:validation:Enum=StoragePolicy;BackupPolicy
+type PolicyKind string
+
+const (
+	// PolicyKindStoragePolicy refers to a StoragePolicy
+	PolicyKindStoragePolicy PolicyKind = "StoragePolicy"
+	// PolicyKindBackupPolicy refers to a BackupPolicy
+	PolicyKindBackupPolicy PolicyKind = "BackupPolicy"
+)
+
+// PolicyReference identifies a specific StoragePolicy or BackupPolicy
 type PolicyReference struct {
-	// Name of the StoragePolicy
+	// Kind of the policy. Defaults to StoragePolicy
+	// +optional
+	Kind PolicyKind `json:"kind,omitempty"`
+
+	// Name of the policy
 	Name string `json:"name"`
 
-	// Namespace of the StoragePolicy
+	// Namespace of the policy
 	Namespace string `json:"namespace"`
 }
 
@@ -118,10 +165,214 @@ type WALCleanupDetails struct {
 	// OldestRetained is the name of the oldest retained WAL segment
 	// +optional
 	OldestRetained string `json:"oldestRetained,omitempty"`
+
+	// Replicas reports the cleanup of each replica when the policy includes replicas
+	// +optional
+	Replicas []ReplicaWALCleanup `json:"replicas,omitempty"`
+}
+
+// ReplicaWALCleanup contains the WAL cleanup result of a replica
+type ReplicaWALCleanup struct {
+	// PodName is the name of the replica pod
+	PodName string `json:"podName"`
+
+	// FilesRemoved is the number of WAL files removed
+	// +optional
+	FilesRemoved int32 `json:"filesRemoved,omitempty"`
+
+	// SpaceFreedBytes is the amount of space freed in bytes
+	// +optional
+	SpaceFreedBytes int64 `json:"spaceFreedBytes,omitempty"`
+}
+
+// RestoreTestDetails contains details for restore-test events
+type RestoreTestDetails struct {
+	// TargetNamespace is the namespace the throwaway recovery cluster is created in
+	// +kubebuilder:validation:Required
+	TargetNamespace string `json:"targetNamespace"`
+
+	// ClusterName is the name of the throwaway recovery cluster
+	// +kubebuilder:validation:Required
+	ClusterName string `json:"clusterName"`
+
+	// SmokeSQL is the query run against the recovered database; it must succeed
+	// +optional
+	SmokeSQL string `json:"smokeSQL,omitempty"`
+
+	// TimeoutMinutes bounds how long the recovery cluster may take to become ready
+	// +optional
+	TimeoutMinutes int32 `json:"timeoutMinutes,omitempty"`
+}
+
+// RestoreTestStatus records the resources created by a restore test and its outcome
+type RestoreTestStatus struct {
+	// Secrets are the credential Secrets copied into the target namespace
+	// +optional
+	Secrets []string `json:"secrets,omitempty"`
+
+	// ObjectStore is the ObjectStore copied into the target namespace
+	// +optional
+	ObjectStore string `json:"objectStore,omitempty"`
+
+	// RestoreDurationSeconds is how long the recovery cluster took to become ready
+	// +optional
+	RestoreDurationSeconds int32 `json:"restoreDurationSeconds,omitempty"`
+
+	// SmokeCheckOutput is the (truncated) output of the smoke SQL check
+	// +optional
+	SmokeCheckOutput string `json:"smokeCheckOutput,omitempty"`
+}
+
+// StorageClassMigrationDetails contains details for storage-class-migration events. They
+// are copied from the policy when the event is created
+type StorageClassMigrationDetails struct {
+	// StorageClass is the StorageClass the data volumes are moved to
+	// +kubebuilder:validation:Required
+	StorageClass string `json:"storageClass"`
+
+	// WALStorageClass is the StorageClass the separate WAL volumes are moved to
+	// +optional
+	WALStorageClass string `json:"walStorageClass,omitempty"`
+
+	// MaxReplicationLagMB aborts the migration when a replica lags by more than this much WAL
+	// +optional
+	MaxReplicationLagMB int32 `json:"maxReplicationLagMB,omitempty"`
+
+	// ResyncTimeoutMinutes is how long a recreated instance may take to re-sync
+	// +optional
+	ResyncTimeoutMinutes int32 `json:"resyncTimeoutMinutes,omitempty"`
+}
+
+// StorageClassMigrationStatus records the progress of a storage-class-migration event
+type StorageClassMigrationStatus struct {
+	// MigratedInstances are the instances recreated on the new StorageClass so far
+	// +optional
+	MigratedInstances []string `json:"migratedInstances,omitempty"`
+
+	// CurrentInstance is the instance being recreated
+	// +optional
+	CurrentInstance string `json:"currentInstance,omitempty"`
+
+	// CurrentStartTime is when the current instance was recreated or the switchover requested
+	// +optional
+	CurrentStartTime *metav1.Time `json:"currentStartTime,omitempty"`
+
+	// SwitchoverTarget is the replica promoted so the primary can be recreated
+	// +optional
+	SwitchoverTarget string `json:"switchoverTarget,omitempty"`
+}
+
+// ExpansionRolloutStatus records the progress of an expansion that expands the instances
+// of a cluster one at a time
+type ExpansionRolloutStatus struct {
+	// ExpandedInstances are the instances expanded and verified so far
+	// +optional
+	ExpandedInstances []string `json:"expandedInstances,omitempty"`
+
+	// CurrentInstance is the instance being expanded
+	// +optional
+	CurrentInstance string `json:"currentInstance,omitempty"`
+
+	// CurrentStartTime is when the resize of the current instance was requested
+	// +optional
+	CurrentStartTime *metav1.Time `json:"currentStartTime,omitempty"`
+}
+
+// VolumeAttributesChangeDetails contains details for volume-attributes-change events
+type VolumeAttributesChangeDetails struct {
+	// VolumeAttributesClass is the class the PVCs are moved to
+	// +kubebuilder:validation:Required
+	VolumeAttributesClass string `json:"volumeAttributesClass"`
+
+	// Threshold is the threshold level that selected the class
+	// +optional
+	Threshold string `json:"threshold,omitempty"`
+
+	// ModifyTimeoutMinutes is how long the volumes may take to be modified
+	// +optional
+	ModifyTimeoutMinutes int32 `json:"modifyTimeoutMinutes,omitempty"`
+}
+
+// VolumeModificationPhase is the state of the ModifyVolume operation of a PVC
+// +kubebuilder:validation:Enum=Pending;InProgress;Infeasible;Completed
+type VolumeModificationPhase string
+
+const (
+	// VolumeModificationPending means the modification waits, e.g. for the class to exist
+	VolumeModificationPending VolumeModificationPhase = "Pending"
+	// VolumeModificationInProgress means the CSI driver is modifying the volume
+	VolumeModificationInProgress VolumeModificationPhase = "InProgress"
+	// VolumeModificationInfeasible means the CSI driver rejected the modification
+	VolumeModificationInfeasible VolumeModificationPhase = "Infeasible"
+	// VolumeModificationCompleted means the volume uses the new class
+	VolumeModificationCompleted VolumeModificationPhase = "Completed"
+)
+
+// EffectivenessResult is the outcome of an effectiveness measurement
+// +kubebuilder:validation:Enum=Pending;Effective;Ineffective;Unknown
+type EffectivenessResult string
+
+const (
+	// EffectivenessPending means the volumes have not been measured again yet
+	EffectivenessPending EffectivenessResult = "Pending"
+	// EffectivenessEffective means usage of the remediated volumes dropped
+	EffectivenessEffective EffectivenessResult = "Effective"
+	// EffectivenessIneffective means usage of the remediated volumes did not drop
+	EffectivenessIneffective EffectivenessResult = "Ineffective"
+	// EffectivenessUnknown means the volumes could not be measured again
+	EffectivenessUnknown EffectivenessResult = "Unknown"
+)
+
+// RemediationEffectiveness compares the usage of the remediated volumes before the
+// remediation started with their usage some time after it completed
+type RemediationEffectiveness struct {
+	// Result is the outcome of the measurement
+	Result EffectivenessResult `json:"result"`
+
+	// UsagePercentBefore is the usage of the remediated volumes before the remediation
+	// +optional
+	UsagePercentBefore int32 `json:"usagePercentBefore,omitempty"`
+
+	// UsedBytesBefore is the space used on the remediated volumes before the remediation
+	// +optional
+	UsedBytesBefore int64 `json:"usedBytesBefore,omitempty"`
+
+	// UsagePercentAfter is the usage of the remediated volumes after the remediation
+	// +optional
+	UsagePercentAfter int32 `json:"usagePercentAfter,omitempty"`
+
+	// UsedBytesAfter is the space used on the remediated volumes after the remediation
+	// +optional
+	UsedBytesAfter int64 `json:"usedBytesAfter,omitempty"`
+
+	// BytesFreed is the used space the remediation freed, negative when usage grew
+	// +optional
+	BytesFreed int64 `json:"bytesFreed,omitempty"`
+
+	// MeasuredAt is when the volumes were measured again
+	// +optional
+	MeasuredAt *metav1.Time `json:"measuredAt,omitempty"`
+
+	// Message describes the measurement
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VolumeModificationStatus tracks the ModifyVolume operation of a single PVC
+type VolumeModificationStatus struct {
+	// PVC is the name of the PVC
+	PVC string `json:"pvc"`
+
+	// PreviousClass is the VolumeAttributesClass the PVC used before the change
+	// +optional
+	PreviousClass string `json:"previousClass,omitempty"`
+
+	// Phase is the state of the modification, from the PVC's modifyVolumeStatus
+	Phase VolumeModificationPhase `json:"phase"`
 }
 
 // PVCPhase represents the phase of a single PVC operation
-// +kubebuilder:validation:Enum=Pending;InProgress;Completed;Failed
+// +kubebuilder:validation:Enum=Pending;InProgress;Completed;Failed;Skipped
 type PVCPhase string
 
 const (
@@ -133,8 +384,48 @@ const (
 	PVCPhaseCompleted PVCPhase = "Completed"
 	// PVCPhaseFailed indicates PVC operation failed
 	PVCPhaseFailed PVCPhase = "Failed"
+	// PVCPhaseSkipped indicates the PVC failed preflight validation and is not retried
+	PVCPhaseSkipped PVCPhase = "Skipped"
+)
+
+// StepPhase represents the phase of a single remediation step
+// +kubebuilder:validation:Enum=Pending;InProgress;Completed;Failed
+type StepPhase string
+
+const (
+	// StepPhasePending indicates the step has not started
+	StepPhasePending StepPhase = "Pending"
+	// StepPhaseInProgress indicates the step is running or waiting to be resumed
+	StepPhaseInProgress StepPhase = "InProgress"
+	// StepPhaseCompleted indicates the step completed
+	StepPhaseCompleted StepPhase = "Completed"
+	// StepPhaseFailed indicates the last attempt of the step failed
+	StepPhaseFailed StepPhase = "Failed"
 )
 
+// RemediationStep records the progress of one step of a multi-step remediation
+type RemediationStep struct {
+	// Name of the step
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Phase of the step
+	// +kubebuilder:validation:Required
+	Phase StepPhase `json:"phase"`
+
+	// StartTime is when the step was first started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the step completed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message provides details about the step outcome
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // PVCStatus represents the status of a single PVC operation
 type PVCStatus struct {
 	// Name of the PVC
@@ -145,6 +436,11 @@ type PVCStatus struct {
 	// +kubebuilder:validation:Required
 	Phase PVCPhase `json:"phase"`
 
+	// Instance is the cluster instance the PVC belongs to. The PVCs of an instance are
+	// planned and resized as one group
+	// +optional
+	Instance string `json:"instance,omitempty"`
+
 	// OriginalSize is the size before operation
 	// +optional
 	OriginalSize *resource.Quantity `json:"originalSize,omitempty"`
@@ -157,7 +453,7 @@ type PVCStatus struct {
 	// +optional
 	FilesystemResized bool `json:"filesystemResized,omitempty"`
 
-	// Error message if the operation failed
+	// Error message if the operation failed or was skipped
 	// +optional
 	Error string `json:"error,omitempty"`
 }
@@ -184,6 +480,17 @@ type StorageEventSpec struct {
 	// +optional
 	Reason string `json:"reason,omitempty"`
 
+	// TriggerUsagePercent is the storage usage of the cluster when the event was triggered
+	// +optional
+	TriggerUsagePercent int32 `json:"triggerUsagePercent,omitempty"`
+
+	// Ownership is the owner metadata of the cluster when the event was created
+	// +optional
+	Ownership *OwnershipMetadata `json:"ownership,omitempty"`
+
+	// ExpansionTarget selects the PVCs an expansion resizes
+	ExpansionTarget `json:",inline"`
+
 	// Expansion contains details for expansion events
 	// +optional
 	Expansion *ExpansionDetails `json:"expansion,omitempty"`
@@ -192,10 +499,35 @@ type StorageEventSpec struct {
 	// +optional
 	WALCleanup *WALCleanupDetails `json:"walCleanup,omitempty"`
 
+	// RestoreTest contains details for restore-test events
+	// +optional
+	RestoreTest *RestoreTestDetails `json:"restoreTest,omitempty"`
+
+	// StorageClassMigration contains details for storage-class-migration events
+	// +optional
+	StorageClassMigration *StorageClassMigrationDetails `json:"storageClassMigration,omitempty"`
+
+	// VolumeAttributesChange contains details for volume-attributes-change events
+	// +optional
+	VolumeAttributesChange *VolumeAttributesChangeDetails `json:"volumeAttributesChange,omitempty"`
+
 	// DryRun indicates this is a dry-run event
 	// +kubebuilder:default=false
 	// +optional
 	DryRun bool `json:"dryRun,omitempty"`
+
+	// ApprovalRequired indicates the event must be approved before it is executed
+	// +optional
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
+
+	// RecommendOnly indicates the event publishes desired sizes instead of resizing PVCs
+	// +optional
+	RecommendOnly bool `json:"recommendOnly,omitempty"`
+
+	// Approved releases an event that requires approval for execution.
+	// Setting the storage.cnpg.supporttools.io/approved annotation to "true" has the same effect.
+	// +optional
+	Approved bool `json:"approved,omitempty"`
 }
 
 // StorageEventStatus defines the observed state of StorageEvent
@@ -217,6 +549,39 @@ type StorageEventStatus struct {
 	// +optional
 	PVCStatuses []PVCStatus `json:"pvcStatuses,omitempty"`
 
+	// CurrentStep is the name of the step being executed or resumed
+	// +optional
+	CurrentStep string `json:"currentStep,omitempty"`
+
+	// Steps is the ordered transaction log of the remediation. Execution resumes
+	// from the first step that has not completed.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Steps []RemediationStep `json:"steps,omitempty"`
+
+	// RestoreTest records the resources and outcome of a restore-test event
+	// +optional
+	RestoreTest *RestoreTestStatus `json:"restoreTest,omitempty"`
+
+	// StorageClassMigration records the progress of a storage-class-migration event
+	// +optional
+	StorageClassMigration *StorageClassMigrationStatus `json:"storageClassMigration,omitempty"`
+
+	// ExpansionRollout records the progress of a rolling expansion
+	// +optional
+	ExpansionRollout *ExpansionRolloutStatus `json:"expansionRollout,omitempty"`
+
+	// VolumeModifications tracks the ModifyVolume operation of each PVC of a
+	// volume-attributes-change event
+	// +optional
+	VolumeModifications []VolumeModificationStatus `json:"volumeModifications,omitempty"`
+
+	// Effectiveness records whether an expansion or WAL cleanup relieved storage
+	// pressure, when the policy tracks effectiveness
+	// +optional
+	Effectiveness *RemediationEffectiveness `json:"effectiveness,omitempty"`
+
 	// Conditions represent the current state of the event
 	// +listType=map
 	// +listMapKey=type
@@ -242,6 +607,11 @@ const (
 	StorageEventConditionComplete = "Complete"
 	// StorageEventConditionProgressing indicates the event is progressing
 	StorageEventConditionProgressing = "Progressing"
+	// StorageEventConditionApproved indicates whether the event has been approved for execution
+	StorageEventConditionApproved = "Approved"
+	// StorageEventConditionPreActionHook indicates whether the policy's preAction hook
+	// allowed the event
+	StorageEventConditionPreActionHook = "PreActionHook"
 )
 
 // +kubebuilder:object:root=true
@@ -251,6 +621,8 @@ const (
 // +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.eventType"
 // +kubebuilder:printcolumn:name="Trigger",type="string",JSONPath=".spec.trigger"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Step",type="string",JSONPath=".status.currentStep",priority=1
+// +kubebuilder:printcolumn:name="Approved",type="boolean",JSONPath=".spec.approved",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // StorageEvent is the Schema for the storageevents API.
@@ -0,0 +1,169 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ForecastBasis is where the growth rate of a forecast comes from
+// +kubebuilder:validation:Enum=History;GrowthRate;None
+type ForecastBasis string
+
+const (
+	// ForecastBasisHistory means the growth rate is measured over the daily usage
+	// samples of the forecast
+	ForecastBasisHistory ForecastBasis = "History"
+	// ForecastBasisGrowthRate means the baseline growth rate of anomaly detection is used
+	// until the samples span a day
+	ForecastBasisGrowthRate ForecastBasis = "GrowthRate"
+	// ForecastBasisNone means no growth rate is known yet and usage is projected flat
+	ForecastBasisNone ForecastBasis = "None"
+)
+
+// StorageForecastSpec defines the cluster a StorageForecast covers
+type StorageForecastSpec struct {
+	// ClusterName is the CNPG cluster in the StorageForecast's namespace
+	ClusterName string `json:"clusterName"`
+}
+
+// ForecastHorizon is the projected storage of a cluster a number of days ahead
+type ForecastHorizon struct {
+	// ProjectedUsage is the projected usage of the fullest data volume, rounded up to Gi
+	ProjectedUsage resource.Quantity `json:"projectedUsage"`
+
+	// ProjectedCapacity is the projected size of the data volumes after the projected
+	// expansions, rounded up to Gi
+	ProjectedCapacity resource.Quantity `json:"projectedCapacity"`
+
+	// UsagePercent is the projected usage percentage of the fullest data volume
+	UsagePercent int32 `json:"usagePercent"`
+
+	// Expansions is the number of expansions projected until the horizon
+	Expansions int32 `json:"expansions"`
+
+	// AdditionalGi is the storage the projected expansions add across all instances
+	AdditionalGi int64 `json:"additionalGi"`
+
+	// ExceedsMaxSize is set when the data volumes would need to grow beyond
+	// expansion.maxSize before the horizon
+	// +optional
+	ExceedsMaxSize bool `json:"exceedsMaxSize,omitempty"`
+}
+
+// ProjectedExpansion is an expansion of the data volumes the forecast expects
+type ProjectedExpansion struct {
+	// Date is the day the expansion threshold is projected to be reached
+	Date metav1.Time `json:"date"`
+
+	// FromSize is the size of each data volume before the expansion
+	FromSize resource.Quantity `json:"fromSize"`
+
+	// ToSize is the size of each data volume after the expansion
+	ToSize resource.Quantity `json:"toSize"`
+}
+
+// StorageForecastStatus is the capacity planning forecast of a cluster
+type StorageForecastStatus struct {
+	// Policy is the StoragePolicy, as namespace/name, that computed the forecast
+	// +optional
+	Policy string `json:"policy,omitempty"`
+
+	// LastUpdated is when the forecast was last computed
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+
+	// Instances is the number of instances, each holding one data volume
+	// +optional
+	Instances int32 `json:"instances,omitempty"`
+
+	// Usage is the current usage of the fullest data volume
+	// +optional
+	Usage *resource.Quantity `json:"usage,omitempty"`
+
+	// Capacity is the current size of the fullest data volume
+	// +optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+
+	// GrowthPerDay is the growth rate of the fullest data volume the forecast projects
+	// +optional
+	GrowthPerDay *resource.Quantity `json:"growthPerDay,omitempty"`
+
+	// Basis is where the growth rate comes from
+	// +optional
+	Basis ForecastBasis `json:"basis,omitempty"`
+
+	// In30Days is the projected storage 30 days after lastUpdated
+	// +optional
+	In30Days *ForecastHorizon `json:"in30Days,omitempty"`
+
+	// In60Days is the projected storage 60 days after lastUpdated
+	// +optional
+	In60Days *ForecastHorizon `json:"in60Days,omitempty"`
+
+	// In90Days is the projected storage 90 days after lastUpdated
+	// +optional
+	In90Days *ForecastHorizon `json:"in90Days,omitempty"`
+
+	// ProjectedExpansions are the expansions projected within 90 days, oldest first
+	// +optional
+	ProjectedExpansions []ProjectedExpansion `json:"projectedExpansions,omitempty"`
+
+	// Samples are the daily usage samples of the fullest data volume over the last
+	// 90 days, oldest first
+	// +optional
+	Samples []UsageSample `json:"samples,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=sf
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterName"
+// +kubebuilder:printcolumn:name="Usage",type="string",JSONPath=".status.usage"
+// +kubebuilder:printcolumn:name="Growth/Day",type="string",JSONPath=".status.growthPerDay"
+// +kubebuilder:printcolumn:name="30d",type="string",JSONPath=".status.in30Days.projectedUsage"
+// +kubebuilder:printcolumn:name="60d",type="string",JSONPath=".status.in60Days.projectedUsage"
+// +kubebuilder:printcolumn:name="90d",type="string",JSONPath=".status.in90Days.projectedUsage"
+// +kubebuilder:printcolumn:name="Expansions",type="integer",JSONPath=".status.in90Days.expansions"
+// +kubebuilder:printcolumn:name="Additional Gi",type="integer",JSONPath=".status.in90Days.additionalGi"
+// +kubebuilder:printcolumn:name="Basis",type="string",JSONPath=".status.basis",priority=1
+// +kubebuilder:printcolumn:name="Updated",type="date",JSONPath=".status.lastUpdated"
+
+// StorageForecast is the Schema for the storageforecasts API. StoragePolicies with
+// forecast.enabled maintain one per cluster with its projected usage, expansions and
+// additional storage over the next 90 days
+type StorageForecast struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StorageForecastSpec   `json:"spec,omitempty"`
+	Status StorageForecastStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StorageForecastList contains a list of StorageForecast
+type StorageForecastList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StorageForecast `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StorageForecast{}, &StorageForecastList{})
+}
@@ -32,35 +32,116 @@ type ClusterReference struct {
 	Namespace string `json:"namespace"`
 }
 
-// ThresholdsConfig defines storage usage thresholds as percentages
+// ClusterInclusion selects clusters by name, for clusters that carry no usable labels
+type ClusterInclusion struct {
+	// Clusters are managed regardless of their labels
+	// +optional
+	Clusters []ClusterReference `json:"clusters,omitempty"`
+
+	// NamePatterns select every cluster whose name matches one of the patterns
+	// +optional
+	NamePatterns []ClusterNamePattern `json:"namePatterns,omitempty"`
+}
+
+// ClusterNamePattern matches cluster names with a regular expression
+type ClusterNamePattern struct {
+	// Pattern is an RE2 regular expression matched against the whole cluster name
+	// +kubebuilder:validation:MinLength=1
+	Pattern string `json:"pattern"`
+
+	// Namespace restricts the pattern to clusters in one namespace. Empty matches
+	// clusters in every namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ThresholdsConfig defines storage usage thresholds as percentages. Unset thresholds take
+// the ManagerConfig default, then 70, 80, 85 and 90 percent respectively. HeadroomGi and
+// AbsoluteFreeBytesFloor add thresholds on the free space left, and a level is breached
+// by whichever of its thresholds fires first
 type ThresholdsConfig struct {
 	// Warning threshold percentage for generating warning alerts
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
-	// +kubebuilder:default=70
 	// +optional
 	Warning int32 `json:"warning,omitempty"`
 
 	// Critical threshold percentage for generating critical alerts
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
-	// +kubebuilder:default=80
 	// +optional
 	Critical int32 `json:"critical,omitempty"`
 
 	// Expansion threshold percentage for triggering automatic PVC expansion
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
-	// +kubebuilder:default=85
 	// +optional
 	Expansion int32 `json:"expansion,omitempty"`
 
 	// Emergency threshold percentage for triggering WAL cleanup
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
-	// +kubebuilder:default=90
 	// +optional
 	Emergency int32 `json:"emergency,omitempty"`
+
+	// HeadroomGi breaches a level when the volume with the least free space has less than
+	// the given Gi left, so large volumes are not left with hundreds of Gi unused
+	// +optional
+	HeadroomGi *HeadroomThresholds `json:"headroomGi,omitempty"`
+
+	// AbsoluteFreeBytesFloor breaches the emergency level when the volume with the least
+	// free space has less than this left, e.g. "500Mi"
+	// +optional
+	AbsoluteFreeBytesFloor *resource.Quantity `json:"absoluteFreeBytesFloor,omitempty"`
+}
+
+// HeadroomThresholds defines thresholds as the free space left on a volume, in Gi.
+// Unset thresholds are not evaluated
+type HeadroomThresholds struct {
+	// Warning is the free space below which warning alerts are generated
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Warning int32 `json:"warning,omitempty"`
+
+	// Critical is the free space below which critical alerts are generated
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Critical int32 `json:"critical,omitempty"`
+
+	// Expansion is the free space below which the PVCs are expanded
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Expansion int32 `json:"expansion,omitempty"`
+
+	// Emergency is the free space below which WAL cleanup is triggered
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Emergency int32 `json:"emergency,omitempty"`
+}
+
+// ExpansionMode defines how expansion decisions are carried out
+// +kubebuilder:validation:Enum=apply;recommend
+type ExpansionMode string
+
+const (
+	// ExpansionModeApply resizes PVCs directly
+	ExpansionModeApply ExpansionMode = "apply"
+	// ExpansionModeRecommend publishes the desired sizes without modifying PVCs
+	ExpansionModeRecommend ExpansionMode = "recommend"
+)
+
+// RecommendationConfig defines where expansion recommendations are published
+// when the expansion mode is recommend
+type RecommendationConfig struct {
+	// ConfigMapName is the ConfigMap in the policy namespace that receives one
+	// JSON document per cluster. Defaults to "<policy-name>-recommendations".
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// WebhookSecret references a Secret (namespace/name) with a 'webhook-url' key.
+	// When set, each recommendation is also POSTed there as JSON.
+	// +optional
+	WebhookSecret string `json:"webhookSecret,omitempty"`
 }
 
 // ExpansionConfig defines PVC expansion settings
@@ -87,242 +168,1597 @@ type ExpansionConfig struct {
 	// +optional
 	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
 
-	// CooldownMinutes is the minimum time between expansions
+	// WALCleanupAtMaxSize requests a WAL cleanup when a data PVC has reached maxSize and
+	// usage is above the expansion threshold, instead of waiting for the emergency
+	// threshold. It requires walCleanup.enabled
+	// +kubebuilder:default=false
+	// +optional
+	WALCleanupAtMaxSize bool `json:"walCleanupAtMaxSize,omitempty"`
+
+	// CooldownMinutes is the minimum time between expansions. Unset or 0 takes the
+	// ManagerConfig expansionCooldownMinutes, then 30
 	// +kubebuilder:validation:Minimum=0
-	// +kubebuilder:default=30
 	// +optional
 	CooldownMinutes int32 `json:"cooldownMinutes,omitempty"`
-}
 
-// WALCleanupConfig defines WAL file cleanup settings
-type WALCleanupConfig struct {
-	// Enabled determines if WAL cleanup is enabled
-	// +kubebuilder:default=true
+	// SustainedMinutes is how long the expansion threshold must stay breached, across
+	// consecutive evaluations, before the cluster is expanded, so transient spikes such
+	// as bulk loads or temp files do not grow the PVCs for good. 0 expands right away
+	// +kubebuilder:validation:Minimum=0
 	// +optional
-	Enabled bool `json:"enabled,omitempty"`
+	SustainedMinutes int32 `json:"sustainedMinutes,omitempty"`
 
-	// RetainCount is the minimum number of WAL files to retain
-	// +kubebuilder:validation:Minimum=1
-	// +kubebuilder:default=10
+	// ApprovalRequired holds expansion StorageEvents in Pending until they are approved
+	// +kubebuilder:default=false
 	// +optional
-	RetainCount int32 `json:"retainCount,omitempty"`
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
 
-	// RequireArchived ensures only archived WAL files are cleaned
-	// +kubebuilder:default=true
+	// DryRun only logs and reports expansions while the rest of the policy enforces
+	// +kubebuilder:default=false
 	// +optional
-	RequireArchived bool `json:"requireArchived,omitempty"`
+	DryRun bool `json:"dryRun,omitempty"`
 
-	// CooldownMinutes is the minimum time between WAL cleanups
-	// +kubebuilder:validation:Minimum=0
-	// +kubebuilder:default=15
+	// Mode selects whether PVCs are resized directly or the desired sizes are only
+	// published for a GitOps pipeline to apply
+	// +kubebuilder:default=apply
 	// +optional
-	CooldownMinutes int32 `json:"cooldownMinutes,omitempty"`
-}
+	Mode ExpansionMode `json:"mode,omitempty"`
 
-// CircuitBreakerScope defines the scope of circuit breaker tracking
-// +kubebuilder:validation:Enum=per-cluster;global
-type CircuitBreakerScope string
+	// Recommendation configures where desired sizes are published in recommend mode
+	// +optional
+	Recommendation RecommendationConfig `json:"recommendation,omitempty"`
 
-const (
-	// CircuitBreakerScopePerCluster tracks failures per cluster
-	CircuitBreakerScopePerCluster CircuitBreakerScope = "per-cluster"
-	// CircuitBreakerScopeGlobal tracks failures globally
-	CircuitBreakerScopeGlobal CircuitBreakerScope = "global"
-)
+	// FrequencyAlert alerts when a cluster expands too often, which usually indicates
+	// runaway growth that needs human attention
+	// +optional
+	FrequencyAlert *ExpansionFrequencyAlert `json:"frequencyAlert,omitempty"`
 
-// CircuitBreakerConfig defines circuit breaker settings
-type CircuitBreakerConfig struct {
-	// MaxFailures is the number of failures before circuit opens
-	// +kubebuilder:validation:Minimum=1
-	// +kubebuilder:default=3
+	// Tablespaces override the expansion settings for individual declarative tablespaces.
+	// Each tablespace is evaluated against the thresholds on its own and expanded
+	// separately from the data and WAL volumes
+	// +listType=map
+	// +listMapKey=name
 	// +optional
-	MaxFailures int32 `json:"maxFailures,omitempty"`
+	Tablespaces []TablespaceExpansionConfig `json:"tablespaces,omitempty"`
 
-	// ResetMinutes is the time before circuit resets
-	// +kubebuilder:validation:Minimum=1
-	// +kubebuilder:default=60
+	// FileSystemResize handles PVCs stuck waiting for their filesystem to be resized
 	// +optional
-	ResetMinutes int32 `json:"resetMinutes,omitempty"`
+	FileSystemResize FileSystemResizeConfig `json:"fileSystemResize,omitempty"`
 
-	// Scope defines whether circuit breaker is per-cluster or global
-	// +kubebuilder:default="per-cluster"
+	// GroupInstanceVolumes expands the tablespace volumes of each instance along with its
+	// data and WAL volumes when the cluster is expanded, so every volume of an instance
+	// grows together. Tablespaces whose expansion is disabled are left out
+	// +kubebuilder:default=false
 	// +optional
-	Scope CircuitBreakerScope `json:"scope,omitempty"`
+	GroupInstanceVolumes bool `json:"groupInstanceVolumes,omitempty"`
+
+	// Rollout expands the instances of a cluster one at a time, verifying each before the
+	// next, instead of resizing every instance at once
+	// +optional
+	Rollout *ExpansionRolloutConfig `json:"rollout,omitempty"`
 }
 
-// AlertChannelType defines the type of alert channel
-// +kubebuilder:validation:Enum=alertmanager;slack;pagerduty
-type AlertChannelType string
+// ExpansionOrder is the order a rolling expansion expands the instances of a cluster in
+// +kubebuilder:validation:Enum=replicas-first;primary-first
+type ExpansionOrder string
 
 const (
-	// AlertChannelTypeAlertmanager sends alerts to Prometheus Alertmanager
-	AlertChannelTypeAlertmanager AlertChannelType = "alertmanager"
-	// AlertChannelTypeSlack sends alerts to Slack
-	AlertChannelTypeSlack AlertChannelType = "slack"
-	// AlertChannelTypePagerDuty sends alerts to PagerDuty
-	AlertChannelTypePagerDuty AlertChannelType = "pagerduty"
+	// ExpansionOrderReplicasFirst expands the replicas before the primary
+	ExpansionOrderReplicasFirst ExpansionOrder = "replicas-first"
+	// ExpansionOrderPrimaryFirst expands the primary before the replicas
+	ExpansionOrderPrimaryFirst ExpansionOrder = "primary-first"
 )
 
-// AlertChannel defines a single alert channel configuration
-type AlertChannel struct {
-	// Type of alert channel
-	// +kubebuilder:validation:Required
-	Type AlertChannelType `json:"type"`
+// ExpansionRolloutConfig configures the rolling expansion of the instances of a cluster
+type ExpansionRolloutConfig struct {
+	// Order selects whether the replicas or the primary are expanded first
+	// +kubebuilder:default=replicas-first
+	// +optional
+	Order ExpansionOrder `json:"order,omitempty"`
 
-	// Endpoint for alertmanager type
+	// WaitForHealthy waits for every instance of the cluster to be ready after an
+	// instance is expanded and before the next one is
+	// +kubebuilder:default=true
 	// +optional
-	Endpoint string `json:"endpoint,omitempty"`
+	WaitForHealthy bool `json:"waitForHealthy,omitempty"`
 
-	// WebhookSecret is the name of the secret containing webhook URL for slack
+	// HealthyTimeoutMinutes is how long an instance may take to report its new capacity,
+	// and the cluster to be healthy again, before the expansion fails and is retried
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=30
 	// +optional
-	WebhookSecret string `json:"webhookSecret,omitempty"`
+	HealthyTimeoutMinutes int32 `json:"healthyTimeoutMinutes,omitempty"`
+}
 
-	// RoutingKeySecret is the name of the secret containing routing key for pagerduty
+// FileSystemResizeConfig handles PVCs whose FileSystemResizePending condition persists,
+// e.g. because the node only resizes the filesystem offline or the CSI driver is stuck
+type FileSystemResizeConfig struct {
+	// TimeoutMinutes is how long a PVC may wait for its filesystem resize before it is
+	// reported and alerted on
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=30
 	// +optional
-	RoutingKeySecret string `json:"routingKeySecret,omitempty"`
+	TimeoutMinutes int32 `json:"timeoutMinutes,omitempty"`
 
-	// Channel for slack notifications
+	// RestartPod deletes the pod of a stuck PVC, so the filesystem is resized offline
+	// when CNPG recreates it. One instance is restarted per reconcile, replicas before
+	// the primary, and only while every instance of the cluster is ready
+	// +kubebuilder:default=false
 	// +optional
-	Channel string `json:"channel,omitempty"`
+	RestartPod bool `json:"restartPod,omitempty"`
 }
 
-// AlertingConfig defines alerting settings
-type AlertingConfig struct {
-	// Channels is the list of alert channels
+// TablespaceExpansionConfig overrides the expansion settings of one tablespace. Unset
+// fields take the policy's expansion settings
+type TablespaceExpansionConfig struct {
+	// Name of the tablespace in the cluster's spec.tablespaces
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	VolumeExpansionConfig `json:",inline"`
+}
+
+// VolumeExpansionConfig overrides the expansion settings of one kind of volume. Unset
+// fields take the policy's expansion settings
+type VolumeExpansionConfig struct {
+	// Enabled determines if the volume is expanded automatically
 	// +optional
-	Channels []AlertChannel `json:"channels,omitempty"`
+	Enabled *bool `json:"enabled,omitempty"`
 
-	// SuppressDuringRemediation suppresses alerts while remediation is active
-	// +kubebuilder:default=true
+	// Percentage to expand the PVCs by
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=500
 	// +optional
-	SuppressDuringRemediation bool `json:"suppressDuringRemediation,omitempty"`
+	Percentage int32 `json:"percentage,omitempty"`
 
-	// EscalationMinutes is the time before re-alerting on unresolved issues
+	// MinIncrementGi is the minimum expansion size in Gi
 	// +kubebuilder:validation:Minimum=1
-	// +kubebuilder:default=15
 	// +optional
-	EscalationMinutes int32 `json:"escalationMinutes,omitempty"`
+	MinIncrementGi int32 `json:"minIncrementGi,omitempty"`
+
+	// MaxSize is the maximum size of the PVCs
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
 }
 
-// BackupMonitoringConfig defines backup and WAL archiving monitoring settings
-type BackupMonitoringConfig struct {
-	// Enabled determines if backup monitoring is enabled
+// ExpansionFrequencyAlert alerts when a cluster expands more than maxExpansions times
+// within the window
+type ExpansionFrequencyAlert struct {
+	// MaxExpansions is the number of expansions within the window that is still expected.
+	// The history keeps the last 20 expansions, so at most 19
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=19
+	MaxExpansions int32 `json:"maxExpansions"`
+
+	// WindowHours is the length of the window
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=168
+	// +optional
+	WindowHours int32 `json:"windowHours,omitempty"`
+}
+
+// WALCleanupConfig defines WAL file cleanup settings
+type WALCleanupConfig struct {
+	// Enabled determines if WAL cleanup is enabled
 	// +kubebuilder:default=true
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
 
-	// MaxBackupAgeHours is the maximum age of the last successful backup before alerting
-	// Set to 0 to disable backup age monitoring
-	// +kubebuilder:validation:Minimum=0
-	// +kubebuilder:default=24
+	// RetainCount is the minimum number of WAL files to retain
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
 	// +optional
-	MaxBackupAgeHours int32 `json:"maxBackupAgeHours,omitempty"`
+	RetainCount int32 `json:"retainCount,omitempty"`
 
-	// RequireContinuousArchiving alerts if WAL archiving is not working
+	// RequireArchived ensures only archived WAL files are cleaned
 	// +kubebuilder:default=true
 	// +optional
-	RequireContinuousArchiving bool `json:"requireContinuousArchiving,omitempty"`
+	RequireArchived bool `json:"requireArchived,omitempty"`
 
-	// MaxRecoveryPointAgeHours is the maximum age of the first recovery point before alerting
-	// Set to 0 to disable recovery point age monitoring
+	// CooldownMinutes is the minimum time between WAL cleanups. Unset or 0 takes the
+	// ManagerConfig walCleanupCooldownMinutes, then 15
 	// +kubebuilder:validation:Minimum=0
-	// +kubebuilder:default=168
 	// +optional
-	MaxRecoveryPointAgeHours int32 `json:"maxRecoveryPointAgeHours,omitempty"`
+	CooldownMinutes int32 `json:"cooldownMinutes,omitempty"`
 
-	// AlertOnNoBackupConfigured alerts if a cluster has no backup configured
-	// +kubebuilder:default=true
+	// ApprovalRequired holds WAL cleanup StorageEvents in Pending until they are approved
+	// +kubebuilder:default=false
 	// +optional
-	AlertOnNoBackupConfigured bool `json:"alertOnNoBackupConfigured,omitempty"`
-}
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
 
-// StoragePolicySpec defines the desired state of StoragePolicy
-type StoragePolicySpec struct {
-	// Selector is a label selector for matching CNPG clusters
+	// DryRun only logs and reports WAL cleanups while the rest of the policy enforces
+	// +kubebuilder:default=false
 	// +optional
-	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	DryRun bool `json:"dryRun,omitempty"`
 
-	// ExcludeClusters is a list of clusters to exclude even if they match the selector
+	// IncludeReplicas also cleans up WAL on replicas. A replica volume breaching the
+	// emergency threshold triggers a cleanup even when the cluster as a whole does not.
+	// Replicas only lose segments older than their latest restartpoint
 	// +optional
-	ExcludeClusters []ClusterReference `json:"excludeClusters,omitempty"`
+	IncludeReplicas bool `json:"includeReplicas,omitempty"`
 
-	// Thresholds defines storage usage thresholds
+	// WALDirectory overrides the pg_wal directory of every instance. When unset it is
+	// detected from the data_directory setting and the pod's WAL volume mount
+	// +kubebuilder:validation:Pattern=`^/`
 	// +optional
-	Thresholds ThresholdsConfig `json:"thresholds,omitempty"`
+	WALDirectory string `json:"walDirectory,omitempty"`
 
-	// Expansion defines PVC expansion settings
+	// AllowedCommands restricts the executables WAL cleanup may run in instance pods.
+	// Empty allows all of them. Without psql the CHECKPOINT step is skipped and the
+	// removal horizon is read from pg_controldata; without rm nothing is removed
+	// +kubebuilder:validation:items:Enum=ls;rm;psql;pg_controldata
 	// +optional
-	Expansion ExpansionConfig `json:"expansion,omitempty"`
+	AllowedCommands []string `json:"allowedCommands,omitempty"`
+}
 
-	// WALCleanup defines WAL file cleanup settings
+// AnomalyDetectionConfig defines runaway-growth detection. The growth rate of the last
+// recentWindowMinutes is compared to the rate over the preceding baselineWindowHours, so
+// abnormally fast growth is caught long before a usage threshold is reached
+type AnomalyDetectionConfig struct {
+	// Enabled enables growth anomaly detection
+	// +kubebuilder:default=false
 	// +optional
-	WALCleanup WALCleanupConfig `json:"walCleanup,omitempty"`
+	Enabled bool `json:"enabled,omitempty"`
 
-	// BackupMonitoring defines backup and WAL archiving monitoring settings
+	// RecentWindowMinutes is the window the current growth rate is measured over
+	// +kubebuilder:validation:Minimum=15
+	// +kubebuilder:default=60
 	// +optional
-	BackupMonitoring BackupMonitoringConfig `json:"backupMonitoring,omitempty"`
+	RecentWindowMinutes int32 `json:"recentWindowMinutes,omitempty"`
 
-	// CircuitBreaker defines circuit breaker settings
+	// BaselineWindowHours is the window the baseline growth rate is measured over. Detection
+	// starts once half of it has been observed
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=168
+	// +kubebuilder:default=24
 	// +optional
-	CircuitBreaker CircuitBreakerConfig `json:"circuitBreaker,omitempty"`
+	BaselineWindowHours int32 `json:"baselineWindowHours,omitempty"`
 
-	// Alerting defines alerting settings
+	// GrowthFactor is how many times faster than the baseline the current growth must be
+	// to be anomalous
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:default=3
 	// +optional
-	Alerting AlertingConfig `json:"alerting,omitempty"`
+	GrowthFactor int32 `json:"growthFactor,omitempty"`
 
-	// DryRun enables dry-run mode where no actions are taken
-	// +kubebuilder:default=false
+	// MinGrowthMiPerHour is the growth rate below which growth is never anomalous, so
+	// idle clusters with a near-zero baseline do not alert on small writes
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=512
 	// +optional
-	DryRun bool `json:"dryRun,omitempty"`
+	MinGrowthMiPerHour int32 `json:"minGrowthMiPerHour,omitempty"`
 }
 
-// ManagedCluster represents a cluster managed by this policy
-type ManagedCluster struct {
-	// Name of the CNPG cluster
-	Name string `json:"name"`
+// ForecastConfig defines the capacity planning forecasts of a policy. Once per schedule
+// the usage of each cluster's data volumes is sampled and projected 30, 60 and 90 days
+// ahead, with the expansions the policy's expansion settings would perform
+type ForecastConfig struct {
+	// Enabled maintains a StorageForecast named after each selected cluster in the
+	// cluster's namespace. Clusters reached through a ClusterConnection are not forecast
+	// +kubebuilder:default=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
 
-	// Namespace of the CNPG cluster
-	Namespace string `json:"namespace"`
+	// Schedule is the cron expression of the forecast refresh, in the manager's time zone
+	// +kubebuilder:default="0 2 * * *"
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+}
 
-	// LastChecked is when the cluster was last evaluated
-	LastChecked metav1.Time `json:"lastChecked"`
+// UsageHistoryConfig defines the persisted usage history of a policy. The usage of
+// each cluster is sampled once per hour and kept for 30 days, and seeds anomaly
+// detection and forecasts when their own samples are missing or too short
+type UsageHistoryConfig struct {
+	// Enabled maintains a StorageUsageHistory named after each selected cluster in the
+	// cluster's namespace
+	// +kubebuilder:default=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+}
 
-	// UsagePercent is the current storage usage percentage
-	UsagePercent int32 `json:"usagePercent"`
+// QuotaManagementConfig monitors the requests.storage ResourceQuotas of the namespaces
+// of the selected clusters, so a quota that cannot hold the next expansion is raised
+// before the expansion is needed
+type QuotaManagementConfig struct {
+	// Enabled checks every reconcile whether the ResourceQuotas and LimitRanges of each
+	// cluster's namespace leave room for its next expansion, and alerts when they do not
+	// +kubebuilder:default=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
 
-	// Status is the current status of the cluster
-	Status string `json:"status"`
+	// AutoRaise patches the hard limit of a ResourceQuota that cannot hold the next
+	// expansion to the value it needs. LimitRanges are never changed
+	// +kubebuilder:default=false
+	// +optional
+	AutoRaise bool `json:"autoRaise,omitempty"`
 
-	// BackupStatus contains backup-related status information
+	// MaxHard caps the hard limits AutoRaise sets. A quota that would need more is only
+	// alerted on. Unset places no cap
 	// +optional
-	BackupStatus *ClusterBackupStatus `json:"backupStatus,omitempty"`
+	MaxHard *resource.Quantity `json:"maxHard,omitempty"`
 }
 
-// ClusterBackupStatus contains backup and WAL archiving status for a cluster
-type ClusterBackupStatus struct {
-	// LastBackupTime is the timestamp of the last successful backup
+// FencingConfig fences instances whose storage is full, so PostgreSQL is stopped
+// cleanly instead of panicking while their volumes are expanded. Only instances the
+// manager fenced are unfenced again
+type FencingConfig struct {
+	// Enabled enables fencing instances at fenceAtPercent
+	// +kubebuilder:default=false
 	// +optional
-	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+	Enabled bool `json:"enabled,omitempty"`
 
-	// LastBackupAgeHours is how many hours since the last backup
+	// FenceAtPercent is the usage of an instance's fullest volume at which it is fenced
+	// +kubebuilder:validation:Minimum=90
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=99
 	// +optional
-	LastBackupAgeHours int32 `json:"lastBackupAgeHours,omitempty"`
+	FenceAtPercent int32 `json:"fenceAtPercent,omitempty"`
 
-	// FirstRecoverabilityPoint is the timestamp of the oldest recoverable point
+	// UnfenceBelowPercent is the usage below which an instance fenced by the manager is
+	// unfenced, once its volumes were expanded or space was freed
+	// +kubebuilder:validation:Minimum=50
+	// +kubebuilder:validation:Maximum=99
+	// +kubebuilder:default=90
 	// +optional
-	FirstRecoverabilityPoint *metav1.Time `json:"firstRecoverabilityPoint,omitempty"`
+	UnfenceBelowPercent int32 `json:"unfenceBelowPercent,omitempty"`
+}
 
-	// ContinuousArchivingWorking indicates if WAL archiving is functioning
+// WriteProbeConfig probes whether the primary of each cluster accepts writes. Disk metrics
+// can look healthy while PostgreSQL already fails writes, e.g. with "could not extend file"
+type WriteProbeConfig struct {
+	// Enabled runs a short SQL write against the primary on every reconcile. It requires
+	// the exec command runner
+	// +kubebuilder:default=false
 	// +optional
-	ContinuousArchivingWorking bool `json:"continuousArchivingWorking,omitempty"`
+	Enabled bool `json:"enabled,omitempty"`
+}
 
-	// BackupConfigured indicates if backups are configured for the cluster
+// StorageClassMigrationConfig moves the volumes of the selected clusters to another
+// StorageClass, e.g. from gp2 to gp3. The Cluster spec is updated and the instances are
+// recreated one at a time, replicas first, each re-syncing before the next is touched
+type StorageClassMigrationConfig struct {
+	// StorageClass is the StorageClass the data volumes are moved to. Clusters whose
+	// volumes already use it are left alone
+	// +kubebuilder:validation:MinLength=1
+	StorageClass string `json:"storageClass"`
+
+	// WALStorageClass is the StorageClass the separate WAL volumes are moved to.
+	// Defaults to storageClass
 	// +optional
-	BackupConfigured bool `json:"backupConfigured,omitempty"`
+	WALStorageClass string `json:"walStorageClass,omitempty"`
 
-	// BackupStatus is the overall backup health status
+	// MaxReplicationLagMB aborts the migration when a replica lags the primary by more
+	// than this much WAL, since recreating an instance lowers the cluster's redundancy
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1024
 	// +optional
-	BackupHealthStatus string `json:"backupHealthStatus,omitempty"`
+	MaxReplicationLagMB int32 `json:"maxReplicationLagMB,omitempty"`
+
+	// ResyncTimeoutMinutes is how long a recreated instance may take to re-sync before
+	// the migration is aborted
+	// +kubebuilder:validation:Minimum=5
+	// +kubebuilder:default=60
+	// +optional
+	ResyncTimeoutMinutes int32 `json:"resyncTimeoutMinutes,omitempty"`
+
+	// ApprovalRequired holds migration StorageEvents in Pending until they are approved
+	// +kubebuilder:default=false
+	// +optional
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
+
+	// DryRun only logs and reports migrations while the rest of the policy enforces
+	// +kubebuilder:default=false
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// VolumeAttributesClassThreshold selects the VolumeAttributesClass for a threshold level
+type VolumeAttributesClassThreshold struct {
+	// Threshold is the usage threshold level the class applies from. normal applies
+	// below the warning threshold, e.g. to move volumes back to a cheaper class
+	// +kubebuilder:validation:Enum=normal;warning;critical;expansion;emergency
+	Threshold string `json:"threshold"`
+
+	// VolumeAttributesClass is the class the PVCs are moved to
+	// +kubebuilder:validation:MinLength=1
+	VolumeAttributesClass string `json:"volumeAttributesClass"`
+}
+
+// VolumeAttributesConfig moves the PVCs of the selected clusters to another
+// VolumeAttributesClass as their usage crosses thresholds, e.g. to raise provisioned
+// IOPS and throughput while a cluster is close to full. It requires the
+// VolumeAttributesClass API: beta behind a feature gate since Kubernetes 1.31, GA in 1.34
+type VolumeAttributesConfig struct {
+	// Classes maps threshold levels to VolumeAttributesClasses. The class of the
+	// highest level the cluster reached applies; below every listed level the PVCs are
+	// left alone
+	// +kubebuilder:validation:MinItems=1
+	Classes []VolumeAttributesClassThreshold `json:"classes"`
+
+	// ModifyTimeoutMinutes is how long the CSI driver may take to modify a volume
+	// before the change is retried
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=30
+	// +optional
+	ModifyTimeoutMinutes int32 `json:"modifyTimeoutMinutes,omitempty"`
+
+	// ApprovalRequired holds volume-attributes-change StorageEvents in Pending until
+	// they are approved
+	// +kubebuilder:default=false
+	// +optional
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
+
+	// DryRun only logs and reports class changes while the rest of the policy enforces
+	// +kubebuilder:default=false
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// PerformanceTuningConfig raises the performance of volumes whose latency, rather than
+// their capacity, is the bottleneck, e.g. gp3, Azure Premium SSD v2 or Hyperdisk volumes
+// with provisioned IOPS and throughput. Latency is read from Prometheus, and PVCs above
+// the threshold are moved to a VolumeAttributesClass and/or given driver annotations.
+// Clusters at the expansion threshold are left to expansion
+// +kubebuilder:validation:XValidation:rule="has(self.volumeAttributesClass) || has(self.annotations)",message="volumeAttributesClass or annotations is required"
+type PerformanceTuningConfig struct {
+	// PrometheusURL is the base URL of the Prometheus HTTP API the latency is read from
+	// +kubebuilder:validation:MinLength=1
+	PrometheusURL string `json:"prometheusURL"`
+
+	// LatencyQuery is the PromQL query returning the latency of each PVC in milliseconds,
+	// as an instant vector labelled with namespace and persistentvolumeclaim. $cluster and
+	// $namespace are replaced with the cluster being evaluated
+	// +kubebuilder:validation:MinLength=1
+	LatencyQuery string `json:"latencyQuery"`
+
+	// LatencyThresholdMs is the latency at which a PVC is tuned
+	// +kubebuilder:validation:Minimum=1
+	LatencyThresholdMs int32 `json:"latencyThresholdMs"`
+
+	// VolumeAttributesClass is the VolumeAttributesClass tuned PVCs are moved to. It
+	// requires Kubernetes 1.34, or the VolumeAttributesClass feature gate before that
+	// +optional
+	VolumeAttributesClass string `json:"volumeAttributesClass,omitempty"`
+
+	// Annotations are set on tuned PVCs, for CSI drivers that modify volumes from PVC
+	// annotations, e.g. ebs.csi.aws.com/iops and ebs.csi.aws.com/throughput
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// DryRun only logs and reports tuning while the rest of the policy enforces
+	// +kubebuilder:default=false
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// MetricsSource selects where volume usage is collected from
+// +kubebuilder:validation:Enum=kubelet;exec;agent
+type MetricsSource string
+
+const (
+	// MetricsSourceKubelet reads kubelet volume stats, falling back to df in the pods
+	MetricsSourceKubelet MetricsSource = "kubelet"
+	// MetricsSourceExec runs df in the pods
+	MetricsSourceExec MetricsSource = "exec"
+	// MetricsSourceAgent queries the node agent DaemonSet
+	MetricsSourceAgent MetricsSource = "agent"
+)
+
+// CircuitBreakerScope defines the scope of circuit breaker tracking
+// +kubebuilder:validation:Enum=per-cluster;global
+type CircuitBreakerScope string
+
+const (
+	// CircuitBreakerScopePerCluster tracks failures per cluster
+	CircuitBreakerScopePerCluster CircuitBreakerScope = "per-cluster"
+	// CircuitBreakerScopeGlobal tracks failures globally
+	CircuitBreakerScopeGlobal CircuitBreakerScope = "global"
+)
+
+// EffectivenessConfig defines how completed expansions and WAL cleanups are checked to
+// have relieved storage pressure
+type EffectivenessConfig struct {
+	// Enabled measures the usage of the remediated volumes before each expansion and WAL
+	// cleanup and again after it completed, and alerts when usage did not drop
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// DelayMinutes is how long after a remediation completed its volumes are measured
+	// again, so filesystem resizes and WAL recycling have settled
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DelayMinutes int32 `json:"delayMinutes,omitempty"`
+}
+
+// StorageSLOConfig defines a storage availability objective. A cluster violates it while
+// its usage is at or above the critical threshold or its backups are unhealthy
+type StorageSLOConfig struct {
+	// Enabled tracks the time each cluster violates the objective in its status and in
+	// the storage_slo_* metrics
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Objective is the percentage of time a cluster must not violate the objective,
+	// such as "99.5"
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,2}(\.[0-9]+)?$`
+	// +kubebuilder:default="99.5"
+	// +optional
+	Objective string `json:"objective,omitempty"`
+
+	// WindowDays is the window the error budget is spent over. The budget is reset when
+	// a window ends
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=90
+	// +kubebuilder:default=30
+	// +optional
+	WindowDays int32 `json:"windowDays,omitempty"`
+}
+
+// PoolerMonitoringConfig configures the monitoring of volumes mounted by CNPG Pooler pods,
+// such as PVCs used for pgBouncer logs
+type PoolerMonitoringConfig struct {
+	// Enabled discovers the Poolers of each cluster and reports the usage of the PVCs
+	// their pods mount. Pooler volumes are alerted on but never expanded
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// CircuitBreakerConfig defines circuit breaker settings
+type CircuitBreakerConfig struct {
+	// MaxFailures is the number of failures before circuit opens
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	// +optional
+	MaxFailures int32 `json:"maxFailures,omitempty"`
+
+	// ResetMinutes is the time before circuit resets
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=60
+	// +optional
+	ResetMinutes int32 `json:"resetMinutes,omitempty"`
+
+	// Scope defines whether circuit breaker is per-cluster or global
+	// +kubebuilder:default="per-cluster"
+	// +optional
+	Scope CircuitBreakerScope `json:"scope,omitempty"`
+}
+
+// AlertChannelType defines the type of alert channel
+// +kubebuilder:validation:Enum=alertmanager;slack;pagerduty;servicenow;jira
+type AlertChannelType string
+
+const (
+	// AlertChannelTypeAlertmanager sends alerts to Prometheus Alertmanager
+	AlertChannelTypeAlertmanager AlertChannelType = "alertmanager"
+	// AlertChannelTypeSlack sends alerts to Slack
+	AlertChannelTypeSlack AlertChannelType = "slack"
+	// AlertChannelTypePagerDuty sends alerts to PagerDuty
+	AlertChannelTypePagerDuty AlertChannelType = "pagerduty"
+	// AlertChannelTypeServiceNow opens ServiceNow tickets
+	AlertChannelTypeServiceNow AlertChannelType = "servicenow"
+	// AlertChannelTypeJira opens Jira issues
+	AlertChannelTypeJira AlertChannelType = "jira"
+)
+
+// AlertChannel defines a single alert channel configuration
+type AlertChannel struct {
+	// Type of alert channel
+	// +kubebuilder:validation:Required
+	Type AlertChannelType `json:"type"`
+
+	// Endpoint for alertmanager type, or the instance URL of servicenow and jira
+	// channels, e.g. https://example.service-now.com
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// WebhookSecret is the name of the secret containing webhook URL for slack
+	// +optional
+	WebhookSecret string `json:"webhookSecret,omitempty"`
+
+	// RoutingKeySecret is the name of the secret containing routing key for pagerduty
+	// +optional
+	RoutingKeySecret string `json:"routingKeySecret,omitempty"`
+
+	// Channel for slack notifications. Required with botTokenSecret
+	// +optional
+	Channel string `json:"channel,omitempty"`
+
+	// BotTokenSecret is the name of the secret whose 'bot-token' key holds a Slack bot
+	// token. When set, the slack channel posts through chat.postMessage instead of the
+	// webhook and threads follow-ups, remediation results and the resolution under the
+	// original alert
+	// +optional
+	BotTokenSecret string `json:"botTokenSecret,omitempty"`
+
+	// Ticket configures servicenow and jira channels
+	// +optional
+	Ticket *TicketConfig `json:"ticket,omitempty"`
+
+	// Templates replace the title and body of the alerts sent through the channel
+	// +optional
+	Templates *AlertTemplates `json:"templates,omitempty"`
+}
+
+// AlertTemplates are Go templates rendered with the alert, e.g. to match a runbook
+// format or link to one. They see .ClusterName, .ClusterNamespace, .Connection,
+// .SourceCluster, .Policy, .OwnerTeam, .RunbookURL, .Escalation, .Type, .Severity,
+// .Message, .UsagePercent, .Thresholds, .LastBackup, .BackupAge, .Details and
+// .Timestamp. A template that fails to render falls back to the default text
+type AlertTemplates struct {
+	// Title replaces the Alertmanager summary, Slack attachment title, PagerDuty
+	// summary and ticket summary
+	// +optional
+	Title string `json:"title,omitempty"`
+
+	// Body replaces the Alertmanager description, Slack attachment text, PagerDuty
+	// body detail and ticket description
+	// +optional
+	Body string `json:"body,omitempty"`
+}
+
+// TicketConfig defines how a ticketing channel tracks alerts. Each cluster and alert
+// type gets one ticket, which is commented on while the problem persists and closed
+// once it clears
+type TicketConfig struct {
+	// CredentialsSecret is the namespace/name of the secret holding the username and
+	// password keys used for basic authentication. Jira Cloud takes the account email
+	// and an API token
+	// +kubebuilder:validation:Required
+	CredentialsSecret string `json:"credentialsSecret"`
+
+	// Severities restricts the alerts that open or update tickets, e.g. [warning] to
+	// track warnings while critical alerts page through another channel. Empty
+	// accepts every severity
+	// +optional
+	Severities []TicketSeverity `json:"severities,omitempty"`
+
+	// Project is the key of the Jira project issues are created in
+	// +optional
+	Project string `json:"project,omitempty"`
+
+	// IssueType is the Jira issue type
+	// +kubebuilder:default="Task"
+	// +optional
+	IssueType string `json:"issueType,omitempty"`
+
+	// Table is the ServiceNow table tickets are created in
+	// +kubebuilder:default="incident"
+	// +optional
+	Table string `json:"table,omitempty"`
+
+	// Fields maps ticket fields to Go templates rendered with the alert, e.g.
+	// assignment_group: "DBA" or urgency: '{{ if eq .Severity "warning" }}3{{ else }}1{{ end }}'.
+	// The templates see the same fields as the channel templates. Values rendering to
+	// a JSON object or array, such as Jira's priority: '{"name": "High"}', are sent as JSON
+	// +optional
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// ResolveFields are set when a ticket is closed, rendered like fields, e.g.
+	// ServiceNow's close_code
+	// +optional
+	ResolveFields map[string]string `json:"resolveFields,omitempty"`
+
+	// ResolvedState is the ServiceNow state a ticket is closed with
+	// +kubebuilder:default="6"
+	// +optional
+	ResolvedState string `json:"resolvedState,omitempty"`
+
+	// CloseTransition is the name of the Jira transition that closes an issue
+	// +kubebuilder:default="Done"
+	// +optional
+	CloseTransition string `json:"closeTransition,omitempty"`
+
+	// LeaveOpen keeps tickets open when the problem clears, for teams that close
+	// them by hand
+	// +optional
+	LeaveOpen bool `json:"leaveOpen,omitempty"`
+}
+
+// TicketSeverity is an alert severity a ticketing channel accepts
+// +kubebuilder:validation:Enum=warning;critical;emergency
+type TicketSeverity string
+
+// OwnershipMetadata identifies the owner of a database and how to handle its problems
+type OwnershipMetadata struct {
+	// OwnerTeam is the team that owns the database
+	// +optional
+	OwnerTeam string `json:"ownerTeam,omitempty"`
+
+	// RunbookURL links to the runbook for the database's storage alerts
+	// +optional
+	RunbookURL string `json:"runbookURL,omitempty"`
+
+	// Escalation tells receivers whom to escalate to, e.g. a rotation or channel
+	// +optional
+	Escalation string `json:"escalation,omitempty"`
+
+	// SlackChannel is the channel Slack bot-token channels post the database's alerts
+	// to instead of their configured channel
+	// +optional
+	SlackChannel string `json:"slackChannel,omitempty"`
+}
+
+// HookFailurePolicy is what happens to a remediation when its preAction hook cannot
+// be reached
+// +kubebuilder:validation:Enum=Fail;Ignore
+type HookFailurePolicy string
+
+const (
+	// HookFailurePolicyFail fails the attempt, which is retried with backoff
+	HookFailurePolicyFail HookFailurePolicy = "Fail"
+	// HookFailurePolicyIgnore proceeds with the remediation
+	HookFailurePolicyIgnore HookFailurePolicy = "Ignore"
+)
+
+// HooksConfig defines the webhooks called around the expansions and WAL cleanups of a
+// policy. Each hook receives the StorageEvent, its policy and cluster as JSON
+type HooksConfig struct {
+	// PreAction is called before a StorageEvent starts. A non-2xx response vetoes the
+	// remediation, which fails without retrying
+	// +optional
+	PreAction *WebhookHook `json:"preAction,omitempty"`
+
+	// PostAction is called once a StorageEvent has completed or failed. Its response is
+	// only logged
+	// +optional
+	PostAction *WebhookHook `json:"postAction,omitempty"`
+}
+
+// WebhookHook is an HTTP endpoint called with a JSON POST
+type WebhookHook struct {
+	// URL of the webhook
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// SigningSecretRef references the key the request body is signed with. The
+	// HMAC-SHA256 signature is sent in the X-Storage-Manager-Signature header as
+	// sha256=<hex>
+	// +optional
+	SigningSecretRef *HookSecretReference `json:"signingSecretRef,omitempty"`
+
+	// TimeoutSeconds bounds the request
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	// +kubebuilder:default=10
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailurePolicy applies when the webhook cannot be reached or times out. Only
+	// preAction hooks use it
+	// +kubebuilder:default=Fail
+	// +optional
+	FailurePolicy HookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// HookSecretReference points to a signing key stored in a Secret
+type HookSecretReference struct {
+	// Name of the Secret in the StoragePolicy's namespace
+	Name string `json:"name"`
+
+	// Key in the Secret holding the signing key
+	// +kubebuilder:default=signing-key
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// AlertingConfig defines alerting settings
+type AlertingConfig struct {
+	// Channels is the list of alert channels. When empty, the ManagerConfig default
+	// channels are used
+	// +optional
+	Channels []AlertChannel `json:"channels,omitempty"`
+
+	// SuppressDuringRemediation suppresses alerts while remediation is active
+	// +kubebuilder:default=true
+	// +optional
+	SuppressDuringRemediation bool `json:"suppressDuringRemediation,omitempty"`
+
+	// EscalationMinutes is the time before re-alerting on unresolved issues
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=15
+	// +optional
+	EscalationMinutes int32 `json:"escalationMinutes,omitempty"`
+
+	// PartialSuccessAlertMinutes is how long the policy may continuously report
+	// PartialSuccess (some clusters failing to process) before a policy-level alert is sent.
+	// Set to 0 to disable the alert
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=60
+	// +optional
+	PartialSuccessAlertMinutes int32 `json:"partialSuccessAlertMinutes,omitempty"`
+
+	// QuietHours hold warning alerts during a daily window and send them as one digest
+	// once it ends. Critical and emergency alerts are sent immediately, and ticketing
+	// channels receive warnings as they happen
+	// +optional
+	QuietHours *QuietHoursConfig `json:"quietHours,omitempty"`
+
+	// Summary sends one periodic message listing the policy's unhealthy clusters
+	// +optional
+	Summary *AlertSummaryConfig `json:"summary,omitempty"`
+
+	// LogHints attach the key error lines of the primary's PostgreSQL log, such as
+	// write failures, archive command failures and checkpoint warnings, to emergency
+	// storage alerts. The log of the primary's postgres container is read through the API
+	// +optional
+	LogHints *LogHintsConfig `json:"logHints,omitempty"`
+
+	// PrometheusRule configures a PrometheusRule mirroring the policy's thresholds so
+	// Prometheus keeps alerting even when the operator is down
+	// +optional
+	PrometheusRule PrometheusRuleConfig `json:"prometheusRule,omitempty"`
+
+	// Labels are added to every Alertmanager alert of the policy, e.g. team, environment
+	// or service, for routing. They do not override the labels the manager sets itself
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.matches('^[a-zA-Z_][a-zA-Z0-9_]*$') && !k.startsWith('__'))",message="label names must match [a-zA-Z_][a-zA-Z0-9_]* and must not start with __"
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to every Alertmanager alert of the policy, e.g. a dashboard link
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// QuietHoursConfig defines a daily window during which warning alerts are held
+type QuietHoursConfig struct {
+	// Start of the window as HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End of the window as HH:MM. A window ending before it starts spans midnight
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+
+	// Timezone is the IANA time zone of start and end, e.g. Europe/Berlin
+	// +kubebuilder:default=UTC
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// Days restricts the window to the days it starts on. Empty means every day
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+}
+
+// LogHintsConfig defines how much of the PostgreSQL log is searched for error lines
+type LogHintsConfig struct {
+	// Lines is the number of latest log lines searched
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=10000
+	// +kubebuilder:default=200
+	// +optional
+	Lines int32 `json:"lines,omitempty"`
+}
+
+// AlertSummaryConfig defines a scheduled summary of the unhealthy clusters of a policy,
+// e.g. a daily table in Slack instead of one message per cluster and breach
+type AlertSummaryConfig struct {
+	// Schedule is the cron expression of the summary, in timezone
+	// +kubebuilder:default="0 9 * * *"
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Timezone is the IANA time zone of the schedule, e.g. Europe/Berlin
+	// +kubebuilder:default=UTC
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// SuppressWarnings leaves warning-level storage and backup alerts about individual
+	// clusters to the summary. Critical and emergency alerts are still sent immediately,
+	// and ticketing channels still receive warnings
+	// +optional
+	SuppressWarnings bool `json:"suppressWarnings,omitempty"`
+}
+
+// Weekday is a day of the week
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type Weekday string
+
+// PrometheusRuleConfig defines the PrometheusRule maintained for a policy. The rules
+// are evaluated against kubelet volume stats and CNPG instance metrics, not the
+// operator's own metrics, so they do not depend on the operator running.
+type PrometheusRuleConfig struct {
+	// Enabled creates and maintains a PrometheusRule in the policy's namespace.
+	// Requires the Prometheus Operator CRDs to be installed
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Labels are added to the PrometheusRule so it is selected by Prometheus' ruleSelector
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// For is how long a condition must hold before the alert fires
+	// +kubebuilder:validation:Pattern=`^([0-9]+(ms|s|m|h))+$`
+	// +kubebuilder:default="5m"
+	// +optional
+	For string `json:"for,omitempty"`
+}
+
+// BackupMonitoringConfig defines backup and WAL archiving monitoring settings
+type BackupMonitoringConfig struct {
+	// Enabled determines if backup monitoring is enabled
+	// +kubebuilder:default=true
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MaxBackupAgeHours is the maximum age of the last successful backup before alerting
+	// Set to 0 to disable backup age monitoring
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=24
+	// +optional
+	MaxBackupAgeHours int32 `json:"maxBackupAgeHours,omitempty"`
+
+	// RequireContinuousArchiving alerts if WAL archiving is not working
+	// +kubebuilder:default=true
+	// +optional
+	RequireContinuousArchiving bool `json:"requireContinuousArchiving,omitempty"`
+
+	// MaxRecoveryPointAgeHours is the maximum age of the first recovery point before alerting
+	// Set to 0 to disable recovery point age monitoring
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=168
+	// +optional
+	MaxRecoveryPointAgeHours int32 `json:"maxRecoveryPointAgeHours,omitempty"`
+
+	// AlertOnNoBackupConfigured alerts if a cluster has no backup configured
+	// +kubebuilder:default=true
+	// +optional
+	AlertOnNoBackupConfigured bool `json:"alertOnNoBackupConfigured,omitempty"`
+}
+
+// StoragePolicySpec defines the desired state of StoragePolicy
+type StoragePolicySpec struct {
+	// Selector is a label selector for matching CNPG clusters
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// IncludeClusters selects clusters by name in addition to the selector. When it is
+	// set without a selector, only the included clusters are managed
+	// +optional
+	IncludeClusters *ClusterInclusion `json:"includeClusters,omitempty"`
+
+	// ExcludeClusters is a list of clusters to exclude even if they match the selector
+	// +optional
+	ExcludeClusters []ClusterReference `json:"excludeClusters,omitempty"`
+
+	// Connections are ClusterConnections in the policy's namespace whose CNPG clusters are
+	// also matched by the selector. Clusters reached through a connection are monitored
+	// and alerted on only; expansion and WAL cleanup apply to local clusters
+	// +optional
+	Connections []string `json:"connections,omitempty"`
+
+	// Thresholds defines storage usage thresholds
+	// +optional
+	Thresholds ThresholdsConfig `json:"thresholds,omitempty"`
+
+	// WALThresholds evaluates the separate WAL volumes (spec.walStorage) on their own,
+	// leaving Thresholds to the data volumes. Unset thresholds take those of Thresholds
+	// +optional
+	WALThresholds *ThresholdsConfig `json:"walThresholds,omitempty"`
+
+	// MetricsSource selects where volume usage is collected from: kubelet volume stats
+	// with a df fallback in the pods, df in the pods only, or the node agent DaemonSet
+	// for clusters where pod exec is not permitted
+	// +kubebuilder:default=kubelet
+	// +optional
+	MetricsSource MetricsSource `json:"metricsSource,omitempty"`
+
+	// Expansion defines PVC expansion settings
+	// +optional
+	Expansion ExpansionConfig `json:"expansion,omitempty"`
+
+	// WALExpansion overrides the expansion settings for the separate WAL volumes, e.g.
+	// to expand them in smaller increments than the data volumes
+	// +optional
+	WALExpansion *VolumeExpansionConfig `json:"walExpansion,omitempty"`
+
+	// WALCleanup defines WAL file cleanup settings
+	// +optional
+	WALCleanup WALCleanupConfig `json:"walCleanup,omitempty"`
+
+	// AnomalyDetection defines runaway-growth detection
+	// +optional
+	AnomalyDetection AnomalyDetectionConfig `json:"anomalyDetection,omitempty"`
+
+	// Forecast maintains a StorageForecast for each selected cluster
+	// +optional
+	Forecast ForecastConfig `json:"forecast,omitempty"`
+
+	// UsageHistory maintains a StorageUsageHistory for each selected cluster
+	// +optional
+	UsageHistory UsageHistoryConfig `json:"usageHistory,omitempty"`
+
+	// Quota monitors the storage quota headroom of the namespaces of the selected
+	// clusters, and optionally raises their ResourceQuotas
+	// +optional
+	Quota QuotaManagementConfig `json:"quota,omitempty"`
+
+	// Fencing fences instances whose storage is full until space is available again
+	// +optional
+	Fencing FencingConfig `json:"fencing,omitempty"`
+
+	// WriteProbe probes whether the primary accepts writes
+	// +optional
+	WriteProbe WriteProbeConfig `json:"writeProbe,omitempty"`
+
+	// StorageClassMigration moves the volumes of the selected clusters to another
+	// StorageClass. Unset leaves their StorageClass alone
+	// +optional
+	StorageClassMigration *StorageClassMigrationConfig `json:"storageClassMigration,omitempty"`
+
+	// PerformanceTuning tunes the volumes of clusters with high latency instead of
+	// expanding them. Unset leaves volume attributes alone
+	// +optional
+	PerformanceTuning *PerformanceTuningConfig `json:"performanceTuning,omitempty"`
+
+	// VolumeAttributes moves the PVCs of the selected clusters to the
+	// VolumeAttributesClass of the threshold they reached. Unset leaves their class alone
+	// +optional
+	VolumeAttributes *VolumeAttributesConfig `json:"volumeAttributes,omitempty"`
+
+	// BackupMonitoring defines backup and WAL archiving monitoring settings.
+	// Deprecated: use a BackupPolicy instead. Clusters matched by a BackupPolicy
+	// are skipped by StoragePolicy backup monitoring to avoid duplicate alerts
+	// +optional
+	BackupMonitoring BackupMonitoringConfig `json:"backupMonitoring,omitempty"`
+
+	// Effectiveness measures whether expansions and WAL cleanups relieved storage pressure
+	// +optional
+	Effectiveness EffectivenessConfig `json:"effectiveness,omitempty"`
+
+	// SLO tracks the time each cluster spends above the critical threshold or with failed
+	// backups against a storage availability objective
+	// +optional
+	SLO StorageSLOConfig `json:"slo,omitempty"`
+
+	// Poolers monitors the PVCs mounted by the pods of the CNPG Poolers of each cluster
+	// +optional
+	Poolers PoolerMonitoringConfig `json:"poolers,omitempty"`
+
+	// CircuitBreaker defines circuit breaker settings
+	// +optional
+	CircuitBreaker CircuitBreakerConfig `json:"circuitBreaker,omitempty"`
+
+	// Alerting defines alerting settings
+	// +optional
+	Alerting AlertingConfig `json:"alerting,omitempty"`
+
+	// Hooks call webhooks before and after expansions and WAL cleanups, e.g. to open and
+	// close change requests
+	// +optional
+	Hooks HooksConfig `json:"hooks,omitempty"`
+
+	// Metadata tells alert receivers who owns the selected databases and what to do.
+	// It is included in every alert and StorageEvent of the policy. Clusters override
+	// it field by field with the storage.cnpg.supporttools.io/owner-team, runbook-url
+	// and escalation annotations
+	// +optional
+	Metadata OwnershipMetadata `json:"metadata,omitempty"`
+
+	// DryRun enables dry-run mode where no actions are taken. The ManagerConfig dryRun
+	// enables it for every policy
+	// +kubebuilder:default=false
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// DryRunUntil runs the policy in dry-run mode until the given time, after which it
+	// enforces automatically and a notification is sent. When set, it takes precedence
+	// over dryRun, so a trial period cannot be forgotten
+	// +optional
+	DryRunUntil *metav1.Time `json:"dryRunUntil,omitempty"`
+
+	// Paused stops remediation for every cluster of the policy, e.g. during maintenance.
+	// Metrics are still collected and alerts still sent
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// PauseUntil pauses the policy until the given time, after which remediation resumes
+	// automatically. When set, it takes precedence over paused
+	// +optional
+	PauseUntil *metav1.Time `json:"pauseUntil,omitempty"`
+}
+
+// ManagedCluster represents a cluster managed by this policy
+type ManagedCluster struct {
+	// Name of the CNPG cluster
+	Name string `json:"name"`
+
+	// Namespace of the CNPG cluster
+	Namespace string `json:"namespace"`
+
+	// Connection is the ClusterConnection the cluster was reached through. Empty for
+	// clusters in the manager's own Kubernetes cluster
+	// +optional
+	Connection string `json:"connection,omitempty"`
+
+	// LastChecked is when the cluster was last evaluated
+	LastChecked metav1.Time `json:"lastChecked"`
+
+	// UsagePercent is the current storage usage percentage. It covers the data volumes
+	// only when the policy sets walThresholds
+	UsagePercent int32 `json:"usagePercent"`
+
+	// UsedBytes is the storage used by the volumes UsagePercent covers
+	// +optional
+	UsedBytes int64 `json:"usedBytes,omitempty"`
+
+	// CapacityBytes is the capacity of the volumes UsagePercent covers
+	// +optional
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+
+	// HealthScore is a 0-100 storage health score, 100 being healthy, weighted from the
+	// usage, growth rate, backup age, WAL archiving and circuit breaker. Unset while the
+	// capacity is unknown
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	HealthScore *int32 `json:"healthScore,omitempty"`
+
+	// Status is the current status of the cluster
+	Status string `json:"status"`
+
+	// BackupStatus contains backup-related status information
+	// +optional
+	BackupStatus *ClusterBackupStatus `json:"backupStatus,omitempty"`
+
+	// RecoveryWindow is the current point-in-time recovery window of the cluster
+	// +optional
+	RecoveryWindow *RecoveryWindowStatus `json:"recoveryWindow,omitempty"`
+
+	// Conditions are the typed conditions of the cluster: StorageHealthy,
+	// ExpansionInProgress, BackupHealthy, CircuitBreakerOpen and Writable
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// PlannedActions are the remediations dry-run mode held back, with exactly what
+	// they would have done
+	// +optional
+	PlannedActions []PlannedAction `json:"plannedActions,omitempty"`
+
+	// ExpansionHistory records the completed expansions of the cluster
+	// +optional
+	ExpansionHistory *ExpansionHistory `json:"expansionHistory,omitempty"`
+
+	// Growth holds the usage samples and growth rates of anomaly detection
+	// +optional
+	Growth *GrowthStatus `json:"growth,omitempty"`
+
+	// Tablespaces reports the usage of each declarative tablespace. UsagePercent covers
+	// the data and WAL volumes only
+	// +optional
+	Tablespaces []TablespaceStatus `json:"tablespaces,omitempty"`
+
+	// WALVolume reports the usage of the WAL volumes when the policy sets walThresholds
+	// +optional
+	WALVolume *WALVolumeStatus `json:"walVolume,omitempty"`
+
+	// PoolerVolumes reports the usage of the PVCs mounted by the cluster's Pooler pods.
+	// Only set with poolers.enabled
+	// +optional
+	PoolerVolumes []PoolerVolumeStatus `json:"poolerVolumes,omitempty"`
+
+	// Zones reports the usage of the data and WAL volumes per topology zone of the
+	// instances' nodes, so growth skewed towards one zone is visible
+	// +optional
+	Zones []ZoneUsageStatus `json:"zones,omitempty"`
+
+	// FencedInstances are the fenced instances of the cluster, against which no
+	// commands are run
+	// +optional
+	FencedInstances []string `json:"fencedInstances,omitempty"`
+
+	// ExpansionSkips are the PVCs an expansion of the data volumes would skip while
+	// the cluster is above the expansion threshold
+	// +optional
+	ExpansionSkips []ExpansionSkipStatus `json:"expansionSkips,omitempty"`
+
+	// PendingResizes are the PVCs whose filesystem resize is pending longer than
+	// expansion.fileSystemResize.timeoutMinutes
+	// +optional
+	PendingResizes []PendingResizeStatus `json:"pendingResizes,omitempty"`
+
+	// Decision explains the last evaluation: the usage, the thresholds it was compared
+	// against, the circuit breaker and cooldown state, and the action taken or what
+	// blocked it
+	// +optional
+	Decision *EvaluationDecision `json:"decision,omitempty"`
+
+	// SLO is the time the cluster spent violating the storage objective in the current
+	// window. Only set with slo.enabled
+	// +optional
+	SLO *StorageSLOStatus `json:"slo,omitempty"`
+}
+
+// StorageSLOStatus is the error budget spent by a cluster in the current SLO window
+type StorageSLOStatus struct {
+	// WindowStart is when the current window started
+	WindowStart metav1.Time `json:"windowStart"`
+
+	// TrackedSeconds is how long the cluster's storage health was known in the window
+	TrackedSeconds int64 `json:"trackedSeconds"`
+
+	// ViolationSeconds is how long the cluster violated the objective in the window
+	ViolationSeconds int64 `json:"violationSeconds"`
+
+	// ErrorBudgetRemainingPercent is the share of the window's error budget left,
+	// negative once it is exhausted
+	ErrorBudgetRemainingPercent int32 `json:"errorBudgetRemainingPercent"`
+
+	// Violations are why the cluster currently violates the objective
+	// +optional
+	Violations []string `json:"violations,omitempty"`
+}
+
+// EvaluationDecision is the decision tree of a cluster evaluation
+type EvaluationDecision struct {
+	// UsagePercent is the storage usage the thresholds were compared against, with one
+	// decimal
+	// +optional
+	UsagePercent string `json:"usagePercent,omitempty"`
+
+	// Level is the highest threshold level the usage reached. Empty when the thresholds
+	// were not evaluated
+	// +optional
+	Level string `json:"level,omitempty"`
+
+	// Thresholds are the effective thresholds, with the defaults of unset ones applied
+	// +optional
+	Thresholds ThresholdsConfig `json:"thresholds,omitempty"`
+
+	// CircuitBreakerOpen is whether the circuit breaker blocked all remediation
+	// +optional
+	CircuitBreakerOpen bool `json:"circuitBreakerOpen,omitempty"`
+
+	// ActiveRemediation is whether an expansion or WAL cleanup was already in progress
+	// +optional
+	ActiveRemediation bool `json:"activeRemediation,omitempty"`
+
+	// Actions are the actions the evaluation recommended
+	// +optional
+	Actions []DecisionAction `json:"actions,omitempty"`
+
+	// Action is the action taken: none, alert, expand or wal-cleanup
+	Action string `json:"action"`
+
+	// BlockReasons are why no or a lesser action was taken, such as a cooldown, the
+	// circuit breaker, a paused policy, dry-run mode or a pending approval
+	// +optional
+	BlockReasons []string `json:"blockReasons,omitempty"`
+
+	// Result is the resulting cluster status
+	// +optional
+	Result string `json:"result,omitempty"`
+}
+
+// DecisionAction is an action recommended by an evaluation
+type DecisionAction struct {
+	// Action is the recommended action: alert, expand or wal-cleanup
+	Action string `json:"action"`
+
+	// Reason the action was recommended
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Blocked is why the evaluation held the action back, such as a cooldown or
+	// suppression during remediation
+	// +optional
+	Blocked string `json:"blocked,omitempty"`
+}
+
+// PendingResizeStatus is a PVC stuck waiting for its filesystem to be resized
+type PendingResizeStatus struct {
+	// PVC is the name of the PVC
+	PVC string `json:"pvc"`
+
+	// Instance is the instance the PVC belongs to
+	// +optional
+	Instance string `json:"instance,omitempty"`
+
+	// PendingSince is when the FileSystemResizePending condition was set
+	PendingSince metav1.Time `json:"pendingSince"`
+
+	// RestartedAt is when the instance's pod was last deleted to complete the resize
+	// +optional
+	RestartedAt *metav1.Time `json:"restartedAt,omitempty"`
+}
+
+// ExpansionSkipStatus is a PVC an expansion skips
+type ExpansionSkipStatus struct {
+	// PVC is the name of the PVC
+	PVC string `json:"pvc"`
+
+	// Reason classifies the skip: no_storage_class, non_expandable_class, unbound,
+	// access_mode or max_size
+	Reason string `json:"reason"`
+
+	// Message describes why the PVC is skipped
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// WALVolumeStatus is the usage of the WAL volume on the instance where it is fullest
+type WALVolumeStatus struct {
+	// PVC is the fullest WAL PVC
+	// +optional
+	PVC string `json:"pvc,omitempty"`
+
+	// UsagePercent is the storage usage percentage of the fullest WAL PVC
+	UsagePercent int32 `json:"usagePercent"`
+
+	// Status is the current status of the WAL volume
+	Status string `json:"status"`
+}
+
+// TablespaceStatus is the usage of a tablespace on the instance where it is fullest
+type TablespaceStatus struct {
+	// Name of the tablespace
+	Name string `json:"name"`
+
+	// PVC is the fullest PVC of the tablespace
+	// +optional
+	PVC string `json:"pvc,omitempty"`
+
+	// UsagePercent is the storage usage percentage of the fullest PVC
+	UsagePercent int32 `json:"usagePercent"`
+
+	// Status is the current status of the tablespace
+	Status string `json:"status"`
+}
+
+// PoolerVolumeStatus is the usage of a PVC mounted by the pods of a CNPG Pooler
+type PoolerVolumeStatus struct {
+	// Pooler is the name of the Pooler
+	Pooler string `json:"pooler"`
+
+	// PVC is the name of the PersistentVolumeClaim
+	PVC string `json:"pvc"`
+
+	// UsagePercent is the storage usage percentage of the PVC
+	UsagePercent int32 `json:"usagePercent"`
+
+	// UsedBytes is the space used on the PVC
+	// +optional
+	UsedBytes int64 `json:"usedBytes,omitempty"`
+
+	// CapacityBytes is the capacity of the PVC
+	// +optional
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+
+	// Status is Healthy, or Alert-<level> while the PVC is above a threshold
+	Status string `json:"status"`
+}
+
+// ZoneUsageStatus is the usage of the data and WAL volumes of a cluster's instances in a zone
+type ZoneUsageStatus struct {
+	// Zone is the topology.kubernetes.io/zone label of the instances' nodes
+	Zone string `json:"zone"`
+
+	// Region is the topology.kubernetes.io/region label of the instances' nodes
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Instances are the instance pods running in the zone
+	// +optional
+	Instances []string `json:"instances,omitempty"`
+
+	// UsagePercent is the storage usage percentage of the zone's volumes
+	UsagePercent int32 `json:"usagePercent"`
+
+	// UsedBytes is the space used on the zone's volumes
+	// +optional
+	UsedBytes int64 `json:"usedBytes,omitempty"`
+
+	// CapacityBytes is the capacity of the zone's volumes
+	// +optional
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+}
+
+// GrowthStatus holds the usage samples anomaly detection derives growth rates from
+type GrowthStatus struct {
+	// Samples are the used bytes of the cluster's largest instance over the baseline
+	// and recent windows, oldest first
+	// +optional
+	Samples []UsageSample `json:"samples,omitempty"`
+
+	// RecentBytesPerHour is the growth rate over the recent window
+	// +optional
+	RecentBytesPerHour int64 `json:"recentBytesPerHour,omitempty"`
+
+	// BaselineBytesPerHour is the growth rate over the baseline window
+	// +optional
+	BaselineBytesPerHour int64 `json:"baselineBytesPerHour,omitempty"`
+
+	// AnomalyDetectedAt is when the current growth anomaly started. Unset while growth
+	// is normal
+	// +optional
+	AnomalyDetectedAt *metav1.Time `json:"anomalyDetectedAt,omitempty"`
+}
+
+// UsageSample is the storage used by a cluster at a point in time
+type UsageSample struct {
+	// Time is when the sample was taken
+	Time metav1.Time `json:"time"`
+
+	// UsedBytes is the storage used by the cluster's largest instance
+	UsedBytes int64 `json:"usedBytes"`
+}
+
+// ExpansionHistory records the completed expansions of a cluster and its cumulative growth
+type ExpansionHistory struct {
+	// Records are the most recent expansions, oldest first
+	// +optional
+	Records []ExpansionRecord `json:"records,omitempty"`
+
+	// TotalExpansions counts every recorded expansion, including those trimmed from records
+	// +optional
+	TotalExpansions int32 `json:"totalExpansions,omitempty"`
+
+	// CumulativeGrowthBytes is the storage added by every recorded expansion
+	// +optional
+	CumulativeGrowthBytes int64 `json:"cumulativeGrowthBytes,omitempty"`
+
+	// ExpansionsLast30Days is the number of expansions completed in the last 30 days
+	// +optional
+	ExpansionsLast30Days int32 `json:"expansionsLast30Days,omitempty"`
+
+	// FrequencyAlertedAt is when the last too-frequent expansion alert was sent
+	// +optional
+	FrequencyAlertedAt *metav1.Time `json:"frequencyAlertedAt,omitempty"`
+}
+
+// ExpansionRecord is a completed expansion of a cluster
+type ExpansionRecord struct {
+	// Event is the name of the StorageEvent that performed the expansion
+	Event string `json:"event"`
+
+	// Time is when the expansion completed
+	Time metav1.Time `json:"time"`
+
+	// OriginalSize is the total size of the expanded PVCs before the expansion
+	OriginalSize resource.Quantity `json:"originalSize"`
+
+	// NewSize is the total size of the expanded PVCs after the expansion
+	NewSize resource.Quantity `json:"newSize"`
+
+	// TriggerUsagePercent is the storage usage that triggered the expansion
+	// +optional
+	TriggerUsagePercent int32 `json:"triggerUsagePercent,omitempty"`
+}
+
+// PlannedAction is a remediation held back by dry-run mode
+type PlannedAction struct {
+	// Type of remediation
+	Type EventType `json:"type"`
+
+	// Reason the remediation was recommended
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// ExpansionTarget selects the PVCs an Expansion would have resized
+	ExpansionTarget `json:",inline"`
+
+	// PlannedAt is when the plan was computed
+	PlannedAt metav1.Time `json:"plannedAt"`
+
+	// PVCs are the expansions an Expansion would have made
+	// +optional
+	PVCs []PlannedPVCExpansion `json:"pvcs,omitempty"`
+
+	// WALCleanup lists what a WALCleanup would have removed on each instance
+	// +optional
+	WALCleanup []PlannedWALCleanup `json:"walCleanup,omitempty"`
+
+	// Error is why the plan could not be computed
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PlannedPVCExpansion is the planned resize of a PVC
+type PlannedPVCExpansion struct {
+	// Name of the PVC
+	Name string `json:"name"`
+
+	// CurrentSize is the requested size of the PVC
+	CurrentSize resource.Quantity `json:"currentSize"`
+
+	// TargetSize is the size the PVC would have been expanded to
+	// +optional
+	TargetSize *resource.Quantity `json:"targetSize,omitempty"`
+
+	// SkipReason is why the PVC would not have been expanded
+	// +optional
+	SkipReason string `json:"skipReason,omitempty"`
+}
+
+// PlannedWALCleanup is what WAL cleanup would have removed on an instance
+type PlannedWALCleanup struct {
+	// PodName is the instance the WAL would have been removed from
+	PodName string `json:"podName"`
+
+	// Files is the number of WAL segments that would have been removed
+	Files int32 `json:"files"`
+
+	// Bytes is the space that would have been freed
+	Bytes int64 `json:"bytes"`
+
+	// Error is why the instance could not be planned
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// Managed cluster condition types
+const (
+	// ManagedClusterConditionStorageHealthy is True while storage usage is below every threshold
+	ManagedClusterConditionStorageHealthy = "StorageHealthy"
+
+	// ManagedClusterConditionExpansionInProgress is True while a PVC expansion is pending or running
+	ManagedClusterConditionExpansionInProgress = "ExpansionInProgress"
+
+	// ManagedClusterConditionBackupHealthy is True while backups meet the policy's requirements.
+	// It is only set when backup monitoring covers the cluster
+	ManagedClusterConditionBackupHealthy = "BackupHealthy"
+
+	// ManagedClusterConditionCircuitBreakerOpen is True while remediation is blocked after
+	// repeated failures
+	ManagedClusterConditionCircuitBreakerOpen = "CircuitBreakerOpen"
+
+	// ManagedClusterConditionWritable is True while the primary commits the write probe.
+	// It is only set when the policy enables writeProbe
+	ManagedClusterConditionWritable = "Writable"
+
+	// ManagedClusterConditionMaxSizeReached is True while data PVCs are at expansion.maxSize
+	// and usage is above the expansion threshold. It is set once a PVC reaches maxSize
+	ManagedClusterConditionMaxSizeReached = "MaxSizeReached"
+
+	// ManagedClusterConditionQuotaHeadroom is True while the ResourceQuotas and LimitRanges
+	// of the namespace leave room for the next expansion. It is only set when the policy
+	// enables quota
+	ManagedClusterConditionQuotaHeadroom = "QuotaHeadroom"
+)
+
+// RecoveryWindowStatus is the span of time a cluster can currently be recovered to,
+// from the ObjectStore serverRecoveryWindow or the cluster status
+type RecoveryWindowStatus struct {
+	// FirstRecoverabilityPoint is the oldest point in time recovery is possible
+	// +optional
+	FirstRecoverabilityPoint *metav1.Time `json:"firstRecoverabilityPoint,omitempty"`
+
+	// LastBackupTime is the timestamp of the last successful backup
+	// +optional
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// WindowHours is how far back point-in-time recovery currently reaches
+	// +optional
+	WindowHours int32 `json:"windowHours,omitempty"`
+}
+
+// ClusterBackupStatus contains backup and WAL archiving status for a cluster
+type ClusterBackupStatus struct {
+	// LastBackupTime is the timestamp of the last successful backup
+	// +optional
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// LastBackupAgeHours is how many hours since the last backup
+	// +optional
+	LastBackupAgeHours int32 `json:"lastBackupAgeHours,omitempty"`
+
+	// FirstRecoverabilityPoint is the timestamp of the oldest recoverable point
+	// +optional
+	FirstRecoverabilityPoint *metav1.Time `json:"firstRecoverabilityPoint,omitempty"`
+
+	// ContinuousArchivingWorking indicates if WAL archiving is functioning
+	// +optional
+	ContinuousArchivingWorking bool `json:"continuousArchivingWorking,omitempty"`
+
+	// BackupConfigured indicates if backups are configured for the cluster
+	// +optional
+	BackupConfigured bool `json:"backupConfigured,omitempty"`
+
+	// BackupStatus is the overall backup health status
+	// +optional
+	BackupHealthStatus string `json:"backupHealthStatus,omitempty"`
 }
 
 // StoragePolicyStatus defines the observed state of StoragePolicy
@@ -337,6 +1773,22 @@ type StoragePolicyStatus struct {
 	// +optional
 	ManagedClusters []ManagedCluster `json:"managedClusters,omitempty"`
 
+	// ManagedClusterCount is the number of clusters managed by this policy
+	// +optional
+	ManagedClusterCount int32 `json:"managedClusterCount,omitempty"`
+
+	// WorstUsagePercent is the highest storage usage percentage of the managed clusters
+	// +optional
+	WorstUsagePercent int32 `json:"worstUsagePercent,omitempty"`
+
+	// WorstCluster is the namespace/name of the cluster with the highest storage usage
+	// +optional
+	WorstCluster string `json:"worstCluster,omitempty"`
+
+	// Summary counts the managed clusters by health state and lists the most concerning ones
+	// +optional
+	Summary *PolicySummary `json:"summary,omitempty"`
+
 	// LastEvaluated is the timestamp of the last policy evaluation
 	// +optional
 	LastEvaluated *metav1.Time `json:"lastEvaluated,omitempty"`
@@ -344,6 +1796,129 @@ type StoragePolicyStatus struct {
 	// ObservedGeneration is the generation observed by the controller
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// PartialSuccessSince is when the policy started continuously reporting PartialSuccess.
+	// It is cleared once every matched cluster is processed successfully
+	// +optional
+	PartialSuccessSince *metav1.Time `json:"partialSuccessSince,omitempty"`
+
+	// PartialSuccessAlertedAt is when the last PartialSuccess alert was sent
+	// +optional
+	PartialSuccessAlertedAt *metav1.Time `json:"partialSuccessAlertedAt,omitempty"`
+
+	// DryRunExpiredAt is when the controller observed the end of the dryRunUntil trial
+	// period and notified that the policy is now enforcing
+	// +optional
+	DryRunExpiredAt *metav1.Time `json:"dryRunExpiredAt,omitempty"`
+
+	// LastAlertSummary is when the scheduled alert summary was last due
+	// +optional
+	LastAlertSummary *metav1.Time `json:"lastAlertSummary,omitempty"`
+
+	// AlertChannels reports the delivery health of each configured alert channel
+	// +optional
+	AlertChannels []AlertChannelStatus `json:"alertChannels,omitempty"`
+}
+
+// PolicySummaryTopN is the number of clusters listed in each PolicySummary ranking
+const PolicySummaryTopN = 5
+
+// PolicySummary counts the managed clusters of a policy by health state and lists the
+// clusters with the highest usage and the oldest backups
+type PolicySummary struct {
+	// Healthy is the number of clusters below every threshold
+	// +optional
+	Healthy int32 `json:"healthy,omitempty"`
+
+	// Warning is the number of clusters at the warning threshold
+	// +optional
+	Warning int32 `json:"warning,omitempty"`
+
+	// Critical is the number of clusters at the critical threshold
+	// +optional
+	Critical int32 `json:"critical,omitempty"`
+
+	// Emergency is the number of clusters at the emergency threshold
+	// +optional
+	Emergency int32 `json:"emergency,omitempty"`
+
+	// Unknown is the number of clusters whose usage could not be evaluated, e.g. paused,
+	// failing or without metrics
+	// +optional
+	Unknown int32 `json:"unknown,omitempty"`
+
+	// BackupUnhealthy is the number of monitored clusters whose backups do not meet the
+	// policy's requirements
+	// +optional
+	BackupUnhealthy int32 `json:"backupUnhealthy,omitempty"`
+
+	// TopUsage lists the clusters with the highest usage percentage, highest first
+	// +optional
+	TopUsage []ClusterSummaryEntry `json:"topUsage,omitempty"`
+
+	// OldestBackups lists the monitored clusters with the oldest last backup, oldest
+	// first. Clusters without a successful backup come before all others
+	// +optional
+	OldestBackups []ClusterSummaryEntry `json:"oldestBackups,omitempty"`
+}
+
+// ClusterSummaryEntry identifies a cluster in a PolicySummary ranking
+type ClusterSummaryEntry struct {
+	// Name of the CNPG cluster
+	Name string `json:"name"`
+
+	// Namespace of the CNPG cluster
+	Namespace string `json:"namespace"`
+
+	// Connection is the ClusterConnection the cluster was reached through
+	// +optional
+	Connection string `json:"connection,omitempty"`
+
+	// UsagePercent is the storage usage percentage of the cluster
+	// +optional
+	UsagePercent int32 `json:"usagePercent,omitempty"`
+
+	// LastBackupAgeHours is the age of the last successful backup in hours
+	// +optional
+	LastBackupAgeHours *int32 `json:"lastBackupAgeHours,omitempty"`
+
+	// BackupHealthStatus is the backup health of the cluster
+	// +optional
+	BackupHealthStatus string `json:"backupHealthStatus,omitempty"`
+}
+
+// AlertChannelStatus reports the delivery health of an alert channel
+type AlertChannelStatus struct {
+	// Type of alert channel
+	Type AlertChannelType `json:"type"`
+
+	// Target identifies the channel: the Alertmanager endpoint, or the Slack or PagerDuty secret
+	// +optional
+	Target string `json:"target,omitempty"`
+
+	// SuccessCount is the number of alerts delivered through the channel
+	// +optional
+	SuccessCount int64 `json:"successCount,omitempty"`
+
+	// FailureCount is the number of alerts the channel failed to deliver
+	// +optional
+	FailureCount int64 `json:"failureCount,omitempty"`
+
+	// ConsecutiveFailures is the number of failed deliveries since the last success
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// LastSuccess is when an alert was last delivered through the channel
+	// +optional
+	LastSuccess *metav1.Time `json:"lastSuccess,omitempty"`
+
+	// LastFailure is when the channel last failed to deliver an alert
+	// +optional
+	LastFailure *metav1.Time `json:"lastFailure,omitempty"`
+
+	// LastError is the error of the last failed delivery
+	// +optional
+	LastError string `json:"lastError,omitempty"`
 }
 
 // StoragePolicy condition types
@@ -352,16 +1927,30 @@ const (
 	StoragePolicyConditionActive = "Active"
 	// StoragePolicyConditionConflicting indicates the policy conflicts with another policy
 	StoragePolicyConditionConflicting = "Conflicting"
+	// StoragePolicyConditionPrometheusRuleSynced indicates the policy's PrometheusRule is up to date
+	StoragePolicyConditionPrometheusRuleSynced = "PrometheusRuleSynced"
+	// StoragePolicyConditionAlertingDegraded indicates the last delivery through at least
+	// one alert channel failed
+	StoragePolicyConditionAlertingDegraded = "AlertingDegraded"
+	// StoragePolicyConditionPaused indicates remediation is paused for every cluster of the policy
+	StoragePolicyConditionPaused = "Paused"
+	// StoragePolicyConditionMissingPermission indicates the manager lacks RBAC permissions
+	// an enabled feature needs; the message lists them, e.g. "create pods/exec in db"
+	StoragePolicyConditionMissingPermission = "MissingPermission"
 )
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=sp
-// +kubebuilder:printcolumn:name="Managed",type="integer",JSONPath=".status.managedClusters"
-// +kubebuilder:printcolumn:name="Warning",type="integer",JSONPath=".spec.thresholds.warning"
-// +kubebuilder:printcolumn:name="Critical",type="integer",JSONPath=".spec.thresholds.critical"
-// +kubebuilder:printcolumn:name="Expansion",type="integer",JSONPath=".spec.thresholds.expansion"
+// +kubebuilder:printcolumn:name="Clusters",type="integer",JSONPath=".status.managedClusterCount"
+// +kubebuilder:printcolumn:name="Worst Usage",type="integer",JSONPath=".status.worstUsagePercent"
+// +kubebuilder:printcolumn:name="Worst Cluster",type="string",JSONPath=".status.worstCluster",priority=1
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Warning",type="integer",JSONPath=".spec.thresholds.warning",priority=1
+// +kubebuilder:printcolumn:name="Critical",type="integer",JSONPath=".spec.thresholds.critical",priority=1
+// +kubebuilder:printcolumn:name="Expansion",type="integer",JSONPath=".spec.thresholds.expansion",priority=1
 // +kubebuilder:printcolumn:name="DryRun",type="boolean",JSONPath=".spec.dryRun"
+// +kubebuilder:printcolumn:name="DryRunUntil",type="date",JSONPath=".spec.dryRunUntil",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // StoragePolicy is the Schema for the storagepolicies API
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StorageUsageHistorySpec defines the cluster a StorageUsageHistory covers
+type StorageUsageHistorySpec struct {
+	// ClusterName is the CNPG cluster in the StorageUsageHistory's namespace
+	ClusterName string `json:"clusterName"`
+}
+
+// StorageUsageHistoryStatus holds one usage sample per hour of a cluster over the
+// last 30 days. Entries are positional: the last one is the hour starting at newest
+// and each earlier entry is one hour older. Hours without a sample hold -1
+type StorageUsageHistoryStatus struct {
+	// Policy is the StoragePolicy, as namespace/name, that records the history
+	// +optional
+	Policy string `json:"policy,omitempty"`
+
+	// Newest is the start of the hour of the last entry
+	// +optional
+	Newest *metav1.Time `json:"newest,omitempty"`
+
+	// UsedBytes is the storage used by the cluster's largest instance at the first
+	// sample of each hour, oldest first
+	// +kubebuilder:validation:MaxItems=720
+	// +optional
+	UsedBytes []int64 `json:"usedBytes,omitempty"`
+
+	// DataUsedBytes is the storage used on the cluster's fullest data volume at the
+	// first sample of each hour, oldest first
+	// +kubebuilder:validation:MaxItems=720
+	// +optional
+	DataUsedBytes []int64 `json:"dataUsedBytes,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=suh
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterName"
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=".status.policy"
+// +kubebuilder:printcolumn:name="Newest",type="date",JSONPath=".status.newest"
+
+// StorageUsageHistory is the Schema for the storageusagehistories API. StoragePolicies
+// with usageHistory.enabled maintain one per cluster, so growth rates and forecasts
+// are computed from the last 30 days of usage after an operator restart or a policy
+// change, without an external time series database
+type StorageUsageHistory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StorageUsageHistorySpec   `json:"spec,omitempty"`
+	Status StorageUsageHistoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StorageUsageHistoryList contains a list of StorageUsageHistory
+type StorageUsageHistoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StorageUsageHistory `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StorageUsageHistory{}, &StorageUsageHistoryList{})
+}
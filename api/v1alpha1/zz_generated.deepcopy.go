@@ -43,6 +43,16 @@ func (in *AffectedPVC) DeepCopy() *AffectedPVC {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AlertChannel) DeepCopyInto(out *AlertChannel) {
 	*out = *in
+	if in.Ticket != nil {
+		in, out := &in.Ticket, &out.Ticket
+		*out = new(TicketConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = new(AlertTemplates)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertChannel.
@@ -56,198 +66,199 @@ func (in *AlertChannel) DeepCopy() *AlertChannel {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AlertingConfig) DeepCopyInto(out *AlertingConfig) {
+func (in *AlertChannelStatus) DeepCopyInto(out *AlertChannelStatus) {
 	*out = *in
-	if in.Channels != nil {
-		in, out := &in.Channels, &out.Channels
-		*out = make([]AlertChannel, len(*in))
-		copy(*out, *in)
+	if in.LastSuccess != nil {
+		in, out := &in.LastSuccess, &out.LastSuccess
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertingConfig.
-func (in *AlertingConfig) DeepCopy() *AlertingConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertChannelStatus.
+func (in *AlertChannelStatus) DeepCopy() *AlertChannelStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(AlertingConfig)
+	out := new(AlertChannelStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BackupMonitoringConfig) DeepCopyInto(out *BackupMonitoringConfig) {
+func (in *AlertSummaryConfig) DeepCopyInto(out *AlertSummaryConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupMonitoringConfig.
-func (in *BackupMonitoringConfig) DeepCopy() *BackupMonitoringConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertSummaryConfig.
+func (in *AlertSummaryConfig) DeepCopy() *AlertSummaryConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(BackupMonitoringConfig)
+	out := new(AlertSummaryConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CircuitBreakerConfig) DeepCopyInto(out *CircuitBreakerConfig) {
+func (in *AlertTemplates) DeepCopyInto(out *AlertTemplates) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakerConfig.
-func (in *CircuitBreakerConfig) DeepCopy() *CircuitBreakerConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertTemplates.
+func (in *AlertTemplates) DeepCopy() *AlertTemplates {
 	if in == nil {
 		return nil
 	}
-	out := new(CircuitBreakerConfig)
+	out := new(AlertTemplates)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterBackupStatus) DeepCopyInto(out *ClusterBackupStatus) {
+func (in *AlertingConfig) DeepCopyInto(out *AlertingConfig) {
 	*out = *in
-	if in.LastBackupTime != nil {
-		in, out := &in.LastBackupTime, &out.LastBackupTime
-		*out = (*in).DeepCopy()
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]AlertChannel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.FirstRecoverabilityPoint != nil {
-		in, out := &in.FirstRecoverabilityPoint, &out.FirstRecoverabilityPoint
-		*out = (*in).DeepCopy()
+	if in.QuietHours != nil {
+		in, out := &in.QuietHours, &out.QuietHours
+		*out = new(QuietHoursConfig)
+		(*in).DeepCopyInto(*out)
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterBackupStatus.
-func (in *ClusterBackupStatus) DeepCopy() *ClusterBackupStatus {
-	if in == nil {
-		return nil
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(AlertSummaryConfig)
+		**out = **in
+	}
+	if in.LogHints != nil {
+		in, out := &in.LogHints, &out.LogHints
+		*out = new(LogHintsConfig)
+		**out = **in
+	}
+	in.PrometheusRule.DeepCopyInto(&out.PrometheusRule)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	out := new(ClusterBackupStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterReference) DeepCopyInto(out *ClusterReference) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReference.
-func (in *ClusterReference) DeepCopy() *ClusterReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertingConfig.
+func (in *AlertingConfig) DeepCopy() *AlertingConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterReference)
+	out := new(AlertingConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExpansionConfig) DeepCopyInto(out *ExpansionConfig) {
+func (in *AnomalyDetectionConfig) DeepCopyInto(out *AnomalyDetectionConfig) {
 	*out = *in
-	if in.MaxSize != nil {
-		in, out := &in.MaxSize, &out.MaxSize
-		x := (*in).DeepCopy()
-		*out = &x
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionConfig.
-func (in *ExpansionConfig) DeepCopy() *ExpansionConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnomalyDetectionConfig.
+func (in *AnomalyDetectionConfig) DeepCopy() *AnomalyDetectionConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ExpansionConfig)
+	out := new(AnomalyDetectionConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExpansionDetails) DeepCopyInto(out *ExpansionDetails) {
+func (in *ArchiveLagConfig) DeepCopyInto(out *ArchiveLagConfig) {
 	*out = *in
-	out.OriginalSize = in.OriginalSize.DeepCopy()
-	out.RequestedSize = in.RequestedSize.DeepCopy()
-	if in.AffectedPVCs != nil {
-		in, out := &in.AffectedPVCs, &out.AffectedPVCs
-		*out = make([]AffectedPVC, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionDetails.
-func (in *ExpansionDetails) DeepCopy() *ExpansionDetails {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiveLagConfig.
+func (in *ArchiveLagConfig) DeepCopy() *ArchiveLagConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ExpansionDetails)
+	out := new(ArchiveLagConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagedCluster) DeepCopyInto(out *ManagedCluster) {
+func (in *BackupMethodCheck) DeepCopyInto(out *BackupMethodCheck) {
 	*out = *in
-	in.LastChecked.DeepCopyInto(&out.LastChecked)
-	if in.BackupStatus != nil {
-		in, out := &in.BackupStatus, &out.BackupStatus
-		*out = new(ClusterBackupStatus)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedCluster.
-func (in *ManagedCluster) DeepCopy() *ManagedCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupMethodCheck.
+func (in *BackupMethodCheck) DeepCopy() *BackupMethodCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagedCluster)
+	out := new(BackupMethodCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PVCStatus) DeepCopyInto(out *PVCStatus) {
+func (in *BackupMethodStatus) DeepCopyInto(out *BackupMethodStatus) {
 	*out = *in
-	if in.OriginalSize != nil {
-		in, out := &in.OriginalSize, &out.OriginalSize
-		x := (*in).DeepCopy()
-		*out = &x
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
 	}
-	if in.NewSize != nil {
-		in, out := &in.NewSize, &out.NewSize
-		x := (*in).DeepCopy()
-		*out = &x
+	if in.LastFailedBackupTime != nil {
+		in, out := &in.LastFailedBackupTime, &out.LastFailedBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FirstRecoverabilityPoint != nil {
+		in, out := &in.FirstRecoverabilityPoint, &out.FirstRecoverabilityPoint
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCStatus.
-func (in *PVCStatus) DeepCopy() *PVCStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupMethodStatus.
+func (in *BackupMethodStatus) DeepCopy() *BackupMethodStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PVCStatus)
+	out := new(BackupMethodStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PolicyReference) DeepCopyInto(out *PolicyReference) {
+func (in *BackupMonitoringConfig) DeepCopyInto(out *BackupMonitoringConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyReference.
-func (in *PolicyReference) DeepCopy() *PolicyReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupMonitoringConfig.
+func (in *BackupMonitoringConfig) DeepCopy() *BackupMonitoringConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(PolicyReference)
+	out := new(BackupMonitoringConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StorageEvent) DeepCopyInto(out *StorageEvent) {
+func (in *BackupPolicy) DeepCopyInto(out *BackupPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -255,18 +266,18 @@ func (in *StorageEvent) DeepCopyInto(out *StorageEvent) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEvent.
-func (in *StorageEvent) DeepCopy() *StorageEvent {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicy.
+func (in *BackupPolicy) DeepCopy() *BackupPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(StorageEvent)
+	out := new(BackupPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *StorageEvent) DeepCopyObject() runtime.Object {
+func (in *BackupPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -274,31 +285,31 @@ func (in *StorageEvent) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StorageEventList) DeepCopyInto(out *StorageEventList) {
+func (in *BackupPolicyList) DeepCopyInto(out *BackupPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]StorageEvent, len(*in))
+		*out = make([]BackupPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEventList.
-func (in *StorageEventList) DeepCopy() *StorageEventList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicyList.
+func (in *BackupPolicyList) DeepCopy() *BackupPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(StorageEventList)
+	out := new(BackupPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *StorageEventList) DeepCopyObject() runtime.Object {
+func (in *BackupPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -306,192 +317,2299 @@ func (in *StorageEventList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StorageEventSpec) DeepCopyInto(out *StorageEventSpec) {
+func (in *BackupPolicySpec) DeepCopyInto(out *BackupPolicySpec) {
 	*out = *in
-	out.ClusterRef = in.ClusterRef
-	out.PolicyRef = in.PolicyRef
-	if in.Expansion != nil {
-		in, out := &in.Expansion, &out.Expansion
-		*out = new(ExpansionDetails)
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.WALCleanup != nil {
-		in, out := &in.WALCleanup, &out.WALCleanup
-		*out = new(WALCleanupDetails)
-		**out = **in
+	if in.ExcludeClusters != nil {
+		in, out := &in.ExcludeClusters, &out.ExcludeClusters
+		*out = make([]ClusterReference, len(*in))
+		copy(*out, *in)
+	}
+	out.Schedule = in.Schedule
+	out.ArchiveLag = in.ArchiveLag
+	out.ObjectStoreProbe = in.ObjectStoreProbe
+	out.RestoreVerification = in.RestoreVerification
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]BackupMethodCheck, len(*in))
+		copy(*out, *in)
 	}
+	in.Alerting.DeepCopyInto(&out.Alerting)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEventSpec.
-func (in *StorageEventSpec) DeepCopy() *StorageEventSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicySpec.
+func (in *BackupPolicySpec) DeepCopy() *BackupPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(StorageEventSpec)
+	out := new(BackupPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StorageEventStatus) DeepCopyInto(out *StorageEventStatus) {
+func (in *BackupPolicyStatus) DeepCopyInto(out *BackupPolicyStatus) {
 	*out = *in
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
-	}
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
-		*out = (*in).DeepCopy()
-	}
-	if in.PVCStatuses != nil {
-		in, out := &in.PVCStatuses, &out.PVCStatuses
-		*out = make([]PVCStatus, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterBackupHealth, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.NextRetryTime != nil {
-		in, out := &in.NextRetryTime, &out.NextRetryTime
+	if in.LastEvaluated != nil {
+		in, out := &in.LastEvaluated, &out.LastEvaluated
+		*out = (*in).DeepCopy()
+	}
+	if in.LastAlertSummary != nil {
+		in, out := &in.LastAlertSummary, &out.LastAlertSummary
 		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEventStatus.
-func (in *StorageEventStatus) DeepCopy() *StorageEventStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicyStatus.
+func (in *BackupPolicyStatus) DeepCopy() *BackupPolicyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(StorageEventStatus)
+	out := new(BackupPolicyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoragePolicy) DeepCopyInto(out *StoragePolicy) {
+func (in *BackupScheduleConfig) DeepCopyInto(out *BackupScheduleConfig) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePolicy.
-func (in *StoragePolicy) DeepCopy() *StoragePolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupScheduleConfig.
+func (in *BackupScheduleConfig) DeepCopy() *BackupScheduleConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(StoragePolicy)
+	out := new(BackupScheduleConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *StoragePolicy) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoragePolicyList) DeepCopyInto(out *StoragePolicyList) {
+func (in *BackupTargetOverrides) DeepCopyInto(out *BackupTargetOverrides) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]StoragePolicy, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.MaxBackupAgeHours != nil {
+		in, out := &in.MaxBackupAgeHours, &out.MaxBackupAgeHours
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RPOTargetMinutes != nil {
+		in, out := &in.RPOTargetMinutes, &out.RPOTargetMinutes
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePolicyList.
-func (in *StoragePolicyList) DeepCopy() *StoragePolicyList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTargetOverrides.
+func (in *BackupTargetOverrides) DeepCopy() *BackupTargetOverrides {
 	if in == nil {
 		return nil
 	}
-	out := new(StoragePolicyList)
+	out := new(BackupTargetOverrides)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *StoragePolicyList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoragePolicySpec) DeepCopyInto(out *StoragePolicySpec) {
+func (in *CircuitBreakerConfig) DeepCopyInto(out *CircuitBreakerConfig) {
 	*out = *in
-	if in.Selector != nil {
-		in, out := &in.Selector, &out.Selector
-		*out = new(v1.LabelSelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ExcludeClusters != nil {
-		in, out := &in.ExcludeClusters, &out.ExcludeClusters
-		*out = make([]ClusterReference, len(*in))
-		copy(*out, *in)
-	}
-	out.Thresholds = in.Thresholds
-	in.Expansion.DeepCopyInto(&out.Expansion)
-	out.WALCleanup = in.WALCleanup
-	out.BackupMonitoring = in.BackupMonitoring
-	out.CircuitBreaker = in.CircuitBreaker
-	in.Alerting.DeepCopyInto(&out.Alerting)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePolicySpec.
-func (in *StoragePolicySpec) DeepCopy() *StoragePolicySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakerConfig.
+func (in *CircuitBreakerConfig) DeepCopy() *CircuitBreakerConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(StoragePolicySpec)
+	out := new(CircuitBreakerConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoragePolicyStatus) DeepCopyInto(out *StoragePolicyStatus) {
+func (in *ClusterBackupHealth) DeepCopyInto(out *ClusterBackupHealth) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Issues != nil {
+		in, out := &in.Issues, &out.Issues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.ManagedClusters != nil {
-		in, out := &in.ManagedClusters, &out.ManagedClusters
-		*out = make([]ManagedCluster, len(*in))
+	if in.TargetOverrides != nil {
+		in, out := &in.TargetOverrides, &out.TargetOverrides
+		*out = new(BackupTargetOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FirstRecoverabilityPoint != nil {
+		in, out := &in.FirstRecoverabilityPoint, &out.FirstRecoverabilityPoint
+		*out = (*in).DeepCopy()
+	}
+	if in.RecoveryWindowHours != nil {
+		in, out := &in.RecoveryWindowHours, &out.RecoveryWindowHours
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RecoveryWindowCompliant != nil {
+		in, out := &in.RecoveryWindowCompliant, &out.RecoveryWindowCompliant
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RecoveryPointAgeMinutes != nil {
+		in, out := &in.RecoveryPointAgeMinutes, &out.RecoveryPointAgeMinutes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ArchiveLagSegments != nil {
+		in, out := &in.ArchiveLagSegments, &out.ArchiveLagSegments
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ArchiveLagSeconds != nil {
+		in, out := &in.ArchiveLagSeconds, &out.ArchiveLagSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NextExpectedBackup != nil {
+		in, out := &in.NextExpectedBackup, &out.NextExpectedBackup
+		*out = (*in).DeepCopy()
+	}
+	if in.ScheduledBackups != nil {
+		in, out := &in.ScheduledBackups, &out.ScheduledBackups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ObjectStoreProbe != nil {
+		in, out := &in.ObjectStoreProbe, &out.ObjectStoreProbe
+		*out = new(ObjectStoreProbeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastRestoreTest != nil {
+		in, out := &in.LastRestoreTest, &out.LastRestoreTest
+		*out = new(RestoreTestSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]BackupMethodStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastChecked.DeepCopyInto(&out.LastChecked)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterBackupHealth.
+func (in *ClusterBackupHealth) DeepCopy() *ClusterBackupHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterBackupHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterBackupStatus) DeepCopyInto(out *ClusterBackupStatus) {
+	*out = *in
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FirstRecoverabilityPoint != nil {
+		in, out := &in.FirstRecoverabilityPoint, &out.FirstRecoverabilityPoint
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterBackupStatus.
+func (in *ClusterBackupStatus) DeepCopy() *ClusterBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterConnection) DeepCopyInto(out *ClusterConnection) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConnection.
+func (in *ClusterConnection) DeepCopy() *ClusterConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterConnection) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterConnectionList) DeepCopyInto(out *ClusterConnectionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterConnection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConnectionList.
+func (in *ClusterConnectionList) DeepCopy() *ClusterConnectionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterConnectionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterConnectionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterConnectionSpec) DeepCopyInto(out *ClusterConnectionSpec) {
+	*out = *in
+	out.KubeconfigSecretRef = in.KubeconfigSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConnectionSpec.
+func (in *ClusterConnectionSpec) DeepCopy() *ClusterConnectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterConnectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterConnectionStatus) DeepCopyInto(out *ClusterConnectionStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastConnected != nil {
+		in, out := &in.LastConnected, &out.LastConnected
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConnectionStatus.
+func (in *ClusterConnectionStatus) DeepCopy() *ClusterConnectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterConnectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInclusion) DeepCopyInto(out *ClusterInclusion) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamePatterns != nil {
+		in, out := &in.NamePatterns, &out.NamePatterns
+		*out = make([]ClusterNamePattern, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInclusion.
+func (in *ClusterInclusion) DeepCopy() *ClusterInclusion {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInclusion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNamePattern) DeepCopyInto(out *ClusterNamePattern) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNamePattern.
+func (in *ClusterNamePattern) DeepCopy() *ClusterNamePattern {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterNamePattern)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterReference) DeepCopyInto(out *ClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReference.
+func (in *ClusterReference) DeepCopy() *ClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSummaryEntry) DeepCopyInto(out *ClusterSummaryEntry) {
+	*out = *in
+	if in.LastBackupAgeHours != nil {
+		in, out := &in.LastBackupAgeHours, &out.LastBackupAgeHours
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSummaryEntry.
+func (in *ClusterSummaryEntry) DeepCopy() *ClusterSummaryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSummaryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecisionAction) DeepCopyInto(out *DecisionAction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecisionAction.
+func (in *DecisionAction) DeepCopy() *DecisionAction {
+	if in == nil {
+		return nil
+	}
+	out := new(DecisionAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultAlertingConfig) DeepCopyInto(out *DefaultAlertingConfig) {
+	*out = *in
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]AlertChannel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultAlertingConfig.
+func (in *DefaultAlertingConfig) DeepCopy() *DefaultAlertingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultAlertingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EffectivenessConfig) DeepCopyInto(out *EffectivenessConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EffectivenessConfig.
+func (in *EffectivenessConfig) DeepCopy() *EffectivenessConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EffectivenessConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvaluationDecision) DeepCopyInto(out *EvaluationDecision) {
+	*out = *in
+	in.Thresholds.DeepCopyInto(&out.Thresholds)
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]DecisionAction, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlockReasons != nil {
+		in, out := &in.BlockReasons, &out.BlockReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvaluationDecision.
+func (in *EvaluationDecision) DeepCopy() *EvaluationDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(EvaluationDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionConfig) DeepCopyInto(out *ExpansionConfig) {
+	*out = *in
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	out.Recommendation = in.Recommendation
+	if in.FrequencyAlert != nil {
+		in, out := &in.FrequencyAlert, &out.FrequencyAlert
+		*out = new(ExpansionFrequencyAlert)
+		**out = **in
+	}
+	if in.Tablespaces != nil {
+		in, out := &in.Tablespaces, &out.Tablespaces
+		*out = make([]TablespaceExpansionConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.FileSystemResize = in.FileSystemResize
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(ExpansionRolloutConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionConfig.
+func (in *ExpansionConfig) DeepCopy() *ExpansionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionRolloutConfig) DeepCopyInto(out *ExpansionRolloutConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionRolloutConfig.
+func (in *ExpansionRolloutConfig) DeepCopy() *ExpansionRolloutConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionRolloutConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionRolloutStatus) DeepCopyInto(out *ExpansionRolloutStatus) {
+	*out = *in
+	if in.ExpandedInstances != nil {
+		in, out := &in.ExpandedInstances, &out.ExpandedInstances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CurrentStartTime != nil {
+		in, out := &in.CurrentStartTime, &out.CurrentStartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionRolloutStatus.
+func (in *ExpansionRolloutStatus) DeepCopy() *ExpansionRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionDetails) DeepCopyInto(out *ExpansionDetails) {
+	*out = *in
+	out.OriginalSize = in.OriginalSize.DeepCopy()
+	out.RequestedSize = in.RequestedSize.DeepCopy()
+	if in.AffectedPVCs != nil {
+		in, out := &in.AffectedPVCs, &out.AffectedPVCs
+		*out = make([]AffectedPVC, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionDetails.
+func (in *ExpansionDetails) DeepCopy() *ExpansionDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionFrequencyAlert) DeepCopyInto(out *ExpansionFrequencyAlert) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionFrequencyAlert.
+func (in *ExpansionFrequencyAlert) DeepCopy() *ExpansionFrequencyAlert {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionFrequencyAlert)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionHistory) DeepCopyInto(out *ExpansionHistory) {
+	*out = *in
+	if in.Records != nil {
+		in, out := &in.Records, &out.Records
+		*out = make([]ExpansionRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FrequencyAlertedAt != nil {
+		in, out := &in.FrequencyAlertedAt, &out.FrequencyAlertedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionHistory.
+func (in *ExpansionHistory) DeepCopy() *ExpansionHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionRecord) DeepCopyInto(out *ExpansionRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	out.OriginalSize = in.OriginalSize.DeepCopy()
+	out.NewSize = in.NewSize.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionRecord.
+func (in *ExpansionRecord) DeepCopy() *ExpansionRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionSkipStatus) DeepCopyInto(out *ExpansionSkipStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionSkipStatus.
+func (in *ExpansionSkipStatus) DeepCopy() *ExpansionSkipStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionSkipStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionTarget) DeepCopyInto(out *ExpansionTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionTarget.
+func (in *ExpansionTarget) DeepCopy() *ExpansionTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FencingConfig) DeepCopyInto(out *FencingConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FencingConfig.
+func (in *FencingConfig) DeepCopy() *FencingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FencingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileSystemResizeConfig) DeepCopyInto(out *FileSystemResizeConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileSystemResizeConfig.
+func (in *FileSystemResizeConfig) DeepCopy() *FileSystemResizeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FileSystemResizeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForecastConfig) DeepCopyInto(out *ForecastConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForecastConfig.
+func (in *ForecastConfig) DeepCopy() *ForecastConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ForecastConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForecastHorizon) DeepCopyInto(out *ForecastHorizon) {
+	*out = *in
+	out.ProjectedUsage = in.ProjectedUsage.DeepCopy()
+	out.ProjectedCapacity = in.ProjectedCapacity.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForecastHorizon.
+func (in *ForecastHorizon) DeepCopy() *ForecastHorizon {
+	if in == nil {
+		return nil
+	}
+	out := new(ForecastHorizon)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrowthStatus) DeepCopyInto(out *GrowthStatus) {
+	*out = *in
+	if in.Samples != nil {
+		in, out := &in.Samples, &out.Samples
+		*out = make([]UsageSample, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AnomalyDetectedAt != nil {
+		in, out := &in.AnomalyDetectedAt, &out.AnomalyDetectedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrowthStatus.
+func (in *GrowthStatus) DeepCopy() *GrowthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrowthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeadroomThresholds) DeepCopyInto(out *HeadroomThresholds) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeadroomThresholds.
+func (in *HeadroomThresholds) DeepCopy() *HeadroomThresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(HeadroomThresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookSecretReference) DeepCopyInto(out *HookSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookSecretReference.
+func (in *HookSecretReference) DeepCopy() *HookSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HookSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HooksConfig) DeepCopyInto(out *HooksConfig) {
+	*out = *in
+	if in.PreAction != nil {
+		in, out := &in.PreAction, &out.PreAction
+		*out = new(WebhookHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostAction != nil {
+		in, out := &in.PostAction, &out.PostAction
+		*out = new(WebhookHook)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HooksConfig.
+func (in *HooksConfig) DeepCopy() *HooksConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HooksConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeconfigSecretReference) DeepCopyInto(out *KubeconfigSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeconfigSecretReference.
+func (in *KubeconfigSecretReference) DeepCopy() *KubeconfigSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeconfigSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogHintsConfig) DeepCopyInto(out *LogHintsConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogHintsConfig.
+func (in *LogHintsConfig) DeepCopy() *LogHintsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LogHintsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedCluster) DeepCopyInto(out *ManagedCluster) {
+	*out = *in
+	in.LastChecked.DeepCopyInto(&out.LastChecked)
+	if in.HealthScore != nil {
+		in, out := &in.HealthScore, &out.HealthScore
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BackupStatus != nil {
+		in, out := &in.BackupStatus, &out.BackupStatus
+		*out = new(ClusterBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RecoveryWindow != nil {
+		in, out := &in.RecoveryWindow, &out.RecoveryWindow
+		*out = new(RecoveryWindowStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PlannedActions != nil {
+		in, out := &in.PlannedActions, &out.PlannedActions
+		*out = make([]PlannedAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExpansionHistory != nil {
+		in, out := &in.ExpansionHistory, &out.ExpansionHistory
+		*out = new(ExpansionHistory)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Growth != nil {
+		in, out := &in.Growth, &out.Growth
+		*out = new(GrowthStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tablespaces != nil {
+		in, out := &in.Tablespaces, &out.Tablespaces
+		*out = make([]TablespaceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.WALVolume != nil {
+		in, out := &in.WALVolume, &out.WALVolume
+		*out = new(WALVolumeStatus)
+		**out = **in
+	}
+	if in.PoolerVolumes != nil {
+		in, out := &in.PoolerVolumes, &out.PoolerVolumes
+		*out = make([]PoolerVolumeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]ZoneUsageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FencedInstances != nil {
+		in, out := &in.FencedInstances, &out.FencedInstances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpansionSkips != nil {
+		in, out := &in.ExpansionSkips, &out.ExpansionSkips
+		*out = make([]ExpansionSkipStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingResizes != nil {
+		in, out := &in.PendingResizes, &out.PendingResizes
+		*out = make([]PendingResizeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Decision != nil {
+		in, out := &in.Decision, &out.Decision
+		*out = new(EvaluationDecision)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(StorageSLOStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedCluster.
+func (in *ManagedCluster) DeepCopy() *ManagedCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerConfig) DeepCopyInto(out *ManagerConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerConfig.
+func (in *ManagerConfig) DeepCopy() *ManagerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagerConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerConfigList) DeepCopyInto(out *ManagerConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ManagerConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerConfigList.
+func (in *ManagerConfigList) DeepCopy() *ManagerConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagerConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerConfigSpec) DeepCopyInto(out *ManagerConfigSpec) {
+	*out = *in
+	in.Thresholds.DeepCopyInto(&out.Thresholds)
+	in.Alerting.DeepCopyInto(&out.Alerting)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerConfigSpec.
+func (in *ManagerConfigSpec) DeepCopy() *ManagerConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerConfigStatus) DeepCopyInto(out *ManagerConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerConfigStatus.
+func (in *ManagerConfigStatus) DeepCopy() *ManagerConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreProbeConfig) DeepCopyInto(out *ObjectStoreProbeConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreProbeConfig.
+func (in *ObjectStoreProbeConfig) DeepCopy() *ObjectStoreProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreProbeStatus) DeepCopyInto(out *ObjectStoreProbeStatus) {
+	*out = *in
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreProbeStatus.
+func (in *ObjectStoreProbeStatus) DeepCopy() *ObjectStoreProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OwnershipMetadata) DeepCopyInto(out *OwnershipMetadata) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnershipMetadata.
+func (in *OwnershipMetadata) DeepCopy() *OwnershipMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(OwnershipMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCStatus) DeepCopyInto(out *PVCStatus) {
+	*out = *in
+	if in.OriginalSize != nil {
+		in, out := &in.OriginalSize, &out.OriginalSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.NewSize != nil {
+		in, out := &in.NewSize, &out.NewSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCStatus.
+func (in *PVCStatus) DeepCopy() *PVCStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingResizeStatus) DeepCopyInto(out *PendingResizeStatus) {
+	*out = *in
+	in.PendingSince.DeepCopyInto(&out.PendingSince)
+	if in.RestartedAt != nil {
+		in, out := &in.RestartedAt, &out.RestartedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingResizeStatus.
+func (in *PendingResizeStatus) DeepCopy() *PendingResizeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingResizeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerformanceTuningConfig) DeepCopyInto(out *PerformanceTuningConfig) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerformanceTuningConfig.
+func (in *PerformanceTuningConfig) DeepCopy() *PerformanceTuningConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PerformanceTuningConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedAction) DeepCopyInto(out *PlannedAction) {
+	*out = *in
+	out.ExpansionTarget = in.ExpansionTarget
+	in.PlannedAt.DeepCopyInto(&out.PlannedAt)
+	if in.PVCs != nil {
+		in, out := &in.PVCs, &out.PVCs
+		*out = make([]PlannedPVCExpansion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WALCleanup != nil {
+		in, out := &in.WALCleanup, &out.WALCleanup
+		*out = make([]PlannedWALCleanup, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedAction.
+func (in *PlannedAction) DeepCopy() *PlannedAction {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedPVCExpansion) DeepCopyInto(out *PlannedPVCExpansion) {
+	*out = *in
+	out.CurrentSize = in.CurrentSize.DeepCopy()
+	if in.TargetSize != nil {
+		in, out := &in.TargetSize, &out.TargetSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedPVCExpansion.
+func (in *PlannedPVCExpansion) DeepCopy() *PlannedPVCExpansion {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedPVCExpansion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedWALCleanup) DeepCopyInto(out *PlannedWALCleanup) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedWALCleanup.
+func (in *PlannedWALCleanup) DeepCopy() *PlannedWALCleanup {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedWALCleanup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyReference) DeepCopyInto(out *PolicyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyReference.
+func (in *PolicyReference) DeepCopy() *PolicyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicySummary) DeepCopyInto(out *PolicySummary) {
+	*out = *in
+	if in.TopUsage != nil {
+		in, out := &in.TopUsage, &out.TopUsage
+		*out = make([]ClusterSummaryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OldestBackups != nil {
+		in, out := &in.OldestBackups, &out.OldestBackups
+		*out = make([]ClusterSummaryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicySummary.
+func (in *PolicySummary) DeepCopy() *PolicySummary {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicySummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolerMonitoringConfig) DeepCopyInto(out *PoolerMonitoringConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolerMonitoringConfig.
+func (in *PoolerMonitoringConfig) DeepCopy() *PoolerMonitoringConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolerMonitoringConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolerVolumeStatus) DeepCopyInto(out *PoolerVolumeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolerVolumeStatus.
+func (in *PoolerVolumeStatus) DeepCopy() *PoolerVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolerVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectedExpansion) DeepCopyInto(out *ProjectedExpansion) {
+	*out = *in
+	in.Date.DeepCopyInto(&out.Date)
+	out.FromSize = in.FromSize.DeepCopy()
+	out.ToSize = in.ToSize.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectedExpansion.
+func (in *ProjectedExpansion) DeepCopy() *ProjectedExpansion {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectedExpansion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusRuleConfig) DeepCopyInto(out *PrometheusRuleConfig) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusRuleConfig.
+func (in *PrometheusRuleConfig) DeepCopy() *PrometheusRuleConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusRuleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuietHoursConfig) DeepCopyInto(out *QuietHoursConfig) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuietHoursConfig.
+func (in *QuietHoursConfig) DeepCopy() *QuietHoursConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QuietHoursConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaManagementConfig) DeepCopyInto(out *QuotaManagementConfig) {
+	*out = *in
+	if in.MaxHard != nil {
+		in, out := &in.MaxHard, &out.MaxHard
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaManagementConfig.
+func (in *QuotaManagementConfig) DeepCopy() *QuotaManagementConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaManagementConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommendationConfig) DeepCopyInto(out *RecommendationConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendationConfig.
+func (in *RecommendationConfig) DeepCopy() *RecommendationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommendationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryWindowStatus) DeepCopyInto(out *RecoveryWindowStatus) {
+	*out = *in
+	if in.FirstRecoverabilityPoint != nil {
+		in, out := &in.FirstRecoverabilityPoint, &out.FirstRecoverabilityPoint
+		*out = (*in).DeepCopy()
+	}
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryWindowStatus.
+func (in *RecoveryWindowStatus) DeepCopy() *RecoveryWindowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryWindowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationEffectiveness) DeepCopyInto(out *RemediationEffectiveness) {
+	*out = *in
+	if in.MeasuredAt != nil {
+		in, out := &in.MeasuredAt, &out.MeasuredAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationEffectiveness.
+func (in *RemediationEffectiveness) DeepCopy() *RemediationEffectiveness {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationEffectiveness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationStep) DeepCopyInto(out *RemediationStep) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationStep.
+func (in *RemediationStep) DeepCopy() *RemediationStep {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaWALCleanup) DeepCopyInto(out *ReplicaWALCleanup) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaWALCleanup.
+func (in *ReplicaWALCleanup) DeepCopy() *ReplicaWALCleanup {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaWALCleanup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTestDetails) DeepCopyInto(out *RestoreTestDetails) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTestDetails.
+func (in *RestoreTestDetails) DeepCopy() *RestoreTestDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTestDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTestStatus) DeepCopyInto(out *RestoreTestStatus) {
+	*out = *in
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTestStatus.
+func (in *RestoreTestStatus) DeepCopy() *RestoreTestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTestSummary) DeepCopyInto(out *RestoreTestSummary) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTestSummary.
+func (in *RestoreTestSummary) DeepCopy() *RestoreTestSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTestSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerificationConfig) DeepCopyInto(out *RestoreVerificationConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationConfig.
+func (in *RestoreVerificationConfig) DeepCopy() *RestoreVerificationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreVerificationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClassMigrationConfig) DeepCopyInto(out *StorageClassMigrationConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassMigrationConfig.
+func (in *StorageClassMigrationConfig) DeepCopy() *StorageClassMigrationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClassMigrationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClassMigrationDetails) DeepCopyInto(out *StorageClassMigrationDetails) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassMigrationDetails.
+func (in *StorageClassMigrationDetails) DeepCopy() *StorageClassMigrationDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClassMigrationDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClassMigrationStatus) DeepCopyInto(out *StorageClassMigrationStatus) {
+	*out = *in
+	if in.MigratedInstances != nil {
+		in, out := &in.MigratedInstances, &out.MigratedInstances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CurrentStartTime != nil {
+		in, out := &in.CurrentStartTime, &out.CurrentStartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassMigrationStatus.
+func (in *StorageClassMigrationStatus) DeepCopy() *StorageClassMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClassMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageEvent) DeepCopyInto(out *StorageEvent) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEvent.
+func (in *StorageEvent) DeepCopy() *StorageEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StorageEvent) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageEventList) DeepCopyInto(out *StorageEventList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StorageEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEventList.
+func (in *StorageEventList) DeepCopy() *StorageEventList {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageEventList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StorageEventList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageEventSpec) DeepCopyInto(out *StorageEventSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	out.PolicyRef = in.PolicyRef
+	if in.Ownership != nil {
+		in, out := &in.Ownership, &out.Ownership
+		*out = new(OwnershipMetadata)
+		**out = **in
+	}
+	out.ExpansionTarget = in.ExpansionTarget
+	if in.Expansion != nil {
+		in, out := &in.Expansion, &out.Expansion
+		*out = new(ExpansionDetails)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WALCleanup != nil {
+		in, out := &in.WALCleanup, &out.WALCleanup
+		*out = new(WALCleanupDetails)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestoreTest != nil {
+		in, out := &in.RestoreTest, &out.RestoreTest
+		*out = new(RestoreTestDetails)
+		**out = **in
+	}
+	if in.StorageClassMigration != nil {
+		in, out := &in.StorageClassMigration, &out.StorageClassMigration
+		*out = new(StorageClassMigrationDetails)
+		**out = **in
+	}
+	if in.VolumeAttributesChange != nil {
+		in, out := &in.VolumeAttributesChange, &out.VolumeAttributesChange
+		*out = new(VolumeAttributesChangeDetails)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEventSpec.
+func (in *StorageEventSpec) DeepCopy() *StorageEventSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageEventSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageEventStatus) DeepCopyInto(out *StorageEventStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PVCStatuses != nil {
+		in, out := &in.PVCStatuses, &out.PVCStatuses
+		*out = make([]PVCStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RemediationStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RestoreTest != nil {
+		in, out := &in.RestoreTest, &out.RestoreTest
+		*out = new(RestoreTestStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageClassMigration != nil {
+		in, out := &in.StorageClassMigration, &out.StorageClassMigration
+		*out = new(StorageClassMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpansionRollout != nil {
+		in, out := &in.ExpansionRollout, &out.ExpansionRollout
+		*out = new(ExpansionRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeModifications != nil {
+		in, out := &in.VolumeModifications, &out.VolumeModifications
+		*out = make([]VolumeModificationStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Effectiveness != nil {
+		in, out := &in.Effectiveness, &out.Effectiveness
+		*out = new(RemediationEffectiveness)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEventStatus.
+func (in *StorageEventStatus) DeepCopy() *StorageEventStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageEventStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageForecast) DeepCopyInto(out *StorageForecast) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageForecast.
+func (in *StorageForecast) DeepCopy() *StorageForecast {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageForecast)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StorageForecast) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageForecastList) DeepCopyInto(out *StorageForecastList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StorageForecast, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageForecastList.
+func (in *StorageForecastList) DeepCopy() *StorageForecastList {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageForecastList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StorageForecastList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageForecastSpec) DeepCopyInto(out *StorageForecastSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageForecastSpec.
+func (in *StorageForecastSpec) DeepCopy() *StorageForecastSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageForecastSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageForecastStatus) DeepCopyInto(out *StorageForecastStatus) {
+	*out = *in
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.GrowthPerDay != nil {
+		in, out := &in.GrowthPerDay, &out.GrowthPerDay
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.In30Days != nil {
+		in, out := &in.In30Days, &out.In30Days
+		*out = new(ForecastHorizon)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.In60Days != nil {
+		in, out := &in.In60Days, &out.In60Days
+		*out = new(ForecastHorizon)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.In90Days != nil {
+		in, out := &in.In90Days, &out.In90Days
+		*out = new(ForecastHorizon)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProjectedExpansions != nil {
+		in, out := &in.ProjectedExpansions, &out.ProjectedExpansions
+		*out = make([]ProjectedExpansion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Samples != nil {
+		in, out := &in.Samples, &out.Samples
+		*out = make([]UsageSample, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageForecastStatus.
+func (in *StorageForecastStatus) DeepCopy() *StorageForecastStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageForecastStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoragePolicy) DeepCopyInto(out *StoragePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePolicy.
+func (in *StoragePolicy) DeepCopy() *StoragePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StoragePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StoragePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoragePolicyList) DeepCopyInto(out *StoragePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StoragePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePolicyList.
+func (in *StoragePolicyList) DeepCopy() *StoragePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(StoragePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StoragePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoragePolicySpec) DeepCopyInto(out *StoragePolicySpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IncludeClusters != nil {
+		in, out := &in.IncludeClusters, &out.IncludeClusters
+		*out = new(ClusterInclusion)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludeClusters != nil {
+		in, out := &in.ExcludeClusters, &out.ExcludeClusters
+		*out = make([]ClusterReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Connections != nil {
+		in, out := &in.Connections, &out.Connections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Thresholds.DeepCopyInto(&out.Thresholds)
+	if in.WALThresholds != nil {
+		in, out := &in.WALThresholds, &out.WALThresholds
+		*out = new(ThresholdsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Expansion.DeepCopyInto(&out.Expansion)
+	if in.WALExpansion != nil {
+		in, out := &in.WALExpansion, &out.WALExpansion
+		*out = new(VolumeExpansionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	in.WALCleanup.DeepCopyInto(&out.WALCleanup)
+	out.AnomalyDetection = in.AnomalyDetection
+	out.Forecast = in.Forecast
+	out.UsageHistory = in.UsageHistory
+	in.Quota.DeepCopyInto(&out.Quota)
+	out.Fencing = in.Fencing
+	out.WriteProbe = in.WriteProbe
+	if in.StorageClassMigration != nil {
+		in, out := &in.StorageClassMigration, &out.StorageClassMigration
+		*out = new(StorageClassMigrationConfig)
+		**out = **in
+	}
+	if in.PerformanceTuning != nil {
+		in, out := &in.PerformanceTuning, &out.PerformanceTuning
+		*out = new(PerformanceTuningConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeAttributes != nil {
+		in, out := &in.VolumeAttributes, &out.VolumeAttributes
+		*out = new(VolumeAttributesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	out.BackupMonitoring = in.BackupMonitoring
+	out.Effectiveness = in.Effectiveness
+	out.SLO = in.SLO
+	out.Poolers = in.Poolers
+	out.CircuitBreaker = in.CircuitBreaker
+	in.Alerting.DeepCopyInto(&out.Alerting)
+	in.Hooks.DeepCopyInto(&out.Hooks)
+	out.Metadata = in.Metadata
+	if in.DryRunUntil != nil {
+		in, out := &in.DryRunUntil, &out.DryRunUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.PauseUntil != nil {
+		in, out := &in.PauseUntil, &out.PauseUntil
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePolicySpec.
+func (in *StoragePolicySpec) DeepCopy() *StoragePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StoragePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoragePolicyStatus) DeepCopyInto(out *StoragePolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ManagedClusters != nil {
+		in, out := &in.ManagedClusters, &out.ManagedClusters
+		*out = make([]ManagedCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(PolicySummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastEvaluated != nil {
+		in, out := &in.LastEvaluated, &out.LastEvaluated
+		*out = (*in).DeepCopy()
+	}
+	if in.PartialSuccessSince != nil {
+		in, out := &in.PartialSuccessSince, &out.PartialSuccessSince
+		*out = (*in).DeepCopy()
+	}
+	if in.PartialSuccessAlertedAt != nil {
+		in, out := &in.PartialSuccessAlertedAt, &out.PartialSuccessAlertedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.DryRunExpiredAt != nil {
+		in, out := &in.DryRunExpiredAt, &out.DryRunExpiredAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastAlertSummary != nil {
+		in, out := &in.LastAlertSummary, &out.LastAlertSummary
+		*out = (*in).DeepCopy()
+	}
+	if in.AlertChannels != nil {
+		in, out := &in.AlertChannels, &out.AlertChannels
+		*out = make([]AlertChannelStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePolicyStatus.
+func (in *StoragePolicyStatus) DeepCopy() *StoragePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StoragePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageSLOConfig) DeepCopyInto(out *StorageSLOConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSLOConfig.
+func (in *StorageSLOConfig) DeepCopy() *StorageSLOConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageSLOConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageSLOStatus) DeepCopyInto(out *StorageSLOStatus) {
+	*out = *in
+	in.WindowStart.DeepCopyInto(&out.WindowStart)
+	if in.Violations != nil {
+		in, out := &in.Violations, &out.Violations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSLOStatus.
+func (in *StorageSLOStatus) DeepCopy() *StorageSLOStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageSLOStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageUsageHistory) DeepCopyInto(out *StorageUsageHistory) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageUsageHistory.
+func (in *StorageUsageHistory) DeepCopy() *StorageUsageHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageUsageHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StorageUsageHistory) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageUsageHistoryList) DeepCopyInto(out *StorageUsageHistoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StorageUsageHistory, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.LastEvaluated != nil {
-		in, out := &in.LastEvaluated, &out.LastEvaluated
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageUsageHistoryList.
+func (in *StorageUsageHistoryList) DeepCopy() *StorageUsageHistoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageUsageHistoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StorageUsageHistoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageUsageHistorySpec) DeepCopyInto(out *StorageUsageHistorySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageUsageHistorySpec.
+func (in *StorageUsageHistorySpec) DeepCopy() *StorageUsageHistorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageUsageHistorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageUsageHistoryStatus) DeepCopyInto(out *StorageUsageHistoryStatus) {
+	*out = *in
+	if in.Newest != nil {
+		in, out := &in.Newest, &out.Newest
 		*out = (*in).DeepCopy()
 	}
+	if in.UsedBytes != nil {
+		in, out := &in.UsedBytes, &out.UsedBytes
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataUsedBytes != nil {
+		in, out := &in.DataUsedBytes, &out.DataUsedBytes
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePolicyStatus.
-func (in *StoragePolicyStatus) DeepCopy() *StoragePolicyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageUsageHistoryStatus.
+func (in *StorageUsageHistoryStatus) DeepCopy() *StorageUsageHistoryStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(StoragePolicyStatus)
+	out := new(StorageUsageHistoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TablespaceExpansionConfig) DeepCopyInto(out *TablespaceExpansionConfig) {
+	*out = *in
+	in.VolumeExpansionConfig.DeepCopyInto(&out.VolumeExpansionConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TablespaceExpansionConfig.
+func (in *TablespaceExpansionConfig) DeepCopy() *TablespaceExpansionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TablespaceExpansionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TablespaceStatus) DeepCopyInto(out *TablespaceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TablespaceStatus.
+func (in *TablespaceStatus) DeepCopy() *TablespaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TablespaceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -499,6 +2617,16 @@ func (in *StoragePolicyStatus) DeepCopy() *StoragePolicyStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThresholdsConfig) DeepCopyInto(out *ThresholdsConfig) {
 	*out = *in
+	if in.HeadroomGi != nil {
+		in, out := &in.HeadroomGi, &out.HeadroomGi
+		*out = new(HeadroomThresholds)
+		**out = **in
+	}
+	if in.AbsoluteFreeBytesFloor != nil {
+		in, out := &in.AbsoluteFreeBytesFloor, &out.AbsoluteFreeBytesFloor
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThresholdsConfig.
@@ -511,9 +2639,169 @@ func (in *ThresholdsConfig) DeepCopy() *ThresholdsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TicketConfig) DeepCopyInto(out *TicketConfig) {
+	*out = *in
+	if in.Severities != nil {
+		in, out := &in.Severities, &out.Severities
+		*out = make([]TicketSeverity, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResolveFields != nil {
+		in, out := &in.ResolveFields, &out.ResolveFields
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TicketConfig.
+func (in *TicketConfig) DeepCopy() *TicketConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TicketConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageHistoryConfig) DeepCopyInto(out *UsageHistoryConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageHistoryConfig.
+func (in *UsageHistoryConfig) DeepCopy() *UsageHistoryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageHistoryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageSample) DeepCopyInto(out *UsageSample) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageSample.
+func (in *UsageSample) DeepCopy() *UsageSample {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageSample)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeAttributesChangeDetails) DeepCopyInto(out *VolumeAttributesChangeDetails) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeAttributesChangeDetails.
+func (in *VolumeAttributesChangeDetails) DeepCopy() *VolumeAttributesChangeDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeAttributesChangeDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeAttributesClassThreshold) DeepCopyInto(out *VolumeAttributesClassThreshold) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeAttributesClassThreshold.
+func (in *VolumeAttributesClassThreshold) DeepCopy() *VolumeAttributesClassThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeAttributesClassThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeAttributesConfig) DeepCopyInto(out *VolumeAttributesConfig) {
+	*out = *in
+	if in.Classes != nil {
+		in, out := &in.Classes, &out.Classes
+		*out = make([]VolumeAttributesClassThreshold, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeAttributesConfig.
+func (in *VolumeAttributesConfig) DeepCopy() *VolumeAttributesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeAttributesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeExpansionConfig) DeepCopyInto(out *VolumeExpansionConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeExpansionConfig.
+func (in *VolumeExpansionConfig) DeepCopy() *VolumeExpansionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeExpansionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeModificationStatus) DeepCopyInto(out *VolumeModificationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeModificationStatus.
+func (in *VolumeModificationStatus) DeepCopy() *VolumeModificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeModificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WALCleanupConfig) DeepCopyInto(out *WALCleanupConfig) {
 	*out = *in
+	if in.AllowedCommands != nil {
+		in, out := &in.AllowedCommands, &out.AllowedCommands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WALCleanupConfig.
@@ -529,6 +2817,11 @@ func (in *WALCleanupConfig) DeepCopy() *WALCleanupConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WALCleanupDetails) DeepCopyInto(out *WALCleanupDetails) {
 	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]ReplicaWALCleanup, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WALCleanupDetails.
@@ -540,3 +2833,73 @@ func (in *WALCleanupDetails) DeepCopy() *WALCleanupDetails {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WALVolumeStatus) DeepCopyInto(out *WALVolumeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WALVolumeStatus.
+func (in *WALVolumeStatus) DeepCopy() *WALVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WALVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookHook) DeepCopyInto(out *WebhookHook) {
+	*out = *in
+	if in.SigningSecretRef != nil {
+		in, out := &in.SigningSecretRef, &out.SigningSecretRef
+		*out = new(HookSecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookHook.
+func (in *WebhookHook) DeepCopy() *WebhookHook {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WriteProbeConfig) DeepCopyInto(out *WriteProbeConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WriteProbeConfig.
+func (in *WriteProbeConfig) DeepCopy() *WriteProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WriteProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneUsageStatus) DeepCopyInto(out *ZoneUsageStatus) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneUsageStatus.
+func (in *ZoneUsageStatus) DeepCopy() *ZoneUsageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneUsageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
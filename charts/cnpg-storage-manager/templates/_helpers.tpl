@@ -49,6 +49,15 @@ app.kubernetes.io/instance: {{ .Release.Name }}
 control-plane: controller-manager
 {{- end }}
 
+{{/*
+Node agent selector labels
+*/}}
+{{- define "cnpg-storage-manager.agentSelectorLabels" -}}
+app.kubernetes.io/name: {{ include "cnpg-storage-manager.name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+app.kubernetes.io/component: node-agent
+{{- end }}
+
 {{/*
 Create the name of the service account to use
 */}}
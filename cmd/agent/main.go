@@ -0,0 +1,81 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command agent is the cnpg-storage-manager node agent. It runs as a DaemonSet and
+// reports the usage of the PVC mounts of pods on its node, so the manager can
+// collect storage metrics where pod exec is not permitted.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/agent"
+)
+
+func main() {
+	var bindAddr string
+	var podsDir string
+	var nodeName string
+	flag.StringVar(&bindAddr, "bind-address", fmt.Sprintf(":%d", agent.DefaultPort),
+		"The address the volume report endpoint binds to.")
+	flag.StringVar(&podsDir, "pods-dir", agent.DefaultPodsDir,
+		"The kubelet pods directory, mounted read-only from the host.")
+	flag.StringVar(&nodeName, "node-name", os.Getenv("NODE_NAME"),
+		"The name of the node the agent runs on. Defaults to $NODE_NAME.")
+	opts := zap.Options{Development: false}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	setupLog := ctrl.Log.WithName("agent")
+
+	mux := http.NewServeMux()
+	mux.Handle(agent.VolumesPath, &agent.Server{NodeName: nodeName, PodsDir: podsDir})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{
+		Addr:              bindAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	setupLog.Info("Starting node agent", "address", bindAddr, "node", nodeName, "podsDir", podsDir)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		setupLog.Error(err, "node agent failed")
+		os.Exit(1)
+	}
+}
@@ -17,26 +17,56 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
+	// Embed the time zone database, which the distroless image lacks, for quiet hours
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
 	"github.com/supporttools/cnpg-storage-manager/internal/controller"
+	"github.com/supporttools/cnpg-storage-manager/pkg/agent"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/backup"
+	"github.com/supporttools/cnpg-storage-manager/pkg/clock"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/connection"
+	"github.com/supporttools/cnpg-storage-manager/pkg/dashboard"
+	"github.com/supporttools/cnpg-storage-manager/pkg/faults"
+	"github.com/supporttools/cnpg-storage-manager/pkg/hooks"
+	"github.com/supporttools/cnpg-storage-manager/pkg/identity"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/permissions"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+	"github.com/supporttools/cnpg-storage-manager/pkg/report"
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+	"github.com/supporttools/cnpg-storage-manager/pkg/sharding"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -60,8 +90,24 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var secureMetrics bool
+	var enableDashboard bool
 	var enableHTTP2 bool
 	var globalDryRun bool
+	var readOnly bool
+	var watchDiskErrors bool
+	var commandRunnerMode string
+	var jobRunnerConfig runner.JobConfig
+	var execTimeout, remediationDeadline time.Duration
+	var statusPush report.PushConfig
+	var remoteWrite metrics.RemoteWriteConfig
+	var agentNamespace, agentSelector string
+	var agentPort int
+	var clusterIdentity identity.ClusterIdentity
+	identityResolver := identity.DefaultResolver()
+	var tracingConfig tracing.Config
+	var shard sharding.Shard
+	var timeOffset time.Duration
+	var faultSpec string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -71,6 +117,9 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
+	flag.BoolVar(&enableDashboard, "dashboard", false,
+		"Serve a web UI of managed clusters and recent StorageEvents, with pause, resume and approve actions, "+
+			"at "+dashboard.Path+" on the metrics server. Requires --metrics-secure, whose authn/authz guards it.")
 	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
 	flag.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
 	flag.StringVar(&webhookCertKey, "webhook-cert-key", "tls.key", "The name of the webhook key file.")
@@ -83,6 +132,75 @@ func main() {
 	flag.BoolVar(&globalDryRun, "dry-run", false,
 		"Enable global dry-run mode. When enabled, no actual changes are made to PVCs or WAL files. "+
 			"Useful for testing and validation. Can also be set via DRY_RUN environment variable.")
+	flag.BoolVar(&readOnly, "read-only", false,
+		"Monitor-only mode: observe and alert without changing anything. Implies --dry-run, runs no commands "+
+			"in pods and does not annotate clusters or probe object stores, so the manager can run without write "+
+			"access to PVCs, clusters, pods, Jobs and Secrets, and without pods/exec.")
+	flag.BoolVar(&watchDiskErrors, "watch-disk-errors", false,
+		"Watch Kubernetes Events for 'No space left on device' errors of CNPG pods and evaluate "+
+			"the policies selecting the cluster right away instead of at the next periodic reconcile. "+
+			"Requires get, list and watch on events.")
+	flag.StringVar(&commandRunnerMode, "command-runner", string(runner.ModeExec),
+		"How WAL cleanup and df probes run against instance pods: 'exec' uses pod exec from the manager, "+
+			"'job' runs a short-lived Job that mounts the pod's volumes, so pods/exec is not needed.")
+	flag.StringVar(&jobRunnerConfig.Image, "job-runner-image", "",
+		"Image for runner Jobs. Defaults to the image of the target postgres container.")
+	flag.StringVar(&jobRunnerConfig.ServiceAccountName, "job-runner-service-account", "",
+		"Service account for runner Jobs. Runner pods never mount an API token.")
+	flag.DurationVar(&jobRunnerConfig.Timeout, "job-runner-timeout", runner.DefaultJobTimeout,
+		"Maximum time a runner Job may take.")
+	flag.DurationVar(&execTimeout, "exec-timeout", runner.DefaultExecTimeout,
+		"Maximum time a single pod exec command (df, ls, rm, psql) may take before it is cancelled.")
+	flag.DurationVar(&remediationDeadline, "remediation-deadline", remediation.DefaultRemediationDeadline,
+		"Maximum time the commands of one WAL cleanup attempt may take across all instances of a cluster.")
+	flag.StringVar(&agentNamespace, "agent-namespace", "",
+		"Namespace of the node agent DaemonSet used by policies with metricsSource 'agent'. "+
+			"Empty disables the node agent.")
+	flag.StringVar(&agentSelector, "agent-selector", metrics.DefaultAgentSelector,
+		"Label selector of the node agent pods.")
+	flag.IntVar(&agentPort, "agent-port", agent.DefaultPort,
+		"Port the node agent serves volume reports on.")
+	flag.StringVar(&clusterIdentity.ID, "cluster-id", os.Getenv(identity.EnvClusterID),
+		"ID of the Kubernetes cluster the manager runs in (e.g. the Rancher cluster ID), added to alerts. "+
+			"Defaults to the CLUSTER_ID environment variable.")
+	flag.StringVar(&clusterIdentity.Name, "cluster-name", os.Getenv(identity.EnvClusterName),
+		"Name of the Kubernetes cluster the manager runs in, added to alerts. "+
+			"Defaults to the CLUSTER_NAME environment variable.")
+	flag.StringVar(&identityResolver.IDLabel, "cluster-id-label", identityResolver.IDLabel,
+		"ClusterConnection label holding the downstream cluster ID. Empty disables the lookup.")
+	flag.StringVar(&identityResolver.NameLabel, "cluster-name-label", identityResolver.NameLabel,
+		"ClusterConnection label holding the downstream cluster name. Empty disables the lookup.")
+	flag.StringVar(&tracingConfig.Endpoint, "tracing-endpoint", "",
+		"host:port of an OTLP gRPC receiver (e.g. Tempo or Jaeger) to export traces to. "+
+			"Tracing is disabled when empty, unless OTEL_EXPORTER_OTLP_ENDPOINT is set.")
+	flag.BoolVar(&tracingConfig.Insecure, "tracing-insecure", false,
+		"If set, traces are exported without TLS.")
+	flag.Float64Var(&tracingConfig.SampleRatio, "tracing-sample-ratio", 1.0,
+		"Fraction of reconciles that are traced, between 0 and 1.")
+	flag.StringVar(&statusPush.Endpoint, "status-push-endpoint", "",
+		"URL fleet summaries (clusters, usage, backup health, StorageEvents) are POSTed to as JSON. "+
+			"Empty disables pushing. The bearer token is read from the "+report.EnvPushToken+" environment variable.")
+	flag.DurationVar(&statusPush.Interval, "status-push-interval", report.DefaultPushInterval,
+		"Interval between fleet summary pushes.")
+	flag.StringVar(&remoteWrite.URL, "remote-write-url", "",
+		"Prometheus remote_write endpoint (e.g. Mimir or Thanos Receive) the manager's gauges and counters are "+
+			"pushed to, for clusters that cannot be scraped. Empty disables remote write. "+
+			"The bearer token is read from the "+metrics.EnvRemoteWriteToken+" environment variable.")
+	flag.DurationVar(&remoteWrite.Interval, "remote-write-interval", metrics.DefaultRemoteWriteInterval,
+		"Interval between remote writes.")
+	flag.IntVar(&shard.Count, "shard-count", 1,
+		"Number of shards StoragePolicies and BackupPolicies are split into by a hash of their namespace/name. "+
+			"Each shard elects its own leader, so every shard reconciles its policies actively.")
+	flag.IntVar(&shard.Index, "shard-index", -1,
+		"Shard of this replica, from 0 to shard-count-1. Defaults to the SHARD_INDEX environment variable, "+
+			"then to the StatefulSet ordinal of the pod's hostname.")
+	flag.DurationVar(&timeOffset, "time-offset", 0,
+		"Debug: evaluate cooldowns, pauses, dry-run trials and alert suppression as if the clock were shifted "+
+			"by this duration, e.g. 2h to see what the manager will do in two hours. Do not use in production.")
+	flag.StringVar(&faultSpec, "inject-faults", "",
+		"Testing: inject failures to exercise the circuit breaker and cooldowns, e.g. "+
+			"pvc-update=20,kubelet-stats-delay=5s,exec=10 fails 20% of PVC updates, delays kubelet stats "+
+			"by 5s and fails 10% of pod exec commands. Do not use in production.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -91,12 +209,60 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	if tracingConfig.Enabled() {
+		setupLog.Info("OpenTelemetry tracing enabled", "endpoint", tracingConfig.Endpoint,
+			"sampleRatio", tracingConfig.SampleRatio)
+	}
+
 	// Check for DRY_RUN environment variable override
 	if envDryRun := os.Getenv("DRY_RUN"); envDryRun == "true" || envDryRun == "1" {
 		globalDryRun = true
 	}
 
-	if globalDryRun {
+	if !clusterIdentity.IsZero() {
+		metrics.SetClusterInfo("", clusterIdentity.ID, clusterIdentity.Name)
+	}
+
+	if timeOffset != 0 {
+		setupLog.Info("Evaluating policies with a shifted clock, for debugging only", "timeOffset", timeOffset)
+	}
+
+	// The dashboard changes policies and events, so it is never served without authn/authz
+	if enableDashboard && (!secureMetrics || metricsAddr == "0") {
+		setupLog.Error(nil, "--dashboard requires --metrics-secure and a --metrics-bind-address")
+		os.Exit(1)
+	}
+
+	faultConfig, err := faults.Parse(faultSpec)
+	if err != nil {
+		setupLog.Error(err, "invalid --inject-faults")
+		os.Exit(1)
+	}
+	if faultConfig.Enabled() {
+		faultConfig.OnFault = metrics.RecordInjectedFault
+		setupLog.Info("Injecting faults, for testing only", "faults", faultConfig.String())
+	}
+
+	leaderElectionID := "2df84ba7.supporttools.io"
+	if shard.Enabled() {
+		if err := resolveShardIndex(&shard); err != nil {
+			setupLog.Error(err, "unable to determine the shard index")
+			os.Exit(1)
+		}
+		leaderElectionID = fmt.Sprintf("shard-%d.%s", shard.Index, leaderElectionID)
+		metrics.SetShard(shard.Label())
+		setupLog.Info("Sharding enabled", "shard", shard.Index, "shardCount", shard.Count)
+	}
+
+	if readOnly {
+		setupLog.Info("READ-ONLY MODE ENABLED - Clusters are observed and alerted on; no remediation runs, " +
+			"no commands are run in pods and clusters are not annotated")
+	} else if globalDryRun {
 		setupLog.Info("GLOBAL DRY-RUN MODE ENABLED - No actual changes will be made to PVCs or WAL files")
 	}
 
@@ -167,13 +333,20 @@ func main() {
 		metricsServerOptions.KeyName = metricsCertKey
 	}
 
+	// The disk error watch only caches the Events of pods, not every Event of the fleet
+	var cacheOptions cache.Options
+	if watchDiskErrors {
+		cacheOptions.ByObject = map[client.Object]cache.ByObject{&corev1.Event{}: controller.DiskErrorEventCache()}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "2df84ba7.supporttools.io",
+		LeaderElectionID:       leaderElectionID,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -191,17 +364,261 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := (&controller.StoragePolicyReconciler{
-		Client:       mgr.GetClient(),
-		Scheme:       mgr.GetScheme(),
-		RestConfig:   mgr.GetConfig(),
-		GlobalDryRun: globalDryRun,
+	// Read-only mode runs no commands in pods, so no runner is created
+	var commandRunner runner.CommandRunner
+	if !readOnly {
+		commandRunner, err = runner.New(runner.Config{
+			Mode:        runner.Mode(commandRunnerMode),
+			Job:         jobRunnerConfig,
+			ExecTimeout: execTimeout,
+			OnTimeout:   metrics.RecordExecTimeout,
+		}, mgr.GetConfig())
+		if err != nil {
+			setupLog.Error(err, "unable to create command runner")
+			os.Exit(1)
+		}
+		commandRunner = faultConfig.WrapRunner(commandRunner)
+		setupLog.Info("Command runner configured", "mode", commandRunnerMode)
+	}
+
+	var agentCollector *metrics.AgentCollector
+	if agentNamespace != "" {
+		selector, err := labels.Parse(agentSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid agent selector")
+			os.Exit(1)
+		}
+		agentCollector = metrics.NewAgentCollector(mgr.GetClient(), agentNamespace, selector, agentPort)
+		setupLog.Info("Node agent configured", "namespace", agentNamespace, "selector", agentSelector, "port", agentPort)
+	}
+
+	// The cluster inventory watches CNPG clusters through the manager's cache so
+	// controllers do not have to list and parse every cluster on each reconcile
+	inventory := cnpg.NewInventory().WithRESTMapper(mgr.GetRESTMapper())
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return inventory.Start(ctx, mgr.GetCache())
+	})); err != nil {
+		setupLog.Error(err, "unable to add cluster inventory")
+		os.Exit(1)
+	}
+
+	// Backup status is read from barman-cloud ObjectStores through the same cache, and
+	// recovery window changes trigger reconciles of the policies selecting the cluster
+	objectStores := cnpg.NewObjectStoreCache()
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return objectStores.Start(ctx, mgr.GetCache())
+	})); err != nil {
+		setupLog.Error(err, "unable to add ObjectStore cache")
+		os.Exit(1)
+	}
+
+	// psql (archive lag, restore smoke checks) needs a database connection, which only
+	// pod exec provides; Job mode runners only mount the instance's volumes
+	var sqlRunner runner.CommandRunner
+	if commandRunner != nil && runner.Mode(commandRunnerMode) != runner.ModeJob {
+		sqlRunner = commandRunner
+	}
+
+	// ClusterConnections give policies access to CNPG clusters in downstream Kubernetes clusters
+	connections := connection.NewRegistry()
+	if err := (&controller.ClusterConnectionReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		APIReader:   mgr.GetAPIReader(),
+		Connections: connections,
+		Identity:    identityResolver,
 	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterConnection")
+		os.Exit(1)
+	}
+
+	if err := (&controller.ManagerConfigReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ManagerConfig")
+		os.Exit(1)
+	}
+
+	// Alert channel secrets are cached between sends and re-read once their metadata
+	// watch reports a new resourceVersion
+	secretCache := alerting.NewSecretCache(mgr.GetClient(), mgr.GetAPIReader())
+
+	// The PostgreSQL log is read from the postgres container log, which needs only
+	// pods/log, so log hints work with every command runner and in read-only mode
+	logClientset, err := kubernetes.NewForConfig(faultConfig.WrapConfig(mgr.GetConfig()))
+	if err != nil {
+		setupLog.Error(err, "unable to create clientset")
+		os.Exit(1)
+	}
+	postgresLogs := metrics.NewPostgresLogCollector(logClientset)
+
+	var databaseSizes *metrics.DatabaseSizeCollector
+	var writeProbe *metrics.WriteProbe
+	var replicationLag *metrics.ReplicationLagCollector
+	if sqlRunner != nil {
+		databaseSizes = metrics.NewDatabaseSizeCollector(sqlRunner)
+		writeProbe = metrics.NewWriteProbe(sqlRunner)
+		replicationLag = metrics.NewReplicationLagCollector(sqlRunner)
+	}
+	// Missing permissions are reported once at startup, and per policy in its
+	// MissingPermission condition, which holds back the features that need them
+	permissionChecker := permissions.NewChecker(mgr.GetClient())
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		missing, err := permissionChecker.Check(ctx, []string{""},
+			controller.StartupPermissions(readOnly, runner.Mode(commandRunnerMode)))
+		if err != nil {
+			setupLog.Error(err, "unable to check permissions")
+			return nil
+		}
+		for _, m := range missing {
+			setupLog.Info("Missing cluster-wide permission; policies whose namespaces lack it too report it "+
+				"in their MissingPermission condition", "permission", m.String(), "feature", m.Feature)
+		}
+		return nil
+	})); err != nil {
+		setupLog.Error(err, "unable to add permission check")
+		os.Exit(1)
+	}
+
+	// Policies track the clusters they export metrics for, so the metrics of clusters
+	// they no longer match are deleted
+	exportedMetrics := metrics.NewExportedClusters()
+
+	// Both controllers check pauses and dry-run trials against the same, possibly shifted, clock
+	policyClock := clock.WithOffset(timeOffset)
+
+	storagePolicyReconciler := &controller.StoragePolicyReconciler{
+		Client:          faultConfig.WrapClient(mgr.GetClient()),
+		Scheme:          mgr.GetScheme(),
+		RestConfig:      faultConfig.WrapConfig(mgr.GetConfig()),
+		GlobalDryRun:    globalDryRun,
+		ReadOnly:        readOnly,
+		CommandRunner:   commandRunner,
+		AgentCollector:  agentCollector,
+		DatabaseSizes:   databaseSizes,
+		PostgresLogs:    postgresLogs,
+		WriteProbe:      writeProbe,
+		Inventory:       inventory,
+		ObjectStores:    objectStores,
+		Connections:     connections,
+		ClusterIdentity: clusterIdentity,
+		Recorder:        mgr.GetEventRecorderFor(recorder.Component),
+		Secrets:         secretCache,
+		Shard:           shard,
+		Clock:           policyClock,
+		Permissions:     permissionChecker,
+		ExportedMetrics: exportedMetrics,
+		RunnerMode:      runner.Mode(commandRunnerMode),
+		WatchDiskErrors: watchDiskErrors,
+	}
+	if err := storagePolicyReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "StoragePolicy")
 		os.Exit(1)
 	}
+	if err := (&controller.StorageEventReconciler{
+		Client:              faultConfig.WrapClient(mgr.GetClient()),
+		Scheme:              mgr.GetScheme(),
+		RestConfig:          faultConfig.WrapConfig(mgr.GetConfig()),
+		APIReader:           mgr.GetAPIReader(),
+		GlobalDryRun:        globalDryRun,
+		ReadOnly:            readOnly,
+		CommandRunner:       commandRunner,
+		RemediationDeadline: remediationDeadline,
+		RestoreTester:       backup.NewRestoreTester(mgr.GetClient(), mgr.GetAPIReader(), sqlRunner),
+		ReplicationLag:      replicationLag,
+		Recorder:            mgr.GetEventRecorderFor(recorder.Component),
+		Shard:               shard,
+		Hooks:               hooks.NewCaller(secretCache),
+		AgentCollector:      agentCollector,
+		Clock:               policyClock,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "StorageEvent")
+		os.Exit(1)
+	}
+	var archiverCollector *metrics.ArchiverCollector
+	if sqlRunner != nil {
+		archiverCollector = metrics.NewArchiverCollector(sqlRunner)
+	}
+	// Object store probes run Jobs, which read-only mode does not
+	var objectStoreProber *backup.ObjectStoreProber
+	if !readOnly {
+		objectStoreProber = backup.NewObjectStoreProber(mgr.GetClient(), mgr.GetAPIReader())
+	}
+	if err := (&controller.BackupPolicyReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Inventory:         inventory,
+		ObjectStores:      objectStores,
+		ArchiverCollector: archiverCollector,
+		ObjectStoreProber: objectStoreProber,
+		ClusterIdentity:   clusterIdentity,
+		Recorder:          mgr.GetEventRecorderFor(recorder.Component),
+		Secrets:           secretCache,
+		Shard:             shard,
+		ExportedMetrics:   exportedMetrics,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupPolicy")
+		os.Exit(1)
+	}
+
+	// The janitor deletes the metrics of clusters deleted while no reconcile noticed it
+	janitorDiscovery := cnpg.NewDiscovery(mgr.GetClient()).WithInventory(inventory)
+	if err := mgr.Add(manager.RunnableFunc(metrics.NewJanitor(exportedMetrics,
+		func(ctx context.Context) ([]metrics.ClusterRef, error) {
+			clusters, err := janitorDiscovery.ListClusters(ctx, "")
+			if err != nil {
+				return nil, err
+			}
+			refs := make([]metrics.ClusterRef, 0, len(clusters))
+			for _, cluster := range clusters {
+				refs = append(refs, metrics.ClusterRef{Namespace: cluster.Namespace, Name: cluster.Name})
+			}
+			return refs, nil
+		}, metrics.DefaultJanitorInterval).Start)); err != nil {
+		setupLog.Error(err, "unable to add metrics janitor")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	// The fleet-wide storage report is served next to the metrics, behind the same authn/authz
+	if err := mgr.AddMetricsServerExtraHandler("/report", report.Handler(mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to add report endpoint")
+		os.Exit(1)
+	}
+	// Every shard sees all policies, so only the first shard pushes fleet summaries
+	if statusPush.Endpoint != "" && (!shard.Enabled() || shard.Index == 0) {
+		statusPush.Token = os.Getenv(report.EnvPushToken)
+		statusPush.Identity = clusterIdentity
+		if err := mgr.Add(manager.RunnableFunc(report.NewPusher(mgr.GetClient(), statusPush).Start)); err != nil {
+			setupLog.Error(err, "unable to add status pusher")
+			os.Exit(1)
+		}
+		setupLog.Info("Status push configured", "endpoint", statusPush.Endpoint, "interval", statusPush.Interval)
+	}
+	// Each shard writes the series of its own policies, told apart by their shard label
+	if remoteWrite.URL != "" {
+		remoteWrite.Token = os.Getenv(metrics.EnvRemoteWriteToken)
+		remoteWrite.ExternalLabels = clusterIdentity.Labels()
+		if err := mgr.Add(manager.RunnableFunc(metrics.NewRemoteWriter(remoteWrite).Start)); err != nil {
+			setupLog.Error(err, "unable to add remote writer")
+			os.Exit(1)
+		}
+		setupLog.Info("Remote write configured", "url", remoteWrite.URL, "interval", remoteWrite.Interval)
+	}
+	if err := mgr.AddMetricsServerExtraHandler(controller.PreviewPath,
+		storagePolicyReconciler.PreviewHandler()); err != nil {
+		setupLog.Error(err, "unable to add policy preview endpoint")
+		os.Exit(1)
+	}
+	if enableDashboard {
+		if err := mgr.AddMetricsServerExtraHandler(dashboard.Path, dashboard.Handler(mgr.GetClient())); err != nil {
+			setupLog.Error(err, "unable to add dashboard")
+			os.Exit(1)
+		}
+		setupLog.Info("Dashboard enabled", "path", dashboard.Path)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -212,8 +629,40 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
-		setupLog.Error(err, "problem running manager")
+	startErr := mgr.Start(ctrl.SetupSignalHandler())
+
+	// Flush the spans of the last reconciles before exiting
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "unable to flush traces")
+	}
+	cancel()
+
+	if startErr != nil {
+		setupLog.Error(startErr, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// resolveShardIndex fills in the shard index from the SHARD_INDEX environment variable or
+// the StatefulSet ordinal of the hostname when --shard-index is not set, and validates it
+func resolveShardIndex(shard *sharding.Shard) error {
+	if shard.Index < 0 {
+		if env := os.Getenv(sharding.EnvShardIndex); env != "" {
+			index, err := strconv.Atoi(env)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q: %w", sharding.EnvShardIndex, env, err)
+			}
+			shard.Index = index
+		} else {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return err
+			}
+			if shard.Index, err = sharding.IndexFromHostname(hostname); err != nil {
+				return err
+			}
+		}
+	}
+	return shard.Validate()
+}
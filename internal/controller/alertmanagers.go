@@ -0,0 +1,65 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+)
+
+// alertManagers holds the alert manager of each policy, shared by concurrent reconciles.
+// The zero value is ready to use
+type alertManagers struct {
+	mu       sync.Mutex
+	managers map[types.NamespacedName]*alerting.AlertManager
+}
+
+// get returns the alert manager of a policy, creating it with create the first time.
+// created is true when it was just created
+func (m *alertManagers) get(
+	key types.NamespacedName,
+	create func() *alerting.AlertManager,
+) (am *alerting.AlertManager, created bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if am, ok := m.managers[key]; ok {
+		return am, false
+	}
+	if m.managers == nil {
+		m.managers = make(map[types.NamespacedName]*alerting.AlertManager)
+	}
+	am = create()
+	m.managers[key] = am
+	return am, true
+}
+
+// evict drops the alert manager of a deleted policy
+func (m *alertManagers) evict(key types.NamespacedName) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.managers, key)
+}
+
+// len returns the number of policies with an alert manager
+func (m *alertManagers) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.managers)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+)
+
+// conditionAlertChannelsReady reports whether the secrets of a policy's alert channels
+// resolve, their templates parse and the quiet hours and summary are valid
+const conditionAlertChannelsReady = "AlertChannelsReady"
+
+// checkAlertChannels sets the AlertChannelsReady condition from the secrets and
+// templates of the channels of am, and removes it when the policy has no channels
+func checkAlertChannels(
+	ctx context.Context,
+	am *alerting.AlertManager,
+	channels []cnpgv1alpha1.AlertChannel,
+	conditions *[]metav1.Condition,
+	generation int64,
+) {
+	if len(channels) == 0 {
+		meta.RemoveStatusCondition(conditions, conditionAlertChannelsReady)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               conditionAlertChannelsReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "SecretsResolved",
+		Message:            "All alert channel secrets resolve",
+	}
+	if err := am.CheckSecrets(ctx); err != nil {
+		logf.FromContext(ctx).Error(err, "Alert channel is misconfigured")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SecretInvalid"
+		condition.Message = err.Error()
+	} else if err := am.CheckTemplates(); err != nil {
+		logf.FromContext(ctx).Error(err, "Alert channel template is invalid")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "TemplateInvalid"
+		condition.Message = err.Error()
+	} else if err := am.CheckQuietHours(); err != nil {
+		logf.FromContext(ctx).Error(err, "Alert quiet hours are invalid")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "QuietHoursInvalid"
+		condition.Message = err.Error()
+	} else if err := am.CheckSummary(); err != nil {
+		logf.FromContext(ctx).Error(err, "Alert summary is invalid")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SummaryInvalid"
+		condition.Message = err.Error()
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// flushAlertDigest sends the warnings am held during the quiet hours of a policy once
+// they have ended
+func flushAlertDigest(ctx context.Context, am *alerting.AlertManager, policyObj client.Object) {
+	if err := am.FlushDigest(ctx, policyObj.GetName(), policyObj.GetNamespace()); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to send the quiet hours digest")
+	}
+}
+
+// referencesSecret reports whether any of the channels reads secret
+func referencesSecret(channels []cnpgv1alpha1.AlertChannel, secret client.Object) bool {
+	key := types.NamespacedName{Name: secret.GetName(), Namespace: secret.GetNamespace()}
+	for _, ref := range alerting.ChannelSecrets(channels) {
+		if ref == key {
+			return true
+		}
+	}
+	return false
+}
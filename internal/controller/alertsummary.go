@@ -0,0 +1,179 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+)
+
+// sendAlertSummary sends the scheduled summary built by summary once it is due and
+// records the time in last. The first reconcile after the summary is enabled only
+// records the time, and nothing is sent while every cluster is healthy
+func sendAlertSummary(
+	ctx context.Context,
+	am *alerting.AlertManager,
+	cfg *cnpgv1alpha1.AlertSummaryConfig,
+	last **metav1.Time,
+	now time.Time,
+	summary func() *alerting.Alert,
+) {
+	if cfg == nil {
+		*last = nil
+		return
+	}
+	if *last == nil {
+		*last = &metav1.Time{Time: now}
+		return
+	}
+	if !am.SummaryDue((*last).Time) {
+		return
+	}
+
+	if alert := summary(); alert != nil {
+		if err := am.SendAlert(ctx, alert); err != nil {
+			logf.FromContext(ctx).Error(err, "Failed to send the alert summary")
+			return
+		}
+		logf.FromContext(ctx).Info("Alert summary sent", "unhealthyClusters", alert.Details["unhealthy_clusters"])
+	}
+	*last = &metav1.Time{Time: now}
+}
+
+// storagePolicySummary builds the alert summary of a StoragePolicy: a table of its
+// clusters whose storage breached a threshold, failed to evaluate or whose backups are
+// unhealthy, fullest first. It returns nil when there are none
+func storagePolicySummary(policyObj *cnpgv1alpha1.StoragePolicy, now time.Time) *alerting.Alert {
+	clusters := slices.Clone(policyObj.Status.ManagedClusters)
+	slices.SortStableFunc(clusters, func(a, b cnpgv1alpha1.ManagedCluster) int {
+		return cmp.Or(cmp.Compare(b.UsagePercent, a.UsagePercent), compareSummaryEntries(
+			cnpgv1alpha1.ClusterSummaryEntry{Name: a.Name, Namespace: a.Namespace, Connection: a.Connection},
+			cnpgv1alpha1.ClusterSummaryEntry{Name: b.Name, Namespace: b.Namespace, Connection: b.Connection}))
+	})
+
+	var rows [][]string
+	for _, mc := range clusters {
+		storage, storageUnhealthy := summaryStorageState(mc)
+		backup, backupUnhealthy := "-", false
+		if mc.BackupStatus != nil {
+			backup = mc.BackupStatus.BackupHealthStatus
+			if mc.BackupStatus.LastBackupTime != nil {
+				backup = fmt.Sprintf("%s (%dh)", backup, mc.BackupStatus.LastBackupAgeHours)
+			}
+			backupUnhealthy = mc.BackupStatus.BackupHealthStatus != "Healthy"
+		}
+		if !storageUnhealthy && !backupUnhealthy {
+			continue
+		}
+		rows = append(rows, []string{
+			summaryClusterName(mc.Connection, mc.Namespace, mc.Name),
+			storage,
+			fmt.Sprintf("%d%%", mc.UsagePercent),
+			backup,
+			mc.Status,
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("%d of %d clusters of StoragePolicy %s/%s need attention:\n%s",
+		len(rows), len(clusters), policyObj.Namespace, policyObj.Name,
+		alerting.SummaryTable([]string{"CLUSTER", "STORAGE", "USAGE", "BACKUP", "STATUS"}, rows))
+	alert := newPolicyAlert(policyObj, alerting.AlertSeverityWarning, alerting.AlertTypeSummary, message, now)
+	alert.Details["unhealthy_clusters"] = strconv.Itoa(len(rows))
+	return alert
+}
+
+// summaryStorageState describes the StorageHealthy condition of a cluster for the
+// alert summary, and reports whether it breached a threshold or failed to evaluate
+func summaryStorageState(mc cnpgv1alpha1.ManagedCluster) (string, bool) {
+	condition := meta.FindStatusCondition(mc.Conditions, cnpgv1alpha1.ManagedClusterConditionStorageHealthy)
+	switch {
+	case condition == nil:
+		return "Unknown", false
+	case condition.Status == metav1.ConditionTrue:
+		return "OK", false
+	case condition.Status == metav1.ConditionFalse:
+		return strings.TrimSuffix(condition.Reason, "ThresholdExceeded"), true
+	default:
+		return condition.Reason, condition.Reason == policy.ReasonEvaluationFailed
+	}
+}
+
+// backupPolicySummary builds the alert summary of a BackupPolicy: a table of its
+// clusters whose backups are not healthy. It returns nil when there are none
+func backupPolicySummary(policyObj *cnpgv1alpha1.BackupPolicy, now time.Time) *alerting.Alert {
+	var rows [][]string
+	for _, cluster := range policyObj.Status.Clusters {
+		if cluster.Health == cnpgv1alpha1.BackupHealthHealthy {
+			continue
+		}
+		lastBackup := "never"
+		if cluster.LastBackupTime != nil {
+			lastBackup = fmt.Sprintf("%dh ago", int(now.Sub(cluster.LastBackupTime.Time).Hours()))
+		}
+		rows = append(rows, []string{
+			summaryClusterName("", cluster.Namespace, cluster.Name),
+			string(cluster.Health),
+			lastBackup,
+			strings.Join(cluster.Issues, "; "),
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("%d of %d clusters of BackupPolicy %s/%s need attention:\n%s",
+		len(rows), len(policyObj.Status.Clusters), policyObj.Namespace, policyObj.Name,
+		alerting.SummaryTable([]string{"CLUSTER", "HEALTH", "LAST BACKUP", "ISSUES"}, rows))
+	return &alerting.Alert{
+		ClusterName:      policyObj.Name,
+		ClusterNamespace: policyObj.Namespace,
+		Type:             alerting.AlertTypeSummary,
+		Policy:           policyObj.Name,
+		Severity:         alerting.AlertSeverityWarning,
+		Message:          message,
+		Details: map[string]string{
+			"policy":             policyObj.Name,
+			"unhealthy_clusters": strconv.Itoa(len(rows)),
+		},
+		Timestamp: now,
+	}
+}
+
+// summaryClusterName is how the alert summary names a cluster: namespace/name,
+// prefixed with the connection for clusters in downstream Kubernetes clusters
+func summaryClusterName(connection, namespace, name string) string {
+	if connection != "" {
+		return fmt.Sprintf("%s:%s/%s", connection, namespace, name)
+	}
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
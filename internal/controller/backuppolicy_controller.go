@@ -0,0 +1,711 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/annotations"
+	"github.com/supporttools/cnpg-storage-manager/pkg/backup"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/identity"
+	"github.com/supporttools/cnpg-storage-manager/pkg/managerconfig"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+	"github.com/supporttools/cnpg-storage-manager/pkg/sharding"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+const (
+	// BackupPolicyRequeueInterval is how often backup health is re-evaluated
+	BackupPolicyRequeueInterval = 5 * time.Minute
+	// ObjectStoreProbePollInterval is how often a policy is re-evaluated while probe Jobs are running
+	ObjectStoreProbePollInterval = 15 * time.Second
+	// defaultRestoreTestInterval is used when spec.restoreVerification.intervalHours is unset
+	defaultRestoreTestInterval = 168 * time.Hour
+)
+
+// BackupPolicyReconciler reconciles a BackupPolicy object
+type BackupPolicyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// ArchiverCollector queries pg_stat_archiver for archive lag. It is nil when the
+	// command runner cannot reach the database (Job mode), disabling spec.archiveLag
+	ArchiverCollector *metrics.ArchiverCollector
+
+	// ObjectStoreProber validates object store credentials and runs probe Jobs for
+	// spec.objectStoreProbe. Probing is disabled when it is nil
+	ObjectStoreProber *backup.ObjectStoreProber
+
+	// Inventory serves cluster listings from a watch when set, and triggers reconciles
+	// when clusters selected by a policy change
+	Inventory *cnpg.Inventory
+
+	// ObjectStores serves barman-cloud ObjectStore recovery windows from a watch when
+	// set, and triggers reconciles when a selected cluster's backup status changes
+	ObjectStores *cnpg.ObjectStoreCache
+
+	// ClusterIdentity names the manager's own Kubernetes cluster in alerts
+	ClusterIdentity identity.ClusterIdentity
+
+	// Recorder records Kubernetes Events on CNPG clusters. Events are not recorded when nil.
+	Recorder record.EventRecorder
+
+	// Secrets caches alert channel secrets between sends and triggers reconciles when a
+	// referenced Secret changes. Secrets are read on every send when nil.
+	Secrets *alerting.SecretCache
+
+	// Shard limits the reconciler to the policies of this replica's shard. The zero value
+	// reconciles every policy
+	Shard sharding.Shard
+
+	// ExportedMetrics tracks the clusters the policy exports backup metrics for, so the
+	// metrics of clusters it no longer matches are deleted. Metrics are not tracked when nil
+	ExportedMetrics *metrics.ExportedClusters
+
+	// Internal components
+	discovery     *cnpg.Discovery
+	events        *recorder.Recorder
+	alertManagers alertManagers // per-policy alert managers
+}
+
+// RBAC for BackupPolicy management
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=backuppolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=backuppolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=backuppolicies/finalizers,verbs=update
+
+// RBAC for CNPG Backup access (per-method backup history)
+// +kubebuilder:rbac:groups=postgresql.cnpg.io,resources=backups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=postgresql.cnpg.io,resources=scheduledbackups,verbs=get;list;watch
+
+// RBAC for Pod access (pg_stat_archiver via psql exec on the primary)
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+
+// RBAC for scheduling restore tests
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=storageevents,verbs=get;list;watch;create
+
+// RBAC for object store probes (credential Secrets and barman-cloud probe Jobs)
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;create;delete
+
+// Reconcile evaluates the backup health of every cluster matched by a BackupPolicy
+func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	startTime := time.Now()
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		metrics.ReconcileDuration.WithLabelValues("backuppolicy").Observe(duration)
+	}()
+
+	// Policies of other shards are reconciled by the replicas owning them
+	if !r.Shard.Owns(req.Namespace, req.Name) {
+		return ctrl.Result{}, nil
+	}
+
+	var policyObj cnpgv1alpha1.BackupPolicy
+	if err := r.Get(ctx, req.NamespacedName, &policyObj); err != nil {
+		if errors.IsNotFound(err) {
+			// The policy was deleted; its alert manager and metrics are no longer needed
+			r.alertManagers.evict(req.NamespacedName)
+			trackExportedMetrics(ctx, r.ExportedMetrics, backupPolicyOwner(req.Namespace, req.Name), nil)
+			return ctrl.Result{}, nil
+		}
+		metrics.RecordReconcile("backuppolicy", "error", time.Since(startTime).Seconds())
+		return ctrl.Result{}, err
+	}
+
+	r.initComponents()
+
+	// Policies without channels alert through the ManagerConfig default channels.
+	// The defaulted spec is only used in memory and must never be written back.
+	defaults, err := managerconfig.Load(ctx, r.Client)
+	if err != nil {
+		log.Error(err, "Failed to load ManagerConfig")
+		metrics.RecordReconcile("backuppolicy", "error", time.Since(startTime).Seconds())
+		return ctrl.Result{}, err
+	}
+	managerconfig.ApplyAlertingDefaults(&policyObj.Spec.Alerting, defaults)
+
+	evaluator, err := backup.NewEvaluator(policyObj.Spec)
+	if err != nil {
+		// Invalid specs only change with a new generation, so do not requeue
+		log.Error(err, "Invalid BackupPolicy")
+		r.setCondition(&policyObj, metav1.ConditionFalse, "InvalidSchedule", err.Error())
+		policyObj.Status.ObservedGeneration = policyObj.Generation
+		metrics.RecordReconcile("backuppolicy", "error", time.Since(startTime).Seconds())
+		return ctrl.Result{}, r.Status().Update(ctx, &policyObj)
+	}
+
+	clusters, err := matchClusters(ctx, r.discovery, policyObj.Spec.Selector, nil, policyObj.Spec.ExcludeClusters)
+	if !reportCNPGInstalled(&policyObj.Status.Conditions, policyObj.Generation, err) {
+		// The CRD watch reconciles the policy as soon as CloudNativePG is installed
+		log.Info("CloudNativePG CRDs not installed, waiting for them to appear")
+		r.setCondition(&policyObj, metav1.ConditionFalse, conditionCNPGNotInstalled,
+			"Waiting for the CloudNativePG CRDs to be installed")
+		if statusErr := r.Status().Update(ctx, &policyObj); statusErr != nil {
+			log.Error(statusErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: CNPGNotInstalledRequeueInterval}, nil
+	}
+	if err != nil {
+		log.Error(err, "Failed to find matching clusters")
+		r.setCondition(&policyObj, metav1.ConditionFalse, "ClusterDiscoveryFailed", err.Error())
+		if statusErr := r.Status().Update(ctx, &policyObj); statusErr != nil {
+			log.Error(statusErr, "Failed to update status")
+		}
+		metrics.RecordReconcile("backuppolicy", "error", time.Since(startTime).Seconds())
+		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, err
+	}
+
+	trackExportedMetrics(ctx, r.ExportedMetrics, backupPolicyOwner(policyObj.Namespace, policyObj.Name), clusters)
+
+	backupStatuses := r.discovery.GetBackupStatusesForClusters(ctx, clusters)
+	backupsByNamespace := make(map[string][]cnpg.BackupInfo)
+	scheduledByNamespace := make(map[string][]cnpg.ScheduledBackupInfo)
+
+	// Probe results are carried over between reconciles so probes only run once per interval,
+	// and failure counts so alerts only fire after alertAfterFailures consecutive evaluations
+	previousProbes := make(map[string]*cnpgv1alpha1.ObjectStoreProbeStatus)
+	previousFailures := make(map[string]int32)
+	for _, c := range policyObj.Status.Clusters {
+		if c.ObjectStoreProbe != nil {
+			previousProbes[c.Namespace+"/"+c.Name] = c.ObjectStoreProbe
+		}
+		previousFailures[c.Namespace+"/"+c.Name] = c.ConsecutiveFailures
+	}
+
+	now := time.Now()
+	results := make([]cnpgv1alpha1.ClusterBackupHealth, 0, len(clusters))
+	var healthy, unhealthy int32
+	probeRunning := false
+	for _, cluster := range clusters {
+		input := backup.Input{Cluster: cluster, PreviousFailures: previousFailures[cluster.Namespace+"/"+cluster.Name]}
+
+		if cluster.Status.BarmanCloudPlugin != nil && cluster.Status.BarmanCloudPlugin.Enabled {
+			objectStoreStatus, err := backupStatuses.ForCluster(cluster)
+			if err != nil {
+				log.Error(err, "Failed to get ObjectStore backup status, falling back to cluster status",
+					"cluster", cluster.Name, "objectStore", cluster.Status.BarmanCloudPlugin.ObjectStoreName)
+			}
+			input.ObjectStore = objectStoreStatus
+		}
+
+		backups, ok := backupsByNamespace[cluster.Namespace]
+		if !ok {
+			if backups, err = r.discovery.ListBackups(ctx, cluster.Namespace, ""); err != nil {
+				log.Error(err, "Failed to list backups", "namespace", cluster.Namespace)
+			}
+			backupsByNamespace[cluster.Namespace] = backups
+		}
+		for _, b := range backups {
+			if b.ClusterName == cluster.Name {
+				input.Backups = append(input.Backups, b)
+			}
+		}
+
+		scheduled, ok := scheduledByNamespace[cluster.Namespace]
+		if !ok {
+			if scheduled, err = r.discovery.ListScheduledBackups(ctx, cluster.Namespace, ""); err != nil {
+				log.Error(err, "Failed to list scheduled backups", "namespace", cluster.Namespace)
+			}
+			scheduledByNamespace[cluster.Namespace] = scheduled
+		}
+		for _, sb := range scheduled {
+			if sb.ClusterName == cluster.Name {
+				input.ScheduledBackups = append(input.ScheduledBackups, sb)
+			}
+		}
+
+		if policyObj.Spec.ArchiveLag.Enabled && cluster.Status.BackupConfigured {
+			input.Archiver = r.collectArchiverStats(ctx, cluster)
+		}
+
+		if policyObj.Spec.ObjectStoreProbe.Enabled && cluster.Status.BackupConfigured {
+			input.ObjectStoreProbe = r.probeObjectStore(ctx, &policyObj, cluster,
+				previousProbes[cluster.Namespace+"/"+cluster.Name], now)
+			if input.ObjectStoreProbe != nil && input.ObjectStoreProbe.Phase == cnpgv1alpha1.ObjectStoreProbeRunning {
+				probeRunning = true
+			}
+		}
+
+		if policyObj.Spec.RestoreVerification.Enabled && cluster.Status.BackupConfigured {
+			input.LastRestoreTest = r.scheduleRestoreTest(ctx, &policyObj, cluster, now)
+		}
+
+		result := evaluator.Evaluate(input, now)
+		r.recordMetrics(&policyObj, cluster, result)
+		if len(result.Issues) > 0 {
+			unhealthy++
+			if result.Alert {
+				r.sendAlert(ctx, &policyObj, cluster, result)
+			} else {
+				log.V(1).Info("Backup issues below alert threshold", "cluster", cluster.Name,
+					"consecutiveFailures", result.Status.ConsecutiveFailures,
+					"alertAfterFailures", policyObj.Spec.AlertAfterFailures)
+			}
+		} else {
+			healthy++
+			r.resolveAlert(ctx, &policyObj, cluster)
+		}
+		results = append(results, result.Status)
+	}
+
+	policyObj.Status.Clusters = results
+	policyObj.Status.HealthyClusters = healthy
+	policyObj.Status.UnhealthyClusters = unhealthy
+	policyObj.Status.LastEvaluated = &metav1.Time{Time: now}
+	policyObj.Status.ObservedGeneration = policyObj.Generation
+
+	switch {
+	case len(clusters) == 0:
+		r.setCondition(&policyObj, metav1.ConditionTrue, "NoClustersMatched", "No CNPG clusters matched the selector")
+	case unhealthy > 0:
+		r.setCondition(&policyObj, metav1.ConditionFalse, "BackupIssues",
+			fmt.Sprintf("%d of %d clusters have backup issues", unhealthy, len(clusters)))
+	default:
+		r.setCondition(&policyObj, metav1.ConditionTrue, "BackupsHealthy",
+			fmt.Sprintf("All %d clusters have healthy backups", len(clusters)))
+	}
+	flushAlertDigest(ctx, r.getAlertManager(&policyObj), &policyObj)
+	sendAlertSummary(ctx, r.getAlertManager(&policyObj), policyObj.Spec.Alerting.Summary,
+		&policyObj.Status.LastAlertSummary, now, func() *alerting.Alert { return backupPolicySummary(&policyObj, now) })
+	checkAlertChannels(ctx, r.getAlertManager(&policyObj), policyObj.Spec.Alerting.Channels,
+		&policyObj.Status.Conditions, policyObj.Generation)
+
+	if err := r.Status().Update(ctx, &policyObj); err != nil {
+		log.Error(err, "Failed to update status")
+		metrics.RecordReconcile("backuppolicy", "error", time.Since(startTime).Seconds())
+		return ctrl.Result{}, err
+	}
+
+	metrics.RecordReconcile("backuppolicy", "success", time.Since(startTime).Seconds())
+	if probeRunning {
+		return ctrl.Result{RequeueAfter: ObjectStoreProbePollInterval}, nil
+	}
+	return ctrl.Result{RequeueAfter: BackupPolicyRequeueInterval}, nil
+}
+
+// initComponents initializes internal components if not already done
+func (r *BackupPolicyReconciler) initComponents() {
+	if r.discovery == nil {
+		r.discovery = cnpg.NewDiscovery(r.Client).WithInventory(r.Inventory).WithObjectStoreCache(r.ObjectStores)
+	}
+	if r.events == nil && r.Recorder != nil {
+		r.events = recorder.New(r.Recorder, r.Client)
+	}
+}
+
+// collectArchiverStats queries pg_stat_archiver on the cluster's primary and records
+// the archive lag metrics. Failures and a fenced primary leave the lag unknown.
+func (r *BackupPolicyReconciler) collectArchiverStats(
+	ctx context.Context,
+	cluster cnpg.ClusterInfo,
+) *metrics.ArchiverStats {
+	log := logf.FromContext(ctx)
+
+	if r.ArchiverCollector == nil {
+		log.V(1).Info("Archive lag monitoring requires the exec command runner, skipping", "cluster", cluster.Name)
+		return nil
+	}
+
+	primary, err := r.discovery.GetPrimaryPod(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get primary pod for archive lag", "cluster", cluster.Name)
+		return nil
+	}
+	if cluster.IsInstanceFenced(primary.Name) {
+		log.V(1).Info("Primary is fenced, not collecting archive lag", "cluster", cluster.Name, "pod", primary.Name)
+		return nil
+	}
+
+	stats, err := r.ArchiverCollector.Collect(ctx, primary)
+	if err != nil {
+		log.Error(err, "Failed to collect pg_stat_archiver", "cluster", cluster.Name, "pod", primary.Name)
+		return nil
+	}
+	if stats.LagKnown {
+		metrics.RecordWALArchiveLag(cluster.Name, cluster.Namespace, stats.LagSegments, stats.LagSeconds, stats.FailedCount)
+	}
+	return stats
+}
+
+// probeObjectStore probes the object store of a cluster. A missing ObjectStore is a
+// failed probe; other lookup errors keep the previous result.
+func (r *BackupPolicyReconciler) probeObjectStore(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.BackupPolicy,
+	cluster cnpg.ClusterInfo,
+	previous *cnpgv1alpha1.ObjectStoreProbeStatus,
+	now time.Time,
+) *cnpgv1alpha1.ObjectStoreProbeStatus {
+	log := logf.FromContext(ctx)
+
+	if r.ObjectStoreProber == nil {
+		return nil
+	}
+
+	cfg, err := r.discovery.GetObjectStoreConfig(ctx, cluster)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return &cnpgv1alpha1.ObjectStoreProbeStatus{
+				Phase:         cnpgv1alpha1.ObjectStoreProbeFailed,
+				Message:       err.Error(),
+				LastProbeTime: &metav1.Time{Time: now},
+			}
+		}
+		log.Error(err, "Failed to get object store configuration", "cluster", cluster.Name)
+		return previous
+	}
+	if cfg == nil {
+		// Backups do not go to an object store (e.g. volume snapshots only)
+		return nil
+	}
+
+	return r.ObjectStoreProber.Probe(ctx, cluster, cfg, policyObj.Spec.ObjectStoreProbe, previous, now)
+}
+
+// scheduleRestoreTest creates a restore-test StorageEvent for a cluster when none is
+// running and the last one was created more than intervalHours ago. It returns the
+// outcome of the most recent finished restore test.
+func (r *BackupPolicyReconciler) scheduleRestoreTest(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.BackupPolicy,
+	cluster cnpg.ClusterInfo,
+	now time.Time,
+) *cnpgv1alpha1.RestoreTestSummary {
+	log := logf.FromContext(ctx)
+
+	var events cnpgv1alpha1.StorageEventList
+	if err := r.List(ctx, &events,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{
+			remediation.LabelCluster:   cluster.Name,
+			remediation.LabelEventType: string(cnpgv1alpha1.EventTypeRestoreTest),
+		},
+	); err != nil {
+		log.Error(err, "Failed to list restore tests", "cluster", cluster.Name)
+		return nil
+	}
+
+	var newest, lastFinished *cnpgv1alpha1.StorageEvent
+	active := false
+	for i := range events.Items {
+		event := &events.Items[i]
+		if remediation.IsEventActive(event) {
+			active = true
+		}
+		if newest == nil || event.CreationTimestamp.After(newest.CreationTimestamp.Time) {
+			newest = event
+		}
+		// Tests skipped in dry-run mode never restored anything
+		finished := event.Status.Phase == cnpgv1alpha1.EventPhaseFailed ||
+			event.Status.Phase == cnpgv1alpha1.EventPhaseCompleted && event.Status.RestoreTest != nil
+		if finished && event.Status.CompletionTime != nil && (lastFinished == nil ||
+			event.Status.CompletionTime.After(lastFinished.Status.CompletionTime.Time)) {
+			lastFinished = event
+		}
+	}
+
+	interval := time.Duration(policyObj.Spec.RestoreVerification.IntervalHours) * time.Hour
+	if interval <= 0 {
+		interval = defaultRestoreTestInterval
+	}
+	if policyObj.Spec.RestoreVerification.TargetNamespace == "" {
+		// Policies created before the target namespace was required would otherwise
+		// restore next to the production cluster
+		log.Info("Restore verification has no target namespace, not scheduling restore tests",
+			"policy", policyObj.Name, "cluster", cluster.Name)
+	} else if !active && (newest == nil || now.Sub(newest.CreationTimestamp.Time) >= interval) {
+		event := remediation.NewRestoreTestEvent(policyObj, cluster.Name, cluster.Namespace)
+		owner := annotations.Ownership(cluster.Annotations, cnpgv1alpha1.OwnershipMetadata{})
+		if owner != (cnpgv1alpha1.OwnershipMetadata{}) {
+			event.Spec.Ownership = &owner
+		}
+		if err := r.Create(ctx, event); err != nil {
+			log.Error(err, "Failed to create restore test", "cluster", cluster.Name)
+		} else {
+			log.Info("Restore test scheduled", "cluster", cluster.Name, "event", event.Name,
+				"targetNamespace", event.Spec.RestoreTest.TargetNamespace)
+		}
+	}
+
+	if lastFinished == nil {
+		return nil
+	}
+	return &cnpgv1alpha1.RestoreTestSummary{
+		Event:          lastFinished.Name,
+		Succeeded:      lastFinished.Status.Phase == cnpgv1alpha1.EventPhaseCompleted,
+		CompletionTime: lastFinished.Status.CompletionTime,
+		Message:        lastFinished.Status.Message,
+	}
+}
+
+// recordMetrics exports the backup health of a cluster
+func (r *BackupPolicyReconciler) recordMetrics(
+	policyObj *cnpgv1alpha1.BackupPolicy,
+	cluster cnpg.ClusterInfo,
+	result backup.Result,
+) {
+	status := result.Status
+
+	var lastBackup, firstRecoverability *float64
+	if status.LastBackupTime != nil {
+		ts := float64(status.LastBackupTime.Unix())
+		lastBackup = &ts
+		metrics.RecordBackupAge(cluster.Name, cluster.Namespace, status.LastChecked.Sub(status.LastBackupTime.Time).Hours())
+	}
+	if status.FirstRecoverabilityPoint != nil {
+		ts := float64(status.FirstRecoverabilityPoint.Unix())
+		firstRecoverability = &ts
+		metrics.RecordFirstRecoverabilityAge(cluster.Name, cluster.Namespace,
+			status.LastChecked.Sub(status.FirstRecoverabilityPoint.Time).Hours())
+	}
+
+	metrics.RecordBackupMetrics(cluster.Name, cluster.Namespace, lastBackup, firstRecoverability,
+		status.ContinuousArchivingWorking, cluster.Status.BackupConfigured, len(result.Issues) == 0)
+	for _, method := range status.Methods {
+		var last, first *time.Time
+		if method.LastBackupTime != nil {
+			last = &method.LastBackupTime.Time
+		}
+		if method.FirstRecoverabilityPoint != nil {
+			first = &method.FirstRecoverabilityPoint.Time
+		}
+		metrics.RecordBackupMethodTimes(cluster.Name, cluster.Namespace, string(method.Method), last, first,
+			status.LastChecked.Time)
+	}
+
+	if status.RecoveryWindowCompliant != nil {
+		metrics.RecordRecoveryWindowCompliance(cluster.Name, cluster.Namespace,
+			float64(policyObj.Spec.MinRecoveryWindowDays*24), *status.RecoveryWindowCompliant)
+	} else {
+		metrics.DeleteRecoveryWindowCompliance(cluster.Name, cluster.Namespace)
+	}
+
+	for _, issue := range result.Issues {
+		metrics.RecordBackupAlert(cluster.Name, cluster.Namespace, string(issue.Type))
+	}
+}
+
+// sendAlert records a BackupUnhealthy event and sends a backup alert for a cluster with failed checks
+func (r *BackupPolicyReconciler) sendAlert(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.BackupPolicy,
+	cluster cnpg.ClusterInfo,
+	result backup.Result,
+) {
+	log := logf.FromContext(ctx)
+
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonBackupUnhealthy,
+		"Backup issues (%s): %s", result.Status.Health, strings.Join(result.Status.Issues, "; "))
+
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		log.V(1).Info("No alert channels configured, skipping backup alert", "cluster", cluster.Name)
+		return
+	}
+
+	am := r.getAlertManager(policyObj)
+
+	severity := alerting.AlertSeverityWarning
+	if result.Status.Health == cnpgv1alpha1.BackupHealthCritical {
+		severity = alerting.AlertSeverityCritical
+	}
+
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeBackup,
+		Policy:           policyObj.Name,
+		Ownership:        annotations.Ownership(cluster.Annotations, cnpgv1alpha1.OwnershipMetadata{}),
+		Severity:         severity,
+		Message: fmt.Sprintf("Backup issues for cluster %s/%s: %s",
+			cluster.Namespace, cluster.Name, strings.Join(result.Status.Issues, "; ")),
+		Details: map[string]string{
+			"backup_policy": policyObj.Name,
+			"issue_count":   fmt.Sprintf("%d", len(result.Issues)),
+		},
+		Timestamp:  time.Now(),
+		LastBackup: cluster.Status.LastSuccessfulBackup,
+	}
+	for i, issue := range result.Issues {
+		alert.Details[fmt.Sprintf("issue_%d", i+1)] = issue.Message
+	}
+
+	if err := am.SendAlert(ctx, alert); err != nil {
+		log.Error(err, "Failed to send backup alert", "cluster", cluster.Name, "severity", severity)
+	}
+}
+
+// resolveAlert reports that the backup issues of a cluster have cleared, so ticketing
+// channels close the ticket they opened for them
+func (r *BackupPolicyReconciler) resolveAlert(ctx context.Context, policyObj *cnpgv1alpha1.BackupPolicy,
+	cluster cnpg.ClusterInfo) {
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeBackup,
+	}
+	if err := r.getAlertManager(policyObj).ResolveAlert(ctx, alert); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to resolve backup alert", "cluster", cluster.Name)
+	}
+}
+
+// getAlertManager gets or creates an alert manager for the given policy
+func (r *BackupPolicyReconciler) getAlertManager(policyObj *cnpgv1alpha1.BackupPolicy) *alerting.AlertManager {
+	key := types.NamespacedName{Namespace: policyObj.Namespace, Name: policyObj.Name}
+	am, created := r.alertManagers.get(key, func() *alerting.AlertManager {
+		am := alerting.NewAlertManager(r.Client, policyObj.Spec.Alerting.Channels)
+		am.SetSource(r.ClusterIdentity)
+		am.SetSecretCache(r.Secrets)
+		return am
+	})
+	if !created {
+		am.UpdateChannels(policyObj.Spec.Alerting.Channels)
+	}
+	am.SetQuietHours(policyObj.Spec.Alerting.QuietHours)
+	am.SetSummary(policyObj.Spec.Alerting.Summary)
+	am.SetStaticLabels(policyObj.Spec.Alerting.Labels, policyObj.Spec.Alerting.Annotations)
+	return am
+}
+
+// setCondition sets the Ready condition on the BackupPolicy status
+func (r *BackupPolicyReconciler) setCondition(
+	policyObj *cnpgv1alpha1.BackupPolicy,
+	status metav1.ConditionStatus,
+	reason, message string,
+) {
+	meta.SetStatusCondition(&policyObj.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             status,
+		ObservedGeneration: policyObj.Generation,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&cnpgv1alpha1.BackupPolicy{}).
+		Named("backuppolicy")
+	// Policies waiting for CloudNativePG recover as soon as its CRDs are installed
+	b = watchCNPGCRD(b, r.allPolicies)
+	if r.Inventory != nil {
+		// Reconcile policies as soon as a cluster they select appears, disappears or is relabeled
+		b = b.WatchesRawSource(source.Channel(watchInventory(r.Inventory),
+			handler.EnqueueRequestsFromMapFunc(r.policiesForCluster)))
+	}
+	if r.ObjectStores != nil {
+		// Re-check backup health as soon as a selected cluster's recovery window changes
+		b = b.WatchesRawSource(source.Channel(watchObjectStores(r.ObjectStores, r.Inventory),
+			handler.EnqueueRequestsFromMapFunc(r.policiesForCluster)))
+	}
+	if r.Secrets != nil {
+		// Re-check alert channels as soon as a secret they read is rotated, created or deleted
+		b = b.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.policiesForSecret),
+			builder.OnlyMetadata)
+	}
+	return b.Complete(tracing.Reconciler("BackupPolicy", r))
+}
+
+// policiesForSecret maps a Secret to the policies whose alert channels read it
+func (r *BackupPolicyReconciler) policiesForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	var policies cnpgv1alpha1.BackupPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list backuppolicies for secret change", "secret", secret.GetName())
+		return nil
+	}
+	defaults, err := managerconfig.Load(ctx, r.Client)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to load ManagerConfig for secret change")
+	}
+
+	var requests []reconcile.Request
+	for _, p := range policies.Items {
+		managerconfig.ApplyAlertingDefaults(&p.Spec.Alerting, defaults)
+		if referencesSecret(p.Spec.Alerting.Channels, secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: p.Name, Namespace: p.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// allPolicies maps an object to every BackupPolicy
+func (r *BackupPolicyReconciler) allPolicies(ctx context.Context, obj client.Object) []reconcile.Request {
+	var policies cnpgv1alpha1.BackupPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list backuppolicies", "trigger", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(policies.Items))
+	for _, p := range policies.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: p.Name, Namespace: p.Namespace},
+		})
+	}
+	return requests
+}
+
+// policiesForCluster maps a CNPG cluster to the policies selecting it
+func (r *BackupPolicyReconciler) policiesForCluster(ctx context.Context, cluster client.Object) []reconcile.Request {
+	var policies cnpgv1alpha1.BackupPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list backuppolicies for cluster change", "cluster", cluster.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, p := range policies.Items {
+		if selectsCluster(p.Spec.Selector, nil, p.Spec.ExcludeClusters, cluster) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: p.Name, Namespace: p.Namespace},
+			})
+		}
+	}
+	return requests
+}
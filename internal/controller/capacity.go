@@ -0,0 +1,128 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// checkBackendCapacity plans the expansion of a cluster target and compares the growth
+// with the CSIStorageCapacity published for the PVCs' storage class and topology. It
+// returns a *remediation.CapacityShortfall when the backend cannot hold the expansion,
+// so it is skipped instead of leaving the resize hanging. A failed check does not block
+// the expansion
+func (r *StoragePolicyReconciler) checkBackendCapacity(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	target cnpgv1alpha1.ExpansionTarget,
+) error {
+	if r.capacityChecker == nil || r.expansionEngine == nil {
+		return nil
+	}
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name, "tablespace", target.Tablespace,
+		"volume", target.Volume)
+
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to list PVCs for the backend capacity check")
+		return nil
+	}
+	plan := r.expansionEngine.PlanClusterExpansion(ctx, &remediation.ExpansionRequest{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		PVCs:             pvcs,
+		Policy:           policyObj,
+		DryRun:           true,
+		Target:           target,
+	})
+	shortfall, err := r.capacityChecker.CheckExpansion(ctx, pvcs, plan)
+	if err != nil {
+		log.Error(err, "Failed to check backend capacity, expanding without it")
+		return nil
+	}
+	if shortfall == nil {
+		return nil
+	}
+
+	log.Info("Skipping expansion, insufficient backend capacity", "storageClass", shortfall.StorageClass,
+		"required", shortfall.RequiredBytes, "available", shortfall.AvailableBytes, "pvcs", shortfall.PVCs)
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonInsufficientCapacity,
+		"Expansion skipped: %s", shortfall.Error())
+	r.alertInsufficientCapacity(ctx, policyObj, cluster, shortfall)
+	return shortfall
+}
+
+// isCapacityShortfall returns true if err reports an expansion skipped for lack of
+// backend capacity
+func isCapacityShortfall(err error) bool {
+	var shortfall *remediation.CapacityShortfall
+	return errors.As(err, &shortfall)
+}
+
+// alertInsufficientCapacity sends a warning alert with the capacity the storage backend
+// is missing for an expansion
+func (r *StoragePolicyReconciler) alertInsufficientCapacity(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	shortfall *remediation.CapacityShortfall,
+) {
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	details := map[string]string{
+		"policy":        policyObj.Name,
+		"storage_class": shortfall.StorageClass,
+		"pvcs":          strings.Join(shortfall.PVCs, ","),
+		"required":      remediation.FormatBytes(shortfall.RequiredBytes),
+	}
+	if shortfall.MaximumVolumeSize > 0 {
+		details["maximum_volume_size"] = remediation.FormatBytes(shortfall.MaximumVolumeSize)
+	} else {
+		details["available"] = remediation.FormatBytes(shortfall.AvailableBytes)
+		details["shortfall"] = remediation.FormatBytes(shortfall.ShortBytes())
+	}
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeInsufficientCapacity,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
+		Severity:         alerting.AlertSeverityWarning,
+		Message: fmt.Sprintf("Expansion of cluster %s/%s skipped: %s",
+			cluster.Namespace, cluster.Name, shortfall.Error()),
+		Details:   details,
+		Timestamp: time.Now(),
+	}
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to send insufficient capacity alert", "cluster", cluster.Name)
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/connection"
+	"github.com/supporttools/cnpg-storage-manager/pkg/identity"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+const (
+	// connectionCheckInterval is how often a connected downstream cluster is re-checked
+	// and the kubeconfig Secret re-read
+	connectionCheckInterval = 5 * time.Minute
+
+	// connectionRetryInterval is how often a failed connection is retried
+	connectionRetryInterval = time.Minute
+
+	// defaultKubeconfigKey is the Secret key read when kubeconfigSecretRef.key is empty
+	defaultKubeconfigKey = "kubeconfig"
+)
+
+// ClusterConnectionReconciler maintains the clients for downstream Kubernetes clusters
+// in the shared connection registry
+type ClusterConnectionReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// APIReader reads kubeconfig Secrets without caching every Secret in the cluster
+	APIReader client.Reader
+
+	// Connections is the registry the policy controllers read connections from
+	Connections *connection.Registry
+
+	// Identity resolves the identity of downstream clusters from ClusterConnection labels
+	Identity identity.Resolver
+}
+
+// RBAC for ClusterConnection management
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=clusterconnections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=clusterconnections/status,verbs=get;update;patch
+
+// Reconcile builds the clients of a ClusterConnection from its kubeconfig, verifies the
+// downstream cluster is reachable and registers the connection for the policy controllers.
+func (r *ClusterConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	startTime := time.Now()
+
+	var conn cnpgv1alpha1.ClusterConnection
+	if err := r.Get(ctx, req.NamespacedName, &conn); err != nil {
+		if errors.IsNotFound(err) {
+			r.Connections.Remove(req.Name, req.Namespace)
+			metrics.DeleteClusterConnectionMetrics(req.Name, req.Namespace)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if conn.Spec.Suspend {
+		r.Connections.Remove(conn.Name, conn.Namespace)
+		metrics.DeleteClusterConnectionMetrics(conn.Name, conn.Namespace)
+		conn.Status.Phase = cnpgv1alpha1.ClusterConnectionSuspended
+		conn.Status.Message = "Connection is suspended"
+		r.setReadyCondition(&conn, metav1.ConditionFalse, "Suspended", conn.Status.Message)
+		return ctrl.Result{}, r.Status().Update(ctx, &conn)
+	}
+
+	active, err := r.connect(ctx, &conn)
+	if err != nil {
+		log.Error(err, "Failed to connect to downstream cluster", "connection", conn.Name)
+		r.Connections.Remove(conn.Name, conn.Namespace)
+		metrics.SetClusterConnectionUp(conn.Name, conn.Namespace, false)
+		metrics.RecordReconcile("clusterconnection", "error", time.Since(startTime).Seconds())
+
+		conn.Status.Phase = cnpgv1alpha1.ClusterConnectionFailed
+		conn.Status.Message = err.Error()
+		r.setReadyCondition(&conn, metav1.ConditionFalse, "ConnectionFailed", err.Error())
+		if statusErr := r.Status().Update(ctx, &conn); statusErr != nil {
+			return ctrl.Result{}, statusErr
+		}
+		return ctrl.Result{RequeueAfter: connectionRetryInterval}, nil
+	}
+
+	clusters, err := active.Discovery.ListClusters(ctx, "")
+	if err != nil {
+		log.Error(err, "Downstream cluster is unreachable", "connection", conn.Name, "server", active.Server)
+		r.Connections.Remove(conn.Name, conn.Namespace)
+		metrics.SetClusterConnectionUp(conn.Name, conn.Namespace, false)
+		metrics.RecordReconcile("clusterconnection", "error", time.Since(startTime).Seconds())
+
+		conn.Status.Phase = cnpgv1alpha1.ClusterConnectionFailed
+		conn.Status.Server = active.Server
+		conn.Status.Message = fmt.Sprintf("Failed to list CNPG clusters: %v", err)
+		r.setReadyCondition(&conn, metav1.ConditionFalse, "Unreachable", conn.Status.Message)
+		if statusErr := r.Status().Update(ctx, &conn); statusErr != nil {
+			return ctrl.Result{}, statusErr
+		}
+		return ctrl.Result{RequeueAfter: connectionRetryInterval}, nil
+	}
+
+	active.Identity = r.Identity.ForConnection(conn.Name, identity.ClusterIdentity{
+		ID:   conn.Spec.ClusterID,
+		Name: conn.Spec.ClusterName,
+	}, conn.Labels)
+	r.Connections.Set(active)
+	metrics.SetClusterConnectionUp(conn.Name, conn.Namespace, true)
+	metrics.SetClusterInfo(conn.Name, active.Identity.ID, active.Identity.Name)
+
+	now := metav1.Now()
+	conn.Status.Phase = cnpgv1alpha1.ClusterConnectionConnected
+	conn.Status.Server = active.Server
+	conn.Status.ClusterID = active.Identity.ID
+	conn.Status.ClusterName = active.Identity.Name
+	conn.Status.ClusterCount = int32(len(clusters))
+	conn.Status.LastConnected = &now
+	conn.Status.Message = fmt.Sprintf("Found %d CNPG clusters", len(clusters))
+	r.setReadyCondition(&conn, metav1.ConditionTrue, "Connected", conn.Status.Message)
+	if err := r.Status().Update(ctx, &conn); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	metrics.RecordReconcile("clusterconnection", "success", time.Since(startTime).Seconds())
+	return ctrl.Result{RequeueAfter: connectionCheckInterval}, nil
+}
+
+// connect returns the registered connection when the kubeconfig Secret is unchanged,
+// and builds new clients otherwise
+func (r *ClusterConnectionReconciler) connect(
+	ctx context.Context,
+	conn *cnpgv1alpha1.ClusterConnection,
+) (*connection.Connection, error) {
+	ref := conn.Spec.KubeconfigSecretRef
+	var secret corev1.Secret
+	if err := r.APIReader.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: conn.Namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret %s: %w", ref.Name, err)
+	}
+
+	key := ref.Key
+	if key == "" {
+		key = defaultKubeconfigKey
+	}
+	kubeconfig, ok := secret.Data[key]
+	if !ok || len(kubeconfig) == 0 {
+		return nil, fmt.Errorf("secret %s has no %q key", ref.Name, key)
+	}
+
+	if existing := r.Connections.Get(conn.Name, conn.Namespace); existing != nil &&
+		existing.SecretVersion == secret.ResourceVersion {
+		// Copied so policy controllers never see the identity change under them
+		reused := *existing
+		return &reused, nil
+	}
+
+	active, err := connection.New(conn.Name, conn.Namespace, kubeconfig, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	active.SecretVersion = secret.ResourceVersion
+	return active, nil
+}
+
+// setReadyCondition sets the Ready condition of a ClusterConnection
+func (r *ClusterConnectionReconciler) setReadyCondition(
+	conn *cnpgv1alpha1.ClusterConnection,
+	status metav1.ConditionStatus,
+	reason, message string,
+) {
+	conn.Status.ObservedGeneration = conn.Generation
+	meta.SetStatusCondition(&conn.Status.Conditions, metav1.Condition{
+		Type:               cnpgv1alpha1.ClusterConnectionConditionReady,
+		Status:             status,
+		ObservedGeneration: conn.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cnpgv1alpha1.ClusterConnection{}).
+		Named("clusterconnection").
+		Complete(tracing.Reconciler("ClusterConnection", r))
+}
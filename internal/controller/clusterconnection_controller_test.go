@@ -0,0 +1,112 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/connection"
+)
+
+var _ = Describe("ClusterConnection Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-connection"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		AfterEach(func() {
+			resource := &cnpgv1alpha1.ClusterConnection{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			if errors.IsNotFound(err) {
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance ClusterConnection")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should remove a deleted connection from the registry", func() {
+			registry := connection.NewRegistry()
+			registry.Set(&connection.Connection{Name: resourceName, Namespace: "default"})
+
+			controllerReconciler := &ClusterConnectionReconciler{
+				Client:      k8sClient,
+				Scheme:      k8sClient.Scheme(),
+				APIReader:   k8sClient,
+				Connections: registry,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registry.Get(resourceName, "default")).To(BeNil())
+		})
+
+		It("should mark a connection with a missing kubeconfig secret as failed", func() {
+			By("creating a ClusterConnection referencing a missing Secret")
+			resource := &cnpgv1alpha1.ClusterConnection{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: cnpgv1alpha1.ClusterConnectionSpec{
+					KubeconfigSecretRef: cnpgv1alpha1.KubeconfigSecretReference{Name: "missing-kubeconfig"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+			registry := connection.NewRegistry()
+			controllerReconciler := &ClusterConnectionReconciler{
+				Client:      k8sClient,
+				Scheme:      k8sClient.Scheme(),
+				APIReader:   k8sClient,
+				Connections: registry,
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(connectionRetryInterval))
+			Expect(registry.Get(resourceName, "default")).To(BeNil())
+
+			updated := &cnpgv1alpha1.ClusterConnection{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal(cnpgv1alpha1.ClusterConnectionFailed))
+			condition := meta.FindStatusCondition(updated.Status.Conditions, cnpgv1alpha1.ClusterConnectionConditionReady)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("ConnectionFailed"))
+		})
+	})
+})
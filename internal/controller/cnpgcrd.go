@@ -0,0 +1,82 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+const (
+	// conditionCNPGNotInstalled is True while the CloudNativePG CRDs are missing from the cluster
+	conditionCNPGNotInstalled = "CNPGNotInstalled"
+
+	// CNPGNotInstalledRequeueInterval is how often a policy is re-checked while the
+	// CloudNativePG CRDs are missing. The CRD watch reconciles it as soon as they appear.
+	CNPGNotInstalledRequeueInterval = 5 * time.Minute
+)
+
+// customResourceDefinitionGVK is watched metadata-only, so the apiextensions types do
+// not need to be registered in the scheme
+var customResourceDefinitionGVK = schema.GroupVersionKind{
+	Group:   "apiextensions.k8s.io",
+	Version: "v1",
+	Kind:    "CustomResourceDefinition",
+}
+
+// reportCNPGInstalled sets the CNPGNotInstalled condition from the error of listing CNPG
+// clusters and reports whether the CRDs are installed
+func reportCNPGInstalled(conditions *[]metav1.Condition, generation int64, listErr error) bool {
+	condition := metav1.Condition{
+		Type:               conditionCNPGNotInstalled,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "CNPGInstalled",
+		Message:            "The CloudNativePG CRDs are installed",
+	}
+	installed := !meta.IsNoMatchError(listErr)
+	if !installed {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "CRDNotFound"
+		condition.Message = "The " + cnpg.CNPGClusterCRDName +
+			" CRD is not installed; install CloudNativePG for clusters to be managed"
+	}
+	meta.SetStatusCondition(conditions, condition)
+	return installed
+}
+
+// watchCNPGCRD reconciles the policies returned by mapFn whenever the CNPG Cluster
+// CRD is created, established or removed, so policies recover without waiting for
+// their next requeue
+func watchCNPGCRD(b *builder.Builder, mapFn handler.MapFunc) *builder.Builder {
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(customResourceDefinitionGVK)
+	return b.Watches(crd, handler.EnqueueRequestsFromMapFunc(mapFn),
+		builder.OnlyMetadata,
+		builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetName() == cnpg.CNPGClusterCRDName
+		})))
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+const (
+	// diskFullMessage is the error Postgres and the kubelet report when a volume is out
+	// of space, matched case-insensitively in Event messages
+	diskFullMessage = "no space left on device"
+
+	// diskErrorDebounce is how long further disk errors of a cluster are ignored after
+	// one triggered an evaluation, so a crash-looping instance does not flood the queue
+	diskErrorDebounce = 30 * time.Second
+
+	// diskErrorMaxAge is how old a disk error may be and still trigger an evaluation.
+	// Older Events, such as those listed when the watch starts, are left to the
+	// periodic evaluation
+	diskErrorMaxAge = 5 * time.Minute
+)
+
+// diskErrors remembers when each cluster was last evaluated for a disk error
+type diskErrors struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]time.Time
+}
+
+// trigger returns true when a disk error of the cluster at now should trigger an
+// evaluation, false while an earlier one is being debounced
+func (d *diskErrors) trigger(cluster types.NamespacedName, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if last, ok := d.seen[cluster]; ok && now.Sub(last) < diskErrorDebounce {
+		return false
+	}
+	if d.seen == nil {
+		d.seen = make(map[types.NamespacedName]time.Time)
+	}
+	for key, last := range d.seen {
+		if now.Sub(last) >= diskErrorDebounce {
+			delete(d.seen, key)
+		}
+	}
+	d.seen[cluster] = now
+	return true
+}
+
+// DiskErrorEventCache restricts the cached Events to those of pods, the only ones the
+// disk error watch maps to clusters, so the manager does not hold every Event of every
+// namespace in memory
+func DiskErrorEventCache() cache.ByObject {
+	return cache.ByObject{Field: fields.OneTermEqualSelector("involvedObject.kind", "Pod")}
+}
+
+// isDiskFullEvent returns true for Events reporting that a pod ran out of space
+func isDiskFullEvent(e *corev1.Event) bool {
+	return e.InvolvedObject.Kind == "Pod" && strings.Contains(strings.ToLower(e.Message), diskFullMessage)
+}
+
+// diskErrorPredicate passes Events reporting disk full errors. Updates pass too, as a
+// repeated error bumps the count of the existing Event
+func diskErrorPredicate() predicate.Funcs {
+	passes := func(obj client.Object) bool {
+		e, ok := obj.(*corev1.Event)
+		return ok && isDiskFullEvent(e)
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return passes(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return passes(e.ObjectNew) },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// eventTime returns when an Event was last observed
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// policiesForDiskError maps an Event reporting a disk full error to the policies
+// selecting the CNPG cluster of the pod it involves, so the cluster is evaluated right
+// away instead of at the next periodic reconcile
+func (r *StoragePolicyReconciler) policiesForDiskError(ctx context.Context, obj client.Object) []reconcile.Request {
+	e, ok := obj.(*corev1.Event)
+	if !ok {
+		return nil
+	}
+	now := r.now()
+	if now.Sub(eventTime(e)) > diskErrorMaxAge {
+		return nil
+	}
+
+	log := logf.FromContext(ctx)
+	podKey := types.NamespacedName{Name: e.InvolvedObject.Name, Namespace: e.InvolvedObject.Namespace}
+	var pod corev1.Pod
+	if err := r.Get(ctx, podKey, &pod); err != nil {
+		log.V(1).Info("Ignoring disk error of an unknown pod", "pod", podKey.String(), "error", err.Error())
+		return nil
+	}
+	cluster := types.NamespacedName{Name: pod.Labels["cnpg.io/cluster"], Namespace: pod.Namespace}
+	if cluster.Name == "" {
+		return nil
+	}
+	if !r.diskErrors.trigger(cluster, now) {
+		return nil
+	}
+
+	// Label selectors need the cluster's labels
+	info := cnpg.ClusterInfo{Name: cluster.Name, Namespace: cluster.Namespace}
+	if r.Inventory != nil {
+		if known, ok := r.Inventory.Get(cluster.Namespace, cluster.Name); ok {
+			info = known
+		}
+	} else if known, err := cnpg.NewDiscovery(r.Client).GetCluster(ctx, cluster.Name, cluster.Namespace); err == nil {
+		info = *known
+	}
+	log.Info("Disk full error reported, evaluating cluster now", "cluster", cluster.String(),
+		"pod", pod.Name, "reason", e.Reason)
+	return r.policiesForCluster(ctx, clusterObject(r.Inventory.ClusterAPI(), info))
+}
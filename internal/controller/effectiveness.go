@@ -0,0 +1,193 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// startEffectiveness records the usage of the volumes an event remediates before it
+// starts. The measurement is Unknown when they cannot be measured
+func (r *StorageEventReconciler) startEffectiveness(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) {
+	used, capacity, err := r.remediatedUsage(ctx, event, policyObj)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to measure usage before remediation", "event", event.Name)
+		event.Status.Effectiveness = &cnpgv1alpha1.RemediationEffectiveness{}
+		remediation.FailEffectiveness(event.Status.Effectiveness,
+			"Usage before the remediation could not be measured: "+err.Error(), time.Now())
+		return
+	}
+	event.Status.Effectiveness = remediation.StartEffectiveness(used, capacity)
+}
+
+// reconcileEffectiveness measures the volumes of a completed event again once the
+// policy's effectiveness delay passed, and records whether usage dropped
+func (r *StorageEventReconciler) reconcileEffectiveness(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var policyObj cnpgv1alpha1.StoragePolicy
+	policyKey := client.ObjectKey{Name: event.Spec.PolicyRef.Name, Namespace: event.Spec.PolicyRef.Namespace}
+	if err := r.Get(ctx, policyKey, &policyObj); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		remediation.FailEffectiveness(event.Status.Effectiveness,
+			fmt.Sprintf("StoragePolicy %s/%s no longer exists", policyKey.Namespace, policyKey.Name), time.Now())
+		return ctrl.Result{}, r.Status().Update(ctx, event)
+	}
+
+	if event.Status.CompletionTime != nil {
+		due := event.Status.CompletionTime.Add(remediation.EffectivenessDelay(&policyObj))
+		if remaining := time.Until(due); remaining > 0 {
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+	}
+
+	effectiveness := event.Status.Effectiveness
+	used, capacity, err := r.remediatedUsage(ctx, event, &policyObj)
+	if err != nil {
+		log.Error(err, "Failed to measure usage after remediation", "event", event.Name)
+		remediation.FailEffectiveness(effectiveness,
+			"Usage after the remediation could not be measured: "+err.Error(), time.Now())
+		return ctrl.Result{}, r.Status().Update(ctx, event)
+	}
+	remediation.CompleteEffectiveness(effectiveness, used, capacity, time.Now())
+	if err := r.Status().Update(ctx, event); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	cluster, namespace := event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace
+	metrics.RecordRemediationEffectiveness(cluster, namespace, string(event.Spec.EventType),
+		string(effectiveness.Result), effectiveness.BytesFreed)
+	log.Info("Measured remediation effectiveness", "event", event.Name, "result", effectiveness.Result,
+		"usagePercentBefore", effectiveness.UsagePercentBefore, "usagePercentAfter", effectiveness.UsagePercentAfter,
+		"bytesFreed", effectiveness.BytesFreed)
+	if effectiveness.Result == cnpgv1alpha1.EffectivenessIneffective {
+		r.events.ClusterByName(ctx, cluster, namespace, corev1.EventTypeWarning, recorder.ReasonRemediationIneffective,
+			"%s (StorageEvent %s) did not reduce usage: %s", event.Spec.EventType, event.Name, effectiveness.Message)
+	}
+	return ctrl.Result{}, nil
+}
+
+// remediatedUsage collects the metrics of an event's cluster and returns the used and
+// capacity bytes of the volumes the event remediates
+func (r *StorageEventReconciler) remediatedUsage(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) (int64, int64, error) {
+	if r.metricsCollector == nil {
+		return 0, 0, fmt.Errorf("no metrics collector is configured")
+	}
+	cluster, namespace := event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace
+	pods, err := r.discovery.GetClusterPods(ctx, cluster, namespace)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get cluster pods: %w", err)
+	}
+	clusterMetrics, err := r.metricsCollector.CollectClusterMetricsFrom(ctx, metrics.Source(policyObj.Spec.MetricsSource),
+		cluster, namespace, pods)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to collect metrics: %w", err)
+	}
+	used, capacity := remediation.RemediatedUsage(clusterMetrics, event)
+	if capacity == 0 {
+		return 0, 0, fmt.Errorf("no usage was collected for the remediated volumes")
+	}
+	return used, capacity, nil
+}
+
+// alertIneffectiveRemediation alerts when the last measured expansion or WAL cleanup of
+// a cluster did not reduce usage, once per measurement, and resolves the alert once a
+// later remediation did
+func (r *StoragePolicyReconciler) alertIneffectiveRemediation(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+) {
+	if !policyObj.Spec.Effectiveness.Enabled {
+		return
+	}
+	log := logf.FromContext(ctx)
+
+	event, err := remediation.FindLatestMeasuredEvent(ctx, r.Client, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to find measured remediation", "cluster", cluster.Name)
+		return
+	}
+	if event == nil {
+		return
+	}
+	effectiveness := event.Status.Effectiveness
+	if effectiveness.Result == cnpgv1alpha1.EffectivenessEffective {
+		r.resolveAlert(ctx, policyObj, cluster, alerting.AlertTypeRemediationIneffective)
+		return
+	}
+	if previous := previousManagedCluster(policyObj, cluster, ""); previous != nil &&
+		effectiveness.MeasuredAt.Before(&previous.LastChecked) {
+		return
+	}
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+
+	message := fmt.Sprintf("%s of cluster %s/%s (StorageEvent %s) did not reduce usage: %s",
+		event.Spec.EventType, cluster.Namespace, cluster.Name, event.Name, effectiveness.Message)
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeRemediationIneffective,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
+		Severity:         alerting.AlertSeverityCritical,
+		Message:          message,
+		UsagePercent:     float64(effectiveness.UsagePercentAfter),
+		Details: map[string]string{
+			"policy":               policyObj.Name,
+			"event":                event.Name,
+			"event_type":           string(event.Spec.EventType),
+			"usage_percent_before": fmt.Sprintf("%d", effectiveness.UsagePercentBefore),
+			"usage_percent_after":  fmt.Sprintf("%d", effectiveness.UsagePercentAfter),
+			"bytes_freed":          fmt.Sprintf("%d", effectiveness.BytesFreed),
+		},
+		Timestamp: time.Now(),
+	}
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		log.Error(err, "Failed to send ineffective remediation alert", "cluster", cluster.Name)
+	}
+}
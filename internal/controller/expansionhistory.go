@@ -0,0 +1,138 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// defaultExpansionFrequencyWindowHours is the window of expansion.frequencyAlert when unset
+const defaultExpansionFrequencyWindowHours = 168
+
+// updateExpansionHistory adds the cluster's completed expansions to its history, records
+// the history metrics and alerts when the cluster expands more often than the policy
+// expects. It returns nil for clusters that never expanded
+func (r *StoragePolicyReconciler) updateExpansionHistory(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+) *cnpgv1alpha1.ExpansionHistory {
+	var previous *cnpgv1alpha1.ExpansionHistory
+	if mc := previousManagedCluster(policyObj, cluster, ""); mc != nil {
+		previous = mc.ExpansionHistory
+	}
+
+	var events cnpgv1alpha1.StorageEventList
+	if err := r.List(ctx, &events,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{
+			remediation.LabelCluster:   cluster.Name,
+			remediation.LabelEventType: string(cnpgv1alpha1.EventTypeExpansion),
+		},
+	); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list expansion events", "cluster", cluster.Name)
+		return previous
+	}
+
+	now := time.Now()
+	history := remediation.RecordExpansions(previous, events.Items, now)
+	metrics.SetExpansionHistory(cluster.Name, cluster.Namespace, history.ExpansionsLast30Days,
+		history.CumulativeGrowthBytes)
+	if history.TotalExpansions == 0 {
+		return nil
+	}
+
+	r.checkExpansionFrequency(ctx, policyObj, cluster, history, now)
+	return history
+}
+
+// checkExpansionFrequency alerts once per window when a cluster expanded more than
+// expansion.frequencyAlert.maxExpansions times within the window
+func (r *StoragePolicyReconciler) checkExpansionFrequency(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	history *cnpgv1alpha1.ExpansionHistory,
+	now time.Time,
+) {
+	config := policyObj.Spec.Expansion.FrequencyAlert
+	if config == nil {
+		return
+	}
+	windowHours := config.WindowHours
+	if windowHours <= 0 {
+		windowHours = defaultExpansionFrequencyWindowHours
+	}
+	window := time.Duration(windowHours) * time.Hour
+
+	count := remediation.ExpansionsSince(history, now.Add(-window))
+	if count <= config.MaxExpansions {
+		return
+	}
+	if history.FrequencyAlertedAt != nil && now.Sub(history.FrequencyAlertedAt.Time) < window {
+		return
+	}
+
+	log := logf.FromContext(ctx)
+	growth := resource.NewQuantity(history.CumulativeGrowthBytes, resource.BinarySI)
+	message := fmt.Sprintf("Cluster %s/%s expanded %d times in the last %d hours (expected at most %d); "+
+		"storage may be growing out of control", cluster.Namespace, cluster.Name, count, windowHours,
+		config.MaxExpansions)
+	log.Info("Cluster expands too often", "cluster", cluster.Name, "expansions", count,
+		"windowHours", windowHours, "cumulativeGrowth", growth.String())
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonFrequentExpansion, "%s", message)
+
+	if len(policyObj.Spec.Alerting.Channels) > 0 {
+		alert := &alerting.Alert{
+			ClusterName:      cluster.Name,
+			ClusterNamespace: cluster.Namespace,
+			Type:             alerting.AlertTypeExpansionFrequency,
+			Policy:           policyObj.Name,
+			Ownership:        ownership(policyObj, cluster),
+			Severity:         alerting.AlertSeverityWarning,
+			Message:          message,
+			Details: map[string]string{
+				"policy":            policyObj.Name,
+				"expansions":        fmt.Sprintf("%d", count),
+				"window_hours":      fmt.Sprintf("%d", windowHours),
+				"max_expansions":    fmt.Sprintf("%d", config.MaxExpansions),
+				"cumulative_growth": growth.String(),
+			},
+			Timestamp: now,
+		}
+		if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+			log.Error(err, "Failed to send expansion frequency alert", "cluster", cluster.Name)
+			return
+		}
+	}
+	history.FrequencyAlertedAt = &metav1.Time{Time: now}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// rollOutExpansion expands the instances of the cluster one at a time, in the order the
+// plan sorted them in. Each call resizes the current instance or checks on it, and is
+// requeued until the instance reports its new capacity and, with waitForHealthy, the
+// cluster is healthy again; only then is the next instance resized. The progress is
+// recorded in the event status, so a restart resumes with the current instance
+func (r *StorageEventReconciler) rollOutExpansion(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	rollout *cnpgv1alpha1.ExpansionRolloutConfig,
+) (stepOutcome, error) {
+	log := logf.FromContext(ctx)
+	clusterName := event.Spec.ClusterRef.Name
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+
+	if event.Status.ExpansionRollout == nil {
+		event.Status.ExpansionRollout = &cnpgv1alpha1.ExpansionRolloutStatus{}
+	}
+	progress := event.Status.ExpansionRollout
+	timeout := remediation.RolloutHealthyTimeout(rollout)
+	now := time.Now()
+
+	groups := instanceGroups(event.Status.PVCStatuses)
+	for n, group := range groups {
+		instance := groupName(event.Status.PVCStatuses, group)
+		if slices.Contains(progress.ExpandedInstances, instance) {
+			continue
+		}
+		if progress.CurrentInstance != instance || progress.CurrentStartTime == nil {
+			start := metav1.NewTime(now)
+			progress.CurrentInstance = instance
+			progress.CurrentStartTime = &start
+		}
+
+		if result := r.resizeInstance(ctx, event, group); result.failure != "" {
+			// The instance gets its full timeout again on the next attempt
+			progress.CurrentInstance = ""
+			progress.CurrentStartTime = nil
+			metrics.RecordExpansion(clusterName, clusterNamespace, "failure", 0, event.Name)
+			return stepOutcome{}, fmt.Errorf("expansion failed for %s", result.failure)
+		}
+
+		waiting, err := r.awaitInstance(ctx, event, group, rollout)
+		if err != nil {
+			return stepOutcome{}, err
+		}
+		if waiting != "" {
+			if now.Sub(progress.CurrentStartTime.Time) > timeout {
+				progress.CurrentInstance = ""
+				progress.CurrentStartTime = nil
+				return stepOutcome{}, fmt.Errorf("instance %s still %s after %s", instance, waiting, timeout)
+			}
+			return stepOutcome{
+				message:      fmt.Sprintf("Expanding instance %s (%d of %d): %s", instance, n+1, len(groups), waiting),
+				requeueAfter: expansionVerifyInterval,
+			}, nil
+		}
+
+		progress.ExpandedInstances = append(progress.ExpandedInstances, instance)
+		progress.CurrentInstance = ""
+		progress.CurrentStartTime = nil
+		log.Info("Instance expanded", "event", event.Name, "instance", instance,
+			"expanded", len(progress.ExpandedInstances), "instances", len(groups))
+	}
+
+	var bytesAdded int64
+	for _, status := range event.Status.PVCStatuses {
+		if status.Phase == cnpgv1alpha1.PVCPhaseCompleted && status.OriginalSize != nil && status.NewSize != nil {
+			bytesAdded += status.NewSize.Value() - status.OriginalSize.Value()
+		}
+	}
+	metrics.RecordExpansion(clusterName, clusterNamespace, "success", bytesAdded, event.Name)
+	return stepOutcome{message: fmt.Sprintf("%d instances expanded one at a time, %d bytes added",
+		len(progress.ExpandedInstances), bytesAdded)}, nil
+}
+
+// awaitInstance checks on an instance of a rolling expansion. It returns what the
+// instance is still waiting for: its resized PVCs to report their new capacity, then,
+// with waitForHealthy, every instance of the cluster to be ready. It is empty once the
+// instance is done, or when none of its PVCs was resized
+func (r *StorageEventReconciler) awaitInstance(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	group []int,
+	rollout *cnpgv1alpha1.ExpansionRolloutConfig,
+) (string, error) {
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+
+	expanded := false
+	var pending []string
+	for _, i := range group {
+		status := &event.Status.PVCStatuses[i]
+		switch status.Phase {
+		case cnpgv1alpha1.PVCPhaseCompleted:
+			expanded = true
+		case cnpgv1alpha1.PVCPhaseInProgress:
+			expanded = true
+			complete, err := r.verifyPVC(ctx, clusterNamespace, status)
+			if err != nil {
+				return "", err
+			}
+			if !complete {
+				pending = append(pending, status.Name)
+			}
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Sprintf("waiting for PVCs %s to report their new capacity", strings.Join(pending, ", ")), nil
+	}
+	if !expanded || !rollout.WaitForHealthy {
+		return "", nil
+	}
+
+	cluster, err := r.discovery.GetCluster(ctx, event.Spec.ClusterRef.Name, clusterNamespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster: %w", err)
+	}
+	if !remediation.IsClusterHealthy(*cluster) {
+		return fmt.Sprintf("waiting for the cluster to be healthy, %d of %d instances ready",
+			cluster.Status.ReadyInstances, cluster.Instances), nil
+	}
+	return "", nil
+}
+
+// groupName names an instance group after its instance, or its PVC for a PVC without one
+func groupName(statuses []cnpgv1alpha1.PVCStatus, group []int) string {
+	if instance := statuses[group[0]].Instance; instance != "" {
+		return instance
+	}
+	return statuses[group[0]].Name
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// expansionSkips plans the expansion of a cluster target and returns the PVCs it would
+// skip, so a cluster whose PVCs cannot grow does not sit above the expansion threshold
+// with the reason only in the StorageEvent controller's logs
+func (r *StoragePolicyReconciler) expansionSkips(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	target cnpgv1alpha1.ExpansionTarget,
+) []cnpgv1alpha1.ExpansionSkipStatus {
+	if r.expansionEngine == nil {
+		return nil
+	}
+
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list PVCs for the expansion plan", "cluster", cluster.Name)
+		return nil
+	}
+	plan := r.expansionEngine.PlanClusterExpansion(ctx, &remediation.ExpansionRequest{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		PVCs:             pvcs,
+		Policy:           policyObj,
+		DryRun:           true,
+		Target:           target,
+	})
+
+	var skips []cnpgv1alpha1.ExpansionSkipStatus
+	for _, planned := range plan {
+		if planned.Skipped {
+			skips = append(skips, cnpgv1alpha1.ExpansionSkipStatus{
+				PVC:     planned.PVCName,
+				Reason:  string(planned.SkipCode),
+				Message: planned.SkipReason,
+			})
+		}
+	}
+	return skips
+}
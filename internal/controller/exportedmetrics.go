@@ -0,0 +1,57 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+// storagePolicyOwner names a StoragePolicy as the owner of exported cluster metrics
+func storagePolicyOwner(namespace, name string) string {
+	return "StoragePolicy/" + namespace + "/" + name
+}
+
+// backupPolicyOwner names a BackupPolicy as the owner of exported cluster metrics
+func backupPolicyOwner(namespace, name string) string {
+	return "BackupPolicy/" + namespace + "/" + name
+}
+
+// trackExportedMetrics records the clusters a policy matches and deletes the metrics of
+// the clusters it matched before and no policy matches anymore. Nil clusters forget a
+// deleted policy
+func trackExportedMetrics(
+	ctx context.Context,
+	exported *metrics.ExportedClusters,
+	owner string,
+	clusters []cnpg.ClusterInfo,
+) {
+	if exported == nil {
+		return
+	}
+	refs := make([]metrics.ClusterRef, 0, len(clusters))
+	for _, cluster := range clusters {
+		refs = append(refs, metrics.ClusterRef{Namespace: cluster.Namespace, Name: cluster.Name})
+	}
+	if deleted := exported.Set(owner, refs); len(deleted) > 0 {
+		logf.FromContext(ctx).Info("Deleted the metrics of clusters no longer managed", "clusters", deleted)
+	}
+}
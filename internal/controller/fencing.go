@@ -0,0 +1,96 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+)
+
+// manageFencing fences the instances of a cluster whose storage is full and unfences the
+// ones the manager fenced once they have space again, by editing the cnpg.io/fencedInstances
+// annotation that processCluster writes back. Fencing is held back while the policy is
+// paused or in dry-run, while unfencing only undoes the manager's own fencing and always
+// runs. It returns the fenced instances of the cluster
+func (r *StoragePolicyReconciler) manageFencing(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	clusterMetrics *metrics.ClusterMetrics,
+	ca *clusterAnnotationsWrapper,
+) []string {
+	log := logf.FromContext(ctx)
+
+	fenced := cnpg.FencedInstances(ca.annotations)
+	managerFenced := ca.GetFencedInstances()
+	// Instances unfenced by hand are no longer the manager's to unfence
+	kept := slices.DeleteFunc(slices.Clone(managerFenced), func(instance string) bool {
+		return !slices.Contains(fenced, instance)
+	})
+	changed := len(kept) != len(managerFenced)
+	managerFenced = kept
+
+	if clusterMetrics != nil {
+		usage := clusterMetrics.InstanceUsagePercent()
+		plan := policy.PlanFencing(policyObj.Spec.Fencing, usage, fenced, managerFenced)
+
+		for _, instance := range plan.Unfence {
+			fenced = slices.DeleteFunc(fenced, func(i string) bool { return i == instance })
+			managerFenced = slices.DeleteFunc(managerFenced, func(i string) bool { return i == instance })
+			changed = true
+			log.Info("Unfencing instance", "cluster", cluster.Name, "instance", instance, "usagePercent", usage[instance])
+			r.events.Cluster(cluster, corev1.EventTypeNormal, recorder.ReasonInstanceUnfenced,
+				"Instance %s has space again at %.1f%% usage and was unfenced", instance, usage[instance])
+		}
+
+		if len(plan.Fence) > 0 {
+			switch {
+			case policy.IsPolicyPaused(policyObj, r.now()):
+				log.Info("Policy is paused, not fencing instances", "cluster", cluster.Name, "instances", plan.Fence)
+			case r.globalDryRun() || policy.IsPolicyDryRun(policyObj, r.now()):
+				log.Info("DryRun: Would fence instances", "cluster", cluster.Name, "instances", plan.Fence)
+			default:
+				for _, instance := range plan.Fence {
+					fenced = append(fenced, instance)
+					managerFenced = append(managerFenced, instance)
+					changed = true
+					log.Info("Fencing instance", "cluster", cluster.Name, "instance", instance,
+						"usagePercent", usage[instance])
+					r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonInstanceFenced,
+						"Instance %s is at %.1f%% storage usage and was fenced until space is available",
+						instance, usage[instance])
+				}
+			}
+		}
+	}
+
+	if changed {
+		cnpg.SetFencedInstances(ca.annotations, fenced)
+		ca.SetFencedInstances(managerFenced)
+	}
+	slices.Sort(fenced)
+	return fenced
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/backup"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+const (
+	mebibyte = 1024 * 1024
+	gibibyte = 1024 * mebibyte
+)
+
+// updateForecast recomputes the StorageForecast of a cluster once per forecast schedule,
+// sampling the usage of its fullest data volume. Without metrics the forecast is left
+// as it is until the next run
+func (r *StoragePolicyReconciler) updateForecast(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	clusterMetrics *metrics.ClusterMetrics,
+	growth *cnpgv1alpha1.GrowthStatus,
+	history *cnpgv1alpha1.StorageUsageHistory,
+) {
+	config := policyObj.Spec.Forecast
+	if !config.Enabled || clusterMetrics == nil {
+		return
+	}
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name, "namespace", cluster.Namespace)
+
+	scheduleExpr := config.Schedule
+	if scheduleExpr == "" {
+		scheduleExpr = policy.DefaultForecastSchedule
+	}
+	schedule, err := backup.ParseSchedule(scheduleExpr)
+	if err != nil {
+		log.Error(err, "Invalid forecast schedule", "schedule", scheduleExpr)
+		return
+	}
+
+	forecast := &cnpgv1alpha1.StorageForecast{}
+	key := client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}
+	exists := true
+	if err := r.Get(ctx, key, forecast); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get StorageForecast")
+			return
+		}
+		exists = false
+	}
+
+	now := r.now()
+	if exists && forecast.Status.LastUpdated != nil {
+		if run, ok := schedule.Prev(now); !ok || !run.After(forecast.Status.LastUpdated.Time) {
+			return
+		}
+	}
+
+	usedBytes, capacityBytes, instances := fullestDataVolume(clusterMetrics)
+	if instances == 0 {
+		return
+	}
+
+	if !exists {
+		forecast = &cnpgv1alpha1.StorageForecast{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cluster.Name,
+				Namespace: cluster.Namespace,
+				Labels:    map[string]string{remediation.LabelCluster: cluster.Name},
+			},
+			Spec: cnpgv1alpha1.StorageForecastSpec{ClusterName: cluster.Name},
+		}
+		if err := r.Create(ctx, forecast); err != nil {
+			log.Error(err, "Failed to create StorageForecast")
+			return
+		}
+	}
+
+	samples := policy.RecordForecastSample(forecast.Status.Samples, usedBytes, now)
+	growthPerDay, basis := forecastGrowth(samples, historyForecastSamples(history), growth)
+	percentage, minIncrement, maxSize := remediation.DataExpansionParameters(policyObj)
+	projection := policy.ProjectStorage(policy.ForecastInput{
+		UsedBytes:          usedBytes,
+		CapacityBytes:      capacityBytes,
+		Instances:          instances,
+		GrowthBytesPerDay:  growthPerDay,
+		ExpansionThreshold: policy.EffectiveThresholds(policyObj.Spec.Thresholds).Expansion,
+		Percentage:         percentage,
+		MinIncrementBytes:  minIncrement,
+		MaxSizeBytes:       maxSize,
+	}, now)
+
+	forecast.Status = cnpgv1alpha1.StorageForecastStatus{
+		Policy:       fmt.Sprintf("%s/%s", policyObj.Namespace, policyObj.Name),
+		LastUpdated:  &metav1.Time{Time: now},
+		Instances:    instances,
+		Usage:        roundedQuantity(usedBytes, mebibyte),
+		Capacity:     roundedQuantity(capacityBytes, mebibyte),
+		GrowthPerDay: roundedQuantity(growthPerDay, mebibyte),
+		Basis:        basis,
+		Samples:      samples,
+	}
+	horizons := []**cnpgv1alpha1.ForecastHorizon{
+		&forecast.Status.In30Days, &forecast.Status.In60Days, &forecast.Status.In90Days,
+	}
+	for i, point := range projection.Points {
+		*horizons[i] = forecastHorizon(point)
+	}
+	for _, expansion := range projection.Expansions {
+		forecast.Status.ProjectedExpansions = append(forecast.Status.ProjectedExpansions,
+			cnpgv1alpha1.ProjectedExpansion{
+				Date:     metav1.NewTime(expansion.Time),
+				FromSize: *roundedQuantity(expansion.FromBytes, gibibyte),
+				ToSize:   *roundedQuantity(expansion.ToBytes, gibibyte),
+			})
+	}
+
+	if err := r.Status().Update(ctx, forecast); err != nil {
+		log.Error(err, "Failed to update StorageForecast status")
+		return
+	}
+	log.V(1).Info("Updated storage forecast", "growthPerDay", remediation.FormatBytes(growthPerDay),
+		"basis", basis, "expansions", len(projection.Expansions))
+}
+
+// fullestDataVolume returns the usage and size of the data volume using the most
+// storage, and the number of data volumes. Tablespace and WAL volumes are left out
+func fullestDataVolume(clusterMetrics *metrics.ClusterMetrics) (usedBytes, capacityBytes int64, instances int32) {
+	for i := range clusterMetrics.PVCMetrics {
+		pvc := &clusterMetrics.PVCMetrics[i]
+		if pvc.Tablespace != "" || pvc.WALVolume {
+			continue
+		}
+		instances++
+		if pvc.UsedBytes >= usedBytes {
+			usedBytes, capacityBytes = pvc.UsedBytes, pvc.CapacityBytes
+		}
+	}
+	return usedBytes, capacityBytes, instances
+}
+
+// forecastGrowth returns the daily growth rate a forecast projects: the growth over
+// its samples, else over the hourly usage history, else the baseline rate of anomaly
+// detection
+func forecastGrowth(
+	samples, history []cnpgv1alpha1.UsageSample,
+	growth *cnpgv1alpha1.GrowthStatus,
+) (int64, cnpgv1alpha1.ForecastBasis) {
+	if perDay, ok := policy.ForecastGrowthPerDay(samples); ok {
+		return perDay, cnpgv1alpha1.ForecastBasisHistory
+	}
+	if perDay, ok := policy.ForecastGrowthPerDay(history); ok {
+		return perDay, cnpgv1alpha1.ForecastBasisHistory
+	}
+	if growth != nil && growth.BaselineBytesPerHour > 0 {
+		return growth.BaselineBytesPerHour * 24, cnpgv1alpha1.ForecastBasisGrowthRate
+	}
+	return 0, cnpgv1alpha1.ForecastBasisNone
+}
+
+// forecastHorizon converts a projected point to its StorageForecast status
+func forecastHorizon(point policy.ForecastPoint) *cnpgv1alpha1.ForecastHorizon {
+	return &cnpgv1alpha1.ForecastHorizon{
+		ProjectedUsage:    *roundedQuantity(point.UsedBytes, gibibyte),
+		ProjectedCapacity: *roundedQuantity(point.CapacityBytes, gibibyte),
+		UsagePercent:      int32(point.UsagePercent()),
+		Expansions:        int32(point.Expansions),
+		AdditionalGi:      int64(math.Ceil(float64(point.AdditionalBytes) / gibibyte)),
+		ExceedsMaxSize:    point.ExceedsMaxSize,
+	}
+}
+
+// roundedQuantity returns bytes rounded up to a multiple of unit, so the quantity
+// prints as whole Mi or Gi
+func roundedQuantity(bytes, unit int64) *resource.Quantity {
+	rounded := (bytes + unit - 1) / unit * unit
+	return resource.NewQuantity(rounded, resource.BinarySI)
+}
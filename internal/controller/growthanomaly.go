@@ -0,0 +1,157 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// anomalyTopDatabases is the number of databases listed in an anomalous growth alert
+const anomalyTopDatabases = 5
+
+// updateGrowth samples the cluster's usage for anomaly detection and alerts when an
+// anomaly starts. Without previous samples, e.g. after the policy was recreated, the
+// usage history seeds them. Without metrics the previous samples are kept as they are
+func (r *StoragePolicyReconciler) updateGrowth(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	clusterMetrics *metrics.ClusterMetrics,
+	history *cnpgv1alpha1.StorageUsageHistory,
+) *cnpgv1alpha1.GrowthStatus {
+	config := policyObj.Spec.AnomalyDetection
+	if !config.Enabled {
+		return nil
+	}
+
+	var previous *cnpgv1alpha1.GrowthStatus
+	if mc := previousManagedCluster(policyObj, cluster, ""); mc != nil {
+		previous = mc.Growth
+	}
+	if samples := historyGrowthSamples(history); previous == nil && len(samples) > 0 {
+		previous = &cnpgv1alpha1.GrowthStatus{Samples: samples}
+	}
+	if clusterMetrics == nil || len(clusterMetrics.PVCMetrics) == 0 {
+		return previous
+	}
+
+	usedBytes := clusterMetrics.LargestInstanceUsedBytes()
+	growth, analysis := policy.RecordGrowth(previous, config, usedBytes, time.Now())
+	if !analysis.Known {
+		return growth
+	}
+	metrics.SetStorageGrowth(cluster.Name, cluster.Namespace, analysis.RecentBytesPerHour,
+		analysis.BaselineBytesPerHour, analysis.Anomalous)
+	if analysis.Started {
+		r.alertAnomalousGrowth(ctx, policyObj, cluster, clusterMetrics, analysis)
+	}
+	return growth
+}
+
+// alertAnomalousGrowth sends the AnomalousGrowth alert and event of a cluster, listing
+// its largest databases when they can be queried
+func (r *StoragePolicyReconciler) alertAnomalousGrowth(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	clusterMetrics *metrics.ClusterMetrics,
+	analysis policy.GrowthAnalysis,
+) {
+	log := logf.FromContext(ctx)
+
+	recentRate := remediation.FormatBytes(analysis.RecentBytesPerHour) + "/h"
+	baselineRate := remediation.FormatBytes(analysis.BaselineBytesPerHour) + "/h"
+	message := fmt.Sprintf("Cluster %s/%s is growing abnormally fast: %s in the last %s (%s, baseline %s) "+
+		"at %.1f%% usage", cluster.Namespace, cluster.Name, remediation.FormatBytes(analysis.RecentGrowthBytes),
+		analysis.RecentWindow.Round(time.Minute), recentRate, baselineRate, clusterMetrics.TotalUsagePercent())
+	log.Info("Anomalous storage growth", "cluster", cluster.Name, "recentRate", recentRate,
+		"baselineRate", baselineRate)
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonAnomalousGrowth, "%s", message)
+
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	details := map[string]string{
+		"policy":               policyObj.Name,
+		"recent_growth":        remediation.FormatBytes(analysis.RecentGrowthBytes),
+		"recent_window":        analysis.RecentWindow.Round(time.Minute).String(),
+		"recent_growth_rate":   recentRate,
+		"baseline_growth_rate": baselineRate,
+		"usage_percent":        fmt.Sprintf("%.1f", clusterMetrics.TotalUsagePercent()),
+	}
+	if databases := r.topDatabases(ctx, cluster); databases != "" {
+		details["top_databases"] = databases
+	}
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeAnomalousGrowth,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
+		Severity:         alerting.AlertSeverityWarning,
+		Message:          message,
+		Details:          details,
+		Timestamp:        time.Now(),
+	}
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		log.Error(err, "Failed to send anomalous growth alert", "cluster", cluster.Name)
+	}
+}
+
+// topDatabases lists the largest databases on the cluster's primary as "name=size"
+// pairs, or returns an empty string when they cannot be queried or the primary is fenced
+func (r *StoragePolicyReconciler) topDatabases(ctx context.Context, cluster cnpg.ClusterInfo) string {
+	log := logf.FromContext(ctx)
+
+	if r.DatabaseSizes == nil {
+		return ""
+	}
+	primary, err := r.discovery.GetPrimaryPod(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get primary pod for database sizes", "cluster", cluster.Name)
+		return ""
+	}
+	if cluster.IsInstanceFenced(primary.Name) {
+		log.V(1).Info("Primary is fenced, not querying database sizes", "cluster", cluster.Name, "pod", primary.Name)
+		return ""
+	}
+	sizes, err := r.DatabaseSizes.TopDatabases(ctx, primary, anomalyTopDatabases)
+	if err != nil {
+		log.Error(err, "Failed to query database sizes", "cluster", cluster.Name, "pod", primary.Name)
+		return ""
+	}
+
+	entries := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		entries = append(entries, fmt.Sprintf("%s=%s", size.Name, remediation.FormatBytes(size.SizeBytes)))
+	}
+	return strings.Join(entries, ", ")
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+)
+
+// scoreManagedClusters sets the health score of each managed cluster and its metric,
+// removing the metric of clusters the policy no longer manages
+func scoreManagedClusters(
+	previous, current []cnpgv1alpha1.ManagedCluster,
+	thresholds cnpgv1alpha1.ThresholdsConfig,
+) {
+	managed := make(map[string]bool, len(current))
+	for i := range current {
+		mc := &current[i]
+		mc.HealthScore = policy.HealthScore(mc, thresholds)
+		metrics.SetClusterHealthScore(mc.Name, mc.Namespace, mc.Connection, mc.HealthScore)
+		managed[managedClusterKey(mc)] = true
+	}
+	for i := range previous {
+		if mc := &previous[i]; !managed[managedClusterKey(mc)] {
+			metrics.SetClusterHealthScore(mc.Name, mc.Namespace, mc.Connection, nil)
+		}
+	}
+}
+
+// managedClusterKey identifies a managed cluster across connections
+func managedClusterKey(mc *cnpgv1alpha1.ManagedCluster) string {
+	return mc.Connection + "/" + mc.Namespace + "/" + mc.Name
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/hooks"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+)
+
+// runPreActionHook calls the policy's preAction hook once per event, before it starts.
+// It returns true when the hook vetoed the event, which is then failed without
+// retrying. An error means the hook could not be called and the attempt has failed
+func (r *StorageEventReconciler) runPreActionHook(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) (bool, error) {
+	hook := policyObj.Spec.Hooks.PreAction
+	if hook == nil || !isHookedEvent(event) || event.Status.Phase == cnpgv1alpha1.EventPhaseInProgress ||
+		meta.IsStatusConditionTrue(event.Status.Conditions, cnpgv1alpha1.StorageEventConditionPreActionHook) {
+		return false, nil
+	}
+	log := logf.FromContext(ctx).WithValues("event", event.Name, "type", event.Spec.EventType)
+	name := event.Spec.ClusterRef.Name
+	namespace := event.Spec.ClusterRef.Namespace
+
+	err := r.Hooks.Call(ctx, hook, policyObj.Namespace, hooks.NewPayload(hooks.HookPreAction, event, time.Now()))
+	switch {
+	case hooks.IsRejected(err):
+		message := fmt.Sprintf("Vetoed by the preAction hook: %v", err)
+		log.Info("Storage event vetoed by the preAction hook", "reason", err.Error())
+		setEventCondition(event, cnpgv1alpha1.StorageEventConditionPreActionHook, metav1.ConditionFalse,
+			"Vetoed", message)
+		if err := r.markFailed(ctx, event, "Vetoed", message); err != nil {
+			return true, err
+		}
+		r.events.ClusterByName(ctx, name, namespace, corev1.EventTypeWarning, recorder.ReasonRemediationVetoed,
+			"%s (StorageEvent %s) vetoed by the preAction hook: %v", event.Spec.EventType, event.Name, err)
+		return true, nil
+	case err != nil && hook.FailurePolicy == cnpgv1alpha1.HookFailurePolicyIgnore:
+		log.Error(err, "PreAction hook failed, proceeding as its failurePolicy is Ignore")
+		setEventCondition(event, cnpgv1alpha1.StorageEventConditionPreActionHook, metav1.ConditionTrue,
+			"FailureIgnored", err.Error())
+	case err != nil:
+		r.events.ClusterByName(ctx, name, namespace, corev1.EventTypeWarning, recorder.ReasonHookFailed,
+			"PreAction hook of StorageEvent %s failed: %v", event.Name, err)
+		return false, fmt.Errorf("preAction hook: %w", err)
+	default:
+		setEventCondition(event, cnpgv1alpha1.StorageEventConditionPreActionHook, metav1.ConditionTrue,
+			"Allowed", "The preAction hook allowed the event")
+	}
+	return false, nil
+}
+
+// runPostActionHook calls the policy's postAction hook once an event has completed or
+// failed. The remediation is already over, so a failed call is only logged and recorded
+func (r *StorageEventReconciler) runPostActionHook(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) {
+	hook := policyObj.Spec.Hooks.PostAction
+	if hook == nil || !isHookedEvent(event) {
+		return
+	}
+
+	err := r.Hooks.Call(ctx, hook, policyObj.Namespace, hooks.NewPayload(hooks.HookPostAction, event, time.Now()))
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "PostAction hook failed", "event", event.Name)
+		r.events.ClusterByName(ctx, event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace,
+			corev1.EventTypeWarning, recorder.ReasonHookFailed, "PostAction hook of StorageEvent %s failed: %v",
+			event.Name, err)
+	}
+}
+
+// isHookedEvent returns true for the event types hooks are called around
+func isHookedEvent(event *cnpgv1alpha1.StorageEvent) bool {
+	return event.Spec.EventType == cnpgv1alpha1.EventTypeExpansion ||
+		event.Spec.EventType == cnpgv1alpha1.EventTypeWALCleanup
+}
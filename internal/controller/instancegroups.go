@@ -0,0 +1,92 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// failInstanceResize records the failed resize of an instance on the PVCs that were not
+// resized: the PVC that failed is Failed, the others stay Pending, held back until it
+// can be resized. It returns the failure of the instance, naming the PVCs of the
+// instance already resized, as PVCs cannot be shrunk back
+func (r *StorageEventReconciler) failInstanceResize(
+	ctx context.Context,
+	namespace string,
+	statuses []cnpgv1alpha1.PVCStatus,
+	group []int,
+	notResized []*cnpgv1alpha1.PVCStatus,
+	err error,
+) string {
+	failed := notResized[0].Name
+	var groupErr *remediation.GroupResizeError
+	if errors.As(err, &groupErr) {
+		failed = groupErr.PVC
+	}
+
+	instance := notResized[0].Instance
+	for _, status := range notResized {
+		if status.Name != failed {
+			status.Error = fmt.Sprintf("held back until PVC %s of instance %s is resized", failed, instance)
+			continue
+		}
+		status.Phase = cnpgv1alpha1.PVCPhaseFailed
+		status.Error = err.Error()
+		r.events.PVC(ctx, status.Name, namespace, corev1.EventTypeWarning, recorder.ReasonExpansionFailed,
+			"Resize to %s failed: %v", status.NewSize.String(), err)
+	}
+
+	if instance == "" {
+		return fmt.Sprintf("PVC %s: %v", failed, err)
+	}
+	var expanded []string
+	for _, i := range group {
+		if phase := statuses[i].Phase; phase == cnpgv1alpha1.PVCPhaseInProgress || phase == cnpgv1alpha1.PVCPhaseCompleted {
+			expanded = append(expanded, statuses[i].Name)
+		}
+	}
+	if len(expanded) == 0 {
+		return fmt.Sprintf("instance %s: %v", instance, err)
+	}
+	return fmt.Sprintf("instance %s, partially expanded with %s resized: %v", instance, strings.Join(expanded, ", "), err)
+}
+
+// instanceGroups returns the indexes of the PVC statuses of each instance, in the order
+// the instances first appear. PVCs without an instance form groups of their own
+func instanceGroups(statuses []cnpgv1alpha1.PVCStatus) [][]int {
+	var groups [][]int
+	byInstance := make(map[string]int)
+	for i := range statuses {
+		instance := statuses[i].Instance
+		if g, ok := byInstance[instance]; ok && instance != "" {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+		byInstance[instance] = len(groups)
+		groups = append(groups, []int{i})
+	}
+	return groups
+}
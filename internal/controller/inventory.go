@@ -0,0 +1,115 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"maps"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/backup"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+)
+
+// inventoryEventBuffer bounds queued cluster membership changes per controller.
+// Changes that do not fit are dropped; the periodic requeue picks them up.
+const inventoryEventBuffer = 128
+
+// watchInventory returns a channel of generic events for CNPG clusters that were
+// added, deleted or relabeled, for use with source.Channel. Status-only updates are
+// ignored since they do not change which policies select a cluster.
+func watchInventory(inv *cnpg.Inventory) <-chan event.GenericEvent {
+	ch := make(chan event.GenericEvent, inventoryEventBuffer)
+	send := func(info cnpg.ClusterInfo) {
+		select {
+		case ch <- event.GenericEvent{Object: clusterObject(inv.ClusterAPI(), info)}:
+		default:
+		}
+	}
+
+	inv.Subscribe(func(e cnpg.InventoryEvent) {
+		switch e.Type {
+		case cnpg.InventoryClusterAdded, cnpg.InventoryClusterDeleted:
+			send(e.Cluster)
+		case cnpg.InventoryClusterUpdated:
+			if e.Previous != nil && !maps.Equal(e.Previous.Labels, e.Cluster.Labels) {
+				// Both the policies that used to select the cluster and the ones that do now
+				send(*e.Previous)
+				send(e.Cluster)
+			}
+		}
+	})
+	return ch
+}
+
+// watchObjectStores returns a channel of generic events for CNPG clusters whose
+// ObjectStore recovery window changed, for use with source.Channel, so backup health
+// is re-evaluated as soon as a backup completes or a recoverability point moves.
+// Clusters are looked up in inv when set so label selectors see their labels.
+func watchObjectStores(objectStores *cnpg.ObjectStoreCache, inv *cnpg.Inventory) <-chan event.GenericEvent {
+	ch := make(chan event.GenericEvent, inventoryEventBuffer)
+	objectStores.Subscribe(func(e cnpg.ObjectStoreEvent) {
+		for _, server := range e.Servers {
+			// Barman cloud servers are named after their cluster, which lives in the
+			// ObjectStore's namespace
+			info := cnpg.ClusterInfo{Name: server, Namespace: e.Namespace}
+			if inv != nil {
+				if known, ok := inv.Get(e.Namespace, server); ok {
+					info = known
+				}
+			}
+			select {
+			case ch <- event.GenericEvent{Object: clusterObject(inv.ClusterAPI(), info)}:
+			default:
+			}
+		}
+	})
+	return ch
+}
+
+// clusterObject builds a minimal object of the given Cluster API version carrying a
+// cluster's identity and labels
+func clusterObject(api cnpg.ClusterAPI, info cnpg.ClusterInfo) client.Object {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(api.GroupVersionKind())
+	obj.SetName(info.Name)
+	obj.SetNamespace(info.Namespace)
+	obj.SetLabels(info.Labels)
+	return obj
+}
+
+// selectsCluster reports whether a policy selector, include and exclude lists match a cluster
+func selectsCluster(
+	selector *metav1.LabelSelector,
+	include *cnpgv1alpha1.ClusterInclusion,
+	exclude []cnpgv1alpha1.ClusterReference,
+	cluster client.Object,
+) bool {
+	if _, restoreTest := cluster.GetLabels()[backup.LabelRestoreTest]; restoreTest {
+		return false
+	}
+	matcher, err := policy.NewClusterMatcher(selector, include, exclude)
+	if err != nil {
+		return false
+	}
+	return matcher.Matches(cluster.GetName(), cluster.GetNamespace(), cluster.GetLabels())
+}
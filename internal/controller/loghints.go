@@ -0,0 +1,63 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+// logHints returns the key error lines of the PostgreSQL log of the cluster's primary,
+// one per line, or an empty string when the policy does not ask for them, the log
+// cannot be read or the primary is fenced
+func (r *StoragePolicyReconciler) logHints(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+) string {
+	log := logf.FromContext(ctx)
+
+	config := policyObj.Spec.Alerting.LogHints
+	if config == nil || r.PostgresLogs == nil {
+		return ""
+	}
+	primary, err := r.discovery.GetPrimaryPod(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get primary pod for log hints", "cluster", cluster.Name)
+		return ""
+	}
+	if cluster.IsInstanceFenced(primary.Name) {
+		log.V(1).Info("Primary is fenced, not reading its log", "cluster", cluster.Name, "pod", primary.Name)
+		return ""
+	}
+	lines := int(config.Lines)
+	if lines <= 0 {
+		lines = metrics.DefaultLogHintLines
+	}
+	hints, err := r.PostgresLogs.ErrorHints(ctx, primary, lines)
+	if err != nil {
+		log.Error(err, "Failed to read PostgreSQL log hints", "cluster", cluster.Name, "pod", primary.Name)
+		return ""
+	}
+	return strings.Join(hints, "\n")
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+// ManagerConfigReconciler reports whether the ManagerConfig is in effect. The policy
+// controllers read the ManagerConfig themselves on every reconcile.
+type ManagerConfigReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// RBAC for ManagerConfig access
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=managerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=managerconfigs/status,verbs=get;update;patch
+
+// Reconcile marks the ManagerConfig as Ready once its generation has been observed
+func (r *ManagerConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	startTime := time.Now()
+
+	var config cnpgv1alpha1.ManagerConfig
+	if err := r.Get(ctx, req.NamespacedName, &config); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	config.Status.ObservedGeneration = config.Generation
+	meta.SetStatusCondition(&config.Status.Conditions, metav1.Condition{
+		Type:               cnpgv1alpha1.ManagerConfigConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: config.Generation,
+		Reason:             "Applied",
+		Message:            fmt.Sprintf("Policies reconciled from now on use generation %d", config.Generation),
+	})
+	if err := r.Status().Update(ctx, &config); err != nil {
+		log.Error(err, "Failed to update ManagerConfig status")
+		metrics.RecordReconcile("managerconfig", "error", time.Since(startTime).Seconds())
+		return ctrl.Result{}, err
+	}
+
+	log.Info("ManagerConfig reconciled", "name", config.Name, "dryRun", config.Spec.DryRun)
+	metrics.RecordReconcile("managerconfig", "success", time.Since(startTime).Seconds())
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ManagerConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cnpgv1alpha1.ManagerConfig{}).
+		Named("managerconfig").
+		Complete(tracing.Reconciler("ManagerConfig", r))
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// escalateMaxSize escalates a cluster whose data PVCs need expansion but are at the
+// maximum size, so usage can only keep climbing: it alerts when that starts, requests a
+// WAL cleanup below the emergency threshold when the policy sets walCleanupAtMaxSize,
+// and resolves the alert once the PVCs can grow again. It returns the PVCs at maxSize
+func (r *StoragePolicyReconciler) escalateMaxSize(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	ca *clusterAnnotationsWrapper,
+	result policy.ThresholdResult,
+	skips []cnpgv1alpha1.ExpansionSkipStatus,
+) []string {
+	var pvcs []string
+	for _, skip := range skips {
+		if skip.Reason == string(remediation.SkipCodeMaxSize) {
+			pvcs = append(pvcs, skip.PVC)
+		}
+	}
+
+	reached := maxSizeReached(policyObj, cluster)
+	if len(pvcs) == 0 {
+		if reached {
+			r.resolveAlert(ctx, policyObj, cluster, alerting.AlertTypeMaxSizeReached)
+		}
+		return nil
+	}
+	if !reached {
+		r.alertMaxSizeReached(ctx, policyObj, cluster, result, pvcs)
+	}
+	if result.Level == policy.ThresholdLevelExpansion {
+		r.cleanupWALAtMaxSize(ctx, policyObj, cluster, ca, result.CurrentUsagePercent)
+	}
+	return pvcs
+}
+
+// maxSizeReached returns true if the cluster's previous status already reported PVCs
+// at the maximum size
+func maxSizeReached(policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo) bool {
+	mc := previousManagedCluster(policyObj, cluster, "")
+	if mc == nil {
+		return false
+	}
+	return meta.IsStatusConditionTrue(mc.Conditions, cnpgv1alpha1.ManagedClusterConditionMaxSizeReached)
+}
+
+// alertMaxSizeReached sends the max_size_reached alert and MaxSizeReached event of a
+// cluster. The alert is critical, or emergency once usage breached the emergency threshold
+func (r *StoragePolicyReconciler) alertMaxSizeReached(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	result policy.ThresholdResult,
+	pvcs []string,
+) {
+	log := logf.FromContext(ctx)
+
+	message := fmt.Sprintf("Cluster %s/%s is at %.1f%% usage and cannot be expanded: %s reached the maximum size",
+		cluster.Namespace, cluster.Name, result.CurrentUsagePercent, strings.Join(pvcs, ", "))
+	log.Info("PVCs at the maximum size", "cluster", cluster.Name, "pvcs", pvcs,
+		"usagePercent", result.CurrentUsagePercent)
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonMaxSizeReached, "%s", message)
+
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	severity := alerting.AlertSeverityCritical
+	if result.Level == policy.ThresholdLevelEmergency {
+		severity = alerting.AlertSeverityEmergency
+	}
+	details := map[string]string{
+		"policy":        policyObj.Name,
+		"pvcs":          strings.Join(pvcs, ","),
+		"usage_percent": fmt.Sprintf("%.1f", result.CurrentUsagePercent),
+	}
+	if maxSize := policyObj.Spec.Expansion.MaxSize; maxSize != nil {
+		details["max_size"] = maxSize.String()
+	}
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeMaxSizeReached,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
+		Severity:         severity,
+		Message:          message,
+		UsagePercent:     result.CurrentUsagePercent,
+		Thresholds:       result.Thresholds,
+		Details:          details,
+		Timestamp:        time.Now(),
+	}
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		log.Error(err, "Failed to send max size alert", "cluster", cluster.Name)
+	}
+}
+
+// cleanupWALAtMaxSize requests a WAL cleanup for a cluster at the maximum size when the
+// policy sets walCleanupAtMaxSize, respecting pauses, dry-run and the WAL cleanup cooldown
+func (r *StoragePolicyReconciler) cleanupWALAtMaxSize(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	ca *clusterAnnotationsWrapper,
+	usagePercent float64,
+) {
+	if !policyObj.Spec.Expansion.WALCleanupAtMaxSize || !policyObj.Spec.WALCleanup.Enabled {
+		return
+	}
+	log := logf.FromContext(ctx)
+	if policy.IsPolicyPaused(policyObj, r.now()) || r.isDryRun(policyObj, cnpgv1alpha1.EventTypeWALCleanup) {
+		log.Info("Would clean up WAL of a cluster at the maximum size", "cluster", cluster.Name)
+		return
+	}
+
+	action := &policy.ActionRecommendation{
+		Action:     policy.ActionTypeWALCleanup,
+		Reason:     fmt.Sprintf("PVCs at maximum size: %.1f%%", usagePercent),
+		Parameters: map[string]interface{}{"maxSize": true},
+	}
+	if _, err := r.handleWALCleanup(ctx, policyObj, cluster, ca, action, usagePercent); err != nil {
+		log.Error(err, "Failed to request WAL cleanup at the maximum size", "cluster", cluster.Name)
+	}
+}
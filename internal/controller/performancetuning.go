@@ -0,0 +1,151 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// tuneVolumePerformance moves the PVCs of a cluster whose latency reaches the policy's
+// performanceTuning threshold to its VolumeAttributesClass and annotations. Tuning is
+// the alternative to expansion when latency rather than capacity is the bottleneck, so
+// clusters at the expansion threshold are left to expansion
+func (r *StoragePolicyReconciler) tuneVolumePerformance(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	level policy.ThresholdLevel,
+) {
+	config := policyObj.Spec.PerformanceTuning
+	if config == nil {
+		return
+	}
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name)
+
+	if level == policy.ThresholdLevelExpansion || level == policy.ThresholdLevelEmergency {
+		log.V(1).Info("Capacity is the bottleneck, leaving the cluster to expansion instead of tuning")
+		return
+	}
+
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to list PVCs for performance tuning")
+		return
+	}
+	latency, err := r.latencyQuerier.VolumeLatency(ctx, config.PrometheusURL,
+		remediation.LatencyQuery(config, cluster), cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to query volume latency")
+		return
+	}
+	targets := remediation.FindTuningTargets(config, pvcs, latency)
+	if len(targets) == 0 {
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case policy.IsPolicyPaused(policyObj, now):
+		log.Info("Policy is paused, not tuning volumes", "pvcs", len(targets))
+		return
+	case r.globalDryRun() || policy.IsPolicyDryRun(policyObj, now) || config.DryRun:
+		for _, target := range targets {
+			log.Info("DryRun: Would tune volume", "pvc", target.PVC, "latencyMs", target.LatencyMs)
+		}
+		return
+	}
+
+	if config.VolumeAttributesClass != "" {
+		if err := remediation.CheckVolumeAttributesClass(ctx, r.Client, config.VolumeAttributesClass); err != nil {
+			log.Error(err, "Cannot use VolumeAttributesClass, not tuning volumes",
+				"volumeAttributesClass", config.VolumeAttributesClass)
+			return
+		}
+	}
+
+	for _, target := range targets {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.Get(ctx, client.ObjectKey{Name: target.PVC, Namespace: cluster.Namespace}, pvc); err != nil {
+			log.Error(err, "Failed to get PVC for performance tuning", "pvc", target.PVC)
+			continue
+		}
+		patch := client.MergeFrom(pvc.DeepCopy())
+		remediation.ApplyTuning(pvc, config)
+		if err := r.Patch(ctx, pvc, patch); err != nil {
+			log.Error(err, "Failed to tune volume", "pvc", target.PVC)
+			continue
+		}
+		log.Info("Tuned volume", "pvc", target.PVC, "latencyMs", target.LatencyMs)
+		r.alertVolumeTuned(ctx, policyObj, cluster, target, now)
+	}
+}
+
+// alertVolumeTuned sends the performance_tuning alert and VolumeTuned event of a PVC
+// that was moved to the policy's VolumeAttributesClass or annotations
+func (r *StoragePolicyReconciler) alertVolumeTuned(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	target remediation.TuningTarget,
+	now time.Time,
+) {
+	log := logf.FromContext(ctx)
+	config := policyObj.Spec.PerformanceTuning
+
+	message := fmt.Sprintf("PVC %s of cluster %s/%s was tuned at %.1fms latency",
+		target.PVC, cluster.Namespace, cluster.Name, target.LatencyMs)
+	r.events.PVC(ctx, target.PVC, cluster.Namespace, corev1.EventTypeNormal, recorder.ReasonVolumeTuned,
+		"Tuned volume at %.1fms latency (threshold %dms)", target.LatencyMs, config.LatencyThresholdMs)
+
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypePerformanceTuning,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
+		Severity:         alerting.AlertSeverityWarning,
+		Message:          message,
+		Details: map[string]string{
+			"policy":                  policyObj.Name,
+			"pvc":                     target.PVC,
+			"instance":                target.Instance,
+			"latency_ms":              fmt.Sprintf("%.1f", target.LatencyMs),
+			"latency_threshold_ms":    fmt.Sprintf("%d", config.LatencyThresholdMs),
+			"volume_attributes_class": config.VolumeAttributesClass,
+		},
+		Timestamp: now,
+	}
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		log.Error(err, "Failed to send performance tuning alert", "cluster", cluster.Name)
+	}
+}
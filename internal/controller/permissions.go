@@ -0,0 +1,204 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/permissions"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+)
+
+// Features whose permissions are checked. Expansion and WAL cleanup are planned as in
+// dry-run mode while their permissions are missing, and clusters are not annotated
+const (
+	featureMetrics     = "metrics"
+	featureEvents      = "events"
+	featureAnnotations = "cluster-annotations"
+	featureExpansion   = "expansion"
+	featureWALCleanup  = "wal-cleanup"
+)
+
+// commandPermissions are what running commands in instance pods needs in a runner mode
+func commandPermissions(feature string, mode runner.Mode) []permissions.Permission {
+	if mode == runner.ModeJob {
+		return []permissions.Permission{
+			{Feature: feature, Group: "batch", Resource: "jobs", Verb: "create"},
+			{Feature: feature, Resource: "pods", Subresource: "log", Verb: "get"},
+		}
+	}
+	return []permissions.Permission{{Feature: feature, Resource: "pods", Subresource: "exec", Verb: "create"}}
+}
+
+// StartupPermissions are the permissions the manager needs whatever its policies enable
+func StartupPermissions(readOnly bool, mode runner.Mode) []permissions.Permission {
+	required := []permissions.Permission{
+		{Feature: featureMetrics, Group: cnpg.CNPGGroup, Resource: "clusters", Verb: "list"},
+		{Feature: featureMetrics, Resource: "pods", Verb: "list"},
+		{Feature: featureMetrics, Resource: "persistentvolumeclaims", Verb: "list"},
+		{Feature: featureMetrics, Resource: "nodes", Subresource: "proxy", Verb: "get", ClusterScoped: true},
+		{Feature: featureEvents, Resource: "events", Verb: "create"},
+	}
+	if readOnly {
+		return required
+	}
+	required = append(required,
+		permissions.Permission{Feature: featureAnnotations, Group: cnpg.CNPGGroup, Resource: "clusters", Verb: "patch"},
+		permissions.Permission{Feature: featureExpansion, Resource: "persistentvolumeclaims", Verb: "patch"})
+	return append(required, commandPermissions(featureWALCleanup, mode)...)
+}
+
+// requiredPermissions are the permissions the features a policy enables need
+func (r *StoragePolicyReconciler) requiredPermissions(policyObj *cnpgv1alpha1.StoragePolicy) []permissions.Permission {
+	var required []permissions.Permission
+	switch policyObj.Spec.MetricsSource {
+	case cnpgv1alpha1.MetricsSourceAgent:
+	case cnpgv1alpha1.MetricsSourceExec:
+		if !r.ReadOnly {
+			required = append(required, commandPermissions(featureMetrics, r.RunnerMode)...)
+		}
+	default:
+		required = append(required, permissions.Permission{
+			Feature: featureMetrics, Resource: "nodes", Subresource: "proxy", Verb: "get", ClusterScoped: true,
+		})
+	}
+	if r.Recorder != nil {
+		required = append(required, permissions.Permission{Feature: featureEvents, Resource: "events", Verb: "create"})
+	}
+	if r.ReadOnly {
+		return required
+	}
+
+	required = append(required, permissions.Permission{
+		Feature: featureAnnotations, Group: cnpg.CNPGGroup, Resource: "clusters", Verb: "patch",
+	})
+	now := r.now()
+	if policyObj.Spec.Expansion.Enabled && !r.globalDryRun() &&
+		!policy.IsActionDryRun(policyObj, cnpgv1alpha1.EventTypeExpansion, now) {
+		if policyObj.Spec.Expansion.Mode == cnpgv1alpha1.ExpansionModeRecommend {
+			required = append(required,
+				permissions.Permission{Feature: featureExpansion, Resource: "configmaps", Verb: "create"},
+				permissions.Permission{Feature: featureExpansion, Resource: "configmaps", Verb: "update"})
+		} else {
+			required = append(required, permissions.Permission{
+				Feature: featureExpansion, Resource: "persistentvolumeclaims", Verb: "patch",
+			})
+		}
+	}
+	if policyObj.Spec.WALCleanup.Enabled && !r.globalDryRun() &&
+		!policy.IsActionDryRun(policyObj, cnpgv1alpha1.EventTypeWALCleanup, now) {
+		required = append(required, commandPermissions(featureWALCleanup, r.RunnerMode)...)
+	}
+	return required
+}
+
+// checkPermissions reviews the permissions the policy's features need in the namespaces
+// of its clusters, sets the MissingPermission condition and holds back the features
+// whose permissions are missing until they are granted. Errors of the reviews leave
+// everything as it was
+func (r *StoragePolicyReconciler) checkPermissions(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	clusters []cnpg.ClusterInfo,
+) {
+	if r.Permissions == nil {
+		return
+	}
+	log := logf.FromContext(ctx)
+
+	namespaces := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		namespaces = append(namespaces, cluster.Namespace)
+	}
+	slices.Sort(namespaces)
+	namespaces = slices.Compact(namespaces)
+	if len(namespaces) == 0 {
+		r.setMissingFeatures(policyObj, nil)
+		meta.RemoveStatusCondition(&policyObj.Status.Conditions, cnpgv1alpha1.StoragePolicyConditionMissingPermission)
+		return
+	}
+
+	missing, err := r.Permissions.Check(ctx, namespaces, r.requiredPermissions(policyObj))
+	if err != nil {
+		log.Error(err, "Failed to check permissions")
+		return
+	}
+
+	features := make(map[string]bool, len(missing))
+	for _, m := range missing {
+		features[m.Feature] = true
+	}
+	r.setMissingFeatures(policyObj, features)
+
+	if len(missing) == 0 {
+		r.setCondition(policyObj, cnpgv1alpha1.StoragePolicyConditionMissingPermission, metav1.ConditionFalse,
+			"PermissionsGranted", "The manager has the permissions every enabled feature needs")
+		return
+	}
+	message := fmt.Sprintf("Missing %s", permissions.Summarize(missing))
+	if features[featureExpansion] || features[featureWALCleanup] {
+		message += "; remediation needing them is planned as in dry-run mode until they are granted"
+	}
+	if !meta.IsStatusConditionTrue(policyObj.Status.Conditions, cnpgv1alpha1.StoragePolicyConditionMissingPermission) {
+		log.Info("Missing permissions for enabled features", "missing", permissions.Summarize(missing))
+	}
+	r.setCondition(policyObj, cnpgv1alpha1.StoragePolicyConditionMissingPermission, metav1.ConditionTrue,
+		"PermissionDenied", message)
+}
+
+// setMissingFeatures records the features of a policy held back for missing permissions
+func (r *StoragePolicyReconciler) setMissingFeatures(policyObj *cnpgv1alpha1.StoragePolicy, features map[string]bool) {
+	key := policyObj.Namespace + "/" + policyObj.Name
+	r.permissionsLock.Lock()
+	defer r.permissionsLock.Unlock()
+	if len(features) == 0 {
+		delete(r.missingFeatures, key)
+		return
+	}
+	if r.missingFeatures == nil {
+		r.missingFeatures = make(map[string]map[string]bool)
+	}
+	r.missingFeatures[key] = features
+}
+
+// lacksPermission returns true while a feature of the policy is held back for missing permissions
+func (r *StoragePolicyReconciler) lacksPermission(policyObj *cnpgv1alpha1.StoragePolicy, feature string) bool {
+	r.permissionsLock.RLock()
+	defer r.permissionsLock.RUnlock()
+	return r.missingFeatures[policyObj.Namespace+"/"+policyObj.Name][feature]
+}
+
+// eventTypeFeature returns the feature whose permissions a remediation type needs
+func eventTypeFeature(eventType cnpgv1alpha1.EventType) string {
+	switch eventType {
+	case cnpgv1alpha1.EventTypeExpansion:
+		return featureExpansion
+	case cnpgv1alpha1.EventTypeWALCleanup:
+		return featureWALCleanup
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// plannedActionTTL is how long a dry-run plan is reused before it is computed again.
+// Dry-run never records a cooldown, so without it WAL would be listed in the pods on
+// every reconcile
+const plannedActionTTL = 5 * time.Minute
+
+// planDryRunAction records what a remediation held back by dry-run mode would have done.
+// target selects the PVCs of an expansion. A recent plan of the same type and target
+// from the previous status is reused
+func (r *StoragePolicyReconciler) planDryRunAction(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	eventType cnpgv1alpha1.EventType,
+	target cnpgv1alpha1.ExpansionTarget,
+	reason string,
+) cnpgv1alpha1.PlannedAction {
+	if previous := previousPlannedAction(policyObj, cluster, eventType, target); previous != nil &&
+		time.Since(previous.PlannedAt.Time) < plannedActionTTL {
+		previous.Reason = reason
+		return *previous
+	}
+
+	action := cnpgv1alpha1.PlannedAction{
+		Type:            eventType,
+		Reason:          reason,
+		ExpansionTarget: target,
+		PlannedAt:       metav1.Now(),
+	}
+	switch eventType {
+	case cnpgv1alpha1.EventTypeExpansion:
+		r.planExpansion(ctx, policyObj, cluster, &action)
+	case cnpgv1alpha1.EventTypeWALCleanup:
+		r.planWALCleanup(ctx, policyObj, cluster, &action)
+	}
+	return action
+}
+
+// planExpansion computes the target size of each PVC of the cluster
+func (r *StoragePolicyReconciler) planExpansion(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	action *cnpgv1alpha1.PlannedAction,
+) {
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		action.Error = err.Error()
+		return
+	}
+
+	plan := r.expansionEngine.PlanClusterExpansion(ctx, &remediation.ExpansionRequest{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		PVCs:             pvcs,
+		Policy:           policyObj,
+		Reason:           action.Reason,
+		DryRun:           true,
+		Target:           action.ExpansionTarget,
+	})
+	for _, planned := range plan {
+		pvc := cnpgv1alpha1.PlannedPVCExpansion{
+			Name:        planned.PVCName,
+			CurrentSize: planned.OriginalSize.DeepCopy(),
+		}
+		switch {
+		case planned.Skipped:
+			pvc.SkipReason = planned.SkipReason
+		case planned.Error != "":
+			pvc.SkipReason = planned.Error
+		default:
+			target := planned.NewSize.DeepCopy()
+			pvc.TargetSize = &target
+		}
+		action.PVCs = append(action.PVCs, pvc)
+	}
+}
+
+// planWALCleanup lists the WAL segments cleanup would remove on the primary, and on the
+// replicas when the policy includes them. Planning only reads the WAL directory; no
+// checkpoint or WAL switch is issued
+func (r *StoragePolicyReconciler) planWALCleanup(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	action *cnpgv1alpha1.PlannedAction,
+) {
+	if r.ReadOnly {
+		action.Error = "WAL cleanup is not planned in read-only mode, which runs no commands in pods"
+		return
+	}
+	if r.walCleanupEngine == nil {
+		action.Error = "WAL cleanup engine not available"
+		return
+	}
+
+	primary, err := r.discovery.GetPrimaryPod(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		action.Error = err.Error()
+		return
+	}
+	action.WALCleanup = append(action.WALCleanup,
+		r.planInstanceWALCleanup(ctx, policyObj, cluster, primary.Name, &remediation.WALCleanupRequest{Pod: primary}))
+
+	if !policyObj.Spec.WALCleanup.IncludeReplicas {
+		return
+	}
+	pods, err := r.discovery.GetClusterPods(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list replicas for the WAL cleanup plan", "cluster", cluster.Name)
+		return
+	}
+	for i := range pods {
+		if pods[i].Name == primary.Name {
+			continue
+		}
+		action.WALCleanup = append(action.WALCleanup, r.planInstanceWALCleanup(ctx, policyObj, cluster,
+			pods[i].Name, &remediation.WALCleanupRequest{Pod: &pods[i], Replica: true}))
+	}
+}
+
+// planInstanceWALCleanup runs a dry-run WAL cleanup against one instance. Fenced
+// instances are not run against, since PostgreSQL is stopped on them
+func (r *StoragePolicyReconciler) planInstanceWALCleanup(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	podName string,
+	req *remediation.WALCleanupRequest,
+) cnpgv1alpha1.PlannedWALCleanup {
+	req.ClusterName = cluster.Name
+	req.ClusterNamespace = cluster.Namespace
+	req.Policy = policyObj
+	req.DryRun = true
+
+	planned := cnpgv1alpha1.PlannedWALCleanup{PodName: podName}
+	if cluster.IsInstanceFenced(podName) {
+		planned.Error = "instance is fenced"
+		return planned
+	}
+	result, err := r.walCleanupEngine.CleanupClusterWAL(ctx, req)
+	if err != nil {
+		planned.Error = err.Error()
+		return planned
+	}
+	planned.Files = int32(result.FilesRemoved)
+	planned.Bytes = result.BytesFreed
+	return planned
+}
+
+// previousPlannedAction returns the plan of the given type and target from the cluster's
+// previous status entry, or nil
+func previousPlannedAction(
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	eventType cnpgv1alpha1.EventType,
+	target cnpgv1alpha1.ExpansionTarget,
+) *cnpgv1alpha1.PlannedAction {
+	mc := previousManagedCluster(policyObj, cluster, "")
+	if mc == nil {
+		return nil
+	}
+	for i := range mc.PlannedActions {
+		if mc.PlannedActions[i].Type == eventType && mc.PlannedActions[i].ExpansionTarget == target {
+			return mc.PlannedActions[i].DeepCopy()
+		}
+	}
+	return nil
+}
+
+// plannedExpansionBytes returns how many bytes the planned expansions would add
+func plannedExpansionBytes(actions []cnpgv1alpha1.PlannedAction) int64 {
+	var total int64
+	for _, action := range actions {
+		for _, pvc := range action.PVCs {
+			if pvc.TargetSize != nil {
+				total += pvc.TargetSize.Value() - pvc.CurrentSize.Value()
+			}
+		}
+	}
+	return total
+}
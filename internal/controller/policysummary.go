@@ -0,0 +1,123 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+)
+
+// summarizeManagedClusters sets the aggregate fields shown by `kubectl get storagepolicies`
+// and the summary of the most concerning clusters from the managed cluster list
+func summarizeManagedClusters(status *cnpgv1alpha1.StoragePolicyStatus) {
+	status.ManagedClusterCount = int32(len(status.ManagedClusters))
+	status.WorstUsagePercent = 0
+	status.WorstCluster = ""
+	status.Summary = nil
+	if len(status.ManagedClusters) == 0 {
+		return
+	}
+
+	summary := &cnpgv1alpha1.PolicySummary{}
+	var backups []cnpgv1alpha1.ClusterSummaryEntry
+	usage := make([]cnpgv1alpha1.ClusterSummaryEntry, 0, len(status.ManagedClusters))
+	for _, mc := range status.ManagedClusters {
+		countStorageHealth(summary, mc)
+
+		entry := cnpgv1alpha1.ClusterSummaryEntry{
+			Name:         mc.Name,
+			Namespace:    mc.Namespace,
+			Connection:   mc.Connection,
+			UsagePercent: mc.UsagePercent,
+		}
+		if mc.BackupStatus != nil {
+			entry.BackupHealthStatus = mc.BackupStatus.BackupHealthStatus
+			if mc.BackupStatus.LastBackupTime != nil {
+				age := mc.BackupStatus.LastBackupAgeHours
+				entry.LastBackupAgeHours = &age
+			}
+			if mc.BackupStatus.BackupHealthStatus != "Healthy" {
+				summary.BackupUnhealthy++
+			}
+			backups = append(backups, entry)
+		}
+		usage = append(usage, entry)
+	}
+
+	slices.SortStableFunc(usage, func(a, b cnpgv1alpha1.ClusterSummaryEntry) int {
+		return cmp.Or(cmp.Compare(b.UsagePercent, a.UsagePercent), compareSummaryEntries(a, b))
+	})
+	slices.SortStableFunc(backups, func(a, b cnpgv1alpha1.ClusterSummaryEntry) int {
+		return cmp.Or(cmp.Compare(backupAgeRank(b), backupAgeRank(a)), compareSummaryEntries(a, b))
+	})
+	summary.TopUsage = usage[:min(len(usage), cnpgv1alpha1.PolicySummaryTopN)]
+	if len(backups) > 0 {
+		summary.OldestBackups = backups[:min(len(backups), cnpgv1alpha1.PolicySummaryTopN)]
+	}
+	status.Summary = summary
+
+	worst := usage[0]
+	status.WorstUsagePercent = worst.UsagePercent
+	status.WorstCluster = fmt.Sprintf("%s/%s", worst.Namespace, worst.Name)
+	if worst.Connection != "" {
+		status.WorstCluster = fmt.Sprintf("%s:%s", worst.Connection, status.WorstCluster)
+	}
+}
+
+// countStorageHealth counts a cluster by the state of its StorageHealthy condition
+func countStorageHealth(summary *cnpgv1alpha1.PolicySummary, mc cnpgv1alpha1.ManagedCluster) {
+	condition := meta.FindStatusCondition(mc.Conditions, cnpgv1alpha1.ManagedClusterConditionStorageHealthy)
+	switch {
+	case condition == nil:
+		summary.Unknown++
+	case condition.Reason == policy.ReasonBelowThresholds:
+		summary.Healthy++
+	case condition.Reason == policy.ThresholdReason(policy.ThresholdLevelWarning):
+		summary.Warning++
+	case condition.Reason == policy.ThresholdReason(policy.ThresholdLevelCritical):
+		summary.Critical++
+	case condition.Reason == policy.ThresholdReason(policy.ThresholdLevelEmergency):
+		summary.Emergency++
+	default:
+		summary.Unknown++
+	}
+}
+
+// backupAgeRank orders clusters by how stale their backups are. Clusters without a
+// successful backup rank above every age
+func backupAgeRank(entry cnpgv1alpha1.ClusterSummaryEntry) int64 {
+	if entry.LastBackupAgeHours == nil {
+		return 1 << 62
+	}
+	return int64(*entry.LastBackupAgeHours)
+}
+
+// compareSummaryEntries orders clusters by connection, namespace and name so rankings
+// are stable between reconciles
+func compareSummaryEntries(a, b cnpgv1alpha1.ClusterSummaryEntry) int {
+	return cmp.Or(
+		cmp.Compare(a.Connection, b.Connection),
+		cmp.Compare(a.Namespace, b.Namespace),
+		cmp.Compare(a.Name, b.Name),
+	)
+}
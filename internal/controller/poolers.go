@@ -0,0 +1,113 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+)
+
+// RBAC for Pooler discovery (monitoring the volumes of pooler pods)
+// +kubebuilder:rbac:groups=postgresql.cnpg.io,resources=poolers,verbs=get;list;watch
+
+// evaluatePoolerVolumes reports the usage of the PVCs mounted by the pods of the
+// cluster's Poolers and alerts when one is above a threshold. Pooler volumes are not
+// managed by CNPG, so they are never expanded. Usage is read from kubelet stats.
+func (r *StoragePolicyReconciler) evaluatePoolerVolumes(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+) []cnpgv1alpha1.PoolerVolumeStatus {
+	metrics.DeletePoolerVolumeMetrics(cluster.Name, cluster.Namespace)
+	if !policyObj.Spec.Poolers.Enabled || r.metricsCollector == nil {
+		return nil
+	}
+
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name, "namespace", cluster.Namespace)
+	poolers, err := r.discovery.ListPoolers(ctx, cluster.Namespace, cluster.Name)
+	if err != nil {
+		log.Error(err, "Failed to list poolers")
+		return nil
+	}
+
+	var statuses []cnpgv1alpha1.PoolerVolumeStatus
+	for _, pooler := range poolers {
+		if !pooler.HasPersistentVolumes() {
+			continue
+		}
+		pods, err := r.discovery.GetPoolerPods(ctx, pooler.Name, pooler.Namespace)
+		if err != nil {
+			log.Error(err, "Failed to get pooler pods", "pooler", pooler.Name)
+			continue
+		}
+		pvcMetrics, err := r.metricsCollector.CollectPVCMetrics(ctx, pods)
+		if err != nil {
+			log.Error(err, "Failed to collect pooler volume metrics", "pooler", pooler.Name)
+			continue
+		}
+
+		for _, pvc := range fullestPoolerVolumes(pvcMetrics) {
+			usagePercent := pvc.UsagePercent()
+			metrics.SetPoolerVolumeUsage(cluster.Name, cluster.Namespace, pooler.Name, pvc.PVCName, usagePercent)
+
+			result := r.evaluator.EvaluateUsage(usagePercent, pvc.AvailableBytes, policyObj.Spec.Thresholds)
+			status := "Healthy"
+			if result.Level != policy.ThresholdLevelNormal {
+				status = fmt.Sprintf("Alert-%s", result.Level)
+				result.Message = fmt.Sprintf("Pooler %s volume %s: %s", pooler.Name, pvc.PVCName, result.Message)
+				if err := r.sendThresholdAlert(ctx, policyObj, cluster, nil, result); err != nil {
+					log.Error(err, "Failed to send pooler volume alert", "pooler", pooler.Name, "pvc", pvc.PVCName)
+				}
+			}
+
+			statuses = append(statuses, cnpgv1alpha1.PoolerVolumeStatus{
+				Pooler:        pooler.Name,
+				PVC:           pvc.PVCName,
+				UsagePercent:  int32(usagePercent),
+				UsedBytes:     pvc.UsedBytes,
+				CapacityBytes: pvc.CapacityBytes,
+				Status:        status,
+			})
+		}
+	}
+	return statuses
+}
+
+// fullestPoolerVolumes returns one entry per PVC, keeping the fullest reading of PVCs
+// shared by several pooler pods, in the order they were first seen
+func fullestPoolerVolumes(pvcMetrics []metrics.PVCMetrics) []metrics.PVCMetrics {
+	var volumes []metrics.PVCMetrics
+	index := make(map[string]int, len(pvcMetrics))
+	for _, pvc := range pvcMetrics {
+		i, seen := index[pvc.PVCName]
+		switch {
+		case !seen:
+			index[pvc.PVCName] = len(volumes)
+			volumes = append(volumes, pvc)
+		case pvc.UsagePercent() > volumes[i].UsagePercent():
+			volumes[i] = pvc
+		}
+	}
+	return volumes
+}
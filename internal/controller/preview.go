@@ -0,0 +1,236 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/managerconfig"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+)
+
+// PreviewPath is where the metrics server serves policy previews
+const PreviewPath = "/api/v1/policies/preview"
+
+// maxPreviewBodyBytes bounds the size of a posted StoragePolicy
+const maxPreviewBodyBytes = 1 << 20
+
+const (
+	previewStatusEvaluated  = "Evaluated"
+	previewStatusHibernated = "Hibernated"
+	previewStatusPaused     = "Paused"
+	previewStatusError      = "Error"
+)
+
+// PolicyPreview is what a StoragePolicy would do to the clusters it selects
+type PolicyPreview struct {
+	Policy      string           `json:"policy"`
+	GeneratedAt time.Time        `json:"generatedAt"`
+	Clusters    []ClusterPreview `json:"clusters"`
+}
+
+// ClusterPreview is the evaluation of a single cluster selected by a previewed policy
+type ClusterPreview struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Status is Evaluated, Hibernated, Paused or Error
+	Status   string                           `json:"status"`
+	Decision *cnpgv1alpha1.EvaluationDecision `json:"decision,omitempty"`
+	Error    string                           `json:"error,omitempty"`
+}
+
+// PreviewHandler serves previews of StoragePolicies: a policy posted as YAML or JSON is
+// validated and defaulted by a dry-run create, then evaluated against the live clusters
+// it selects without creating StorageEvents, sending alerts or changing annotations.
+// Clusters reached through ClusterConnections are not previewed
+func (r *StoragePolicyReconciler) PreviewHandler() http.Handler {
+	r.initComponents()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "a StoragePolicy must be posted", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxPreviewBodyBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var policyObj cnpgv1alpha1.StoragePolicy
+		if err := yaml.UnmarshalStrict(body, &policyObj); err != nil {
+			http.Error(w, fmt.Sprintf("invalid StoragePolicy: %v", err), http.StatusBadRequest)
+			return
+		}
+		if policyObj.Kind != "" && policyObj.Kind != "StoragePolicy" {
+			http.Error(w, fmt.Sprintf("expected a StoragePolicy, got %s", policyObj.Kind), http.StatusBadRequest)
+			return
+		}
+		if namespace := req.URL.Query().Get("namespace"); namespace != "" {
+			policyObj.Namespace = namespace
+		}
+		if policyObj.Namespace == "" {
+			http.Error(w, "the StoragePolicy needs a namespace", http.StatusBadRequest)
+			return
+		}
+
+		preview, err := r.Preview(req.Context(), &policyObj)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(preview)
+	})
+}
+
+// Preview evaluates a StoragePolicy against the local clusters it selects. The policy is
+// defaulted and validated by the API server with a dry-run create under a generated
+// name, so a policy that already exists can be previewed with changes
+func (r *StoragePolicyReconciler) Preview(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) (*PolicyPreview, error) {
+	name := policyObj.Name
+	candidate := &cnpgv1alpha1.StoragePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "preview-",
+			Namespace:    policyObj.Namespace,
+			Labels:       policyObj.Labels,
+			Annotations:  policyObj.Annotations,
+		},
+		Spec: policyObj.Spec,
+	}
+	if err := r.Create(ctx, candidate, client.DryRunAll); err != nil {
+		return nil, fmt.Errorf("invalid StoragePolicy: %w", err)
+	}
+	candidate.Name = name
+
+	defaults, err := managerconfig.Load(ctx, r.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ManagerConfig: %w", err)
+	}
+	managerconfig.ApplyStoragePolicyDefaults(&candidate.Spec, defaults)
+
+	clusters, err := r.findMatchingClusters(ctx, candidate)
+	if err != nil {
+		return nil, err
+	}
+	preview := &PolicyPreview{
+		Policy:      candidate.Namespace + "/" + name,
+		GeneratedAt: r.now().UTC(),
+		Clusters:    make([]ClusterPreview, 0, len(clusters)),
+	}
+	for _, cluster := range clusters {
+		preview.Clusters = append(preview.Clusters, r.previewCluster(ctx, candidate, cluster))
+	}
+	return preview, nil
+}
+
+// previewCluster evaluates a cluster the way processCluster does, without acting on
+// the decision
+func (r *StoragePolicyReconciler) previewCluster(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+) ClusterPreview {
+	preview := ClusterPreview{Namespace: cluster.Namespace, Name: cluster.Name}
+	fail := func(err error) ClusterPreview {
+		logf.FromContext(ctx).Error(err, "Failed to preview cluster", "cluster", cluster.Name)
+		preview.Status = previewStatusError
+		preview.Error = err.Error()
+		return preview
+	}
+
+	if cluster.Hibernated {
+		preview.Status = previewStatusHibernated
+		return preview
+	}
+	annotations, err := r.discovery.GetClusterAnnotations(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		return fail(fmt.Errorf("failed to get cluster annotations: %w", err))
+	}
+	clusterAnnotations := &clusterAnnotationsWrapper{annotations: annotations, clock: r.Clock}
+	if clusterAnnotations.annotations == nil {
+		clusterAnnotations.annotations = make(map[string]string)
+	}
+	if clusterAnnotations.IsPaused() {
+		preview.Status = previewStatusPaused
+		preview.Decision = &cnpgv1alpha1.EvaluationDecision{
+			Action:       string(policy.ActionTypeNone),
+			BlockReasons: []string{"cluster is paused: " + clusterAnnotations.GetPauseReason()},
+			Result:       previewStatusPaused,
+		}
+		return preview
+	}
+
+	if r.metricsCollector == nil {
+		return fail(fmt.Errorf("no metrics collector is configured"))
+	}
+	pods, err := r.discovery.GetClusterPods(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		return fail(fmt.Errorf("failed to get cluster pods: %w", err))
+	}
+	clusterMetrics, err := r.metricsCollector.CollectClusterMetricsFrom(ctx, metrics.Source(policyObj.Spec.MetricsSource),
+		cluster.Name, cluster.Namespace, pods)
+	if err != nil {
+		return fail(fmt.Errorf("failed to collect metrics: %w", err))
+	}
+	activeRemediation, err := r.hasActiveRemediation(ctx, cluster)
+	if err != nil {
+		return fail(err)
+	}
+
+	evalCtx := clusterEvaluationContext(policyObj, cluster, clusterMetrics, clusterAnnotations, activeRemediation)
+	evalResult, err := r.evaluator.FullEvaluation(evalCtx, policyObj)
+	if err != nil {
+		return fail(fmt.Errorf("evaluation failed: %w", err))
+	}
+	decision := policy.Explain(evalCtx, evalResult, policyObj)
+	if action := evalResult.GetHighestPriorityAction(); evalResult.HasPendingActions() && action != nil {
+		decision.Action = string(action.Action)
+		eventType := cnpgv1alpha1.EventTypeExpansion
+		if action.Action == policy.ActionTypeWALCleanup {
+			eventType = cnpgv1alpha1.EventTypeWALCleanup
+		}
+		switch {
+		case action.Action == policy.ActionTypeAlert:
+		case policy.IsPolicyPaused(policyObj, r.now()):
+			decision.BlockReasons = append(decision.BlockReasons, "policy is paused")
+		case r.isDryRun(policyObj, eventType):
+			decision.BlockReasons = append(decision.BlockReasons, "dry-run mode")
+		}
+	}
+	decision.Result = previewStatusEvaluated
+	preview.Status = previewStatusEvaluated
+	preview.Decision = decision
+	return preview
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// checkQuota plans the expansion of a cluster target and compares the growth with the
+// ResourceQuotas and LimitRanges of the cluster's namespace. It returns a
+// *remediation.QuotaExceeded when the API server would reject the resize, so the
+// expansion is skipped with the limit to raise instead. A failed check does not block
+// the expansion
+func (r *StoragePolicyReconciler) checkQuota(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	target cnpgv1alpha1.ExpansionTarget,
+) error {
+	if r.quotaChecker == nil || r.expansionEngine == nil {
+		return nil
+	}
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name, "tablespace", target.Tablespace,
+		"volume", target.Volume)
+
+	exceeded, err := r.planQuota(ctx, policyObj, cluster, target)
+	if err != nil {
+		log.Error(err, "Failed to check quotas, expanding without it")
+		return nil
+	}
+	if exceeded == nil {
+		return nil
+	}
+
+	log.Info("Skipping expansion, quota exceeded", "kind", exceeded.Kind, "name", exceeded.Name,
+		"resource", exceeded.Resource, "required", exceeded.RequiredBytes, "limit", exceeded.LimitBytes,
+		"pvcs", exceeded.PVCs)
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonQuotaExceeded,
+		"Expansion skipped: %s", exceeded.Error())
+	r.alertQuotaExceeded(ctx, policyObj, cluster, exceeded)
+	return exceeded
+}
+
+// planQuota plans the expansion of a cluster target and returns the ResourceQuota or
+// LimitRange of the namespace it would exceed, nil when there is none
+func (r *StoragePolicyReconciler) planQuota(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	target cnpgv1alpha1.ExpansionTarget,
+) (*remediation.QuotaExceeded, error) {
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PVCs: %w", err)
+	}
+	plan := r.expansionEngine.PlanClusterExpansion(ctx, &remediation.ExpansionRequest{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		PVCs:             pvcs,
+		Policy:           policyObj,
+		DryRun:           true,
+		Target:           target,
+	})
+	return r.quotaChecker.CheckExpansion(ctx, cluster.Namespace, pvcs, plan)
+}
+
+// isQuotaExceeded returns true if err reports an expansion skipped because a
+// ResourceQuota or LimitRange would reject it
+func isQuotaExceeded(err error) bool {
+	var exceeded *remediation.QuotaExceeded
+	return errors.As(err, &exceeded)
+}
+
+// alertQuotaExceeded sends a warning alert with the quota or limit that blocks an
+// expansion and the value it needs to be raised to
+func (r *StoragePolicyReconciler) alertQuotaExceeded(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	exceeded *remediation.QuotaExceeded,
+) {
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	details := map[string]string{
+		"policy":          policyObj.Name,
+		"kind":            exceeded.Kind,
+		"name":            exceeded.Name,
+		"resource":        string(exceeded.Resource),
+		"pvcs":            strings.Join(exceeded.PVCs, ","),
+		"limit":           remediation.FormatBytes(exceeded.LimitBytes),
+		"suggested_limit": remediation.FormatBytes(exceeded.SuggestedLimit()),
+	}
+	if exceeded.Kind == remediation.KindResourceQuota {
+		details["used"] = remediation.FormatBytes(exceeded.UsedBytes)
+		details["required"] = remediation.FormatBytes(exceeded.RequiredBytes)
+	}
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeQuotaExceeded,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
+		Severity:         alerting.AlertSeverityWarning,
+		Message: fmt.Sprintf("Expansion of cluster %s/%s skipped: %s",
+			cluster.Namespace, cluster.Name, exceeded.Error()),
+		Details:   details,
+		Timestamp: time.Now(),
+	}
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to send quota exceeded alert", "cluster", cluster.Name)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// RBAC for raising ResourceQuotas with quota.autoRaise
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=patch
+
+// manageQuota checks whether the ResourceQuotas and LimitRanges of a cluster's namespace
+// leave room for its next expansion when the policy enables quota, long before the
+// expansion is skipped for them. It alerts when that starts, raises the ResourceQuota
+// when the policy sets autoRaise, and resolves the alert once there is room again. It
+// returns why the quotas cannot hold the next expansion, empty when they can
+func (r *StoragePolicyReconciler) manageQuota(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+) string {
+	if !policyObj.Spec.Quota.Enabled || r.quotaChecker == nil || r.expansionEngine == nil {
+		return ""
+	}
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name)
+	r.recordQuotaHeadroom(ctx, cluster.Namespace)
+
+	exceeded, err := r.planQuota(ctx, policyObj, cluster, clusterExpansionTarget(policyObj))
+	if err != nil {
+		log.Error(err, "Failed to check the quota headroom")
+		return ""
+	}
+	insufficient := quotaInsufficient(policyObj, cluster)
+	if exceeded == nil || r.raiseQuota(ctx, policyObj, cluster, exceeded) {
+		if insufficient {
+			r.resolveAlert(ctx, policyObj, cluster, alerting.AlertTypeQuotaHeadroom)
+		}
+		return ""
+	}
+	if !insufficient {
+		r.alertQuotaHeadroom(ctx, policyObj, cluster, exceeded)
+	}
+	return exceeded.Error()
+}
+
+// recordQuotaHeadroom records the storage headroom of the ResourceQuotas of a namespace
+func (r *StoragePolicyReconciler) recordQuotaHeadroom(ctx context.Context, namespace string) {
+	var quotas corev1.ResourceQuotaList
+	if err := r.List(ctx, &quotas, client.InNamespace(namespace)); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list ResourceQuotas", "namespace", namespace)
+		return
+	}
+	for i := range quotas.Items {
+		for resourceName, headroom := range remediation.StorageHeadroom(&quotas.Items[i]) {
+			metrics.SetQuotaHeadroom(namespace, quotas.Items[i].Name, string(resourceName), headroom)
+		}
+	}
+}
+
+// quotaInsufficient returns true if the cluster's previous status already reported
+// quotas that cannot hold the next expansion
+func quotaInsufficient(policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo) bool {
+	mc := previousManagedCluster(policyObj, cluster, "")
+	if mc == nil {
+		return false
+	}
+	return meta.IsStatusConditionFalse(mc.Conditions, cnpgv1alpha1.ManagedClusterConditionQuotaHeadroom)
+}
+
+// raiseQuota patches the hard limit of a ResourceQuota that cannot hold the next
+// expansion to the limit it needs when the policy sets autoRaise, respecting pauses,
+// dry-run and maxHard. It returns true if the quota was raised
+func (r *StoragePolicyReconciler) raiseQuota(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	exceeded *remediation.QuotaExceeded,
+) bool {
+	config := policyObj.Spec.Quota
+	if !config.AutoRaise || exceeded.Kind != remediation.KindResourceQuota {
+		return false
+	}
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name, "quota", exceeded.Name,
+		"resource", exceeded.Resource)
+	limit := resource.NewQuantity(exceeded.SuggestedLimit(), resource.BinarySI)
+	if config.MaxHard != nil && limit.Cmp(*config.MaxHard) > 0 {
+		log.Info("Not raising the quota above maxHard", "limit", limit.String(), "maxHard", config.MaxHard.String())
+		return false
+	}
+	if policy.IsPolicyPaused(policyObj, r.now()) || r.isDryRun(policyObj, cnpgv1alpha1.EventTypeExpansion) {
+		log.Info("Would raise the quota for the next expansion", "limit", limit.String())
+		return false
+	}
+
+	var quota corev1.ResourceQuota
+	if err := r.Get(ctx, client.ObjectKey{Name: exceeded.Name, Namespace: exceeded.Namespace}, &quota); err != nil {
+		log.Error(err, "Failed to get the quota to raise")
+		return false
+	}
+	previous := quota.Spec.Hard[exceeded.Resource]
+	patch := client.MergeFrom(quota.DeepCopy())
+	if quota.Spec.Hard == nil {
+		quota.Spec.Hard = corev1.ResourceList{}
+	}
+	quota.Spec.Hard[exceeded.Resource] = *limit
+	if err := r.Patch(ctx, &quota, patch); err != nil {
+		log.Error(err, "Failed to raise the quota")
+		return false
+	}
+
+	log.Info("Raised the quota for the next expansion", "from", previous.String(), "to", limit.String())
+	r.events.Cluster(cluster, corev1.EventTypeNormal, recorder.ReasonQuotaRaised,
+		"Raised %s of ResourceQuota %s from %s to %s for the next expansion",
+		exceeded.Resource, exceeded.Name, previous.String(), limit.String())
+	return true
+}
+
+// alertQuotaHeadroom sends the quota_headroom alert and QuotaHeadroomLow event of a
+// cluster whose namespace quotas cannot hold its next expansion
+func (r *StoragePolicyReconciler) alertQuotaHeadroom(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	exceeded *remediation.QuotaExceeded,
+) {
+	message := fmt.Sprintf("The next expansion of cluster %s/%s would be skipped: %s",
+		cluster.Namespace, cluster.Name, exceeded.Error())
+	logf.FromContext(ctx).Info("Insufficient quota headroom", "cluster", cluster.Name, "kind", exceeded.Kind,
+		"name", exceeded.Name, "resource", exceeded.Resource)
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonQuotaHeadroomLow, "%s", message)
+
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeQuotaHeadroom,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
+		Severity:         alerting.AlertSeverityWarning,
+		Message:          message,
+		Details: map[string]string{
+			"policy":          policyObj.Name,
+			"kind":            exceeded.Kind,
+			"name":            exceeded.Name,
+			"resource":        string(exceeded.Resource),
+			"pvcs":            strings.Join(exceeded.PVCs, ","),
+			"limit":           remediation.FormatBytes(exceeded.LimitBytes),
+			"suggested_limit": remediation.FormatBytes(exceeded.SuggestedLimit()),
+		},
+		Timestamp: time.Now(),
+	}
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to send quota headroom alert", "cluster", cluster.Name)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/connection"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+)
+
+// processConnections evaluates the clusters matched by the policy in each of its
+// ClusterConnections. Remote clusters are monitored and alerted on only: remediation
+// needs StorageEvents and pod exec in the cluster being remediated. It returns the
+// status entries and the clusters or connections that could not be processed.
+func (r *StoragePolicyReconciler) processConnections(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) ([]cnpgv1alpha1.ManagedCluster, []string) {
+	log := logf.FromContext(ctx)
+
+	var managed []cnpgv1alpha1.ManagedCluster
+	var failed []string
+	for _, name := range policyObj.Spec.Connections {
+		conn := r.Connections.Get(name, policyObj.Namespace)
+		if conn == nil {
+			log.Info("ClusterConnection is not connected, skipping its clusters", "connection", name)
+			failed = append(failed, connection.Key(name, policyObj.Namespace))
+			continue
+		}
+
+		clusters, err := matchClusters(ctx, conn.Discovery, policyObj.Spec.Selector, policyObj.Spec.IncludeClusters,
+			policyObj.Spec.ExcludeClusters)
+		if err != nil {
+			log.Error(err, "Failed to find matching clusters", "connection", name)
+			failed = append(failed, connection.Key(name, policyObj.Namespace))
+			continue
+		}
+
+		for _, cluster := range clusters {
+			mc, err := r.processRemoteCluster(ctx, policyObj, conn, cluster)
+			if err != nil {
+				log.Error(err, "Failed to process cluster", "connection", name,
+					"cluster", cluster.Name, "namespace", cluster.Namespace)
+				failed = append(failed, fmt.Sprintf("%s:%s/%s", name, cluster.Namespace, cluster.Name))
+				mc = &cnpgv1alpha1.ManagedCluster{
+					Name:        cluster.Name,
+					Namespace:   cluster.Namespace,
+					Connection:  name,
+					LastChecked: metav1.Now(),
+					Status:      "Error",
+					Conditions:  clusterConditions(policyObj, cluster, name, policy.ClusterConditionState{Err: err}),
+				}
+			}
+			managed = append(managed, *mc)
+		}
+	}
+	return managed, failed
+}
+
+// processRemoteCluster collects the storage usage of a cluster in a downstream Kubernetes
+// cluster and alerts when a threshold is breached
+func (r *StoragePolicyReconciler) processRemoteCluster(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	conn *connection.Connection,
+	cluster cnpg.ClusterInfo,
+) (*cnpgv1alpha1.ManagedCluster, error) {
+	if cluster.Hibernated {
+		return hibernatedCluster(policyObj, cluster, conn.Name), nil
+	}
+
+	pods, err := conn.Discovery.GetClusterPods(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster pods: %w", err)
+	}
+
+	clusterMetrics, err := conn.Collector.CollectClusterMetrics(ctx, cluster.Name, cluster.Namespace, pods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect metrics: %w", err)
+	}
+
+	usagePercent := clusterMetrics.TotalUsagePercent()
+	metrics.RecordRemoteClusterUsage(conn.Name, cluster.Name, cluster.Namespace, usagePercent)
+
+	status := "Healthy"
+	result := r.evaluator.EvaluateUsage(usagePercent, clusterMetrics.LeastFreeBytes(true), policyObj.Spec.Thresholds)
+	if result.Level != policy.ThresholdLevelNormal {
+		if err := r.sendThresholdAlert(ctx, policyObj, cluster, conn, result); err != nil {
+			logf.FromContext(ctx).Error(err, "Failed to send alert", "connection", conn.Name, "cluster", cluster.Name)
+		}
+		status = fmt.Sprintf("Alert-%s", result.Level)
+	}
+
+	return &cnpgv1alpha1.ManagedCluster{
+		Name:         cluster.Name,
+		Namespace:    cluster.Namespace,
+		Connection:   conn.Name,
+		LastChecked:  metav1.Now(),
+		UsagePercent: int32(usagePercent),
+		Status:       status,
+		Zones:        zoneStatuses(clusterMetrics),
+		Conditions:   clusterConditions(policyObj, cluster, conn.Name, policy.ClusterConditionState{Threshold: &result}),
+	}, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// checkPendingResizes reports the PVCs of a cluster whose filesystem resize is pending
+// longer than the policy's timeout, alerting once per PVC. When the policy allows it,
+// the pod of one stuck instance is deleted so CNPG recreates it and the filesystem is
+// resized offline
+func (r *StoragePolicyReconciler) checkPendingResizes(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+) []cnpgv1alpha1.PendingResizeStatus {
+	log := logf.FromContext(ctx)
+
+	var previous []cnpgv1alpha1.PendingResizeStatus
+	if mc := previousManagedCluster(policyObj, cluster, ""); mc != nil {
+		previous = mc.PendingResizes
+	}
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to list PVCs for pending resizes", "cluster", cluster.Name)
+		return previous
+	}
+
+	now := r.now()
+	timeout := remediation.FileSystemResizeTimeout(policyObj)
+	pending := remediation.FindPendingResizes(pvcs, previous, timeout, now)
+	for _, status := range pending {
+		if remediation.FindPendingResize(previous, status.PVC) == nil {
+			r.alertPendingResize(ctx, policyObj, cluster, status, now)
+		}
+	}
+
+	if !policyObj.Spec.Expansion.FileSystemResize.RestartPod {
+		return pending
+	}
+	instance := remediation.NextResizeRestart(pending, cluster, timeout, now)
+	switch {
+	case instance == "":
+	case policy.IsPolicyPaused(policyObj, now):
+		log.Info("Policy is paused, not restarting instance for pending resize", "cluster", cluster.Name,
+			"instance", instance)
+	case r.isDryRun(policyObj, cnpgv1alpha1.EventTypeExpansion):
+		log.Info("DryRun: Would restart instance for pending resize", "cluster", cluster.Name, "instance", instance)
+	default:
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: instance, Namespace: cluster.Namespace}}
+		if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to restart instance for pending resize", "cluster", cluster.Name, "instance", instance)
+			break
+		}
+		remediation.MarkResizeRestarted(pending, instance, now)
+		log.Info("Restarted instance to complete pending resize", "cluster", cluster.Name, "instance", instance)
+		r.events.Cluster(cluster, corev1.EventTypeNormal, recorder.ReasonResizeRestart,
+			"Deleted pod %s so its pending filesystem resize completes offline", instance)
+	}
+	return pending
+}
+
+// alertPendingResize sends the resize_pending alert and FileSystemResizePending event of
+// a PVC whose filesystem resize is stuck
+func (r *StoragePolicyReconciler) alertPendingResize(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	status cnpgv1alpha1.PendingResizeStatus,
+	now time.Time,
+) {
+	log := logf.FromContext(ctx)
+
+	pendingFor := now.Sub(status.PendingSince.Time).Round(time.Minute)
+	message := fmt.Sprintf("PVC %s of cluster %s/%s has been waiting for its filesystem resize for %s",
+		status.PVC, cluster.Namespace, cluster.Name, pendingFor)
+	log.Info("Filesystem resize is stuck", "cluster", cluster.Name, "pvc", status.PVC, "pendingFor", pendingFor)
+	r.events.PVC(ctx, status.PVC, cluster.Namespace, corev1.EventTypeWarning, recorder.ReasonFileSystemResizePending,
+		"Filesystem resize has been pending for %s", pendingFor)
+
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeResizePending,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
+		Severity:         alerting.AlertSeverityWarning,
+		Message:          message,
+		Details: map[string]string{
+			"policy":        policyObj.Name,
+			"pvc":           status.PVC,
+			"instance":      status.Instance,
+			"pending_since": status.PendingSince.Format(time.RFC3339),
+			"restart_pod":   fmt.Sprintf("%t", policyObj.Spec.Expansion.FileSystemResize.RestartPod),
+		},
+		Timestamp: now,
+	}
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		log.Error(err, "Failed to send pending resize alert", "cluster", cluster.Name)
+	}
+}
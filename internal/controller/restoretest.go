@@ -0,0 +1,176 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+// restoreReadyInterval is how often the wait-ready step re-checks the recovery cluster
+const restoreReadyInterval = 30 * time.Second
+
+// RBAC for restore tests: throwaway recovery clusters, copied credentials and the smoke check
+// +kubebuilder:rbac:groups=postgresql.cnpg.io,resources=clusters,verbs=create;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;create;delete
+// +kubebuilder:rbac:groups=barmancloud.cnpg.io,resources=objectstores,verbs=get;create;delete
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+
+// reconcileRestoreTest runs a restore-test event created by a BackupPolicy. Restore
+// tests are not retried: a failure is the result the test reports. Whatever the test
+// created is deleted on failure as well. No restore test runs while globalDryRun is set.
+func (r *StorageEventReconciler) reconcileRestoreTest(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	globalDryRun bool,
+) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var backupPolicy cnpgv1alpha1.BackupPolicy
+	policyKey := client.ObjectKey{Name: event.Spec.PolicyRef.Name, Namespace: event.Spec.PolicyRef.Namespace}
+	if err := r.Get(ctx, policyKey, &backupPolicy); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, r.markFailed(ctx, event, "PolicyNotFound",
+				fmt.Sprintf("BackupPolicy %s/%s no longer exists", policyKey.Namespace, policyKey.Name))
+		}
+		return ctrl.Result{}, err
+	}
+
+	if globalDryRun {
+		log.Info("DryRun: not executing restore test", "event", event.Name)
+		return ctrl.Result{}, r.markCompleted(ctx, event, "Skipped: dry-run mode enabled")
+	}
+
+	if event.Status.Phase != cnpgv1alpha1.EventPhaseInProgress {
+		now := metav1.Now()
+		event.Status.Phase = cnpgv1alpha1.EventPhaseInProgress
+		if event.Status.StartTime == nil {
+			event.Status.StartTime = &now
+		}
+		remediation.InitSteps(event, remediation.StepsForEvent(event))
+		setEventCondition(event, cnpgv1alpha1.StorageEventConditionProgressing, metav1.ConditionTrue,
+			"Executing", "Executing restore test")
+		if err := r.Status().Update(ctx, event); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	for step := remediation.NextStep(event); step != nil; step = remediation.NextStep(event) {
+		remediation.StartStep(event, step)
+		if err := r.Status().Update(ctx, event); err != nil {
+			return ctrl.Result{}, err
+		}
+		step = remediation.FindStep(event, event.Status.CurrentStep)
+
+		stepCtx, span := tracing.Start(ctx, "StorageEvent."+step.Name, tracing.Step(event.Name,
+			string(event.Spec.EventType), step.Name, event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace)...)
+		outcome, execErr := r.runRestoreTestStep(stepCtx, event, step)
+		tracing.End(span, execErr)
+		step = remediation.FindStep(event, event.Status.CurrentStep)
+		if execErr != nil {
+			log.Error(execErr, "Restore test step failed", "event", event.Name, "step", step.Name)
+			remediation.FailStep(step, execErr.Error())
+			return ctrl.Result{}, r.failRestoreTest(ctx, event, fmt.Errorf("step %s: %w", step.Name, execErr))
+		}
+
+		if outcome.requeueAfter > 0 {
+			step.Message = outcome.message
+			if err := r.Status().Update(ctx, event); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: outcome.requeueAfter}, nil
+		}
+
+		remediation.CompleteStep(event, step, outcome.message)
+		if err := r.Status().Update(ctx, event); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("Restore test step completed", "event", event.Name, "step", step.Name, "message", outcome.message)
+	}
+
+	var duration int32
+	if event.Status.RestoreTest != nil {
+		duration = event.Status.RestoreTest.RestoreDurationSeconds
+	}
+	metrics.RecordRestoreTest(event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace, "success", duration)
+	return ctrl.Result{}, r.markCompleted(ctx, event,
+		fmt.Sprintf("Backup restored in %ds and passed the smoke check", duration))
+}
+
+// runRestoreTestStep dispatches a single restore-test step
+func (r *StorageEventReconciler) runRestoreTestStep(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	step *cnpgv1alpha1.RemediationStep,
+) (stepOutcome, error) {
+	switch step.Name {
+	case remediation.StepRestore:
+		message, err := r.RestoreTester.Restore(ctx, event)
+		return stepOutcome{message: message}, err
+	case remediation.StepWaitReady:
+		started := time.Now()
+		if step.StartTime != nil {
+			started = step.StartTime.Time
+		}
+		ready, message, err := r.RestoreTester.Ready(ctx, event, started, time.Now())
+		if err != nil || ready {
+			return stepOutcome{message: message}, err
+		}
+		return stepOutcome{message: message, requeueAfter: restoreReadyInterval}, nil
+	case remediation.StepSmokeCheck:
+		message, err := r.RestoreTester.SmokeCheck(ctx, event)
+		return stepOutcome{message: message}, err
+	case remediation.StepTeardown:
+		message, err := r.RestoreTester.Teardown(ctx, event)
+		return stepOutcome{message: message}, err
+	default:
+		return stepOutcome{}, fmt.Errorf("unknown restore test step %q", step.Name)
+	}
+}
+
+// failRestoreTest deletes whatever the restore test created and marks the event Failed
+func (r *StorageEventReconciler) failRestoreTest(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	testErr error,
+) error {
+	message := fmt.Sprintf("Restore test failed: %v", testErr)
+	if _, err := r.RestoreTester.Teardown(ctx, event); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to clean up after restore test", "event", event.Name)
+		message += fmt.Sprintf(" (cleanup failed: %v)", err)
+	}
+
+	metrics.RecordError(string(event.Spec.EventType), event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace)
+	metrics.RecordRestoreTest(event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace, "failure", 0)
+	r.events.ClusterByName(ctx, event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace,
+		corev1.EventTypeWarning, recorder.ReasonRestoreTestFailed, "StorageEvent %s: %s", event.Name, message)
+	return r.markFailed(ctx, event, "RestoreTestFailed", message)
+}
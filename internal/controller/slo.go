@@ -0,0 +1,75 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+)
+
+// maxSLOInterval caps the time counted between two evaluations of a cluster, so time
+// the manager was not running is not attributed to the state found afterwards
+const maxSLOInterval = 10 * DefaultRequeueInterval
+
+// trackStorageSLOs adds the time since the previous evaluation of each managed cluster
+// to its SLO status and metrics when the policy sets slo.enabled, and removes the
+// metrics of clusters that are no longer tracked
+func trackStorageSLOs(policyObj *cnpgv1alpha1.StoragePolicy, previous, current []cnpgv1alpha1.ManagedCluster) {
+	cfg := policyObj.Spec.SLO
+	previousByKey := make(map[string]*cnpgv1alpha1.ManagedCluster, len(previous))
+	for i := range previous {
+		previousByKey[managedClusterKey(&previous[i])] = &previous[i]
+	}
+
+	tracked := make(map[string]bool, len(current))
+	for i := range current {
+		mc := &current[i]
+		if !cfg.Enabled {
+			mc.SLO = nil
+			continue
+		}
+		var previousStatus *cnpgv1alpha1.StorageSLOStatus
+		var elapsed time.Duration
+		if prev := previousByKey[managedClusterKey(mc)]; prev != nil && prev.SLO != nil {
+			previousStatus = prev.SLO
+			elapsed = min(max(mc.LastChecked.Sub(prev.LastChecked.Time), 0), maxSLOInterval)
+		}
+
+		violations, known := policy.StorageSLOViolations(mc.Conditions)
+		mc.SLO = policy.TrackStorageSLO(previousStatus, cfg, elapsed, violations, known, mc.LastChecked.Time)
+		tracked[managedClusterKey(mc)] = true
+
+		var trackedSeconds, violationSeconds float64
+		if known {
+			trackedSeconds = elapsed.Seconds()
+			if len(violations) > 0 {
+				violationSeconds = trackedSeconds
+			}
+		}
+		metrics.RecordStorageSLO(mc.Name, mc.Namespace, mc.Connection, trackedSeconds, violationSeconds,
+			policy.SLOObjective(cfg), policy.ErrorBudgetRemaining(mc.SLO, cfg))
+	}
+
+	for key, prev := range previousByKey {
+		if prev.SLO != nil && !tracked[key] {
+			metrics.DeleteStorageSLOMetrics(prev.Name, prev.Namespace, prev.Connection)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// migrationPollInterval is how often a storage class migration re-checks the cluster
+// while it waits for an instance to re-sync or a switchover to complete
+const migrationPollInterval = 30 * time.Second
+
+// requestStorageClassMigration requests a storage-class-migration StorageEvent for a
+// cluster whose volumes do not use the StorageClass of the policy's
+// storageClassMigration. A failed migration to the same StorageClass is not requested
+// again until its StorageEvent is deleted. In dry-run mode the planned action is returned
+func (r *StoragePolicyReconciler) requestStorageClassMigration(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	ca *clusterAnnotationsWrapper,
+) *cnpgv1alpha1.PlannedAction {
+	details := remediation.NewStorageClassMigrationDetails(policyObj)
+	if details == nil {
+		return nil
+	}
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name, "storageClass", details.StorageClass)
+
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to list PVCs for the storage class migration")
+		return nil
+	}
+	if !remediation.NeedsStorageClassMigration(cluster, pvcs, details) {
+		return nil
+	}
+
+	reason := fmt.Sprintf("migrate to StorageClass %s", details.StorageClass)
+	switch {
+	case policy.IsPolicyPaused(policyObj, r.now()):
+		log.Info("Policy is paused, not migrating the storage class")
+		return nil
+	case r.isDryRun(policyObj, cnpgv1alpha1.EventTypeStorageClassMigration):
+		log.Info("DryRun: Would migrate the storage class")
+		action := r.planDryRunAction(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeStorageClassMigration,
+			cnpgv1alpha1.ExpansionTarget{}, reason)
+		return &action
+	case ca.IsCircuitBreakerOpen():
+		log.Info("Circuit breaker is open, not migrating the storage class")
+		return nil
+	}
+
+	failed, err := remediation.FindFailedMigration(ctx, r.Client, cluster.Name, cluster.Namespace, details.StorageClass)
+	if err != nil {
+		log.Error(err, "Failed to check previous storage class migrations")
+		return nil
+	}
+	if failed != nil {
+		log.V(1).Info("Previous storage class migration failed, delete its StorageEvent to retry",
+			"event", failed.Name)
+		return nil
+	}
+
+	if _, err := r.requestRemediation(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeStorageClassMigration,
+		cnpgv1alpha1.ExpansionTarget{}, reason, 0); err != nil {
+		log.Error(err, "Failed to request the storage class migration")
+	}
+	return nil
+}
+
+// updateStorageClass sets the new StorageClass in the Cluster spec, so CNPG creates the
+// PVCs of recreated instances on it
+func (r *StorageEventReconciler) updateStorageClass(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+) (stepOutcome, error) {
+	details := event.Spec.StorageClassMigration
+	if details == nil {
+		return stepOutcome{abort: "storage class migration details are missing"}, nil
+	}
+
+	for _, name := range []string{details.StorageClass, details.WALStorageClass} {
+		if name == "" {
+			continue
+		}
+		var storageClass storagev1.StorageClass
+		if err := r.Get(ctx, client.ObjectKey{Name: name}, &storageClass); err != nil {
+			if apierrors.IsNotFound(err) {
+				return stepOutcome{abort: fmt.Sprintf("StorageClass %s does not exist", name)}, nil
+			}
+			return stepOutcome{}, fmt.Errorf("failed to get StorageClass %s: %w", name, err)
+		}
+	}
+
+	if err := r.discovery.SetStorageClass(ctx, event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace,
+		details.StorageClass, details.WALStorageClass); err != nil {
+		return stepOutcome{}, err
+	}
+	return stepOutcome{message: "Cluster spec uses StorageClass " + details.StorageClass}, nil
+}
+
+// migrateInstances recreates the instances of the cluster on the new StorageClass one
+// at a time. Each call takes at most one action and is requeued until every instance
+// is migrated; the progress is persisted in the event status before acting
+func (r *StorageEventReconciler) migrateInstances(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+) (stepOutcome, error) {
+	log := logf.FromContext(ctx)
+	details := event.Spec.StorageClassMigration
+	if details == nil {
+		return stepOutcome{abort: "storage class migration details are missing"}, nil
+	}
+	if r.ReplicationLag == nil {
+		return stepOutcome{abort: "replication lag cannot be checked without the exec command runner"}, nil
+	}
+	name := event.Spec.ClusterRef.Name
+	namespace := event.Spec.ClusterRef.Namespace
+
+	cluster, err := r.discovery.GetCluster(ctx, name, namespace)
+	if err != nil {
+		return stepOutcome{}, err
+	}
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, name, namespace)
+	if err != nil {
+		return stepOutcome{}, err
+	}
+	state := remediation.MigrationState{Cluster: *cluster, PVCs: pvcs}
+	if primary, err := r.discovery.GetPrimaryPod(ctx, name, namespace); err == nil {
+		if state.ReplicationLag, err = r.ReplicationLag.ReplicationLag(ctx, primary); err != nil {
+			return stepOutcome{}, err
+		}
+	}
+
+	if event.Status.StorageClassMigration == nil {
+		event.Status.StorageClassMigration = &cnpgv1alpha1.StorageClassMigrationStatus{}
+	}
+	status := event.Status.StorageClassMigration
+	now := time.Now()
+	action := remediation.PlanMigration(state, details, status, now)
+	log.Info("Storage class migration", "event", event.Name, "action", action.Type, "instance", action.Instance,
+		"message", action.Message)
+
+	switch action.Type {
+	case remediation.MigrationDone:
+		return stepOutcome{message: action.Message}, nil
+	case remediation.MigrationWait:
+		return stepOutcome{message: action.Message, requeueAfter: migrationPollInterval}, nil
+	case remediation.MigrationAbort:
+		return stepOutcome{abort: action.Message}, nil
+	}
+
+	// Persist the instance being acted on first, so a restart waits for it instead of
+	// touching a second instance
+	previous := status.DeepCopy()
+	remediation.StartMigrationAction(status, action, now)
+	if step := remediation.FindStep(event, event.Status.CurrentStep); step != nil {
+		step.Message = action.Message
+	}
+	if err := r.Status().Update(ctx, event); err != nil {
+		return stepOutcome{}, err
+	}
+
+	switch action.Type {
+	case remediation.MigrationRecreate:
+		err = r.recreateInstance(ctx, namespace, action.Instance, pvcs)
+	case remediation.MigrationSwitchover:
+		err = r.discovery.Switchover(ctx, name, namespace, action.Instance)
+	}
+	if err != nil {
+		// Nothing was changed, so the action is retried rather than waited for
+		event.Status.StorageClassMigration = previous
+		return stepOutcome{}, err
+	}
+	r.events.ClusterByName(ctx, name, namespace, corev1.EventTypeNormal, recorder.ReasonStorageClassMigrating,
+		"StorageEvent %s: %s", event.Name, action.Message)
+	return stepOutcome{message: action.Message, requeueAfter: migrationPollInterval}, nil
+}
+
+// recreateInstance deletes the PVCs and then the pod of an instance, the way the cnpg
+// kubectl plugin's destroy command does. CNPG replaces it with a new instance whose PVCs
+// use the StorageClass of the Cluster spec, cloned from the primary
+func (r *StorageEventReconciler) recreateInstance(
+	ctx context.Context,
+	namespace, instance string,
+	pvcs []corev1.PersistentVolumeClaim,
+) error {
+	for i := range pvcs {
+		if pvcs[i].Labels[cnpg.LabelInstanceName] != instance {
+			continue
+		}
+		if err := r.Delete(ctx, &pvcs[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete PVC %s: %w", pvcs[i].Name, err)
+		}
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: instance, Namespace: namespace}}
+	if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete pod %s: %w", instance, err)
+	}
+	return nil
+}
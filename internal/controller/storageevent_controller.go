@@ -0,0 +1,1077 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/annotations"
+	"github.com/supporttools/cnpg-storage-manager/pkg/backup"
+	"github.com/supporttools/cnpg-storage-manager/pkg/clock"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/hooks"
+	"github.com/supporttools/cnpg-storage-manager/pkg/managerconfig"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+	"github.com/supporttools/cnpg-storage-manager/pkg/sharding"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+const (
+	// expansionVerifyInterval is how often the verify step re-checks PVC capacity
+	expansionVerifyInterval = 15 * time.Second
+	// expansionVerifyTimeout bounds how long a verify attempt waits for resizes to finish
+	expansionVerifyTimeout = 10 * time.Minute
+)
+
+// StorageEventReconciler executes the remediation requested by Pending StorageEvents.
+// Progress is persisted in the event status before and after each step so that an
+// operator restart resumes in-flight operations instead of losing track of them.
+type StorageEventReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	RestConfig *rest.Config
+
+	// APIReader reads recommendation ConfigMaps and Secrets without caching them
+	// cluster-wide. Defaults to the client when nil.
+	APIReader client.Reader
+
+	// GlobalDryRun prevents any event from being executed when true
+	GlobalDryRun bool
+
+	// ReadOnly runs the reconciler in monitor-only mode: no event is executed and no
+	// command is run in instance pods
+	ReadOnly bool
+
+	// MaxRetries is the number of attempts before an event is marked Failed.
+	// Defaults to remediation.DefaultMaxEventRetries when zero.
+	MaxRetries int32
+
+	// RemediationDeadline bounds the commands a WAL cleanup runs across all instances
+	// of a cluster. Defaults to remediation.DefaultRemediationDeadline when zero.
+	RemediationDeadline time.Duration
+
+	// CommandRunner runs WAL cleanup commands. Defaults to pod exec when nil.
+	CommandRunner runner.CommandRunner
+
+	// RestoreTester runs restore-test events. Defaults to one using the command runner when nil.
+	RestoreTester *backup.RestoreTester
+
+	// ReplicationLag reads replica lag during storage class migrations, which are aborted when it is nil
+	ReplicationLag *metrics.ReplicationLagCollector
+
+	// Recorder records Kubernetes Events on CNPG clusters and PVCs. Events are not recorded when nil.
+	Recorder record.EventRecorder
+
+	// Shard limits the reconciler to the events of policies in this replica's shard. The
+	// zero value reconciles every event
+	Shard sharding.Shard
+
+	// Hooks calls the preAction and postAction hooks of policies. Defaults to one
+	// reading signing Secrets through the client when nil
+	Hooks *hooks.Caller
+
+	// AgentCollector collects volume usage from the node agent for policies with
+	// metricsSource agent when measuring the effectiveness of remediation
+	AgentCollector *metrics.AgentCollector
+
+	// Clock is the time policy pauses and dry-run trials are checked against, the one of
+	// the StoragePolicy reconciler. The system clock when nil
+	Clock clock.Clock
+
+	// Internal components
+	discovery        *cnpg.Discovery
+	metricsCollector *metrics.Collector
+	events           *recorder.Recorder
+	expansionEngine  *remediation.ExpansionEngine
+	walCleanupEngine *remediation.WALCleanupEngine
+	recommendations  *remediation.RecommendationPublisher
+}
+
+// now returns the current time of the reconciler's clock
+func (r *StorageEventReconciler) now() time.Time {
+	return clock.OrReal(r.Clock).Now()
+}
+
+// RBAC for publishing expansion recommendations
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+
+// RBAC for the Job command runner (--command-runner=job)
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;create;delete
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+
+// Reconcile drives a StorageEvent from Pending through InProgress to Completed or Failed.
+func (r *StorageEventReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	startTime := time.Now()
+
+	defer func() {
+		metrics.ReconcileDuration.WithLabelValues("storageevent").Observe(time.Since(startTime).Seconds())
+	}()
+
+	var event cnpgv1alpha1.StorageEvent
+	if err := r.Get(ctx, req.NamespacedName, &event); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get StorageEvent")
+		return ctrl.Result{}, err
+	}
+
+	// Completed expansions and WAL cleanups are measured again once they took effect
+	if !remediation.IsEventActive(&event) && remediation.IsEffectivenessPending(&event) &&
+		r.Shard.Owns(event.Spec.PolicyRef.Namespace, event.Spec.PolicyRef.Name) {
+		r.initComponents()
+		return r.reconcileEffectiveness(ctx, &event)
+	}
+
+	// Terminal events and audit-only records need no work, and events of other shards'
+	// policies are executed by those shards
+	if !remediation.IsEventActive(&event) || !isExecutableEvent(&event) ||
+		!r.Shard.Owns(event.Spec.PolicyRef.Namespace, event.Spec.PolicyRef.Name) {
+		return ctrl.Result{}, nil
+	}
+
+	// Respect retry backoff
+	if event.Status.NextRetryTime != nil {
+		if remaining := time.Until(event.Status.NextRetryTime.Time); remaining > 0 {
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+	}
+
+	r.initComponents()
+
+	defaults, err := managerconfig.Load(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	globalDryRun := r.ReadOnly || r.GlobalDryRun || managerconfig.DryRun(defaults)
+
+	// Restore tests belong to a BackupPolicy and follow their own lifecycle
+	if event.Spec.EventType == cnpgv1alpha1.EventTypeRestoreTest {
+		return r.reconcileRestoreTest(ctx, &event, globalDryRun)
+	}
+
+	var policyObj cnpgv1alpha1.StoragePolicy
+	policyKey := client.ObjectKey{Name: event.Spec.PolicyRef.Name, Namespace: event.Spec.PolicyRef.Namespace}
+	if err := r.Get(ctx, policyKey, &policyObj); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, r.markFailed(ctx, &event, "PolicyNotFound",
+				fmt.Sprintf("StoragePolicy %s/%s no longer exists", policyKey.Namespace, policyKey.Name))
+		}
+		return ctrl.Result{}, err
+	}
+	// The defaulted spec is only used in memory and must never be written back
+	managerconfig.ApplyStoragePolicyDefaults(&policyObj.Spec, defaults)
+
+	now := r.now()
+	if globalDryRun || policy.IsActionDryRun(&policyObj, event.Spec.EventType, now) {
+		log.Info("DryRun: not executing storage event", "event", event.Name, "type", event.Spec.EventType)
+		return ctrl.Result{}, r.markCompleted(ctx, &event, "Skipped: dry-run mode enabled")
+	}
+
+	// Events that have not started wait out a policy pause; running ones finish
+	if event.Status.Phase != cnpgv1alpha1.EventPhaseInProgress && policy.IsPolicyPaused(&policyObj, now) {
+		log.Info("Policy is paused, holding storage event", "event", event.Name, "type", event.Spec.EventType)
+		return ctrl.Result{RequeueAfter: pausedEventRequeue(&policyObj, now)}, nil
+	}
+
+	// Hold the event until it is approved; approving it updates the object and triggers a reconcile
+	if !remediation.IsEventApproved(&event) {
+		return ctrl.Result{}, r.awaitApproval(ctx, &event)
+	}
+
+	// The preAction hook may veto the event before any work is done
+	vetoed, err := r.runPreActionHook(ctx, &event, &policyObj)
+	if vetoed {
+		return ctrl.Result{}, err
+	}
+	if err != nil {
+		return r.handleFailure(ctx, &event, &policyObj, err)
+	}
+
+	// Persist InProgress and the step list before doing any work so a restart can resume
+	if event.Status.Phase != cnpgv1alpha1.EventPhaseInProgress {
+		now := metav1.Now()
+		event.Status.Phase = cnpgv1alpha1.EventPhaseInProgress
+		if event.Status.StartTime == nil {
+			event.Status.StartTime = &now
+		}
+		event.Status.NextRetryTime = nil
+		if event.Spec.ApprovalRequired {
+			setEventCondition(&event, cnpgv1alpha1.StorageEventConditionApproved, metav1.ConditionTrue,
+				"Approved", "Event approved for execution")
+		}
+		remediation.InitSteps(&event, remediation.StepsForEvent(&event))
+		if event.Status.Effectiveness == nil && remediation.TracksEffectiveness(&policyObj, &event) {
+			r.startEffectiveness(ctx, &event, &policyObj)
+		}
+		setEventCondition(&event, cnpgv1alpha1.StorageEventConditionProgressing, metav1.ConditionTrue,
+			"Executing", fmt.Sprintf("Executing %s (attempt %d)", event.Spec.EventType, event.Status.RetryCount+1))
+		if err := r.Status().Update(ctx, &event); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else {
+		log.Info("Resuming in-progress storage event", "event", event.Name, "type", event.Spec.EventType,
+			"step", event.Status.CurrentStep)
+		// Events created before the transaction log existed have no steps recorded
+		remediation.InitSteps(&event, remediation.StepsForEvent(&event))
+	}
+
+	// Run the remaining steps in order, persisting the log after each transition
+	for step := remediation.NextStep(&event); step != nil; step = remediation.NextStep(&event) {
+		remediation.StartStep(&event, step)
+		if err := r.Status().Update(ctx, &event); err != nil {
+			return ctrl.Result{}, err
+		}
+		// The update refreshed the event; re-resolve the step pointer
+		step = remediation.FindStep(&event, event.Status.CurrentStep)
+
+		stepCtx, span := tracing.Start(ctx, "StorageEvent."+step.Name, tracing.Step(event.Name,
+			string(event.Spec.EventType), step.Name, event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace)...)
+		outcome, execErr := r.runStep(stepCtx, &event, &policyObj, step.Name)
+		tracing.End(span, execErr)
+		step = remediation.FindStep(&event, event.Status.CurrentStep)
+		if execErr != nil {
+			log.Error(execErr, "Storage event step failed", "event", event.Name, "step", step.Name)
+			remediation.FailStep(step, execErr.Error())
+			return r.handleFailure(ctx, &event, &policyObj, fmt.Errorf("step %s: %w", step.Name, execErr))
+		}
+
+		if outcome.abort != "" {
+			log.Info("Storage event aborted", "event", event.Name, "step", step.Name, "reason", outcome.abort)
+			remediation.FailStep(step, outcome.abort)
+			if err := r.markFailed(ctx, &event, "Aborted", outcome.abort); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.events.ClusterByName(ctx, event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace,
+				corev1.EventTypeWarning, recorder.ReasonRemediationAborted, "%s (StorageEvent %s) aborted: %s",
+				event.Spec.EventType, event.Name, outcome.abort)
+			r.runPostActionHook(ctx, &event, &policyObj)
+			return ctrl.Result{}, nil
+		}
+
+		if outcome.requeueAfter > 0 {
+			step.Message = outcome.message
+			if err := r.Status().Update(ctx, &event); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: outcome.requeueAfter}, nil
+		}
+
+		remediation.CompleteStep(&event, step, outcome.message)
+		if err := r.Status().Update(ctx, &event); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("Storage event step completed", "event", event.Name, "step", step.Name, "message", outcome.message)
+	}
+
+	if err := r.markCompleted(ctx, &event, remediation.StepSummary(&event)); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recordClusterSuccess(ctx, &event)
+	r.recordCompletionEvent(ctx, &event)
+	r.runPostActionHook(ctx, &event, &policyObj)
+
+	if remediation.IsEffectivenessPending(&event) {
+		return ctrl.Result{RequeueAfter: remediation.EffectivenessDelay(&policyObj)}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// stepOutcome is the result of running one remediation step. A non-zero
+// requeueAfter leaves the step in progress to be checked again later.
+type stepOutcome struct {
+	message      string
+	requeueAfter time.Duration
+	// abort stops the event as Failed without retrying, with abort as the reason
+	abort string
+}
+
+// runStep dispatches a single named step
+func (r *StorageEventReconciler) runStep(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	name string,
+) (stepOutcome, error) {
+	switch name {
+	case remediation.StepPlan:
+		return r.planExpansion(ctx, event, policyObj)
+	case remediation.StepExpand:
+		return r.resizePVCs(ctx, event, policyObj)
+	case remediation.StepVerify:
+		return r.verifyExpansion(ctx, event)
+	case remediation.StepRecommend:
+		return r.publishRecommendation(ctx, event, policyObj)
+	case remediation.StepLocatePrimary:
+		return r.locatePrimary(ctx, event)
+	case remediation.StepCleanup:
+		return r.cleanupWAL(ctx, event, policyObj)
+	case remediation.StepUpdateSpec:
+		return r.updateStorageClass(ctx, event)
+	case remediation.StepMigrateInstances:
+		return r.migrateInstances(ctx, event)
+	case remediation.StepModifyVolumes:
+		return r.modifyVolumes(ctx, event)
+	case remediation.StepVerifyModification:
+		return r.verifyModification(ctx, event)
+	default:
+		return stepOutcome{}, fmt.Errorf("unknown remediation step %q", name)
+	}
+}
+
+// awaitApproval records that the event is waiting for manual approval
+func (r *StorageEventReconciler) awaitApproval(ctx context.Context, event *cnpgv1alpha1.StorageEvent) error {
+	if meta.IsStatusConditionFalse(event.Status.Conditions, cnpgv1alpha1.StorageEventConditionApproved) {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("Storage event awaiting approval", "event", event.Name, "type", event.Spec.EventType)
+	event.Status.Phase = cnpgv1alpha1.EventPhasePending
+	event.Status.Message = fmt.Sprintf("Awaiting approval: set spec.approved=true or annotate with %s=true",
+		annotations.AnnotationApproved)
+	setEventCondition(event, cnpgv1alpha1.StorageEventConditionApproved, metav1.ConditionFalse,
+		"AwaitingApproval", event.Status.Message)
+	return r.Status().Update(ctx, event)
+}
+
+// isExecutableEvent returns true for event types that represent work to perform
+func isExecutableEvent(event *cnpgv1alpha1.StorageEvent) bool {
+	if event.Spec.DryRun {
+		return false
+	}
+	return event.Spec.EventType == cnpgv1alpha1.EventTypeExpansion ||
+		event.Spec.EventType == cnpgv1alpha1.EventTypeWALCleanup ||
+		event.Spec.EventType == cnpgv1alpha1.EventTypeRestoreTest ||
+		event.Spec.EventType == cnpgv1alpha1.EventTypeStorageClassMigration ||
+		event.Spec.EventType == cnpgv1alpha1.EventTypeVolumeAttributesChange
+}
+
+// initComponents initializes internal components if not already done
+func (r *StorageEventReconciler) initComponents() {
+	if r.discovery == nil {
+		r.discovery = cnpg.NewDiscovery(r.Client)
+	}
+	if r.metricsCollector == nil && r.RestConfig != nil {
+		r.metricsCollector = metrics.NewCollector(r.Client, r.RestConfig)
+		if r.CommandRunner != nil || r.ReadOnly {
+			r.metricsCollector.SetCommandRunner(r.commandRunner())
+		}
+		if r.AgentCollector != nil {
+			r.metricsCollector.SetAgentCollector(r.AgentCollector)
+		}
+	}
+	if r.expansionEngine == nil {
+		r.expansionEngine = remediation.NewExpansionEngine(r.Client)
+	}
+	if r.recommendations == nil {
+		reader := r.APIReader
+		if reader == nil {
+			reader = r.Client
+		}
+		r.recommendations = remediation.NewRecommendationPublisher(r.Client, reader)
+	}
+	if r.events == nil && r.Recorder != nil {
+		r.events = recorder.New(r.Recorder, r.Client)
+	}
+	if r.walCleanupEngine == nil && r.CommandRunner != nil && !r.ReadOnly {
+		r.walCleanupEngine = remediation.NewWALCleanupEngineWithRunner(r.Client, r.CommandRunner)
+	}
+	if r.RestoreTester == nil {
+		r.RestoreTester = backup.NewRestoreTester(r.Client, r.Client, r.commandRunner())
+	}
+	if r.Hooks == nil {
+		r.Hooks = hooks.NewCallerFromReader(r.Client)
+	}
+	if r.walCleanupEngine == nil && r.RestConfig != nil && !r.ReadOnly {
+		// WAL cleanup engine requires rest config for pod exec
+		engine, err := remediation.NewWALCleanupEngine(r.Client, r.RestConfig)
+		if err == nil {
+			r.walCleanupEngine = engine
+		}
+	}
+}
+
+// commandRunner returns the runner of commands in instance pods, nil in read-only mode
+func (r *StorageEventReconciler) commandRunner() runner.CommandRunner {
+	if r.ReadOnly {
+		return nil
+	}
+	return r.CommandRunner
+}
+
+// planExpansion computes target sizes and records them as PVC statuses. The plan is
+// kept across retries so every attempt converges on the same sizes.
+func (r *StorageEventReconciler) planExpansion(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) (stepOutcome, error) {
+	log := logf.FromContext(ctx)
+	clusterName := event.Spec.ClusterRef.Name
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+
+	if len(event.Status.PVCStatuses) > 0 {
+		return stepOutcome{message: fmt.Sprintf("%d PVCs planned", len(event.Status.PVCStatuses))}, nil
+	}
+
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, clusterName, clusterNamespace)
+	if err != nil {
+		return stepOutcome{}, fmt.Errorf("failed to get cluster PVCs: %w", err)
+	}
+
+	plan := r.expansionEngine.PlanClusterExpansion(ctx, &remediation.ExpansionRequest{
+		ClusterName:      clusterName,
+		ClusterNamespace: clusterNamespace,
+		PVCs:             pvcs,
+		Policy:           policyObj,
+		Reason:           event.Spec.Reason,
+		Target:           event.Spec.ExpansionTarget,
+	})
+	plan = remediation.HoldBackIncompleteInstances(plan)
+
+	statuses := make([]cnpgv1alpha1.PVCStatus, 0, len(plan))
+	for _, planned := range plan {
+		if planned.Skipped {
+			log.Info("PVC skipped", "pvc", planned.PVCName, "reason", planned.SkipCode, "message", planned.SkipReason)
+			metrics.RecordExpansionSkipped(clusterName, clusterNamespace, string(planned.SkipCode))
+			continue
+		}
+		originalSize := planned.OriginalSize.DeepCopy()
+		newSize := planned.NewSize.DeepCopy()
+		status := cnpgv1alpha1.PVCStatus{
+			Name:         planned.PVCName,
+			Phase:        cnpgv1alpha1.PVCPhasePending,
+			Instance:     planned.Instance,
+			OriginalSize: &originalSize,
+			NewSize:      &newSize,
+		}
+		if planned.Error != "" {
+			// Preflight failures are not retried, and do not fail the event either, so
+			// a permanently ineligible PVC cannot exhaust retries and trip the circuit breaker
+			log.Info("PVC skipped", "pvc", planned.PVCName, "reason", remediation.SkipCodePreflightError,
+				"message", planned.Error)
+			metrics.RecordExpansionSkipped(clusterName, clusterNamespace, string(remediation.SkipCodePreflightError))
+			status.Phase = cnpgv1alpha1.PVCPhaseSkipped
+			status.NewSize = nil
+			status.Error = planned.Error
+		}
+		statuses = append(statuses, status)
+	}
+
+	if rollout := policyObj.Spec.Expansion.Rollout; rollout != nil && len(statuses) > 0 {
+		cluster, err := r.discovery.GetCluster(ctx, clusterName, clusterNamespace)
+		if err != nil {
+			return stepOutcome{}, fmt.Errorf("failed to get cluster: %w", err)
+		}
+		remediation.OrderByInstance(statuses, cluster.Status.CurrentPrimary, rollout.Order)
+	}
+
+	event.Status.PVCStatuses = statuses
+	if len(statuses) == 0 {
+		return stepOutcome{message: "No PVCs require expansion"}, nil
+	}
+	return stepOutcome{message: fmt.Sprintf("%d PVCs planned", len(statuses))}, nil
+}
+
+// resizePVCs issues the planned resize for each PVC, instance by instance. PVCs
+// already resized in a previous attempt are not touched again. When a resize of an
+// instance fails, its remaining PVCs are held back and retried with it on the next
+// attempt, and the failure is reported for the instance as a whole. Policies with a
+// rollout expand one instance at a time instead
+func (r *StorageEventReconciler) resizePVCs(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) (stepOutcome, error) {
+	if policyObj.Spec.Expansion.Rollout != nil {
+		return r.rollOutExpansion(ctx, event, policyObj.Spec.Expansion.Rollout)
+	}
+	clusterName := event.Spec.ClusterRef.Name
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+
+	var failures []string
+	var resized, skipped int
+	var bytesAdded int64
+	for _, group := range instanceGroups(event.Status.PVCStatuses) {
+		result := r.resizeInstance(ctx, event, group)
+		resized += result.resized
+		skipped += result.skipped
+		bytesAdded += result.bytesAdded
+		if result.failure != "" {
+			failures = append(failures, result.failure)
+		}
+	}
+
+	if len(failures) > 0 {
+		metrics.RecordExpansion(clusterName, clusterNamespace, "failure", 0, event.Name)
+		return stepOutcome{}, fmt.Errorf("expansion failed for %s", strings.Join(failures, "; "))
+	}
+
+	metrics.RecordExpansion(clusterName, clusterNamespace, "success", bytesAdded, event.Name)
+	message := fmt.Sprintf("%d PVCs resized, %d bytes added", resized, bytesAdded)
+	if skipped > 0 {
+		message += fmt.Sprintf(", %d PVCs skipped after failing preflight", skipped)
+	}
+	return stepOutcome{message: message}, nil
+}
+
+// instanceResize is the outcome of resizing the PVCs of an instance
+type instanceResize struct {
+	resized, skipped int
+	bytesAdded       int64
+	// failure reports the instance whose resize failed
+	failure string
+}
+
+// resizeInstance issues the planned resize of the PVCs of an instance that were not
+// resized yet, all together
+func (r *StorageEventReconciler) resizeInstance(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	group []int,
+) instanceResize {
+	log := logf.FromContext(ctx)
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+
+	var result instanceResize
+	var pending []*cnpgv1alpha1.PVCStatus
+	var resizes []remediation.PVCResize
+	for _, i := range group {
+		status := &event.Status.PVCStatuses[i]
+		if status.Phase == cnpgv1alpha1.PVCPhaseCompleted || status.Phase == cnpgv1alpha1.PVCPhaseInProgress {
+			continue
+		}
+		if status.Phase == cnpgv1alpha1.PVCPhaseSkipped || status.NewSize == nil {
+			result.skipped++
+			continue
+		}
+		pending = append(pending, status)
+		resizes = append(resizes, remediation.PVCResize{Name: status.Name, Size: *status.NewSize})
+	}
+	if len(pending) == 0 {
+		return result
+	}
+
+	updated, err := r.expansionEngine.ResizePVCGroup(ctx, clusterNamespace, resizes)
+	for k, status := range pending[:len(updated)] {
+		if updated[k] {
+			r.events.PVC(ctx, status.Name, clusterNamespace, corev1.EventTypeNormal, recorder.ReasonExpansionRequested,
+				"Resize to %s requested by StorageEvent %s: %s", status.NewSize.String(), event.Name, event.Spec.Reason)
+		}
+
+		// InProgress until the verify step observes the new capacity
+		status.Phase = cnpgv1alpha1.PVCPhaseInProgress
+		status.Error = ""
+		result.resized++
+		if updated[k] && status.OriginalSize != nil {
+			result.bytesAdded += status.NewSize.Value() - status.OriginalSize.Value()
+		}
+		log.Info("PVC expansion requested", "pvc", status.Name, "instance", status.Instance,
+			"newSize", status.NewSize.String(), "updated", updated[k])
+	}
+	if err != nil {
+		result.failure = r.failInstanceResize(ctx, clusterNamespace, event.Status.PVCStatuses, group,
+			pending[len(updated):], err)
+	}
+	return result
+}
+
+// verifyExpansion checks that each resized PVC reports its new capacity. It does not
+// block: while resizes are pending the step is requeued until expansionVerifyTimeout.
+func (r *StorageEventReconciler) verifyExpansion(ctx context.Context, event *cnpgv1alpha1.StorageEvent) (stepOutcome, error) {
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+
+	var pending []string
+	for i := range event.Status.PVCStatuses {
+		status := &event.Status.PVCStatuses[i]
+		if status.Phase != cnpgv1alpha1.PVCPhaseInProgress || status.NewSize == nil {
+			continue
+		}
+
+		complete, err := r.verifyPVC(ctx, clusterNamespace, status)
+		if err != nil {
+			return stepOutcome{}, err
+		}
+		if !complete {
+			pending = append(pending, status.Name)
+		}
+	}
+
+	if len(pending) == 0 {
+		return stepOutcome{message: fmt.Sprintf("%d PVCs verified", len(event.Status.PVCStatuses))}, nil
+	}
+
+	step := remediation.FindStep(event, remediation.StepVerify)
+	if step != nil && step.StartTime != nil && time.Since(step.StartTime.Time) > expansionVerifyTimeout {
+		// Restart verification timing on the next attempt
+		step.StartTime = nil
+		return stepOutcome{}, fmt.Errorf("timed out waiting for PVCs to report new capacity: %v", pending)
+	}
+
+	return stepOutcome{
+		message:      fmt.Sprintf("Waiting for %d PVCs to finish resizing", len(pending)),
+		requeueAfter: expansionVerifyInterval,
+	}, nil
+}
+
+// verifyPVC marks a resized PVC Completed once it reports its new capacity
+func (r *StorageEventReconciler) verifyPVC(
+	ctx context.Context,
+	namespace string,
+	status *cnpgv1alpha1.PVCStatus,
+) (bool, error) {
+	result, err := r.expansionEngine.CheckExpansion(ctx, status.Name, namespace, *status.NewSize)
+	if err != nil {
+		return false, err
+	}
+	if !result.Complete {
+		return false, nil
+	}
+
+	status.Phase = cnpgv1alpha1.PVCPhaseCompleted
+	status.FilesystemResized = true
+	r.events.PVC(ctx, status.Name, namespace, corev1.EventTypeNormal, recorder.ReasonExpanded,
+		"Capacity is now %s", status.NewSize.String())
+	return true, nil
+}
+
+// publishRecommendation publishes the planned sizes for a GitOps pipeline to apply
+// instead of resizing the PVCs
+func (r *StorageEventReconciler) publishRecommendation(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) (stepOutcome, error) {
+	clusterName := event.Spec.ClusterRef.Name
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, clusterName, clusterNamespace)
+	if err != nil {
+		return stepOutcome{}, fmt.Errorf("failed to get cluster PVCs: %w", err)
+	}
+	roles := make(map[string]string, len(pvcs))
+	for i := range pvcs {
+		roles[pvcs[i].Name] = remediation.PVCRole(&pvcs[i])
+	}
+
+	rec := remediation.BuildRecommendation(event, roles)
+	if err := r.recommendations.Publish(ctx, policyObj, rec); err != nil {
+		return stepOutcome{}, err
+	}
+	metrics.RecordExpansion(clusterName, clusterNamespace, "recommended", 0, event.Name)
+
+	message := fmt.Sprintf("Published to ConfigMap %s/%s", policyObj.Namespace, remediation.RecommendationConfigMapName(policyObj))
+	if rec.StorageSize != "" {
+		message += ", storage.size=" + rec.StorageSize
+	}
+	if rec.WALStorageSize != "" {
+		message += ", walStorage.size=" + rec.WALStorageSize
+	}
+	return stepOutcome{message: message}, nil
+}
+
+// locatePrimary resolves the current primary pod and records it on the event
+func (r *StorageEventReconciler) locatePrimary(ctx context.Context, event *cnpgv1alpha1.StorageEvent) (stepOutcome, error) {
+	if r.walCleanupEngine == nil {
+		return stepOutcome{}, fmt.Errorf("WAL cleanup engine not available")
+	}
+
+	primaryPod, err := r.discovery.GetPrimaryPod(ctx, event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace)
+	if err != nil {
+		return stepOutcome{}, fmt.Errorf("failed to get primary pod: %w", err)
+	}
+
+	return stepOutcome{message: primaryPod.Name}, nil
+}
+
+// cleanupWAL runs WAL cleanup against the primary, and against each replica when the
+// policy includes replicas. Fenced instances are skipped, since PostgreSQL is stopped on
+// them. Cleanup is naturally idempotent, so a resumed attempt simply re-evaluates the
+// WAL directory. The commands of all instances share the remediation deadline, so a hung
+// exec fails the attempt instead of holding the worker.
+func (r *StorageEventReconciler) cleanupWAL(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) (stepOutcome, error) {
+	if r.walCleanupEngine == nil {
+		return stepOutcome{}, fmt.Errorf("WAL cleanup engine not available")
+	}
+
+	clusterName := event.Spec.ClusterRef.Name
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+
+	cluster, err := r.discovery.GetCluster(ctx, clusterName, clusterNamespace)
+	if err != nil {
+		return stepOutcome{}, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	// Re-resolve the primary in case of a switchover since it was located
+	primaryPod, err := r.discovery.GetPrimaryPod(ctx, clusterName, clusterNamespace)
+	if err != nil {
+		return stepOutcome{}, fmt.Errorf("failed to get primary pod: %w", err)
+	}
+
+	deadline := r.RemediationDeadline
+	if deadline <= 0 {
+		deadline = remediation.DefaultRemediationDeadline
+	}
+	execCtx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+
+	details := &cnpgv1alpha1.WALCleanupDetails{PodName: primaryPod.Name}
+	var skipped []string
+	if cluster.IsInstanceFenced(primaryPod.Name) {
+		skipped = append(skipped, primaryPod.Name)
+	} else {
+		result, err := r.walCleanupEngine.CleanupClusterWAL(execCtx, &remediation.WALCleanupRequest{
+			ClusterName:      clusterName,
+			ClusterNamespace: clusterNamespace,
+			Pod:              primaryPod,
+			Policy:           policyObj,
+			Reason:           event.Spec.Reason,
+			Event:            event.Name,
+		})
+		if err != nil {
+			return stepOutcome{}, fmt.Errorf("WAL cleanup failed: %w", err)
+		}
+		details.FilesRemoved = int32(result.FilesRemoved)
+		details.SpaceFreedBytes = result.BytesFreed
+	}
+	filesRemoved, bytesFreed := int(details.FilesRemoved), details.SpaceFreedBytes
+
+	var replicaErr error
+	if policyObj.Spec.WALCleanup.IncludeReplicas {
+		var skippedReplicas []string
+		details.Replicas, skippedReplicas, replicaErr = r.cleanupReplicaWAL(
+			execCtx, event, policyObj, cluster, primaryPod.Name)
+		skipped = append(skipped, skippedReplicas...)
+		for _, replica := range details.Replicas {
+			filesRemoved += int(replica.FilesRemoved)
+			bytesFreed += replica.SpaceFreedBytes
+		}
+	}
+
+	// Record cleanup details on the event spec for the audit trail. Updating the
+	// spec returns the stored status, so carry the in-memory status across.
+	status := event.Status.DeepCopy()
+	event.Spec.WALCleanup = details
+	if err := r.Update(ctx, event); err != nil {
+		return stepOutcome{}, fmt.Errorf("failed to record WAL cleanup details: %w", err)
+	}
+	event.Status = *status
+	if replicaErr != nil {
+		return stepOutcome{}, replicaErr
+	}
+
+	message := fmt.Sprintf("%d files removed, %d bytes freed", filesRemoved, bytesFreed)
+	if len(skipped) > 0 {
+		message += fmt.Sprintf(", skipped fenced instances %s", strings.Join(skipped, ", "))
+	}
+	return stepOutcome{message: message}, nil
+}
+
+// cleanupReplicaWAL cleans up WAL on every replica of the cluster that is not fenced.
+// Each replica is attempted even when another fails; the first failure is returned
+// alongside the results of the replicas that succeeded and the fenced replicas skipped.
+func (r *StorageEventReconciler) cleanupReplicaWAL(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster *cnpg.ClusterInfo,
+	primaryName string,
+) ([]cnpgv1alpha1.ReplicaWALCleanup, []string, error) {
+	clusterName := event.Spec.ClusterRef.Name
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+
+	pods, err := r.discovery.GetClusterPods(ctx, clusterName, clusterNamespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cluster pods: %w", err)
+	}
+
+	var results []cnpgv1alpha1.ReplicaWALCleanup
+	var skipped []string
+	var firstErr error
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Name == primaryName || pod.Labels["cnpg.io/instanceRole"] == "primary" {
+			continue
+		}
+		if cluster.IsInstanceFenced(pod.Name) {
+			skipped = append(skipped, pod.Name)
+			continue
+		}
+		result, err := r.walCleanupEngine.CleanupClusterWAL(ctx, &remediation.WALCleanupRequest{
+			ClusterName:      clusterName,
+			ClusterNamespace: clusterNamespace,
+			Pod:              pod,
+			Replica:          true,
+			Policy:           policyObj,
+			Reason:           event.Spec.Reason,
+			Event:            event.Name,
+		})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("WAL cleanup failed on replica %s: %w", pod.Name, err)
+			}
+			continue
+		}
+		results = append(results, cnpgv1alpha1.ReplicaWALCleanup{
+			PodName:         result.PodName,
+			FilesRemoved:    int32(result.FilesRemoved),
+			SpaceFreedBytes: result.BytesFreed,
+		})
+	}
+	return results, skipped, firstErr
+}
+
+// handleFailure schedules a retry with exponential backoff, or marks the event Failed
+// and updates the cluster circuit breaker once retries are exhausted.
+func (r *StorageEventReconciler) handleFailure(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	execErr error,
+) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	clusterName := event.Spec.ClusterRef.Name
+	clusterNamespace := event.Spec.ClusterRef.Namespace
+	metrics.RecordError(string(event.Spec.EventType), clusterName, clusterNamespace)
+
+	maxRetries := r.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = remediation.DefaultMaxEventRetries
+	}
+
+	event.Status.RetryCount++
+	if event.Status.RetryCount >= maxRetries {
+		if err := r.markFailed(ctx, event, "RetriesExhausted",
+			fmt.Sprintf("Failed after %d attempts: %v", event.Status.RetryCount, execErr)); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.events.ClusterByName(ctx, clusterName, clusterNamespace, corev1.EventTypeWarning,
+			recorder.ReasonRemediationFailed, "%s (StorageEvent %s) failed after %d attempts: %v",
+			event.Spec.EventType, event.Name, event.Status.RetryCount, execErr)
+		r.recordClusterFailure(ctx, event, policyObj)
+		r.runPostActionHook(ctx, event, policyObj)
+		return ctrl.Result{}, nil
+	}
+
+	backoff := remediation.RetryBackoff(event.Status.RetryCount)
+	next := metav1.NewTime(time.Now().Add(backoff))
+	event.Status.Phase = cnpgv1alpha1.EventPhasePending
+	event.Status.NextRetryTime = &next
+	event.Status.Message = fmt.Sprintf("Attempt %d failed, retrying in %s: %v", event.Status.RetryCount, backoff, execErr)
+	setEventCondition(event, cnpgv1alpha1.StorageEventConditionProgressing, metav1.ConditionFalse,
+		"RetryScheduled", event.Status.Message)
+	if err := r.Status().Update(ctx, event); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Storage event retry scheduled", "event", event.Name, "retry", event.Status.RetryCount, "backoff", backoff)
+	return ctrl.Result{RequeueAfter: backoff}, nil
+}
+
+// markCompleted moves an event to the Completed phase
+func (r *StorageEventReconciler) markCompleted(ctx context.Context, event *cnpgv1alpha1.StorageEvent, message string) error {
+	now := metav1.Now()
+	if event.Status.StartTime == nil {
+		event.Status.StartTime = &now
+	}
+	event.Status.Phase = cnpgv1alpha1.EventPhaseCompleted
+	event.Status.CompletionTime = &now
+	event.Status.NextRetryTime = nil
+	event.Status.Message = message
+	setEventCondition(event, cnpgv1alpha1.StorageEventConditionProgressing, metav1.ConditionFalse, "Finished", message)
+	setEventCondition(event, cnpgv1alpha1.StorageEventConditionComplete, metav1.ConditionTrue, "Succeeded", message)
+	return r.Status().Update(ctx, event)
+}
+
+// markFailed moves an event to the terminal Failed phase
+func (r *StorageEventReconciler) markFailed(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	reason, message string,
+) error {
+	now := metav1.Now()
+	event.Status.Phase = cnpgv1alpha1.EventPhaseFailed
+	event.Status.CompletionTime = &now
+	event.Status.NextRetryTime = nil
+	event.Status.Message = message
+	if remediation.IsEffectivenessPending(event) {
+		remediation.FailEffectiveness(event.Status.Effectiveness, "Remediation did not complete", now.Time)
+	}
+	setEventCondition(event, cnpgv1alpha1.StorageEventConditionProgressing, metav1.ConditionFalse, "Finished", message)
+	setEventCondition(event, cnpgv1alpha1.StorageEventConditionComplete, metav1.ConditionFalse, reason, message)
+	return r.Status().Update(ctx, event)
+}
+
+// recordClusterSuccess stamps the last-action time on the cluster and resets its failure count
+func (r *StorageEventReconciler) recordClusterSuccess(ctx context.Context, event *cnpgv1alpha1.StorageEvent) {
+	r.updateClusterAnnotations(ctx, event, func(ca *clusterAnnotationsWrapper) {
+		switch event.Spec.EventType {
+		case cnpgv1alpha1.EventTypeExpansion:
+			ca.SetLastExpansion(time.Now())
+		case cnpgv1alpha1.EventTypeWALCleanup:
+			ca.SetLastWALCleanup(time.Now())
+		}
+		ca.ResetFailureCount()
+	})
+}
+
+// recordClusterFailure increments the cluster failure count and opens the circuit breaker if needed
+func (r *StorageEventReconciler) recordClusterFailure(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+) {
+	log := logf.FromContext(ctx)
+	var opened bool
+	var failures int32
+	r.updateClusterAnnotations(ctx, event, func(ca *clusterAnnotationsWrapper) {
+		ca.IncrementFailureCount()
+		failures = ca.GetFailureCount()
+		if failures >= policyObj.Spec.CircuitBreaker.MaxFailures {
+			opened = !ca.IsCircuitBreakerOpen()
+			ca.SetCircuitBreakerOpen(true)
+			log.Info("Opening circuit breaker", "cluster", event.Spec.ClusterRef.Name, "failures", failures)
+		}
+	})
+	if opened {
+		r.events.ClusterByName(ctx, event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace,
+			corev1.EventTypeWarning, recorder.ReasonCircuitBreakerOpened,
+			"Automatic remediation paused for %d minutes after %d consecutive failures",
+			policyObj.Spec.CircuitBreaker.ResetMinutes, failures)
+	}
+}
+
+// recordCompletionEvent records the outcome of a completed StorageEvent on its cluster
+func (r *StorageEventReconciler) recordCompletionEvent(ctx context.Context, event *cnpgv1alpha1.StorageEvent) {
+	name := event.Spec.ClusterRef.Name
+	namespace := event.Spec.ClusterRef.Namespace
+
+	switch event.Spec.EventType {
+	case cnpgv1alpha1.EventTypeExpansion:
+		reason := recorder.ReasonStorageExpanded
+		if event.Spec.RecommendOnly {
+			reason = recorder.ReasonExpansionRecommended
+		}
+		r.events.ClusterByName(ctx, name, namespace, corev1.EventTypeNormal, reason,
+			"StorageEvent %s: %s", event.Name, event.Status.Message)
+	case cnpgv1alpha1.EventTypeWALCleanup:
+		message := event.Status.Message
+		if details := event.Spec.WALCleanup; details != nil {
+			message = fmt.Sprintf("%d WAL files removed from %s, %s freed", details.FilesRemoved, details.PodName,
+				resource.NewQuantity(details.SpaceFreedBytes, resource.BinarySI).String())
+		}
+		r.events.ClusterByName(ctx, name, namespace, corev1.EventTypeNormal, recorder.ReasonWALCleanupCompleted,
+			"StorageEvent %s: %s", event.Name, message)
+	case cnpgv1alpha1.EventTypeStorageClassMigration:
+		r.events.ClusterByName(ctx, name, namespace, corev1.EventTypeNormal, recorder.ReasonStorageClassMigrated,
+			"StorageEvent %s: %s", event.Name, event.Status.Message)
+	case cnpgv1alpha1.EventTypeVolumeAttributesChange:
+		r.events.ClusterByName(ctx, name, namespace, corev1.EventTypeNormal, recorder.ReasonVolumeAttributesChanged,
+			"StorageEvent %s: %s", event.Name, event.Status.Message)
+	}
+}
+
+// updateClusterAnnotations applies a mutation to the annotations of the event's cluster
+func (r *StorageEventReconciler) updateClusterAnnotations(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	mutate func(ca *clusterAnnotationsWrapper),
+) {
+	log := logf.FromContext(ctx)
+	name := event.Spec.ClusterRef.Name
+	namespace := event.Spec.ClusterRef.Namespace
+
+	existing, err := r.discovery.GetClusterAnnotations(ctx, name, namespace)
+	if err != nil {
+		log.Error(err, "Failed to get cluster annotations", "cluster", name)
+		return
+	}
+	// The wrapper mutates its map, so keep the read annotations to detect removed keys
+	ca := &clusterAnnotationsWrapper{annotations: maps.Clone(existing)}
+	if ca.annotations == nil {
+		ca.annotations = make(map[string]string)
+	}
+
+	mutate(ca)
+
+	if err := r.discovery.UpdateClusterAnnotations(ctx, name, namespace, existing, ca.GetAnnotations()); err != nil {
+		log.Error(err, "Failed to update cluster annotations", "cluster", name)
+	}
+}
+
+// pausedEventRequeue returns when to look at an event held by a policy pause again.
+// Policy changes do not trigger event reconciles, so clearing the pause is picked up
+// on the next poll
+func pausedEventRequeue(policyObj *cnpgv1alpha1.StoragePolicy, now time.Time) time.Duration {
+	if policyObj.Spec.PauseUntil != nil {
+		if remaining := policyObj.Spec.PauseUntil.Sub(now); remaining < DefaultRequeueInterval {
+			return max(remaining, time.Second)
+		}
+	}
+	return DefaultRequeueInterval
+}
+
+// setEventCondition sets a condition on the StorageEvent status
+func setEventCondition(
+	event *cnpgv1alpha1.StorageEvent,
+	conditionType string,
+	status metav1.ConditionStatus,
+	reason, message string,
+) {
+	meta.SetStatusCondition(&event.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: event.Generation,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *StorageEventReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cnpgv1alpha1.StorageEvent{}).
+		Named("storageevent").
+		Complete(tracing.Reconciler("StorageEvent", r))
+}
@@ -18,27 +18,49 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
 	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
 	"github.com/supporttools/cnpg-storage-manager/pkg/annotations"
+	"github.com/supporttools/cnpg-storage-manager/pkg/backup"
+	"github.com/supporttools/cnpg-storage-manager/pkg/clock"
 	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/connection"
+	"github.com/supporttools/cnpg-storage-manager/pkg/identity"
+	"github.com/supporttools/cnpg-storage-manager/pkg/managerconfig"
 	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/permissions"
 	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
 	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+	"github.com/supporttools/cnpg-storage-manager/pkg/sharding"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
 )
 
 const (
@@ -47,6 +69,29 @@ const (
 
 	// DefaultRequeueInterval is the default requeue interval
 	DefaultRequeueInterval = 30 * time.Second
+
+	// statusAwaitingApproval is the managed cluster status while a remediation waits for approval
+	statusAwaitingApproval = "AwaitingApproval"
+
+	// statusBackingOff is the managed cluster status while evaluation of a repeatedly
+	// failing cluster is delayed
+	statusBackingOff = "BackingOff"
+
+	// statusPausedWouldExpand and statusPausedWouldCleanupWAL are the managed cluster
+	// statuses when remediation was skipped because the policy is paused
+	statusPausedWouldExpand     = "Paused-WouldExpand"
+	statusPausedWouldCleanupWAL = "Paused-WouldCleanupWAL"
+
+	// statusHibernated is the managed cluster status of a hibernated CNPG cluster
+	statusHibernated = "Hibernated"
+
+	// statusInsufficientCapacity is the managed cluster status when an expansion was
+	// skipped because the storage backend cannot hold it
+	statusInsufficientCapacity = "InsufficientCapacity"
+
+	// statusQuotaExceeded is the managed cluster status when an expansion was skipped
+	// because a ResourceQuota or LimitRange of the namespace would reject it
+	statusQuotaExceeded = "QuotaExceeded"
 )
 
 // StoragePolicyReconciler reconciles a StoragePolicy object
@@ -59,13 +104,100 @@ type StoragePolicyReconciler struct {
 	// When true, no actual changes are made to PVCs or WAL files.
 	GlobalDryRun bool
 
+	// ReadOnly runs the reconciler in monitor-only mode: every remediation is dry-run, no
+	// command is run in instance pods and CNPG clusters are not annotated, so no write
+	// access to PVCs or clusters and no pods/exec is needed. Policies still observe and alert
+	ReadOnly bool
+
+	// CommandRunner runs df probes and dry-run WAL cleanup planning inside instance pods.
+	// Defaults to pod exec when nil.
+	CommandRunner runner.CommandRunner
+
+	// AgentCollector collects volume usage from the node agent for policies with
+	// metricsSource agent. Those policies collect no metrics when nil.
+	AgentCollector *metrics.AgentCollector
+
+	// DatabaseSizes lists the largest databases in anomalous growth alerts. Alerts omit
+	// them when nil
+	DatabaseSizes *metrics.DatabaseSizeCollector
+
+	// PostgresLogs attaches the key error lines of the primary's PostgreSQL log to the
+	// emergency alerts of policies with alerting.logHints. Alerts omit them when nil
+	PostgresLogs *metrics.PostgresLogCollector
+
+	// WriteProbe checks whether the primaries of policies with writeProbe accept writes.
+	// Writes are not probed when nil
+	WriteProbe *metrics.WriteProbe
+
+	// Inventory serves cluster listings from a watch when set, and triggers reconciles
+	// when clusters selected by a policy change
+	Inventory *cnpg.Inventory
+
+	// ObjectStores serves barman-cloud ObjectStore recovery windows from a watch when
+	// set, and triggers reconciles when a selected cluster's backup status changes
+	ObjectStores *cnpg.ObjectStoreCache
+
+	// Connections holds the clients of ClusterConnections listed in spec.connections
+	Connections *connection.Registry
+
+	// ClusterIdentity names the manager's own Kubernetes cluster in alerts
+	ClusterIdentity identity.ClusterIdentity
+
+	// Recorder records Kubernetes Events on CNPG clusters. Events are not recorded when nil.
+	Recorder record.EventRecorder
+
+	// Secrets caches alert channel secrets between sends and triggers reconciles when a
+	// referenced Secret changes. Secrets are read on every send when nil.
+	Secrets *alerting.SecretCache
+
+	// Shard limits the reconciler to the policies of this replica's shard. The zero value
+	// reconciles every policy
+	Shard sharding.Shard
+
+	// Clock is the time cooldowns, pauses, dry-run trials and alert suppression are
+	// checked against. The system clock when nil
+	Clock clock.Clock
+
+	// Permissions reviews the RBAC permissions the features of each policy need, held
+	// back while they are missing. Permissions are not checked when nil
+	Permissions *permissions.Checker
+
+	// ExportedMetrics tracks the clusters the policy exports metrics for, so the metrics
+	// of clusters it no longer matches are deleted. Metrics are not tracked when nil
+	ExportedMetrics *metrics.ExportedClusters
+
+	// RunnerMode is how CommandRunner runs commands, which decides the permissions it
+	// needs. Pod exec when empty
+	RunnerMode runner.Mode
+
+	// WatchDiskErrors watches Kubernetes Events for disk full errors of CNPG pods, and
+	// evaluates the policies selecting the cluster right away instead of
+	// at the next periodic reconcile
+	WatchDiskErrors bool
+
+	// configDryRun holds the dryRun of the ManagerConfig seen by the latest reconcile
+	configDryRun atomic.Bool
+
+	// missingFeatures are the features of each policy held back for missing permissions
+	permissionsLock sync.RWMutex
+	missingFeatures map[string]map[string]bool
+
+	// diskErrors debounces the evaluations disk full errors trigger per cluster
+	diskErrors diskErrors
+
 	// Internal components
 	discovery        *cnpg.Discovery
+	events           *recorder.Recorder
 	metricsCollector *metrics.Collector
 	evaluator        *policy.Evaluator
+	alertManagers    alertManagers // per-policy alert managers
+	prometheusRules  *alerting.PrometheusRuleManager
+	clusterBackoff   *policy.FailureBackoff // per-cluster failure streaks
 	expansionEngine  *remediation.ExpansionEngine
-	walCleanupEngine *remediation.WALCleanupEngine
-	alertManagers    map[string]*alerting.AlertManager // per-policy alert managers
+	walCleanupEngine *remediation.WALCleanupEngine // plans dry-run WAL cleanups
+	capacityChecker  *remediation.CapacityChecker
+	quotaChecker     *remediation.QuotaChecker
+	latencyQuerier   *metrics.LatencyQuerier
 }
 
 // RBAC for StoragePolicy management
@@ -73,37 +205,62 @@ type StoragePolicyReconciler struct {
 // +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=storagepolicies/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=storagepolicies/finalizers,verbs=update
 
+// RBAC for BackupPolicy discovery (clusters covered by a BackupPolicy skip StoragePolicy backup checks)
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=backuppolicies,verbs=get;list;watch
+
 // RBAC for StorageEvent management (audit trail)
 // +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=storageevents,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=storageevents/status,verbs=get;update;patch
 
+// RBAC for StorageForecast maintenance (capacity planning)
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=storageforecasts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=storageforecasts/status,verbs=get;update;patch
+
 // RBAC for CNPG Cluster access (read and annotate)
 // +kubebuilder:rbac:groups=postgresql.cnpg.io,resources=clusters,verbs=get;list;watch;patch;update
-// +kubebuilder:rbac:groups=postgresql.cnpg.io,resources=clusters/status,verbs=get
+// +kubebuilder:rbac:groups=postgresql.cnpg.io,resources=clusters/status,verbs=get;patch
+
+// RBAC for CRD discovery (recovering once CloudNativePG is installed)
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 
 // RBAC for ObjectStore access (barman-cloud plugin backup status)
 // +kubebuilder:rbac:groups=barmancloud.cnpg.io,resources=objectstores,verbs=get;list;watch
 // +kubebuilder:rbac:groups=barmancloud.cnpg.io,resources=objectstores/status,verbs=get
 
 // RBAC for PVC management (expansion)
-// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;patch;update
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;patch;update;delete
 
-// RBAC for Pod access (WAL cleanup via exec)
-// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// RBAC for Pod access (WAL cleanup via exec, restarts completing pending resizes)
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
 
+// RBAC for PrometheusRule management (alerting that survives operator outages)
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;create;update;delete
+
 // RBAC for Node access (kubelet metrics via proxy)
-// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=nodes/proxy,verbs=get
 
-// RBAC for Kubernetes Events (create events for auditing)
+// RBAC for Kubernetes Events (create events for auditing). Watching Events for disk full
+// errors is opt-in, so its get, list and watch are granted by
+// config/rbac/disk_error_watcher_role.yaml or the chart's watchDiskErrors value instead
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // RBAC for StorageClass validation
 // +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 
+// RBAC for backend capacity checks before expansion
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=csistoragecapacities,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch
+
+// RBAC for ResourceQuota and LimitRange checks before expansion
+// +kubebuilder:rbac:groups="",resources=resourcequotas;limitranges,verbs=get;list;watch
+
+// RBAC for performance tuning of PVCs
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=volumeattributesclasses,verbs=get;list;watch
+
 // RBAC for Secret access (alert channel credentials)
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 
 // RBAC for leader election
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
@@ -119,11 +276,18 @@ func (r *StoragePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		metrics.ReconcileDuration.WithLabelValues("storagepolicy").Observe(duration)
 	}()
 
+	// Policies of other shards are reconciled by the replicas owning them
+	if !r.Shard.Owns(req.Namespace, req.Name) {
+		return ctrl.Result{}, nil
+	}
+
 	// Fetch the StoragePolicy instance
 	var policyObj cnpgv1alpha1.StoragePolicy
 	if err := r.Get(ctx, req.NamespacedName, &policyObj); err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("StoragePolicy not found, may have been deleted")
+			r.alertManagers.evict(req.NamespacedName)
+			trackExportedMetrics(ctx, r.ExportedMetrics, storagePolicyOwner(req.Namespace, req.Name), nil)
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Failed to get StoragePolicy")
@@ -151,8 +315,33 @@ func (r *StoragePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// Initialize internal components if needed
 	r.initComponents()
 
+	// Fill unset fields from the ManagerConfig. The defaulted spec is only used in
+	// memory and must never be written back to the policy.
+	defaults, err := managerconfig.Load(ctx, r.Client)
+	if err != nil {
+		log.Error(err, "Failed to load ManagerConfig")
+		metrics.RecordReconcile("storagepolicy", "error", time.Since(startTime).Seconds())
+		return ctrl.Result{}, err
+	}
+	managerconfig.ApplyStoragePolicyDefaults(&policyObj.Spec, defaults)
+	r.configDryRun.Store(managerconfig.DryRun(defaults))
+
+	r.handleDryRunExpiry(ctx, &policyObj)
+	r.reportPolicyPause(&policyObj)
+
 	// Find matching CNPG clusters
 	clusters, err := r.findMatchingClusters(ctx, &policyObj)
+	if !reportCNPGInstalled(&policyObj.Status.Conditions, policyObj.Generation, err) {
+		// There is nothing to manage until CloudNativePG is installed. The CRD watch
+		// reconciles the policy as soon as it is, so back off instead of erroring.
+		log.Info("CloudNativePG CRDs not installed, waiting for them to appear")
+		r.setCondition(&policyObj, "Ready", metav1.ConditionFalse, conditionCNPGNotInstalled,
+			"Waiting for the CloudNativePG CRDs to be installed")
+		if statusErr := r.Status().Update(ctx, &policyObj); statusErr != nil {
+			log.Error(statusErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: CNPGNotInstalledRequeueInterval}, nil
+	}
 	if err != nil {
 		log.Error(err, "Failed to find matching clusters")
 		r.setCondition(&policyObj, "Ready", metav1.ConditionFalse, "ClusterDiscoveryFailed", err.Error())
@@ -164,19 +353,50 @@ func (r *StoragePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	log.Info("Found matching clusters", "count", len(clusters))
+	r.checkPermissions(ctx, &policyObj, clusters)
 
 	// Update managed clusters count metric
 	metrics.ClustersManagedTotal.WithLabelValues(policyObj.Namespace).Set(float64(len(clusters)))
+	trackExportedMetrics(ctx, r.ExportedMetrics, storagePolicyOwner(policyObj.Namespace, policyObj.Name), clusters)
+
+	// Resolve backup status for all clusters up front so shared ObjectStores are fetched once per cycle
+	// and the recovery window of every cluster can be reported
+	backupStatuses := r.discovery.GetBackupStatusesForClusters(ctx, clusters)
+	var backupCovered map[string]bool
+	if policyObj.Spec.BackupMonitoring.Enabled {
+		if backupCovered, err = r.clustersCoveredByBackupPolicies(ctx); err != nil {
+			log.Error(err, "Failed to determine clusters covered by BackupPolicies")
+		}
+	}
 
 	// Process each cluster
 	managedClusters := make([]cnpgv1alpha1.ManagedCluster, 0, len(clusters))
 	var reconciledCount, errorCount int
+	var failedClusters []string
 
 	for _, cluster := range clusters {
-		clusterResult, err := r.processCluster(ctx, &policyObj, cluster)
+		// A repeatedly failing cluster is evaluated less often, so it does not flood
+		// logs and metrics every interval while healthy clusters carry on
+		backoffKey := clusterBackoffKey(&policyObj, cluster)
+		if ready, next := r.clusterBackoff.Ready(backoffKey); !ready {
+			log.V(1).Info("Cluster is backing off after repeated failures", "cluster", cluster.Name,
+				"namespace", cluster.Namespace, "consecutiveFailures", r.clusterBackoff.Failures(backoffKey),
+				"nextAttempt", next)
+			errorCount++
+			failedClusters = append(failedClusters, fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name))
+			managedClusters = append(managedClusters, backingOffCluster(policyObj.Status.ManagedClusters, cluster))
+			continue
+		}
+
+		monitorBackups := policyObj.Spec.BackupMonitoring.Enabled &&
+			!backupCovered[fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name)]
+		clusterResult, err := r.processCluster(ctx, &policyObj, cluster, backupStatuses, monitorBackups)
 		if err != nil {
-			log.Error(err, "Failed to process cluster", "cluster", cluster.Name, "namespace", cluster.Namespace)
+			failures, delay := r.clusterBackoff.Failure(backoffKey)
+			log.Error(err, "Failed to process cluster", "cluster", cluster.Name, "namespace", cluster.Namespace,
+				"consecutiveFailures", failures, "retryIn", delay.Round(time.Second))
 			errorCount++
+			failedClusters = append(failedClusters, fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name))
 			metrics.RecordError("cluster_processing", cluster.Name, cluster.Namespace)
 
 			managedClusters = append(managedClusters, cnpgv1alpha1.ManagedCluster{
@@ -185,29 +405,55 @@ func (r *StoragePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 				LastChecked:  metav1.Now(),
 				UsagePercent: 0,
 				Status:       "Error",
+				Conditions:   clusterConditions(&policyObj, cluster, "", policy.ClusterConditionState{Err: err}),
 			})
 			continue
 		}
 
+		r.clusterBackoff.Success(backoffKey)
 		reconciledCount++
 		managedClusters = append(managedClusters, *clusterResult)
 	}
 
+	// Clusters in downstream Kubernetes clusters
+	if len(policyObj.Spec.Connections) > 0 {
+		remoteClusters, remoteFailed := r.processConnections(ctx, &policyObj)
+		managedClusters = append(managedClusters, remoteClusters...)
+		for _, mc := range remoteClusters {
+			if mc.Status != "Error" {
+				reconciledCount++
+			}
+		}
+		errorCount += len(remoteFailed)
+		failedClusters = append(failedClusters, remoteFailed...)
+	}
+
 	// Update policy status
+	scoreManagedClusters(policyObj.Status.ManagedClusters, managedClusters, policyObj.Spec.Thresholds)
+	trackStorageSLOs(&policyObj, policyObj.Status.ManagedClusters, managedClusters)
 	policyObj.Status.ManagedClusters = managedClusters
-	policyObj.Status.LastEvaluated = &metav1.Time{Time: time.Now()}
+	summarizeManagedClusters(&policyObj.Status)
+	policyObj.Status.LastEvaluated = &metav1.Time{Time: r.now()}
 	policyObj.Status.ObservedGeneration = policyObj.Generation
 
 	if errorCount > 0 {
 		r.setCondition(&policyObj, "Ready", metav1.ConditionFalse, "PartialSuccess",
 			fmt.Sprintf("Processed %d clusters, %d errors", reconciledCount, errorCount))
-	} else if len(clusters) == 0 {
+	} else if len(managedClusters) == 0 {
 		r.setCondition(&policyObj, "Ready", metav1.ConditionTrue, "NoClustersMatched",
 			"No CNPG clusters matched the selector")
 	} else {
 		r.setCondition(&policyObj, "Ready", metav1.ConditionTrue, "ReconcileSucceeded",
 			fmt.Sprintf("Successfully processed %d clusters", reconciledCount))
 	}
+	r.trackPartialSuccess(ctx, &policyObj, failedClusters)
+	r.syncPrometheusRule(ctx, &policyObj, clusters)
+	flushAlertDigest(ctx, r.getAlertManager(&policyObj), &policyObj)
+	sendAlertSummary(ctx, r.getAlertManager(&policyObj), policyObj.Spec.Alerting.Summary,
+		&policyObj.Status.LastAlertSummary, r.now(), func() *alerting.Alert { return storagePolicySummary(&policyObj, r.now()) })
+	checkAlertChannels(ctx, r.getAlertManager(&policyObj), policyObj.Spec.Alerting.Channels,
+		&policyObj.Status.Conditions, policyObj.Generation)
+	r.reportAlertChannels(&policyObj)
 
 	if err := r.Status().Update(ctx, &policyObj); err != nil {
 		log.Error(err, "Failed to update status")
@@ -221,50 +467,343 @@ func (r *StoragePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
 }
 
-// isDryRun returns true if dry-run mode is enabled either globally or for the policy
-func (r *StoragePolicyReconciler) isDryRun(policyObj *cnpgv1alpha1.StoragePolicy) bool {
-	return r.GlobalDryRun || policyObj.Spec.DryRun
+// clusterBackoffKey identifies a cluster evaluated by a policy in the failure backoff
+func clusterBackoffKey(policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo) string {
+	return fmt.Sprintf("%s/%s/%s/%s", policyObj.Namespace, policyObj.Name, cluster.Namespace, cluster.Name)
+}
+
+// backingOffCluster returns the status of a cluster whose evaluation is delayed,
+// keeping the last known usage and backup status
+func backingOffCluster(previous []cnpgv1alpha1.ManagedCluster, cluster cnpg.ClusterInfo) cnpgv1alpha1.ManagedCluster {
+	for _, mc := range previous {
+		if mc.Name == cluster.Name && mc.Namespace == cluster.Namespace && mc.Connection == "" {
+			mc.Status = statusBackingOff
+			return mc
+		}
+	}
+	return cnpgv1alpha1.ManagedCluster{
+		Name:        cluster.Name,
+		Namespace:   cluster.Namespace,
+		LastChecked: metav1.Now(),
+		Status:      statusBackingOff,
+	}
+}
+
+// clusterConditions returns the conditions of a cluster for the given state, carrying
+// lastTransitionTime over from the cluster's previous status entry
+func clusterConditions(
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	connectionName string,
+	state policy.ClusterConditionState,
+) []metav1.Condition {
+	var previous []metav1.Condition
+	if mc := previousManagedCluster(policyObj, cluster, connectionName); mc != nil {
+		previous = mc.Conditions
+	}
+	return policy.ClusterConditions(previous, state)
+}
+
+// previousManagedCluster returns the entry of a cluster in the policy's current status,
+// or nil when the cluster was not managed before
+func previousManagedCluster(
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	connectionName string,
+) *cnpgv1alpha1.ManagedCluster {
+	for i := range policyObj.Status.ManagedClusters {
+		mc := &policyObj.Status.ManagedClusters[i]
+		if mc.Name == cluster.Name && mc.Namespace == cluster.Namespace && mc.Connection == connectionName {
+			return mc
+		}
+	}
+	return nil
+}
+
+// trackPartialSuccess records how long the policy has continuously failed to process
+// some of its clusters and alerts once that exceeds alerting.partialSuccessAlertMinutes
+func (r *StoragePolicyReconciler) trackPartialSuccess(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	failedClusters []string,
+) {
+	log := logf.FromContext(ctx)
+
+	if len(failedClusters) == 0 {
+		policyObj.Status.PartialSuccessSince = nil
+		policyObj.Status.PartialSuccessAlertedAt = nil
+		metrics.SetPolicyPartialSuccess(policyObj.Name, policyObj.Namespace, 0)
+		return
+	}
+
+	if policyObj.Status.PartialSuccessSince == nil {
+		policyObj.Status.PartialSuccessSince = &metav1.Time{Time: r.now()}
+	}
+	since := policyObj.Status.PartialSuccessSince.Time
+	duration := r.now().Sub(since)
+	metrics.SetPolicyPartialSuccess(policyObj.Name, policyObj.Namespace, duration.Seconds())
+
+	var lastAlert *time.Time
+	if policyObj.Status.PartialSuccessAlertedAt != nil {
+		lastAlert = &policyObj.Status.PartialSuccessAlertedAt.Time
+	}
+	if !r.evaluator.ShouldAlertPartialSuccess(&since, lastAlert,
+		policyObj.Spec.Alerting.PartialSuccessAlertMinutes, policyObj.Spec.Alerting.EscalationMinutes) {
+		return
+	}
+
+	alert := newPolicyAlert(policyObj, alerting.AlertSeverityWarning, "partial_success",
+		fmt.Sprintf("StoragePolicy %s/%s has failed to process %d clusters for %s",
+			policyObj.Namespace, policyObj.Name, len(failedClusters), duration.Round(time.Minute)), r.now())
+	alert.Details["failed_clusters"] = strings.Join(failedClusters, ",")
+
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		log.Error(err, "Failed to send partial success alert")
+		return
+	}
+
+	policyObj.Status.PartialSuccessAlertedAt = &metav1.Time{Time: r.now()}
+	log.Info("Partial success alert sent", "failedClusters", len(failedClusters), "duration", duration.Round(time.Second))
+}
+
+// syncPrometheusRule maintains the policy's PrometheusRule and reports the result as a condition
+func (r *StoragePolicyReconciler) syncPrometheusRule(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	clusters []cnpg.ClusterInfo,
+) {
+	log := logf.FromContext(ctx)
+
+	refs := make([]cnpgv1alpha1.ClusterReference, 0, len(clusters))
+	for _, cluster := range clusters {
+		refs = append(refs, cnpgv1alpha1.ClusterReference{Name: cluster.Name, Namespace: cluster.Namespace})
+	}
+
+	err := r.prometheusRules.Sync(ctx, policyObj, refs)
+	if !policyObj.Spec.Alerting.PrometheusRule.Enabled {
+		meta.RemoveStatusCondition(&policyObj.Status.Conditions, cnpgv1alpha1.StoragePolicyConditionPrometheusRuleSynced)
+		if err != nil {
+			log.Error(err, "Failed to remove PrometheusRule")
+		}
+		return
+	}
+
+	if err != nil {
+		log.Error(err, "Failed to sync PrometheusRule")
+		r.setCondition(policyObj, cnpgv1alpha1.StoragePolicyConditionPrometheusRuleSynced, metav1.ConditionFalse, "SyncFailed", err.Error())
+		return
+	}
+	r.setCondition(policyObj, cnpgv1alpha1.StoragePolicyConditionPrometheusRuleSynced, metav1.ConditionTrue, "Synced",
+		fmt.Sprintf("PrometheusRule %s covers %d clusters", alerting.PrometheusRuleName(policyObj), len(refs)))
+}
+
+// reportAlertChannels copies the delivery status of the alert channels to the policy
+// status and sets AlertingDegraded while the last delivery through a channel failed
+func (r *StoragePolicyReconciler) reportAlertChannels(policyObj *cnpgv1alpha1.StoragePolicy) {
+	statuses := r.getAlertManager(policyObj).ChannelStatuses()
+	policyObj.Status.AlertChannels = statuses
+	if len(statuses) == 0 {
+		meta.RemoveStatusCondition(&policyObj.Status.Conditions, cnpgv1alpha1.StoragePolicyConditionAlertingDegraded)
+		return
+	}
+
+	var failing []string
+	for _, status := range statuses {
+		if status.ConsecutiveFailures > 0 {
+			failing = append(failing, fmt.Sprintf("%s %s failed %d times: %s",
+				status.Type, status.Target, status.ConsecutiveFailures, status.LastError))
+		}
+	}
+	if len(failing) > 0 {
+		r.setCondition(policyObj, cnpgv1alpha1.StoragePolicyConditionAlertingDegraded, metav1.ConditionTrue,
+			"DeliveryFailed", strings.Join(failing, "; "))
+		return
+	}
+	r.setCondition(policyObj, cnpgv1alpha1.StoragePolicyConditionAlertingDegraded, metav1.ConditionFalse,
+		"DeliveriesSucceeded", "The last alert of every channel was delivered")
+}
+
+// isDryRun returns true if dry-run mode is enabled globally, for the policy or for the
+// remediation type, or the remediation lacks the permissions it needs
+func (r *StoragePolicyReconciler) isDryRun(policyObj *cnpgv1alpha1.StoragePolicy, eventType cnpgv1alpha1.EventType) bool {
+	if feature := eventTypeFeature(eventType); feature != "" && r.lacksPermission(policyObj, feature) {
+		return true
+	}
+	return r.globalDryRun() || policy.IsActionDryRun(policyObj, eventType, r.now())
+}
+
+// commandRunner returns the runner of commands in instance pods, nil in read-only mode
+func (r *StoragePolicyReconciler) commandRunner() runner.CommandRunner {
+	if r.ReadOnly {
+		return nil
+	}
+	return r.CommandRunner
+}
+
+// now returns the current time of the reconciler's clock
+func (r *StoragePolicyReconciler) now() time.Time {
+	return clock.OrReal(r.Clock).Now()
+}
+
+// globalDryRun returns true if dry-run mode is enabled by the --dry-run or --read-only
+// flag or the ManagerConfig
+func (r *StoragePolicyReconciler) globalDryRun() bool {
+	return r.ReadOnly || r.GlobalDryRun || r.configDryRun.Load()
+}
+
+// handleDryRunExpiry notifies once when the policy's dryRunUntil trial period ends
+// and the policy starts enforcing
+func (r *StoragePolicyReconciler) handleDryRunExpiry(ctx context.Context, policyObj *cnpgv1alpha1.StoragePolicy) {
+	log := logf.FromContext(ctx)
+
+	if !policy.DryRunExpired(policyObj, r.now()) {
+		// A new trial period re-arms the notification
+		policyObj.Status.DryRunExpiredAt = nil
+		return
+	}
+	if policyObj.Status.DryRunExpiredAt != nil {
+		return
+	}
+
+	message := fmt.Sprintf("StoragePolicy %s/%s dry-run trial ended at %s; the policy is now enforcing",
+		policyObj.Namespace, policyObj.Name, policyObj.Spec.DryRunUntil.UTC().Format(time.RFC3339))
+	if r.globalDryRun() {
+		message += " (global dry-run is still enabled)"
+	}
+	log.Info("Policy dry-run period expired", "dryRunUntil", policyObj.Spec.DryRunUntil.Time)
+
+	alert := newPolicyAlert(policyObj, alerting.AlertSeverityWarning, "dry_run_expired", message, r.now())
+	alert.Details["dry_run_until"] = policyObj.Spec.DryRunUntil.UTC().Format(time.RFC3339)
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		log.Error(err, "Failed to send dry-run expiry notification")
+		return
+	}
+
+	policyObj.Status.DryRunExpiredAt = &metav1.Time{Time: r.now()}
+}
+
+// reportPolicyPause sets the Paused condition from spec.paused and spec.pauseUntil
+func (r *StoragePolicyReconciler) reportPolicyPause(policyObj *cnpgv1alpha1.StoragePolicy) {
+	now := r.now()
+	switch {
+	case !policy.IsPolicyPaused(policyObj, now):
+		r.setCondition(policyObj, cnpgv1alpha1.StoragePolicyConditionPaused, metav1.ConditionFalse,
+			"Active", "Remediation is enabled")
+	case policyObj.Spec.PauseUntil != nil:
+		r.setCondition(policyObj, cnpgv1alpha1.StoragePolicyConditionPaused, metav1.ConditionTrue,
+			"MaintenanceWindow", fmt.Sprintf("Remediation is paused until %s",
+				policyObj.Spec.PauseUntil.UTC().Format(time.RFC3339)))
+	default:
+		r.setCondition(policyObj, cnpgv1alpha1.StoragePolicyConditionPaused, metav1.ConditionTrue,
+			"PausedBySpec", "Remediation is paused until spec.paused is cleared")
+	}
+}
+
+// ownership returns the ownership metadata of a cluster: the policy metadata with the
+// cluster's ownership annotations applied
+func ownership(policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo) cnpgv1alpha1.OwnershipMetadata {
+	return annotations.Ownership(cluster.Annotations, policyObj.Spec.Metadata)
+}
+
+// eventOwnership returns the ownership metadata recorded on the StorageEvents of a
+// cluster, nil when there is none
+func eventOwnership(policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo) *cnpgv1alpha1.OwnershipMetadata {
+	owner := ownership(policyObj, cluster)
+	if owner == (cnpgv1alpha1.OwnershipMetadata{}) {
+		return nil
+	}
+	return &owner
+}
+
+// newPolicyAlert builds an alert about the policy itself rather than one of its clusters
+func newPolicyAlert(
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	severity alerting.AlertSeverity,
+	alertType, message string,
+	now time.Time,
+) *alerting.Alert {
+	return &alerting.Alert{
+		ClusterName:      policyObj.Name,
+		ClusterNamespace: policyObj.Namespace,
+		Type:             alertType,
+		Policy:           policyObj.Name,
+		Ownership:        policyObj.Spec.Metadata,
+		Severity:         severity,
+		Message:          message,
+		Details: map[string]string{
+			"policy": policyObj.Name,
+		},
+		Timestamp: now,
+	}
 }
 
 // initComponents initializes internal components if not already done
 func (r *StoragePolicyReconciler) initComponents() {
 	if r.discovery == nil {
-		r.discovery = cnpg.NewDiscovery(r.Client)
+		r.discovery = cnpg.NewDiscovery(r.Client).WithInventory(r.Inventory).WithObjectStoreCache(r.ObjectStores)
 	}
 	if r.metricsCollector == nil && r.RestConfig != nil {
 		r.metricsCollector = metrics.NewCollector(r.Client, r.RestConfig)
+		if r.CommandRunner != nil || r.ReadOnly {
+			r.metricsCollector.SetCommandRunner(r.commandRunner())
+		}
+		if r.AgentCollector != nil {
+			r.metricsCollector.SetAgentCollector(r.AgentCollector)
+		}
 	}
 	if r.evaluator == nil {
 		r.evaluator = policy.NewEvaluator()
+		r.evaluator.Clock = clock.OrReal(r.Clock)
 	}
 	if r.expansionEngine == nil {
 		r.expansionEngine = remediation.NewExpansionEngine(r.Client)
 	}
-	if r.walCleanupEngine == nil && r.RestConfig != nil {
-		// WAL cleanup engine requires rest config for pod exec
-		engine, err := remediation.NewWALCleanupEngine(r.Client, r.RestConfig)
-		if err == nil {
+	if r.capacityChecker == nil {
+		r.capacityChecker = remediation.NewCapacityChecker(r.Client)
+	}
+	if r.quotaChecker == nil {
+		r.quotaChecker = remediation.NewQuotaChecker(r.Client)
+	}
+	if r.latencyQuerier == nil {
+		r.latencyQuerier = metrics.NewLatencyQuerier()
+	}
+	if r.walCleanupEngine == nil && r.CommandRunner != nil && !r.ReadOnly {
+		r.walCleanupEngine = remediation.NewWALCleanupEngineWithRunner(r.Client, r.CommandRunner)
+	}
+	if r.walCleanupEngine == nil && r.RestConfig != nil && !r.ReadOnly {
+		if engine, err := remediation.NewWALCleanupEngine(r.Client, r.RestConfig); err == nil {
 			r.walCleanupEngine = engine
 		}
 	}
-	if r.alertManagers == nil {
-		r.alertManagers = make(map[string]*alerting.AlertManager)
+	if r.clusterBackoff == nil {
+		r.clusterBackoff = policy.NewFailureBackoff(policy.DefaultFailureBackoffBase, policy.DefaultFailureBackoffMax)
+	}
+	if r.prometheusRules == nil {
+		r.prometheusRules = alerting.NewPrometheusRuleManager(r.Client, r.Scheme)
+	}
+	if r.events == nil && r.Recorder != nil {
+		r.events = recorder.New(r.Recorder, r.Client)
 	}
 }
 
 // getAlertManager returns the alert manager for a policy, creating one if needed
 func (r *StoragePolicyReconciler) getAlertManager(policyObj *cnpgv1alpha1.StoragePolicy) *alerting.AlertManager {
-	key := fmt.Sprintf("%s/%s", policyObj.Namespace, policyObj.Name)
-
-	if am, ok := r.alertManagers[key]; ok {
+	key := types.NamespacedName{Namespace: policyObj.Namespace, Name: policyObj.Name}
+	am, created := r.alertManagers.get(key, func() *alerting.AlertManager {
+		am := alerting.NewAlertManager(r.Client, policyObj.Spec.Alerting.Channels)
+		am.SetSource(r.ClusterIdentity)
+		am.SetClock(r.Clock)
+		am.SetSecretCache(r.Secrets)
+		am.SeedChannelStatuses(policyObj.Status.AlertChannels)
+		return am
+	})
+	if !created {
 		// Update channels in case they changed
 		am.UpdateChannels(policyObj.Spec.Alerting.Channels)
-		return am
 	}
-
-	// Create new alert manager
-	am := alerting.NewAlertManager(r.Client, policyObj.Spec.Alerting.Channels)
-	r.alertManagers[key] = am
+	am.SetQuietHours(policyObj.Spec.Alerting.QuietHours)
+	am.SetSummary(policyObj.Spec.Alerting.Summary)
+	am.SetStaticLabels(policyObj.Spec.Alerting.Labels, policyObj.Spec.Alerting.Annotations)
 	return am
 }
 
@@ -282,6 +821,13 @@ func (r *StoragePolicyReconciler) handleDeletion(ctx context.Context, policyObj
 			return ctrl.Result{}, err
 		}
 
+		metrics.DeletePolicyMetrics(policyObj.Name, policyObj.Namespace)
+		r.setMissingFeatures(policyObj, nil)
+		r.alertManagers.evict(types.NamespacedName{Namespace: policyObj.Namespace, Name: policyObj.Name})
+		trackExportedMetrics(ctx, r.ExportedMetrics, storagePolicyOwner(policyObj.Namespace, policyObj.Name), nil)
+		scoreManagedClusters(policyObj.Status.ManagedClusters, nil, policyObj.Spec.Thresholds)
+		trackStorageSLOs(policyObj, policyObj.Status.ManagedClusters, nil)
+
 		// Remove finalizer
 		controllerutil.RemoveFinalizer(policyObj, FinalizerName)
 		if err := r.Update(ctx, policyObj); err != nil {
@@ -300,22 +846,8 @@ func (r *StoragePolicyReconciler) cleanupManagedClusters(ctx context.Context, po
 	log := logf.FromContext(ctx)
 
 	for _, mc := range policyObj.Status.ManagedClusters {
-		existingAnnotations, err := r.discovery.GetClusterAnnotations(ctx, mc.Name, mc.Namespace)
-		if err != nil {
-			log.Error(err, "Failed to get cluster annotations", "cluster", mc.Name)
-			continue
-		}
-
-		// Remove our annotations
-		ca := &annotations.ClusterAnnotations{}
-		for k := range existingAnnotations {
-			if len(k) > len(annotations.AnnotationPrefix) && k[:len(annotations.AnnotationPrefix)] == annotations.AnnotationPrefix {
-				delete(existingAnnotations, k)
-			}
-		}
-
-		if err := r.discovery.UpdateClusterAnnotations(ctx, mc.Name, mc.Namespace, ca.GetAnnotations()); err != nil {
-			log.Error(err, "Failed to update cluster annotations", "cluster", mc.Name)
+		if err := r.discovery.RemoveClusterAnnotations(ctx, mc.Name, mc.Namespace); err != nil {
+			log.Error(err, "Failed to remove cluster annotations", "cluster", mc.Name)
 		}
 	}
 
@@ -324,23 +856,41 @@ func (r *StoragePolicyReconciler) cleanupManagedClusters(ctx context.Context, po
 
 // findMatchingClusters finds CNPG clusters matching the policy selector
 func (r *StoragePolicyReconciler) findMatchingClusters(ctx context.Context, policyObj *cnpgv1alpha1.StoragePolicy) ([]cnpg.ClusterInfo, error) {
-	// Get clusters by selector
-	clusters, err := r.discovery.GetClustersBySelector(ctx, "", policyObj.Spec.Selector)
+	return matchClusters(ctx, r.discovery, policyObj.Spec.Selector, policyObj.Spec.IncludeClusters,
+		policyObj.Spec.ExcludeClusters)
+}
+
+// matchClusters returns the CNPG clusters matching a selector or included by name, minus
+// excluded clusters
+func matchClusters(
+	ctx context.Context,
+	discovery *cnpg.Discovery,
+	selector *metav1.LabelSelector,
+	include *cnpgv1alpha1.ClusterInclusion,
+	exclude []cnpgv1alpha1.ClusterReference,
+) ([]cnpg.ClusterInfo, error) {
+	matcher, err := policy.NewClusterMatcher(selector, include, exclude)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get clusters by selector: %w", err)
+		return nil, err
 	}
 
-	// Filter out excluded clusters
-	excludedSet := make(map[string]bool)
-	for _, ref := range policyObj.Spec.ExcludeClusters {
-		key := fmt.Sprintf("%s/%s", ref.Namespace, ref.Name)
-		excludedSet[key] = true
+	// Clusters included by name may carry none of the selector's labels
+	listSelector := selector
+	if include != nil {
+		listSelector = nil
+	}
+	clusters, err := discovery.GetClustersBySelector(ctx, "", listSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clusters by selector: %w", err)
 	}
 
 	var filtered []cnpg.ClusterInfo
 	for _, cluster := range clusters {
-		key := fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name)
-		if !excludedSet[key] {
+		// Throwaway recovery clusters of restore tests are never managed
+		if _, restoreTest := cluster.Labels[backup.LabelRestoreTest]; restoreTest {
+			continue
+		}
+		if matcher.Matches(cluster.Name, cluster.Namespace, cluster.Labels) {
 			filtered = append(filtered, cluster)
 		}
 	}
@@ -348,11 +898,45 @@ func (r *StoragePolicyReconciler) findMatchingClusters(ctx context.Context, poli
 	return filtered, nil
 }
 
+// clustersCoveredByBackupPolicies returns the clusters whose backup health is monitored
+// by a BackupPolicy, keyed by namespace/name. StoragePolicy backup monitoring skips them
+// to avoid duplicate alerts.
+func (r *StoragePolicyReconciler) clustersCoveredByBackupPolicies(ctx context.Context) (map[string]bool, error) {
+	var backupPolicies cnpgv1alpha1.BackupPolicyList
+	if err := r.List(ctx, &backupPolicies); err != nil {
+		return nil, fmt.Errorf("failed to list BackupPolicies: %w", err)
+	}
+
+	covered := make(map[string]bool)
+	for _, bp := range backupPolicies.Items {
+		clusters, err := matchClusters(ctx, r.discovery, bp.Spec.Selector, nil, bp.Spec.ExcludeClusters)
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range clusters {
+			covered[fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name)] = true
+		}
+	}
+	return covered, nil
+}
+
 // processCluster processes a single CNPG cluster
-func (r *StoragePolicyReconciler) processCluster(ctx context.Context, policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo) (*cnpgv1alpha1.ManagedCluster, error) {
+func (r *StoragePolicyReconciler) processCluster(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	backupStatuses *cnpg.BackupStatusIndex,
+	monitorBackups bool,
+) (*cnpgv1alpha1.ManagedCluster, error) {
 	log := logf.FromContext(ctx)
 	log.Info("Processing cluster", "cluster", cluster.Name, "namespace", cluster.Namespace)
 
+	// A hibernated cluster has no pods, so there is nothing to collect or remediate
+	if cluster.Hibernated {
+		log.Info("Cluster is hibernated, skipping", "cluster", cluster.Name)
+		return hibernatedCluster(policyObj, cluster, ""), nil
+	}
+
 	// Get cluster pods for metrics collection
 	pods, err := r.discovery.GetClusterPods(ctx, cluster.Name, cluster.Namespace)
 	if err != nil {
@@ -362,7 +946,8 @@ func (r *StoragePolicyReconciler) processCluster(ctx context.Context, policyObj
 	// Collect metrics
 	var clusterMetrics *metrics.ClusterMetrics
 	if r.metricsCollector != nil {
-		clusterMetrics, err = r.metricsCollector.CollectClusterMetrics(ctx, cluster.Name, cluster.Namespace, pods)
+		source := metrics.Source(policyObj.Spec.MetricsSource)
+		clusterMetrics, err = r.metricsCollector.CollectClusterMetricsFrom(ctx, source, cluster.Name, cluster.Namespace, pods)
 		if err != nil {
 			log.Error(err, "Failed to collect metrics", "cluster", cluster.Name)
 			// Continue without metrics - we'll use what we have
@@ -375,8 +960,9 @@ func (r *StoragePolicyReconciler) processCluster(ctx context.Context, policyObj
 		return nil, fmt.Errorf("failed to get cluster annotations: %w", err)
 	}
 
-	// Create annotations wrapper using the existing annotations map directly
-	clusterAnnotations := &clusterAnnotationsWrapper{annotations: existingAnnotations}
+	// Create annotations wrapper on a copy, so removed keys can be told apart from the
+	// annotations that were read when they are written back
+	clusterAnnotations := &clusterAnnotationsWrapper{annotations: maps.Clone(existingAnnotations), clock: r.Clock}
 	if clusterAnnotations.annotations == nil {
 		clusterAnnotations.annotations = make(map[string]string)
 	}
@@ -390,306 +976,531 @@ func (r *StoragePolicyReconciler) processCluster(ctx context.Context, policyObj
 			LastChecked:  metav1.Now(),
 			UsagePercent: 0,
 			Status:       "Paused",
+			Conditions: clusterConditions(policyObj, cluster, "", policy.ClusterConditionState{
+				Paused:                 true,
+				CircuitBreakerOpen:     clusterAnnotations.IsCircuitBreakerOpen(),
+				CircuitBreakerFailures: clusterAnnotations.GetFailureCount(),
+			}),
+			ExpansionHistory: r.updateExpansionHistory(ctx, policyObj, cluster),
+			Growth:           r.updateGrowth(ctx, policyObj, cluster, clusterMetrics, nil),
+			Decision: &cnpgv1alpha1.EvaluationDecision{
+				Action:       string(policy.ActionTypeNone),
+				BlockReasons: []string{"cluster is paused: " + clusterAnnotations.GetPauseReason()},
+				Result:       "Paused",
+			},
 		}, nil
 	}
 
 	// Calculate usage
+	var usedBytes, capacityBytes int64
 	var usagePercent float64
 	if clusterMetrics != nil {
-		usagePercent = clusterMetrics.TotalUsagePercent()
-	}
-
-	// Build evaluation context
-	evalCtx := policy.EvaluationContext{
-		ClusterName:        cluster.Name,
-		Namespace:          cluster.Namespace,
-		CurrentUsageBytes:  0,
-		CapacityBytes:      0,
-		ActiveRemediation:  false,
-		CircuitBreakerOpen: clusterAnnotations.IsCircuitBreakerOpen(),
+		usedBytes, capacityBytes = clusterUsage(policyObj, clusterMetrics)
+		if capacityBytes > 0 {
+			usagePercent = float64(usedBytes) / float64(capacityBytes) * 100
+		}
 	}
 
-	if clusterMetrics != nil {
-		evalCtx.CurrentUsageBytes = clusterMetrics.TotalUsedBytes
-		evalCtx.CapacityBytes = clusterMetrics.TotalCapacityBytes
+	// Remediation already queued or running for this cluster
+	activeRemediation, err := r.hasActiveRemediation(ctx, cluster)
+	if err != nil {
+		log.Error(err, "Failed to check active storage events", "cluster", cluster.Name)
 	}
 
-	// Get last action times from annotations
-	evalCtx.LastExpansion = clusterAnnotations.GetLastExpansion()
-	evalCtx.LastWALCleanup = clusterAnnotations.GetLastWALCleanup()
-
 	// Perform evaluation
+	evalCtx := clusterEvaluationContext(policyObj, cluster, clusterMetrics, clusterAnnotations, activeRemediation)
 	evalResult, err := r.evaluator.FullEvaluation(evalCtx, policyObj)
 	if err != nil {
 		log.Error(err, "Evaluation failed", "cluster", cluster.Name)
 		return nil, fmt.Errorf("evaluation failed: %w", err)
 	}
-
-	// Record threshold breach if applicable
-	if evalResult.ThresholdResult.Level != policy.ThresholdLevelNormal {
-		metrics.RecordThresholdBreach(cluster.Name, cluster.Namespace, string(evalResult.ThresholdResult.Level))
+	decision := policy.Explain(evalCtx, evalResult, policyObj)
+	// Evaluations blocked by the circuit breaker do not evaluate thresholds, so they keep
+	// the start of the sustained breach for when the breaker closes
+	if !evalResult.Blocked {
+		clusterAnnotations.SetExpansionBreached(evalResult.ThresholdResult.ShouldExpand)
 	}
 
+	r.recordThresholdBreach(cluster, evalResult)
+
+	// Disk metrics can look healthy while the primary already fails writes
+	writeProbe, primaryFenced := r.probeWrites(ctx, policyObj, cluster, usagePercent)
+
 	// Process recommended actions
 	//nolint:goconst // "Healthy" is a descriptive status string, not a constant
 	status := "Healthy"
+	var plannedActions []cnpgv1alpha1.PlannedAction
+	var expansionSkips []cnpgv1alpha1.ExpansionSkipStatus
+	level := evalResult.ThresholdResult.Level
+	if policyObj.Spec.Expansion.Enabled &&
+		(level == policy.ThresholdLevelExpansion || level == policy.ThresholdLevelEmergency) {
+		expansionSkips = r.expansionSkips(ctx, policyObj, cluster, clusterExpansionTarget(policyObj))
+		for _, skip := range expansionSkips {
+			decision.BlockReasons = append(decision.BlockReasons,
+				fmt.Sprintf("PVC %s is skipped (%s)", skip.PVC, skip.Reason))
+		}
+	}
+	maxSizeReached := r.escalateMaxSize(ctx, policyObj, cluster, clusterAnnotations, evalResult.ThresholdResult,
+		expansionSkips)
+	quotaShortfall := r.manageQuota(ctx, policyObj, cluster)
+	r.alertIneffectiveRemediation(ctx, policyObj, cluster)
+	r.reportRemediation(ctx, policyObj, cluster)
 	if evalResult.HasPendingActions() {
 		action := evalResult.GetHighestPriorityAction()
 		if action != nil {
 			switch action.Action {
 			case policy.ActionTypeExpand:
-				dryRun := r.isDryRun(policyObj)
-				if !dryRun {
-					if err := r.handleExpansion(ctx, policyObj, cluster, evalResult, clusterAnnotations); err != nil {
+				dryRun := r.isDryRun(policyObj, cnpgv1alpha1.EventTypeExpansion)
+				switch {
+				case policy.IsPolicyPaused(policyObj, r.now()):
+					log.Info("Policy is paused, not expanding PVCs", "cluster", cluster.Name)
+					status = statusPausedWouldExpand
+					decision.BlockReasons = append(decision.BlockReasons, "policy is paused")
+				case !dryRun:
+					event, err := r.handleExpansion(ctx, policyObj, cluster, evalResult, clusterAnnotations)
+					switch {
+					case isCapacityShortfall(err):
+						status = statusInsufficientCapacity
+						decision.BlockReasons = append(decision.BlockReasons, err.Error())
+					case isQuotaExceeded(err):
+						status = statusQuotaExceeded
+						decision.BlockReasons = append(decision.BlockReasons, err.Error())
+					case err != nil:
 						log.Error(err, "Expansion failed", "cluster", cluster.Name)
 						status = "ExpansionFailed"
-					} else {
+						decision.BlockReasons = append(decision.BlockReasons, "expansion failed: "+err.Error())
+					case event != nil && !remediation.IsEventApproved(event):
+						status = statusAwaitingApproval
+						decision.Action = string(action.Action)
+						decision.BlockReasons = append(decision.BlockReasons, "awaiting approval of "+event.Name)
+					case policyObj.Spec.Expansion.Mode == cnpgv1alpha1.ExpansionModeRecommend:
+						status = "ExpansionRecommended"
+						decision.Action = string(action.Action)
+					default:
 						status = "Expanding"
+						decision.Action = string(action.Action)
 					}
-				} else {
-					log.Info("DryRun: Would expand PVCs", "cluster", cluster.Name, "globalDryRun", r.GlobalDryRun, "policyDryRun", policyObj.Spec.DryRun)
+				default:
+					log.Info("DryRun: Would expand PVCs", "cluster", cluster.Name, "globalDryRun", r.globalDryRun(), "policyDryRun", policy.IsPolicyDryRun(policyObj, r.now()),
+						"expansionDryRun", policyObj.Spec.Expansion.DryRun)
 					status = "DryRun-WouldExpand"
+					decision.BlockReasons = append(decision.BlockReasons, "expansion is in dry-run mode")
+					reason := fmt.Sprintf("threshold breach: %.1f%%", evalResult.ThresholdResult.CurrentUsagePercent)
+					plannedActions = append(plannedActions,
+						r.planDryRunAction(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeExpansion,
+							clusterExpansionTarget(policyObj), reason))
 				}
 
 			case policy.ActionTypeWALCleanup:
-				dryRun := r.isDryRun(policyObj)
-				if !dryRun {
-					if err := r.handleWALCleanup(ctx, policyObj, cluster, clusterAnnotations); err != nil {
+				dryRun := r.isDryRun(policyObj, cnpgv1alpha1.EventTypeWALCleanup)
+				switch {
+				case policy.IsPolicyPaused(policyObj, r.now()):
+					log.Info("Policy is paused, not cleaning up WAL", "cluster", cluster.Name)
+					status = statusPausedWouldCleanupWAL
+					decision.BlockReasons = append(decision.BlockReasons, "policy is paused")
+				case !dryRun:
+					event, err := r.handleWALCleanup(ctx, policyObj, cluster, clusterAnnotations, action, usagePercent)
+					switch {
+					case err != nil:
 						log.Error(err, "WAL cleanup failed", "cluster", cluster.Name)
 						status = "WALCleanupFailed"
-					} else {
+						decision.BlockReasons = append(decision.BlockReasons, "WAL cleanup failed: "+err.Error())
+					case event != nil && !remediation.IsEventApproved(event):
+						status = statusAwaitingApproval
+						decision.Action = string(action.Action)
+						decision.BlockReasons = append(decision.BlockReasons, "awaiting approval of "+event.Name)
+					default:
 						status = "WALCleanup"
+						decision.Action = string(action.Action)
 					}
-				} else {
-					log.Info("DryRun: Would cleanup WAL", "cluster", cluster.Name, "globalDryRun", r.GlobalDryRun, "policyDryRun", policyObj.Spec.DryRun)
+				default:
+					log.Info("DryRun: Would cleanup WAL", "cluster", cluster.Name, "globalDryRun", r.globalDryRun(), "policyDryRun", policy.IsPolicyDryRun(policyObj, r.now()),
+						"walCleanupDryRun", policyObj.Spec.WALCleanup.DryRun)
 					status = "DryRun-WouldCleanupWAL"
+					decision.BlockReasons = append(decision.BlockReasons, "WAL cleanup is in dry-run mode")
+					plannedActions = append(plannedActions,
+						r.planDryRunAction(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeWALCleanup,
+							cnpgv1alpha1.ExpansionTarget{}, action.Reason))
 				}
 
 			case policy.ActionTypeAlert:
 				// Send alert if not suppressed during remediation
-				if !policyObj.Spec.Alerting.SuppressDuringRemediation || status == "Healthy" {
+				if !policyObj.Spec.Alerting.SuppressDuringRemediation || (status == "Healthy" && !activeRemediation) {
 					if err := r.handleAlert(ctx, policyObj, cluster, evalResult); err != nil {
 						log.Error(err, "Failed to send alert", "cluster", cluster.Name)
 					}
+					decision.Action = string(action.Action)
+				} else {
+					decision.BlockReasons = append(decision.BlockReasons, "alert suppressed during remediation")
 				}
 				status = fmt.Sprintf("Alert-%s", evalResult.ThresholdResult.Level)
 			}
 		}
 	}
 
+	decision.Result = status
+	log.V(1).Info("Evaluation decision", "cluster", cluster.Name, "namespace", cluster.Namespace,
+		"decision", decision)
+
+	// Evaluate declarative tablespaces, which are not part of the cluster usage
+	tablespaces, tablespaceActions := r.evaluateTablespaces(ctx, policyObj, cluster, clusterMetrics, clusterAnnotations)
+	plannedActions = append(plannedActions, tablespaceActions...)
+
+	// Evaluate the WAL volumes when they have their own thresholds
+	walVolume, walAction := r.evaluateWALVolume(ctx, policyObj, cluster, clusterMetrics, clusterAnnotations)
+	if walAction != nil {
+		plannedActions = append(plannedActions, *walAction)
+	}
+
+	// Monitor the volumes of the cluster's Poolers, which are never expanded
+	poolerVolumes := r.evaluatePoolerVolumes(ctx, policyObj, cluster)
+
+	// Fence full instances, and unfence them once space is available again
+	fencedInstances := r.manageFencing(ctx, policyObj, cluster, clusterMetrics, clusterAnnotations)
+
+	// Report PVCs stuck waiting for their filesystem resize
+	pendingResizes := r.checkPendingResizes(ctx, policyObj, cluster)
+
+	// Tune volumes whose latency rather than capacity is the bottleneck
+	r.tuneVolumePerformance(ctx, policyObj, cluster, evalResult.ThresholdResult.Level)
+
+	// Move the PVCs to the VolumeAttributesClass of the threshold the cluster reached
+	if clusterMetrics != nil {
+		if action := r.requestVolumeAttributesChange(ctx, policyObj, cluster, evalResult.ThresholdResult.Level,
+			clusterAnnotations); action != nil {
+			plannedActions = append(plannedActions, *action)
+		}
+	}
+
+	// Move the volumes to the StorageClass of the policy's storageClassMigration
+	if action := r.requestStorageClassMigration(ctx, policyObj, cluster, clusterAnnotations); action != nil {
+		plannedActions = append(plannedActions, *action)
+	}
+
 	// Update cluster annotations
 	clusterAnnotations.SetManaged(true)
 	clusterAnnotations.SetPolicyReference(policyObj.Name, policyObj.Namespace)
-	clusterAnnotations.SetLastCheck(time.Now())
+	clusterAnnotations.SetLastCheck(r.now())
 	clusterAnnotations.SetCurrentUsagePercent(int32(usagePercent))
 
 	// Update circuit breaker state metric
 	metrics.SetCircuitBreakerState(cluster.Name, cluster.Namespace, clusterAnnotations.IsCircuitBreakerOpen())
+	metrics.SetPlannedExpansionBytes(cluster.Name, cluster.Namespace, plannedExpansionBytes(plannedActions))
 
-	if err := r.discovery.UpdateClusterAnnotations(ctx, cluster.Name, cluster.Namespace, clusterAnnotations.GetAnnotations()); err != nil {
-		log.Error(err, "Failed to update cluster annotations", "cluster", cluster.Name)
+	// Read-only mode does not patch clusters, nor a manager that may not; the annotations
+	// only live for this reconcile
+	if !r.ReadOnly && !r.lacksPermission(policyObj, featureAnnotations) {
+		if err := r.discovery.UpdateClusterAnnotations(ctx, cluster.Name, cluster.Namespace, existingAnnotations, clusterAnnotations.GetAnnotations()); err != nil {
+			log.Error(err, "Failed to update cluster annotations", "cluster", cluster.Name)
+		}
 	}
 
 	// Collect and evaluate backup status
 	var backupStatus *cnpgv1alpha1.ClusterBackupStatus
-	if policyObj.Spec.BackupMonitoring.Enabled {
-		backupStatus = r.evaluateBackupStatus(ctx, policyObj, cluster)
+	if monitorBackups {
+		backupStatus = r.evaluateBackupStatus(ctx, policyObj, cluster, backupStatuses)
+	}
+
+	conditionState := policy.ClusterConditionState{
+		Backup:                 backupStatus,
+		CircuitBreakerOpen:     clusterAnnotations.IsCircuitBreakerOpen(),
+		CircuitBreakerFailures: clusterAnnotations.GetFailureCount(),
+		WriteProbe:             writeProbe,
+		PrimaryFenced:          primaryFenced,
+		MaxSizeReached:         maxSizeReached,
+		QuotaMonitored:         policyObj.Spec.Quota.Enabled,
+		QuotaShortfall:         quotaShortfall,
+	}
+	if clusterMetrics != nil {
+		conditionState.Threshold = &evalResult.ThresholdResult
+	}
+	expansion, err := remediation.FindActiveEvent(ctx, r.Client, cluster.Name, cluster.Namespace,
+		cnpgv1alpha1.EventTypeExpansion)
+	if err != nil {
+		log.Error(err, "Failed to check active expansion", "cluster", cluster.Name)
+	} else if expansion != nil {
+		conditionState.Expansion = expansion
+		conditionState.ExpansionAwaitingApproval = !remediation.IsEventApproved(expansion)
+	}
+
+	history := r.updateUsageHistory(ctx, policyObj, cluster, clusterMetrics)
+	growth := r.updateGrowth(ctx, policyObj, cluster, clusterMetrics, history)
+	r.updateForecast(ctx, policyObj, cluster, clusterMetrics, growth, history)
+
+	if clusterMetrics != nil && storageCleared(evalResult.ThresholdResult.Level, tablespaces, walVolume, poolerVolumes) {
+		r.resolveAlert(ctx, policyObj, cluster, alerting.AlertTypeStorage)
 	}
 
 	return &cnpgv1alpha1.ManagedCluster{
-		Name:         cluster.Name,
-		Namespace:    cluster.Namespace,
-		LastChecked:  metav1.Now(),
-		UsagePercent: int32(usagePercent),
-		Status:       status,
-		BackupStatus: backupStatus,
+		Name:             cluster.Name,
+		Namespace:        cluster.Namespace,
+		LastChecked:      metav1.Now(),
+		UsagePercent:     int32(usagePercent),
+		UsedBytes:        usedBytes,
+		CapacityBytes:    capacityBytes,
+		Status:           status,
+		BackupStatus:     backupStatus,
+		RecoveryWindow:   recoveryWindow(cluster, backupStatuses, r.now()),
+		Conditions:       clusterConditions(policyObj, cluster, "", conditionState),
+		PlannedActions:   plannedActions,
+		ExpansionHistory: r.updateExpansionHistory(ctx, policyObj, cluster),
+		Growth:           growth,
+		Tablespaces:      tablespaces,
+		WALVolume:        walVolume,
+		PoolerVolumes:    poolerVolumes,
+		Zones:            zoneStatuses(clusterMetrics),
+		FencedInstances:  fencedInstances,
+		ExpansionSkips:   expansionSkips,
+		PendingResizes:   pendingResizes,
+		Decision:         decision,
 	}, nil
 }
 
-// handleExpansion handles PVC expansion for a cluster using the remediation engine
-func (r *StoragePolicyReconciler) handleExpansion(ctx context.Context, policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo, evalResult *policy.EvaluationResult, ca *clusterAnnotationsWrapper) error {
-	log := logf.FromContext(ctx)
-
-	// Check if expansion is allowed (cooldown, circuit breaker, etc.)
-	if allowed, reason := ca.CanExpand(policyObj.Spec.Expansion.CooldownMinutes); !allowed {
-		log.Info("Expansion not allowed", "cluster", cluster.Name, "reason", reason)
+// zoneStatuses returns the per-zone usage of a cluster, or nil when its nodes carry no
+// zone labels
+func zoneStatuses(clusterMetrics *metrics.ClusterMetrics) []cnpgv1alpha1.ZoneUsageStatus {
+	if clusterMetrics == nil {
 		return nil
 	}
+	var statuses []cnpgv1alpha1.ZoneUsageStatus
+	for _, zone := range clusterMetrics.ZoneUsage() {
+		statuses = append(statuses, cnpgv1alpha1.ZoneUsageStatus{
+			Zone:          zone.Zone,
+			Region:        zone.Region,
+			Instances:     zone.Instances,
+			UsagePercent:  int32(zone.UsagePercent()),
+			UsedBytes:     zone.UsedBytes,
+			CapacityBytes: zone.CapacityBytes,
+		})
+	}
+	return statuses
+}
 
-	// Get cluster PVCs
-	pvcs, err := r.discovery.GetClusterPVCs(ctx, cluster.Name, cluster.Namespace)
-	if err != nil {
-		return fmt.Errorf("failed to get cluster PVCs: %w", err)
-	}
+// hibernatedCluster returns the status of a hibernated cluster. The expansion history
+// and growth samples of its previous entry are kept for when it resumes
+func hibernatedCluster(
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	connectionName string,
+) *cnpgv1alpha1.ManagedCluster {
+	mc := &cnpgv1alpha1.ManagedCluster{
+		Name:        cluster.Name,
+		Namespace:   cluster.Namespace,
+		Connection:  connectionName,
+		LastChecked: metav1.Now(),
+		Status:      statusHibernated,
+		Conditions: clusterConditions(policyObj, cluster, connectionName,
+			policy.ClusterConditionState{Hibernated: true}),
+	}
+	if previous := previousManagedCluster(policyObj, cluster, connectionName); previous != nil {
+		mc.ExpansionHistory = previous.ExpansionHistory
+		mc.Growth = previous.Growth
+	}
+	return mc
+}
 
-	if len(pvcs) == 0 {
-		log.Info("No PVCs found for cluster", "cluster", cluster.Name)
+// recoveryWindow returns the point-in-time recovery window of a cluster, or nil when
+// it has no recovery point
+func recoveryWindow(
+	cluster cnpg.ClusterInfo,
+	backupStatuses *cnpg.BackupStatusIndex,
+	now time.Time,
+) *cnpgv1alpha1.RecoveryWindowStatus {
+	status, err := backupStatuses.ForCluster(cluster)
+	if err != nil || status == nil || status.FirstRecoverabilityPoint == nil {
 		return nil
 	}
 
-	// Build expansion request
-	req := &remediation.ExpansionRequest{
-		ClusterName:      cluster.Name,
-		ClusterNamespace: cluster.Namespace,
-		PVCs:             pvcs,
-		Policy:           policyObj,
-		Reason:           fmt.Sprintf("threshold breach: %.1f%%", evalResult.ThresholdResult.CurrentUsagePercent),
-		DryRun:           r.isDryRun(policyObj),
+	first := metav1.NewTime(*status.FirstRecoverabilityPoint)
+	window := &cnpgv1alpha1.RecoveryWindowStatus{
+		FirstRecoverabilityPoint: &first,
+		WindowHours:              int32(now.Sub(first.Time).Hours()),
 	}
-
-	// Execute expansion using the remediation engine
-	result, err := r.expansionEngine.ExpandClusterPVCs(ctx, req)
-	if err != nil {
-		log.Error(err, "Expansion engine error", "cluster", cluster.Name)
-		ca.IncrementFailureCount()
-		return fmt.Errorf("expansion failed: %w", err)
+	if status.LastSuccessfulBackupTime != nil {
+		last := metav1.NewTime(*status.LastSuccessfulBackupTime)
+		window.LastBackupTime = &last
 	}
+	return window
+}
 
-	// Process results
-	if !result.Success {
-		log.Info("Expansion completed with failures", "cluster", cluster.Name, "results", len(result.PVCResults))
-
-		// Count failures
-		failCount := 0
-		for _, pvcResult := range result.PVCResults {
-			if !pvcResult.Success && !pvcResult.Skipped {
-				failCount++
-				log.Error(fmt.Errorf("%s", pvcResult.Error), "PVC expansion failed", "pvc", pvcResult.PVCName)
-			}
-		}
-
-		ca.IncrementFailureCount()
-
-		// Check if we should open circuit breaker
-		if ca.GetFailureCount() >= policyObj.Spec.CircuitBreaker.MaxFailures {
-			ca.SetCircuitBreakerOpen(true)
-			log.Info("Opening circuit breaker", "cluster", cluster.Name, "failures", ca.GetFailureCount())
-		}
+// handleExpansion requests PVC expansion for a cluster by creating a Pending StorageEvent.
+// The StorageEvent controller performs the actual resize.
+func (r *StoragePolicyReconciler) handleExpansion(ctx context.Context, policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo, evalResult *policy.EvaluationResult, ca *clusterAnnotationsWrapper) (*cnpgv1alpha1.StorageEvent, error) {
+	log := logf.FromContext(ctx)
 
-		return fmt.Errorf("expansion failed for %d PVCs", failCount)
-	}
-
-	// Log success details
-	expandedCount := 0
-	skippedCount := 0
-	for _, pvcResult := range result.PVCResults {
-		if pvcResult.Skipped {
-			skippedCount++
-			log.V(1).Info("PVC skipped", "pvc", pvcResult.PVCName, "reason", pvcResult.SkipReason)
-		} else if pvcResult.Success {
-			expandedCount++
-			log.Info("PVC expanded successfully",
-				"pvc", pvcResult.PVCName,
-				"originalSize", pvcResult.OriginalSize.String(),
-				"newSize", pvcResult.NewSize.String())
-		}
+	// Check if expansion is allowed (cooldown, circuit breaker, etc.)
+	if allowed, reason := ca.CanExpand(policyObj.Spec.Expansion.CooldownMinutes); !allowed {
+		log.Info("Expansion not allowed", "cluster", cluster.Name, "reason", reason)
+		return nil, nil
 	}
 
-	log.Info("Expansion completed",
-		"cluster", cluster.Name,
-		"expanded", expandedCount,
-		"skipped", skippedCount,
-		"totalBytesAdded", result.TotalBytesAdded,
-		"duration", result.Duration)
+	usage := evalResult.ThresholdResult.CurrentUsagePercent
+	reason := fmt.Sprintf("threshold breach: %.1f%%", usage)
+	return r.requestRemediation(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeExpansion,
+		clusterExpansionTarget(policyObj), reason, usage)
+}
 
-	// Update annotations
-	ca.SetLastExpansion(time.Now())
-	ca.ResetFailureCount()
+// clusterUsage returns the used and capacity bytes the policy thresholds apply to: the
+// data and WAL volumes, or only the data volumes when the WAL volumes have their own
+// thresholds
+func clusterUsage(policyObj *cnpgv1alpha1.StoragePolicy, clusterMetrics *metrics.ClusterMetrics) (int64, int64) {
+	if policyObj.Spec.WALThresholds != nil {
+		return clusterMetrics.DataUsage()
+	}
+	return clusterMetrics.TotalUsedBytes, clusterMetrics.TotalCapacityBytes
+}
 
-	// Create StorageEvent for audit trail
-	if !r.isDryRun(policyObj) {
-		event, err := r.expansionEngine.CreateExpansionEvent(ctx, req, result)
-		if err != nil {
-			log.Error(err, "Failed to create storage event")
-		} else {
-			// Update event status
-			if err := r.expansionEngine.UpdateExpansionEventStatus(ctx, event, result); err != nil {
-				log.Error(err, "Failed to update storage event status")
-			}
-		}
+// clusterEvaluationContext builds the evaluation context of a cluster from its metrics,
+// which may be nil, and the last action times recorded in its annotations
+func clusterEvaluationContext(
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	clusterMetrics *metrics.ClusterMetrics,
+	ca *clusterAnnotationsWrapper,
+	activeRemediation bool,
+) policy.EvaluationContext {
+	evalCtx := policy.EvaluationContext{
+		ClusterName:            cluster.Name,
+		Namespace:              cluster.Namespace,
+		ActiveRemediation:      activeRemediation,
+		CircuitBreakerOpen:     ca.IsCircuitBreakerOpen(),
+		LastExpansion:          ca.GetLastExpansion(),
+		LastWALCleanup:         ca.GetLastWALCleanup(),
+		ExpansionBreachedSince: ca.GetExpansionBreachedSince(),
 	}
+	if clusterMetrics != nil {
+		evalCtx.CurrentUsageBytes, evalCtx.CapacityBytes = clusterUsage(policyObj, clusterMetrics)
+		evalCtx.FreeBytes = clusterMetrics.LeastFreeBytes(policyObj.Spec.WALThresholds == nil)
+		evalCtx.ReplicaUsagePercent = clusterMetrics.HighestReplicaUsagePercent(cluster.Status.CurrentPrimary)
+	}
+	return evalCtx
+}
 
-	return nil
+// clusterExpansionTarget returns the PVCs expanded when the cluster usage breaches the
+// expansion threshold: every volume of each instance when the policy groups them,
+// otherwise leaving out the WAL volumes when they have their own thresholds
+func clusterExpansionTarget(policyObj *cnpgv1alpha1.StoragePolicy) cnpgv1alpha1.ExpansionTarget {
+	if policyObj.Spec.Expansion.GroupInstanceVolumes {
+		return cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeAll}
+	}
+	if policyObj.Spec.WALThresholds != nil {
+		return cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeData}
+	}
+	return cnpgv1alpha1.ExpansionTarget{}
 }
 
-// handleWALCleanup handles WAL cleanup for a cluster using the remediation engine
-func (r *StoragePolicyReconciler) handleWALCleanup(ctx context.Context, policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo, ca *clusterAnnotationsWrapper) error {
+// handleWALCleanup requests WAL cleanup for a cluster by creating a Pending StorageEvent.
+// The StorageEvent controller performs the actual cleanup.
+func (r *StoragePolicyReconciler) handleWALCleanup(ctx context.Context, policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo, ca *clusterAnnotationsWrapper, action *policy.ActionRecommendation, usagePercent float64) (*cnpgv1alpha1.StorageEvent, error) {
 	log := logf.FromContext(ctx)
 
 	// Check if WAL cleanup is allowed
 	if allowed, reason := ca.CanWALCleanup(policyObj.Spec.WALCleanup.CooldownMinutes); !allowed {
 		log.Info("WAL cleanup not allowed", "cluster", cluster.Name, "reason", reason)
-		return nil
+		return nil, nil
 	}
 
-	// Check if WAL cleanup engine is available
-	if r.walCleanupEngine == nil {
-		log.Info("WAL cleanup engine not available, skipping", "cluster", cluster.Name)
-		return nil
+	reason := "emergency threshold breach"
+	replica, _ := action.Parameters["replica"].(bool)
+	atMaxSize, _ := action.Parameters["maxSize"].(bool)
+	if replica || atMaxSize {
+		reason = action.Reason
 	}
+	return r.requestRemediation(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeWALCleanup,
+		cnpgv1alpha1.ExpansionTarget{}, reason, usagePercent)
+}
 
-	// Get primary pod
-	primaryPod, err := r.discovery.GetPrimaryPod(ctx, cluster.Name, cluster.Namespace)
-	if err != nil {
-		return fmt.Errorf("failed to get primary pod: %w", err)
-	}
+// requestRemediation creates a Pending StorageEvent unless one of the same type is already active.
+// Expansions are tracked per target, so a tablespace or the WAL volumes expand independently.
+// It returns the active or newly created event.
+func (r *StoragePolicyReconciler) requestRemediation(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	eventType cnpgv1alpha1.EventType,
+	target cnpgv1alpha1.ExpansionTarget,
+	reason string,
+	usagePercent float64,
+) (*cnpgv1alpha1.StorageEvent, error) {
+	log := logf.FromContext(ctx)
 
-	// Build cleanup request
-	req := &remediation.WALCleanupRequest{
-		ClusterName:      cluster.Name,
-		ClusterNamespace: cluster.Namespace,
-		PrimaryPod:       primaryPod,
-		Policy:           policyObj,
-		Reason:           "emergency threshold breach",
-		DryRun:           r.isDryRun(policyObj),
+	var active *cnpgv1alpha1.StorageEvent
+	var err error
+	if eventType == cnpgv1alpha1.EventTypeExpansion {
+		active, err = remediation.FindActiveExpansion(ctx, r.Client, cluster.Name, cluster.Namespace, target)
+	} else {
+		active, err = remediation.FindActiveEvent(ctx, r.Client, cluster.Name, cluster.Namespace, eventType)
 	}
-
-	// Execute WAL cleanup
-	result, err := r.walCleanupEngine.CleanupClusterWAL(ctx, req)
 	if err != nil {
-		log.Error(err, "WAL cleanup failed", "cluster", cluster.Name)
-		ca.IncrementFailureCount()
-
-		// Check if we should open circuit breaker
-		if ca.GetFailureCount() >= policyObj.Spec.CircuitBreaker.MaxFailures {
-			ca.SetCircuitBreakerOpen(true)
-			log.Info("Opening circuit breaker after WAL cleanup failure", "cluster", cluster.Name)
+		return nil, err
+	}
+	if active != nil {
+		log.V(1).Info("Remediation already in progress", "cluster", cluster.Name, "event", active.Name, "type", eventType,
+			"tablespace", target.Tablespace, "volume", target.Volume)
+		return active, nil
+	}
+	if eventType == cnpgv1alpha1.EventTypeExpansion {
+		if err := r.checkQuota(ctx, policyObj, cluster, target); err != nil {
+			return nil, err
+		}
+		if err := r.checkBackendCapacity(ctx, policyObj, cluster, target); err != nil {
+			return nil, err
 		}
-
-		return fmt.Errorf("WAL cleanup failed: %w", err)
 	}
 
-	if !result.Success {
-		log.Info("WAL cleanup completed with no files removed", "cluster", cluster.Name)
-	} else {
-		log.Info("WAL cleanup completed successfully",
-			"cluster", cluster.Name,
-			"filesRemoved", result.FilesRemoved,
-			"bytesFreed", result.BytesFreed,
-			"duration", result.Duration)
+	event := remediation.NewPendingEvent(policyObj, cluster.Name, cluster.Namespace, eventType, reason)
+	event.Spec.TriggerUsagePercent = int32(usagePercent)
+	event.Spec.ExpansionTarget = target
+	event.Spec.Ownership = eventOwnership(policyObj, cluster)
+	if err := r.Create(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to create %s event: %w", eventType, err)
 	}
 
-	// Update annotations
-	ca.SetLastWALCleanup(time.Now())
-	ca.ResetFailureCount()
+	log.Info("Remediation requested", "cluster", cluster.Name, "event", event.Name, "type", eventType,
+		"reason", reason, "approvalRequired", event.Spec.ApprovalRequired)
+	return event, nil
+}
 
-	// Create StorageEvent for audit trail
-	if !r.isDryRun(policyObj) && result.FilesRemoved > 0 {
-		event, err := r.walCleanupEngine.CreateWALCleanupEvent(ctx, req, result)
+// hasActiveRemediation returns true if an expansion or WAL cleanup event is still running for the cluster
+func (r *StoragePolicyReconciler) hasActiveRemediation(ctx context.Context, cluster cnpg.ClusterInfo) (bool, error) {
+	for _, eventType := range []cnpgv1alpha1.EventType{cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.EventTypeWALCleanup} {
+		active, err := remediation.FindActiveEvent(ctx, r.Client, cluster.Name, cluster.Namespace, eventType)
 		if err != nil {
-			log.Error(err, "Failed to create WAL cleanup event")
-		} else {
-			if err := r.walCleanupEngine.UpdateWALCleanupEventStatus(ctx, event, result); err != nil {
-				log.Error(err, "Failed to update WAL cleanup event status")
-			}
+			return false, err
+		}
+		if active != nil {
+			return true, nil
 		}
 	}
+	return false, nil
+}
 
-	return nil
+// recordThresholdBreach records the breach metric and a ThresholdBreached event when
+// usage is above a threshold. Evaluations blocked by the circuit breaker have no
+// threshold result, so they record neither
+func (r *StoragePolicyReconciler) recordThresholdBreach(cluster cnpg.ClusterInfo, evalResult *policy.EvaluationResult) {
+	result := evalResult.ThresholdResult
+	if evalResult.Blocked || result.Level == "" || result.Level == policy.ThresholdLevelNormal {
+		return
+	}
+	metrics.RecordThresholdBreach(cluster.Name, cluster.Namespace, string(result.Level))
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonThresholdBreached, "%s", result.Message)
 }
 
 // handleAlert handles sending alerts for a cluster
 func (r *StoragePolicyReconciler) handleAlert(ctx context.Context, policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo, evalResult *policy.EvaluationResult) error {
+	return r.sendThresholdAlert(ctx, policyObj, cluster, nil, evalResult.ThresholdResult)
+}
+
+// sendThresholdAlert sends a storage threshold alert for a cluster. conn is set for
+// clusters reached through a ClusterConnection
+func (r *StoragePolicyReconciler) sendThresholdAlert(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	conn *connection.Connection,
+	result policy.ThresholdResult,
+) error {
 	log := logf.FromContext(ctx)
 
 	// Skip if no alert channels are configured
@@ -703,7 +1514,7 @@ func (r *StoragePolicyReconciler) handleAlert(ctx context.Context, policyObj *cn
 
 	// Map threshold level to alert severity
 	var severity alerting.AlertSeverity
-	switch evalResult.ThresholdResult.Level {
+	switch result.Level {
 	case policy.ThresholdLevelWarning:
 		severity = alerting.AlertSeverityWarning
 	case policy.ThresholdLevelCritical:
@@ -719,14 +1530,27 @@ func (r *StoragePolicyReconciler) handleAlert(ctx context.Context, policyObj *cn
 	alert := &alerting.Alert{
 		ClusterName:      cluster.Name,
 		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeStorage,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
 		Severity:         severity,
-		Message:          evalResult.ThresholdResult.Message,
+		Message:          result.Message,
+		UsagePercent:     result.CurrentUsagePercent,
+		Thresholds:       result.Thresholds,
 		Details: map[string]string{
-			"usage_percent": fmt.Sprintf("%.1f", evalResult.ThresholdResult.CurrentUsagePercent),
-			"threshold":     string(evalResult.ThresholdResult.Level),
+			"usage_percent": fmt.Sprintf("%.1f", result.CurrentUsagePercent),
+			"threshold":     string(result.Level),
 			"policy":        policyObj.Name,
 		},
-		Timestamp: time.Now(),
+		Timestamp: r.now(),
+	}
+	if conn != nil {
+		alert.Connection = conn.Name
+		alert.Source = conn.Identity
+	} else if result.Level == policy.ThresholdLevelEmergency && !am.IsSuppressed(alert) {
+		if hints := r.logHints(ctx, policyObj, cluster); hints != "" {
+			alert.Details[alerting.DetailLogHints] = hints
+		}
 	}
 
 	// Send alert
@@ -735,15 +1559,85 @@ func (r *StoragePolicyReconciler) handleAlert(ctx context.Context, policyObj *cn
 		return err
 	}
 
-	log.Info("Alert sent successfully", "cluster", cluster.Name, "severity", severity)
+	log.Info("Alert sent successfully", "cluster", cluster.Name, "connection", alert.Connection, "severity", severity)
 	return nil
 }
 
+// storageCleared returns true when neither the data volumes nor any tablespace, WAL or
+// pooler volume is above a threshold, so the cluster's storage alert no longer applies
+func storageCleared(level policy.ThresholdLevel, tablespaces []cnpgv1alpha1.TablespaceStatus,
+	walVolume *cnpgv1alpha1.WALVolumeStatus, poolerVolumes []cnpgv1alpha1.PoolerVolumeStatus) bool {
+	if level != policy.ThresholdLevelNormal {
+		return false
+	}
+	for _, tablespace := range tablespaces {
+		if tablespace.Status != "Healthy" {
+			return false
+		}
+	}
+	for _, volume := range poolerVolumes {
+		if volume.Status != "Healthy" {
+			return false
+		}
+	}
+	return walVolume == nil || walVolume.Status == "Healthy"
+}
+
+// resolveAlert reports that the problem alerts of alertType were sent for has cleared
+// on a local cluster, so ticketing channels close the ticket they opened for it
+func (r *StoragePolicyReconciler) resolveAlert(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	alertType string,
+) {
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	alert := &alerting.Alert{ClusterName: cluster.Name, ClusterNamespace: cluster.Namespace, Type: alertType}
+	if err := r.getAlertManager(policyObj).ResolveAlert(ctx, alert); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to resolve alert", "cluster", cluster.Name, "type", alertType)
+	}
+}
+
+// reportRemediation posts the result of the last expansion or WAL cleanup of a cluster
+// to the storage alert threads of the policy's Slack bot-token channels
+func (r *StoragePolicyReconciler) reportRemediation(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+) {
+	if !slices.ContainsFunc(policyObj.Spec.Alerting.Channels, func(channel cnpgv1alpha1.AlertChannel) bool {
+		return channel.Type == cnpgv1alpha1.AlertChannelTypeSlack && channel.BotTokenSecret != ""
+	}) {
+		return
+	}
+	log := logf.FromContext(ctx)
+
+	event, err := remediation.FindLatestFinishedEvent(ctx, r.Client, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to find finished remediation", "cluster", cluster.Name)
+		return
+	}
+	if event == nil {
+		return
+	}
+
+	text := fmt.Sprintf("%s (StorageEvent %s) %s: %s", event.Spec.EventType, event.Name,
+		strings.ToLower(string(event.Status.Phase)), event.Status.Message)
+	alert := &alerting.Alert{ClusterName: cluster.Name, ClusterNamespace: cluster.Namespace, Type: alerting.AlertTypeStorage}
+	if err := r.getAlertManager(policyObj).ReportRemediation(ctx, alert, event.Name,
+		event.Status.CompletionTime.Time, text); err != nil {
+		log.Error(err, "Failed to report remediation", "cluster", cluster.Name, "event", event.Name)
+	}
+}
+
 // evaluateBackupStatus evaluates the backup status of a cluster and sends alerts if needed
 func (r *StoragePolicyReconciler) evaluateBackupStatus(
 	ctx context.Context,
 	policyObj *cnpgv1alpha1.StoragePolicy,
 	cluster cnpg.ClusterInfo,
+	backupStatuses *cnpg.BackupStatusIndex,
 ) *cnpgv1alpha1.ClusterBackupStatus {
 	log := logf.FromContext(ctx)
 
@@ -753,8 +1647,10 @@ func (r *StoragePolicyReconciler) evaluateBackupStatus(
 		BackupHealthStatus:         "Healthy",
 	}
 
-	now := time.Now()
+	now := r.now()
 	config := policyObj.Spec.BackupMonitoring
+	// Clusters may declare their own maximum backup age through an annotation
+	config.MaxBackupAgeHours = backup.MaxBackupAgeHours(cluster.Annotations, config.MaxBackupAgeHours)
 	healthy := true
 	var alertReasons []string
 
@@ -773,8 +1669,14 @@ func (r *StoragePolicyReconciler) evaluateBackupStatus(
 	var firstRecoverabilityPoint *time.Time
 
 	if cluster.Status.BarmanCloudPlugin != nil && cluster.Status.BarmanCloudPlugin.Enabled {
-		// Get backup status from ObjectStore CRD
-		objectStoreStatus, err := r.discovery.GetBackupStatusForCluster(ctx, cluster)
+		// Get backup status from ObjectStore CRD, using the per-cycle index when available
+		var objectStoreStatus *cnpg.ObjectStoreBackupStatus
+		var err error
+		if backupStatuses != nil {
+			objectStoreStatus, err = backupStatuses.ForCluster(cluster)
+		} else {
+			objectStoreStatus, err = r.discovery.GetBackupStatusForCluster(ctx, cluster)
+		}
 		if err != nil {
 			log.Error(err, "Failed to get ObjectStore backup status, falling back to cluster status",
 				"cluster", cluster.Name, "objectStore", cluster.Status.BarmanCloudPlugin.ObjectStoreName)
@@ -889,19 +1791,46 @@ func (r *StoragePolicyReconciler) evaluateBackupStatus(
 	// Record overall backup health metric
 	metrics.RecordBackupMetrics(cluster.Name, cluster.Namespace, nil, nil,
 		archivingWorking, cluster.Status.BackupConfigured, healthy)
+	recordBackupMethodTimes(cluster, now)
 
 	// Send alerts for backup issues
 	if len(alertReasons) > 0 {
 		r.sendBackupAlert(ctx, policyObj, cluster, alertReasons)
+	} else {
+		r.resolveAlert(ctx, policyObj, cluster, alerting.AlertTypeBackup)
 	}
 
 	return status
 }
 
-// sendBackupAlert sends an alert for backup issues
+// recordBackupMethodTimes exports the per-method backup times CNPG reports in the cluster status
+func recordBackupMethodTimes(cluster cnpg.ClusterInfo, now time.Time) {
+	methods := make(map[string]struct{})
+	for method := range cluster.Status.LastSuccessfulBackupByMethod {
+		methods[method] = struct{}{}
+	}
+	for method := range cluster.Status.FirstRecoverabilityPointByMethod {
+		methods[method] = struct{}{}
+	}
+	for method := range methods {
+		var last, first *time.Time
+		if t, ok := cluster.Status.LastSuccessfulBackupByMethod[method]; ok {
+			last = &t
+		}
+		if t, ok := cluster.Status.FirstRecoverabilityPointByMethod[method]; ok {
+			first = &t
+		}
+		metrics.RecordBackupMethodTimes(cluster.Name, cluster.Namespace, method, last, first, now)
+	}
+}
+
+// sendBackupAlert records a BackupUnhealthy event and sends an alert for backup issues
 func (r *StoragePolicyReconciler) sendBackupAlert(ctx context.Context, policyObj *cnpgv1alpha1.StoragePolicy, cluster cnpg.ClusterInfo, reasons []string) {
 	log := logf.FromContext(ctx)
 
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonBackupUnhealthy,
+		"Backup issues: %s", strings.Join(reasons, "; "))
+
 	// Skip if no alert channels are configured
 	if len(policyObj.Spec.Alerting.Channels) == 0 {
 		log.V(1).Info("No alert channels configured, skipping backup alert", "cluster", cluster.Name)
@@ -929,14 +1858,17 @@ func (r *StoragePolicyReconciler) sendBackupAlert(ctx context.Context, policyObj
 	alert := &alerting.Alert{
 		ClusterName:      cluster.Name,
 		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeBackup,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
 		Severity:         severity,
 		Message:          message,
 		Details: map[string]string{
-			"alert_type":  "backup",
 			"policy":      policyObj.Name,
 			"issue_count": fmt.Sprintf("%d", len(reasons)),
 		},
-		Timestamp: time.Now(),
+		Timestamp:  r.now(),
+		LastBackup: cluster.Status.LastSuccessfulBackup,
 	}
 
 	// Add each reason as a detail
@@ -954,8 +1886,6 @@ func (r *StoragePolicyReconciler) sendBackupAlert(ctx context.Context, policyObj
 }
 
 // setCondition sets a condition on the StoragePolicy status
-//
-//nolint:unparam // conditionType parameter kept for potential future use with multiple condition types
 func (r *StoragePolicyReconciler) setCondition(policyObj *cnpgv1alpha1.StoragePolicy, conditionType string, status metav1.ConditionStatus, reason, message string) {
 	condition := metav1.Condition{
 		Type:               conditionType,
@@ -970,16 +1900,105 @@ func (r *StoragePolicyReconciler) setCondition(policyObj *cnpgv1alpha1.StoragePo
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *StoragePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&cnpgv1alpha1.StoragePolicy{}).
-		Named("storagepolicy").
-		Complete(r)
+		Named("storagepolicy")
+	// Policies waiting for CloudNativePG recover as soon as its CRDs are installed
+	b = watchCNPGCRD(b, r.allPolicies)
+	if r.Inventory != nil {
+		// Reconcile policies as soon as a cluster they select appears, disappears or is relabeled
+		b = b.WatchesRawSource(source.Channel(watchInventory(r.Inventory),
+			handler.EnqueueRequestsFromMapFunc(r.policiesForCluster)))
+	}
+	if r.ObjectStores != nil {
+		// Re-check backup health as soon as a selected cluster's recovery window changes
+		b = b.WatchesRawSource(source.Channel(watchObjectStores(r.ObjectStores, r.Inventory),
+			handler.EnqueueRequestsFromMapFunc(r.policiesForCluster)))
+	}
+	if r.Secrets != nil {
+		// Re-check alert channels as soon as a secret they read is rotated, created or deleted
+		b = b.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.policiesForSecret),
+			builder.OnlyMetadata)
+	}
+	if r.WatchDiskErrors {
+		// Evaluate a cluster as soon as one of its instances runs out of space
+		b = b.Watches(&corev1.Event{}, handler.EnqueueRequestsFromMapFunc(r.policiesForDiskError),
+			builder.WithPredicates(diskErrorPredicate()))
+	}
+	return b.Complete(tracing.Reconciler("StoragePolicy", r))
+}
+
+// policiesForSecret maps a Secret to the policies whose alert channels read it
+func (r *StoragePolicyReconciler) policiesForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	var policies cnpgv1alpha1.StoragePolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list storagepolicies for secret change", "secret", secret.GetName())
+		return nil
+	}
+	defaults, err := managerconfig.Load(ctx, r.Client)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to load ManagerConfig for secret change")
+	}
+
+	var requests []reconcile.Request
+	for _, p := range policies.Items {
+		managerconfig.ApplyAlertingDefaults(&p.Spec.Alerting, defaults)
+		if referencesSecret(p.Spec.Alerting.Channels, secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: p.Name, Namespace: p.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// allPolicies maps an object to every StoragePolicy
+func (r *StoragePolicyReconciler) allPolicies(ctx context.Context, obj client.Object) []reconcile.Request {
+	var policies cnpgv1alpha1.StoragePolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list storagepolicies", "trigger", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(policies.Items))
+	for _, p := range policies.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: p.Name, Namespace: p.Namespace},
+		})
+	}
+	return requests
+}
+
+// policiesForCluster maps a CNPG cluster to the policies selecting it
+func (r *StoragePolicyReconciler) policiesForCluster(ctx context.Context, cluster client.Object) []reconcile.Request {
+	var policies cnpgv1alpha1.StoragePolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list storagepolicies for cluster change", "cluster", cluster.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, p := range policies.Items {
+		if selectsCluster(p.Spec.Selector, p.Spec.IncludeClusters, p.Spec.ExcludeClusters, cluster) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: p.Name, Namespace: p.Namespace},
+			})
+		}
+	}
+	return requests
 }
 
 // clusterAnnotationsWrapper wraps annotations.ClusterAnnotations functionality
 // using the annotations from the cluster
 type clusterAnnotationsWrapper struct {
 	annotations map[string]string
+	// clock is the time pause expiry and cooldowns are checked against. The system
+	// clock when nil
+	clock clock.Clock
+}
+
+func (c *clusterAnnotationsWrapper) now() time.Time {
+	return clock.OrReal(c.clock).Now()
 }
 
 func (c *clusterAnnotationsWrapper) GetAnnotations() map[string]string {
@@ -1006,7 +2025,7 @@ func (c *clusterAnnotationsWrapper) IsPaused() bool {
 	// Check if pause has expired
 	if pauseUntil, ok := c.annotations[annotations.AnnotationPauseUntil]; ok {
 		if t, err := time.Parse(time.RFC3339, pauseUntil); err == nil {
-			if time.Now().After(t) {
+			if c.now().After(t) {
 				return false
 			}
 		}
@@ -1044,6 +2063,27 @@ func (c *clusterAnnotationsWrapper) SetLastExpansion(t time.Time) {
 	c.annotations[annotations.AnnotationLastExpansion] = t.Format(time.RFC3339)
 }
 
+func (c *clusterAnnotationsWrapper) GetExpansionBreachedSince() *time.Time {
+	if ts, ok := c.annotations[annotations.AnnotationExpansionBreached]; ok {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// SetExpansionBreached records when the cluster started to breach the expansion
+// threshold, keeping the time of an ongoing breach, and forgets it once it no longer does
+func (c *clusterAnnotationsWrapper) SetExpansionBreached(breached bool) {
+	if !breached {
+		delete(c.annotations, annotations.AnnotationExpansionBreached)
+		return
+	}
+	if c.GetExpansionBreachedSince() == nil {
+		c.annotations[annotations.AnnotationExpansionBreached] = c.now().Format(time.RFC3339)
+	}
+}
+
 func (c *clusterAnnotationsWrapper) GetLastWALCleanup() *time.Time {
 	if ts, ok := c.annotations[annotations.AnnotationWALCleanupLast]; ok {
 		if t, err := time.Parse(time.RFC3339, ts); err == nil {
@@ -1086,7 +2126,7 @@ func (c *clusterAnnotationsWrapper) SetFailureCount(count int32) {
 func (c *clusterAnnotationsWrapper) IncrementFailureCount() int32 {
 	count := c.GetFailureCount() + 1
 	c.SetFailureCount(count)
-	c.annotations[annotations.AnnotationLastFailure] = time.Now().Format(time.RFC3339)
+	c.annotations[annotations.AnnotationLastFailure] = c.now().Format(time.RFC3339)
 	return count
 }
 
@@ -1095,6 +2135,24 @@ func (c *clusterAnnotationsWrapper) ResetFailureCount() {
 	delete(c.annotations, annotations.AnnotationLastFailure)
 }
 
+// GetFencedInstances returns the instances the manager fenced
+func (c *clusterAnnotationsWrapper) GetFencedInstances() []string {
+	var instances []string
+	if v, ok := c.annotations[annotations.AnnotationFencedInstances]; ok {
+		_ = json.Unmarshal([]byte(v), &instances)
+	}
+	return instances
+}
+
+// SetFencedInstances records the instances the manager fenced
+func (c *clusterAnnotationsWrapper) SetFencedInstances(instances []string) {
+	if instances == nil {
+		instances = []string{}
+	}
+	value, _ := json.Marshal(instances)
+	c.annotations[annotations.AnnotationFencedInstances] = string(value)
+}
+
 func (c *clusterAnnotationsWrapper) CanExpand(cooldownMinutes int32) (bool, string) {
 	if c.IsPaused() {
 		return false, fmt.Sprintf("cluster is paused: %s", c.GetPauseReason())
@@ -1106,8 +2164,8 @@ func (c *clusterAnnotationsWrapper) CanExpand(cooldownMinutes int32) (bool, stri
 	if lastExpansion != nil {
 		cooldown := time.Duration(cooldownMinutes) * time.Minute
 		nextAllowed := lastExpansion.Add(cooldown)
-		if time.Now().Before(nextAllowed) {
-			remaining := time.Until(nextAllowed).Round(time.Second)
+		if now := c.now(); now.Before(nextAllowed) {
+			remaining := nextAllowed.Sub(now).Round(time.Second)
 			return false, fmt.Sprintf("cooldown active, %s remaining", remaining)
 		}
 	}
@@ -1125,8 +2183,8 @@ func (c *clusterAnnotationsWrapper) CanWALCleanup(cooldownMinutes int32) (bool,
 	if lastCleanup != nil {
 		cooldown := time.Duration(cooldownMinutes) * time.Minute
 		nextAllowed := lastCleanup.Add(cooldown)
-		if time.Now().Before(nextAllowed) {
-			remaining := time.Until(nextAllowed).Round(time.Second)
+		if now := c.now(); now.Before(nextAllowed) {
+			remaining := nextAllowed.Sub(now).Round(time.Second)
 			return false, fmt.Sprintf("cooldown active, %s remaining", remaining)
 		}
 	}
@@ -18,17 +18,49 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/annotations"
+	"github.com/supporttools/cnpg-storage-manager/pkg/clock"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/hooks"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/permissions"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+	"github.com/supporttools/cnpg-storage-manager/pkg/sharding"
 )
 
 var _ = Describe("StoragePolicy Controller", func() {
@@ -234,6 +266,57 @@ var _ = Describe("StoragePolicy Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	Context("When the CloudNativePG CRDs are not installed", func() {
+		const resourceName = "cnpg-missing-policy"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			Expect(k8sClient.Create(ctx, &cnpgv1alpha1.StoragePolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &cnpgv1alpha1.StoragePolicy{}
+			if err := k8sClient.Get(ctx, typeNamespacedName, resource); err == nil {
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			}
+		})
+
+		It("should report CNPGNotInstalled and back off without an error", func() {
+			controllerReconciler := &StoragePolicyReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			By("Adding the finalizer")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling without the CNPG Cluster CRD in the test environment")
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(CNPGNotInstalledRequeueInterval))
+
+			updated := &cnpgv1alpha1.StoragePolicy{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, conditionCNPGNotInstalled)).To(BeTrue())
+			ready := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal(conditionCNPGNotInstalled))
+		})
+	})
 })
 
 var _ = Describe("StorageEvent Controller", func() {
@@ -421,3 +504,1221 @@ var _ = Describe("Node Label Annotation Handling", func() {
 		})
 	})
 })
+
+var _ = Describe("StoragePolicy Status Summary", func() {
+	Context("When summarizing managed clusters", func() {
+		It("should report the count and the cluster with the highest usage", func() {
+			status := cnpgv1alpha1.StoragePolicyStatus{
+				ManagedClusters: []cnpgv1alpha1.ManagedCluster{
+					{Name: "pg-a", Namespace: "db", UsagePercent: 42},
+					{Name: "pg-b", Namespace: "db", UsagePercent: 87},
+					{Name: "pg-c", Namespace: "db", Connection: "edge", UsagePercent: 64},
+				},
+			}
+			summarizeManagedClusters(&status)
+			Expect(status.ManagedClusterCount).To(Equal(int32(3)))
+			Expect(status.WorstUsagePercent).To(Equal(int32(87)))
+			Expect(status.WorstCluster).To(Equal("db/pg-b"))
+		})
+
+		It("should clear the summary when no cluster matches", func() {
+			status := cnpgv1alpha1.StoragePolicyStatus{
+				WorstUsagePercent: 90,
+				WorstCluster:      "db/pg-b",
+				Summary:           &cnpgv1alpha1.PolicySummary{Healthy: 1},
+			}
+			summarizeManagedClusters(&status)
+			Expect(status.ManagedClusterCount).To(BeZero())
+			Expect(status.WorstUsagePercent).To(BeZero())
+			Expect(status.WorstCluster).To(BeEmpty())
+			Expect(status.Summary).To(BeNil())
+		})
+
+		It("should count clusters by health state and rank the worst offenders", func() {
+			storageHealthy := func(status metav1.ConditionStatus, reason string) []metav1.Condition {
+				return []metav1.Condition{{
+					Type:   cnpgv1alpha1.ManagedClusterConditionStorageHealthy,
+					Status: status,
+					Reason: reason,
+				}}
+			}
+			lastBackup := metav1.Now()
+			var clusters []cnpgv1alpha1.ManagedCluster
+			for i := range 7 {
+				clusters = append(clusters, cnpgv1alpha1.ManagedCluster{
+					Name:         fmt.Sprintf("pg-%d", i),
+					Namespace:    "db",
+					UsagePercent: int32(10 * i),
+					Conditions:   storageHealthy(metav1.ConditionTrue, "BelowThresholds"),
+					BackupStatus: &cnpgv1alpha1.ClusterBackupStatus{
+						LastBackupTime:     &lastBackup,
+						LastBackupAgeHours: int32(i),
+						BackupHealthStatus: "Healthy",
+					},
+				})
+			}
+			clusters[6].Conditions = storageHealthy(metav1.ConditionFalse, "CriticalThresholdExceeded")
+			clusters[5].Conditions = storageHealthy(metav1.ConditionFalse, "WarningThresholdExceeded")
+			clusters[0].Conditions = storageHealthy(metav1.ConditionUnknown, "Paused")
+			clusters[1].BackupStatus = &cnpgv1alpha1.ClusterBackupStatus{BackupHealthStatus: "NoSuccessfulBackup"}
+
+			status := cnpgv1alpha1.StoragePolicyStatus{ManagedClusters: clusters}
+			summarizeManagedClusters(&status)
+
+			summary := status.Summary
+			Expect(summary).NotTo(BeNil())
+			Expect(summary.Healthy).To(Equal(int32(4)))
+			Expect(summary.Warning).To(Equal(int32(1)))
+			Expect(summary.Critical).To(Equal(int32(1)))
+			Expect(summary.Unknown).To(Equal(int32(1)))
+			Expect(summary.BackupUnhealthy).To(Equal(int32(1)))
+
+			Expect(summary.TopUsage).To(HaveLen(cnpgv1alpha1.PolicySummaryTopN))
+			Expect(summary.TopUsage[0].Name).To(Equal("pg-6"))
+			Expect(summary.TopUsage[4].Name).To(Equal("pg-2"))
+
+			Expect(summary.OldestBackups).To(HaveLen(cnpgv1alpha1.PolicySummaryTopN))
+			Expect(summary.OldestBackups[0].Name).To(Equal("pg-1"))
+			Expect(summary.OldestBackups[0].LastBackupAgeHours).To(BeNil())
+			Expect(summary.OldestBackups[1].Name).To(Equal("pg-6"))
+		})
+	})
+})
+
+var _ = Describe("Alert Summary", func() {
+	storageHealthy := func(status metav1.ConditionStatus, reason string) []metav1.Condition {
+		return []metav1.Condition{{
+			Type:   cnpgv1alpha1.ManagedClusterConditionStorageHealthy,
+			Status: status,
+			Reason: reason,
+		}}
+	}
+
+	It("should list the unhealthy clusters of a policy, fullest first", func() {
+		policyObj := &cnpgv1alpha1.StoragePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: "dba"},
+			Status: cnpgv1alpha1.StoragePolicyStatus{ManagedClusters: []cnpgv1alpha1.ManagedCluster{
+				{
+					Name: "orders", Namespace: "db", UsagePercent: 40, Status: "Healthy",
+					Conditions: storageHealthy(metav1.ConditionTrue, "BelowThresholds"),
+				},
+				{
+					Name: "billing", Namespace: "db", UsagePercent: 82, Status: "Alert-warning",
+					Conditions: storageHealthy(metav1.ConditionFalse, "WarningThresholdExceeded"),
+				},
+				{
+					Name: "users", Namespace: "db", Connection: "edge", UsagePercent: 91, Status: "Expanding",
+					Conditions: storageHealthy(metav1.ConditionFalse, "CriticalThresholdExceeded"),
+				},
+				{
+					Name: "events", Namespace: "db", UsagePercent: 12, Status: "Healthy",
+					Conditions:   storageHealthy(metav1.ConditionTrue, "BelowThresholds"),
+					BackupStatus: &cnpgv1alpha1.ClusterBackupStatus{BackupHealthStatus: "NoSuccessfulBackup"},
+				},
+			}},
+		}
+
+		alert := storagePolicySummary(policyObj, time.Now())
+		Expect(alert).NotTo(BeNil())
+		Expect(alert.Type).To(Equal(alerting.AlertTypeSummary))
+		Expect(alert.ClusterName).To(Equal("production"))
+		Expect(alert.Details).To(HaveKeyWithValue("unhealthy_clusters", "3"))
+		lines := strings.Split(alert.Message, "\n")
+		Expect(lines).To(HaveLen(5))
+		Expect(lines[0]).To(Equal("3 of 4 clusters of StoragePolicy dba/production need attention:"))
+		Expect(strings.Fields(lines[1])).To(Equal([]string{"CLUSTER", "STORAGE", "USAGE", "BACKUP", "STATUS"}))
+		Expect(strings.Fields(lines[2])).To(Equal([]string{"edge:db/users", "Critical", "91%", "-", "Expanding"}))
+		Expect(strings.Fields(lines[3])).To(Equal([]string{"db/billing", "Warning", "82%", "-", "Alert-warning"}))
+		Expect(strings.Fields(lines[4])).To(Equal([]string{"db/events", "OK", "12%", "NoSuccessfulBackup", "Healthy"}))
+	})
+
+	It("should not build a summary while every cluster is healthy", func() {
+		policyObj := &cnpgv1alpha1.StoragePolicy{
+			Status: cnpgv1alpha1.StoragePolicyStatus{ManagedClusters: []cnpgv1alpha1.ManagedCluster{{
+				Name: "orders", Namespace: "db", Conditions: storageHealthy(metav1.ConditionUnknown, "Paused"),
+			}}},
+		}
+		Expect(storagePolicySummary(policyObj, time.Now())).To(BeNil())
+	})
+
+	It("should wait for the next scheduled time after the summary is enabled", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 6, 4, 8, 0, 0, 0, time.UTC))
+		am := alerting.NewAlertManager(nil, nil)
+		am.SetClock(fakeClock)
+		cfg := &cnpgv1alpha1.AlertSummaryConfig{Schedule: "0 9 * * *"}
+		am.SetSummary(cfg)
+
+		var last *metav1.Time
+		built := 0
+		summary := func() *alerting.Alert {
+			built++
+			return nil
+		}
+		sendAlertSummary(ctx, am, cfg, &last, fakeClock.Now(), summary)
+		Expect(last).NotTo(BeNil())
+		Expect(built).To(BeZero())
+
+		fakeClock.SetTime(time.Date(2025, 6, 4, 9, 0, 30, 0, time.UTC))
+		sendAlertSummary(ctx, am, cfg, &last, fakeClock.Now(), summary)
+		sendAlertSummary(ctx, am, cfg, &last, fakeClock.Now(), summary)
+		Expect(built).To(Equal(1))
+		Expect(last.Time).To(Equal(fakeClock.Now()))
+
+		sendAlertSummary(ctx, am, nil, &last, fakeClock.Now(), summary)
+		Expect(last).To(BeNil())
+	})
+})
+
+var _ = Describe("Dry-Run Planned Actions", func() {
+	Context("When planning remediation held back by dry-run", func() {
+		It("should sum the bytes planned expansions would add", func() {
+			target := resource.MustParse("15Gi")
+			actions := []cnpgv1alpha1.PlannedAction{{
+				Type: cnpgv1alpha1.EventTypeExpansion,
+				PVCs: []cnpgv1alpha1.PlannedPVCExpansion{
+					{Name: "pg-1", CurrentSize: resource.MustParse("10Gi"), TargetSize: &target},
+					{Name: "pg-2", CurrentSize: resource.MustParse("10Gi"), TargetSize: &target},
+					{Name: "pg-3", CurrentSize: resource.MustParse("100Gi"), SkipReason: "at max size"},
+				},
+			}}
+			Expect(plannedExpansionBytes(actions)).To(Equal(int64(10 * 1024 * 1024 * 1024)))
+			Expect(plannedExpansionBytes(nil)).To(BeZero())
+		})
+
+		It("should reuse a recent plan from the previous status", func() {
+			cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+			policyObj := &cnpgv1alpha1.StoragePolicy{
+				Status: cnpgv1alpha1.StoragePolicyStatus{
+					ManagedClusters: []cnpgv1alpha1.ManagedCluster{{
+						Name:      "pg",
+						Namespace: "db",
+						PlannedActions: []cnpgv1alpha1.PlannedAction{{
+							Type:       cnpgv1alpha1.EventTypeWALCleanup,
+							PlannedAt:  metav1.Now(),
+							WALCleanup: []cnpgv1alpha1.PlannedWALCleanup{{PodName: "pg-1", Files: 12, Bytes: 201326592}},
+						}},
+					}},
+				},
+			}
+
+			r := &StoragePolicyReconciler{}
+			action := r.planDryRunAction(context.Background(), policyObj, cluster,
+				cnpgv1alpha1.EventTypeWALCleanup, cnpgv1alpha1.ExpansionTarget{}, "emergency threshold breach")
+			Expect(action.Reason).To(Equal("emergency threshold breach"))
+			Expect(action.WALCleanup).To(HaveLen(1))
+			Expect(action.WALCleanup[0].Files).To(Equal(int32(12)))
+			Expect(previousPlannedAction(policyObj, cluster, cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.ExpansionTarget{})).To(BeNil())
+		})
+	})
+})
+
+var _ = Describe("Hibernated Clusters", func() {
+	Context("When a matched cluster is hibernated", func() {
+		It("should report it as hibernated and keep its history", func() {
+			cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db", Hibernated: true}
+			history := &cnpgv1alpha1.ExpansionHistory{TotalExpansions: 2}
+			policyObj := &cnpgv1alpha1.StoragePolicy{
+				Status: cnpgv1alpha1.StoragePolicyStatus{
+					ManagedClusters: []cnpgv1alpha1.ManagedCluster{{
+						Name:             "pg",
+						Namespace:        "db",
+						Status:           "Healthy",
+						ExpansionHistory: history,
+					}},
+				},
+			}
+
+			mc := hibernatedCluster(policyObj, cluster, "")
+			Expect(mc.Status).To(Equal(statusHibernated))
+			Expect(mc.ExpansionHistory).To(Equal(history))
+			condition := meta.FindStatusCondition(mc.Conditions, cnpgv1alpha1.ManagedClusterConditionStorageHealthy)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal(policy.ReasonHibernated))
+		})
+	})
+})
+
+var _ = Describe("Instance Fencing", func() {
+	ctx := context.Background()
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+	policyObj := &cnpgv1alpha1.StoragePolicy{
+		Spec: cnpgv1alpha1.StoragePolicySpec{Fencing: cnpgv1alpha1.FencingConfig{Enabled: true}},
+	}
+
+	usage := func(percents map[string]int64) *metrics.ClusterMetrics {
+		clusterMetrics := &metrics.ClusterMetrics{}
+		for pod, percent := range percents {
+			clusterMetrics.PVCMetrics = append(clusterMetrics.PVCMetrics,
+				metrics.PVCMetrics{PVCName: pod, PodName: pod, UsedBytes: percent, CapacityBytes: 100})
+		}
+		return clusterMetrics
+	}
+
+	It("should fence a full instance and unfence it once it has space", func() {
+		r := &StoragePolicyReconciler{}
+		ca := &clusterAnnotationsWrapper{annotations: map[string]string{}}
+
+		fenced := r.manageFencing(ctx, policyObj, cluster, usage(map[string]int64{"pg-1": 100, "pg-2": 50}), ca)
+		Expect(fenced).To(Equal([]string{"pg-1"}))
+		Expect(ca.annotations[cnpg.AnnotationFencedInstances]).To(Equal(`["pg-1"]`))
+		Expect(ca.GetFencedInstances()).To(Equal([]string{"pg-1"}))
+
+		fenced = r.manageFencing(ctx, policyObj, cluster, usage(map[string]int64{"pg-1": 60, "pg-2": 50}), ca)
+		Expect(fenced).To(BeEmpty())
+		Expect(ca.annotations[cnpg.AnnotationFencedInstances]).To(Equal(`[]`))
+		Expect(ca.GetFencedInstances()).To(BeEmpty())
+	})
+
+	It("should not unfence instances fenced by someone else", func() {
+		r := &StoragePolicyReconciler{}
+		ca := &clusterAnnotationsWrapper{annotations: map[string]string{
+			cnpg.AnnotationFencedInstances: `["pg-1"]`,
+		}}
+
+		fenced := r.manageFencing(ctx, policyObj, cluster, usage(map[string]int64{"pg-1": 10}), ca)
+		Expect(fenced).To(Equal([]string{"pg-1"}))
+		Expect(ca.annotations[cnpg.AnnotationFencedInstances]).To(Equal(`["pg-1"]`))
+	})
+
+	It("should only log the fencing in dry-run mode", func() {
+		r := &StoragePolicyReconciler{GlobalDryRun: true}
+		ca := &clusterAnnotationsWrapper{annotations: map[string]string{}}
+
+		fenced := r.manageFencing(ctx, policyObj, cluster, usage(map[string]int64{"pg-1": 100}), ca)
+		Expect(fenced).To(BeEmpty())
+		Expect(ca.annotations).NotTo(HaveKey(cnpg.AnnotationFencedInstances))
+	})
+})
+
+var _ = Describe("Read-Only Mode", func() {
+	It("should dry-run every remediation and run no commands in pods", func() {
+		r := &StoragePolicyReconciler{ReadOnly: true, CommandRunner: &runner.ExecRunner{}}
+		Expect(r.globalDryRun()).To(BeTrue())
+		Expect(r.isDryRun(&cnpgv1alpha1.StoragePolicy{}, cnpgv1alpha1.EventTypeExpansion)).To(BeTrue())
+		Expect(r.commandRunner()).To(BeNil())
+
+		r.initComponents()
+		Expect(r.walCleanupEngine).To(BeNil())
+	})
+
+	It("should not plan WAL cleanups", func() {
+		r := &StoragePolicyReconciler{ReadOnly: true}
+		action := &cnpgv1alpha1.PlannedAction{}
+		r.planWALCleanup(ctx, &cnpgv1alpha1.StoragePolicy{}, cnpg.ClusterInfo{Name: "pg", Namespace: "db"}, action)
+		Expect(action.Error).To(ContainSubstring("read-only mode"))
+	})
+})
+
+var _ = Describe("Permission Checks", func() {
+	policyObj := func() *cnpgv1alpha1.StoragePolicy {
+		return &cnpgv1alpha1.StoragePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "storage", Namespace: "db"},
+			Spec: cnpgv1alpha1.StoragePolicySpec{
+				Expansion:  cnpgv1alpha1.ExpansionConfig{Enabled: true},
+				WALCleanup: cnpgv1alpha1.WALCleanupConfig{Enabled: true},
+			},
+		}
+	}
+	// denying denies the pods/exec reviews, allowing everything else
+	denying := func() *permissions.Checker {
+		return permissions.NewChecker(fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+				ssar := obj.(*authorizationv1.SelfSubjectAccessReview)
+				ssar.Status.Allowed = ssar.Spec.ResourceAttributes.Subresource != "exec"
+				return nil
+			},
+		}).Build())
+	}
+
+	It("should require the permissions of the enabled features", func() {
+		r := &StoragePolicyReconciler{}
+		required := make([]string, 0)
+		for _, p := range r.requiredPermissions(policyObj()) {
+			required = append(required, p.String())
+		}
+		Expect(required).To(ContainElements("get nodes/proxy", "patch clusters.postgresql.cnpg.io",
+			"patch persistentvolumeclaims", "create pods/exec"))
+
+		r.RunnerMode = runner.ModeJob
+		required = required[:0]
+		for _, p := range r.requiredPermissions(policyObj()) {
+			required = append(required, p.String())
+		}
+		Expect(required).To(ContainElements("create jobs.batch", "get pods/log"))
+		Expect(required).NotTo(ContainElement("create pods/exec"))
+	})
+
+	It("should require no write permissions in dry-run and read-only mode", func() {
+		for _, r := range []*StoragePolicyReconciler{{GlobalDryRun: true}, {ReadOnly: true}} {
+			for _, p := range r.requiredPermissions(policyObj()) {
+				Expect(p.Feature).NotTo(BeElementOf(featureExpansion, featureWALCleanup))
+			}
+		}
+	})
+
+	It("should report missing permissions and dry-run the features needing them", func() {
+		r := &StoragePolicyReconciler{Permissions: denying()}
+		p := policyObj()
+		r.checkPermissions(ctx, p, []cnpg.ClusterInfo{{Name: "pg", Namespace: "db"}})
+
+		condition := meta.FindStatusCondition(p.Status.Conditions, cnpgv1alpha1.StoragePolicyConditionMissingPermission)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("create pods/exec in db (wal-cleanup)"))
+		Expect(r.isDryRun(p, cnpgv1alpha1.EventTypeWALCleanup)).To(BeTrue())
+		Expect(r.isDryRun(p, cnpgv1alpha1.EventTypeExpansion)).To(BeFalse())
+
+		// A policy without clusters needs no permissions
+		r.checkPermissions(ctx, p, nil)
+		Expect(meta.FindStatusCondition(p.Status.Conditions,
+			cnpgv1alpha1.StoragePolicyConditionMissingPermission)).To(BeNil())
+		Expect(r.isDryRun(p, cnpgv1alpha1.EventTypeWALCleanup)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Alert Managers", func() {
+	It("should create the alert managers of concurrently reconciled policies once", func() {
+		r := &StoragePolicyReconciler{}
+		var wg sync.WaitGroup
+		for i := range 16 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.getAlertManager(&cnpgv1alpha1.StoragePolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("storage-%d", i%4), Namespace: "db"},
+				})
+			}()
+		}
+		wg.Wait()
+		Expect(r.alertManagers.len()).To(Equal(4))
+
+		policyObj := &cnpgv1alpha1.StoragePolicy{ObjectMeta: metav1.ObjectMeta{Name: "storage-0", Namespace: "db"}}
+		Expect(r.getAlertManager(policyObj)).To(BeIdenticalTo(r.getAlertManager(policyObj)))
+	})
+
+	It("should evict the alert manager of a deleted policy", func() {
+		r := &StoragePolicyReconciler{}
+		policyObj := &cnpgv1alpha1.StoragePolicy{ObjectMeta: metav1.ObjectMeta{Name: "storage", Namespace: "db"}}
+		first := r.getAlertManager(policyObj)
+
+		r.alertManagers.evict(types.NamespacedName{Namespace: "db", Name: "storage"})
+		Expect(r.alertManagers.len()).To(Equal(0))
+		Expect(r.getAlertManager(policyObj)).NotTo(BeIdenticalTo(first))
+	})
+})
+
+var _ = Describe("Write Probe", func() {
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+
+	It("should alert only when a write failure starts", func() {
+		policyObj := &cnpgv1alpha1.StoragePolicy{}
+		Expect(writeFailureStarted(policyObj, cluster, metrics.WriteProbeOutOfSpace)).To(BeTrue())
+
+		policyObj.Status.ManagedClusters = []cnpgv1alpha1.ManagedCluster{{
+			Name:      "pg",
+			Namespace: "db",
+			Conditions: []metav1.Condition{{
+				Type:   cnpgv1alpha1.ManagedClusterConditionWritable,
+				Status: metav1.ConditionFalse,
+				Reason: string(metrics.WriteProbeOutOfSpace),
+			}},
+		}}
+		Expect(writeFailureStarted(policyObj, cluster, metrics.WriteProbeOutOfSpace)).To(BeFalse())
+		Expect(writeFailureStarted(policyObj, cluster, metrics.WriteProbeReadOnly)).To(BeTrue())
+	})
+
+	It("should not probe when the policy does not enable it", func() {
+		r := &StoragePolicyReconciler{WriteProbe: metrics.NewWriteProbe(nil)}
+		result, fenced := r.probeWrites(context.Background(), &cnpgv1alpha1.StoragePolicy{}, cluster, 50)
+		Expect(result).To(BeNil())
+		Expect(fenced).To(BeFalse())
+	})
+})
+
+var _ = Describe("Max Size Escalation", func() {
+	ctx := context.Background()
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+	result := policy.ThresholdResult{Level: policy.ThresholdLevelExpansion, CurrentUsagePercent: 88}
+
+	It("should report the PVCs skipped at the maximum size", func() {
+		r := &StoragePolicyReconciler{}
+		ca := &clusterAnnotationsWrapper{annotations: map[string]string{}}
+		pvcs := r.escalateMaxSize(ctx, &cnpgv1alpha1.StoragePolicy{}, cluster, ca, result,
+			[]cnpgv1alpha1.ExpansionSkipStatus{
+				{PVC: "pg-1", Reason: string(remediation.SkipCodeMaxSize)},
+				{PVC: "pg-2", Reason: string(remediation.SkipCodeUnbound)},
+				{PVC: "pg-3", Reason: string(remediation.SkipCodeMaxSize)},
+			})
+		Expect(pvcs).To(Equal([]string{"pg-1", "pg-3"}))
+		Expect(r.escalateMaxSize(ctx, &cnpgv1alpha1.StoragePolicy{}, cluster, ca, result, nil)).To(BeEmpty())
+	})
+
+	It("should alert only when the maximum size is first reached", func() {
+		policyObj := &cnpgv1alpha1.StoragePolicy{}
+		Expect(maxSizeReached(policyObj, cluster)).To(BeFalse())
+
+		policyObj.Status.ManagedClusters = []cnpgv1alpha1.ManagedCluster{{
+			Name:      "pg",
+			Namespace: "db",
+			Conditions: []metav1.Condition{{
+				Type:   cnpgv1alpha1.ManagedClusterConditionMaxSizeReached,
+				Status: metav1.ConditionTrue,
+				Reason: policy.ReasonMaxSizeReached,
+			}},
+		}}
+		Expect(maxSizeReached(policyObj, cluster)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Policy Preview", func() {
+	ctx := context.Background()
+
+	post := func(r *StoragePolicyReconciler, method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.PreviewHandler().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	It("should only accept posted StoragePolicies with a namespace", func() {
+		r := &StoragePolicyReconciler{Client: k8sClient}
+		Expect(post(r, http.MethodGet, PreviewPath, "").Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(post(r, http.MethodPost, PreviewPath, "spec: [").Code).To(Equal(http.StatusBadRequest))
+		Expect(post(r, http.MethodPost, PreviewPath, "kind: BackupPolicy").Code).To(Equal(http.StatusBadRequest))
+		Expect(post(r, http.MethodPost, PreviewPath, "spec:\n  unknownField: true").Code).
+			To(Equal(http.StatusBadRequest))
+		Expect(post(r, http.MethodPost, PreviewPath, "kind: StoragePolicy\nmetadata:\n  name: p").Code).
+			To(Equal(http.StatusBadRequest))
+	})
+
+	It("should reject policies the API server rejects", func() {
+		r := &StoragePolicyReconciler{Client: k8sClient}
+		rec := post(r, http.MethodPost, PreviewPath+"?namespace=default",
+			"kind: StoragePolicy\nmetadata:\n  name: p\nspec:\n  thresholds:\n    warning: 150")
+		Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+	})
+
+	It("should evaluate a policy without creating it", func() {
+		r := &StoragePolicyReconciler{Client: k8sClient}
+		r.initComponents()
+		policyObj := &cnpgv1alpha1.StoragePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "previewed", Namespace: "default"},
+			Spec: cnpgv1alpha1.StoragePolicySpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"preview": "none"}},
+			},
+		}
+		preview, err := r.Preview(ctx, policyObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(preview.Policy).To(Equal("default/previewed"))
+		Expect(preview.Clusters).To(BeEmpty())
+
+		var policies cnpgv1alpha1.StoragePolicyList
+		Expect(k8sClient.List(ctx, &policies)).To(Succeed())
+		for _, p := range policies.Items {
+			Expect(p.Name).NotTo(HavePrefix("preview-"))
+		}
+	})
+
+	It("should not collect metrics of hibernated clusters", func() {
+		r := &StoragePolicyReconciler{}
+		preview := r.previewCluster(ctx, &cnpgv1alpha1.StoragePolicy{},
+			cnpg.ClusterInfo{Name: "pg", Namespace: "db", Hibernated: true})
+		Expect(preview.Status).To(Equal(previewStatusHibernated))
+		Expect(preview.Decision).To(BeNil())
+	})
+})
+
+var _ = Describe("Storage Class Migration", func() {
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+
+	It("should not request a migration when the policy does not configure one", func() {
+		r := &StoragePolicyReconciler{}
+		ca := &clusterAnnotationsWrapper{annotations: map[string]string{}}
+		Expect(r.requestStorageClassMigration(context.Background(), &cnpgv1alpha1.StoragePolicy{}, cluster, ca)).To(BeNil())
+	})
+
+	It("should abort events without migration details", func() {
+		r := &StorageEventReconciler{}
+		event := &cnpgv1alpha1.StorageEvent{Spec: cnpgv1alpha1.StorageEventSpec{
+			EventType: cnpgv1alpha1.EventTypeStorageClassMigration,
+		}}
+		outcome, err := r.updateStorageClass(context.Background(), event)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outcome.abort).NotTo(BeEmpty())
+	})
+
+	It("should abort when replication lag cannot be checked", func() {
+		r := &StorageEventReconciler{}
+		event := &cnpgv1alpha1.StorageEvent{Spec: cnpgv1alpha1.StorageEventSpec{
+			EventType:             cnpgv1alpha1.EventTypeStorageClassMigration,
+			StorageClassMigration: &cnpgv1alpha1.StorageClassMigrationDetails{StorageClass: "gp3"},
+		}}
+		outcome, err := r.migrateInstances(context.Background(), event)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outcome.abort).To(ContainSubstring("replication lag"))
+	})
+})
+
+var _ = Describe("Performance Tuning", func() {
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+
+	It("should leave clusters at the expansion threshold to expansion", func() {
+		// The reconciler has no discovery, so reaching the PVC listing would panic
+		r := &StoragePolicyReconciler{}
+		policyObj := &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{
+			PerformanceTuning: &cnpgv1alpha1.PerformanceTuningConfig{
+				PrometheusURL:         "http://prometheus:9090",
+				LatencyQuery:          "pvc_latency_ms",
+				LatencyThresholdMs:    20,
+				VolumeAttributesClass: "gp3-fast",
+			},
+		}}
+		Expect(func() {
+			r.tuneVolumePerformance(context.Background(), policyObj, cluster, policy.ThresholdLevelExpansion)
+			r.tuneVolumePerformance(context.Background(), policyObj, cluster, policy.ThresholdLevelEmergency)
+		}).NotTo(Panic())
+	})
+})
+
+var _ = Describe("VolumeAttributesClass Changes", func() {
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+
+	It("should not request a change when no class applies to the threshold level", func() {
+		r := &StoragePolicyReconciler{}
+		ca := &clusterAnnotationsWrapper{annotations: map[string]string{}}
+		policyObj := &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{
+			VolumeAttributes: &cnpgv1alpha1.VolumeAttributesConfig{
+				Classes: []cnpgv1alpha1.VolumeAttributesClassThreshold{
+					{Threshold: "critical", VolumeAttributesClass: "fast"},
+				},
+			},
+		}}
+		Expect(r.requestVolumeAttributesChange(context.Background(), &cnpgv1alpha1.StoragePolicy{}, cluster,
+			policy.ThresholdLevelCritical, ca)).To(BeNil())
+		Expect(r.requestVolumeAttributesChange(context.Background(), policyObj, cluster,
+			policy.ThresholdLevelWarning, ca)).To(BeNil())
+	})
+
+	It("should abort events without change details", func() {
+		r := &StorageEventReconciler{}
+		event := &cnpgv1alpha1.StorageEvent{Spec: cnpgv1alpha1.StorageEventSpec{
+			EventType: cnpgv1alpha1.EventTypeVolumeAttributesChange,
+		}}
+		outcome, err := r.modifyVolumes(context.Background(), event)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outcome.abort).NotTo(BeEmpty())
+		outcome, err = r.verifyModification(context.Background(), event)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outcome.abort).NotTo(BeEmpty())
+	})
+})
+
+var _ = Describe("Sharding", func() {
+	It("should skip policies owned by another shard", func() {
+		// The reconcilers have no client, so fetching the policy would panic
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "policy", Namespace: "db"}}
+		shard := sharding.Shard{Index: 1 - sharding.For("db", "policy", 2), Count: 2}
+		Expect(func() {
+			_, err := (&StoragePolicyReconciler{Shard: shard}).Reconcile(context.Background(), req)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = (&BackupPolicyReconciler{Shard: shard}).Reconcile(context.Background(), req)
+			Expect(err).NotTo(HaveOccurred())
+		}).NotTo(Panic())
+	})
+})
+
+var _ = Describe("Clock", func() {
+	It("should check cooldowns against the injected clock", func() {
+		lastExpansion := time.Now().Add(-10 * time.Minute).Format(time.RFC3339)
+		ca := &clusterAnnotationsWrapper{annotations: map[string]string{
+			annotations.AnnotationLastExpansion: lastExpansion,
+		}}
+		allowed, _ := ca.CanExpand(30)
+		Expect(allowed).To(BeFalse())
+
+		ca.clock = clock.WithOffset(time.Hour)
+		allowed, _ = ca.CanExpand(30)
+		Expect(allowed).To(BeTrue())
+	})
+})
+
+var _ = Describe("ObjectStore Watch", func() {
+	It("should emit the inventory cluster whose recovery window changed", func() {
+		inv := cnpg.NewInventory()
+		cluster := &unstructured.Unstructured{}
+		cluster.SetGroupVersionKind(cnpg.CNPGClusterGVK)
+		cluster.SetName("pg-a")
+		cluster.SetNamespace("db")
+		cluster.SetLabels(map[string]string{"env": "prod"})
+		inv.Upsert(cluster)
+
+		objectStores := cnpg.NewObjectStoreCache()
+		ch := watchObjectStores(objectStores, inv)
+
+		store := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"serverRecoveryWindow": map[string]interface{}{
+				"pg-a": map[string]interface{}{"lastSuccessfulBackupTime": "2025-01-02T00:00:00Z"},
+			}},
+		}}
+		store.SetGroupVersionKind(cnpg.ObjectStoreGVK)
+		store.SetName("store")
+		store.SetNamespace("db")
+		objectStores.Upsert(store)
+
+		var e event.GenericEvent
+		Eventually(ch).Should(Receive(&e))
+		Expect(e.Object.GetName()).To(Equal("pg-a"))
+		Expect(e.Object.GetNamespace()).To(Equal("db"))
+		Expect(e.Object.GetLabels()).To(HaveKeyWithValue("env", "prod"))
+
+		// A resync with the same recovery window does not re-trigger reconciles
+		objectStores.Upsert(store)
+		Consistently(ch).ShouldNot(Receive())
+	})
+})
+
+var _ = Describe("Storage Forecast", func() {
+	It("should forecast the fullest data volume", func() {
+		used, capacity, instances := fullestDataVolume(&metrics.ClusterMetrics{PVCMetrics: []metrics.PVCMetrics{
+			{PVCName: "pg-1", UsedBytes: 40 << 30, CapacityBytes: 100 << 30},
+			{PVCName: "pg-1-wal", UsedBytes: 90 << 30, CapacityBytes: 100 << 30, WALVolume: true},
+			{PVCName: "pg-2", UsedBytes: 45 << 30, CapacityBytes: 100 << 30},
+			{PVCName: "pg-2-tbs-idx", UsedBytes: 95 << 30, CapacityBytes: 100 << 30, Tablespace: "idx"},
+		}})
+		Expect(used).To(Equal(int64(45 << 30)))
+		Expect(capacity).To(Equal(int64(100 << 30)))
+		Expect(instances).To(Equal(int32(2)))
+	})
+
+	It("should fall back to the anomaly detection baseline until the samples span a day", func() {
+		now := time.Now()
+		samples := []cnpgv1alpha1.UsageSample{{Time: metav1.NewTime(now), UsedBytes: 1 << 30}}
+		growth := &cnpgv1alpha1.GrowthStatus{BaselineBytesPerHour: 1 << 20}
+
+		perDay, basis := forecastGrowth(samples, nil, growth)
+		Expect(basis).To(Equal(cnpgv1alpha1.ForecastBasisGrowthRate))
+		Expect(perDay).To(Equal(int64(24 << 20)))
+
+		_, basis = forecastGrowth(samples, nil, nil)
+		Expect(basis).To(Equal(cnpgv1alpha1.ForecastBasisNone))
+
+		samples = append([]cnpgv1alpha1.UsageSample{{Time: metav1.NewTime(now.Add(-48 * time.Hour))}}, samples...)
+		perDay, basis = forecastGrowth(samples, nil, growth)
+		Expect(basis).To(Equal(cnpgv1alpha1.ForecastBasisHistory))
+		Expect(perDay).To(Equal(int64(512 << 20)))
+	})
+
+	It("should use the hourly usage history until the forecast samples span a day", func() {
+		now := time.Now().Truncate(time.Hour)
+		samples := []cnpgv1alpha1.UsageSample{{Time: metav1.NewTime(now), UsedBytes: 3 << 30}}
+		history := &cnpgv1alpha1.StorageUsageHistory{Status: cnpgv1alpha1.StorageUsageHistoryStatus{
+			Newest:        &metav1.Time{Time: now},
+			DataUsedBytes: append(append([]int64{1 << 30}, make([]int64, 47)...), 3<<30),
+		}}
+		history.Status.DataUsedBytes[1] = -1
+
+		perDay, basis := forecastGrowth(samples, historyForecastSamples(history), nil)
+		Expect(basis).To(Equal(cnpgv1alpha1.ForecastBasisHistory))
+		Expect(perDay).To(Equal(int64(1 << 30)))
+		Expect(historyForecastSamples(nil)).To(BeEmpty())
+	})
+
+	It("should round quantities up to whole units", func() {
+		Expect(roundedQuantity((10<<30)+1, gibibyte).String()).To(Equal("11Gi"))
+		Expect(roundedQuantity(1536<<20, mebibyte).String()).To(Equal("1536Mi"))
+	})
+})
+
+var _ = Describe("Remediation Hooks", func() {
+	newEvent := func(eventType cnpgv1alpha1.EventType) *cnpgv1alpha1.StorageEvent {
+		return &cnpgv1alpha1.StorageEvent{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg-event", Namespace: "db"},
+			Spec: cnpgv1alpha1.StorageEventSpec{
+				ClusterRef: cnpgv1alpha1.ClusterReference{Name: "pg", Namespace: "db"},
+				EventType:  eventType,
+			},
+		}
+	}
+	policyWithHook := func(url string, failurePolicy cnpgv1alpha1.HookFailurePolicy) *cnpgv1alpha1.StoragePolicy {
+		return &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{Hooks: cnpgv1alpha1.HooksConfig{
+			PreAction: &cnpgv1alpha1.WebhookHook{URL: url, TimeoutSeconds: 1, FailurePolicy: failurePolicy},
+		}}}
+	}
+
+	It("should record that the preAction hook allowed the event", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		r := &StorageEventReconciler{Hooks: hooks.NewCallerFromReader(nil)}
+		event := newEvent(cnpgv1alpha1.EventTypeExpansion)
+		vetoed, err := r.runPreActionHook(context.Background(), event, policyWithHook(server.URL, ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vetoed).To(BeFalse())
+		Expect(meta.IsStatusConditionTrue(event.Status.Conditions,
+			cnpgv1alpha1.StorageEventConditionPreActionHook)).To(BeTrue())
+	})
+
+	It("should fail the attempt when the preAction hook is unreachable", func() {
+		r := &StorageEventReconciler{Hooks: hooks.NewCallerFromReader(nil)}
+		policyObj := policyWithHook("http://127.0.0.1:1", cnpgv1alpha1.HookFailurePolicyFail)
+		vetoed, err := r.runPreActionHook(context.Background(), newEvent(cnpgv1alpha1.EventTypeWALCleanup), policyObj)
+		Expect(err).To(HaveOccurred())
+		Expect(vetoed).To(BeFalse())
+
+		policyObj.Spec.Hooks.PreAction.FailurePolicy = cnpgv1alpha1.HookFailurePolicyIgnore
+		vetoed, err = r.runPreActionHook(context.Background(), newEvent(cnpgv1alpha1.EventTypeWALCleanup), policyObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vetoed).To(BeFalse())
+	})
+
+	It("should only call hooks around expansions and WAL cleanups", func() {
+		r := &StorageEventReconciler{Hooks: hooks.NewCallerFromReader(nil)}
+		policyObj := policyWithHook("http://127.0.0.1:1", cnpgv1alpha1.HookFailurePolicyFail)
+		vetoed, err := r.runPreActionHook(context.Background(), newEvent(cnpgv1alpha1.EventTypeRestoreTest), policyObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vetoed).To(BeFalse())
+	})
+})
+
+var _ = Describe("Pooler Volumes", func() {
+	It("should keep the fullest reading of a PVC shared by several pooler pods", func() {
+		volumes := fullestPoolerVolumes([]metrics.PVCMetrics{
+			{PVCName: "logs", PodName: "pooler-1", UsedBytes: 20, CapacityBytes: 100},
+			{PVCName: "scratch-pooler-1", PodName: "pooler-1", UsedBytes: 5, CapacityBytes: 100},
+			{PVCName: "logs", PodName: "pooler-2", UsedBytes: 30, CapacityBytes: 100},
+		})
+		Expect(volumes).To(HaveLen(2))
+		Expect(volumes[0].PVCName).To(Equal("logs"))
+		Expect(volumes[0].PodName).To(Equal("pooler-2"))
+		Expect(volumes[1].PVCName).To(Equal("scratch-pooler-1"))
+	})
+
+	It("should keep the storage alert open while a pooler volume is above a threshold", func() {
+		healthy := []cnpgv1alpha1.PoolerVolumeStatus{{Pooler: "pooler-rw", PVC: "logs", Status: "Healthy"}}
+		full := []cnpgv1alpha1.PoolerVolumeStatus{{Pooler: "pooler-rw", PVC: "logs", Status: "Alert-critical"}}
+		Expect(storageCleared(policy.ThresholdLevelNormal, nil, nil, healthy)).To(BeTrue())
+		Expect(storageCleared(policy.ThresholdLevelNormal, nil, nil, full)).To(BeFalse())
+	})
+
+	It("should not monitor pooler volumes unless enabled", func() {
+		r := &StoragePolicyReconciler{}
+		policyObj := &cnpgv1alpha1.StoragePolicy{}
+		Expect(r.evaluatePoolerVolumes(context.Background(), policyObj, cnpg.ClusterInfo{Name: "pg"})).To(BeNil())
+	})
+})
+
+var _ = Describe("Quota Management", func() {
+	It("should not check quotas unless enabled", func() {
+		r := &StoragePolicyReconciler{}
+		policyObj := &cnpgv1alpha1.StoragePolicy{}
+		Expect(r.manageQuota(context.Background(), policyObj, cnpg.ClusterInfo{Name: "pg"})).To(BeEmpty())
+	})
+
+	It("should raise a ResourceQuota for the next expansion up to maxHard", func() {
+		ctx := context.Background()
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "storage-quota", Namespace: "default"},
+			Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+				corev1.ResourceRequestsStorage: resource.MustParse("100Gi"),
+			}},
+		}
+		Expect(k8sClient.Create(ctx, quota)).To(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, quota)).To(Succeed()) }()
+
+		maxHard := resource.MustParse("150Gi")
+		policyObj := &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{
+			Quota: cnpgv1alpha1.QuotaManagementConfig{Enabled: true, AutoRaise: true, MaxHard: &maxHard},
+		}}
+		exceeded := &remediation.QuotaExceeded{
+			Kind:          remediation.KindResourceQuota,
+			Name:          quota.Name,
+			Namespace:     quota.Namespace,
+			Resource:      corev1.ResourceRequestsStorage,
+			UsedBytes:     95 << 30,
+			RequiredBytes: 10 << 30,
+			LimitBytes:    100 << 30,
+		}
+		r := &StoragePolicyReconciler{Client: k8sClient}
+		cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "default"}
+		Expect(r.raiseQuota(ctx, policyObj, cluster, exceeded)).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: quota.Name, Namespace: quota.Namespace}, quota)).To(Succeed())
+		hard := quota.Spec.Hard[corev1.ResourceRequestsStorage]
+		Expect(hard.String()).To(Equal("105Gi"))
+
+		exceeded.RequiredBytes = 100 << 30
+		Expect(r.raiseQuota(ctx, policyObj, cluster, exceeded)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Sustained Breach Window", func() {
+	It("should restart the window when the breach clears between reconciles", func() {
+		ctx := context.Background()
+		cluster := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "postgresql.cnpg.io/v1",
+			"kind":       "Cluster",
+			"metadata":   map[string]interface{}{"name": "pg", "namespace": "db"},
+		}}
+		discovery := cnpg.NewDiscovery(fake.NewClientBuilder().WithObjects(cluster).Build())
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 6, 4, 8, 0, 0, 0, time.UTC))
+		evaluator := policy.NewEvaluator()
+		evaluator.Clock = fakeClock
+
+		// reconcile writes the breach state the way reconcileCluster does and
+		// returns when the persisted breach started
+		reconcile := func(breached bool) *time.Time {
+			read, err := discovery.GetClusterAnnotations(ctx, "pg", "db")
+			Expect(err).NotTo(HaveOccurred())
+			ca := &clusterAnnotationsWrapper{annotations: maps.Clone(read), clock: fakeClock}
+			if ca.annotations == nil {
+				ca.annotations = make(map[string]string)
+			}
+			ca.SetExpansionBreached(breached)
+			Expect(discovery.UpdateClusterAnnotations(ctx, "pg", "db", read, ca.GetAnnotations())).To(Succeed())
+
+			persisted, err := discovery.GetClusterAnnotations(ctx, "pg", "db")
+			Expect(err).NotTo(HaveOccurred())
+			return (&clusterAnnotationsWrapper{annotations: persisted}).GetExpansionBreachedSince()
+		}
+
+		Expect(reconcile(true)).NotTo(BeNil())
+
+		fakeClock.Step(10 * time.Minute)
+		Expect(reconcile(false)).To(BeNil())
+
+		fakeClock.Step(30 * time.Minute)
+		since := reconcile(true)
+		Expect(since).NotTo(BeNil())
+		Expect(since.Equal(fakeClock.Now())).To(BeTrue())
+
+		sustained, remaining := evaluator.CheckSustained(since, 20)
+		Expect(sustained).To(BeFalse())
+		Expect(remaining).To(BeNumerically(">", 0))
+	})
+})
+
+var _ = Describe("Expansion Preflight Failures", func() {
+	It("should skip PVCs that failed preflight without failing the event", func() {
+		r := &StorageEventReconciler{expansionEngine: remediation.NewExpansionEngine(fake.NewClientBuilder().Build())}
+		event := &cnpgv1alpha1.StorageEvent{
+			Spec: cnpgv1alpha1.StorageEventSpec{
+				ClusterRef: cnpgv1alpha1.ClusterReference{Name: "pg", Namespace: "db"},
+			},
+			Status: cnpgv1alpha1.StorageEventStatus{
+				PVCStatuses: []cnpgv1alpha1.PVCStatus{{
+					Name:  "pg-1",
+					Phase: cnpgv1alpha1.PVCPhaseSkipped,
+					Error: "preflight validation error: storage class not found",
+				}},
+			},
+		}
+
+		outcome, err := r.resizePVCs(context.Background(), event, &cnpgv1alpha1.StoragePolicy{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outcome.message).To(ContainSubstring("1 PVCs skipped"))
+		Expect(event.Status.PVCStatuses[0].Phase).To(Equal(cnpgv1alpha1.PVCPhaseSkipped))
+	})
+})
+
+var _ = Describe("Instance Groups", func() {
+	pvc := func(name, size string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+				},
+			},
+		}
+	}
+	status := func(name, instance, size string) cnpgv1alpha1.PVCStatus {
+		newSize := resource.MustParse(size)
+		return cnpgv1alpha1.PVCStatus{
+			Name: name, Instance: instance, Phase: cnpgv1alpha1.PVCPhasePending, NewSize: &newSize,
+		}
+	}
+
+	It("should hold back the rest of an instance when one of its PVCs fails to resize", func() {
+		c := fake.NewClientBuilder().
+			WithObjects(pvc("pg-1", "10Gi"), pvc("pg-1-wal", "2Gi"), pvc("pg-2", "10Gi"), pvc("pg-2-wal", "2Gi")).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, cl client.WithWatch, obj client.Object, patch client.Patch,
+					opts ...client.PatchOption) error {
+					if obj.GetName() == "pg-2" {
+						return fmt.Errorf("exceeded quota")
+					}
+					return cl.Patch(ctx, obj, patch, opts...)
+				},
+			}).Build()
+		r := &StorageEventReconciler{expansionEngine: remediation.NewExpansionEngine(c)}
+		event := &cnpgv1alpha1.StorageEvent{
+			Spec: cnpgv1alpha1.StorageEventSpec{
+				ClusterRef: cnpgv1alpha1.ClusterReference{Name: "pg", Namespace: "db"},
+			},
+			Status: cnpgv1alpha1.StorageEventStatus{
+				PVCStatuses: []cnpgv1alpha1.PVCStatus{
+					status("pg-1", "pg-1", "15Gi"),
+					status("pg-2", "pg-2", "15Gi"),
+					status("pg-1-wal", "pg-1", "3Gi"),
+					status("pg-2-wal", "pg-2", "3Gi"),
+				},
+			},
+		}
+
+		_, err := r.resizePVCs(context.Background(), event, &cnpgv1alpha1.StoragePolicy{})
+		Expect(err).To(MatchError(ContainSubstring("instance pg-2")))
+		statuses := event.Status.PVCStatuses
+		Expect(statuses[0].Phase).To(Equal(cnpgv1alpha1.PVCPhaseInProgress))
+		Expect(statuses[2].Phase).To(Equal(cnpgv1alpha1.PVCPhaseInProgress))
+		Expect(statuses[1].Phase).To(Equal(cnpgv1alpha1.PVCPhaseFailed))
+		Expect(statuses[3].Phase).To(Equal(cnpgv1alpha1.PVCPhasePending))
+		Expect(statuses[3].Error).To(ContainSubstring("held back until PVC pg-2"))
+
+		var wal corev1.PersistentVolumeClaim
+		Expect(c.Get(context.Background(), client.ObjectKey{Name: "pg-2-wal", Namespace: "db"}, &wal)).To(Succeed())
+		request := wal.Spec.Resources.Requests[corev1.ResourceStorage]
+		Expect(request.String()).To(Equal("2Gi"))
+	})
+
+	It("should report an instance left partially expanded", func() {
+		c := fake.NewClientBuilder().WithObjects(pvc("pg-1", "10Gi")).Build()
+		r := &StorageEventReconciler{expansionEngine: remediation.NewExpansionEngine(c)}
+		event := &cnpgv1alpha1.StorageEvent{
+			Spec: cnpgv1alpha1.StorageEventSpec{
+				ClusterRef: cnpgv1alpha1.ClusterReference{Name: "pg", Namespace: "db"},
+			},
+			Status: cnpgv1alpha1.StorageEventStatus{
+				PVCStatuses: []cnpgv1alpha1.PVCStatus{status("pg-1", "pg-1", "15Gi"), status("pg-1-wal", "pg-1", "3Gi")},
+			},
+		}
+		event.Status.PVCStatuses[0].Phase = cnpgv1alpha1.PVCPhaseCompleted
+
+		_, err := r.resizePVCs(context.Background(), event, &cnpgv1alpha1.StoragePolicy{})
+		Expect(err).To(MatchError(ContainSubstring("instance pg-1, partially expanded with pg-1 resized")))
+		Expect(event.Status.PVCStatuses[1].Phase).To(Equal(cnpgv1alpha1.PVCPhaseFailed))
+	})
+})
+
+var _ = Describe("Rolling Expansion", func() {
+	It("should expand and verify the replicas before the primary", func() {
+		ctx := context.Background()
+		cluster := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "postgresql.cnpg.io/v1",
+			"kind":       "Cluster",
+			"metadata":   map[string]interface{}{"name": "pg", "namespace": "db"},
+			"spec":       map[string]interface{}{"instances": int64(2)},
+			"status": map[string]interface{}{
+				"phase": cnpg.ClusterPhaseHealthy, "readyInstances": int64(2), "currentPrimary": "pg-1",
+			},
+		}}
+		pvc := func(name string) *corev1.PersistentVolumeClaim {
+			size := corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+			return &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db"},
+				Spec:       corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{Requests: size}},
+				Status:     corev1.PersistentVolumeClaimStatus{Capacity: size},
+			}
+		}
+		c := fake.NewClientBuilder().WithObjects(cluster, pvc("pg-1"), pvc("pg-2")).Build()
+		r := &StorageEventReconciler{
+			Client:          c,
+			expansionEngine: remediation.NewExpansionEngine(c),
+			discovery:       cnpg.NewDiscovery(c),
+		}
+		policyObj := &cnpgv1alpha1.StoragePolicy{}
+		policyObj.Spec.Expansion.Rollout = &cnpgv1alpha1.ExpansionRolloutConfig{
+			Order: cnpgv1alpha1.ExpansionOrderReplicasFirst, WaitForHealthy: true,
+		}
+		status := func(name string) cnpgv1alpha1.PVCStatus {
+			newSize := resource.MustParse("15Gi")
+			return cnpgv1alpha1.PVCStatus{Name: name, Instance: name, Phase: cnpgv1alpha1.PVCPhasePending, NewSize: &newSize}
+		}
+		statuses := []cnpgv1alpha1.PVCStatus{status("pg-1"), status("pg-2")}
+		remediation.OrderByInstance(statuses, "pg-1", policyObj.Spec.Expansion.Rollout.Order)
+		event := &cnpgv1alpha1.StorageEvent{
+			Spec: cnpgv1alpha1.StorageEventSpec{
+				ClusterRef: cnpgv1alpha1.ClusterReference{Name: "pg", Namespace: "db"},
+			},
+			Status: cnpgv1alpha1.StorageEventStatus{PVCStatuses: statuses},
+		}
+		request := func(name string) string {
+			var got corev1.PersistentVolumeClaim
+			Expect(c.Get(ctx, client.ObjectKey{Name: name, Namespace: "db"}, &got)).To(Succeed())
+			size := got.Spec.Resources.Requests[corev1.ResourceStorage]
+			return size.String()
+		}
+		resized := func(name string) {
+			var got corev1.PersistentVolumeClaim
+			Expect(c.Get(ctx, client.ObjectKey{Name: name, Namespace: "db"}, &got)).To(Succeed())
+			got.Status.Capacity = got.Spec.Resources.Requests
+			Expect(c.Status().Update(ctx, &got)).To(Succeed())
+		}
+
+		outcome, err := r.resizePVCs(ctx, event, policyObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outcome.requeueAfter).NotTo(BeZero())
+		Expect(outcome.message).To(ContainSubstring("Expanding instance pg-2 (1 of 2)"))
+		Expect(request("pg-2")).To(Equal("15Gi"))
+		Expect(request("pg-1")).To(Equal("10Gi"))
+
+		resized("pg-2")
+		Expect(unstructured.SetNestedField(cluster.Object, int64(1), "status", "readyInstances")).To(Succeed())
+		Expect(c.Update(ctx, cluster)).To(Succeed())
+		outcome, err = r.resizePVCs(ctx, event, policyObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outcome.message).To(ContainSubstring("waiting for the cluster to be healthy"))
+		Expect(request("pg-1")).To(Equal("10Gi"))
+
+		Expect(unstructured.SetNestedField(cluster.Object, int64(2), "status", "readyInstances")).To(Succeed())
+		Expect(c.Update(ctx, cluster)).To(Succeed())
+		outcome, err = r.resizePVCs(ctx, event, policyObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outcome.message).To(ContainSubstring("Expanding instance pg-1 (2 of 2)"))
+		Expect(request("pg-1")).To(Equal("15Gi"))
+		Expect(event.Status.ExpansionRollout.ExpandedInstances).To(Equal([]string{"pg-2"}))
+
+		resized("pg-1")
+		outcome, err = r.resizePVCs(ctx, event, policyObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outcome.requeueAfter).To(BeZero())
+		Expect(outcome.message).To(ContainSubstring("2 instances expanded one at a time"))
+	})
+})
+
+var _ = Describe("Disk Error Watch", func() {
+	It("evaluates the policies selecting the cluster of a pod out of space, once per debounce", func() {
+		ctx := context.Background()
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		fakeClock := clocktesting.NewFakePassiveClock(now)
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "pg-2", Namespace: "db", Labels: map[string]string{"cnpg.io/cluster": "pg"},
+		}}
+		selecting := &cnpgv1alpha1.StoragePolicy{ObjectMeta: metav1.ObjectMeta{Name: "selecting", Namespace: "db"}}
+		selecting.Spec.IncludeClusters = &cnpgv1alpha1.ClusterInclusion{
+			Clusters: []cnpgv1alpha1.ClusterReference{{Name: "pg", Namespace: "db"}},
+		}
+		other := &cnpgv1alpha1.StoragePolicy{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "db"}}
+		other.Spec.IncludeClusters = &cnpgv1alpha1.ClusterInclusion{
+			Clusters: []cnpgv1alpha1.ClusterReference{{Name: "pg-other", Namespace: "db"}},
+		}
+		c := fake.NewClientBuilder().WithObjects(pod, selecting, other).Build()
+		r := &StoragePolicyReconciler{Client: c, Clock: fakeClock, WatchDiskErrors: true}
+
+		diskFull := &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg-2.1", Namespace: "db"},
+			InvolvedObject: corev1.ObjectReference{
+				Kind: "Pod", Name: "pg-2", Namespace: "db",
+			},
+			Reason:        "BackOff",
+			Message:       `could not write to file "pg_wal/xlogtemp.42": No space left on device`,
+			LastTimestamp: metav1.NewTime(now.Add(-time.Second)),
+		}
+		Expect(isDiskFullEvent(diskFull)).To(BeTrue())
+		Expect(diskErrorPredicate().Create(event.CreateEvent{Object: diskFull})).To(BeTrue())
+		Expect(r.policiesForDiskError(ctx, diskFull)).To(ConsistOf(reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "selecting", Namespace: "db"},
+		}))
+
+		By("debouncing further errors of the cluster")
+		Expect(r.policiesForDiskError(ctx, diskFull)).To(BeEmpty())
+		fakeClock.SetTime(now.Add(diskErrorDebounce))
+		diskFull.LastTimestamp = metav1.NewTime(fakeClock.Now())
+		Expect(r.policiesForDiskError(ctx, diskFull)).To(HaveLen(1))
+
+		By("ignoring stale errors and other events")
+		fakeClock.SetTime(now.Add(time.Hour))
+		Expect(r.policiesForDiskError(ctx, diskFull)).To(BeEmpty())
+		unrelated := diskFull.DeepCopy()
+		unrelated.Message = "Back-off restarting failed container"
+		Expect(isDiskFullEvent(unrelated)).To(BeFalse())
+		node := diskFull.DeepCopy()
+		node.InvolvedObject.Kind = "Node"
+		Expect(isDiskFullEvent(node)).To(BeFalse())
+		cluster := diskFull.DeepCopy()
+		cluster.InvolvedObject = corev1.ObjectReference{Kind: "Cluster", APIVersion: cnpg.CNPGGroup + "/v1", Name: "pg"}
+		Expect(isDiskFullEvent(cluster)).To(BeFalse())
+		Expect(DiskErrorEventCache().Field.Matches(fields.Set{"involvedObject.kind": "Pod"})).To(BeTrue())
+		Expect(DiskErrorEventCache().Field.Matches(fields.Set{"involvedObject.kind": "Cluster"})).To(BeFalse())
+	})
+})
+
+var _ = Describe("Log Hints", func() {
+	It("attaches the key errors of the primary's log to emergency alerts", func() {
+		ctx := context.Background()
+		var annotations []map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var alerts []struct {
+				Annotations map[string]string `json:"annotations"`
+			}
+			Expect(json.NewDecoder(req.Body).Decode(&alerts)).To(Succeed())
+			annotations = append(annotations, alerts[0].Annotations)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		primary := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Namespace: "db", Labels: map[string]string{
+			"cnpg.io/cluster": "pg", "cnpg.io/instanceRole": "primary",
+		}}}
+		c := fake.NewClientBuilder().WithObjects(primary).Build()
+		var logRequests []string
+		apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			logRequests = append(logRequests, req.URL.Path+"?"+req.URL.RawQuery)
+			_, _ = fmt.Fprintln(w, `{"level":"info","logger":"postgres","msg":"record",`+
+				`"record":{"error_severity":"LOG","message":"checkpoint starting: time"}}`)
+			_, _ = fmt.Fprintln(w, `{"level":"info","logger":"postgres","msg":"record","record":{"error_severity":"ERROR",`+
+				`"message":"could not extend file \"base/16384/16385\": No space left on device"}}`)
+		}))
+		defer apiServer.Close()
+		clientset, err := kubernetes.NewForConfig(&rest.Config{Host: apiServer.URL})
+		Expect(err).NotTo(HaveOccurred())
+		r := &StoragePolicyReconciler{
+			Client:       c,
+			PostgresLogs: metrics.NewPostgresLogCollector(clientset),
+			discovery:    cnpg.NewDiscovery(c),
+		}
+		policyObj := &cnpgv1alpha1.StoragePolicy{ObjectMeta: metav1.ObjectMeta{Name: "hints", Namespace: "db"}}
+		policyObj.Spec.Alerting.Channels = []cnpgv1alpha1.AlertChannel{
+			{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: server.URL},
+		}
+		policyObj.Spec.Alerting.LogHints = &cnpgv1alpha1.LogHintsConfig{Lines: 50}
+		cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+
+		By("leaving them out of critical alerts")
+		critical := policy.ThresholdResult{Level: policy.ThresholdLevelCritical, CurrentUsagePercent: 92}
+		Expect(r.sendThresholdAlert(ctx, policyObj, cluster, nil, critical)).To(Succeed())
+		Expect(logRequests).To(BeEmpty())
+
+		emergency := policy.ThresholdResult{Level: policy.ThresholdLevelEmergency, CurrentUsagePercent: 97}
+		Expect(r.sendThresholdAlert(ctx, policyObj, cluster, nil, emergency)).To(Succeed())
+		Expect(annotations).To(HaveLen(2))
+		Expect(annotations[1]).To(HaveKeyWithValue(alerting.DetailLogHints,
+			`ERROR: could not extend file "base/16384/16385": No space left on device`))
+		Expect(logRequests).To(Equal([]string{"/api/v1/namespaces/db/pods/pg-1/log?container=postgres&tailLines=50"}))
+
+		By("not reading the log again for a suppressed duplicate")
+		Expect(r.sendThresholdAlert(ctx, policyObj, cluster, nil, emergency)).To(Succeed())
+		Expect(logRequests).To(HaveLen(1))
+		Expect(annotations).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("Threshold Breach Events", func() {
+	It("records a breach only when the evaluation was not blocked", func() {
+		events := record.NewFakeRecorder(10)
+		r := &StoragePolicyReconciler{events: recorder.New(events, fake.NewClientBuilder().Build())}
+		cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+
+		By("recording nothing while the circuit breaker is open")
+		r.recordThresholdBreach(cluster, &policy.EvaluationResult{Blocked: true, BlockedReason: "circuit breaker is open"})
+		r.recordThresholdBreach(cluster, &policy.EvaluationResult{
+			ThresholdResult: policy.ThresholdResult{Level: policy.ThresholdLevelNormal},
+		})
+		Expect(events.Events).To(BeEmpty())
+
+		r.recordThresholdBreach(cluster, &policy.EvaluationResult{ThresholdResult: policy.ThresholdResult{
+			Level: policy.ThresholdLevelCritical, Message: "Storage usage 91.0% exceeds critical threshold",
+		}})
+		Expect(events.Events).To(Receive(ContainSubstring("Storage usage 91.0% exceeds critical threshold")))
+	})
+})
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// evaluateTablespaces evaluates each declarative tablespace of a cluster against the
+// policy thresholds on its own, requesting an expansion of the tablespace's PVCs when
+// the expansion threshold is reached. Tablespaces share the cluster's expansion
+// cooldown and circuit breaker
+func (r *StoragePolicyReconciler) evaluateTablespaces(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	clusterMetrics *metrics.ClusterMetrics,
+	ca *clusterAnnotationsWrapper,
+) ([]cnpgv1alpha1.TablespaceStatus, []cnpgv1alpha1.PlannedAction) {
+	if clusterMetrics == nil {
+		return nil, nil
+	}
+
+	log := logf.FromContext(ctx)
+	var statuses []cnpgv1alpha1.TablespaceStatus
+	var plannedActions []cnpgv1alpha1.PlannedAction
+	for _, usage := range clusterMetrics.TablespaceUsage() {
+		usagePercent := usage.UsagePercent()
+		result := r.evaluator.EvaluateUsage(usagePercent, usage.FreeBytes(), policyObj.Spec.Thresholds)
+		result.Message = fmt.Sprintf("Tablespace %s: %s", usage.Name, result.Message)
+
+		status := "Healthy"
+		if result.Level != policy.ThresholdLevelNormal {
+			metrics.RecordThresholdBreach(cluster.Name, cluster.Namespace, string(result.Level))
+			r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonThresholdBreached, "%s", result.Message)
+			status = fmt.Sprintf("Alert-%s", result.Level)
+		}
+
+		switch {
+		case result.ShouldExpand && remediation.IsTablespaceExpansionEnabled(policyObj, usage.Name):
+			reason := fmt.Sprintf("tablespace %s threshold breach: %.1f%%", usage.Name, usagePercent)
+			var planned *cnpgv1alpha1.PlannedAction
+			status, planned = r.expandTarget(ctx, policyObj, cluster, ca,
+				cnpgv1alpha1.ExpansionTarget{Tablespace: usage.Name}, reason, usagePercent, status)
+			if planned != nil {
+				plannedActions = append(plannedActions, *planned)
+			}
+		case result.ShouldAlert:
+			if err := r.sendThresholdAlert(ctx, policyObj, cluster, nil, result); err != nil {
+				log.Error(err, "Failed to send tablespace alert", "cluster", cluster.Name, "tablespace", usage.Name)
+			}
+		}
+
+		statuses = append(statuses, cnpgv1alpha1.TablespaceStatus{
+			Name:         usage.Name,
+			PVC:          usage.PVCName,
+			UsagePercent: int32(usagePercent),
+			Status:       status,
+		})
+	}
+	return statuses, plannedActions
+}
+
+// expandTarget requests the expansion of the PVCs of a target evaluated on its own, such
+// as a tablespace, honouring pause, dry-run and the cluster's expansion cooldown and
+// circuit breaker. It returns the resulting status, which stays status when the
+// expansion is not allowed yet, and the plan of an expansion held back by dry-run mode
+func (r *StoragePolicyReconciler) expandTarget(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	ca *clusterAnnotationsWrapper,
+	target cnpgv1alpha1.ExpansionTarget,
+	reason string,
+	usagePercent float64,
+	status string,
+) (string, *cnpgv1alpha1.PlannedAction) {
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name, "tablespace", target.Tablespace,
+		"volume", target.Volume)
+
+	switch {
+	case policy.IsPolicyPaused(policyObj, r.now()):
+		log.Info("Policy is paused, not expanding PVCs")
+		return statusPausedWouldExpand, nil
+	case r.isDryRun(policyObj, cnpgv1alpha1.EventTypeExpansion):
+		log.Info("DryRun: Would expand PVCs")
+		action := r.planDryRunAction(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeExpansion, target, reason)
+		return "DryRun-WouldExpand", &action
+	}
+
+	if allowed, why := ca.CanExpand(policyObj.Spec.Expansion.CooldownMinutes); !allowed {
+		log.Info("Expansion not allowed", "reason", why)
+		return status, nil
+	}
+	event, err := r.requestRemediation(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeExpansion,
+		target, reason, usagePercent)
+	switch {
+	case isCapacityShortfall(err):
+		return statusInsufficientCapacity, nil
+	case isQuotaExceeded(err):
+		return statusQuotaExceeded, nil
+	case err != nil:
+		log.Error(err, "Expansion failed")
+		return "ExpansionFailed", nil
+	case event != nil && !remediation.IsEventApproved(event):
+		return statusAwaitingApproval, nil
+	case policyObj.Spec.Expansion.Mode == cnpgv1alpha1.ExpansionModeRecommend:
+		return "ExpansionRecommended", nil
+	default:
+		return "Expanding", nil
+	}
+}
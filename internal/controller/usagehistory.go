@@ -0,0 +1,118 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// RBAC for StorageUsageHistory maintenance (persisted usage samples)
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=storageusagehistories,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cnpg.supporttools.io,resources=storageusagehistories/status,verbs=get;update;patch
+
+// updateUsageHistory records the usage of a cluster in its StorageUsageHistory once per
+// hour and returns the history, or nil when the policy keeps none or it cannot be read.
+// Without metrics the history is returned as it is
+func (r *StoragePolicyReconciler) updateUsageHistory(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	clusterMetrics *metrics.ClusterMetrics,
+) *cnpgv1alpha1.StorageUsageHistory {
+	if !policyObj.Spec.UsageHistory.Enabled {
+		return nil
+	}
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name, "namespace", cluster.Namespace)
+
+	history := &cnpgv1alpha1.StorageUsageHistory{}
+	key := client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}
+	exists := true
+	if err := r.Get(ctx, key, history); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get StorageUsageHistory")
+			return nil
+		}
+		exists = false
+	}
+
+	if clusterMetrics == nil || len(clusterMetrics.PVCMetrics) == 0 {
+		if !exists {
+			return nil
+		}
+		return history
+	}
+	dataUsedBytes, _, instances := fullestDataVolume(clusterMetrics)
+	if instances == 0 {
+		dataUsedBytes = -1
+	}
+
+	status := history.Status.DeepCopy()
+	if !policy.RecordUsageHistory(status, clusterMetrics.LargestInstanceUsedBytes(), dataUsedBytes, r.now()) {
+		return history
+	}
+	status.Policy = fmt.Sprintf("%s/%s", policyObj.Namespace, policyObj.Name)
+
+	if !exists {
+		history = &cnpgv1alpha1.StorageUsageHistory{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cluster.Name,
+				Namespace: cluster.Namespace,
+				Labels:    map[string]string{remediation.LabelCluster: cluster.Name},
+			},
+			Spec: cnpgv1alpha1.StorageUsageHistorySpec{ClusterName: cluster.Name},
+		}
+		if err := r.Create(ctx, history); err != nil {
+			log.Error(err, "Failed to create StorageUsageHistory")
+			return nil
+		}
+	}
+	history.Status = *status
+	if err := r.Status().Update(ctx, history); err != nil {
+		log.Error(err, "Failed to update StorageUsageHistory status")
+	}
+	return history
+}
+
+// historyGrowthSamples returns the hourly samples of a usage history for anomaly
+// detection, or nil without a history
+func historyGrowthSamples(history *cnpgv1alpha1.StorageUsageHistory) []cnpgv1alpha1.UsageSample {
+	if history == nil {
+		return nil
+	}
+	return policy.UsageHistorySamples(history.Status.Newest, history.Status.UsedBytes)
+}
+
+// historyForecastSamples returns the hourly samples of the fullest data volume of a
+// usage history for forecasts, or nil without a history
+func historyForecastSamples(history *cnpgv1alpha1.StorageUsageHistory) []cnpgv1alpha1.UsageSample {
+	if history == nil {
+		return nil
+	}
+	return policy.UsageHistorySamples(history.Status.Newest, history.Status.DataUsedBytes)
+}
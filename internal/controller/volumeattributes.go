@@ -0,0 +1,268 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// volumeModifyPollInterval is how often a volume-attributes-change event re-checks the
+// ModifyVolume operations of its PVCs
+const volumeModifyPollInterval = 30 * time.Second
+
+// requestVolumeAttributesChange requests a volume-attributes-change StorageEvent for a
+// cluster whose PVCs do not use the VolumeAttributesClass the policy selects for its
+// threshold level. A failed change to the same class is not requested again until its
+// StorageEvent is deleted. In dry-run mode the planned action is returned
+func (r *StoragePolicyReconciler) requestVolumeAttributesChange(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	level policy.ThresholdLevel,
+	ca *clusterAnnotationsWrapper,
+) *cnpgv1alpha1.PlannedAction {
+	details := remediation.NewVolumeAttributesChangeDetails(policyObj, string(level))
+	if details == nil {
+		return nil
+	}
+	log := logf.FromContext(ctx).WithValues("cluster", cluster.Name,
+		"volumeAttributesClass", details.VolumeAttributesClass)
+
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to list PVCs for the VolumeAttributesClass change")
+		return nil
+	}
+	if len(remediation.PVCsNeedingVolumeAttributesClass(pvcs, details.VolumeAttributesClass)) == 0 {
+		return nil
+	}
+
+	reason := fmt.Sprintf("%s threshold selects VolumeAttributesClass %s", details.Threshold,
+		details.VolumeAttributesClass)
+	switch {
+	case policy.IsPolicyPaused(policyObj, r.now()):
+		log.Info("Policy is paused, not changing the VolumeAttributesClass")
+		return nil
+	case r.isDryRun(policyObj, cnpgv1alpha1.EventTypeVolumeAttributesChange):
+		log.Info("DryRun: Would change the VolumeAttributesClass")
+		action := r.planDryRunAction(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeVolumeAttributesChange,
+			cnpgv1alpha1.ExpansionTarget{}, reason)
+		return &action
+	case ca.IsCircuitBreakerOpen():
+		log.Info("Circuit breaker is open, not changing the VolumeAttributesClass")
+		return nil
+	}
+
+	if err := remediation.CheckVolumeAttributesClass(ctx, r.Client, details.VolumeAttributesClass); err != nil {
+		log.Error(err, "Cannot change the VolumeAttributesClass")
+		r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonVolumeAttributesClassUnavailable,
+			"Cannot move PVCs to VolumeAttributesClass %s: %v", details.VolumeAttributesClass, err)
+		return nil
+	}
+
+	active, err := remediation.FindActiveEvent(ctx, r.Client, cluster.Name, cluster.Namespace,
+		cnpgv1alpha1.EventTypeVolumeAttributesChange)
+	if err != nil {
+		log.Error(err, "Failed to check active VolumeAttributesClass changes")
+		return nil
+	}
+	if active != nil {
+		log.V(1).Info("VolumeAttributesClass change already in progress", "event", active.Name)
+		return nil
+	}
+	failed, err := remediation.FindFailedVolumeAttributesChange(ctx, r.Client, cluster.Name, cluster.Namespace,
+		details.VolumeAttributesClass)
+	if err != nil {
+		log.Error(err, "Failed to check previous VolumeAttributesClass changes")
+		return nil
+	}
+	if failed != nil {
+		log.V(1).Info("Previous VolumeAttributesClass change failed, delete its StorageEvent to retry",
+			"event", failed.Name)
+		return nil
+	}
+
+	event := remediation.NewVolumeAttributesChangeEvent(policyObj, cluster.Name, cluster.Namespace, details, reason)
+	event.Spec.Ownership = eventOwnership(policyObj, cluster)
+	if err := r.Create(ctx, event); err != nil {
+		log.Error(err, "Failed to request the VolumeAttributesClass change")
+		return nil
+	}
+	log.Info("Remediation requested", "event", event.Name, "type", event.Spec.EventType, "reason", reason,
+		"approvalRequired", event.Spec.ApprovalRequired)
+	return nil
+}
+
+// modifyVolumes sets the event's VolumeAttributesClass on the PVCs of the cluster. The
+// event is aborted when the cluster does not serve the VolumeAttributesClass API or the
+// class does not exist
+func (r *StorageEventReconciler) modifyVolumes(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+) (stepOutcome, error) {
+	log := logf.FromContext(ctx)
+	details := event.Spec.VolumeAttributesChange
+	if details == nil {
+		return stepOutcome{abort: "volume attributes change details are missing"}, nil
+	}
+	className := details.VolumeAttributesClass
+
+	err := remediation.CheckVolumeAttributesClass(ctx, r.Client, className)
+	switch {
+	case errors.Is(err, remediation.ErrVolumeAttributesClassUnsupported):
+		return stepOutcome{abort: err.Error()}, nil
+	case apierrors.IsNotFound(err):
+		return stepOutcome{abort: fmt.Sprintf("VolumeAttributesClass %s does not exist", className)}, nil
+	case err != nil:
+		return stepOutcome{}, err
+	}
+
+	namespace := event.Spec.ClusterRef.Namespace
+	pvcs, err := r.discovery.GetClusterPVCs(ctx, event.Spec.ClusterRef.Name, namespace)
+	if err != nil {
+		return stepOutcome{}, err
+	}
+	// Record every PVC before patching, so a resumed event still verifies the PVCs
+	// patched before a restart
+	if len(event.Status.VolumeModifications) == 0 {
+		for i := range pvcs {
+			status := cnpgv1alpha1.VolumeModificationStatus{
+				PVC:   pvcs[i].Name,
+				Phase: cnpgv1alpha1.VolumeModificationPending,
+			}
+			if previous := pvcs[i].Spec.VolumeAttributesClassName; previous != nil {
+				status.PreviousClass = *previous
+			}
+			event.Status.VolumeModifications = append(event.Status.VolumeModifications, status)
+		}
+		if err := r.Status().Update(ctx, event); err != nil {
+			return stepOutcome{}, err
+		}
+	}
+
+	needed := remediation.PVCsNeedingVolumeAttributesClass(pvcs, className)
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		if !slices.Contains(needed, pvc.Name) {
+			continue
+		}
+		patch := client.MergeFrom(pvc.DeepCopy())
+		pvc.Spec.VolumeAttributesClassName = &className
+		if err := r.Patch(ctx, pvc, patch); err != nil {
+			return stepOutcome{}, fmt.Errorf("failed to set VolumeAttributesClass on PVC %s: %w", pvc.Name, err)
+		}
+		log.Info("VolumeAttributesClass change requested", "pvc", pvc.Name, "volumeAttributesClass", className)
+		r.events.PVC(ctx, pvc.Name, namespace, corev1.EventTypeNormal, recorder.ReasonModifyVolumeRequested,
+			"Requested VolumeAttributesClass %s", className)
+	}
+	return stepOutcome{message: fmt.Sprintf("%d PVCs moved to VolumeAttributesClass %s", len(needed), className)}, nil
+}
+
+// verifyModification follows the ModifyVolume operation of each PVC until the volumes use
+// the new class. A modification the CSI driver reports as infeasible is reverted to the
+// PVC's previous class and aborts the event; one that does not complete within the
+// policy's timeout fails the step so it is retried
+func (r *StorageEventReconciler) verifyModification(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+) (stepOutcome, error) {
+	details := event.Spec.VolumeAttributesChange
+	if details == nil {
+		return stepOutcome{abort: "volume attributes change details are missing"}, nil
+	}
+	className := details.VolumeAttributesClass
+	namespace := event.Spec.ClusterRef.Namespace
+
+	var pending []string
+	for i := range event.Status.VolumeModifications {
+		status := &event.Status.VolumeModifications[i]
+		if status.Phase == cnpgv1alpha1.VolumeModificationCompleted {
+			continue
+		}
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.Get(ctx, client.ObjectKey{Name: status.PVC, Namespace: namespace}, pvc); err != nil {
+			return stepOutcome{}, fmt.Errorf("failed to get PVC %s: %w", status.PVC, err)
+		}
+		status.Phase = remediation.VolumeModificationPhaseOf(pvc, className)
+		switch status.Phase {
+		case cnpgv1alpha1.VolumeModificationCompleted:
+			r.events.PVC(ctx, status.PVC, namespace, corev1.EventTypeNormal, recorder.ReasonVolumeModified,
+				"Volume uses VolumeAttributesClass %s", className)
+		case cnpgv1alpha1.VolumeModificationInfeasible:
+			r.events.PVC(ctx, status.PVC, namespace, corev1.EventTypeWarning, recorder.ReasonModifyVolumeInfeasible,
+				"The CSI driver cannot apply VolumeAttributesClass %s", className)
+			if err := r.revertVolumeAttributesClass(ctx, pvc, status.PreviousClass); err != nil {
+				return stepOutcome{}, err
+			}
+			return stepOutcome{abort: fmt.Sprintf("the CSI driver cannot move PVC %s to VolumeAttributesClass %s",
+				status.PVC, className)}, nil
+		default:
+			pending = append(pending, status.PVC)
+		}
+	}
+
+	if len(pending) == 0 {
+		return stepOutcome{message: fmt.Sprintf("%d PVCs use VolumeAttributesClass %s",
+			len(event.Status.VolumeModifications), className)}, nil
+	}
+
+	step := remediation.FindStep(event, remediation.StepVerifyModification)
+	if step != nil && step.StartTime != nil && time.Since(step.StartTime.Time) > remediation.ModifyVolumeTimeout(details) {
+		// Restart verification timing on the next attempt
+		step.StartTime = nil
+		return stepOutcome{}, fmt.Errorf("timed out waiting for PVCs to be modified: %v", pending)
+	}
+
+	return stepOutcome{
+		message:      fmt.Sprintf("Waiting for %d PVCs to be modified", len(pending)),
+		requeueAfter: volumeModifyPollInterval,
+	}, nil
+}
+
+// revertVolumeAttributesClass sets a PVC back to the class it used before the change,
+// which cancels an infeasible modification
+func (r *StorageEventReconciler) revertVolumeAttributesClass(
+	ctx context.Context,
+	pvc *corev1.PersistentVolumeClaim,
+	previousClass string,
+) error {
+	patch := client.MergeFrom(pvc.DeepCopy())
+	pvc.Spec.VolumeAttributesClassName = nil
+	if previousClass != "" {
+		pvc.Spec.VolumeAttributesClassName = &previousClass
+	}
+	if err := r.Patch(ctx, pvc, patch); err != nil {
+		return fmt.Errorf("failed to revert the VolumeAttributesClass of PVC %s: %w", pvc.Name, err)
+	}
+	return nil
+}
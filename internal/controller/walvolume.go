@@ -0,0 +1,107 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// evaluateWALVolume evaluates the separate WAL volumes of a cluster against the policy's
+// walThresholds, using the instance where they are fullest. An emergency requests WAL
+// cleanup and the expansion threshold an expansion of the WAL PVCs only. It returns nil
+// when the policy has no walThresholds or the cluster no separate WAL volumes
+func (r *StoragePolicyReconciler) evaluateWALVolume(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	clusterMetrics *metrics.ClusterMetrics,
+	ca *clusterAnnotationsWrapper,
+) (*cnpgv1alpha1.WALVolumeStatus, *cnpgv1alpha1.PlannedAction) {
+	if policyObj.Spec.WALThresholds == nil || clusterMetrics == nil {
+		return nil, nil
+	}
+	usage := clusterMetrics.WALUsage()
+	if usage == nil {
+		return nil, nil
+	}
+
+	log := logf.FromContext(ctx)
+	usagePercent := usage.UsagePercent()
+	result := r.evaluator.EvaluateUsage(usagePercent, usage.FreeBytes(), *policyObj.Spec.WALThresholds)
+	result.Message = "WAL volume: " + result.Message
+
+	status := "Healthy"
+	if result.Level != policy.ThresholdLevelNormal {
+		metrics.RecordThresholdBreach(cluster.Name, cluster.Namespace, string(result.Level))
+		r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonThresholdBreached, "%s", result.Message)
+		status = fmt.Sprintf("Alert-%s", result.Level)
+	}
+
+	var planned *cnpgv1alpha1.PlannedAction
+	switch {
+	case result.ShouldCleanupWAL && policyObj.Spec.WALCleanup.Enabled:
+		reason := fmt.Sprintf("WAL volume emergency threshold breach: %.1f%%", usagePercent)
+		switch {
+		case policy.IsPolicyPaused(policyObj, r.now()):
+			log.Info("Policy is paused, not cleaning up WAL", "cluster", cluster.Name)
+			status = statusPausedWouldCleanupWAL
+		case r.isDryRun(policyObj, cnpgv1alpha1.EventTypeWALCleanup):
+			log.Info("DryRun: Would cleanup WAL", "cluster", cluster.Name, "walUsagePercent", usagePercent)
+			status = "DryRun-WouldCleanupWAL"
+			action := r.planDryRunAction(ctx, policyObj, cluster, cnpgv1alpha1.EventTypeWALCleanup,
+				cnpgv1alpha1.ExpansionTarget{}, reason)
+			planned = &action
+		default:
+			event, err := r.handleWALCleanup(ctx, policyObj, cluster, ca,
+				&policy.ActionRecommendation{Action: policy.ActionTypeWALCleanup, Reason: reason}, usagePercent)
+			switch {
+			case err != nil:
+				log.Error(err, "WAL cleanup failed", "cluster", cluster.Name)
+				status = "WALCleanupFailed"
+			case event != nil && !remediation.IsEventApproved(event):
+				status = statusAwaitingApproval
+			case event != nil:
+				status = "WALCleanup"
+			}
+		}
+	case result.ShouldExpand && remediation.IsWALExpansionEnabled(policyObj):
+		reason := fmt.Sprintf("WAL volume threshold breach: %.1f%%", usagePercent)
+		status, planned = r.expandTarget(ctx, policyObj, cluster, ca,
+			cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeWAL}, reason, usagePercent, status)
+	case result.ShouldAlert:
+		if err := r.sendThresholdAlert(ctx, policyObj, cluster, nil, result); err != nil {
+			log.Error(err, "Failed to send WAL volume alert", "cluster", cluster.Name)
+		}
+	}
+
+	return &cnpgv1alpha1.WALVolumeStatus{
+		PVC:          usage.PVCName,
+		UsagePercent: int32(usagePercent),
+		Status:       status,
+	}, planned
+}
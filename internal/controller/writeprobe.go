@@ -0,0 +1,133 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/alerting"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/recorder"
+)
+
+// probeWrites runs the write probe against the cluster's primary when the policy enables
+// it, and alerts when the primary stops accepting writes. A fenced primary is reported
+// instead of probed. The result is nil when writes were not probed
+func (r *StoragePolicyReconciler) probeWrites(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	usagePercent float64,
+) (result *metrics.WriteProbeResult, primaryFenced bool) {
+	log := logf.FromContext(ctx)
+
+	if !policyObj.Spec.WriteProbe.Enabled {
+		return nil, false
+	}
+	if r.WriteProbe == nil {
+		log.V(1).Info("Write probe requires the exec command runner, skipping", "cluster", cluster.Name)
+		return nil, false
+	}
+
+	primary, err := r.discovery.GetPrimaryPod(ctx, cluster.Name, cluster.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get primary pod for the write probe", "cluster", cluster.Name)
+		return nil, false
+	}
+	if cluster.IsInstanceFenced(primary.Name) {
+		return nil, true
+	}
+
+	probe := r.WriteProbe.Probe(ctx, primary)
+	switch probe.Status {
+	case metrics.WriteProbeWritable:
+		metrics.SetClusterWritable(cluster.Name, cluster.Namespace, true)
+	case metrics.WriteProbeFailed:
+		log.Info("Write probe failed", "cluster", cluster.Name, "pod", primary.Name, "error", probe.Message)
+	default:
+		metrics.SetClusterWritable(cluster.Name, cluster.Namespace, false)
+		if writeFailureStarted(policyObj, cluster, probe.Status) {
+			r.alertWriteFailure(ctx, policyObj, cluster, probe, usagePercent)
+		}
+	}
+	return &probe, false
+}
+
+// writeFailureStarted returns true unless the cluster's previous status already reported
+// the same write failure
+func writeFailureStarted(
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	status metrics.WriteProbeStatus,
+) bool {
+	mc := previousManagedCluster(policyObj, cluster, "")
+	if mc == nil {
+		return true
+	}
+	condition := meta.FindStatusCondition(mc.Conditions, cnpgv1alpha1.ManagedClusterConditionWritable)
+	return condition == nil || condition.Reason != string(status)
+}
+
+// alertWriteFailure sends the write_failure alert and WriteProbeFailed event of a
+// primary that is read-only or out of space
+func (r *StoragePolicyReconciler) alertWriteFailure(
+	ctx context.Context,
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	cluster cnpg.ClusterInfo,
+	probe metrics.WriteProbeResult,
+	usagePercent float64,
+) {
+	log := logf.FromContext(ctx)
+
+	message := fmt.Sprintf("Primary %s of cluster %s/%s does not accept writes (%s) at %.1f%% usage",
+		probe.Pod, cluster.Namespace, cluster.Name, probe.Status, usagePercent)
+	log.Info("Primary does not accept writes", "cluster", cluster.Name, "pod", probe.Pod,
+		"status", probe.Status, "error", probe.Message)
+	r.events.Cluster(cluster, corev1.EventTypeWarning, recorder.ReasonWriteProbeFailed, "%s", message)
+
+	if len(policyObj.Spec.Alerting.Channels) == 0 {
+		return
+	}
+	alert := &alerting.Alert{
+		ClusterName:      cluster.Name,
+		ClusterNamespace: cluster.Namespace,
+		Type:             alerting.AlertTypeWriteFailure,
+		Policy:           policyObj.Name,
+		Ownership:        ownership(policyObj, cluster),
+		Severity:         alerting.AlertSeverityCritical,
+		Message:          message,
+		Details: map[string]string{
+			"policy":        policyObj.Name,
+			"pod":           probe.Pod,
+			"status":        string(probe.Status),
+			"usage_percent": fmt.Sprintf("%.1f", usagePercent),
+			"error":         probe.Message,
+		},
+		Timestamp: time.Now(),
+	}
+	if err := r.getAlertManager(policyObj).SendAlert(ctx, alert); err != nil {
+		log.Error(err, "Failed to send write failure alert", "cluster", cluster.Name)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package agent implements the node agent, which reports the usage of the PVC
+// mounts of pods on its node over HTTP so that storage metrics can be collected
+// without pod exec.
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// VolumesPath is the HTTP path of the volume usage report
+	VolumesPath = "/v1/volumes"
+	// PodParam is the query parameter naming the UID of a pod to report on; it may be repeated
+	PodParam = "pod"
+	// DefaultPort is the port the agent listens on
+	DefaultPort = 9470
+	// DefaultPodsDir is where the kubelet keeps pod volumes
+	DefaultPodsDir = "/var/lib/kubelet/pods"
+)
+
+// skippedPlugins are volume plugins that never back a PVC
+var skippedPlugins = map[string]bool{
+	"kubernetes.io~empty-dir":    true,
+	"kubernetes.io~configmap":    true,
+	"kubernetes.io~secret":       true,
+	"kubernetes.io~projected":    true,
+	"kubernetes.io~downward-api": true,
+}
+
+// Report is the volume usage report of a node
+type Report struct {
+	NodeName    string        `json:"nodeName"`
+	CollectedAt time.Time     `json:"collectedAt"`
+	Volumes     []VolumeUsage `json:"volumes"`
+}
+
+// VolumeUsage is the usage of one volume mounted into a pod
+type VolumeUsage struct {
+	PodUID string `json:"podUID"`
+	// VolumeName is the name of the volume directory, which is the PersistentVolume
+	// name for PVC-backed volumes
+	VolumeName     string `json:"volumeName"`
+	CapacityBytes  int64  `json:"capacityBytes"`
+	UsedBytes      int64  `json:"usedBytes"`
+	AvailableBytes int64  `json:"availableBytes"`
+	Inodes         int64  `json:"inodes"`
+	InodesUsed     int64  `json:"inodesUsed"`
+	InodesFree     int64  `json:"inodesFree"`
+	// WALBytes and WALFiles describe the pg_wal directory when the volume holds one
+	WALBytes int64 `json:"walBytes,omitempty"`
+	WALFiles int   `json:"walFiles,omitempty"`
+}
+
+// Server serves volume usage reports for the pods of one node
+type Server struct {
+	// NodeName is reported back to the manager
+	NodeName string
+	// PodsDir is the kubelet pods directory, mounted read-only from the host
+	PodsDir string
+}
+
+// ServeHTTP reports the volumes of the pods named by the pod query parameters
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	podUIDs := r.URL.Query()[PodParam]
+	if len(podUIDs) == 0 {
+		http.Error(w, "at least one pod parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	report := Report{NodeName: s.NodeName, CollectedAt: time.Now(), Volumes: []VolumeUsage{}}
+	for _, uid := range podUIDs {
+		volumes, err := s.podVolumes(uid)
+		if err != nil {
+			log.FromContext(r.Context()).Error(err, "Failed to read pod volumes", "podUID", uid)
+			continue
+		}
+		report.Volumes = append(report.Volumes, volumes...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// podVolumes reports the volumes of one pod. A pod that is not on this node has no
+// directory and reports nothing.
+func (s *Server) podVolumes(uid string) ([]VolumeUsage, error) {
+	// The UID comes from the request, so it must not be able to leave PodsDir
+	if uid == "" || uid != filepath.Base(uid) || strings.HasPrefix(uid, ".") {
+		return nil, errors.New("invalid pod UID")
+	}
+
+	volumesDir := filepath.Join(s.PodsDir, uid, "volumes")
+	plugins, err := os.ReadDir(volumesDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var usage []VolumeUsage
+	for _, plugin := range plugins {
+		if !plugin.IsDir() || skippedPlugins[plugin.Name()] {
+			continue
+		}
+		volumes, err := os.ReadDir(filepath.Join(volumesDir, plugin.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, volume := range volumes {
+			mount := filepath.Join(volumesDir, plugin.Name(), volume.Name())
+			// CSI volumes are mounted below the volume directory, next to vol_data.json
+			if plugin.Name() == "kubernetes.io~csi" {
+				mount = filepath.Join(mount, "mount")
+			}
+
+			stats, err := statfs(mount)
+			if err != nil {
+				continue
+			}
+			stats.PodUID = uid
+			stats.VolumeName = volume.Name()
+			stats.WALBytes, stats.WALFiles = walUsage(mount)
+			usage = append(usage, stats)
+		}
+	}
+	return usage, nil
+}
+
+// walUsage sums the regular files of the pg_wal directory on a volume: at the
+// root of a dedicated WAL volume, or inside PGDATA on the data volume. pg_wal is a
+// symlink into the WAL volume when the cluster has one, and is not followed.
+func walUsage(mount string) (int64, int) {
+	for _, dir := range []string{"pg_wal", filepath.Join("pgdata", "pg_wal")} {
+		path := filepath.Join(mount, dir)
+		info, err := os.Lstat(path)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+
+		var bytes int64
+		var files int
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				bytes += info.Size()
+				files++
+			}
+		}
+		return bytes, files
+	}
+	return 0, 0
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer_Volumes(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("statfs is only implemented on Linux")
+	}
+
+	podsDir := t.TempDir()
+	volumes := filepath.Join(podsDir, "uid-1", "volumes")
+	// Data volume with pg_wal inside PGDATA, as a CSI mount
+	writeFile(t, filepath.Join(volumes, "kubernetes.io~csi", "pv-data", "mount", "pgdata", "pg_wal",
+		"000000010000000000000001"), 1024)
+	writeFile(t, filepath.Join(volumes, "kubernetes.io~csi", "pv-data", "mount", "pgdata", "pg_wal",
+		"000000010000000000000002"), 2048)
+	// Dedicated WAL volume from a local volume
+	writeFile(t, filepath.Join(volumes, "kubernetes.io~local-volume", "pv-wal", "pg_wal",
+		"000000010000000000000003"), 512)
+	// Ephemeral volumes are not reported
+	writeFile(t, filepath.Join(volumes, "kubernetes.io~empty-dir", "shm", "file"), 1)
+
+	server := &Server{NodeName: "node-1", PodsDir: podsDir}
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, VolumesPath+"?pod=uid-1&pod=uid-elsewhere", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if report.NodeName != "node-1" {
+		t.Errorf("expected node-1, got %s", report.NodeName)
+	}
+
+	byName := make(map[string]VolumeUsage)
+	for _, volume := range report.Volumes {
+		byName[volume.VolumeName] = volume
+	}
+	if len(byName) != 2 {
+		t.Fatalf("expected the two PV volumes, got %+v", report.Volumes)
+	}
+	data := byName["pv-data"]
+	if data.PodUID != "uid-1" || data.CapacityBytes == 0 || data.WALBytes != 3072 || data.WALFiles != 2 {
+		t.Errorf("unexpected data volume %+v", data)
+	}
+	if wal := byName["pv-wal"]; wal.WALBytes != 512 || wal.WALFiles != 1 {
+		t.Errorf("unexpected WAL volume %+v", wal)
+	}
+}
+
+func TestServer_RejectsBadRequests(t *testing.T) {
+	server := &Server{NodeName: "node-1", PodsDir: t.TempDir()}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		code   int
+		empty  bool
+	}{
+		{name: "no pods", method: http.MethodGet, target: VolumesPath, code: http.StatusBadRequest},
+		{
+			name:   "wrong method",
+			method: http.MethodPost,
+			target: VolumesPath + "?pod=uid-1",
+			code:   http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "path traversal",
+			method: http.MethodGet,
+			target: VolumesPath + "?pod=../../etc",
+			code:   http.StatusOK,
+			empty:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+			if rec.Code != tt.code {
+				t.Fatalf("expected %d, got %d", tt.code, rec.Code)
+			}
+			if !tt.empty {
+				return
+			}
+			var report Report
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil || len(report.Volumes) != 0 {
+				t.Errorf("expected an empty report, got %+v (%v)", report, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import "syscall"
+
+// statfs returns the filesystem usage of a mount
+func statfs(path string) (VolumeUsage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return VolumeUsage{}, err
+	}
+	blockSize := int64(st.Bsize)
+	capacity := int64(st.Blocks) * blockSize
+	return VolumeUsage{
+		CapacityBytes:  capacity,
+		UsedBytes:      capacity - int64(st.Bfree)*blockSize,
+		AvailableBytes: int64(st.Bavail) * blockSize,
+		Inodes:         int64(st.Files),
+		InodesUsed:     int64(st.Files) - int64(st.Ffree),
+		InodesFree:     int64(st.Ffree),
+	}, nil
+}
@@ -22,16 +22,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/clock"
+	"github.com/supporttools/cnpg-storage-manager/pkg/identity"
 	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
 )
 
 // AlertSeverity defines the severity of an alert
@@ -46,14 +50,66 @@ const (
 	AlertSeverityEmergency AlertSeverity = "emergency"
 )
 
+const (
+	// AlertTypeStorage is the type of storage usage alerts, and of alerts without a type
+	AlertTypeStorage = "storage"
+	// AlertTypeBackup is the type of backup health alerts
+	AlertTypeBackup = "backup"
+	// AlertTypeExpansionFrequency is the type of alerts about clusters expanding too often
+	AlertTypeExpansionFrequency = "expansion_frequency"
+	// AlertTypeAnomalousGrowth is the type of alerts about clusters growing abnormally fast
+	AlertTypeAnomalousGrowth = "anomalous_growth"
+	// AlertTypeWriteFailure is the type of alerts about primaries that fail the write probe
+	AlertTypeWriteFailure = "write_failure"
+	// AlertTypeResizePending is the type of alerts about PVCs stuck waiting for their filesystem resize
+	AlertTypeResizePending = "resize_pending"
+	// AlertTypeInsufficientCapacity is the type of alerts about expansions the storage backend cannot hold
+	AlertTypeInsufficientCapacity = "insufficient_capacity"
+	// AlertTypeQuotaExceeded is the type of alerts about expansions a ResourceQuota or LimitRange would reject
+	AlertTypeQuotaExceeded = "quota_exceeded"
+	// AlertTypeQuotaHeadroom is the type of alerts about namespace quotas that cannot hold the next expansion
+	AlertTypeQuotaHeadroom = "quota_headroom"
+	// AlertTypePerformanceTuning is the type of alerts about PVCs tuned for their latency
+	AlertTypePerformanceTuning = "performance_tuning"
+	// AlertTypeMaxSizeReached is the type of alerts about PVCs that need expansion but are at the maximum size
+	AlertTypeMaxSizeReached = "max_size_reached"
+	// AlertTypeRemediationIneffective is the type of alerts about remediation that did not reduce usage
+	AlertTypeRemediationIneffective = "remediation_ineffective"
+)
+
+// DetailLogHints is the alert detail holding the key error lines of the primary's
+// PostgreSQL log. Alertmanager receives it as an annotation rather than a label, as it
+// changes with the log
+const DetailLogHints = "log_hints"
+
 // Alert represents an alert to be sent
 type Alert struct {
 	ClusterName      string
 	ClusterNamespace string
-	Severity         AlertSeverity
-	Message          string
-	Details          map[string]string
-	Timestamp        time.Time
+	// Connection is the ClusterConnection of a cluster in a downstream Kubernetes
+	// cluster. Empty for local clusters
+	Connection string
+	// Source identifies the Kubernetes cluster the CNPG cluster runs in. Defaults to
+	// the alert manager's source for local clusters
+	Source identity.ClusterIdentity
+	// Type names the problem an alert reports, e.g. storage or backup. Alerts of
+	// different types about the same cluster are deduplicated separately
+	Type      string
+	Severity  AlertSeverity
+	Message   string
+	Details   map[string]string
+	Timestamp time.Time
+
+	// Policy is the name of the policy that raised the alert
+	Policy string
+	// UsagePercent and Thresholds are the usage of storage alerts and the thresholds
+	// it was evaluated against
+	UsagePercent float64
+	Thresholds   cnpgv1alpha1.ThresholdsConfig
+	// LastBackup is when the last successful backup of a backup alert's cluster completed
+	LastBackup *time.Time
+	// Ownership tells receivers who owns the cluster and what to do
+	Ownership cnpgv1alpha1.OwnershipMetadata
 }
 
 // AlertManager handles sending alerts through various channels
@@ -63,22 +119,95 @@ type AlertManager struct {
 	channels        []cnpgv1alpha1.AlertChannel
 	suppressionMap  map[string]time.Time
 	suppressionLock sync.RWMutex
+
+	// clock is the time alert suppression is checked against
+	clock clock.Clock
+
+	// source identifies the manager's own Kubernetes cluster
+	source identity.ClusterIdentity
+
+	// secrets caches channel secrets between sends. Secrets are read on every send when nil
+	secrets *SecretCache
+
+	// channelStatuses tracks the delivery health of each channel
+	channelStatuses map[channelID]*cnpgv1alpha1.AlertChannelStatus
+	statusLock      sync.Mutex
+
+	// tickets tracks the tickets ticketing channels opened. A nil entry means no
+	// ticket is open; missing entries are looked up in the ticketing system
+	tickets    map[ticketID]*openTicket
+	ticketLock sync.Mutex
+
+	// quiet is the window warnings are held in, parsed from quietConfig. held are the
+	// warnings waiting for the digest, by fingerprint
+	quietConfig *cnpgv1alpha1.QuietHoursConfig
+	quiet       *quietWindow
+	quietErr    error
+	held        map[string]*Alert
+	quietLock   sync.Mutex
+
+	// summary is the schedule of the policy's alert summary, parsed from summaryConfig
+	summaryConfig *cnpgv1alpha1.AlertSummaryConfig
+	summary       *summarySchedule
+	summaryErr    error
+	summaryLock   sync.Mutex
+
+	// staticLabels and staticAnnotations are added to every Alertmanager alert
+	staticLabels      map[string]string
+	staticAnnotations map[string]string
+	staticLock        sync.RWMutex
+
+	// threads tracks the Slack threads bot-token channels started for alerts.
+	// slackAPIURL is the Web API they post to
+	threads     map[threadID]*slackThread
+	threadLock  sync.Mutex
+	slackAPIURL string
 }
 
 // NewAlertManager creates a new alert manager
 func NewAlertManager(c client.Client, channels []cnpgv1alpha1.AlertChannel) *AlertManager {
 	return &AlertManager{
-		client:         c,
-		httpClient:     &http.Client{Timeout: 30 * time.Second},
-		channels:       channels,
-		suppressionMap: make(map[string]time.Time),
+		client:          c,
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		channels:        channels,
+		suppressionMap:  make(map[string]time.Time),
+		clock:           clock.Real,
+		channelStatuses: make(map[channelID]*cnpgv1alpha1.AlertChannelStatus),
+		tickets:         make(map[ticketID]*openTicket),
+		held:            make(map[string]*Alert),
+		threads:         make(map[threadID]*slackThread),
+		slackAPIURL:     defaultSlackAPIURL,
 	}
 }
 
+// SetClock sets the clock alert suppression is checked against
+func (m *AlertManager) SetClock(c clock.Clock) {
+	m.clock = clock.OrReal(c)
+}
+
+// SetSource sets the identity added to alerts about clusters in the manager's own
+// Kubernetes cluster
+func (m *AlertManager) SetSource(source identity.ClusterIdentity) {
+	m.source = source
+}
+
+// SetSecretCache makes the alert manager read channel secrets through cache
+func (m *AlertManager) SetSecretCache(cache *SecretCache) {
+	m.secrets = cache
+}
+
 // SendAlert sends an alert through all configured channels
-func (m *AlertManager) SendAlert(ctx context.Context, alert *Alert) error {
+func (m *AlertManager) SendAlert(ctx context.Context, alert *Alert) (err error) {
+	ctx, span := tracing.Start(ctx, "alerting.SendAlert",
+		append(tracing.Cluster(alert.ClusterName, alert.ClusterNamespace),
+			attribute.String("alert.severity", string(alert.Severity)))...)
+	defer func() { tracing.End(span, err) }()
 	logger := log.FromContext(ctx)
 
+	if alert.Connection == "" && alert.Source.IsZero() {
+		alert.Source = m.source
+	}
+
 	// Check if alert is suppressed
 	if m.isSuppressed(alert) {
 		logger.V(1).Info("Alert suppressed", "cluster", alert.ClusterName, "severity", alert.Severity)
@@ -86,22 +215,39 @@ func (m *AlertManager) SendAlert(ctx context.Context, alert *Alert) error {
 		return nil
 	}
 
+	// Warnings left to the summary, or raised during quiet hours, are only sent to
+	// ticketing channels
+	held := m.summarizes(ctx, alert) || m.holdDuringQuietHours(ctx, alert)
+
 	var lastErr error
 	sentCount := 0
 
 	for _, channel := range m.channels {
+		if !acceptsSeverity(channel, alert.Severity) {
+			continue
+		}
+		if isTicketChannel(channel) && isAggregate(alert) || !isTicketChannel(channel) && held {
+			continue
+		}
+		channelCtx, channelSpan := tracing.Start(ctx, "alerting.Send",
+			attribute.String("alert.channel", string(channel.Type)))
 		var err error
 		switch channel.Type {
 		case cnpgv1alpha1.AlertChannelTypeAlertmanager:
-			err = m.sendToAlertmanager(ctx, alert, channel)
+			err = m.sendToAlertmanager(channelCtx, alert, channel)
 		case cnpgv1alpha1.AlertChannelTypeSlack:
-			err = m.sendToSlack(ctx, alert, channel)
+			err = m.sendToSlack(channelCtx, alert, channel)
 		case cnpgv1alpha1.AlertChannelTypePagerDuty:
-			err = m.sendToPagerDuty(ctx, alert, channel)
+			err = m.sendToPagerDuty(channelCtx, alert, channel)
+		case cnpgv1alpha1.AlertChannelTypeServiceNow, cnpgv1alpha1.AlertChannelTypeJira:
+			err = m.sendToTicket(channelCtx, alert, channel)
 		default:
 			logger.Info("Unknown alert channel type", "type", channel.Type)
+			channelSpan.End()
 			continue
 		}
+		tracing.End(channelSpan, err)
+		m.recordDelivery(channel, err)
 
 		if err != nil {
 			logger.Error(err, "Failed to send alert", "channel", channel.Type)
@@ -128,29 +274,16 @@ func (m *AlertManager) sendToAlertmanager(ctx context.Context, alert *Alert, cha
 		return fmt.Errorf("alertmanager endpoint not configured")
 	}
 
+	summary, description := alertText(ctx, channel, alert,
+		alert.Message, fmt.Sprintf("Storage alert for CNPG cluster %s", alertKey(alert)))
 	alertPayload := []map[string]interface{}{
 		{
-			"labels": map[string]string{
-				"alertname": "CNPGStorageAlert",
-				"cluster":   alert.ClusterName,
-				"namespace": alert.ClusterNamespace,
-				"severity":  string(alert.Severity),
-			},
-			"annotations": map[string]string{
-				"summary":     alert.Message,
-				"description": fmt.Sprintf("Storage alert for CNPG cluster %s/%s", alert.ClusterNamespace, alert.ClusterName),
-			},
+			"labels":       m.alertmanagerLabels(alert),
+			"annotations":  m.alertmanagerAnnotations(alert, summary, description),
 			"generatorURL": fmt.Sprintf("http://cnpg-storage-manager/clusters/%s/%s", alert.ClusterNamespace, alert.ClusterName),
 		},
 	}
 
-	// Add custom details to labels
-	if labels, ok := alertPayload[0]["labels"].(map[string]string); ok {
-		for k, v := range alert.Details {
-			labels[k] = v
-		}
-	}
-
 	body, err := json.Marshal(alertPayload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal alertmanager payload: %w", err)
@@ -183,35 +316,18 @@ func (m *AlertManager) sendToAlertmanager(ctx context.Context, alert *Alert, cha
 
 // sendToSlack sends an alert to Slack
 func (m *AlertManager) sendToSlack(ctx context.Context, alert *Alert, channel cnpgv1alpha1.AlertChannel) error {
+	if channel.BotTokenSecret != "" {
+		return m.sendToSlackBot(ctx, alert, channel)
+	}
+
 	// Get webhook URL from secret
-	webhookURL, err := m.getSecretValue(ctx, channel.WebhookSecret, "webhook-url")
+	webhookURL, err := m.getSecretValue(ctx, channel.WebhookSecret, slackWebhookKey)
 	if err != nil {
 		return fmt.Errorf("failed to get slack webhook URL: %w", err)
 	}
 
-	// Build Slack message
-	color := "#36a64f" // green
-	switch alert.Severity {
-	case AlertSeverityWarning:
-		color = "#ffcc00" // yellow
-	case AlertSeverityCritical:
-		color = "#ff6600" // orange
-	case AlertSeverityEmergency:
-		color = "#ff0000" // red
-	}
-
-	payload := map[string]interface{}{
-		"channel": channel.Channel,
-		"attachments": []map[string]interface{}{
-			{
-				"color":  color,
-				"title":  fmt.Sprintf("CNPG Storage Alert - %s", alert.Severity),
-				"text":   alert.Message,
-				"fields": buildSlackFields(alert),
-				"ts":     alert.Timestamp.Unix(),
-			},
-		},
-	}
+	payload := slackPayload(ctx, alert, channel)
+	payload["channel"] = channel.Channel
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -237,10 +353,36 @@ func (m *AlertManager) sendToSlack(ctx context.Context, alert *Alert, channel cn
 	return nil
 }
 
+// slackPayload builds the Slack message of an alert, without its channel
+func slackPayload(ctx context.Context, alert *Alert, channel cnpgv1alpha1.AlertChannel) map[string]interface{} {
+	color := "#36a64f" // green
+	switch alert.Severity {
+	case AlertSeverityWarning:
+		color = "#ffcc00" // yellow
+	case AlertSeverityCritical:
+		color = "#ff6600" // orange
+	case AlertSeverityEmergency:
+		color = "#ff0000" // red
+	}
+
+	title, text := alertText(ctx, channel, alert, fmt.Sprintf("CNPG Storage Alert - %s", alert.Severity), slackText(alert))
+	return map[string]interface{}{
+		"attachments": []map[string]interface{}{
+			{
+				"color":  color,
+				"title":  title,
+				"text":   text,
+				"fields": buildSlackFields(alert),
+				"ts":     alert.Timestamp.Unix(),
+			},
+		},
+	}
+}
+
 // sendToPagerDuty sends an alert to PagerDuty
 func (m *AlertManager) sendToPagerDuty(ctx context.Context, alert *Alert, channel cnpgv1alpha1.AlertChannel) error {
 	// Get routing key from secret
-	routingKey, err := m.getSecretValue(ctx, channel.RoutingKeySecret, "routing-key")
+	routingKey, err := m.getSecretValue(ctx, channel.RoutingKeySecret, pagerDutyRoutingKey)
 	if err != nil {
 		return fmt.Errorf("failed to get pagerduty routing key: %w", err)
 	}
@@ -256,25 +398,45 @@ func (m *AlertManager) sendToPagerDuty(ctx context.Context, alert *Alert, channe
 		pdSeverity = "critical"
 	}
 
+	summary, text := alertText(ctx, channel, alert, alert.Message, "")
+	customDetails := map[string]interface{}{
+		"cluster_name":      alert.ClusterName,
+		"cluster_namespace": alert.ClusterNamespace,
+		"severity":          string(alert.Severity),
+		"alert_type":        alertType(alert),
+		"source_cluster":    alert.Source.Labels(),
+		"details":           alert.Details,
+	}
+	if text != "" {
+		customDetails["body"] = text
+	}
+	for key, value := range map[string]string{
+		"owner_team":  alert.Ownership.OwnerTeam,
+		"runbook_url": alert.Ownership.RunbookURL,
+		"escalation":  alert.Ownership.Escalation,
+	} {
+		if value != "" {
+			customDetails[key] = value
+		}
+	}
+
 	payload := map[string]interface{}{
 		"routing_key":  routingKey,
 		"event_action": "trigger",
-		"dedup_key":    fmt.Sprintf("cnpg-storage-%s-%s", alert.ClusterNamespace, alert.ClusterName),
+		"dedup_key":    dedupKey(alert),
 		"payload": map[string]interface{}{
-			"summary":   alert.Message,
-			"severity":  pdSeverity,
-			"source":    fmt.Sprintf("%s/%s", alert.ClusterNamespace, alert.ClusterName),
-			"component": "cnpg-storage-manager",
-			"group":     "storage",
-			"class":     "storage-alert",
-			"custom_details": map[string]interface{}{
-				"cluster_name":      alert.ClusterName,
-				"cluster_namespace": alert.ClusterNamespace,
-				"severity":          string(alert.Severity),
-				"details":           alert.Details,
-			},
+			"summary":        summary,
+			"severity":       pdSeverity,
+			"source":         alertKey(alert),
+			"component":      "cnpg-storage-manager",
+			"group":          "storage",
+			"class":          "storage-alert",
+			"custom_details": customDetails,
 		},
 	}
+	if alert.Ownership.RunbookURL != "" {
+		payload["links"] = []map[string]string{{"href": alert.Ownership.RunbookURL, "text": "Runbook"}}
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -300,46 +462,56 @@ func (m *AlertManager) sendToPagerDuty(ctx context.Context, alert *Alert, channe
 	return nil
 }
 
-// getSecretValue retrieves a value from a Kubernetes secret
+// getSecretValue retrieves a value from a Kubernetes secret, through the secret
+// cache when one is set
 func (m *AlertManager) getSecretValue(ctx context.Context, secretName, key string) (string, error) {
 	if secretName == "" {
 		return "", fmt.Errorf("secret name is empty")
 	}
 
-	// Parse namespace/name if provided
-	namespace := "default"
-	name := secretName
-	if idx := bytes.IndexByte([]byte(secretName), '/'); idx != -1 {
-		namespace = secretName[:idx]
-		name = secretName[idx+1:]
-	}
-
-	var secret corev1.Secret
-	if err := m.client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &secret); err != nil {
-		return "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	secretRef := secretKey(secretName)
+	var data map[string][]byte
+	if m.secrets != nil {
+		cached, err := m.secrets.Get(ctx, secretRef)
+		if err != nil {
+			return "", fmt.Errorf("failed to get secret %s: %w", secretRef, err)
+		}
+		data = cached
+	} else {
+		var secret corev1.Secret
+		if err := m.client.Get(ctx, secretRef, &secret); err != nil {
+			return "", fmt.Errorf("failed to get secret %s: %w", secretRef, err)
+		}
+		data = secret.Data
 	}
 
-	value, ok := secret.Data[key]
+	value, ok := data[key]
 	if !ok {
-		return "", fmt.Errorf("key %s not found in secret %s/%s", key, namespace, name)
+		return "", fmt.Errorf("key %s not found in secret %s", key, secretRef)
 	}
 
 	return string(value), nil
 }
 
+// IsSuppressed returns true when an alert would be dropped as a duplicate of one sent
+// recently, so callers can skip collecting details that are costly to gather
+func (m *AlertManager) IsSuppressed(alert *Alert) bool {
+	return m.isSuppressed(alert)
+}
+
 // isSuppressed checks if an alert should be suppressed
 func (m *AlertManager) isSuppressed(alert *Alert) bool {
 	m.suppressionLock.RLock()
 	defer m.suppressionLock.RUnlock()
 
-	key := fmt.Sprintf("%s/%s/%s", alert.ClusterNamespace, alert.ClusterName, alert.Severity)
+	key := fmt.Sprintf("%s/%s", fingerprint(alert), alert.Severity)
 	lastSent, ok := m.suppressionMap[key]
 	if !ok {
 		return false
 	}
 
 	// Suppress if sent within the last 5 minutes
-	return time.Since(lastSent) < 5*time.Minute
+	return m.clock.Since(lastSent) < 5*time.Minute
 }
 
 // addSuppression adds an alert to the suppression map
@@ -347,8 +519,49 @@ func (m *AlertManager) addSuppression(alert *Alert) {
 	m.suppressionLock.Lock()
 	defer m.suppressionLock.Unlock()
 
-	key := fmt.Sprintf("%s/%s/%s", alert.ClusterNamespace, alert.ClusterName, alert.Severity)
-	m.suppressionMap[key] = time.Now()
+	key := fmt.Sprintf("%s/%s", fingerprint(alert), alert.Severity)
+	m.suppressionMap[key] = m.clock.Now()
+}
+
+// alertKey identifies the cluster an alert is about: namespace/name, prefixed with
+// the connection for clusters in downstream Kubernetes clusters
+func alertKey(alert *Alert) string {
+	if alert.Connection != "" {
+		return fmt.Sprintf("%s/%s/%s", alert.Connection, alert.ClusterNamespace, alert.ClusterName)
+	}
+	return fmt.Sprintf("%s/%s", alert.ClusterNamespace, alert.ClusterName)
+}
+
+// alertType returns the type of an alert, defaulting to storage
+func alertType(alert *Alert) string {
+	if alert.Type == "" {
+		return AlertTypeStorage
+	}
+	return alert.Type
+}
+
+// fingerprint identifies the problem an alert reports: the cluster and the alert type
+func fingerprint(alert *Alert) string {
+	return fmt.Sprintf("%s/%s", alertKey(alert), alertType(alert))
+}
+
+// dedupKey identifies the problem an alert reports in systems that deduplicate
+// alerts, such as PagerDuty incidents and tickets
+func dedupKey(alert *Alert) string {
+	return "cnpg-storage-" + strings.ReplaceAll(fingerprint(alert), "/", "-")
+}
+
+// clearFingerprint clears the suppression of every severity of the problem an alert reports
+func (m *AlertManager) clearFingerprint(alert *Alert) {
+	m.suppressionLock.Lock()
+	defer m.suppressionLock.Unlock()
+
+	prefix := fingerprint(alert) + "/"
+	for key := range m.suppressionMap {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.suppressionMap, key)
+		}
+	}
 }
 
 // ClearSuppression clears suppression for a specific cluster
@@ -369,7 +582,7 @@ func buildSlackFields(alert *Alert) []map[string]interface{} {
 	fields := []map[string]interface{}{
 		{
 			"title": "Cluster",
-			"value": fmt.Sprintf("%s/%s", alert.ClusterNamespace, alert.ClusterName),
+			"value": alertKey(alert),
 			"short": true,
 		},
 		{
@@ -377,6 +590,33 @@ func buildSlackFields(alert *Alert) []map[string]interface{} {
 			"value": string(alert.Severity),
 			"short": true,
 		},
+		{
+			"title": "Type",
+			"value": alertType(alert),
+			"short": true,
+		},
+	}
+
+	if alert.Source.Name != "" {
+		fields = append(fields, map[string]interface{}{
+			"title": "Source Cluster",
+			"value": alert.Source.Name,
+			"short": true,
+		})
+	}
+
+	for _, owner := range []struct{ title, value string }{
+		{"Owner", alert.Ownership.OwnerTeam},
+		{"Runbook", alert.Ownership.RunbookURL},
+		{"Escalation", alert.Ownership.Escalation},
+	} {
+		if owner.value != "" {
+			fields = append(fields, map[string]interface{}{
+				"title": owner.title,
+				"value": owner.value,
+				"short": true,
+			})
+		}
 	}
 
 	for k, v := range alert.Details {
@@ -21,15 +21,18 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	clocktesting "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/identity"
 )
 
 const (
@@ -78,6 +81,37 @@ func TestAlertManager_Suppression(t *testing.T) {
 		t.Error("expected different severity to not be suppressed")
 	}
 
+	// A different problem with the same severity should not be suppressed
+	backupAlert := &Alert{
+		ClusterName:      testClusterName,
+		ClusterNamespace: "default",
+		Type:             AlertTypeBackup,
+		Severity:         AlertSeverityWarning,
+		Message:          "Backup alert",
+		Timestamp:        time.Now(),
+	}
+	if manager.isSuppressed(backupAlert) {
+		t.Error("expected a backup alert to not be suppressed by a storage alert")
+	}
+	explicitStorage := *alert
+	explicitStorage.Type = AlertTypeStorage
+	if !manager.isSuppressed(&explicitStorage) {
+		t.Error("expected an untyped alert to count as a storage alert")
+	}
+
+	// The same cluster name in a downstream Kubernetes cluster is a different cluster
+	remote := &Alert{
+		ClusterName:      testClusterName,
+		ClusterNamespace: "default",
+		Connection:       "edge-1",
+		Severity:         AlertSeverityWarning,
+		Message:          "Test alert",
+		Timestamp:        time.Now(),
+	}
+	if manager.isSuppressed(remote) {
+		t.Error("expected alert for a remote cluster to not be suppressed by a local one")
+	}
+
 	// Clear suppression
 	manager.ClearSuppression("default", testClusterName)
 	if manager.isSuppressed(alert) {
@@ -85,6 +119,24 @@ func TestAlertManager_Suppression(t *testing.T) {
 	}
 }
 
+func TestAlertManager_SuppressionExpires(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	manager := NewAlertManager(fake.NewClientBuilder().Build(), nil)
+	manager.SetClock(fakeClock)
+
+	alert := &Alert{ClusterName: testClusterName, ClusterNamespace: "default", Severity: AlertSeverityWarning}
+	manager.addSuppression(alert)
+
+	fakeClock.SetTime(fakeClock.Now().Add(4 * time.Minute))
+	if !manager.isSuppressed(alert) {
+		t.Error("expected alert to be suppressed within 5 minutes")
+	}
+	fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+	if manager.isSuppressed(alert) {
+		t.Error("expected suppression to expire after 5 minutes")
+	}
+}
+
 func TestAlertManager_AlertmanagerPayload(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
@@ -147,6 +199,18 @@ func TestAlertManager_AlertmanagerPayload(t *testing.T) {
 	if labels["severity"] != "critical" {
 		t.Errorf("expected severity critical, got %v", labels["severity"])
 	}
+	if _, ok := labels["connection"]; ok {
+		t.Error("expected no connection label for a local cluster")
+	}
+
+	alert.Connection = "edge-1"
+	if err := manager.sendToAlertmanager(context.Background(), alert, channels[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	labels = receivedPayload[0]["labels"].(map[string]interface{})
+	if labels["connection"] != "edge-1" {
+		t.Errorf("expected connection label edge-1, got %v", labels["connection"])
+	}
 }
 
 func TestAlertManager_SlackPayload(t *testing.T) {
@@ -289,3 +353,221 @@ func TestBuildSlackFields(t *testing.T) {
 		t.Error("expected to find Cluster field")
 	}
 }
+
+func TestAlertManager_SourceIdentity(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var receivedPayload []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&receivedPayload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	channels := []cnpgv1alpha1.AlertChannel{
+		{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: server.URL},
+	}
+	manager := NewAlertManager(client, channels)
+	manager.SetSource(identity.ClusterIdentity{ID: "local", Name: "rancher-mgmt"})
+
+	tests := []struct {
+		name       string
+		alert      *Alert
+		wantID     interface{}
+		wantName   interface{}
+		connection interface{}
+	}{
+		{
+			name:     "local cluster gets the manager's identity",
+			alert:    &Alert{ClusterName: "pg-local", ClusterNamespace: "default", Severity: AlertSeverityWarning},
+			wantID:   "local",
+			wantName: "rancher-mgmt",
+		},
+		{
+			name: "remote cluster keeps its own identity",
+			alert: &Alert{
+				ClusterName:      "pg-edge",
+				ClusterNamespace: "default",
+				Connection:       "edge-1",
+				Source:           identity.ClusterIdentity{ID: "c-m-abc12", Name: "edge-prod-1"},
+				Severity:         AlertSeverityWarning,
+			},
+			wantID:     "c-m-abc12",
+			wantName:   "edge-prod-1",
+			connection: "edge-1",
+		},
+		{
+			name: "remote cluster without an identity is not labelled as local",
+			alert: &Alert{
+				ClusterName:      "pg-other",
+				ClusterNamespace: "default",
+				Connection:       "edge-2",
+				Severity:         AlertSeverityWarning,
+			},
+			connection: "edge-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := manager.SendAlert(context.Background(), tt.alert); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			labels := receivedPayload[0]["labels"].(map[string]interface{})
+			if labels["source_cluster_id"] != tt.wantID {
+				t.Errorf("expected source_cluster_id %v, got %v", tt.wantID, labels["source_cluster_id"])
+			}
+			if labels["source_cluster_name"] != tt.wantName {
+				t.Errorf("expected source_cluster_name %v, got %v", tt.wantName, labels["source_cluster_name"])
+			}
+			if labels["connection"] != tt.connection {
+				t.Errorf("expected connection %v, got %v", tt.connection, labels["connection"])
+			}
+		})
+	}
+}
+
+func TestAlertManager_PagerDutyDedupKey(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	dedupKeys := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		dedupKeys[payload["dedup_key"].(string)] = true
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pagerduty-key", Namespace: "default"},
+		Data:       map[string][]byte{"routing-key": []byte("test-routing-key")},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(secret).Build()
+	channel := cnpgv1alpha1.AlertChannel{
+		Type:             cnpgv1alpha1.AlertChannelTypePagerDuty,
+		RoutingKeySecret: "default/pagerduty-key",
+	}
+	manager := NewAlertManager(client, []cnpgv1alpha1.AlertChannel{channel})
+	manager.httpClient = &http.Client{Transport: rewriteTransport{target: server.URL}}
+
+	for _, alertType := range []string{AlertTypeStorage, AlertTypeBackup} {
+		alert := &Alert{
+			ClusterName:      testClusterName,
+			ClusterNamespace: "default",
+			Type:             alertType,
+			Severity:         AlertSeverityCritical,
+			Message:          "Test alert",
+			Timestamp:        time.Now(),
+		}
+		if err := manager.sendToPagerDuty(context.Background(), alert, channel); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for _, want := range []string{
+		"cnpg-storage-default-" + testClusterName + "-storage",
+		"cnpg-storage-default-" + testClusterName + "-backup",
+	} {
+		if !dedupKeys[want] {
+			t.Errorf("expected dedup key %s, got %v", want, dedupKeys)
+		}
+	}
+}
+
+// rewriteTransport sends every request to target, for endpoints that are not configurable
+type rewriteTransport struct {
+	target string
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(t.target)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestAlertManager_Ownership(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	payloads := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		payloads[r.URL.Path] = payload
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pagerduty-key", Namespace: "default"},
+		Data:       map[string][]byte{"routing-key": []byte("test-routing-key")},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(secret).Build()
+	alertmanager := cnpgv1alpha1.AlertChannel{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: server.URL}
+	pagerDuty := cnpgv1alpha1.AlertChannel{
+		Type:             cnpgv1alpha1.AlertChannelTypePagerDuty,
+		RoutingKeySecret: "default/pagerduty-key",
+	}
+	manager := NewAlertManager(client, []cnpgv1alpha1.AlertChannel{alertmanager, pagerDuty})
+	manager.httpClient = &http.Client{Transport: rewriteTransport{target: server.URL}}
+
+	alert := &Alert{
+		ClusterName:      testClusterName,
+		ClusterNamespace: "default",
+		Severity:         AlertSeverityCritical,
+		Message:          "Test alert",
+		Ownership: cnpgv1alpha1.OwnershipMetadata{
+			OwnerTeam:  "payments",
+			RunbookURL: "https://runbooks.example.com/payments-db",
+			Escalation: "payments-primary",
+		},
+		Timestamp: time.Now(),
+	}
+	if err := manager.SendAlert(context.Background(), alert); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	amAlert := payloads["/api/v2/alerts"].([]interface{})[0].(map[string]interface{})
+	if labels := amAlert["labels"].(map[string]interface{}); labels["team"] != "payments" {
+		t.Errorf("expected team label payments, got %v", labels["team"])
+	}
+	annotations := amAlert["annotations"].(map[string]interface{})
+	if annotations["runbook_url"] != alert.Ownership.RunbookURL || annotations["escalation"] != "payments-primary" {
+		t.Errorf("expected runbook_url and escalation annotations, got %v", annotations)
+	}
+
+	pdEvent := payloads["/v2/enqueue"].(map[string]interface{})
+	details := pdEvent["payload"].(map[string]interface{})["custom_details"].(map[string]interface{})
+	if details["owner_team"] != "payments" || details["runbook_url"] != alert.Ownership.RunbookURL ||
+		details["escalation"] != "payments-primary" {
+		t.Errorf("expected ownership in custom details, got %v", details)
+	}
+	links := pdEvent["links"].([]interface{})
+	if link := links[0].(map[string]interface{}); link["href"] != alert.Ownership.RunbookURL {
+		t.Errorf("expected a runbook link, got %v", links)
+	}
+
+	fields := buildSlackFields(alert)
+	titles := map[string]interface{}{}
+	for _, field := range fields {
+		titles[field["title"].(string)] = field["value"]
+	}
+	if titles["Owner"] != "payments" || titles["Runbook"] != alert.Ownership.RunbookURL ||
+		titles["Escalation"] != "payments-primary" {
+		t.Errorf("expected ownership slack fields, got %v", titles)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// channelID identifies an alert channel across channel list updates
+type channelID struct {
+	channelType cnpgv1alpha1.AlertChannelType
+	target      string
+}
+
+// channelTarget returns what identifies a channel of its type
+func channelTarget(channel cnpgv1alpha1.AlertChannel) string {
+	if ref, _ := channelSecret(channel); ref != "" {
+		return ref
+	}
+	return channel.Endpoint
+}
+
+func idOf(channel cnpgv1alpha1.AlertChannel) channelID {
+	return channelID{channelType: channel.Type, target: channelTarget(channel)}
+}
+
+// SeedChannelStatuses restores delivery statuses persisted by an earlier alert
+// manager, so counts survive operator restarts. Channels with a status already
+// tracked are left unchanged.
+func (m *AlertManager) SeedChannelStatuses(statuses []cnpgv1alpha1.AlertChannelStatus) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+
+	for _, status := range statuses {
+		id := channelID{channelType: status.Type, target: status.Target}
+		if _, ok := m.channelStatuses[id]; !ok {
+			m.channelStatuses[id] = status.DeepCopy()
+		}
+	}
+}
+
+// ChannelStatuses returns the delivery status of every configured channel, in
+// channel order
+func (m *AlertManager) ChannelStatuses() []cnpgv1alpha1.AlertChannelStatus {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+
+	statuses := make([]cnpgv1alpha1.AlertChannelStatus, 0, len(m.channels))
+	for _, channel := range m.channels {
+		if status, ok := m.channelStatuses[idOf(channel)]; ok {
+			statuses = append(statuses, *status.DeepCopy())
+			continue
+		}
+		statuses = append(statuses, cnpgv1alpha1.AlertChannelStatus{
+			Type:   channel.Type,
+			Target: channelTarget(channel),
+		})
+	}
+	return statuses
+}
+
+// recordDelivery updates the delivery status of a channel after a send
+func (m *AlertManager) recordDelivery(channel cnpgv1alpha1.AlertChannel, err error) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+
+	id := idOf(channel)
+	status, ok := m.channelStatuses[id]
+	if !ok {
+		status = &cnpgv1alpha1.AlertChannelStatus{Type: channel.Type, Target: id.target}
+		m.channelStatuses[id] = status
+	}
+
+	now := metav1.NewTime(time.Now())
+	if err != nil {
+		status.FailureCount++
+		status.ConsecutiveFailures++
+		status.LastFailure = &now
+		status.LastError = err.Error()
+		return
+	}
+	status.SuccessCount++
+	status.ConsecutiveFailures = 0
+	status.LastSuccess = &now
+}
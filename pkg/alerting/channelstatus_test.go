@@ -0,0 +1,84 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestAlertManager_ChannelStatuses(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	channels := []cnpgv1alpha1.AlertChannel{
+		{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: healthy.URL},
+		{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: broken.URL},
+	}
+	manager := NewAlertManager(fake.NewClientBuilder().WithScheme(scheme).Build(), channels)
+	manager.SeedChannelStatuses([]cnpgv1alpha1.AlertChannelStatus{
+		{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Target: healthy.URL, SuccessCount: 4},
+	})
+
+	for _, cluster := range []string{"pg-a", "pg-b"} {
+		alert := &Alert{
+			ClusterName:      cluster,
+			ClusterNamespace: "default",
+			Severity:         AlertSeverityWarning,
+			Message:          "Storage usage high",
+			Timestamp:        time.Now(),
+		}
+		if err := manager.SendAlert(context.Background(), alert); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	statuses := manager.ChannelStatuses()
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 channel statuses, got %d", len(statuses))
+	}
+	if statuses[0].SuccessCount != 6 || statuses[0].ConsecutiveFailures != 0 || statuses[0].LastSuccess == nil {
+		t.Errorf("expected the healthy channel to add to its seeded count, got %+v", statuses[0])
+	}
+	if statuses[1].FailureCount != 2 || statuses[1].ConsecutiveFailures != 2 || statuses[1].LastError == "" {
+		t.Errorf("expected the broken channel to report two failures, got %+v", statuses[1])
+	}
+
+	// Channels removed from the policy are no longer reported
+	manager.UpdateChannels(channels[:1])
+	if statuses := manager.ChannelStatuses(); len(statuses) != 1 || statuses[0].Target != healthy.URL {
+		t.Errorf("expected only the remaining channel, got %+v", statuses)
+	}
+}
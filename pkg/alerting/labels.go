@@ -0,0 +1,129 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"maps"
+	"strings"
+)
+
+// SetStaticLabels sets the labels and annotations added to every Alertmanager alert
+func (m *AlertManager) SetStaticLabels(labels, annotations map[string]string) {
+	m.staticLock.Lock()
+	defer m.staticLock.Unlock()
+	m.staticLabels = maps.Clone(labels)
+	m.staticAnnotations = maps.Clone(annotations)
+}
+
+// alertmanagerLabels returns the labels of an alert sent to Alertmanager. The policy's
+// static labels are overridden by the alert details, and both by the labels the manager
+// sets itself, so a detail or static label can never change how an alert is identified
+func (m *AlertManager) alertmanagerLabels(alert *Alert) map[string]string {
+	labels := make(map[string]string)
+
+	m.staticLock.RLock()
+	for k, v := range m.staticLabels {
+		setLabel(labels, k, v)
+	}
+	m.staticLock.RUnlock()
+
+	for k, v := range alert.Details {
+		if k != DetailLogHints {
+			setLabel(labels, k, v)
+		}
+	}
+	if alert.Connection != "" {
+		labels["connection"] = alert.Connection
+	}
+	for k, v := range alert.Source.Labels() {
+		labels[k] = v
+	}
+	if alert.Ownership.OwnerTeam != "" {
+		labels["team"] = alert.Ownership.OwnerTeam
+	}
+
+	labels["alertname"] = "CNPGStorageAlert"
+	labels["cluster"] = alert.ClusterName
+	labels["namespace"] = alert.ClusterNamespace
+	labels["severity"] = string(alert.Severity)
+	labels["alert_type"] = alertType(alert)
+	return labels
+}
+
+// alertmanagerAnnotations returns the annotations of an alert sent to Alertmanager,
+// the policy's static annotations under the ones the manager sets itself
+func (m *AlertManager) alertmanagerAnnotations(alert *Alert, summary, description string) map[string]string {
+	annotations := make(map[string]string)
+
+	m.staticLock.RLock()
+	for k, v := range m.staticAnnotations {
+		if v != "" {
+			annotations[k] = v
+		}
+	}
+	m.staticLock.RUnlock()
+
+	annotations["summary"] = summary
+	annotations["description"] = description
+	if alert.Ownership.RunbookURL != "" {
+		annotations["runbook_url"] = alert.Ownership.RunbookURL
+	}
+	if alert.Ownership.Escalation != "" {
+		annotations["escalation"] = alert.Ownership.Escalation
+	}
+	if hints := alert.Details[DetailLogHints]; hints != "" {
+		annotations[DetailLogHints] = hints
+	}
+	return annotations
+}
+
+// setLabel sets a label under a valid Prometheus label name. Empty values are left out:
+// Alertmanager treats them as absent labels
+func setLabel(labels map[string]string, name, value string) {
+	name = sanitizeLabelName(name)
+	if name == "" || value == "" {
+		return
+	}
+	labels[name] = value
+}
+
+// sanitizeLabelName turns name into a valid Prometheus label name, [a-zA-Z_][a-zA-Z0-9_]*,
+// replacing invalid characters with underscores. Names reserved for internal use, those
+// starting with "__", are returned empty
+func sanitizeLabelName(name string) string {
+	if name == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	if name[0] >= '0' && name[0] <= '9' {
+		b.WriteByte('_')
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	sanitized := b.String()
+	if strings.HasPrefix(sanitized, "__") {
+		return ""
+	}
+	return sanitized
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestSanitizeLabelName(t *testing.T) {
+	tests := map[string]string{
+		"usage_percent": "usage_percent",
+		"usage-percent": "usage_percent",
+		"pvc.name":      "pvc_name",
+		"1st_pvc":       "_1st_pvc",
+		"größe":         "gr____e",
+		"__name__":      "",
+		"_-hidden":      "",
+		"":              "",
+	}
+	for name, want := range tests {
+		if got := sanitizeLabelName(name); got != want {
+			t.Errorf("sanitizeLabelName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestAlertManager_StaticLabels(t *testing.T) {
+	var received []struct {
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	channels := []cnpgv1alpha1.AlertChannel{{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: server.URL}}
+	manager := NewAlertManager(fake.NewClientBuilder().Build(), channels)
+	manager.SetStaticLabels(
+		map[string]string{"team": "storage", "environment": "prod", "severity": "info"},
+		map[string]string{"dashboard": "https://grafana.example.com/d/cnpg", "summary": "overridden"},
+	)
+
+	alert := &Alert{
+		ClusterName:      testClusterName,
+		ClusterNamespace: "default",
+		Severity:         AlertSeverityCritical,
+		Message:          "Storage usage critical",
+		Details: map[string]string{
+			"pvc.name":     "test-cluster-1",
+			"environment":  "staging",
+			"__name__":     "dropped",
+			"empty":        "",
+			DetailLogHints: "PANIC: could not write to file \"pg_wal/xlogtemp.42\": No space left on device",
+		},
+	}
+	if err := manager.sendToAlertmanager(context.Background(), alert, channels[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(received))
+	}
+
+	labels := received[0].Labels
+	wantLabels := map[string]string{
+		"alertname":   testAlertName,
+		"cluster":     testClusterName,
+		"namespace":   "default",
+		"severity":    "critical",
+		"alert_type":  AlertTypeStorage,
+		"team":        "storage",
+		"environment": "staging",
+		"pvc_name":    "test-cluster-1",
+	}
+	if len(labels) != len(wantLabels) {
+		t.Errorf("expected labels %v, got %v", wantLabels, labels)
+	}
+	for k, v := range wantLabels {
+		if labels[k] != v {
+			t.Errorf("expected label %s=%q, got %q", k, v, labels[k])
+		}
+	}
+
+	annotations := received[0].Annotations
+	if annotations["dashboard"] != "https://grafana.example.com/d/cnpg" {
+		t.Errorf("expected the static dashboard annotation, got %v", annotations)
+	}
+	if annotations["summary"] == "overridden" {
+		t.Error("expected the static annotations not to override the summary")
+	}
+	if annotations[DetailLogHints] != alert.Details[DetailLogHints] {
+		t.Errorf("expected the log hints as an annotation, got %v", annotations)
+	}
+}
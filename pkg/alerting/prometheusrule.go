@@ -0,0 +1,349 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/policy"
+)
+
+const (
+	// prometheusRuleSuffix is appended to the policy name for the generated PrometheusRule
+	prometheusRuleSuffix = "-cnpg-storage"
+
+	// defaultRuleFor is used when the policy does not set prometheusRule.for
+	defaultRuleFor = "5m"
+
+	// Threshold defaults matching the StoragePolicy CRD defaults
+	defaultWarningThreshold  = 70
+	defaultCriticalThreshold = 80
+)
+
+// PrometheusRuleGVK is the GroupVersionKind of the Prometheus Operator PrometheusRule
+var PrometheusRuleGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "PrometheusRule",
+}
+
+// ErrPrometheusRuleCRDMissing is returned when the PrometheusRule CRD is not installed
+var ErrPrometheusRuleCRDMissing = fmt.Errorf("PrometheusRule CRD (monitoring.coreos.com/v1) is not installed")
+
+// PrometheusRuleName returns the name of the PrometheusRule generated for a policy
+func PrometheusRuleName(policy *cnpgv1alpha1.StoragePolicy) string {
+	return policy.Name + prometheusRuleSuffix
+}
+
+// PrometheusRuleManager keeps a PrometheusRule per policy in sync with its thresholds
+type PrometheusRuleManager struct {
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// NewPrometheusRuleManager creates a new PrometheusRule manager
+func NewPrometheusRuleManager(c client.Client, scheme *runtime.Scheme) *PrometheusRuleManager {
+	return &PrometheusRuleManager{client: c, scheme: scheme}
+}
+
+// Sync creates, updates or deletes the policy's PrometheusRule. Clusters are the
+// clusters currently matched by the policy.
+func (m *PrometheusRuleManager) Sync(
+	ctx context.Context,
+	policy *cnpgv1alpha1.StoragePolicy,
+	clusters []cnpgv1alpha1.ClusterReference,
+) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(PrometheusRuleGVK)
+	key := client.ObjectKey{Name: PrometheusRuleName(policy), Namespace: policy.Namespace}
+	err := m.client.Get(ctx, key, existing)
+	if meta.IsNoMatchError(err) {
+		if !policy.Spec.Alerting.PrometheusRule.Enabled {
+			return nil
+		}
+		return ErrPrometheusRuleCRDMissing
+	}
+	found := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get PrometheusRule %s/%s: %w", key.Namespace, key.Name, err)
+	}
+
+	if !policy.Spec.Alerting.PrometheusRule.Enabled {
+		if !found {
+			return nil
+		}
+		if err := m.client.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PrometheusRule %s/%s: %w", key.Namespace, key.Name, err)
+		}
+		return nil
+	}
+
+	desired, err := BuildPrometheusRule(policy, clusters)
+	if err != nil {
+		return err
+	}
+	if err := controllerutil.SetControllerReference(policy, desired, m.scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference on PrometheusRule: %w", err)
+	}
+
+	if !found {
+		if err := m.client.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create PrometheusRule %s/%s: %w", key.Namespace, key.Name, err)
+		}
+		return nil
+	}
+
+	existing.SetLabels(desired.GetLabels())
+	existing.SetOwnerReferences(desired.GetOwnerReferences())
+	existing.Object["spec"] = desired.Object["spec"]
+	if err := m.client.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update PrometheusRule %s/%s: %w", key.Namespace, key.Name, err)
+	}
+	return nil
+}
+
+// BuildPrometheusRule builds the PrometheusRule mirroring the policy's usage and
+// backup thresholds for the given clusters
+func BuildPrometheusRule(
+	policy *cnpgv1alpha1.StoragePolicy,
+	clusters []cnpgv1alpha1.ClusterReference,
+) (*unstructured.Unstructured, error) {
+	cfg := policy.Spec.Alerting.PrometheusRule
+	forDuration := cfg.For
+	if forDuration == "" {
+		forDuration = defaultRuleFor
+	}
+
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "cnpg-storage-manager",
+		"cnpg.supporttools.io/policy":  policy.Name,
+	}
+	for k, v := range cfg.Labels {
+		labels[k] = v
+	}
+
+	var rules []interface{}
+	if byNamespace := clustersByNamespace(clusters); len(byNamespace) > 0 {
+		rules = buildRules(policy, byNamespace, forDuration)
+	}
+
+	groups := []interface{}{}
+	if len(rules) > 0 {
+		groups = append(groups, map[string]interface{}{
+			"name":  "cnpg-storage-manager." + policy.Namespace + "." + policy.Name,
+			"rules": rules,
+		})
+	}
+
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(PrometheusRuleGVK)
+	rule.SetName(PrometheusRuleName(policy))
+	rule.SetNamespace(policy.Namespace)
+	rule.SetLabels(labels)
+	if err := unstructured.SetNestedSlice(rule.Object, groups, "spec", "groups"); err != nil {
+		return nil, fmt.Errorf("failed to set PrometheusRule groups: %w", err)
+	}
+	return rule, nil
+}
+
+// buildRules returns the alerting rules for the policy's thresholds
+func buildRules(policy *cnpgv1alpha1.StoragePolicy, byNamespace map[string][]string, forDuration string) []interface{} {
+	thresholds := policy.Spec.Thresholds
+	warning := thresholdOrDefault(thresholds.Warning, defaultWarningThreshold)
+	critical := thresholdOrDefault(thresholds.Critical, defaultCriticalThreshold)
+
+	usage := func(threshold int32) string {
+		return joinSelectors(byNamespace, func(namespace, names string) string {
+			sel := fmt.Sprintf(`namespace=%q,persistentvolumeclaim=~"(%s)-[0-9]+(-wal)?"`, namespace, names)
+			expr := fmt.Sprintf("100 * kubelet_volume_stats_used_bytes{%s} / kubelet_volume_stats_capacity_bytes{%s} > %d",
+				sel, sel, threshold)
+			return fmt.Sprintf(`label_replace(%s, "cluster", "$1", "persistentvolumeclaim", "(%s)-[0-9]+(-wal)?")`,
+				expr, names)
+		})
+	}
+
+	rules := []interface{}{
+		alertRule(policy, "CNPGStorageUsageWarning", usage(warning), forDuration, AlertSeverityWarning, "usage",
+			fmt.Sprintf("PVC {{ $labels.namespace }}/{{ $labels.persistentvolumeclaim }} is {{ $value | humanize }}%% full "+
+				"(warning threshold %d%%)", warning)),
+		alertRule(policy, "CNPGStorageUsageCritical", usage(critical), forDuration, AlertSeverityCritical, "usage",
+			fmt.Sprintf("PVC {{ $labels.namespace }}/{{ $labels.persistentvolumeclaim }} is {{ $value | humanize }}%% full "+
+				"(critical threshold %d%%)", critical)),
+	}
+
+	if policy.Spec.SLO.Enabled {
+		rules = append(rules, burnRateRules(policy, byNamespace, forDuration)...)
+	}
+
+	backup := policy.Spec.BackupMonitoring
+	if !backup.Enabled {
+		return rules
+	}
+
+	podSelector := func(metric string) func(namespace, names string) string {
+		return func(namespace, names string) string {
+			return fmt.Sprintf(`%s{namespace=%q,pod=~"(%s)-[0-9]+"}`, metric, namespace, names)
+		}
+	}
+	withCluster := func(names, expr string) string {
+		return fmt.Sprintf(`label_replace(%s, "cluster", "$1", "pod", "(%s)-[0-9]+")`, expr, names)
+	}
+
+	if backup.MaxBackupAgeHours > 0 {
+		expr := joinSelectors(byNamespace, func(namespace, names string) string {
+			last := podSelector("cnpg_collector_last_available_backup_timestamp")(namespace, names)
+			return withCluster(names,
+				fmt.Sprintf("time() - max by (namespace, pod) (%s) > %d", last, int64(backup.MaxBackupAgeHours)*3600))
+		})
+		rules = append(rules, alertRule(policy, "CNPGBackupTooOld", expr, forDuration, AlertSeverityWarning, "backup",
+			fmt.Sprintf("Last available backup of {{ $labels.namespace }}/{{ $labels.cluster }} is older than %d hours",
+				backup.MaxBackupAgeHours)))
+	}
+
+	if backup.RequireContinuousArchiving {
+		expr := joinSelectors(byNamespace, func(namespace, names string) string {
+			failed := podSelector("cnpg_pg_stat_archiver_last_failed_time")(namespace, names)
+			archived := podSelector("cnpg_pg_stat_archiver_last_archived_time")(namespace, names)
+			return withCluster(names, fmt.Sprintf("%s > on (namespace, pod) %s", failed, archived))
+		})
+		rules = append(rules, alertRule(policy, "CNPGWALArchivingFailing", expr, forDuration, AlertSeverityCritical,
+			"backup", "WAL archiving is failing on {{ $labels.namespace }}/{{ $labels.pod }}"))
+	}
+
+	return rules
+}
+
+// burnRateRules returns multi-window, multi-burn-rate alerts on the storage SLO: a fast
+// burn spending 2% of the error budget in an hour, confirmed over 5 minutes, and a slow
+// burn spending 5% in 6 hours, confirmed over 30 minutes
+func burnRateRules(
+	policyObj *cnpgv1alpha1.StoragePolicy,
+	byNamespace map[string][]string,
+	forDuration string,
+) []interface{} {
+	objective := policy.SLOObjective(policyObj.Spec.SLO)
+	windowHours := policy.SLOWindow(policyObj.Spec.SLO).Hours()
+
+	errorRatio := func(window string) string {
+		return "(" + joinSelectors(byNamespace, func(namespace, names string) string {
+			sel := fmt.Sprintf(`namespace=%q,cluster=~"(%s)",connection=""`, namespace, names)
+			rate := func(metric string) string {
+				return fmt.Sprintf("sum by (namespace, cluster) (rate(%s{%s}[%s]))", metric, sel, window)
+			}
+			return rate("cnpg_storage_manager_storage_slo_violation_seconds_total") + " / " +
+				rate("cnpg_storage_manager_storage_slo_seconds_total")
+		}) + ")"
+	}
+	burnRate := func(budgetSpent, longHours float64, long, short string) (string, float64) {
+		factor := budgetSpent * windowHours / longHours
+		threshold := strconv.FormatFloat(factor*(1-objective), 'g', 6, 64)
+		return fmt.Sprintf("%s > %s and %s > %s", errorRatio(long), threshold, errorRatio(short), threshold), factor
+	}
+
+	fast, fastFactor := burnRate(0.02, 1, "1h", "5m")
+	slow, slowFactor := burnRate(0.05, 6, "6h", "30m")
+	return []interface{}{
+		alertRule(policyObj, "CNPGStorageSLOBurnRateFast", fast, forDuration, AlertSeverityCritical, "slo",
+			fmt.Sprintf("{{ $labels.namespace }}/{{ $labels.cluster }} is burning its storage error budget %.1fx "+
+				"too fast (2%% of the budget in an hour)", fastFactor)),
+		alertRule(policyObj, "CNPGStorageSLOBurnRateSlow", slow, forDuration, AlertSeverityWarning, "slo",
+			fmt.Sprintf("{{ $labels.namespace }}/{{ $labels.cluster }} is burning its storage error budget %.1fx "+
+				"too fast (5%% of the budget in 6 hours)", slowFactor)),
+	}
+}
+
+// alertRule builds a single Prometheus alerting rule. The policy's static alert labels
+// and annotations are added so the rules route like the manager's own alerts
+func alertRule(
+	policy *cnpgv1alpha1.StoragePolicy,
+	name, expr, forDuration string,
+	severity AlertSeverity,
+	alertType, summary string,
+) map[string]interface{} {
+	labels := make(map[string]interface{})
+	for k, v := range policy.Spec.Alerting.Labels {
+		labels[k] = v
+	}
+	labels["severity"] = string(severity)
+	labels["alert_type"] = alertType
+	labels["policy"] = policy.Name
+
+	annotations := make(map[string]interface{})
+	for k, v := range policy.Spec.Alerting.Annotations {
+		annotations[k] = v
+	}
+	annotations["summary"] = summary
+	annotations["description"] = fmt.Sprintf("Generated by cnpg-storage-manager from StoragePolicy %s/%s",
+		policy.Namespace, policy.Name)
+
+	return map[string]interface{}{
+		"alert":       name,
+		"expr":        expr,
+		"for":         forDuration,
+		"labels":      labels,
+		"annotations": annotations,
+	}
+}
+
+// clustersByNamespace groups cluster names by namespace as sorted regex alternations
+func clustersByNamespace(clusters []cnpgv1alpha1.ClusterReference) map[string][]string {
+	byNamespace := make(map[string][]string)
+	for _, cluster := range clusters {
+		byNamespace[cluster.Namespace] = append(byNamespace[cluster.Namespace], regexp.QuoteMeta(cluster.Name))
+	}
+	for namespace := range byNamespace {
+		sort.Strings(byNamespace[namespace])
+	}
+	return byNamespace
+}
+
+// joinSelectors builds one expression per namespace and combines them with "or"
+func joinSelectors(byNamespace map[string][]string, build func(namespace, names string) string) string {
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	exprs := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		exprs = append(exprs, build(namespace, strings.Join(byNamespace[namespace], "|")))
+	}
+	return strings.Join(exprs, " or ")
+}
+
+// thresholdOrDefault returns the threshold value or a default if zero
+func thresholdOrDefault(value, defaultValue int32) int32 {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
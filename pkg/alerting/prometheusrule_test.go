@@ -0,0 +1,200 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func rulePolicy(enabled bool) *cnpgv1alpha1.StoragePolicy {
+	return &cnpgv1alpha1.StoragePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "ops", UID: "policy-uid"},
+		Spec: cnpgv1alpha1.StoragePolicySpec{
+			Thresholds: cnpgv1alpha1.ThresholdsConfig{Warning: 75, Critical: 85},
+			Alerting: cnpgv1alpha1.AlertingConfig{
+				PrometheusRule: cnpgv1alpha1.PrometheusRuleConfig{
+					Enabled: enabled,
+					Labels:  map[string]string{"release": "prometheus"},
+				},
+			},
+			BackupMonitoring: cnpgv1alpha1.BackupMonitoringConfig{
+				Enabled:                    true,
+				MaxBackupAgeHours:          24,
+				RequireContinuousArchiving: true,
+			},
+		},
+	}
+}
+
+func ruleExprs(t *testing.T, rule *unstructured.Unstructured) map[string]string {
+	t.Helper()
+	groups, _, err := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	if err != nil {
+		t.Fatalf("failed to read groups: %v", err)
+	}
+	exprs := make(map[string]string)
+	for _, group := range groups {
+		rules, _, _ := unstructured.NestedSlice(group.(map[string]interface{}), "rules")
+		for _, r := range rules {
+			fields := r.(map[string]interface{})
+			exprs[fields["alert"].(string)] = fields["expr"].(string)
+		}
+	}
+	return exprs
+}
+
+func TestBuildPrometheusRule(t *testing.T) {
+	clusters := []cnpgv1alpha1.ClusterReference{
+		{Name: "pg-b", Namespace: "db"},
+		{Name: "pg-a", Namespace: "db"},
+		{Name: "pg-c", Namespace: "analytics"},
+	}
+
+	rule, err := BuildPrometheusRule(rulePolicy(true), clusters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rule.GetName() != "prod-cnpg-storage" || rule.GetNamespace() != "ops" {
+		t.Errorf("unexpected rule name %s/%s", rule.GetNamespace(), rule.GetName())
+	}
+	if rule.GetLabels()["release"] != "prometheus" {
+		t.Errorf("expected custom labels to be applied, got %v", rule.GetLabels())
+	}
+
+	exprs := ruleExprs(t, rule)
+	for _, name := range []string{
+		"CNPGStorageUsageWarning", "CNPGStorageUsageCritical", "CNPGBackupTooOld", "CNPGWALArchivingFailing",
+	} {
+		if _, ok := exprs[name]; !ok {
+			t.Errorf("expected rule %s, got %v", name, exprs)
+		}
+	}
+
+	warning := exprs["CNPGStorageUsageWarning"]
+	if !strings.Contains(warning, `namespace="db",persistentvolumeclaim=~"(pg-a|pg-b)-[0-9]+(-wal)?"`) {
+		t.Errorf("expected db clusters in warning rule, got %s", warning)
+	}
+	if !strings.Contains(warning, `namespace="analytics"`) || !strings.Contains(warning, " or ") {
+		t.Errorf("expected one selector per namespace joined with or, got %s", warning)
+	}
+	if !strings.Contains(warning, "> 75") || !strings.Contains(exprs["CNPGStorageUsageCritical"], "> 85") {
+		t.Error("expected usage rules to mirror the policy thresholds")
+	}
+	if !strings.Contains(exprs["CNPGBackupTooOld"], "> 86400") {
+		t.Errorf("expected backup age of 24h, got %s", exprs["CNPGBackupTooOld"])
+	}
+}
+
+func TestBuildPrometheusRule_SLOBurnRate(t *testing.T) {
+	policy := rulePolicy(true)
+	clusters := []cnpgv1alpha1.ClusterReference{{Name: "pg", Namespace: "db"}}
+
+	rule, err := BuildPrometheusRule(policy, clusters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := ruleExprs(t, rule)["CNPGStorageSLOBurnRateFast"]; ok {
+		t.Error("expected no burn rate rules without slo.enabled")
+	}
+
+	policy.Spec.SLO = cnpgv1alpha1.StorageSLOConfig{Enabled: true, Objective: "99.9", WindowDays: 30}
+	if rule, err = BuildPrometheusRule(policy, clusters); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exprs := ruleExprs(t, rule)
+	fast, slow := exprs["CNPGStorageSLOBurnRateFast"], exprs["CNPGStorageSLOBurnRateSlow"]
+	// A 30 day window burns 2% of the budget in an hour at 14.4x and 5% in 6 hours at 6x
+	if !strings.Contains(fast, "[1h]") || !strings.Contains(fast, "[5m]") || !strings.Contains(fast, "> 0.0144") {
+		t.Errorf("expected a 14.4x burn rate over 1h and 5m, got %s", fast)
+	}
+	if !strings.Contains(slow, "[6h]") || !strings.Contains(slow, "[30m]") || !strings.Contains(slow, "> 0.006") {
+		t.Errorf("expected a 6x burn rate over 6h and 30m, got %s", slow)
+	}
+	if !strings.Contains(fast, `namespace="db",cluster=~"(pg)",connection=""`) {
+		t.Errorf("expected the policy's clusters to be selected, got %s", fast)
+	}
+}
+
+func TestBuildPrometheusRule_NoClusters(t *testing.T) {
+	rule, err := BuildPrometheusRule(rulePolicy(true), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exprs := ruleExprs(t, rule); len(exprs) != 0 {
+		t.Errorf("expected no rules without clusters, got %v", exprs)
+	}
+}
+
+func TestPrometheusRuleManager_Sync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = cnpgv1alpha1.AddToScheme(scheme)
+
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(PrometheusRuleGVK, meta.RESTScopeNamespace)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(restMapper).Build()
+
+	manager := NewPrometheusRuleManager(c, scheme)
+	ctx := context.Background()
+	key := client.ObjectKey{Name: "prod-cnpg-storage", Namespace: "ops"}
+	clusters := []cnpgv1alpha1.ClusterReference{{Name: "pg", Namespace: "db"}}
+
+	policy := rulePolicy(true)
+	if err := manager.Sync(ctx, policy, clusters); err != nil {
+		t.Fatalf("unexpected error on create: %v", err)
+	}
+
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(PrometheusRuleGVK)
+	if err := c.Get(ctx, key, rule); err != nil {
+		t.Fatalf("expected PrometheusRule to be created: %v", err)
+	}
+	if owners := rule.GetOwnerReferences(); len(owners) != 1 || owners[0].Name != "prod" {
+		t.Errorf("expected rule to be owned by the policy, got %v", owners)
+	}
+
+	policy.Spec.Thresholds.Warning = 60
+	if err := manager.Sync(ctx, policy, clusters); err != nil {
+		t.Fatalf("unexpected error on update: %v", err)
+	}
+	if err := c.Get(ctx, key, rule); err != nil {
+		t.Fatalf("failed to get PrometheusRule: %v", err)
+	}
+	if !strings.Contains(ruleExprs(t, rule)["CNPGStorageUsageWarning"], "> 60") {
+		t.Error("expected rule to be updated with the new warning threshold")
+	}
+
+	policy.Spec.Alerting.PrometheusRule.Enabled = false
+	if err := manager.Sync(ctx, policy, clusters); err != nil {
+		t.Fatalf("unexpected error on delete: %v", err)
+	}
+	if err := c.Get(ctx, key, rule); !errors.IsNotFound(err) {
+		t.Errorf("expected PrometheusRule to be deleted, got %v", err)
+	}
+}
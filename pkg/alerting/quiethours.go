@@ -0,0 +1,211 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+// AlertTypeDigest is the type of the digest of warnings held during quiet hours
+const AlertTypeDigest = "digest"
+
+// weekdays maps the API day names to time weekdays
+var weekdays = map[cnpgv1alpha1.Weekday]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// quietWindow is a parsed daily quiet hours window
+type quietWindow struct {
+	// start and end are minutes of the day
+	start, end int
+	location   *time.Location
+	// days are the days the window starts on. Nil means every day
+	days map[time.Weekday]bool
+}
+
+// parseQuietHours parses a quiet hours configuration. It returns nil without one
+func parseQuietHours(cfg *cnpgv1alpha1.QuietHoursConfig) (*quietWindow, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	start, err := parseClock(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours start: %w", err)
+	}
+	end, err := parseClock(cfg.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours end: %w", err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("quiet hours start and end are both %s", cfg.Start)
+	}
+
+	location := time.UTC
+	if cfg.Timezone != "" {
+		if location, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid quiet hours timezone: %w", err)
+		}
+	}
+
+	window := &quietWindow{start: start, end: end, location: location}
+	for _, day := range cfg.Days {
+		weekday, ok := weekdays[day]
+		if !ok {
+			return nil, fmt.Errorf("invalid quiet hours day %q", day)
+		}
+		if window.days == nil {
+			window.days = map[time.Weekday]bool{}
+		}
+		window.days[weekday] = true
+	}
+	return window, nil
+}
+
+// parseClock parses HH:MM into minutes of the day
+func parseClock(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// contains reports whether t falls in the window. A window spanning midnight
+// belongs to the day it starts on
+func (w *quietWindow) contains(t time.Time) bool {
+	local := t.In(w.location)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+
+	switch {
+	case w.start < w.end:
+		return minute >= w.start && minute < w.end && w.startsOn(day)
+	case minute >= w.start:
+		return w.startsOn(day)
+	case minute < w.end:
+		return w.startsOn((day + 6) % 7)
+	default:
+		return false
+	}
+}
+
+func (w *quietWindow) startsOn(day time.Weekday) bool {
+	return w.days == nil || w.days[day]
+}
+
+// SetQuietHours sets the window warning alerts are held in. An invalid window holds
+// nothing and is reported by CheckQuietHours
+func (m *AlertManager) SetQuietHours(cfg *cnpgv1alpha1.QuietHoursConfig) {
+	m.quietLock.Lock()
+	defer m.quietLock.Unlock()
+
+	if reflect.DeepEqual(cfg, m.quietConfig) {
+		return
+	}
+	m.quietConfig = cfg.DeepCopy()
+	m.quiet, m.quietErr = parseQuietHours(cfg)
+}
+
+// CheckQuietHours returns the error of an invalid quiet hours configuration
+func (m *AlertManager) CheckQuietHours() error {
+	m.quietLock.Lock()
+	defer m.quietLock.Unlock()
+	return m.quietErr
+}
+
+// holdDuringQuietHours holds a warning alert raised during quiet hours for the digest,
+// replacing an earlier one about the same problem. Alerts of a higher severity drop
+// the held warning they supersede. It returns true when the alert was held
+func (m *AlertManager) holdDuringQuietHours(ctx context.Context, alert *Alert) bool {
+	m.quietLock.Lock()
+	defer m.quietLock.Unlock()
+
+	key := fingerprint(alert)
+	if alert.Severity != AlertSeverityWarning || alert.Type == AlertTypeDigest {
+		delete(m.held, key)
+		return false
+	}
+	if m.quiet == nil || !m.quiet.contains(m.clock.Now()) {
+		return false
+	}
+
+	m.held[key] = alert
+	log.FromContext(ctx).V(1).Info("Alert held for the quiet hours digest",
+		"cluster", alertKey(alert), "type", alertType(alert))
+	metrics.RecordAlertSuppressed(alert.ClusterName, alert.ClusterNamespace, "quiet_hours")
+	return true
+}
+
+// dropHeld drops the held warning about the problem an alert reports
+func (m *AlertManager) dropHeld(alert *Alert) {
+	m.quietLock.Lock()
+	defer m.quietLock.Unlock()
+	delete(m.held, fingerprint(alert))
+}
+
+// FlushDigest sends the warnings held during quiet hours as one alert about the
+// policy name/namespace, once the window has ended
+func (m *AlertManager) FlushDigest(ctx context.Context, name, namespace string) error {
+	m.quietLock.Lock()
+	if len(m.held) == 0 || (m.quiet != nil && m.quiet.contains(m.clock.Now())) {
+		m.quietLock.Unlock()
+		return nil
+	}
+	held := make([]*Alert, 0, len(m.held))
+	for _, alert := range m.held {
+		held = append(held, alert)
+	}
+	m.held = make(map[string]*Alert)
+	m.quietLock.Unlock()
+
+	sort.Slice(held, func(i, j int) bool { return fingerprint(held[i]) < fingerprint(held[j]) })
+	lines := make([]string, 0, len(held)+1)
+	lines = append(lines, fmt.Sprintf("%d warning(s) were held during quiet hours:", len(held)))
+	details := make(map[string]string, len(held))
+	for _, alert := range held {
+		lines = append(lines, fmt.Sprintf("- %s (%s): %s", alertKey(alert), alertType(alert), alert.Message))
+		details[fmt.Sprintf("%s/%s", alertKey(alert), alertType(alert))] = alert.Message
+	}
+
+	return m.SendAlert(ctx, &Alert{
+		ClusterName:      name,
+		ClusterNamespace: namespace,
+		Type:             AlertTypeDigest,
+		Severity:         AlertSeverityWarning,
+		Message:          strings.Join(lines, "\n"),
+		Details:          details,
+		Timestamp:        m.clock.Now(),
+		Policy:           name,
+	})
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestQuietWindowContains(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      cnpgv1alpha1.QuietHoursConfig
+		time     string
+		expected bool
+	}{
+		{"inside a daytime window", cnpgv1alpha1.QuietHoursConfig{Start: "12:00", End: "13:00"}, "2025-06-04T12:30:00Z", true},
+		{"end is exclusive", cnpgv1alpha1.QuietHoursConfig{Start: "12:00", End: "13:00"}, "2025-06-04T13:00:00Z", false},
+		{"before midnight", cnpgv1alpha1.QuietHoursConfig{Start: "22:00", End: "07:00"}, "2025-06-04T23:15:00Z", true},
+		{"after midnight", cnpgv1alpha1.QuietHoursConfig{Start: "22:00", End: "07:00"}, "2025-06-05T03:00:00Z", true},
+		{"outside the window", cnpgv1alpha1.QuietHoursConfig{Start: "22:00", End: "07:00"}, "2025-06-05T08:00:00Z", false},
+		{
+			name:     "timezone",
+			cfg:      cnpgv1alpha1.QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "America/New_York"},
+			time:     "2025-06-05T03:00:00Z", // 23:00 in New York
+			expected: true,
+		},
+		{
+			name:     "timezone outside the window",
+			cfg:      cnpgv1alpha1.QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "America/New_York"},
+			time:     "2025-06-05T12:00:00Z", // 08:00 in New York
+			expected: false,
+		},
+		{
+			name:     "overnight window belongs to the day it starts",
+			cfg:      cnpgv1alpha1.QuietHoursConfig{Start: "22:00", End: "07:00", Days: []cnpgv1alpha1.Weekday{"Sat"}},
+			time:     "2025-06-08T03:00:00Z", // Sunday morning
+			expected: true,
+		},
+		{
+			name:     "other days",
+			cfg:      cnpgv1alpha1.QuietHoursConfig{Start: "22:00", End: "07:00", Days: []cnpgv1alpha1.Weekday{"Sat"}},
+			time:     "2025-06-04T23:00:00Z", // Wednesday
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := parseQuietHours(&tt.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			at, _ := time.Parse(time.RFC3339, tt.time)
+			if got := window.contains(at); got != tt.expected {
+				t.Errorf("expected contains %v at %s, got %v", tt.expected, tt.time, got)
+			}
+		})
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	if window, err := parseQuietHours(nil); window != nil || err != nil {
+		t.Errorf("expected no window without a configuration, got %v, %v", window, err)
+	}
+
+	for _, cfg := range []cnpgv1alpha1.QuietHoursConfig{
+		{Start: "25:00", End: "07:00"},
+		{Start: "22:00", End: "7"},
+		{Start: "22:00", End: "22:00"},
+		{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus_Mons"},
+		{Start: "22:00", End: "07:00", Days: []cnpgv1alpha1.Weekday{"Someday"}},
+	} {
+		if _, err := parseQuietHours(&cfg); err == nil {
+			t.Errorf("expected %+v to be invalid", cfg)
+		}
+	}
+}
+
+func TestAlertManager_QuietHours(t *testing.T) {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload []map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload...)
+	}))
+	defer server.Close()
+
+	fakeClock := clocktesting.NewFakeClock(time.Date(2025, 6, 4, 23, 0, 0, 0, time.UTC))
+	manager := NewAlertManager(nil, []cnpgv1alpha1.AlertChannel{
+		{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: server.URL},
+	})
+	manager.SetClock(fakeClock)
+	manager.SetQuietHours(&cnpgv1alpha1.QuietHoursConfig{Start: "22:00", End: "07:00"})
+	ctx := context.Background()
+
+	alert := func(cluster string, severity AlertSeverity) *Alert {
+		return &Alert{
+			ClusterName:      cluster,
+			ClusterNamespace: "default",
+			Type:             AlertTypeStorage,
+			Severity:         severity,
+			Message:          cluster + " is filling up",
+			Timestamp:        fakeClock.Now(),
+		}
+	}
+
+	for _, a := range []*Alert{
+		alert("orders", AlertSeverityWarning),
+		alert("billing", AlertSeverityWarning),
+		alert("users", AlertSeverityWarning),
+		alert("events", AlertSeverityCritical),
+	} {
+		if err := manager.SendAlert(ctx, a); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(received) != 1 || received[0]["labels"].(map[string]interface{})["cluster"] != "events" {
+		t.Fatalf("expected only the critical alert during quiet hours, got %v", received)
+	}
+
+	// A resolved warning leaves the digest, and nothing is flushed before the window ends
+	if err := manager.ResolveAlert(ctx, alert("users", AlertSeverityWarning)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.FlushDigest(ctx, "production", "dba"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("expected no digest during quiet hours, got %v", received)
+	}
+
+	fakeClock.SetTime(time.Date(2025, 6, 5, 7, 5, 0, 0, time.UTC))
+	if err := manager.FlushDigest(ctx, "production", "dba"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("expected a digest after quiet hours, got %v", received)
+	}
+	labels := received[1]["labels"].(map[string]interface{})
+	if labels["alert_type"] != AlertTypeDigest || labels["cluster"] != "production" {
+		t.Errorf("expected a digest about the policy, got %v", labels)
+	}
+	summary := received[1]["annotations"].(map[string]interface{})["summary"].(string)
+	if !strings.HasPrefix(summary, "2 warning(s) were held during quiet hours:\n- default/billing (storage)") ||
+		!strings.Contains(summary, "default/orders") || strings.Contains(summary, "users") {
+		t.Errorf("unexpected digest %q", summary)
+	}
+
+	// The digest is sent once, and warnings go out directly outside quiet hours
+	if err := manager.FlushDigest(ctx, "production", "dba"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.SendAlert(ctx, alert("users", AlertSeverityWarning)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 3 || received[2]["labels"].(map[string]interface{})["cluster"] != "users" {
+		t.Errorf("expected the warning to be sent directly, got %v", received)
+	}
+}
+
+func TestAlertManager_QuietHoursTicketChannels(t *testing.T) {
+	fakeServer := &fakeTicketServer{}
+	server := fakeServer.start(t, func(r ticketRequest) interface{} {
+		if r.method == http.MethodGet {
+			return map[string]interface{}{"result": []interface{}{}}
+		}
+		return map[string]interface{}{"result": map[string]string{"sys_id": "abc123"}}
+	})
+
+	fakeClock := clocktesting.NewFakeClock(time.Date(2025, 6, 4, 23, 0, 0, 0, time.UTC))
+	manager := NewAlertManager(ticketCredentialsClient(), []cnpgv1alpha1.AlertChannel{{
+		Type:     cnpgv1alpha1.AlertChannelTypeServiceNow,
+		Endpoint: server.URL,
+		Ticket:   &cnpgv1alpha1.TicketConfig{CredentialsSecret: "ops/ticketing"},
+	}})
+	manager.SetClock(fakeClock)
+	manager.SetQuietHours(&cnpgv1alpha1.QuietHoursConfig{Start: "22:00", End: "07:00"})
+
+	if err := manager.SendAlert(context.Background(), ticketAlert(AlertSeverityWarning)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests := fakeServer.taken(); len(requests) != 2 {
+		t.Fatalf("expected the ticket to be opened during quiet hours, got %+v", requests)
+	}
+
+	fakeClock.SetTime(time.Date(2025, 6, 5, 8, 0, 0, 0, time.UTC))
+	if err := manager.FlushDigest(context.Background(), "production", "dba"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests := fakeServer.taken(); len(requests) != 0 {
+		t.Errorf("expected no digest ticket, got %+v", requests)
+	}
+}
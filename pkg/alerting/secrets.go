@@ -0,0 +1,167 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// slackWebhookKey is the Secret key holding the Slack webhook URL
+	slackWebhookKey = "webhook-url"
+	// slackBotTokenKey is the Secret key holding the token of a Slack bot
+	slackBotTokenKey = "bot-token"
+	// pagerDutyRoutingKey is the Secret key holding the PagerDuty routing key
+	pagerDutyRoutingKey = "routing-key"
+	// ticketUsernameKey is the Secret key holding the user of a ticketing channel
+	ticketUsernameKey = "username"
+	// ticketPasswordKey is the Secret key holding the password or API token of a ticketing channel
+	ticketPasswordKey = "password"
+)
+
+// SecretCache caches alert channel secrets between sends. Each entry remembers the
+// resourceVersion it was read at. The current resourceVersion is read from the
+// metadata-only Secret watch of the manager cache, so a rotated or deleted secret is
+// noticed on the next send without keeping the data of every Secret in memory.
+type SecretCache struct {
+	// metadata serves PartialObjectMetadata reads from a watch
+	metadata client.Reader
+	// reader reads Secret data from the API server
+	reader client.Reader
+
+	mu      sync.Mutex
+	entries map[types.NamespacedName]cachedSecret
+}
+
+// cachedSecret is the data of a Secret at a resourceVersion
+type cachedSecret struct {
+	resourceVersion string
+	data            map[string][]byte
+}
+
+// NewSecretCache creates a secret cache. metadata is normally the manager's cached
+// client and reader its API reader.
+func NewSecretCache(metadata, reader client.Reader) *SecretCache {
+	return &SecretCache{
+		metadata: metadata,
+		reader:   reader,
+		entries:  make(map[types.NamespacedName]cachedSecret),
+	}
+}
+
+// Get returns the data of a Secret, reading it from the API server only when it
+// changed since the last read
+func (c *SecretCache) Get(ctx context.Context, key types.NamespacedName) (map[string][]byte, error) {
+	current := &metav1.PartialObjectMetadata{}
+	current.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	if err := c.metadata.Get(ctx, key, current); err != nil {
+		if apierrors.IsNotFound(err) {
+			c.mu.Lock()
+			delete(c.entries, key)
+			c.mu.Unlock()
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && entry.resourceVersion == current.ResourceVersion {
+		return entry.data, nil
+	}
+
+	var secret corev1.Secret
+	if err := c.reader.Get(ctx, key, &secret); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cachedSecret{resourceVersion: secret.ResourceVersion, data: secret.Data}
+	c.mu.Unlock()
+	return secret.Data, nil
+}
+
+// ChannelSecrets returns the Secrets referenced by channels
+func ChannelSecrets(channels []cnpgv1alpha1.AlertChannel) []types.NamespacedName {
+	var keys []types.NamespacedName
+	for _, channel := range channels {
+		if ref, _ := channelSecret(channel); ref != "" {
+			keys = append(keys, secretKey(ref))
+		}
+	}
+	return keys
+}
+
+// channelSecret returns the secret reference of a channel and the keys read from it.
+// The reference is empty for channels without a secret.
+func channelSecret(channel cnpgv1alpha1.AlertChannel) (ref string, keys []string) {
+	switch channel.Type {
+	case cnpgv1alpha1.AlertChannelTypeSlack:
+		if channel.BotTokenSecret != "" {
+			return channel.BotTokenSecret, []string{slackBotTokenKey}
+		}
+		return channel.WebhookSecret, []string{slackWebhookKey}
+	case cnpgv1alpha1.AlertChannelTypePagerDuty:
+		return channel.RoutingKeySecret, []string{pagerDutyRoutingKey}
+	case cnpgv1alpha1.AlertChannelTypeServiceNow, cnpgv1alpha1.AlertChannelTypeJira:
+		if channel.Ticket == nil {
+			return "", nil
+		}
+		return channel.Ticket.CredentialsSecret, []string{ticketUsernameKey, ticketPasswordKey}
+	default:
+		return "", nil
+	}
+}
+
+// secretKey parses a namespace/name secret reference. References without a
+// namespace are in the default namespace.
+func secretKey(ref string) types.NamespacedName {
+	if namespace, name, ok := strings.Cut(ref, "/"); ok {
+		return types.NamespacedName{Namespace: namespace, Name: name}
+	}
+	return types.NamespacedName{Namespace: "default", Name: ref}
+}
+
+// CheckSecrets verifies that the secret of every channel exists and holds the keys
+// the channel reads, so a misconfigured channel is reported before an alert is lost
+func (m *AlertManager) CheckSecrets(ctx context.Context) error {
+	var problems []string
+	for _, channel := range m.channels {
+		ref, keys := channelSecret(channel)
+		for _, key := range keys {
+			if _, err := m.getSecretValue(ctx, ref, key); err != nil {
+				problems = append(problems, fmt.Sprintf("%s channel: %v", channel.Type, err))
+				break
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
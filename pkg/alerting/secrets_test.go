@@ -0,0 +1,200 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// countingReader counts the Secrets read from the API server
+type countingReader struct {
+	client.Reader
+	reads int
+}
+
+func (r *countingReader) Get(
+	ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption,
+) error {
+	r.reads++
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func newSecretClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestSecretCache_Get(t *testing.T) {
+	ctx := context.Background()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "slack-webhook", Namespace: "ops"},
+		Data:       map[string][]byte{slackWebhookKey: []byte("https://hooks.example.com/old")},
+	}
+	c := newSecretClient(t, secret)
+	reader := &countingReader{Reader: c}
+	cache := NewSecretCache(c, reader)
+	key := types.NamespacedName{Name: "slack-webhook", Namespace: "ops"}
+
+	for range 3 {
+		data, err := cache.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data[slackWebhookKey]) != "https://hooks.example.com/old" {
+			t.Errorf("unexpected webhook URL %q", data[slackWebhookKey])
+		}
+	}
+	if reader.reads != 1 {
+		t.Errorf("expected the secret to be read once, got %d reads", reader.reads)
+	}
+
+	// A rotated secret is read again on the next send
+	secret.Data[slackWebhookKey] = []byte("https://hooks.example.com/new")
+	if err := c.Update(ctx, secret); err != nil {
+		t.Fatalf("failed to rotate secret: %v", err)
+	}
+	data, err := cache.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data[slackWebhookKey]) != "https://hooks.example.com/new" {
+		t.Errorf("expected the rotated webhook URL, got %q", data[slackWebhookKey])
+	}
+	if reader.reads != 2 {
+		t.Errorf("expected the rotated secret to be read again, got %d reads", reader.reads)
+	}
+
+	if err := c.Delete(ctx, secret); err != nil {
+		t.Fatalf("failed to delete secret: %v", err)
+	}
+	if _, err := cache.Get(ctx, key); err == nil {
+		t.Error("expected an error for a deleted secret")
+	}
+}
+
+func TestAlertManager_CheckSecrets(t *testing.T) {
+	c := newSecretClient(t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pagerduty-key", Namespace: "ops"},
+		Data:       map[string][]byte{pagerDutyRoutingKey: []byte("routing")},
+	}, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "jira-credentials", Namespace: "ops"},
+		Data:       map[string][]byte{ticketUsernameKey: []byte("bot"), ticketPasswordKey: []byte("token")},
+	}, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "jira-token-only", Namespace: "ops"},
+		Data:       map[string][]byte{ticketPasswordKey: []byte("token")},
+	})
+
+	tests := []struct {
+		name     string
+		channels []cnpgv1alpha1.AlertChannel
+		wantErr  string
+	}{
+		{
+			name: "valid channels",
+			channels: []cnpgv1alpha1.AlertChannel{
+				{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: "http://am:9093"},
+				{Type: cnpgv1alpha1.AlertChannelTypePagerDuty, RoutingKeySecret: "ops/pagerduty-key"},
+			},
+		},
+		{
+			name: "missing secret",
+			channels: []cnpgv1alpha1.AlertChannel{
+				{Type: cnpgv1alpha1.AlertChannelTypeSlack, WebhookSecret: "ops/slack-webhook"},
+			},
+			wantErr: "slack channel",
+		},
+		{
+			name: "missing key",
+			channels: []cnpgv1alpha1.AlertChannel{
+				{Type: cnpgv1alpha1.AlertChannelTypeSlack, WebhookSecret: "ops/pagerduty-key"},
+			},
+			wantErr: "key webhook-url not found",
+		},
+		{
+			name: "ticket credentials",
+			channels: []cnpgv1alpha1.AlertChannel{{
+				Type:   cnpgv1alpha1.AlertChannelTypeJira,
+				Ticket: &cnpgv1alpha1.TicketConfig{CredentialsSecret: "ops/jira-credentials"},
+			}},
+		},
+		{
+			name: "missing ticket username",
+			channels: []cnpgv1alpha1.AlertChannel{{
+				Type:   cnpgv1alpha1.AlertChannelTypeJira,
+				Ticket: &cnpgv1alpha1.TicketConfig{CredentialsSecret: "ops/jira-token-only"},
+			}},
+			wantErr: "key username not found",
+		},
+		{
+			name:     "no secret reference",
+			channels: []cnpgv1alpha1.AlertChannel{{Type: cnpgv1alpha1.AlertChannelTypePagerDuty}},
+			wantErr:  "secret name is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewAlertManager(c, tt.channels)
+			manager.SetSecretCache(NewSecretCache(c, c))
+
+			err := manager.CheckSecrets(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestChannelSecrets(t *testing.T) {
+	keys := ChannelSecrets([]cnpgv1alpha1.AlertChannel{
+		{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: "http://am:9093"},
+		{Type: cnpgv1alpha1.AlertChannelTypeSlack, WebhookSecret: "ops/slack-webhook"},
+		{Type: cnpgv1alpha1.AlertChannelTypePagerDuty, RoutingKeySecret: "pagerduty-key"},
+	})
+
+	want := []types.NamespacedName{
+		{Namespace: "ops", Name: "slack-webhook"},
+		{Namespace: "default", Name: "pagerduty-key"},
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("expected %v, got %v", want, keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("expected %v, got %v", want[i], keys[i])
+		}
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// defaultSlackAPIURL is the Web API bot-token Slack channels post to
+const defaultSlackAPIURL = "https://slack.com/api"
+
+// threadID identifies the Slack thread a bot-token channel keeps for an alert fingerprint
+type threadID struct {
+	channel     channelID
+	fingerprint string
+}
+
+// slackThread is the message an alert was first posted as. Follow-ups are replies to it
+type slackThread struct {
+	// channel is the ID of the Slack channel the message was posted to
+	channel string
+	ts      string
+	// started is when the message was posted; remediation finished earlier is not reported
+	started time.Time
+	// reported is the last StorageEvent whose result was posted to the thread
+	reported string
+}
+
+// slackPostResponse is the response of chat.postMessage
+type slackPostResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	TS      string `json:"ts,omitempty"`
+}
+
+// isSlackBotChannel returns true for Slack channels posting with a bot token
+func isSlackBotChannel(channel cnpgv1alpha1.AlertChannel) bool {
+	return channel.Type == cnpgv1alpha1.AlertChannelTypeSlack && channel.BotTokenSecret != ""
+}
+
+// sendToSlackBot posts an alert with chat.postMessage. The first alert about a problem
+// starts a thread in the cluster's Slack channel, or the channel's own, and later alerts
+// about it are replies in that thread until it is resolved
+func (m *AlertManager) sendToSlackBot(ctx context.Context, alert *Alert, channel cnpgv1alpha1.AlertChannel) error {
+	token, err := m.getSecretValue(ctx, channel.BotTokenSecret, slackBotTokenKey)
+	if err != nil {
+		return fmt.Errorf("failed to get slack bot token: %w", err)
+	}
+
+	id := threadID{channel: idOf(channel), fingerprint: fingerprint(alert)}
+	payload := slackPayload(ctx, alert, channel)
+	if thread := m.thread(id); thread != nil {
+		payload["channel"] = thread.channel
+		payload["thread_ts"] = thread.ts
+		_, err := m.postSlackMessage(ctx, token, payload)
+		return err
+	}
+
+	target := alert.Ownership.SlackChannel
+	if target == "" {
+		target = channel.Channel
+	}
+	if target == "" {
+		return fmt.Errorf("slack bot channel has no channel configured")
+	}
+	payload["channel"] = target
+	posted, err := m.postSlackMessage(ctx, token, payload)
+	if err != nil {
+		return err
+	}
+	m.setThread(id, &slackThread{channel: posted.Channel, ts: posted.TS, started: m.clock.Now()})
+	return nil
+}
+
+// ReportRemediation posts the result of a StorageEvent to the threads bot-token Slack
+// channels keep for an alert, once per event. Events that finished before the thread
+// was started are not reported. Only the cluster, connection and type of the alert are used
+func (m *AlertManager) ReportRemediation(ctx context.Context, alert *Alert, event string, finished time.Time, text string) error {
+	var errs []error
+	for _, channel := range m.channels {
+		if !isSlackBotChannel(channel) {
+			continue
+		}
+		id := threadID{channel: idOf(channel), fingerprint: fingerprint(alert)}
+		thread := m.thread(id)
+		if thread == nil || thread.reported == event || finished.Before(thread.started) {
+			continue
+		}
+		if err := m.replyInThread(ctx, channel, thread, text); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.threadLock.Lock()
+		thread.reported = event
+		m.threadLock.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// resolveThreads posts the resolution of an alert to the threads bot-token Slack
+// channels keep for it and forgets them, so the next alert starts a new thread.
+// Threads whose reply failed are kept and retried on the next resolution
+func (m *AlertManager) resolveThreads(ctx context.Context, alert *Alert) error {
+	var errs []error
+	for _, channel := range m.channels {
+		if !isSlackBotChannel(channel) {
+			continue
+		}
+		id := threadID{channel: idOf(channel), fingerprint: fingerprint(alert)}
+		thread := m.thread(id)
+		if thread == nil {
+			continue
+		}
+		text := fmt.Sprintf("Resolved: the %s problem of %s has cleared", alertType(alert), alertKey(alert))
+		if err := m.replyInThread(ctx, channel, thread, text); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.setThread(id, nil)
+	}
+	return errors.Join(errs...)
+}
+
+// replyInThread posts text as a reply in a thread
+func (m *AlertManager) replyInThread(ctx context.Context, channel cnpgv1alpha1.AlertChannel, thread *slackThread, text string) error {
+	token, err := m.getSecretValue(ctx, channel.BotTokenSecret, slackBotTokenKey)
+	if err != nil {
+		return fmt.Errorf("failed to get slack bot token: %w", err)
+	}
+	_, err = m.postSlackMessage(ctx, token, map[string]interface{}{
+		"channel":   thread.channel,
+		"thread_ts": thread.ts,
+		"text":      text,
+	})
+	return err
+}
+
+// postSlackMessage calls chat.postMessage, which reports errors in the body of 200 responses
+func (m *AlertManager) postSlackMessage(ctx context.Context, token string, payload map[string]interface{}) (*slackPostResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal slack payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", m.slackAPIURL+"/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send slack request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("slack returned status %d", resp.StatusCode)
+	}
+	var posted slackPostResponse
+	if err := json.NewDecoder(resp.Body).Decode(&posted); err != nil {
+		return nil, fmt.Errorf("failed to decode slack response: %w", err)
+	}
+	if !posted.OK {
+		return nil, fmt.Errorf("slack chat.postMessage failed: %s", posted.Error)
+	}
+	return &posted, nil
+}
+
+// thread returns the open thread for id, nil when there is none
+func (m *AlertManager) thread(id threadID) *slackThread {
+	m.threadLock.Lock()
+	defer m.threadLock.Unlock()
+	return m.threads[id]
+}
+
+// setThread tracks the open thread for id, or forgets it when thread is nil
+func (m *AlertManager) setThread(id threadID, thread *slackThread) {
+	m.threadLock.Lock()
+	defer m.threadLock.Unlock()
+	if thread == nil {
+		delete(m.threads, id)
+		return
+	}
+	m.threads[id] = thread
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// fakeSlackAPI records chat.postMessage calls and answers them like Slack does
+type fakeSlackAPI struct {
+	mu       sync.Mutex
+	messages []map[string]interface{}
+	fail     string
+}
+
+func (f *fakeSlackAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Path != "/chat.postMessage" || r.Header.Get("Authorization") != "Bearer xoxb-test" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if f.fail != "" {
+		_, _ = fmt.Fprintf(w, `{"ok":false,"error":%q}`, f.fail)
+		return
+	}
+	var message map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&message)
+	f.messages = append(f.messages, message)
+	_, _ = fmt.Fprintf(w, `{"ok":true,"channel":"C0123","ts":"1700000000.%06d"}`, len(f.messages))
+}
+
+func (f *fakeSlackAPI) posted() []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]interface{}(nil), f.messages...)
+}
+
+func newSlackBotManager(t *testing.T, api *fakeSlackAPI) *AlertManager {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "slack-bot", Namespace: "monitoring"},
+		Data:       map[string][]byte{slackBotTokenKey: []byte("xoxb-test")},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+	manager := NewAlertManager(c, []cnpgv1alpha1.AlertChannel{{
+		Type:           cnpgv1alpha1.AlertChannelTypeSlack,
+		BotTokenSecret: "monitoring/slack-bot",
+		Channel:        "#databases",
+	}})
+	manager.slackAPIURL = server.URL
+	return manager
+}
+
+func TestAlertManager_SlackBotThreads(t *testing.T) {
+	api := &fakeSlackAPI{}
+	manager := newSlackBotManager(t, api)
+	ctx := context.Background()
+
+	alert := &Alert{
+		ClusterName:      testClusterName,
+		ClusterNamespace: "default",
+		Severity:         AlertSeverityWarning,
+		Message:          "Storage usage high",
+		Ownership:        cnpgv1alpha1.OwnershipMetadata{SlackChannel: "#payments-db"},
+	}
+	if err := manager.SendAlert(ctx, alert); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	escalated := *alert
+	escalated.Severity = AlertSeverityCritical
+	if err := manager.SendAlert(ctx, &escalated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resolved := &Alert{ClusterName: testClusterName, ClusterNamespace: "default"}
+	finished := time.Now().Add(time.Minute)
+	if err := manager.ReportRemediation(ctx, resolved, "expansion-1", finished, "expansion completed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.ReportRemediation(ctx, resolved, "expansion-1", finished, "expansion completed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.ResolveAlert(ctx, resolved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.SendAlert(ctx, alert); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := api.posted()
+	if len(messages) != 5 {
+		t.Fatalf("expected 5 messages, got %d: %v", len(messages), messages)
+	}
+	if messages[0]["channel"] != "#payments-db" || messages[0]["thread_ts"] != nil {
+		t.Errorf("expected the first alert to start a thread in the cluster's channel, got %v", messages[0])
+	}
+	for _, message := range messages[1:4] {
+		if message["channel"] != "C0123" || message["thread_ts"] != "1700000000.000001" {
+			t.Errorf("expected a reply in the thread, got %v", message)
+		}
+	}
+	if messages[2]["text"] != "expansion completed" {
+		t.Errorf("expected the remediation result once, got %v", messages[2]["text"])
+	}
+	if !strings.HasPrefix(fmt.Sprint(messages[3]["text"]), "Resolved") {
+		t.Errorf("expected the resolution, got %v", messages[3]["text"])
+	}
+	if messages[4]["channel"] != "#payments-db" || messages[4]["thread_ts"] != nil {
+		t.Errorf("expected an alert after the resolution to start a new thread, got %v", messages[4])
+	}
+}
+
+func TestAlertManager_SlackBotRemediationBeforeThread(t *testing.T) {
+	api := &fakeSlackAPI{}
+	manager := newSlackBotManager(t, api)
+	ctx := context.Background()
+
+	alert := &Alert{ClusterName: testClusterName, ClusterNamespace: "default", Severity: AlertSeverityWarning}
+	if err := manager.SendAlert(ctx, alert); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.ReportRemediation(ctx, alert, "expansion-0", time.Now().Add(-time.Hour), "old"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := api.posted()
+	if len(messages) != 1 {
+		t.Fatalf("expected remediation finished before the thread not to be reported, got %v", messages)
+	}
+	if messages[0]["channel"] != "#databases" {
+		t.Errorf("expected the channel's own channel without an override, got %v", messages[0]["channel"])
+	}
+}
+
+func TestAlertManager_SlackBotError(t *testing.T) {
+	api := &fakeSlackAPI{fail: "channel_not_found"}
+	manager := newSlackBotManager(t, api)
+
+	alert := &Alert{ClusterName: testClusterName, ClusterNamespace: "default", Severity: AlertSeverityWarning}
+	err := manager.SendAlert(context.Background(), alert)
+	if err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Fatalf("expected the Slack error to be returned, got %v", err)
+	}
+}
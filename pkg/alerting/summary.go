@@ -0,0 +1,150 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/backup"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+const (
+	// AlertTypeSummary is the type of the scheduled summary of a policy's unhealthy clusters
+	AlertTypeSummary = "summary"
+
+	// DefaultSummarySchedule is the default cron expression of the alert summary
+	DefaultSummarySchedule = "0 9 * * *"
+)
+
+// summarySchedule is a parsed alert summary configuration
+type summarySchedule struct {
+	schedule         *backup.Schedule
+	location         *time.Location
+	suppressWarnings bool
+}
+
+// parseSummary parses an alert summary configuration. It returns nil without one
+func parseSummary(cfg *cnpgv1alpha1.AlertSummaryConfig) (*summarySchedule, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	expr := cfg.Schedule
+	if expr == "" {
+		expr = DefaultSummarySchedule
+	}
+	schedule, err := backup.ParseSchedule(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert summary schedule: %w", err)
+	}
+
+	location := time.UTC
+	if cfg.Timezone != "" {
+		if location, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid alert summary timezone: %w", err)
+		}
+	}
+	return &summarySchedule{schedule: schedule, location: location, suppressWarnings: cfg.SuppressWarnings}, nil
+}
+
+// SetSummary sets the schedule of the alert summary. An invalid configuration sends
+// no summary, suppresses no warnings and is reported by CheckSummary
+func (m *AlertManager) SetSummary(cfg *cnpgv1alpha1.AlertSummaryConfig) {
+	m.summaryLock.Lock()
+	defer m.summaryLock.Unlock()
+
+	if reflect.DeepEqual(cfg, m.summaryConfig) {
+		return
+	}
+	m.summaryConfig = cfg.DeepCopy()
+	m.summary, m.summaryErr = parseSummary(cfg)
+}
+
+// CheckSummary returns the error of an invalid alert summary configuration
+func (m *AlertManager) CheckSummary() error {
+	m.summaryLock.Lock()
+	defer m.summaryLock.Unlock()
+	return m.summaryErr
+}
+
+// SummaryDue reports whether a scheduled summary time has passed since last
+func (m *AlertManager) SummaryDue(last time.Time) bool {
+	m.summaryLock.Lock()
+	defer m.summaryLock.Unlock()
+
+	if m.summary == nil {
+		return false
+	}
+	run, ok := m.summary.schedule.Prev(m.clock.Now().In(m.summary.location))
+	return ok && run.After(last)
+}
+
+// summarizes reports whether a warning about a cluster is left to the summary
+func (m *AlertManager) summarizes(ctx context.Context, alert *Alert) bool {
+	m.summaryLock.Lock()
+	defer m.summaryLock.Unlock()
+
+	if m.summary == nil || !m.summary.suppressWarnings || alert.Severity != AlertSeverityWarning {
+		return false
+	}
+	if t := alertType(alert); t != AlertTypeStorage && t != AlertTypeBackup {
+		return false
+	}
+
+	log.FromContext(ctx).V(1).Info("Alert left to the summary", "cluster", alertKey(alert), "type", alertType(alert))
+	metrics.RecordAlertSuppressed(alert.ClusterName, alert.ClusterNamespace, "summary")
+	return true
+}
+
+// isAggregate reports whether an alert is a digest or summary of other alerts, which
+// ticketing channels do not receive
+func isAggregate(alert *Alert) bool {
+	return alert.Type == AlertTypeDigest || alert.Type == AlertTypeSummary
+}
+
+// SummaryTable renders the rows of a summary as a plain text table with aligned columns
+func SummaryTable(header []string, rows [][]string) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	_ = w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// slackText formats the table following the first line of a summary as a code block,
+// so Slack keeps its columns aligned
+func slackText(alert *Alert) string {
+	if alert.Type != AlertTypeSummary {
+		return alert.Message
+	}
+	header, table, ok := strings.Cut(alert.Message, "\n")
+	if !ok {
+		return alert.Message
+	}
+	return fmt.Sprintf("%s\n```\n%s\n```", header, table)
+}
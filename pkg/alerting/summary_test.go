@@ -0,0 +1,124 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestAlertManager_SummaryDue(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Date(2025, 6, 4, 6, 30, 0, 0, time.UTC))
+	manager := NewAlertManager(nil, nil)
+	manager.SetClock(fakeClock)
+
+	last := time.Date(2025, 6, 3, 12, 0, 0, 0, time.UTC)
+	if manager.SummaryDue(last) {
+		t.Error("expected no summary without a configuration")
+	}
+
+	// 09:00 in Berlin is 07:00 UTC in summer
+	manager.SetSummary(&cnpgv1alpha1.AlertSummaryConfig{Schedule: "0 9 * * *", Timezone: "Europe/Berlin"})
+	if manager.SummaryDue(last) {
+		t.Error("expected no summary before 09:00 Berlin time")
+	}
+	fakeClock.SetTime(time.Date(2025, 6, 4, 7, 1, 0, 0, time.UTC))
+	if !manager.SummaryDue(last) {
+		t.Error("expected the summary to be due after 09:00 Berlin time")
+	}
+	if manager.SummaryDue(fakeClock.Now()) {
+		t.Error("expected the summary to be due once a day")
+	}
+
+	manager.SetSummary(&cnpgv1alpha1.AlertSummaryConfig{Timezone: "Europe/Atlantis"})
+	if manager.CheckSummary() == nil {
+		t.Error("expected an invalid timezone to be reported")
+	}
+	if manager.SummaryDue(last) {
+		t.Error("expected no summary with an invalid configuration")
+	}
+	manager.SetSummary(&cnpgv1alpha1.AlertSummaryConfig{Schedule: "every morning"})
+	if manager.CheckSummary() == nil {
+		t.Error("expected an invalid schedule to be reported")
+	}
+	manager.SetSummary(&cnpgv1alpha1.AlertSummaryConfig{})
+	if err := manager.CheckSummary(); err != nil {
+		t.Errorf("expected the default schedule to be valid, got %v", err)
+	}
+}
+
+func TestAlertManager_SummarySuppressesWarnings(t *testing.T) {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload []map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload...)
+	}))
+	defer server.Close()
+
+	manager := NewAlertManager(nil, []cnpgv1alpha1.AlertChannel{
+		{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: server.URL},
+	})
+	manager.SetSummary(&cnpgv1alpha1.AlertSummaryConfig{SuppressWarnings: true})
+
+	for _, alert := range []*Alert{
+		{ClusterName: "orders", ClusterNamespace: "db", Type: AlertTypeStorage, Severity: AlertSeverityWarning},
+		{ClusterName: "billing", ClusterNamespace: "db", Type: AlertTypeBackup, Severity: AlertSeverityWarning},
+		{ClusterName: "users", ClusterNamespace: "db", Type: AlertTypeStorage, Severity: AlertSeverityCritical},
+		{ClusterName: "events", ClusterNamespace: "db", Type: AlertTypeAnomalousGrowth, Severity: AlertSeverityWarning},
+		{ClusterName: "production", ClusterNamespace: "dba", Type: AlertTypeSummary, Severity: AlertSeverityWarning},
+	} {
+		if err := manager.SendAlert(context.Background(), alert); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var clusters []string
+	for _, alert := range received {
+		clusters = append(clusters, alert["labels"].(map[string]interface{})["cluster"].(string))
+	}
+	if len(clusters) != 3 || clusters[0] != "users" || clusters[1] != "events" || clusters[2] != "production" {
+		t.Errorf("expected storage and backup warnings to be left to the summary, got %v", clusters)
+	}
+}
+
+func TestSummaryTable(t *testing.T) {
+	table := SummaryTable([]string{"CLUSTER", "USAGE"}, [][]string{{"db/orders", "91%"}, {"edge:db/billing", "82%"}})
+	expected := "CLUSTER          USAGE\n" +
+		"db/orders        91%\n" +
+		"edge:db/billing  82%"
+	if table != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, table)
+	}
+
+	alert := &Alert{Type: AlertTypeSummary, Message: "1 of 2 clusters need attention:\n" + table}
+	if got := slackText(alert); got != "1 of 2 clusters need attention:\n```\n"+table+"\n```" {
+		t.Errorf("expected the table in a code block, got %q", got)
+	}
+	alert.Type = AlertTypeStorage
+	if got := slackText(alert); got != alert.Message {
+		t.Errorf("expected other alerts unchanged, got %q", got)
+	}
+}
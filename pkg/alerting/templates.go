@@ -0,0 +1,153 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// TemplateData is what channel templates and ticket fields are rendered with
+type TemplateData struct {
+	ClusterName      string
+	ClusterNamespace string
+	Connection       string
+	// SourceCluster is the name of the Kubernetes cluster the CNPG cluster runs in
+	SourceCluster string
+	Policy        string
+	OwnerTeam     string
+	RunbookURL    string
+	Escalation    string
+	Type          string
+	Severity      string
+	Message       string
+	UsagePercent  float64
+	Thresholds    cnpgv1alpha1.ThresholdsConfig
+	LastBackup    *time.Time
+	// BackupAge is the time since LastBackup, rounded to the minute. Zero without a backup
+	BackupAge time.Duration
+	Details   map[string]string
+	Timestamp time.Time
+}
+
+// newTemplateData returns the template data of an alert
+func newTemplateData(alert *Alert) TemplateData {
+	data := TemplateData{
+		ClusterName:      alert.ClusterName,
+		ClusterNamespace: alert.ClusterNamespace,
+		Connection:       alert.Connection,
+		SourceCluster:    alert.Source.Name,
+		Policy:           alert.Policy,
+		OwnerTeam:        alert.Ownership.OwnerTeam,
+		RunbookURL:       alert.Ownership.RunbookURL,
+		Escalation:       alert.Ownership.Escalation,
+		Type:             alertType(alert),
+		Severity:         string(alert.Severity),
+		Message:          alert.Message,
+		UsagePercent:     alert.UsagePercent,
+		Thresholds:       alert.Thresholds,
+		LastBackup:       alert.LastBackup,
+		Details:          alert.Details,
+		Timestamp:        alert.Timestamp,
+	}
+	if data.Timestamp.IsZero() {
+		data.Timestamp = time.Now()
+	}
+	if alert.LastBackup != nil {
+		data.BackupAge = data.Timestamp.Sub(*alert.LastBackup).Round(time.Minute)
+	}
+	return data
+}
+
+// parseTemplate parses a channel template. Missing map keys render empty
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=zero").Parse(text)
+}
+
+// renderTemplate renders a channel template with data
+func renderTemplate(name, text string, data TemplateData) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// alertText returns the title and body of an alert sent through a channel: the
+// channel's templates where set, otherwise title and body. A template that fails to
+// render is logged and falls back to the default, so the alert still goes out
+func alertText(ctx context.Context, channel cnpgv1alpha1.AlertChannel, alert *Alert, title, body string) (string, string) {
+	if channel.Templates == nil {
+		return title, body
+	}
+
+	data := newTemplateData(alert)
+	render := func(name, text, fallback string) string {
+		if text == "" {
+			return fallback
+		}
+		rendered, err := renderTemplate(name, text, data)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to render alert template", "channel", channel.Type, "template", name)
+			return fallback
+		}
+		return rendered
+	}
+	return render("title", channel.Templates.Title, title), render("body", channel.Templates.Body, body)
+}
+
+// CheckTemplates verifies that the templates and ticket fields of every channel parse
+func (m *AlertManager) CheckTemplates() error {
+	var problems []string
+	for _, channel := range m.channels {
+		templates := map[string]string{}
+		if channel.Templates != nil {
+			templates["title"] = channel.Templates.Title
+			templates["body"] = channel.Templates.Body
+		}
+		if channel.Ticket != nil {
+			for field, text := range channel.Ticket.Fields {
+				templates["field "+field] = text
+			}
+			for field, text := range channel.Ticket.ResolveFields {
+				templates["resolve field "+field] = text
+			}
+		}
+		for name, text := range templates {
+			if _, err := parseTemplate(name, text); err != nil {
+				problems = append(problems, fmt.Sprintf("%s channel: invalid %s template: %v", channel.Type, name, err))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
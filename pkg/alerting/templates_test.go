@@ -0,0 +1,175 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestAlertText(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lastBackup := now.Add(-26*time.Hour - 20*time.Second)
+	alert := &Alert{
+		ClusterName:      testClusterName,
+		ClusterNamespace: testNamespaceName,
+		Type:             AlertTypeBackup,
+		Policy:           "production",
+		Severity:         AlertSeverityCritical,
+		Message:          "No backup in 26h",
+		Thresholds:       cnpgv1alpha1.ThresholdsConfig{Warning: 70, Critical: 80},
+		UsagePercent:     81.25,
+		LastBackup:       &lastBackup,
+		Timestamp:        now,
+	}
+
+	tests := []struct {
+		name      string
+		templates *cnpgv1alpha1.AlertTemplates
+		wantTitle string
+		wantBody  string
+	}{
+		{
+			name:      "no templates",
+			wantTitle: "default title",
+			wantBody:  "default body",
+		},
+		{
+			name: "title and body",
+			templates: &cnpgv1alpha1.AlertTemplates{
+				Title: "[{{ .Severity }}] {{ .ClusterNamespace }}/{{ .ClusterName }} ({{ .Policy }})",
+				Body: `Usage {{ printf "%.1f" .UsagePercent }}% (critical at {{ .Thresholds.Critical }}%), ` +
+					`last backup {{ .BackupAge }} ago. Runbook: https://runbooks.example.com/{{ .Type }}`,
+			},
+			wantTitle: "[critical] test-namespace/test-cluster (production)",
+			wantBody:  "Usage 81.2% (critical at 80%), last backup 26h0m0s ago. Runbook: https://runbooks.example.com/backup",
+		},
+		{
+			name:      "only body",
+			templates: &cnpgv1alpha1.AlertTemplates{Body: "{{ .Message }}{{ .Details.missing }}"},
+			wantTitle: "default title",
+			wantBody:  "No backup in 26h",
+		},
+		{
+			name:      "invalid template falls back",
+			templates: &cnpgv1alpha1.AlertTemplates{Title: "{{ .Nope }}", Body: "{{ .Message"},
+			wantTitle: "default title",
+			wantBody:  "default body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := cnpgv1alpha1.AlertChannel{Type: cnpgv1alpha1.AlertChannelTypeSlack, Templates: tt.templates}
+			title, body := alertText(context.Background(), channel, alert, "default title", "default body")
+			if title != tt.wantTitle {
+				t.Errorf("expected title %q, got %q", tt.wantTitle, title)
+			}
+			if body != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, body)
+			}
+		})
+	}
+}
+
+func TestAlertManager_SlackTemplates(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	secret := &corev1.Secret{}
+	secret.Name, secret.Namespace = "slack", "default"
+	secret.Data = map[string][]byte{slackWebhookKey: []byte(server.URL)}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	channel := cnpgv1alpha1.AlertChannel{
+		Type:          cnpgv1alpha1.AlertChannelTypeSlack,
+		WebhookSecret: "slack",
+		Templates: &cnpgv1alpha1.AlertTemplates{
+			Title: "{{ .ClusterName }} is at {{ .UsagePercent }}%",
+			Body:  "See https://runbooks.example.com/storage",
+		},
+	}
+	manager := NewAlertManager(client, []cnpgv1alpha1.AlertChannel{channel})
+	alert := &Alert{
+		ClusterName:      testClusterName,
+		ClusterNamespace: testNamespaceName,
+		Severity:         AlertSeverityWarning,
+		Message:          "Storage usage is high",
+		UsagePercent:     75,
+		Timestamp:        time.Now(),
+	}
+	if err := manager.sendToSlack(context.Background(), alert, channel); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	attachment := received["attachments"].([]interface{})[0].(map[string]interface{})
+	if attachment["title"] != "test-cluster is at 75%" {
+		t.Errorf("unexpected title %v", attachment["title"])
+	}
+	if attachment["text"] != "See https://runbooks.example.com/storage" {
+		t.Errorf("unexpected text %v", attachment["text"])
+	}
+}
+
+func TestAlertManager_CheckTemplates(t *testing.T) {
+	manager := NewAlertManager(nil, []cnpgv1alpha1.AlertChannel{
+		{
+			Type:      cnpgv1alpha1.AlertChannelTypeSlack,
+			Templates: &cnpgv1alpha1.AlertTemplates{Title: "{{ .ClusterName }}", Body: "{{ if .Message }}"},
+		},
+		{
+			Type: cnpgv1alpha1.AlertChannelTypeJira,
+			Ticket: &cnpgv1alpha1.TicketConfig{
+				Fields: map[string]string{"priority": `{"name": "High"}`, "labels": "{{ range }}"},
+			},
+		},
+	})
+
+	err := manager.CheckTemplates()
+	if err == nil {
+		t.Fatal("expected invalid templates to be reported")
+	}
+	for _, want := range []string{"slack channel: invalid body template", "jira channel: invalid field labels template"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err.Error())
+		}
+	}
+	if strings.Contains(err.Error(), "priority") || strings.Contains(err.Error(), "title") {
+		t.Errorf("expected valid templates not to be reported, got %q", err.Error())
+	}
+
+	manager.UpdateChannels([]cnpgv1alpha1.AlertChannel{{Type: cnpgv1alpha1.AlertChannelTypeSlack}})
+	if err := manager.CheckTemplates(); err != nil {
+		t.Errorf("expected channels without templates to pass, got %v", err)
+	}
+}
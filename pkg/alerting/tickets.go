@@ -0,0 +1,553 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+const (
+	// defaultServiceNowTable is the table tickets are created in when none is configured
+	defaultServiceNowTable = "incident"
+	// defaultServiceNowResolvedState is the Resolved state of the incident table
+	defaultServiceNowResolvedState = "6"
+	// defaultJiraIssueType is the issue type created when none is configured
+	defaultJiraIssueType = "Task"
+	// defaultJiraCloseTransition is the transition that closes issues when none is configured
+	defaultJiraCloseTransition = "Done"
+)
+
+// ticketSystem is the REST API of a ticketing channel
+type ticketSystem interface {
+	// find returns the reference of the open ticket carrying key, or "" when there is none
+	find(ctx context.Context, key string) (string, error)
+	// create opens a ticket carrying key and returns its reference
+	create(ctx context.Context, key, summary, description string, fields map[string]interface{}) (string, error)
+	// comment adds a note to a ticket
+	comment(ctx context.Context, ref, text string) error
+	// close resolves a ticket, setting fields
+	close(ctx context.Context, ref, text string, fields map[string]interface{}) error
+}
+
+// ticketID identifies the ticket a channel keeps for an alert fingerprint
+type ticketID struct {
+	channel     channelID
+	fingerprint string
+}
+
+// openTicket is a ticket known to be open, and the severity last reported on it
+type openTicket struct {
+	ref      string
+	severity AlertSeverity
+}
+
+// isTicketChannel returns true for channels that track alerts as tickets
+func isTicketChannel(channel cnpgv1alpha1.AlertChannel) bool {
+	return channel.Type == cnpgv1alpha1.AlertChannelTypeServiceNow || channel.Type == cnpgv1alpha1.AlertChannelTypeJira
+}
+
+// acceptsSeverity returns false for ticketing channels restricted to other severities
+func acceptsSeverity(channel cnpgv1alpha1.AlertChannel, severity AlertSeverity) bool {
+	if channel.Ticket == nil || len(channel.Ticket.Severities) == 0 {
+		return true
+	}
+	return slices.Contains(channel.Ticket.Severities, cnpgv1alpha1.TicketSeverity(severity))
+}
+
+// sendToTicket opens a ticket for an alert, or comments on the open one when the
+// severity changed. Repeats of the same severity leave the ticket untouched
+func (m *AlertManager) sendToTicket(ctx context.Context, alert *Alert, channel cnpgv1alpha1.AlertChannel) error {
+	system, err := m.ticketSystem(ctx, channel)
+	if err != nil {
+		return err
+	}
+
+	id := ticketID{channel: idOf(channel), fingerprint: fingerprint(alert)}
+	ticket, err := m.lookupTicket(ctx, system, id, alert)
+	if err != nil {
+		return fmt.Errorf("failed to look up %s ticket: %w", channel.Type, err)
+	}
+
+	if ticket == nil {
+		fields, err := renderTicketFields(channel.Ticket.Fields, alert)
+		if err != nil {
+			return err
+		}
+		summary, description := alertText(ctx, channel, alert, ticketSummary(alert), ticketDescription(alert))
+		ref, err := system.create(ctx, dedupKey(alert), summary, description, fields)
+		if err != nil {
+			return fmt.Errorf("failed to create %s ticket: %w", channel.Type, err)
+		}
+		m.setTicket(id, &openTicket{ref: ref, severity: alert.Severity})
+		return nil
+	}
+
+	if ticket.severity == alert.Severity {
+		return nil
+	}
+	if err := system.comment(ctx, ticket.ref, fmt.Sprintf("Severity is now %s: %s", alert.Severity, alert.Message)); err != nil {
+		return fmt.Errorf("failed to update %s ticket %s: %w", channel.Type, ticket.ref, err)
+	}
+	m.setTicket(id, &openTicket{ref: ticket.ref, severity: alert.Severity})
+	return nil
+}
+
+// ResolveAlert reports that the problem an alert was sent for has cleared. Ticketing
+// channels close the ticket they opened for it unless configured to leave it open,
+// Slack bot-token channels reply in its thread, a warning held for the quiet hours
+// digest is dropped, and the next alert about the problem is sent without waiting for
+// suppression to expire. Only the cluster, connection and type of the alert are used
+func (m *AlertManager) ResolveAlert(ctx context.Context, alert *Alert) (err error) {
+	ctx, span := tracing.Start(ctx, "alerting.ResolveAlert",
+		append(tracing.Cluster(alert.ClusterName, alert.ClusterNamespace),
+			attribute.String("alert.type", alertType(alert)))...)
+	defer func() { tracing.End(span, err) }()
+
+	m.clearFingerprint(alert)
+	m.dropHeld(alert)
+
+	var errs []error
+	if err := m.resolveThreads(ctx, alert); err != nil {
+		errs = append(errs, err)
+	}
+	for _, channel := range m.channels {
+		if !isTicketChannel(channel) || channel.Ticket == nil || channel.Ticket.LeaveOpen {
+			continue
+		}
+		id := ticketID{channel: idOf(channel), fingerprint: fingerprint(alert)}
+		if ticket, known := m.ticket(id); known && ticket == nil {
+			continue
+		}
+		if err := m.closeTicket(ctx, alert, channel, id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeTicket closes the open ticket of a channel for an alert, if there is one
+func (m *AlertManager) closeTicket(ctx context.Context, alert *Alert, channel cnpgv1alpha1.AlertChannel, id ticketID) error {
+	system, err := m.ticketSystem(ctx, channel)
+	if err != nil {
+		return err
+	}
+	ticket, err := m.lookupTicket(ctx, system, id, alert)
+	if err != nil {
+		return fmt.Errorf("failed to look up %s ticket: %w", channel.Type, err)
+	}
+	if ticket == nil {
+		return nil
+	}
+
+	fields, err := renderTicketFields(channel.Ticket.ResolveFields, alert)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("The %s problem on cluster %s has cleared", alertType(alert), alertKey(alert))
+	err = system.close(ctx, ticket.ref, text, fields)
+	m.recordDelivery(channel, err)
+	if err != nil {
+		return fmt.Errorf("failed to close %s ticket %s: %w", channel.Type, ticket.ref, err)
+	}
+
+	log.FromContext(ctx).Info("Closed ticket", "channel", channel.Type, "ticket", ticket.ref,
+		"cluster", alertKey(alert), "type", alertType(alert))
+	m.setTicket(id, nil)
+	return nil
+}
+
+// lookupTicket returns the open ticket for id, asking the ticketing system when the
+// alert manager has not tracked one since it started. It returns nil when there is none
+func (m *AlertManager) lookupTicket(ctx context.Context, system ticketSystem, id ticketID, alert *Alert) (*openTicket, error) {
+	if ticket, known := m.ticket(id); known {
+		return ticket, nil
+	}
+	ref, err := system.find(ctx, dedupKey(alert))
+	if err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		m.setTicket(id, nil)
+		return nil, nil
+	}
+	return &openTicket{ref: ref}, nil
+}
+
+// ticket returns the tracked ticket for id. A nil ticket that is known means no
+// ticket is open
+func (m *AlertManager) ticket(id ticketID) (ticket *openTicket, known bool) {
+	m.ticketLock.Lock()
+	defer m.ticketLock.Unlock()
+	ticket, known = m.tickets[id]
+	return ticket, known
+}
+
+// setTicket tracks the open ticket for id, or that none is open when ticket is nil
+func (m *AlertManager) setTicket(id ticketID, ticket *openTicket) {
+	m.ticketLock.Lock()
+	defer m.ticketLock.Unlock()
+	m.tickets[id] = ticket
+}
+
+// ticketSystem returns the API client of a ticketing channel
+func (m *AlertManager) ticketSystem(ctx context.Context, channel cnpgv1alpha1.AlertChannel) (ticketSystem, error) {
+	config := channel.Ticket
+	if config == nil {
+		return nil, fmt.Errorf("%s channel has no ticket configuration", channel.Type)
+	}
+	if channel.Endpoint == "" {
+		return nil, fmt.Errorf("%s channel has no endpoint", channel.Type)
+	}
+
+	username, err := m.getSecretValue(ctx, config.CredentialsSecret, ticketUsernameKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s credentials: %w", channel.Type, err)
+	}
+	password, err := m.getSecretValue(ctx, config.CredentialsSecret, ticketPasswordKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s credentials: %w", channel.Type, err)
+	}
+	api := ticketAPI{
+		client:   m.httpClient,
+		baseURL:  strings.TrimSuffix(channel.Endpoint, "/"),
+		username: username,
+		password: password,
+	}
+
+	switch channel.Type {
+	case cnpgv1alpha1.AlertChannelTypeServiceNow:
+		return &serviceNow{
+			ticketAPI:     api,
+			table:         valueOr(config.Table, defaultServiceNowTable),
+			resolvedState: valueOr(config.ResolvedState, defaultServiceNowResolvedState),
+		}, nil
+	case cnpgv1alpha1.AlertChannelTypeJira:
+		if config.Project == "" {
+			return nil, fmt.Errorf("jira channel has no project")
+		}
+		return &jira{
+			ticketAPI:       api,
+			project:         config.Project,
+			issueType:       valueOr(config.IssueType, defaultJiraIssueType),
+			closeTransition: valueOr(config.CloseTransition, defaultJiraCloseTransition),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%s is not a ticketing channel", channel.Type)
+	}
+}
+
+// renderTicketFields renders field templates with an alert. Values rendering to a
+// JSON object or array are decoded so they are sent as JSON
+func renderTicketFields(templates map[string]string, alert *Alert) (map[string]interface{}, error) {
+	data := newTemplateData(alert)
+	fields := make(map[string]interface{}, len(templates))
+	for name, text := range templates {
+		value, err := renderTemplate(name, text, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render ticket field %s: %w", name, err)
+		}
+
+		if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(trimmed), &decoded); err == nil {
+				fields[name] = decoded
+				continue
+			}
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// ticketSummary is the title of the ticket tracking an alert. It leaves out the
+// severity, which changes over the life of the ticket
+func ticketSummary(alert *Alert) string {
+	return fmt.Sprintf("CNPG %s alert for cluster %s", alertType(alert), alertKey(alert))
+}
+
+// ticketDescription is the body of the ticket tracking an alert
+func ticketDescription(alert *Alert) string {
+	lines := []string{
+		alert.Message,
+		"",
+		"Cluster: " + alertKey(alert),
+		"Type: " + alertType(alert),
+		"Severity: " + string(alert.Severity),
+	}
+	if alert.Source.Name != "" {
+		lines = append(lines, "Source cluster: "+alert.Source.Name)
+	}
+	if alert.Ownership.OwnerTeam != "" {
+		lines = append(lines, "Owner team: "+alert.Ownership.OwnerTeam)
+	}
+	if alert.Ownership.RunbookURL != "" {
+		lines = append(lines, "Runbook: "+alert.Ownership.RunbookURL)
+	}
+	if alert.Ownership.Escalation != "" {
+		lines = append(lines, "Escalation: "+alert.Ownership.Escalation)
+	}
+
+	keys := make([]string, 0, len(alert.Details))
+	for key := range alert.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, alert.Details[key]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// valueOr returns value, or fallback when value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// ticketAPI sends JSON requests to a ticketing system with basic authentication
+type ticketAPI struct {
+	client   *http.Client
+	baseURL  string
+	username string
+	password string
+}
+
+// do sends a request to path and decodes the response into out when it is set
+func (a *ticketAPI) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(a.username, a.password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned status %d", method, path, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// serviceNow tracks alerts as records of a ServiceNow table, found by correlation_id
+type serviceNow struct {
+	ticketAPI
+	table         string
+	resolvedState string
+}
+
+func (s *serviceNow) tablePath(sysID string) string {
+	path := "/api/now/table/" + url.PathEscape(s.table)
+	if sysID != "" {
+		path += "/" + url.PathEscape(sysID)
+	}
+	return path
+}
+
+func (s *serviceNow) find(ctx context.Context, key string) (string, error) {
+	query := url.Values{
+		"sysparm_query":  {"active=true^correlation_id=" + key},
+		"sysparm_fields": {"sys_id"},
+		"sysparm_limit":  {"1"},
+	}
+	var response struct {
+		Result []struct {
+			SysID string `json:"sys_id"`
+		} `json:"result"`
+	}
+	if err := s.do(ctx, http.MethodGet, s.tablePath("")+"?"+query.Encode(), nil, &response); err != nil {
+		return "", err
+	}
+	if len(response.Result) == 0 {
+		return "", nil
+	}
+	return response.Result[0].SysID, nil
+}
+
+func (s *serviceNow) create(ctx context.Context, key, summary, description string,
+	fields map[string]interface{}) (string, error) {
+	record := map[string]interface{}{
+		"short_description":   summary,
+		"description":         description,
+		"correlation_id":      key,
+		"correlation_display": "cnpg-storage-manager",
+	}
+	for name, value := range fields {
+		record[name] = value
+	}
+
+	var response struct {
+		Result struct {
+			SysID string `json:"sys_id"`
+		} `json:"result"`
+	}
+	if err := s.do(ctx, http.MethodPost, s.tablePath(""), record, &response); err != nil {
+		return "", err
+	}
+	if response.Result.SysID == "" {
+		return "", fmt.Errorf("servicenow returned no sys_id")
+	}
+	return response.Result.SysID, nil
+}
+
+func (s *serviceNow) comment(ctx context.Context, ref, text string) error {
+	return s.do(ctx, http.MethodPatch, s.tablePath(ref), map[string]interface{}{"work_notes": text}, nil)
+}
+
+func (s *serviceNow) close(ctx context.Context, ref, text string, fields map[string]interface{}) error {
+	record := map[string]interface{}{
+		"state":       s.resolvedState,
+		"close_notes": text,
+	}
+	for name, value := range fields {
+		record[name] = value
+	}
+	return s.do(ctx, http.MethodPatch, s.tablePath(ref), record, nil)
+}
+
+// jira tracks alerts as issues of a Jira project, found by label
+type jira struct {
+	ticketAPI
+	project         string
+	issueType       string
+	closeTransition string
+}
+
+func (j *jira) find(ctx context.Context, key string) (string, error) {
+	query := url.Values{
+		"jql":        {fmt.Sprintf(`project = "%s" AND labels = "%s" AND statusCategory != Done`, j.project, key)},
+		"fields":     {"key"},
+		"maxResults": {"1"},
+	}
+	var response struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	if err := j.do(ctx, http.MethodGet, "/rest/api/2/search?"+query.Encode(), nil, &response); err != nil {
+		return "", err
+	}
+	if len(response.Issues) == 0 {
+		return "", nil
+	}
+	return response.Issues[0].Key, nil
+}
+
+func (j *jira) create(ctx context.Context, key, summary, description string,
+	fields map[string]interface{}) (string, error) {
+	issue := map[string]interface{}{
+		"project":     map[string]string{"key": j.project},
+		"issuetype":   map[string]string{"name": j.issueType},
+		"summary":     summary,
+		"description": description,
+		"labels":      []string{key, "cnpg-storage-manager"},
+	}
+	for name, value := range fields {
+		issue[name] = value
+	}
+
+	var response struct {
+		Key string `json:"key"`
+	}
+	if err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": issue}, &response); err != nil {
+		return "", err
+	}
+	if response.Key == "" {
+		return "", fmt.Errorf("jira returned no issue key")
+	}
+	return response.Key, nil
+}
+
+func (j *jira) comment(ctx context.Context, ref, text string) error {
+	return j.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(ref)+"/comment",
+		map[string]interface{}{"body": text}, nil)
+}
+
+func (j *jira) close(ctx context.Context, ref, text string, fields map[string]interface{}) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(ref) + "/transitions"
+	var response struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"transitions"`
+	}
+	if err := j.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return err
+	}
+
+	transitionID := ""
+	for _, transition := range response.Transitions {
+		if strings.EqualFold(transition.Name, j.closeTransition) {
+			transitionID = transition.ID
+			break
+		}
+	}
+	if transitionID == "" {
+		return fmt.Errorf("issue %s has no %q transition", ref, j.closeTransition)
+	}
+
+	request := map[string]interface{}{
+		"transition": map[string]string{"id": transitionID},
+		"update": map[string]interface{}{
+			"comment": []interface{}{map[string]interface{}{"add": map[string]string{"body": text}}},
+		},
+	}
+	if len(fields) > 0 {
+		request["fields"] = fields
+	}
+	return j.do(ctx, http.MethodPost, path, request, nil)
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// ticketRequest is a request received by a fake ticketing system
+type ticketRequest struct {
+	method string
+	path   string
+	query  string
+	body   map[string]interface{}
+}
+
+// fakeTicketServer records requests and answers them with respond
+type fakeTicketServer struct {
+	mu       sync.Mutex
+	requests []ticketRequest
+}
+
+func (f *fakeTicketServer) start(t *testing.T, respond func(r ticketRequest) interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "ops" || password != "token" {
+			t.Errorf("expected basic authentication as ops, got %q", user)
+		}
+		request := ticketRequest{method: r.Method, path: r.URL.Path, query: r.URL.RawQuery}
+		if r.Body != nil && r.ContentLength != 0 {
+			_ = json.NewDecoder(r.Body).Decode(&request.body)
+		}
+		f.mu.Lock()
+		f.requests = append(f.requests, request)
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(respond(request))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeTicketServer) taken() []ticketRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = nil
+	return requests
+}
+
+func ticketCredentialsClient() client.Client {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ticketing", Namespace: "ops"},
+		Data:       map[string][]byte{ticketUsernameKey: []byte("ops"), ticketPasswordKey: []byte("token")},
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+}
+
+func ticketAlert(severity AlertSeverity) *Alert {
+	return &Alert{
+		ClusterName:      testClusterName,
+		ClusterNamespace: testNamespaceName,
+		Type:             AlertTypeStorage,
+		Severity:         severity,
+		Message:          "Storage usage is high",
+		Details:          map[string]string{"usage_percent": "85.0"},
+		Timestamp:        time.Now(),
+	}
+}
+
+func TestAlertManager_ServiceNowTickets(t *testing.T) {
+	fakeServer := &fakeTicketServer{}
+	server := fakeServer.start(t, func(r ticketRequest) interface{} {
+		switch r.method {
+		case http.MethodGet:
+			return map[string]interface{}{"result": []interface{}{}}
+		case http.MethodPost:
+			return map[string]interface{}{"result": map[string]string{"sys_id": "abc123"}}
+		default:
+			return map[string]interface{}{"result": map[string]string{}}
+		}
+	})
+
+	channels := []cnpgv1alpha1.AlertChannel{{
+		Type:     cnpgv1alpha1.AlertChannelTypeServiceNow,
+		Endpoint: server.URL + "/",
+		Ticket: &cnpgv1alpha1.TicketConfig{
+			CredentialsSecret: "ops/ticketing",
+			Fields: map[string]string{
+				"assignment_group": "DBA",
+				"urgency":          `{{ if eq .Severity "warning" }}3{{ else }}1{{ end }}`,
+				"u_cluster":        "{{ .ClusterNamespace }}/{{ .ClusterName }}",
+			},
+			ResolveFields: map[string]string{"close_code": "Solved (Permanently)"},
+		},
+	}}
+	manager := NewAlertManager(ticketCredentialsClient(), channels)
+	ctx := context.Background()
+
+	if err := manager.SendAlert(ctx, ticketAlert(AlertSeverityWarning)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests := fakeServer.taken()
+	if len(requests) != 2 {
+		t.Fatalf("expected a lookup and a create, got %+v", requests)
+	}
+	if requests[0].method != http.MethodGet || requests[0].path != "/api/now/table/incident" ||
+		!strings.Contains(requests[0].query, "correlation_id%3Dcnpg-storage-test-namespace-test-cluster-storage") {
+		t.Errorf("unexpected lookup %+v", requests[0])
+	}
+	created := requests[1].body
+	if requests[1].method != http.MethodPost {
+		t.Errorf("expected POST, got %s", requests[1].method)
+	}
+	for field, want := range map[string]string{
+		"correlation_id":    "cnpg-storage-test-namespace-test-cluster-storage",
+		"short_description": "CNPG storage alert for cluster test-namespace/test-cluster",
+		"assignment_group":  "DBA",
+		"urgency":           "3",
+		"u_cluster":         "test-namespace/test-cluster",
+	} {
+		if created[field] != want {
+			t.Errorf("expected %s %q, got %v", field, want, created[field])
+		}
+	}
+
+	// The same severity leaves the ticket alone, a new one adds a work note
+	manager.ClearSuppression(testNamespaceName, testClusterName)
+	if err := manager.SendAlert(ctx, ticketAlert(AlertSeverityWarning)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests := fakeServer.taken(); len(requests) != 0 {
+		t.Errorf("expected no requests for a repeated severity, got %+v", requests)
+	}
+	if err := manager.SendAlert(ctx, ticketAlert(AlertSeverityCritical)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests = fakeServer.taken()
+	if len(requests) != 1 || requests[0].method != http.MethodPatch || requests[0].path != "/api/now/table/incident/abc123" ||
+		!strings.HasPrefix(requests[0].body["work_notes"].(string), "Severity is now critical") {
+		t.Errorf("expected a work note, got %+v", requests)
+	}
+
+	if err := manager.ResolveAlert(ctx, ticketAlert(AlertSeverityCritical)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests = fakeServer.taken()
+	if len(requests) != 1 || requests[0].method != http.MethodPatch ||
+		requests[0].body["state"] != "6" || requests[0].body["close_code"] != "Solved (Permanently)" {
+		t.Errorf("expected the ticket to be resolved, got %+v", requests)
+	}
+
+	// Resolving again is a no-op, and a recurrence opens a new ticket right away
+	if err := manager.ResolveAlert(ctx, ticketAlert(AlertSeverityCritical)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests := fakeServer.taken(); len(requests) != 0 {
+		t.Errorf("expected no requests for a closed ticket, got %+v", requests)
+	}
+	if err := manager.SendAlert(ctx, ticketAlert(AlertSeverityCritical)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests = fakeServer.taken()
+	if len(requests) != 1 || requests[0].method != http.MethodPost || requests[0].body["urgency"] != "1" {
+		t.Errorf("expected a new ticket, got %+v", requests)
+	}
+}
+
+func TestAlertManager_JiraTickets(t *testing.T) {
+	fakeServer := &fakeTicketServer{}
+	server := fakeServer.start(t, func(r ticketRequest) interface{} {
+		switch {
+		case r.path == "/rest/api/2/search":
+			return map[string]interface{}{"issues": []map[string]string{{"key": "DBA-7"}}}
+		case strings.HasSuffix(r.path, "/transitions") && r.method == http.MethodGet:
+			return map[string]interface{}{"transitions": []map[string]string{
+				{"id": "11", "name": "In Progress"},
+				{"id": "31", "name": "Done"},
+			}}
+		default:
+			return map[string]interface{}{}
+		}
+	})
+
+	channels := []cnpgv1alpha1.AlertChannel{{
+		Type:     cnpgv1alpha1.AlertChannelTypeJira,
+		Endpoint: server.URL,
+		Ticket: &cnpgv1alpha1.TicketConfig{
+			CredentialsSecret: "ops/ticketing",
+			Project:           "DBA",
+		},
+	}}
+	manager := NewAlertManager(ticketCredentialsClient(), channels)
+	ctx := context.Background()
+
+	// An issue opened before a restart is found by its label and commented on
+	if err := manager.SendAlert(ctx, ticketAlert(AlertSeverityCritical)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests := fakeServer.taken()
+	if len(requests) != 2 {
+		t.Fatalf("expected a search and a comment, got %+v", requests)
+	}
+	if !strings.Contains(requests[0].query, "labels+%3D+%22cnpg-storage-test-namespace-test-cluster-storage%22") {
+		t.Errorf("expected the search to match the alert label, got %s", requests[0].query)
+	}
+	if requests[1].method != http.MethodPost || requests[1].path != "/rest/api/2/issue/DBA-7/comment" {
+		t.Errorf("expected a comment on DBA-7, got %+v", requests[1])
+	}
+
+	if err := manager.ResolveAlert(ctx, ticketAlert(AlertSeverityCritical)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests = fakeServer.taken()
+	if len(requests) != 2 || requests[1].method != http.MethodPost {
+		t.Fatalf("expected the transitions to be listed and applied, got %+v", requests)
+	}
+	if transition := requests[1].body["transition"].(map[string]interface{}); transition["id"] != "31" {
+		t.Errorf("expected the Done transition, got %v", transition)
+	}
+}
+
+func TestAlertManager_TicketSeverities(t *testing.T) {
+	fakeServer := &fakeTicketServer{}
+	server := fakeServer.start(t, func(ticketRequest) interface{} {
+		return map[string]interface{}{"result": map[string]string{"sys_id": "abc123"}}
+	})
+
+	channels := []cnpgv1alpha1.AlertChannel{{
+		Type:     cnpgv1alpha1.AlertChannelTypeServiceNow,
+		Endpoint: server.URL,
+		Ticket: &cnpgv1alpha1.TicketConfig{
+			CredentialsSecret: "ops/ticketing",
+			Severities:        []cnpgv1alpha1.TicketSeverity{"warning"},
+		},
+	}}
+	manager := NewAlertManager(ticketCredentialsClient(), channels)
+
+	if err := manager.SendAlert(context.Background(), ticketAlert(AlertSeverityCritical)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests := fakeServer.taken(); len(requests) != 0 {
+		t.Errorf("expected critical alerts to skip a warning-only channel, got %+v", requests)
+	}
+	if statuses := manager.ChannelStatuses(); statuses[0].SuccessCount != 0 || statuses[0].FailureCount != 0 {
+		t.Errorf("expected a skipped channel to record no delivery, got %+v", statuses[0])
+	}
+}
+
+func TestRenderTicketFields(t *testing.T) {
+	alert := ticketAlert(AlertSeverityEmergency)
+	fields, err := renderTicketFields(map[string]string{
+		"priority":   `{"name": "{{ if eq .Severity "emergency" }}Highest{{ else }}Medium{{ end }}"}`,
+		"components": `[{"name": "postgres"}]`,
+		"usage":      "{{ .Details.usage_percent }}%",
+		"missing":    "{{ .Details.nothing }}",
+		"brace":      "{not json",
+	}, alert)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"priority":   map[string]interface{}{"name": "Highest"},
+		"components": []interface{}{map[string]interface{}{"name": "postgres"}},
+		"usage":      "85.0%",
+		"missing":    "",
+		"brace":      "{not json",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("expected %v, got %v", want, fields)
+	}
+
+	if _, err := renderTicketFields(map[string]string{"bad": "{{ .Nope"}, alert); err == nil {
+		t.Error("expected an invalid template to fail")
+	}
+}
@@ -23,6 +23,9 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/clock"
 )
 
 const (
@@ -47,6 +50,7 @@ const (
 	AnnotationExpansionReason    = AnnotationPrefix + "/expansion-reason"
 	AnnotationExpansionCompleted = AnnotationPrefix + "/expansion-completed"
 	AnnotationLastExpansion      = AnnotationPrefix + "/last-expansion"
+	AnnotationExpansionBreached  = AnnotationPrefix + "/expansion-breached-since"
 
 	// WAL cleanup annotations
 	AnnotationWALCleanupLast      = AnnotationPrefix + "/wal-cleanup-last"
@@ -57,11 +61,45 @@ const (
 	AnnotationCircuitBreakerReset = AnnotationPrefix + "/reset-circuit-breaker"
 	AnnotationFailureCount        = AnnotationPrefix + "/failure-count"
 	AnnotationLastFailure         = AnnotationPrefix + "/last-failure"
+
+	// Fencing annotations
+	AnnotationFencedInstances = AnnotationPrefix + "/fenced-instances"
+
+	// StorageEvent annotations
+	AnnotationApproved = AnnotationPrefix + "/approved"
+
+	// PVC annotations. A PVC set to "true" is left out of metrics aggregation,
+	// thresholds and expansion
+	AnnotationIgnore = AnnotationPrefix + "/ignore"
+
+	// Ownership annotations, overriding the StoragePolicy metadata of a cluster
+	AnnotationOwnerTeam    = AnnotationPrefix + "/owner-team"
+	AnnotationRunbookURL   = AnnotationPrefix + "/runbook-url"
+	AnnotationEscalation   = AnnotationPrefix + "/escalation"
+	AnnotationSlackChannel = AnnotationPrefix + "/slack-channel"
 )
 
+// Ownership returns the ownership metadata of a cluster: its ownership annotations,
+// falling back field by field to defaults
+func Ownership(annotations map[string]string, defaults cnpgv1alpha1.OwnershipMetadata) cnpgv1alpha1.OwnershipMetadata {
+	ownership := defaults
+	for key, field := range map[string]*string{
+		AnnotationOwnerTeam:    &ownership.OwnerTeam,
+		AnnotationRunbookURL:   &ownership.RunbookURL,
+		AnnotationEscalation:   &ownership.Escalation,
+		AnnotationSlackChannel: &ownership.SlackChannel,
+	} {
+		if value := annotations[key]; value != "" {
+			*field = value
+		}
+	}
+	return ownership
+}
+
 // ClusterAnnotations provides helpers for reading/writing cluster annotations
 type ClusterAnnotations struct {
 	annotations map[string]string
+	clock       clock.Clock
 }
 
 // NewClusterAnnotations creates a new ClusterAnnotations from an object's annotations
@@ -73,6 +111,17 @@ func NewClusterAnnotations(obj metav1.Object) *ClusterAnnotations {
 	return &ClusterAnnotations{annotations: annotations}
 }
 
+// SetClock sets the clock pause expiry and cooldowns are checked against. The system
+// clock is used when it is not set
+func (ca *ClusterAnnotations) SetClock(c clock.Clock) {
+	ca.clock = c
+}
+
+// now returns the current time of the clock
+func (ca *ClusterAnnotations) now() time.Time {
+	return clock.OrReal(ca.clock).Now()
+}
+
 // GetAnnotations returns the underlying annotations map
 func (ca *ClusterAnnotations) GetAnnotations() map[string]string {
 	return ca.annotations
@@ -97,7 +146,7 @@ func (ca *ClusterAnnotations) IsPaused() bool {
 	// Check if pause has expired
 	if pauseUntil, ok := ca.annotations[AnnotationPauseUntil]; ok {
 		if t, err := time.Parse(time.RFC3339, pauseUntil); err == nil {
-			if time.Now().After(t) {
+			if ca.now().After(t) {
 				return false // Pause expired
 			}
 		}
@@ -194,7 +243,7 @@ func (ca *ClusterAnnotations) GetExpansionRequested() *time.Time {
 
 // SetExpansionRequested marks an expansion as requested
 func (ca *ClusterAnnotations) SetExpansionRequested(reason string) {
-	ca.annotations[AnnotationExpansionRequested] = time.Now().Format(time.RFC3339)
+	ca.annotations[AnnotationExpansionRequested] = ca.now().Format(time.RFC3339)
 	ca.annotations[AnnotationExpansionReason] = reason
 }
 
@@ -278,7 +327,7 @@ func (ca *ClusterAnnotations) SetFailureCount(count int32) {
 func (ca *ClusterAnnotations) IncrementFailureCount() int32 {
 	count := ca.GetFailureCount() + 1
 	ca.SetFailureCount(count)
-	ca.annotations[AnnotationLastFailure] = time.Now().Format(time.RFC3339)
+	ca.annotations[AnnotationLastFailure] = ca.now().Format(time.RFC3339)
 	return count
 }
 
@@ -312,8 +361,8 @@ func (ca *ClusterAnnotations) CanExpand(cooldownMinutes int32) (bool, string) {
 	if lastExpansion != nil {
 		cooldown := time.Duration(cooldownMinutes) * time.Minute
 		nextAllowed := lastExpansion.Add(cooldown)
-		if time.Now().Before(nextAllowed) {
-			remaining := time.Until(nextAllowed).Round(time.Second)
+		if now := ca.now(); now.Before(nextAllowed) {
+			remaining := nextAllowed.Sub(now).Round(time.Second)
 			return false, fmt.Sprintf("cooldown active, %s remaining", remaining)
 		}
 	}
@@ -335,8 +384,8 @@ func (ca *ClusterAnnotations) CanWALCleanup(cooldownMinutes int32) (bool, string
 	if lastCleanup != nil {
 		cooldown := time.Duration(cooldownMinutes) * time.Minute
 		nextAllowed := lastCleanup.Add(cooldown)
-		if time.Now().Before(nextAllowed) {
-			remaining := time.Until(nextAllowed).Round(time.Second)
+		if now := ca.now(); now.Before(nextAllowed) {
+			remaining := nextAllowed.Sub(now).Round(time.Second)
 			return false, fmt.Sprintf("cooldown active, %s remaining", remaining)
 		}
 	}
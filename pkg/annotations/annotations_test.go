@@ -23,6 +23,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
 )
 
 func TestNewClusterAnnotations(t *testing.T) {
@@ -358,6 +361,7 @@ func TestFailureCount(t *testing.T) {
 }
 
 func TestCanExpand(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
 	tests := []struct {
 		name            string
 		annotations     map[string]string
@@ -390,7 +394,7 @@ func TestCanExpand(t *testing.T) {
 		{
 			name: "cannot expand - cooldown active",
 			annotations: map[string]string{
-				AnnotationLastExpansion: time.Now().Add(-10 * time.Minute).Format(time.RFC3339),
+				AnnotationLastExpansion: now.Add(-10 * time.Minute).Format(time.RFC3339),
 			},
 			cooldownMinutes: 30,
 			expectAllowed:   false,
@@ -398,7 +402,7 @@ func TestCanExpand(t *testing.T) {
 		{
 			name: "can expand - cooldown expired",
 			annotations: map[string]string{
-				AnnotationLastExpansion: time.Now().Add(-60 * time.Minute).Format(time.RFC3339),
+				AnnotationLastExpansion: now.Add(-60 * time.Minute).Format(time.RFC3339),
 			},
 			cooldownMinutes: 30,
 			expectAllowed:   true,
@@ -408,6 +412,7 @@ func TestCanExpand(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ca := &ClusterAnnotations{annotations: tt.annotations}
+			ca.SetClock(clocktesting.NewFakePassiveClock(now))
 			allowed, _ := ca.CanExpand(tt.cooldownMinutes)
 			if allowed != tt.expectAllowed {
 				t.Errorf("CanExpand() = %v, want %v", allowed, tt.expectAllowed)
@@ -417,6 +422,7 @@ func TestCanExpand(t *testing.T) {
 }
 
 func TestCanWALCleanup(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
 	tests := []struct {
 		name            string
 		annotations     map[string]string
@@ -448,7 +454,7 @@ func TestCanWALCleanup(t *testing.T) {
 		{
 			name: "cannot cleanup - cooldown active",
 			annotations: map[string]string{
-				AnnotationWALCleanupLast: time.Now().Add(-5 * time.Minute).Format(time.RFC3339),
+				AnnotationWALCleanupLast: now.Add(-5 * time.Minute).Format(time.RFC3339),
 			},
 			cooldownMinutes: 15,
 			expectAllowed:   false,
@@ -456,7 +462,7 @@ func TestCanWALCleanup(t *testing.T) {
 		{
 			name: "can cleanup - cooldown expired",
 			annotations: map[string]string{
-				AnnotationWALCleanupLast: time.Now().Add(-30 * time.Minute).Format(time.RFC3339),
+				AnnotationWALCleanupLast: now.Add(-30 * time.Minute).Format(time.RFC3339),
 			},
 			cooldownMinutes: 15,
 			expectAllowed:   true,
@@ -466,6 +472,7 @@ func TestCanWALCleanup(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ca := &ClusterAnnotations{annotations: tt.annotations}
+			ca.SetClock(clocktesting.NewFakePassiveClock(now))
 			allowed, _ := ca.CanWALCleanup(tt.cooldownMinutes)
 			if allowed != tt.expectAllowed {
 				t.Errorf("CanWALCleanup() = %v, want %v", allowed, tt.expectAllowed)
@@ -473,3 +480,55 @@ func TestCanWALCleanup(t *testing.T) {
 		})
 	}
 }
+
+func TestOwnership(t *testing.T) {
+	defaults := cnpgv1alpha1.OwnershipMetadata{
+		OwnerTeam:  "platform",
+		RunbookURL: "https://runbooks.example.com/cnpg",
+		Escalation: "#platform-oncall",
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    cnpgv1alpha1.OwnershipMetadata
+	}{
+		{
+			name:     "no annotations",
+			expected: defaults,
+		},
+		{
+			name: "annotations override field by field",
+			annotations: map[string]string{
+				AnnotationOwnerTeam:  "payments",
+				AnnotationEscalation: "",
+			},
+			expected: cnpgv1alpha1.OwnershipMetadata{
+				OwnerTeam:  "payments",
+				RunbookURL: defaults.RunbookURL,
+				Escalation: defaults.Escalation,
+			},
+		},
+		{
+			name: "all annotations",
+			annotations: map[string]string{
+				AnnotationOwnerTeam:  "payments",
+				AnnotationRunbookURL: "https://runbooks.example.com/payments-db",
+				AnnotationEscalation: "payments-primary",
+			},
+			expected: cnpgv1alpha1.OwnershipMetadata{
+				OwnerTeam:  "payments",
+				RunbookURL: "https://runbooks.example.com/payments-db",
+				Escalation: "payments-primary",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Ownership(tt.annotations, defaults); got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
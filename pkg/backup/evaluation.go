@@ -0,0 +1,567 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup evaluates the backup health of CNPG clusters against a BackupPolicy.
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+// IssueType identifies a failed backup check. Values are used as metric labels.
+type IssueType string
+
+const (
+	// IssueNoBackupConfigured means the cluster has no backup method configured
+	IssueNoBackupConfigured IssueType = "no_backup_configured"
+	// IssueNoSuccessfulBackup means backups are configured but none has succeeded
+	IssueNoSuccessfulBackup IssueType = "no_successful_backup"
+	// IssueBackupTooOld means the last successful backup exceeds maxBackupAgeHours
+	IssueBackupTooOld IssueType = "backup_too_old"
+	// IssueRecoveryPointTooOld means the first recovery point exceeds maxRecoveryPointAgeHours
+	IssueRecoveryPointTooOld IssueType = "recovery_point_too_old"
+	// IssueRecoveryWindowTooShort means point-in-time recovery does not reach back minRecoveryWindowDays
+	IssueRecoveryWindowTooShort IssueType = "recovery_window_too_short"
+	// IssueArchivingNotWorking means continuous WAL archiving is failing
+	IssueArchivingNotWorking IssueType = "archiving_not_working"
+	// IssueRPOExceeded means the recovery point is older than the RPO target
+	IssueRPOExceeded IssueType = "rpo_exceeded"
+	// IssueArchiveLagExceeded means WAL segments are waiting to be archived beyond the thresholds
+	IssueArchiveLagExceeded IssueType = "archive_lag_exceeded"
+	// IssueObjectStoreProbeFailed means the object store is misconfigured or unreachable
+	IssueObjectStoreProbeFailed IssueType = "object_store_probe_failed"
+	// IssueRestoreTestFailed means the latest backup could not be restored in the last restore test
+	IssueRestoreTestFailed IssueType = "restore_test_failed"
+	// IssueScheduledBackupMissed means no backup completed after the last scheduled time
+	IssueScheduledBackupMissed IssueType = "scheduled_backup_missed"
+	// IssueNoScheduledBackup means backups are configured but no active ScheduledBackup targets the cluster
+	IssueNoScheduledBackup IssueType = "no_scheduled_backup"
+	// IssueScheduledBackupTooInfrequent means the ScheduledBackups allow gaps longer than maxBackupAgeHours
+	IssueScheduledBackupTooInfrequent IssueType = "scheduled_backup_too_infrequent"
+	// IssueMethodNotConfigured means a required backup method is not configured
+	IssueMethodNotConfigured IssueType = "method_not_configured"
+	// IssueMethodBackupTooOld means the last backup of a method exceeds its maxAgeHours
+	IssueMethodBackupTooOld IssueType = "method_backup_too_old"
+	// IssueInvalidTargetOverride means a cluster's backup target annotation cannot be parsed
+	IssueInvalidTargetOverride IssueType = "invalid_target_override"
+)
+
+// Issue is a single failed backup check
+type Issue struct {
+	Type     IssueType
+	Message  string
+	Critical bool
+}
+
+// Input contains the observed backup state of a cluster
+type Input struct {
+	Cluster cnpg.ClusterInfo
+	// ObjectStore is the backup status from the barman-cloud ObjectStore, if any
+	ObjectStore *cnpg.ObjectStoreBackupStatus
+	// Backups are the CNPG Backup objects of the cluster
+	Backups []cnpg.BackupInfo
+	// ScheduledBackups are the CNPG ScheduledBackup objects targeting the cluster
+	ScheduledBackups []cnpg.ScheduledBackupInfo
+	// Archiver is the pg_stat_archiver state of the primary, if collected
+	Archiver *metrics.ArchiverStats
+	// ObjectStoreProbe is the result of the last object store probe, if enabled
+	ObjectStoreProbe *cnpgv1alpha1.ObjectStoreProbeStatus
+	// LastRestoreTest is the outcome of the last finished restore test, if enabled
+	LastRestoreTest *cnpgv1alpha1.RestoreTestSummary
+	// PreviousFailures is the number of consecutive evaluations with issues before this one
+	PreviousFailures int32
+}
+
+// Result is the outcome of evaluating a cluster against a BackupPolicy
+type Result struct {
+	Status cnpgv1alpha1.ClusterBackupHealth
+	Issues []Issue
+	// Alert is set when the cluster has had issues for alertAfterFailures consecutive evaluations
+	Alert bool
+}
+
+// Evaluator evaluates clusters against a BackupPolicy
+type Evaluator struct {
+	spec     cnpgv1alpha1.BackupPolicySpec
+	schedule *Schedule
+}
+
+// NewEvaluator creates an evaluator for the policy. It returns an error if the
+// policy's schedule cannot be parsed.
+func NewEvaluator(spec cnpgv1alpha1.BackupPolicySpec) (*Evaluator, error) {
+	e := &Evaluator{spec: spec}
+	if spec.Schedule.Schedule != "" {
+		schedule, err := ParseSchedule(spec.Schedule.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		e.schedule = schedule
+	}
+	return e, nil
+}
+
+// Evaluate runs all checks of the policy against a cluster. Backup targets the cluster
+// overrides through annotations take precedence over the policy's
+func (e *Evaluator) Evaluate(input Input, now time.Time) Result {
+	spec, overrides, overrideErrs := applyTargetOverrides(e.spec, input.Cluster.Annotations)
+	result := (&Evaluator{spec: spec, schedule: e.schedule}).evaluate(input, now, overrideErrs)
+	result.Status.TargetOverrides = overrides
+	return result
+}
+
+// evaluate runs all checks of the effective policy against a cluster
+func (e *Evaluator) evaluate(input Input, now time.Time, overrideErrs []error) Result {
+	cluster := input.Cluster
+	result := Result{
+		Status: cnpgv1alpha1.ClusterBackupHealth{
+			Name:                       cluster.Name,
+			Namespace:                  cluster.Namespace,
+			ContinuousArchivingWorking: cluster.Status.ContinuousArchivingWorking,
+			LastChecked:                metav1.NewTime(now),
+		},
+	}
+	addIssue := func(issueType IssueType, critical bool, format string, args ...interface{}) {
+		result.Issues = append(result.Issues, Issue{
+			Type:     issueType,
+			Message:  fmt.Sprintf(format, args...),
+			Critical: critical,
+		})
+	}
+
+	lastBackup, firstRecoverability := e.backupTimes(input)
+	if lastBackup != nil {
+		t := metav1.NewTime(*lastBackup)
+		result.Status.LastBackupTime = &t
+	}
+	if firstRecoverability != nil {
+		t := metav1.NewTime(*firstRecoverability)
+		result.Status.FirstRecoverabilityPoint = &t
+	}
+
+	archivingWorking := cluster.Status.ContinuousArchivingWorking
+	if cluster.Status.BarmanCloudPlugin != nil && cluster.Status.BarmanCloudPlugin.IsWALArchiver {
+		// With barman-cloud as WAL archiver, a recovery point means archiving is working
+		archivingWorking = archivingWorking || firstRecoverability != nil
+	}
+	if input.Archiver != nil && input.Archiver.Failing {
+		// pg_stat_archiver reports failures long before the ContinuousArchiving condition flips
+		archivingWorking = false
+	}
+	result.Status.ContinuousArchivingWorking = archivingWorking
+
+	if !cluster.Status.BackupConfigured && e.spec.AlertOnNoBackupConfigured {
+		addIssue(IssueNoBackupConfigured, true, "no backup configured")
+	}
+	for _, err := range overrideErrs {
+		addIssue(IssueInvalidTargetOverride, false, "%v", err)
+	}
+
+	pending := e.pendingFirstBackup(cluster, lastBackup, now)
+	result.Status.PendingFirstBackup = pending
+
+	switch {
+	case lastBackup != nil:
+		ageHours := int32(now.Sub(*lastBackup).Hours())
+		if e.spec.MaxBackupAgeHours > 0 && ageHours > e.spec.MaxBackupAgeHours {
+			addIssue(IssueBackupTooOld, false, "last backup is %d hours old (max: %d)", ageHours, e.spec.MaxBackupAgeHours)
+		}
+	case cluster.Status.BackupConfigured && !pending:
+		addIssue(IssueNoSuccessfulBackup, true, "no successful backup recorded")
+	}
+
+	if firstRecoverability != nil && e.spec.MaxRecoveryPointAgeHours > 0 {
+		ageHours := int32(now.Sub(*firstRecoverability).Hours())
+		if ageHours > e.spec.MaxRecoveryPointAgeHours {
+			addIssue(IssueRecoveryPointTooOld, false, "first recovery point is %d hours old (max: %d)",
+				ageHours, e.spec.MaxRecoveryPointAgeHours)
+		}
+	}
+
+	e.checkRecoveryWindow(&result, firstRecoverability, now, addIssue)
+
+	// barman-cloud only reports archiving through the recovery point, which needs a first backup
+	archivingUnknown := pending && cluster.Status.BarmanCloudPlugin != nil &&
+		cluster.Status.BarmanCloudPlugin.IsWALArchiver && !cluster.Status.ContinuousArchivingWorking
+	if e.spec.RequireContinuousArchiving && cluster.Status.BackupConfigured && !archivingWorking && !archivingUnknown {
+		addIssue(IssueArchivingNotWorking, true, "continuous WAL archiving is not working")
+	}
+
+	e.checkRPO(&result, lastBackup, archivingWorking, now, addIssue)
+	e.checkArchiveLag(&result, input.Archiver, addIssue)
+	e.checkObjectStoreProbe(&result, input.ObjectStoreProbe, addIssue)
+	e.checkRestoreTest(&result, input.LastRestoreTest, addIssue)
+	if !pending {
+		e.checkSchedule(&result, lastBackup, now, addIssue)
+	} else if next, ok := e.nextScheduled(now); ok {
+		result.Status.NextExpectedBackup = &next
+	}
+	e.checkScheduledBackups(&result, input, now, addIssue)
+	e.checkMethods(&result, input, pending, addIssue)
+
+	result.Status.Health = cnpgv1alpha1.BackupHealthHealthy
+	if pending {
+		result.Status.Health = cnpgv1alpha1.BackupHealthPendingFirstBackup
+	}
+	for _, issue := range result.Issues {
+		result.Status.Issues = append(result.Status.Issues, issue.Message)
+		if issue.Critical {
+			result.Status.Health = cnpgv1alpha1.BackupHealthCritical
+		} else if result.Status.Health != cnpgv1alpha1.BackupHealthCritical {
+			result.Status.Health = cnpgv1alpha1.BackupHealthDegraded
+		}
+	}
+
+	if len(result.Issues) > 0 {
+		result.Status.ConsecutiveFailures = input.PreviousFailures + 1
+		result.Alert = result.Status.ConsecutiveFailures >= max(e.spec.AlertAfterFailures, 1)
+	}
+
+	return result
+}
+
+// pendingFirstBackup reports whether a cluster without a successful backup is still
+// within initialGracePeriodHours of its creation
+func (e *Evaluator) pendingFirstBackup(cluster cnpg.ClusterInfo, lastBackup *time.Time, now time.Time) bool {
+	if lastBackup != nil || !cluster.Status.BackupConfigured ||
+		e.spec.InitialGracePeriodHours <= 0 || cluster.CreationTimestamp.IsZero() {
+		return false
+	}
+	return now.Sub(cluster.CreationTimestamp) < time.Duration(e.spec.InitialGracePeriodHours)*time.Hour
+}
+
+// backupTimes returns the last successful backup and first recoverability point,
+// preferring the ObjectStore over the cluster status and considering Backup objects
+func (e *Evaluator) backupTimes(input Input) (*time.Time, *time.Time) {
+	lastBackup := input.Cluster.Status.LastSuccessfulBackup
+	firstRecoverability := input.Cluster.Status.FirstRecoverabilityPoint
+	if input.ObjectStore != nil {
+		if input.ObjectStore.LastSuccessfulBackupTime != nil {
+			lastBackup = input.ObjectStore.LastSuccessfulBackupTime
+		}
+		if input.ObjectStore.FirstRecoverabilityPoint != nil {
+			firstRecoverability = input.ObjectStore.FirstRecoverabilityPoint
+		}
+	}
+
+	for _, b := range input.Backups {
+		if b.Phase == cnpg.BackupPhaseCompleted && b.StoppedAt != nil &&
+			(lastBackup == nil || b.StoppedAt.After(*lastBackup)) {
+			lastBackup = b.StoppedAt
+		}
+	}
+
+	return lastBackup, firstRecoverability
+}
+
+// checkRPO compares the recovery point age against the RPO target. With working
+// continuous archiving the recovery point is current; otherwise it is the last backup.
+func (e *Evaluator) checkRPO(
+	result *Result,
+	lastBackup *time.Time,
+	archivingWorking bool,
+	now time.Time,
+	addIssue func(IssueType, bool, string, ...interface{}),
+) {
+	var ageMinutes int32
+	switch {
+	case archivingWorking:
+		// WAL is shipped continuously, so the recovery point is current
+	case lastBackup != nil:
+		ageMinutes = int32(now.Sub(*lastBackup).Minutes())
+	default:
+		return
+	}
+	result.Status.RecoveryPointAgeMinutes = &ageMinutes
+
+	if e.spec.RPOTargetMinutes > 0 && ageMinutes > e.spec.RPOTargetMinutes {
+		addIssue(IssueRPOExceeded, true, "recovery point is %d minutes old (RPO target: %d)",
+			ageMinutes, e.spec.RPOTargetMinutes)
+	}
+}
+
+// checkRecoveryWindow records how far back recovery reaches and reports a window shorter
+// than minRecoveryWindowDays
+func (e *Evaluator) checkRecoveryWindow(
+	result *Result,
+	firstRecoverability *time.Time,
+	now time.Time,
+	addIssue func(IssueType, bool, string, ...interface{}),
+) {
+	if firstRecoverability == nil {
+		return
+	}
+	windowHours := int32(now.Sub(*firstRecoverability).Hours())
+	result.Status.RecoveryWindowHours = &windowHours
+
+	if e.spec.MinRecoveryWindowDays <= 0 {
+		return
+	}
+	compliant := windowHours >= e.spec.MinRecoveryWindowDays*24
+	result.Status.RecoveryWindowCompliant = &compliant
+	if !compliant {
+		addIssue(IssueRecoveryWindowTooShort, false, "recovery window is %d days %d hours (required: %d days)",
+			windowHours/24, windowHours%24, e.spec.MinRecoveryWindowDays)
+	}
+}
+
+// checkArchiveLag compares the pg_stat_archiver lag against the thresholds
+func (e *Evaluator) checkArchiveLag(
+	result *Result,
+	stats *metrics.ArchiverStats,
+	addIssue func(IssueType, bool, string, ...interface{}),
+) {
+	if stats == nil || !stats.LagKnown {
+		return
+	}
+	segments := int32(stats.LagSegments)
+	seconds := int32(stats.LagSeconds)
+	result.Status.ArchiveLagSegments = &segments
+	result.Status.ArchiveLagSeconds = &seconds
+
+	cfg := e.spec.ArchiveLag
+	switch {
+	case cfg.MaxSegments > 0 && segments > cfg.MaxSegments:
+		addIssue(IssueArchiveLagExceeded, false, "%d WAL segments waiting to be archived (max: %d)",
+			segments, cfg.MaxSegments)
+	case cfg.MaxSeconds > 0 && seconds > cfg.MaxSeconds:
+		addIssue(IssueArchiveLagExceeded, false, "WAL segments waiting %d seconds to be archived (max: %d)",
+			seconds, cfg.MaxSeconds)
+	}
+}
+
+// checkObjectStoreProbe reports a failed object store probe
+func (e *Evaluator) checkObjectStoreProbe(
+	result *Result,
+	probe *cnpgv1alpha1.ObjectStoreProbeStatus,
+	addIssue func(IssueType, bool, string, ...interface{}),
+) {
+	if probe == nil {
+		return
+	}
+	result.Status.ObjectStoreProbe = probe
+	if probe.Phase == cnpgv1alpha1.ObjectStoreProbeFailed {
+		addIssue(IssueObjectStoreProbeFailed, false, "object store probe failed: %s", probe.Message)
+	}
+}
+
+// checkRestoreTest reports a failed restore test
+func (e *Evaluator) checkRestoreTest(
+	result *Result,
+	restoreTest *cnpgv1alpha1.RestoreTestSummary,
+	addIssue func(IssueType, bool, string, ...interface{}),
+) {
+	if restoreTest == nil {
+		return
+	}
+	result.Status.LastRestoreTest = restoreTest
+	if !restoreTest.Succeeded {
+		addIssue(IssueRestoreTestFailed, true, "restore test %s failed: %s", restoreTest.Event, restoreTest.Message)
+	}
+}
+
+// checkSchedule verifies a backup completed after the last scheduled time plus the grace period
+func (e *Evaluator) checkSchedule(
+	result *Result,
+	lastBackup *time.Time,
+	now time.Time,
+	addIssue func(IssueType, bool, string, ...interface{}),
+) {
+	if e.schedule == nil {
+		return
+	}
+
+	if next, ok := e.nextScheduled(now); ok {
+		result.Status.NextExpectedBackup = &next
+	}
+
+	grace := time.Duration(e.spec.Schedule.GracePeriodMinutes) * time.Minute
+	expected, ok := e.schedule.Prev(now.Add(-grace))
+	if !ok {
+		return
+	}
+	if lastBackup == nil || lastBackup.Before(expected) {
+		addIssue(IssueScheduledBackupMissed, false, "backup scheduled at %s was missed",
+			expected.UTC().Format(time.RFC3339))
+	}
+}
+
+// nextScheduled returns the next time a backup is expected according to the policy schedule
+func (e *Evaluator) nextScheduled(now time.Time) (metav1.Time, bool) {
+	if e.schedule == nil {
+		return metav1.Time{}, false
+	}
+	next, ok := e.schedule.Next(now)
+	return metav1.NewTime(next), ok
+}
+
+// checkScheduledBackups verifies that base backups are scheduled, not only WAL
+// archiving configured, and that the schedule keeps backups within maxBackupAgeHours
+func (e *Evaluator) checkScheduledBackups(
+	result *Result,
+	input Input,
+	now time.Time,
+	addIssue func(IssueType, bool, string, ...interface{}),
+) {
+	if !e.spec.RequireScheduledBackup || !input.Cluster.Status.BackupConfigured {
+		return
+	}
+
+	var schedules []*Schedule
+	suspended := 0
+	for _, sb := range input.ScheduledBackups {
+		if sb.Suspended {
+			suspended++
+			continue
+		}
+		result.Status.ScheduledBackups = append(result.Status.ScheduledBackups, sb.Name)
+		// Unparseable schedules still count as scheduled; CNPG reports them on the resource
+		if schedule, err := ParseSchedule(sb.Schedule); err == nil {
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	if len(result.Status.ScheduledBackups) == 0 {
+		if suspended > 0 {
+			addIssue(IssueNoScheduledBackup, false,
+				"backup configured but not scheduled: all %d ScheduledBackups are suspended", suspended)
+		} else {
+			addIssue(IssueNoScheduledBackup, false, "backup configured but not scheduled: no ScheduledBackup found")
+		}
+		return
+	}
+
+	if e.spec.MaxBackupAgeHours <= 0 || len(schedules) == 0 {
+		return
+	}
+	limit := time.Duration(e.spec.MaxBackupAgeHours) * time.Hour
+	if gap := LongestGap(schedules, now, limit); gap > limit {
+		addIssue(IssueScheduledBackupTooInfrequent, false,
+			"ScheduledBackups allow %d hours between backups (maxBackupAgeHours: %d)",
+			int64(gap.Hours()), e.spec.MaxBackupAgeHours)
+	}
+}
+
+// checkMethods records per-method status and runs per-method checks
+func (e *Evaluator) checkMethods(
+	result *Result,
+	input Input,
+	pending bool,
+	addIssue func(IssueType, bool, string, ...interface{}),
+) {
+	configured := make(map[string]bool)
+	for _, method := range input.Cluster.Status.BackupMethods {
+		configured[method] = true
+	}
+
+	methods := make([]string, 0, len(input.Cluster.Status.BackupMethods)+len(e.spec.Methods))
+	methods = append(methods, input.Cluster.Status.BackupMethods...)
+	for _, check := range e.spec.Methods {
+		if !configured[string(check.Method)] {
+			methods = append(methods, string(check.Method))
+		}
+	}
+
+	statuses := make(map[string]*cnpgv1alpha1.BackupMethodStatus, len(methods))
+	for _, method := range methods {
+		result.Status.Methods = append(result.Status.Methods, cnpgv1alpha1.BackupMethodStatus{
+			Method:     cnpgv1alpha1.BackupMethod(method),
+			Configured: configured[method],
+		})
+	}
+	for i := range result.Status.Methods {
+		statuses[string(result.Status.Methods[i].Method)] = &result.Status.Methods[i]
+	}
+
+	for _, b := range input.Backups {
+		status, ok := statuses[b.Method]
+		if !ok || b.StoppedAt == nil {
+			continue
+		}
+		switch b.Phase {
+		case cnpg.BackupPhaseCompleted:
+			if status.LastBackupTime == nil || b.StoppedAt.After(status.LastBackupTime.Time) {
+				t := metav1.NewTime(*b.StoppedAt)
+				status.LastBackupTime = &t
+			}
+		case cnpg.BackupPhaseFailed:
+			if status.LastFailedBackupTime == nil || b.StoppedAt.After(status.LastFailedBackupTime.Time) {
+				t := metav1.NewTime(*b.StoppedAt)
+				status.LastFailedBackupTime = &t
+			}
+		}
+	}
+
+	// Plugin backups are also reflected in the ObjectStore status
+	if status, ok := statuses[cnpg.BackupMethodPlugin]; ok && input.ObjectStore != nil &&
+		input.ObjectStore.LastSuccessfulBackupTime != nil &&
+		(status.LastBackupTime == nil || input.ObjectStore.LastSuccessfulBackupTime.After(status.LastBackupTime.Time)) {
+		t := metav1.NewTime(*input.ObjectStore.LastSuccessfulBackupTime)
+		status.LastBackupTime = &t
+	}
+
+	// CNPG also reports the last backup and first recovery point of each method in the
+	// cluster status, which outlives Backup objects removed by retention
+	for method, last := range input.Cluster.Status.LastSuccessfulBackupByMethod {
+		if status, ok := statuses[method]; ok && (status.LastBackupTime == nil || last.After(status.LastBackupTime.Time)) {
+			t := metav1.NewTime(last)
+			status.LastBackupTime = &t
+		}
+	}
+	for method, first := range input.Cluster.Status.FirstRecoverabilityPointByMethod {
+		if status, ok := statuses[method]; ok {
+			t := metav1.NewTime(first)
+			status.FirstRecoverabilityPoint = &t
+		}
+	}
+	if status, ok := statuses[cnpg.BackupMethodPlugin]; ok && input.ObjectStore != nil &&
+		input.ObjectStore.FirstRecoverabilityPoint != nil {
+		t := metav1.NewTime(*input.ObjectStore.FirstRecoverabilityPoint)
+		status.FirstRecoverabilityPoint = &t
+	}
+
+	for _, check := range e.spec.Methods {
+		status := statuses[string(check.Method)]
+		if !status.Configured {
+			if check.Required {
+				addIssue(IssueMethodNotConfigured, true, "required backup method %s is not configured", check.Method)
+			}
+			continue
+		}
+		if check.MaxAgeHours <= 0 {
+			continue
+		}
+		if status.LastBackupTime == nil {
+			if pending {
+				continue
+			}
+			addIssue(IssueMethodBackupTooOld, false, "no completed %s backup found (max age: %dh)",
+				check.Method, check.MaxAgeHours)
+			continue
+		}
+		ageHours := int32(result.Status.LastChecked.Sub(status.LastBackupTime.Time).Hours())
+		if ageHours > check.MaxAgeHours {
+			addIssue(IssueMethodBackupTooOld, false, "last %s backup is %d hours old (max: %d)",
+				check.Method, ageHours, check.MaxAgeHours)
+		}
+	}
+}
@@ -0,0 +1,514 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+	"time"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func hasIssue(result Result, issueType IssueType) bool {
+	for _, issue := range result.Issues {
+		if issue.Type == issueType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+
+	baseSpec := cnpgv1alpha1.BackupPolicySpec{
+		MaxBackupAgeHours:          24,
+		MaxRecoveryPointAgeHours:   168,
+		RequireContinuousArchiving: true,
+		AlertOnNoBackupConfigured:  true,
+	}
+	healthyCluster := cnpg.ClusterInfo{
+		Name:      "pg",
+		Namespace: "db",
+		Status: cnpg.ClusterStatus{
+			BackupConfigured:           true,
+			ContinuousArchivingWorking: true,
+			BackupMethods:              []string{cnpg.BackupMethodBarmanObjectStore},
+			LastSuccessfulBackup:       timePtr(now.Add(-6 * time.Hour)),
+			FirstRecoverabilityPoint:   timePtr(now.Add(-72 * time.Hour)),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		mutateSpec     func(*cnpgv1alpha1.BackupPolicySpec)
+		mutateInput    func(*Input)
+		expectHealth   cnpgv1alpha1.BackupHealth
+		expectedIssues []IssueType
+	}{
+		{
+			name:         "healthy",
+			expectHealth: cnpgv1alpha1.BackupHealthHealthy,
+		},
+		{
+			name: "no backup configured",
+			mutateInput: func(in *Input) {
+				in.Cluster.Status = cnpg.ClusterStatus{}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthCritical,
+			expectedIssues: []IssueType{IssueNoBackupConfigured},
+		},
+		{
+			name: "backup too old",
+			mutateInput: func(in *Input) {
+				in.Cluster.Status.LastSuccessfulBackup = timePtr(now.Add(-30 * time.Hour))
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueBackupTooOld},
+		},
+		{
+			name: "newer completed backup object wins over cluster status",
+			mutateInput: func(in *Input) {
+				in.Cluster.Status.LastSuccessfulBackup = timePtr(now.Add(-30 * time.Hour))
+				in.Backups = []cnpg.BackupInfo{{
+					Method:    cnpg.BackupMethodBarmanObjectStore,
+					Phase:     cnpg.BackupPhaseCompleted,
+					StoppedAt: timePtr(now.Add(-2 * time.Hour)),
+				}}
+			},
+			expectHealth: cnpgv1alpha1.BackupHealthHealthy,
+		},
+		{
+			name: "archiving broken exceeds RPO",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.RPOTargetMinutes = 60
+			},
+			mutateInput: func(in *Input) {
+				in.Cluster.Status.ContinuousArchivingWorking = false
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthCritical,
+			expectedIssues: []IssueType{IssueArchivingNotWorking, IssueRPOExceeded},
+		},
+		{
+			name: "scheduled backup missed",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.Schedule = cnpgv1alpha1.BackupScheduleConfig{Schedule: "0 0 * * *", GracePeriodMinutes: 60}
+			},
+			mutateInput: func(in *Input) {
+				in.Cluster.Status.LastSuccessfulBackup = timePtr(now.Add(-20 * time.Hour))
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueScheduledBackupMissed},
+		},
+		{
+			name: "required method missing and method backup too old",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.Methods = []cnpgv1alpha1.BackupMethodCheck{
+					{Method: cnpgv1alpha1.BackupMethodVolumeSnapshot, Required: true},
+					{Method: cnpgv1alpha1.BackupMethodBarmanObjectStore, MaxAgeHours: 12},
+				}
+			},
+			mutateInput: func(in *Input) {
+				in.Backups = []cnpg.BackupInfo{{
+					Method:    cnpg.BackupMethodBarmanObjectStore,
+					Phase:     cnpg.BackupPhaseCompleted,
+					StoppedAt: timePtr(now.Add(-13 * time.Hour)),
+				}}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthCritical,
+			expectedIssues: []IssueType{IssueMethodNotConfigured, IssueMethodBackupTooOld},
+		},
+		{
+			name: "archive lag within thresholds",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.ArchiveLag = cnpgv1alpha1.ArchiveLagConfig{Enabled: true, MaxSegments: 16, MaxSeconds: 900}
+			},
+			mutateInput: func(in *Input) {
+				in.Archiver = &metrics.ArchiverStats{LagKnown: true, LagSegments: 2, LagSeconds: 120}
+			},
+			expectHealth: cnpgv1alpha1.BackupHealthHealthy,
+		},
+		{
+			name: "archive lag exceeds segment threshold",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.ArchiveLag = cnpgv1alpha1.ArchiveLagConfig{Enabled: true, MaxSegments: 16, MaxSeconds: 900}
+			},
+			mutateInput: func(in *Input) {
+				in.Archiver = &metrics.ArchiverStats{LagKnown: true, LagSegments: 40, LagSeconds: 300}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueArchiveLagExceeded},
+		},
+		{
+			name: "archive lag exceeds seconds threshold",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.ArchiveLag = cnpgv1alpha1.ArchiveLagConfig{Enabled: true, MaxSegments: 16, MaxSeconds: 900}
+			},
+			mutateInput: func(in *Input) {
+				in.Archiver = &metrics.ArchiverStats{LagKnown: true, LagSegments: 1, LagSeconds: 1800}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueArchiveLagExceeded},
+		},
+		{
+			name: "failing archiver detected before condition flips",
+			mutateInput: func(in *Input) {
+				in.Archiver = &metrics.ArchiverStats{Failing: true}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthCritical,
+			expectedIssues: []IssueType{IssueArchivingNotWorking},
+		},
+		{
+			name: "object store probe failed",
+			mutateInput: func(in *Input) {
+				in.ObjectStoreProbe = &cnpgv1alpha1.ObjectStoreProbeStatus{
+					Phase:   cnpgv1alpha1.ObjectStoreProbeFailed,
+					Message: "secret db/s3-creds not found",
+				}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueObjectStoreProbeFailed},
+		},
+		{
+			name: "object store probe running",
+			mutateInput: func(in *Input) {
+				in.ObjectStoreProbe = &cnpgv1alpha1.ObjectStoreProbeStatus{Phase: cnpgv1alpha1.ObjectStoreProbeRunning}
+			},
+			expectHealth: cnpgv1alpha1.BackupHealthHealthy,
+		},
+		{
+			name: "recovery window meets requirement",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.MinRecoveryWindowDays = 3
+			},
+			expectHealth: cnpgv1alpha1.BackupHealthHealthy,
+		},
+		{
+			name: "recovery window too short",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.MinRecoveryWindowDays = 14
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueRecoveryWindowTooShort},
+		},
+		{
+			name: "recovery window from object store",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.MinRecoveryWindowDays = 3
+			},
+			mutateInput: func(in *Input) {
+				in.ObjectStore = &cnpg.ObjectStoreBackupStatus{FirstRecoverabilityPoint: timePtr(now.Add(-24 * time.Hour))}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueRecoveryWindowTooShort},
+		},
+		{
+			name: "restore test failed",
+			mutateInput: func(in *Input) {
+				in.LastRestoreTest = &cnpgv1alpha1.RestoreTestSummary{
+					Event:   "pg-restore-test-abcde",
+					Message: "step wait-ready: recovery cluster not ready after 1h0m0s",
+				}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthCritical,
+			expectedIssues: []IssueType{IssueRestoreTestFailed},
+		},
+		{
+			name: "restore test succeeded",
+			mutateInput: func(in *Input) {
+				in.LastRestoreTest = &cnpgv1alpha1.RestoreTestSummary{Event: "pg-restore-test-abcde", Succeeded: true}
+			},
+			expectHealth: cnpgv1alpha1.BackupHealthHealthy,
+		},
+		{
+			name: "backup configured but not scheduled",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.RequireScheduledBackup = true
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueNoScheduledBackup},
+		},
+		{
+			name: "all scheduled backups suspended",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.RequireScheduledBackup = true
+			},
+			mutateInput: func(in *Input) {
+				in.ScheduledBackups = []cnpg.ScheduledBackupInfo{{Name: "daily", Schedule: "0 0 2 * * *", Suspended: true}}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueNoScheduledBackup},
+		},
+		{
+			name: "daily scheduled backup is compatible with max age",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.RequireScheduledBackup = true
+			},
+			mutateInput: func(in *Input) {
+				in.ScheduledBackups = []cnpg.ScheduledBackupInfo{{Name: "daily", Schedule: "0 0 2 * * *"}}
+			},
+			expectHealth: cnpgv1alpha1.BackupHealthHealthy,
+		},
+		{
+			name: "weekly scheduled backup exceeds max age",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.RequireScheduledBackup = true
+			},
+			mutateInput: func(in *Input) {
+				in.ScheduledBackups = []cnpg.ScheduledBackupInfo{{Name: "weekly", Schedule: "0 0 2 * * 0"}}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueScheduledBackupTooInfrequent},
+		},
+		{
+			name: "scheduled backup check ignored without backup configured",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.RequireScheduledBackup = true
+			},
+			mutateInput: func(in *Input) {
+				in.Cluster.Status = cnpg.ClusterStatus{}
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthCritical,
+			expectedIssues: []IssueType{IssueNoBackupConfigured},
+		},
+		{
+			name: "new cluster pending first backup",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.InitialGracePeriodHours = 24
+				spec.Schedule.Schedule = "0 0 2 * * *"
+				spec.Methods = []cnpgv1alpha1.BackupMethodCheck{{Method: cnpgv1alpha1.BackupMethodPlugin, MaxAgeHours: 24}}
+			},
+			mutateInput: func(in *Input) {
+				in.Cluster.CreationTimestamp = now.Add(-10 * time.Hour)
+				in.Cluster.Status.LastSuccessfulBackup = nil
+				in.Cluster.Status.FirstRecoverabilityPoint = nil
+				in.Cluster.Status.ContinuousArchivingWorking = false
+				in.Cluster.Status.BackupMethods = []string{cnpg.BackupMethodPlugin}
+				in.Cluster.Status.BarmanCloudPlugin = &cnpg.BarmanCloudPluginInfo{Enabled: true, IsWALArchiver: true}
+			},
+			expectHealth: cnpgv1alpha1.BackupHealthPendingFirstBackup,
+		},
+		{
+			name: "pending cluster still reports other issues",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.InitialGracePeriodHours = 24
+				spec.RequireScheduledBackup = true
+			},
+			mutateInput: func(in *Input) {
+				in.Cluster.CreationTimestamp = now.Add(-10 * time.Hour)
+				in.Cluster.Status.LastSuccessfulBackup = nil
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthDegraded,
+			expectedIssues: []IssueType{IssueNoScheduledBackup},
+		},
+		{
+			name: "first backup overdue after grace period",
+			mutateSpec: func(spec *cnpgv1alpha1.BackupPolicySpec) {
+				spec.InitialGracePeriodHours = 24
+			},
+			mutateInput: func(in *Input) {
+				in.Cluster.CreationTimestamp = now.Add(-30 * time.Hour)
+				in.Cluster.Status.LastSuccessfulBackup = nil
+			},
+			expectHealth:   cnpgv1alpha1.BackupHealthCritical,
+			expectedIssues: []IssueType{IssueNoSuccessfulBackup},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := baseSpec
+			if tt.mutateSpec != nil {
+				tt.mutateSpec(&spec)
+			}
+			input := Input{Cluster: healthyCluster}
+			if tt.mutateInput != nil {
+				tt.mutateInput(&input)
+			}
+
+			evaluator, err := NewEvaluator(spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result := evaluator.Evaluate(input, now)
+
+			if result.Status.Health != tt.expectHealth {
+				t.Errorf("expected health %s, got %s (issues: %v)", tt.expectHealth, result.Status.Health, result.Status.Issues)
+			}
+			if len(result.Issues) != len(tt.expectedIssues) {
+				t.Errorf("expected %d issues, got %v", len(tt.expectedIssues), result.Status.Issues)
+			}
+			for _, issueType := range tt.expectedIssues {
+				if !hasIssue(result, issueType) {
+					t.Errorf("expected issue %s, got %v", issueType, result.Status.Issues)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluator_AlertAfterFailures(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	evaluator, err := NewEvaluator(cnpgv1alpha1.BackupPolicySpec{AlertOnNoBackupConfigured: true, AlertAfterFailures: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unconfigured := Input{Cluster: cnpg.ClusterInfo{Name: "pg", Namespace: "db"}}
+	for i, expectAlert := range []bool{false, false, true, true} {
+		result := evaluator.Evaluate(unconfigured, now)
+		if result.Status.ConsecutiveFailures != int32(i+1) {
+			t.Errorf("evaluation %d: expected %d consecutive failures, got %d", i+1, i+1, result.Status.ConsecutiveFailures)
+		}
+		if result.Alert != expectAlert {
+			t.Errorf("evaluation %d: expected alert %v, got %v", i+1, expectAlert, result.Alert)
+		}
+		unconfigured.PreviousFailures = result.Status.ConsecutiveFailures
+	}
+
+	healthy := Input{
+		Cluster: cnpg.ClusterInfo{Name: "pg", Namespace: "db", Status: cnpg.ClusterStatus{
+			BackupConfigured:           true,
+			ContinuousArchivingWorking: true,
+			LastSuccessfulBackup:       timePtr(now.Add(-time.Hour)),
+		}},
+		PreviousFailures: 5,
+	}
+	result := evaluator.Evaluate(healthy, now)
+	if result.Status.ConsecutiveFailures != 0 || result.Alert {
+		t.Errorf("expected a healthy evaluation to reset the count, got %d (alert=%v)",
+			result.Status.ConsecutiveFailures, result.Alert)
+	}
+
+	// Policies created before alertAfterFailures existed alert on the first failure
+	evaluator, _ = NewEvaluator(cnpgv1alpha1.BackupPolicySpec{AlertOnNoBackupConfigured: true})
+	if result := evaluator.Evaluate(Input{Cluster: cnpg.ClusterInfo{Name: "pg"}}, now); !result.Alert {
+		t.Error("expected an alert on the first failure when alertAfterFailures is unset")
+	}
+}
+
+func TestEvaluator_RecoveryWindowStatus(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	input := Input{Cluster: cnpg.ClusterInfo{Status: cnpg.ClusterStatus{
+		BackupConfigured:         true,
+		FirstRecoverabilityPoint: timePtr(now.Add(-50 * time.Hour)),
+	}}}
+
+	evaluator, err := NewEvaluator(cnpgv1alpha1.BackupPolicySpec{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status := evaluator.Evaluate(input, now).Status
+	if status.RecoveryWindowHours == nil || *status.RecoveryWindowHours != 50 {
+		t.Errorf("expected a 50h recovery window, got %v", status.RecoveryWindowHours)
+	}
+	if status.RecoveryWindowCompliant != nil {
+		t.Errorf("expected no compliance without a requirement, got %v", *status.RecoveryWindowCompliant)
+	}
+
+	evaluator, err = NewEvaluator(cnpgv1alpha1.BackupPolicySpec{MinRecoveryWindowDays: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status = evaluator.Evaluate(input, now).Status
+	if status.RecoveryWindowCompliant == nil || !*status.RecoveryWindowCompliant {
+		t.Errorf("expected a 50h window to meet a 2 day requirement, got %v", status.RecoveryWindowCompliant)
+	}
+}
+
+func TestEvaluator_MethodStatus(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	evaluator, err := NewEvaluator(cnpgv1alpha1.BackupPolicySpec{
+		Methods: []cnpgv1alpha1.BackupMethodCheck{{Method: cnpgv1alpha1.BackupMethodVolumeSnapshot}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := evaluator.Evaluate(Input{
+		Cluster: cnpg.ClusterInfo{Status: cnpg.ClusterStatus{
+			BackupConfigured: true,
+			BackupMethods:    []string{cnpg.BackupMethodPlugin},
+		}},
+		ObjectStore: &cnpg.ObjectStoreBackupStatus{LastSuccessfulBackupTime: timePtr(now.Add(-time.Hour))},
+		Backups: []cnpg.BackupInfo{
+			{Method: cnpg.BackupMethodPlugin, Phase: cnpg.BackupPhaseFailed, StoppedAt: timePtr(now.Add(-30 * time.Minute))},
+		},
+	}, now)
+
+	if len(result.Status.Methods) != 2 {
+		t.Fatalf("expected 2 method statuses, got %v", result.Status.Methods)
+	}
+	plugin := result.Status.Methods[0]
+	if !plugin.Configured || plugin.LastBackupTime == nil || plugin.LastFailedBackupTime == nil {
+		t.Errorf("expected plugin status with success from ObjectStore and a failure, got %+v", plugin)
+	}
+	if snapshot := result.Status.Methods[1]; snapshot.Configured {
+		t.Errorf("expected volumeSnapshot to be reported as not configured, got %+v", snapshot)
+	}
+}
+
+func TestEvaluator_MethodStatusFromClusterStatus(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	evaluator, err := NewEvaluator(cnpgv1alpha1.BackupPolicySpec{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := evaluator.Evaluate(Input{
+		Cluster: cnpg.ClusterInfo{Status: cnpg.ClusterStatus{
+			BackupConfigured: true,
+			BackupMethods:    []string{cnpg.BackupMethodBarmanObjectStore, cnpg.BackupMethodVolumeSnapshot},
+			LastSuccessfulBackupByMethod: map[string]time.Time{
+				cnpg.BackupMethodBarmanObjectStore: now.Add(-2 * time.Hour),
+				cnpg.BackupMethodVolumeSnapshot:    now.Add(-48 * time.Hour),
+			},
+			FirstRecoverabilityPointByMethod: map[string]time.Time{
+				cnpg.BackupMethodBarmanObjectStore: now.Add(-72 * time.Hour),
+			},
+		}},
+		// A newer Backup object wins over the cluster status
+		Backups: []cnpg.BackupInfo{
+			{Method: cnpg.BackupMethodVolumeSnapshot, Phase: cnpg.BackupPhaseCompleted, StoppedAt: timePtr(now.Add(-time.Hour))},
+		},
+	}, now)
+
+	barman, snapshot := result.Status.Methods[0], result.Status.Methods[1]
+	if barman.LastBackupTime == nil || !barman.LastBackupTime.Time.Equal(now.Add(-2*time.Hour)) ||
+		barman.FirstRecoverabilityPoint == nil || !barman.FirstRecoverabilityPoint.Time.Equal(now.Add(-72*time.Hour)) {
+		t.Errorf("expected barmanObjectStore times from the cluster status, got %+v", barman)
+	}
+	if snapshot.LastBackupTime == nil || !snapshot.LastBackupTime.Time.Equal(now.Add(-time.Hour)) ||
+		snapshot.FirstRecoverabilityPoint != nil {
+		t.Errorf("expected the newer volumeSnapshot Backup and no recovery point, got %+v", snapshot)
+	}
+}
+
+func TestNewEvaluator_InvalidSchedule(t *testing.T) {
+	_, err := NewEvaluator(cnpgv1alpha1.BackupPolicySpec{
+		Schedule: cnpgv1alpha1.BackupScheduleConfig{Schedule: "not a cron"},
+	})
+	if err == nil {
+		t.Error("expected error for invalid schedule")
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"strconv"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// AnnotationPrefix is the prefix of the backup target annotations of CNPG clusters
+	AnnotationPrefix = "backup.cnpg.supporttools.io"
+
+	// AnnotationMaxBackupAgeHours overrides the policy's maxBackupAgeHours for a cluster
+	AnnotationMaxBackupAgeHours = AnnotationPrefix + "/max-age-hours"
+	// AnnotationRPOTargetMinutes overrides the policy's rpoTargetMinutes for a cluster
+	AnnotationRPOTargetMinutes = AnnotationPrefix + "/rpo-target-minutes"
+)
+
+// MaxBackupAgeHours returns the maximum backup age of a cluster: its max-age-hours
+// annotation when set to a valid value, otherwise the policy's
+func MaxBackupAgeHours(annotations map[string]string, policyHours int32) int32 {
+	if hours, ok, err := parseTarget(annotations, AnnotationMaxBackupAgeHours); ok && err == nil {
+		return hours
+	}
+	return policyHours
+}
+
+// applyTargetOverrides returns the policy spec with the backup targets a cluster declares
+// through annotations applied, and the overrides that took effect. Annotations that are
+// not non-negative integers are ignored and returned as errors.
+func applyTargetOverrides(
+	spec cnpgv1alpha1.BackupPolicySpec,
+	annotations map[string]string,
+) (cnpgv1alpha1.BackupPolicySpec, *cnpgv1alpha1.BackupTargetOverrides, []error) {
+	var overrides cnpgv1alpha1.BackupTargetOverrides
+	var errs []error
+
+	for _, target := range []struct {
+		key      string
+		spec     *int32
+		override **int32
+	}{
+		{AnnotationMaxBackupAgeHours, &spec.MaxBackupAgeHours, &overrides.MaxBackupAgeHours},
+		{AnnotationRPOTargetMinutes, &spec.RPOTargetMinutes, &overrides.RPOTargetMinutes},
+	} {
+		value, ok, err := parseTarget(annotations, target.key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			*target.spec = value
+			*target.override = &value
+		}
+	}
+
+	if overrides == (cnpgv1alpha1.BackupTargetOverrides{}) {
+		return spec, nil, errs
+	}
+	return spec, &overrides, errs
+}
+
+// parseTarget parses a backup target annotation, which must be a non-negative integer
+func parseTarget(annotations map[string]string, key string) (int32, bool, error) {
+	value, ok := annotations[key]
+	if !ok {
+		return 0, false, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || parsed < 0 {
+		return 0, false, fmt.Errorf("annotation %s=%q ignored: must be a non-negative integer", key, value)
+	}
+	return int32(parsed), true, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+	"time"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+func TestMaxBackupAgeHours(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expect      int32
+	}{
+		{name: "no annotation", expect: 24},
+		{name: "override", annotations: map[string]string{AnnotationMaxBackupAgeHours: "4"}, expect: 4},
+		{name: "disabled", annotations: map[string]string{AnnotationMaxBackupAgeHours: "0"}, expect: 0},
+		{name: "invalid", annotations: map[string]string{AnnotationMaxBackupAgeHours: "4h"}, expect: 24},
+		{name: "negative", annotations: map[string]string{AnnotationMaxBackupAgeHours: "-1"}, expect: 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaxBackupAgeHours(tt.annotations, 24); got != tt.expect {
+				t.Errorf("expected %d, got %d", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestEvaluator_TargetOverrides(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	evaluator, err := NewEvaluator(cnpgv1alpha1.BackupPolicySpec{MaxBackupAgeHours: 24, RPOTargetMinutes: 600})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cluster := cnpg.ClusterInfo{
+		Name:      "pg",
+		Namespace: "db",
+		Status: cnpg.ClusterStatus{
+			BackupConfigured:     true,
+			LastSuccessfulBackup: timePtr(now.Add(-6 * time.Hour)),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		expectedIssues []IssueType
+		expectMaxAge   *int32
+	}{
+		{name: "policy targets"},
+		{
+			name: "stricter cluster targets",
+			annotations: map[string]string{
+				AnnotationMaxBackupAgeHours: "4",
+				AnnotationRPOTargetMinutes:  "60",
+			},
+			expectedIssues: []IssueType{IssueBackupTooOld, IssueRPOExceeded},
+			expectMaxAge:   ptrInt32(4),
+		},
+		{
+			name:           "invalid annotation keeps the policy target",
+			annotations:    map[string]string{AnnotationMaxBackupAgeHours: "four"},
+			expectedIssues: []IssueType{IssueInvalidTargetOverride},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := Input{Cluster: cluster}
+			input.Cluster.Annotations = tt.annotations
+			result := evaluator.Evaluate(input, now)
+
+			if len(result.Issues) != len(tt.expectedIssues) {
+				t.Errorf("expected %d issues, got %v", len(tt.expectedIssues), result.Status.Issues)
+			}
+			for _, issueType := range tt.expectedIssues {
+				if !hasIssue(result, issueType) {
+					t.Errorf("expected issue %s, got %v", issueType, result.Status.Issues)
+				}
+			}
+
+			overrides := result.Status.TargetOverrides
+			switch {
+			case tt.expectMaxAge == nil && overrides != nil:
+				t.Errorf("expected no overrides, got %+v", overrides)
+			case tt.expectMaxAge != nil && (overrides == nil || overrides.MaxBackupAgeHours == nil ||
+				*overrides.MaxBackupAgeHours != *tt.expectMaxAge):
+				t.Errorf("expected max age override %d, got %+v", *tt.expectMaxAge, overrides)
+			}
+		})
+	}
+}
+
+func ptrInt32(v int32) *int32 {
+	return &v
+}
@@ -0,0 +1,359 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+const (
+	// LabelProbeCluster is set on probe Jobs to the name of the probed cluster
+	LabelProbeCluster = "cnpg.supporttools.io/probe-cluster"
+
+	// DefaultProbeInterval is the default interval between probes of a cluster's object store
+	DefaultProbeInterval = time.Hour
+	// DefaultProbeTimeout is the default time allowed for a probe Job to finish
+	DefaultProbeTimeout = 2 * time.Minute
+
+	// probeJobTTLSeconds lets Kubernetes garbage collect probe Jobs that were not deleted
+	probeJobTTLSeconds = 3600
+	// maxProbeJobNamePrefix keeps generated Job names within the label value limit
+	maxProbeJobNamePrefix = 40
+	// credentialsMountPath is where file-based credentials are mounted in probe pods
+	credentialsMountPath = "/probe/credentials"
+	// endpointCAMountPath is where the endpoint CA bundle is mounted in probe pods
+	endpointCAMountPath = "/probe/endpoint-ca"
+)
+
+// ObjectStoreProber validates object store configurations and, when an image is
+// configured, lists the bucket with barman-cloud-backup-list in a short-lived Job.
+// Probe Jobs are started and collected across reconciles so the reconciler never
+// waits for them.
+type ObjectStoreProber struct {
+	client client.Client
+	// reader reads Secrets, Jobs and Pods without a cache, so the manager does not
+	// need to watch them cluster-wide
+	reader client.Reader
+}
+
+// NewObjectStoreProber creates a prober. Jobs are created and deleted with c and read with reader.
+func NewObjectStoreProber(c client.Client, reader client.Reader) *ObjectStoreProber {
+	return &ObjectStoreProber{client: c, reader: reader}
+}
+
+// Probe returns the current probe status of a cluster's object store. previous is the
+// status recorded by the last reconcile; it is returned unchanged until the interval
+// has elapsed, and its Job is collected once it finished.
+func (p *ObjectStoreProber) Probe(
+	ctx context.Context,
+	cluster cnpg.ClusterInfo,
+	cfg *cnpg.ObjectStoreConfig,
+	spec cnpgv1alpha1.ObjectStoreProbeConfig,
+	previous *cnpgv1alpha1.ObjectStoreProbeStatus,
+	now time.Time,
+) *cnpgv1alpha1.ObjectStoreProbeStatus {
+	if previous != nil && previous.Source == cfg.Source {
+		if previous.Phase == cnpgv1alpha1.ObjectStoreProbeRunning && previous.Job != "" {
+			status, done, exists := p.collect(ctx, cfg, previous, now)
+			if done {
+				return status
+			}
+			if exists {
+				return previous
+			}
+		} else if previous.LastProbeTime != nil && now.Sub(previous.LastProbeTime.Time) < probeInterval(spec) {
+			return previous
+		}
+	}
+
+	status := &cnpgv1alpha1.ObjectStoreProbeStatus{Source: cfg.Source}
+	if err := cfg.Validate(); err != nil {
+		return finishProbe(status, cnpgv1alpha1.ObjectStoreProbeFailed, err.Error(), now)
+	}
+	problem, err := p.validateSecrets(ctx, cfg)
+	if err != nil {
+		// Keep the previous outcome; the probe is retried on the next reconcile
+		log.FromContext(ctx).Error(err, "Failed to validate object store credentials", "cluster", cluster.Name)
+		return previous
+	}
+	if problem != "" {
+		return finishProbe(status, cnpgv1alpha1.ObjectStoreProbeFailed, problem, now)
+	}
+	if spec.Image == "" {
+		return finishProbe(status, cnpgv1alpha1.ObjectStoreProbePassed, "configuration and credentials are valid", now)
+	}
+
+	job := buildProbeJob(cluster, cfg, spec)
+	if err := p.client.Create(ctx, job); err != nil {
+		// Keep the previous outcome; the probe is retried on the next reconcile
+		log.FromContext(ctx).Error(err, "Failed to create object store probe job", "cluster", cluster.Name)
+		return previous
+	}
+	status.Phase = cnpgv1alpha1.ObjectStoreProbeRunning
+	status.Job = job.Name
+	status.Message = fmt.Sprintf("probe job %s started", job.Name)
+	if previous != nil {
+		status.LastProbeTime = previous.LastProbeTime
+	}
+	return status
+}
+
+// collect checks the probe Job of a running probe. It reports done with the outcome
+// when the Job finished, and exists=false when the Job is gone and a new probe is needed.
+func (p *ObjectStoreProber) collect(
+	ctx context.Context,
+	cfg *cnpg.ObjectStoreConfig,
+	previous *cnpgv1alpha1.ObjectStoreProbeStatus,
+	now time.Time,
+) (status *cnpgv1alpha1.ObjectStoreProbeStatus, done, exists bool) {
+	logger := log.FromContext(ctx)
+
+	var job batchv1.Job
+	if err := p.reader.Get(ctx, client.ObjectKey{Name: previous.Job, Namespace: cfg.Namespace}, &job); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Deleted or garbage collected before it was collected
+			return nil, false, false
+		}
+		logger.Error(err, "Failed to get object store probe job", "job", previous.Job)
+		return nil, false, true
+	}
+
+	var phase cnpgv1alpha1.ObjectStoreProbePhase
+	var message string
+	switch {
+	case job.Status.Succeeded > 0:
+		phase = cnpgv1alpha1.ObjectStoreProbePassed
+		message = "object store is reachable with the configured credentials"
+	case job.Status.Failed > 0 || jobFailed(&job):
+		phase = cnpgv1alpha1.ObjectStoreProbeFailed
+		message = p.failureMessage(ctx, &job)
+	default:
+		return nil, false, true
+	}
+
+	if err := p.client.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil &&
+		!apierrors.IsNotFound(err) {
+		logger.Error(err, "Failed to delete object store probe job", "job", job.Name)
+	}
+
+	status = &cnpgv1alpha1.ObjectStoreProbeStatus{Source: cfg.Source}
+	return finishProbe(status, phase, message, now), true, true
+}
+
+// failureMessage returns the termination message of the probe pod, which holds the
+// tail of the barman-cloud output
+func (p *ObjectStoreProber) failureMessage(ctx context.Context, job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Reason == "DeadlineExceeded" {
+			return fmt.Sprintf("probe job %s timed out", job.Name)
+		}
+	}
+
+	var pods corev1.PodList
+	if err := p.reader.List(ctx, &pods,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{"job-name": job.Name},
+	); err == nil {
+		for _, pod := range pods.Items {
+			for _, cs := range pod.Status.ContainerStatuses {
+				if cs.State.Terminated != nil && cs.State.Terminated.Message != "" {
+					return lastLine(cs.State.Terminated.Message)
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("probe job %s failed", job.Name)
+}
+
+// validateSecrets checks that every Secret key referenced by the configuration exists.
+// It returns the problem found, or an error when the Secrets could not be read.
+func (p *ObjectStoreProber) validateSecrets(ctx context.Context, cfg *cnpg.ObjectStoreConfig) (string, error) {
+	secrets := make(map[string]*corev1.Secret)
+	for _, ref := range cfg.SecretRefs() {
+		secret, ok := secrets[ref.Name]
+		if !ok {
+			secret = &corev1.Secret{}
+			if err := p.reader.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: cfg.Namespace}, secret); err != nil {
+				if apierrors.IsNotFound(err) {
+					return fmt.Sprintf("%s: secret %s/%s not found", cfg.Source, cfg.Namespace, ref.Name), nil
+				}
+				return "", fmt.Errorf("failed to get secret %s/%s: %w", cfg.Namespace, ref.Name, err)
+			}
+			secrets[ref.Name] = secret
+		}
+		if len(secret.Data[ref.Key]) == 0 {
+			return fmt.Sprintf("%s: key %s not found in secret %s/%s", cfg.Source, ref.Key, cfg.Namespace, ref.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// buildProbeJob builds a Job that lists the cluster's backups in the object store
+func buildProbeJob(
+	cluster cnpg.ClusterInfo,
+	cfg *cnpg.ObjectStoreConfig,
+	spec cnpgv1alpha1.ObjectStoreProbeConfig,
+) *batchv1.Job {
+	command := []string{"barman-cloud-backup-list", "--cloud-provider", cfg.Provider}
+	if cfg.EndpointURL != "" {
+		command = append(command, "--endpoint-url", cfg.EndpointURL)
+	}
+	command = append(command, cfg.DestinationPath, cfg.ServerName)
+
+	var env []corev1.EnvVar
+	for _, cred := range cfg.Credentials {
+		env = append(env, corev1.EnvVar{
+			Name: cred.Env,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cred.Ref.Name},
+				Key:                  cred.Ref.Key,
+			}},
+		})
+	}
+
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	mountSecret := func(name, mountPath string, ref *cnpg.SecretKeyRef, envNames ...string) {
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: ref.Name,
+				Items:      []corev1.KeyToPath{{Key: ref.Key, Path: name}},
+			}},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: name, MountPath: mountPath, ReadOnly: true})
+		for _, envName := range envNames {
+			env = append(env, corev1.EnvVar{Name: envName, Value: mountPath + "/" + name})
+		}
+	}
+	if cfg.CredentialFile != nil {
+		mountSecret("credentials", credentialsMountPath, cfg.CredentialFile, "GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if cfg.EndpointCA != nil {
+		mountSecret("endpoint-ca", endpointCAMountPath, cfg.EndpointCA, "AWS_CA_BUNDLE", "REQUESTS_CA_BUNDLE")
+	}
+
+	// Inherited credentials come from the identity of the cluster's service account,
+	// which CNPG names after the cluster
+	serviceAccount := ""
+	if cfg.InheritedCredentials && cfg.Namespace == cluster.Namespace {
+		serviceAccount = cluster.Name
+	}
+
+	prefix := cluster.Name
+	if len(prefix) > maxProbeJobNamePrefix {
+		prefix = prefix[:maxProbeJobNamePrefix]
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "cnpg-storage-manager",
+		"app.kubernetes.io/component":  "objectstore-probe",
+		LabelProbeCluster:              cluster.Name,
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: prefix + "-objectstore-probe-",
+			Namespace:    cfg.Namespace,
+			Labels:       labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To[int32](0),
+			ActiveDeadlineSeconds:   ptr.To(int64(probeTimeout(spec).Seconds())),
+			TTLSecondsAfterFinished: ptr.To[int32](probeJobTTLSeconds),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					ServiceAccountName:           serviceAccount,
+					AutomountServiceAccountToken: ptr.To(serviceAccount != ""),
+					Volumes:                      volumes,
+					Containers: []corev1.Container{{
+						Name:                     "probe",
+						Image:                    spec.Image,
+						Command:                  command,
+						Env:                      env,
+						VolumeMounts:             mounts,
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: ptr.To(false),
+							Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// jobFailed reports whether the Job has a Failed condition
+func jobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// finishProbe sets the outcome of a completed probe
+func finishProbe(
+	status *cnpgv1alpha1.ObjectStoreProbeStatus,
+	phase cnpgv1alpha1.ObjectStoreProbePhase,
+	message string,
+	now time.Time,
+) *cnpgv1alpha1.ObjectStoreProbeStatus {
+	t := metav1.NewTime(now)
+	status.Phase = phase
+	status.Message = message
+	status.Job = ""
+	status.LastProbeTime = &t
+	return status
+}
+
+// lastLine returns the last non-empty line of the output, which carries the barman-cloud error
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func probeInterval(spec cnpgv1alpha1.ObjectStoreProbeConfig) time.Duration {
+	if spec.IntervalMinutes <= 0 {
+		return DefaultProbeInterval
+	}
+	return time.Duration(spec.IntervalMinutes) * time.Minute
+}
+
+func probeTimeout(spec cnpgv1alpha1.ObjectStoreProbeConfig) time.Duration {
+	if spec.TimeoutSeconds <= 0 {
+		return DefaultProbeTimeout
+	}
+	return time.Duration(spec.TimeoutSeconds) * time.Second
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+func newProbeClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&batchv1.Job{}).Build()
+}
+
+func s3Config() *cnpg.ObjectStoreConfig {
+	return &cnpg.ObjectStoreConfig{
+		Source:          "ObjectStore/minio",
+		Namespace:       "db",
+		DestinationPath: "s3://backups/",
+		EndpointURL:     "https://minio:9000",
+		ServerName:      "pg",
+		Provider:        cnpg.ProviderS3,
+		Credentials: []cnpg.ObjectStoreCredential{
+			{Env: "AWS_ACCESS_KEY_ID", Ref: cnpg.SecretKeyRef{Name: "s3-creds", Key: "ACCESS_KEY_ID"}},
+			{Env: "AWS_SECRET_ACCESS_KEY", Ref: cnpg.SecretKeyRef{Name: "s3-creds", Key: "ACCESS_SECRET_KEY"}},
+		},
+	}
+}
+
+func s3Secret(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-creds", Namespace: "db"},
+		Data:       data,
+	}
+}
+
+func TestObjectStoreProber_Validation(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+	spec := cnpgv1alpha1.ObjectStoreProbeConfig{Enabled: true, IntervalMinutes: 60}
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		objs          []client.Object
+		expectPhase   cnpgv1alpha1.ObjectStoreProbePhase
+		expectMessage string
+	}{
+		{
+			name:          "secret missing",
+			expectPhase:   cnpgv1alpha1.ObjectStoreProbeFailed,
+			expectMessage: "secret db/s3-creds not found",
+		},
+		{
+			name:          "key missing",
+			objs:          []client.Object{s3Secret(map[string][]byte{"ACCESS_KEY_ID": []byte("id")})},
+			expectPhase:   cnpgv1alpha1.ObjectStoreProbeFailed,
+			expectMessage: "key ACCESS_SECRET_KEY not found",
+		},
+		{
+			name: "valid",
+			objs: []client.Object{s3Secret(map[string][]byte{
+				"ACCESS_KEY_ID":     []byte("id"),
+				"ACCESS_SECRET_KEY": []byte("secret"),
+			})},
+			expectPhase: cnpgv1alpha1.ObjectStoreProbePassed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newProbeClient(tt.objs...)
+			status := NewObjectStoreProber(c, c).Probe(ctx, cluster, s3Config(), spec, nil, now)
+			if status.Phase != tt.expectPhase {
+				t.Fatalf("expected phase %s, got %s (%s)", tt.expectPhase, status.Phase, status.Message)
+			}
+			if !strings.Contains(status.Message, tt.expectMessage) {
+				t.Errorf("expected message containing %q, got %q", tt.expectMessage, status.Message)
+			}
+			if status.LastProbeTime == nil || !status.LastProbeTime.Equal(&metav1.Time{Time: now}) {
+				t.Errorf("expected LastProbeTime %v, got %v", now, status.LastProbeTime)
+			}
+		})
+	}
+}
+
+func TestObjectStoreProber_Interval(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	c := newProbeClient()
+	prober := NewObjectStoreProber(c, c)
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+	spec := cnpgv1alpha1.ObjectStoreProbeConfig{Enabled: true, IntervalMinutes: 60}
+
+	previous := &cnpgv1alpha1.ObjectStoreProbeStatus{
+		Source:        "ObjectStore/minio",
+		Phase:         cnpgv1alpha1.ObjectStoreProbePassed,
+		LastProbeTime: &metav1.Time{Time: now.Add(-30 * time.Minute)},
+	}
+	if status := prober.Probe(context.Background(), cluster, s3Config(), spec, previous, now); status != previous {
+		t.Errorf("expected previous result within the interval, got %+v", status)
+	}
+
+	// Once the interval elapsed the missing secret is detected
+	status := prober.Probe(context.Background(), cluster, s3Config(), spec, previous, now.Add(31*time.Minute))
+	if status.Phase != cnpgv1alpha1.ObjectStoreProbeFailed {
+		t.Errorf("expected a new failed probe after the interval, got %+v", status)
+	}
+}
+
+func TestObjectStoreProber_Job(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	c := newProbeClient(s3Secret(map[string][]byte{
+		"ACCESS_KEY_ID":     []byte("id"),
+		"ACCESS_SECRET_KEY": []byte("secret"),
+	}))
+	prober := NewObjectStoreProber(c, c)
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+	spec := cnpgv1alpha1.ObjectStoreProbeConfig{Enabled: true, Image: "barman-cloud:latest"}
+
+	status := prober.Probe(ctx, cluster, s3Config(), spec, nil, now)
+	if status.Phase != cnpgv1alpha1.ObjectStoreProbeRunning || status.Job == "" {
+		t.Fatalf("expected a running probe job, got %+v", status)
+	}
+
+	var jobs batchv1.JobList
+	if err := c.List(ctx, &jobs); err != nil || len(jobs.Items) != 1 {
+		t.Fatalf("expected 1 probe job, got %d (%v)", len(jobs.Items), err)
+	}
+	job := jobs.Items[0]
+	container := job.Spec.Template.Spec.Containers[0]
+	expectedCommand := "barman-cloud-backup-list --cloud-provider aws-s3 " +
+		"--endpoint-url https://minio:9000 s3://backups/ pg"
+	if got := strings.Join(container.Command, " "); got != expectedCommand {
+		t.Errorf("expected command %q, got %q", expectedCommand, got)
+	}
+	if len(container.Env) != 2 || container.Env[0].ValueFrom.SecretKeyRef.Name != "s3-creds" {
+		t.Errorf("expected credentials from secret refs, got %+v", container.Env)
+	}
+	if *job.Spec.Template.Spec.AutomountServiceAccountToken {
+		t.Error("expected no service account token without inherited credentials")
+	}
+
+	// Still running: the status is kept
+	if again := prober.Probe(ctx, cluster, s3Config(), spec, status, now.Add(15*time.Second)); again != status {
+		t.Errorf("expected running status to be kept, got %+v", again)
+	}
+
+	job.Status.Failed = 1
+	if err := c.Status().Update(ctx, &job); err != nil {
+		t.Fatalf("failed to update job status: %v", err)
+	}
+	finished := prober.Probe(ctx, cluster, s3Config(), spec, status, now.Add(30*time.Second))
+	if finished.Phase != cnpgv1alpha1.ObjectStoreProbeFailed || finished.Job != "" || finished.LastProbeTime == nil {
+		t.Errorf("expected failed probe, got %+v", finished)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&job), &batchv1.Job{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected probe job to be deleted, got %v", err)
+	}
+}
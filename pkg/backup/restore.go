@@ -0,0 +1,496 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+)
+
+const (
+	// LabelRestoreTest is set to the restore-test event name on every object a restore
+	// test creates. Clusters carrying it are never matched by policies.
+	LabelRestoreTest = "cnpg.supporttools.io/restore-test"
+
+	// DefaultSmokeSQL is run against the recovered database when no query is configured
+	DefaultSmokeSQL = "SELECT 1"
+	// DefaultRestoreTimeout bounds how long recovery may take when no timeout is configured
+	DefaultRestoreTimeout = time.Hour
+
+	// cnpgHealthyPhase is the CNPG cluster phase once all instances are ready
+	cnpgHealthyPhase = "Cluster in healthy state"
+	// recoverySourceName is the externalClusters entry the recovery bootstraps from
+	recoverySourceName = "origin"
+	// maxSmokeCheckOutput bounds the smoke check output recorded in the event status
+	maxSmokeCheckOutput = 256
+	// maxSecretNameLength is the maximum length of a Secret name
+	maxSecretNameLength = 253
+)
+
+// RestoreTester runs the steps of restore-test StorageEvents: it recovers a cluster
+// from its object store into a throwaway CNPG cluster, runs a smoke SQL check against
+// it and deletes everything it created again
+type RestoreTester struct {
+	client    client.Client
+	reader    client.Reader
+	discovery *cnpg.Discovery
+	runner    runner.CommandRunner
+}
+
+// NewRestoreTester creates a restore tester. Credential Secrets are read with reader.
+// commandRunner runs the smoke check with psql; it must use pod exec, and the smoke
+// check is skipped when it is nil.
+func NewRestoreTester(c client.Client, reader client.Reader, commandRunner runner.CommandRunner) *RestoreTester {
+	return &RestoreTester{
+		client:    c,
+		reader:    reader,
+		discovery: cnpg.NewDiscovery(c),
+		runner:    commandRunner,
+	}
+}
+
+// Restore creates the recovery cluster, copying credential Secrets and the ObjectStore
+// into the target namespace when it differs from the source. Created objects are
+// recorded in the event status so teardown can find them. Existing objects from an
+// interrupted attempt are reused; existing objects without the restore-test label
+// are never touched.
+func (t *RestoreTester) Restore(ctx context.Context, event *cnpgv1alpha1.StorageEvent) (string, error) {
+	details := event.Spec.RestoreTest
+	if details == nil {
+		return "", fmt.Errorf("restore-test event has no restoreTest details")
+	}
+	if details.TargetNamespace == "" {
+		return "", fmt.Errorf("restore-test event has no target namespace")
+	}
+	if event.Status.RestoreTest == nil {
+		event.Status.RestoreTest = &cnpgv1alpha1.RestoreTestStatus{}
+	}
+
+	source, err := t.discovery.GetCluster(ctx, event.Spec.ClusterRef.Name, event.Spec.ClusterRef.Namespace)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := t.discovery.GetObjectStoreConfig(ctx, *source)
+	if err != nil {
+		return "", err
+	}
+	if cfg == nil {
+		return "", fmt.Errorf("cluster %s/%s has no object store to restore from", source.Namespace, source.Name)
+	}
+	if source.Storage.Size == "" {
+		return "", fmt.Errorf("cluster %s/%s has no storage size", source.Namespace, source.Name)
+	}
+
+	renamed, err := t.copySecrets(ctx, event, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	external := map[string]interface{}{"name": recoverySourceName}
+	if plugin := source.Status.BarmanCloudPlugin; plugin != nil && plugin.Enabled && plugin.ObjectStoreName != "" {
+		objectStoreName, err := t.copyObjectStore(ctx, event, plugin, renamed)
+		if err != nil {
+			return "", err
+		}
+		external["plugin"] = map[string]interface{}{
+			"name": cnpg.BarmanCloudPluginName,
+			"parameters": map[string]interface{}{
+				"barmanObjectName": objectStoreName,
+				"serverName":       cfg.ServerName,
+			},
+		}
+	} else {
+		configuration, err := t.sourceBarmanObjectStore(ctx, source)
+		if err != nil {
+			return "", err
+		}
+		configuration["serverName"] = cfg.ServerName
+		renameSecretRefs(configuration, renamed)
+		external["barmanObjectStore"] = configuration
+	}
+
+	storage := map[string]interface{}{"size": source.Storage.Size}
+	if source.Storage.StorageClass != "" {
+		storage["storageClass"] = source.Storage.StorageClass
+	}
+	spec := map[string]interface{}{
+		"instances": int64(1),
+		"storage":   storage,
+		"bootstrap": map[string]interface{}{
+			"recovery": map[string]interface{}{"source": recoverySourceName},
+		},
+		"externalClusters": []interface{}{external},
+	}
+	if imageName, err := t.sourceImageName(ctx, source); err != nil {
+		return "", err
+	} else if imageName != "" {
+		spec["imageName"] = imageName
+	}
+
+	recovery, err := t.newCluster()
+	if err != nil {
+		return "", err
+	}
+	recovery.Object["spec"] = spec
+	recovery.SetName(details.ClusterName)
+	recovery.SetNamespace(details.TargetNamespace)
+	recovery.SetLabels(restoreTestLabels(event))
+	if err := t.create(ctx, recovery); err != nil {
+		return "", fmt.Errorf("failed to create recovery cluster %s/%s: %w",
+			details.TargetNamespace, details.ClusterName, err)
+	}
+
+	return fmt.Sprintf("created recovery cluster %s/%s from %s", details.TargetNamespace, details.ClusterName,
+		cfg.Source), nil
+}
+
+// Ready reports whether the recovery cluster finished recovery. It fails once the
+// timeout measured from started has elapsed.
+func (t *RestoreTester) Ready(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	started, now time.Time,
+) (bool, string, error) {
+	details := event.Spec.RestoreTest
+	recovery, err := t.newCluster()
+	if err != nil {
+		return false, "", err
+	}
+	if err := t.client.Get(ctx, client.ObjectKey{
+		Name:      details.ClusterName,
+		Namespace: details.TargetNamespace,
+	}, recovery); err != nil {
+		return false, "", fmt.Errorf("failed to get recovery cluster %s/%s: %w",
+			details.TargetNamespace, details.ClusterName, err)
+	}
+
+	phase, _, _ := unstructured.NestedString(recovery.Object, "status", "phase")
+	readyInstances, _, _ := unstructured.NestedInt64(recovery.Object, "status", "readyInstances")
+	elapsed := now.Sub(started)
+	if phase == cnpgHealthyPhase && readyInstances > 0 {
+		if event.Status.RestoreTest == nil {
+			event.Status.RestoreTest = &cnpgv1alpha1.RestoreTestStatus{}
+		}
+		event.Status.RestoreTest.RestoreDurationSeconds = int32(elapsed.Seconds())
+		return true, fmt.Sprintf("recovered in %s", elapsed.Round(time.Second)), nil
+	}
+
+	timeout := DefaultRestoreTimeout
+	if details.TimeoutMinutes > 0 {
+		timeout = time.Duration(details.TimeoutMinutes) * time.Minute
+	}
+	if elapsed > timeout {
+		return false, "", fmt.Errorf("recovery cluster not ready after %s (phase: %q)", timeout, phase)
+	}
+	return false, fmt.Sprintf("waiting for recovery (phase: %q)", phase), nil
+}
+
+// SmokeCheck runs the smoke SQL on the recovered primary
+func (t *RestoreTester) SmokeCheck(ctx context.Context, event *cnpgv1alpha1.StorageEvent) (string, error) {
+	if t.runner == nil {
+		return "skipped: the smoke check requires the exec command runner", nil
+	}
+
+	details := event.Spec.RestoreTest
+	pod, err := t.discovery.GetPrimaryPod(ctx, details.ClusterName, details.TargetNamespace)
+	if err != nil {
+		return "", err
+	}
+
+	query := details.SmokeSQL
+	if query == "" {
+		query = DefaultSmokeSQL
+	}
+	command := []string{"psql", "-X", "-A", "-t", "-q", "-v", "ON_ERROR_STOP=1", "-d", "postgres", "-c", query}
+	output, err := t.runner.Run(ctx, pod, runner.PreferredContainer(pod), command)
+	if err != nil {
+		return "", fmt.Errorf("smoke check failed: %w", err)
+	}
+
+	output = strings.TrimSpace(output)
+	if len(output) > maxSmokeCheckOutput {
+		output = output[:maxSmokeCheckOutput]
+	}
+	if event.Status.RestoreTest == nil {
+		event.Status.RestoreTest = &cnpgv1alpha1.RestoreTestStatus{}
+	}
+	event.Status.RestoreTest.SmokeCheckOutput = output
+	return "smoke check passed", nil
+}
+
+// Teardown deletes the recovery cluster and the copied ObjectStore and Secrets. CNPG
+// owns the recovery cluster's PVCs, so they are garbage collected with it.
+func (t *RestoreTester) Teardown(ctx context.Context, event *cnpgv1alpha1.StorageEvent) (string, error) {
+	details := event.Spec.RestoreTest
+	if details == nil {
+		return "nothing to delete", nil
+	}
+
+	var errs []error
+	deleteObject := func(obj client.Object) {
+		if err := t.client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil &&
+			!apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", obj.GetName(), err))
+		}
+	}
+
+	// The recovery cluster is found by its computed name, so only delete it when a
+	// restore test created it
+	recovery, err := t.newCluster()
+	if err != nil {
+		return "", err
+	}
+	err = t.reader.Get(ctx, client.ObjectKey{Name: details.ClusterName, Namespace: details.TargetNamespace}, recovery)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		errs = append(errs, fmt.Errorf("failed to get recovery cluster %s: %w", details.ClusterName, err))
+	case !isRestoreTestObject(recovery):
+		errs = append(errs, fmt.Errorf("not deleting cluster %s/%s: it is not labeled %s",
+			details.TargetNamespace, details.ClusterName, LabelRestoreTest))
+	default:
+		deleteObject(recovery)
+	}
+
+	deleted := 0
+	if status := event.Status.RestoreTest; status != nil {
+		if status.ObjectStore != "" {
+			objectStore := &unstructured.Unstructured{}
+			objectStore.SetGroupVersionKind(cnpg.ObjectStoreGVK)
+			objectStore.SetName(status.ObjectStore)
+			objectStore.SetNamespace(details.TargetNamespace)
+			deleteObject(objectStore)
+		}
+		for _, name := range status.Secrets {
+			deleteObject(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: details.TargetNamespace}})
+			deleted++
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("deleted recovery cluster %s/%s and %d copied secrets",
+		details.TargetNamespace, details.ClusterName, deleted), nil
+}
+
+// copySecrets copies the credential Secrets of the object store into the target
+// namespace and returns the new name of each copied Secret
+func (t *RestoreTester) copySecrets(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	cfg *cnpg.ObjectStoreConfig,
+) (map[string]string, error) {
+	details := event.Spec.RestoreTest
+	renamed := make(map[string]string)
+	if cfg.Namespace == details.TargetNamespace {
+		return renamed, nil
+	}
+
+	for _, ref := range cfg.SecretRefs() {
+		if _, ok := renamed[ref.Name]; ok {
+			continue
+		}
+		var secret corev1.Secret
+		if err := t.reader.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: cfg.Namespace}, &secret); err != nil {
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", cfg.Namespace, ref.Name, err)
+		}
+
+		name := details.ClusterName + "-" + ref.Name
+		if len(name) > maxSecretNameLength {
+			name = name[:maxSecretNameLength]
+		}
+		cp := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: details.TargetNamespace,
+				Labels:    restoreTestLabels(event),
+			},
+			Type: secret.Type,
+			Data: secret.Data,
+		}
+		if err := t.create(ctx, cp); err != nil {
+			return nil, fmt.Errorf("failed to copy secret %s to %s: %w", ref.Name, details.TargetNamespace, err)
+		}
+		renamed[ref.Name] = name
+		event.Status.RestoreTest.Secrets = appendUnique(event.Status.RestoreTest.Secrets, name)
+	}
+	return renamed, nil
+}
+
+// copyObjectStore copies the cluster's ObjectStore into the target namespace and
+// returns the name the recovery cluster must reference
+func (t *RestoreTester) copyObjectStore(
+	ctx context.Context,
+	event *cnpgv1alpha1.StorageEvent,
+	plugin *cnpg.BarmanCloudPluginInfo,
+	renamed map[string]string,
+) (string, error) {
+	details := event.Spec.RestoreTest
+	if plugin.ObjectStoreNamespace == details.TargetNamespace {
+		return plugin.ObjectStoreName, nil
+	}
+
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(cnpg.ObjectStoreGVK)
+	if err := t.client.Get(ctx, client.ObjectKey{
+		Name:      plugin.ObjectStoreName,
+		Namespace: plugin.ObjectStoreNamespace,
+	}, source); err != nil {
+		return "", fmt.Errorf("failed to get ObjectStore %s/%s: %w",
+			plugin.ObjectStoreNamespace, plugin.ObjectStoreName, err)
+	}
+
+	spec, _, _ := unstructured.NestedMap(source.Object, "spec")
+	renameSecretRefs(spec, renamed)
+	cp := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	cp.SetGroupVersionKind(cnpg.ObjectStoreGVK)
+	cp.SetName(details.ClusterName)
+	cp.SetNamespace(details.TargetNamespace)
+	cp.SetLabels(restoreTestLabels(event))
+	if err := t.create(ctx, cp); err != nil {
+		return "", fmt.Errorf("failed to copy ObjectStore %s to %s: %w",
+			plugin.ObjectStoreName, details.TargetNamespace, err)
+	}
+	event.Status.RestoreTest.ObjectStore = cp.GetName()
+	return cp.GetName(), nil
+}
+
+// sourceBarmanObjectStore returns a copy of the in-tree barmanObjectStore section of the source cluster
+func (t *RestoreTester) sourceBarmanObjectStore(
+	ctx context.Context,
+	source *cnpg.ClusterInfo,
+) (map[string]interface{}, error) {
+	obj, err := t.getSourceCluster(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	configuration, found, _ := unstructured.NestedMap(obj.Object, "spec", "backup", "barmanObjectStore")
+	if !found {
+		return nil, fmt.Errorf("cluster %s/%s has no barmanObjectStore", source.Namespace, source.Name)
+	}
+	return configuration, nil
+}
+
+// sourceImageName returns the PostgreSQL image of the source cluster, if set explicitly
+func (t *RestoreTester) sourceImageName(ctx context.Context, source *cnpg.ClusterInfo) (string, error) {
+	obj, err := t.getSourceCluster(ctx, source)
+	if err != nil {
+		return "", err
+	}
+	imageName, _, _ := unstructured.NestedString(obj.Object, "spec", "imageName")
+	return imageName, nil
+}
+
+func (t *RestoreTester) getSourceCluster(
+	ctx context.Context,
+	source *cnpg.ClusterInfo,
+) (*unstructured.Unstructured, error) {
+	obj, err := t.newCluster()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.client.Get(ctx, client.ObjectKey{Name: source.Name, Namespace: source.Namespace}, obj); err != nil {
+		return nil, fmt.Errorf("failed to get CNPG cluster %s/%s: %w", source.Namespace, source.Name, err)
+	}
+	return obj, nil
+}
+
+// renameSecretRefs rewrites every {name, key} Secret reference in a barman object
+// store configuration to the name of its copy
+func renameSecretRefs(value interface{}, renamed map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if name, ok := v["name"].(string); ok {
+			if _, hasKey := v["key"]; hasKey {
+				if newName, ok := renamed[name]; ok {
+					v["name"] = newName
+				}
+			}
+		}
+		for _, child := range v {
+			renameSecretRefs(child, renamed)
+		}
+	case []interface{}:
+		for _, child := range v {
+			renameSecretRefs(child, renamed)
+		}
+	}
+}
+
+// newCluster returns an empty CNPG cluster of the Cluster API version served by the API server
+func (t *RestoreTester) newCluster() (*unstructured.Unstructured, error) {
+	return cnpg.NewClusterObject(t.client.RESTMapper())
+}
+
+// create creates obj, reusing an existing object of the same name only when it carries
+// the restore-test label, so objects of other owners are never adopted and later deleted
+func (t *RestoreTester) create(ctx context.Context, obj client.Object) error {
+	err := t.client.Create(ctx, obj)
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return err
+	}
+	if err := t.reader.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		return err
+	}
+	if !isRestoreTestObject(existing) {
+		return fmt.Errorf("%s/%s already exists and is not labeled %s",
+			obj.GetNamespace(), obj.GetName(), LabelRestoreTest)
+	}
+	return nil
+}
+
+// isRestoreTestObject reports whether obj was created by a restore test
+func isRestoreTestObject(obj client.Object) bool {
+	_, ok := obj.GetLabels()[LabelRestoreTest]
+	return ok
+}
+
+func restoreTestLabels(event *cnpgv1alpha1.StorageEvent) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/managed-by": "cnpg-storage-manager",
+		"app.kubernetes.io/component":  "restore-test",
+		LabelRestoreTest:               event.Name,
+	}
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+// fakeRunner records the commands it runs and returns a fixed result
+type fakeRunner struct {
+	commands [][]string
+	output   string
+	err      error
+}
+
+func (f *fakeRunner) Run(_ context.Context, _ *corev1.Pod, _ string, command []string) (string, error) {
+	f.commands = append(f.commands, command)
+	return f.output, f.err
+}
+
+func newRestoreClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func cnpgCluster(name, namespace string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(cnpg.CNPGClusterGVK)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return obj
+}
+
+func restoreTestEvent(targetNamespace string) *cnpgv1alpha1.StorageEvent {
+	return &cnpgv1alpha1.StorageEvent{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg-restore-test-abcde", Namespace: "db"},
+		Spec: cnpgv1alpha1.StorageEventSpec{
+			ClusterRef: cnpgv1alpha1.ClusterReference{Name: "pg", Namespace: "db"},
+			EventType:  cnpgv1alpha1.EventTypeRestoreTest,
+			RestoreTest: &cnpgv1alpha1.RestoreTestDetails{
+				TargetNamespace: targetNamespace,
+				ClusterName:     "pg-restore-1234",
+				TimeoutMinutes:  30,
+			},
+		},
+	}
+}
+
+func getRecoveryCluster(t *testing.T, c client.Client, namespace string) *unstructured.Unstructured {
+	t.Helper()
+	recovery := &unstructured.Unstructured{}
+	recovery.SetGroupVersionKind(cnpg.CNPGClusterGVK)
+	if err := c.Get(context.Background(), client.ObjectKey{Name: "pg-restore-1234", Namespace: namespace},
+		recovery); err != nil {
+		t.Fatalf("expected recovery cluster: %v", err)
+	}
+	return recovery
+}
+
+func TestRestoreTester_RestoreInTree(t *testing.T) {
+	ctx := context.Background()
+	source := cnpgCluster("pg", "db", map[string]interface{}{
+		"instances": int64(3),
+		"imageName": "ghcr.io/cloudnative-pg/postgresql:16.4",
+		"storage":   map[string]interface{}{"size": "10Gi", "storageClass": "fast"},
+		"backup": map[string]interface{}{
+			"barmanObjectStore": map[string]interface{}{
+				"destinationPath": "s3://backups/",
+				"s3Credentials": map[string]interface{}{
+					"accessKeyId":     map[string]interface{}{"name": "s3-creds", "key": "ACCESS_KEY_ID"},
+					"secretAccessKey": map[string]interface{}{"name": "s3-creds", "key": "ACCESS_SECRET_KEY"},
+				},
+			},
+		},
+	})
+	c := newRestoreClient(source, s3Secret(map[string][]byte{"ACCESS_KEY_ID": []byte("id")}))
+	tester := NewRestoreTester(c, c, nil)
+	event := restoreTestEvent("restore-tests")
+
+	if _, err := tester.Restore(ctx, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A resumed restore step reuses the existing objects
+	if _, err := tester.Restore(ctx, event); err != nil {
+		t.Fatalf("unexpected error on resume: %v", err)
+	}
+
+	if got := event.Status.RestoreTest.Secrets; len(got) != 1 || got[0] != "pg-restore-1234-s3-creds" {
+		t.Fatalf("expected the copied secret to be recorded, got %v", got)
+	}
+	var copied corev1.Secret
+	if err := c.Get(ctx, client.ObjectKey{Name: "pg-restore-1234-s3-creds", Namespace: "restore-tests"},
+		&copied); err != nil {
+		t.Fatalf("expected copied secret: %v", err)
+	}
+	if string(copied.Data["ACCESS_KEY_ID"]) != "id" || copied.Labels[LabelRestoreTest] != event.Name {
+		t.Errorf("unexpected copied secret: %+v", copied)
+	}
+
+	recovery := getRecoveryCluster(t, c, "restore-tests")
+	if recovery.GetLabels()[LabelRestoreTest] != event.Name {
+		t.Errorf("expected restore-test label, got %v", recovery.GetLabels())
+	}
+	instances, _, _ := unstructured.NestedInt64(recovery.Object, "spec", "instances")
+	size, _, _ := unstructured.NestedString(recovery.Object, "spec", "storage", "size")
+	imageName, _, _ := unstructured.NestedString(recovery.Object, "spec", "imageName")
+	if instances != 1 || size != "10Gi" || imageName != "ghcr.io/cloudnative-pg/postgresql:16.4" {
+		t.Errorf("unexpected recovery spec: instances=%d size=%s image=%s", instances, size, imageName)
+	}
+	externals, _, _ := unstructured.NestedSlice(recovery.Object, "spec", "externalClusters")
+	if len(externals) != 1 {
+		t.Fatalf("expected 1 external cluster, got %d", len(externals))
+	}
+	external := externals[0].(map[string]interface{})
+	serverName, _, _ := unstructured.NestedString(external, "barmanObjectStore", "serverName")
+	secretName, _, _ := unstructured.NestedString(external, "barmanObjectStore", "s3Credentials", "accessKeyId", "name")
+	if serverName != "pg" || secretName != "pg-restore-1234-s3-creds" {
+		t.Errorf("expected serverName pg and renamed credentials, got %q and %q", serverName, secretName)
+	}
+}
+
+func TestRestoreTester_RestorePlugin(t *testing.T) {
+	ctx := context.Background()
+	source := cnpgCluster("pg", "db", map[string]interface{}{
+		"storage": map[string]interface{}{"size": "5Gi"},
+		"plugins": []interface{}{map[string]interface{}{
+			"name":       cnpg.BarmanCloudPluginName,
+			"parameters": map[string]interface{}{"barmanObjectName": "minio"},
+		}},
+	})
+	objectStore := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"configuration": map[string]interface{}{
+				"destinationPath": "s3://backups/",
+				"s3Credentials":   map[string]interface{}{"inheritFromIAMRole": true},
+			},
+		},
+	}}
+	objectStore.SetGroupVersionKind(cnpg.ObjectStoreGVK)
+	objectStore.SetName("minio")
+	objectStore.SetNamespace("db")
+
+	tests := []struct {
+		name              string
+		targetNamespace   string
+		expectObjectStore string
+		expectCopy        bool
+	}{
+		{name: "same namespace", targetNamespace: "db", expectObjectStore: "minio"},
+		{name: "other namespace", targetNamespace: "restore-tests", expectObjectStore: "pg-restore-1234", expectCopy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newRestoreClient(source.DeepCopy(), objectStore.DeepCopy())
+			event := restoreTestEvent(tt.targetNamespace)
+			if _, err := NewRestoreTester(c, c, nil).Restore(ctx, event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			recovery := getRecoveryCluster(t, c, tt.targetNamespace)
+			externals, _, _ := unstructured.NestedSlice(recovery.Object, "spec", "externalClusters")
+			external := externals[0].(map[string]interface{})
+			name, _, _ := unstructured.NestedString(external, "plugin", "parameters", "barmanObjectName")
+			if name != tt.expectObjectStore {
+				t.Errorf("expected barmanObjectName %q, got %q", tt.expectObjectStore, name)
+			}
+			if _, found, _ := unstructured.NestedSlice(recovery.Object, "spec", "plugins"); found {
+				t.Error("expected the recovery cluster not to archive WAL")
+			}
+
+			recorded := event.Status.RestoreTest.ObjectStore
+			if tt.expectCopy != (recorded != "") {
+				t.Errorf("expected ObjectStore copy %v, recorded %q", tt.expectCopy, recorded)
+			}
+		})
+	}
+}
+
+func TestRestoreTester_RestoreWithoutObjectStore(t *testing.T) {
+	c := newRestoreClient(cnpgCluster("pg", "db", map[string]interface{}{
+		"storage": map[string]interface{}{"size": "5Gi"},
+	}))
+	_, err := NewRestoreTester(c, c, nil).Restore(context.Background(), restoreTestEvent("db"))
+	if err == nil || !strings.Contains(err.Error(), "no object store") {
+		t.Errorf("expected no object store error, got %v", err)
+	}
+}
+
+func TestRestoreTester_Ready(t *testing.T) {
+	started := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		status      map[string]interface{}
+		elapsed     time.Duration
+		expectReady bool
+		expectError bool
+	}{
+		{
+			name:    "recovering",
+			status:  map[string]interface{}{"phase": "Setting up primary"},
+			elapsed: 5 * time.Minute,
+		},
+		{
+			name:        "healthy",
+			status:      map[string]interface{}{"phase": "Cluster in healthy state", "readyInstances": int64(1)},
+			elapsed:     12 * time.Minute,
+			expectReady: true,
+		},
+		{
+			name:        "timed out",
+			status:      map[string]interface{}{"phase": "Setting up primary"},
+			elapsed:     31 * time.Minute,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recovery := cnpgCluster("pg-restore-1234", "db", map[string]interface{}{})
+			recovery.Object["status"] = tt.status
+			c := newRestoreClient(recovery)
+			event := restoreTestEvent("db")
+
+			ready, _, err := NewRestoreTester(c, c, nil).Ready(ctx, event, started, started.Add(tt.elapsed))
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error %v, got %v", tt.expectError, err)
+			}
+			if ready != tt.expectReady {
+				t.Errorf("expected ready %v, got %v", tt.expectReady, ready)
+			}
+			if tt.expectReady && event.Status.RestoreTest.RestoreDurationSeconds != int32(tt.elapsed.Seconds()) {
+				t.Errorf("expected duration %v, got %+v", tt.elapsed, event.Status.RestoreTest)
+			}
+		})
+	}
+}
+
+func TestRestoreTester_SmokeCheck(t *testing.T) {
+	ctx := context.Background()
+	primary := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pg-restore-1234-1",
+			Namespace: "db",
+			Labels:    map[string]string{"cnpg.io/cluster": "pg-restore-1234", "cnpg.io/instanceRole": "primary"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "postgres"}}},
+	}
+
+	c := newRestoreClient(primary)
+	event := restoreTestEvent("db")
+	event.Spec.RestoreTest.SmokeSQL = "SELECT count(*) FROM orders"
+
+	if message, err := NewRestoreTester(c, c, nil).SmokeCheck(ctx, event); err != nil ||
+		!strings.HasPrefix(message, "skipped") {
+		t.Errorf("expected the smoke check to be skipped without a runner, got %q, %v", message, err)
+	}
+
+	runner := &fakeRunner{output: "42\n"}
+	if _, err := NewRestoreTester(c, c, runner).SmokeCheck(ctx, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runner.commands) != 1 || runner.commands[0][len(runner.commands[0])-1] != "SELECT count(*) FROM orders" {
+		t.Errorf("expected the smoke SQL to be run, got %v", runner.commands)
+	}
+	if event.Status.RestoreTest.SmokeCheckOutput != "42" {
+		t.Errorf("expected output 42, got %q", event.Status.RestoreTest.SmokeCheckOutput)
+	}
+
+	runner.err = fmt.Errorf("relation \"orders\" does not exist")
+	if _, err := NewRestoreTester(c, c, runner).SmokeCheck(ctx, event); err == nil {
+		t.Error("expected a failing smoke check to return an error")
+	}
+}
+
+func TestRestoreTester_Teardown(t *testing.T) {
+	ctx := context.Background()
+	recovery := cnpgCluster("pg-restore-1234", "restore-tests", map[string]interface{}{})
+	recovery.SetLabels(map[string]string{LabelRestoreTest: "pg-restore-test-abcde"})
+	objectStore := &unstructured.Unstructured{}
+	objectStore.SetGroupVersionKind(cnpg.ObjectStoreGVK)
+	objectStore.SetName("pg-restore-1234")
+	objectStore.SetNamespace("restore-tests")
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pg-restore-1234-s3-creds", Namespace: "restore-tests"}}
+
+	c := newRestoreClient(recovery, objectStore, secret)
+	tester := NewRestoreTester(c, c, nil)
+	event := restoreTestEvent("restore-tests")
+	event.Status.RestoreTest = &cnpgv1alpha1.RestoreTestStatus{
+		Secrets:     []string{secret.Name},
+		ObjectStore: objectStore.GetName(),
+	}
+
+	if _, err := tester.Teardown(ctx, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, obj := range []client.Object{recovery, objectStore, secret} {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); !apierrors.IsNotFound(err) {
+			t.Errorf("expected %s to be deleted, got %v", obj.GetName(), err)
+		}
+	}
+
+	// Teardown after a failure may find nothing left to delete
+	if _, err := tester.Teardown(ctx, event); err != nil {
+		t.Errorf("expected repeated teardown to succeed, got %v", err)
+	}
+}
+
+func TestRestoreTester_ForeignCluster(t *testing.T) {
+	ctx := context.Background()
+	source := cnpgCluster("pg", "db", map[string]interface{}{
+		"storage": map[string]interface{}{"size": "10Gi"},
+		"backup": map[string]interface{}{
+			"barmanObjectStore": map[string]interface{}{"destinationPath": "s3://backups/"},
+		},
+	})
+	// A cluster that happens to have the computed name but was not created by a restore test
+	foreign := cnpgCluster("pg-restore-1234", "restore-tests", map[string]interface{}{})
+	c := newRestoreClient(source, foreign)
+	tester := NewRestoreTester(c, c, nil)
+	event := restoreTestEvent("restore-tests")
+
+	if _, err := tester.Restore(ctx, event); err == nil {
+		t.Error("expected restore to refuse to adopt an unlabeled cluster")
+	}
+	if _, err := tester.Teardown(ctx, event); err == nil {
+		t.Error("expected teardown to refuse to delete an unlabeled cluster")
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(foreign), foreign); err != nil {
+		t.Errorf("expected the unlabeled cluster to be kept, got %v", err)
+	}
+
+	if _, err := tester.Restore(ctx, restoreTestEvent("")); err == nil {
+		t.Error("expected restore without a target namespace to fail")
+	}
+}
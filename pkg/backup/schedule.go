@@ -0,0 +1,310 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxScheduleSearch bounds how far Prev and Next search for a matching time
+const maxScheduleSearch = 366 * 24 * time.Hour
+
+// Schedule is a parsed cron expression with minute resolution. Seconds in
+// six-field CNPG schedules are validated but otherwise ignored.
+type Schedule struct {
+	minutes  uint64
+	hours    uint64
+	doms     uint64
+	months   uint64
+	dows     uint64
+	domStar  bool
+	dowStar  bool
+	original string
+}
+
+type fieldBounds struct {
+	name     string
+	min, max int
+}
+
+var (
+	secondBounds = fieldBounds{"second", 0, 59}
+	minuteBounds = fieldBounds{"minute", 0, 59}
+	hourBounds   = fieldBounds{"hour", 0, 23}
+	domBounds    = fieldBounds{"day of month", 1, 31}
+	monthBounds  = fieldBounds{"month", 1, 12}
+	dowBounds    = fieldBounds{"day of week", 0, 7}
+)
+
+var scheduleDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a five-field cron expression, a six-field expression with a
+// leading seconds field as used by CNPG ScheduledBackups, or a descriptor like @daily
+func ParseSchedule(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if descriptor, ok := scheduleDescriptors[spec]; ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+	case 6:
+		if _, _, err := parseField(fields[0], secondBounds); err != nil {
+			return nil, err
+		}
+		fields = fields[1:]
+	default:
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 or 6 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{original: expr}
+	var err error
+	if s.minutes, _, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if s.hours, _, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if s.doms, s.domStar, err = parseField(fields[2], domBounds); err != nil {
+		return nil, err
+	}
+	if s.months, _, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if s.dows, s.dowStar, err = parseField(fields[4], dowBounds); err != nil {
+		return nil, err
+	}
+	// Sunday may be written as 0 or 7
+	if s.dows&(1<<7) != 0 {
+		s.dows |= 1
+	}
+
+	return s, nil
+}
+
+// String returns the original expression
+func (s *Schedule) String() string {
+	return s.original
+}
+
+// Prev returns the latest scheduled time at or before t
+func (s *Schedule) Prev(t time.Time) (time.Time, bool) {
+	current := t.Truncate(time.Minute)
+	limit := t.Add(-maxScheduleSearch)
+
+	for current.After(limit) {
+		if !s.matchMonth(current) {
+			// Jump to the last minute of the previous month
+			current = time.Date(current.Year(), current.Month(), 1, 0, 0, 0, 0, current.Location()).Add(-time.Minute)
+			continue
+		}
+		if !s.matchDay(current) {
+			current = time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, current.Location()).
+				Add(-time.Minute)
+			continue
+		}
+		if s.hours&(1<<uint(current.Hour())) == 0 {
+			current = current.Truncate(time.Hour).Add(-time.Minute)
+			continue
+		}
+		if s.minutes&(1<<uint(current.Minute())) == 0 {
+			current = current.Add(-time.Minute)
+			continue
+		}
+		return current, true
+	}
+
+	return time.Time{}, false
+}
+
+// Next returns the earliest scheduled time strictly after t
+func (s *Schedule) Next(t time.Time) (time.Time, bool) {
+	current := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxScheduleSearch)
+
+	for current.Before(limit) {
+		if !s.matchMonth(current) {
+			current = time.Date(current.Year(), current.Month()+1, 1, 0, 0, 0, 0, current.Location())
+			continue
+		}
+		if !s.matchDay(current) {
+			current = time.Date(current.Year(), current.Month(), current.Day()+1, 0, 0, 0, 0, current.Location())
+			continue
+		}
+		if s.hours&(1<<uint(current.Hour())) == 0 {
+			current = current.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minutes&(1<<uint(current.Minute())) == 0 {
+			current = current.Add(time.Minute)
+			continue
+		}
+		return current, true
+	}
+
+	return time.Time{}, false
+}
+
+// maxGapRuns bounds how many runs LongestGap inspects for very frequent schedules
+const maxGapRuns = 10000
+
+// LongestGap returns the longest time between consecutive runs of the combined
+// schedules in the year following from. It stops early once a gap longer than
+// limit is found. Schedules that never run yield a gap of a full year.
+func LongestGap(schedules []*Schedule, from time.Time, limit time.Duration) time.Duration {
+	end := from.Add(maxScheduleSearch)
+	next := func(t time.Time) (time.Time, bool) {
+		var earliest time.Time
+		found := false
+		for _, s := range schedules {
+			if n, ok := s.Next(t); ok && (!found || n.Before(earliest)) {
+				earliest, found = n, true
+			}
+		}
+		return earliest, found && earliest.Before(end)
+	}
+
+	prev, ok := next(from)
+	if !ok {
+		return maxScheduleSearch
+	}
+
+	var longest time.Duration
+	for i := 0; i < maxGapRuns; i++ {
+		run, ok := next(prev)
+		if !ok {
+			// A single run within the search window repeats at most yearly
+			if i == 0 {
+				return maxScheduleSearch
+			}
+			break
+		}
+		if gap := run.Sub(prev); gap > longest {
+			longest = gap
+			if longest > limit {
+				break
+			}
+		}
+		prev = run
+	}
+	return longest
+}
+
+func (s *Schedule) matchMonth(t time.Time) bool {
+	return s.months&(1<<uint(t.Month())) != 0
+}
+
+// matchDay applies the cron rule that a restricted day-of-month and day-of-week
+// match if either matches
+func (s *Schedule) matchDay(t time.Time) bool {
+	domMatch := s.doms&(1<<uint(t.Day())) != 0
+	dowMatch := s.dows&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField parses a comma-separated cron field into a bit set. The boolean
+// reports whether the field was an unrestricted "*" or "?"
+func parseField(field string, bounds fieldBounds) (uint64, bool, error) {
+	if field == "*" || field == "?" {
+		return rangeBits(bounds.min, bounds.max, 1), true, nil
+	}
+
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		partBits, err := parsePart(part, bounds)
+		if err != nil {
+			return 0, false, err
+		}
+		bits |= partBits
+	}
+	return bits, false, nil
+}
+
+// parsePart parses a single value, range or step expression
+func parsePart(part string, bounds fieldBounds) (uint64, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepExpr)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q in %s field", stepExpr, bounds.name)
+		}
+	}
+
+	low, high := bounds.min, bounds.max
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+	case strings.Contains(rangeExpr, "-"):
+		lowExpr, highExpr, _ := strings.Cut(rangeExpr, "-")
+		var err error
+		if low, err = parseValue(lowExpr, bounds); err != nil {
+			return 0, err
+		}
+		if high, err = parseValue(highExpr, bounds); err != nil {
+			return 0, err
+		}
+		if low > high {
+			return 0, fmt.Errorf("invalid range %q in %s field", rangeExpr, bounds.name)
+		}
+	default:
+		value, err := parseValue(rangeExpr, bounds)
+		if err != nil {
+			return 0, err
+		}
+		low = value
+		if !hasStep {
+			high = value
+		}
+	}
+
+	return rangeBits(low, high, step), nil
+}
+
+func parseValue(expr string, bounds fieldBounds) (int, error) {
+	value, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", expr, bounds.name)
+	}
+	if value < bounds.min || value > bounds.max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d] in %s field", value, bounds.min, bounds.max, bounds.name)
+	}
+	return value, nil
+}
+
+func rangeBits(low, high, step int) uint64 {
+	var bits uint64
+	for i := low; i <= high; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * *", "61 * * * *", "0 0 * * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
+
+func TestSchedule_PrevNext(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 37, 20, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		name         string
+		expr         string
+		expectedPrev time.Time
+		expectedNext time.Time
+	}{
+		{
+			name:         "daily",
+			expr:         "@daily",
+			expectedPrev: time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+			expectedNext: time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "cnpg six-field schedule",
+			expr:         "0 30 2 * * *",
+			expectedPrev: time.Date(2025, 3, 12, 2, 30, 0, 0, time.UTC),
+			expectedNext: time.Date(2025, 3, 13, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name:         "every 15 minutes",
+			expr:         "*/15 * * * *",
+			expectedPrev: time.Date(2025, 3, 12, 10, 30, 0, 0, time.UTC),
+			expectedNext: time.Date(2025, 3, 12, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name:         "weekly on sunday written as 7",
+			expr:         "0 3 * * 7",
+			expectedPrev: time.Date(2025, 3, 9, 3, 0, 0, 0, time.UTC),
+			expectedNext: time.Date(2025, 3, 16, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "first of month",
+			expr:         "0 1 1 * *",
+			expectedPrev: time.Date(2025, 3, 1, 1, 0, 0, 0, time.UTC),
+			expectedNext: time.Date(2025, 4, 1, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "weekday range and list",
+			expr:         "0 6,18 * * 1-5",
+			expectedPrev: time.Date(2025, 3, 12, 6, 0, 0, 0, time.UTC),
+			expectedNext: time.Date(2025, 3, 12, 18, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			prev, ok := s.Prev(now)
+			if !ok || !prev.Equal(tt.expectedPrev) {
+				t.Errorf("expected prev %s, got %s", tt.expectedPrev, prev)
+			}
+			next, ok := s.Next(now)
+			if !ok || !next.Equal(tt.expectedNext) {
+				t.Errorf("expected next %s, got %s", tt.expectedNext, next)
+			}
+		})
+	}
+}
+
+func TestLongestGap(t *testing.T) {
+	from := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+
+	parse := func(exprs ...string) []*Schedule {
+		schedules := make([]*Schedule, 0, len(exprs))
+		for _, expr := range exprs {
+			s, err := ParseSchedule(expr)
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", expr, err)
+			}
+			schedules = append(schedules, s)
+		}
+		return schedules
+	}
+
+	tests := []struct {
+		name      string
+		schedules []*Schedule
+		limit     time.Duration
+		expected  time.Duration
+	}{
+		{name: "daily", schedules: parse("0 2 * * *"), limit: 48 * time.Hour, expected: 24 * time.Hour},
+		{name: "weekdays only", schedules: parse("0 2 * * 1-5"), limit: 96 * time.Hour, expected: 72 * time.Hour},
+		{
+			name:      "stops at first gap over limit",
+			schedules: parse("0 2 * * 0"),
+			limit:     24 * time.Hour,
+			expected:  168 * time.Hour,
+		},
+		{
+			name:      "combined schedules",
+			schedules: parse("0 0 2 * * 1-5", "0 0 2 * * 0,6"),
+			limit:     48 * time.Hour,
+			expected:  24 * time.Hour,
+		},
+		{name: "yearly", schedules: parse("@yearly"), limit: 24 * time.Hour, expected: maxScheduleSearch},
+		{name: "no schedules", schedules: nil, limit: 24 * time.Hour, expected: maxScheduleSearch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LongestGap(tt.schedules, from, tt.limit); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
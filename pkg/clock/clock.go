@@ -0,0 +1,62 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clock provides the time source of cooldown, pause and suppression logic, so
+// tests can inject a fake clock and --time-offset can evaluate policies as if at a later
+// time.
+package clock
+
+import (
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// Clock tells the current time. k8s.io/utils/clock/testing provides fake clocks for tests
+type Clock = clock.PassiveClock
+
+// Real is the system clock
+var Real Clock = clock.RealClock{}
+
+// Offset is the system clock shifted by a fixed duration, ahead of it when positive
+type Offset time.Duration
+
+// Now returns the system time shifted by the offset
+func (o Offset) Now() time.Time {
+	return time.Now().Add(time.Duration(o))
+}
+
+// Since returns the time elapsed since t on the shifted clock
+func (o Offset) Since(t time.Time) time.Duration {
+	return o.Now().Sub(t)
+}
+
+// WithOffset returns the system clock shifted by offset, or the system clock itself when
+// the offset is zero
+func WithOffset(offset time.Duration) Clock {
+	if offset == 0 {
+		return Real
+	}
+	return Offset(offset)
+}
+
+// OrReal returns c, or the system clock when c is nil
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithOffset(t *testing.T) {
+	if WithOffset(0) != Real {
+		t.Error("WithOffset(0) should return the system clock")
+	}
+
+	c := WithOffset(2 * time.Hour)
+	if d := c.Now().Sub(time.Now()); d < 2*time.Hour-time.Minute || d > 2*time.Hour {
+		t.Errorf("Now() is %v ahead of the system clock, want 2h", d)
+	}
+	if d := c.Since(time.Now()); d < 2*time.Hour || d > 2*time.Hour+time.Minute {
+		t.Errorf("Since(now) = %v, want 2h", d)
+	}
+}
+
+func TestOrReal(t *testing.T) {
+	if OrReal(nil) != Real {
+		t.Error("OrReal(nil) should return the system clock")
+	}
+	c := Offset(time.Minute)
+	if OrReal(c) != c {
+		t.Error("OrReal should return a non-nil clock")
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CNPGGroup is the API group of the CNPG resources
+const CNPGGroup = "postgresql.cnpg.io"
+
+// ErrUnsupportedClusterAPI is returned when the API server serves the CNPG Cluster CRD,
+// but none of its versions can be read by the manager
+var ErrUnsupportedClusterAPI = errors.New("no supported CNPG Cluster API version is served")
+
+// ClusterAPI describes a version of the CNPG Cluster API: its version and the spec field
+// paths the manager reads and writes, which may move between versions
+type ClusterAPI struct {
+	Version         string
+	InstancesPath   []string
+	StoragePath     []string
+	WALStoragePath  []string
+	TablespacesPath []string
+	BackupPath      []string
+	PluginsPath     []string
+}
+
+// clusterAPIs are the CNPG Cluster API versions the manager can read, most preferred
+// first. A new CNPG API version is supported by adding it here with its field paths.
+var clusterAPIs = []ClusterAPI{
+	{
+		Version:         "v1",
+		InstancesPath:   []string{"spec", "instances"},
+		StoragePath:     []string{"spec", "storage"},
+		WALStoragePath:  []string{"spec", "walStorage"},
+		TablespacesPath: []string{"spec", "tablespaces"},
+		BackupPath:      []string{"spec", "backup"},
+		PluginsPath:     []string{"spec", "plugins"},
+	},
+}
+
+// defaultClusterAPI is used when the served versions cannot be determined
+var defaultClusterAPI = clusterAPIs[0]
+
+// GroupVersionKind returns the GroupVersionKind of clusters of this version
+func (a ClusterAPI) GroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: CNPGGroup, Version: a.Version, Kind: CNPGKind}
+}
+
+// ListGroupVersionKind returns the GroupVersionKind of cluster lists of this version
+func (a ClusterAPI) ListGroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: CNPGGroup, Version: a.Version, Kind: CNPGKind + "List"}
+}
+
+// fieldPath returns base extended with fields, without sharing base's backing array
+func fieldPath(base []string, fields ...string) []string {
+	return append(slices.Clip(base), fields...)
+}
+
+// clusterAPIForVersion returns the API of a version, or the default API when the
+// version is unknown, such as for objects that carry no apiVersion
+func clusterAPIForVersion(version string) ClusterAPI {
+	for _, api := range clusterAPIs {
+		if api.Version == version {
+			return api
+		}
+	}
+	return defaultClusterAPI
+}
+
+// NegotiateClusterAPI probes the versions of the CNPG Cluster CRD served by the API
+// server and returns the most preferred one the manager supports. A missing CRD is
+// reported as a NoMatch error; served versions that are all unknown to the manager as
+// ErrUnsupportedClusterAPI.
+func NegotiateClusterAPI(mapper meta.RESTMapper) (ClusterAPI, error) {
+	mappings, err := mapper.RESTMappings(schema.GroupKind{Group: CNPGGroup, Kind: CNPGKind})
+	if err != nil {
+		return ClusterAPI{}, err
+	}
+
+	served := make([]string, 0, len(mappings))
+	for _, mapping := range mappings {
+		served = append(served, mapping.GroupVersionKind.Version)
+	}
+	for _, api := range clusterAPIs {
+		if slices.Contains(served, api.Version) {
+			return api, nil
+		}
+	}
+
+	supported := make([]string, 0, len(clusterAPIs))
+	for _, api := range clusterAPIs {
+		supported = append(supported, api.Version)
+	}
+	return ClusterAPI{}, fmt.Errorf("%w: served %v, supported %v", ErrUnsupportedClusterAPI, served, supported)
+}
+
+// resolveClusterAPI negotiates the Cluster API version with mapper. Only unsupported
+// versions are returned as errors: when the served versions cannot be determined, for
+// example because the CRD is missing or mapper is nil, the default version is returned
+// so that the request made with it reports the underlying error.
+func resolveClusterAPI(mapper meta.RESTMapper) (ClusterAPI, error) {
+	if mapper == nil {
+		return defaultClusterAPI, nil
+	}
+	api, err := NegotiateClusterAPI(mapper)
+	if errors.Is(err, ErrUnsupportedClusterAPI) {
+		return ClusterAPI{}, err
+	}
+	if err != nil {
+		return defaultClusterAPI, nil
+	}
+	return api, nil
+}
+
+// NewClusterObject returns an empty CNPG cluster of the Cluster API version negotiated
+// with mapper, for callers that read or write clusters outside of Discovery
+func NewClusterObject(mapper meta.RESTMapper) (*unstructured.Unstructured, error) {
+	api, err := resolveClusterAPI(mapper)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(api.GroupVersionKind())
+	return obj, nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// v2ClusterAPI is a hypothetical future Cluster API version that moves the storage
+// configuration under spec.volumes
+var v2ClusterAPI = ClusterAPI{
+	Version:         "v2",
+	InstancesPath:   []string{"spec", "instances"},
+	StoragePath:     []string{"spec", "volumes", "data"},
+	WALStoragePath:  []string{"spec", "volumes", "wal"},
+	TablespacesPath: []string{"spec", "tablespaces"},
+	BackupPath:      []string{"spec", "backup"},
+	PluginsPath:     []string{"spec", "plugins"},
+}
+
+// withClusterAPIs replaces the supported Cluster API versions for the duration of a test
+func withClusterAPIs(t *testing.T, apis ...ClusterAPI) {
+	t.Helper()
+	previous := clusterAPIs
+	clusterAPIs = apis
+	t.Cleanup(func() { clusterAPIs = previous })
+}
+
+// servingMapper returns a RESTMapper serving the given versions of the Cluster kind
+func servingMapper(versions ...string) meta.RESTMapper {
+	groupVersions := make([]schema.GroupVersion, 0, len(versions))
+	for _, version := range versions {
+		groupVersions = append(groupVersions, schema.GroupVersion{Group: CNPGGroup, Version: version})
+	}
+	mapper := meta.NewDefaultRESTMapper(groupVersions)
+	for _, gv := range groupVersions {
+		mapper.Add(gv.WithKind(CNPGKind), meta.RESTScopeNamespace)
+	}
+	return mapper
+}
+
+func TestNegotiateClusterAPI(t *testing.T) {
+	withClusterAPIs(t, v2ClusterAPI, defaultClusterAPI)
+
+	tests := []struct {
+		name        string
+		served      []string
+		wantVersion string
+		wantNoMatch bool
+		wantErr     error
+	}{
+		{name: "v1 only", served: []string{"v1"}, wantVersion: "v1"},
+		{name: "preferred supported version", served: []string{"v1", "v2"}, wantVersion: "v2"},
+		{name: "unknown versions skipped", served: []string{"v3", "v1"}, wantVersion: "v1"},
+		{name: "only unknown versions", served: []string{"v3"}, wantErr: ErrUnsupportedClusterAPI},
+		{name: "CRD not installed", wantNoMatch: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, err := NegotiateClusterAPI(servingMapper(tt.served...))
+			switch {
+			case tt.wantNoMatch:
+				if !meta.IsNoMatchError(err) {
+					t.Fatalf("expected a NoMatch error, got %v", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case api.Version != tt.wantVersion:
+				t.Errorf("expected version %s, got %s", tt.wantVersion, api.Version)
+			}
+		})
+	}
+}
+
+func TestResolveClusterAPI(t *testing.T) {
+	if api, err := resolveClusterAPI(nil); err != nil || api.Version != "v1" {
+		t.Errorf("expected v1 without a mapper, got %q, %v", api.Version, err)
+	}
+	// The request made with the default version reports the missing CRD
+	if api, err := resolveClusterAPI(servingMapper()); err != nil || api.Version != "v1" {
+		t.Errorf("expected v1 when the CRD is missing, got %q, %v", api.Version, err)
+	}
+	if _, err := resolveClusterAPI(servingMapper("v3")); !errors.Is(err, ErrUnsupportedClusterAPI) {
+		t.Errorf("expected ErrUnsupportedClusterAPI, got %v", err)
+	}
+}
+
+func TestNewClusterObject(t *testing.T) {
+	withClusterAPIs(t, v2ClusterAPI, defaultClusterAPI)
+
+	obj, err := NewClusterObject(servingMapper("v1", "v2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk := obj.GroupVersionKind(); gvk != v2ClusterAPI.GroupVersionKind() {
+		t.Errorf("expected the negotiated v2 kind, got %v", gvk)
+	}
+	if _, err := NewClusterObject(servingMapper("v3")); !errors.Is(err, ErrUnsupportedClusterAPI) {
+		t.Errorf("expected ErrUnsupportedClusterAPI, got %v", err)
+	}
+}
+
+func TestDiscovery_ListClustersUnsupportedVersion(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithRESTMapper(servingMapper("v3")).Build()
+
+	_, err := NewDiscovery(c).ListClusters(context.Background(), "")
+	if !errors.Is(err, ErrUnsupportedClusterAPI) {
+		t.Fatalf("expected ErrUnsupportedClusterAPI, got %v", err)
+	}
+}
+
+func TestExtractClusterInfo_FieldPathsByVersion(t *testing.T) {
+	withClusterAPIs(t, v2ClusterAPI, defaultClusterAPI)
+
+	cluster := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "postgresql.cnpg.io/v2",
+		"kind":       "Cluster",
+		"metadata":   map[string]interface{}{"name": "test-cluster", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"instances": int64(3),
+			"volumes": map[string]interface{}{
+				"data": map[string]interface{}{"size": "10Gi", "storageClass": "fast"},
+				"wal":  map[string]interface{}{"size": "2Gi"},
+			},
+		},
+	}}
+
+	info, err := (&Discovery{}).extractClusterInfo(cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Storage.Size != "10Gi" || info.Storage.StorageClass != "fast" {
+		t.Errorf("expected storage read from spec.volumes.data, got %+v", info.Storage)
+	}
+	if info.WALStorage == nil || info.WALStorage.Size != "2Gi" {
+		t.Errorf("expected WAL storage read from spec.volumes.wal, got %+v", info.WALStorage)
+	}
+	if info.Instances != 3 {
+		t.Errorf("expected 3 instances, got %d", info.Instances)
+	}
+}
+
+func TestFieldPathDoesNotAlias(t *testing.T) {
+	base := make([]string, 2, 4)
+	copy(base, []string{"spec", "backup"})
+
+	barman := fieldPath(base, "barmanObjectStore")
+	snapshot := fieldPath(base, "volumeSnapshot")
+	if barman[2] != "barmanObjectStore" || snapshot[2] != "volumeSnapshot" {
+		t.Errorf("expected independent paths, got %v and %v", barman, snapshot)
+	}
+}
@@ -18,15 +18,22 @@ package cnpg
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/annotations"
 )
 
 const (
@@ -34,6 +41,8 @@ const (
 	CNPGGroupVersion = "postgresql.cnpg.io/v1"
 	// CNPGKind is the kind for CNPG clusters
 	CNPGKind = "Cluster"
+	// CNPGClusterCRDName is the name of the CustomResourceDefinition of CNPG clusters
+	CNPGClusterCRDName = "clusters.postgresql.cnpg.io"
 	// BarmanCloudPluginName is the name of the barman-cloud plugin
 	BarmanCloudPluginName = "barman-cloud.cloudnative-pg.io"
 	// ObjectStoreGroup is the API group for ObjectStore CRD
@@ -42,12 +51,51 @@ const (
 	ObjectStoreVersion = "v1"
 	// ObjectStoreKind is the kind for ObjectStore CRD
 	ObjectStoreKind = "ObjectStore"
+
+	// BackupMethodBarmanObjectStore is the in-tree barman object store backup method
+	BackupMethodBarmanObjectStore = "barmanObjectStore"
+	// BackupMethodPlugin is the CNPG-I plugin backup method
+	BackupMethodPlugin = "plugin"
+	// BackupMethodVolumeSnapshot is the volume snapshot backup method
+	BackupMethodVolumeSnapshot = "volumeSnapshot"
+
+	// BackupPhaseCompleted is the phase of a successfully completed CNPG Backup
+	BackupPhaseCompleted = "completed"
+	// BackupPhaseFailed is the phase of a failed CNPG Backup
+	BackupPhaseFailed = "failed"
+
+	// AnnotationHibernation is the CNPG annotation that hibernates a cluster when "on"
+	AnnotationHibernation = "cnpg.io/hibernation"
+	// ClusterPhaseOffline is the phase of a cluster without running instances
+	ClusterPhaseOffline = "Offline"
+	// ClusterPhaseHealthy is the phase of a cluster whose instances are all ready
+	ClusterPhaseHealthy = "Cluster in healthy state"
+	// ClusterPhaseSwitchover is the phase of a cluster switching over to a new primary
+	ClusterPhaseSwitchover = "Switchover in progress"
+	// AnnotationFencedInstances is the CNPG annotation listing the fenced instances as a
+	// JSON array, where "*" fences every instance
+	AnnotationFencedInstances = "cnpg.io/fencedInstances"
+	// FenceAllInstances is the fencedInstances entry that fences every instance
+	FenceAllInstances = "*"
+
+	// LabelPVCRole is the CNPG label identifying what a PVC stores
+	LabelPVCRole = "cnpg.io/pvcRole"
+	// PVCRoleWAL is the role of the separate WAL PVCs (spec.walStorage)
+	PVCRoleWAL = "PG_WAL"
+	// PVCRoleTablespace is the role of the PVCs of declarative tablespaces
+	PVCRoleTablespace = "PG_TABLESPACE"
+	// LabelTablespaceName is the CNPG label holding the tablespace of a tablespace PVC
+	LabelTablespaceName = "cnpg.io/tablespaceName"
+	// LabelInstanceName is the CNPG label holding the instance of a PVC or pod
+	LabelInstanceName = "cnpg.io/instanceName"
 )
 
 var (
-	// CNPGClusterGVK is the GroupVersionKind for CNPG Cluster
+	// CNPGClusterGVK is the GroupVersionKind of the v1 CNPG Cluster API. Clusters are read
+	// and written with the version negotiated with the API server, see NegotiateClusterAPI
+	// and NewClusterObject
 	CNPGClusterGVK = schema.GroupVersionKind{
-		Group:   "postgresql.cnpg.io",
+		Group:   CNPGGroup,
 		Version: "v1",
 		Kind:    "Cluster",
 	}
@@ -63,10 +111,37 @@ var (
 type ClusterInfo struct {
 	Name      string
 	Namespace string
+	UID       types.UID
 	Labels    map[string]string
 	Instances int32
 	Storage   StorageInfo
-	Status    ClusterStatus
+	// WALStorage is set for clusters with separate WAL volumes (spec.walStorage)
+	WALStorage *StorageInfo
+	Status     ClusterStatus
+	// Tablespaces are the declarative tablespaces of the cluster, each with its own PVCs
+	Tablespaces []TablespaceInfo
+	// Hibernated is set for clusters hibernated through the cnpg.io/hibernation
+	// annotation or in the Offline phase. They have no pods to collect metrics from
+	Hibernated bool
+	// FencedInstances are the instances fenced through the cnpg.io/fencedInstances
+	// annotation. PostgreSQL is stopped on them, so nothing may be run against them
+	FencedInstances []string
+	// CreationTimestamp is when the cluster resource was created
+	CreationTimestamp time.Time
+	// Annotations are the annotations of the cluster resource
+	Annotations map[string]string
+}
+
+// IsInstanceFenced returns true if the instance is fenced
+func (c ClusterInfo) IsInstanceFenced(instance string) bool {
+	return slices.Contains(c.FencedInstances, instance) || slices.Contains(c.FencedInstances, FenceAllInstances)
+}
+
+// TablespaceInfo describes a declarative tablespace from spec.tablespaces
+type TablespaceInfo struct {
+	Name         string
+	Size         string
+	StorageClass string
 }
 
 // StorageInfo contains storage information for a cluster
@@ -90,6 +165,35 @@ type ClusterStatus struct {
 	BackupConfigured           bool
 	// Barman-cloud plugin info (when using external ObjectStore)
 	BarmanCloudPlugin *BarmanCloudPluginInfo
+	// BackupMethods are the backup methods configured on the cluster
+	BackupMethods []string
+	// LastSuccessfulBackupByMethod and FirstRecoverabilityPointByMethod are the
+	// per-method backup times CNPG reports, keyed by backup method
+	LastSuccessfulBackupByMethod     map[string]time.Time
+	FirstRecoverabilityPointByMethod map[string]time.Time
+}
+
+// BackupInfo contains information about a CNPG Backup
+type BackupInfo struct {
+	Name        string
+	Namespace   string
+	ClusterName string
+	Method      string
+	Phase       string
+	StartedAt   *time.Time
+	StoppedAt   *time.Time
+}
+
+// ScheduledBackupInfo contains information about a CNPG ScheduledBackup
+type ScheduledBackupInfo struct {
+	Name        string
+	Namespace   string
+	ClusterName string
+	Method      string
+	// Schedule is the six-field cron expression (with seconds) used by CNPG
+	Schedule         string
+	Suspended        bool
+	LastScheduleTime *time.Time
 }
 
 // BarmanCloudPluginInfo contains information about the barman-cloud plugin configuration
@@ -102,6 +206,8 @@ type BarmanCloudPluginInfo struct {
 	ObjectStoreName string
 	// ObjectStoreNamespace is the namespace of the ObjectStore CRD (defaults to cluster namespace)
 	ObjectStoreNamespace string
+	// ServerName is the folder of the cluster in the object store (defaults to the cluster name)
+	ServerName string
 }
 
 // ObjectStoreBackupStatus contains backup status from an ObjectStore CRD
@@ -116,7 +222,9 @@ type ObjectStoreBackupStatus struct {
 
 // Discovery provides methods for discovering CNPG clusters
 type Discovery struct {
-	client client.Client
+	client       client.Client
+	inventory    *Inventory
+	objectStores *ObjectStoreCache
 }
 
 // NewDiscovery creates a new Discovery
@@ -124,14 +232,33 @@ func NewDiscovery(c client.Client) *Discovery {
 	return &Discovery{client: c}
 }
 
+// WithInventory serves cluster listings from a watch-driven inventory once it has
+// synced. Until then, and when inv is nil, clusters are listed from the API server.
+func (d *Discovery) WithInventory(inv *Inventory) *Discovery {
+	d.inventory = inv
+	return d
+}
+
+// WithObjectStoreCache serves ObjectStore recovery windows from a watch-driven cache
+// once it has synced. Until then, and when c is nil, ObjectStores are read from the
+// API server.
+func (d *Discovery) WithObjectStoreCache(c *ObjectStoreCache) *Discovery {
+	d.objectStores = c
+	return d
+}
+
 // ListClusters lists all CNPG clusters in a namespace (or all namespaces if empty)
 func (d *Discovery) ListClusters(ctx context.Context, namespace string) ([]ClusterInfo, error) {
+	if d.inventory.HasSynced() {
+		return d.inventory.List(namespace), nil
+	}
+
+	api, err := d.clusterAPI()
+	if err != nil {
+		return nil, err
+	}
 	clusterList := &unstructured.UnstructuredList{}
-	clusterList.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   "postgresql.cnpg.io",
-		Version: "v1",
-		Kind:    "ClusterList",
-	})
+	clusterList.SetGroupVersionKind(api.ListGroupVersionKind())
 
 	opts := []client.ListOption{}
 	if namespace != "" {
@@ -154,10 +281,28 @@ func (d *Discovery) ListClusters(ctx context.Context, namespace string) ([]Clust
 	return clusters, nil
 }
 
+// clusterAPI returns the CNPG Cluster API version negotiated with the API server
+func (d *Discovery) clusterAPI() (ClusterAPI, error) {
+	return resolveClusterAPI(d.client.RESTMapper())
+}
+
+// newCluster returns an empty CNPG cluster of the negotiated API version
+func (d *Discovery) newCluster() (*unstructured.Unstructured, ClusterAPI, error) {
+	api, err := d.clusterAPI()
+	if err != nil {
+		return nil, ClusterAPI{}, err
+	}
+	cluster := &unstructured.Unstructured{}
+	cluster.SetGroupVersionKind(api.GroupVersionKind())
+	return cluster, api, nil
+}
+
 // GetCluster gets a specific CNPG cluster
 func (d *Discovery) GetCluster(ctx context.Context, name, namespace string) (*ClusterInfo, error) {
-	cluster := &unstructured.Unstructured{}
-	cluster.SetGroupVersionKind(CNPGClusterGVK)
+	cluster, _, err := d.newCluster()
+	if err != nil {
+		return nil, err
+	}
 
 	if err := d.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, cluster); err != nil {
 		return nil, fmt.Errorf("failed to get CNPG cluster %s/%s: %w", namespace, name, err)
@@ -177,6 +322,10 @@ func (d *Discovery) GetClustersBySelector(
 	namespace string,
 	selector *metav1.LabelSelector,
 ) ([]ClusterInfo, error) {
+	if d.inventory.HasSynced() {
+		return d.inventory.Select(namespace, selector)
+	}
+
 	allClusters, err := d.ListClusters(ctx, namespace)
 	if err != nil {
 		return nil, err
@@ -201,31 +350,42 @@ func (d *Discovery) GetClustersBySelector(
 	return matched, nil
 }
 
-// extractClusterInfo extracts cluster information from an unstructured object
+// extractClusterInfo extracts cluster information from an unstructured object, reading
+// the spec at the field paths of the object's API version
 //
 //nolint:unparam // error return kept for future extensibility
 func (d *Discovery) extractClusterInfo(cluster *unstructured.Unstructured) (ClusterInfo, error) {
+	api := clusterAPIForVersion(cluster.GroupVersionKind().Version)
 	info := ClusterInfo{
-		Name:      cluster.GetName(),
-		Namespace: cluster.GetNamespace(),
-		Labels:    cluster.GetLabels(),
+		Name:              cluster.GetName(),
+		Namespace:         cluster.GetNamespace(),
+		UID:               cluster.GetUID(),
+		Labels:            cluster.GetLabels(),
+		CreationTimestamp: cluster.GetCreationTimestamp().Time,
+		Annotations:       cluster.GetAnnotations(),
 	}
 
 	// Extract spec.instances
-	if instances, found, _ := unstructured.NestedInt64(cluster.Object, "spec", "instances"); found {
+	if instances, found, _ := unstructured.NestedInt64(cluster.Object, api.InstancesPath...); found {
 		info.Instances = int32(instances)
 	} else {
 		info.Instances = 1 // Default
 	}
 
 	// Extract storage info
-	if size, found, _ := unstructured.NestedString(cluster.Object, "spec", "storage", "size"); found {
-		info.Storage.Size = size
+	if storage, found, _ := unstructured.NestedMap(cluster.Object, api.StoragePath...); found {
+		info.Storage.Size, _, _ = unstructured.NestedString(storage, "size")
+		info.Storage.StorageClass, _, _ = unstructured.NestedString(storage, "storageClass")
 	}
-	if storageClass, found, _ := unstructured.NestedString(cluster.Object, "spec", "storage", "storageClass"); found {
-		info.Storage.StorageClass = storageClass
+
+	if walStorage, found, _ := unstructured.NestedMap(cluster.Object, api.WALStoragePath...); found {
+		info.WALStorage = &StorageInfo{}
+		info.WALStorage.Size, _, _ = unstructured.NestedString(walStorage, "size")
+		info.WALStorage.StorageClass, _, _ = unstructured.NestedString(walStorage, "storageClass")
 	}
 
+	info.Tablespaces = extractTablespaces(cluster, api)
+
 	// Extract status
 	if phase, found, _ := unstructured.NestedString(cluster.Object, "status", "phase"); found {
 		info.Status.Phase = phase
@@ -240,7 +400,9 @@ func (d *Discovery) extractClusterInfo(cluster *unstructured.Unstructured) (Clus
 		info.Status.CurrentPrimaryNode = primaryNode
 	}
 
-	info.Status.Ready = info.Status.Phase == "Cluster in healthy state" || info.Status.ReadyInstances >= info.Instances
+	info.Status.Ready = info.Status.Phase == ClusterPhaseHealthy || info.Status.ReadyInstances >= info.Instances
+	info.Hibernated = cluster.GetAnnotations()[AnnotationHibernation] == "on" || info.Status.Phase == ClusterPhaseOffline
+	info.FencedInstances = FencedInstances(cluster.GetAnnotations())
 
 	// Extract backup status fields
 	firstRecoverability, found, _ := unstructured.NestedString(
@@ -259,6 +421,8 @@ func (d *Discovery) extractClusterInfo(cluster *unstructured.Unstructured) (Clus
 			info.Status.LastSuccessfulBackup = &t
 		}
 	}
+	info.Status.LastSuccessfulBackupByMethod = timesByMethod(cluster, "lastSuccessfulBackupByMethod")
+	info.Status.FirstRecoverabilityPointByMethod = timesByMethod(cluster, "firstRecoverabilityPointByMethod")
 
 	// Check for ContinuousArchiving condition
 	if conditions, found, _ := unstructured.NestedSlice(cluster.Object, "status", "conditions"); found {
@@ -276,25 +440,58 @@ func (d *Discovery) extractClusterInfo(cluster *unstructured.Unstructured) (Clus
 	}
 
 	// Check if backup is configured (presence of backup section in spec)
-	if _, found, _ := unstructured.NestedMap(cluster.Object, "spec", "backup"); found {
+	if _, found, _ := unstructured.NestedMap(cluster.Object, api.BackupPath...); found {
 		info.Status.BackupConfigured = true
 	}
+	if _, found, _ := unstructured.NestedMap(cluster.Object, fieldPath(api.BackupPath, "barmanObjectStore")...); found {
+		info.Status.BackupMethods = append(info.Status.BackupMethods, BackupMethodBarmanObjectStore)
+	}
+	if _, found, _ := unstructured.NestedMap(cluster.Object, fieldPath(api.BackupPath, "volumeSnapshot")...); found {
+		info.Status.BackupMethods = append(info.Status.BackupMethods, BackupMethodVolumeSnapshot)
+	}
 
 	// Check for barman-cloud plugin configuration
-	info.Status.BarmanCloudPlugin = d.extractBarmanCloudPluginInfo(cluster)
+	info.Status.BarmanCloudPlugin = d.extractBarmanCloudPluginInfo(cluster, api)
 	if info.Status.BarmanCloudPlugin != nil && info.Status.BarmanCloudPlugin.Enabled {
 		// If barman-cloud plugin is configured, backup is configured
 		info.Status.BackupConfigured = true
+		info.Status.BackupMethods = append(info.Status.BackupMethods, BackupMethodPlugin)
 	}
 
 	return info, nil
 }
 
+// extractTablespaces reads the declarative tablespaces of spec.tablespaces
+func extractTablespaces(cluster *unstructured.Unstructured, api ClusterAPI) []TablespaceInfo {
+	entries, found, _ := unstructured.NestedSlice(cluster.Object, api.TablespacesPath...)
+	if !found {
+		return nil
+	}
+
+	var tablespaces []TablespaceInfo
+	for _, entry := range entries {
+		tbs, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(tbs, "name")
+		if name == "" {
+			continue
+		}
+		info := TablespaceInfo{Name: name}
+		info.Size, _, _ = unstructured.NestedString(tbs, "storage", "size")
+		info.StorageClass, _, _ = unstructured.NestedString(tbs, "storage", "storageClass")
+		tablespaces = append(tablespaces, info)
+	}
+	return tablespaces
+}
+
 // extractBarmanCloudPluginInfo extracts barman-cloud plugin configuration from cluster spec
 func (d *Discovery) extractBarmanCloudPluginInfo(
 	cluster *unstructured.Unstructured,
+	api ClusterAPI,
 ) *BarmanCloudPluginInfo {
-	plugins, found, _ := unstructured.NestedSlice(cluster.Object, "spec", "plugins")
+	plugins, found, _ := unstructured.NestedSlice(cluster.Object, api.PluginsPath...)
 	if !found {
 		return nil
 	}
@@ -325,6 +522,9 @@ func (d *Discovery) extractBarmanCloudPluginInfo(
 			if barmanObjectName, ok := params["barmanObjectName"].(string); ok {
 				info.ObjectStoreName = barmanObjectName
 			}
+			if serverName, ok := params["serverName"].(string); ok {
+				info.ServerName = serverName
+			}
 		}
 
 		return info
@@ -333,6 +533,95 @@ func (d *Discovery) extractBarmanCloudPluginInfo(
 	return nil
 }
 
+// ListBackups lists CNPG Backups in a namespace, optionally restricted to one cluster
+func (d *Discovery) ListBackups(ctx context.Context, namespace, clusterName string) ([]BackupInfo, error) {
+	backupList := &unstructured.UnstructuredList{}
+	backupList.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "postgresql.cnpg.io",
+		Version: "v1",
+		Kind:    "BackupList",
+	})
+
+	if err := d.client.List(ctx, backupList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list CNPG backups: %w", err)
+	}
+
+	backups := make([]BackupInfo, 0, len(backupList.Items))
+	for _, item := range backupList.Items {
+		info := BackupInfo{
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			// CNPG defaults spec.method to barmanObjectStore
+			Method: BackupMethodBarmanObjectStore,
+		}
+		info.ClusterName, _, _ = unstructured.NestedString(item.Object, "spec", "cluster", "name")
+		if clusterName != "" && info.ClusterName != clusterName {
+			continue
+		}
+		if method, found, _ := unstructured.NestedString(item.Object, "spec", "method"); found && method != "" {
+			info.Method = method
+		}
+		info.Phase, _, _ = unstructured.NestedString(item.Object, "status", "phase")
+		info.StartedAt = nestedTime(&item, "status", "startedAt")
+		info.StoppedAt = nestedTime(&item, "status", "stoppedAt")
+		backups = append(backups, info)
+	}
+
+	return backups, nil
+}
+
+// ListScheduledBackups lists CNPG ScheduledBackups in a namespace, optionally restricted to one cluster
+func (d *Discovery) ListScheduledBackups(
+	ctx context.Context,
+	namespace, clusterName string,
+) ([]ScheduledBackupInfo, error) {
+	scheduledList := &unstructured.UnstructuredList{}
+	scheduledList.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "postgresql.cnpg.io",
+		Version: "v1",
+		Kind:    "ScheduledBackupList",
+	})
+
+	if err := d.client.List(ctx, scheduledList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list CNPG scheduled backups: %w", err)
+	}
+
+	scheduled := make([]ScheduledBackupInfo, 0, len(scheduledList.Items))
+	for _, item := range scheduledList.Items {
+		info := ScheduledBackupInfo{
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			Method:    BackupMethodBarmanObjectStore,
+		}
+		info.ClusterName, _, _ = unstructured.NestedString(item.Object, "spec", "cluster", "name")
+		if clusterName != "" && info.ClusterName != clusterName {
+			continue
+		}
+		if method, found, _ := unstructured.NestedString(item.Object, "spec", "method"); found && method != "" {
+			info.Method = method
+		}
+		info.Schedule, _, _ = unstructured.NestedString(item.Object, "spec", "schedule")
+		info.Suspended, _, _ = unstructured.NestedBool(item.Object, "spec", "suspend")
+		info.LastScheduleTime = nestedTime(&item, "status", "lastScheduleTime")
+		scheduled = append(scheduled, info)
+	}
+
+	return scheduled, nil
+}
+
+// nestedTime parses an RFC 3339 timestamp field, returning nil if it is missing or invalid
+func nestedTime(obj *unstructured.Unstructured, fields ...string) *time.Time {
+	value, found, _ := unstructured.NestedString(obj.Object, fields...)
+	if !found || value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
 // GetClusterPVCs gets the PVCs associated with a CNPG cluster
 func (d *Discovery) GetClusterPVCs(
 	ctx context.Context,
@@ -355,7 +644,79 @@ func (d *Discovery) GetClusterPVCs(
 	return pvcList.Items, nil
 }
 
-// GetClusterPods gets the pods associated with a CNPG cluster
+// GetTablespacePVCs gets the PVCs of a cluster's tablespaces, keyed by tablespace
+func (d *Discovery) GetTablespacePVCs(
+	ctx context.Context,
+	clusterName, namespace string,
+) (map[string][]corev1.PersistentVolumeClaim, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := d.client.List(ctx, pvcList,
+		client.InNamespace(namespace),
+		client.MatchingLabels{
+			"cnpg.io/cluster": clusterName,
+			LabelPVCRole:      PVCRoleTablespace,
+		},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list tablespace PVCs for cluster %s/%s: %w", namespace, clusterName, err)
+	}
+
+	tablespaces := make(map[string][]corev1.PersistentVolumeClaim)
+	for _, pvc := range pvcList.Items {
+		if name := PVCTablespace(&pvc); name != "" {
+			tablespaces[name] = append(tablespaces[name], pvc)
+		}
+	}
+	return tablespaces, nil
+}
+
+// PVCTablespace returns the tablespace a PVC holds, or an empty string for data and
+// WAL PVCs
+func PVCTablespace(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Labels[LabelPVCRole] != PVCRoleTablespace {
+		return ""
+	}
+	return pvc.Labels[LabelTablespaceName]
+}
+
+// IsWALPVC reports whether a PVC is a separate WAL volume
+func IsWALPVC(pvc *corev1.PersistentVolumeClaim) bool {
+	return pvc.Labels[LabelPVCRole] == PVCRoleWAL
+}
+
+// IsIgnoredPVC reports whether a PVC opted out of monitoring and expansion with the
+// ignore annotation
+func IsIgnoredPVC(pvc *corev1.PersistentVolumeClaim) bool {
+	return pvc.Annotations[annotations.AnnotationIgnore] == "true"
+}
+
+// FencedInstances parses the cnpg.io/fencedInstances annotation. A missing or malformed
+// annotation fences nothing
+func FencedInstances(annotations map[string]string) []string {
+	value, ok := annotations[AnnotationFencedInstances]
+	if !ok || value == "" {
+		return nil
+	}
+	var instances []string
+	if err := json.Unmarshal([]byte(value), &instances); err != nil {
+		return nil
+	}
+	return instances
+}
+
+// SetFencedInstances writes the cnpg.io/fencedInstances annotation. An empty list is
+// written as "[]" rather than removed, since annotation updates are merged
+func SetFencedInstances(annotations map[string]string, instances []string) {
+	sorted := slices.Clone(instances)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	if sorted == nil {
+		sorted = []string{}
+	}
+	value, _ := json.Marshal(sorted)
+	annotations[AnnotationFencedInstances] = string(value)
+}
+
+// GetClusterPods gets the instance pods of a CNPG cluster, without its Pooler pods
 func (d *Discovery) GetClusterPods(ctx context.Context, clusterName, namespace string) ([]corev1.Pod, error) {
 	podList := &corev1.PodList{}
 
@@ -371,7 +732,12 @@ func (d *Discovery) GetClusterPods(ctx context.Context, clusterName, namespace s
 		return nil, fmt.Errorf("failed to list pods for cluster %s/%s: %w", namespace, clusterName, err)
 	}
 
-	return podList.Items, nil
+	// Pooler pods may carry the cluster label too, but their volumes are not the
+	// cluster's storage
+	return slices.DeleteFunc(podList.Items, func(pod corev1.Pod) bool {
+		_, pooler := pod.Labels[LabelPoolerName]
+		return pooler
+	}), nil
 }
 
 // GetPrimaryPod gets the primary pod for a CNPG cluster
@@ -390,40 +756,152 @@ func (d *Discovery) GetPrimaryPod(ctx context.Context, clusterName, namespace st
 	return nil, fmt.Errorf("no primary pod found for cluster %s/%s", namespace, clusterName)
 }
 
-// UpdateClusterAnnotations updates the annotations on a CNPG cluster
+// UpdateClusterAnnotations sets the manager's storage.cnpg.supporttools.io/* annotations on
+// a CNPG cluster with a JSON merge patch. read holds the annotations values was derived
+// from: manager annotations in read but not in values are removed. Other keys are
+// ignored, so annotations the CNPG operator or users changed since they were read are
+// never reverted
 func (d *Discovery) UpdateClusterAnnotations(
 	ctx context.Context,
 	name, namespace string,
-	annotations map[string]string,
+	read, values map[string]string,
 ) error {
-	cluster := &unstructured.Unstructured{}
-	cluster.SetGroupVersionKind(CNPGClusterGVK)
+	patch := make(map[string]interface{})
+	for k, v := range values {
+		if isManagerAnnotation(k) {
+			patch[k] = v
+		}
+	}
+	// A null value removes the key in a JSON merge patch
+	for k := range read {
+		if _, ok := values[k]; !ok && isManagerAnnotation(k) {
+			patch[k] = nil
+		}
+	}
+	return d.patchClusterAnnotations(ctx, name, namespace, patch)
+}
+
+// RemoveClusterAnnotations removes all of the manager's storage.cnpg.supporttools.io/*
+// annotations from a CNPG cluster, leaving its other annotations untouched
+func (d *Discovery) RemoveClusterAnnotations(ctx context.Context, name, namespace string) error {
+	existing, err := d.GetClusterAnnotations(ctx, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	// A null value removes the key in a JSON merge patch
+	patch := make(map[string]interface{})
+	for k := range existing {
+		if isManagerAnnotation(k) {
+			patch[k] = nil
+		}
+	}
+	return d.patchClusterAnnotations(ctx, name, namespace, patch)
+}
+
+// patchClusterAnnotations applies a JSON merge patch of the given annotations to a CNPG
+// cluster. Nothing is sent when the patch is empty
+func (d *Discovery) patchClusterAnnotations(
+	ctx context.Context,
+	name, namespace string,
+	values map[string]interface{},
+) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": values},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode annotation patch: %w", err)
+	}
+
+	cluster, _, err := d.newCluster()
+	if err != nil {
+		return err
+	}
+	cluster.SetName(name)
+	cluster.SetNamespace(namespace)
+	if err := d.client.Patch(ctx, cluster, client.RawPatch(types.MergePatchType, data)); err != nil {
+		return fmt.Errorf("failed to patch CNPG cluster %s/%s annotations: %w", namespace, name, err)
+	}
+
+	return nil
+}
+
+// isManagerAnnotation reports whether an annotation key belongs to the manager
+func isManagerAnnotation(key string) bool {
+	return strings.HasPrefix(key, annotations.AnnotationPrefix+"/")
+}
+
+// SetStorageClass sets the StorageClass of a CNPG cluster's data volumes and, when the
+// cluster has separate WAL volumes and walStorageClass is set, of its WAL volumes. CNPG
+// only uses it for PVCs created afterwards
+func (d *Discovery) SetStorageClass(ctx context.Context, name, namespace, storageClass, walStorageClass string) error {
+	cluster, api, err := d.newCluster()
+	if err != nil {
+		return err
+	}
 
 	if err := d.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, cluster); err != nil {
 		return fmt.Errorf("failed to get CNPG cluster %s/%s: %w", namespace, name, err)
 	}
 
-	// Merge annotations
-	existing := cluster.GetAnnotations()
-	if existing == nil {
-		existing = make(map[string]string)
+	patch := client.MergeFrom(cluster.DeepCopy())
+	if err := unstructured.SetNestedField(cluster.Object, storageClass,
+		fieldPath(api.StoragePath, "storageClass")...); err != nil {
+		return err
 	}
-	for k, v := range annotations {
-		existing[k] = v
+	if _, found, _ := unstructured.NestedMap(cluster.Object, api.WALStoragePath...); found && walStorageClass != "" {
+		if err := unstructured.SetNestedField(cluster.Object, walStorageClass,
+			fieldPath(api.WALStoragePath, "storageClass")...); err != nil {
+			return err
+		}
 	}
-	cluster.SetAnnotations(existing)
 
-	if err := d.client.Update(ctx, cluster); err != nil {
-		return fmt.Errorf("failed to update CNPG cluster %s/%s annotations: %w", namespace, name, err)
+	if err := d.client.Patch(ctx, cluster, patch); err != nil {
+		return fmt.Errorf("failed to update CNPG cluster %s/%s storage class: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// Switchover requests a switchover of a CNPG cluster to the given replica, the way the
+// cnpg kubectl plugin's promote command does
+func (d *Discovery) Switchover(ctx context.Context, name, namespace, targetPrimary string) error {
+	cluster, _, err := d.newCluster()
+	if err != nil {
+		return err
 	}
 
+	if err := d.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, cluster); err != nil {
+		return fmt.Errorf("failed to get CNPG cluster %s/%s: %w", namespace, name, err)
+	}
+
+	patch := client.MergeFrom(cluster.DeepCopy())
+	status := map[string]interface{}{
+		"targetPrimary": targetPrimary,
+		"phase":         ClusterPhaseSwitchover,
+		"phaseReason":   "Switching over to " + targetPrimary,
+	}
+	for field, value := range status {
+		if err := unstructured.SetNestedField(cluster.Object, value, "status", field); err != nil {
+			return err
+		}
+	}
+
+	if err := d.client.Status().Patch(ctx, cluster, patch); err != nil {
+		return fmt.Errorf("failed to request switchover of CNPG cluster %s/%s: %w", namespace, name, err)
+	}
 	return nil
 }
 
 // GetClusterAnnotations gets the annotations for a CNPG cluster
 func (d *Discovery) GetClusterAnnotations(ctx context.Context, name, namespace string) (map[string]string, error) {
-	cluster := &unstructured.Unstructured{}
-	cluster.SetGroupVersionKind(CNPGClusterGVK)
+	cluster, _, err := d.newCluster()
+	if err != nil {
+		return nil, err
+	}
 
 	if err := d.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, cluster); err != nil {
 		return nil, fmt.Errorf("failed to get CNPG cluster %s/%s: %w", namespace, name, err)
@@ -437,30 +915,78 @@ func (d *Discovery) GetObjectStoreBackupStatus(
 	ctx context.Context,
 	objectStoreName, objectStoreNamespace, clusterName string,
 ) (*ObjectStoreBackupStatus, error) {
-	objectStore := &unstructured.Unstructured{}
-	objectStore.SetGroupVersionKind(ObjectStoreGVK)
-
-	if err := d.client.Get(ctx, client.ObjectKey{
+	serverRecoveryWindow, err := d.getServerRecoveryWindow(ctx, client.ObjectKey{
 		Name:      objectStoreName,
 		Namespace: objectStoreNamespace,
-	}, objectStore); err != nil {
-		return nil, fmt.Errorf(
-			"failed to get ObjectStore %s/%s: %w",
-			objectStoreNamespace, objectStoreName, err,
-		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if serverRecoveryWindow == nil {
+		return nil, nil // ObjectStore exists but has no recovery window data yet
+	}
+
+	return parseClusterRecoveryWindow(serverRecoveryWindow, clusterName), nil
+}
+
+// getServerRecoveryWindow returns the serverRecoveryWindow of an ObjectStore, or nil
+// if it has none yet. It is read from the ObjectStore cache once synced and from the
+// API server otherwise.
+func (d *Discovery) getServerRecoveryWindow(
+	ctx context.Context,
+	key client.ObjectKey,
+) (map[string]interface{}, error) {
+	if d.objectStores.HasSynced() {
+		window, ok := d.objectStores.RecoveryWindow(key.Namespace, key.Name)
+		if !ok {
+			return nil, fmt.Errorf("failed to get ObjectStore %s/%s: %w", key.Namespace, key.Name,
+				apierrors.NewNotFound(schema.GroupResource{Group: ObjectStoreGroup, Resource: "objectstores"}, key.Name))
+		}
+		return window, nil
 	}
 
-	// Extract serverRecoveryWindow for the specific cluster
-	serverRecoveryWindow, found, _ := unstructured.NestedMap(
+	objectStore := &unstructured.Unstructured{}
+	objectStore.SetGroupVersionKind(ObjectStoreGVK)
+	if err := d.client.Get(ctx, key, objectStore); err != nil {
+		return nil, fmt.Errorf("failed to get ObjectStore %s/%s: %w", key.Namespace, key.Name, err)
+	}
+
+	serverRecoveryWindow, _, _ := unstructured.NestedMap(
 		objectStore.Object, "status", "serverRecoveryWindow",
 	)
+	return serverRecoveryWindow, nil
+}
+
+// timesByMethod parses a status map of backup method to RFC 3339 timestamp. Returns nil
+// when the field is missing or holds no valid timestamps
+func timesByMethod(cluster *unstructured.Unstructured, field string) map[string]time.Time {
+	values, found, _ := unstructured.NestedStringMap(cluster.Object, "status", field)
 	if !found {
-		return nil, nil // ObjectStore exists but has no recovery window data yet
+		return nil
 	}
+	var times map[string]time.Time
+	for method, value := range values {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			continue
+		}
+		if times == nil {
+			times = make(map[string]time.Time, len(values))
+		}
+		times[method] = t
+	}
+	return times
+}
 
+// parseClusterRecoveryWindow extracts the backup status of a single cluster from an
+// ObjectStore serverRecoveryWindow map. Returns nil if the cluster has no entry.
+func parseClusterRecoveryWindow(
+	serverRecoveryWindow map[string]interface{},
+	clusterName string,
+) *ObjectStoreBackupStatus {
 	clusterWindow, ok := serverRecoveryWindow[clusterName].(map[string]interface{})
 	if !ok {
-		return nil, nil // No data for this cluster in the ObjectStore
+		return nil // No data for this cluster in the ObjectStore
 	}
 
 	status := &ObjectStoreBackupStatus{
@@ -481,7 +1007,7 @@ func (d *Discovery) GetObjectStoreBackupStatus(
 		}
 	}
 
-	return status, nil
+	return status
 }
 
 // GetBackupStatusForCluster gets backup status for a cluster, checking ObjectStore if barman-cloud plugin is used
@@ -513,3 +1039,74 @@ func (d *Discovery) GetBackupStatusForCluster(
 
 	return nil, nil
 }
+
+// BackupStatusIndex holds backup status for a set of clusters resolved in a single pass.
+// ObjectStores shared by many clusters are fetched once and every relevant cluster is
+// extracted from the same serverRecoveryWindow map.
+type BackupStatusIndex struct {
+	statuses map[string]*ObjectStoreBackupStatus
+	errors   map[string]error
+}
+
+// ForCluster returns the backup status for a cluster, mirroring GetBackupStatusForCluster
+func (i *BackupStatusIndex) ForCluster(cluster ClusterInfo) (*ObjectStoreBackupStatus, error) {
+	if i == nil {
+		return nil, nil
+	}
+	key := fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name)
+	if err, ok := i.errors[key]; ok {
+		return nil, err
+	}
+	return i.statuses[key], nil
+}
+
+// GetBackupStatusesForClusters resolves backup status for all clusters, issuing at most
+// one read per referenced ObjectStore instead of one per cluster.
+func (d *Discovery) GetBackupStatusesForClusters(ctx context.Context, clusters []ClusterInfo) *BackupStatusIndex {
+	index := &BackupStatusIndex{
+		statuses: make(map[string]*ObjectStoreBackupStatus, len(clusters)),
+		errors:   make(map[string]error),
+	}
+
+	// Group plugin-backed clusters by the ObjectStore they reference
+	byStore := make(map[client.ObjectKey][]ClusterInfo)
+	for _, cluster := range clusters {
+		key := fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name)
+		plugin := cluster.Status.BarmanCloudPlugin
+		if plugin != nil && plugin.Enabled && plugin.ObjectStoreName != "" {
+			storeKey := client.ObjectKey{Name: plugin.ObjectStoreName, Namespace: plugin.ObjectStoreNamespace}
+			byStore[storeKey] = append(byStore[storeKey], cluster)
+			continue
+		}
+
+		// Legacy mode: status comes from the cluster itself
+		if cluster.Status.LastSuccessfulBackup != nil || cluster.Status.FirstRecoverabilityPoint != nil {
+			index.statuses[key] = &ObjectStoreBackupStatus{
+				ClusterName:              cluster.Name,
+				FirstRecoverabilityPoint: cluster.Status.FirstRecoverabilityPoint,
+				LastSuccessfulBackupTime: cluster.Status.LastSuccessfulBackup,
+			}
+		}
+	}
+
+	for storeKey, storeClusters := range byStore {
+		serverRecoveryWindow, err := d.getServerRecoveryWindow(ctx, storeKey)
+		if err != nil {
+			for _, cluster := range storeClusters {
+				index.errors[fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name)] = err
+			}
+			continue
+		}
+		if serverRecoveryWindow == nil {
+			continue
+		}
+
+		for _, cluster := range storeClusters {
+			if status := parseClusterRecoveryWindow(serverRecoveryWindow, cluster.Name); status != nil {
+				index.statuses[fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name)] = status
+			}
+		}
+	}
+
+	return index
+}
@@ -18,13 +18,17 @@ package cnpg
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 func TestNewDiscovery(t *testing.T) {
@@ -189,6 +193,65 @@ func TestDiscovery_GetClusterPVCs(t *testing.T) {
 	}
 }
 
+func TestDiscovery_GetTablespacePVCs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pvc := func(name, role, tablespace string) *corev1.PersistentVolumeClaim {
+		labels := map[string]string{"cnpg.io/cluster": "test-cluster", LabelPVCRole: role}
+		if tablespace != "" {
+			labels[LabelTablespaceName] = tablespace
+		}
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		}
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(
+			pvc("test-cluster-1", "PG_DATA", ""),
+			pvc("test-cluster-1-wal", "PG_WAL", ""),
+			pvc("test-cluster-1-tbs-archive", PVCRoleTablespace, "archive"),
+			pvc("test-cluster-2-tbs-archive", PVCRoleTablespace, "archive"),
+			pvc("test-cluster-1-tbs-hot", PVCRoleTablespace, "hot"),
+		).
+		Build()
+
+	tablespaces, err := NewDiscovery(client).GetTablespacePVCs(context.Background(), "test-cluster", "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tablespaces) != 2 {
+		t.Fatalf("expected 2 tablespaces, got %d", len(tablespaces))
+	}
+	if len(tablespaces["archive"]) != 2 || len(tablespaces["hot"]) != 1 {
+		t.Errorf("expected 2 archive and 1 hot PVCs, got %d and %d",
+			len(tablespaces["archive"]), len(tablespaces["hot"]))
+	}
+}
+
+func TestPVCTablespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected string
+	}{
+		{"tablespace PVC", map[string]string{LabelPVCRole: PVCRoleTablespace, LabelTablespaceName: "archive"}, "archive"},
+		{"data PVC", map[string]string{LabelPVCRole: "PG_DATA"}, ""},
+		{"unlabeled PVC", nil, ""},
+		{"tablespace name without role", map[string]string{LabelTablespaceName: "archive"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Labels: tt.labels}}
+			if got := PVCTablespace(pvc); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestDiscovery_GetClusterPods(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
@@ -221,9 +284,21 @@ func TestDiscovery_GetClusterPods(t *testing.T) {
 		},
 	}
 
+	// Pooler pods carry the cluster label too, but are not instances
+	poolerPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-pooler-rw-abc12",
+			Namespace: "default",
+			Labels: map[string]string{
+				"cnpg.io/cluster": "test-cluster",
+				LabelPoolerName:   "test-cluster-pooler-rw",
+			},
+		},
+	}
+
 	client := fake.NewClientBuilder().
 		WithScheme(scheme).
-		WithRuntimeObjects(primaryPod, replicaPod).
+		WithRuntimeObjects(primaryPod, replicaPod, poolerPod).
 		Build()
 
 	discovery := NewDiscovery(client)
@@ -235,7 +310,7 @@ func TestDiscovery_GetClusterPods(t *testing.T) {
 	}
 
 	if len(pods) != 2 {
-		t.Errorf("expected 2 pods, got %d", len(pods))
+		t.Errorf("expected 2 instance pods, got %d", len(pods))
 	}
 }
 
@@ -336,12 +411,25 @@ func TestExtractClusterInfo(t *testing.T) {
 					"size":         "10Gi",
 					"storageClass": "gp3-csi",
 				},
+				"tablespaces": []interface{}{
+					map[string]interface{}{
+						"name": "archive",
+						"storage": map[string]interface{}{
+							"size":         "50Gi",
+							"storageClass": "standard",
+						},
+					},
+				},
 			},
 			"status": map[string]interface{}{
 				"phase":              "Cluster in healthy state",
 				"readyInstances":     int64(3),
 				"currentPrimary":     "test-cluster-1",
 				"currentPrimaryNode": "worker-1",
+				"lastSuccessfulBackupByMethod": map[string]interface{}{
+					"barmanObjectStore": "2025-01-02T00:00:00Z",
+					"volumeSnapshot":    "not a time",
+				},
 			},
 		},
 	}
@@ -372,6 +460,103 @@ func TestExtractClusterInfo(t *testing.T) {
 	if !info.Status.Ready {
 		t.Error("expected cluster to be ready")
 	}
+	expectedTablespaces := []TablespaceInfo{{Name: "archive", Size: "50Gi", StorageClass: "standard"}}
+	if !reflect.DeepEqual(info.Tablespaces, expectedTablespaces) {
+		t.Errorf("expected tablespaces %+v, got %+v", expectedTablespaces, info.Tablespaces)
+	}
+	if info.Hibernated {
+		t.Error("expected cluster not to be hibernated")
+	}
+	expectedByMethod := map[string]time.Time{"barmanObjectStore": time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(info.Status.LastSuccessfulBackupByMethod, expectedByMethod) {
+		t.Errorf("expected last backups by method %v, got %v", expectedByMethod, info.Status.LastSuccessfulBackupByMethod)
+	}
+	if info.Status.FirstRecoverabilityPointByMethod != nil {
+		t.Errorf("expected no recovery points by method, got %v", info.Status.FirstRecoverabilityPointByMethod)
+	}
+}
+
+func TestExtractClusterInfo_Hibernated(t *testing.T) {
+	discovery := NewDiscovery(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+
+	tests := []struct {
+		name        string
+		annotations map[string]interface{}
+		phase       string
+		expected    bool
+	}{
+		{"hibernation annotation on", map[string]interface{}{AnnotationHibernation: "on"}, "", true},
+		{"hibernation annotation off", map[string]interface{}{AnnotationHibernation: "off"}, "", false},
+		{"offline phase", nil, ClusterPhaseOffline, true},
+		{"healthy", nil, "Cluster in healthy state", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := map[string]interface{}{"name": "pg", "namespace": "default"}
+			if tt.annotations != nil {
+				metadata["annotations"] = tt.annotations
+			}
+			cluster := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "postgresql.cnpg.io/v1",
+				"kind":       "Cluster",
+				"metadata":   metadata,
+				"status":     map[string]interface{}{"phase": tt.phase},
+			}}
+
+			info, err := discovery.extractClusterInfo(cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if info.Hibernated != tt.expected {
+				t.Errorf("expected hibernated %v, got %v", tt.expected, info.Hibernated)
+			}
+		})
+	}
+}
+
+func TestFencedInstances(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{"single instance", `["pg-1"]`, []string{"pg-1"}},
+		{"all instances", `["*"]`, []string{FenceAllInstances}},
+		{"empty list", `[]`, []string{}},
+		{"malformed", `pg-1`, nil},
+		{"empty value", ``, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instances := FencedInstances(map[string]string{AnnotationFencedInstances: tt.value})
+			if !reflect.DeepEqual(instances, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, instances)
+			}
+		})
+	}
+}
+
+func TestSetFencedInstances(t *testing.T) {
+	annotations := map[string]string{}
+	SetFencedInstances(annotations, []string{"pg-2", "pg-1", "pg-2"})
+	if value := annotations[AnnotationFencedInstances]; value != `["pg-1","pg-2"]` {
+		t.Errorf("expected sorted unique instances, got %s", value)
+	}
+	SetFencedInstances(annotations, nil)
+	if value := annotations[AnnotationFencedInstances]; value != `[]` {
+		t.Errorf("expected an empty list, got %s", value)
+	}
+}
+
+func TestClusterInfo_IsInstanceFenced(t *testing.T) {
+	cluster := ClusterInfo{FencedInstances: []string{"pg-1"}}
+	if !cluster.IsInstanceFenced("pg-1") || cluster.IsInstanceFenced("pg-2") {
+		t.Errorf("expected only pg-1 to be fenced")
+	}
+	cluster.FencedInstances = []string{FenceAllInstances}
+	if !cluster.IsInstanceFenced("pg-2") {
+		t.Errorf("expected every instance to be fenced by %q", FenceAllInstances)
+	}
 }
 
 func TestExtractClusterInfo_Defaults(t *testing.T) {
@@ -440,3 +625,263 @@ func TestClusterInfo_Labels(t *testing.T) {
 		t.Error("expected team=database label")
 	}
 }
+
+func TestDiscovery_GetBackupStatusesForClusters(t *testing.T) {
+	scheme := runtime.NewScheme()
+
+	objectStore := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "barmancloud.cnpg.io/v1",
+			"kind":       "ObjectStore",
+			"metadata": map[string]interface{}{
+				"name":      "shared-store",
+				"namespace": "default",
+			},
+			"status": map[string]interface{}{
+				"serverRecoveryWindow": map[string]interface{}{
+					"cluster-a": map[string]interface{}{
+						"firstRecoverabilityPoint": "2025-01-01T00:00:00Z",
+						"lastSuccessfulBackupTime": "2025-01-02T00:00:00Z",
+					},
+					"cluster-b": map[string]interface{}{
+						"lastSuccessfulBackupTime": "2025-01-03T00:00:00Z",
+					},
+				},
+			},
+		},
+	}
+
+	var objectStoreGets int
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objectStore).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, cl client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if u, ok := obj.(*unstructured.Unstructured); ok && u.GroupVersionKind() == ObjectStoreGVK {
+					objectStoreGets++
+				}
+				return cl.Get(ctx, key, obj, opts...)
+			},
+		}).
+		Build()
+
+	pluginCluster := func(name, store string) ClusterInfo {
+		return ClusterInfo{
+			Name:      name,
+			Namespace: "default",
+			Status: ClusterStatus{
+				BarmanCloudPlugin: &BarmanCloudPluginInfo{
+					Enabled:              true,
+					ObjectStoreName:      store,
+					ObjectStoreNamespace: "default",
+				},
+			},
+		}
+	}
+
+	clusters := []ClusterInfo{
+		pluginCluster("cluster-a", "shared-store"),
+		pluginCluster("cluster-b", "shared-store"),
+		pluginCluster("cluster-c", "shared-store"),
+		pluginCluster("cluster-d", "missing-store"),
+	}
+
+	discovery := NewDiscovery(c)
+	index := discovery.GetBackupStatusesForClusters(context.Background(), clusters)
+
+	if objectStoreGets != 2 {
+		t.Errorf("expected 2 ObjectStore GETs (one per store), got %d", objectStoreGets)
+	}
+
+	statusA, err := index.ForCluster(clusters[0])
+	if err != nil || statusA == nil {
+		t.Fatalf("expected status for cluster-a, got %v (err=%v)", statusA, err)
+	}
+	if statusA.FirstRecoverabilityPoint == nil || statusA.LastSuccessfulBackupTime == nil {
+		t.Error("expected both timestamps for cluster-a")
+	}
+
+	statusB, err := index.ForCluster(clusters[1])
+	if err != nil || statusB == nil || statusB.LastSuccessfulBackupTime == nil {
+		t.Errorf("expected last backup time for cluster-b, got %v (err=%v)", statusB, err)
+	}
+
+	statusC, err := index.ForCluster(clusters[2])
+	if err != nil || statusC != nil {
+		t.Errorf("expected no status and no error for cluster-c, got %v (err=%v)", statusC, err)
+	}
+
+	if _, err := index.ForCluster(clusters[3]); err == nil {
+		t.Error("expected error for cluster referencing a missing ObjectStore")
+	}
+}
+
+func TestDiscovery_ListScheduledBackups(t *testing.T) {
+	newScheduled := func(name, cluster string, spec map[string]interface{}) *unstructured.Unstructured {
+		spec["cluster"] = map[string]interface{}{"name": cluster}
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": CNPGGroupVersion,
+				"kind":       "ScheduledBackup",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "db",
+				},
+				"spec": spec,
+				"status": map[string]interface{}{
+					"lastScheduleTime": "2025-03-12T02:00:00Z",
+				},
+			},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(
+		newScheduled("pg-daily", "pg", map[string]interface{}{"schedule": "0 0 2 * * *"}),
+		newScheduled("pg-snapshots", "pg", map[string]interface{}{
+			"schedule": "0 0 3 * * 0",
+			"method":   BackupMethodVolumeSnapshot,
+			"suspend":  true,
+		}),
+		newScheduled("other-daily", "other", map[string]interface{}{"schedule": "@daily"}),
+	).Build()
+
+	scheduled, err := NewDiscovery(c).ListScheduledBackups(context.Background(), "db", "pg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(scheduled) != 2 {
+		t.Fatalf("expected 2 scheduled backups for pg, got %d", len(scheduled))
+	}
+
+	byName := make(map[string]ScheduledBackupInfo)
+	for _, sb := range scheduled {
+		byName[sb.Name] = sb
+	}
+	daily := byName["pg-daily"]
+	if daily.Method != BackupMethodBarmanObjectStore || daily.Suspended || daily.Schedule != "0 0 2 * * *" {
+		t.Errorf("unexpected daily scheduled backup: %+v", daily)
+	}
+	if daily.LastScheduleTime == nil {
+		t.Error("expected lastScheduleTime to be parsed")
+	}
+	snapshots := byName["pg-snapshots"]
+	if snapshots.Method != BackupMethodVolumeSnapshot || !snapshots.Suspended {
+		t.Errorf("unexpected snapshot scheduled backup: %+v", snapshots)
+	}
+}
+
+func TestDiscovery_SetStorageClass(t *testing.T) {
+	tests := []struct {
+		name            string
+		walStorage      bool
+		walStorageClass string
+		expectWALClass  string
+	}{
+		{name: "data volumes only"},
+		{name: "WAL volumes", walStorage: true, walStorageClass: "gp3-wal", expectWALClass: "gp3-wal"},
+		{name: "WAL volumes keep their class without walStorageClass", walStorage: true, expectWALClass: "gp2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := map[string]interface{}{
+				"instances": int64(3),
+				"storage":   map[string]interface{}{"size": "10Gi", "storageClass": "gp2"},
+			}
+			if tt.walStorage {
+				spec["walStorage"] = map[string]interface{}{"size": "1Gi", "storageClass": "gp2"}
+			}
+			cluster := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "postgresql.cnpg.io/v1",
+					"kind":       "Cluster",
+					"metadata":   map[string]interface{}{"name": "test-cluster", "namespace": "default"},
+					"spec":       spec,
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(cluster).Build()
+			discovery := NewDiscovery(c)
+
+			if err := discovery.SetStorageClass(context.Background(), "test-cluster", "default", "gp3",
+				tt.walStorageClass); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			info, err := discovery.GetCluster(context.Background(), "test-cluster", "default")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if info.Storage.StorageClass != "gp3" {
+				t.Errorf("expected storage class gp3, got %q", info.Storage.StorageClass)
+			}
+			if !tt.walStorage {
+				if info.WALStorage != nil {
+					t.Errorf("expected no WAL storage, got %+v", info.WALStorage)
+				}
+				return
+			}
+			if info.WALStorage == nil || info.WALStorage.StorageClass != tt.expectWALClass {
+				t.Errorf("expected WAL storage class %q, got %+v", tt.expectWALClass, info.WALStorage)
+			}
+		})
+	}
+}
+
+func TestDiscovery_UpdateClusterAnnotations(t *testing.T) {
+	const managed = "storage.cnpg.supporttools.io/managed"
+	const lastFailure = "storage.cnpg.supporttools.io/last-failure"
+	cluster := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "postgresql.cnpg.io/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]interface{}{
+				"name":      "test-cluster",
+				"namespace": "default",
+				"annotations": map[string]interface{}{
+					"cnpg.io/hibernation": "on",
+					managed:               "false",
+					lastFailure:           "2025-01-01T00:00:00Z",
+				},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(cluster).Build()
+	discovery := NewDiscovery(c)
+	ctx := context.Background()
+
+	read, err := discovery.GetClusterAnnotations(ctx, "test-cluster", "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A stale value of an annotation the manager does not own is not written back,
+	// and a manager annotation missing from the values is removed
+	err = discovery.UpdateClusterAnnotations(ctx, "test-cluster", "default", read, map[string]string{
+		"cnpg.io/hibernation": "off",
+		managed:               "true",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := discovery.GetClusterAnnotations(ctx, "test-cluster", "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["cnpg.io/hibernation"] != "on" || got[managed] != "true" {
+		t.Errorf("unexpected annotations after update: %v", got)
+	}
+	if _, ok := got[lastFailure]; ok {
+		t.Errorf("expected %s to be removed, got %v", lastFailure, got)
+	}
+
+	if err := discovery.RemoveClusterAnnotations(ctx, "test-cluster", "default"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err = discovery.GetClusterAnnotations(ctx, "test-cluster", "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := got[managed]; ok || got["cnpg.io/hibernation"] != "on" {
+		t.Errorf("unexpected annotations after removal: %v", got)
+	}
+}
@@ -0,0 +1,454 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// CRDRetryInterval is how often the inventory retries watching CNPG clusters while
+// their CRD is not installed
+const CRDRetryInterval = time.Minute
+
+// InventoryEventType is the kind of change delivered to inventory subscribers
+type InventoryEventType string
+
+const (
+	// InventoryClusterAdded is delivered when a cluster appears in the inventory
+	InventoryClusterAdded InventoryEventType = "Added"
+	// InventoryClusterUpdated is delivered when a known cluster changes
+	InventoryClusterUpdated InventoryEventType = "Updated"
+	// InventoryClusterDeleted is delivered when a cluster is removed
+	InventoryClusterDeleted InventoryEventType = "Deleted"
+)
+
+// InventoryEvent describes a change to a cluster in the inventory
+type InventoryEvent struct {
+	Type InventoryEventType
+	// Cluster is the current state, or the last known state for deletions
+	Cluster ClusterInfo
+	// Previous is the state before an update, nil otherwise
+	Previous *ClusterInfo
+}
+
+// InventorySubscriber is called for every inventory change. Subscribers are called
+// synchronously from the watch goroutine and must not block.
+type InventorySubscriber func(InventoryEvent)
+
+// index maps an index value to a set of cluster keys
+type index map[string]map[string]struct{}
+
+func (idx index) add(value, key string) {
+	if idx[value] == nil {
+		idx[value] = make(map[string]struct{})
+	}
+	idx[value][key] = struct{}{}
+}
+
+func (idx index) remove(value, key string) {
+	delete(idx[value], key)
+	if len(idx[value]) == 0 {
+		delete(idx, value)
+	}
+}
+
+// Inventory maintains a live, indexed view of all CNPG clusters driven by a watch,
+// so that consumers do not have to list and parse clusters on every reconcile
+type Inventory struct {
+	mu             sync.RWMutex
+	clusters       map[string]ClusterInfo
+	byNamespace    index
+	byLabel        index
+	byBackupMethod index
+	byStorageClass index
+
+	subMu       sync.RWMutex
+	subscribers map[int]InventorySubscriber
+	nextSubID   int
+
+	synced atomic.Bool
+
+	// mapper negotiates the CNPG Cluster API version to watch. v1 is watched when nil
+	mapper meta.RESTMapper
+
+	extract func(*unstructured.Unstructured) (ClusterInfo, error)
+}
+
+// NewInventory creates an empty inventory. It is populated by Start, or by Upsert
+// and Delete when driven externally.
+func NewInventory() *Inventory {
+	return &Inventory{
+		clusters:       make(map[string]ClusterInfo),
+		byNamespace:    make(index),
+		byLabel:        make(index),
+		byBackupMethod: make(index),
+		byStorageClass: make(index),
+		subscribers:    make(map[int]InventorySubscriber),
+		extract:        (&Discovery{}).extractClusterInfo,
+	}
+}
+
+// WithRESTMapper negotiates the CNPG Cluster API version to watch with mapper instead of
+// always watching v1
+func (inv *Inventory) WithRESTMapper(mapper meta.RESTMapper) *Inventory {
+	inv.mapper = mapper
+	return inv
+}
+
+// Start registers a watch on CNPG clusters with the given informer source, marks
+// the inventory as synced once the initial list is loaded and blocks until the
+// context is cancelled. While the CNPG CRDs are not installed the inventory stays
+// unsynced, consumers fall back to listing clusters directly and the watch is
+// retried every CRDRetryInterval until the CRDs appear. The watched API version is
+// negotiated with the RESTMapper set by WithRESTMapper.
+func (inv *Inventory) Start(ctx context.Context, informers cache.Informers) error {
+	log := logf.FromContext(ctx).WithName("cnpg-inventory")
+
+	informer, err := inv.clusterInformer(ctx, informers)
+	if meta.IsNoMatchError(err) {
+		log.Info("CNPG Cluster CRD not installed, cluster inventory disabled until it appears")
+		ticker := time.NewTicker(CRDRetryInterval)
+		defer ticker.Stop()
+		for meta.IsNoMatchError(err) {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+			informer, err = inv.clusterInformer(ctx, informers)
+		}
+		if err == nil {
+			log.Info("CNPG Cluster CRD installed, starting cluster inventory")
+		}
+	}
+	if errors.Is(err, ErrUnsupportedClusterAPI) {
+		// Consumers keep listing clusters directly, which reports the error on the policies
+		log.Error(err, "CNPG Cluster API version not supported, cluster inventory disabled")
+		<-ctx.Done()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get CNPG cluster informer: %w", err)
+	}
+
+	registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(o interface{}) {
+			if u, ok := o.(*unstructured.Unstructured); ok {
+				inv.Upsert(u)
+			}
+		},
+		UpdateFunc: func(_, o interface{}) {
+			if u, ok := o.(*unstructured.Unstructured); ok {
+				inv.Upsert(u)
+			}
+		},
+		DeleteFunc: func(o interface{}) {
+			if tombstone, ok := o.(toolscache.DeletedFinalStateUnknown); ok {
+				o = tombstone.Obj
+			}
+			if u, ok := o.(*unstructured.Unstructured); ok {
+				inv.Delete(u.GetNamespace(), u.GetName())
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register CNPG cluster event handler: %w", err)
+	}
+
+	if !toolscache.WaitForCacheSync(ctx.Done(), registration.HasSynced) {
+		return nil
+	}
+	inv.synced.Store(true)
+	log.Info("CNPG cluster inventory synced", "clusters", inv.Len())
+
+	<-ctx.Done()
+	return nil
+}
+
+// clusterInformer returns the informer of CNPG clusters of the API version negotiated
+// with the RESTMapper
+func (inv *Inventory) clusterInformer(ctx context.Context, informers cache.Informers) (cache.Informer, error) {
+	obj, err := NewClusterObject(inv.mapper)
+	if err != nil {
+		return nil, err
+	}
+	return informers.GetInformer(ctx, obj)
+}
+
+// ClusterAPI returns the CNPG Cluster API version the inventory watches. The default
+// version is returned for a nil inventory and when no supported version is served.
+func (inv *Inventory) ClusterAPI() ClusterAPI {
+	if inv == nil {
+		return defaultClusterAPI
+	}
+	api, err := resolveClusterAPI(inv.mapper)
+	if err != nil {
+		return defaultClusterAPI
+	}
+	return api
+}
+
+// HasSynced reports whether the inventory holds the complete set of clusters
+func (inv *Inventory) HasSynced() bool {
+	return inv != nil && inv.synced.Load()
+}
+
+// Upsert adds or updates a cluster from its unstructured representation
+func (inv *Inventory) Upsert(obj *unstructured.Unstructured) {
+	info, err := inv.extract(obj)
+	if err != nil {
+		return
+	}
+	key := clusterKey(info.Namespace, info.Name)
+
+	inv.mu.Lock()
+	previous, existed := inv.clusters[key]
+	if existed {
+		inv.unindex(key, previous)
+	}
+	inv.clusters[key] = info
+	inv.indexCluster(key, info)
+	inv.mu.Unlock()
+
+	event := InventoryEvent{Type: InventoryClusterAdded, Cluster: info}
+	if existed {
+		event.Type = InventoryClusterUpdated
+		event.Previous = &previous
+	}
+	inv.notify(event)
+}
+
+// Delete removes a cluster from the inventory
+func (inv *Inventory) Delete(namespace, name string) {
+	key := clusterKey(namespace, name)
+
+	inv.mu.Lock()
+	previous, existed := inv.clusters[key]
+	if existed {
+		inv.unindex(key, previous)
+		delete(inv.clusters, key)
+	}
+	inv.mu.Unlock()
+
+	if existed {
+		inv.notify(InventoryEvent{Type: InventoryClusterDeleted, Cluster: previous})
+	}
+}
+
+// Subscribe registers a callback for inventory changes and returns a function that
+// removes it
+func (inv *Inventory) Subscribe(fn InventorySubscriber) func() {
+	inv.subMu.Lock()
+	id := inv.nextSubID
+	inv.nextSubID++
+	inv.subscribers[id] = fn
+	inv.subMu.Unlock()
+
+	return func() {
+		inv.subMu.Lock()
+		delete(inv.subscribers, id)
+		inv.subMu.Unlock()
+	}
+}
+
+// Len returns the number of clusters in the inventory
+func (inv *Inventory) Len() int {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	return len(inv.clusters)
+}
+
+// Get returns a cluster by namespace and name
+func (inv *Inventory) Get(namespace, name string) (ClusterInfo, bool) {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	info, ok := inv.clusters[clusterKey(namespace, name)]
+	return info, ok
+}
+
+// List returns all clusters in a namespace (or all namespaces if empty), sorted by
+// namespace and name
+func (inv *Inventory) List(namespace string) []ClusterInfo {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	if namespace == "" {
+		return inv.all()
+	}
+	return inv.collect(inv.byNamespace[namespace])
+}
+
+// Select returns the clusters in a namespace (or all namespaces if empty) matching a
+// label selector. A nil selector matches everything.
+func (inv *Inventory) Select(namespace string, selector *metav1.LabelSelector) ([]ClusterInfo, error) {
+	if selector == nil {
+		return inv.List(namespace), nil
+	}
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+
+	// Narrow the candidates with the smallest matchLabels or namespace index before filtering
+	var candidates []ClusterInfo
+	var smallest map[string]struct{}
+	narrowed := false
+	narrow := func(keys map[string]struct{}) {
+		if !narrowed || len(keys) < len(smallest) {
+			smallest = keys
+			narrowed = true
+		}
+	}
+	for k, v := range selector.MatchLabels {
+		narrow(inv.byLabel[labelIndexValue(k, v)])
+	}
+	if namespace != "" {
+		narrow(inv.byNamespace[namespace])
+	}
+	if narrowed {
+		candidates = inv.collect(smallest)
+	} else {
+		candidates = inv.all()
+	}
+
+	var matched []ClusterInfo
+	for _, info := range candidates {
+		if namespace != "" && info.Namespace != namespace {
+			continue
+		}
+		if sel.Matches(labels.Set(info.Labels)) {
+			matched = append(matched, info)
+		}
+	}
+	return matched, nil
+}
+
+// ByLabel returns the clusters carrying the given label value
+func (inv *Inventory) ByLabel(key, value string) []ClusterInfo {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	return inv.collect(inv.byLabel[labelIndexValue(key, value)])
+}
+
+// ByBackupMethod returns the clusters with the given backup method configured
+func (inv *Inventory) ByBackupMethod(method string) []ClusterInfo {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	return inv.collect(inv.byBackupMethod[method])
+}
+
+// ByStorageClass returns the clusters using the given storage class. Clusters that
+// do not set a storage class are indexed under the empty string.
+func (inv *Inventory) ByStorageClass(storageClass string) []ClusterInfo {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	return inv.collect(inv.byStorageClass[storageClass])
+}
+
+// all returns every cluster sorted by namespace and name. Callers must hold the read lock.
+func (inv *Inventory) all() []ClusterInfo {
+	result := make([]ClusterInfo, 0, len(inv.clusters))
+	for _, info := range inv.clusters {
+		result = append(result, info)
+	}
+	sortClusters(result)
+	return result
+}
+
+// collect returns the clusters for a set of keys sorted by namespace and name.
+// Callers must hold the read lock.
+func (inv *Inventory) collect(keys map[string]struct{}) []ClusterInfo {
+	result := make([]ClusterInfo, 0, len(keys))
+	for key := range keys {
+		if info, ok := inv.clusters[key]; ok {
+			result = append(result, info)
+		}
+	}
+	sortClusters(result)
+	return result
+}
+
+func sortClusters(clusters []ClusterInfo) {
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Namespace != clusters[j].Namespace {
+			return clusters[i].Namespace < clusters[j].Namespace
+		}
+		return clusters[i].Name < clusters[j].Name
+	})
+}
+
+// indexCluster adds a cluster to all indexes. Callers must hold the write lock.
+func (inv *Inventory) indexCluster(key string, info ClusterInfo) {
+	inv.byNamespace.add(info.Namespace, key)
+	for k, v := range info.Labels {
+		inv.byLabel.add(labelIndexValue(k, v), key)
+	}
+	for _, method := range info.Status.BackupMethods {
+		inv.byBackupMethod.add(method, key)
+	}
+	inv.byStorageClass.add(info.Storage.StorageClass, key)
+}
+
+// unindex removes a cluster from all indexes. Callers must hold the write lock.
+func (inv *Inventory) unindex(key string, info ClusterInfo) {
+	inv.byNamespace.remove(info.Namespace, key)
+	for k, v := range info.Labels {
+		inv.byLabel.remove(labelIndexValue(k, v), key)
+	}
+	for _, method := range info.Status.BackupMethods {
+		inv.byBackupMethod.remove(method, key)
+	}
+	inv.byStorageClass.remove(info.Storage.StorageClass, key)
+}
+
+// notify delivers an event to all subscribers outside the inventory lock
+func (inv *Inventory) notify(event InventoryEvent) {
+	inv.subMu.RLock()
+	subscribers := make([]InventorySubscriber, 0, len(inv.subscribers))
+	for _, fn := range inv.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	inv.subMu.RUnlock()
+
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
+
+func clusterKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+func labelIndexValue(key, value string) string {
+	return key + "=" + value
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testCluster(name, namespace string, labels, spec map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"name":      name,
+		"namespace": namespace,
+	}
+	if labels != nil {
+		metadata["labels"] = labels
+	}
+	if spec == nil {
+		spec = map[string]interface{}{}
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": CNPGGroupVersion,
+			"kind":       CNPGKind,
+			"metadata":   metadata,
+			"spec":       spec,
+		},
+	}
+}
+
+func clusterNames(clusters []ClusterInfo) []string {
+	names := make([]string, 0, len(clusters))
+	for _, c := range clusters {
+		names = append(names, c.Namespace+"/"+c.Name)
+	}
+	return names
+}
+
+func assertNames(t *testing.T, what string, got []ClusterInfo, want ...string) {
+	t.Helper()
+	names := clusterNames(got)
+	if len(names) != len(want) {
+		t.Fatalf("%s: expected %v, got %v", what, want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("%s: expected %v, got %v", what, want, names)
+		}
+	}
+}
+
+func newTestInventory() *Inventory {
+	inv := NewInventory()
+	inv.Upsert(testCluster("pg-a", "prod", map[string]interface{}{"env": "prod", "tier": "gold"},
+		map[string]interface{}{
+			"storage": map[string]interface{}{"storageClass": "fast"},
+			"backup":  map[string]interface{}{"barmanObjectStore": map[string]interface{}{}},
+		}))
+	inv.Upsert(testCluster("pg-b", "prod", map[string]interface{}{"env": "prod"},
+		map[string]interface{}{
+			"storage": map[string]interface{}{"storageClass": "standard"},
+			"backup":  map[string]interface{}{"volumeSnapshot": map[string]interface{}{}},
+		}))
+	inv.Upsert(testCluster("pg-c", "dev", map[string]interface{}{"env": "dev"},
+		map[string]interface{}{"storage": map[string]interface{}{"storageClass": "fast"}}))
+	return inv
+}
+
+func TestInventory_Indexes(t *testing.T) {
+	inv := newTestInventory()
+
+	if inv.Len() != 3 {
+		t.Fatalf("expected 3 clusters, got %d", inv.Len())
+	}
+	assertNames(t, "List all", inv.List(""), "dev/pg-c", "prod/pg-a", "prod/pg-b")
+	assertNames(t, "List prod", inv.List("prod"), "prod/pg-a", "prod/pg-b")
+	assertNames(t, "ByLabel", inv.ByLabel("env", "prod"), "prod/pg-a", "prod/pg-b")
+	assertNames(t, "ByBackupMethod", inv.ByBackupMethod(BackupMethodVolumeSnapshot), "prod/pg-b")
+	assertNames(t, "ByStorageClass", inv.ByStorageClass("fast"), "dev/pg-c", "prod/pg-a")
+
+	if _, ok := inv.Get("prod", "pg-a"); !ok {
+		t.Error("expected prod/pg-a to be found")
+	}
+	if _, ok := inv.Get("prod", "missing"); ok {
+		t.Error("expected prod/missing not to be found")
+	}
+}
+
+func TestInventory_Select(t *testing.T) {
+	inv := newTestInventory()
+
+	tests := []struct {
+		name      string
+		namespace string
+		selector  *metav1.LabelSelector
+		want      []string
+	}{
+		{name: "nil selector", selector: nil, want: []string{"dev/pg-c", "prod/pg-a", "prod/pg-b"}},
+		{
+			name:     "match labels",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod", "tier": "gold"}},
+			want:     []string{"prod/pg-a"},
+		},
+		{
+			name:     "no match",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "staging"}},
+			want:     []string{},
+		},
+		{
+			name: "match expressions",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpDoesNotExist},
+			}},
+			want: []string{"dev/pg-c", "prod/pg-b"},
+		},
+		{
+			name:      "namespaced",
+			namespace: "dev",
+			selector:  &metav1.LabelSelector{},
+			want:      []string{"dev/pg-c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inv.Select(tt.namespace, tt.selector)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertNames(t, tt.name, got, tt.want...)
+		})
+	}
+
+	_, err := inv.Select("", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "env", Operator: "Bogus"},
+	}})
+	if err == nil {
+		t.Error("expected error for invalid selector")
+	}
+}
+
+func TestInventory_UpdateReindexes(t *testing.T) {
+	inv := newTestInventory()
+
+	inv.Upsert(testCluster("pg-a", "prod", map[string]interface{}{"env": "staging"},
+		map[string]interface{}{"storage": map[string]interface{}{"storageClass": "standard"}}))
+
+	assertNames(t, "old label", inv.ByLabel("env", "prod"), "prod/pg-b")
+	assertNames(t, "new label", inv.ByLabel("env", "staging"), "prod/pg-a")
+	assertNames(t, "old storage class", inv.ByStorageClass("fast"), "dev/pg-c")
+	assertNames(t, "old backup method", inv.ByBackupMethod(BackupMethodBarmanObjectStore))
+
+	inv.Delete("prod", "pg-a")
+	assertNames(t, "after delete", inv.ByLabel("env", "staging"))
+	if inv.Len() != 2 {
+		t.Errorf("expected 2 clusters after delete, got %d", inv.Len())
+	}
+}
+
+func TestInventory_Subscribe(t *testing.T) {
+	inv := NewInventory()
+
+	var events []InventoryEvent
+	unsubscribe := inv.Subscribe(func(e InventoryEvent) {
+		events = append(events, e)
+	})
+
+	inv.Upsert(testCluster("pg", "prod", map[string]interface{}{"env": "prod"}, nil))
+	inv.Upsert(testCluster("pg", "prod", map[string]interface{}{"env": "dev"}, nil))
+	inv.Delete("prod", "pg")
+	inv.Delete("prod", "pg") // unknown clusters produce no event
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if events[0].Type != InventoryClusterAdded {
+		t.Errorf("expected Added, got %s", events[0].Type)
+	}
+	if events[1].Type != InventoryClusterUpdated || events[1].Previous == nil ||
+		events[1].Previous.Labels["env"] != "prod" || events[1].Cluster.Labels["env"] != "dev" {
+		t.Errorf("unexpected update event: %+v", events[1])
+	}
+	if events[2].Type != InventoryClusterDeleted || events[2].Cluster.Labels["env"] != "dev" {
+		t.Errorf("unexpected delete event: %+v", events[2])
+	}
+
+	unsubscribe()
+	inv.Upsert(testCluster("pg", "prod", nil, nil))
+	if len(events) != 3 {
+		t.Errorf("expected no events after unsubscribe, got %d", len(events))
+	}
+}
+
+func TestDiscovery_WithInventory(t *testing.T) {
+	scheme := runtime.NewScheme()
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	inv := newTestInventory()
+	discovery := NewDiscovery(client).WithInventory(inv)
+	ctx := context.Background()
+
+	// Until synced, listings go to the API server, which has no clusters
+	clusters, err := discovery.ListClusters(ctx, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertNames(t, "unsynced ListClusters", clusters)
+
+	inv.synced.Store(true)
+	clusters, err = discovery.ListClusters(ctx, "prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertNames(t, "ListClusters", clusters, "prod/pg-a", "prod/pg-b")
+
+	clusters, err = discovery.GetClustersBySelector(ctx, "", &metav1.LabelSelector{
+		MatchLabels: map[string]string{"env": "dev"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertNames(t, "GetClustersBySelector", clusters, "dev/pg-c")
+}
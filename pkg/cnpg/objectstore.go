@@ -0,0 +1,249 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ProviderS3 is the barman-cloud provider for S3-compatible object stores
+	ProviderS3 = "aws-s3"
+	// ProviderAzure is the barman-cloud provider for Azure Blob Storage
+	ProviderAzure = "azure-blob-storage"
+	// ProviderGoogle is the barman-cloud provider for Google Cloud Storage
+	ProviderGoogle = "google-cloud-storage"
+)
+
+// SecretKeyRef references a key of a Secret in the object store's namespace
+type SecretKeyRef struct {
+	Name string
+	Key  string
+}
+
+// ObjectStoreCredential is a credential passed to the barman-cloud tools in an environment variable
+type ObjectStoreCredential struct {
+	Env string
+	Ref SecretKeyRef
+}
+
+// ObjectStoreConfig is the barman object store configuration used by a cluster, either
+// from an ObjectStore referenced by the barman-cloud plugin or from the in-tree
+// spec.backup.barmanObjectStore section
+type ObjectStoreConfig struct {
+	// Source identifies the configuration, e.g. "ObjectStore/minio" or "Cluster/pg-main"
+	Source string
+	// Namespace is where the configuration and its credential Secrets live
+	Namespace       string
+	DestinationPath string
+	EndpointURL     string
+	// ServerName is the folder of the cluster in the object store
+	ServerName string
+	// Provider is the barman-cloud --cloud-provider value
+	Provider    string
+	Credentials []ObjectStoreCredential
+	// CredentialFile is a credential the tools read from a file (Google application credentials)
+	CredentialFile *SecretKeyRef
+	// EndpointCA is the CA bundle used to verify the endpoint
+	EndpointCA *SecretKeyRef
+	// InheritedCredentials is true when credentials come from the pod identity
+	// (IAM role, Azure AD workload identity or GKE) instead of Secrets
+	InheritedCredentials bool
+}
+
+// SecretRefs returns every Secret key the configuration references
+func (c *ObjectStoreConfig) SecretRefs() []SecretKeyRef {
+	refs := make([]SecretKeyRef, 0, len(c.Credentials)+2)
+	for _, cred := range c.Credentials {
+		refs = append(refs, cred.Ref)
+	}
+	if c.CredentialFile != nil {
+		refs = append(refs, *c.CredentialFile)
+	}
+	if c.EndpointCA != nil {
+		refs = append(refs, *c.EndpointCA)
+	}
+	return refs
+}
+
+// Validate checks the configuration for problems that would make every backup fail
+func (c *ObjectStoreConfig) Validate() error {
+	if c.DestinationPath == "" {
+		return fmt.Errorf("%s has no destinationPath", c.Source)
+	}
+	if c.InheritedCredentials {
+		return nil
+	}
+
+	present := make(map[string]bool, len(c.Credentials))
+	for _, cred := range c.Credentials {
+		present[cred.Env] = true
+	}
+	switch c.Provider {
+	case ProviderS3:
+		if !present["AWS_ACCESS_KEY_ID"] || !present["AWS_SECRET_ACCESS_KEY"] {
+			return fmt.Errorf("%s: s3Credentials need accessKeyId and secretAccessKey or inheritFromIAMRole", c.Source)
+		}
+	case ProviderAzure:
+		if !present["AZURE_STORAGE_CONNECTION_STRING"] && (!present["AZURE_STORAGE_ACCOUNT"] ||
+			!present["AZURE_STORAGE_KEY"] && !present["AZURE_STORAGE_SAS_TOKEN"]) {
+			return fmt.Errorf("%s: azureCredentials need connectionString, storageAccount with storageKey "+
+				"or storageSasToken, or inheritFromAzureAD", c.Source)
+		}
+	case ProviderGoogle:
+		if c.CredentialFile == nil {
+			return fmt.Errorf("%s: googleCredentials need applicationCredentials or gkeEnvironment", c.Source)
+		}
+	}
+	return nil
+}
+
+// GetObjectStoreConfig resolves the object store configuration of a cluster. It returns
+// nil when the cluster does not back up to an object store.
+func (d *Discovery) GetObjectStoreConfig(ctx context.Context, cluster ClusterInfo) (*ObjectStoreConfig, error) {
+	plugin := cluster.Status.BarmanCloudPlugin
+	if plugin != nil && plugin.Enabled && plugin.ObjectStoreName != "" {
+		objectStore := &unstructured.Unstructured{}
+		objectStore.SetGroupVersionKind(ObjectStoreGVK)
+		if err := d.client.Get(ctx, client.ObjectKey{
+			Name:      plugin.ObjectStoreName,
+			Namespace: plugin.ObjectStoreNamespace,
+		}, objectStore); err != nil {
+			return nil, fmt.Errorf(
+				"failed to get ObjectStore %s/%s: %w",
+				plugin.ObjectStoreNamespace, plugin.ObjectStoreName, err,
+			)
+		}
+
+		configuration, _, _ := unstructured.NestedMap(objectStore.Object, "spec", "configuration")
+		serverName := plugin.ServerName
+		if serverName == "" {
+			serverName = cluster.Name
+		}
+		return parseObjectStoreConfig(configuration, ObjectStoreKind+"/"+plugin.ObjectStoreName,
+			plugin.ObjectStoreNamespace, serverName), nil
+	}
+
+	hasBarman := false
+	for _, method := range cluster.Status.BackupMethods {
+		if method == BackupMethodBarmanObjectStore {
+			hasBarman = true
+		}
+	}
+	if !hasBarman {
+		return nil, nil
+	}
+
+	obj, api, err := d.newCluster()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.client.Get(ctx, client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}, obj); err != nil {
+		return nil, fmt.Errorf("failed to get CNPG cluster %s/%s: %w", cluster.Namespace, cluster.Name, err)
+	}
+	configuration, _, _ := unstructured.NestedMap(obj.Object, fieldPath(api.BackupPath, "barmanObjectStore")...)
+	serverName, _ := configuration["serverName"].(string)
+	if serverName == "" {
+		serverName = cluster.Name
+	}
+	return parseObjectStoreConfig(configuration, CNPGKind+"/"+cluster.Name, cluster.Namespace, serverName), nil
+}
+
+// parseObjectStoreConfig parses a barman object store configuration section
+func parseObjectStoreConfig(
+	configuration map[string]interface{},
+	source, namespace, serverName string,
+) *ObjectStoreConfig {
+	cfg := &ObjectStoreConfig{
+		Source:     source,
+		Namespace:  namespace,
+		ServerName: serverName,
+	}
+	cfg.DestinationPath, _ = configuration["destinationPath"].(string)
+	cfg.EndpointURL, _ = configuration["endpointURL"].(string)
+	cfg.EndpointCA = secretKeyRef(configuration["endpointCA"])
+
+	addCredentials := func(section map[string]interface{}, envs map[string]string) {
+		for field, env := range envs {
+			if ref := secretKeyRef(section[field]); ref != nil {
+				cfg.Credentials = append(cfg.Credentials, ObjectStoreCredential{Env: env, Ref: *ref})
+			}
+		}
+	}
+
+	if s3, ok := configuration["s3Credentials"].(map[string]interface{}); ok {
+		cfg.Provider = ProviderS3
+		cfg.InheritedCredentials, _ = s3["inheritFromIAMRole"].(bool)
+		addCredentials(s3, map[string]string{
+			"accessKeyId":     "AWS_ACCESS_KEY_ID",
+			"secretAccessKey": "AWS_SECRET_ACCESS_KEY",
+			"region":          "AWS_DEFAULT_REGION",
+			"sessionToken":    "AWS_SESSION_TOKEN",
+		})
+	} else if azure, ok := configuration["azureCredentials"].(map[string]interface{}); ok {
+		cfg.Provider = ProviderAzure
+		cfg.InheritedCredentials, _ = azure["inheritFromAzureAD"].(bool)
+		addCredentials(azure, map[string]string{
+			"connectionString": "AZURE_STORAGE_CONNECTION_STRING",
+			"storageAccount":   "AZURE_STORAGE_ACCOUNT",
+			"storageKey":       "AZURE_STORAGE_KEY",
+			"storageSasToken":  "AZURE_STORAGE_SAS_TOKEN",
+		})
+	} else if google, ok := configuration["googleCredentials"].(map[string]interface{}); ok {
+		cfg.Provider = ProviderGoogle
+		cfg.InheritedCredentials, _ = google["gkeEnvironment"].(bool)
+		cfg.CredentialFile = secretKeyRef(google["applicationCredentials"])
+	} else {
+		cfg.Provider = providerFromPath(cfg.DestinationPath)
+	}
+
+	// Map iteration order is random; keep the environment stable
+	sort.Slice(cfg.Credentials, func(i, j int) bool { return cfg.Credentials[i].Env < cfg.Credentials[j].Env })
+	return cfg
+}
+
+// secretKeyRef parses a {name, key} Secret reference
+func secretKeyRef(value interface{}) *SecretKeyRef {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	name, _ := m["name"].(string)
+	key, _ := m["key"].(string)
+	if name == "" || key == "" {
+		return nil
+	}
+	return &SecretKeyRef{Name: name, Key: key}
+}
+
+// providerFromPath guesses the provider from the destination path when no credentials section is set
+func providerFromPath(destinationPath string) string {
+	switch {
+	case strings.HasPrefix(destinationPath, "gs://"):
+		return ProviderGoogle
+	case strings.Contains(destinationPath, ".blob.core.windows.net"):
+		return ProviderAzure
+	default:
+		return ProviderS3
+	}
+}
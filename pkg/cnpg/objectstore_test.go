@@ -0,0 +1,218 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func secretRef(name, key string) map[string]interface{} {
+	return map[string]interface{}{"name": name, "key": key}
+}
+
+func TestParseObjectStoreConfig(t *testing.T) {
+	cfg := parseObjectStoreConfig(map[string]interface{}{
+		"destinationPath": "s3://backups/",
+		"endpointURL":     "https://minio:9000",
+		"endpointCA":      secretRef("minio-ca", "ca.crt"),
+		"s3Credentials": map[string]interface{}{
+			"accessKeyId":     secretRef("s3-creds", "ACCESS_KEY_ID"),
+			"secretAccessKey": secretRef("s3-creds", "ACCESS_SECRET_KEY"),
+		},
+	}, "ObjectStore/minio", "db", "pg")
+
+	if cfg.Provider != ProviderS3 {
+		t.Errorf("expected provider %s, got %s", ProviderS3, cfg.Provider)
+	}
+	if cfg.EndpointURL != "https://minio:9000" || cfg.ServerName != "pg" || cfg.Namespace != "db" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if len(cfg.Credentials) != 2 || cfg.Credentials[0].Env != "AWS_ACCESS_KEY_ID" ||
+		cfg.Credentials[1].Env != "AWS_SECRET_ACCESS_KEY" {
+		t.Errorf("unexpected credentials: %+v", cfg.Credentials)
+	}
+	if refs := cfg.SecretRefs(); len(refs) != 3 || refs[2].Name != "minio-ca" {
+		t.Errorf("unexpected secret refs: %+v", refs)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
+
+func TestObjectStoreConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		configuration map[string]interface{}
+		expectError   string
+	}{
+		{
+			name:          "missing destination path",
+			configuration: map[string]interface{}{"s3Credentials": map[string]interface{}{"inheritFromIAMRole": true}},
+			expectError:   "no destinationPath",
+		},
+		{
+			name: "s3 without secret key",
+			configuration: map[string]interface{}{
+				"destinationPath": "s3://backups/",
+				"s3Credentials": map[string]interface{}{
+					"accessKeyId": secretRef("s3-creds", "ACCESS_KEY_ID"),
+				},
+			},
+			expectError: "secretAccessKey",
+		},
+		{
+			name: "s3 with IAM role",
+			configuration: map[string]interface{}{
+				"destinationPath": "s3://backups/",
+				"s3Credentials":   map[string]interface{}{"inheritFromIAMRole": true},
+			},
+		},
+		{
+			name: "azure with account and SAS token",
+			configuration: map[string]interface{}{
+				"destinationPath": "https://acct.blob.core.windows.net/backups",
+				"azureCredentials": map[string]interface{}{
+					"storageAccount":  secretRef("azure", "ACCOUNT"),
+					"storageSasToken": secretRef("azure", "SAS"),
+				},
+			},
+		},
+		{
+			name: "azure account without key",
+			configuration: map[string]interface{}{
+				"destinationPath": "https://acct.blob.core.windows.net/backups",
+				"azureCredentials": map[string]interface{}{
+					"storageAccount": secretRef("azure", "ACCOUNT"),
+				},
+			},
+			expectError: "azureCredentials",
+		},
+		{
+			name: "google without credentials",
+			configuration: map[string]interface{}{
+				"destinationPath":   "gs://backups/",
+				"googleCredentials": map[string]interface{}{},
+			},
+			expectError: "googleCredentials",
+		},
+		{
+			name:          "no credentials section",
+			configuration: map[string]interface{}{"destinationPath": "s3://backups/"},
+			expectError:   "s3Credentials",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseObjectStoreConfig(tt.configuration, "Cluster/pg", "db", "pg").Validate()
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestDiscovery_GetObjectStoreConfig(t *testing.T) {
+	objectStore := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "minio", "namespace": "db"},
+		"spec": map[string]interface{}{
+			"configuration": map[string]interface{}{
+				"destinationPath": "s3://backups/",
+				"s3Credentials":   map[string]interface{}{"inheritFromIAMRole": true},
+			},
+		},
+	}}
+	objectStore.SetGroupVersionKind(ObjectStoreGVK)
+
+	inTree := testCluster("legacy", "db", nil, map[string]interface{}{
+		"backup": map[string]interface{}{
+			"barmanObjectStore": map[string]interface{}{
+				"destinationPath": "gs://legacy/",
+				"serverName":      "legacy-v2",
+			},
+		},
+	})
+
+	client := fake.NewClientBuilder().
+		WithScheme(runtime.NewScheme()).
+		WithObjects(objectStore, inTree).
+		Build()
+	discovery := NewDiscovery(client)
+	ctx := context.Background()
+
+	cfg, err := discovery.GetObjectStoreConfig(ctx, ClusterInfo{
+		Name:      "pg",
+		Namespace: "db",
+		Status: ClusterStatus{BarmanCloudPlugin: &BarmanCloudPluginInfo{
+			Enabled:              true,
+			ObjectStoreName:      "minio",
+			ObjectStoreNamespace: "db",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Source != "ObjectStore/minio" || cfg.ServerName != "pg" || !cfg.InheritedCredentials {
+		t.Errorf("unexpected plugin config: %+v", cfg)
+	}
+
+	cfg, err = discovery.GetObjectStoreConfig(ctx, ClusterInfo{
+		Name:      "legacy",
+		Namespace: "db",
+		Status:    ClusterStatus{BackupMethods: []string{BackupMethodBarmanObjectStore}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Source != "Cluster/legacy" || cfg.ServerName != "legacy-v2" || cfg.Provider != ProviderGoogle {
+		t.Errorf("unexpected in-tree config: %+v", cfg)
+	}
+
+	cfg, err = discovery.GetObjectStoreConfig(ctx, ClusterInfo{
+		Name:      "snapshots",
+		Namespace: "db",
+		Status:    ClusterStatus{BackupMethods: []string{BackupMethodVolumeSnapshot}},
+	})
+	if err != nil || cfg != nil {
+		t.Errorf("expected no config for volume snapshot clusters, got %+v, %v", cfg, err)
+	}
+
+	_, err = discovery.GetObjectStoreConfig(ctx, ClusterInfo{
+		Name:      "pg",
+		Namespace: "db",
+		Status: ClusterStatus{BarmanCloudPlugin: &BarmanCloudPluginInfo{
+			Enabled:              true,
+			ObjectStoreName:      "missing",
+			ObjectStoreNamespace: "db",
+		}},
+	})
+	if err == nil {
+		t.Error("expected error for missing ObjectStore")
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ObjectStoreEvent describes a change to the serverRecoveryWindow of an ObjectStore
+type ObjectStoreEvent struct {
+	Namespace string
+	Name      string
+	// Servers are the servers, named after their clusters, whose recovery window
+	// entry was added, changed or removed
+	Servers []string
+}
+
+// ObjectStoreSubscriber is called for every recovery window change. Subscribers are
+// called synchronously from the watch goroutine and must not block.
+type ObjectStoreSubscriber func(ObjectStoreEvent)
+
+// ObjectStoreCache keeps the serverRecoveryWindow of every barman-cloud ObjectStore
+// from a watch, so backup status is read without a GET per ObjectStore on every
+// reconcile and backup health changes can trigger reconciles
+type ObjectStoreCache struct {
+	mu      sync.RWMutex
+	windows map[string]map[string]interface{}
+
+	subMu       sync.RWMutex
+	subscribers map[int]ObjectStoreSubscriber
+	nextSubID   int
+
+	synced atomic.Bool
+}
+
+// NewObjectStoreCache creates an empty cache. It is populated by Start, or by Upsert
+// and Delete when driven externally.
+func NewObjectStoreCache() *ObjectStoreCache {
+	return &ObjectStoreCache{
+		windows:     make(map[string]map[string]interface{}),
+		subscribers: make(map[int]ObjectStoreSubscriber),
+	}
+}
+
+// Start registers a watch on ObjectStores with the given informer source, marks the
+// cache as synced once the initial list is loaded and blocks until the context is
+// cancelled. If the barman-cloud plugin CRDs are not installed the cache stays unsynced
+// and ObjectStores are read from the API server.
+func (c *ObjectStoreCache) Start(ctx context.Context, informers cache.Informers) error {
+	log := logf.FromContext(ctx).WithName("objectstore-cache")
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ObjectStoreGVK)
+	informer, err := informers.GetInformer(ctx, obj)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			log.Info("ObjectStore CRD not installed, ObjectStore cache disabled")
+			<-ctx.Done()
+			return nil
+		}
+		return fmt.Errorf("failed to get ObjectStore informer: %w", err)
+	}
+
+	registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(o interface{}) {
+			if u, ok := o.(*unstructured.Unstructured); ok {
+				c.Upsert(u)
+			}
+		},
+		UpdateFunc: func(_, o interface{}) {
+			if u, ok := o.(*unstructured.Unstructured); ok {
+				c.Upsert(u)
+			}
+		},
+		DeleteFunc: func(o interface{}) {
+			if tombstone, ok := o.(toolscache.DeletedFinalStateUnknown); ok {
+				o = tombstone.Obj
+			}
+			if u, ok := o.(*unstructured.Unstructured); ok {
+				c.Delete(u.GetNamespace(), u.GetName())
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register ObjectStore event handler: %w", err)
+	}
+
+	if !toolscache.WaitForCacheSync(ctx.Done(), registration.HasSynced) {
+		return nil
+	}
+	c.synced.Store(true)
+	log.Info("ObjectStore cache synced", "objectStores", c.Len())
+
+	<-ctx.Done()
+	return nil
+}
+
+// HasSynced reports whether the cache holds the complete set of ObjectStores
+func (c *ObjectStoreCache) HasSynced() bool {
+	return c != nil && c.synced.Load()
+}
+
+// Upsert adds or updates an ObjectStore from its unstructured representation
+func (c *ObjectStoreCache) Upsert(obj *unstructured.Unstructured) {
+	window, _, _ := unstructured.NestedMap(obj.Object, "status", "serverRecoveryWindow")
+	key := clusterKey(obj.GetNamespace(), obj.GetName())
+
+	c.mu.Lock()
+	previous := c.windows[key]
+	c.windows[key] = window
+	c.mu.Unlock()
+
+	c.notify(obj.GetNamespace(), obj.GetName(), changedServers(previous, window))
+}
+
+// Delete removes an ObjectStore from the cache
+func (c *ObjectStoreCache) Delete(namespace, name string) {
+	key := clusterKey(namespace, name)
+
+	c.mu.Lock()
+	previous, existed := c.windows[key]
+	delete(c.windows, key)
+	c.mu.Unlock()
+
+	if existed {
+		c.notify(namespace, name, changedServers(previous, nil))
+	}
+}
+
+// Len returns the number of ObjectStores in the cache
+func (c *ObjectStoreCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.windows)
+}
+
+// RecoveryWindow returns the serverRecoveryWindow of an ObjectStore, nil when it has
+// none yet, and whether the ObjectStore exists. The map must not be modified.
+func (c *ObjectStoreCache) RecoveryWindow(namespace, name string) (map[string]interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	window, ok := c.windows[clusterKey(namespace, name)]
+	return window, ok
+}
+
+// Subscribe registers a callback for recovery window changes and returns a function
+// that removes it
+func (c *ObjectStoreCache) Subscribe(fn ObjectStoreSubscriber) func() {
+	c.subMu.Lock()
+	id := c.nextSubID
+	c.nextSubID++
+	c.subscribers[id] = fn
+	c.subMu.Unlock()
+
+	return func() {
+		c.subMu.Lock()
+		delete(c.subscribers, id)
+		c.subMu.Unlock()
+	}
+}
+
+// notify delivers a change to all subscribers outside the cache lock. Nothing is
+// delivered when no server's recovery window changed
+func (c *ObjectStoreCache) notify(namespace, name string, servers []string) {
+	if len(servers) == 0 {
+		return
+	}
+
+	c.subMu.RLock()
+	subscribers := make([]ObjectStoreSubscriber, 0, len(c.subscribers))
+	for _, fn := range c.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	c.subMu.RUnlock()
+
+	event := ObjectStoreEvent{Namespace: namespace, Name: name, Servers: servers}
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
+
+// changedServers returns the sorted servers whose recovery window entry differs between
+// two serverRecoveryWindow maps
+func changedServers(previous, current map[string]interface{}) []string {
+	var servers []string
+	for server, window := range current {
+		if !equality.Semantic.DeepEqual(previous[server], window) {
+			servers = append(servers, server)
+		}
+	}
+	for server := range previous {
+		if _, ok := current[server]; !ok {
+			servers = append(servers, server)
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
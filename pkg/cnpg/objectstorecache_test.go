@@ -0,0 +1,223 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func testObjectStore(name, namespace string, window map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": ObjectStoreGroup + "/" + ObjectStoreVersion,
+			"kind":       ObjectStoreKind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+		},
+	}
+	if window != nil {
+		obj.Object["status"] = map[string]interface{}{"serverRecoveryWindow": window}
+	}
+	return obj
+}
+
+func recoveryWindow(lastBackup string) map[string]interface{} {
+	return map[string]interface{}{
+		"firstRecoverabilityPoint": "2025-01-01T00:00:00Z",
+		"lastSuccessfulBackupTime": lastBackup,
+	}
+}
+
+func TestObjectStoreCache_Events(t *testing.T) {
+	c := NewObjectStoreCache()
+	var events []ObjectStoreEvent
+	c.Subscribe(func(e ObjectStoreEvent) { events = append(events, e) })
+
+	tests := []struct {
+		name   string
+		apply  func()
+		expect []string
+	}{
+		{
+			name:   "created without status",
+			apply:  func() { c.Upsert(testObjectStore("store", "prod", nil)) },
+			expect: nil,
+		},
+		{
+			name: "first backups",
+			apply: func() {
+				c.Upsert(testObjectStore("store", "prod", map[string]interface{}{
+					"pg-a": recoveryWindow("2025-01-02T00:00:00Z"),
+					"pg-b": recoveryWindow("2025-01-02T00:00:00Z"),
+				}))
+			},
+			expect: []string{"pg-a", "pg-b"},
+		},
+		{
+			name: "unchanged resync",
+			apply: func() {
+				c.Upsert(testObjectStore("store", "prod", map[string]interface{}{
+					"pg-a": recoveryWindow("2025-01-02T00:00:00Z"),
+					"pg-b": recoveryWindow("2025-01-02T00:00:00Z"),
+				}))
+			},
+			expect: nil,
+		},
+		{
+			name: "one cluster backed up, one removed",
+			apply: func() {
+				c.Upsert(testObjectStore("store", "prod", map[string]interface{}{
+					"pg-a": recoveryWindow("2025-01-03T00:00:00Z"),
+				}))
+			},
+			expect: []string{"pg-a", "pg-b"},
+		},
+		{
+			name:   "deleted",
+			apply:  func() { c.Delete("prod", "store") },
+			expect: []string{"pg-a"},
+		},
+		{
+			name:   "deleting an unknown store",
+			apply:  func() { c.Delete("prod", "store") },
+			expect: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events = nil
+			tt.apply()
+			if tt.expect == nil {
+				if len(events) != 0 {
+					t.Fatalf("expected no event, got %+v", events)
+				}
+				return
+			}
+			if len(events) != 1 {
+				t.Fatalf("expected one event, got %+v", events)
+			}
+			e := events[0]
+			if e.Namespace != "prod" || e.Name != "store" || !slices.Equal(e.Servers, tt.expect) {
+				t.Errorf("expected prod/store %v, got %+v", tt.expect, e)
+			}
+		})
+	}
+}
+
+func TestObjectStoreCache_RecoveryWindow(t *testing.T) {
+	c := NewObjectStoreCache()
+	if c.HasSynced() {
+		t.Error("expected a new cache to be unsynced")
+	}
+	var nilCache *ObjectStoreCache
+	if nilCache.HasSynced() {
+		t.Error("expected a nil cache to be unsynced")
+	}
+
+	c.Upsert(testObjectStore("empty", "prod", nil))
+	c.Upsert(testObjectStore("store", "prod", map[string]interface{}{
+		"pg-a": recoveryWindow("2025-01-02T00:00:00Z"),
+	}))
+
+	if window, ok := c.RecoveryWindow("prod", "empty"); !ok || window != nil {
+		t.Errorf("expected an existing store without a window, got %v (ok=%v)", window, ok)
+	}
+	if window, ok := c.RecoveryWindow("prod", "store"); !ok || window["pg-a"] == nil {
+		t.Errorf("expected the pg-a window, got %v (ok=%v)", window, ok)
+	}
+	if _, ok := c.RecoveryWindow("staging", "store"); ok {
+		t.Error("expected no store in another namespace")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 stores, got %d", c.Len())
+	}
+}
+
+func TestDiscovery_BackupStatusFromObjectStoreCache(t *testing.T) {
+	var objectStoreGets int
+	c := fake.NewClientBuilder().
+		WithScheme(runtime.NewScheme()).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, cl client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if u, ok := obj.(*unstructured.Unstructured); ok && u.GroupVersionKind() == ObjectStoreGVK {
+					objectStoreGets++
+				}
+				return cl.Get(ctx, key, obj, opts...)
+			},
+		}).
+		Build()
+
+	cache := NewObjectStoreCache()
+	cache.Upsert(testObjectStore("store", "default", map[string]interface{}{
+		"pg-a": recoveryWindow("2025-01-02T00:00:00Z"),
+	}))
+	discovery := NewDiscovery(c).WithObjectStoreCache(cache)
+
+	pluginCluster := func(name, store string) ClusterInfo {
+		return ClusterInfo{
+			Name:      name,
+			Namespace: "default",
+			Status: ClusterStatus{
+				BarmanCloudPlugin: &BarmanCloudPluginInfo{
+					Enabled:              true,
+					ObjectStoreName:      store,
+					ObjectStoreNamespace: "default",
+				},
+			},
+		}
+	}
+	clusters := []ClusterInfo{pluginCluster("pg-a", "store"), pluginCluster("pg-b", "missing")}
+
+	// Until the cache has synced, ObjectStores are read from the API server
+	index := discovery.GetBackupStatusesForClusters(context.Background(), clusters)
+	if objectStoreGets != 2 {
+		t.Errorf("expected 2 ObjectStore GETs before sync, got %d", objectStoreGets)
+	}
+	if status, _ := index.ForCluster(clusters[0]); status != nil {
+		t.Errorf("expected no status from the empty API server, got %+v", status)
+	}
+
+	cache.synced.Store(true)
+	objectStoreGets = 0
+	index = discovery.GetBackupStatusesForClusters(context.Background(), clusters)
+	if objectStoreGets != 0 {
+		t.Errorf("expected no ObjectStore GETs once synced, got %d", objectStoreGets)
+	}
+	status, err := index.ForCluster(clusters[0])
+	if err != nil || status == nil || status.LastSuccessfulBackupTime == nil {
+		t.Errorf("expected pg-a status from the cache, got %+v (err=%v)", status, err)
+	}
+	if _, err := index.ForCluster(clusters[1]); err == nil {
+		t.Error("expected an error for a cluster referencing a missing ObjectStore")
+	}
+
+	status, err = discovery.GetObjectStoreBackupStatus(context.Background(), "store", "default", "pg-a")
+	if err != nil || status == nil || objectStoreGets != 0 {
+		t.Errorf("expected pg-a status from the cache, got %+v (err=%v, gets=%d)", status, err, objectStoreGets)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LabelPoolerName is the CNPG label holding the Pooler of a pooler pod
+const LabelPoolerName = "cnpg.io/poolerName"
+
+// PoolerGVK is the GroupVersionKind for CNPG Pooler
+var PoolerGVK = schema.GroupVersionKind{
+	Group:   CNPGGroup,
+	Version: "v1",
+	Kind:    "Pooler",
+}
+
+// PoolerInfo contains information about a CNPG Pooler
+type PoolerInfo struct {
+	Name      string
+	Namespace string
+	// Cluster is the name of the cluster the Pooler connects to
+	Cluster string
+	// Type is the kind of instances the Pooler connects to, rw, ro or r
+	Type      string
+	Instances int32
+	// PVCNames are the claims the Pooler's pod template mounts by name
+	PVCNames []string
+	// EphemeralVolumes are the generic ephemeral volumes of the pod template, which
+	// get a PVC per pod
+	EphemeralVolumes []string
+}
+
+// HasPersistentVolumes reports whether the Pooler's pods mount PVCs
+func (p PoolerInfo) HasPersistentVolumes() bool {
+	return len(p.PVCNames) > 0 || len(p.EphemeralVolumes) > 0
+}
+
+// ListPoolers lists the CNPG Poolers of a cluster, sorted by name. Nothing is returned
+// when the Pooler CRD is not installed
+func (d *Discovery) ListPoolers(ctx context.Context, namespace, clusterName string) ([]PoolerInfo, error) {
+	poolerList := &unstructured.UnstructuredList{}
+	poolerList.SetGroupVersionKind(PoolerGVK.GroupVersion().WithKind(PoolerGVK.Kind + "List"))
+
+	if err := d.client.List(ctx, poolerList, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list CNPG poolers: %w", err)
+	}
+
+	var poolers []PoolerInfo
+	for i := range poolerList.Items {
+		info := extractPoolerInfo(&poolerList.Items[i])
+		if info.Cluster == clusterName {
+			poolers = append(poolers, info)
+		}
+	}
+	sort.Slice(poolers, func(i, j int) bool { return poolers[i].Name < poolers[j].Name })
+	return poolers, nil
+}
+
+// extractPoolerInfo extracts Pooler information from an unstructured object
+func extractPoolerInfo(pooler *unstructured.Unstructured) PoolerInfo {
+	info := PoolerInfo{
+		Name:      pooler.GetName(),
+		Namespace: pooler.GetNamespace(),
+		Instances: 1,
+	}
+	info.Cluster, _, _ = unstructured.NestedString(pooler.Object, "spec", "cluster", "name")
+	info.Type, _, _ = unstructured.NestedString(pooler.Object, "spec", "type")
+	if instances, found, _ := unstructured.NestedInt64(pooler.Object, "spec", "instances"); found {
+		info.Instances = int32(instances)
+	}
+
+	volumes, _, _ := unstructured.NestedSlice(pooler.Object, "spec", "template", "spec", "volumes")
+	for _, volume := range volumes {
+		v, ok := volume.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if claimName, found, _ := unstructured.NestedString(v, "persistentVolumeClaim", "claimName"); found {
+			info.PVCNames = append(info.PVCNames, claimName)
+		} else if _, found, _ := unstructured.NestedMap(v, "ephemeral"); found {
+			name, _, _ := unstructured.NestedString(v, "name")
+			info.EphemeralVolumes = append(info.EphemeralVolumes, name)
+		}
+	}
+	return info
+}
+
+// GetPoolerPods gets the pods of a CNPG Pooler
+func (d *Discovery) GetPoolerPods(ctx context.Context, name, namespace string) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := d.client.List(ctx, podList,
+		client.InNamespace(namespace),
+		client.MatchingLabels{LabelPoolerName: name},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list pods for pooler %s/%s: %w", namespace, name, err)
+	}
+	return podList.Items, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cnpg
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// testPooler returns a Pooler of a cluster whose pod template has the given volumes
+func testPooler(name, cluster string, volumes ...interface{}) *unstructured.Unstructured {
+	pooler := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Pooler",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		"spec": map[string]interface{}{
+			"cluster":   map[string]interface{}{"name": cluster},
+			"type":      "rw",
+			"instances": int64(2),
+		},
+	}}
+	if len(volumes) > 0 {
+		_ = unstructured.SetNestedSlice(pooler.Object, volumes, "spec", "template", "spec", "volumes")
+	}
+	return pooler
+}
+
+func TestDiscovery_ListPoolers(t *testing.T) {
+	logs := map[string]interface{}{
+		"name":                  "logs",
+		"persistentVolumeClaim": map[string]interface{}{"claimName": "pooler-logs"},
+	}
+	scratch := map[string]interface{}{
+		"name":      "scratch",
+		"ephemeral": map[string]interface{}{"volumeClaimTemplate": map[string]interface{}{}},
+	}
+	config := map[string]interface{}{
+		"name":      "config",
+		"configMap": map[string]interface{}{"name": "pgbouncer-config"},
+	}
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(
+		testPooler("pooler-rw", "test-cluster", logs, scratch, config),
+		testPooler("pooler-ro", "test-cluster"),
+		testPooler("other-pooler", "other-cluster", logs),
+	).Build()
+
+	poolers, err := NewDiscovery(c).ListPoolers(context.Background(), "default", "test-cluster")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(poolers) != 2 || poolers[0].Name != "pooler-ro" || poolers[1].Name != "pooler-rw" {
+		t.Fatalf("expected the two poolers of test-cluster sorted by name, got %+v", poolers)
+	}
+
+	ro, rw := poolers[0], poolers[1]
+	if ro.HasPersistentVolumes() {
+		t.Errorf("expected a pooler without volumes, got %+v", ro)
+	}
+	if rw.Cluster != "test-cluster" || rw.Type != "rw" || rw.Instances != 2 {
+		t.Errorf("unexpected pooler info %+v", rw)
+	}
+	if !reflect.DeepEqual(rw.PVCNames, []string{"pooler-logs"}) ||
+		!reflect.DeepEqual(rw.EphemeralVolumes, []string{"scratch"}) || !rw.HasPersistentVolumes() {
+		t.Errorf("expected the claim and the ephemeral volume, got %+v", rw)
+	}
+}
+
+func TestDiscovery_GetPoolerPods(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		pod("pooler-rw-1", map[string]string{LabelPoolerName: "pooler-rw"}),
+		pod("pooler-ro-1", map[string]string{LabelPoolerName: "pooler-ro"}),
+		pod("test-cluster-1", map[string]string{"cnpg.io/cluster": "test-cluster"}),
+	).Build()
+
+	pods, err := NewDiscovery(c).GetPoolerPods(context.Background(), "pooler-rw", "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pods) != 1 || pods[0].Name != "pooler-rw-1" {
+		t.Errorf("expected the pod of pooler-rw, got %v", pods)
+	}
+}
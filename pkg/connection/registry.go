@@ -0,0 +1,199 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package connection keeps clients for CNPG clusters running in other Kubernetes
+// clusters, built from the kubeconfigs referenced by ClusterConnections.
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/identity"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+// Connection holds the clients used to reach one downstream Kubernetes cluster
+type Connection struct {
+	// Name is the ClusterConnection name
+	Name string
+	// Namespace is the ClusterConnection namespace
+	Namespace string
+	// Server is the API server address from the kubeconfig
+	Server string
+	// Identity names the downstream cluster in alerts and metrics
+	Identity identity.ClusterIdentity
+
+	Client     client.Client
+	RestConfig *rest.Config
+	Discovery  *cnpg.Discovery
+	Collector  *metrics.Collector
+
+	// SecretVersion is the resourceVersion of the kubeconfig Secret the clients were built from
+	SecretVersion string
+}
+
+// New builds the clients of a connection from a kubeconfig. Per-PVC Prometheus series
+// are not recorded for downstream clusters since their names may clash with local ones.
+// Only kubeconfigs with inline credentials are accepted, see loadKubeconfig.
+func New(name, namespace string, kubeconfig []byte, scheme *runtime.Scheme) (*Connection, error) {
+	restConfig, err := loadKubeconfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for %s: %w", restConfig.Host, err)
+	}
+
+	collector := metrics.NewCollector(c, restConfig)
+	collector.SetRecordPVCMetrics(false)
+
+	return &Connection{
+		Name:       name,
+		Namespace:  namespace,
+		Server:     restConfig.Host,
+		Client:     c,
+		RestConfig: restConfig,
+		Discovery:  cnpg.NewDiscovery(c),
+		Collector:  collector,
+	}, nil
+}
+
+// loadKubeconfig builds the client config of a kubeconfig taken from a ClusterConnection
+// Secret. The kubeconfig is written by whoever may create ClusterConnections, so it may
+// only carry inline credentials: references to files, such as the manager's own
+// ServiceAccount token, credential plugins and proxies are rejected, as they would act
+// with the manager's filesystem, processes or network on the kubeconfig author's behalf
+func loadKubeconfig(kubeconfig []byte) (*rest.Config, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	if err := validateInline(config); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	return restConfig, nil
+}
+
+// validateInline returns an error listing the users and clusters of a kubeconfig that
+// read files, run a credential plugin or use a proxy
+func validateInline(config *clientcmdapi.Config) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(config.AuthInfos)) {
+		user := config.AuthInfos[name]
+		for _, field := range []struct {
+			name string
+			set  bool
+		}{
+			{"exec", user.Exec != nil},
+			{"auth-provider", user.AuthProvider != nil},
+			{"tokenFile", user.TokenFile != ""},
+			{"client-certificate", user.ClientCertificate != ""},
+			{"client-key", user.ClientKey != ""},
+		} {
+			if field.set {
+				errs = append(errs, fmt.Errorf("user %q: %s is not allowed, only inline credentials are", name, field.name))
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.Clusters)) {
+		cluster := config.Clusters[name]
+		if cluster.CertificateAuthority != "" {
+			errs = append(errs, fmt.Errorf("cluster %q: certificate-authority is not allowed, "+
+				"use certificate-authority-data", name))
+		}
+		if cluster.ProxyURL != "" {
+			errs = append(errs, fmt.Errorf("cluster %q: proxy-url is not allowed", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Key returns the registry key of a ClusterConnection
+func Key(name, namespace string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// Registry holds the active connections, keyed by namespace/name. It is shared between
+// the ClusterConnection controller, which maintains it, and the policy controllers.
+type Registry struct {
+	mu          sync.RWMutex
+	connections map[string]*Connection
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{connections: make(map[string]*Connection)}
+}
+
+// Set adds or replaces a connection
+func (r *Registry) Set(conn *Connection) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.connections[Key(conn.Name, conn.Namespace)] = conn
+}
+
+// Remove drops a connection
+func (r *Registry) Remove(name, namespace string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.connections, Key(name, namespace))
+}
+
+// Get returns a connection, or nil when it is not registered
+func (r *Registry) Get(name, namespace string) *Connection {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.connections[Key(name, namespace)]
+}
+
+// List returns all connections sorted by key
+func (r *Registry) List() []*Connection {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conns := make([]*Connection, 0, len(r.connections))
+	for _, conn := range r.connections {
+		conns = append(conns, conn)
+	}
+	sort.Slice(conns, func(i, j int) bool {
+		return Key(conns[i].Name, conns[i].Namespace) < Key(conns[j].Name, conns[j].Namespace)
+	})
+	return conns
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: edge-1
+  cluster:
+    server: https://edge-1.example.com:6443
+    insecure-skip-tls-verify: true
+users:
+- name: manager
+  user:
+    token: abc123
+contexts:
+- name: edge-1
+  context:
+    cluster: edge-1
+    user: manager
+current-context: edge-1
+`
+
+func TestNew(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	tests := []struct {
+		name       string
+		kubeconfig string
+		wantErr    bool
+	}{
+		{name: "valid kubeconfig", kubeconfig: testKubeconfig},
+		{name: "empty kubeconfig", kubeconfig: "", wantErr: true},
+		{name: "malformed kubeconfig", kubeconfig: "clusters: [", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := New("edge-1", "fleet", []byte(tt.kubeconfig), scheme)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if conn.Server != "https://edge-1.example.com:6443" {
+				t.Errorf("expected server from kubeconfig, got %q", conn.Server)
+			}
+			if conn.Client == nil || conn.Discovery == nil || conn.Collector == nil {
+				t.Error("expected client, discovery and collector to be set")
+			}
+		})
+	}
+}
+
+func TestNew_RejectsNonInlineKubeconfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	tests := map[string]struct {
+		old, new string
+	}{
+		"tokenFile": {
+			"    token: abc123", "    tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token",
+		},
+		"exec": {
+			"    token: abc123", "    exec:\n      apiVersion: client.authentication.k8s.io/v1\n      command: /bin/sh",
+		},
+		"auth-provider": {
+			"    token: abc123", "    auth-provider:\n      name: oidc",
+		},
+		"client-certificate": {
+			"    token: abc123", "    client-certificate: /etc/ssl/manager.crt",
+		},
+		"client-key": {
+			"    token: abc123", "    client-key: /etc/ssl/manager.key",
+		},
+		"certificate-authority": {
+			"    insecure-skip-tls-verify: true", "    certificate-authority: /etc/ssl/ca.crt",
+		},
+		"proxy-url": {
+			"    insecure-skip-tls-verify: true", "    insecure-skip-tls-verify: true\n    proxy-url: http://proxy:3128",
+		},
+	}
+	for field, tt := range tests {
+		t.Run(field, func(t *testing.T) {
+			kubeconfig := strings.Replace(testKubeconfig, tt.old, tt.new, 1)
+			_, err := New("edge-1", "fleet", []byte(kubeconfig), scheme)
+			if err == nil || !strings.Contains(err.Error(), field+" is not allowed") {
+				t.Errorf("expected %s to be rejected, got %v", field, err)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Set(&Connection{Name: "edge-2", Namespace: "fleet"})
+	r.Set(&Connection{Name: "edge-1", Namespace: "fleet"})
+	r.Set(&Connection{Name: "edge-1", Namespace: "other"})
+
+	if conn := r.Get("edge-1", "fleet"); conn == nil || conn.Namespace != "fleet" {
+		t.Fatalf("expected fleet/edge-1, got %+v", conn)
+	}
+	if conn := r.Get("edge-3", "fleet"); conn != nil {
+		t.Errorf("expected no connection, got %+v", conn)
+	}
+
+	list := r.List()
+	want := []string{"fleet/edge-1", "fleet/edge-2", "other/edge-1"}
+	if len(list) != len(want) {
+		t.Fatalf("expected %d connections, got %d", len(want), len(list))
+	}
+	for i, conn := range list {
+		if got := Key(conn.Name, conn.Namespace); got != want[i] {
+			t.Errorf("connection %d: expected %s, got %s", i, want[i], got)
+		}
+	}
+
+	// Replacing keeps a single entry
+	r.Set(&Connection{Name: "edge-1", Namespace: "fleet", SecretVersion: "2"})
+	if conn := r.Get("edge-1", "fleet"); conn.SecretVersion != "2" {
+		t.Errorf("expected replaced connection, got version %q", conn.SecretVersion)
+	}
+
+	r.Remove("edge-1", "fleet")
+	if conn := r.Get("edge-1", "fleet"); conn != nil {
+		t.Error("expected connection to be removed")
+	}
+	if len(r.List()) != 2 {
+		t.Errorf("expected 2 connections after removal, got %d", len(r.List()))
+	}
+
+	var nilRegistry *Registry
+	if nilRegistry.Get("edge-1", "fleet") != nil || nilRegistry.List() != nil {
+		t.Error("expected nil registry to have no connections")
+	}
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dashboard serves a web UI of the clusters managed by StoragePolicies: their
+// usage, circuit breakers and recent StorageEvents, with buttons to pause and resume
+// policies and approve events. It is served by the metrics server, behind its authn/authz.
+package dashboard
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/remediation"
+)
+
+// Path is where the metrics server serves the dashboard. Everything below it is part of it
+const Path = "/dashboard/"
+
+// maxEvents bounds the number of recent StorageEvents shown
+const maxEvents = 50
+
+const (
+	actionPause  = "pause"
+	actionResume = "resume"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+var log = logf.Log.WithName("dashboard")
+
+// State is what the dashboard shows
+type State struct {
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Policies    []PolicyState `json:"policies"`
+	// Events are the most recent StorageEvents, newest first
+	Events []EventState `json:"events"`
+}
+
+// PolicyState is a StoragePolicy and the clusters it manages
+type PolicyState struct {
+	Namespace  string         `json:"namespace"`
+	Name       string         `json:"name"`
+	Paused     bool           `json:"paused"`
+	PauseUntil *time.Time     `json:"pauseUntil,omitempty"`
+	DryRun     bool           `json:"dryRun"`
+	Clusters   []ClusterState `json:"clusters"`
+}
+
+// ClusterState is the storage state of a managed cluster
+type ClusterState struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Connection is the ClusterConnection the cluster is reached through, empty for local clusters
+	Connection         string `json:"connection,omitempty"`
+	Status             string `json:"status,omitempty"`
+	UsagePercent       int32  `json:"usagePercent"`
+	UsedBytes          int64  `json:"usedBytes"`
+	CapacityBytes      int64  `json:"capacityBytes"`
+	HealthScore        *int32 `json:"healthScore,omitempty"`
+	CircuitBreakerOpen bool   `json:"circuitBreakerOpen"`
+}
+
+// EventState is a StorageEvent
+type EventState struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Cluster   string    `json:"cluster"`
+	Type      string    `json:"type"`
+	Phase     string    `json:"phase"`
+	Reason    string    `json:"reason,omitempty"`
+	Created   time.Time `json:"created"`
+	DryRun    bool      `json:"dryRun,omitempty"`
+	// AwaitingApproval is true for Pending events held until they are approved
+	AwaitingApproval bool `json:"awaitingApproval,omitempty"`
+}
+
+// Handler serves the dashboard page, its state as JSON and the pause, resume and
+// approve actions. Actions must be posted as JSON, which browsers do not allow
+// cross-site without a CORS preflight the dashboard never answers
+func Handler(c client.Client) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+Path+"{$}", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; "+
+			"style-src 'unsafe-inline'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		_, _ = w.Write(indexHTML)
+	})
+	mux.HandleFunc("GET "+Path+"api/state", func(w http.ResponseWriter, req *http.Request) {
+		state, err := Load(req.Context(), c, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(state)
+	})
+	mux.HandleFunc("POST "+Path+"api/policies/{namespace}/{name}/{action}", func(w http.ResponseWriter, req *http.Request) {
+		if !postedJSON(w, req) {
+			return
+		}
+		status, err := setPaused(req, c, req.PathValue("action"))
+		respond(w, req, status, err)
+	})
+	mux.HandleFunc("POST "+Path+"api/events/{namespace}/{name}/approve", func(w http.ResponseWriter, req *http.Request) {
+		if !postedJSON(w, req) {
+			return
+		}
+		status, err := approve(req, c)
+		respond(w, req, status, err)
+	})
+	return mux
+}
+
+// Load lists StoragePolicies and StorageEvents and builds the dashboard state from them
+func Load(ctx context.Context, c client.Reader, now time.Time) (State, error) {
+	var policies cnpgv1alpha1.StoragePolicyList
+	if err := c.List(ctx, &policies); err != nil {
+		return State{}, fmt.Errorf("failed to list StoragePolicies: %w", err)
+	}
+	var events cnpgv1alpha1.StorageEventList
+	if err := c.List(ctx, &events); err != nil {
+		return State{}, fmt.Errorf("failed to list StorageEvents: %w", err)
+	}
+	return Build(policies.Items, events.Items, now), nil
+}
+
+// Build assembles the dashboard state, policies sorted by namespace and name
+func Build(policies []cnpgv1alpha1.StoragePolicy, events []cnpgv1alpha1.StorageEvent, now time.Time) State {
+	state := State{
+		GeneratedAt: now.UTC(),
+		Policies:    make([]PolicyState, 0, len(policies)),
+		Events:      make([]EventState, 0, min(len(events), maxEvents)),
+	}
+
+	for _, policy := range policies {
+		ps := PolicyState{
+			Namespace: policy.Namespace,
+			Name:      policy.Name,
+			Paused:    policy.Spec.Paused,
+			DryRun:    policy.Spec.DryRun,
+			Clusters:  make([]ClusterState, 0, len(policy.Status.ManagedClusters)),
+		}
+		if policy.Spec.PauseUntil != nil {
+			until := policy.Spec.PauseUntil.UTC()
+			ps.PauseUntil = &until
+			ps.Paused = until.After(now)
+		}
+		if policy.Spec.DryRunUntil != nil {
+			ps.DryRun = policy.Spec.DryRunUntil.After(now)
+		}
+		for _, mc := range policy.Status.ManagedClusters {
+			ps.Clusters = append(ps.Clusters, ClusterState{
+				Namespace:     mc.Namespace,
+				Name:          mc.Name,
+				Connection:    mc.Connection,
+				Status:        mc.Status,
+				UsagePercent:  mc.UsagePercent,
+				UsedBytes:     mc.UsedBytes,
+				CapacityBytes: mc.CapacityBytes,
+				HealthScore:   mc.HealthScore,
+				CircuitBreakerOpen: meta.IsStatusConditionTrue(mc.Conditions,
+					cnpgv1alpha1.ManagedClusterConditionCircuitBreakerOpen),
+			})
+		}
+		state.Policies = append(state.Policies, ps)
+	}
+	slices.SortFunc(state.Policies, func(a, b PolicyState) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	events = slices.Clone(events)
+	slices.SortFunc(events, func(a, b cnpgv1alpha1.StorageEvent) int {
+		return b.CreationTimestamp.Compare(a.CreationTimestamp.Time)
+	})
+	for _, event := range events[:min(len(events), maxEvents)] {
+		state.Events = append(state.Events, EventState{
+			Namespace: event.Namespace,
+			Name:      event.Name,
+			Cluster:   event.Spec.ClusterRef.Namespace + "/" + event.Spec.ClusterRef.Name,
+			Type:      string(event.Spec.EventType),
+			Phase:     string(event.Status.Phase),
+			Reason:    event.Spec.Reason,
+			Created:   event.CreationTimestamp.UTC(),
+			DryRun:    event.Spec.DryRun,
+			AwaitingApproval: event.Status.Phase == cnpgv1alpha1.EventPhasePending &&
+				!remediation.IsEventApproved(&event),
+		})
+	}
+	return state
+}
+
+// setPaused pauses or resumes a StoragePolicy. Both clear pauseUntil, so a pause lasts
+// until it is resumed and a resume takes effect immediately
+func setPaused(req *http.Request, c client.Client, action string) (int, error) {
+	var paused bool
+	switch action {
+	case actionPause:
+		paused = true
+	case actionResume:
+	default:
+		return http.StatusNotFound, fmt.Errorf("unknown action %q, expected %s or %s", action, actionPause, actionResume)
+	}
+
+	policy := &cnpgv1alpha1.StoragePolicy{}
+	policy.Namespace = req.PathValue("namespace")
+	policy.Name = req.PathValue("name")
+	patch := fmt.Appendf(nil, `{"spec":{"paused":%t,"pauseUntil":null}}`, paused)
+	if err := c.Patch(req.Context(), policy, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return statusOf(err), fmt.Errorf("failed to %s StoragePolicy %s/%s: %w", action, policy.Namespace, policy.Name, err)
+	}
+	return http.StatusOK, nil
+}
+
+// approve approves a Pending StorageEvent that is awaiting approval
+func approve(req *http.Request, c client.Client) (int, error) {
+	ctx := req.Context()
+	key := types.NamespacedName{Namespace: req.PathValue("namespace"), Name: req.PathValue("name")}
+	var event cnpgv1alpha1.StorageEvent
+	if err := c.Get(ctx, key, &event); err != nil {
+		return statusOf(err), fmt.Errorf("failed to get StorageEvent %s: %w", key, err)
+	}
+	if event.Status.Phase != cnpgv1alpha1.EventPhasePending || remediation.IsEventApproved(&event) {
+		return http.StatusConflict, fmt.Errorf("StorageEvent %s is not awaiting approval", key)
+	}
+
+	original := event.DeepCopy()
+	event.Spec.Approved = true
+	if err := c.Patch(ctx, &event, client.MergeFrom(original)); err != nil {
+		return statusOf(err), fmt.Errorf("failed to approve StorageEvent %s: %w", key, err)
+	}
+	return http.StatusOK, nil
+}
+
+// postedJSON rejects actions that were not posted as JSON
+func postedJSON(w http.ResponseWriter, req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		http.Error(w, "actions must be posted as application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+	return true
+}
+
+// respond logs an action and writes its outcome
+func respond(w http.ResponseWriter, req *http.Request, status int, err error) {
+	if err != nil {
+		log.Error(err, "Dashboard action failed", "path", req.URL.Path, "remoteAddr", req.RemoteAddr)
+		http.Error(w, err.Error(), status)
+		return
+	}
+	log.Info("Dashboard action", "path", req.URL.Path, "remoteAddr", req.RemoteAddr)
+	w.WriteHeader(status)
+}
+
+// statusOf maps an API error to the status of the response
+func statusOf(err error) int {
+	switch {
+	case apierrors.IsNotFound(err):
+		return http.StatusNotFound
+	case apierrors.IsConflict(err):
+		return http.StatusConflict
+	case apierrors.IsForbidden(err):
+		return http.StatusForbidden
+	case apierrors.IsInvalid(err):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
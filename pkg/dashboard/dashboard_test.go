@@ -0,0 +1,194 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+var now = time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+
+func newClient(t *testing.T) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := cnpgv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	pauseUntil := metav1.NewTime(now.Add(-time.Hour))
+	objects := []client.Object{
+		&cnpgv1alpha1.StoragePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "storage", Namespace: "db"},
+			Spec:       cnpgv1alpha1.StoragePolicySpec{Paused: true, PauseUntil: &pauseUntil},
+			Status: cnpgv1alpha1.StoragePolicyStatus{ManagedClusters: []cnpgv1alpha1.ManagedCluster{{
+				Name: "pg-a", Namespace: "db", Status: "Critical", UsagePercent: 91,
+				Conditions: []metav1.Condition{{
+					Type:   cnpgv1alpha1.ManagedClusterConditionCircuitBreakerOpen,
+					Status: metav1.ConditionTrue,
+				}},
+			}}},
+		},
+		&cnpgv1alpha1.StorageEvent{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg-a-expansion", Namespace: "db",
+				CreationTimestamp: metav1.NewTime(now.Add(-time.Minute))},
+			Spec: cnpgv1alpha1.StorageEventSpec{
+				ClusterRef:       cnpgv1alpha1.ClusterReference{Name: "pg-a", Namespace: "db"},
+				EventType:        cnpgv1alpha1.EventTypeExpansion,
+				ApprovalRequired: true,
+			},
+			Status: cnpgv1alpha1.StorageEventStatus{Phase: cnpgv1alpha1.EventPhasePending},
+		},
+		&cnpgv1alpha1.StorageEvent{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg-a-cleanup", Namespace: "db",
+				CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+			Spec: cnpgv1alpha1.StorageEventSpec{
+				ClusterRef: cnpgv1alpha1.ClusterReference{Name: "pg-a", Namespace: "db"},
+				EventType:  cnpgv1alpha1.EventTypeWALCleanup,
+			},
+			Status: cnpgv1alpha1.StorageEventStatus{Phase: cnpgv1alpha1.EventPhaseCompleted},
+		},
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+		WithStatusSubresource(&cnpgv1alpha1.StoragePolicy{}, &cnpgv1alpha1.StorageEvent{}).Build()
+}
+
+func serve(handler http.Handler, method, path, contentType string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLoad(t *testing.T) {
+	state, err := Load(context.Background(), newClient(t), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(state.Policies) != 1 || len(state.Policies[0].Clusters) != 1 {
+		t.Fatalf("expected 1 policy with 1 cluster, got %+v", state.Policies)
+	}
+	policy := state.Policies[0]
+	if policy.Paused {
+		t.Error("expected a pause that expired to be reported as not paused")
+	}
+	if !policy.Clusters[0].CircuitBreakerOpen {
+		t.Error("expected the circuit breaker to be reported open")
+	}
+
+	if len(state.Events) != 2 || state.Events[0].Name != "pg-a-expansion" {
+		t.Fatalf("expected the events newest first, got %+v", state.Events)
+	}
+	if !state.Events[0].AwaitingApproval || state.Events[1].AwaitingApproval {
+		t.Errorf("expected only the pending expansion to await approval, got %+v", state.Events)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	c := newClient(t)
+	handler := Handler(c)
+
+	if rec := serve(handler, http.MethodGet, Path, ""); rec.Code != http.StatusOK ||
+		!strings.Contains(rec.Body.String(), "<title>CNPG Storage Manager</title>") {
+		t.Errorf("expected the page, got %d", rec.Code)
+	}
+	rec := serve(handler, http.MethodGet, Path+"api/state", "")
+	var state State
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &state) != nil || len(state.Policies) != 1 {
+		t.Errorf("expected the state as JSON, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(handler, http.MethodGet, Path+"api/policies/db/storage/pause", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected actions to require POST, got %d", rec.Code)
+	}
+}
+
+func TestHandler_Pause(t *testing.T) {
+	c := newClient(t)
+	handler := Handler(c)
+	ctx := context.Background()
+
+	if rec := serve(handler, http.MethodPost, Path+"api/policies/db/storage/pause", "text/plain"); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected actions not posted as JSON to be rejected, got %d", rec.Code)
+	}
+	if rec := serve(handler, http.MethodPost, Path+"api/policies/db/storage/stop", "application/json"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown action to be rejected, got %d", rec.Code)
+	}
+	if rec := serve(handler, http.MethodPost, Path+"api/policies/db/missing/pause", "application/json"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected a missing policy to be reported, got %d", rec.Code)
+	}
+
+	var policy cnpgv1alpha1.StoragePolicy
+	key := types.NamespacedName{Namespace: "db", Name: "storage"}
+	if rec := serve(handler, http.MethodPost, Path+"api/policies/db/storage/pause", "application/json"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the policy to be paused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := c.Get(ctx, key, &policy); err != nil {
+		t.Fatal(err)
+	}
+	if !policy.Spec.Paused || policy.Spec.PauseUntil != nil {
+		t.Errorf("expected an indefinite pause, got paused=%v pauseUntil=%v", policy.Spec.Paused, policy.Spec.PauseUntil)
+	}
+
+	if rec := serve(handler, http.MethodPost, Path+"api/policies/db/storage/resume", "application/json"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the policy to be resumed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := c.Get(ctx, key, &policy); err != nil {
+		t.Fatal(err)
+	}
+	if policy.Spec.Paused {
+		t.Error("expected the policy to be resumed")
+	}
+}
+
+func TestHandler_Approve(t *testing.T) {
+	c := newClient(t)
+	handler := Handler(c)
+
+	if rec := serve(handler, http.MethodPost, Path+"api/events/db/pg-a-cleanup/approve", "application/json"); rec.Code != http.StatusConflict {
+		t.Errorf("expected an event not awaiting approval to be rejected, got %d", rec.Code)
+	}
+	if rec := serve(handler, http.MethodPost, Path+"api/events/db/pg-a-expansion/approve", "application/json; charset=utf-8"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the event to be approved, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var event cnpgv1alpha1.StorageEvent
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "db", Name: "pg-a-expansion"}, &event); err != nil {
+		t.Fatal(err)
+	}
+	if !event.Spec.Approved {
+		t.Error("expected the event to be approved")
+	}
+	if rec := serve(handler, http.MethodPost, Path+"api/events/db/pg-a-expansion/approve", "application/json"); rec.Code != http.StatusConflict {
+		t.Errorf("expected a second approval to be rejected, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package faults injects failures into PVC updates, kubelet stats requests and pod exec
+// commands, so circuit breaker and cooldown behavior can be exercised in staging. It is
+// only active when --inject-faults is set.
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+)
+
+// Names of the injectable faults, as used in the --inject-faults spec and passed to OnFault
+const (
+	// FaultPVCUpdate fails PVC patches and updates with a percentage, e.g. pvc-update=20
+	FaultPVCUpdate = "pvc-update"
+	// FaultKubeletStatsDelay delays kubelet stats summary requests, e.g. kubelet-stats-delay=5s
+	FaultKubeletStatsDelay = "kubelet-stats-delay"
+	// FaultExec fails pod exec commands with a percentage, e.g. exec=10
+	FaultExec = "exec"
+)
+
+// ErrInjected is returned by injected exec failures
+var ErrInjected = errors.New("injected fault")
+
+// Config selects the faults to inject. The zero value injects none
+type Config struct {
+	// PVCUpdatePercent is the percentage of PVC patches and updates that fail
+	PVCUpdatePercent float64
+	// KubeletStatsDelay is added to every kubelet stats summary request
+	KubeletStatsDelay time.Duration
+	// ExecPercent is the percentage of pod exec commands that fail
+	ExecPercent float64
+	// OnFault is called with the name of each fault injected
+	OnFault func(fault string)
+
+	// rand returns a number in [0, 100). Defaults to math/rand
+	rand func() float64
+}
+
+// Parse reads a comma separated list of faults, e.g.
+// "pvc-update=20,kubelet-stats-delay=5s,exec=10". An empty spec injects none
+func Parse(spec string) (Config, error) {
+	var cfg Config
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("fault %q has no value", entry)
+		}
+		var err error
+		switch name {
+		case FaultPVCUpdate:
+			cfg.PVCUpdatePercent, err = parsePercent(value)
+		case FaultExec:
+			cfg.ExecPercent, err = parsePercent(value)
+		case FaultKubeletStatsDelay:
+			cfg.KubeletStatsDelay, err = time.ParseDuration(value)
+			if err == nil && cfg.KubeletStatsDelay < 0 {
+				err = fmt.Errorf("delay must not be negative")
+			}
+		default:
+			return Config{}, fmt.Errorf("unknown fault %q", name)
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid value for fault %s: %w", name, err)
+		}
+	}
+	return cfg, nil
+}
+
+func parsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, err
+	}
+	if percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("percentage must be between 0 and 100")
+	}
+	return percent, nil
+}
+
+// Enabled reports whether any fault is injected
+func (c Config) Enabled() bool {
+	return c.PVCUpdatePercent > 0 || c.KubeletStatsDelay > 0 || c.ExecPercent > 0
+}
+
+// String lists the injected faults in the --inject-faults format
+func (c Config) String() string {
+	var faults []string
+	if c.PVCUpdatePercent > 0 {
+		faults = append(faults, fmt.Sprintf("%s=%g", FaultPVCUpdate, c.PVCUpdatePercent))
+	}
+	if c.KubeletStatsDelay > 0 {
+		faults = append(faults, fmt.Sprintf("%s=%s", FaultKubeletStatsDelay, c.KubeletStatsDelay))
+	}
+	if c.ExecPercent > 0 {
+		faults = append(faults, fmt.Sprintf("%s=%g", FaultExec, c.ExecPercent))
+	}
+	return strings.Join(faults, ",")
+}
+
+// inject reports whether a fault with the given percentage fires, and records it
+func (c Config) inject(fault string, percent float64) bool {
+	if percent <= 0 {
+		return false
+	}
+	random := c.rand
+	if random == nil {
+		random = func() float64 { return rand.Float64() * 100 }
+	}
+	if random() >= percent {
+		return false
+	}
+	c.record(fault)
+	return true
+}
+
+func (c Config) record(fault string) {
+	if c.OnFault != nil {
+		c.OnFault(fault)
+	}
+}
+
+// WrapClient returns a client whose PVC patches and updates fail with
+// PVCUpdatePercent, or cl itself when no PVC faults are configured
+func (c Config) WrapClient(cl client.Client) client.Client {
+	if c.PVCUpdatePercent <= 0 || cl == nil {
+		return cl
+	}
+	return &faultyClient{Client: cl, cfg: c}
+}
+
+type faultyClient struct {
+	client.Client
+	cfg Config
+}
+
+func (f *faultyClient) Patch(
+	ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption,
+) error {
+	if err := f.cfg.pvcUpdateFault(obj); err != nil {
+		return err
+	}
+	return f.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (f *faultyClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := f.cfg.pvcUpdateFault(obj); err != nil {
+		return err
+	}
+	return f.Client.Update(ctx, obj, opts...)
+}
+
+// pvcUpdateFault returns the error of an injected PVC update failure, as the API
+// server would report an unavailable backend
+func (c Config) pvcUpdateFault(obj client.Object) error {
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok || !c.inject(FaultPVCUpdate, c.PVCUpdatePercent) {
+		return nil
+	}
+	return apierrors.NewServiceUnavailable(fmt.Sprintf("%s: update of PVC %s/%s", ErrInjected, pvc.Namespace, pvc.Name))
+}
+
+// WrapConfig returns a copy of restConfig whose kubelet stats summary requests are
+// delayed by KubeletStatsDelay, or restConfig itself when no delay is configured
+func (c Config) WrapConfig(restConfig *rest.Config) *rest.Config {
+	if c.KubeletStatsDelay <= 0 || restConfig == nil {
+		return restConfig
+	}
+	wrapped := rest.CopyConfig(restConfig)
+	wrapped.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &delayTransport{next: rt, cfg: c}
+	})
+	return wrapped
+}
+
+// delayTransport delays requests to the kubelet stats summary endpoint
+type delayTransport struct {
+	next http.RoundTripper
+	cfg  Config
+}
+
+func (t *delayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/proxy/stats/summary") {
+		t.cfg.record(FaultKubeletStatsDelay)
+		timer := time.NewTimer(t.cfg.KubeletStatsDelay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// WrapRunner returns a command runner whose commands fail with ExecPercent, or r
+// itself when no exec faults are configured
+func (c Config) WrapRunner(r runner.CommandRunner) runner.CommandRunner {
+	if c.ExecPercent <= 0 || r == nil {
+		return r
+	}
+	return &faultyRunner{next: r, cfg: c}
+}
+
+type faultyRunner struct {
+	next runner.CommandRunner
+	cfg  Config
+}
+
+func (f *faultyRunner) Run(ctx context.Context, pod *corev1.Pod, container string, command []string) (string, error) {
+	if f.cfg.inject(FaultExec, f.cfg.ExecPercent) {
+		return "", fmt.Errorf("%w: exec in pod %s/%s", ErrInjected, pod.Namespace, pod.Name)
+	}
+	return f.next.Run(ctx, pod, container, command)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faults
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Config
+		wantErr bool
+	}{
+		{name: "empty", spec: ""},
+		{
+			name: "all faults",
+			spec: "pvc-update=20, kubelet-stats-delay=5s,exec=10%",
+			want: Config{PVCUpdatePercent: 20, KubeletStatsDelay: 5 * time.Second, ExecPercent: 10},
+		},
+		{name: "unknown fault", spec: "dns=10", wantErr: true},
+		{name: "missing value", spec: "exec", wantErr: true},
+		{name: "percentage above 100", spec: "pvc-update=150", wantErr: true},
+		{name: "negative delay", spec: "kubelet-stats-delay=-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if err == nil && (got.PVCUpdatePercent != tt.want.PVCUpdatePercent ||
+				got.KubeletStatsDelay != tt.want.KubeletStatsDelay || got.ExecPercent != tt.want.ExecPercent) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+
+	cfg, _ := Parse("exec=10,pvc-update=20,kubelet-stats-delay=5s")
+	if got := cfg.String(); got != "pvc-update=20,kubelet-stats-delay=5s,exec=10" {
+		t.Errorf("expected the spec to round-trip, got %q", got)
+	}
+	if (Config{}).Enabled() {
+		t.Errorf("expected the zero config to inject no faults")
+	}
+}
+
+func TestWrapClient(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Namespace: "db"}}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "db"}}
+	base := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pvc, cm).Build()
+
+	if (Config{}).WrapClient(base) != base {
+		t.Errorf("expected the client to be returned as is without PVC faults")
+	}
+
+	var injected []string
+	cfg := Config{PVCUpdatePercent: 50, OnFault: func(fault string) { injected = append(injected, fault) }}
+	cfg.rand = func() float64 { return 10 }
+	c := cfg.WrapClient(base)
+	ctx := context.Background()
+
+	patch := client.RawPatch(types.MergePatchType, []byte(`{"metadata":{"labels":{"a":"b"}}}`))
+	err := c.Patch(ctx, pvc.DeepCopy(), patch)
+	if !apierrors.IsServiceUnavailable(err) {
+		t.Errorf("expected an injected ServiceUnavailable error, got %v", err)
+	}
+	if err := c.Update(ctx, pvc.DeepCopy()); !apierrors.IsServiceUnavailable(err) {
+		t.Errorf("expected the update to fail too, got %v", err)
+	}
+	if err := c.Patch(ctx, cm.DeepCopy(), patch); err != nil {
+		t.Errorf("expected other objects to be patched, got %v", err)
+	}
+	if len(injected) != 2 || injected[0] != FaultPVCUpdate {
+		t.Errorf("expected 2 pvc-update faults to be recorded, got %v", injected)
+	}
+
+	cfg.rand = func() float64 { return 60 }
+	if err := cfg.WrapClient(base).Patch(ctx, pvc.DeepCopy(), patch); err != nil {
+		t.Errorf("expected the patch to pass above the percentage, got %v", err)
+	}
+}
+
+type fakeRunner struct{ calls int }
+
+func (f *fakeRunner) Run(context.Context, *corev1.Pod, string, []string) (string, error) {
+	f.calls++
+	return "ok", nil
+}
+
+func TestWrapRunner(t *testing.T) {
+	next := &fakeRunner{}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Namespace: "db"}}
+
+	cfg := Config{ExecPercent: 100}
+	r := cfg.WrapRunner(next)
+	if _, err := r.Run(context.Background(), pod, "postgres", []string{"df"}); !errors.Is(err, ErrInjected) {
+		t.Errorf("expected an injected error, got %v", err)
+	}
+	if next.calls != 0 {
+		t.Errorf("expected the command not to run, ran %d times", next.calls)
+	}
+
+	cfg.rand = func() float64 { return 99.5 }
+	cfg.ExecPercent = 99
+	out, err := cfg.WrapRunner(next).Run(context.Background(), pod, "postgres", []string{"df"})
+	if err != nil || out != "ok" {
+		t.Errorf("expected the command to run, got %q, %v", out, err)
+	}
+}
+
+func TestWrapConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	restConfig := &rest.Config{Host: server.URL}
+	if (Config{}).WrapConfig(restConfig) != restConfig {
+		t.Errorf("expected the config to be returned as is without a delay")
+	}
+
+	delayed := 0
+	cfg := Config{KubeletStatsDelay: 50 * time.Millisecond, OnFault: func(string) { delayed++ }}
+	transport, err := rest.TransportFor(cfg.WrapConfig(restConfig))
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+	httpClient := &http.Client{Transport: transport}
+
+	for _, path := range []string{"/api/v1/nodes/node-a/proxy/stats/summary", "/api/v1/namespaces"} {
+		start := time.Now()
+		resp, err := httpClient.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("request to %s failed: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		if slow := time.Since(start) >= cfg.KubeletStatsDelay; slow != (path != "/api/v1/namespaces") {
+			t.Errorf("unexpected delay for %s: %v", path, time.Since(start))
+		}
+	}
+	if delayed != 1 {
+		t.Errorf("expected one delayed request, got %d", delayed)
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hooks calls the webhooks StoragePolicies configure around expansions and WAL
+// cleanups, so change-management and CMDB systems can approve and record remediation.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+const (
+	// HookPreAction is the name of the hook called before a remediation starts
+	HookPreAction = "preAction"
+	// HookPostAction is the name of the hook called once a remediation has finished
+	HookPostAction = "postAction"
+
+	// SignatureHeader carries the HMAC-SHA256 signature of the request body
+	SignatureHeader = "X-Storage-Manager-Signature"
+	// HookHeader carries the name of the hook
+	HookHeader = "X-Storage-Manager-Hook"
+	// EventHeader carries the StorageEvent name, so receivers can deduplicate calls
+	EventHeader = "X-Storage-Manager-Event"
+
+	// DefaultSigningKey is the Secret key read when signingSecretRef.key is empty
+	DefaultSigningKey = "signing-key"
+	// DefaultTimeout bounds a call when timeoutSeconds is unset
+	DefaultTimeout = 10 * time.Second
+
+	// maxResponseBody bounds how much of a response is kept for the veto message
+	maxResponseBody = 1024
+)
+
+// ObjectReference names a namespaced object in a payload
+type ObjectReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// EventContext is the StorageEvent a hook is called for
+type EventContext struct {
+	ObjectReference `json:",inline"`
+	Type            cnpgv1alpha1.EventType           `json:"type"`
+	Spec            cnpgv1alpha1.StorageEventSpec    `json:"spec"`
+	Status          *cnpgv1alpha1.StorageEventStatus `json:"status,omitempty"`
+}
+
+// Payload is the JSON body of a hook call
+type Payload struct {
+	Hook      string          `json:"hook"`
+	Timestamp time.Time       `json:"timestamp"`
+	Policy    ObjectReference `json:"policy"`
+	Cluster   ObjectReference `json:"cluster"`
+	Event     EventContext    `json:"event"`
+}
+
+// NewPayload returns the payload of a hook call for a StorageEvent. The event status
+// is only sent to postAction hooks
+func NewPayload(hook string, event *cnpgv1alpha1.StorageEvent, now time.Time) Payload {
+	payload := Payload{
+		Hook:      hook,
+		Timestamp: now.UTC(),
+		Policy:    ObjectReference{Name: event.Spec.PolicyRef.Name, Namespace: event.Spec.PolicyRef.Namespace},
+		Cluster:   ObjectReference{Name: event.Spec.ClusterRef.Name, Namespace: event.Spec.ClusterRef.Namespace},
+		Event: EventContext{
+			ObjectReference: ObjectReference{Name: event.Name, Namespace: event.Namespace},
+			Type:            event.Spec.EventType,
+			Spec:            event.Spec,
+		},
+	}
+	if hook == HookPostAction {
+		payload.Event.Status = event.Status.DeepCopy()
+	}
+	return payload
+}
+
+// RejectedError is returned when a webhook answers with a non-2xx status
+type RejectedError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *RejectedError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("webhook returned HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("webhook returned HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// IsRejected reports whether err is a non-2xx webhook response
+func IsRejected(err error) bool {
+	var rejected *RejectedError
+	return errors.As(err, &rejected)
+}
+
+// SecretSource returns the data of a Secret. *alerting.SecretCache implements it
+type SecretSource interface {
+	Get(ctx context.Context, key types.NamespacedName) (map[string][]byte, error)
+}
+
+// readerSecrets reads Secrets through a client.Reader
+type readerSecrets struct {
+	reader client.Reader
+}
+
+func (s readerSecrets) Get(ctx context.Context, key types.NamespacedName) (map[string][]byte, error) {
+	var secret corev1.Secret
+	if err := s.reader.Get(ctx, key, &secret); err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
+// Caller calls remediation hooks
+type Caller struct {
+	httpClient *http.Client
+	secrets    SecretSource
+}
+
+// NewCaller creates a hook caller reading signing keys from secrets
+func NewCaller(secrets SecretSource) *Caller {
+	return &Caller{httpClient: &http.Client{}, secrets: secrets}
+}
+
+// NewCallerFromReader creates a hook caller reading signing keys through reader
+func NewCallerFromReader(reader client.Reader) *Caller {
+	return NewCaller(readerSecrets{reader: reader})
+}
+
+// Call POSTs the payload to the hook. namespace is the StoragePolicy's namespace, where
+// the signing Secret is read from. A non-2xx response returns a *RejectedError; any
+// other error means the webhook could not be called
+func (c *Caller) Call(ctx context.Context, hook *cnpgv1alpha1.WebhookHook, namespace string, payload Payload) (err error) {
+	ctx, span := tracing.Start(ctx, "hooks.Call",
+		tracing.Cluster(payload.Cluster.Name, payload.Cluster.Namespace)...)
+	defer func() { tracing.End(span, err) }()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook payload: %w", err)
+	}
+
+	timeout := DefaultTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create hook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HookHeader, payload.Hook)
+	req.Header.Set(EventHeader, payload.Event.Name)
+	if hook.SigningSecretRef != nil {
+		key, err := c.signingKey(ctx, hook.SigningSecretRef, namespace)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(SignatureHeader, Sign(key, body))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s hook: %w", payload.Hook, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		return &RejectedError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(respBody))}
+	}
+	return nil
+}
+
+// signingKey reads the signing key of a hook from its Secret
+func (c *Caller) signingKey(ctx context.Context, ref *cnpgv1alpha1.HookSecretReference, namespace string) ([]byte, error) {
+	secretRef := types.NamespacedName{Name: ref.Name, Namespace: namespace}
+	key := ref.Key
+	if key == "" {
+		key = DefaultSigningKey
+	}
+	data, err := c.secrets.Get(ctx, secretRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hook signing secret %s: %w", secretRef, err)
+	}
+	value, ok := data[key]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("key %s not found in hook signing secret %s", key, secretRef)
+	}
+	return value, nil
+}
+
+// Sign returns the signature header value of body: sha256= followed by the hex
+// HMAC-SHA256 of body with key
+func Sign(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func testEvent() *cnpgv1alpha1.StorageEvent {
+	return &cnpgv1alpha1.StorageEvent{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg-expansion-abc", Namespace: "db"},
+		Spec: cnpgv1alpha1.StorageEventSpec{
+			ClusterRef: cnpgv1alpha1.ClusterReference{Name: "pg", Namespace: "db"},
+			PolicyRef:  cnpgv1alpha1.PolicyReference{Name: "policy", Namespace: "ops"},
+			EventType:  cnpgv1alpha1.EventTypeExpansion,
+			Reason:     "threshold breach: 85.0%",
+		},
+		Status: cnpgv1alpha1.StorageEventStatus{Phase: cnpgv1alpha1.EventPhaseCompleted},
+	}
+}
+
+func testCaller(t *testing.T, objects ...runtime.Object) *Caller {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	return NewCallerFromReader(fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build())
+}
+
+func TestCall(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook-key", Namespace: "ops"},
+		Data:       map[string][]byte{DefaultSigningKey: []byte("s3cret")},
+	}
+
+	var body []byte
+	var header http.Header
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("change window closed\n"))
+	}))
+	defer server.Close()
+
+	caller := testCaller(t, secret)
+	hook := &cnpgv1alpha1.WebhookHook{
+		URL:              server.URL,
+		SigningSecretRef: &cnpgv1alpha1.HookSecretReference{Name: "hook-key"},
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("signs the payload", func(t *testing.T) {
+		payload := NewPayload(HookPreAction, testEvent(), now)
+		if err := caller.Call(context.Background(), hook, "ops", payload); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, want := header.Get(SignatureHeader), Sign([]byte("s3cret"), body); got != want {
+			t.Errorf("expected signature %s, got %s", want, got)
+		}
+		if header.Get(HookHeader) != HookPreAction || header.Get(EventHeader) != "pg-expansion-abc" {
+			t.Errorf("unexpected hook headers: %v", header)
+		}
+
+		var received Payload
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Fatalf("invalid payload: %v", err)
+		}
+		if received.Cluster.Name != "pg" || received.Policy.Namespace != "ops" ||
+			received.Event.Type != cnpgv1alpha1.EventTypeExpansion || received.Event.Spec.Reason == "" {
+			t.Errorf("unexpected payload: %+v", received)
+		}
+		if received.Event.Status != nil {
+			t.Error("expected no event status in a preAction payload")
+		}
+	})
+
+	t.Run("postAction carries the status", func(t *testing.T) {
+		payload := NewPayload(HookPostAction, testEvent(), now)
+		if err := caller.Call(context.Background(), hook, "ops", payload); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(string(body), `"phase":"Completed"`) {
+			t.Errorf("expected the event status in the payload, got %s", body)
+		}
+	})
+
+	t.Run("non-2xx is a rejection", func(t *testing.T) {
+		status = http.StatusConflict
+		defer func() { status = http.StatusOK }()
+		err := caller.Call(context.Background(), hook, "ops", NewPayload(HookPreAction, testEvent(), now))
+		if !IsRejected(err) {
+			t.Fatalf("expected a rejection, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "HTTP 409: change window closed") {
+			t.Errorf("expected the status and response in the error, got %v", err)
+		}
+	})
+
+	t.Run("missing signing secret", func(t *testing.T) {
+		err := caller.Call(context.Background(), hook, "other", NewPayload(HookPreAction, testEvent(), now))
+		if err == nil || IsRejected(err) {
+			t.Errorf("expected a call error for a missing secret, got %v", err)
+		}
+	})
+
+	t.Run("unreachable webhook", func(t *testing.T) {
+		unreachable := &cnpgv1alpha1.WebhookHook{URL: "http://127.0.0.1:1", TimeoutSeconds: 1}
+		err := caller.Call(context.Background(), unreachable, "ops", NewPayload(HookPreAction, testEvent(), now))
+		if err == nil || IsRejected(err) {
+			t.Errorf("expected a call error, got %v", err)
+		}
+	})
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{}' | openssl dgst -sha256 -hmac key
+	want := "sha256=a777724d943eb48dc69bca8a4a6d57a04db3f9ec7e1de4e581e860265bdf3032"
+	if got := Sign([]byte("key"), []byte("{}")); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
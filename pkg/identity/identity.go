@@ -0,0 +1,91 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package identity names the Kubernetes cluster a CNPG cluster runs in, such as a
+// Rancher downstream cluster, so alerts and metrics can be routed per cluster.
+package identity
+
+const (
+	// LabelRancherClusterID is the label Rancher and Fleet put on cluster objects with
+	// the cluster ID (e.g. c-m-abc12)
+	LabelRancherClusterID = "management.cattle.io/cluster-name"
+	// LabelRancherClusterName is the label Rancher and Fleet put on cluster objects with
+	// the display name
+	LabelRancherClusterName = "management.cattle.io/cluster-display-name"
+
+	// EnvClusterID is the environment variable the manager's own cluster ID is read from
+	EnvClusterID = "CLUSTER_ID"
+	// EnvClusterName is the environment variable the manager's own cluster name is read from
+	EnvClusterName = "CLUSTER_NAME"
+)
+
+// ClusterIdentity identifies a Kubernetes cluster
+type ClusterIdentity struct {
+	// ID is a stable identifier such as the Rancher cluster ID
+	ID string
+	// Name is the human-readable cluster name
+	Name string
+}
+
+// IsZero reports whether no identity is known
+func (i ClusterIdentity) IsZero() bool {
+	return i.ID == "" && i.Name == ""
+}
+
+// Labels returns the identity as alert labels, omitting unknown values
+func (i ClusterIdentity) Labels() map[string]string {
+	labels := make(map[string]string, 2)
+	if i.ID != "" {
+		labels["source_cluster_id"] = i.ID
+	}
+	if i.Name != "" {
+		labels["source_cluster_name"] = i.Name
+	}
+	return labels
+}
+
+// Resolver derives the identity of downstream clusters from their ClusterConnection
+type Resolver struct {
+	// IDLabel is the ClusterConnection label holding the cluster ID
+	IDLabel string
+	// NameLabel is the ClusterConnection label holding the cluster name
+	NameLabel string
+}
+
+// DefaultResolver reads the labels Rancher and Fleet use
+func DefaultResolver() Resolver {
+	return Resolver{IDLabel: LabelRancherClusterID, NameLabel: LabelRancherClusterName}
+}
+
+// ForConnection resolves the identity of a downstream cluster. Explicit values win over
+// labels; the name falls back to the ClusterConnection name
+func (r Resolver) ForConnection(
+	connectionName string,
+	explicit ClusterIdentity,
+	labels map[string]string,
+) ClusterIdentity {
+	id := explicit
+	if id.ID == "" && r.IDLabel != "" {
+		id.ID = labels[r.IDLabel]
+	}
+	if id.Name == "" && r.NameLabel != "" {
+		id.Name = labels[r.NameLabel]
+	}
+	if id.Name == "" {
+		id.Name = connectionName
+	}
+	return id
+}
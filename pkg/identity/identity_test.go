@@ -0,0 +1,104 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolver_ForConnection(t *testing.T) {
+	rancherLabels := map[string]string{
+		LabelRancherClusterID:   "c-m-abc12",
+		LabelRancherClusterName: "edge-prod-1",
+	}
+
+	tests := []struct {
+		name     string
+		resolver Resolver
+		explicit ClusterIdentity
+		labels   map[string]string
+		want     ClusterIdentity
+	}{
+		{
+			name:     "rancher labels",
+			resolver: DefaultResolver(),
+			labels:   rancherLabels,
+			want:     ClusterIdentity{ID: "c-m-abc12", Name: "edge-prod-1"},
+		},
+		{
+			name:     "explicit values win over labels",
+			resolver: DefaultResolver(),
+			explicit: ClusterIdentity{ID: "c-m-override"},
+			labels:   rancherLabels,
+			want:     ClusterIdentity{ID: "c-m-override", Name: "edge-prod-1"},
+		},
+		{
+			name:     "name falls back to the connection name",
+			resolver: DefaultResolver(),
+			want:     ClusterIdentity{Name: "edge-1"},
+		},
+		{
+			name:     "custom labels",
+			resolver: Resolver{IDLabel: "example.com/cluster-id", NameLabel: "example.com/cluster"},
+			labels:   map[string]string{"example.com/cluster-id": "42", "example.com/cluster": "dc-east"},
+			want:     ClusterIdentity{ID: "42", Name: "dc-east"},
+		},
+		{
+			name:     "label lookup disabled",
+			resolver: Resolver{},
+			labels:   rancherLabels,
+			want:     ClusterIdentity{Name: "edge-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.resolver.ForConnection("edge-1", tt.explicit, tt.labels)
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClusterIdentity_Labels(t *testing.T) {
+	tests := []struct {
+		name string
+		id   ClusterIdentity
+		want map[string]string
+	}{
+		{name: "empty", id: ClusterIdentity{}, want: map[string]string{}},
+		{name: "name only", id: ClusterIdentity{Name: "local"}, want: map[string]string{"source_cluster_name": "local"}},
+		{
+			name: "id and name",
+			id:   ClusterIdentity{ID: "c-m-abc12", Name: "edge-prod-1"},
+			want: map[string]string{"source_cluster_id": "c-m-abc12", "source_cluster_name": "edge-prod-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.id.Labels(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if tt.id.IsZero() != (len(tt.want) == 0) {
+				t.Errorf("unexpected IsZero for %+v", tt.id)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package managerconfig reads the operator-wide defaults of the ManagerConfig and
+// applies them to the policies that leave fields unset.
+package managerconfig
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// DefaultExpansionCooldownMinutes is the expansion cooldown when neither the policy
+	// nor the ManagerConfig sets one
+	DefaultExpansionCooldownMinutes = 30
+	// DefaultWALCleanupCooldownMinutes is the WAL cleanup cooldown when neither the policy
+	// nor the ManagerConfig sets one
+	DefaultWALCleanupCooldownMinutes = 15
+)
+
+// Load returns the spec of the ManagerConfig, or nil when there is none
+func Load(ctx context.Context, reader client.Reader) (*cnpgv1alpha1.ManagerConfigSpec, error) {
+	var config cnpgv1alpha1.ManagerConfig
+	if err := reader.Get(ctx, client.ObjectKey{Name: cnpgv1alpha1.ManagerConfigName}, &config); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ManagerConfig %s: %w", cnpgv1alpha1.ManagerConfigName, err)
+	}
+	return &config.Spec, nil
+}
+
+// DryRun reports whether defaults enable dry-run mode for every policy
+func DryRun(defaults *cnpgv1alpha1.ManagerConfigSpec) bool {
+	return defaults != nil && defaults.DryRun
+}
+
+// ApplyStoragePolicyDefaults fills the unset fields of a StoragePolicy spec from
+// defaults, which may be nil. Cooldowns fall back to the built-in defaults and unset
+// WAL thresholds to the policy's thresholds.
+func ApplyStoragePolicyDefaults(spec *cnpgv1alpha1.StoragePolicySpec, defaults *cnpgv1alpha1.ManagerConfigSpec) {
+	if defaults == nil {
+		defaults = &cnpgv1alpha1.ManagerConfigSpec{}
+	}
+
+	thresholds := &spec.Thresholds
+	thresholds.Warning = valueOrDefault(thresholds.Warning, defaults.Thresholds.Warning)
+	thresholds.Critical = valueOrDefault(thresholds.Critical, defaults.Thresholds.Critical)
+	thresholds.Expansion = valueOrDefault(thresholds.Expansion, defaults.Thresholds.Expansion)
+	thresholds.Emergency = valueOrDefault(thresholds.Emergency, defaults.Thresholds.Emergency)
+	if thresholds.HeadroomGi == nil {
+		thresholds.HeadroomGi = defaults.Thresholds.HeadroomGi
+	}
+	if thresholds.AbsoluteFreeBytesFloor == nil {
+		thresholds.AbsoluteFreeBytesFloor = defaults.Thresholds.AbsoluteFreeBytesFloor
+	}
+
+	if spec.WALThresholds != nil {
+		wal := *spec.WALThresholds
+		wal.Warning = valueOrDefault(wal.Warning, thresholds.Warning)
+		wal.Critical = valueOrDefault(wal.Critical, thresholds.Critical)
+		wal.Expansion = valueOrDefault(wal.Expansion, thresholds.Expansion)
+		wal.Emergency = valueOrDefault(wal.Emergency, thresholds.Emergency)
+		spec.WALThresholds = &wal
+	}
+
+	spec.Expansion.CooldownMinutes = valueOrDefault(spec.Expansion.CooldownMinutes,
+		valueOrDefault(defaults.ExpansionCooldownMinutes, DefaultExpansionCooldownMinutes))
+	spec.WALCleanup.CooldownMinutes = valueOrDefault(spec.WALCleanup.CooldownMinutes,
+		valueOrDefault(defaults.WALCleanupCooldownMinutes, DefaultWALCleanupCooldownMinutes))
+
+	ApplyAlertingDefaults(&spec.Alerting, defaults)
+}
+
+// ApplyAlertingDefaults sets the default alert channels when alerting has none
+func ApplyAlertingDefaults(alerting *cnpgv1alpha1.AlertingConfig, defaults *cnpgv1alpha1.ManagerConfigSpec) {
+	if defaults == nil || len(alerting.Channels) > 0 || len(defaults.Alerting.Channels) == 0 {
+		return
+	}
+	alerting.Channels = append([]cnpgv1alpha1.AlertChannel(nil), defaults.Alerting.Channels...)
+}
+
+// valueOrDefault returns value, or fallback when value is unset
+func valueOrDefault(value, fallback int32) int32 {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
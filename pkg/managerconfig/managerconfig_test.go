@@ -0,0 +1,164 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managerconfig
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestApplyStoragePolicyDefaults(t *testing.T) {
+	orgChannels := []cnpgv1alpha1.AlertChannel{
+		{Type: cnpgv1alpha1.AlertChannelTypeSlack, WebhookSecret: "ops/slack-webhook"},
+	}
+	orgDefaults := &cnpgv1alpha1.ManagerConfigSpec{
+		Thresholds:                cnpgv1alpha1.ThresholdsConfig{Warning: 60, Critical: 75},
+		ExpansionCooldownMinutes:  60,
+		WALCleanupCooldownMinutes: 20,
+		Alerting:                  cnpgv1alpha1.DefaultAlertingConfig{Channels: orgChannels},
+	}
+	orgHeadroom := &cnpgv1alpha1.HeadroomThresholds{Expansion: 20, Emergency: 5}
+	policyHeadroom := &cnpgv1alpha1.HeadroomThresholds{Expansion: 50}
+	headroomDefaults := &cnpgv1alpha1.ManagerConfigSpec{Thresholds: cnpgv1alpha1.ThresholdsConfig{HeadroomGi: orgHeadroom}}
+
+	tests := []struct {
+		name              string
+		spec              cnpgv1alpha1.StoragePolicySpec
+		defaults          *cnpgv1alpha1.ManagerConfigSpec
+		wantThresholds    cnpgv1alpha1.ThresholdsConfig
+		wantWALThresholds *cnpgv1alpha1.ThresholdsConfig
+		wantExpansionCD   int32
+		wantWALCleanupCD  int32
+		wantChannels      int
+	}{
+		{
+			name:             "no ManagerConfig uses built-in cooldowns",
+			wantExpansionCD:  DefaultExpansionCooldownMinutes,
+			wantWALCleanupCD: DefaultWALCleanupCooldownMinutes,
+		},
+		{
+			name:             "minimal policy takes every default",
+			defaults:         orgDefaults,
+			wantThresholds:   cnpgv1alpha1.ThresholdsConfig{Warning: 60, Critical: 75},
+			wantExpansionCD:  60,
+			wantWALCleanupCD: 20,
+			wantChannels:     1,
+		},
+		{
+			name: "policy values win",
+			spec: cnpgv1alpha1.StoragePolicySpec{
+				Thresholds: cnpgv1alpha1.ThresholdsConfig{Warning: 50, Expansion: 88},
+				Expansion:  cnpgv1alpha1.ExpansionConfig{CooldownMinutes: 10},
+				Alerting: cnpgv1alpha1.AlertingConfig{Channels: []cnpgv1alpha1.AlertChannel{
+					{Type: cnpgv1alpha1.AlertChannelTypeAlertmanager, Endpoint: "http://am:9093"},
+					{Type: cnpgv1alpha1.AlertChannelTypePagerDuty, RoutingKeySecret: "ops/pd"},
+				}},
+			},
+			defaults:         orgDefaults,
+			wantThresholds:   cnpgv1alpha1.ThresholdsConfig{Warning: 50, Critical: 75, Expansion: 88},
+			wantExpansionCD:  10,
+			wantWALCleanupCD: 20,
+			wantChannels:     2,
+		},
+		{
+			name: "WAL thresholds fall back to the policy thresholds",
+			spec: cnpgv1alpha1.StoragePolicySpec{
+				Thresholds:    cnpgv1alpha1.ThresholdsConfig{Expansion: 88},
+				WALThresholds: &cnpgv1alpha1.ThresholdsConfig{Warning: 50, Expansion: 70},
+			},
+			defaults:          orgDefaults,
+			wantThresholds:    cnpgv1alpha1.ThresholdsConfig{Warning: 60, Critical: 75, Expansion: 88},
+			wantWALThresholds: &cnpgv1alpha1.ThresholdsConfig{Warning: 50, Critical: 75, Expansion: 70},
+			wantExpansionCD:   60,
+			wantWALCleanupCD:  20,
+			wantChannels:      1,
+		},
+		{
+			name:             "headroom falls back to the ManagerConfig",
+			defaults:         headroomDefaults,
+			wantThresholds:   cnpgv1alpha1.ThresholdsConfig{HeadroomGi: orgHeadroom},
+			wantExpansionCD:  DefaultExpansionCooldownMinutes,
+			wantWALCleanupCD: DefaultWALCleanupCooldownMinutes,
+		},
+		{
+			name: "policy headroom wins",
+			spec: cnpgv1alpha1.StoragePolicySpec{
+				Thresholds: cnpgv1alpha1.ThresholdsConfig{HeadroomGi: policyHeadroom},
+			},
+			defaults:         headroomDefaults,
+			wantThresholds:   cnpgv1alpha1.ThresholdsConfig{HeadroomGi: policyHeadroom},
+			wantExpansionCD:  DefaultExpansionCooldownMinutes,
+			wantWALCleanupCD: DefaultWALCleanupCooldownMinutes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			ApplyStoragePolicyDefaults(&spec, tt.defaults)
+
+			if spec.Thresholds != tt.wantThresholds {
+				t.Errorf("expected thresholds %+v, got %+v", tt.wantThresholds, spec.Thresholds)
+			}
+			if (spec.WALThresholds == nil) != (tt.wantWALThresholds == nil) ||
+				spec.WALThresholds != nil && *spec.WALThresholds != *tt.wantWALThresholds {
+				t.Errorf("expected WAL thresholds %+v, got %+v", tt.wantWALThresholds, spec.WALThresholds)
+			}
+			if spec.Expansion.CooldownMinutes != tt.wantExpansionCD {
+				t.Errorf("expected expansion cooldown %d, got %d", tt.wantExpansionCD, spec.Expansion.CooldownMinutes)
+			}
+			if spec.WALCleanup.CooldownMinutes != tt.wantWALCleanupCD {
+				t.Errorf("expected WAL cleanup cooldown %d, got %d", tt.wantWALCleanupCD, spec.WALCleanup.CooldownMinutes)
+			}
+			if len(spec.Alerting.Channels) != tt.wantChannels {
+				t.Errorf("expected %d channels, got %d", tt.wantChannels, len(spec.Alerting.Channels))
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = cnpgv1alpha1.AddToScheme(scheme)
+
+	empty := fake.NewClientBuilder().WithScheme(scheme).Build()
+	defaults, err := Load(context.Background(), empty)
+	if err != nil || defaults != nil {
+		t.Fatalf("expected no defaults without a ManagerConfig, got %+v, %v", defaults, err)
+	}
+	if DryRun(defaults) {
+		t.Error("expected dry-run to be off without a ManagerConfig")
+	}
+
+	configured := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&cnpgv1alpha1.ManagerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: cnpgv1alpha1.ManagerConfigName},
+		Spec:       cnpgv1alpha1.ManagerConfigSpec{DryRun: true},
+	}).Build()
+	defaults, err = Load(context.Background(), configured)
+	if err != nil || defaults == nil {
+		t.Fatalf("expected the ManagerConfig spec, got %+v, %v", defaults, err)
+	}
+	if !DryRun(defaults) {
+		t.Error("expected dry-run to be on")
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/agent"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+// DefaultAgentSelector selects the node agent pods installed by the Helm chart
+const DefaultAgentSelector = "app.kubernetes.io/component=node-agent"
+
+// AgentCollector collects storage metrics from the node agent DaemonSet, for
+// clusters where pod exec is not permitted
+type AgentCollector struct {
+	client     client.Client
+	httpClient *http.Client
+	namespace  string
+	selector   labels.Selector
+	port       int
+}
+
+// NewAgentCollector creates a collector that queries the agent pods matching
+// selector in namespace on the given port
+func NewAgentCollector(c client.Client, namespace string, selector labels.Selector, port int) *AgentCollector {
+	return &AgentCollector{
+		client:     c,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		namespace:  namespace,
+		selector:   selector,
+		port:       port,
+	}
+}
+
+// CollectPVCMetrics collects metrics for the PVCs of the given pods from the agent on
+// each pod's node
+func (a *AgentCollector) CollectPVCMetrics(ctx context.Context, pods []corev1.Pod) (_ []PVCMetrics, err error) {
+	ctx, span := tracing.Start(ctx, "metrics.CollectPVCMetricsViaAgent")
+	defer func() { tracing.End(span, err) }()
+	logger := log.FromContext(ctx)
+	start := time.Now()
+	defer func() {
+		MetricsCollectionDuration.WithLabelValues("agent").Observe(time.Since(start).Seconds())
+	}()
+
+	agents, err := a.agentsByNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	podsByNode := make(map[string][]corev1.Pod)
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" && pod.Status.Phase == corev1.PodRunning {
+			podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+		}
+	}
+
+	var allMetrics []PVCMetrics
+	for nodeName, nodePods := range podsByNode {
+		address, ok := agents[nodeName]
+		if !ok {
+			logger.Info("No node agent running on node", "node", nodeName)
+			RecordError("agent_missing", "", nodeName)
+			continue
+		}
+
+		report, err := a.fetchReport(ctx, address, nodePods)
+		if err != nil {
+			logger.Error(err, "Failed to fetch node agent report", "node", nodeName)
+			RecordError("agent_fetch", "", nodeName)
+			continue
+		}
+
+		allMetrics = append(allMetrics, a.extractPVCMetrics(ctx, report, nodePods, nodeName)...)
+	}
+
+	return allMetrics, nil
+}
+
+// agentsByNode returns the address of the ready agent on each node
+func (a *AgentCollector) agentsByNode(ctx context.Context) (map[string]string, error) {
+	var agentPods corev1.PodList
+	if err := a.client.List(ctx, &agentPods,
+		client.InNamespace(a.namespace),
+		client.MatchingLabelsSelector{Selector: a.selector},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list node agent pods: %w", err)
+	}
+
+	agents := make(map[string]string, len(agentPods.Items))
+	for _, pod := range agentPods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.Spec.NodeName == "" {
+			continue
+		}
+		agents[pod.Spec.NodeName] = net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(a.port))
+	}
+	return agents, nil
+}
+
+// fetchReport requests the volume report of the given pods from an agent
+func (a *AgentCollector) fetchReport(ctx context.Context, address string, pods []corev1.Pod) (*agent.Report, error) {
+	query := url.Values{}
+	for _, pod := range pods {
+		query.Add(agent.PodParam, string(pod.UID))
+	}
+	target := url.URL{Scheme: "http", Host: address, Path: agent.VolumesPath, RawQuery: query.Encode()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach node agent: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("node agent request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var report agent.Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to decode node agent report: %w", err)
+	}
+	return &report, nil
+}
+
+// extractPVCMetrics matches the volumes of an agent report to the pods' PVCs. The
+// agent names volumes after their PersistentVolume, so each claim is resolved to
+// its bound volume.
+func (a *AgentCollector) extractPVCMetrics(
+	ctx context.Context,
+	report *agent.Report,
+	pods []corev1.Pod,
+	nodeName string,
+) []PVCMetrics {
+	logger := log.FromContext(ctx)
+
+	type volumeKey struct{ podUID, volumeName string }
+	usage := make(map[volumeKey]agent.VolumeUsage, len(report.Volumes))
+	for _, volume := range report.Volumes {
+		usage[volumeKey{volume.PodUID, volume.VolumeName}] = volume
+	}
+
+	var metrics []PVCMetrics
+	for _, pod := range pods {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			claimName := volume.PersistentVolumeClaim.ClaimName
+
+			var pvc corev1.PersistentVolumeClaim
+			if err := a.client.Get(ctx, types.NamespacedName{Name: claimName, Namespace: pod.Namespace}, &pvc); err != nil {
+				logger.Error(err, "Failed to get PVC", "pvc", claimName, "namespace", pod.Namespace)
+				continue
+			}
+
+			stats, ok := usage[volumeKey{string(pod.UID), pvc.Spec.VolumeName}]
+			if !ok {
+				logger.V(2).Info("No node agent stats for PVC", "pod", pod.Name, "pvc", claimName)
+				continue
+			}
+
+			metrics = append(metrics, PVCMetrics{
+				PVCName:        claimName,
+				PVCNamespace:   pod.Namespace,
+				PodName:        pod.Name,
+				PodNamespace:   pod.Namespace,
+				NodeName:       nodeName,
+				UsedBytes:      stats.UsedBytes,
+				CapacityBytes:  stats.CapacityBytes,
+				AvailableBytes: stats.AvailableBytes,
+				Inodes:         stats.Inodes,
+				InodesUsed:     stats.InodesUsed,
+				InodesFree:     stats.InodesFree,
+				WALBytes:       stats.WALBytes,
+				WALFiles:       stats.WALFiles,
+				CollectedAt:    report.CollectedAt,
+			})
+		}
+	}
+	return metrics
+}
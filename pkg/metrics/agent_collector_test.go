@@ -0,0 +1,127 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/agent"
+)
+
+func TestAgentCollector_CollectPVCMetrics(t *testing.T) {
+	var requestedPods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPods = r.URL.Query()[agent.PodParam]
+		_ = json.NewEncoder(w).Encode(agent.Report{
+			NodeName: "node-1",
+			Volumes: []agent.VolumeUsage{
+				{PodUID: "uid-1", VolumeName: "pv-data", CapacityBytes: 100, UsedBytes: 40, AvailableBytes: 60},
+				{PodUID: "uid-1", VolumeName: "pv-wal", CapacityBytes: 50, UsedBytes: 45, WALBytes: 32, WALFiles: 2},
+				{PodUID: "uid-1", VolumeName: "pv-unrelated", CapacityBytes: 10, UsedBytes: 1},
+			},
+		})
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	pvc := func(name, volume string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volume},
+		}
+	}
+	agentPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "agent-abc",
+			Namespace: "cnpg-storage-manager",
+			Labels:    map[string]string{"app.kubernetes.io/component": "node-agent"},
+		},
+		Spec:   corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: host},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(pvc("pg-1", "pv-data"), pvc("pg-1-wal", "pv-wal"), agentPod).
+		Build()
+
+	selector, _ := labels.Parse(DefaultAgentSelector)
+	collector := NewAgentCollector(c, "cnpg-storage-manager", selector, port)
+
+	instance := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Namespace: "db", UID: types.UID("uid-1")},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Volumes: []corev1.Volume{
+				{Name: "pgdata", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pg-1"},
+				}},
+				{Name: "pg-wal", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pg-1-wal"},
+				}},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	// A pod on a node without an agent is skipped
+	elsewhere := *instance.DeepCopy()
+	elsewhere.Name, elsewhere.UID, elsewhere.Spec.NodeName = "pg-2", "uid-2", "node-2"
+
+	metrics, err := collector.CollectPVCMetrics(context.Background(), []corev1.Pod{instance, elsewhere})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(requestedPods) != 1 || requestedPods[0] != "uid-1" {
+		t.Errorf("expected only uid-1 to be requested, got %v", requestedPods)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("expected 2 PVC metrics, got %+v", metrics)
+	}
+
+	byPVC := make(map[string]PVCMetrics)
+	for _, m := range metrics {
+		byPVC[m.PVCName] = m
+	}
+	if data := byPVC["pg-1"]; data.UsedBytes != 40 || data.CapacityBytes != 100 || data.NodeName != "node-1" {
+		t.Errorf("unexpected data PVC metrics %+v", data)
+	}
+	if wal := byPVC["pg-1-wal"]; wal.WALBytes != 32 || wal.WALFiles != 2 || wal.PodName != "pg-1" {
+		t.Errorf("unexpected WAL PVC metrics %+v", wal)
+	}
+}
+
+func TestCollector_AgentSourceRequiresAgent(t *testing.T) {
+	collector := &Collector{}
+	if _, err := collector.CollectClusterMetricsFrom(context.Background(), SourceAgent, "pg", "db", nil); err == nil {
+		t.Error("expected an error when the node agent is not configured")
+	}
+}
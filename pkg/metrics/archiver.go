@@ -0,0 +1,180 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+)
+
+// archiverQuery reads pg_stat_archiver together with the current WAL file and the
+// WAL segment size, using the server clock for all timestamps
+const archiverQuery = `SELECT a.archived_count, a.failed_count,
+coalesce(a.last_archived_wal, ''),
+coalesce(floor(extract(epoch FROM a.last_archived_time))::bigint::text, ''),
+coalesce(a.last_failed_wal, ''),
+coalesce(floor(extract(epoch FROM a.last_failed_time))::bigint::text, ''),
+pg_walfile_name(pg_current_wal_lsn()),
+(SELECT setting FROM pg_settings WHERE name = 'wal_segment_size'),
+floor(extract(epoch FROM now()))::bigint
+FROM pg_stat_archiver a`
+
+// archiverFields is the number of columns returned by archiverQuery
+const archiverFields = 9
+
+// walFileNameLength is the length of a WAL segment file name (timeline, log, segment)
+const walFileNameLength = 24
+
+// ArchiverStats contains WAL archiving statistics of a primary from pg_stat_archiver
+type ArchiverStats struct {
+	ArchivedCount    int64
+	FailedCount      int64
+	LastArchivedWAL  string
+	LastArchivedTime *time.Time
+	LastFailedWAL    string
+	LastFailedTime   *time.Time
+	// CurrentWAL is the WAL file currently being written
+	CurrentWAL string
+	// LagKnown is false when the lag cannot be derived, e.g. nothing has been archived yet
+	// or the last archived file is a timeline history file
+	LagKnown bool
+	// LagSegments is the number of completed WAL segments not yet archived
+	LagSegments int64
+	// LagSeconds is the time since the last successful archival while segments are pending
+	LagSeconds float64
+	// Failing is true when the most recent archive attempt failed
+	Failing bool
+}
+
+// ArchiverCollector collects pg_stat_archiver statistics by running psql inside the
+// postgres container. It needs pod exec; the Job runner cannot reach the database.
+type ArchiverCollector struct {
+	runner runner.CommandRunner
+}
+
+// NewArchiverCollector creates a collector that runs psql through the given command runner
+func NewArchiverCollector(commandRunner runner.CommandRunner) *ArchiverCollector {
+	return &ArchiverCollector{runner: commandRunner}
+}
+
+// Collect queries pg_stat_archiver on a primary pod
+func (a *ArchiverCollector) Collect(ctx context.Context, pod *corev1.Pod) (*ArchiverStats, error) {
+	start := time.Now()
+	defer func() {
+		MetricsCollectionDuration.WithLabelValues("psql_archiver").Observe(time.Since(start).Seconds())
+	}()
+
+	command := []string{"psql", "-X", "-A", "-t", "-q", "-d", "postgres", "-F", "|", "-c", archiverQuery}
+	stdout, err := a.runner.Run(ctx, pod, runner.PreferredContainer(pod), command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_stat_archiver on %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return parseArchiverOutput(stdout)
+}
+
+// parseArchiverOutput parses the unaligned psql output of archiverQuery
+func parseArchiverOutput(output string) (*ArchiverStats, error) {
+	line := strings.TrimSpace(output)
+	fields := strings.Split(line, "|")
+	if len(fields) != archiverFields {
+		return nil, fmt.Errorf("unexpected pg_stat_archiver output %q", line)
+	}
+
+	stats := &ArchiverStats{
+		LastArchivedWAL: fields[2],
+		LastFailedWAL:   fields[4],
+		CurrentWAL:      fields[6],
+	}
+	var err error
+	if stats.ArchivedCount, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid archived_count %q", fields[0])
+	}
+	if stats.FailedCount, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid failed_count %q", fields[1])
+	}
+	if stats.LastArchivedTime, err = parseEpoch(fields[3]); err != nil {
+		return nil, fmt.Errorf("invalid last_archived_time %q", fields[3])
+	}
+	if stats.LastFailedTime, err = parseEpoch(fields[5]); err != nil {
+		return nil, fmt.Errorf("invalid last_failed_time %q", fields[5])
+	}
+	segmentSize, err := strconv.ParseInt(fields[7], 10, 64)
+	if err != nil || segmentSize <= 0 {
+		return nil, fmt.Errorf("invalid wal_segment_size %q", fields[7])
+	}
+	now, err := parseEpoch(fields[8])
+	if err != nil || now == nil {
+		return nil, fmt.Errorf("invalid server time %q", fields[8])
+	}
+
+	stats.Failing = stats.LastFailedTime != nil &&
+		(stats.LastArchivedTime == nil || stats.LastFailedTime.After(*stats.LastArchivedTime))
+
+	current, currentOK := walSegmentNumber(stats.CurrentWAL, segmentSize)
+	archived, archivedOK := walSegmentNumber(stats.LastArchivedWAL, segmentSize)
+	if !currentOK || !archivedOK {
+		return stats, nil
+	}
+
+	stats.LagKnown = true
+	// The segment being written cannot be archived yet
+	if current > archived+1 {
+		stats.LagSegments = int64(current - archived - 1)
+	}
+	if stats.LagSegments > 0 && stats.LastArchivedTime != nil {
+		stats.LagSeconds = now.Sub(*stats.LastArchivedTime).Seconds()
+	}
+	return stats, nil
+}
+
+// walSegmentNumber returns the absolute segment number of a WAL file name, ignoring
+// the timeline. Partial segments are accepted; history files are not.
+func walSegmentNumber(name string, segmentSize int64) (uint64, bool) {
+	if len(name) < walFileNameLength {
+		return 0, false
+	}
+	logID, err := strconv.ParseUint(name[8:16], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	segID, err := strconv.ParseUint(name[16:24], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	segmentsPerLogID := uint64(0x100000000) / uint64(segmentSize)
+	return logID*segmentsPerLogID + segID, true
+}
+
+// parseEpoch parses an epoch seconds value, returning nil for an empty string
+func parseEpoch(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+)
+
+func TestParseArchiverOutput(t *testing.T) {
+	tests := []struct {
+		name            string
+		output          string
+		expectLagKnown  bool
+		expectSegments  int64
+		expectSeconds   float64
+		expectFailing   bool
+		expectFailedCnt int64
+	}{
+		{
+			name:           "caught up",
+			output:         "120|0|00000001000000000000007F|1741773000|||000000010000000000000080|16777216|1741773600\n",
+			expectLagKnown: true,
+		},
+		{
+			name: "lagging across log boundary while failing",
+			output: "120|3|0000000100000000000000FE|1741773000|0000000100000000000000FF|1741773500|" +
+				"000000010000000100000003|16777216|1741773600\n",
+			expectLagKnown:  true,
+			expectSegments:  4, // FF, 1/00, 1/01, 1/02
+			expectSeconds:   600,
+			expectFailing:   true,
+			expectFailedCnt: 3,
+		},
+		{
+			name: "recovered after failure",
+			output: "121|3|000000010000000000000080|1741773550|00000001000000000000007F|1741773500|" +
+				"000000010000000000000081|16777216|1741773600",
+			expectLagKnown:  true,
+			expectFailedCnt: 3,
+		},
+		{
+			name:           "partial segment after promotion",
+			output:         "5|0|000000010000000000000010.partial|1741773000|||000000020000000000000013|16777216|1741773600",
+			expectLagKnown: true,
+			expectSegments: 2,
+			expectSeconds:  600,
+		},
+		{
+			name:   "history file",
+			output: "5|0|00000002.history|1741773000|||000000020000000000000013|16777216|1741773600",
+		},
+		{
+			name:   "nothing archived yet",
+			output: "0|0|||||000000010000000000000003|16777216|1741773600",
+		},
+		{
+			name:           "64MB segments",
+			output:         "9|0|00000001000000000000003E|1741773000|||000000010000000100000001|67108864|1741773600",
+			expectLagKnown: true,
+			expectSegments: 2, // 3F, 1/00
+			expectSeconds:  600,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := parseArchiverOutput(tt.output)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stats.LagKnown != tt.expectLagKnown {
+				t.Errorf("expected LagKnown %v, got %v", tt.expectLagKnown, stats.LagKnown)
+			}
+			if stats.LagSegments != tt.expectSegments {
+				t.Errorf("expected %d lag segments, got %d", tt.expectSegments, stats.LagSegments)
+			}
+			if stats.LagSeconds != tt.expectSeconds {
+				t.Errorf("expected %.0f lag seconds, got %.0f", tt.expectSeconds, stats.LagSeconds)
+			}
+			if stats.Failing != tt.expectFailing {
+				t.Errorf("expected Failing %v, got %v", tt.expectFailing, stats.Failing)
+			}
+			if stats.FailedCount != tt.expectFailedCnt {
+				t.Errorf("expected failed count %d, got %d", tt.expectFailedCnt, stats.FailedCount)
+			}
+		})
+	}
+}
+
+func TestParseArchiverOutput_Invalid(t *testing.T) {
+	for _, output := range []string{
+		"",
+		"1|2|3",
+		"x|0|||||000000010000000000000003|16777216|1741773600",
+		"0|0|||||000000010000000000000003|0|1741773600",
+		"0|0|||||000000010000000000000003|16777216|",
+	} {
+		if _, err := parseArchiverOutput(output); err == nil {
+			t.Errorf("expected error for %q", output)
+		}
+	}
+}
@@ -23,12 +23,18 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
 )
 
 // KubeletStatsSummary represents the kubelet stats/summary response
@@ -99,7 +105,18 @@ type PVCMetrics struct {
 	InodesUsed     int64
 	Inodes         int64
 	InodesFree     int64
-	CollectedAt    time.Time
+	// WALBytes and WALFiles describe the pg_wal directory on the volume. Only the
+	// node agent reports them
+	WALBytes int64
+	WALFiles int
+	// Tablespace is the tablespace the PVC holds, empty for data and WAL PVCs
+	Tablespace string
+	// WALVolume is set for the separate WAL PVCs (spec.walStorage)
+	WALVolume bool
+	// Zone and Region are the topology.kubernetes.io labels of the pod's node
+	Zone        string
+	Region      string
+	CollectedAt time.Time
 }
 
 // UsagePercent returns the usage percentage
@@ -118,12 +135,30 @@ func (m *PVCMetrics) InodesUsedPercent() float64 {
 	return float64(m.InodesUsed) / float64(m.Inodes) * 100
 }
 
+// Source selects where volume usage is collected from
+type Source string
+
+const (
+	// SourceKubelet reads kubelet volume stats, falling back to df in the pods
+	SourceKubelet Source = "kubelet"
+	// SourceExec runs df in the pods
+	SourceExec Source = "exec"
+	// SourceAgent queries the node agent DaemonSet
+	SourceAgent Source = "agent"
+)
+
 // Collector collects storage metrics from kubelet
 type Collector struct {
-	client        client.Client
-	restConfig    *rest.Config
-	httpClient    *http.Client
-	execCollector *ExecCollector
+	client         client.Client
+	restConfig     *rest.Config
+	httpClient     *http.Client
+	execCollector  *ExecCollector
+	agentCollector *AgentCollector
+	nodeStats      *nodeStatsCache
+
+	// skipPVCMetrics stops per-PVC Prometheus series from being recorded, for clusters
+	// whose names may clash with local ones
+	skipPVCMetrics bool
 }
 
 // NewCollector creates a new metrics collector
@@ -144,6 +179,7 @@ func NewCollector(c client.Client, restConfig *rest.Config) *Collector {
 		client:        c,
 		restConfig:    restConfig,
 		execCollector: execCollector,
+		nodeStats:     newNodeStatsCache(DefaultNodeStatsTTL),
 		httpClient: &http.Client{
 			Transport: transport,
 			Timeout:   30 * time.Second,
@@ -151,6 +187,33 @@ func NewCollector(c client.Client, restConfig *rest.Config) *Collector {
 	}
 }
 
+// SetCommandRunner makes the df fallback run through the given command runner
+// instead of pod exec. A nil runner disables the fallback and the exec source
+func (c *Collector) SetCommandRunner(commandRunner runner.CommandRunner) {
+	if commandRunner == nil {
+		c.execCollector = nil
+		return
+	}
+	c.execCollector = NewExecCollectorWithRunner(commandRunner)
+}
+
+// SetNodeStatsTTL sets how long a node's kubelet stats summary is shared between
+// clusters. Zero fetches the summary for every cluster
+func (c *Collector) SetNodeStatsTTL(ttl time.Duration) {
+	c.nodeStats = newNodeStatsCache(ttl)
+}
+
+// SetAgentCollector enables collection from the node agent for SourceAgent
+func (c *Collector) SetAgentCollector(agentCollector *AgentCollector) {
+	c.agentCollector = agentCollector
+}
+
+// SetRecordPVCMetrics controls whether collected PVC usage is exported as per-PVC
+// Prometheus series. Enabled by default
+func (c *Collector) SetRecordPVCMetrics(record bool) {
+	c.skipPVCMetrics = !record
+}
+
 // CollectPVCMetrics collects metrics for PVCs associated with a cluster
 func (c *Collector) CollectPVCMetrics(ctx context.Context, pods []corev1.Pod) ([]PVCMetrics, error) {
 	logger := log.FromContext(ctx)
@@ -166,7 +229,7 @@ func (c *Collector) CollectPVCMetrics(ctx context.Context, pods []corev1.Pod) ([
 
 	// Collect metrics from each node
 	for nodeName, nodePods := range podsByNode {
-		stats, err := c.fetchKubeletStats(ctx, nodeName)
+		stats, err := c.kubeletStats(ctx, nodeName)
 		if err != nil {
 			logger.Error(err, "Failed to fetch kubelet stats", "node", nodeName)
 			RecordError("kubelet_stats_fetch", "", nodeName)
@@ -181,8 +244,19 @@ func (c *Collector) CollectPVCMetrics(ctx context.Context, pods []corev1.Pod) ([
 	return allMetrics, nil
 }
 
+// kubeletStats returns the stats summary of a node, from the node stats cache when
+// one is set
+func (c *Collector) kubeletStats(ctx context.Context, nodeName string) (*KubeletStatsSummary, error) {
+	if c.nodeStats == nil {
+		return c.fetchKubeletStats(ctx, nodeName)
+	}
+	return c.nodeStats.get(ctx, nodeName, c.fetchKubeletStats)
+}
+
 // fetchKubeletStats fetches stats from kubelet's /stats/summary endpoint
-func (c *Collector) fetchKubeletStats(ctx context.Context, nodeName string) (*KubeletStatsSummary, error) {
+func (c *Collector) fetchKubeletStats(ctx context.Context, nodeName string) (_ *KubeletStatsSummary, err error) {
+	ctx, span := tracing.Start(ctx, "metrics.FetchKubeletStats", tracing.Node(nodeName))
+	defer func() { tracing.End(span, err) }()
 	logger := log.FromContext(ctx)
 	start := time.Now()
 	defer func() {
@@ -320,23 +394,51 @@ func (c *Collector) extractPVCMetrics(stats *KubeletStatsSummary, pods []corev1.
 	return metrics
 }
 
-// CollectClusterMetrics collects all PVC metrics for a CNPG cluster
+// CollectClusterMetrics collects all PVC metrics for a CNPG cluster from kubelet stats
 func (c *Collector) CollectClusterMetrics(
 	ctx context.Context,
 	clusterName, namespace string,
 	pods []corev1.Pod,
 ) (*ClusterMetrics, error) {
+	return c.CollectClusterMetricsFrom(ctx, SourceKubelet, clusterName, namespace, pods)
+}
+
+// CollectClusterMetricsFrom collects all PVC metrics for a CNPG cluster from the
+// given source. An empty source means SourceKubelet.
+func (c *Collector) CollectClusterMetricsFrom(
+	ctx context.Context,
+	source Source,
+	clusterName, namespace string,
+	pods []corev1.Pod,
+) (_ *ClusterMetrics, err error) {
+	ctx, span := tracing.Start(ctx, "metrics.CollectClusterMetrics", tracing.Cluster(clusterName, namespace)...)
+	defer func() { tracing.End(span, err) }()
 	logger := log.FromContext(ctx)
 	start := time.Now()
 
-	pvcMetrics, err := c.CollectPVCMetrics(ctx, pods)
+	var pvcMetrics []PVCMetrics
+	switch source {
+	case SourceAgent:
+		if c.agentCollector == nil {
+			return nil, fmt.Errorf("metrics source %q requires the node agent, which is not configured", source)
+		}
+		pvcMetrics, err = c.agentCollector.CollectPVCMetrics(ctx, pods)
+	case SourceExec:
+		if c.execCollector == nil {
+			return nil, fmt.Errorf("metrics source %q is not available: commands cannot be run in pods", source)
+		}
+		pvcMetrics, err = c.execCollector.CollectPVCMetricsViaExec(ctx, pods)
+	default:
+		pvcMetrics, err = c.CollectPVCMetrics(ctx, pods)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if we got any PVC metrics from kubelet stats
 	// If not, try the exec-based fallback (for storage classes like local-path)
-	if len(pvcMetrics) == 0 && c.execCollector != nil && len(pods) > 0 {
+	isKubelet := source == "" || source == SourceKubelet
+	if isKubelet && len(pvcMetrics) == 0 && c.execCollector != nil && len(pods) > 0 {
 		logger.Info("No PVC metrics from kubelet stats, trying exec-based fallback",
 			"cluster", clusterName,
 			"namespace", namespace,
@@ -359,6 +461,9 @@ func (c *Collector) CollectClusterMetrics(
 		}
 	}
 
+	pvcMetrics = c.classifyPVCs(ctx, clusterName, namespace, pvcMetrics)
+	c.labelTopology(ctx, pvcMetrics, pods)
+
 	clusterMetrics := &ClusterMetrics{
 		ClusterName: clusterName,
 		Namespace:   namespace,
@@ -366,18 +471,40 @@ func (c *Collector) CollectClusterMetrics(
 		CollectedAt: time.Now(),
 	}
 
-	// Calculate aggregates
+	// Calculate aggregates. Tablespaces are evaluated on their own
+	if !c.skipPVCMetrics {
+		DeletePVCTopology(clusterName, namespace)
+	}
 	for _, pvc := range pvcMetrics {
-		clusterMetrics.TotalUsedBytes += pvc.UsedBytes
-		clusterMetrics.TotalCapacityBytes += pvc.CapacityBytes
+		if pvc.Tablespace == "" {
+			clusterMetrics.TotalUsedBytes += pvc.UsedBytes
+			clusterMetrics.TotalCapacityBytes += pvc.CapacityBytes
+		}
 
 		// Record individual PVC metrics to Prometheus
+		if c.skipPVCMetrics {
+			continue
+		}
 		RecordPVCMetrics(clusterName, namespace, pvc.PVCName, pvc.PodName, pvc.UsedBytes, pvc.CapacityBytes)
+		if pvc.Zone != "" || pvc.Region != "" {
+			SetPVCTopology(clusterName, namespace, pvc.PVCName, pvc.PodName, pvc.Zone, pvc.Region)
+		}
+		if pvc.WALFiles > 0 {
+			RecordWALMetrics(clusterName, namespace, pvc.PodName, pvc.WALBytes, pvc.WALFiles)
+		}
+	}
+	if !c.skipPVCMetrics {
+		for _, tablespace := range clusterMetrics.TablespaceUsage() {
+			SetTablespaceUsage(clusterName, namespace, tablespace.Name, tablespace.UsagePercent())
+		}
+		SetZoneUsage(clusterName, namespace, clusterMetrics.ZoneUsage())
+		setExportedPVCs(clusterName, namespace, pvcMetrics)
 	}
 
 	logger.V(1).Info("Collected cluster metrics",
 		"cluster", clusterName,
 		"namespace", namespace,
+		"source", source,
 		"pvcCount", len(pvcMetrics),
 		"totalUsed", clusterMetrics.TotalUsedBytes,
 		"totalCapacity", clusterMetrics.TotalCapacityBytes,
@@ -387,7 +514,80 @@ func (c *Collector) CollectClusterMetrics(
 	return clusterMetrics, nil
 }
 
-// ClusterMetrics contains aggregated metrics for a CNPG cluster
+// classifyPVCs marks the WAL and tablespace PVCs among the metrics of a cluster and drops
+// the PVCs with the ignore annotation. Without a client, or when the PVCs cannot be
+// listed, every PVC counts as a data PVC
+func (c *Collector) classifyPVCs(
+	ctx context.Context,
+	clusterName, namespace string,
+	pvcMetrics []PVCMetrics,
+) []PVCMetrics {
+	if c.client == nil {
+		return pvcMetrics
+	}
+	var pvcs corev1.PersistentVolumeClaimList
+	if err := c.client.List(ctx, &pvcs,
+		client.InNamespace(namespace),
+		client.MatchingLabels{"cnpg.io/cluster": clusterName},
+	); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list cluster PVCs", "cluster", clusterName, "namespace", namespace)
+		return pvcMetrics
+	}
+
+	byName := make(map[string]*corev1.PersistentVolumeClaim, len(pvcs.Items))
+	for i := range pvcs.Items {
+		byName[pvcs.Items[i].Name] = &pvcs.Items[i]
+	}
+	classified := pvcMetrics[:0]
+	for _, m := range pvcMetrics {
+		if pvc, ok := byName[m.PVCName]; ok {
+			if cnpg.IsIgnoredPVC(pvc) {
+				continue
+			}
+			m.Tablespace = cnpg.PVCTablespace(pvc)
+			m.WALVolume = cnpg.IsWALPVC(pvc)
+		}
+		classified = append(classified, m)
+	}
+	return classified
+}
+
+// labelTopology sets the zone and region of each PVC from the labels of the node its
+// pod runs on. Without a client, or for nodes that cannot be read, they are left empty
+func (c *Collector) labelTopology(ctx context.Context, pvcMetrics []PVCMetrics, pods []corev1.Pod) {
+	if c.client == nil {
+		return
+	}
+	podNodes := make(map[string]string, len(pods))
+	for i := range pods {
+		podNodes[pods[i].Name] = pods[i].Spec.NodeName
+	}
+
+	nodeLabels := map[string]map[string]string{}
+	for i := range pvcMetrics {
+		nodeName := pvcMetrics[i].NodeName
+		if nodeName == "" {
+			nodeName = podNodes[pvcMetrics[i].PodName]
+		}
+		if nodeName == "" {
+			continue
+		}
+		labels, ok := nodeLabels[nodeName]
+		if !ok {
+			var node corev1.Node
+			if err := c.client.Get(ctx, client.ObjectKey{Name: nodeName}, &node); err != nil {
+				log.FromContext(ctx).V(1).Info("Failed to get node topology", "node", nodeName, "error", err.Error())
+			}
+			labels = node.Labels
+			nodeLabels[nodeName] = labels
+		}
+		pvcMetrics[i].Zone = labels[corev1.LabelTopologyZone]
+		pvcMetrics[i].Region = labels[corev1.LabelTopologyRegion]
+	}
+}
+
+// ClusterMetrics contains aggregated metrics for a CNPG cluster. The totals cover the
+// data and WAL PVCs; tablespace PVCs are reported by TablespaceUsage
 type ClusterMetrics struct {
 	ClusterName        string
 	Namespace          string
@@ -405,6 +605,164 @@ func (m *ClusterMetrics) TotalUsagePercent() float64 {
 	return float64(m.TotalUsedBytes) / float64(m.TotalCapacityBytes) * 100
 }
 
+// LargestInstanceUsedBytes returns the storage used by the instance using the most,
+// summing its data and WAL volumes. Unlike TotalUsedBytes it does not jump when a
+// replica is added or removed
+func (m *ClusterMetrics) LargestInstanceUsedBytes() int64 {
+	perInstance := make(map[string]int64, len(m.PVCMetrics))
+	var largest int64
+	for i := range m.PVCMetrics {
+		perInstance[m.PVCMetrics[i].PodName] += m.PVCMetrics[i].UsedBytes
+		largest = max(largest, perInstance[m.PVCMetrics[i].PodName])
+	}
+	return largest
+}
+
+// VolumeUsage is the usage of a tablespace or of the WAL volume on the instance where
+// it is fullest
+type VolumeUsage struct {
+	// Name is the tablespace, empty for the WAL volume
+	Name          string
+	PVCName       string
+	UsedBytes     int64
+	CapacityBytes int64
+}
+
+// UsagePercent returns the usage percentage of the volume
+func (t *VolumeUsage) UsagePercent() float64 {
+	if t.CapacityBytes == 0 {
+		return 0
+	}
+	return float64(t.UsedBytes) / float64(t.CapacityBytes) * 100
+}
+
+// FreeBytes returns the free space of the volume
+func (t *VolumeUsage) FreeBytes() int64 {
+	return max(t.CapacityBytes-t.UsedBytes, 0)
+}
+
+// DataUsage returns the used and capacity bytes of the data PVCs, leaving out the WAL
+// and tablespace PVCs
+func (m *ClusterMetrics) DataUsage() (usedBytes, capacityBytes int64) {
+	for i := range m.PVCMetrics {
+		if m.PVCMetrics[i].Tablespace == "" && !m.PVCMetrics[i].WALVolume {
+			usedBytes += m.PVCMetrics[i].UsedBytes
+			capacityBytes += m.PVCMetrics[i].CapacityBytes
+		}
+	}
+	return usedBytes, capacityBytes
+}
+
+// LeastFreeBytes returns the free space of the data PVC with the least, also counting
+// the WAL PVCs when includeWAL is set, or 0 when there is none
+func (m *ClusterMetrics) LeastFreeBytes(includeWAL bool) int64 {
+	var least int64
+	found := false
+	for i := range m.PVCMetrics {
+		pvc := &m.PVCMetrics[i]
+		if pvc.Tablespace != "" || (pvc.WALVolume && !includeWAL) || pvc.CapacityBytes == 0 {
+			continue
+		}
+		if free := max(pvc.CapacityBytes-pvc.UsedBytes, 0); !found || free < least {
+			least, found = free, true
+		}
+	}
+	return least
+}
+
+// WALUsage returns the fullest WAL PVC, or nil when the cluster has no separate WAL
+// volumes
+func (m *ClusterMetrics) WALUsage() *VolumeUsage {
+	var fullest *PVCMetrics
+	for i := range m.PVCMetrics {
+		pvc := &m.PVCMetrics[i]
+		if pvc.WALVolume && (fullest == nil || pvc.UsagePercent() > fullest.UsagePercent()) {
+			fullest = pvc
+		}
+	}
+	if fullest == nil {
+		return nil
+	}
+	return &VolumeUsage{
+		PVCName:       fullest.PVCName,
+		UsedBytes:     fullest.UsedBytes,
+		CapacityBytes: fullest.CapacityBytes,
+	}
+}
+
+// TablespaceUsage returns the fullest PVC of each tablespace, sorted by tablespace name
+func (m *ClusterMetrics) TablespaceUsage() []VolumeUsage {
+	fullest := make(map[string]*PVCMetrics)
+	for i := range m.PVCMetrics {
+		pvc := &m.PVCMetrics[i]
+		if pvc.Tablespace == "" {
+			continue
+		}
+		if current, ok := fullest[pvc.Tablespace]; !ok || pvc.UsagePercent() > current.UsagePercent() {
+			fullest[pvc.Tablespace] = pvc
+		}
+	}
+
+	usage := make([]VolumeUsage, 0, len(fullest))
+	for name, pvc := range fullest {
+		usage = append(usage, VolumeUsage{
+			Name:          name,
+			PVCName:       pvc.PVCName,
+			UsedBytes:     pvc.UsedBytes,
+			CapacityBytes: pvc.CapacityBytes,
+		})
+	}
+	slices.SortFunc(usage, func(a, b VolumeUsage) int { return strings.Compare(a.Name, b.Name) })
+	return usage
+}
+
+// ZoneUsage is the usage of the data and WAL volumes of the instances in a zone
+type ZoneUsage struct {
+	Zone          string
+	Region        string
+	Instances     []string
+	UsedBytes     int64
+	CapacityBytes int64
+}
+
+// UsagePercent returns the usage percentage of the zone's volumes
+func (z *ZoneUsage) UsagePercent() float64 {
+	if z.CapacityBytes == 0 {
+		return 0
+	}
+	return float64(z.UsedBytes) / float64(z.CapacityBytes) * 100
+}
+
+// ZoneUsage sums the data and WAL PVCs of each zone, sorted by zone name. PVCs whose
+// node has no zone label are left out, so clusters without zones report none
+func (m *ClusterMetrics) ZoneUsage() []ZoneUsage {
+	zones := map[string]*ZoneUsage{}
+	for i := range m.PVCMetrics {
+		pvc := &m.PVCMetrics[i]
+		if pvc.Zone == "" || pvc.Tablespace != "" {
+			continue
+		}
+		zone, ok := zones[pvc.Zone]
+		if !ok {
+			zone = &ZoneUsage{Zone: pvc.Zone, Region: pvc.Region}
+			zones[pvc.Zone] = zone
+		}
+		if !slices.Contains(zone.Instances, pvc.PodName) {
+			zone.Instances = append(zone.Instances, pvc.PodName)
+		}
+		zone.UsedBytes += pvc.UsedBytes
+		zone.CapacityBytes += pvc.CapacityBytes
+	}
+
+	usage := make([]ZoneUsage, 0, len(zones))
+	for _, zone := range zones {
+		slices.Sort(zone.Instances)
+		usage = append(usage, *zone)
+	}
+	slices.SortFunc(usage, func(a, b ZoneUsage) int { return strings.Compare(a.Zone, b.Zone) })
+	return usage
+}
+
 // GetPrimaryPVCMetrics returns metrics for the primary instance PVC
 func (m *ClusterMetrics) GetPrimaryPVCMetrics(primaryPodName string) *PVCMetrics {
 	for i := range m.PVCMetrics {
@@ -415,6 +773,33 @@ func (m *ClusterMetrics) GetPrimaryPVCMetrics(primaryPodName string) *PVCMetrics
 	return nil
 }
 
+// HighestReplicaUsagePercent returns the highest usage percentage of any data or WAL PVC
+// that does not belong to the primary, or 0 when there is none
+func (m *ClusterMetrics) HighestReplicaUsagePercent(primaryPodName string) float64 {
+	var highest float64
+	for i := range m.PVCMetrics {
+		if m.PVCMetrics[i].PodName == primaryPodName || m.PVCMetrics[i].Tablespace != "" {
+			continue
+		}
+		if percent := m.PVCMetrics[i].UsagePercent(); percent > highest {
+			highest = percent
+		}
+	}
+	return highest
+}
+
+// InstanceUsagePercent returns the usage percentage of the fullest PVC of each instance
+func (m *ClusterMetrics) InstanceUsagePercent() map[string]float64 {
+	usage := make(map[string]float64)
+	for i := range m.PVCMetrics {
+		pvc := &m.PVCMetrics[i]
+		if highest, ok := usage[pvc.PodName]; !ok || pvc.UsagePercent() > highest {
+			usage[pvc.PodName] = pvc.UsagePercent()
+		}
+	}
+	return usage
+}
+
 // GetHighestUsagePVC returns the PVC with the highest usage percentage
 func (m *ClusterMetrics) GetHighestUsagePVC() *PVCMetrics {
 	var highest *PVCMetrics
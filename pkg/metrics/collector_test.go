@@ -0,0 +1,247 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/annotations"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+func tablespaceClusterMetrics() *ClusterMetrics {
+	return &ClusterMetrics{
+		PVCMetrics: []PVCMetrics{
+			{PVCName: "pg-1", PodName: "pg-1", UsedBytes: 40, CapacityBytes: 100},
+			{PVCName: "pg-1-wal", PodName: "pg-1", UsedBytes: 10, CapacityBytes: 100, WALVolume: true},
+			{PVCName: "pg-1-tbs-archive", PodName: "pg-1", UsedBytes: 300, CapacityBytes: 1000, Tablespace: "archive"},
+			{PVCName: "pg-2", PodName: "pg-2", UsedBytes: 50, CapacityBytes: 100},
+			{PVCName: "pg-2-wal", PodName: "pg-2", UsedBytes: 30, CapacityBytes: 50, WALVolume: true},
+			{PVCName: "pg-2-tbs-archive", PodName: "pg-2", UsedBytes: 900, CapacityBytes: 1000, Tablespace: "archive"},
+			{PVCName: "pg-2-tbs-hot", PodName: "pg-2", UsedBytes: 5, CapacityBytes: 10, Tablespace: "hot"},
+		},
+	}
+}
+
+func TestClusterMetrics_TablespaceUsage(t *testing.T) {
+	usage := tablespaceClusterMetrics().TablespaceUsage()
+	if len(usage) != 2 {
+		t.Fatalf("expected 2 tablespaces, got %d", len(usage))
+	}
+	if usage[0].Name != "archive" || usage[0].PVCName != "pg-2-tbs-archive" {
+		t.Errorf("expected the fullest archive PVC pg-2-tbs-archive, got %s/%s", usage[0].Name, usage[0].PVCName)
+	}
+	if percent := usage[0].UsagePercent(); percent != 90 {
+		t.Errorf("expected archive usage 90%%, got %.1f%%", percent)
+	}
+	if usage[1].Name != "hot" || usage[1].UsagePercent() != 50 {
+		t.Errorf("expected hot at 50%%, got %s at %.1f%%", usage[1].Name, usage[1].UsagePercent())
+	}
+}
+
+func TestClusterMetrics_HighestReplicaUsagePercentIgnoresTablespaces(t *testing.T) {
+	if percent := tablespaceClusterMetrics().HighestReplicaUsagePercent("pg-1"); percent != 60 {
+		t.Errorf("expected the replica WAL PVC at 60%%, got %.1f%%", percent)
+	}
+}
+
+func TestClusterMetrics_InstanceUsagePercent(t *testing.T) {
+	usage := tablespaceClusterMetrics().InstanceUsagePercent()
+	expected := map[string]float64{"pg-1": 40, "pg-2": 90}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected %v, got %v", expected, usage)
+	}
+}
+
+func TestClusterMetrics_LargestInstanceUsedBytes(t *testing.T) {
+	if used := tablespaceClusterMetrics().LargestInstanceUsedBytes(); used != 985 {
+		t.Errorf("expected pg-2 to use 985 bytes, got %d", used)
+	}
+}
+
+func TestClusterMetrics_DataUsage(t *testing.T) {
+	used, capacity := tablespaceClusterMetrics().DataUsage()
+	if used != 90 || capacity != 200 {
+		t.Errorf("expected 90 of 200 bytes on the data PVCs, got %d of %d", used, capacity)
+	}
+}
+
+func TestClusterMetrics_LeastFreeBytes(t *testing.T) {
+	if free := tablespaceClusterMetrics().LeastFreeBytes(false); free != 50 {
+		t.Errorf("expected 50 bytes free on pg-2, got %d", free)
+	}
+	if free := tablespaceClusterMetrics().LeastFreeBytes(true); free != 20 {
+		t.Errorf("expected 20 bytes free on pg-2-wal, got %d", free)
+	}
+	if free := (&ClusterMetrics{}).LeastFreeBytes(true); free != 0 {
+		t.Errorf("expected 0 without PVCs, got %d", free)
+	}
+}
+
+func TestClusterMetrics_WALUsage(t *testing.T) {
+	usage := tablespaceClusterMetrics().WALUsage()
+	if usage == nil || usage.PVCName != "pg-2-wal" || usage.UsagePercent() != 60 {
+		t.Errorf("expected the fullest WAL PVC pg-2-wal at 60%%, got %+v", usage)
+	}
+	if usage := (&ClusterMetrics{PVCMetrics: []PVCMetrics{{PVCName: "pg-1"}}}).WALUsage(); usage != nil {
+		t.Errorf("expected no WAL usage without WAL PVCs, got %+v", usage)
+	}
+}
+
+func TestCollector_ClassifyPVCs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name: "pg-1", Namespace: "db",
+			Labels: map[string]string{"cnpg.io/cluster": "pg", cnpg.LabelPVCRole: "PG_DATA"},
+		}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name: "pg-1-wal", Namespace: "db",
+			Labels: map[string]string{"cnpg.io/cluster": "pg", cnpg.LabelPVCRole: cnpg.PVCRoleWAL},
+		}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name: "pg-1-tbs-archive", Namespace: "db",
+			Labels: map[string]string{
+				"cnpg.io/cluster":        "pg",
+				cnpg.LabelPVCRole:        cnpg.PVCRoleTablespace,
+				cnpg.LabelTablespaceName: "archive",
+			},
+		}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name: "pg-1-tbs-scratch", Namespace: "db",
+			Labels: map[string]string{
+				"cnpg.io/cluster":        "pg",
+				cnpg.LabelPVCRole:        cnpg.PVCRoleTablespace,
+				cnpg.LabelTablespaceName: "scratch",
+			},
+			Annotations: map[string]string{annotations.AnnotationIgnore: "true"},
+		}},
+	).Build()
+
+	pvcMetrics := []PVCMetrics{
+		{PVCName: "pg-1"}, {PVCName: "pg-1-tbs-scratch"}, {PVCName: "pg-1-wal"}, {PVCName: "pg-1-tbs-archive"},
+	}
+	pvcMetrics = (&Collector{client: c}).classifyPVCs(context.Background(), "pg", "db", pvcMetrics)
+	if len(pvcMetrics) != 3 {
+		t.Fatalf("expected the ignored PVC to be dropped, got %+v", pvcMetrics)
+	}
+	if pvcMetrics[0].WALVolume || pvcMetrics[0].Tablespace != "" {
+		t.Errorf("expected pg-1 to be a data PVC, got %+v", pvcMetrics[0])
+	}
+	if !pvcMetrics[1].WALVolume {
+		t.Errorf("expected pg-1-wal to be a WAL PVC")
+	}
+	if pvcMetrics[2].Tablespace != "archive" {
+		t.Errorf("expected pg-1-tbs-archive in archive, got %q", pvcMetrics[2].Tablespace)
+	}
+
+	unclassified := (&Collector{}).classifyPVCs(context.Background(), "pg", "db", []PVCMetrics{{PVCName: "pg-1-wal"}})
+	if unclassified[0].WALVolume {
+		t.Errorf("expected no classification without a client")
+	}
+}
+
+func TestClusterMetrics_ZoneUsage(t *testing.T) {
+	m := tablespaceClusterMetrics()
+	m.PVCMetrics = append(m.PVCMetrics, PVCMetrics{PVCName: "pg-3", PodName: "pg-3", UsedBytes: 70, CapacityBytes: 100})
+	for i := range m.PVCMetrics {
+		switch m.PVCMetrics[i].PodName {
+		case "pg-1":
+			m.PVCMetrics[i].Zone, m.PVCMetrics[i].Region = "eu-west-1b", "eu-west-1"
+		case "pg-2":
+			m.PVCMetrics[i].Zone, m.PVCMetrics[i].Region = "eu-west-1a", "eu-west-1"
+		}
+	}
+
+	zones := m.ZoneUsage()
+	if len(zones) != 2 {
+		t.Fatalf("expected 2 zones without the unlabeled pg-3, got %+v", zones)
+	}
+	if zones[0].Zone != "eu-west-1a" || !reflect.DeepEqual(zones[0].Instances, []string{"pg-2"}) {
+		t.Errorf("expected eu-west-1a with pg-2 first, got %+v", zones[0])
+	}
+	if zones[0].UsedBytes != 80 || zones[0].CapacityBytes != 150 {
+		t.Errorf("expected the data and WAL volumes of pg-2 without tablespaces, got %+v", zones[0])
+	}
+	if percent := zones[1].UsagePercent(); percent != 25 {
+		t.Errorf("expected eu-west-1b at 25%%, got %.1f%%", percent)
+	}
+	if (&ClusterMetrics{}).ZoneUsage() == nil {
+		t.Errorf("expected an empty, non-nil slice without zones")
+	}
+}
+
+func TestCollector_LabelTopology(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name: "node-a",
+			Labels: map[string]string{
+				corev1.LabelTopologyZone:   "eu-west-1a",
+				corev1.LabelTopologyRegion: "eu-west-1",
+			},
+		}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+	).Build()
+
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pg-1"}, Spec: corev1.PodSpec{NodeName: "node-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pg-2"}, Spec: corev1.PodSpec{NodeName: "node-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pg-3"}, Spec: corev1.PodSpec{NodeName: "node-gone"}},
+	}
+	pvcMetrics := []PVCMetrics{
+		{PVCName: "pg-1", PodName: "pg-1"},
+		{PVCName: "pg-1-wal", PodName: "pg-1", NodeName: "node-a"},
+		{PVCName: "pg-2", PodName: "pg-2"},
+		{PVCName: "pg-3", PodName: "pg-3"},
+	}
+	(&Collector{client: c}).labelTopology(context.Background(), pvcMetrics, pods)
+	for _, pvc := range pvcMetrics[:2] {
+		if pvc.Zone != "eu-west-1a" || pvc.Region != "eu-west-1" {
+			t.Errorf("expected %s in eu-west-1a, got %q/%q", pvc.PVCName, pvc.Region, pvc.Zone)
+		}
+	}
+	for _, pvc := range pvcMetrics[2:] {
+		if pvc.Zone != "" || pvc.Region != "" {
+			t.Errorf("expected no topology for %s, got %q/%q", pvc.PVCName, pvc.Region, pvc.Zone)
+		}
+	}
+}
+
+func TestCollector_SetCommandRunnerNilDisablesExec(t *testing.T) {
+	c := &Collector{execCollector: NewExecCollectorWithRunner(nil)}
+	c.SetCommandRunner(nil)
+	if c.execCollector != nil {
+		t.Fatal("expected a nil runner to disable exec collection")
+	}
+
+	pods := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Namespace: "db"}}}
+	if _, err := c.CollectClusterMetricsFrom(context.Background(), SourceExec, "pg", "db", pods); err == nil {
+		t.Error("expected the exec source to be unavailable")
+	}
+}
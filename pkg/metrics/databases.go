@@ -0,0 +1,87 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+)
+
+// databaseSizeQuery lists the largest databases of an instance
+const databaseSizeQuery = `SELECT datname, pg_database_size(datname) FROM pg_database
+WHERE datallowconn ORDER BY 2 DESC LIMIT %d`
+
+// DatabaseSize is the size of one database of a cluster
+type DatabaseSize struct {
+	Name      string
+	SizeBytes int64
+}
+
+// DatabaseSizeCollector lists the largest databases of a cluster by running psql inside
+// the postgres container. Like ArchiverCollector it needs pod exec.
+type DatabaseSizeCollector struct {
+	runner runner.CommandRunner
+}
+
+// NewDatabaseSizeCollector creates a collector that runs psql through the given command runner
+func NewDatabaseSizeCollector(commandRunner runner.CommandRunner) *DatabaseSizeCollector {
+	return &DatabaseSizeCollector{runner: commandRunner}
+}
+
+// TopDatabases returns the limit largest databases on a pod, largest first
+func (d *DatabaseSizeCollector) TopDatabases(ctx context.Context, pod *corev1.Pod, limit int) ([]DatabaseSize, error) {
+	start := time.Now()
+	defer func() {
+		MetricsCollectionDuration.WithLabelValues("psql_database_size").Observe(time.Since(start).Seconds())
+	}()
+
+	query := fmt.Sprintf(databaseSizeQuery, limit)
+	command := []string{"psql", "-X", "-A", "-t", "-q", "-d", "postgres", "-F", "|", "-c", query}
+	stdout, err := d.runner.Run(ctx, pod, runner.PreferredContainer(pod), command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database sizes on %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return parseDatabaseSizes(stdout)
+}
+
+// parseDatabaseSizes parses the unaligned psql output of databaseSizeQuery
+func parseDatabaseSizes(output string) ([]DatabaseSize, error) {
+	var sizes []DatabaseSize
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		// Database names may contain the separator, the size never does
+		sep := strings.LastIndex(line, "|")
+		if sep < 0 {
+			return nil, fmt.Errorf("unexpected database size output %q", line)
+		}
+		size, err := strconv.ParseInt(line[sep+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid database size %q", line[sep+1:])
+		}
+		sizes = append(sizes, DatabaseSize{Name: line[:sep], SizeBytes: size})
+	}
+	return sizes, nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDatabaseSizes(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		expected  []DatabaseSize
+		expectErr bool
+	}{
+		{
+			name:   "largest first",
+			output: "app|12884901888\npostgres|7700000\n",
+			expected: []DatabaseSize{
+				{Name: "app", SizeBytes: 12884901888},
+				{Name: "postgres", SizeBytes: 7700000},
+			},
+		},
+		{
+			name:     "name containing the separator",
+			output:   "odd|name|4096",
+			expected: []DatabaseSize{{Name: "odd|name", SizeBytes: 4096}},
+		},
+		{
+			name:   "no databases",
+			output: "\n",
+		},
+		{
+			name:      "missing size",
+			output:    "app",
+			expectErr: true,
+		},
+		{
+			name:      "invalid size",
+			output:    "app|large",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sizes, err := parseDatabaseSizes(tt.output)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(sizes, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, sizes)
+			}
+		})
+	}
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package metrics
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"strconv"
@@ -25,32 +24,33 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
 )
 
 // ExecCollector collects storage metrics by executing commands inside pods
 // This is used as a fallback when kubelet stats don't provide volume metrics
 // (e.g., for local-path provisioner volumes)
 type ExecCollector struct {
-	clientset  *kubernetes.Clientset
-	restConfig *rest.Config
+	runner runner.CommandRunner
 }
 
 // NewExecCollector creates a new exec-based metrics collector
 func NewExecCollector(restConfig *rest.Config) (*ExecCollector, error) {
-	clientset, err := kubernetes.NewForConfig(restConfig)
+	execRunner, err := runner.NewExecRunner(restConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kubernetes clientset: %w", err)
 	}
 
-	return &ExecCollector{
-		clientset:  clientset,
-		restConfig: restConfig,
-	}, nil
+	return NewExecCollectorWithRunner(execRunner), nil
+}
+
+// NewExecCollectorWithRunner creates a new metrics collector that runs df through the given command runner
+func NewExecCollectorWithRunner(commandRunner runner.CommandRunner) *ExecCollector {
+	return &ExecCollector{runner: commandRunner}
 }
 
 // DfOutput represents parsed output from the df command
@@ -65,7 +65,9 @@ type DfOutput struct {
 
 // CollectPVCMetricsViaExec collects metrics for PVCs by executing df inside the pods
 // This is used when kubelet stats don't provide volume metrics
-func (e *ExecCollector) CollectPVCMetricsViaExec(ctx context.Context, pods []corev1.Pod) ([]PVCMetrics, error) {
+func (e *ExecCollector) CollectPVCMetricsViaExec(ctx context.Context, pods []corev1.Pod) (_ []PVCMetrics, err error) {
+	ctx, span := tracing.Start(ctx, "metrics.CollectPVCMetricsViaExec")
+	defer func() { tracing.End(span, err) }()
 	logger := log.FromContext(ctx)
 	var allMetrics []PVCMetrics
 
@@ -164,7 +166,7 @@ func (e *ExecCollector) execDfInPod(ctx context.Context, pod corev1.Pod) ([]DfOu
 
 	// Use df with -B1 to get bytes, -P for POSIX format (single line per filesystem)
 	command := []string{"df", "-B1", "-P"}
-	stdout, _, err := e.execInPod(ctx, pod, command)
+	stdout, err := e.execInPod(ctx, pod, command)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +177,7 @@ func (e *ExecCollector) execDfInPod(ctx context.Context, pod corev1.Pod) ([]DfOu
 // execDfInodesInPod executes df -i to get inode stats for a specific mount point
 func (e *ExecCollector) execDfInodesInPod(ctx context.Context, pod corev1.Pod, mountPath string) (*DfOutput, error) {
 	command := []string{"df", "-i", "-P", mountPath}
-	stdout, _, err := e.execInPod(ctx, pod, command)
+	stdout, err := e.execInPod(ctx, pod, command)
 	if err != nil {
 		return nil, err
 	}
@@ -187,56 +189,14 @@ func (e *ExecCollector) execDfInodesInPod(ctx context.Context, pod corev1.Pod, m
 	return nil, nil
 }
 
-// execInPod executes a command inside a pod and returns stdout/stderr
-//
-//nolint:unparam // stderr return kept for debugging purposes
+// execInPod executes a command inside a pod and returns stdout
 func (e *ExecCollector) execInPod(
 	ctx context.Context,
 	pod corev1.Pod,
 	command []string,
-) (string, string, error) {
-	// Find the first container (typically the postgres container for CNPG)
-	containerName := ""
-	for _, container := range pod.Spec.Containers {
-		// Prefer the postgres container if available
-		if container.Name == "postgres" {
-			containerName = container.Name
-			break
-		}
-		if containerName == "" {
-			containerName = container.Name
-		}
-	}
-
-	req := e.clientset.CoreV1().RESTClient().Post().
-		Resource("pods").
-		Name(pod.Name).
-		Namespace(pod.Namespace).
-		SubResource("exec").
-		VersionedParams(&corev1.PodExecOptions{
-			Container: containerName,
-			Command:   command,
-			Stdin:     false,
-			Stdout:    true,
-			Stderr:    true,
-			TTY:       false,
-		}, scheme.ParameterCodec)
-
-	exec, err := remotecommand.NewSPDYExecutor(e.restConfig, "POST", req.URL())
-	if err != nil {
-		return "", "", fmt.Errorf("failed to create executor: %w", err)
-	}
-
-	var stdout, stderr bytes.Buffer
-	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
-		Stdout: &stdout,
-		Stderr: &stderr,
-	})
-	if err != nil {
-		return "", "", fmt.Errorf("failed to execute command: %w (stderr: %s)", err, stderr.String())
-	}
-
-	return stdout.String(), stderr.String(), nil
+) (string, error) {
+	// Prefer the postgres container (typical for CNPG), otherwise the first container
+	return e.runner.Run(ctx, &pod, runner.PreferredContainer(&pod), command)
 }
 
 // parseDfOutput parses the output of df -B1 -P
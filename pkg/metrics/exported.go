@@ -0,0 +1,260 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultJanitorInterval is how often clusters that no longer exist are pruned
+const DefaultJanitorInterval = 10 * time.Minute
+
+// ClusterRef identifies a CNPG cluster in the labels of its series
+type ClusterRef struct {
+	Namespace string
+	Name      string
+}
+
+// String returns namespace/name
+func (c ClusterRef) String() string {
+	return c.Namespace + "/" + c.Name
+}
+
+// pvcSeries are the pvc and instance labels of a PVC's series
+type pvcSeries struct {
+	pvc      string
+	instance string
+}
+
+// exportedPVCs are the PVC series recorded for each cluster by the latest collection
+var exportedPVCs = struct {
+	sync.Mutex
+	byCluster map[ClusterRef]map[pvcSeries]bool
+}{byCluster: make(map[ClusterRef]map[pvcSeries]bool)}
+
+// setExportedPVCs records the PVC series of a cluster's latest collection and deletes
+// those of PVCs and instances it no longer has, e.g. after a scale down
+func setExportedPVCs(cluster, namespace string, pvcs []PVCMetrics) {
+	current := make(map[pvcSeries]bool, len(pvcs))
+	instances := make(map[string]bool, len(pvcs))
+	for _, pvc := range pvcs {
+		current[pvcSeries{pvc: pvc.PVCName, instance: pvc.PodName}] = true
+		instances[pvc.PodName] = true
+	}
+
+	ref := ClusterRef{Namespace: namespace, Name: cluster}
+	exportedPVCs.Lock()
+	defer exportedPVCs.Unlock()
+	for series := range exportedPVCs.byCluster[ref] {
+		if current[series] {
+			continue
+		}
+		DeletePVCMetrics(cluster, namespace, series.pvc, series.instance)
+		if !instances[series.instance] {
+			DeleteWALMetrics(cluster, namespace, series.instance)
+		}
+	}
+	exportedPVCs.byCluster[ref] = current
+}
+
+// DeleteClusterMetrics removes every gauge of a cluster that was deleted or is no
+// longer managed. Counters are kept, so a cluster matched again continues them
+func DeleteClusterMetrics(cluster, namespace string) {
+	exportedPVCs.Lock()
+	delete(exportedPVCs.byCluster, ClusterRef{Namespace: namespace, Name: cluster})
+	exportedPVCs.Unlock()
+
+	labels := prometheus.Labels{"cluster": cluster, "namespace": namespace}
+	for _, gauge := range []*prometheus.GaugeVec{
+		PVCUsageBytes,
+		PVCCapacityBytes,
+		PVCUsagePercent,
+		WALDirectoryBytes,
+		WALFilesCount,
+		CircuitBreakerState,
+		PlannedExpansionBytes,
+		RemediationBytesFreed,
+		ExpansionCount30d,
+		ExpansionCumulativeGrowthBytes,
+		StorageGrowthBytesPerHour,
+		StorageGrowthAnomaly,
+		TablespaceUsagePercent,
+		PoolerVolumeUsagePercent,
+		ZoneUsageBytes,
+		ZoneCapacityBytes,
+		PVCTopologyInfo,
+		ClusterWritable,
+	} {
+		gauge.DeletePartialMatch(labels)
+	}
+	// Clusters reached through a ClusterConnection share these, under their connection
+	for _, gauge := range []*prometheus.GaugeVec{
+		ClusterHealthScore,
+		StorageSLOObjective,
+		StorageSLOErrorBudgetRemaining,
+	} {
+		gauge.DeleteLabelValues(cluster, namespace, "")
+	}
+	DeleteBackupMetrics(cluster, namespace)
+}
+
+// ExportedClusters tracks the clusters each owner, e.g. a StoragePolicy, exports series
+// for, so the series of clusters that are deleted or no longer matched are removed
+// instead of lingering until the manager restarts. A cluster's series are kept while
+// any owner still exports them
+type ExportedClusters struct {
+	mu     sync.Mutex
+	owners map[string]map[ClusterRef]bool
+}
+
+// NewExportedClusters creates an empty tracker
+func NewExportedClusters() *ExportedClusters {
+	return &ExportedClusters{owners: make(map[string]map[ClusterRef]bool)}
+}
+
+// Set records the clusters an owner exports series for, and deletes the series of those
+// it exported before and no owner exports anymore. No clusters forget a deleted owner.
+// It returns the clusters whose series were deleted
+func (e *ExportedClusters) Set(owner string, clusters []ClusterRef) []ClusterRef {
+	current := make(map[ClusterRef]bool, len(clusters))
+	for _, cluster := range clusters {
+		current[cluster] = true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	previous := e.owners[owner]
+	if len(current) == 0 {
+		delete(e.owners, owner)
+	} else {
+		e.owners[owner] = current
+	}
+	return e.deleteUnexported(previous)
+}
+
+// Prune drops the clusters exists reports as gone from every owner and deletes their series
+func (e *ExportedClusters) Prune(exists func(ClusterRef) bool) []ClusterRef {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	gone := make(map[ClusterRef]bool)
+	for owner, clusters := range e.owners {
+		for cluster := range clusters {
+			if !exists(cluster) {
+				gone[cluster] = true
+				delete(clusters, cluster)
+			}
+		}
+		if len(clusters) == 0 {
+			delete(e.owners, owner)
+		}
+	}
+	return e.deleteUnexported(gone)
+}
+
+// deleteUnexported deletes the series of the candidates no owner exports. e.mu must be held
+func (e *ExportedClusters) deleteUnexported(candidates map[ClusterRef]bool) []ClusterRef {
+	var deleted []ClusterRef
+	for cluster := range candidates {
+		if e.exported(cluster) {
+			continue
+		}
+		DeleteClusterMetrics(cluster.Name, cluster.Namespace)
+		deleted = append(deleted, cluster)
+	}
+	sortClusterRefs(deleted)
+	return deleted
+}
+
+// exported returns true if any owner exports the cluster's series. e.mu must be held
+func (e *ExportedClusters) exported(cluster ClusterRef) bool {
+	for _, clusters := range e.owners {
+		if clusters[cluster] {
+			return true
+		}
+	}
+	return false
+}
+
+func sortClusterRefs(clusters []ClusterRef) {
+	slices.SortFunc(clusters, func(a, b ClusterRef) int {
+		return strings.Compare(a.String(), b.String())
+	})
+}
+
+// Janitor periodically deletes the series of clusters that no longer exist, for
+// clusters deleted while no reconcile of their owner noticed it
+type Janitor struct {
+	exported *ExportedClusters
+	list     func(ctx context.Context) ([]ClusterRef, error)
+	interval time.Duration
+}
+
+// NewJanitor creates a janitor pruning the clusters list no longer returns. An interval
+// of zero uses DefaultJanitorInterval
+func NewJanitor(
+	exported *ExportedClusters,
+	list func(ctx context.Context) ([]ClusterRef, error),
+	interval time.Duration,
+) *Janitor {
+	if interval <= 0 {
+		interval = DefaultJanitorInterval
+	}
+	return &Janitor{exported: exported, list: list, interval: interval}
+}
+
+// Start prunes every interval until the context is cancelled
+func (j *Janitor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		j.Prune(ctx)
+	}
+}
+
+// Prune deletes the series of exported clusters that no longer exist. Nothing is
+// deleted when the clusters cannot be listed
+func (j *Janitor) Prune(ctx context.Context) []ClusterRef {
+	logger := logf.FromContext(ctx).WithName("metrics-janitor")
+	clusters, err := j.list(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to list clusters, keeping their metrics")
+		return nil
+	}
+	existing := make(map[ClusterRef]bool, len(clusters))
+	for _, cluster := range clusters {
+		existing[cluster] = true
+	}
+
+	pruned := j.exported.Prune(func(cluster ClusterRef) bool { return existing[cluster] })
+	if len(pruned) > 0 {
+		logger.Info("Deleted the metrics of clusters that no longer exist", "clusters", pruned)
+	}
+	return pruned
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// seriesOf counts the series of a gauge for a cluster
+func seriesOf(gauge *prometheus.GaugeVec, cluster, namespace string) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		gauge.Collect(ch)
+		close(ch)
+	}()
+	count := 0
+	for m := range ch {
+		var metric dto.Metric
+		if err := m.Write(&metric); err != nil {
+			continue
+		}
+		labels := make(map[string]string, len(metric.GetLabel()))
+		for _, pair := range metric.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+		if labels["cluster"] == cluster && labels["namespace"] == namespace {
+			count++
+		}
+	}
+	return count
+}
+
+func recordCluster(cluster, namespace string) {
+	RecordPVCMetrics(cluster, namespace, cluster+"-1", cluster+"-1", 50, 100)
+	RecordWALMetrics(cluster, namespace, cluster+"-1", 10, 2)
+	SetCircuitBreakerState(cluster, namespace, false)
+	RecordBackupMetrics(cluster, namespace, nil, nil, true, true, true)
+	score := int32(90)
+	SetClusterHealthScore(cluster, namespace, "", &score)
+}
+
+func TestDeleteClusterMetrics(t *testing.T) {
+	recordCluster("gone", "exported")
+	recordCluster("kept", "exported")
+
+	DeleteClusterMetrics("gone", "exported")
+
+	for _, gauge := range []*prometheus.GaugeVec{PVCUsageBytes, WALDirectoryBytes, CircuitBreakerState,
+		BackupHealthy, ClusterHealthScore} {
+		if n := seriesOf(gauge, "gone", "exported"); n != 0 {
+			t.Errorf("expected the series of the deleted cluster to be removed, got %d", n)
+		}
+		if n := seriesOf(gauge, "kept", "exported"); n != 1 {
+			t.Errorf("expected the series of other clusters to be kept, got %d", n)
+		}
+	}
+	DeleteClusterMetrics("kept", "exported")
+}
+
+func TestSetExportedPVCs(t *testing.T) {
+	pvcs := []PVCMetrics{
+		{PVCName: "pg-1", PodName: "pg-1"},
+		{PVCName: "pg-1-wal", PodName: "pg-1"},
+		{PVCName: "pg-2", PodName: "pg-2"},
+	}
+	for _, pvc := range pvcs {
+		RecordPVCMetrics("pg", "scaled", pvc.PVCName, pvc.PodName, 50, 100)
+		RecordWALMetrics("pg", "scaled", pvc.PodName, 10, 2)
+	}
+	setExportedPVCs("pg", "scaled", pvcs)
+
+	// The second instance is scaled down
+	setExportedPVCs("pg", "scaled", pvcs[:2])
+
+	if n := seriesOf(PVCUsageBytes, "pg", "scaled"); n != 2 {
+		t.Errorf("expected the PVC of the removed instance to be deleted, got %d series", n)
+	}
+	if n := seriesOf(WALDirectoryBytes, "pg", "scaled"); n != 1 {
+		t.Errorf("expected the WAL series of the removed instance to be deleted, got %d series", n)
+	}
+	if v := testutil.ToFloat64(PVCUsageBytes.WithLabelValues("pg", "scaled", "pg-1-wal", "pg-1")); v != 50 {
+		t.Errorf("expected the remaining PVCs to be kept, got %v", v)
+	}
+	DeleteClusterMetrics("pg", "scaled")
+}
+
+func TestExportedClusters_Set(t *testing.T) {
+	a := ClusterRef{Namespace: "tracked", Name: "a"}
+	b := ClusterRef{Namespace: "tracked", Name: "b"}
+	recordCluster(a.Name, a.Namespace)
+	recordCluster(b.Name, b.Namespace)
+
+	exported := NewExportedClusters()
+	exported.Set("StoragePolicy/db/one", []ClusterRef{a, b})
+	exported.Set("BackupPolicy/db/two", []ClusterRef{b})
+
+	if deleted := exported.Set("StoragePolicy/db/one", []ClusterRef{a}); len(deleted) != 0 {
+		t.Errorf("expected a cluster another policy exports to be kept, got %v", deleted)
+	}
+	if deleted := exported.Set("BackupPolicy/db/two", nil); len(deleted) != 1 || deleted[0] != b {
+		t.Errorf("expected the cluster no policy exports anymore to be deleted, got %v", deleted)
+	}
+	if n := seriesOf(PVCUsageBytes, b.Name, b.Namespace); n != 0 {
+		t.Errorf("expected the series of %s to be deleted, got %d", b, n)
+	}
+	if n := seriesOf(PVCUsageBytes, a.Name, a.Namespace); n != 1 {
+		t.Errorf("expected the series of %s to be kept, got %d", a, n)
+	}
+	DeleteClusterMetrics(a.Name, a.Namespace)
+}
+
+func TestJanitor_Prune(t *testing.T) {
+	a := ClusterRef{Namespace: "janitor", Name: "a"}
+	b := ClusterRef{Namespace: "janitor", Name: "b"}
+	recordCluster(a.Name, a.Namespace)
+	recordCluster(b.Name, b.Namespace)
+	exported := NewExportedClusters()
+	exported.Set("StoragePolicy/db/one", []ClusterRef{a, b})
+
+	failing := NewJanitor(exported, func(context.Context) ([]ClusterRef, error) {
+		return nil, errors.New("the server could not find the requested resource")
+	}, 0)
+	if pruned := failing.Prune(context.Background()); len(pruned) != 0 {
+		t.Errorf("expected nothing to be pruned when clusters cannot be listed, got %v", pruned)
+	}
+
+	janitor := NewJanitor(exported, func(context.Context) ([]ClusterRef, error) {
+		return []ClusterRef{a}, nil
+	}, 0)
+	if pruned := janitor.Prune(context.Background()); len(pruned) != 1 || pruned[0] != b {
+		t.Errorf("expected the deleted cluster to be pruned, got %v", pruned)
+	}
+	if n := seriesOf(CircuitBreakerState, b.Name, b.Namespace); n != 0 {
+		t.Errorf("expected the series of the deleted cluster to be removed, got %d", n)
+	}
+	if deleted := exported.Set("StoragePolicy/db/one", nil); len(deleted) != 1 || deleted[0] != a {
+		t.Errorf("expected only the remaining cluster to be tracked, got %v", deleted)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Labels a latency query must keep on its samples to identify the PVC
+const (
+	latencyNamespaceLabel = "namespace"
+	latencyPVCLabel       = "persistentvolumeclaim"
+)
+
+// LatencyQuerier reads volume latency from the Prometheus HTTP API named by a policy's
+// performanceTuning. Unlike the other collectors it needs no access to the pods
+type LatencyQuerier struct {
+	httpClient *http.Client
+}
+
+// NewLatencyQuerier creates a querier for Prometheus instant queries
+func NewLatencyQuerier() *LatencyQuerier {
+	return &LatencyQuerier{httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// prometheusResponse is the body of a Prometheus /api/v1/query response
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []any             `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// VolumeLatency runs an instant query against the Prometheus at prometheusURL and returns
+// the latency in milliseconds of the PVCs in namespace, keyed by PVC name. Samples of
+// other namespaces or without a persistentvolumeclaim label are ignored; the highest
+// sample wins when the query returns several per PVC
+func (q *LatencyQuerier) VolumeLatency(
+	ctx context.Context,
+	prometheusURL, query, namespace string,
+) (map[string]float64, error) {
+	start := time.Now()
+	defer func() {
+		MetricsCollectionDuration.WithLabelValues("prometheus_latency").Observe(time.Since(start).Seconds())
+	}()
+
+	endpoint := strings.TrimSuffix(prometheusURL, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create latency query request: %w", err)
+	}
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latency from %s: %w", prometheusURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var body prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode latency query response (status %d): %w", resp.StatusCode, err)
+	}
+	return parseVolumeLatency(body, namespace)
+}
+
+// parseVolumeLatency extracts the latency of the PVCs in namespace from a query response
+func parseVolumeLatency(body prometheusResponse, namespace string) (map[string]float64, error) {
+	if body.Status != "success" {
+		return nil, fmt.Errorf("latency query failed: %s: %s", body.ErrorType, body.Error)
+	}
+	if body.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("latency query returned a %s, expected a vector", body.Data.ResultType)
+	}
+
+	latency := make(map[string]float64)
+	for _, sample := range body.Data.Result {
+		pvc := sample.Metric[latencyPVCLabel]
+		if pvc == "" || sample.Metric[latencyNamespaceLabel] != namespace {
+			continue
+		}
+		if len(sample.Value) != 2 {
+			return nil, fmt.Errorf("unexpected latency sample %v", sample.Value)
+		}
+		text, ok := sample.Value[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected latency sample %v", sample.Value)
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid latency %q", text)
+		}
+		if current, found := latency[pvc]; !found || value > current {
+			latency[pvc] = value
+		}
+	}
+	return latency, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestVolumeLatency(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		expected  map[string]float64
+		expectErr bool
+	}{
+		{
+			name:   "samples of the namespace",
+			status: http.StatusOK,
+			body: `{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"namespace":"db","persistentvolumeclaim":"pg-1"},"value":[1700000000,"12.5"]},
+				{"metric":{"namespace":"db","persistentvolumeclaim":"pg-2"},"value":[1700000000,"40"]},
+				{"metric":{"namespace":"db","persistentvolumeclaim":"pg-2"},"value":[1700000000,"55"]},
+				{"metric":{"namespace":"other","persistentvolumeclaim":"pg-1"},"value":[1700000000,"90"]},
+				{"metric":{"namespace":"db"},"value":[1700000000,"90"]}
+			]}}`,
+			expected: map[string]float64{"pg-1": 12.5, "pg-2": 55},
+		},
+		{
+			name:     "no samples",
+			status:   http.StatusOK,
+			body:     `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			expected: map[string]float64{},
+		},
+		{
+			name:      "query error",
+			status:    http.StatusBadRequest,
+			body:      `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			expectErr: true,
+		},
+		{
+			name:      "range vector",
+			status:    http.StatusOK,
+			body:      `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			expectErr: true,
+		},
+		{
+			name:   "invalid value",
+			status: http.StatusOK,
+			body: `{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"namespace":"db","persistentvolumeclaim":"pg-1"},"value":[1700000000,"fast"]}
+			]}}`,
+			expectErr: true,
+		},
+		{
+			name:      "not prometheus",
+			status:    http.StatusNotFound,
+			body:      `not found`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/query" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				query = r.URL.Query().Get("query")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			latency, err := NewLatencyQuerier().VolumeLatency(context.Background(), server.URL+"/",
+				`latency{namespace="db"}`, "db")
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("VolumeLatency() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if query != `latency{namespace="db"}` {
+				t.Errorf("query = %q", query)
+			}
+			if !tt.expectErr && !reflect.DeepEqual(latency, tt.expected) {
+				t.Errorf("VolumeLatency() = %v, expected %v", latency, tt.expected)
+			}
+		})
+	}
+}
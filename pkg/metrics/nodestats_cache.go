@@ -0,0 +1,107 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultNodeStatsTTL is how long a node's kubelet stats summary is reused. It is
+// shorter than the StoragePolicy requeue interval, so each reconcile sees fresh stats
+// while the clusters processed during one reconcile share a fetch.
+const DefaultNodeStatsTTL = 15 * time.Second
+
+// nodeStatsCache holds kubelet stats summaries per node for a TTL, so a node's
+// summary is fetched at most once per interval no matter how many clusters run on it
+type nodeStatsCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*nodeStatsEntry
+}
+
+// nodeStatsEntry is the cached summary of one node
+type nodeStatsEntry struct {
+	// mu serializes fetches of the node, so concurrent callers share one request
+	mu        sync.Mutex
+	summary   *KubeletStatsSummary
+	fetchedAt time.Time
+
+	// lastUsed is guarded by nodeStatsCache.mu
+	lastUsed time.Time
+}
+
+// newNodeStatsCache creates a cache; a zero TTL disables caching
+func newNodeStatsCache(ttl time.Duration) *nodeStatsCache {
+	return &nodeStatsCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*nodeStatsEntry),
+	}
+}
+
+// get returns the cached summary of a node, calling fetch when it is missing or
+// expired. Errors are not cached.
+func (c *nodeStatsCache) get(
+	ctx context.Context,
+	nodeName string,
+	fetch func(context.Context, string) (*KubeletStatsSummary, error),
+) (*KubeletStatsSummary, error) {
+	if c.ttl <= 0 {
+		return fetch(ctx, nodeName)
+	}
+
+	entry := c.entry(nodeName)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.summary != nil && c.now().Sub(entry.fetchedAt) < c.ttl {
+		return entry.summary, nil
+	}
+	summary, err := fetch(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	entry.summary = summary
+	entry.fetchedAt = c.now()
+	return summary, nil
+}
+
+// entry returns the entry of a node, pruning entries of nodes that have not been
+// asked about for a while, such as deleted nodes
+func (c *nodeStatsCache) entry(nodeName string) *nodeStatsEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for name, entry := range c.entries {
+		if now.Sub(entry.lastUsed) > 10*c.ttl {
+			delete(c.entries, name)
+		}
+	}
+
+	entry, ok := c.entries[nodeName]
+	if !ok {
+		entry = &nodeStatsEntry{}
+		c.entries[nodeName] = entry
+	}
+	entry.lastUsed = now
+	return entry
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNodeStatsCache(t *testing.T) {
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	cache := newNodeStatsCache(15 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	var fetches atomic.Int32
+	var failNext atomic.Bool
+	fetch := func(_ context.Context, nodeName string) (*KubeletStatsSummary, error) {
+		fetches.Add(1)
+		if failNext.Swap(false) {
+			return nil, errors.New("kubelet unreachable")
+		}
+		return &KubeletStatsSummary{Node: NodeStats{NodeName: nodeName}}, nil
+	}
+	ctx := context.Background()
+
+	for range 3 {
+		summary, err := cache.get(ctx, "node-1", fetch)
+		if err != nil || summary.Node.NodeName != "node-1" {
+			t.Fatalf("unexpected result %+v, %v", summary, err)
+		}
+	}
+	if _, err := cache.get(ctx, "node-2", fetch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("expected one fetch per node within the TTL, got %d", got)
+	}
+
+	// Expired entries are refetched, and failures are not cached
+	now = now.Add(16 * time.Second)
+	failNext.Store(true)
+	if _, err := cache.get(ctx, "node-1", fetch); err == nil {
+		t.Error("expected the fetch error")
+	}
+	if _, err := cache.get(ctx, "node-1", fetch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fetches.Load(); got != 4 {
+		t.Errorf("expected a refetch after expiry and after an error, got %d fetches", got)
+	}
+
+	// Nodes that are no longer asked about are pruned
+	now = now.Add(time.Hour)
+	if _, err := cache.get(ctx, "node-1", fetch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.entries["node-2"]; ok {
+		t.Error("expected the unused node to be pruned")
+	}
+}
+
+func TestNodeStatsCache_ConcurrentCallersShareFetch(t *testing.T) {
+	cache := newNodeStatsCache(time.Minute)
+
+	var fetches atomic.Int32
+	fetch := func(_ context.Context, _ string) (*KubeletStatsSummary, error) {
+		fetches.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return &KubeletStatsSummary{}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = cache.get(context.Background(), "node-1", fetch)
+		}()
+	}
+	wg.Wait()
+
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("expected concurrent callers to share one fetch, got %d", got)
+	}
+}
+
+func TestNodeStatsCache_ZeroTTLDisablesCaching(t *testing.T) {
+	cache := newNodeStatsCache(0)
+
+	var fetches atomic.Int32
+	fetch := func(_ context.Context, _ string) (*KubeletStatsSummary, error) {
+		fetches.Add(1)
+		return &KubeletStatsSummary{}, nil
+	}
+	for range 3 {
+		_, _ = cache.get(context.Background(), "node-1", fetch)
+	}
+	if got := fetches.Load(); got != 3 {
+		t.Errorf("expected every call to fetch, got %d", got)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PostgresLogContainer is the container of CloudNativePG instances whose log holds
+// the PostgreSQL log, as JSON records
+const PostgresLogContainer = "postgres"
+
+const (
+	// DefaultLogHintLines is how many of the latest log lines are searched when the
+	// policy does not set it
+	DefaultLogHintLines = 200
+	// maxLogHints bounds the error lines attached to an alert, keeping the latest
+	maxLogHints = 10
+	// maxLogHintLength truncates long error lines
+	maxLogHintLength = 300
+)
+
+// logHintPatterns match, case-insensitively, the PostgreSQL log lines that explain
+// why an instance runs out of space: write failures, failing WAL archiving and
+// checkpoints falling behind
+var logHintPatterns = []string{
+	"no space left on device",
+	"could not extend file",
+	"could not write",
+	"archive command failed",
+	"failed too many times",
+	"checkpoints are occurring too frequently",
+	"panic:",
+}
+
+// PostgresLogCollector picks the key error lines out of the PostgreSQL log of an
+// instance by reading the log of its postgres container through the API, so it
+// needs neither pod exec nor a Job
+type PostgresLogCollector struct {
+	clientset kubernetes.Interface
+}
+
+// NewPostgresLogCollector creates a collector that reads pod logs through the given clientset
+func NewPostgresLogCollector(clientset kubernetes.Interface) *PostgresLogCollector {
+	return &PostgresLogCollector{clientset: clientset}
+}
+
+// ErrorHints returns the key error lines among the last lines of the PostgreSQL log of
+// a pod, oldest first
+func (c *PostgresLogCollector) ErrorHints(ctx context.Context, pod *corev1.Pod, lines int) ([]string, error) {
+	start := time.Now()
+	defer func() {
+		MetricsCollectionDuration.WithLabelValues("postgres_log").Observe(time.Since(start).Seconds())
+	}()
+
+	tailLines := int64(lines)
+	raw, err := c.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: PostgresLogContainer,
+		TailLines: &tailLines,
+	}).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the PostgreSQL log of %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return parseLogHints(string(raw)), nil
+}
+
+// parseLogHints returns the PostgreSQL log records of a container log matching
+// logHintPatterns as "SEVERITY: message", the latest maxLogHints of them without
+// consecutive repeats
+func parseLogHints(output string) []string {
+	var hints []string
+	for _, line := range strings.Split(output, "\n") {
+		hint := logHint(line)
+		if hint == "" || !matchesLogHint(hint) {
+			continue
+		}
+		if len(hint) > maxLogHintLength {
+			hint = hint[:maxLogHintLength] + "..."
+		}
+		if len(hints) > 0 && hints[len(hints)-1] == hint {
+			continue
+		}
+		hints = append(hints, hint)
+	}
+	if len(hints) > maxLogHints {
+		hints = hints[len(hints)-maxLogHints:]
+	}
+	return hints
+}
+
+// logLine is the part of a line of the CloudNativePG instance log that logHint reads.
+// The instance manager logs each PostgreSQL log record as the record field of a line
+// of the postgres logger
+type logLine struct {
+	Logger string `json:"logger"`
+	Record struct {
+		ErrorSeverity string `json:"error_severity"`
+		Message       string `json:"message"`
+	} `json:"record"`
+}
+
+// logHint formats a PostgreSQL log record of the instance log as "SEVERITY: message".
+// Lines of the instance manager itself are left out
+func logHint(line string) string {
+	var entry logLine
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &entry); err != nil {
+		return ""
+	}
+	if entry.Logger != PostgresLogContainer || entry.Record.Message == "" {
+		return ""
+	}
+	if entry.Record.ErrorSeverity == "" {
+		return entry.Record.Message
+	}
+	return entry.Record.ErrorSeverity + ": " + entry.Record.Message
+}
+
+// matchesLogHint returns true when a formatted log line matches one of logHintPatterns
+func matchesLogHint(hint string) bool {
+	lower := strings.ToLower(hint)
+	for _, pattern := range logHintPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// jsonLogLine builds a line of the CloudNativePG instance log holding a PostgreSQL log
+// record with the given severity and message
+func jsonLogLine(severity, message string) string {
+	line, _ := json.Marshal(map[string]any{
+		"level": "info", "ts": "2025-06-01T12:00:00.000000000Z", "logger": "postgres",
+		"msg": "record", "logging_pod": "pg-1",
+		"record": map[string]string{
+			"log_time": "2025-06-01 12:00:00.000 UTC", "user_name": "app", "database_name": "app",
+			"error_severity": severity, "sql_state_code": "53100", "message": message,
+			"backend_type": "client backend",
+		},
+	})
+	return string(line)
+}
+
+func TestParseLogHints(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []string
+	}{
+		{
+			name: "key errors",
+			output: strings.Join([]string{
+				jsonLogLine("LOG", "checkpoint starting: time"),
+				jsonLogLine("LOG", "checkpoints are occurring too frequently (12 seconds apart)"),
+				jsonLogLine("LOG", `archive command failed with exit code 1`),
+				jsonLogLine("ERROR", `could not extend file "base/16384/16385": No space left on device`),
+				jsonLogLine("ERROR", `could not extend file "base/16384/16385": No space left on device`),
+				jsonLogLine("PANIC", `could not write to file "pg_wal/xlogtemp.42": No space left on device`),
+			}, "\n"),
+			expected: []string{
+				"LOG: checkpoints are occurring too frequently (12 seconds apart)",
+				"LOG: archive command failed with exit code 1",
+				`ERROR: could not extend file "base/16384/16385": No space left on device`,
+				`PANIC: could not write to file "pg_wal/xlogtemp.42": No space left on device`,
+			},
+		},
+		{
+			name: "instance manager lines",
+			output: `{"level":"error","ts":"2025-06-01T12:00:00Z","logger":"wal-archive",` +
+				`"msg":"failed to run wal-archive command","error":"could not write: No space left on device"}` + "\n" +
+				"could not write block 42: No space left on device\n",
+		},
+		{
+			name:   "no errors",
+			output: jsonLogLine("LOG", "database system is ready to accept connections") + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if hints := parseLogHints(tt.output); !reflect.DeepEqual(hints, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, hints)
+			}
+		})
+	}
+}
+
+func TestParseLogHints_KeepsLatest(t *testing.T) {
+	var lines []string
+	for i := range maxLogHints + 5 {
+		lines = append(lines, jsonLogLine("ERROR", fmt.Sprintf("could not write block %d: No space left on device", i)))
+	}
+	hints := parseLogHints(strings.Join(lines, "\n"))
+	if len(hints) != maxLogHints || hints[len(hints)-1] != fmt.Sprintf(
+		"ERROR: could not write block %d: No space left on device", maxLogHints+4) {
+		t.Errorf("expected the latest %d hints, got %q", maxLogHints, hints)
+	}
+
+	long := parseLogHints(jsonLogLine("ERROR", "could not write "+strings.Repeat("x", 2*maxLogHintLength)))
+	if len(long) != 1 || len(long[0]) != maxLogHintLength+len("...") {
+		t.Errorf("expected a truncated hint, got %q", long)
+	}
+}
+
+func TestPostgresLogCollector_ErrorHints(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requested = req.URL.Path + "?" + req.URL.RawQuery
+		_, _ = fmt.Fprintln(w, jsonLogLine("ERROR", "could not extend file: No space left on device"))
+	}))
+	defer server.Close()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("failed to create clientset: %v", err)
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Namespace: "db"}}
+	hints, err := NewPostgresLogCollector(clientset).ErrorHints(context.Background(), pod, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "/api/v1/namespaces/db/pods/pg-1/log?container=postgres&tailLines=50"; requested != expected {
+		t.Errorf("expected a request for %s, got %s", expected, requested)
+	}
+	if expected := []string{"ERROR: could not extend file: No space left on device"}; !reflect.DeepEqual(hints, expected) {
+		t.Errorf("expected %q, got %q", expected, hints)
+	}
+}
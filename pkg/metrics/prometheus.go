@@ -17,6 +17,8 @@ limitations under the License.
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -24,6 +26,12 @@ import (
 const (
 	// MetricsNamespace is the namespace for all CNPG Storage Manager metrics
 	MetricsNamespace = "cnpg_storage_manager"
+	// ExemplarLabelStorageEvent is the exemplar label naming the StorageEvent behind a
+	// remediation counter increment
+	ExemplarLabelStorageEvent = "storage_event"
+
+	// allBackupMethods is the method label of backup time series covering all methods
+	allBackupMethods = ""
 )
 
 var (
@@ -97,6 +105,16 @@ var (
 		[]string{"namespace"},
 	)
 
+	// PolicyPartialSuccessSeconds tracks how long a policy has continuously reported PartialSuccess
+	PolicyPartialSuccessSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "policy_partial_success_seconds",
+			Help:      "Seconds a storage policy has continuously reported PartialSuccess (0 when healthy)",
+		},
+		[]string{"policy", "namespace"},
+	)
+
 	// ReconcileTotal tracks the total number of reconciliations
 	ReconcileTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -128,6 +146,56 @@ var (
 		[]string{"type", "cluster", "namespace"},
 	)
 
+	// UpdateConflictsTotal tracks resourceVersion conflicts retried when updating objects
+	UpdateConflictsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "update_conflicts_total",
+			Help:      "Total number of resourceVersion conflicts retried when updating objects",
+		},
+		[]string{"resource"},
+	)
+
+	// ExecTimeoutsTotal tracks pod exec commands cancelled by the exec timeout
+	ExecTimeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "exec_timeout_total",
+			Help:      "Total number of pod exec commands cancelled by the exec timeout",
+		},
+		[]string{"command"},
+	)
+
+	// InjectedFaultsTotal tracks the failures injected by --inject-faults
+	InjectedFaultsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "injected_faults_total",
+			Help:      "Total number of failures injected for testing with --inject-faults",
+		},
+		[]string{"fault"},
+	)
+
+	// StatusPushTotal tracks fleet summary pushes to the central status endpoint
+	StatusPushTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "status_push_total",
+			Help:      "Total number of fleet summary pushes to the central status endpoint",
+		},
+		[]string{"result"},
+	)
+
+	// RemoteWriteTotal tracks writes to the Prometheus remote write endpoint
+	RemoteWriteTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "remote_write_total",
+			Help:      "Total number of writes to the Prometheus remote write endpoint",
+		},
+		[]string{"result"},
+	)
+
 	// ThresholdBreachesTotal tracks threshold breaches
 	ThresholdBreachesTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -158,6 +226,36 @@ var (
 		[]string{"cluster", "namespace"},
 	)
 
+	// ExpansionSkippedTotal tracks PVCs an expansion skipped, by reason
+	ExpansionSkippedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "expansion_skipped_total",
+			Help:      "Total number of PVCs skipped by expansions",
+		},
+		[]string{"cluster", "namespace", "reason"},
+	)
+
+	// RemediationEffectivenessTotal tracks effectiveness measurements of remediation, by result
+	RemediationEffectivenessTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "remediation_effectiveness_total",
+			Help:      "Total number of remediations measured for effectiveness, by result",
+		},
+		[]string{"cluster", "namespace", "type", "result"},
+	)
+
+	// RemediationBytesFreed reports the used space the last measured remediation freed
+	RemediationBytesFreed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "remediation_bytes_freed",
+			Help:      "Used space freed by the last measured remediation, negative when usage grew",
+		},
+		[]string{"cluster", "namespace", "type"},
+	)
+
 	// WALCleanupTotal tracks WAL cleanup operations
 	WALCleanupTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -223,9 +321,9 @@ var (
 		prometheus.GaugeOpts{
 			Namespace: MetricsNamespace,
 			Name:      "backup_last_success_timestamp",
-			Help:      "Unix timestamp of the last successful backup",
+			Help:      "Unix timestamp of the last successful backup, per backup method or across all methods (method=\"\")",
 		},
-		[]string{"cluster", "namespace"},
+		[]string{"cluster", "namespace", "method"},
 	)
 
 	// BackupLastSuccessAgeHours tracks hours since last successful backup
@@ -233,9 +331,9 @@ var (
 		prometheus.GaugeOpts{
 			Namespace: MetricsNamespace,
 			Name:      "backup_last_success_age_hours",
-			Help:      "Hours since the last successful backup",
+			Help:      "Hours since the last successful backup, per backup method or across all methods (method=\"\")",
 		},
-		[]string{"cluster", "namespace"},
+		[]string{"cluster", "namespace", "method"},
 	)
 
 	// BackupFirstRecoverabilityTimestamp tracks the first recoverability point
@@ -243,9 +341,9 @@ var (
 		prometheus.GaugeOpts{
 			Namespace: MetricsNamespace,
 			Name:      "backup_first_recoverability_timestamp",
-			Help:      "Unix timestamp of the first recoverability point",
+			Help:      "Unix timestamp of the first recoverability point, per backup method or across all methods (method=\"\")",
 		},
-		[]string{"cluster", "namespace"},
+		[]string{"cluster", "namespace", "method"},
 	)
 
 	// BackupFirstRecoverabilityAgeHours tracks hours since first recoverability point
@@ -253,9 +351,9 @@ var (
 		prometheus.GaugeOpts{
 			Namespace: MetricsNamespace,
 			Name:      "backup_first_recoverability_age_hours",
-			Help:      "Hours since the first recoverability point",
+			Help:      "Hours since the first recoverability point, per backup method or across all methods (method=\"\")",
 		},
-		[]string{"cluster", "namespace"},
+		[]string{"cluster", "namespace", "method"},
 	)
 
 	// BackupContinuousArchivingWorking tracks if WAL archiving is working
@@ -288,6 +386,278 @@ var (
 		[]string{"cluster", "namespace"},
 	)
 
+	// BackupRecoveryWindowRequiredHours tracks the recovery window a BackupPolicy requires
+	BackupRecoveryWindowRequiredHours = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "backup_recovery_window_required_hours",
+			Help:      "How far back point-in-time recovery must reach (minRecoveryWindowDays)",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// BackupRecoveryWindowCompliant tracks whether the recovery window meets the requirement
+	BackupRecoveryWindowCompliant = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "backup_recovery_window_compliant",
+			Help:      "Whether point-in-time recovery reaches back the required window (1=yes, 0=no)",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// WALArchiveLagSegments tracks WAL segments waiting to be archived on the primary
+	WALArchiveLagSegments = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "wal_archive_lag_segments",
+			Help:      "Completed WAL segments on the primary not yet archived, from pg_stat_archiver",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// WALArchiveLagSeconds tracks how long WAL segments have been waiting to be archived
+	WALArchiveLagSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "wal_archive_lag_seconds",
+			Help:      "Seconds since the last successful WAL archival while segments are pending, from pg_stat_archiver",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// WALArchiveFailedCount tracks the failed archive attempts reported by pg_stat_archiver
+	WALArchiveFailedCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "wal_archive_failed_count",
+			Help:      "Failed WAL archive attempts since the pg_stat_archiver statistics were reset",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// RestoreTestsTotal tracks restore tests of the latest backup
+	RestoreTestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "restore_tests_total",
+			Help:      "Total number of restore tests of the latest backup",
+		},
+		[]string{"cluster", "namespace", "result"},
+	)
+
+	// RestoreTestDurationSeconds tracks how long the last successful restore test took to recover
+	RestoreTestDurationSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "restore_test_duration_seconds",
+			Help:      "Seconds the recovery cluster of the last successful restore test took to become ready",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// ClusterConnectionUp tracks whether downstream Kubernetes clusters are reachable
+	ClusterConnectionUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "cluster_connection_up",
+			Help:      "Whether a ClusterConnection can reach its downstream cluster (1 = connected)",
+		},
+		[]string{"connection", "namespace"},
+	)
+
+	// ClusterInfo maps a connection to the identity of its Kubernetes cluster, e.g. the Rancher
+	// cluster ID. The local cluster has an empty connection label
+	ClusterInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "cluster_info",
+			Help:      "Identity of the Kubernetes clusters the manager monitors (always 1)",
+		},
+		[]string{"connection", "source_cluster_id", "source_cluster_name"},
+	)
+
+	// RemoteClusterUsagePercent tracks storage usage of CNPG clusters reached through a ClusterConnection
+	RemoteClusterUsagePercent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "remote_cluster_usage_percent",
+			Help:      "Storage usage percentage of a CNPG cluster in a downstream Kubernetes cluster",
+		},
+		[]string{"connection", "cluster", "namespace"},
+	)
+
+	// PlannedExpansionBytes tracks the bytes a dry-run expansion would have added to a cluster
+	PlannedExpansionBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "planned_expansion_bytes",
+			Help:      "Bytes a PVC expansion held back by dry-run mode would have added",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// QuotaHeadroomBytes tracks the storage requests a namespace ResourceQuota still allows
+	QuotaHeadroomBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "quota_headroom_bytes",
+			Help:      "Storage requests a ResourceQuota of a managed cluster's namespace still allows",
+		},
+		[]string{"namespace", "quota", "resource"},
+	)
+
+	// ExpansionCount30d tracks the expansions of a cluster completed in the last 30 days
+	ExpansionCount30d = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "expansion_count_30d",
+			Help:      "Number of PVC expansions of a cluster completed in the last 30 days",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// ExpansionCumulativeGrowthBytes tracks the storage added to a cluster by all recorded expansions
+	ExpansionCumulativeGrowthBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "expansion_cumulative_growth_bytes",
+			Help:      "Storage added to a cluster by all expansions in its expansion history",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// StorageGrowthBytesPerHour tracks the growth rate anomaly detection measured, by window
+	StorageGrowthBytesPerHour = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "storage_growth_bytes_per_hour",
+			Help:      "Storage growth rate of a cluster over the recent or baseline window of anomaly detection",
+		},
+		[]string{"cluster", "namespace", "window"},
+	)
+
+	// StorageGrowthAnomaly tracks whether a cluster grows abnormally fast
+	StorageGrowthAnomaly = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "storage_growth_anomaly",
+			Help:      "Whether a cluster grows abnormally fast compared to its baseline (1 = anomalous)",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// TablespaceUsagePercent tracks the usage of each tablespace on its fullest instance
+	TablespaceUsagePercent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "tablespace_usage_percent",
+			Help:      "Storage usage percentage of a tablespace on the instance where it is fullest",
+		},
+		[]string{"cluster", "namespace", "tablespace"},
+	)
+
+	// PoolerVolumeUsagePercent tracks the usage of the PVCs mounted by Pooler pods
+	PoolerVolumeUsagePercent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "pooler_volume_usage_percent",
+			Help:      "Storage usage percentage of a PVC mounted by the pods of a CNPG Pooler",
+		},
+		[]string{"cluster", "namespace", "pooler", "pvc"},
+	)
+
+	// ZoneUsageBytes tracks the used bytes of a cluster's data and WAL volumes per zone
+	ZoneUsageBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "zone_usage_bytes",
+			Help:      "Used bytes of the data and WAL volumes of a cluster's instances in a zone",
+		},
+		[]string{"cluster", "namespace", "zone", "region"},
+	)
+
+	// ZoneCapacityBytes tracks the capacity of a cluster's data and WAL volumes per zone
+	ZoneCapacityBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "zone_capacity_bytes",
+			Help:      "Capacity in bytes of the data and WAL volumes of a cluster's instances in a zone",
+		},
+		[]string{"cluster", "namespace", "zone", "region"},
+	)
+
+	// PVCTopologyInfo maps each PVC to the zone and region of the node it is mounted on
+	PVCTopologyInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "pvc_topology_info",
+			Help:      "Zone and region of the node a PVC's instance runs on (always 1)",
+		},
+		[]string{"cluster", "namespace", "pvc", "instance", "zone", "region"},
+	)
+
+	// ClusterWritable tracks whether the primary of a cluster commits the write probe
+	ClusterWritable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "cluster_writable",
+			Help:      "Whether the primary of a cluster committed the write probe (1 = writable)",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
+	// ClusterHealthScore tracks the composite storage health score of a cluster
+	ClusterHealthScore = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "cluster_health_score",
+			Help:      "Storage health score of a cluster from 0 to 100, 100 being healthy",
+		},
+		[]string{"cluster", "namespace", "connection"},
+	)
+
+	// StorageSLOSecondsTotal tracks the time a cluster's storage health was known, the
+	// denominator of the storage SLO error ratio
+	StorageSLOSecondsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "storage_slo_seconds_total",
+			Help:      "Seconds a cluster's storage health was tracked against its storage SLO",
+		},
+		[]string{"cluster", "namespace", "connection"},
+	)
+
+	// StorageSLOViolationSecondsTotal tracks the time a cluster violated its storage SLO
+	StorageSLOViolationSecondsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "storage_slo_violation_seconds_total",
+			Help:      "Seconds a cluster was above the critical threshold or had unhealthy backups",
+		},
+		[]string{"cluster", "namespace", "connection"},
+	)
+
+	// StorageSLOObjective tracks the storage SLO of a cluster
+	StorageSLOObjective = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "storage_slo_objective",
+			Help:      "Storage SLO objective of a cluster as a ratio, e.g. 0.995",
+		},
+		[]string{"cluster", "namespace", "connection"},
+	)
+
+	// StorageSLOErrorBudgetRemaining tracks the error budget a cluster has left in the window
+	StorageSLOErrorBudgetRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "storage_slo_error_budget_remaining",
+			Help:      "Share of the storage SLO error budget a cluster has left in the current window",
+		},
+		[]string{"cluster", "namespace", "connection"},
+	)
+
 	// BackupAlertsTotal tracks backup-related alerts
 	BackupAlertsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -299,38 +669,110 @@ var (
 	)
 )
 
+// collectors are the manager's metrics
+var collectors = []prometheus.Collector{
+	PVCUsageBytes,
+	PVCCapacityBytes,
+	PVCUsagePercent,
+	WALDirectoryBytes,
+	WALFilesCount,
+	ClustersManagedTotal,
+	PoliciesActiveTotal,
+	PolicyPartialSuccessSeconds,
+	ReconcileTotal,
+	ReconcileDuration,
+	ErrorsTotal,
+	UpdateConflictsTotal,
+	ExecTimeoutsTotal,
+	InjectedFaultsTotal,
+	StatusPushTotal,
+	RemoteWriteTotal,
+	ThresholdBreachesTotal,
+	ExpansionTotal,
+	ExpansionBytesTotal,
+	ExpansionSkippedTotal,
+	RemediationEffectivenessTotal,
+	RemediationBytesFreed,
+	WALCleanupTotal,
+	WALFilesRemoved,
+	CircuitBreakerState,
+	AlertsSentTotal,
+	AlertsSuppressedTotal,
+	MetricsCollectionDuration,
+	// Backup metrics
+	BackupLastSuccessTimestamp,
+	BackupLastSuccessAgeHours,
+	BackupFirstRecoverabilityTimestamp,
+	BackupFirstRecoverabilityAgeHours,
+	BackupContinuousArchivingWorking,
+	BackupConfigured,
+	BackupHealthy,
+	BackupAlertsTotal,
+	BackupRecoveryWindowRequiredHours,
+	BackupRecoveryWindowCompliant,
+	WALArchiveLagSegments,
+	WALArchiveLagSeconds,
+	WALArchiveFailedCount,
+	RestoreTestsTotal,
+	RestoreTestDurationSeconds,
+	ClusterConnectionUp,
+	ClusterInfo,
+	RemoteClusterUsagePercent,
+	PlannedExpansionBytes,
+	QuotaHeadroomBytes,
+	ExpansionCount30d,
+	ExpansionCumulativeGrowthBytes,
+	StorageGrowthBytesPerHour,
+	StorageGrowthAnomaly,
+	TablespaceUsagePercent,
+	PoolerVolumeUsagePercent,
+	ZoneUsageBytes,
+	ZoneCapacityBytes,
+	PVCTopologyInfo,
+	ClusterWritable,
+	ClusterHealthScore,
+	StorageSLOSecondsTotal,
+	StorageSLOViolationSecondsTotal,
+	StorageSLOObjective,
+	StorageSLOErrorBudgetRemaining,
+}
+
 func init() {
 	// Register all metrics with the controller-runtime metrics registry
-	metrics.Registry.MustRegister(
-		PVCUsageBytes,
-		PVCCapacityBytes,
-		PVCUsagePercent,
-		WALDirectoryBytes,
-		WALFilesCount,
-		ClustersManagedTotal,
-		PoliciesActiveTotal,
-		ReconcileTotal,
-		ReconcileDuration,
-		ErrorsTotal,
-		ThresholdBreachesTotal,
-		ExpansionTotal,
-		ExpansionBytesTotal,
-		WALCleanupTotal,
-		WALFilesRemoved,
-		CircuitBreakerState,
-		AlertsSentTotal,
-		AlertsSuppressedTotal,
-		MetricsCollectionDuration,
-		// Backup metrics
-		BackupLastSuccessTimestamp,
-		BackupLastSuccessAgeHours,
-		BackupFirstRecoverabilityTimestamp,
-		BackupFirstRecoverabilityAgeHours,
-		BackupContinuousArchivingWorking,
-		BackupConfigured,
-		BackupHealthy,
-		BackupAlertsTotal,
-	)
+	metrics.Registry.MustRegister(collectors...)
+}
+
+// shardedRegistry serves the manager's shard-labelled metrics next to those of the
+// original registry, which keeps taking new registrations
+type shardedRegistry struct {
+	prometheus.Registerer
+	prometheus.Gatherer
+}
+
+// SetShard moves the manager's metrics to a registry adding a shard label, so the series
+// of sharded replicas can be told apart. A registry keeps the label names of a metric
+// once registered, hence the second registry. It must be called before metrics are served
+func SetShard(shard string) {
+	sharded := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(prometheus.Labels{"shard": shard}, sharded)
+	for _, collector := range collectors {
+		metrics.Registry.Unregister(collector)
+		registerer.MustRegister(collector)
+	}
+	metrics.Registry = shardedRegistry{
+		Registerer: metrics.Registry,
+		Gatherer:   prometheus.Gatherers{metrics.Registry, sharded},
+	}
+}
+
+// SetPolicyPartialSuccess records how long a policy has been in PartialSuccess
+func SetPolicyPartialSuccess(policy, namespace string, seconds float64) {
+	PolicyPartialSuccessSeconds.WithLabelValues(policy, namespace).Set(seconds)
+}
+
+// DeletePolicyMetrics removes all metrics for a deleted policy
+func DeletePolicyMetrics(policy, namespace string) {
+	PolicyPartialSuccessSeconds.DeleteLabelValues(policy, namespace)
 }
 
 // RecordPVCMetrics records PVC usage metrics
@@ -360,22 +802,69 @@ func RecordError(errorType, cluster, namespace string) {
 	ErrorsTotal.WithLabelValues(errorType, cluster, namespace).Inc()
 }
 
+// RecordUpdateConflict records a resourceVersion conflict on an update of the given resource
+func RecordUpdateConflict(resource string) {
+	UpdateConflictsTotal.WithLabelValues(resource).Inc()
+}
+
+// RecordExecTimeout records a pod exec command cancelled by the exec timeout
+func RecordExecTimeout(command string) {
+	ExecTimeoutsTotal.WithLabelValues(command).Inc()
+}
+
+// RecordInjectedFault records a failure injected for testing
+func RecordInjectedFault(fault string) {
+	InjectedFaultsTotal.WithLabelValues(fault).Inc()
+}
+
+// RecordStatusPush records a fleet summary push with its result (success or failure)
+func RecordStatusPush(result string) {
+	StatusPushTotal.WithLabelValues(result).Inc()
+}
+
+// RecordRemoteWrite records a remote write with its result (success or failure)
+func RecordRemoteWrite(result string) {
+	RemoteWriteTotal.WithLabelValues(result).Inc()
+}
+
 // RecordThresholdBreach records a threshold breach
 func RecordThresholdBreach(cluster, namespace, level string) {
 	ThresholdBreachesTotal.WithLabelValues(cluster, namespace, level).Inc()
 }
 
 // RecordExpansion records an expansion operation
-func RecordExpansion(cluster, namespace, result string, bytes int64) {
-	ExpansionTotal.WithLabelValues(cluster, namespace, result).Inc()
+func RecordExpansion(cluster, namespace, result string, bytes int64, event string) {
+	incWithEvent(ExpansionTotal.WithLabelValues(cluster, namespace, result), event)
 	if result == "success" && bytes > 0 {
 		ExpansionBytesTotal.WithLabelValues(cluster, namespace).Add(float64(bytes))
 	}
 }
 
+// RecordExpansionSkipped records a PVC an expansion skipped
+func RecordExpansionSkipped(cluster, namespace, reason string) {
+	ExpansionSkippedTotal.WithLabelValues(cluster, namespace, reason).Inc()
+}
+
+// RecordRemediationEffectiveness records the effectiveness measurement of a remediation
+func RecordRemediationEffectiveness(cluster, namespace, eventType, result string, bytesFreed int64) {
+	RemediationEffectivenessTotal.WithLabelValues(cluster, namespace, eventType, result).Inc()
+	RemediationBytesFreed.WithLabelValues(cluster, namespace, eventType).Set(float64(bytesFreed))
+}
+
 // RecordWALCleanup records a WAL cleanup operation
-func RecordWALCleanup(cluster, namespace, result string) {
-	WALCleanupTotal.WithLabelValues(cluster, namespace, result).Inc()
+func RecordWALCleanup(cluster, namespace, result, event string) {
+	incWithEvent(WALCleanupTotal.WithLabelValues(cluster, namespace, result), event)
+}
+
+// incWithEvent increments a remediation counter with the StorageEvent that caused it as
+// an exemplar, so dashboards can link a sample to the event. Without an event the
+// counter is incremented plainly
+func incWithEvent(counter prometheus.Counter, event string) {
+	if adder, ok := counter.(prometheus.ExemplarAdder); ok && event != "" {
+		adder.AddWithExemplar(1, prometheus.Labels{ExemplarLabelStorageEvent: event})
+		return
+	}
+	counter.Inc()
 }
 
 // SetCircuitBreakerState sets the circuit breaker state
@@ -387,6 +876,119 @@ func SetCircuitBreakerState(cluster, namespace string, open bool) {
 	CircuitBreakerState.WithLabelValues(cluster, namespace).Set(value)
 }
 
+// SetPlannedExpansionBytes records the bytes a dry-run expansion would have added.
+// Zero removes the series
+func SetPlannedExpansionBytes(cluster, namespace string, bytes int64) {
+	if bytes <= 0 {
+		PlannedExpansionBytes.DeleteLabelValues(cluster, namespace)
+		return
+	}
+	PlannedExpansionBytes.WithLabelValues(cluster, namespace).Set(float64(bytes))
+}
+
+// SetQuotaHeadroom records the storage requests a ResourceQuota still allows. The
+// headroom is negative when the quota is already exceeded
+func SetQuotaHeadroom(namespace, quota, resource string, bytes int64) {
+	QuotaHeadroomBytes.WithLabelValues(namespace, quota, resource).Set(float64(bytes))
+}
+
+// SetExpansionHistory records the expansion count of the last 30 days and the
+// cumulative growth of a cluster
+func SetExpansionHistory(cluster, namespace string, count30d int32, cumulativeGrowthBytes int64) {
+	ExpansionCount30d.WithLabelValues(cluster, namespace).Set(float64(count30d))
+	ExpansionCumulativeGrowthBytes.WithLabelValues(cluster, namespace).Set(float64(cumulativeGrowthBytes))
+}
+
+// SetStorageGrowth records the recent and baseline growth rates of a cluster and
+// whether its growth is anomalous
+func SetStorageGrowth(cluster, namespace string, recentBytesPerHour, baselineBytesPerHour int64, anomalous bool) {
+	StorageGrowthBytesPerHour.WithLabelValues(cluster, namespace, "recent").Set(float64(recentBytesPerHour))
+	StorageGrowthBytesPerHour.WithLabelValues(cluster, namespace, "baseline").Set(float64(baselineBytesPerHour))
+	value := 0.0
+	if anomalous {
+		value = 1.0
+	}
+	StorageGrowthAnomaly.WithLabelValues(cluster, namespace).Set(value)
+}
+
+// SetTablespaceUsage records the usage percentage of a tablespace
+func SetTablespaceUsage(cluster, namespace, tablespace string, usagePercent float64) {
+	TablespaceUsagePercent.WithLabelValues(cluster, namespace, tablespace).Set(usagePercent)
+}
+
+// SetPoolerVolumeUsage records the usage percentage of a PVC mounted by a Pooler's pods
+func SetPoolerVolumeUsage(cluster, namespace, pooler, pvc string, usagePercent float64) {
+	PoolerVolumeUsagePercent.WithLabelValues(cluster, namespace, pooler, pvc).Set(usagePercent)
+}
+
+// DeletePoolerVolumeMetrics removes the pooler volume series of a cluster, so volumes of
+// deleted Poolers are not reported
+func DeletePoolerVolumeMetrics(cluster, namespace string) {
+	PoolerVolumeUsagePercent.DeletePartialMatch(prometheus.Labels{"cluster": cluster, "namespace": namespace})
+}
+
+// SetZoneUsage records the per-zone usage of a cluster, replacing the series of zones
+// the cluster no longer has instances in
+func SetZoneUsage(cluster, namespace string, zones []ZoneUsage) {
+	labels := prometheus.Labels{"cluster": cluster, "namespace": namespace}
+	ZoneUsageBytes.DeletePartialMatch(labels)
+	ZoneCapacityBytes.DeletePartialMatch(labels)
+	for _, zone := range zones {
+		ZoneUsageBytes.WithLabelValues(cluster, namespace, zone.Zone, zone.Region).Set(float64(zone.UsedBytes))
+		ZoneCapacityBytes.WithLabelValues(cluster, namespace, zone.Zone, zone.Region).Set(float64(zone.CapacityBytes))
+	}
+}
+
+// SetPVCTopology records the zone and region of a PVC's instance
+func SetPVCTopology(cluster, namespace, pvc, instance, zone, region string) {
+	PVCTopologyInfo.WithLabelValues(cluster, namespace, pvc, instance, zone, region).Set(1)
+}
+
+// DeletePVCTopology removes the topology series of a cluster's PVCs, so moved or
+// deleted instances are not reported
+func DeletePVCTopology(cluster, namespace string) {
+	PVCTopologyInfo.DeletePartialMatch(prometheus.Labels{"cluster": cluster, "namespace": namespace})
+}
+
+// SetClusterWritable records whether the primary of a cluster committed the write probe
+func SetClusterWritable(cluster, namespace string, writable bool) {
+	value := 0.0
+	if writable {
+		value = 1.0
+	}
+	ClusterWritable.WithLabelValues(cluster, namespace).Set(value)
+}
+
+// SetClusterHealthScore records the health score of a cluster. A nil score removes the
+// series
+func SetClusterHealthScore(cluster, namespace, connection string, score *int32) {
+	if score == nil {
+		ClusterHealthScore.DeleteLabelValues(cluster, namespace, connection)
+		return
+	}
+	ClusterHealthScore.WithLabelValues(cluster, namespace, connection).Set(float64(*score))
+}
+
+// RecordStorageSLO records the time since a cluster's previous evaluation against its
+// storage SLO, and its objective and remaining error budget
+func RecordStorageSLO(
+	cluster, namespace, connection string,
+	trackedSeconds, violationSeconds, objective, budgetRemaining float64,
+) {
+	StorageSLOSecondsTotal.WithLabelValues(cluster, namespace, connection).Add(trackedSeconds)
+	StorageSLOViolationSecondsTotal.WithLabelValues(cluster, namespace, connection).Add(violationSeconds)
+	StorageSLOObjective.WithLabelValues(cluster, namespace, connection).Set(objective)
+	StorageSLOErrorBudgetRemaining.WithLabelValues(cluster, namespace, connection).Set(budgetRemaining)
+}
+
+// DeleteStorageSLOMetrics removes the storage SLO metrics of a cluster
+func DeleteStorageSLOMetrics(cluster, namespace, connection string) {
+	StorageSLOSecondsTotal.DeleteLabelValues(cluster, namespace, connection)
+	StorageSLOViolationSecondsTotal.DeleteLabelValues(cluster, namespace, connection)
+	StorageSLOObjective.DeleteLabelValues(cluster, namespace, connection)
+	StorageSLOErrorBudgetRemaining.DeleteLabelValues(cluster, namespace, connection)
+}
+
 // RecordAlertSent records an alert being sent
 func RecordAlertSent(cluster, namespace, severity, channel string) {
 	AlertsSentTotal.WithLabelValues(cluster, namespace, severity, channel).Inc()
@@ -437,21 +1039,41 @@ func RecordBackupMetrics(
 
 	// Set timestamp metrics if available
 	if lastBackupTimestamp != nil {
-		BackupLastSuccessTimestamp.WithLabelValues(cluster, namespace).Set(*lastBackupTimestamp)
+		BackupLastSuccessTimestamp.WithLabelValues(cluster, namespace, allBackupMethods).Set(*lastBackupTimestamp)
 	}
 	if firstRecoverabilityTimestamp != nil {
-		BackupFirstRecoverabilityTimestamp.WithLabelValues(cluster, namespace).Set(*firstRecoverabilityTimestamp)
+		BackupFirstRecoverabilityTimestamp.WithLabelValues(cluster, namespace, allBackupMethods).
+			Set(*firstRecoverabilityTimestamp)
 	}
 }
 
 // RecordBackupAge records the age of the last backup in hours
 func RecordBackupAge(cluster, namespace string, ageHours float64) {
-	BackupLastSuccessAgeHours.WithLabelValues(cluster, namespace).Set(ageHours)
+	BackupLastSuccessAgeHours.WithLabelValues(cluster, namespace, allBackupMethods).Set(ageHours)
 }
 
 // RecordFirstRecoverabilityAge records the age of the first recoverability point in hours
 func RecordFirstRecoverabilityAge(cluster, namespace string, ageHours float64) {
-	BackupFirstRecoverabilityAgeHours.WithLabelValues(cluster, namespace).Set(ageHours)
+	BackupFirstRecoverabilityAgeHours.WithLabelValues(cluster, namespace, allBackupMethods).Set(ageHours)
+}
+
+// RecordBackupMethodTimes records the last successful backup and first recoverability
+// point of a single backup method with their ages at now. Nil times are not recorded
+func RecordBackupMethodTimes(
+	cluster, namespace, method string,
+	lastBackup, firstRecoverability *time.Time,
+	now time.Time,
+) {
+	if lastBackup != nil {
+		BackupLastSuccessTimestamp.WithLabelValues(cluster, namespace, method).Set(float64(lastBackup.Unix()))
+		BackupLastSuccessAgeHours.WithLabelValues(cluster, namespace, method).Set(now.Sub(*lastBackup).Hours())
+	}
+	if firstRecoverability != nil {
+		BackupFirstRecoverabilityTimestamp.WithLabelValues(cluster, namespace, method).
+			Set(float64(firstRecoverability.Unix()))
+		BackupFirstRecoverabilityAgeHours.WithLabelValues(cluster, namespace, method).
+			Set(now.Sub(*firstRecoverability).Hours())
+	}
 }
 
 // RecordBackupAlert records a backup-related alert
@@ -459,13 +1081,78 @@ func RecordBackupAlert(cluster, namespace, alertType string) {
 	BackupAlertsTotal.WithLabelValues(cluster, namespace, alertType).Inc()
 }
 
+// RecordWALArchiveLag records WAL archive lag from pg_stat_archiver
+func RecordWALArchiveLag(cluster, namespace string, lagSegments int64, lagSeconds float64, failedCount int64) {
+	WALArchiveLagSegments.WithLabelValues(cluster, namespace).Set(float64(lagSegments))
+	WALArchiveLagSeconds.WithLabelValues(cluster, namespace).Set(lagSeconds)
+	WALArchiveFailedCount.WithLabelValues(cluster, namespace).Set(float64(failedCount))
+}
+
+// RecordRecoveryWindowCompliance records the required recovery window and whether it is met
+func RecordRecoveryWindowCompliance(cluster, namespace string, requiredHours float64, compliant bool) {
+	value := 0.0
+	if compliant {
+		value = 1.0
+	}
+	BackupRecoveryWindowRequiredHours.WithLabelValues(cluster, namespace).Set(requiredHours)
+	BackupRecoveryWindowCompliant.WithLabelValues(cluster, namespace).Set(value)
+}
+
+// DeleteRecoveryWindowCompliance removes the compliance metrics when no requirement applies
+func DeleteRecoveryWindowCompliance(cluster, namespace string) {
+	BackupRecoveryWindowRequiredHours.DeleteLabelValues(cluster, namespace)
+	BackupRecoveryWindowCompliant.DeleteLabelValues(cluster, namespace)
+}
+
+// RecordRestoreTest records the result of a restore test
+func RecordRestoreTest(cluster, namespace, result string, durationSeconds int32) {
+	RestoreTestsTotal.WithLabelValues(cluster, namespace, result).Inc()
+	if result == "success" && durationSeconds > 0 {
+		RestoreTestDurationSeconds.WithLabelValues(cluster, namespace).Set(float64(durationSeconds))
+	}
+}
+
+// SetClusterConnectionUp records whether a ClusterConnection reaches its downstream cluster
+func SetClusterConnectionUp(connection, namespace string, up bool) {
+	value := 0.0
+	if up {
+		value = 1.0
+	}
+	ClusterConnectionUp.WithLabelValues(connection, namespace).Set(value)
+}
+
+// DeleteClusterConnectionMetrics removes the metrics of a deleted ClusterConnection
+func DeleteClusterConnectionMetrics(connection, namespace string) {
+	ClusterConnectionUp.DeleteLabelValues(connection, namespace)
+	RemoteClusterUsagePercent.DeletePartialMatch(prometheus.Labels{"connection": connection})
+	ClusterInfo.DeletePartialMatch(prometheus.Labels{"connection": connection})
+}
+
+// SetClusterInfo records the identity of the Kubernetes cluster behind a connection,
+// replacing any previous identity. Use an empty connection for the local cluster
+func SetClusterInfo(connection, clusterID, clusterName string) {
+	ClusterInfo.DeletePartialMatch(prometheus.Labels{"connection": connection})
+	ClusterInfo.WithLabelValues(connection, clusterID, clusterName).Set(1)
+}
+
+// RecordRemoteClusterUsage records the storage usage of a cluster reached through a ClusterConnection
+func RecordRemoteClusterUsage(connection, cluster, namespace string, usagePercent float64) {
+	RemoteClusterUsagePercent.WithLabelValues(connection, cluster, namespace).Set(usagePercent)
+}
+
 // DeleteBackupMetrics deletes backup metrics for a specific cluster
 func DeleteBackupMetrics(cluster, namespace string) {
-	BackupLastSuccessTimestamp.DeleteLabelValues(cluster, namespace)
-	BackupLastSuccessAgeHours.DeleteLabelValues(cluster, namespace)
-	BackupFirstRecoverabilityTimestamp.DeleteLabelValues(cluster, namespace)
-	BackupFirstRecoverabilityAgeHours.DeleteLabelValues(cluster, namespace)
+	clusterLabels := prometheus.Labels{"cluster": cluster, "namespace": namespace}
+	BackupLastSuccessTimestamp.DeletePartialMatch(clusterLabels)
+	BackupLastSuccessAgeHours.DeletePartialMatch(clusterLabels)
+	BackupFirstRecoverabilityTimestamp.DeletePartialMatch(clusterLabels)
+	BackupFirstRecoverabilityAgeHours.DeletePartialMatch(clusterLabels)
 	BackupContinuousArchivingWorking.DeleteLabelValues(cluster, namespace)
 	BackupConfigured.DeleteLabelValues(cluster, namespace)
 	BackupHealthy.DeleteLabelValues(cluster, namespace)
+	WALArchiveLagSegments.DeleteLabelValues(cluster, namespace)
+	WALArchiveLagSeconds.DeleteLabelValues(cluster, namespace)
+	WALArchiveFailedCount.DeleteLabelValues(cluster, namespace)
+	RestoreTestDurationSeconds.DeleteLabelValues(cluster, namespace)
+	DeleteRecoveryWindowCompliance(cluster, namespace)
 }
@@ -18,9 +18,11 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 func TestRecordPVCMetrics(t *testing.T) {
@@ -161,7 +163,7 @@ func TestRecordExpansion(t *testing.T) {
 	ExpansionBytesTotal.Reset()
 
 	// Record successful expansion
-	RecordExpansion("test-cluster", "default", "success", 5368709120)
+	RecordExpansion("test-cluster", "default", "success", 5368709120, "")
 
 	successCount := testutil.ToFloat64(ExpansionTotal.WithLabelValues("test-cluster", "default", "success"))
 	if successCount != 1 {
@@ -174,7 +176,7 @@ func TestRecordExpansion(t *testing.T) {
 	}
 
 	// Record failed expansion (shouldn't add bytes)
-	RecordExpansion("test-cluster", "default", "failure", 1000000)
+	RecordExpansion("test-cluster", "default", "failure", 1000000, "")
 
 	failureCount := testutil.ToFloat64(ExpansionTotal.WithLabelValues("test-cluster", "default", "failure"))
 	if failureCount != 1 {
@@ -188,12 +190,125 @@ func TestRecordExpansion(t *testing.T) {
 	}
 }
 
+func TestRecordExpansionSkipped(t *testing.T) {
+	ExpansionSkippedTotal.Reset()
+
+	RecordExpansionSkipped("test-cluster", "default", "max_size")
+	RecordExpansionSkipped("test-cluster", "default", "max_size")
+	RecordExpansionSkipped("test-cluster", "default", "unbound")
+
+	maxSize := testutil.ToFloat64(ExpansionSkippedTotal.WithLabelValues("test-cluster", "default", "max_size"))
+	if maxSize != 2 {
+		t.Errorf("expected 2 PVCs skipped at max size, got %f", maxSize)
+	}
+	unbound := testutil.ToFloat64(ExpansionSkippedTotal.WithLabelValues("test-cluster", "default", "unbound"))
+	if unbound != 1 {
+		t.Errorf("expected 1 unbound PVC skipped, got %f", unbound)
+	}
+}
+
+func TestRecordRemediationEffectiveness(t *testing.T) {
+	RemediationEffectivenessTotal.Reset()
+	RemediationBytesFreed.Reset()
+
+	RecordRemediationEffectiveness("test-cluster", "default", "wal-cleanup", "Effective", 4096)
+	RecordRemediationEffectiveness("test-cluster", "default", "wal-cleanup", "Ineffective", -1024)
+
+	effective := testutil.ToFloat64(RemediationEffectivenessTotal.WithLabelValues("test-cluster", "default",
+		"wal-cleanup", "Effective"))
+	if effective != 1 {
+		t.Errorf("expected 1 effective remediation, got %f", effective)
+	}
+	freed := testutil.ToFloat64(RemediationBytesFreed.WithLabelValues("test-cluster", "default", "wal-cleanup"))
+	if freed != -1024 {
+		t.Errorf("expected the last measurement of -1024 bytes, got %f", freed)
+	}
+}
+
+func TestSetClusterHealthScore(t *testing.T) {
+	ClusterHealthScore.Reset()
+
+	score := int32(72)
+	SetClusterHealthScore("test-cluster", "default", "", &score)
+	if got := testutil.ToFloat64(ClusterHealthScore.WithLabelValues("test-cluster", "default", "")); got != 72 {
+		t.Errorf("expected a health score of 72, got %f", got)
+	}
+
+	SetClusterHealthScore("test-cluster", "default", "", nil)
+	if count := testutil.CollectAndCount(ClusterHealthScore); count != 0 {
+		t.Errorf("expected the series to be removed, got %d series", count)
+	}
+}
+
+func TestPoolerVolumeUsage(t *testing.T) {
+	PoolerVolumeUsagePercent.Reset()
+
+	SetPoolerVolumeUsage("test-cluster", "default", "pooler-rw", "pooler-logs", 42)
+	SetPoolerVolumeUsage("other-cluster", "default", "pooler-rw", "pooler-logs", 10)
+	series := PoolerVolumeUsagePercent.WithLabelValues("test-cluster", "default", "pooler-rw", "pooler-logs")
+	if got := testutil.ToFloat64(series); got != 42 {
+		t.Errorf("expected a usage of 42, got %f", got)
+	}
+
+	DeletePoolerVolumeMetrics("test-cluster", "default")
+	if count := testutil.CollectAndCount(PoolerVolumeUsagePercent); count != 1 {
+		t.Errorf("expected only the other cluster's series to remain, got %d series", count)
+	}
+}
+
+func TestSetZoneUsage(t *testing.T) {
+	ZoneUsageBytes.Reset()
+	ZoneCapacityBytes.Reset()
+
+	SetZoneUsage("test-cluster", "default", []ZoneUsage{
+		{Zone: "eu-west-1a", Region: "eu-west-1", UsedBytes: 40, CapacityBytes: 100},
+		{Zone: "eu-west-1b", Region: "eu-west-1", UsedBytes: 90, CapacityBytes: 100},
+	})
+	SetZoneUsage("other-cluster", "default", []ZoneUsage{{Zone: "eu-west-1a", UsedBytes: 1, CapacityBytes: 2}})
+	series := ZoneUsageBytes.WithLabelValues("test-cluster", "default", "eu-west-1b", "eu-west-1")
+	if got := testutil.ToFloat64(series); got != 90 {
+		t.Errorf("expected 90 used bytes, got %f", got)
+	}
+
+	SetZoneUsage("test-cluster", "default", []ZoneUsage{
+		{Zone: "eu-west-1a", Region: "eu-west-1", UsedBytes: 50, CapacityBytes: 100},
+	})
+	if count := testutil.CollectAndCount(ZoneCapacityBytes); count != 2 {
+		t.Errorf("expected the moved zone's series to be removed, got %d series", count)
+	}
+}
+
+func TestRecordStorageSLO(t *testing.T) {
+	StorageSLOSecondsTotal.Reset()
+	StorageSLOViolationSecondsTotal.Reset()
+
+	RecordStorageSLO("test-cluster", "default", "", 30, 0, 0.995, 1)
+	RecordStorageSLO("test-cluster", "default", "", 30, 30, 0.995, 0.98)
+
+	if got := testutil.ToFloat64(StorageSLOSecondsTotal.WithLabelValues("test-cluster", "default", "")); got != 60 {
+		t.Errorf("expected 60 tracked seconds, got %f", got)
+	}
+	violation := testutil.ToFloat64(StorageSLOViolationSecondsTotal.WithLabelValues("test-cluster", "default", ""))
+	if violation != 30 {
+		t.Errorf("expected 30 violation seconds, got %f", violation)
+	}
+	budget := testutil.ToFloat64(StorageSLOErrorBudgetRemaining.WithLabelValues("test-cluster", "default", ""))
+	if budget != 0.98 {
+		t.Errorf("expected the last remaining budget, got %f", budget)
+	}
+
+	DeleteStorageSLOMetrics("test-cluster", "default", "")
+	if count := testutil.CollectAndCount(StorageSLOSecondsTotal); count != 0 {
+		t.Errorf("expected the series to be removed, got %d series", count)
+	}
+}
+
 func TestRecordWALCleanup(t *testing.T) {
 	WALCleanupTotal.Reset()
 
-	RecordWALCleanup("test-cluster", "default", "success")
-	RecordWALCleanup("test-cluster", "default", "success")
-	RecordWALCleanup("test-cluster", "default", "failure")
+	RecordWALCleanup("test-cluster", "default", "success", "")
+	RecordWALCleanup("test-cluster", "default", "success", "")
+	RecordWALCleanup("test-cluster", "default", "failure", "")
 
 	successCount := testutil.ToFloat64(WALCleanupTotal.WithLabelValues("test-cluster", "default", "success"))
 	if successCount != 2 {
@@ -224,6 +339,24 @@ func TestSetCircuitBreakerState(t *testing.T) {
 	}
 }
 
+func TestRecordRecoveryWindowCompliance(t *testing.T) {
+	BackupRecoveryWindowRequiredHours.Reset()
+	BackupRecoveryWindowCompliant.Reset()
+
+	RecordRecoveryWindowCompliance("test-cluster", "default", 336, false)
+	if v := testutil.ToFloat64(BackupRecoveryWindowRequiredHours.WithLabelValues("test-cluster", "default")); v != 336 {
+		t.Errorf("expected required window 336h, got %f", v)
+	}
+	if v := testutil.ToFloat64(BackupRecoveryWindowCompliant.WithLabelValues("test-cluster", "default")); v != 0 {
+		t.Errorf("expected non-compliant (0), got %f", v)
+	}
+
+	DeleteRecoveryWindowCompliance("test-cluster", "default")
+	if n := testutil.CollectAndCount(BackupRecoveryWindowCompliant); n != 0 {
+		t.Errorf("expected compliance metric to be deleted, got %d series", n)
+	}
+}
+
 func TestRecordAlertSent(t *testing.T) {
 	AlertsSentTotal.Reset()
 
@@ -300,6 +433,27 @@ func TestDeleteWALMetrics(t *testing.T) {
 	DeleteWALMetrics("test-cluster", "default", "test-instance")
 }
 
+func TestSetClusterInfo(t *testing.T) {
+	ClusterInfo.Reset()
+
+	SetClusterInfo("", "local", "rancher-mgmt")
+	SetClusterInfo("edge-1", "c-m-abc12", "edge-prod-1")
+	// A changed identity replaces the previous series
+	SetClusterInfo("edge-1", "c-m-abc12", "edge-prod-renamed")
+
+	if count := testutil.CollectAndCount(ClusterInfo); count != 2 {
+		t.Errorf("expected 2 cluster_info series, got %d", count)
+	}
+	if value := testutil.ToFloat64(ClusterInfo.WithLabelValues("edge-1", "c-m-abc12", "edge-prod-renamed")); value != 1 {
+		t.Errorf("expected cluster_info 1, got %f", value)
+	}
+
+	DeleteClusterConnectionMetrics("edge-1", "fleet")
+	if count := testutil.CollectAndCount(ClusterInfo); count != 1 {
+		t.Errorf("expected only the local series after deleting the connection, got %d", count)
+	}
+}
+
 func TestMetricsNamespace(t *testing.T) {
 	if MetricsNamespace != "cnpg_storage_manager" {
 		t.Errorf("expected namespace 'cnpg_storage_manager', got '%s'", MetricsNamespace)
@@ -336,3 +490,57 @@ func TestMetricsRegistration(t *testing.T) {
 		}
 	}
 }
+
+func TestSetShard(t *testing.T) {
+	SetShard("2")
+	PoliciesActiveTotal.WithLabelValues("shard-test").Set(1)
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "cnpg_storage_manager_policies_active_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "shard" && label.GetValue() == "2" {
+					return
+				}
+			}
+		}
+	}
+	t.Error("expected the policies_active_total series to carry the shard label")
+}
+
+func TestRecordBackupMethodTimes(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	last := now.Add(-2 * time.Hour)
+	first := now.Add(-72 * time.Hour)
+
+	RecordBackupAge("method-cluster", "default", 1)
+	RecordBackupMethodTimes("method-cluster", "default", "volumeSnapshot", &last, &first, now)
+	RecordBackupMethodTimes("method-cluster", "default", "barmanObjectStore", nil, &first, now)
+
+	value := func(vec *prometheus.GaugeVec, method string) float64 {
+		return testutil.ToFloat64(vec.WithLabelValues("method-cluster", "default", method))
+	}
+	if v := value(BackupLastSuccessTimestamp, "volumeSnapshot"); v != float64(last.Unix()) {
+		t.Errorf("expected last backup timestamp %d, got %f", last.Unix(), v)
+	}
+	if v := value(BackupLastSuccessAgeHours, "volumeSnapshot"); v != 2 {
+		t.Errorf("expected last backup age 2h, got %f", v)
+	}
+	if v := value(BackupFirstRecoverabilityAgeHours, "barmanObjectStore"); v != 72 {
+		t.Errorf("expected recovery point age 72h, got %f", v)
+	}
+	if v := value(BackupLastSuccessAgeHours, ""); v != 1 {
+		t.Errorf("expected the all-methods series to be kept, got %f", v)
+	}
+
+	DeleteBackupMetrics("method-cluster", "default")
+	if n := testutil.CollectAndCount(BackupLastSuccessAgeHours); n != 0 {
+		t.Errorf("expected all backup age series of the cluster to be deleted, %d left", n)
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+	"k8s.io/utils/ptr"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultRemoteWriteInterval is the default interval between remote writes
+	DefaultRemoteWriteInterval = 30 * time.Second
+	// EnvRemoteWriteToken is the environment variable holding the bearer token for remote writes
+	EnvRemoteWriteToken = "REMOTE_WRITE_TOKEN"
+	// remoteWriteVersion is the Prometheus remote write protocol version spoken
+	remoteWriteVersion = "0.1.0"
+)
+
+// RemoteWriteConfig configures the remote write client
+type RemoteWriteConfig struct {
+	// URL is the remote write endpoint, e.g. Mimir's /api/v1/push
+	URL string
+	// Token is sent as a bearer token when set
+	Token string
+	// Interval is the time between writes. Defaults to DefaultRemoteWriteInterval.
+	Interval time.Duration
+	// ExternalLabels are added to every series that does not have them already
+	ExternalLabels map[string]string
+	// Gatherer provides the metrics. Defaults to the controller-runtime registry.
+	Gatherer prometheus.Gatherer
+}
+
+// RemoteWriter pushes the manager's gauges and counters to a Prometheus remote write
+// receiver, for clusters where the manager cannot be scraped. Remediation counters
+// carry the StorageEvent behind their last increment as an exemplar
+type RemoteWriter struct {
+	config     RemoteWriteConfig
+	httpClient *http.Client
+	// sentExemplars holds the timestamp of the last exemplar written per series, so
+	// each exemplar is written once
+	sentExemplars map[string]int64
+}
+
+// remoteSeries is a single sample of a time series with an optional exemplar
+type remoteSeries struct {
+	labels    []*dto.LabelPair
+	value     float64
+	timestamp int64
+	exemplar  *dto.Exemplar
+}
+
+// NewRemoteWriter creates a remote write client
+func NewRemoteWriter(cfg RemoteWriteConfig) *RemoteWriter {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultRemoteWriteInterval
+	}
+	if cfg.Gatherer == nil {
+		cfg.Gatherer = metrics.Registry
+	}
+	return &RemoteWriter{
+		config:        cfg,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+		sentExemplars: map[string]int64{},
+	}
+}
+
+// Start writes the metrics every interval until the context is done. Failed writes
+// are logged and counted; the next write sends the then current values.
+func (w *RemoteWriter) Start(ctx context.Context) error {
+	logger := logf.FromContext(ctx).WithName("remote-write")
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if err := w.Write(ctx, time.Now()); err != nil {
+			logger.Error(err, "Failed to remote write metrics", "url", w.config.URL)
+			RecordRemoteWrite("failure")
+		} else {
+			RecordRemoteWrite("success")
+		}
+	}
+}
+
+// Write sends the current value of every gauge and counter of the manager
+func (w *RemoteWriter) Write(ctx context.Context, now time.Time) error {
+	families, err := w.config.Gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather metrics: %w", err)
+	}
+	series := w.series(families, now)
+	if len(series) == 0 {
+		return nil
+	}
+
+	// Exemplars are marked as sent only once the receiver accepted them
+	pending := map[string]int64{}
+	for i := range series {
+		if exemplar := series[i].exemplar; exemplar != nil {
+			key := seriesKey(series[i].labels)
+			timestamp := exemplar.GetTimestamp().AsTime().UnixMilli()
+			if w.sentExemplars[key] == timestamp {
+				series[i].exemplar = nil
+				continue
+			}
+			pending[key] = timestamp
+		}
+	}
+
+	body := s2.EncodeSnappy(nil, encodeWriteRequest(series))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+	if w.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.config.Token)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("remote write endpoint returned status %d", resp.StatusCode)
+	}
+	for key, timestamp := range pending {
+		w.sentExemplars[key] = timestamp
+	}
+	return nil
+}
+
+// series converts the gauges and counters of the manager to remote write series with
+// sorted labels. Histograms are left to scraping
+func (w *RemoteWriter) series(families []*dto.MetricFamily, now time.Time) []remoteSeries {
+	var series []remoteSeries
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), MetricsNamespace+"_") {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			s := remoteSeries{timestamp: now.UnixMilli()}
+			if metric.TimestampMs != nil {
+				s.timestamp = metric.GetTimestampMs()
+			}
+			switch family.GetType() {
+			case dto.MetricType_GAUGE:
+				s.value = metric.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				s.value = metric.GetCounter().GetValue()
+				s.exemplar = metric.GetCounter().GetExemplar()
+			case dto.MetricType_UNTYPED:
+				s.value = metric.GetUntyped().GetValue()
+			default:
+				continue
+			}
+			s.labels = w.seriesLabels(family.GetName(), metric.GetLabel())
+			series = append(series, s)
+		}
+	}
+	return series
+}
+
+// seriesLabels returns the name, labels and external labels of a series sorted by name
+func (w *RemoteWriter) seriesLabels(name string, labels []*dto.LabelPair) []*dto.LabelPair {
+	result := make([]*dto.LabelPair, 0, len(labels)+len(w.config.ExternalLabels)+1)
+	result = append(result, &dto.LabelPair{Name: ptr.To("__name__"), Value: ptr.To(name)})
+
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		seen[label.GetName()] = true
+		result = append(result, label)
+	}
+	for labelName, value := range w.config.ExternalLabels {
+		if !seen[labelName] {
+			result = append(result, &dto.LabelPair{Name: ptr.To(labelName), Value: ptr.To(value)})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].GetName() < result[j].GetName() })
+	return result
+}
+
+// seriesKey identifies a series by its sorted labels
+func seriesKey(labels []*dto.LabelPair) string {
+	var key strings.Builder
+	for _, label := range labels {
+		key.WriteString(label.GetName())
+		key.WriteByte(0)
+		key.WriteString(label.GetValue())
+		key.WriteByte(0)
+	}
+	return key.String()
+}
+
+// encodeWriteRequest encodes a prometheus.WriteRequest protobuf message:
+// timeseries = 1 (labels = 1, samples = 2, exemplars = 3)
+func encodeWriteRequest(series []remoteSeries) []byte {
+	var request []byte
+	for _, s := range series {
+		var ts []byte
+		for _, label := range s.labels {
+			ts = appendMessage(ts, 1, encodeLabel(label))
+		}
+		var sample []byte
+		sample = appendDouble(sample, 1, s.value)
+		sample = appendInt64(sample, 2, s.timestamp)
+		ts = appendMessage(ts, 2, sample)
+		if s.exemplar != nil {
+			var exemplar []byte
+			for _, label := range s.exemplar.GetLabel() {
+				exemplar = appendMessage(exemplar, 1, encodeLabel(label))
+			}
+			exemplar = appendDouble(exemplar, 2, s.exemplar.GetValue())
+			exemplar = appendInt64(exemplar, 3, s.exemplar.GetTimestamp().AsTime().UnixMilli())
+			ts = appendMessage(ts, 3, exemplar)
+		}
+		request = appendMessage(request, 1, ts)
+	}
+	return request
+}
+
+// encodeLabel encodes a prometheus.Label message: name = 1, value = 2
+func encodeLabel(label *dto.LabelPair) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, label.GetName())
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendString(b, label.GetValue())
+}
+
+func appendMessage(b []byte, field protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendDouble(b []byte, field protowire.Number, value float64) []byte {
+	b = protowire.AppendTag(b, field, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(value))
+}
+
+func appendInt64(b []byte, field protowire.Number, value int64) []byte {
+	b = protowire.AppendTag(b, field, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(value))
+}
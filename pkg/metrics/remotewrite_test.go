@@ -0,0 +1,204 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// writtenSeries is a decoded remote write time series
+type writtenSeries struct {
+	labels   map[string]string
+	value    float64
+	exemplar map[string]string
+}
+
+// decodeWriteRequest decodes the series of a prometheus.WriteRequest
+func decodeWriteRequest(t *testing.T, b []byte) []writtenSeries {
+	t.Helper()
+	var series []writtenSeries
+	for _, ts := range decodeFields(t, b)[1] {
+		s := writtenSeries{labels: map[string]string{}}
+		fields := decodeFields(t, ts)
+		for _, label := range fields[1] {
+			decodeLabel(t, label, s.labels)
+		}
+		for _, sample := range fields[2] {
+			for _, value := range decodeFields(t, sample)[1] {
+				bits, _ := protowire.ConsumeFixed64(value)
+				s.value = math.Float64frombits(bits)
+			}
+		}
+		for _, exemplar := range fields[3] {
+			s.exemplar = map[string]string{}
+			for _, label := range decodeFields(t, exemplar)[1] {
+				decodeLabel(t, label, s.exemplar)
+			}
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+func decodeLabel(t *testing.T, b []byte, into map[string]string) {
+	t.Helper()
+	fields := decodeFields(t, b)
+	into[string(fields[1][0])] = string(fields[2][0])
+}
+
+// decodeFields splits a protobuf message into the raw values of its fields
+func decodeFields(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	t.Helper()
+	fields := map[protowire.Number][][]byte{}
+	for len(b) > 0 {
+		number, wireType, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var value []byte
+		switch wireType {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(number, wireType, b)
+			value = b[:max(n, 0)]
+		}
+		if n < 0 {
+			t.Fatalf("invalid field %d: %v", number, protowire.ParseError(n))
+		}
+		fields[number] = append(fields[number], value)
+		b = b[n:]
+	}
+	return fields
+}
+
+func TestRemoteWriter_Write(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	usage := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace, Name: "pvc_usage_percent", Help: "usage",
+	}, []string{"cluster"})
+	expansions := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace, Name: "expansion_total", Help: "expansions",
+	}, []string{"cluster"})
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace, Name: "latency_seconds", Help: "latency",
+	})
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "other_gauge", Help: "other"})
+	registry.MustRegister(usage, expansions, latency, other)
+
+	usage.WithLabelValues("pg").Set(87.5)
+	incWithEvent(expansions.WithLabelValues("pg"), "pg-expansion-1")
+	latency.Observe(1)
+	other.Set(1)
+
+	var requests []writtenSeries
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		compressed, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := s2.Decode(nil, compressed)
+		if err != nil {
+			t.Fatalf("failed to decode snappy body: %v", err)
+		}
+		requests = decodeWriteRequest(t, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	writer := NewRemoteWriter(RemoteWriteConfig{
+		URL:            server.URL,
+		Gatherer:       registry,
+		Token:          "secret",
+		ExternalLabels: map[string]string{"source_cluster_id": "c-m-1234", "cluster": "ignored"},
+	})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := writer.Write(context.Background(), now); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if got := headers.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", got)
+	}
+	if got := headers.Get("Content-Encoding"); got != "snappy" {
+		t.Errorf("Content-Encoding = %q, want snappy", got)
+	}
+	if got := headers.Get("X-Prometheus-Remote-Write-Version"); got != remoteWriteVersion {
+		t.Errorf("X-Prometheus-Remote-Write-Version = %q, want %s", got, remoteWriteVersion)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("wrote %d series, want 2 (histograms and foreign metrics are skipped)", len(requests))
+	}
+
+	byName := map[string]writtenSeries{}
+	for _, s := range requests {
+		if s.labels["cluster"] != "pg" || s.labels["source_cluster_id"] != "c-m-1234" {
+			t.Errorf("series labels = %v, want cluster=pg and the external labels", s.labels)
+		}
+		byName[strings.TrimPrefix(s.labels["__name__"], MetricsNamespace+"_")] = s
+	}
+	if got := byName["pvc_usage_percent"].value; got != 87.5 {
+		t.Errorf("pvc_usage_percent = %v, want 87.5", got)
+	}
+	expansion := byName["expansion_total"]
+	if expansion.value != 1 {
+		t.Errorf("expansion_total = %v, want 1", expansion.value)
+	}
+	if got := expansion.exemplar[ExemplarLabelStorageEvent]; got != "pg-expansion-1" {
+		t.Errorf("expansion_total exemplar = %v, want %s=pg-expansion-1", expansion.exemplar, ExemplarLabelStorageEvent)
+	}
+
+	// An exemplar is written once
+	if err := writer.Write(context.Background(), now.Add(time.Minute)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, s := range requests {
+		if s.exemplar != nil {
+			t.Errorf("exemplar %v written again", s.exemplar)
+		}
+	}
+}
+
+func TestRemoteWriter_WriteFailure(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Namespace: MetricsNamespace, Name: "up", Help: "up"})
+	registry.MustRegister(gauge)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	writer := NewRemoteWriter(RemoteWriteConfig{URL: server.URL, Gatherer: registry})
+	if err := writer.Write(context.Background(), time.Now()); err == nil {
+		t.Error("Write() error = nil, want an error for a 503 response")
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+)
+
+// replicationLagQuery lists how many bytes of WAL each standby has yet to replay. CNPG
+// sets the application_name of a standby to its instance name
+const replicationLagQuery = `SELECT application_name,
+COALESCE(pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn), 0)::bigint
+FROM pg_stat_replication`
+
+// ReplicationLagCollector reads the replication lag of the standbys of a cluster by
+// running psql inside the primary's postgres container. Like ArchiverCollector it needs
+// pod exec.
+type ReplicationLagCollector struct {
+	runner runner.CommandRunner
+}
+
+// NewReplicationLagCollector creates a collector that runs psql through the given command runner
+func NewReplicationLagCollector(commandRunner runner.CommandRunner) *ReplicationLagCollector {
+	return &ReplicationLagCollector{runner: commandRunner}
+}
+
+// ReplicationLag returns the replay lag in bytes of each standby streaming from the
+// primary, keyed by instance name. Standbys that are not streaming are missing
+func (c *ReplicationLagCollector) ReplicationLag(ctx context.Context, primary *corev1.Pod) (map[string]int64, error) {
+	start := time.Now()
+	defer func() {
+		MetricsCollectionDuration.WithLabelValues("psql_replication_lag").Observe(time.Since(start).Seconds())
+	}()
+
+	command := []string{"psql", "-X", "-A", "-t", "-q", "-d", "postgres", "-F", "|", "-c", replicationLagQuery}
+	stdout, err := c.runner.Run(ctx, primary, runner.PreferredContainer(primary), command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replication lag on %s/%s: %w", primary.Namespace, primary.Name, err)
+	}
+	return parseReplicationLag(stdout)
+}
+
+// parseReplicationLag parses the unaligned psql output of replicationLagQuery
+func parseReplicationLag(output string) (map[string]int64, error) {
+	lag := make(map[string]int64)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		name, value, found := strings.Cut(line, "|")
+		if !found {
+			return nil, fmt.Errorf("unexpected replication lag output %q", line)
+		}
+		bytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replication lag %q", value)
+		}
+		lag[name] = bytes
+	}
+	return lag, nil
+}
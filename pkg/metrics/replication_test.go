@@ -0,0 +1,70 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReplicationLag(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		expected  map[string]int64
+		expectErr bool
+	}{
+		{
+			name:     "streaming standbys",
+			output:   "db-2|0\ndb-3|16777216\n",
+			expected: map[string]int64{"db-2": 0, "db-3": 16777216},
+		},
+		{
+			name:     "no standbys",
+			output:   "\n",
+			expected: map[string]int64{},
+		},
+		{
+			name:      "missing lag",
+			output:    "db-2",
+			expectErr: true,
+		},
+		{
+			name:      "invalid lag",
+			output:    "db-2|far",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lag, err := parseReplicationLag(tt.output)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(lag, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, lag)
+			}
+		})
+	}
+}
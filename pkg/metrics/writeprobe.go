@@ -0,0 +1,118 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
+)
+
+// writeProbeQuery checks that the instance answers queries and can commit a write. The
+// temporary table is dropped on commit, but creating it writes to the catalog and WAL
+const writeProbeQuery = `SET statement_timeout = '10s';
+SELECT 1;
+BEGIN;
+CREATE TEMP TABLE cnpg_storage_manager_write_probe (probed_at timestamptz) ON COMMIT DROP;
+INSERT INTO cnpg_storage_manager_write_probe VALUES (now());
+COMMIT;`
+
+// WriteProbeStatus is the outcome of a write probe
+type WriteProbeStatus string
+
+const (
+	// WriteProbeWritable means the instance committed the probe write
+	WriteProbeWritable WriteProbeStatus = "Writable"
+	// WriteProbeReadOnly means the instance rejected the write as read-only
+	WriteProbeReadOnly WriteProbeStatus = "ReadOnly"
+	// WriteProbeOutOfSpace means the write failed for lack of disk space
+	WriteProbeOutOfSpace WriteProbeStatus = "OutOfSpace"
+	// WriteProbeFailed means the probe failed for another reason, e.g. the instance
+	// could not be reached
+	WriteProbeFailed WriteProbeStatus = "ProbeFailed"
+)
+
+// outOfSpaceErrors are PostgreSQL errors of writes failing on a full volume
+var outOfSpaceErrors = []string{
+	"could not extend file",
+	"No space left on device",
+	"could not write to file",
+}
+
+// readOnlyErrors are PostgreSQL errors of writes rejected by a read-only instance
+var readOnlyErrors = []string{
+	"read-only transaction",
+	"recovery is in progress",
+}
+
+// WriteProbeResult is the result of probing an instance
+type WriteProbeResult struct {
+	// Pod is the probed instance
+	Pod    string
+	Status WriteProbeStatus
+	// Message is the error of a failed probe
+	Message string
+}
+
+// WriteProbe checks whether PostgreSQL accepts writes by running psql inside the
+// postgres container. Disk metrics can look healthy while writes already fail, e.g.
+// on a volume that ran out of inodes. Like ArchiverCollector it needs pod exec.
+type WriteProbe struct {
+	runner runner.CommandRunner
+}
+
+// NewWriteProbe creates a probe that runs psql through the given command runner
+func NewWriteProbe(commandRunner runner.CommandRunner) *WriteProbe {
+	return &WriteProbe{runner: commandRunner}
+}
+
+// Probe runs the write probe against a pod
+func (w *WriteProbe) Probe(ctx context.Context, pod *corev1.Pod) WriteProbeResult {
+	start := time.Now()
+	defer func() {
+		MetricsCollectionDuration.WithLabelValues("psql_write_probe").Observe(time.Since(start).Seconds())
+	}()
+
+	command := []string{"psql", "-X", "-q", "-d", "postgres", "-v", "ON_ERROR_STOP=1", "-c", writeProbeQuery}
+	_, err := w.runner.Run(ctx, pod, runner.PreferredContainer(pod), command)
+	result := classifyWriteProbeError(err)
+	result.Pod = pod.Name
+	return result
+}
+
+// classifyWriteProbeError returns the probe result for the error of the probe command
+func classifyWriteProbeError(err error) WriteProbeResult {
+	if err == nil {
+		return WriteProbeResult{Status: WriteProbeWritable}
+	}
+	message := err.Error()
+	for _, pattern := range outOfSpaceErrors {
+		if strings.Contains(message, pattern) {
+			return WriteProbeResult{Status: WriteProbeOutOfSpace, Message: message}
+		}
+	}
+	for _, pattern := range readOnlyErrors {
+		if strings.Contains(message, pattern) {
+			return WriteProbeResult{Status: WriteProbeReadOnly, Message: message}
+		}
+	}
+	return WriteProbeResult{Status: WriteProbeFailed, Message: message}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClassifyWriteProbeError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected WriteProbeStatus
+	}{
+		{"writable", nil, WriteProbeWritable},
+		{
+			"could not extend file",
+			errors.New(`failed to execute command: exit code 3, stderr: ERROR:  could not extend file ` +
+				`"base/16384/16385": No space left on device`),
+			WriteProbeOutOfSpace,
+		},
+		{
+			"wal write failure",
+			errors.New("stderr: PANIC:  could not write to file \"pg_wal/xlogtemp.42\": No space left on device"),
+			WriteProbeOutOfSpace,
+		},
+		{
+			"read-only transaction",
+			errors.New("stderr: ERROR:  cannot execute CREATE TABLE in a read-only transaction"),
+			WriteProbeReadOnly,
+		},
+		{
+			"unreachable",
+			errors.New(`stderr: psql: error: connection to server on socket "/controller/run/.s.PGSQL.5432" failed`),
+			WriteProbeFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := classifyWriteProbeError(tt.err)
+			if result.Status != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result.Status)
+			}
+			if tt.err != nil && result.Message != tt.err.Error() {
+				t.Errorf("expected the error as message, got %q", result.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package permissions checks the RBAC permissions of the manager with
+// SelfSubjectAccessReviews, so features whose permissions are missing can be reported
+// and held back instead of failing halfway through a remediation.
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultCacheTTL is how long the result of an access review is reused. RBAC changes
+// are picked up after at most this long
+const DefaultCacheTTL = 5 * time.Minute
+
+// Permission is a verb on a resource, needed by a feature of the manager
+type Permission struct {
+	// Feature is what needs the permission, e.g. "expansion"
+	Feature     string
+	Group       string
+	Resource    string
+	Subresource string
+	Verb        string
+	// ClusterScoped permissions, e.g. on nodes, are reviewed once for all namespaces
+	ClusterScoped bool
+}
+
+// String renders the permission the way kubectl auth can-i takes it, e.g.
+// "create pods/exec" or "patch clusters.postgresql.cnpg.io"
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	if p.Subresource != "" {
+		resource += "/" + p.Subresource
+	}
+	return p.Verb + " " + resource
+}
+
+// Missing is a permission the manager lacks, with the namespaces it lacks it in. An
+// empty namespace is a cluster-wide check
+type Missing struct {
+	Permission
+	Namespaces []string
+}
+
+// Summarize renders missing permissions as "create pods/exec in db (wal-cleanup), patch
+// persistentvolumeclaims in db (expansion)"
+func Summarize(missing []Missing) string {
+	parts := make([]string, 0, len(missing))
+	for _, m := range missing {
+		part := m.Permission.String()
+		if len(m.Namespaces) > 0 && m.Namespaces[0] != "" {
+			part += " in " + strings.Join(m.Namespaces, ", ")
+		}
+		if m.Feature != "" {
+			part += " (" + m.Feature + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reviewKey identifies an access review; features share the reviews of their permissions
+type reviewKey struct {
+	namespace   string
+	group       string
+	resource    string
+	subresource string
+	verb        string
+}
+
+type review struct {
+	allowed bool
+	at      time.Time
+}
+
+// Checker runs SelfSubjectAccessReviews and caches their results
+type Checker struct {
+	client client.Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	reviews map[reviewKey]review
+}
+
+// NewChecker creates a checker reviewing access with the given client
+func NewChecker(c client.Client) *Checker {
+	return &Checker{
+		client:  c,
+		ttl:     DefaultCacheTTL,
+		now:     time.Now,
+		reviews: make(map[reviewKey]review),
+	}
+}
+
+// Allowed returns whether the manager may perform the permission's verb in a namespace,
+// or in every namespace when it is empty
+func (c *Checker) Allowed(ctx context.Context, namespace string, p Permission) (bool, error) {
+	key := reviewKey{namespace: namespace, group: p.Group, resource: p.Resource, subresource: p.Subresource, verb: p.Verb}
+	now := c.now()
+
+	c.mu.Lock()
+	cached, ok := c.reviews[key]
+	c.mu.Unlock()
+	if ok && now.Sub(cached.at) < c.ttl {
+		return cached.allowed, nil
+	}
+
+	ssar := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Group:       p.Group,
+				Resource:    p.Resource,
+				Subresource: p.Subresource,
+				Verb:        p.Verb,
+			},
+		},
+	}
+	if err := c.client.Create(ctx, ssar); err != nil {
+		return false, fmt.Errorf("failed to review access to %s: %w", p, err)
+	}
+
+	c.mu.Lock()
+	c.reviews[key] = review{allowed: ssar.Status.Allowed, at: now}
+	c.mu.Unlock()
+	return ssar.Status.Allowed, nil
+}
+
+// Check returns the permissions the manager lacks in any of the namespaces, in the
+// order they were given. Permissions needed by several features are reported for each
+func (c *Checker) Check(ctx context.Context, namespaces []string, required []Permission) ([]Missing, error) {
+	var missing []Missing
+	for _, p := range required {
+		reviewed := namespaces
+		if p.ClusterScoped {
+			reviewed = []string{""}
+		}
+		var denied []string
+		for _, namespace := range reviewed {
+			allowed, err := c.Allowed(ctx, namespace, p)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				denied = append(denied, namespace)
+			}
+		}
+		if len(denied) > 0 {
+			slices.Sort(denied)
+			missing = append(missing, Missing{Permission: p, Namespaces: denied})
+		}
+	}
+	return missing, nil
+}
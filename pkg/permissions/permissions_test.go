@@ -0,0 +1,150 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package permissions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var (
+	exec   = Permission{Feature: "wal-cleanup", Resource: "pods", Subresource: "exec", Verb: "create"}
+	patch  = Permission{Feature: "expansion", Resource: "persistentvolumeclaims", Verb: "patch"}
+	proxy  = Permission{Feature: "metrics", Resource: "nodes", Subresource: "proxy", Verb: "get", ClusterScoped: true}
+	labels = Permission{Feature: "cluster-annotations", Group: "postgresql.cnpg.io", Resource: "clusters", Verb: "patch"}
+)
+
+// newChecker returns a checker whose reviews deny everything allowed does not allow,
+// and the number of reviews run
+func newChecker(allowed func(attrs *authorizationv1.ResourceAttributes) bool) (*Checker, *int) {
+	reviews := 0
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+			ssar, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+			if !ok {
+				return errors.New("unexpected object")
+			}
+			reviews++
+			ssar.Status.Allowed = allowed(ssar.Spec.ResourceAttributes)
+			return nil
+		},
+	}).Build()
+	return NewChecker(c), &reviews
+}
+
+func TestPermission_String(t *testing.T) {
+	for p, want := range map[Permission]string{
+		exec:   "create pods/exec",
+		patch:  "patch persistentvolumeclaims",
+		labels: "patch clusters.postgresql.cnpg.io",
+	} {
+		if got := p.String(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	checker, _ := newChecker(func(attrs *authorizationv1.ResourceAttributes) bool {
+		return attrs.Subresource != "exec" && !(attrs.Resource == "persistentvolumeclaims" && attrs.Namespace == "db")
+	})
+
+	missing, err := checker.Check(context.Background(), []string{"db", "apps"}, []Permission{exec, patch, proxy})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(missing) != 2 {
+		t.Fatalf("expected 2 missing permissions, got %+v", missing)
+	}
+	if missing[0].Permission != exec || len(missing[0].Namespaces) != 2 || missing[0].Namespaces[0] != "apps" {
+		t.Errorf("expected pods/exec to be missing in both namespaces, got %+v", missing[0])
+	}
+	if missing[1].Permission != patch || len(missing[1].Namespaces) != 1 || missing[1].Namespaces[0] != "db" {
+		t.Errorf("expected the PVC patch to be missing in db only, got %+v", missing[1])
+	}
+
+	want := "create pods/exec in apps, db (wal-cleanup), patch persistentvolumeclaims in db (expansion)"
+	if got := Summarize(missing); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestChecker_ClusterScoped(t *testing.T) {
+	var namespaces []string
+	checker, _ := newChecker(func(attrs *authorizationv1.ResourceAttributes) bool {
+		namespaces = append(namespaces, attrs.Namespace)
+		return false
+	})
+
+	missing, err := checker.Check(context.Background(), []string{"db", "apps"}, []Permission{proxy})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaces) != 1 || namespaces[0] != "" {
+		t.Errorf("expected a single cluster-wide review, got namespaces %q", namespaces)
+	}
+	if got := Summarize(missing); got != "get nodes/proxy (metrics)" {
+		t.Errorf("expected no namespaces for a cluster-wide permission, got %q", got)
+	}
+}
+
+func TestChecker_Cache(t *testing.T) {
+	allowed := false
+	checker, reviews := newChecker(func(*authorizationv1.ResourceAttributes) bool { return allowed })
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 2 {
+		if ok, err := checker.Allowed(ctx, "db", exec); err != nil || ok {
+			t.Fatalf("expected pods/exec to be denied, got %v, %v", ok, err)
+		}
+	}
+	if *reviews != 1 {
+		t.Errorf("expected the review to be cached, got %d reviews", *reviews)
+	}
+
+	// A grant is picked up once the cached review expires
+	allowed = true
+	now = now.Add(DefaultCacheTTL)
+	if ok, err := checker.Allowed(ctx, "db", exec); err != nil || !ok {
+		t.Errorf("expected pods/exec to be allowed after the cache expired, got %v, %v", ok, err)
+	}
+	if *reviews != 2 {
+		t.Errorf("expected a second review, got %d", *reviews)
+	}
+}
+
+func TestChecker_Error(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(context.Context, client.WithWatch, client.Object, ...client.CreateOption) error {
+			return errors.New("connection refused")
+		},
+	}).Build()
+
+	if _, err := NewChecker(c).Check(context.Background(), []string{"db"}, []Permission{exec}); err == nil {
+		t.Error("expected the review error to be returned")
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFailureBackoffBase is the delay after a cluster's first consecutive failure
+	DefaultFailureBackoffBase = 30 * time.Second
+	// DefaultFailureBackoffMax caps the delay between evaluations of a failing cluster
+	DefaultFailureBackoffMax = 10 * time.Minute
+	// failureBackoffJitter spreads retries by up to this fraction either way, so
+	// clusters failing for the same reason do not retry in lockstep
+	failureBackoffJitter = 0.2
+)
+
+// FailureBackoff tracks consecutive evaluation failures per cluster and delays the
+// next evaluation of a failing cluster exponentially, with jitter. It is safe for
+// concurrent use.
+type FailureBackoff struct {
+	base time.Duration
+	max  time.Duration
+	now  func() time.Time
+	rand func() float64
+
+	mu      sync.Mutex
+	streaks map[string]*failureStreak
+}
+
+// failureStreak is the failure state of one cluster
+type failureStreak struct {
+	failures    int32
+	nextAttempt time.Time
+}
+
+// NewFailureBackoff creates a backoff doubling from base up to max
+func NewFailureBackoff(base, maxDelay time.Duration) *FailureBackoff {
+	return &FailureBackoff{
+		base:    base,
+		max:     maxDelay,
+		now:     time.Now,
+		rand:    rand.Float64,
+		streaks: make(map[string]*failureStreak),
+	}
+}
+
+// Ready reports whether the cluster is due for evaluation, and otherwise when it
+// will be. Clusters without a failure streak are always due.
+func (b *FailureBackoff) Ready(key string) (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.prune(now)
+	streak, ok := b.streaks[key]
+	if !ok || !now.Before(streak.nextAttempt) {
+		return true, time.Time{}
+	}
+	return false, streak.nextAttempt
+}
+
+// Failure records a failed evaluation and returns the number of consecutive
+// failures and the delay before the next attempt
+func (b *FailureBackoff) Failure(key string) (int32, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	streak, ok := b.streaks[key]
+	if !ok {
+		streak = &failureStreak{}
+		b.streaks[key] = streak
+	}
+	streak.failures++
+
+	delay := b.delay(streak.failures)
+	streak.nextAttempt = b.now().Add(delay)
+	return streak.failures, delay
+}
+
+// Success ends the failure streak of a cluster
+func (b *FailureBackoff) Success(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.streaks, key)
+}
+
+// Failures returns the number of consecutive failures of a cluster
+func (b *FailureBackoff) Failures(key string) int32 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if streak, ok := b.streaks[key]; ok {
+		return streak.failures
+	}
+	return 0
+}
+
+// delay returns the jittered delay after the given number of consecutive failures
+func (b *FailureBackoff) delay(failures int32) time.Duration {
+	delay := b.base
+	for i := int32(1); i < failures && delay < b.max; i++ {
+		delay *= 2
+	}
+	delay = min(delay, b.max)
+
+	jitter := (b.rand()*2 - 1) * failureBackoffJitter
+	return time.Duration(float64(delay) * (1 + jitter))
+}
+
+// prune drops streaks of clusters that were due long ago and never evaluated
+// again, such as deleted clusters. Must be called with mu held.
+func (b *FailureBackoff) prune(now time.Time) {
+	for key, streak := range b.streaks {
+		if now.Sub(streak.nextAttempt) > 2*b.max {
+			delete(b.streaks, key)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailureBackoff(t *testing.T) {
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	backoff := NewFailureBackoff(30*time.Second, 10*time.Minute)
+	backoff.now = func() time.Time { return now }
+	backoff.rand = func() float64 { return 0.5 } // no jitter
+
+	if ready, _ := backoff.Ready("db/pg"); !ready {
+		t.Fatal("expected a cluster without failures to be ready")
+	}
+
+	expected := []time.Duration{
+		30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute,
+		10 * time.Minute, 10 * time.Minute,
+	}
+	for i, want := range expected {
+		failures, delay := backoff.Failure("db/pg")
+		if failures != int32(i+1) || delay != want {
+			t.Errorf("failure %d: expected %s, got %d failures and %s", i+1, want, failures, delay)
+		}
+	}
+
+	ready, next := backoff.Ready("db/pg")
+	if ready || !next.Equal(now.Add(10*time.Minute)) {
+		t.Errorf("expected the cluster to back off until %s, got ready=%v next=%s", now.Add(10*time.Minute), ready, next)
+	}
+	if ready, _ := backoff.Ready("db/other"); !ready {
+		t.Error("expected other clusters to be unaffected")
+	}
+
+	now = now.Add(10 * time.Minute)
+	if ready, _ := backoff.Ready("db/pg"); !ready {
+		t.Error("expected the cluster to be ready once the delay has passed")
+	}
+
+	backoff.Success("db/pg")
+	if backoff.Failures("db/pg") != 0 {
+		t.Error("expected success to end the failure streak")
+	}
+	if _, delay := backoff.Failure("db/pg"); delay != 30*time.Second {
+		t.Errorf("expected the backoff to restart from the base delay, got %s", delay)
+	}
+}
+
+func TestFailureBackoff_Jitter(t *testing.T) {
+	backoff := NewFailureBackoff(time.Minute, 10*time.Minute)
+
+	backoff.rand = func() float64 { return 0 }
+	if _, delay := backoff.Failure("low"); delay != 48*time.Second {
+		t.Errorf("expected -20%% jitter, got %s", delay)
+	}
+	backoff.rand = func() float64 { return 1 }
+	if _, delay := backoff.Failure("high"); delay != 72*time.Second {
+		t.Errorf("expected +20%% jitter, got %s", delay)
+	}
+}
+
+func TestFailureBackoff_PrunesStaleStreaks(t *testing.T) {
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	backoff := NewFailureBackoff(30*time.Second, 10*time.Minute)
+	backoff.now = func() time.Time { return now }
+
+	backoff.Failure("db/deleted")
+	now = now.Add(time.Hour)
+	backoff.Ready("db/other")
+	if backoff.Failures("db/deleted") != 0 {
+		t.Error("expected the stale streak to be pruned")
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+// Reasons of the managed cluster conditions
+const (
+	// ReasonBelowThresholds means storage usage is below every threshold
+	ReasonBelowThresholds = "BelowThresholds"
+	// ReasonMetricsUnavailable means storage usage could not be collected
+	ReasonMetricsUnavailable = "MetricsUnavailable"
+	// ReasonPaused means the cluster is paused and was not evaluated
+	ReasonPaused = "Paused"
+	// ReasonHibernated means the cluster is hibernated and was not evaluated
+	ReasonHibernated = "Hibernated"
+	// ReasonEvaluationFailed means the cluster could not be evaluated
+	ReasonEvaluationFailed = "EvaluationFailed"
+	// ReasonNoExpansion means no expansion event is active
+	ReasonNoExpansion = "NoExpansion"
+	// ReasonAwaitingApproval means the active expansion event waits for approval
+	ReasonAwaitingApproval = "AwaitingApproval"
+	// ReasonCircuitBreakerTripped means remediation failed too often and is blocked
+	ReasonCircuitBreakerTripped = "CircuitBreakerTripped"
+	// ReasonCircuitBreakerClosed means remediation is allowed
+	ReasonCircuitBreakerClosed = "CircuitBreakerClosed"
+	// ReasonPrimaryFenced means the primary is fenced and was not probed
+	ReasonPrimaryFenced = "PrimaryFenced"
+	// ReasonMaxSizeReached means PVCs that need expansion are at the maximum size
+	ReasonMaxSizeReached = "MaxSizeReached"
+	// ReasonBelowMaxSize means every PVC that needs expansion can still grow
+	ReasonBelowMaxSize = "BelowMaxSize"
+	// ReasonQuotaSufficient means the namespace quotas leave room for the next expansion
+	ReasonQuotaSufficient = "QuotaSufficient"
+	// ReasonQuotaInsufficient means a namespace quota or limit would reject the next expansion
+	ReasonQuotaInsufficient = "QuotaInsufficient"
+)
+
+// ClusterConditionState is the evaluated state the conditions of a managed cluster
+// are derived from
+type ClusterConditionState struct {
+	// Threshold is the threshold evaluation of the cluster's storage usage. Nil when
+	// usage could not be collected
+	Threshold *ThresholdResult
+	// Paused marks a cluster that was skipped because it is paused
+	Paused bool
+	// Hibernated marks a cluster that was skipped because it is hibernated
+	Hibernated bool
+	// Err is the error that stopped the cluster from being evaluated
+	Err error
+	// Expansion is the active expansion StorageEvent of the cluster, nil when none
+	Expansion *cnpgv1alpha1.StorageEvent
+	// ExpansionAwaitingApproval marks an expansion event that has not been approved
+	ExpansionAwaitingApproval bool
+	// Backup is the backup status of the cluster, nil when backups are not monitored
+	Backup *cnpgv1alpha1.ClusterBackupStatus
+	// CircuitBreakerOpen reports whether remediation is blocked after repeated failures
+	CircuitBreakerOpen bool
+	// CircuitBreakerFailures is the number of consecutive remediation failures
+	CircuitBreakerFailures int32
+	// WriteProbe is the write probe result of the primary, nil when it was not probed
+	WriteProbe *metrics.WriteProbeResult
+	// PrimaryFenced marks a cluster whose primary is fenced, so writes are not probed
+	PrimaryFenced bool
+	// MaxSizeReached are the PVCs that need expansion but are at the maximum size
+	MaxSizeReached []string
+	// QuotaMonitored marks a cluster whose namespace quotas are checked
+	QuotaMonitored bool
+	// QuotaShortfall is why the namespace quotas cannot hold the next expansion, empty
+	// when they can
+	QuotaShortfall string
+}
+
+// ClusterConditions returns the conditions of a managed cluster for the given state.
+// Conditions whose status did not change keep their lastTransitionTime from previous
+func ClusterConditions(previous []metav1.Condition, state ClusterConditionState) []metav1.Condition {
+	conditions := make([]metav1.Condition, len(previous))
+	copy(conditions, previous)
+
+	meta.SetStatusCondition(&conditions, storageHealthyCondition(state))
+	meta.SetStatusCondition(&conditions, expansionCondition(state))
+	if state.Backup != nil {
+		meta.SetStatusCondition(&conditions, backupHealthyCondition(state.Backup))
+	} else {
+		meta.RemoveStatusCondition(&conditions, cnpgv1alpha1.ManagedClusterConditionBackupHealthy)
+	}
+	meta.SetStatusCondition(&conditions, circuitBreakerCondition(state))
+	if state.WriteProbe != nil || state.PrimaryFenced {
+		meta.SetStatusCondition(&conditions, writableCondition(state))
+	} else {
+		meta.RemoveStatusCondition(&conditions, cnpgv1alpha1.ManagedClusterConditionWritable)
+	}
+	if len(state.MaxSizeReached) > 0 ||
+		meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionMaxSizeReached) != nil {
+		meta.SetStatusCondition(&conditions, maxSizeCondition(state))
+	}
+	if state.QuotaMonitored {
+		meta.SetStatusCondition(&conditions, quotaHeadroomCondition(state))
+	} else {
+		meta.RemoveStatusCondition(&conditions, cnpgv1alpha1.ManagedClusterConditionQuotaHeadroom)
+	}
+
+	return conditions
+}
+
+func storageHealthyCondition(state ClusterConditionState) metav1.Condition {
+	condition := metav1.Condition{
+		Type:   cnpgv1alpha1.ManagedClusterConditionStorageHealthy,
+		Status: metav1.ConditionUnknown,
+	}
+
+	switch {
+	case state.Err != nil:
+		condition.Reason = ReasonEvaluationFailed
+		condition.Message = state.Err.Error()
+	case state.Paused:
+		condition.Reason = ReasonPaused
+		condition.Message = "Cluster is paused and was not evaluated"
+	case state.Hibernated:
+		condition.Reason = ReasonHibernated
+		condition.Message = "Cluster is hibernated and has no running instances to evaluate"
+	case state.Threshold == nil:
+		condition.Reason = ReasonMetricsUnavailable
+		condition.Message = "Storage metrics could not be collected"
+	case state.Threshold.Level == ThresholdLevelNormal:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonBelowThresholds
+		condition.Message = fmt.Sprintf("Storage usage is %.1f%%", state.Threshold.CurrentUsagePercent)
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ThresholdReason(state.Threshold.Level)
+		condition.Message = state.Threshold.Message
+		if condition.Message == "" {
+			condition.Message = fmt.Sprintf("Storage usage is %.1f%%", state.Threshold.CurrentUsagePercent)
+		}
+	}
+	return condition
+}
+
+// ThresholdReason returns the StorageHealthy reason of a breached threshold level, e.g.
+// CriticalThresholdExceeded
+func ThresholdReason(level ThresholdLevel) string {
+	name := string(level)
+	if name == "" {
+		return "ThresholdExceeded"
+	}
+	return strings.ToUpper(name[:1]) + name[1:] + "ThresholdExceeded"
+}
+
+func expansionCondition(state ClusterConditionState) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    cnpgv1alpha1.ManagedClusterConditionExpansionInProgress,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonNoExpansion,
+		Message: "No expansion is pending or running",
+	}
+
+	event := state.Expansion
+	if event == nil {
+		return condition
+	}
+
+	phase := event.Status.Phase
+	if phase == "" {
+		phase = cnpgv1alpha1.EventPhasePending
+	}
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "Expansion" + string(phase)
+	if state.ExpansionAwaitingApproval {
+		condition.Reason = ReasonAwaitingApproval
+	}
+	condition.Message = fmt.Sprintf("StorageEvent %s is %s", event.Name, phase)
+	if event.Spec.Reason != "" {
+		condition.Message += ": " + event.Spec.Reason
+	}
+	return condition
+}
+
+func backupHealthyCondition(backup *cnpgv1alpha1.ClusterBackupStatus) metav1.Condition {
+	condition := metav1.Condition{
+		Type:   cnpgv1alpha1.ManagedClusterConditionBackupHealthy,
+		Status: metav1.ConditionFalse,
+		Reason: backup.BackupHealthStatus,
+	}
+
+	switch backup.BackupHealthStatus {
+	case "Healthy":
+		condition.Status = metav1.ConditionTrue
+		condition.Message = "Backups meet the policy's requirements"
+		if backup.LastBackupTime != nil {
+			condition.Message = fmt.Sprintf("Last backup is %d hours old", backup.LastBackupAgeHours)
+		}
+	case "NoBackupConfigured":
+		condition.Message = "No backup is configured for the cluster"
+	case "NoSuccessfulBackup":
+		condition.Message = "No successful backup is recorded"
+	case "BackupTooOld":
+		condition.Message = fmt.Sprintf("Last backup is %d hours old", backup.LastBackupAgeHours)
+	case "RecoveryPointTooOld":
+		condition.Message = "First recovery point is older than allowed"
+	case "ArchivingNotWorking":
+		condition.Message = "Continuous WAL archiving is not working"
+	case "":
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "Unknown"
+		condition.Message = "Backup health was not determined"
+	default:
+		condition.Message = "Backup health is " + backup.BackupHealthStatus
+	}
+	return condition
+}
+
+func circuitBreakerCondition(state ClusterConditionState) metav1.Condition {
+	if state.CircuitBreakerOpen {
+		return metav1.Condition{
+			Type:   cnpgv1alpha1.ManagedClusterConditionCircuitBreakerOpen,
+			Status: metav1.ConditionTrue,
+			Reason: ReasonCircuitBreakerTripped,
+			Message: fmt.Sprintf("Remediation is blocked after %d consecutive failures",
+				state.CircuitBreakerFailures),
+		}
+	}
+	return metav1.Condition{
+		Type:    cnpgv1alpha1.ManagedClusterConditionCircuitBreakerOpen,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonCircuitBreakerClosed,
+		Message: "Remediation is allowed",
+	}
+}
+
+func writableCondition(state ClusterConditionState) metav1.Condition {
+	if state.PrimaryFenced {
+		return metav1.Condition{
+			Type:    cnpgv1alpha1.ManagedClusterConditionWritable,
+			Status:  metav1.ConditionFalse,
+			Reason:  ReasonPrimaryFenced,
+			Message: "The primary is fenced and does not accept writes",
+		}
+	}
+
+	probe := state.WriteProbe
+	condition := metav1.Condition{
+		Type:    cnpgv1alpha1.ManagedClusterConditionWritable,
+		Status:  metav1.ConditionFalse,
+		Reason:  string(probe.Status),
+		Message: probe.Message,
+	}
+	switch probe.Status {
+	case metrics.WriteProbeWritable:
+		condition.Status = metav1.ConditionTrue
+		condition.Message = fmt.Sprintf("Primary %s committed the write probe", probe.Pod)
+	case metrics.WriteProbeFailed:
+		condition.Status = metav1.ConditionUnknown
+	}
+	return condition
+}
+
+func maxSizeCondition(state ClusterConditionState) metav1.Condition {
+	if len(state.MaxSizeReached) == 0 {
+		return metav1.Condition{
+			Type:    cnpgv1alpha1.ManagedClusterConditionMaxSizeReached,
+			Status:  metav1.ConditionFalse,
+			Reason:  ReasonBelowMaxSize,
+			Message: "No PVC that needs expansion is at the maximum size",
+		}
+	}
+	return metav1.Condition{
+		Type:    cnpgv1alpha1.ManagedClusterConditionMaxSizeReached,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonMaxSizeReached,
+		Message: fmt.Sprintf("PVCs at the maximum size: %s", strings.Join(state.MaxSizeReached, ", ")),
+	}
+}
+
+func quotaHeadroomCondition(state ClusterConditionState) metav1.Condition {
+	if state.QuotaShortfall == "" {
+		return metav1.Condition{
+			Type:    cnpgv1alpha1.ManagedClusterConditionQuotaHeadroom,
+			Status:  metav1.ConditionTrue,
+			Reason:  ReasonQuotaSufficient,
+			Message: "Namespace quotas leave room for the next expansion",
+		}
+	}
+	return metav1.Condition{
+		Type:    cnpgv1alpha1.ManagedClusterConditionQuotaHeadroom,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonQuotaInsufficient,
+		Message: state.QuotaShortfall,
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+func TestClusterConditions(t *testing.T) {
+	expansion := &cnpgv1alpha1.StorageEvent{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg-expansion-1"},
+		Spec:       cnpgv1alpha1.StorageEventSpec{Reason: "threshold breach: 86.0%"},
+		Status:     cnpgv1alpha1.StorageEventStatus{Phase: cnpgv1alpha1.EventPhaseInProgress},
+	}
+
+	tests := []struct {
+		name  string
+		state ClusterConditionState
+		want  map[string]metav1.Condition
+	}{
+		{
+			name: "healthy",
+			state: ClusterConditionState{
+				Threshold: &ThresholdResult{Level: ThresholdLevelNormal, CurrentUsagePercent: 42},
+			},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionStorageHealthy: {
+					Status: metav1.ConditionTrue, Reason: ReasonBelowThresholds,
+				},
+				cnpgv1alpha1.ManagedClusterConditionExpansionInProgress: {
+					Status: metav1.ConditionFalse, Reason: ReasonNoExpansion,
+				},
+				cnpgv1alpha1.ManagedClusterConditionCircuitBreakerOpen: {
+					Status: metav1.ConditionFalse, Reason: ReasonCircuitBreakerClosed,
+				},
+			},
+		},
+		{
+			name: "critical and expanding",
+			state: ClusterConditionState{
+				Threshold: &ThresholdResult{Level: ThresholdLevelCritical, Message: "Critical: 86.0% >= 85%"},
+				Expansion: expansion,
+				Backup:    &cnpgv1alpha1.ClusterBackupStatus{BackupHealthStatus: "BackupTooOld", LastBackupAgeHours: 30},
+			},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionStorageHealthy: {
+					Status: metav1.ConditionFalse, Reason: "CriticalThresholdExceeded",
+				},
+				cnpgv1alpha1.ManagedClusterConditionExpansionInProgress: {
+					Status: metav1.ConditionTrue, Reason: "ExpansionInProgress",
+				},
+				cnpgv1alpha1.ManagedClusterConditionBackupHealthy: {Status: metav1.ConditionFalse, Reason: "BackupTooOld"},
+			},
+		},
+		{
+			name: "awaiting approval with open circuit breaker",
+			state: ClusterConditionState{
+				Threshold:                 &ThresholdResult{Level: ThresholdLevelEmergency},
+				Expansion:                 expansion,
+				ExpansionAwaitingApproval: true,
+				CircuitBreakerOpen:        true,
+				CircuitBreakerFailures:    3,
+			},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionStorageHealthy: {
+					Status: metav1.ConditionFalse, Reason: "EmergencyThresholdExceeded",
+				},
+				cnpgv1alpha1.ManagedClusterConditionExpansionInProgress: {
+					Status: metav1.ConditionTrue, Reason: ReasonAwaitingApproval,
+				},
+				cnpgv1alpha1.ManagedClusterConditionCircuitBreakerOpen: {
+					Status: metav1.ConditionTrue, Reason: ReasonCircuitBreakerTripped,
+				},
+			},
+		},
+		{
+			name:  "hibernated",
+			state: ClusterConditionState{Hibernated: true},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionStorageHealthy: {
+					Status: metav1.ConditionUnknown, Reason: ReasonHibernated,
+				},
+			},
+		},
+		{
+			name: "writable",
+			state: ClusterConditionState{
+				WriteProbe: &metrics.WriteProbeResult{Pod: "pg-1", Status: metrics.WriteProbeWritable},
+			},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionWritable: {Status: metav1.ConditionTrue, Reason: "Writable"},
+			},
+		},
+		{
+			name: "out of space",
+			state: ClusterConditionState{
+				WriteProbe: &metrics.WriteProbeResult{Pod: "pg-1", Status: metrics.WriteProbeOutOfSpace,
+					Message: "ERROR:  could not extend file"},
+			},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionWritable: {Status: metav1.ConditionFalse, Reason: "OutOfSpace"},
+			},
+		},
+		{
+			name: "probe failed",
+			state: ClusterConditionState{
+				WriteProbe: &metrics.WriteProbeResult{Pod: "pg-1", Status: metrics.WriteProbeFailed,
+					Message: "connection refused"},
+			},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionWritable: {Status: metav1.ConditionUnknown, Reason: "ProbeFailed"},
+			},
+		},
+		{
+			name:  "primary fenced",
+			state: ClusterConditionState{PrimaryFenced: true},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionWritable: {Status: metav1.ConditionFalse, Reason: ReasonPrimaryFenced},
+			},
+		},
+		{
+			name:  "max size reached",
+			state: ClusterConditionState{MaxSizeReached: []string{"pg-1", "pg-2"}},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionMaxSizeReached: {
+					Status: metav1.ConditionTrue, Reason: ReasonMaxSizeReached,
+				},
+			},
+		},
+		{
+			name:  "quota headroom",
+			state: ClusterConditionState{QuotaMonitored: true},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionQuotaHeadroom: {
+					Status: metav1.ConditionTrue, Reason: ReasonQuotaSufficient,
+				},
+			},
+		},
+		{
+			name: "quota insufficient",
+			state: ClusterConditionState{
+				QuotaMonitored: true,
+				QuotaShortfall: "ResourceQuota db/storage exceeded",
+			},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionQuotaHeadroom: {
+					Status: metav1.ConditionFalse, Reason: ReasonQuotaInsufficient,
+				},
+			},
+		},
+		{
+			name:  "metrics unavailable",
+			state: ClusterConditionState{},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionStorageHealthy: {
+					Status: metav1.ConditionUnknown, Reason: ReasonMetricsUnavailable,
+				},
+			},
+		},
+		{
+			name:  "evaluation failed",
+			state: ClusterConditionState{Err: errors.New("failed to get cluster pods")},
+			want: map[string]metav1.Condition{
+				cnpgv1alpha1.ManagedClusterConditionStorageHealthy: {
+					Status: metav1.ConditionUnknown, Reason: ReasonEvaluationFailed,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions := ClusterConditions(nil, tt.state)
+			for conditionType, want := range tt.want {
+				got := meta.FindStatusCondition(conditions, conditionType)
+				if got == nil {
+					t.Fatalf("expected condition %s, got %v", conditionType, conditions)
+				}
+				if got.Status != want.Status || got.Reason != want.Reason {
+					t.Errorf("%s = %s/%s, want %s/%s", conditionType, got.Status, got.Reason, want.Status, want.Reason)
+				}
+				if got.Message == "" || got.LastTransitionTime.IsZero() {
+					t.Errorf("%s should have a message and transition time, got %+v", conditionType, got)
+				}
+			}
+			if tt.state.Backup == nil &&
+				meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionBackupHealthy) != nil {
+				t.Error("BackupHealthy should only be set when backups are monitored")
+			}
+			if tt.state.WriteProbe == nil && !tt.state.PrimaryFenced &&
+				meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionWritable) != nil {
+				t.Error("Writable should only be set when writes are probed")
+			}
+			if len(tt.state.MaxSizeReached) == 0 &&
+				meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionMaxSizeReached) != nil {
+				t.Error("MaxSizeReached should only be set once a PVC reaches the maximum size")
+			}
+			if !tt.state.QuotaMonitored &&
+				meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionQuotaHeadroom) != nil {
+				t.Error("QuotaHeadroom should only be set when quotas are monitored")
+			}
+		})
+	}
+}
+
+func TestClusterConditions_TransitionTime(t *testing.T) {
+	since := metav1.NewTime(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC))
+	previous := []metav1.Condition{
+		{
+			Type:               cnpgv1alpha1.ManagedClusterConditionStorageHealthy,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonBelowThresholds,
+			LastTransitionTime: since,
+		},
+		{
+			Type:               cnpgv1alpha1.ManagedClusterConditionBackupHealthy,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Healthy",
+			LastTransitionTime: since,
+		},
+	}
+
+	conditions := ClusterConditions(previous, ClusterConditionState{
+		Threshold: &ThresholdResult{Level: ThresholdLevelNormal, CurrentUsagePercent: 50},
+	})
+
+	storage := meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionStorageHealthy)
+	if storage == nil || !storage.LastTransitionTime.Equal(&since) {
+		t.Errorf("unchanged condition should keep its transition time, got %+v", storage)
+	}
+	if meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionBackupHealthy) != nil {
+		t.Error("BackupHealthy should be removed when backups are no longer monitored")
+	}
+	if previous[0].Reason != ReasonBelowThresholds || len(previous) != 2 {
+		t.Error("previous conditions should not be modified")
+	}
+
+	conditions = ClusterConditions(conditions, ClusterConditionState{
+		Threshold: &ThresholdResult{Level: ThresholdLevelWarning, Message: "Warning: 72.0% >= 70%"},
+	})
+	storage = meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionStorageHealthy)
+	if storage.Status != metav1.ConditionFalse || storage.LastTransitionTime.Equal(&since) {
+		t.Errorf("changed condition should get a new transition time, got %+v", storage)
+	}
+
+	conditions = ClusterConditions(conditions, ClusterConditionState{MaxSizeReached: []string{"pg-1"}})
+	conditions = ClusterConditions(conditions, ClusterConditionState{})
+	maxSize := meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionMaxSizeReached)
+	if maxSize == nil || maxSize.Status != metav1.ConditionFalse || maxSize.Reason != ReasonBelowMaxSize {
+		t.Errorf("MaxSizeReached should turn False once the PVCs can grow again, got %+v", maxSize)
+	}
+}
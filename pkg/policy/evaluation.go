@@ -21,6 +21,7 @@ import (
 	"time"
 
 	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/clock"
 )
 
 // ThresholdLevel represents a threshold level
@@ -43,8 +44,12 @@ const (
 type ThresholdResult struct {
 	// CurrentUsagePercent is the current storage usage percentage
 	CurrentUsagePercent float64
+	// FreeBytes is the free space of the volume with the least, 0 when unknown
+	FreeBytes int64
 	// Level is the highest breached threshold level
 	Level ThresholdLevel
+	// Thresholds are the thresholds usage was evaluated against, with defaults applied
+	Thresholds cnpgv1alpha1.ThresholdsConfig
 	// ShouldAlert indicates if an alert should be sent
 	ShouldAlert bool
 	// ShouldExpand indicates if PVC expansion should be triggered
@@ -85,27 +90,37 @@ const (
 type Evaluator struct {
 	// HysteresisPercent is the percentage below threshold before clearing alerts
 	HysteresisPercent float64
+	// Clock is the time cooldowns are checked against. The system clock when nil
+	Clock clock.Clock
 }
 
 // NewEvaluator creates a new threshold evaluator
 func NewEvaluator() *Evaluator {
 	return &Evaluator{
 		HysteresisPercent: 2.0, // 2% hysteresis
+		Clock:             clock.Real,
 	}
 }
 
+// now returns the current time of the evaluator's clock
+func (e *Evaluator) now() time.Time {
+	return clock.OrReal(e.Clock).Now()
+}
+
 // EvaluateThresholds evaluates current usage against policy thresholds
 func (e *Evaluator) EvaluateThresholds(usagePercent float64, thresholds cnpgv1alpha1.ThresholdsConfig) ThresholdResult {
+	// Get threshold values with defaults
+	effective := EffectiveThresholds(thresholds)
 	result := ThresholdResult{
 		CurrentUsagePercent: usagePercent,
 		Level:               ThresholdLevelNormal,
+		Thresholds:          effective,
 	}
 
-	// Get threshold values with defaults
-	warningThreshold := getThresholdOrDefault(thresholds.Warning, 70)
-	criticalThreshold := getThresholdOrDefault(thresholds.Critical, 80)
-	expansionThreshold := getThresholdOrDefault(thresholds.Expansion, 85)
-	emergencyThreshold := getThresholdOrDefault(thresholds.Emergency, 90)
+	warningThreshold := effective.Warning
+	criticalThreshold := effective.Critical
+	expansionThreshold := effective.Expansion
+	emergencyThreshold := effective.Emergency
 
 	// Evaluate thresholds from highest to lowest
 	if usagePercent >= float64(emergencyThreshold) {
@@ -150,6 +165,89 @@ func (e *Evaluator) EvaluateThresholds(usagePercent float64, thresholds cnpgv1al
 	return result
 }
 
+// EvaluateUsage evaluates usage like EvaluateThresholds and, when freeBytes, the free
+// space of the volume with the least, is known, also against the free space thresholds.
+// The higher of the two levels wins
+func (e *Evaluator) EvaluateUsage(
+	usagePercent float64,
+	freeBytes int64,
+	thresholds cnpgv1alpha1.ThresholdsConfig,
+) ThresholdResult {
+	result := e.EvaluateThresholds(usagePercent, thresholds)
+	result.FreeBytes = freeBytes
+
+	level, headroom := headroomLevel(freeBytes, thresholds)
+	if levelRank(level) <= levelRank(result.Level) {
+		return result
+	}
+	result.Level = level
+	result.ShouldAlert = true
+	result.ShouldExpand = level == ThresholdLevelExpansion || level == ThresholdLevelEmergency
+	result.ShouldCleanupWAL = level == ThresholdLevelEmergency
+	result.Message = fmt.Sprintf(
+		"%s: %.1fGi free is below %s headroom %s (usage %.1f%%)",
+		levelMessagePrefix[level],
+		float64(freeBytes)/(1<<30),
+		level,
+		headroom,
+		usagePercent,
+	)
+	return result
+}
+
+// levelMessagePrefix is how threshold messages of each breached level start
+var levelMessagePrefix = map[ThresholdLevel]string{
+	ThresholdLevelWarning:   "Warning",
+	ThresholdLevelCritical:  "Critical",
+	ThresholdLevelExpansion: "Expansion required",
+	ThresholdLevelEmergency: "Emergency",
+}
+
+// headroomLevel returns the highest level whose free space threshold freeBytes is below,
+// and that threshold. The absolute floor counts as an emergency threshold
+func headroomLevel(freeBytes int64, thresholds cnpgv1alpha1.ThresholdsConfig) (ThresholdLevel, string) {
+	if freeBytes <= 0 {
+		return ThresholdLevelNormal, ""
+	}
+	if floor := thresholds.AbsoluteFreeBytesFloor; floor != nil && freeBytes < floor.Value() {
+		return ThresholdLevelEmergency, floor.String()
+	}
+	headroom := thresholds.HeadroomGi
+	if headroom == nil {
+		return ThresholdLevelNormal, ""
+	}
+	for _, threshold := range []struct {
+		level ThresholdLevel
+		gi    int32
+	}{
+		{ThresholdLevelEmergency, headroom.Emergency},
+		{ThresholdLevelExpansion, headroom.Expansion},
+		{ThresholdLevelCritical, headroom.Critical},
+		{ThresholdLevelWarning, headroom.Warning},
+	} {
+		if threshold.gi > 0 && freeBytes < int64(threshold.gi)<<30 {
+			return threshold.level, fmt.Sprintf("%dGi", threshold.gi)
+		}
+	}
+	return ThresholdLevelNormal, ""
+}
+
+// levelRank orders threshold levels from normal to emergency
+func levelRank(level ThresholdLevel) int {
+	switch level {
+	case ThresholdLevelWarning:
+		return 1
+	case ThresholdLevelCritical:
+		return 2
+	case ThresholdLevelExpansion:
+		return 3
+	case ThresholdLevelEmergency:
+		return 4
+	default:
+		return 0
+	}
+}
+
 // GetRecommendedActions returns a list of recommended actions based on evaluation
 func (e *Evaluator) GetRecommendedActions(
 	result ThresholdResult,
@@ -279,7 +377,7 @@ func (e *Evaluator) CheckCooldown(lastAction *time.Time, cooldownMinutes int32)
 
 	cooldown := time.Duration(cooldownMinutes) * time.Minute
 	nextAllowed := lastAction.Add(cooldown)
-	now := time.Now()
+	now := e.now()
 
 	if now.Before(nextAllowed) {
 		return false, nextAllowed.Sub(now)
@@ -288,6 +386,90 @@ func (e *Evaluator) CheckCooldown(lastAction *time.Time, cooldownMinutes int32)
 	return true, 0
 }
 
+// CheckSustained checks if a threshold breached since the given time, nil for a breach
+// that just started, has lasted sustainedMinutes
+func (e *Evaluator) CheckSustained(since *time.Time, sustainedMinutes int32) (bool, time.Duration) {
+	if sustainedMinutes <= 0 {
+		return true, 0
+	}
+
+	sustained := time.Duration(sustainedMinutes) * time.Minute
+	now := e.now()
+	start := now
+	if since != nil {
+		start = *since
+	}
+	if elapsed := now.Sub(start); elapsed < sustained {
+		return false, sustained - elapsed
+	}
+
+	return true, 0
+}
+
+// ShouldAlertPartialSuccess determines if a policy that has continuously reported
+// PartialSuccess since the given time should alert. After the first alert, the
+// alert is repeated every escalationMinutes while the condition persists.
+func (e *Evaluator) ShouldAlertPartialSuccess(since, lastAlert *time.Time, alertMinutes, escalationMinutes int32) bool {
+	if since == nil || alertMinutes <= 0 {
+		return false
+	}
+
+	now := e.now()
+	if now.Sub(*since) < time.Duration(alertMinutes)*time.Minute {
+		return false
+	}
+
+	if lastAlert == nil || lastAlert.Before(*since) {
+		return true
+	}
+
+	allowed, _ := e.CheckCooldown(lastAlert, getThresholdOrDefault(escalationMinutes, 15))
+	return allowed
+}
+
+// IsPolicyDryRun reports whether the policy itself is in dry-run mode at the given time.
+// A dryRunUntil trial period takes precedence over the dryRun flag.
+func IsPolicyDryRun(p *cnpgv1alpha1.StoragePolicy, now time.Time) bool {
+	if p.Spec.DryRunUntil != nil {
+		return now.Before(p.Spec.DryRunUntil.Time)
+	}
+	return p.Spec.DryRun
+}
+
+// DryRunExpired reports whether the policy's dryRunUntil trial period has ended
+func DryRunExpired(p *cnpgv1alpha1.StoragePolicy, now time.Time) bool {
+	return p.Spec.DryRunUntil != nil && !now.Before(p.Spec.DryRunUntil.Time)
+}
+
+// IsActionDryRun reports whether remediation of the given type only runs in dry-run
+// mode, because of the policy-wide dry-run or the action's own dryRun flag
+func IsActionDryRun(p *cnpgv1alpha1.StoragePolicy, eventType cnpgv1alpha1.EventType, now time.Time) bool {
+	if IsPolicyDryRun(p, now) {
+		return true
+	}
+	switch eventType {
+	case cnpgv1alpha1.EventTypeExpansion:
+		return p.Spec.Expansion.DryRun
+	case cnpgv1alpha1.EventTypeWALCleanup:
+		return p.Spec.WALCleanup.DryRun
+	case cnpgv1alpha1.EventTypeStorageClassMigration:
+		return p.Spec.StorageClassMigration != nil && p.Spec.StorageClassMigration.DryRun
+	case cnpgv1alpha1.EventTypeVolumeAttributesChange:
+		return p.Spec.VolumeAttributes != nil && p.Spec.VolumeAttributes.DryRun
+	default:
+		return false
+	}
+}
+
+// IsPolicyPaused reports whether remediation is paused for the whole policy at the given
+// time. A pauseUntil maintenance window takes precedence over the paused flag.
+func IsPolicyPaused(p *cnpgv1alpha1.StoragePolicy, now time.Time) bool {
+	if p.Spec.PauseUntil != nil {
+		return now.Before(p.Spec.PauseUntil.Time)
+	}
+	return p.Spec.Paused
+}
+
 // getThresholdOrDefault returns the threshold value or a default if zero
 func getThresholdOrDefault(value, defaultValue int32) int32 {
 	if value == 0 {
@@ -307,6 +489,13 @@ type EvaluationContext struct {
 	LastWALCleanup     *time.Time
 	ActiveRemediation  bool
 	CircuitBreakerOpen bool
+	// ReplicaUsagePercent is the highest volume usage of any replica, 0 when unknown
+	ReplicaUsagePercent float64
+	// FreeBytes is the free space of the volume with the least, 0 when unknown
+	FreeBytes int64
+	// ExpansionBreachedSince is when the expansion threshold started to be breached,
+	// nil when it was not at the previous evaluation
+	ExpansionBreachedSince *time.Time
 }
 
 // FullEvaluation performs a complete evaluation with all checks
@@ -317,7 +506,7 @@ func (e *Evaluator) FullEvaluation(
 	result := &EvaluationResult{
 		ClusterName:   ctx.ClusterName,
 		Namespace:     ctx.Namespace,
-		EvaluatedAt:   time.Now(),
+		EvaluatedAt:   e.now(),
 		Actions:       []ActionRecommendation{},
 		Blocked:       false,
 		BlockedReason: "",
@@ -338,11 +527,12 @@ func (e *Evaluator) FullEvaluation(
 	}
 
 	// Evaluate thresholds
-	thresholdResult := e.EvaluateThresholds(usagePercent, policy.Spec.Thresholds)
+	thresholdResult := e.EvaluateUsage(usagePercent, ctx.FreeBytes, policy.Spec.Thresholds)
 	result.ThresholdResult = thresholdResult
 
 	// Get recommended actions
 	actions := e.GetRecommendedActions(thresholdResult, policy)
+	actions = append(actions, e.replicaWALCleanupActions(ctx, policy, actions)...)
 
 	// Check cooldowns and filter actions
 	for _, action := range actions {
@@ -352,6 +542,12 @@ func (e *Evaluator) FullEvaluation(
 				action.Reason = fmt.Sprintf("%s (blocked: cooldown %v remaining)", action.Reason, remaining.Round(time.Second))
 				action.Parameters["blocked"] = true
 				action.Parameters["cooldown_remaining"] = remaining.Seconds()
+			} else if sustained, remaining := e.CheckSustained(ctx.ExpansionBreachedSince,
+				policy.Spec.Expansion.SustainedMinutes); !sustained {
+				action.Reason = fmt.Sprintf("%s (blocked: breach not sustained, %v remaining)",
+					action.Reason, remaining.Round(time.Second))
+				action.Parameters["blocked"] = true
+				action.Parameters["sustained_remaining"] = remaining.Seconds()
 			}
 		case ActionTypeWALCleanup:
 			if allowed, remaining := e.CheckCooldown(ctx.LastWALCleanup, policy.Spec.WALCleanup.CooldownMinutes); !allowed {
@@ -372,6 +568,36 @@ func (e *Evaluator) FullEvaluation(
 	return result, nil
 }
 
+// replicaWALCleanupActions recommends WAL cleanup when a replica volume breaches the
+// emergency threshold while the cluster as a whole does not
+func (e *Evaluator) replicaWALCleanupActions(
+	ctx EvaluationContext,
+	policy *cnpgv1alpha1.StoragePolicy,
+	actions []ActionRecommendation,
+) []ActionRecommendation {
+	if !policy.Spec.WALCleanup.Enabled || !policy.Spec.WALCleanup.IncludeReplicas {
+		return nil
+	}
+	for _, action := range actions {
+		if action.Action == ActionTypeWALCleanup {
+			return nil
+		}
+	}
+
+	replica := e.EvaluateThresholds(ctx.ReplicaUsagePercent, policy.Spec.Thresholds)
+	if replica.Level != ThresholdLevelEmergency {
+		return nil
+	}
+	return []ActionRecommendation{{
+		Action:   ActionTypeWALCleanup,
+		Reason:   fmt.Sprintf("Replica volume at %.1f%% breached emergency threshold", ctx.ReplicaUsagePercent),
+		Priority: 1,
+		Parameters: map[string]interface{}{
+			"replica": true,
+		},
+	}}
+}
+
 // EvaluationResult contains the complete result of an evaluation
 type EvaluationResult struct {
 	ClusterName     string
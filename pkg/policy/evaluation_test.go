@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
 )
@@ -152,6 +153,99 @@ func TestEvaluateThresholds(t *testing.T) {
 			if result.CurrentUsagePercent != tt.usagePercent {
 				t.Errorf("expected usagePercent %f, got %f", tt.usagePercent, result.CurrentUsagePercent)
 			}
+			if expected := EffectiveThresholds(tt.thresholds); result.Thresholds != expected {
+				t.Errorf("expected thresholds %+v, got %+v", expected, result.Thresholds)
+			}
+		})
+	}
+}
+
+func TestEvaluateUsage(t *testing.T) {
+	evaluator := NewEvaluator()
+	const gi = int64(1) << 30
+	floor := resource.MustParse("500Mi")
+	thresholds := cnpgv1alpha1.ThresholdsConfig{
+		HeadroomGi:             &cnpgv1alpha1.HeadroomThresholds{Warning: 100, Critical: 50, Expansion: 20, Emergency: 5},
+		AbsoluteFreeBytesFloor: &floor,
+	}
+
+	tests := []struct {
+		name          string
+		usagePercent  float64
+		freeBytes     int64
+		thresholds    cnpgv1alpha1.ThresholdsConfig
+		expectedLevel ThresholdLevel
+		shouldExpand  bool
+		shouldCleanup bool
+	}{
+		{
+			name:          "plenty of headroom",
+			usagePercent:  50,
+			freeBytes:     2000 * gi,
+			thresholds:    thresholds,
+			expectedLevel: ThresholdLevelNormal,
+		},
+		{
+			name:          "large volume below expansion headroom",
+			usagePercent:  60,
+			freeBytes:     15 * gi,
+			thresholds:    thresholds,
+			expectedLevel: ThresholdLevelExpansion,
+			shouldExpand:  true,
+		},
+		{
+			name:          "percentage fires first",
+			usagePercent:  92,
+			freeBytes:     60 * gi,
+			thresholds:    thresholds,
+			expectedLevel: ThresholdLevelEmergency,
+			shouldExpand:  true,
+			shouldCleanup: true,
+		},
+		{
+			name:          "below the absolute floor",
+			usagePercent:  40,
+			freeBytes:     100 << 20,
+			thresholds:    cnpgv1alpha1.ThresholdsConfig{AbsoluteFreeBytesFloor: &floor},
+			expectedLevel: ThresholdLevelEmergency,
+			shouldExpand:  true,
+			shouldCleanup: true,
+		},
+		{
+			name:          "unknown free space",
+			usagePercent:  40,
+			freeBytes:     0,
+			thresholds:    thresholds,
+			expectedLevel: ThresholdLevelNormal,
+		},
+		{
+			name:          "no headroom thresholds",
+			usagePercent:  75,
+			freeBytes:     1 * gi,
+			thresholds:    cnpgv1alpha1.ThresholdsConfig{},
+			expectedLevel: ThresholdLevelWarning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evaluator.EvaluateUsage(tt.usagePercent, tt.freeBytes, tt.thresholds)
+
+			if result.Level != tt.expectedLevel {
+				t.Errorf("expected level %s, got %s (%s)", tt.expectedLevel, result.Level, result.Message)
+			}
+			if result.ShouldAlert != (tt.expectedLevel != ThresholdLevelNormal) {
+				t.Errorf("expected shouldAlert at level %s, got %v", tt.expectedLevel, result.ShouldAlert)
+			}
+			if result.ShouldExpand != tt.shouldExpand {
+				t.Errorf("expected shouldExpand %v, got %v", tt.shouldExpand, result.ShouldExpand)
+			}
+			if result.ShouldCleanupWAL != tt.shouldCleanup {
+				t.Errorf("expected shouldCleanupWAL %v, got %v", tt.shouldCleanup, result.ShouldCleanupWAL)
+			}
+			if result.FreeBytes != tt.freeBytes {
+				t.Errorf("expected freeBytes %d, got %d", tt.freeBytes, result.FreeBytes)
+			}
 		})
 	}
 }
@@ -255,7 +349,9 @@ func TestCalculateExpansionSize(t *testing.T) {
 }
 
 func TestCheckCooldown(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
 	evaluator := NewEvaluator()
+	evaluator.Clock = clocktesting.NewFakePassiveClock(now)
 
 	tests := []struct {
 		name            string
@@ -271,19 +367,25 @@ func TestCheckCooldown(t *testing.T) {
 		},
 		{
 			name:            "cooldown expired",
-			lastAction:      timePtr(time.Now().Add(-60 * time.Minute)),
+			lastAction:      timePtr(now.Add(-60 * time.Minute)),
 			cooldownMinutes: 30,
 			expectAllowed:   true,
 		},
 		{
 			name:            "cooldown active",
-			lastAction:      timePtr(time.Now().Add(-10 * time.Minute)),
+			lastAction:      timePtr(now.Add(-10 * time.Minute)),
+			cooldownMinutes: 30,
+			expectAllowed:   false,
+		},
+		{
+			name:            "one second before cooldown boundary",
+			lastAction:      timePtr(now.Add(-30*time.Minute + time.Second)),
 			cooldownMinutes: 30,
 			expectAllowed:   false,
 		},
 		{
 			name:            "exactly at cooldown boundary",
-			lastAction:      timePtr(time.Now().Add(-30 * time.Minute)),
+			lastAction:      timePtr(now.Add(-30 * time.Minute)),
 			cooldownMinutes: 30,
 			expectAllowed:   true,
 		},
@@ -300,6 +402,190 @@ func TestCheckCooldown(t *testing.T) {
 	}
 }
 
+func TestCheckSustained(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	evaluator := NewEvaluator()
+	evaluator.Clock = clocktesting.NewFakePassiveClock(now)
+
+	tests := []struct {
+		name             string
+		since            *time.Time
+		sustainedMinutes int32
+		expectAllowed    bool
+		expectRemaining  time.Duration
+	}{
+		{
+			name:             "not required",
+			since:            nil,
+			sustainedMinutes: 0,
+			expectAllowed:    true,
+		},
+		{
+			name:             "breach just started",
+			since:            nil,
+			sustainedMinutes: 15,
+			expectAllowed:    false,
+			expectRemaining:  15 * time.Minute,
+		},
+		{
+			name:             "breach not sustained yet",
+			since:            timePtr(now.Add(-10 * time.Minute)),
+			sustainedMinutes: 15,
+			expectAllowed:    false,
+			expectRemaining:  5 * time.Minute,
+		},
+		{
+			name:             "breach sustained",
+			since:            timePtr(now.Add(-15 * time.Minute)),
+			sustainedMinutes: 15,
+			expectAllowed:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, remaining := evaluator.CheckSustained(tt.since, tt.sustainedMinutes)
+
+			if allowed != tt.expectAllowed {
+				t.Errorf("expected allowed %v, got %v", tt.expectAllowed, allowed)
+			}
+			if remaining != tt.expectRemaining {
+				t.Errorf("expected %v remaining, got %v", tt.expectRemaining, remaining)
+			}
+		})
+	}
+}
+
+func TestShouldAlertPartialSuccess(t *testing.T) {
+	evaluator := NewEvaluator()
+	since := time.Now().Add(-90 * time.Minute)
+
+	tests := []struct {
+		name         string
+		since        *time.Time
+		lastAlert    *time.Time
+		alertMinutes int32
+		expectAlert  bool
+	}{
+		{"not in partial success", nil, nil, 60, false},
+		{"alert disabled", &since, nil, 0, false},
+		{"below threshold", timePtr(time.Now().Add(-30 * time.Minute)), nil, 60, false},
+		{"threshold exceeded", &since, nil, 60, true},
+		{"alerted recently", &since, timePtr(time.Now().Add(-5 * time.Minute)), 60, false},
+		{"escalation elapsed", &since, timePtr(time.Now().Add(-20 * time.Minute)), 60, true},
+		{"alert from previous episode", &since, timePtr(since.Add(-time.Minute)), 60, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluator.ShouldAlertPartialSuccess(tt.since, tt.lastAlert, tt.alertMinutes, 15)
+			if got != tt.expectAlert {
+				t.Errorf("expected alert %v, got %v", tt.expectAlert, got)
+			}
+		})
+	}
+}
+
+func TestIsPolicyDryRun(t *testing.T) {
+	now := time.Now()
+	future := metav1.NewTime(now.Add(time.Hour))
+	past := metav1.NewTime(now.Add(-time.Hour))
+
+	tests := []struct {
+		name          string
+		dryRun        bool
+		dryRunUntil   *metav1.Time
+		expectDryRun  bool
+		expectExpired bool
+	}{
+		{"enforcing", false, nil, false, false},
+		{"dry-run", true, nil, true, false},
+		{"trial active", false, &future, true, false},
+		{"trial ended", false, &past, false, true},
+		{"trial ended overrides dryRun", true, &past, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &cnpgv1alpha1.StoragePolicy{
+				Spec: cnpgv1alpha1.StoragePolicySpec{DryRun: tt.dryRun, DryRunUntil: tt.dryRunUntil},
+			}
+			if got := IsPolicyDryRun(p, now); got != tt.expectDryRun {
+				t.Errorf("expected dry-run %v, got %v", tt.expectDryRun, got)
+			}
+			if got := DryRunExpired(p, now); got != tt.expectExpired {
+				t.Errorf("expected expired %v, got %v", tt.expectExpired, got)
+			}
+		})
+	}
+}
+
+func TestIsActionDryRun(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		spec          cnpgv1alpha1.StoragePolicySpec
+		expectExpand  bool
+		expectCleanup bool
+	}{
+		{"enforcing", cnpgv1alpha1.StoragePolicySpec{}, false, false},
+		{"policy dry-run", cnpgv1alpha1.StoragePolicySpec{DryRun: true}, true, true},
+		{
+			"wal cleanup observe-only",
+			cnpgv1alpha1.StoragePolicySpec{WALCleanup: cnpgv1alpha1.WALCleanupConfig{DryRun: true}},
+			false, true,
+		},
+		{
+			"expansion observe-only",
+			cnpgv1alpha1.StoragePolicySpec{Expansion: cnpgv1alpha1.ExpansionConfig{DryRun: true}},
+			true, false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &cnpgv1alpha1.StoragePolicy{Spec: tt.spec}
+			if got := IsActionDryRun(p, cnpgv1alpha1.EventTypeExpansion, now); got != tt.expectExpand {
+				t.Errorf("expected expansion dry-run %v, got %v", tt.expectExpand, got)
+			}
+			if got := IsActionDryRun(p, cnpgv1alpha1.EventTypeWALCleanup, now); got != tt.expectCleanup {
+				t.Errorf("expected WAL cleanup dry-run %v, got %v", tt.expectCleanup, got)
+			}
+		})
+	}
+}
+
+func TestIsPolicyPaused(t *testing.T) {
+	now := time.Now()
+	future := metav1.NewTime(now.Add(time.Hour))
+	past := metav1.NewTime(now.Add(-time.Hour))
+
+	tests := []struct {
+		name       string
+		paused     bool
+		pauseUntil *metav1.Time
+		expected   bool
+	}{
+		{"active", false, nil, false},
+		{"paused", true, nil, true},
+		{"maintenance window", false, &future, true},
+		{"maintenance window ended", false, &past, false},
+		{"window ended overrides paused", true, &past, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &cnpgv1alpha1.StoragePolicy{
+				Spec: cnpgv1alpha1.StoragePolicySpec{Paused: tt.paused, PauseUntil: tt.pauseUntil},
+			}
+			if got := IsPolicyPaused(p, now); got != tt.expected {
+				t.Errorf("expected paused %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestShouldSuppressAlert(t *testing.T) {
 	evaluator := NewEvaluator()
 
@@ -548,6 +834,60 @@ func TestFullEvaluation(t *testing.T) {
 	}
 }
 
+func TestFullEvaluation_ReplicaWALCleanup(t *testing.T) {
+	evaluator := NewEvaluator()
+
+	newPolicy := func(includeReplicas bool) *cnpgv1alpha1.StoragePolicy {
+		return &cnpgv1alpha1.StoragePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+			Spec: cnpgv1alpha1.StoragePolicySpec{
+				Thresholds: cnpgv1alpha1.ThresholdsConfig{Warning: 70, Critical: 80, Emergency: 90},
+				WALCleanup: cnpgv1alpha1.WALCleanupConfig{Enabled: true, IncludeReplicas: includeReplicas},
+			},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		replicaUsage    float64
+		includeReplicas bool
+		expectCleanup   bool
+	}{
+		{name: "replica at emergency", replicaUsage: 95, includeReplicas: true, expectCleanup: true},
+		{name: "replica below emergency", replicaUsage: 85, includeReplicas: true, expectCleanup: false},
+		{name: "replicas not included", replicaUsage: 95, includeReplicas: false, expectCleanup: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := EvaluationContext{
+				ClusterName:         "test-cluster",
+				Namespace:           "default",
+				CurrentUsageBytes:   50,
+				CapacityBytes:       100,
+				ReplicaUsagePercent: tt.replicaUsage,
+			}
+			result, err := evaluator.FullEvaluation(ctx, newPolicy(tt.includeReplicas))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var cleanup *ActionRecommendation
+			for i := range result.Actions {
+				if result.Actions[i].Action == ActionTypeWALCleanup {
+					cleanup = &result.Actions[i]
+				}
+			}
+			if (cleanup != nil) != tt.expectCleanup {
+				t.Fatalf("expected WAL cleanup %v, got actions %+v", tt.expectCleanup, result.Actions)
+			}
+			if cleanup != nil && cleanup.Parameters["replica"] != true {
+				t.Errorf("expected replica parameter, got %+v", cleanup.Parameters)
+			}
+		})
+	}
+}
+
 func TestEvaluationResultHasPendingActions(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,85 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// Explain returns the decision tree of an evaluation: the usage, the thresholds it was
+// compared against, the circuit breaker state and the recommended actions with what held
+// them back. The caller completes it with the action it took and why others were not
+func Explain(
+	ctx EvaluationContext,
+	result *EvaluationResult,
+	p *cnpgv1alpha1.StoragePolicy,
+) *cnpgv1alpha1.EvaluationDecision {
+	decision := &cnpgv1alpha1.EvaluationDecision{
+		UsagePercent:       strconv.FormatFloat(result.UsagePercent, 'f', 1, 64),
+		Level:              string(result.ThresholdResult.Level),
+		Thresholds:         EffectiveThresholds(p.Spec.Thresholds),
+		CircuitBreakerOpen: ctx.CircuitBreakerOpen,
+		ActiveRemediation:  ctx.ActiveRemediation,
+		Action:             string(ActionTypeNone),
+	}
+	if result.Blocked {
+		decision.BlockReasons = append(decision.BlockReasons, result.BlockedReason)
+	}
+	for _, action := range result.Actions {
+		decision.Actions = append(decision.Actions, cnpgv1alpha1.DecisionAction{
+			Action:  string(action.Action),
+			Reason:  action.Reason,
+			Blocked: actionBlockReason(action),
+		})
+	}
+	return decision
+}
+
+// EffectiveThresholds returns the thresholds with the defaults of unset ones applied
+func EffectiveThresholds(thresholds cnpgv1alpha1.ThresholdsConfig) cnpgv1alpha1.ThresholdsConfig {
+	return cnpgv1alpha1.ThresholdsConfig{
+		Warning:   getThresholdOrDefault(thresholds.Warning, 70),
+		Critical:  getThresholdOrDefault(thresholds.Critical, 80),
+		Expansion: getThresholdOrDefault(thresholds.Expansion, 85),
+		Emergency: getThresholdOrDefault(thresholds.Emergency, 90),
+
+		HeadroomGi:             thresholds.HeadroomGi,
+		AbsoluteFreeBytesFloor: thresholds.AbsoluteFreeBytesFloor,
+	}
+}
+
+// actionBlockReason returns why the evaluation held an action back, empty when it did not
+func actionBlockReason(action ActionRecommendation) string {
+	if blocked, _ := action.Parameters["blocked"].(bool); blocked {
+		if remaining, ok := action.Parameters["sustained_remaining"].(float64); ok {
+			wait := time.Duration(remaining * float64(time.Second)).Round(time.Second)
+			return fmt.Sprintf("breach not sustained, %v remaining", wait)
+		}
+		remaining, _ := action.Parameters["cooldown_remaining"].(float64)
+		cooldown := time.Duration(remaining * float64(time.Second)).Round(time.Second)
+		return fmt.Sprintf("cooldown, %v remaining", cooldown)
+	}
+	if suppressed, _ := action.Parameters["suppressed"].(bool); suppressed {
+		reason, _ := action.Parameters["suppress_reason"].(string)
+		return "suppressed: " + reason
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestExplain(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	evaluator := NewEvaluator()
+	evaluator.Clock = clocktesting.NewFakePassiveClock(now)
+	recent := now.Add(-10 * time.Minute)
+	expandPolicy := &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{
+		Thresholds: cnpgv1alpha1.ThresholdsConfig{Expansion: 80},
+		Expansion:  cnpgv1alpha1.ExpansionConfig{Enabled: true, CooldownMinutes: 60},
+		Alerting:   cnpgv1alpha1.AlertingConfig{SuppressDuringRemediation: true},
+	}}
+
+	tests := []struct {
+		name          string
+		ctx           EvaluationContext
+		expectLevel   string
+		expectBlocked map[string]string
+		expectReasons []string
+	}{
+		{
+			name:          "expansion allowed",
+			ctx:           EvaluationContext{CurrentUsageBytes: 81, CapacityBytes: 100},
+			expectLevel:   "expansion",
+			expectBlocked: map[string]string{"expand": "", "alert": ""},
+		},
+		{
+			name: "expansion in cooldown and alert suppressed",
+			ctx: EvaluationContext{
+				CurrentUsageBytes: 81, CapacityBytes: 100, LastExpansion: &recent, ActiveRemediation: true,
+			},
+			expectLevel: "expansion",
+			expectBlocked: map[string]string{
+				"expand": "cooldown, 50m0s remaining",
+				"alert":  "suppressed: remediation in progress",
+			},
+		},
+		{
+			name:          "circuit breaker open",
+			ctx:           EvaluationContext{CurrentUsageBytes: 95, CapacityBytes: 100, CircuitBreakerOpen: true},
+			expectBlocked: map[string]string{},
+			expectReasons: []string{"circuit breaker is open"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := evaluator.FullEvaluation(tt.ctx, expandPolicy)
+			if err != nil {
+				t.Fatalf("FullEvaluation() error = %v", err)
+			}
+			decision := Explain(tt.ctx, result, expandPolicy)
+
+			if decision.Level != tt.expectLevel {
+				t.Errorf("Level = %q, want %q", decision.Level, tt.expectLevel)
+			}
+			if decision.Action != string(ActionTypeNone) {
+				t.Errorf("Action = %q, want %q", decision.Action, ActionTypeNone)
+			}
+			if decision.CircuitBreakerOpen != tt.ctx.CircuitBreakerOpen {
+				t.Errorf("CircuitBreakerOpen = %v, want %v", decision.CircuitBreakerOpen, tt.ctx.CircuitBreakerOpen)
+			}
+			if len(decision.Actions) != len(tt.expectBlocked) {
+				t.Fatalf("got %d actions, want %d", len(decision.Actions), len(tt.expectBlocked))
+			}
+			for _, action := range decision.Actions {
+				if action.Blocked != tt.expectBlocked[action.Action] {
+					t.Errorf("%s blocked = %q, want %q", action.Action, action.Blocked, tt.expectBlocked[action.Action])
+				}
+			}
+			if strings.Join(decision.BlockReasons, ",") != strings.Join(tt.expectReasons, ",") {
+				t.Errorf("BlockReasons = %v, want %v", decision.BlockReasons, tt.expectReasons)
+			}
+		})
+	}
+}
+
+func TestExplain_SustainedBreach(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	evaluator := NewEvaluator()
+	evaluator.Clock = clocktesting.NewFakePassiveClock(now)
+	sustainedPolicy := &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{
+		Expansion: cnpgv1alpha1.ExpansionConfig{Enabled: true, SustainedMinutes: 20},
+	}}
+
+	since := now.Add(-5 * time.Minute)
+	ctx := EvaluationContext{CurrentUsageBytes: 87, CapacityBytes: 100, ExpansionBreachedSince: &since}
+	result, err := evaluator.FullEvaluation(ctx, sustainedPolicy)
+	if err != nil {
+		t.Fatalf("FullEvaluation() error = %v", err)
+	}
+	decision := Explain(ctx, result, sustainedPolicy)
+	for _, action := range decision.Actions {
+		if action.Action == string(ActionTypeExpand) && action.Blocked != "breach not sustained, 15m0s remaining" {
+			t.Errorf("expand blocked = %q, want the remaining sustain time", action.Blocked)
+		}
+	}
+
+	since = now.Add(-25 * time.Minute)
+	result, err = evaluator.FullEvaluation(ctx, sustainedPolicy)
+	if err != nil {
+		t.Fatalf("FullEvaluation() error = %v", err)
+	}
+	for _, action := range result.Actions {
+		if blocked, _ := action.Parameters["blocked"].(bool); action.Action == ActionTypeExpand && blocked {
+			t.Errorf("expected the sustained breach to expand, got %+v", action)
+		}
+	}
+}
+
+func TestEffectiveThresholds(t *testing.T) {
+	got := EffectiveThresholds(cnpgv1alpha1.ThresholdsConfig{Warning: 60, Emergency: 95})
+	want := cnpgv1alpha1.ThresholdsConfig{Warning: 60, Critical: 80, Expansion: 85, Emergency: 95}
+	if got != want {
+		t.Errorf("EffectiveThresholds() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"slices"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+const (
+	// DefaultFenceAtPercent is the default usage at which an instance is fenced
+	DefaultFenceAtPercent = 99
+	// DefaultUnfenceBelowPercent is the default usage below which a fenced instance is unfenced
+	DefaultUnfenceBelowPercent = 90
+)
+
+// FencingPlan lists the instances to fence and to unfence
+type FencingPlan struct {
+	// Fence are the full instances that are not fenced yet
+	Fence []string
+	// Unfence are the instances fenced by the manager that have space again
+	Unfence []string
+}
+
+// PlanFencing decides which instances to fence and unfence from the usage of each
+// instance's fullest volume. managerFenced are the instances the manager fenced
+// itself; instances fenced by anyone else are never unfenced. Instances without
+// usage keep their fencing. Disabling fencing stops new fencing, but the instances
+// the manager fenced are still unfenced once they have space
+func PlanFencing(
+	config cnpgv1alpha1.FencingConfig,
+	instanceUsage map[string]float64,
+	fenced, managerFenced []string,
+) FencingPlan {
+	var plan FencingPlan
+	fenceAt := float64(getThresholdOrDefault(config.FenceAtPercent, DefaultFenceAtPercent))
+	unfenceBelow := float64(getThresholdOrDefault(config.UnfenceBelowPercent, DefaultUnfenceBelowPercent))
+	for instance, usage := range instanceUsage {
+		isFenced := slices.Contains(fenced, instance) || slices.Contains(fenced, cnpg.FenceAllInstances)
+		switch {
+		case config.Enabled && !isFenced && usage >= fenceAt:
+			plan.Fence = append(plan.Fence, instance)
+		case slices.Contains(managerFenced, instance) && usage < unfenceBelow:
+			plan.Unfence = append(plan.Unfence, instance)
+		}
+	}
+	slices.Sort(plan.Fence)
+	slices.Sort(plan.Unfence)
+	return plan
+}
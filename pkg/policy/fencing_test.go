@@ -0,0 +1,101 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"reflect"
+	"testing"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestPlanFencing(t *testing.T) {
+	enabled := cnpgv1alpha1.FencingConfig{Enabled: true}
+
+	tests := []struct {
+		name          string
+		config        cnpgv1alpha1.FencingConfig
+		usage         map[string]float64
+		fenced        []string
+		managerFenced []string
+		expected      FencingPlan
+	}{
+		{
+			name:     "disabled",
+			config:   cnpgv1alpha1.FencingConfig{},
+			usage:    map[string]float64{"pg-1": 100},
+			expected: FencingPlan{},
+		},
+		{
+			name:          "disabled still unfences manager fenced instances",
+			config:        cnpgv1alpha1.FencingConfig{},
+			usage:         map[string]float64{"pg-1": 50},
+			fenced:        []string{"pg-1"},
+			managerFenced: []string{"pg-1"},
+			expected:      FencingPlan{Unfence: []string{"pg-1"}},
+		},
+		{
+			name:     "fences full instances at the default",
+			config:   enabled,
+			usage:    map[string]float64{"pg-1": 99.5, "pg-2": 98, "pg-3": 100},
+			expected: FencingPlan{Fence: []string{"pg-1", "pg-3"}},
+		},
+		{
+			name:     "custom fence threshold",
+			config:   cnpgv1alpha1.FencingConfig{Enabled: true, FenceAtPercent: 95},
+			usage:    map[string]float64{"pg-1": 96},
+			expected: FencingPlan{Fence: []string{"pg-1"}},
+		},
+		{
+			name:     "already fenced instances are left alone",
+			config:   enabled,
+			usage:    map[string]float64{"pg-1": 100, "pg-2": 100},
+			fenced:   []string{"pg-1"},
+			expected: FencingPlan{Fence: []string{"pg-2"}},
+		},
+		{
+			name:     "all instances fenced",
+			config:   enabled,
+			usage:    map[string]float64{"pg-1": 100},
+			fenced:   []string{"*"},
+			expected: FencingPlan{},
+		},
+		{
+			name:          "unfences manager fenced instances with space",
+			config:        enabled,
+			usage:         map[string]float64{"pg-1": 60, "pg-2": 92},
+			fenced:        []string{"pg-1", "pg-2"},
+			managerFenced: []string{"pg-1", "pg-2"},
+			expected:      FencingPlan{Unfence: []string{"pg-1"}},
+		},
+		{
+			name:     "instances fenced by others are never unfenced",
+			config:   enabled,
+			usage:    map[string]float64{"pg-1": 10},
+			fenced:   []string{"pg-1"},
+			expected: FencingPlan{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanFencing(tt.config, tt.usage, tt.fenced, tt.managerFenced)
+			if !reflect.DeepEqual(plan, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, plan)
+			}
+		})
+	}
+}
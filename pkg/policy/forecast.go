@@ -0,0 +1,174 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// DefaultForecastSchedule is the default cron expression of the forecast refresh
+	DefaultForecastSchedule = "0 2 * * *"
+
+	// forecastHistory is how long the daily usage samples of a forecast are kept
+	forecastHistory = 90 * 24 * time.Hour
+	// minForecastHistory is the span the samples need before their growth rate is used
+	minForecastHistory = 24 * time.Hour
+)
+
+// ForecastHorizonDays are the days ahead a forecast projects the storage of a cluster
+var ForecastHorizonDays = []int{30, 60, 90}
+
+// ForecastInput is the current storage of a cluster's fullest data volume and the
+// expansion settings applied to it
+type ForecastInput struct {
+	// UsedBytes and CapacityBytes are the usage and size of the fullest data volume
+	UsedBytes     int64
+	CapacityBytes int64
+	// Instances is the number of data volumes, each expanded like the fullest one
+	Instances int32
+	// GrowthBytesPerDay is the projected growth of the data volume
+	GrowthBytesPerDay int64
+	// ExpansionThreshold is the usage percentage that triggers an expansion
+	ExpansionThreshold int32
+	// Percentage, MinIncrementBytes and MaxSizeBytes size each expansion. A zero
+	// MaxSizeBytes is unlimited
+	Percentage        int32
+	MinIncrementBytes int64
+	MaxSizeBytes      int64
+}
+
+// ForecastPoint is the projected storage of a cluster a number of days ahead
+type ForecastPoint struct {
+	Days          int
+	UsedBytes     int64
+	CapacityBytes int64
+	// Expansions counts the expansions projected until the horizon
+	Expansions int
+	// AdditionalBytes is the storage the expansions add across all instances
+	AdditionalBytes int64
+	// ExceedsMaxSize is set when an expansion was needed beyond the maximum size
+	ExceedsMaxSize bool
+}
+
+// UsagePercent returns the projected usage percentage of the data volume
+func (p ForecastPoint) UsagePercent() float64 {
+	if p.CapacityBytes == 0 {
+		return 0
+	}
+	return float64(p.UsedBytes) / float64(p.CapacityBytes) * 100
+}
+
+// ForecastExpansion is an expansion of the data volumes projected by a forecast
+type ForecastExpansion struct {
+	Time      time.Time
+	FromBytes int64
+	ToBytes   int64
+}
+
+// StorageForecast is the projection of a cluster's storage at each of ForecastHorizonDays
+type StorageForecast struct {
+	Points     []ForecastPoint
+	Expansions []ForecastExpansion
+}
+
+// ProjectStorage grows the data volume by the daily growth rate and expands it the way
+// the expansion engine would whenever the expansion threshold is reached, recording
+// the storage at each of ForecastHorizonDays
+func ProjectStorage(input ForecastInput, now time.Time) StorageForecast {
+	var forecast StorageForecast
+	used, capacity := input.UsedBytes, input.CapacityBytes
+	var exceedsMaxSize bool
+
+	horizon := 0
+	for day := 1; horizon < len(ForecastHorizonDays); day++ {
+		used += max(input.GrowthBytesPerDay, 0)
+		for capacity > 0 && used*100 >= int64(input.ExpansionThreshold)*capacity {
+			next := projectedExpansionSize(capacity, input)
+			if next <= capacity {
+				exceedsMaxSize = true
+				break
+			}
+			forecast.Expansions = append(forecast.Expansions, ForecastExpansion{
+				Time:      now.AddDate(0, 0, day),
+				FromBytes: capacity,
+				ToBytes:   next,
+			})
+			capacity = next
+		}
+
+		if day == ForecastHorizonDays[horizon] {
+			forecast.Points = append(forecast.Points, ForecastPoint{
+				Days:            day,
+				UsedBytes:       used,
+				CapacityBytes:   capacity,
+				Expansions:      len(forecast.Expansions),
+				AdditionalBytes: (capacity - input.CapacityBytes) * int64(input.Instances),
+				ExceedsMaxSize:  exceedsMaxSize,
+			})
+			horizon++
+		}
+	}
+	return forecast
+}
+
+// projectedExpansionSize returns the size of a volume after one expansion, capped at
+// the maximum size
+func projectedExpansionSize(capacity int64, input ForecastInput) int64 {
+	next := capacity + max(capacity*int64(input.Percentage)/100, input.MinIncrementBytes)
+	if input.MaxSizeBytes > 0 && next > input.MaxSizeBytes {
+		next = max(input.MaxSizeBytes, capacity)
+	}
+	return next
+}
+
+// RecordForecastSample adds the current used bytes to the daily samples of a forecast,
+// dropping the samples older than 90 days. The previous samples are not modified
+func RecordForecastSample(
+	previous []cnpgv1alpha1.UsageSample,
+	usedBytes int64,
+	now time.Time,
+) []cnpgv1alpha1.UsageSample {
+	oldest := now.Add(-forecastHistory)
+	samples := make([]cnpgv1alpha1.UsageSample, 0, len(previous)+1)
+	for _, sample := range previous {
+		if sample.Time.Time.Before(oldest) || !sample.Time.Time.Before(now) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return append(samples, cnpgv1alpha1.UsageSample{Time: metav1.NewTime(now), UsedBytes: usedBytes})
+}
+
+// ForecastGrowthPerDay returns the daily growth from the oldest to the newest sample.
+// It returns false until the samples span a day. Shrinking usage grows by zero
+func ForecastGrowthPerDay(samples []cnpgv1alpha1.UsageSample) (int64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	span := last.Time.Sub(first.Time.Time)
+	if span < minForecastHistory {
+		return 0, false
+	}
+	growth := max(last.UsedBytes-first.UsedBytes, 0)
+	return int64(float64(growth) / span.Hours() * 24), true
+}
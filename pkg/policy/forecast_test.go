@@ -0,0 +1,151 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestProjectStorage(t *testing.T) {
+	const gi = int64(1) << 30
+	now := time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)
+	base := ForecastInput{
+		UsedBytes:          50 * gi,
+		CapacityBytes:      100 * gi,
+		Instances:          3,
+		ExpansionThreshold: 80,
+		Percentage:         20,
+		MinIncrementBytes:  5 * gi,
+	}
+
+	t.Run("flat usage", func(t *testing.T) {
+		forecast := ProjectStorage(base, now)
+		if len(forecast.Points) != len(ForecastHorizonDays) {
+			t.Fatalf("expected %d points, got %d", len(ForecastHorizonDays), len(forecast.Points))
+		}
+		for i, point := range forecast.Points {
+			if point.Days != ForecastHorizonDays[i] {
+				t.Errorf("expected point %d at %d days, got %d", i, ForecastHorizonDays[i], point.Days)
+			}
+			if point.UsedBytes != 50*gi || point.CapacityBytes != 100*gi || point.Expansions != 0 ||
+				point.AdditionalBytes != 0 {
+				t.Errorf("expected an unchanged projection at %d days, got %+v", point.Days, point)
+			}
+		}
+		if len(forecast.Expansions) != 0 {
+			t.Errorf("expected no expansions, got %v", forecast.Expansions)
+		}
+	})
+
+	t.Run("growth triggers expansions", func(t *testing.T) {
+		input := base
+		input.GrowthBytesPerDay = gi
+		forecast := ProjectStorage(input, now)
+
+		in30 := forecast.Points[0]
+		if in30.UsedBytes != 80*gi || in30.CapacityBytes != 120*gi || in30.Expansions != 1 {
+			t.Errorf("expected 80Gi used of 120Gi after one expansion in 30 days, got %+v", in30)
+		}
+		if in30.AdditionalBytes != 60*gi {
+			t.Errorf("expected 60Gi more across three instances in 30 days, got %d", in30.AdditionalBytes/gi)
+		}
+		if in30.UsagePercent() < 66 || in30.UsagePercent() > 67 {
+			t.Errorf("expected a usage of about 66.7%%, got %.1f", in30.UsagePercent())
+		}
+
+		in60 := forecast.Points[1]
+		if in60.CapacityBytes != 144*gi || in60.Expansions != 2 || in60.AdditionalBytes != 132*gi {
+			t.Errorf("expected two expansions to 144Gi in 60 days, got %+v", in60)
+		}
+
+		first := forecast.Expansions[0]
+		if !first.Time.Equal(now.AddDate(0, 0, 30)) || first.FromBytes != 100*gi || first.ToBytes != 120*gi {
+			t.Errorf("expected the first expansion from 100Gi to 120Gi in 30 days, got %+v", first)
+		}
+		if in90 := forecast.Points[2]; in90.Expansions != len(forecast.Expansions) {
+			t.Errorf("expected every expansion within 90 days, got %d of %d", in90.Expansions, len(forecast.Expansions))
+		}
+	})
+
+	t.Run("minimum increment", func(t *testing.T) {
+		input := base
+		input.CapacityBytes = 10 * gi
+		input.UsedBytes = 7 * gi
+		input.Instances = 1
+		input.GrowthBytesPerDay = gi
+		forecast := ProjectStorage(input, now)
+		if first := forecast.Expansions[0]; first.ToBytes != 15*gi {
+			t.Errorf("expected the minimum increment to expand 10Gi to 15Gi, got %d", first.ToBytes/gi)
+		}
+	})
+
+	t.Run("maximum size", func(t *testing.T) {
+		input := base
+		input.GrowthBytesPerDay = 2 * gi
+		input.MaxSizeBytes = 110 * gi
+		forecast := ProjectStorage(input, now)
+
+		in30 := forecast.Points[0]
+		if in30.CapacityBytes != 110*gi || in30.Expansions != 1 || !in30.ExceedsMaxSize {
+			t.Errorf("expected a single expansion capped at 110Gi that is exceeded, got %+v", in30)
+		}
+		if in30.UsedBytes != 110*gi {
+			t.Errorf("expected usage to keep growing past the maximum size, got %d", in30.UsedBytes/gi)
+		}
+	})
+}
+
+func TestForecastSamples(t *testing.T) {
+	const gi = int64(1) << 30
+	now := time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)
+	sample := func(daysAgo int, used int64) cnpgv1alpha1.UsageSample {
+		return cnpgv1alpha1.UsageSample{Time: metav1.NewTime(now.AddDate(0, 0, -daysAgo)), UsedBytes: used}
+	}
+
+	samples := RecordForecastSample([]cnpgv1alpha1.UsageSample{
+		sample(120, 1*gi), sample(10, 10*gi), sample(5, 15*gi),
+	}, 20*gi, now)
+	if len(samples) != 3 || samples[0].UsedBytes != 10*gi || samples[2].UsedBytes != 20*gi {
+		t.Fatalf("expected the sample older than 90 days to be dropped, got %v", samples)
+	}
+
+	perDay, ok := ForecastGrowthPerDay(samples)
+	if !ok || perDay != gi {
+		t.Errorf("expected 1Gi per day, got %d (known %v)", perDay, ok)
+	}
+
+	if _, ok := ForecastGrowthPerDay(RecordForecastSample(nil, gi, now)); ok {
+		t.Error("expected a single sample not to yield a growth rate")
+	}
+	recent := []cnpgv1alpha1.UsageSample{
+		{Time: metav1.NewTime(now.Add(-12 * time.Hour)), UsedBytes: gi},
+		{Time: metav1.NewTime(now), UsedBytes: 2 * gi},
+	}
+	if _, ok := ForecastGrowthPerDay(recent); ok {
+		t.Error("expected samples spanning less than a day not to yield a growth rate")
+	}
+
+	if perDay, ok := ForecastGrowthPerDay([]cnpgv1alpha1.UsageSample{sample(2, 5*gi), sample(0, 3*gi)}); !ok ||
+		perDay != 0 {
+		t.Errorf("expected shrinking usage to grow by zero, got %d", perDay)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// DefaultAnomalyRecentWindowMinutes is the default window of the current growth rate
+	DefaultAnomalyRecentWindowMinutes = 60
+	// DefaultAnomalyBaselineWindowHours is the default window of the baseline growth rate
+	DefaultAnomalyBaselineWindowHours = 24
+	// DefaultAnomalyGrowthFactor is the default ratio of current to baseline growth that
+	// is anomalous
+	DefaultAnomalyGrowthFactor = 3
+	// DefaultAnomalyMinGrowthMiPerHour is the default growth rate below which growth is
+	// never anomalous
+	DefaultAnomalyMinGrowthMiPerHour = 512
+
+	// minGrowthSampleInterval is the shortest time between two stored usage samples
+	minGrowthSampleInterval = 15 * time.Minute
+	// maxGrowthSamples bounds the samples kept per cluster; long windows are sampled
+	// less often instead
+	maxGrowthSamples = 96
+)
+
+// GrowthAnalysis is the result of comparing a cluster's current growth rate to its baseline
+type GrowthAnalysis struct {
+	// Known is false until enough samples cover the baseline and recent windows
+	Known bool
+	// RecentBytesPerHour is the growth rate over the recent window
+	RecentBytesPerHour int64
+	// BaselineBytesPerHour is the growth rate over the baseline window, never negative
+	BaselineBytesPerHour int64
+	// RecentGrowthBytes is the storage added over the recent window
+	RecentGrowthBytes int64
+	// RecentWindow is the time the recent growth was measured over
+	RecentWindow time.Duration
+	// Anomalous is true when the cluster grows abnormally fast
+	Anomalous bool
+	// Started is true when the anomaly was first detected by this analysis
+	Started bool
+}
+
+// RecordGrowth adds the current used bytes of a cluster to its growth samples and
+// compares the growth of the recent window to the baseline before it. Growth is
+// anomalous when it is at least growthFactor times the baseline rate and above
+// minGrowthMiPerHour. The previous status is not modified
+func RecordGrowth(
+	previous *cnpgv1alpha1.GrowthStatus,
+	config cnpgv1alpha1.AnomalyDetectionConfig,
+	usedBytes int64,
+	now time.Time,
+) (*cnpgv1alpha1.GrowthStatus, GrowthAnalysis) {
+	recentWindow := time.Duration(
+		getThresholdOrDefault(config.RecentWindowMinutes, DefaultAnomalyRecentWindowMinutes)) * time.Minute
+	baselineWindow := time.Duration(
+		getThresholdOrDefault(config.BaselineWindowHours, DefaultAnomalyBaselineWindowHours)) * time.Hour
+
+	status := &cnpgv1alpha1.GrowthStatus{}
+	var wasAnomalous bool
+	if previous != nil {
+		wasAnomalous = previous.AnomalyDetectedAt != nil
+		oldest := now.Add(-(baselineWindow + recentWindow))
+		for _, sample := range previous.Samples {
+			if sample.Time.Time.Before(oldest) || sample.Time.Time.After(now) {
+				continue
+			}
+			status.Samples = append(status.Samples, sample)
+		}
+	}
+
+	analysis := analyzeGrowth(status.Samples, config, usedBytes, now, recentWindow, baselineWindow)
+	if analysis.Known {
+		status.RecentBytesPerHour = analysis.RecentBytesPerHour
+		status.BaselineBytesPerHour = analysis.BaselineBytesPerHour
+	}
+	if analysis.Anomalous {
+		analysis.Started = !wasAnomalous
+		status.AnomalyDetectedAt = &metav1.Time{Time: now}
+		if wasAnomalous {
+			status.AnomalyDetectedAt = previous.AnomalyDetectedAt.DeepCopy()
+		}
+	}
+
+	interval := max(minGrowthSampleInterval, (baselineWindow+recentWindow)/maxGrowthSamples)
+	if n := len(status.Samples); n == 0 || now.Sub(status.Samples[n-1].Time.Time) >= interval {
+		status.Samples = append(status.Samples, cnpgv1alpha1.UsageSample{
+			Time:      metav1.NewTime(now),
+			UsedBytes: usedBytes,
+		})
+	}
+	return status, analysis
+}
+
+// analyzeGrowth measures the recent growth from the newest sample at least recentWindow
+// old, and the baseline growth from the oldest sample to that one. The baseline must
+// span at least half the baseline window
+func analyzeGrowth(
+	samples []cnpgv1alpha1.UsageSample,
+	config cnpgv1alpha1.AnomalyDetectionConfig,
+	usedBytes int64,
+	now time.Time,
+	recentWindow, baselineWindow time.Duration,
+) GrowthAnalysis {
+	reference := -1
+	for i := range samples {
+		if now.Sub(samples[i].Time.Time) >= recentWindow {
+			reference = i
+		}
+	}
+	if reference < 0 {
+		return GrowthAnalysis{}
+	}
+	ref := samples[reference]
+	baselineSpan := ref.Time.Sub(samples[0].Time.Time)
+	if baselineSpan < baselineWindow/2 {
+		return GrowthAnalysis{}
+	}
+
+	elapsed := now.Sub(ref.Time.Time)
+	analysis := GrowthAnalysis{
+		Known:             true,
+		RecentGrowthBytes: usedBytes - ref.UsedBytes,
+		RecentWindow:      elapsed,
+	}
+	analysis.RecentBytesPerHour = int64(float64(analysis.RecentGrowthBytes) / elapsed.Hours())
+	analysis.BaselineBytesPerHour = max(0,
+		int64(float64(ref.UsedBytes-samples[0].UsedBytes)/baselineSpan.Hours()))
+
+	factor := int64(getThresholdOrDefault(config.GrowthFactor, DefaultAnomalyGrowthFactor))
+	minRate := int64(getThresholdOrDefault(config.MinGrowthMiPerHour, DefaultAnomalyMinGrowthMiPerHour)) << 20
+	analysis.Anomalous = analysis.RecentBytesPerHour >= minRate &&
+		analysis.RecentBytesPerHour >= factor*analysis.BaselineBytesPerHour
+	return analysis
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// growthRun feeds RecordGrowth a usage that grows at the given rates, sampling every
+// five minutes, and returns the last status and analysis
+type growthRun struct {
+	config   cnpgv1alpha1.AnomalyDetectionConfig
+	now      time.Time
+	used     int64
+	status   *cnpgv1alpha1.GrowthStatus
+	analysis GrowthAnalysis
+	started  int
+}
+
+func (g *growthRun) grow(d time.Duration, bytesPerHour int64) {
+	const step = 5 * time.Minute
+	for elapsed := time.Duration(0); elapsed < d; elapsed += step {
+		g.now = g.now.Add(step)
+		g.used += bytesPerHour / int64(time.Hour/step)
+		g.status, g.analysis = RecordGrowth(g.status, g.config, g.used, g.now)
+		if g.analysis.Started {
+			g.started++
+		}
+	}
+}
+
+func TestRecordGrowth(t *testing.T) {
+	const mi = int64(1) << 20
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("needs half the baseline window", func(t *testing.T) {
+		run := &growthRun{config: cnpgv1alpha1.AnomalyDetectionConfig{Enabled: true}, now: start, used: 10 << 30}
+		run.grow(11*time.Hour, 100*mi)
+		if run.analysis.Known {
+			t.Fatal("expected growth to be unknown before half the baseline was observed")
+		}
+		run.grow(3*time.Hour, 100*mi)
+		if !run.analysis.Known {
+			t.Fatal("expected growth to be known once half the baseline was observed")
+		}
+		if rate := run.analysis.BaselineBytesPerHour; rate < 90*mi || rate > 110*mi {
+			t.Errorf("expected a baseline of about 100Mi/h, got %d", rate)
+		}
+		if run.analysis.Anomalous {
+			t.Error("expected steady growth not to be anomalous")
+		}
+	})
+
+	t.Run("detects a growth spike once", func(t *testing.T) {
+		run := &growthRun{config: cnpgv1alpha1.AnomalyDetectionConfig{Enabled: true}, now: start, used: 10 << 30}
+		run.grow(24*time.Hour, 100*mi)
+		run.grow(2*time.Hour, 2048*mi)
+
+		if !run.analysis.Anomalous {
+			t.Fatalf("expected a 20x growth spike to be anomalous, got %+v", run.analysis)
+		}
+		if run.started != 1 {
+			t.Errorf("expected the anomaly to start once, started %d times", run.started)
+		}
+		if run.status.AnomalyDetectedAt == nil {
+			t.Fatal("expected anomalyDetectedAt to be set")
+		}
+		detectedAt := run.status.AnomalyDetectedAt.Time
+
+		run.grow(30*time.Minute, 2048*mi)
+		if !run.status.AnomalyDetectedAt.Time.Equal(detectedAt) {
+			t.Error("expected anomalyDetectedAt to be kept while the anomaly lasts")
+		}
+
+		run.grow(3*time.Hour, 0)
+		if run.analysis.Anomalous || run.status.AnomalyDetectedAt != nil {
+			t.Error("expected the anomaly to end once growth stops")
+		}
+	})
+
+	t.Run("ignores growth below the minimum rate", func(t *testing.T) {
+		run := &growthRun{config: cnpgv1alpha1.AnomalyDetectionConfig{Enabled: true}, now: start, used: 10 << 30}
+		run.grow(24*time.Hour, 0)
+		run.grow(2*time.Hour, 100*mi)
+		if run.analysis.Anomalous {
+			t.Errorf("expected 100Mi/h on an idle cluster to stay below the minimum, got %+v", run.analysis)
+		}
+
+		run.config.MinGrowthMiPerHour = 50
+		run.grow(5*time.Minute, 100*mi)
+		if !run.analysis.Anomalous {
+			t.Errorf("expected 100Mi/h to be anomalous with a 50Mi/h minimum, got %+v", run.analysis)
+		}
+	})
+
+	t.Run("honors the growth factor", func(t *testing.T) {
+		config := cnpgv1alpha1.AnomalyDetectionConfig{Enabled: true, GrowthFactor: 10, MinGrowthMiPerHour: 1}
+		run := &growthRun{config: config, now: start, used: 10 << 30}
+		run.grow(24*time.Hour, 100*mi)
+		run.grow(2*time.Hour, 500*mi)
+		if run.analysis.Anomalous {
+			t.Errorf("expected 5x growth to stay below a factor of 10, got %+v", run.analysis)
+		}
+	})
+
+	t.Run("bounds the samples", func(t *testing.T) {
+		config := cnpgv1alpha1.AnomalyDetectionConfig{Enabled: true, BaselineWindowHours: 168}
+		run := &growthRun{config: config, now: start, used: 10 << 30}
+		run.grow(10*24*time.Hour, 100*mi)
+		if n := len(run.status.Samples); n > maxGrowthSamples+1 {
+			t.Errorf("expected at most %d samples, got %d", maxGrowthSamples+1, n)
+		}
+		if !run.analysis.Known {
+			t.Error("expected growth to be known with a sparse sample interval")
+		}
+	})
+
+	t.Run("does not modify the previous status", func(t *testing.T) {
+		previous, _ := RecordGrowth(nil, cnpgv1alpha1.AnomalyDetectionConfig{}, 100, start)
+		RecordGrowth(previous, cnpgv1alpha1.AnomalyDetectionConfig{}, 200, start.Add(time.Hour))
+		if len(previous.Samples) != 1 {
+			t.Errorf("expected previous samples to be unchanged, got %d", len(previous.Samples))
+		}
+	})
+}
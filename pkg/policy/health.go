@@ -0,0 +1,119 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"math"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// Weights of the health score components, summing to 100
+const (
+	HealthWeightUsage     = 40
+	HealthWeightGrowth    = 20
+	HealthWeightBackup    = 20
+	HealthWeightArchiving = 10
+	HealthWeightBreaker   = 10
+)
+
+const (
+	// healthyRunwayHours is the time until full from which growth costs no points
+	healthyRunwayHours = 30 * 24
+	// criticalRunwayHours is the time until full at which growth costs all its points
+	criticalRunwayHours = 24
+	// healthyBackupAgeHours is the backup age from which backups start costing points
+	healthyBackupAgeHours = 24
+	// staleBackupAgeHours is the backup age at which backups cost all their points
+	staleBackupAgeHours = 7 * 24
+)
+
+// HealthScore returns a 0-100 storage health score of a cluster, 100 being healthy,
+// weighted from its usage against the thresholds, the time until it is full at its
+// growth rate, the age of its last backup, WAL archiving and the circuit breaker.
+// Backups and archiving only count when the cluster's backups are monitored; the
+// other components are weighted up otherwise. It returns nil while the cluster's
+// capacity is unknown
+func HealthScore(mc *cnpgv1alpha1.ManagedCluster, thresholds cnpgv1alpha1.ThresholdsConfig) *int32 {
+	if mc.CapacityBytes <= 0 {
+		return nil
+	}
+	t := EffectiveThresholds(thresholds)
+
+	var score, weight float64
+	add := func(componentWeight int, value float64) {
+		score += float64(componentWeight) * value
+		weight += float64(componentWeight)
+	}
+
+	add(HealthWeightUsage, 1-interpolate(float64(mc.UsagePercent), float64(t.Warning), float64(t.Emergency)))
+	add(HealthWeightGrowth, growthHealth(mc))
+	if backup := mc.BackupStatus; backup != nil {
+		switch {
+		case !backup.BackupConfigured || backup.LastBackupTime == nil:
+			add(HealthWeightBackup, 0)
+		default:
+			add(HealthWeightBackup,
+				1-interpolate(float64(backup.LastBackupAgeHours), healthyBackupAgeHours, staleBackupAgeHours))
+		}
+		if backup.BackupConfigured {
+			archiving := 0.0
+			if backup.ContinuousArchivingWorking {
+				archiving = 1
+			}
+			add(HealthWeightArchiving, archiving)
+		}
+	}
+	breaker := 1.0
+	if meta.IsStatusConditionTrue(mc.Conditions, cnpgv1alpha1.ManagedClusterConditionCircuitBreakerOpen) {
+		breaker = 0
+	}
+	add(HealthWeightBreaker, breaker)
+
+	result := int32(math.Round(score / weight * 100))
+	return &result
+}
+
+// growthHealth scores the time until a cluster is full at the faster of its recent and
+// baseline growth rates, halved while its growth is anomalous
+func growthHealth(mc *cnpgv1alpha1.ManagedCluster) float64 {
+	if mc.Growth == nil {
+		return 1
+	}
+	health := 1.0
+	if rate := max(mc.Growth.RecentBytesPerHour, mc.Growth.BaselineBytesPerHour); rate > 0 {
+		runwayHours := float64(max(mc.CapacityBytes-mc.UsedBytes, 0)) / float64(rate)
+		health = interpolate(runwayHours, criticalRunwayHours, healthyRunwayHours)
+	}
+	if mc.Growth.AnomalyDetectedAt != nil {
+		health = min(health, 0.5)
+	}
+	return health
+}
+
+// interpolate returns where value lies between low (0) and high (1), clamped to [0, 1]
+func interpolate(value, low, high float64) float64 {
+	if high <= low {
+		if value >= high {
+			return 1
+		}
+		return 0
+	}
+	return math.Min(math.Max((value-low)/(high-low), 0), 1)
+}
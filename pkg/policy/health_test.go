@@ -0,0 +1,84 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestHealthScore(t *testing.T) {
+	const gi = int64(1) << 30
+	lastBackup := metav1.Now()
+	anomalyAt := metav1.Now()
+	breakerOpen := []metav1.Condition{{
+		Type:   cnpgv1alpha1.ManagedClusterConditionCircuitBreakerOpen,
+		Status: metav1.ConditionTrue,
+	}}
+
+	tests := []struct {
+		name     string
+		cluster  cnpgv1alpha1.ManagedCluster
+		expected *int32
+	}{
+		{"unknown capacity", cnpgv1alpha1.ManagedCluster{UsagePercent: 50}, nil},
+		{"healthy", cnpgv1alpha1.ManagedCluster{UsagePercent: 50, CapacityBytes: 100}, score(100)},
+		{"between warning and emergency", cnpgv1alpha1.ManagedCluster{UsagePercent: 80, CapacityBytes: 100}, score(71)},
+		{"at the emergency threshold", cnpgv1alpha1.ManagedCluster{UsagePercent: 90, CapacityBytes: 100}, score(43)},
+		{"circuit breaker open", cnpgv1alpha1.ManagedCluster{
+			UsagePercent: 50, CapacityBytes: 100, Conditions: breakerOpen}, score(86)},
+		{"full within a day", cnpgv1alpha1.ManagedCluster{
+			UsagePercent: 50, UsedBytes: 50 * gi, CapacityBytes: 100 * gi,
+			Growth: &cnpgv1alpha1.GrowthStatus{RecentBytesPerHour: 50 * gi / 24}}, score(71)},
+		{"anomalous growth", cnpgv1alpha1.ManagedCluster{
+			UsagePercent: 50, CapacityBytes: 100,
+			Growth: &cnpgv1alpha1.GrowthStatus{AnomalyDetectedAt: &anomalyAt}}, score(86)},
+		{"recent backup and working archiving", cnpgv1alpha1.ManagedCluster{
+			UsagePercent: 50, CapacityBytes: 100,
+			BackupStatus: &cnpgv1alpha1.ClusterBackupStatus{
+				BackupConfigured: true, LastBackupTime: &lastBackup, LastBackupAgeHours: 12,
+				ContinuousArchivingWorking: true,
+			}}, score(100)},
+		{"stale backup and broken archiving", cnpgv1alpha1.ManagedCluster{
+			UsagePercent: 50, CapacityBytes: 100,
+			BackupStatus: &cnpgv1alpha1.ClusterBackupStatus{
+				BackupConfigured: true, LastBackupTime: &lastBackup, LastBackupAgeHours: 168,
+			}}, score(70)},
+		{"backups not configured", cnpgv1alpha1.ManagedCluster{
+			UsagePercent: 50, CapacityBytes: 100,
+			BackupStatus: &cnpgv1alpha1.ClusterBackupStatus{}}, score(78)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HealthScore(&tt.cluster, cnpgv1alpha1.ThresholdsConfig{})
+			switch {
+			case tt.expected == nil && got != nil:
+				t.Errorf("expected no score, got %d", *got)
+			case tt.expected != nil && (got == nil || *got != *tt.expected):
+				t.Errorf("expected %d, got %v", *tt.expected, got)
+			}
+		})
+	}
+}
+
+func score(value int32) *int32 {
+	return &value
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// DefaultSLOObjective is the storage objective, in percent, when the policy sets none
+	DefaultSLOObjective = 99.5
+	// DefaultSLOWindowDays is the SLO window when the policy sets none
+	DefaultSLOWindowDays = 30
+
+	// SLOViolationCriticalUsage is the violation of a cluster at or above the critical threshold
+	SLOViolationCriticalUsage = "CriticalUsage"
+	// SLOViolationBackupUnhealthy is the violation of a cluster whose backups are unhealthy
+	SLOViolationBackupUnhealthy = "BackupUnhealthy"
+)
+
+// SLOObjective returns the storage objective of a policy as a ratio, e.g. 0.995
+func SLOObjective(cfg cnpgv1alpha1.StorageSLOConfig) float64 {
+	objective, err := strconv.ParseFloat(cfg.Objective, 64)
+	if err != nil || objective <= 0 || objective >= 100 {
+		objective = DefaultSLOObjective
+	}
+	return objective / 100
+}
+
+// SLOWindow returns the window the error budget of a policy is spent over
+func SLOWindow(cfg cnpgv1alpha1.StorageSLOConfig) time.Duration {
+	days := cfg.WindowDays
+	if days <= 0 {
+		days = DefaultSLOWindowDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// StorageSLOViolations returns why a cluster violates the storage objective, from its
+// conditions: usage at or above the critical threshold, or unhealthy backups. known is
+// false while its storage health is unknown, so the time is not counted
+func StorageSLOViolations(conditions []metav1.Condition) (violations []string, known bool) {
+	storage := meta.FindStatusCondition(conditions, cnpgv1alpha1.ManagedClusterConditionStorageHealthy)
+	if storage == nil || storage.Status == metav1.ConditionUnknown {
+		return nil, false
+	}
+	critical := []string{
+		ThresholdReason(ThresholdLevelCritical),
+		ThresholdReason(ThresholdLevelExpansion),
+		ThresholdReason(ThresholdLevelEmergency),
+	}
+	if storage.Status == metav1.ConditionFalse && slices.Contains(critical, storage.Reason) {
+		violations = append(violations, SLOViolationCriticalUsage)
+	}
+	if meta.IsStatusConditionFalse(conditions, cnpgv1alpha1.ManagedClusterConditionBackupHealthy) {
+		violations = append(violations, SLOViolationBackupUnhealthy)
+	}
+	return violations, true
+}
+
+// TrackStorageSLO returns the SLO status of a cluster with the time since its previous
+// evaluation added, counted as violating when it violates the objective now. A new
+// window starts once the previous one ended
+func TrackStorageSLO(
+	previous *cnpgv1alpha1.StorageSLOStatus,
+	cfg cnpgv1alpha1.StorageSLOConfig,
+	elapsed time.Duration,
+	violations []string,
+	known bool,
+	now time.Time,
+) *cnpgv1alpha1.StorageSLOStatus {
+	status := &cnpgv1alpha1.StorageSLOStatus{WindowStart: metav1.NewTime(now)}
+	if previous != nil && now.Sub(previous.WindowStart.Time) < SLOWindow(cfg) {
+		status.WindowStart = previous.WindowStart
+		status.TrackedSeconds = previous.TrackedSeconds
+		status.ViolationSeconds = previous.ViolationSeconds
+	}
+	if known {
+		seconds := int64(elapsed.Seconds())
+		status.TrackedSeconds += seconds
+		if len(violations) > 0 {
+			status.ViolationSeconds += seconds
+		}
+	}
+	status.Violations = violations
+	status.ErrorBudgetRemainingPercent = int32(math.Floor(ErrorBudgetRemaining(status, cfg) * 100))
+	return status
+}
+
+// ErrorBudgetRemaining returns the share of a window's error budget a cluster has left,
+// negative once it is exhausted. The budget is the objective's allowed violation time
+// over the whole window
+func ErrorBudgetRemaining(status *cnpgv1alpha1.StorageSLOStatus, cfg cnpgv1alpha1.StorageSLOConfig) float64 {
+	budget := (1 - SLOObjective(cfg)) * SLOWindow(cfg).Seconds()
+	return 1 - float64(status.ViolationSeconds)/budget
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestSLOObjective(t *testing.T) {
+	tests := []struct {
+		objective string
+		expected  float64
+	}{
+		{"", 0.995},
+		{"99.9", 0.999},
+		{"95", 0.95},
+		{"invalid", 0.995},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.objective, func(t *testing.T) {
+			got := SLOObjective(cnpgv1alpha1.StorageSLOConfig{Objective: tt.objective})
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStorageSLOViolations(t *testing.T) {
+	storage := func(status metav1.ConditionStatus, reason string) metav1.Condition {
+		return metav1.Condition{Type: cnpgv1alpha1.ManagedClusterConditionStorageHealthy, Status: status, Reason: reason}
+	}
+	backupFailed := metav1.Condition{
+		Type:   cnpgv1alpha1.ManagedClusterConditionBackupHealthy,
+		Status: metav1.ConditionFalse,
+	}
+
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		expected   []string
+		known      bool
+	}{
+		{"no conditions", nil, nil, false},
+		{"metrics unavailable", []metav1.Condition{storage(metav1.ConditionUnknown, "MetricsUnavailable")}, nil, false},
+		{"healthy", []metav1.Condition{storage(metav1.ConditionTrue, ReasonBelowThresholds)}, nil, true},
+		{"warning", []metav1.Condition{
+			storage(metav1.ConditionFalse, ThresholdReason(ThresholdLevelWarning))}, nil, true},
+		{"critical", []metav1.Condition{storage(metav1.ConditionFalse, ThresholdReason(ThresholdLevelCritical))},
+			[]string{SLOViolationCriticalUsage}, true},
+		{"emergency and failed backups", []metav1.Condition{
+			storage(metav1.ConditionFalse, ThresholdReason(ThresholdLevelEmergency)), backupFailed},
+			[]string{SLOViolationCriticalUsage, SLOViolationBackupUnhealthy}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, known := StorageSLOViolations(tt.conditions)
+			if known != tt.known || !slices.Equal(violations, tt.expected) {
+				t.Errorf("expected %v (known %v), got %v (known %v)", tt.expected, tt.known, violations, known)
+			}
+		})
+	}
+}
+
+func TestTrackStorageSLO(t *testing.T) {
+	cfg := cnpgv1alpha1.StorageSLOConfig{Enabled: true, Objective: "99", WindowDays: 1}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	status := TrackStorageSLO(nil, cfg, 0, nil, true, start)
+	if !status.WindowStart.Time.Equal(start) || status.ErrorBudgetRemainingPercent != 100 {
+		t.Fatalf("expected a new window with the full budget, got %+v", status)
+	}
+
+	// 1% of a day is 864 seconds of budget
+	status = TrackStorageSLO(status, cfg, 5*time.Minute, nil, true, start.Add(5*time.Minute))
+	status = TrackStorageSLO(status, cfg, 216*time.Second, []string{SLOViolationCriticalUsage}, true,
+		start.Add(10*time.Minute))
+	status = TrackStorageSLO(status, cfg, time.Minute, nil, false, start.Add(11*time.Minute))
+	if status.TrackedSeconds != 516 || status.ViolationSeconds != 216 {
+		t.Errorf("expected 516s tracked and 216s violating, got %+v", status)
+	}
+	if status.ErrorBudgetRemainingPercent != 75 {
+		t.Errorf("expected 75%% of the budget left, got %d", status.ErrorBudgetRemainingPercent)
+	}
+
+	status = TrackStorageSLO(status, cfg, time.Minute, []string{SLOViolationBackupUnhealthy}, true,
+		start.Add(25*time.Hour))
+	if !status.WindowStart.Time.Equal(start.Add(25*time.Hour)) || status.TrackedSeconds != 60 ||
+		status.ViolationSeconds != 60 {
+		t.Errorf("expected a new window once the previous one ended, got %+v", status)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// ClusterMatcher decides whether a policy manages a cluster from its label selector,
+// included clusters and excluded clusters
+type ClusterMatcher struct {
+	selector labels.Selector
+	included map[string]bool
+	patterns []namePattern
+	excluded map[string]bool
+}
+
+type namePattern struct {
+	re        *regexp.Regexp
+	namespace string
+}
+
+// NewClusterMatcher compiles the cluster targeting of a policy. A nil selector matches
+// every cluster unless clusters are included by name, in which case only those match
+func NewClusterMatcher(
+	selector *metav1.LabelSelector,
+	include *cnpgv1alpha1.ClusterInclusion,
+	exclude []cnpgv1alpha1.ClusterReference,
+) (*ClusterMatcher, error) {
+	m := &ClusterMatcher{
+		included: make(map[string]bool),
+		excluded: make(map[string]bool, len(exclude)),
+	}
+
+	switch {
+	case selector != nil:
+		sel, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector: %w", err)
+		}
+		m.selector = sel
+	case include == nil || (len(include.Clusters) == 0 && len(include.NamePatterns) == 0):
+		m.selector = labels.Everything()
+	}
+
+	if include != nil {
+		for _, ref := range include.Clusters {
+			m.included[clusterKey(ref.Namespace, ref.Name)] = true
+		}
+		for _, p := range include.NamePatterns {
+			re, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid cluster name pattern %q: %w", p.Pattern, err)
+			}
+			m.patterns = append(m.patterns, namePattern{re: re, namespace: p.Namespace})
+		}
+	}
+
+	for _, ref := range exclude {
+		m.excluded[clusterKey(ref.Namespace, ref.Name)] = true
+	}
+	return m, nil
+}
+
+// Matches reports whether the policy manages the cluster
+func (m *ClusterMatcher) Matches(name, namespace string, clusterLabels map[string]string) bool {
+	key := clusterKey(namespace, name)
+	if m.excluded[key] {
+		return false
+	}
+	if m.selector != nil && m.selector.Matches(labels.Set(clusterLabels)) {
+		return true
+	}
+	if m.included[key] {
+		return true
+	}
+	for _, p := range m.patterns {
+		if (p.namespace == "" || p.namespace == namespace) && p.re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func clusterKey(namespace, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestClusterMatcher(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"storage-policy": "default"}}
+	include := &cnpgv1alpha1.ClusterInclusion{
+		Clusters: []cnpgv1alpha1.ClusterReference{{Name: "legacy", Namespace: "billing"}},
+		NamePatterns: []cnpgv1alpha1.ClusterNamePattern{
+			{Pattern: "orders-.*"},
+			{Pattern: "pg-[0-9]+", Namespace: "analytics"},
+		},
+	}
+	exclude := []cnpgv1alpha1.ClusterReference{{Name: "orders-scratch", Namespace: "shop"}}
+	labeled := map[string]string{"storage-policy": "default"}
+
+	tests := []struct {
+		name      string
+		selector  *metav1.LabelSelector
+		include   *cnpgv1alpha1.ClusterInclusion
+		cluster   string
+		namespace string
+		labels    map[string]string
+		want      bool
+	}{
+		{name: "no targeting matches everything", cluster: "any", namespace: "db", want: true},
+		{name: "selector match", selector: selector, cluster: "pg", namespace: "db", labels: labeled, want: true},
+		{name: "selector mismatch", selector: selector, cluster: "pg", namespace: "db", want: false},
+		{name: "included by name", selector: selector, include: include, cluster: "legacy", namespace: "billing", want: true},
+		{name: "included name in other namespace", include: include, cluster: "legacy", namespace: "db", want: false},
+		{name: "pattern match", include: include, cluster: "orders-eu", namespace: "shop", want: true},
+		{name: "pattern is anchored", include: include, cluster: "old-orders-eu", namespace: "shop", want: false},
+		{name: "namespaced pattern", include: include, cluster: "pg-12", namespace: "analytics", want: true},
+		{name: "namespaced pattern other namespace", include: include, cluster: "pg-12", namespace: "db", want: false},
+		{name: "include without selector skips labels", include: include, cluster: "pg", namespace: "db", labels: labeled},
+		{name: "selector still applies with include", selector: selector, include: include, cluster: "pg", namespace: "db",
+			labels: labeled, want: true},
+		{name: "exclude wins over pattern", include: include, cluster: "orders-scratch", namespace: "shop", want: false},
+		{name: "exclude wins over selector", selector: selector, cluster: "orders-scratch", namespace: "shop",
+			labels: labeled, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewClusterMatcher(tt.selector, tt.include, exclude)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := m.Matches(tt.cluster, tt.namespace, tt.labels); got != tt.want {
+				t.Errorf("Matches(%s/%s) = %v, want %v", tt.namespace, tt.cluster, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterMatcher_InvalidPattern(t *testing.T) {
+	include := &cnpgv1alpha1.ClusterInclusion{
+		NamePatterns: []cnpgv1alpha1.ClusterNamePattern{{Pattern: "orders-("}},
+	}
+	if _, err := NewClusterMatcher(nil, include, nil); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// UsageHistoryHours is the number of hourly entries a usage history keeps
+	UsageHistoryHours = 30 * 24
+	// noUsageSample marks the hours of a usage history without a sample
+	noUsageSample = -1
+)
+
+// RecordUsageHistory adds the current usage as the entry of the current hour. Only the
+// first sample of each hour is kept: hours missed since the newest entry become gaps
+// and entries older than 30 days are dropped. It returns false, leaving the status
+// unchanged, when the current hour already has an entry
+func RecordUsageHistory(
+	status *cnpgv1alpha1.StorageUsageHistoryStatus,
+	usedBytes, dataUsedBytes int64,
+	now time.Time,
+) bool {
+	hour := now.Truncate(time.Hour)
+	hours := 1
+	if status.Newest != nil {
+		if !hour.After(status.Newest.Time) {
+			return false
+		}
+		hours = int(hour.Sub(status.Newest.Time) / time.Hour)
+	} else {
+		status.UsedBytes, status.DataUsedBytes = nil, nil
+	}
+
+	status.UsedBytes = appendHours(status.UsedBytes, hours, usedBytes)
+	status.DataUsedBytes = appendHours(status.DataUsedBytes, hours, dataUsedBytes)
+	status.Newest = &metav1.Time{Time: hour}
+	return true
+}
+
+// appendHours advances entries by the given number of hours, ending with value, and
+// keeps the newest UsageHistoryHours entries
+func appendHours(entries []int64, hours int, value int64) []int64 {
+	for range min(hours, UsageHistoryHours) - 1 {
+		entries = append(entries, noUsageSample)
+	}
+	entries = append(entries, value)
+	if excess := len(entries) - UsageHistoryHours; excess > 0 {
+		entries = slices.Clone(entries[excess:])
+	}
+	return entries
+}
+
+// UsageHistorySamples converts the hourly entries of a usage history ending at newest
+// to samples, oldest first, leaving out the gaps
+func UsageHistorySamples(newest *metav1.Time, entries []int64) []cnpgv1alpha1.UsageSample {
+	if newest == nil {
+		return nil
+	}
+	samples := make([]cnpgv1alpha1.UsageSample, 0, len(entries))
+	for i, usedBytes := range entries {
+		if usedBytes < 0 {
+			continue
+		}
+		age := time.Duration(len(entries)-1-i) * time.Hour
+		samples = append(samples, cnpgv1alpha1.UsageSample{
+			Time:      metav1.NewTime(newest.Add(-age)),
+			UsedBytes: usedBytes,
+		})
+	}
+	return samples
+}
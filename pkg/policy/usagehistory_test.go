@@ -0,0 +1,87 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestRecordUsageHistory(t *testing.T) {
+	start := time.Date(2025, 6, 1, 2, 10, 0, 0, time.UTC)
+	status := &cnpgv1alpha1.StorageUsageHistoryStatus{}
+
+	if !RecordUsageHistory(status, 100, 80, start) {
+		t.Fatal("expected the first sample to be recorded")
+	}
+	if !status.Newest.Time.Equal(time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the newest entry to start at the hour, got %v", status.Newest)
+	}
+	if RecordUsageHistory(status, 200, 160, start.Add(40*time.Minute)) {
+		t.Error("expected a second sample within the hour to be dropped")
+	}
+
+	// Three hours later: two missed hours become gaps
+	if !RecordUsageHistory(status, 400, 320, start.Add(3*time.Hour)) {
+		t.Fatal("expected a sample in a new hour to be recorded")
+	}
+	if want := []int64{100, -1, -1, 400}; !slices.Equal(status.UsedBytes, want) {
+		t.Errorf("expected used bytes %v, got %v", want, status.UsedBytes)
+	}
+	if want := []int64{80, -1, -1, 320}; !slices.Equal(status.DataUsedBytes, want) {
+		t.Errorf("expected data used bytes %v, got %v", want, status.DataUsedBytes)
+	}
+
+	samples := UsageHistorySamples(status.Newest, status.UsedBytes)
+	if len(samples) != 2 || samples[0].UsedBytes != 100 || samples[1].UsedBytes != 400 {
+		t.Fatalf("expected the two recorded samples without gaps, got %+v", samples)
+	}
+	if got := samples[1].Time.Sub(samples[0].Time.Time); got != 3*time.Hour {
+		t.Errorf("expected the samples 3 hours apart, got %v", got)
+	}
+
+	// A clock going backwards records nothing
+	if RecordUsageHistory(status, 1, 1, start.Add(-time.Hour)) {
+		t.Error("expected a sample before the newest entry to be dropped")
+	}
+}
+
+func TestRecordUsageHistory_Retention(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	status := &cnpgv1alpha1.StorageUsageHistoryStatus{}
+	for hour := range UsageHistoryHours + 10 {
+		RecordUsageHistory(status, int64(hour), int64(hour), start.Add(time.Duration(hour)*time.Hour))
+	}
+	if len(status.UsedBytes) != UsageHistoryHours || status.UsedBytes[0] != 10 {
+		t.Errorf("expected the newest %d hours, got %d entries from %d",
+			UsageHistoryHours, len(status.UsedBytes), status.UsedBytes[0])
+	}
+
+	// After a long outage only the current hour is left
+	RecordUsageHistory(status, 7, 7, start.Add(90*24*time.Hour))
+	samples := UsageHistorySamples(status.Newest, status.UsedBytes)
+	if len(status.UsedBytes) != UsageHistoryHours || len(samples) != 1 || samples[0].UsedBytes != 7 {
+		t.Errorf("expected a single sample after a long gap, got %d entries and %+v", len(status.UsedBytes), samples)
+	}
+
+	if UsageHistorySamples(nil, []int64{1}) != nil {
+		t.Error("expected no samples without a newest entry")
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recorder records Kubernetes Events on CNPG clusters and their PVCs, so
+// `kubectl describe` shows threshold breaches and the remediation taken.
+package recorder
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+// Component is the source component of recorded events
+const Component = "cnpg-storage-manager"
+
+// Event reasons
+const (
+	// ReasonThresholdBreached is recorded on a cluster whose storage usage crossed a threshold
+	ReasonThresholdBreached = "StorageThresholdBreached"
+	// ReasonExpansionRequested is recorded on a PVC when its resize is requested
+	ReasonExpansionRequested = "ExpansionRequested"
+	// ReasonExpansionFailed is recorded on a PVC whose resize request failed
+	ReasonExpansionFailed = "ExpansionFailed"
+	// ReasonExpanded is recorded on a PVC once it reports its new capacity
+	ReasonExpanded = "Expanded"
+	// ReasonStorageExpanded is recorded on a cluster when an expansion event completes
+	ReasonStorageExpanded = "StorageExpanded"
+	// ReasonExpansionRecommended is recorded on a cluster when an expansion is published as a recommendation
+	ReasonExpansionRecommended = "ExpansionRecommended"
+	// ReasonWALCleanupCompleted is recorded on a cluster when archived WAL segments were removed
+	ReasonWALCleanupCompleted = "WALCleanupCompleted"
+	// ReasonRemediationFailed is recorded on a cluster when a StorageEvent fails for good
+	ReasonRemediationFailed = "RemediationFailed"
+	// ReasonCircuitBreakerOpened is recorded on a cluster when repeated failures stop remediation
+	ReasonCircuitBreakerOpened = "CircuitBreakerOpened"
+	// ReasonBackupUnhealthy is recorded on a cluster with failed backup checks
+	ReasonBackupUnhealthy = "BackupUnhealthy"
+	// ReasonRestoreTestFailed is recorded on a cluster whose latest backup failed to restore
+	ReasonRestoreTestFailed = "RestoreTestFailed"
+	// ReasonFrequentExpansion is recorded on a cluster that expanded more often than its policy expects
+	ReasonFrequentExpansion = "FrequentExpansion"
+	// ReasonAnomalousGrowth is recorded on a cluster growing much faster than its baseline
+	ReasonAnomalousGrowth = "AnomalousGrowth"
+	// ReasonInstanceFenced is recorded on a cluster when an instance with full storage is fenced
+	ReasonInstanceFenced = "InstanceFenced"
+	// ReasonInstanceUnfenced is recorded on a cluster when a fenced instance has space again
+	ReasonInstanceUnfenced = "InstanceUnfenced"
+	// ReasonWriteProbeFailed is recorded on a cluster whose primary is read-only or out of space
+	ReasonWriteProbeFailed = "WriteProbeFailed"
+	// ReasonFileSystemResizePending is recorded on a PVC whose filesystem resize is stuck
+	ReasonFileSystemResizePending = "FileSystemResizePending"
+	// ReasonResizeRestart is recorded on a cluster when an instance is restarted to complete its resize
+	ReasonResizeRestart = "ResizeRestart"
+	// ReasonInsufficientCapacity is recorded on a cluster when an expansion is skipped for lack of backend capacity
+	ReasonInsufficientCapacity = "InsufficientCapacity"
+	// ReasonQuotaExceeded is recorded on a cluster when an expansion is skipped because it would exceed a quota
+	ReasonQuotaExceeded = "QuotaExceeded"
+	// ReasonQuotaHeadroomLow is recorded on a cluster when a namespace quota cannot hold its next expansion
+	ReasonQuotaHeadroomLow = "QuotaHeadroomLow"
+	// ReasonQuotaRaised is recorded on a cluster when a namespace ResourceQuota is raised for its next expansion
+	ReasonQuotaRaised = "QuotaRaised"
+	// ReasonRemediationAborted is recorded on a cluster when a StorageEvent is stopped by a safety check
+	ReasonRemediationAborted = "RemediationAborted"
+	// ReasonStorageClassMigrating is recorded on a cluster for each instance a storage class migration acts on
+	ReasonStorageClassMigrating = "StorageClassMigrating"
+	// ReasonStorageClassMigrated is recorded on a cluster when a storage class migration completed
+	ReasonStorageClassMigrated = "StorageClassMigrated"
+	// ReasonVolumeTuned is recorded on a PVC moved to a faster VolumeAttributesClass or annotations for its latency
+	ReasonVolumeTuned = "VolumeTuned"
+	// ReasonVolumeAttributesClassUnavailable is recorded on a cluster when its VolumeAttributesClass cannot be used
+	ReasonVolumeAttributesClassUnavailable = "VolumeAttributesClassUnavailable"
+	// ReasonModifyVolumeRequested is recorded on a PVC when it is moved to another VolumeAttributesClass
+	ReasonModifyVolumeRequested = "ModifyVolumeRequested"
+	// ReasonVolumeModified is recorded on a PVC once its volume uses the new VolumeAttributesClass
+	ReasonVolumeModified = "VolumeModified"
+	// ReasonModifyVolumeInfeasible is recorded on a PVC whose CSI driver cannot apply the VolumeAttributesClass
+	ReasonModifyVolumeInfeasible = "ModifyVolumeInfeasible"
+	// ReasonVolumeAttributesChanged is recorded on a cluster when all its PVCs use the new VolumeAttributesClass
+	ReasonVolumeAttributesChanged = "VolumeAttributesChanged"
+	// ReasonRemediationVetoed is recorded on a cluster when a preAction hook rejects a remediation
+	ReasonRemediationVetoed = "RemediationVetoed"
+	// ReasonHookFailed is recorded on a cluster when a remediation hook cannot be called
+	ReasonHookFailed = "HookFailed"
+	// ReasonMaxSizeReached is recorded on a cluster when PVCs that need expansion are at the maximum size
+	ReasonMaxSizeReached = "MaxSizeReached"
+	// ReasonRemediationIneffective is recorded on a cluster when an expansion or WAL cleanup did not reduce usage
+	ReasonRemediationIneffective = "RemediationIneffective"
+)
+
+// Recorder records events on CNPG clusters and PVCs. A nil Recorder, or one without an
+// EventRecorder, records nothing.
+type Recorder struct {
+	recorder record.EventRecorder
+	reader   client.Reader
+	mapper   meta.RESTMapper
+}
+
+// New returns a Recorder. c looks up the UIDs of objects only known by name, which
+// `kubectl describe` needs to match their events, and negotiates the CNPG Cluster API
+// version to look clusters up with.
+func New(recorder record.EventRecorder, c client.Client) *Recorder {
+	return &Recorder{recorder: recorder, reader: c, mapper: c.RESTMapper()}
+}
+
+// Cluster records an event on a discovered CNPG cluster
+func (r *Recorder) Cluster(cluster cnpg.ClusterInfo, eventType, reason, messageFmt string, args ...interface{}) {
+	if r == nil || r.recorder == nil {
+		return
+	}
+	ref := clusterReference(cluster.Name, cluster.Namespace, cluster.UID)
+	r.recorder.Eventf(ref, eventType, reason, messageFmt, args...)
+}
+
+// ClusterByName records an event on a CNPG cluster, looking up its UID
+func (r *Recorder) ClusterByName(
+	ctx context.Context,
+	name, namespace string,
+	eventType, reason, messageFmt string,
+	args ...interface{},
+) {
+	if r == nil || r.recorder == nil {
+		return
+	}
+	cluster, err := cnpg.NewClusterObject(r.mapper)
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("Not recording event, cluster lookup failed",
+			"cluster", name, "namespace", namespace, "reason", reason, "error", err.Error())
+		return
+	}
+	if err := r.reader.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, cluster); err != nil {
+		log.FromContext(ctx).V(1).Info("Not recording event, cluster lookup failed",
+			"cluster", name, "namespace", namespace, "reason", reason, "error", err.Error())
+		return
+	}
+	r.recorder.Eventf(clusterReference(name, namespace, cluster.GetUID()), eventType, reason, messageFmt, args...)
+}
+
+// PVC records an event on a PersistentVolumeClaim, looking up its UID
+func (r *Recorder) PVC(
+	ctx context.Context,
+	name, namespace string,
+	eventType, reason, messageFmt string,
+	args ...interface{},
+) {
+	if r == nil || r.recorder == nil {
+		return
+	}
+	var pvc corev1.PersistentVolumeClaim
+	if err := r.reader.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &pvc); err != nil {
+		log.FromContext(ctx).V(1).Info("Not recording event, PVC lookup failed",
+			"pvc", name, "namespace", namespace, "reason", reason, "error", err.Error())
+		return
+	}
+	r.recorder.Eventf(&corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "PersistentVolumeClaim",
+		Name:       name,
+		Namespace:  namespace,
+		UID:        pvc.UID,
+	}, eventType, reason, messageFmt, args...)
+}
+
+// clusterReference returns the reference events on a CNPG cluster are recorded against
+func clusterReference(name, namespace string, uid types.UID) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: cnpg.CNPGGroupVersion,
+		Kind:       cnpg.CNPGKind,
+		Name:       name,
+		Namespace:  namespace,
+		UID:        uid,
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recorder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+func newTestClient(t *testing.T) *fake.ClientBuilder {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	cluster := &unstructured.Unstructured{}
+	cluster.SetGroupVersionKind(cnpg.CNPGClusterGVK)
+	cluster.SetName("pg-main")
+	cluster.SetNamespace("default")
+	cluster.SetUID("cluster-uid")
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg-main-1", Namespace: "default", UID: "pvc-uid"},
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, pvc)
+}
+
+// nextEvent returns the next recorded event, or "" when none was recorded
+func nextEvent(fakeRecorder *record.FakeRecorder) string {
+	select {
+	case event := <-fakeRecorder.Events:
+		return event
+	default:
+		return ""
+	}
+}
+
+func TestRecorder_Cluster(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	r := New(fakeRecorder, newTestClient(t).Build())
+
+	r.Cluster(cnpg.ClusterInfo{Name: "pg-main", Namespace: "default", UID: "cluster-uid"},
+		corev1.EventTypeWarning, ReasonThresholdBreached, "Storage usage at %.1f%%", 91.5)
+
+	want := "Warning StorageThresholdBreached Storage usage at 91.5%"
+	if got := nextEvent(fakeRecorder); got != want {
+		t.Errorf("expected event %q, got %q", want, got)
+	}
+}
+
+func TestRecorder_ClusterByName(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster string
+		want    string
+	}{
+		{name: "existing cluster", cluster: "pg-main", want: "Normal StorageExpanded 2 PVCs expanded"},
+		{name: "missing cluster", cluster: "pg-gone", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeRecorder := record.NewFakeRecorder(10)
+			r := New(fakeRecorder, newTestClient(t).Build())
+
+			r.ClusterByName(context.Background(), tt.cluster, "default",
+				corev1.EventTypeNormal, ReasonStorageExpanded, "%d PVCs expanded", 2)
+
+			if got := nextEvent(fakeRecorder); got != tt.want {
+				t.Errorf("expected event %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRecorder_PVC(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	r := New(fakeRecorder, newTestClient(t).Build())
+
+	r.PVC(context.Background(), "pg-main-1", "default",
+		corev1.EventTypeNormal, ReasonExpansionRequested, "Resizing to %s", "20Gi")
+	if got := nextEvent(fakeRecorder); !strings.HasPrefix(got, "Normal ExpansionRequested") {
+		t.Errorf("expected an ExpansionRequested event, got %q", got)
+	}
+
+	r.PVC(context.Background(), "pg-main-9", "default",
+		corev1.EventTypeNormal, ReasonExpansionRequested, "Resizing to %s", "20Gi")
+	if got := nextEvent(fakeRecorder); got != "" {
+		t.Errorf("expected no event for a missing PVC, got %q", got)
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+	r.Cluster(cnpg.ClusterInfo{Name: "pg-main"}, corev1.EventTypeNormal, ReasonStorageExpanded, "ignored")
+	r.ClusterByName(context.Background(), "pg-main", "default", corev1.EventTypeNormal, ReasonStorageExpanded, "ignored")
+
+	withoutRecorder := New(nil, newTestClient(t).Build())
+	withoutRecorder.PVC(context.Background(), "pg-main-1", "default",
+		corev1.EventTypeNormal, ReasonExpanded, "ignored")
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CapacityShortfall reports that the storage backend cannot hold an expansion. It is
+// returned as an error so callers can tell it apart with errors.As
+type CapacityShortfall struct {
+	StorageClass   string
+	PVCs           []string
+	RequiredBytes  int64
+	AvailableBytes int64
+	// MaximumVolumeSize is set when a PVC would outgrow the largest volume the backend
+	// can provision, rather than the free capacity
+	MaximumVolumeSize int64
+}
+
+// ShortBytes returns how many bytes the backend is missing
+func (s *CapacityShortfall) ShortBytes() int64 {
+	return s.RequiredBytes - s.AvailableBytes
+}
+
+func (s *CapacityShortfall) Error() string {
+	if s.MaximumVolumeSize > 0 {
+		return fmt.Sprintf("insufficient backend capacity in storage class %s: %s exceeds the maximum volume size %s",
+			s.StorageClass, FormatBytes(s.RequiredBytes), FormatBytes(s.MaximumVolumeSize))
+	}
+	return fmt.Sprintf("insufficient backend capacity in storage class %s: %s required, %s available (short %s)",
+		s.StorageClass, FormatBytes(s.RequiredBytes), FormatBytes(s.AvailableBytes), FormatBytes(s.ShortBytes()))
+}
+
+// CapacityVolume is a PVC an expansion grows, with the topology of its volume
+type CapacityVolume struct {
+	PVC          string
+	StorageClass string
+	// Topology holds the node labels the bound volume is pinned to
+	Topology   map[string]string
+	NewSize    resource.Quantity
+	BytesAdded int64
+}
+
+// CapacityChecker compares planned expansions with the CSIStorageCapacity objects
+// published by CSI drivers
+type CapacityChecker struct {
+	client client.Reader
+}
+
+// NewCapacityChecker creates a new capacity checker
+func NewCapacityChecker(c client.Reader) *CapacityChecker {
+	return &CapacityChecker{client: c}
+}
+
+// CheckExpansion returns the shortfall when the backend cannot hold the planned PVC
+// growth. Clusters without the CSIStorageCapacity API, and storage classes whose driver
+// publishes no capacity, are not checked
+func (c *CapacityChecker) CheckExpansion(
+	ctx context.Context,
+	pvcs []corev1.PersistentVolumeClaim,
+	plan []PVCExpansionResult,
+) (*CapacityShortfall, error) {
+	var capacities storagev1.CSIStorageCapacityList
+	if err := c.client.List(ctx, &capacities); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list CSIStorageCapacity objects: %w", err)
+	}
+	if len(capacities.Items) == 0 {
+		return nil, nil
+	}
+
+	byName := make(map[string]*corev1.PersistentVolumeClaim, len(pvcs))
+	for i := range pvcs {
+		byName[pvcs[i].Name] = &pvcs[i]
+	}
+
+	var volumes []CapacityVolume
+	for _, planned := range plan {
+		pvc := byName[planned.PVCName]
+		if pvc == nil || planned.Skipped || planned.Error != "" || planned.BytesAdded <= 0 ||
+			pvc.Spec.StorageClassName == nil {
+			continue
+		}
+		volume := CapacityVolume{
+			PVC:          pvc.Name,
+			StorageClass: *pvc.Spec.StorageClassName,
+			NewSize:      planned.NewSize,
+			BytesAdded:   planned.BytesAdded,
+		}
+		if pvc.Spec.VolumeName != "" {
+			var pv corev1.PersistentVolume
+			if err := c.client.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, &pv); err != nil {
+				return nil, fmt.Errorf("failed to get PersistentVolume %s: %w", pvc.Spec.VolumeName, err)
+			}
+			volume.Topology = VolumeTopology(&pv)
+		}
+		volumes = append(volumes, volume)
+	}
+	return FindCapacityShortfall(volumes, capacities.Items), nil
+}
+
+// VolumeTopology returns the node labels a volume is pinned to by its node affinity.
+// Only single-valued In requirements identify a topology segment
+func VolumeTopology(pv *corev1.PersistentVolume) map[string]string {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return nil
+	}
+	topology := map[string]string{}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+				topology[expr.Key] = expr.Values[0]
+			}
+		}
+	}
+	return topology
+}
+
+// FindCapacityShortfall matches each volume with the capacity published for its storage
+// class and topology and returns the first segment that cannot hold the combined growth
+// of its volumes. Volumes without a matching capacity are not checked
+func FindCapacityShortfall(volumes []CapacityVolume, capacities []storagev1.CSIStorageCapacity) *CapacityShortfall {
+	required := map[int]*CapacityShortfall{}
+	var order []int
+	for _, volume := range volumes {
+		idx := matchCapacity(volume, capacities)
+		if idx < 0 {
+			continue
+		}
+		capacity := capacities[idx]
+		if capacity.MaximumVolumeSize != nil && volume.NewSize.Cmp(*capacity.MaximumVolumeSize) > 0 {
+			return &CapacityShortfall{
+				StorageClass:      volume.StorageClass,
+				PVCs:              []string{volume.PVC},
+				RequiredBytes:     volume.NewSize.Value(),
+				MaximumVolumeSize: capacity.MaximumVolumeSize.Value(),
+			}
+		}
+		shortfall, ok := required[idx]
+		if !ok {
+			shortfall = &CapacityShortfall{StorageClass: volume.StorageClass, AvailableBytes: capacity.Capacity.Value()}
+			required[idx] = shortfall
+			order = append(order, idx)
+		}
+		shortfall.PVCs = append(shortfall.PVCs, volume.PVC)
+		shortfall.RequiredBytes += volume.BytesAdded
+	}
+
+	for _, idx := range order {
+		if shortfall := required[idx]; shortfall.RequiredBytes > shortfall.AvailableBytes {
+			sort.Strings(shortfall.PVCs)
+			return shortfall
+		}
+	}
+	return nil
+}
+
+// matchCapacity returns the index of the largest capacity published for the volume's
+// storage class whose topology covers the volume, or -1 when there is none
+func matchCapacity(volume CapacityVolume, capacities []storagev1.CSIStorageCapacity) int {
+	best := -1
+	for i := range capacities {
+		capacity := &capacities[i]
+		if capacity.StorageClassName != volume.StorageClass || capacity.Capacity == nil {
+			continue
+		}
+		if capacity.NodeTopology != nil {
+			selector, err := metav1.LabelSelectorAsSelector(capacity.NodeTopology)
+			if err != nil || !selector.Matches(labels.Set(volume.Topology)) {
+				continue
+			}
+		}
+		if best < 0 || capacity.Capacity.Cmp(*capacities[best].Capacity) > 0 {
+			best = i
+		}
+	}
+	return best
+}
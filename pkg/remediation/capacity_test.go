@@ -0,0 +1,239 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func storageCapacity(name, class, capacity string, topology map[string]string) storagev1.CSIStorageCapacity {
+	c := storagev1.CSIStorageCapacity{
+		ObjectMeta:       metav1.ObjectMeta{Name: name, Namespace: "kube-system"},
+		StorageClassName: class,
+	}
+	if capacity != "" {
+		q := resource.MustParse(capacity)
+		c.Capacity = &q
+	}
+	if topology != nil {
+		c.NodeTopology = &metav1.LabelSelector{MatchLabels: topology}
+	}
+	return c
+}
+
+func quantityBytes(size string) int64 {
+	q := resource.MustParse(size)
+	return q.Value()
+}
+
+func TestFindCapacityShortfall(t *testing.T) {
+	zoneA := map[string]string{"topology.kubernetes.io/zone": "a"}
+	zoneB := map[string]string{"topology.kubernetes.io/zone": "b"}
+	volume := func(pvc, class, added, newSize string, topology map[string]string) CapacityVolume {
+		return CapacityVolume{
+			PVC:          pvc,
+			StorageClass: class,
+			Topology:     topology,
+			NewSize:      resource.MustParse(newSize),
+			BytesAdded:   quantityBytes(added),
+		}
+	}
+	maxSize := func(c storagev1.CSIStorageCapacity, size string) storagev1.CSIStorageCapacity {
+		q := resource.MustParse(size)
+		c.MaximumVolumeSize = &q
+		return c
+	}
+
+	tests := []struct {
+		name       string
+		volumes    []CapacityVolume
+		capacities []storagev1.CSIStorageCapacity
+		expectPVCs []string
+		expectMax  bool
+	}{
+		{
+			name:       "enough capacity",
+			volumes:    []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi", zoneA)},
+			capacities: []storagev1.CSIStorageCapacity{storageCapacity("a", "fast", "20Gi", zoneA)},
+		},
+		{
+			name:       "no capacity published for the class",
+			volumes:    []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi", zoneA)},
+			capacities: []storagev1.CSIStorageCapacity{storageCapacity("a", "slow", "1Gi", zoneA)},
+		},
+		{
+			name:       "capacity of another topology is ignored",
+			volumes:    []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi", zoneA)},
+			capacities: []storagev1.CSIStorageCapacity{storageCapacity("b", "fast", "1Gi", zoneB)},
+		},
+		{
+			name: "combined growth exceeds the segment",
+			volumes: []CapacityVolume{
+				volume("db-2", "fast", "10Gi", "60Gi", zoneA),
+				volume("db-1", "fast", "10Gi", "60Gi", zoneA),
+			},
+			capacities: []storagev1.CSIStorageCapacity{
+				storageCapacity("a", "fast", "15Gi", zoneA),
+				storageCapacity("b", "fast", "100Gi", zoneB),
+			},
+			expectPVCs: []string{"db-1", "db-2"},
+		},
+		{
+			name:       "capacity without topology applies everywhere",
+			volumes:    []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi", nil)},
+			capacities: []storagev1.CSIStorageCapacity{storageCapacity("all", "fast", "5Gi", nil)},
+			expectPVCs: []string{"db-1"},
+		},
+		{
+			name:    "largest matching capacity is used",
+			volumes: []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi", zoneA)},
+			capacities: []storagev1.CSIStorageCapacity{
+				storageCapacity("small", "fast", "5Gi", nil),
+				storageCapacity("a", "fast", "50Gi", zoneA),
+			},
+		},
+		{
+			name:       "capacity without a value is ignored",
+			volumes:    []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi", zoneA)},
+			capacities: []storagev1.CSIStorageCapacity{storageCapacity("a", "fast", "", zoneA)},
+		},
+		{
+			name:       "new size above the maximum volume size",
+			volumes:    []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi", zoneA)},
+			capacities: []storagev1.CSIStorageCapacity{maxSize(storageCapacity("a", "fast", "1Ti", zoneA), "50Gi")},
+			expectPVCs: []string{"db-1"},
+			expectMax:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortfall := FindCapacityShortfall(tt.volumes, tt.capacities)
+			if tt.expectPVCs == nil {
+				if shortfall != nil {
+					t.Fatalf("expected no shortfall, got %v", shortfall)
+				}
+				return
+			}
+			if shortfall == nil {
+				t.Fatal("expected a shortfall")
+			}
+			if strings.Join(shortfall.PVCs, ",") != strings.Join(tt.expectPVCs, ",") {
+				t.Errorf("expected PVCs %v, got %v", tt.expectPVCs, shortfall.PVCs)
+			}
+			if (shortfall.MaximumVolumeSize > 0) != tt.expectMax {
+				t.Errorf("expected maximum volume size shortfall %v, got %d", tt.expectMax, shortfall.MaximumVolumeSize)
+			}
+			if !strings.HasPrefix(shortfall.Error(), "insufficient backend capacity in storage class fast") {
+				t.Errorf("unexpected message %q", shortfall.Error())
+			}
+		})
+	}
+}
+
+func TestCapacityShortfall_Error(t *testing.T) {
+	shortfall := &CapacityShortfall{
+		StorageClass:   "fast",
+		RequiredBytes:  20 * 1024 * 1024 * 1024,
+		AvailableBytes: 5 * 1024 * 1024 * 1024,
+	}
+	if shortfall.ShortBytes() != 15*1024*1024*1024 {
+		t.Errorf("expected 15Gi short, got %d", shortfall.ShortBytes())
+	}
+	if !strings.Contains(shortfall.Error(), "short "+FormatBytes(shortfall.ShortBytes())) {
+		t.Errorf("expected the shortfall amount in %q", shortfall.Error())
+	}
+}
+
+func TestCapacityChecker_CheckExpansion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = storagev1.AddToScheme(scheme)
+
+	class := "fast"
+	pvc := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-1", Namespace: "default"},
+		Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &class, VolumeName: "pv-1"},
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+		Spec: corev1.PersistentVolumeSpec{
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "topology.kubernetes.io/zone",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{"a"},
+				}}}},
+			}},
+		},
+	}
+	plan := []PVCExpansionResult{{
+		PVCName:    "db-1",
+		Namespace:  "default",
+		NewSize:    resource.MustParse("60Gi"),
+		BytesAdded: quantityBytes("10Gi"),
+	}}
+
+	tests := []struct {
+		name            string
+		capacities      []storagev1.CSIStorageCapacity
+		expectShortfall bool
+	}{
+		{name: "no capacity objects"},
+		{
+			name: "enough capacity in the zone",
+			capacities: []storagev1.CSIStorageCapacity{
+				storageCapacity("a", class, "20Gi", map[string]string{"topology.kubernetes.io/zone": "a"}),
+			},
+		},
+		{
+			name: "zone is full",
+			capacities: []storagev1.CSIStorageCapacity{
+				storageCapacity("a", class, "2Gi", map[string]string{"topology.kubernetes.io/zone": "a"}),
+				storageCapacity("b", class, "200Gi", map[string]string{"topology.kubernetes.io/zone": "b"}),
+			},
+			expectShortfall: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pv)
+			for i := range tt.capacities {
+				builder = builder.WithObjects(&tt.capacities[i])
+			}
+			checker := NewCapacityChecker(builder.Build())
+
+			shortfall, err := checker.CheckExpansion(context.Background(), []corev1.PersistentVolumeClaim{pvc}, plan)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (shortfall != nil) != tt.expectShortfall {
+				t.Errorf("expected shortfall %v, got %v", tt.expectShortfall, shortfall)
+			}
+		})
+	}
+}
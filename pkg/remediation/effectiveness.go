@@ -0,0 +1,164 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+// DefaultEffectivenessDelay is how long after a remediation completed its volumes are
+// measured again when the policy does not set a delay
+const DefaultEffectivenessDelay = 10 * time.Minute
+
+// EffectivenessDelay returns the policy's effectiveness measurement delay
+func EffectivenessDelay(policy *cnpgv1alpha1.StoragePolicy) time.Duration {
+	if minutes := policy.Spec.Effectiveness.DelayMinutes; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return DefaultEffectivenessDelay
+}
+
+// TracksEffectiveness reports whether the effectiveness of an event is measured: an
+// expansion or WAL cleanup that changes volumes, of a policy tracking effectiveness
+func TracksEffectiveness(policy *cnpgv1alpha1.StoragePolicy, event *cnpgv1alpha1.StorageEvent) bool {
+	if !policy.Spec.Effectiveness.Enabled || event.Spec.RecommendOnly {
+		return false
+	}
+	return event.Spec.EventType == cnpgv1alpha1.EventTypeExpansion ||
+		event.Spec.EventType == cnpgv1alpha1.EventTypeWALCleanup
+}
+
+// IsEffectivenessPending reports whether an event still waits for its volumes to be
+// measured again
+func IsEffectivenessPending(event *cnpgv1alpha1.StorageEvent) bool {
+	return event.Status.Effectiveness != nil &&
+		event.Status.Effectiveness.Result == cnpgv1alpha1.EffectivenessPending
+}
+
+// RemediatedUsage returns the used and capacity bytes of the volumes an event
+// remediates: those of its expansion target, or for a WAL cleanup the WAL volumes, and
+// the data volumes when the cluster keeps its WAL there
+func RemediatedUsage(m *metrics.ClusterMetrics, event *cnpgv1alpha1.StorageEvent) (usedBytes, capacityBytes int64) {
+	switch {
+	case event.Spec.Tablespace != "":
+		for _, usage := range m.TablespaceUsage() {
+			if usage.Name == event.Spec.Tablespace {
+				return usage.UsedBytes, usage.CapacityBytes
+			}
+		}
+		return 0, 0
+	case event.Spec.EventType == cnpgv1alpha1.EventTypeWALCleanup,
+		event.Spec.Volume == cnpgv1alpha1.VolumeTypeWAL:
+		if usage := m.WALUsage(); usage != nil {
+			return usage.UsedBytes, usage.CapacityBytes
+		}
+		if event.Spec.Volume == cnpgv1alpha1.VolumeTypeWAL {
+			return 0, 0
+		}
+		return m.DataUsage()
+	case event.Spec.Volume == cnpgv1alpha1.VolumeTypeData:
+		return m.DataUsage()
+	default:
+		return m.TotalUsedBytes, m.TotalCapacityBytes
+	}
+}
+
+// StartEffectiveness records the usage of the remediated volumes before the remediation
+// and leaves the measurement pending
+func StartEffectiveness(usedBytes, capacityBytes int64) *cnpgv1alpha1.RemediationEffectiveness {
+	return &cnpgv1alpha1.RemediationEffectiveness{
+		Result:             cnpgv1alpha1.EffectivenessPending,
+		UsagePercentBefore: usagePercent(usedBytes, capacityBytes),
+		UsedBytesBefore:    usedBytes,
+	}
+}
+
+// CompleteEffectiveness records the usage of the remediated volumes after the
+// remediation. It was effective when usage dropped by at least a percentage point
+func CompleteEffectiveness(
+	effectiveness *cnpgv1alpha1.RemediationEffectiveness,
+	usedBytes, capacityBytes int64,
+	now time.Time,
+) {
+	measuredAt := metav1.NewTime(now)
+	effectiveness.MeasuredAt = &measuredAt
+	effectiveness.UsagePercentAfter = usagePercent(usedBytes, capacityBytes)
+	effectiveness.UsedBytesAfter = usedBytes
+	effectiveness.BytesFreed = effectiveness.UsedBytesBefore - usedBytes
+
+	effectiveness.Result = cnpgv1alpha1.EffectivenessEffective
+	if effectiveness.UsagePercentAfter >= effectiveness.UsagePercentBefore {
+		effectiveness.Result = cnpgv1alpha1.EffectivenessIneffective
+	}
+	freed := FormatBytes(effectiveness.BytesFreed) + " freed"
+	if effectiveness.BytesFreed < 0 {
+		freed = FormatBytes(-effectiveness.BytesFreed) + " more used"
+	}
+	effectiveness.Message = fmt.Sprintf("Usage went from %d%% to %d%%, %s",
+		effectiveness.UsagePercentBefore, effectiveness.UsagePercentAfter, freed)
+}
+
+// FailEffectiveness records that the remediated volumes could not be measured again
+func FailEffectiveness(effectiveness *cnpgv1alpha1.RemediationEffectiveness, message string, now time.Time) {
+	measuredAt := metav1.NewTime(now)
+	effectiveness.MeasuredAt = &measuredAt
+	effectiveness.Result = cnpgv1alpha1.EffectivenessUnknown
+	effectiveness.Message = message
+}
+
+// FindLatestMeasuredEvent returns the expansion or WAL cleanup of a cluster that was
+// measured for effectiveness last, or nil if none was
+func FindLatestMeasuredEvent(
+	ctx context.Context,
+	c client.Client,
+	clusterName, clusterNamespace string,
+) (*cnpgv1alpha1.StorageEvent, error) {
+	var latest *cnpgv1alpha1.StorageEvent
+	for _, eventType := range []cnpgv1alpha1.EventType{cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.EventTypeWALCleanup} {
+		events, err := listClusterEvents(ctx, c, clusterName, clusterNamespace, eventType)
+		if err != nil {
+			return nil, err
+		}
+		for i := range events {
+			effectiveness := events[i].Status.Effectiveness
+			if effectiveness == nil || effectiveness.MeasuredAt == nil ||
+				effectiveness.Result == cnpgv1alpha1.EffectivenessUnknown {
+				continue
+			}
+			if latest == nil || effectiveness.MeasuredAt.After(latest.Status.Effectiveness.MeasuredAt.Time) {
+				latest = &events[i]
+			}
+		}
+	}
+	return latest, nil
+}
+
+// usagePercent returns used as a whole percentage of capacity, 0 when capacity is unknown
+func usagePercent(usedBytes, capacityBytes int64) int32 {
+	if capacityBytes <= 0 {
+		return 0
+	}
+	return int32(usedBytes * 100 / capacityBytes)
+}
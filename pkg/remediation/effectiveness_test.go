@@ -0,0 +1,199 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+func TestTracksEffectiveness(t *testing.T) {
+	enabled := &cnpgv1alpha1.StoragePolicy{}
+	enabled.Spec.Effectiveness.Enabled = true
+
+	tests := []struct {
+		name          string
+		policy        *cnpgv1alpha1.StoragePolicy
+		eventType     cnpgv1alpha1.EventType
+		recommendOnly bool
+		expected      bool
+	}{
+		{"disabled", &cnpgv1alpha1.StoragePolicy{}, cnpgv1alpha1.EventTypeExpansion, false, false},
+		{"expansion", enabled, cnpgv1alpha1.EventTypeExpansion, false, true},
+		{"wal cleanup", enabled, cnpgv1alpha1.EventTypeWALCleanup, false, true},
+		{"recommendation", enabled, cnpgv1alpha1.EventTypeExpansion, true, false},
+		{"restore test", enabled, cnpgv1alpha1.EventTypeRestoreTest, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &cnpgv1alpha1.StorageEvent{}
+			event.Spec.EventType = tt.eventType
+			event.Spec.RecommendOnly = tt.recommendOnly
+			if got := TracksEffectiveness(tt.policy, event); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRemediatedUsage(t *testing.T) {
+	m := &metrics.ClusterMetrics{
+		PVCMetrics: []metrics.PVCMetrics{
+			{PVCName: "pg-1", UsedBytes: 80, CapacityBytes: 100},
+			{PVCName: "pg-1-wal", UsedBytes: 30, CapacityBytes: 50, WALVolume: true},
+			{PVCName: "pg-1-tbs-idx", UsedBytes: 10, CapacityBytes: 40, Tablespace: "idx"},
+		},
+		TotalUsedBytes:     120,
+		TotalCapacityBytes: 190,
+	}
+	expansionOf := func(target cnpgv1alpha1.ExpansionTarget) cnpgv1alpha1.StorageEventSpec {
+		return cnpgv1alpha1.StorageEventSpec{EventType: cnpgv1alpha1.EventTypeExpansion, ExpansionTarget: target}
+	}
+	dataOnly := &metrics.ClusterMetrics{
+		PVCMetrics: []metrics.PVCMetrics{{PVCName: "pg-1", UsedBytes: 80, CapacityBytes: 100}},
+	}
+
+	tests := []struct {
+		name             string
+		metrics          *metrics.ClusterMetrics
+		spec             cnpgv1alpha1.StorageEventSpec
+		expectedUsed     int64
+		expectedCapacity int64
+	}{
+		{"whole cluster", m, cnpgv1alpha1.StorageEventSpec{EventType: cnpgv1alpha1.EventTypeExpansion}, 120, 190},
+		{"data volume", m, expansionOf(cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeData}), 80, 100},
+		{"wal volume", m, expansionOf(cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeWAL}), 30, 50},
+		{"tablespace", m, expansionOf(cnpgv1alpha1.ExpansionTarget{Tablespace: "idx"}), 10, 40},
+		{"wal cleanup", m, cnpgv1alpha1.StorageEventSpec{EventType: cnpgv1alpha1.EventTypeWALCleanup}, 30, 50},
+		{"wal cleanup without wal volume", dataOnly,
+			cnpgv1alpha1.StorageEventSpec{EventType: cnpgv1alpha1.EventTypeWALCleanup}, 80, 100},
+		{"missing tablespace", m, expansionOf(cnpgv1alpha1.ExpansionTarget{Tablespace: "archive"}), 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used, capacity := RemediatedUsage(tt.metrics, &cnpgv1alpha1.StorageEvent{Spec: tt.spec})
+			if used != tt.expectedUsed || capacity != tt.expectedCapacity {
+				t.Errorf("expected %d/%d, got %d/%d", tt.expectedUsed, tt.expectedCapacity, used, capacity)
+			}
+		})
+	}
+}
+
+func TestCompleteEffectiveness(t *testing.T) {
+	const gi = int64(1) << 30
+
+	tests := []struct {
+		name            string
+		usedAfter       int64
+		capacityAfter   int64
+		expectedResult  cnpgv1alpha1.EffectivenessResult
+		expectedPercent int32
+		expectedFreed   int64
+		expectedMessage string
+	}{
+		{"expansion lowered usage", 85 * gi, 200 * gi, cnpgv1alpha1.EffectivenessEffective, 42, 0,
+			"Usage went from 85% to 42%, 0 bytes freed"},
+		{"cleanup freed space", 60 * gi, 100 * gi, cnpgv1alpha1.EffectivenessEffective, 60, 25 * gi,
+			"Usage went from 85% to 60%, 25.00Gi freed"},
+		{"usage kept growing", 90 * gi, 100 * gi, cnpgv1alpha1.EffectivenessIneffective, 90, -5 * gi,
+			"Usage went from 85% to 90%, 5.00Gi more used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effectiveness := StartEffectiveness(85*gi, 100*gi)
+			if effectiveness.Result != cnpgv1alpha1.EffectivenessPending || effectiveness.UsagePercentBefore != 85 {
+				t.Fatalf("unexpected start: %+v", effectiveness)
+			}
+			CompleteEffectiveness(effectiveness, tt.usedAfter, tt.capacityAfter, time.Now())
+			if effectiveness.Result != tt.expectedResult {
+				t.Errorf("expected result %s, got %s", tt.expectedResult, effectiveness.Result)
+			}
+			if effectiveness.UsagePercentAfter != tt.expectedPercent {
+				t.Errorf("expected %d%% after, got %d%%", tt.expectedPercent, effectiveness.UsagePercentAfter)
+			}
+			if effectiveness.BytesFreed != tt.expectedFreed {
+				t.Errorf("expected %d bytes freed, got %d", tt.expectedFreed, effectiveness.BytesFreed)
+			}
+			if effectiveness.Message != tt.expectedMessage {
+				t.Errorf("expected message %q, got %q", tt.expectedMessage, effectiveness.Message)
+			}
+			if effectiveness.MeasuredAt == nil {
+				t.Error("expected MeasuredAt to be set")
+			}
+		})
+	}
+}
+
+func TestFindLatestMeasuredEvent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = cnpgv1alpha1.AddToScheme(scheme)
+
+	policy := &cnpgv1alpha1.StoragePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"}}
+	now := time.Now()
+
+	newEvent := func(
+		name string,
+		eventType cnpgv1alpha1.EventType,
+		result cnpgv1alpha1.EffectivenessResult,
+		measuredAgo time.Duration,
+	) *cnpgv1alpha1.StorageEvent {
+		event := NewPendingEvent(policy, "pg", "default", eventType, "test")
+		event.GenerateName = ""
+		event.Name = name
+		measuredAt := metav1.NewTime(now.Add(-measuredAgo))
+		event.Status.Effectiveness = &cnpgv1alpha1.RemediationEffectiveness{Result: result, MeasuredAt: &measuredAt}
+		return event
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newEvent("old-expansion", cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.EffectivenessEffective, time.Hour),
+			newEvent("cleanup", cnpgv1alpha1.EventTypeWALCleanup, cnpgv1alpha1.EffectivenessIneffective, 10*time.Minute),
+			newEvent("unmeasured", cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.EffectivenessUnknown, time.Minute),
+		).
+		Build()
+
+	ctx := context.Background()
+
+	latest, err := FindLatestMeasuredEvent(ctx, c, "pg", "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest == nil || latest.Name != "cleanup" {
+		t.Fatalf("expected the cleanup event, got %v", latest)
+	}
+
+	latest, err = FindLatestMeasuredEvent(ctx, c, "other", "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest != nil {
+		t.Errorf("expected no event for another cluster, got %s", latest.Name)
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/annotations"
+)
+
+const (
+	// LabelCluster is the StorageEvent label holding the CNPG cluster name
+	LabelCluster = "cnpg.supporttools.io/cluster"
+	// LabelEventType is the StorageEvent label holding the event type
+	LabelEventType = "cnpg.supporttools.io/event-type"
+
+	// DefaultMaxEventRetries is the number of attempts before an event is marked Failed
+	DefaultMaxEventRetries = 3
+	// DefaultRemediationDeadline bounds the commands of one remediation attempt on a cluster
+	DefaultRemediationDeadline = 5 * time.Minute
+	// baseRetryBackoff is the delay before the first retry of a failed event
+	baseRetryBackoff = 30 * time.Second
+	// maxRetryBackoff caps the exponential retry delay
+	maxRetryBackoff = 10 * time.Minute
+	// maxRestoreClusterPrefix keeps recovery cluster names within CNPG's 50 character limit
+	maxRestoreClusterPrefix = 33
+)
+
+// NewPendingEvent builds a StorageEvent in the Pending phase. The StorageEvent
+// controller picks it up and executes the requested action, once approved if the
+// policy requires approval for this event type.
+func NewPendingEvent(
+	policy *cnpgv1alpha1.StoragePolicy,
+	clusterName, clusterNamespace string,
+	eventType cnpgv1alpha1.EventType,
+	reason string,
+) *cnpgv1alpha1.StorageEvent {
+	event := &cnpgv1alpha1.StorageEvent{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-%s-", clusterName, eventType),
+			Namespace:    clusterNamespace,
+			Labels: map[string]string{
+				LabelCluster:   clusterName,
+				LabelEventType: string(eventType),
+			},
+		},
+		Spec: cnpgv1alpha1.StorageEventSpec{
+			ClusterRef: cnpgv1alpha1.ClusterReference{
+				Name:      clusterName,
+				Namespace: clusterNamespace,
+			},
+			PolicyRef: cnpgv1alpha1.PolicyReference{
+				Name:      policy.Name,
+				Namespace: policy.Namespace,
+			},
+			EventType:        eventType,
+			Trigger:          cnpgv1alpha1.TriggerTypeThresholdBreach,
+			Reason:           reason,
+			ApprovalRequired: approvalRequired(policy, eventType),
+			RecommendOnly: eventType == cnpgv1alpha1.EventTypeExpansion &&
+				policy.Spec.Expansion.Mode == cnpgv1alpha1.ExpansionModeRecommend,
+		},
+		Status: cnpgv1alpha1.StorageEventStatus{
+			Phase: cnpgv1alpha1.EventPhasePending,
+		},
+	}
+	if eventType == cnpgv1alpha1.EventTypeStorageClassMigration {
+		event.Spec.StorageClassMigration = NewStorageClassMigrationDetails(policy)
+	}
+	return event
+}
+
+// NewVolumeAttributesChangeEvent builds a Pending volume-attributes-change StorageEvent
+// moving the PVCs of a cluster to the class in details
+func NewVolumeAttributesChangeEvent(
+	policy *cnpgv1alpha1.StoragePolicy,
+	clusterName, clusterNamespace string,
+	details *cnpgv1alpha1.VolumeAttributesChangeDetails,
+	reason string,
+) *cnpgv1alpha1.StorageEvent {
+	event := NewPendingEvent(policy, clusterName, clusterNamespace, cnpgv1alpha1.EventTypeVolumeAttributesChange, reason)
+	event.Spec.VolumeAttributesChange = details
+	return event
+}
+
+// NewRestoreTestEvent builds a Pending restore-test StorageEvent for a cluster matched by a
+// BackupPolicy. The recovery cluster is created in the policy's target namespace, which
+// callers must check is set
+func NewRestoreTestEvent(
+	policy *cnpgv1alpha1.BackupPolicy,
+	clusterName, clusterNamespace string,
+) *cnpgv1alpha1.StorageEvent {
+	cfg := policy.Spec.RestoreVerification
+
+	return &cnpgv1alpha1.StorageEvent{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-%s-", clusterName, cnpgv1alpha1.EventTypeRestoreTest),
+			Namespace:    clusterNamespace,
+			Labels: map[string]string{
+				LabelCluster:   clusterName,
+				LabelEventType: string(cnpgv1alpha1.EventTypeRestoreTest),
+			},
+		},
+		Spec: cnpgv1alpha1.StorageEventSpec{
+			ClusterRef: cnpgv1alpha1.ClusterReference{
+				Name:      clusterName,
+				Namespace: clusterNamespace,
+			},
+			PolicyRef: cnpgv1alpha1.PolicyReference{
+				Kind:      cnpgv1alpha1.PolicyKindBackupPolicy,
+				Name:      policy.Name,
+				Namespace: policy.Namespace,
+			},
+			EventType: cnpgv1alpha1.EventTypeRestoreTest,
+			Trigger:   cnpgv1alpha1.TriggerTypeScheduled,
+			Reason:    "Periodic restore verification of the latest backup",
+			RestoreTest: &cnpgv1alpha1.RestoreTestDetails{
+				TargetNamespace: cfg.TargetNamespace,
+				ClusterName:     RestoreClusterName(clusterName, clusterNamespace),
+				SmokeSQL:        cfg.SmokeSQL,
+				TimeoutMinutes:  cfg.TimeoutMinutes,
+			},
+		},
+		Status: cnpgv1alpha1.StorageEventStatus{
+			Phase: cnpgv1alpha1.EventPhasePending,
+		},
+	}
+}
+
+// RestoreClusterName returns the name of the recovery cluster of a restore test. The
+// source namespace is hashed in so clusters of the same name from different namespaces
+// can be tested in one target namespace.
+func RestoreClusterName(clusterName, clusterNamespace string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(clusterNamespace))
+	prefix := clusterName
+	if len(prefix) > maxRestoreClusterPrefix {
+		prefix = prefix[:maxRestoreClusterPrefix]
+	}
+	return fmt.Sprintf("%s-restore-%08x", prefix, h.Sum32())
+}
+
+// approvalRequired returns true if the policy requires manual approval for the event type
+func approvalRequired(policy *cnpgv1alpha1.StoragePolicy, eventType cnpgv1alpha1.EventType) bool {
+	switch eventType {
+	case cnpgv1alpha1.EventTypeExpansion:
+		return policy.Spec.Expansion.ApprovalRequired
+	case cnpgv1alpha1.EventTypeWALCleanup:
+		return policy.Spec.WALCleanup.ApprovalRequired
+	case cnpgv1alpha1.EventTypeStorageClassMigration:
+		return policy.Spec.StorageClassMigration != nil && policy.Spec.StorageClassMigration.ApprovalRequired
+	case cnpgv1alpha1.EventTypeVolumeAttributesChange:
+		return policy.Spec.VolumeAttributes != nil && policy.Spec.VolumeAttributes.ApprovalRequired
+	default:
+		return false
+	}
+}
+
+// IsEventApproved returns true if the event may be executed, either because it
+// does not require approval or because it was approved via spec or annotation.
+func IsEventApproved(event *cnpgv1alpha1.StorageEvent) bool {
+	if !event.Spec.ApprovalRequired || event.Spec.Approved {
+		return true
+	}
+	return event.GetAnnotations()[annotations.AnnotationApproved] == "true"
+}
+
+// IsEventActive returns true if the event has not reached a terminal phase
+func IsEventActive(event *cnpgv1alpha1.StorageEvent) bool {
+	switch event.Status.Phase {
+	case cnpgv1alpha1.EventPhaseCompleted, cnpgv1alpha1.EventPhaseFailed:
+		return false
+	default:
+		return true
+	}
+}
+
+// FindActiveEvent returns the first non-terminal event of the given type for a
+// cluster, or nil if none exists.
+func FindActiveEvent(
+	ctx context.Context,
+	c client.Client,
+	clusterName, clusterNamespace string,
+	eventType cnpgv1alpha1.EventType,
+) (*cnpgv1alpha1.StorageEvent, error) {
+	events, err := listClusterEvents(ctx, c, clusterName, clusterNamespace, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range events {
+		if IsEventActive(&events[i]) {
+			return &events[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindActiveExpansion returns the non-terminal expansion of the given target of a
+// cluster, or nil if none exists. Expansions of different targets, such as two
+// tablespaces or the data and WAL volumes, run independently.
+func FindActiveExpansion(
+	ctx context.Context,
+	c client.Client,
+	clusterName, clusterNamespace string,
+	target cnpgv1alpha1.ExpansionTarget,
+) (*cnpgv1alpha1.StorageEvent, error) {
+	events, err := listClusterEvents(ctx, c, clusterName, clusterNamespace, cnpgv1alpha1.EventTypeExpansion)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range events {
+		if events[i].Spec.ExpansionTarget == target && IsEventActive(&events[i]) {
+			return &events[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindFailedMigration returns a failed storage-class-migration event of a cluster to the
+// given StorageClass, or nil if none exists
+func FindFailedMigration(
+	ctx context.Context,
+	c client.Client,
+	clusterName, clusterNamespace, storageClass string,
+) (*cnpgv1alpha1.StorageEvent, error) {
+	events, err := listClusterEvents(ctx, c, clusterName, clusterNamespace, cnpgv1alpha1.EventTypeStorageClassMigration)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range events {
+		details := events[i].Spec.StorageClassMigration
+		if events[i].Status.Phase == cnpgv1alpha1.EventPhaseFailed && details != nil &&
+			details.StorageClass == storageClass {
+			return &events[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindFailedVolumeAttributesChange returns a failed volume-attributes-change event of a
+// cluster to the given VolumeAttributesClass, or nil if none exists
+func FindFailedVolumeAttributesChange(
+	ctx context.Context,
+	c client.Client,
+	clusterName, clusterNamespace, className string,
+) (*cnpgv1alpha1.StorageEvent, error) {
+	events, err := listClusterEvents(ctx, c, clusterName, clusterNamespace, cnpgv1alpha1.EventTypeVolumeAttributesChange)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range events {
+		details := events[i].Spec.VolumeAttributesChange
+		if events[i].Status.Phase == cnpgv1alpha1.EventPhaseFailed && details != nil &&
+			details.VolumeAttributesClass == className {
+			return &events[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindLatestFinishedEvent returns the expansion or WAL cleanup of a cluster that
+// completed or failed last, or nil if none did
+func FindLatestFinishedEvent(
+	ctx context.Context,
+	c client.Client,
+	clusterName, clusterNamespace string,
+) (*cnpgv1alpha1.StorageEvent, error) {
+	var latest *cnpgv1alpha1.StorageEvent
+	for _, eventType := range []cnpgv1alpha1.EventType{cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.EventTypeWALCleanup} {
+		events, err := listClusterEvents(ctx, c, clusterName, clusterNamespace, eventType)
+		if err != nil {
+			return nil, err
+		}
+		for i := range events {
+			completion := events[i].Status.CompletionTime
+			if IsEventActive(&events[i]) || completion == nil {
+				continue
+			}
+			if latest == nil || completion.After(latest.Status.CompletionTime.Time) {
+				latest = &events[i]
+			}
+		}
+	}
+	return latest, nil
+}
+
+// listClusterEvents lists the events of the given type for a cluster
+func listClusterEvents(
+	ctx context.Context,
+	c client.Client,
+	clusterName, clusterNamespace string,
+	eventType cnpgv1alpha1.EventType,
+) ([]cnpgv1alpha1.StorageEvent, error) {
+	var events cnpgv1alpha1.StorageEventList
+	if err := c.List(ctx, &events,
+		client.InNamespace(clusterNamespace),
+		client.MatchingLabels{
+			LabelCluster:   clusterName,
+			LabelEventType: string(eventType),
+		},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list storage events for cluster %s/%s: %w", clusterNamespace, clusterName, err)
+	}
+	return events.Items, nil
+}
+
+// RetryBackoff returns the delay before the given retry attempt (1-based),
+// doubling from 30s and capped at 10 minutes.
+func RetryBackoff(retryCount int32) time.Duration {
+	if retryCount <= 1 {
+		return baseRetryBackoff
+	}
+	backoff := baseRetryBackoff
+	for i := int32(1); i < retryCount; i++ {
+		backoff *= 2
+		if backoff >= maxRetryBackoff {
+			return maxRetryBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,394 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/annotations"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryCount int32
+		expected   time.Duration
+	}{
+		{"zero uses base", 0, 30 * time.Second},
+		{"first retry", 1, 30 * time.Second},
+		{"second retry doubles", 2, 60 * time.Second},
+		{"third retry", 3, 120 * time.Second},
+		{"capped at max", 10, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetryBackoff(tt.retryCount); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsEventActive(t *testing.T) {
+	tests := []struct {
+		phase    cnpgv1alpha1.EventPhase
+		expected bool
+	}{
+		{"", true},
+		{cnpgv1alpha1.EventPhasePending, true},
+		{cnpgv1alpha1.EventPhaseInProgress, true},
+		{cnpgv1alpha1.EventPhaseCompleted, false},
+		{cnpgv1alpha1.EventPhaseFailed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.phase), func(t *testing.T) {
+			event := &cnpgv1alpha1.StorageEvent{Status: cnpgv1alpha1.StorageEventStatus{Phase: tt.phase}}
+			if got := IsEventActive(event); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindActiveEvent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = cnpgv1alpha1.AddToScheme(scheme)
+
+	policy := &cnpgv1alpha1.StoragePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"}}
+
+	newEvent := func(
+		name string,
+		eventType cnpgv1alpha1.EventType,
+		phase cnpgv1alpha1.EventPhase,
+	) *cnpgv1alpha1.StorageEvent {
+		event := NewPendingEvent(policy, "pg", "default", eventType, "test")
+		event.GenerateName = ""
+		event.Name = name
+		event.Status.Phase = phase
+		return event
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newEvent("done", cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.EventPhaseCompleted),
+			newEvent("running", cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.EventPhaseInProgress),
+			newEvent("failed-wal", cnpgv1alpha1.EventTypeWALCleanup, cnpgv1alpha1.EventPhaseFailed),
+		).
+		Build()
+
+	ctx := context.Background()
+
+	active, err := FindActiveEvent(ctx, c, "pg", "default", cnpgv1alpha1.EventTypeExpansion)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if active == nil || active.Name != "running" {
+		t.Errorf("expected active event 'running', got %v", active)
+	}
+
+	active, err = FindActiveEvent(ctx, c, "pg", "default", cnpgv1alpha1.EventTypeWALCleanup)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if active != nil {
+		t.Errorf("expected no active WAL cleanup event, got %s", active.Name)
+	}
+
+	active, err = FindActiveEvent(ctx, c, "other", "default", cnpgv1alpha1.EventTypeExpansion)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if active != nil {
+		t.Errorf("expected no event for other cluster, got %s", active.Name)
+	}
+}
+
+func TestFindActiveExpansion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = cnpgv1alpha1.AddToScheme(scheme)
+
+	policy := &cnpgv1alpha1.StoragePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"}}
+	newExpansion := func(
+		name string,
+		target cnpgv1alpha1.ExpansionTarget,
+		phase cnpgv1alpha1.EventPhase,
+	) *cnpgv1alpha1.StorageEvent {
+		event := NewPendingEvent(policy, "pg", "default", cnpgv1alpha1.EventTypeExpansion, "test")
+		event.GenerateName = ""
+		event.Name = name
+		event.Spec.ExpansionTarget = target
+		event.Status.Phase = phase
+		return event
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newExpansion("archive-running", cnpgv1alpha1.ExpansionTarget{Tablespace: "archive"},
+				cnpgv1alpha1.EventPhaseInProgress),
+			newExpansion("wal-pending", cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeWAL},
+				cnpgv1alpha1.EventPhasePending),
+			newExpansion("data-done", cnpgv1alpha1.ExpansionTarget{}, cnpgv1alpha1.EventPhaseCompleted),
+		).
+		Build()
+	ctx := context.Background()
+
+	for target, expected := range map[cnpgv1alpha1.ExpansionTarget]string{
+		{Tablespace: "archive"}:               "archive-running",
+		{Volume: cnpgv1alpha1.VolumeTypeWAL}:  "wal-pending",
+		{}:                                    "",
+		{Tablespace: "hot"}:                   "",
+		{Volume: cnpgv1alpha1.VolumeTypeData}: "",
+	} {
+		active, err := FindActiveExpansion(ctx, c, "pg", "default", target)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var name string
+		if active != nil {
+			name = active.Name
+		}
+		if name != expected {
+			t.Errorf("expected active expansion %q for %+v, got %q", expected, target, name)
+		}
+	}
+}
+
+func TestExpansionEngine_ResizePVC(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pvc := createTestPVC("pvc-1", "default", "expandable-sc", "10Gi")
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&pvc).Build()
+	engine := NewExpansionEngine(c)
+	ctx := context.Background()
+
+	updated, err := engine.ResizePVC(ctx, "pvc-1", "default", resource.MustParse("15Gi"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !updated {
+		t.Error("expected PVC to be updated")
+	}
+
+	// Repeating the same resize is a no-op
+	updated, err = engine.ResizePVC(ctx, "pvc-1", "default", resource.MustParse("15Gi"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated {
+		t.Error("expected repeated resize to be a no-op")
+	}
+
+	var got corev1.PersistentVolumeClaim
+	if err := c.Get(ctx, client.ObjectKey{Name: "pvc-1", Namespace: "default"}, &got); err != nil {
+		t.Fatalf("failed to get PVC: %v", err)
+	}
+	request := got.Spec.Resources.Requests[corev1.ResourceStorage]
+	if request.Cmp(resource.MustParse("15Gi")) != 0 {
+		t.Errorf("expected request 15Gi, got %s", request.String())
+	}
+}
+
+func TestExpansionEngine_ResizePVCRetriesConflicts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pvc := createTestPVC("pvc-1", "default", "expandable-sc", "10Gi")
+	conflicts := 0
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&pvc).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, cl client.WithWatch, obj client.Object, patch client.Patch,
+				opts ...client.PatchOption) error {
+				// The CNPG operator updates the PVC between our read and our patch
+				if conflicts < 2 {
+					conflicts++
+					return apierrors.NewConflict(corev1.Resource("persistentvolumeclaims"), obj.GetName(),
+						errors.New("the object has been modified"))
+				}
+				return cl.Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+	engine := NewExpansionEngine(c)
+	before := testutil.ToFloat64(metrics.UpdateConflictsTotal.WithLabelValues("persistentvolumeclaim"))
+
+	updated, err := engine.ResizePVC(context.Background(), "pvc-1", "default", resource.MustParse("15Gi"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !updated {
+		t.Error("expected PVC to be updated after retrying")
+	}
+	after := testutil.ToFloat64(metrics.UpdateConflictsTotal.WithLabelValues("persistentvolumeclaim"))
+	if after-before != 2 {
+		t.Errorf("expected 2 conflicts to be recorded, got %v", after-before)
+	}
+}
+
+func TestIsEventApproved(t *testing.T) {
+	tests := []struct {
+		name             string
+		approvalRequired bool
+		approved         bool
+		annotations      map[string]string
+		expected         bool
+	}{
+		{"approval not required", false, false, nil, true},
+		{"required and pending", true, false, nil, false},
+		{"approved via spec", true, true, nil, true},
+		{"approved via annotation", true, false, map[string]string{annotations.AnnotationApproved: "true"}, true},
+		{"annotation not true", true, false, map[string]string{annotations.AnnotationApproved: "yes"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &cnpgv1alpha1.StorageEvent{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec: cnpgv1alpha1.StorageEventSpec{
+					ApprovalRequired: tt.approvalRequired,
+					Approved:         tt.approved,
+				},
+			}
+			if got := IsEventApproved(event); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewPendingEvent_ApprovalRequired(t *testing.T) {
+	policy := &cnpgv1alpha1.StoragePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+		Spec: cnpgv1alpha1.StoragePolicySpec{
+			Expansion: cnpgv1alpha1.ExpansionConfig{ApprovalRequired: true},
+		},
+	}
+
+	expansion := NewPendingEvent(policy, "pg", "default", cnpgv1alpha1.EventTypeExpansion, "test")
+	if !expansion.Spec.ApprovalRequired {
+		t.Error("expected expansion event to require approval")
+	}
+
+	walCleanup := NewPendingEvent(policy, "pg", "default", cnpgv1alpha1.EventTypeWALCleanup, "test")
+	if walCleanup.Spec.ApprovalRequired {
+		t.Error("expected WAL cleanup event not to require approval")
+	}
+}
+
+func TestNewPendingEvent_StorageClassMigration(t *testing.T) {
+	policy := &cnpgv1alpha1.StoragePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+		Spec: cnpgv1alpha1.StoragePolicySpec{
+			StorageClassMigration: &cnpgv1alpha1.StorageClassMigrationConfig{
+				StorageClass:     "gp3",
+				ApprovalRequired: true,
+			},
+		},
+	}
+
+	event := NewPendingEvent(policy, "pg", "default", cnpgv1alpha1.EventTypeStorageClassMigration, "test")
+	if !event.Spec.ApprovalRequired {
+		t.Error("expected migration event to require approval")
+	}
+	if event.Spec.StorageClassMigration == nil || event.Spec.StorageClassMigration.StorageClass != "gp3" {
+		t.Errorf("expected migration details for gp3, got %+v", event.Spec.StorageClassMigration)
+	}
+
+	expansion := NewPendingEvent(policy, "pg", "default", cnpgv1alpha1.EventTypeExpansion, "test")
+	if expansion.Spec.StorageClassMigration != nil {
+		t.Error("expected no migration details on an expansion event")
+	}
+}
+
+func TestNewVolumeAttributesChangeEvent(t *testing.T) {
+	policy := &cnpgv1alpha1.StoragePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+		Spec: cnpgv1alpha1.StoragePolicySpec{
+			VolumeAttributes: &cnpgv1alpha1.VolumeAttributesConfig{ApprovalRequired: true},
+		},
+	}
+	details := &cnpgv1alpha1.VolumeAttributesChangeDetails{VolumeAttributesClass: "fast", Threshold: "critical"}
+
+	event := NewVolumeAttributesChangeEvent(policy, "pg", "default", details, "test")
+	if event.Spec.EventType != cnpgv1alpha1.EventTypeVolumeAttributesChange {
+		t.Errorf("expected a volume-attributes-change event, got %s", event.Spec.EventType)
+	}
+	if !event.Spec.ApprovalRequired {
+		t.Error("expected the event to require approval")
+	}
+	if event.Spec.VolumeAttributesChange != details {
+		t.Errorf("expected the event to carry its details, got %+v", event.Spec.VolumeAttributesChange)
+	}
+}
+
+func TestNewRestoreTestEvent(t *testing.T) {
+	policy := &cnpgv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "backups", Namespace: "ops"},
+		Spec: cnpgv1alpha1.BackupPolicySpec{
+			RestoreVerification: cnpgv1alpha1.RestoreVerificationConfig{
+				Enabled:         true,
+				TargetNamespace: "restore-tests",
+				SmokeSQL:        "SELECT 1",
+			},
+		},
+	}
+
+	event := NewRestoreTestEvent(policy, "pg", "db")
+	if event.Spec.PolicyRef.Kind != cnpgv1alpha1.PolicyKindBackupPolicy || event.Spec.PolicyRef.Name != "backups" {
+		t.Errorf("expected a BackupPolicy reference, got %+v", event.Spec.PolicyRef)
+	}
+	if event.Labels[LabelEventType] != string(cnpgv1alpha1.EventTypeRestoreTest) {
+		t.Errorf("expected restore-test label, got %v", event.Labels)
+	}
+	details := event.Spec.RestoreTest
+	if details == nil || details.TargetNamespace != "restore-tests" || details.SmokeSQL != "SELECT 1" {
+		t.Fatalf("expected restore test details in the target namespace, got %+v", details)
+	}
+}
+
+func TestRestoreClusterName(t *testing.T) {
+	name := RestoreClusterName("pg", "db")
+	if name != RestoreClusterName("pg", "db") {
+		t.Error("expected a stable name")
+	}
+	if name == RestoreClusterName("pg", "other") {
+		t.Error("expected clusters from different namespaces to get different names")
+	}
+
+	long := RestoreClusterName("a-very-long-cluster-name-that-exceeds-the-limit", "db")
+	if len(long) > 50 {
+		t.Errorf("expected at most 50 characters, got %d (%s)", len(long), long)
+	}
+}
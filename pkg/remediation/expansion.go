@@ -22,12 +22,15 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
 	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
 )
 
@@ -53,6 +56,8 @@ type ExpansionRequest struct {
 	Policy           *cnpgv1alpha1.StoragePolicy
 	Reason           string
 	DryRun           bool
+	// Target selects the PVCs to expand. The zero value expands the data and WAL PVCs
+	Target cnpgv1alpha1.ExpansionTarget
 }
 
 // ExpansionResult contains the result of an expansion operation
@@ -70,50 +75,50 @@ type ExpansionResult struct {
 type PVCExpansionResult struct {
 	PVCName      string
 	Namespace    string
+	Instance     string
 	OriginalSize resource.Quantity
 	NewSize      resource.Quantity
 	BytesAdded   int64
 	Success      bool
 	Error        string
 	Skipped      bool
+	SkipCode     SkipCode
 	SkipReason   string
 }
 
-// ExpandClusterPVCs expands all PVCs for a cluster
+// ExpandClusterPVCs expands the PVCs of a cluster targeted by the request
 func (e *ExpansionEngine) ExpandClusterPVCs(ctx context.Context, req *ExpansionRequest) (*ExpansionResult, error) {
 	logger := log.FromContext(ctx)
 	startTime := time.Now()
 
+	pvcs := requestTargets(req)
 	result := &ExpansionResult{
 		ClusterName:      req.ClusterName,
 		ClusterNamespace: req.ClusterNamespace,
-		PVCResults:       make([]PVCExpansionResult, 0, len(req.PVCs)),
+		PVCResults:       make([]PVCExpansionResult, 0, len(pvcs)),
 	}
 
 	logger.Info("Starting cluster PVC expansion",
 		"cluster", req.ClusterName,
 		"namespace", req.ClusterNamespace,
-		"pvcCount", len(req.PVCs),
+		"tablespace", req.Target.Tablespace,
+		"volume", req.Target.Volume,
+		"pvcCount", len(pvcs),
 		"dryRun", req.DryRun,
 	)
 
-	if len(req.PVCs) == 0 {
+	if len(pvcs) == 0 {
 		result.Success = true
 		result.Duration = time.Since(startTime)
 		return result, nil
 	}
 
-	// Calculate expansion parameters
-	expansionConfig := req.Policy.Spec.Expansion
-	percentage := getExpansionPercentage(expansionConfig.Percentage)
-	minIncrement := getMinIncrementBytes(expansionConfig.MinIncrementGi)
-	maxSize := getMaxSizeBytes(expansionConfig.MaxSize)
-
 	// Process each PVC
 	var successCount, failCount, skipCount int
 
-	for i := range req.PVCs {
-		pvc := &req.PVCs[i]
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		percentage, minIncrement, maxSize := expansionParameters(req.Policy, pvc)
 		pvcResult := e.expandSinglePVC(ctx, pvc, percentage, minIncrement, maxSize, req.DryRun)
 		result.PVCResults = append(result.PVCResults, pvcResult)
 
@@ -132,9 +137,9 @@ func (e *ExpansionEngine) ExpandClusterPVCs(ctx context.Context, req *ExpansionR
 
 	// Record metrics
 	if result.Success {
-		metrics.RecordExpansion(req.ClusterName, req.ClusterNamespace, "success", result.TotalBytesAdded)
+		metrics.RecordExpansion(req.ClusterName, req.ClusterNamespace, "success", result.TotalBytesAdded, "")
 	} else {
-		metrics.RecordExpansion(req.ClusterName, req.ClusterNamespace, "failure", 0)
+		metrics.RecordExpansion(req.ClusterName, req.ClusterNamespace, "failure", 0, "")
 	}
 
 	logger.Info("Completed cluster PVC expansion",
@@ -150,6 +155,142 @@ func (e *ExpansionEngine) ExpandClusterPVCs(ctx context.Context, req *ExpansionR
 	return result, nil
 }
 
+// PlanClusterExpansion computes the target size of every PVC targeted by the request
+// without modifying anything. Skipped and failed preflight results are included so
+// callers can record why a PVC will not be expanded.
+func (e *ExpansionEngine) PlanClusterExpansion(ctx context.Context, req *ExpansionRequest) []PVCExpansionResult {
+	pvcs := requestTargets(req)
+	plan := make([]PVCExpansionResult, 0, len(pvcs))
+	for i := range pvcs {
+		percentage, minIncrement, maxSize := expansionParameters(req.Policy, &pvcs[i])
+		plan = append(plan, e.expandSinglePVC(ctx, &pvcs[i], percentage, minIncrement, maxSize, true))
+	}
+	return plan
+}
+
+// ExpansionTargets returns the PVCs an expansion resizes: those of the target's
+// tablespace, or else its data or WAL PVCs, or both when the target sets neither, or
+// every volume of each instance for VolumeTypeAll. PVCs with the ignore annotation are
+// never resized
+func ExpansionTargets(
+	pvcs []corev1.PersistentVolumeClaim,
+	target cnpgv1alpha1.ExpansionTarget,
+) []corev1.PersistentVolumeClaim {
+	targets := make([]corev1.PersistentVolumeClaim, 0, len(pvcs))
+	for i := range pvcs {
+		if cnpg.IsIgnoredPVC(&pvcs[i]) {
+			continue
+		}
+		if target.Tablespace == "" && target.Volume == cnpgv1alpha1.VolumeTypeAll {
+			targets = append(targets, pvcs[i])
+			continue
+		}
+		if cnpg.PVCTablespace(&pvcs[i]) != target.Tablespace {
+			continue
+		}
+		if target.Tablespace == "" && target.Volume != "" &&
+			cnpg.IsWALPVC(&pvcs[i]) != (target.Volume == cnpgv1alpha1.VolumeTypeWAL) {
+			continue
+		}
+		targets = append(targets, pvcs[i])
+	}
+	return targets
+}
+
+// requestTargets returns the PVCs a request resizes. When every volume of each
+// instance is targeted, the WAL and tablespace volumes whose expansion the policy
+// disables are left out
+func requestTargets(req *ExpansionRequest) []corev1.PersistentVolumeClaim {
+	pvcs := ExpansionTargets(req.PVCs, req.Target)
+	if req.Target.Tablespace != "" || req.Target.Volume != cnpgv1alpha1.VolumeTypeAll {
+		return pvcs
+	}
+	targets := pvcs[:0]
+	for i := range pvcs {
+		tablespace := cnpg.PVCTablespace(&pvcs[i])
+		switch {
+		case tablespace != "" && !IsTablespaceExpansionEnabled(req.Policy, tablespace):
+		case tablespace == "" && cnpg.IsWALPVC(&pvcs[i]) && !IsWALExpansionEnabled(req.Policy):
+		default:
+			targets = append(targets, pvcs[i])
+		}
+	}
+	return targets
+}
+
+// TablespaceExpansion returns the expansion overrides of a tablespace, or nil
+func TablespaceExpansion(
+	policy *cnpgv1alpha1.StoragePolicy,
+	tablespace string,
+) *cnpgv1alpha1.TablespaceExpansionConfig {
+	for i := range policy.Spec.Expansion.Tablespaces {
+		if policy.Spec.Expansion.Tablespaces[i].Name == tablespace {
+			return &policy.Spec.Expansion.Tablespaces[i]
+		}
+	}
+	return nil
+}
+
+// IsTablespaceExpansionEnabled reports whether a tablespace is expanded automatically
+func IsTablespaceExpansionEnabled(policy *cnpgv1alpha1.StoragePolicy, tablespace string) bool {
+	if override := TablespaceExpansion(policy, tablespace); override != nil && override.Enabled != nil {
+		return *override.Enabled
+	}
+	return policy.Spec.Expansion.Enabled
+}
+
+// IsWALExpansionEnabled reports whether the separate WAL volumes are expanded automatically
+func IsWALExpansionEnabled(policy *cnpgv1alpha1.StoragePolicy) bool {
+	if override := policy.Spec.WALExpansion; override != nil && override.Enabled != nil {
+		return *override.Enabled
+	}
+	return policy.Spec.Expansion.Enabled
+}
+
+// volumeExpansion returns the expansion overrides that apply to a PVC, or nil
+func volumeExpansion(
+	policy *cnpgv1alpha1.StoragePolicy,
+	pvc *corev1.PersistentVolumeClaim,
+) *cnpgv1alpha1.VolumeExpansionConfig {
+	if tablespace := cnpg.PVCTablespace(pvc); tablespace != "" {
+		if override := TablespaceExpansion(policy, tablespace); override != nil {
+			return &override.VolumeExpansionConfig
+		}
+		return nil
+	}
+	if cnpg.IsWALPVC(pvc) {
+		return policy.Spec.WALExpansion
+	}
+	return nil
+}
+
+// expansionParameters returns the percentage, minimum increment and maximum size of the
+// expansion of a PVC, applying the overrides of its tablespace or of the WAL volumes
+func expansionParameters(policy *cnpgv1alpha1.StoragePolicy, pvc *corev1.PersistentVolumeClaim) (int32, int64, int64) {
+	config := policy.Spec.Expansion
+	percentage, minIncrementGi, maxSize := config.Percentage, config.MinIncrementGi, config.MaxSize
+	if override := volumeExpansion(policy, pvc); override != nil {
+		if override.Percentage > 0 {
+			percentage = override.Percentage
+		}
+		if override.MinIncrementGi > 0 {
+			minIncrementGi = override.MinIncrementGi
+		}
+		if override.MaxSize != nil {
+			maxSize = override.MaxSize
+		}
+	}
+	return getExpansionPercentage(percentage), getMinIncrementBytes(minIncrementGi), getMaxSizeBytes(maxSize)
+}
+
+// DataExpansionParameters returns the percentage, minimum increment and maximum size
+// of the expansion of a cluster's data volumes, with the defaults of unset ones applied
+func DataExpansionParameters(policy *cnpgv1alpha1.StoragePolicy) (int32, int64, int64) {
+	config := policy.Spec.Expansion
+	return getExpansionPercentage(config.Percentage), getMinIncrementBytes(config.MinIncrementGi),
+		getMaxSizeBytes(config.MaxSize)
+}
+
 // expandSinglePVC expands a single PVC
 func (e *ExpansionEngine) expandSinglePVC(
 	ctx context.Context,
@@ -163,6 +304,7 @@ func (e *ExpansionEngine) expandSinglePVC(
 	result := PVCExpansionResult{
 		PVCName:   pvc.Name,
 		Namespace: pvc.Namespace,
+		Instance:  pvc.Labels[cnpg.LabelInstanceName],
 	}
 
 	// Get current size
@@ -194,7 +336,7 @@ func (e *ExpansionEngine) expandSinglePVC(
 
 	if !preflight.CanExpand {
 		result.Skipped = true
-		result.SkipReason = preflight.Summary()
+		result.SkipCode, result.SkipReason = preflight.SkipReason()
 		return result
 	}
 
@@ -210,7 +352,8 @@ func (e *ExpansionEngine) expandSinglePVC(
 	if maxSize > 0 && newBytes > maxSize {
 		if currentBytes >= maxSize {
 			result.Skipped = true
-			result.SkipReason = fmt.Sprintf("PVC already at max size (%s)", formatBytes(maxSize))
+			result.SkipCode = SkipCodeMaxSize
+			result.SkipReason = fmt.Sprintf("PVC already at max size (%s)", FormatBytes(maxSize))
 			return result
 		}
 		newBytes = maxSize
@@ -226,7 +369,7 @@ func (e *ExpansionEngine) expandSinglePVC(
 		"namespace", pvc.Namespace,
 		"currentSize", currentSize.String(),
 		"newSize", newSize.String(),
-		"increase", formatBytes(increaseBytes),
+		"increase", FormatBytes(increaseBytes),
 		"dryRun", dryRun,
 	)
 
@@ -236,11 +379,8 @@ func (e *ExpansionEngine) expandSinglePVC(
 	}
 
 	// Perform the actual expansion
-	pvcCopy := pvc.DeepCopy()
-	pvcCopy.Spec.Resources.Requests[corev1.ResourceStorage] = *newSize
-
-	if err := e.client.Update(ctx, pvcCopy); err != nil {
-		result.Error = fmt.Sprintf("failed to update PVC: %v", err)
+	if _, err := e.patchStorageRequest(ctx, pvc.DeepCopy(), *newSize); err != nil {
+		result.Error = err.Error()
 		logger.Error(err, "Failed to expand PVC", "pvc", pvc.Name)
 		return result
 	}
@@ -249,6 +389,61 @@ func (e *ExpansionEngine) expandSinglePVC(
 	return result
 }
 
+// ResizePVC sets a PVC's storage request to the target size. It is idempotent: if the
+// PVC already requests at least the target size, no update is made. The returned bool
+// reports whether an update was issued.
+func (e *ExpansionEngine) ResizePVC(
+	ctx context.Context,
+	name, namespace string,
+	target resource.Quantity,
+) (bool, error) {
+	var pvc corev1.PersistentVolumeClaim
+	if err := e.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &pvc); err != nil {
+		return false, fmt.Errorf("failed to get PVC %s/%s: %w", namespace, name, err)
+	}
+
+	return e.patchStorageRequest(ctx, &pvc, target)
+}
+
+// patchStorageRequest raises a PVC's storage request to size with an optimistic-lock
+// merge patch. When another writer such as the CNPG operator updated the PVC first, it
+// is read again and the patch retried. It never shrinks a PVC that already requests at
+// least size, and reports whether a patch was applied
+func (e *ExpansionEngine) patchStorageRequest(
+	ctx context.Context,
+	pvc *corev1.PersistentVolumeClaim,
+	size resource.Quantity,
+) (bool, error) {
+	patched := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if current.Cmp(size) >= 0 {
+			return nil
+		}
+
+		patch := client.MergeFromWithOptions(pvc.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		err := e.client.Patch(ctx, pvc, patch)
+		if !apierrors.IsConflict(err) {
+			patched = err == nil
+			return err
+		}
+
+		metrics.RecordUpdateConflict("persistentvolumeclaim")
+		if getErr := e.client.Get(ctx, client.ObjectKeyFromObject(pvc), pvc); getErr != nil {
+			return getErr
+		}
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update PVC %s/%s: %w", pvc.Namespace, pvc.Name, err)
+	}
+	return patched, nil
+}
+
 // VerifyExpansion verifies that a PVC expansion completed successfully
 func (e *ExpansionEngine) VerifyExpansion(
 	ctx context.Context,
@@ -259,49 +454,26 @@ func (e *ExpansionEngine) VerifyExpansion(
 ) {
 	logger := log.FromContext(ctx)
 
-	result := &VerificationResult{
-		PVCName:      pvc.Name,
-		Namespace:    pvc.Namespace,
-		ExpectedSize: expectedSize,
-	}
-
 	startTime := time.Now()
 	deadline := startTime.Add(timeout)
 
+	var result *VerificationResult
 	for time.Now().Before(deadline) {
-		// Fetch current PVC state
-		var currentPVC corev1.PersistentVolumeClaim
-		if err := e.client.Get(ctx, client.ObjectKeyFromObject(pvc), &currentPVC); err != nil {
-			return nil, fmt.Errorf("failed to get PVC: %w", err)
-		}
-
-		// Check for FileSystemResizePending condition
-		resizePending := false
-		for _, cond := range currentPVC.Status.Conditions {
-			if cond.Type == corev1.PersistentVolumeClaimFileSystemResizePending {
-				resizePending = cond.Status == corev1.ConditionTrue
-				result.FileSystemResizePending = resizePending
-				break
-			}
+		var err error
+		result, err = e.CheckExpansion(ctx, pvc.Name, pvc.Namespace, expectedSize)
+		if err != nil {
+			return nil, err
 		}
 
-		// Get actual capacity
-		if currentPVC.Status.Capacity != nil {
-			actualSize := currentPVC.Status.Capacity[corev1.ResourceStorage]
-			result.ActualSize = actualSize
-
-			// Check if expansion is complete
-			if actualSize.Cmp(expectedSize) >= 0 && !resizePending {
-				result.Complete = true
-				result.Duration = time.Since(startTime)
-				logger.Info("PVC expansion verified",
-					"pvc", pvc.Name,
-					"actualSize", actualSize.String(),
-					"expectedSize", expectedSize.String(),
-					"duration", result.Duration,
-				)
-				return result, nil
-			}
+		if result.Complete {
+			result.Duration = time.Since(startTime)
+			logger.Info("PVC expansion verified",
+				"pvc", pvc.Name,
+				"actualSize", result.ActualSize.String(),
+				"expectedSize", expectedSize.String(),
+				"duration", result.Duration,
+			)
+			return result, nil
 		}
 
 		// Wait before checking again
@@ -312,6 +484,9 @@ func (e *ExpansionEngine) VerifyExpansion(
 		}
 	}
 
+	if result == nil {
+		result = &VerificationResult{PVCName: pvc.Name, Namespace: pvc.Namespace, ExpectedSize: expectedSize}
+	}
 	result.Complete = false
 	result.Duration = time.Since(startTime)
 	result.Error = "timeout waiting for expansion to complete"
@@ -325,6 +500,41 @@ func (e *ExpansionEngine) VerifyExpansion(
 	return result, nil
 }
 
+// CheckExpansion performs a single, non-blocking check of whether a PVC reports
+// at least the expected capacity with no filesystem resize pending.
+func (e *ExpansionEngine) CheckExpansion(
+	ctx context.Context,
+	name, namespace string,
+	expectedSize resource.Quantity,
+) (*VerificationResult, error) {
+	result := &VerificationResult{
+		PVCName:      name,
+		Namespace:    namespace,
+		ExpectedSize: expectedSize,
+	}
+
+	var currentPVC corev1.PersistentVolumeClaim
+	if err := e.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &currentPVC); err != nil {
+		return nil, fmt.Errorf("failed to get PVC: %w", err)
+	}
+
+	// Check for FileSystemResizePending condition
+	for _, cond := range currentPVC.Status.Conditions {
+		if cond.Type == corev1.PersistentVolumeClaimFileSystemResizePending {
+			result.FileSystemResizePending = cond.Status == corev1.ConditionTrue
+			break
+		}
+	}
+
+	// Get actual capacity
+	if currentPVC.Status.Capacity != nil {
+		result.ActualSize = currentPVC.Status.Capacity[corev1.ResourceStorage]
+		result.Complete = result.ActualSize.Cmp(expectedSize) >= 0 && !result.FileSystemResizePending
+	}
+
+	return result, nil
+}
+
 // VerificationResult contains the result of expansion verification
 type VerificationResult struct {
 	PVCName                 string
@@ -373,7 +583,8 @@ func accessModesToStrings(modes []corev1.PersistentVolumeAccessMode) []string {
 	return result
 }
 
-func formatBytes(bytes int64) string {
+// FormatBytes formats a byte count with binary units, e.g. "1.50Gi"
+func FormatBytes(bytes int64) string {
 	const (
 		KB = 1024
 		MB = KB * 1024
@@ -426,8 +637,8 @@ func (e *ExpansionEngine) CreateExpansionEvent(
 			GenerateName: fmt.Sprintf("%s-expansion-", req.ClusterName),
 			Namespace:    req.ClusterNamespace,
 			Labels: map[string]string{
-				"cnpg.supporttools.io/cluster":    req.ClusterName,
-				"cnpg.supporttools.io/event-type": string(cnpgv1alpha1.EventTypeExpansion),
+				LabelCluster:   req.ClusterName,
+				LabelEventType: string(cnpgv1alpha1.EventTypeExpansion),
 			},
 		},
 		Spec: cnpgv1alpha1.StorageEventSpec{
@@ -501,7 +712,7 @@ func (e *ExpansionEngine) UpdateExpansionEventStatus(
 	event.Status.CompletionTime = &now
 	event.Status.PVCStatuses = pvcStatuses
 	event.Status.Message = fmt.Sprintf("Expansion completed: %d PVCs, %s added",
-		len(pvcStatuses), formatBytes(result.TotalBytesAdded))
+		len(pvcStatuses), FormatBytes(result.TotalBytesAdded))
 
 	return e.client.Status().Update(ctx, event)
 }
@@ -53,6 +53,7 @@ func TestExpansionEngine_ExpandClusterPVCs(t *testing.T) {
 		expectedSuccess  bool
 		expectedExpanded int
 		expectedSkipped  int
+		expectedSkipCode SkipCode
 	}{
 		{
 			name: "expand single PVC",
@@ -99,6 +100,7 @@ func TestExpansionEngine_ExpandClusterPVCs(t *testing.T) {
 			expectedSuccess:  true,
 			expectedExpanded: 0,
 			expectedSkipped:  1,
+			expectedSkipCode: SkipCodeMaxSize,
 		},
 		{
 			name:             "no PVCs",
@@ -119,6 +121,7 @@ func TestExpansionEngine_ExpandClusterPVCs(t *testing.T) {
 			expectedSuccess:  true,
 			expectedExpanded: 0,
 			expectedSkipped:  1,
+			expectedSkipCode: SkipCodeUnbound,
 		},
 	}
 
@@ -156,6 +159,9 @@ func TestExpansionEngine_ExpandClusterPVCs(t *testing.T) {
 			for _, pvcResult := range result.PVCResults {
 				if pvcResult.Skipped {
 					skipped++
+					if pvcResult.SkipCode != tt.expectedSkipCode {
+						t.Errorf("expected skip code %q, got %q", tt.expectedSkipCode, pvcResult.SkipCode)
+					}
 				} else if pvcResult.Success {
 					expanded++
 				}
@@ -19,7 +19,13 @@ package remediation
 import (
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/annotations"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
 )
 
 func TestGetExpansionPercentage(t *testing.T) {
@@ -89,6 +95,125 @@ func TestGetMaxSizeBytes(t *testing.T) {
 	}
 }
 
+func TestExpansionTargets(t *testing.T) {
+	pvc := func(name string, labels map[string]string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	ignored := pvc("pg-1-tbs-scratch", map[string]string{
+		cnpg.LabelPVCRole: cnpg.PVCRoleTablespace, cnpg.LabelTablespaceName: "scratch",
+	})
+	ignored.Annotations = map[string]string{annotations.AnnotationIgnore: "true"}
+	ignoredData := pvc("pg-2", map[string]string{cnpg.LabelPVCRole: PVCRoleData})
+	ignoredData.Annotations = map[string]string{annotations.AnnotationIgnore: "true"}
+	tablespace := func(name string) map[string]string {
+		return map[string]string{cnpg.LabelPVCRole: cnpg.PVCRoleTablespace, cnpg.LabelTablespaceName: name}
+	}
+	pvcs := []corev1.PersistentVolumeClaim{
+		pvc("pg-1", map[string]string{cnpg.LabelPVCRole: PVCRoleData}),
+		pvc("pg-1-wal", map[string]string{cnpg.LabelPVCRole: PVCRoleWAL}),
+		pvc("pg-1-tbs-archive", tablespace("archive")),
+		pvc("pg-1-tbs-hot", tablespace("hot")),
+		pvc("unlabeled", nil),
+		ignored,
+		ignoredData,
+	}
+
+	tests := []struct {
+		name     string
+		target   cnpgv1alpha1.ExpansionTarget
+		expected []string
+	}{
+		{"data and WAL", cnpgv1alpha1.ExpansionTarget{}, []string{"pg-1", "pg-1-wal", "unlabeled"}},
+		{"data", cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeData}, []string{"pg-1", "unlabeled"}},
+		{"WAL", cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeWAL}, []string{"pg-1-wal"}},
+		{"all", cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeAll},
+			[]string{"pg-1", "pg-1-wal", "pg-1-tbs-archive", "pg-1-tbs-hot", "unlabeled"}},
+		{"tablespace", cnpgv1alpha1.ExpansionTarget{Tablespace: "archive"}, []string{"pg-1-tbs-archive"}},
+		{"missing tablespace", cnpgv1alpha1.ExpansionTarget{Tablespace: "missing"}, nil},
+		{"ignored tablespace", cnpgv1alpha1.ExpansionTarget{Tablespace: "scratch"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, target := range ExpansionTargets(pvcs, tt.target) {
+				names = append(names, target.Name)
+			}
+			if len(names) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, names)
+			}
+			for i := range names {
+				if names[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, names)
+				}
+			}
+		})
+	}
+}
+
+func TestExpansionParameters(t *testing.T) {
+	disabled := false
+	policy := &cnpgv1alpha1.StoragePolicy{
+		Spec: cnpgv1alpha1.StoragePolicySpec{
+			Expansion: cnpgv1alpha1.ExpansionConfig{
+				Enabled:        true,
+				Percentage:     20,
+				MinIncrementGi: 10,
+				MaxSize:        quantityPtr(resource.MustParse("500Gi")),
+				Tablespaces: []cnpgv1alpha1.TablespaceExpansionConfig{
+					{Name: "archive", VolumeExpansionConfig: cnpgv1alpha1.VolumeExpansionConfig{
+						Percentage: 100, MaxSize: quantityPtr(resource.MustParse("2Ti")),
+					}},
+					{Name: "frozen", VolumeExpansionConfig: cnpgv1alpha1.VolumeExpansionConfig{Enabled: &disabled}},
+				},
+			},
+			WALExpansion: &cnpgv1alpha1.VolumeExpansionConfig{Percentage: 10, MinIncrementGi: 1},
+		},
+	}
+	const gi = int64(1) << 30
+	pvc := func(labels map[string]string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Labels: labels}}
+	}
+	tablespace := func(name string) map[string]string {
+		return map[string]string{cnpg.LabelPVCRole: cnpg.PVCRoleTablespace, cnpg.LabelTablespaceName: name}
+	}
+
+	tests := []struct {
+		name         string
+		pvc          *corev1.PersistentVolumeClaim
+		percentage   int32
+		minIncrement int64
+		maxSize      int64
+	}{
+		{"data", pvc(map[string]string{cnpg.LabelPVCRole: PVCRoleData}), 20, 10 * gi, 500 * gi},
+		{"WAL", pvc(map[string]string{cnpg.LabelPVCRole: cnpg.PVCRoleWAL}), 10, 1 * gi, 500 * gi},
+		{"archive", pvc(tablespace("archive")), 100, 10 * gi, 2048 * gi},
+		{"frozen", pvc(tablespace("frozen")), 20, 10 * gi, 500 * gi},
+		{"other", pvc(tablespace("other")), 20, 10 * gi, 500 * gi},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			percentage, minIncrement, maxSize := expansionParameters(policy, tt.pvc)
+			if percentage != tt.percentage || minIncrement != tt.minIncrement || maxSize != tt.maxSize {
+				t.Errorf("expected %d%%, %d, %d, got %d%%, %d, %d",
+					tt.percentage, tt.minIncrement, tt.maxSize, percentage, minIncrement, maxSize)
+			}
+		})
+	}
+
+	for tablespace, expected := range map[string]bool{"archive": true, "frozen": false, "other": true} {
+		if enabled := IsTablespaceExpansionEnabled(policy, tablespace); enabled != expected {
+			t.Errorf("expected %s enabled %v, got %v", tablespace, expected, enabled)
+		}
+	}
+	if !IsWALExpansionEnabled(policy) {
+		t.Errorf("expected WAL expansion to follow expansion.enabled")
+	}
+	policy.Spec.WALExpansion.Enabled = &disabled
+	if IsWALExpansionEnabled(policy) {
+		t.Errorf("expected walExpansion.enabled to disable WAL expansion")
+	}
+}
+
 func TestAccessModesToStrings(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -126,7 +251,7 @@ func TestFormatBytes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatBytes(tt.bytes)
+			result := FormatBytes(tt.bytes)
 			if result != tt.expected {
 				t.Errorf("expected %s, got %s", tt.expected, result)
 			}
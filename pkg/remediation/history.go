@@ -0,0 +1,131 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// ExpansionHistoryLimit is the number of expansions kept in a cluster's history
+	ExpansionHistoryLimit = 20
+
+	// ExpansionCountWindow is the window of ExpansionHistory.ExpansionsLast30Days
+	ExpansionCountWindow = 30 * 24 * time.Hour
+)
+
+// RecordExpansions returns the history with the completed expansion events it does not
+// hold yet. Events are identified by completion time, so expansions trimmed from the
+// records are not added again while their StorageEvent still exists. Recommend-only,
+// dry-run and failed events did not grow the cluster and are ignored
+func RecordExpansions(
+	previous *cnpgv1alpha1.ExpansionHistory,
+	events []cnpgv1alpha1.StorageEvent,
+	now time.Time,
+) *cnpgv1alpha1.ExpansionHistory {
+	history := &cnpgv1alpha1.ExpansionHistory{}
+	if previous != nil {
+		history = previous.DeepCopy()
+	}
+
+	var newest time.Time
+	recorded := make(map[string]bool, len(history.Records))
+	for _, record := range history.Records {
+		recorded[record.Event] = true
+		if record.Time.After(newest) {
+			newest = record.Time.Time
+		}
+	}
+
+	var added []cnpgv1alpha1.ExpansionRecord
+	for i := range events {
+		record, ok := expansionRecord(&events[i])
+		if !ok || recorded[record.Event] || record.Time.Time.Before(newest) {
+			continue
+		}
+		added = append(added, record)
+	}
+	sortExpansionRecords(added)
+
+	for _, record := range added {
+		history.Records = append(history.Records, record)
+		history.TotalExpansions++
+		history.CumulativeGrowthBytes += record.NewSize.Value() - record.OriginalSize.Value()
+	}
+	if len(history.Records) > ExpansionHistoryLimit {
+		history.Records = history.Records[len(history.Records)-ExpansionHistoryLimit:]
+	}
+	history.ExpansionsLast30Days = ExpansionsSince(history, now.Add(-ExpansionCountWindow))
+	return history
+}
+
+// ExpansionsSince counts the recorded expansions completed at or after since
+func ExpansionsSince(history *cnpgv1alpha1.ExpansionHistory, since time.Time) int32 {
+	if history == nil {
+		return 0
+	}
+	var count int32
+	for _, record := range history.Records {
+		if !record.Time.Time.Before(since) {
+			count++
+		}
+	}
+	return count
+}
+
+// expansionRecord returns the record of a completed expansion event, summing the sizes
+// of the PVCs it resized
+func expansionRecord(event *cnpgv1alpha1.StorageEvent) (cnpgv1alpha1.ExpansionRecord, bool) {
+	if event.Spec.EventType != cnpgv1alpha1.EventTypeExpansion ||
+		event.Status.Phase != cnpgv1alpha1.EventPhaseCompleted ||
+		event.Status.CompletionTime == nil || event.Spec.RecommendOnly || event.Spec.DryRun {
+		return cnpgv1alpha1.ExpansionRecord{}, false
+	}
+
+	var original, resized int64
+	for _, pvc := range event.Status.PVCStatuses {
+		if pvc.Phase != cnpgv1alpha1.PVCPhaseCompleted || pvc.OriginalSize == nil || pvc.NewSize == nil {
+			continue
+		}
+		original += pvc.OriginalSize.Value()
+		resized += pvc.NewSize.Value()
+	}
+	if resized <= original {
+		return cnpgv1alpha1.ExpansionRecord{}, false
+	}
+
+	return cnpgv1alpha1.ExpansionRecord{
+		Event:               event.Name,
+		Time:                metav1.NewTime(event.Status.CompletionTime.Time),
+		OriginalSize:        *resource.NewQuantity(original, resource.BinarySI),
+		NewSize:             *resource.NewQuantity(resized, resource.BinarySI),
+		TriggerUsagePercent: event.Spec.TriggerUsagePercent,
+	}, true
+}
+
+func sortExpansionRecords(records []cnpgv1alpha1.ExpansionRecord) {
+	slices.SortFunc(records, func(a, b cnpgv1alpha1.ExpansionRecord) int {
+		return cmp.Or(a.Time.Time.Compare(b.Time.Time), cmp.Compare(a.Event, b.Event))
+	})
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func completedExpansion(name string, completed time.Time, from, to string) cnpgv1alpha1.StorageEvent {
+	original := resource.MustParse(from)
+	resized := resource.MustParse(to)
+	completion := metav1.NewTime(completed)
+	return cnpgv1alpha1.StorageEvent{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: cnpgv1alpha1.StorageEventSpec{
+			EventType:           cnpgv1alpha1.EventTypeExpansion,
+			TriggerUsagePercent: 85,
+		},
+		Status: cnpgv1alpha1.StorageEventStatus{
+			Phase:          cnpgv1alpha1.EventPhaseCompleted,
+			CompletionTime: &completion,
+			PVCStatuses: []cnpgv1alpha1.PVCStatus{
+				{
+					Name:         name + "-1",
+					Phase:        cnpgv1alpha1.PVCPhaseCompleted,
+					OriginalSize: &original,
+					NewSize:      &resized,
+				},
+			},
+		},
+	}
+}
+
+func TestRecordExpansions(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("records completed expansions in order", func(t *testing.T) {
+		events := []cnpgv1alpha1.StorageEvent{
+			completedExpansion("second", now.Add(-time.Hour), "12Gi", "15Gi"),
+			completedExpansion("first", now.Add(-40*24*time.Hour), "10Gi", "12Gi"),
+		}
+
+		history := RecordExpansions(nil, events, now)
+		if history.TotalExpansions != 2 {
+			t.Fatalf("expected 2 expansions, got %d", history.TotalExpansions)
+		}
+		if history.Records[0].Event != "first" || history.Records[1].Event != "second" {
+			t.Errorf("expected records in completion order, got %s, %s",
+				history.Records[0].Event, history.Records[1].Event)
+		}
+		if want := int64(5 << 30); history.CumulativeGrowthBytes != want {
+			t.Errorf("expected cumulative growth %d, got %d", want, history.CumulativeGrowthBytes)
+		}
+		if history.ExpansionsLast30Days != 1 {
+			t.Errorf("expected 1 expansion in the last 30 days, got %d", history.ExpansionsLast30Days)
+		}
+		if history.Records[1].TriggerUsagePercent != 85 {
+			t.Errorf("expected trigger usage 85, got %d", history.Records[1].TriggerUsagePercent)
+		}
+	})
+
+	t.Run("does not record an event twice", func(t *testing.T) {
+		events := []cnpgv1alpha1.StorageEvent{completedExpansion("first", now.Add(-time.Hour), "10Gi", "12Gi")}
+
+		history := RecordExpansions(RecordExpansions(nil, events, now), events, now)
+		if history.TotalExpansions != 1 || len(history.Records) != 1 {
+			t.Errorf("expected a single expansion, got %d (%d records)", history.TotalExpansions, len(history.Records))
+		}
+	})
+
+	t.Run("does not modify the previous history", func(t *testing.T) {
+		previous := RecordExpansions(nil, []cnpgv1alpha1.StorageEvent{
+			completedExpansion("first", now.Add(-2*time.Hour), "10Gi", "12Gi"),
+		}, now)
+
+		RecordExpansions(previous, []cnpgv1alpha1.StorageEvent{
+			completedExpansion("second", now.Add(-time.Hour), "12Gi", "15Gi"),
+		}, now)
+		if previous.TotalExpansions != 1 || len(previous.Records) != 1 {
+			t.Errorf("expected previous history to be unchanged, got %d expansions", previous.TotalExpansions)
+		}
+	})
+
+	t.Run("ignores events that did not grow the cluster", func(t *testing.T) {
+		recommend := completedExpansion("recommend", now, "10Gi", "12Gi")
+		recommend.Spec.RecommendOnly = true
+		dryRun := completedExpansion("dry-run", now, "10Gi", "12Gi")
+		dryRun.Spec.DryRun = true
+		failed := completedExpansion("failed", now, "10Gi", "12Gi")
+		failed.Status.Phase = cnpgv1alpha1.EventPhaseFailed
+		pvcFailed := completedExpansion("pvc-failed", now, "10Gi", "12Gi")
+		pvcFailed.Status.PVCStatuses[0].Phase = cnpgv1alpha1.PVCPhaseFailed
+		cleanup := completedExpansion("cleanup", now, "10Gi", "12Gi")
+		cleanup.Spec.EventType = cnpgv1alpha1.EventTypeWALCleanup
+
+		history := RecordExpansions(nil, []cnpgv1alpha1.StorageEvent{recommend, dryRun, failed, pvcFailed, cleanup}, now)
+		if history.TotalExpansions != 0 || len(history.Records) != 0 {
+			t.Errorf("expected no expansions, got %d", history.TotalExpansions)
+		}
+	})
+
+	t.Run("keeps the most recent records", func(t *testing.T) {
+		var events []cnpgv1alpha1.StorageEvent
+		for i := range ExpansionHistoryLimit + 5 {
+			completed := now.Add(-time.Duration(ExpansionHistoryLimit+5-i) * time.Hour)
+			events = append(events, completedExpansion(fmt.Sprintf("event-%02d", i), completed, "10Gi", "11Gi"))
+		}
+
+		history := RecordExpansions(nil, events, now)
+		if len(history.Records) != ExpansionHistoryLimit {
+			t.Fatalf("expected %d records, got %d", ExpansionHistoryLimit, len(history.Records))
+		}
+		if history.Records[0].Event != "event-05" {
+			t.Errorf("expected oldest kept record event-05, got %s", history.Records[0].Event)
+		}
+		if history.TotalExpansions != ExpansionHistoryLimit+5 {
+			t.Errorf("expected total %d, got %d", ExpansionHistoryLimit+5, history.TotalExpansions)
+		}
+
+		// Trimmed expansions whose StorageEvents still exist are not added again
+		again := RecordExpansions(history, events, now)
+		if again.TotalExpansions != history.TotalExpansions {
+			t.Errorf("expected trimmed expansions to stay counted once, got %d", again.TotalExpansions)
+		}
+	})
+}
+
+func TestExpansionsSince(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	history := RecordExpansions(nil, []cnpgv1alpha1.StorageEvent{
+		completedExpansion("old", now.Add(-10*24*time.Hour), "10Gi", "12Gi"),
+		completedExpansion("recent", now.Add(-2*24*time.Hour), "12Gi", "14Gi"),
+		completedExpansion("today", now.Add(-time.Hour), "14Gi", "16Gi"),
+	}, now)
+
+	tests := []struct {
+		name     string
+		since    time.Time
+		expected int32
+	}{
+		{"last day", now.Add(-24 * time.Hour), 1},
+		{"last week", now.Add(-7 * 24 * time.Hour), 2},
+		{"last month", now.Add(-30 * 24 * time.Hour), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpansionsSince(history, tt.since); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+
+	if got := ExpansionsSince(nil, now); got != 0 {
+		t.Errorf("expected 0 for nil history, got %d", got)
+	}
+}
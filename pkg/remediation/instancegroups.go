@@ -0,0 +1,111 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HoldBackIncompleteInstances keeps an instance from being expanded partially: when a
+// PVC of an instance fails preflight, the other PVCs of the instance are marked with a
+// preflight error too, so none of them is resized. PVCs already at their maximum size
+// do not hold back the others, as they cannot grow anyway. PVCs without an instance
+// are planned on their own
+func HoldBackIncompleteInstances(plan []PVCExpansionResult) []PVCExpansionResult {
+	blockers := make(map[string]*PVCExpansionResult)
+	for i := range plan {
+		planned := &plan[i]
+		if planned.Instance == "" || blockers[planned.Instance] != nil {
+			continue
+		}
+		if planned.Error != "" || (planned.Skipped && planned.SkipCode != SkipCodeMaxSize) {
+			blockers[planned.Instance] = planned
+		}
+	}
+
+	for i := range plan {
+		planned := &plan[i]
+		blocker := blockers[planned.Instance]
+		if blocker == nil || blocker == planned || planned.Skipped || planned.Error != "" {
+			continue
+		}
+		reason := blocker.Error
+		if reason == "" {
+			reason = blocker.SkipReason
+		}
+		planned.Error = fmt.Sprintf("held back with PVC %s of instance %s: %s", blocker.PVCName, planned.Instance, reason)
+	}
+	return plan
+}
+
+// PVCResize is the planned size of a PVC of an instance
+type PVCResize struct {
+	Name string
+	Size resource.Quantity
+}
+
+// GroupResizeError reports the PVC whose resize failed the resize of its group
+type GroupResizeError struct {
+	PVC string
+	Err error
+}
+
+// Error implements error
+func (e *GroupResizeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the PVC
+func (e *GroupResizeError) Unwrap() error {
+	return e.Err
+}
+
+// ResizePVCGroup resizes the PVCs of an instance together. Every PVC is read before any
+// is patched, so a missing PVC leaves the whole group untouched, and the patches stop
+// at the first failure. It returns whether each applied resize issued an update, in
+// order, so the PVCs past the applied ones were not resized. Errors are a
+// *GroupResizeError naming the PVC that failed
+func (e *ExpansionEngine) ResizePVCGroup(
+	ctx context.Context,
+	namespace string,
+	resizes []PVCResize,
+) ([]bool, error) {
+	pvcs := make([]corev1.PersistentVolumeClaim, len(resizes))
+	for i, resize := range resizes {
+		if err := e.client.Get(ctx, client.ObjectKey{Name: resize.Name, Namespace: namespace}, &pvcs[i]); err != nil {
+			return nil, &GroupResizeError{
+				PVC: resize.Name,
+				Err: fmt.Errorf("failed to get PVC %s/%s: %w", namespace, resize.Name, err),
+			}
+		}
+	}
+
+	updated := make([]bool, 0, len(resizes))
+	for i, resize := range resizes {
+		patched, err := e.patchStorageRequest(ctx, &pvcs[i], resize.Size)
+		if err != nil {
+			return updated, &GroupResizeError{PVC: resize.Name, Err: err}
+		}
+		updated = append(updated, patched)
+	}
+	return updated, nil
+}
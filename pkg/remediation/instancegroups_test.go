@@ -0,0 +1,154 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+func TestHoldBackIncompleteInstances(t *testing.T) {
+	plan := HoldBackIncompleteInstances([]PVCExpansionResult{
+		{PVCName: "pg-1", Instance: "pg-1"},
+		{PVCName: "pg-1-wal", Instance: "pg-1", Skipped: true, SkipCode: SkipCodeNonExpandableClass,
+			SkipReason: "storage class does not allow expansion"},
+		{PVCName: "pg-2", Instance: "pg-2"},
+		{PVCName: "pg-2-wal", Instance: "pg-2", Skipped: true, SkipCode: SkipCodeMaxSize},
+		{PVCName: "pg-3", Instance: "pg-3"},
+		{PVCName: "pg-3-tbs-archive", Instance: "pg-3", Error: "preflight validation error: timeout"},
+		{PVCName: "orphan"},
+	})
+
+	want := map[string]string{
+		"pg-1": "held back with PVC pg-1-wal of instance pg-1: storage class does not allow expansion",
+		"pg-3": "held back with PVC pg-3-tbs-archive of instance pg-3: preflight validation error: timeout",
+	}
+	for _, planned := range plan {
+		if planned.Skipped || planned.PVCName == "pg-3-tbs-archive" {
+			continue
+		}
+		if planned.Error != want[planned.PVCName] {
+			t.Errorf("expected %s to have error %q, got %q", planned.PVCName, want[planned.PVCName], planned.Error)
+		}
+	}
+}
+
+func TestRequestTargets(t *testing.T) {
+	pvcs := []corev1.PersistentVolumeClaim{
+		createTestPVC("pg-1", "db", "sc", "10Gi"),
+		createTestPVC("pg-1-wal", "db", "sc", "10Gi"),
+		createTestPVC("pg-1-tbs-archive", "db", "sc", "10Gi"),
+		createTestPVC("pg-1-tbs-hot", "db", "sc", "10Gi"),
+	}
+	pvcs[1].Labels = map[string]string{cnpg.LabelPVCRole: PVCRoleWAL}
+	pvcs[2].Labels = map[string]string{cnpg.LabelPVCRole: cnpg.PVCRoleTablespace, cnpg.LabelTablespaceName: "archive"}
+	pvcs[3].Labels = map[string]string{cnpg.LabelPVCRole: cnpg.PVCRoleTablespace, cnpg.LabelTablespaceName: "hot"}
+
+	disabled := false
+	policy := &cnpgv1alpha1.StoragePolicy{}
+	policy.Spec.Expansion.Enabled = true
+	policy.Spec.Expansion.Tablespaces = []cnpgv1alpha1.TablespaceExpansionConfig{
+		{Name: "archive", VolumeExpansionConfig: cnpgv1alpha1.VolumeExpansionConfig{Enabled: &disabled}},
+	}
+
+	targets := requestTargets(&ExpansionRequest{
+		PVCs:   pvcs,
+		Policy: policy,
+		Target: cnpgv1alpha1.ExpansionTarget{Volume: cnpgv1alpha1.VolumeTypeAll},
+	})
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	if len(names) != 3 || names[0] != "pg-1" || names[1] != "pg-1-wal" || names[2] != "pg-1-tbs-hot" {
+		t.Errorf("expected the tablespace whose expansion is disabled to be left out, got %v", names)
+	}
+}
+
+func TestExpansionEngine_ResizePVCGroup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	data := createTestPVC("pg-1", "db", "sc", "10Gi")
+	wal := createTestPVC("pg-1-wal", "db", "sc", "2Gi")
+	resizes := []PVCResize{
+		{Name: "pg-1", Size: resource.MustParse("15Gi")},
+		{Name: "pg-1-wal", Size: resource.MustParse("3Gi")},
+	}
+	request := func(c client.Client, name string) string {
+		var pvc corev1.PersistentVolumeClaim
+		if err := c.Get(context.Background(), client.ObjectKey{Name: name, Namespace: "db"}, &pvc); err != nil {
+			t.Fatalf("failed to get PVC: %v", err)
+		}
+		size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		return size.String()
+	}
+
+	t.Run("missing PVC", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(data.DeepCopy()).Build()
+		updated, err := NewExpansionEngine(c).ResizePVCGroup(context.Background(), "db", resizes)
+		var groupErr *GroupResizeError
+		if !errors.As(err, &groupErr) || groupErr.PVC != "pg-1-wal" {
+			t.Fatalf("expected the missing PVC to fail the group, got %v", err)
+		}
+		if len(updated) != 0 || request(c, "pg-1") != "10Gi" {
+			t.Errorf("expected no PVC of the group to be resized, got %v", updated)
+		}
+	})
+
+	t.Run("failed patch", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(data.DeepCopy(), wal.DeepCopy()).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, cl client.WithWatch, obj client.Object, patch client.Patch,
+					opts ...client.PatchOption) error {
+					if obj.GetName() == "pg-1-wal" {
+						return errors.New("exceeded quota")
+					}
+					return cl.Patch(ctx, obj, patch, opts...)
+				},
+			}).Build()
+		updated, err := NewExpansionEngine(c).ResizePVCGroup(context.Background(), "db", resizes)
+		var groupErr *GroupResizeError
+		if !errors.As(err, &groupErr) || groupErr.PVC != "pg-1-wal" {
+			t.Fatalf("expected the WAL PVC to fail the group, got %v", err)
+		}
+		if len(updated) != 1 || !updated[0] {
+			t.Errorf("expected only the data PVC to be resized, got %v", updated)
+		}
+	})
+
+	t.Run("resized", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(data.DeepCopy(), wal.DeepCopy()).Build()
+		updated, err := NewExpansionEngine(c).ResizePVCGroup(context.Background(), "db", resizes)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(updated) != 2 || request(c, "pg-1") != "15Gi" || request(c, "pg-1-wal") != "3Gi" {
+			t.Errorf("expected both PVCs to be resized, got %v", updated)
+		}
+	})
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+const (
+	// DefaultMaxReplicationLagMB is the replication lag that aborts a storage class
+	// migration when the policy does not set one
+	DefaultMaxReplicationLagMB = 1024
+	// DefaultResyncTimeout is how long a recreated instance may take to re-sync when the
+	// policy does not set a timeout
+	DefaultResyncTimeout = 60 * time.Minute
+)
+
+// MigrationActionType is what a storage class migration does next
+type MigrationActionType string
+
+const (
+	// MigrationWait waits for the cluster to become healthy or an instance to re-sync
+	MigrationWait MigrationActionType = "Wait"
+	// MigrationRecreate deletes the PVCs and pod of a replica so CNPG recreates it on the
+	// new StorageClass
+	MigrationRecreate MigrationActionType = "Recreate"
+	// MigrationSwitchover promotes a migrated replica so the primary can be recreated
+	MigrationSwitchover MigrationActionType = "Switchover"
+	// MigrationAbort stops the migration
+	MigrationAbort MigrationActionType = "Abort"
+	// MigrationDone means every instance uses the new StorageClass
+	MigrationDone MigrationActionType = "Done"
+)
+
+// MigrationAction is the next action of a storage class migration
+type MigrationAction struct {
+	Type MigrationActionType
+	// Instance is the instance to recreate or to switch over to
+	Instance string
+	Message  string
+}
+
+// MigrationState is what a storage class migration step observed of the cluster
+type MigrationState struct {
+	Cluster cnpg.ClusterInfo
+	// PVCs are the PVCs of the cluster
+	PVCs []corev1.PersistentVolumeClaim
+	// ReplicationLag is the replay lag in bytes of each streaming replica
+	ReplicationLag map[string]int64
+}
+
+// NewStorageClassMigrationDetails returns the details of a storage-class-migration event
+// created for the policy
+func NewStorageClassMigrationDetails(policy *cnpgv1alpha1.StoragePolicy) *cnpgv1alpha1.StorageClassMigrationDetails {
+	cfg := policy.Spec.StorageClassMigration
+	if cfg == nil {
+		return nil
+	}
+	details := &cnpgv1alpha1.StorageClassMigrationDetails{
+		StorageClass:         cfg.StorageClass,
+		WALStorageClass:      cfg.WALStorageClass,
+		MaxReplicationLagMB:  cfg.MaxReplicationLagMB,
+		ResyncTimeoutMinutes: cfg.ResyncTimeoutMinutes,
+	}
+	if details.WALStorageClass == "" {
+		details.WALStorageClass = details.StorageClass
+	}
+	if details.MaxReplicationLagMB <= 0 {
+		details.MaxReplicationLagMB = DefaultMaxReplicationLagMB
+	}
+	if details.ResyncTimeoutMinutes <= 0 {
+		details.ResyncTimeoutMinutes = int32(DefaultResyncTimeout / time.Minute)
+	}
+	return details
+}
+
+// NeedsStorageClassMigration returns true if the Cluster spec or a data or WAL PVC of
+// the cluster does not use the StorageClass the details move it to
+func NeedsStorageClassMigration(
+	cluster cnpg.ClusterInfo,
+	pvcs []corev1.PersistentVolumeClaim,
+	details *cnpgv1alpha1.StorageClassMigrationDetails,
+) bool {
+	if cluster.Storage.StorageClass != details.StorageClass {
+		return true
+	}
+	if cluster.WALStorage != nil && cluster.WALStorage.StorageClass != details.WALStorageClass {
+		return true
+	}
+	for i := range pvcs {
+		if !pvcMigrated(&pvcs[i], details) {
+			return true
+		}
+	}
+	return false
+}
+
+// pvcMigrated returns true if a data or WAL PVC uses the StorageClass it is moved to.
+// Tablespace PVCs keep their own StorageClass and are always migrated
+func pvcMigrated(pvc *corev1.PersistentVolumeClaim, details *cnpgv1alpha1.StorageClassMigrationDetails) bool {
+	if cnpg.PVCTablespace(pvc) != "" {
+		return true
+	}
+	storageClass := ""
+	if pvc.Spec.StorageClassName != nil {
+		storageClass = *pvc.Spec.StorageClassName
+	}
+	if cnpg.IsWALPVC(pvc) {
+		return storageClass == details.WALStorageClass
+	}
+	return storageClass == details.StorageClass
+}
+
+// PlanMigration decides the next action of a storage class migration. Instances are
+// recreated one at a time, replicas first; the primary is recreated after a switchover to
+// a migrated replica. Nothing is touched while the cluster is unhealthy, and the
+// migration is aborted when a replica lags by more than the allowed replication lag or a
+// recreated instance does not re-sync in time. status records the progress and is
+// updated as instances finish
+func PlanMigration(
+	state MigrationState,
+	details *cnpgv1alpha1.StorageClassMigrationDetails,
+	status *cnpgv1alpha1.StorageClassMigrationStatus,
+	now time.Time,
+) MigrationAction {
+	cluster := state.Cluster
+	instances := migrationInstances(state.PVCs, details)
+	healthy := cluster.Status.Phase == cnpg.ClusterPhaseHealthy &&
+		cluster.Status.ReadyInstances >= cluster.Instances && int32(len(instances)) >= cluster.Instances
+	timeout := time.Duration(details.ResyncTimeoutMinutes) * time.Minute
+	if timeout <= 0 {
+		timeout = DefaultResyncTimeout
+	}
+	timedOut := status.CurrentStartTime != nil && now.Sub(status.CurrentStartTime.Time) > timeout
+
+	if status.CurrentInstance != "" {
+		_, exists := instances[status.CurrentInstance]
+		if exists || !healthy {
+			if timedOut {
+				return MigrationAction{Type: MigrationAbort, Instance: status.CurrentInstance,
+					Message: fmt.Sprintf("instance %s did not re-sync within %s", status.CurrentInstance, timeout)}
+			}
+			return MigrationAction{Type: MigrationWait, Instance: status.CurrentInstance,
+				Message: fmt.Sprintf("waiting for instance %s to be recreated and re-sync", status.CurrentInstance)}
+		}
+		status.MigratedInstances = append(status.MigratedInstances, status.CurrentInstance)
+		status.CurrentInstance = ""
+		status.CurrentStartTime = nil
+	}
+
+	if status.SwitchoverTarget != "" {
+		if cluster.Status.CurrentPrimary != status.SwitchoverTarget || !healthy {
+			if timedOut {
+				return MigrationAction{Type: MigrationAbort, Instance: status.SwitchoverTarget,
+					Message: fmt.Sprintf("switchover to %s did not complete within %s", status.SwitchoverTarget, timeout)}
+			}
+			return MigrationAction{Type: MigrationWait, Instance: status.SwitchoverTarget,
+				Message: fmt.Sprintf("waiting for the switchover to %s", status.SwitchoverTarget)}
+		}
+		status.SwitchoverTarget = ""
+		status.CurrentStartTime = nil
+	}
+
+	var pendingReplicas, migratedReplicas []string
+	primaryPending := false
+	for name, migrated := range instances {
+		switch {
+		case name == cluster.Status.CurrentPrimary:
+			primaryPending = !migrated
+		case migrated:
+			migratedReplicas = append(migratedReplicas, name)
+		default:
+			pendingReplicas = append(pendingReplicas, name)
+		}
+	}
+	if len(pendingReplicas) == 0 && !primaryPending {
+		return MigrationAction{Type: MigrationDone,
+			Message: fmt.Sprintf("%d instances use StorageClass %s", len(instances), details.StorageClass)}
+	}
+	if !healthy {
+		return MigrationAction{Type: MigrationWait, Message: "waiting for the cluster to be healthy"}
+	}
+
+	maxLag := int64(details.MaxReplicationLagMB) * 1024 * 1024
+	if maxLag <= 0 {
+		maxLag = DefaultMaxReplicationLagMB * 1024 * 1024
+	}
+	replicas := append(slices.Clone(pendingReplicas), migratedReplicas...)
+	slices.Sort(replicas)
+	for _, name := range replicas {
+		lag, streaming := state.ReplicationLag[name]
+		if !streaming {
+			return MigrationAction{Type: MigrationWait, Instance: name,
+				Message: fmt.Sprintf("waiting for replica %s to stream from the primary", name)}
+		}
+		if lag > maxLag {
+			return MigrationAction{Type: MigrationAbort, Instance: name,
+				Message: fmt.Sprintf("replica %s lags %s behind the primary, more than the allowed %dMB",
+					name, FormatBytes(lag), details.MaxReplicationLagMB)}
+		}
+	}
+
+	if len(pendingReplicas) > 0 {
+		slices.Sort(pendingReplicas)
+		return MigrationAction{Type: MigrationRecreate, Instance: pendingReplicas[0],
+			Message: fmt.Sprintf("recreating replica %s on StorageClass %s", pendingReplicas[0], details.StorageClass)}
+	}
+	if len(migratedReplicas) == 0 {
+		return MigrationAction{Type: MigrationAbort, Instance: cluster.Status.CurrentPrimary,
+			Message: "a single-instance cluster cannot be migrated without downtime"}
+	}
+	target := migratedReplicas[0]
+	for _, name := range migratedReplicas[1:] {
+		if lag := state.ReplicationLag[name]; lag < state.ReplicationLag[target] ||
+			(lag == state.ReplicationLag[target] && name < target) {
+			target = name
+		}
+	}
+	return MigrationAction{Type: MigrationSwitchover, Instance: target,
+		Message: fmt.Sprintf("switching over from %s to %s", cluster.Status.CurrentPrimary, target)}
+}
+
+// StartMigrationAction records in status that an instance is being recreated or a
+// switchover was requested
+func StartMigrationAction(status *cnpgv1alpha1.StorageClassMigrationStatus, action MigrationAction, now time.Time) {
+	start := metav1.NewTime(now)
+	status.CurrentStartTime = &start
+	switch action.Type {
+	case MigrationRecreate:
+		status.CurrentInstance = action.Instance
+	case MigrationSwitchover:
+		status.SwitchoverTarget = action.Instance
+	}
+}
+
+// migrationInstances returns the instances of a cluster and whether each has its data and
+// WAL PVCs on the new StorageClass. PVCs being deleted belong to a recreated instance
+func migrationInstances(
+	pvcs []corev1.PersistentVolumeClaim,
+	details *cnpgv1alpha1.StorageClassMigrationDetails,
+) map[string]bool {
+	instances := make(map[string]bool)
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		instance := pvc.Labels[cnpg.LabelInstanceName]
+		if instance == "" || pvc.DeletionTimestamp != nil {
+			continue
+		}
+		migrated, seen := instances[instance]
+		instances[instance] = (migrated || !seen) && pvcMigrated(pvc, details)
+	}
+	return instances
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+func migrationPVC(instance, storageClass string, wal bool) corev1.PersistentVolumeClaim {
+	name := instance
+	pvcLabels := map[string]string{cnpg.LabelInstanceName: instance}
+	if wal {
+		name += "-wal"
+		pvcLabels[cnpg.LabelPVCRole] = cnpg.PVCRoleWAL
+	}
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: pvcLabels},
+		Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+	}
+}
+
+func healthyCluster(primary string) cnpg.ClusterInfo {
+	return cnpg.ClusterInfo{
+		Name:      "db",
+		Instances: 3,
+		Storage:   cnpg.StorageInfo{StorageClass: "gp3"},
+		Status: cnpg.ClusterStatus{
+			Phase:          cnpg.ClusterPhaseHealthy,
+			ReadyInstances: 3,
+			CurrentPrimary: primary,
+		},
+	}
+}
+
+func TestNewStorageClassMigrationDetails(t *testing.T) {
+	policy := &cnpgv1alpha1.StoragePolicy{}
+	if NewStorageClassMigrationDetails(policy) != nil {
+		t.Error("expected no details without a migration")
+	}
+
+	policy.Spec.StorageClassMigration = &cnpgv1alpha1.StorageClassMigrationConfig{StorageClass: "gp3"}
+	details := NewStorageClassMigrationDetails(policy)
+	if details.WALStorageClass != "gp3" {
+		t.Errorf("expected the WAL StorageClass to default to gp3, got %q", details.WALStorageClass)
+	}
+	if details.MaxReplicationLagMB != DefaultMaxReplicationLagMB || details.ResyncTimeoutMinutes != 60 {
+		t.Errorf("unexpected defaults %+v", details)
+	}
+}
+
+func TestNeedsStorageClassMigration(t *testing.T) {
+	details := &cnpgv1alpha1.StorageClassMigrationDetails{StorageClass: "gp3", WALStorageClass: "gp3"}
+	tablespace := migrationPVC("db-1", "standard", false)
+	tablespace.Labels[cnpg.LabelPVCRole] = cnpg.PVCRoleTablespace
+	tablespace.Labels[cnpg.LabelTablespaceName] = "archive"
+
+	tests := []struct {
+		name     string
+		cluster  cnpg.ClusterInfo
+		pvcs     []corev1.PersistentVolumeClaim
+		expected bool
+	}{
+		{
+			name:    "migrated",
+			cluster: healthyCluster("db-1"),
+			pvcs:    []corev1.PersistentVolumeClaim{migrationPVC("db-1", "gp3", false), tablespace},
+		},
+		{
+			name:     "spec not updated",
+			cluster:  cnpg.ClusterInfo{Storage: cnpg.StorageInfo{StorageClass: "gp2"}},
+			expected: true,
+		},
+		{
+			name: "WAL spec not updated",
+			cluster: cnpg.ClusterInfo{
+				Storage:    cnpg.StorageInfo{StorageClass: "gp3"},
+				WALStorage: &cnpg.StorageInfo{StorageClass: "gp2"},
+			},
+			expected: true,
+		},
+		{
+			name:     "PVC on the old class",
+			cluster:  healthyCluster("db-1"),
+			pvcs:     []corev1.PersistentVolumeClaim{migrationPVC("db-1", "gp3", false), migrationPVC("db-2", "gp2", true)},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsStorageClassMigration(tt.cluster, tt.pvcs, details); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPlanMigration(t *testing.T) {
+	now := time.Now()
+	details := &cnpgv1alpha1.StorageClassMigrationDetails{
+		StorageClass:         "gp3",
+		WALStorageClass:      "gp3",
+		MaxReplicationLagMB:  16,
+		ResyncTimeoutMinutes: 30,
+	}
+	pvcs := func(classes map[string]string) []corev1.PersistentVolumeClaim {
+		var result []corev1.PersistentVolumeClaim
+		for instance, class := range classes {
+			result = append(result, migrationPVC(instance, class, false))
+		}
+		return result
+	}
+	started := func(ago time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(-ago))
+		return &t
+	}
+	recreating := func(instance string, ago time.Duration) cnpgv1alpha1.StorageClassMigrationStatus {
+		return cnpgv1alpha1.StorageClassMigrationStatus{CurrentInstance: instance, CurrentStartTime: started(ago)}
+	}
+	switchingOver := func(target string, ago time.Duration) cnpgv1alpha1.StorageClassMigrationStatus {
+		return cnpgv1alpha1.StorageClassMigrationStatus{SwitchoverTarget: target, CurrentStartTime: started(ago)}
+	}
+	unhealthy := healthyCluster("db-1")
+	unhealthy.Status.Phase = "Waiting for the instances to become active"
+	unhealthy.Status.ReadyInstances = 2
+	streaming := map[string]int64{"db-2": 0, "db-3": 0}
+
+	tests := []struct {
+		name           string
+		cluster        cnpg.ClusterInfo
+		classes        map[string]string
+		lag            map[string]int64
+		status         cnpgv1alpha1.StorageClassMigrationStatus
+		expectType     MigrationActionType
+		expectInstance string
+		expectMigrated []string
+	}{
+		{
+			name:           "replicas are recreated first",
+			cluster:        healthyCluster("db-1"),
+			classes:        map[string]string{"db-1": "gp2", "db-2": "gp2", "db-3": "gp2"},
+			lag:            streaming,
+			expectType:     MigrationRecreate,
+			expectInstance: "db-2",
+		},
+		{
+			name:       "unhealthy cluster waits",
+			cluster:    unhealthy,
+			classes:    map[string]string{"db-1": "gp2", "db-2": "gp2", "db-3": "gp2"},
+			lag:        streaming,
+			expectType: MigrationWait,
+		},
+		{
+			name:           "lagging replica aborts",
+			cluster:        healthyCluster("db-1"),
+			classes:        map[string]string{"db-1": "gp2", "db-2": "gp2", "db-3": "gp2"},
+			lag:            map[string]int64{"db-2": 0, "db-3": 64 * 1024 * 1024},
+			expectType:     MigrationAbort,
+			expectInstance: "db-3",
+		},
+		{
+			name:           "replica not streaming waits",
+			cluster:        healthyCluster("db-1"),
+			classes:        map[string]string{"db-1": "gp2", "db-2": "gp2", "db-3": "gp2"},
+			lag:            map[string]int64{"db-2": 0},
+			expectType:     MigrationWait,
+			expectInstance: "db-3",
+		},
+		{
+			name:           "recreated instance still syncing",
+			cluster:        unhealthy,
+			classes:        map[string]string{"db-1": "gp2", "db-3": "gp2", "db-4": "gp3"},
+			lag:            map[string]int64{"db-3": 0},
+			status:         recreating("db-2", time.Minute),
+			expectType:     MigrationWait,
+			expectInstance: "db-2",
+		},
+		{
+			name:           "recreated instance times out",
+			cluster:        unhealthy,
+			classes:        map[string]string{"db-1": "gp2", "db-3": "gp2", "db-4": "gp3"},
+			status:         recreating("db-2", time.Hour),
+			expectType:     MigrationAbort,
+			expectInstance: "db-2",
+		},
+		{
+			name:           "re-synced instance is recorded and the next replica recreated",
+			cluster:        healthyCluster("db-1"),
+			classes:        map[string]string{"db-1": "gp2", "db-3": "gp2", "db-4": "gp3"},
+			lag:            map[string]int64{"db-3": 0, "db-4": 0},
+			status:         recreating("db-2", time.Minute),
+			expectType:     MigrationRecreate,
+			expectInstance: "db-3",
+			expectMigrated: []string{"db-2"},
+		},
+		{
+			name:           "primary is switched over to the least lagging migrated replica",
+			cluster:        healthyCluster("db-1"),
+			classes:        map[string]string{"db-1": "gp2", "db-4": "gp3", "db-5": "gp3"},
+			lag:            map[string]int64{"db-4": 4096, "db-5": 0},
+			expectType:     MigrationSwitchover,
+			expectInstance: "db-5",
+		},
+		{
+			name:           "switchover in progress",
+			cluster:        healthyCluster("db-1"),
+			classes:        map[string]string{"db-1": "gp2", "db-4": "gp3", "db-5": "gp3"},
+			lag:            map[string]int64{"db-4": 0, "db-5": 0},
+			status:         switchingOver("db-5", time.Minute),
+			expectType:     MigrationWait,
+			expectInstance: "db-5",
+		},
+		{
+			name:           "old primary is recreated after the switchover",
+			cluster:        healthyCluster("db-5"),
+			classes:        map[string]string{"db-1": "gp2", "db-4": "gp3", "db-5": "gp3"},
+			lag:            map[string]int64{"db-1": 0, "db-4": 0},
+			status:         switchingOver("db-5", time.Minute),
+			expectType:     MigrationRecreate,
+			expectInstance: "db-1",
+		},
+		{
+			name: "single instance aborts",
+			cluster: cnpg.ClusterInfo{
+				Instances: 1,
+				Status:    cnpg.ClusterStatus{Phase: cnpg.ClusterPhaseHealthy, ReadyInstances: 1, CurrentPrimary: "db-1"},
+			},
+			classes:        map[string]string{"db-1": "gp2"},
+			expectType:     MigrationAbort,
+			expectInstance: "db-1",
+		},
+		{
+			name:       "every instance migrated",
+			cluster:    healthyCluster("db-5"),
+			classes:    map[string]string{"db-4": "gp3", "db-5": "gp3", "db-6": "gp3"},
+			expectType: MigrationDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			state := MigrationState{Cluster: tt.cluster, PVCs: pvcs(tt.classes), ReplicationLag: tt.lag}
+			action := PlanMigration(state, details, &status, now)
+			if action.Type != tt.expectType || action.Instance != tt.expectInstance {
+				t.Fatalf("expected %s %q, got %s %q (%s)", tt.expectType, tt.expectInstance,
+					action.Type, action.Instance, action.Message)
+			}
+			if len(status.MigratedInstances) != len(tt.expectMigrated) {
+				t.Errorf("expected migrated instances %v, got %v", tt.expectMigrated, status.MigratedInstances)
+			}
+		})
+	}
+}
+
+func TestStartMigrationAction(t *testing.T) {
+	now := time.Now()
+	var status cnpgv1alpha1.StorageClassMigrationStatus
+
+	StartMigrationAction(&status, MigrationAction{Type: MigrationRecreate, Instance: "db-2"}, now)
+	if status.CurrentInstance != "db-2" || status.CurrentStartTime == nil {
+		t.Errorf("expected db-2 being recreated, got %+v", status)
+	}
+
+	status = cnpgv1alpha1.StorageClassMigrationStatus{}
+	StartMigrationAction(&status, MigrationAction{Type: MigrationSwitchover, Instance: "db-3"}, now)
+	if status.SwitchoverTarget != "db-3" || status.CurrentInstance != "" {
+		t.Errorf("expected a switchover to db-3, got %+v", status)
+	}
+}
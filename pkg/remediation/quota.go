@@ -0,0 +1,231 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// KindResourceQuota is the QuotaExceeded kind of a ResourceQuota limit
+	KindResourceQuota = "ResourceQuota"
+	// KindLimitRange is the QuotaExceeded kind of a LimitRange maximum
+	KindLimitRange = "LimitRange"
+
+	// storageClassRequestsStorage is the suffix of the per storage class quota on PVC requests
+	storageClassRequestsStorage = ".storageclass.storage.k8s.io/requests.storage"
+)
+
+// QuotaExceeded reports that an expansion would exceed a ResourceQuota or LimitRange of
+// the namespace, so the API server would reject the resize. It is returned as an error
+// so callers can tell it apart with errors.As
+type QuotaExceeded struct {
+	// Kind is KindResourceQuota or KindLimitRange
+	Kind      string
+	Name      string
+	Namespace string
+	// Resource is the quota resource or limit that is exceeded
+	Resource corev1.ResourceName
+	PVCs     []string
+	// RequiredBytes is the storage the expansion adds for a ResourceQuota, and the new
+	// size of the PVC for a LimitRange
+	RequiredBytes int64
+	// UsedBytes is the storage already counted by a ResourceQuota
+	UsedBytes int64
+	// LimitBytes is the hard limit of a ResourceQuota or the maximum of a LimitRange
+	LimitBytes int64
+}
+
+// SuggestedLimit returns the smallest limit that lets the expansion through, rounded up
+// to a whole GiB
+func (q *QuotaExceeded) SuggestedLimit() int64 {
+	const gi = 1024 * 1024 * 1024
+	return (q.UsedBytes + q.RequiredBytes + gi - 1) / gi * gi
+}
+
+func (q *QuotaExceeded) Error() string {
+	if q.Kind == KindLimitRange {
+		return fmt.Sprintf("LimitRange %s/%s allows PVCs of at most %s, %s would grow to %s (raise its max to at least %s)",
+			q.Namespace, q.Name, FormatBytes(q.LimitBytes), q.PVCs[0], FormatBytes(q.RequiredBytes),
+			FormatBytes(q.SuggestedLimit()))
+	}
+	return fmt.Sprintf("ResourceQuota %s/%s exceeded: %s has %s of %s used, the expansion needs %s more "+
+		"(raise it to at least %s)", q.Namespace, q.Name, q.Resource, FormatBytes(q.UsedBytes),
+		FormatBytes(q.LimitBytes), FormatBytes(q.RequiredBytes), FormatBytes(q.SuggestedLimit()))
+}
+
+// QuotaChecker compares planned expansions with the ResourceQuotas and LimitRanges of
+// the cluster's namespace
+type QuotaChecker struct {
+	client client.Reader
+}
+
+// NewQuotaChecker creates a new quota checker
+func NewQuotaChecker(c client.Reader) *QuotaChecker {
+	return &QuotaChecker{client: c}
+}
+
+// CheckExpansion returns the exceeded quota or limit when the namespace cannot hold the
+// planned PVC growth
+func (c *QuotaChecker) CheckExpansion(
+	ctx context.Context,
+	namespace string,
+	pvcs []corev1.PersistentVolumeClaim,
+	plan []PVCExpansionResult,
+) (*QuotaExceeded, error) {
+	var quotas corev1.ResourceQuotaList
+	if err := c.client.List(ctx, &quotas, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list ResourceQuotas: %w", err)
+	}
+	var limitRanges corev1.LimitRangeList
+	if err := c.client.List(ctx, &limitRanges, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list LimitRanges: %w", err)
+	}
+	if len(quotas.Items) == 0 && len(limitRanges.Items) == 0 {
+		return nil, nil
+	}
+
+	byName := make(map[string]*corev1.PersistentVolumeClaim, len(pvcs))
+	for i := range pvcs {
+		byName[pvcs[i].Name] = &pvcs[i]
+	}
+	var volumes []CapacityVolume
+	for _, planned := range plan {
+		pvc := byName[planned.PVCName]
+		if pvc == nil || planned.Skipped || planned.Error != "" || planned.BytesAdded <= 0 {
+			continue
+		}
+		volume := CapacityVolume{PVC: pvc.Name, NewSize: planned.NewSize, BytesAdded: planned.BytesAdded}
+		if pvc.Spec.StorageClassName != nil {
+			volume.StorageClass = *pvc.Spec.StorageClassName
+		}
+		volumes = append(volumes, volume)
+	}
+	return FindQuotaExceeded(volumes, quotas.Items, limitRanges.Items), nil
+}
+
+// FindQuotaExceeded returns the first LimitRange maximum a volume would outgrow, or else
+// the first ResourceQuota on PVC storage requests, overall or for a storage class, that
+// cannot hold the combined growth of the volumes
+func FindQuotaExceeded(
+	volumes []CapacityVolume,
+	quotas []corev1.ResourceQuota,
+	limitRanges []corev1.LimitRange,
+) *QuotaExceeded {
+	for _, limitRange := range limitRanges {
+		for _, item := range limitRange.Spec.Limits {
+			maximum, ok := item.Max[corev1.ResourceStorage]
+			if item.Type != corev1.LimitTypePersistentVolumeClaim || !ok {
+				continue
+			}
+			for _, volume := range volumes {
+				if volume.NewSize.Cmp(maximum) > 0 {
+					return &QuotaExceeded{
+						Kind:          KindLimitRange,
+						Name:          limitRange.Name,
+						Namespace:     limitRange.Namespace,
+						Resource:      corev1.ResourceStorage,
+						PVCs:          []string{volume.PVC},
+						RequiredBytes: volume.NewSize.Value(),
+						LimitBytes:    maximum.Value(),
+					}
+				}
+			}
+		}
+	}
+
+	sorted := make([]corev1.ResourceQuota, len(quotas))
+	copy(sorted, quotas)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for i := range sorted {
+		quota := &sorted[i]
+		// Scoped quotas only count the PVCs of a volume attributes class, which this does not resolve
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		hard := quota.Status.Hard
+		if len(hard) == 0 {
+			hard = quota.Spec.Hard
+		}
+		resources := make([]corev1.ResourceName, 0, len(hard))
+		for resourceName := range hard {
+			resources = append(resources, resourceName)
+		}
+		sort.Slice(resources, func(i, j int) bool { return resources[i] < resources[j] })
+
+		for _, resourceName := range resources {
+			exceeded := &QuotaExceeded{
+				Kind:      KindResourceQuota,
+				Name:      quota.Name,
+				Namespace: quota.Namespace,
+				Resource:  resourceName,
+			}
+			for _, volume := range volumes {
+				if quotaCounts(resourceName, volume) {
+					exceeded.PVCs = append(exceeded.PVCs, volume.PVC)
+					exceeded.RequiredBytes += volume.BytesAdded
+				}
+			}
+			if len(exceeded.PVCs) == 0 {
+				continue
+			}
+			limit := hard[resourceName]
+			used := quota.Status.Used[resourceName]
+			exceeded.LimitBytes = limit.Value()
+			exceeded.UsedBytes = used.Value()
+			if exceeded.UsedBytes+exceeded.RequiredBytes > exceeded.LimitBytes {
+				sort.Strings(exceeded.PVCs)
+				return exceeded
+			}
+		}
+	}
+	return nil
+}
+
+// quotaCounts returns true if a quota resource counts the storage requests of a volume
+func quotaCounts(resourceName corev1.ResourceName, volume CapacityVolume) bool {
+	if resourceName == corev1.ResourceRequestsStorage {
+		return true
+	}
+	return volume.StorageClass != "" &&
+		resourceName == corev1.ResourceName(volume.StorageClass+storageClassRequestsStorage)
+}
+
+// StorageHeadroom returns the bytes a quota leaves for PVC storage requests, overall and
+// per storage class, keyed by quota resource
+func StorageHeadroom(quota *corev1.ResourceQuota) map[corev1.ResourceName]int64 {
+	hard := quota.Status.Hard
+	if len(hard) == 0 {
+		hard = quota.Spec.Hard
+	}
+	headroom := map[corev1.ResourceName]int64{}
+	for resourceName, limit := range hard {
+		if resourceName != corev1.ResourceRequestsStorage &&
+			!strings.HasSuffix(string(resourceName), storageClassRequestsStorage) {
+			continue
+		}
+		used := quota.Status.Used[resourceName]
+		headroom[resourceName] = limit.Value() - used.Value()
+	}
+	return headroom
+}
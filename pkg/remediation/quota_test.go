@@ -0,0 +1,245 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func storageQuota(name string, hard, used map[corev1.ResourceName]string) corev1.ResourceQuota {
+	quota := corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{}},
+		Status:     corev1.ResourceQuotaStatus{Hard: corev1.ResourceList{}, Used: corev1.ResourceList{}},
+	}
+	for resourceName, value := range hard {
+		quota.Spec.Hard[resourceName] = resource.MustParse(value)
+		quota.Status.Hard[resourceName] = resource.MustParse(value)
+	}
+	for resourceName, value := range used {
+		quota.Status.Used[resourceName] = resource.MustParse(value)
+	}
+	return quota
+}
+
+func pvcLimitRange(name, maximum string) corev1.LimitRange {
+	return corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db"},
+		Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+			{Type: corev1.LimitTypeContainer, Max: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}},
+			{Type: corev1.LimitTypePersistentVolumeClaim, Max: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse(maximum),
+			}},
+		}},
+	}
+}
+
+func TestFindQuotaExceeded(t *testing.T) {
+	volume := func(pvc, class, added, newSize string) CapacityVolume {
+		return CapacityVolume{
+			PVC:          pvc,
+			StorageClass: class,
+			NewSize:      resource.MustParse(newSize),
+			BytesAdded:   quantityBytes(added),
+		}
+	}
+	fastRequests := corev1.ResourceName("fast" + storageClassRequestsStorage)
+	scoped := storageQuota("scoped", map[corev1.ResourceName]string{corev1.ResourceRequestsStorage: "1Gi"}, nil)
+	scoped.Spec.ScopeSelector = &corev1.ScopeSelector{}
+
+	tests := []struct {
+		name           string
+		volumes        []CapacityVolume
+		quotas         []corev1.ResourceQuota
+		limitRanges    []corev1.LimitRange
+		expectKind     string
+		expectResource corev1.ResourceName
+		expectPVCs     []string
+		expectSuggest  string
+	}{
+		{
+			name:    "quota with room",
+			volumes: []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi")},
+			quotas: []corev1.ResourceQuota{storageQuota("storage",
+				map[corev1.ResourceName]string{corev1.ResourceRequestsStorage: "200Gi"},
+				map[corev1.ResourceName]string{corev1.ResourceRequestsStorage: "100Gi"})},
+		},
+		{
+			name: "combined growth exceeds requests.storage",
+			volumes: []CapacityVolume{
+				volume("db-2", "fast", "10Gi", "60Gi"),
+				volume("db-1", "fast", "10Gi", "60Gi"),
+			},
+			quotas: []corev1.ResourceQuota{storageQuota("storage",
+				map[corev1.ResourceName]string{corev1.ResourceRequestsStorage: "110Gi"},
+				map[corev1.ResourceName]string{corev1.ResourceRequestsStorage: "100Gi"})},
+			expectKind:     KindResourceQuota,
+			expectResource: corev1.ResourceRequestsStorage,
+			expectPVCs:     []string{"db-1", "db-2"},
+			expectSuggest:  "120Gi",
+		},
+		{
+			name:    "storage class quota",
+			volumes: []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi")},
+			quotas: []corev1.ResourceQuota{storageQuota("classes",
+				map[corev1.ResourceName]string{fastRequests: "55Gi"},
+				map[corev1.ResourceName]string{fastRequests: "50Gi"})},
+			expectKind:     KindResourceQuota,
+			expectResource: fastRequests,
+			expectPVCs:     []string{"db-1"},
+			expectSuggest:  "60Gi",
+		},
+		{
+			name:    "quota of another storage class is ignored",
+			volumes: []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi")},
+			quotas: []corev1.ResourceQuota{storageQuota("classes",
+				map[corev1.ResourceName]string{"slow" + storageClassRequestsStorage: "1Gi"},
+				map[corev1.ResourceName]string{"slow" + storageClassRequestsStorage: "1Gi"})},
+		},
+		{
+			name:    "scoped quota is ignored",
+			volumes: []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi")},
+			quotas:  []corev1.ResourceQuota{scoped},
+		},
+		{
+			name:           "new size above the LimitRange maximum",
+			volumes:        []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi")},
+			limitRanges:    []corev1.LimitRange{pvcLimitRange("limits", "50Gi")},
+			expectKind:     KindLimitRange,
+			expectResource: corev1.ResourceStorage,
+			expectPVCs:     []string{"db-1"},
+			expectSuggest:  "60Gi",
+		},
+		{
+			name:        "new size within the LimitRange maximum",
+			volumes:     []CapacityVolume{volume("db-1", "fast", "10Gi", "60Gi")},
+			limitRanges: []corev1.LimitRange{pvcLimitRange("limits", "100Gi")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exceeded := FindQuotaExceeded(tt.volumes, tt.quotas, tt.limitRanges)
+			if tt.expectKind == "" {
+				if exceeded != nil {
+					t.Fatalf("expected no exceeded quota, got %v", exceeded)
+				}
+				return
+			}
+			if exceeded == nil {
+				t.Fatal("expected an exceeded quota")
+			}
+			if exceeded.Kind != tt.expectKind || exceeded.Resource != tt.expectResource {
+				t.Errorf("expected %s %s, got %s %s", tt.expectKind, tt.expectResource, exceeded.Kind, exceeded.Resource)
+			}
+			if strings.Join(exceeded.PVCs, ",") != strings.Join(tt.expectPVCs, ",") {
+				t.Errorf("expected PVCs %v, got %v", tt.expectPVCs, exceeded.PVCs)
+			}
+			if exceeded.SuggestedLimit() != quantityBytes(tt.expectSuggest) {
+				t.Errorf("expected a suggested limit of %s, got %s", tt.expectSuggest, FormatBytes(exceeded.SuggestedLimit()))
+			}
+			if !strings.Contains(exceeded.Error(), "at least "+FormatBytes(exceeded.SuggestedLimit())) {
+				t.Errorf("expected the suggested limit in %q", exceeded.Error())
+			}
+		})
+	}
+}
+
+func TestQuotaChecker_CheckExpansion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pvc := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "db-1", Namespace: "db"}}
+	plan := []PVCExpansionResult{
+		{PVCName: "db-1", Namespace: "db", NewSize: resource.MustParse("60Gi"), BytesAdded: quantityBytes("10Gi")},
+		{PVCName: "db-2", Namespace: "db", Skipped: true},
+	}
+	full := storageQuota("storage",
+		map[corev1.ResourceName]string{corev1.ResourceRequestsStorage: "100Gi"},
+		map[corev1.ResourceName]string{corev1.ResourceRequestsStorage: "95Gi"})
+	otherNamespace := full.DeepCopy()
+	otherNamespace.Namespace = "other"
+
+	tests := []struct {
+		name           string
+		objects        []corev1.ResourceQuota
+		expectExceeded bool
+	}{
+		{name: "no quotas"},
+		{name: "quota of another namespace", objects: []corev1.ResourceQuota{*otherNamespace}},
+		{name: "quota is full", objects: []corev1.ResourceQuota{full}, expectExceeded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			for i := range tt.objects {
+				builder = builder.WithObjects(&tt.objects[i])
+			}
+			checker := NewQuotaChecker(builder.Build())
+
+			exceeded, err := checker.CheckExpansion(context.Background(), "db", []corev1.PersistentVolumeClaim{pvc}, plan)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (exceeded != nil) != tt.expectExceeded {
+				t.Errorf("expected exceeded %v, got %v", tt.expectExceeded, exceeded)
+			}
+		})
+	}
+}
+
+func TestStorageHeadroom(t *testing.T) {
+	fastRequests := corev1.ResourceName("fast" + storageClassRequestsStorage)
+	quota := storageQuota("storage",
+		map[corev1.ResourceName]string{
+			corev1.ResourceRequestsStorage: "200Gi",
+			fastRequests:                   "100Gi",
+			corev1.ResourcePods:            "10",
+		},
+		map[corev1.ResourceName]string{corev1.ResourceRequestsStorage: "150Gi", corev1.ResourcePods: "3"})
+
+	headroom := StorageHeadroom(&quota)
+	if len(headroom) != 2 {
+		t.Fatalf("expected the headroom of the two storage resources, got %v", headroom)
+	}
+	if headroom[corev1.ResourceRequestsStorage] != quantityBytes("50Gi") {
+		t.Errorf("expected 50Gi left of requests.storage, got %s", FormatBytes(headroom[corev1.ResourceRequestsStorage]))
+	}
+	if headroom[fastRequests] != quantityBytes("100Gi") {
+		t.Errorf("expected 100Gi left of %s, got %s", fastRequests, FormatBytes(headroom[fastRequests]))
+	}
+}
+
+func TestQuotaExceeded_SuggestedLimit(t *testing.T) {
+	exceeded := &QuotaExceeded{
+		Kind:          KindResourceQuota,
+		UsedBytes:     quantityBytes("100Gi"),
+		RequiredBytes: quantityBytes("10Gi") + 1,
+	}
+	if got := exceeded.SuggestedLimit(); got != quantityBytes("111Gi") {
+		t.Errorf("expected the suggested limit rounded up to 111Gi, got %d", got)
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// LabelPVCRole is the CNPG label identifying what a PVC stores
+	LabelPVCRole = "cnpg.io/pvcRole"
+	// PVCRoleData is the role of the PGDATA volume (spec.storage)
+	PVCRoleData = "PG_DATA"
+	// PVCRoleWAL is the role of the dedicated WAL volume (spec.walStorage)
+	PVCRoleWAL = "PG_WAL"
+
+	// recommendationConfigMapSuffix is appended to the policy name for the default ConfigMap
+	recommendationConfigMapSuffix = "-recommendations"
+)
+
+// Recommendation is the structured output of an expansion in recommend mode. It
+// is meant to be consumed by GitOps tooling that updates the Cluster manifest.
+type Recommendation struct {
+	ClusterName      string    `json:"clusterName"`
+	ClusterNamespace string    `json:"clusterNamespace"`
+	Policy           string    `json:"policy"`
+	Event            string    `json:"event"`
+	Reason           string    `json:"reason,omitempty"`
+	GeneratedAt      time.Time `json:"generatedAt"`
+
+	// StorageSize is the recommended value for the Cluster's spec.storage.size
+	StorageSize string `json:"storageSize,omitempty"`
+	// WALStorageSize is the recommended value for the Cluster's spec.walStorage.size
+	WALStorageSize string `json:"walStorageSize,omitempty"`
+
+	PVCs []PVCRecommendation `json:"pvcs"`
+}
+
+// PVCRecommendation is the recommended size for a single PVC
+type PVCRecommendation struct {
+	Name            string `json:"name"`
+	Role            string `json:"role,omitempty"`
+	CurrentSize     string `json:"currentSize"`
+	RecommendedSize string `json:"recommendedSize"`
+}
+
+// PVCRole returns the CNPG role of a PVC, defaulting to PG_DATA for unlabeled PVCs
+func PVCRole(pvc *corev1.PersistentVolumeClaim) string {
+	if role := pvc.Labels[LabelPVCRole]; role != "" {
+		return role
+	}
+	return PVCRoleData
+}
+
+// BuildRecommendation converts the planned PVC sizes of an expansion event into a
+// Recommendation. Because CNPG sizes all instances from one template, the
+// cluster-level size for each role is the largest planned size for that role.
+func BuildRecommendation(event *cnpgv1alpha1.StorageEvent, roles map[string]string) *Recommendation {
+	rec := &Recommendation{
+		ClusterName:      event.Spec.ClusterRef.Name,
+		ClusterNamespace: event.Spec.ClusterRef.Namespace,
+		Policy:           event.Spec.PolicyRef.Namespace + "/" + event.Spec.PolicyRef.Name,
+		Event:            event.Name,
+		Reason:           event.Spec.Reason,
+		GeneratedAt:      time.Now().UTC(),
+		PVCs:             []PVCRecommendation{},
+	}
+
+	var storageSize, walStorageSize *resource.Quantity
+	for _, status := range event.Status.PVCStatuses {
+		if status.NewSize == nil {
+			continue
+		}
+
+		pvcRec := PVCRecommendation{
+			Name:            status.Name,
+			Role:            roles[status.Name],
+			RecommendedSize: status.NewSize.String(),
+		}
+		if status.OriginalSize != nil {
+			pvcRec.CurrentSize = status.OriginalSize.String()
+		}
+		rec.PVCs = append(rec.PVCs, pvcRec)
+
+		switch pvcRec.Role {
+		case PVCRoleWAL:
+			if walStorageSize == nil || status.NewSize.Cmp(*walStorageSize) > 0 {
+				walStorageSize = status.NewSize
+			}
+		case PVCRoleData, "":
+			if storageSize == nil || status.NewSize.Cmp(*storageSize) > 0 {
+				storageSize = status.NewSize
+			}
+		}
+	}
+
+	if storageSize != nil {
+		rec.StorageSize = storageSize.String()
+	}
+	if walStorageSize != nil {
+		rec.WALStorageSize = walStorageSize.String()
+	}
+
+	return rec
+}
+
+// RecommendationConfigMapName returns the ConfigMap that receives recommendations for a policy
+func RecommendationConfigMapName(policy *cnpgv1alpha1.StoragePolicy) string {
+	if name := policy.Spec.Expansion.Recommendation.ConfigMapName; name != "" {
+		return name
+	}
+	return policy.Name + recommendationConfigMapSuffix
+}
+
+// RecommendationKey returns the ConfigMap data key for a cluster's recommendation
+func RecommendationKey(clusterNamespace, clusterName string) string {
+	return clusterNamespace + "." + clusterName + ".json"
+}
+
+// RecommendationPublisher writes expansion recommendations to a ConfigMap and,
+// optionally, a webhook
+type RecommendationPublisher struct {
+	client client.Client
+	// reader reads the ConfigMap and webhook Secret without a cache, so the manager
+	// does not need to list and watch them cluster-wide
+	reader     client.Reader
+	httpClient *http.Client
+}
+
+// NewRecommendationPublisher creates a new recommendation publisher. ConfigMaps are
+// written with c and read with reader.
+func NewRecommendationPublisher(c client.Client, reader client.Reader) *RecommendationPublisher {
+	return &RecommendationPublisher{
+		client:     c,
+		reader:     reader,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Publish stores the recommendation in the policy's recommendation ConfigMap and
+// POSTs it to the configured webhook, if any
+func (p *RecommendationPublisher) Publish(
+	ctx context.Context,
+	policy *cnpgv1alpha1.StoragePolicy,
+	rec *Recommendation,
+) error {
+	body, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recommendation: %w", err)
+	}
+
+	key := RecommendationKey(rec.ClusterNamespace, rec.ClusterName)
+	if err := p.writeConfigMap(ctx, policy, key, string(body)); err != nil {
+		return err
+	}
+
+	if secretRef := policy.Spec.Expansion.Recommendation.WebhookSecret; secretRef != "" {
+		if err := p.postWebhook(ctx, policy.Namespace, secretRef, body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeConfigMap creates or updates the recommendation ConfigMap entry
+func (p *RecommendationPublisher) writeConfigMap(
+	ctx context.Context,
+	policy *cnpgv1alpha1.StoragePolicy,
+	key, value string,
+) error {
+	name := RecommendationConfigMapName(policy)
+
+	var cm corev1.ConfigMap
+	err := p.reader.Get(ctx, client.ObjectKey{Name: name, Namespace: policy.Namespace}, &cm)
+	if errors.IsNotFound(err) {
+		cm = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: policy.Namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "cnpg-storage-manager",
+					"cnpg.supporttools.io/policy":  policy.Name,
+				},
+			},
+			Data: map[string]string{key: value},
+		}
+		if err := p.client.Create(ctx, &cm); err != nil {
+			return fmt.Errorf("failed to create recommendation ConfigMap %s/%s: %w", policy.Namespace, name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get recommendation ConfigMap %s/%s: %w", policy.Namespace, name, err)
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[key] = value
+	if err := p.client.Update(ctx, &cm); err != nil {
+		return fmt.Errorf("failed to update recommendation ConfigMap %s/%s: %w", policy.Namespace, name, err)
+	}
+	return nil
+}
+
+// postWebhook sends the recommendation to the URL stored in the referenced Secret
+func (p *RecommendationPublisher) postWebhook(
+	ctx context.Context,
+	defaultNamespace, secretRef string,
+	body []byte,
+) error {
+	namespace := defaultNamespace
+	name := secretRef
+	if idx := strings.IndexByte(secretRef, '/'); idx != -1 {
+		namespace = secretRef[:idx]
+		name = secretRef[idx+1:]
+	}
+
+	var secret corev1.Secret
+	if err := p.reader.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &secret); err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+	webhookURL, ok := secret.Data["webhook-url"]
+	if !ok {
+		return fmt.Errorf("key webhook-url not found in secret %s/%s", namespace, name)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, string(webhookURL), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create recommendation webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send recommendation webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("recommendation webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestBuildRecommendation(t *testing.T) {
+	planned := func(name, original, target string) cnpgv1alpha1.PVCStatus {
+		return cnpgv1alpha1.PVCStatus{
+			Name:         name,
+			OriginalSize: quantityPtr(resource.MustParse(original)),
+			NewSize:      quantityPtr(resource.MustParse(target)),
+		}
+	}
+
+	event := &cnpgv1alpha1.StorageEvent{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg-expansion-abc"},
+		Spec: cnpgv1alpha1.StorageEventSpec{
+			ClusterRef: cnpgv1alpha1.ClusterReference{Name: "pg", Namespace: "db"},
+			PolicyRef:  cnpgv1alpha1.PolicyReference{Name: "policy", Namespace: "ops"},
+			Reason:     "threshold breach: 86.0%",
+		},
+		Status: cnpgv1alpha1.StorageEventStatus{
+			PVCStatuses: []cnpgv1alpha1.PVCStatus{
+				planned("pg-1", "10Gi", "15Gi"),
+				planned("pg-2", "12Gi", "18Gi"),
+				planned("pg-1-wal", "5Gi", "10Gi"),
+				{Name: "pg-3", Phase: cnpgv1alpha1.PVCPhaseFailed, Error: "preflight failed"},
+			},
+		},
+	}
+	roles := map[string]string{"pg-1": PVCRoleData, "pg-2": PVCRoleData, "pg-1-wal": PVCRoleWAL}
+
+	rec := BuildRecommendation(event, roles)
+
+	if rec.Policy != "ops/policy" || rec.Event != "pg-expansion-abc" {
+		t.Errorf("unexpected references: policy=%s event=%s", rec.Policy, rec.Event)
+	}
+	if rec.StorageSize != "18Gi" {
+		t.Errorf("expected storage size 18Gi, got %s", rec.StorageSize)
+	}
+	if rec.WALStorageSize != "10Gi" {
+		t.Errorf("expected WAL storage size 10Gi, got %s", rec.WALStorageSize)
+	}
+	if len(rec.PVCs) != 3 {
+		t.Errorf("expected 3 PVC recommendations, got %d", len(rec.PVCs))
+	}
+}
+
+func TestRecommendationPublisher_Publish(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = cnpgv1alpha1.AddToScheme(scheme)
+
+	var received Recommendation
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "gitops-webhook", Namespace: "ops"},
+		Data:       map[string][]byte{"webhook-url": []byte(server.URL)},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	policy := &cnpgv1alpha1.StoragePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ops"},
+		Spec: cnpgv1alpha1.StoragePolicySpec{
+			Expansion: cnpgv1alpha1.ExpansionConfig{
+				Mode:           cnpgv1alpha1.ExpansionModeRecommend,
+				Recommendation: cnpgv1alpha1.RecommendationConfig{WebhookSecret: "gitops-webhook"},
+			},
+		},
+	}
+
+	publisher := NewRecommendationPublisher(c, c)
+	ctx := context.Background()
+
+	for _, size := range []string{"15Gi", "20Gi"} {
+		rec := &Recommendation{ClusterName: "pg", ClusterNamespace: "db", StorageSize: size}
+		if err := publisher.Publish(ctx, policy, rec); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, client.ObjectKey{Name: "policy-recommendations", Namespace: "ops"}, &cm); err != nil {
+		t.Fatalf("failed to get recommendation ConfigMap: %v", err)
+	}
+
+	var stored Recommendation
+	if err := json.Unmarshal([]byte(cm.Data[RecommendationKey("db", "pg")]), &stored); err != nil {
+		t.Fatalf("failed to decode stored recommendation: %v", err)
+	}
+	if stored.StorageSize != "20Gi" {
+		t.Errorf("expected stored storage size 20Gi, got %s", stored.StorageSize)
+	}
+	if received.StorageSize != "20Gi" {
+		t.Errorf("expected webhook to receive storage size 20Gi, got %s", received.StorageSize)
+	}
+}
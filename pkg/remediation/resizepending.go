@@ -0,0 +1,134 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+// DefaultFileSystemResizeTimeout is how long a PVC may wait for its filesystem resize
+// before it is reported when the policy does not set a timeout
+const DefaultFileSystemResizeTimeout = 30 * time.Minute
+
+// FileSystemResizeTimeout returns the policy's filesystem resize timeout
+func FileSystemResizeTimeout(policy *cnpgv1alpha1.StoragePolicy) time.Duration {
+	if minutes := policy.Spec.Expansion.FileSystemResize.TimeoutMinutes; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return DefaultFileSystemResizeTimeout
+}
+
+// FindPendingResizes returns the PVCs whose FileSystemResizePending condition has been
+// True for longer than timeout, sorted by name. previous carries the pod restarts of
+// PVCs that were already pending
+func FindPendingResizes(
+	pvcs []corev1.PersistentVolumeClaim,
+	previous []cnpgv1alpha1.PendingResizeStatus,
+	timeout time.Duration,
+	now time.Time,
+) []cnpgv1alpha1.PendingResizeStatus {
+	var pending []cnpgv1alpha1.PendingResizeStatus
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		for _, cond := range pvc.Status.Conditions {
+			if cond.Type != corev1.PersistentVolumeClaimFileSystemResizePending || cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			if now.Sub(cond.LastTransitionTime.Time) < timeout {
+				break
+			}
+			status := cnpgv1alpha1.PendingResizeStatus{
+				PVC:          pvc.Name,
+				Instance:     pvc.Labels[cnpg.LabelInstanceName],
+				PendingSince: cond.LastTransitionTime,
+			}
+			if prev := FindPendingResize(previous, pvc.Name); prev != nil {
+				status.RestartedAt = prev.RestartedAt
+			}
+			pending = append(pending, status)
+			break
+		}
+	}
+	slices.SortFunc(pending, func(a, b cnpgv1alpha1.PendingResizeStatus) int {
+		return strings.Compare(a.PVC, b.PVC)
+	})
+	return pending
+}
+
+// FindPendingResize returns the pending resize of the named PVC, or nil
+func FindPendingResize(pending []cnpgv1alpha1.PendingResizeStatus, pvc string) *cnpgv1alpha1.PendingResizeStatus {
+	for i := range pending {
+		if pending[i].PVC == pvc {
+			return &pending[i]
+		}
+	}
+	return nil
+}
+
+// NextResizeRestart returns the instance whose pod to delete to complete a pending
+// filesystem resize, or an empty string. Only one instance is restarted at a time: none
+// while an instance is not ready or a restart is more recent than timeout. Replicas are
+// restarted before the primary
+func NextResizeRestart(
+	pending []cnpgv1alpha1.PendingResizeStatus,
+	cluster cnpg.ClusterInfo,
+	timeout time.Duration,
+	now time.Time,
+) string {
+	if cluster.Status.ReadyInstances < cluster.Instances {
+		return ""
+	}
+
+	var replicas, primary []string
+	for _, status := range pending {
+		if status.RestartedAt != nil && now.Sub(status.RestartedAt.Time) < timeout {
+			return ""
+		}
+		switch status.Instance {
+		case "":
+		case cluster.Status.CurrentPrimary:
+			primary = append(primary, status.Instance)
+		default:
+			replicas = append(replicas, status.Instance)
+		}
+	}
+	if len(replicas) > 0 {
+		return replicas[0]
+	}
+	if len(primary) > 0 {
+		return primary[0]
+	}
+	return ""
+}
+
+// MarkResizeRestarted records the restart of an instance on its pending resizes
+func MarkResizeRestarted(pending []cnpgv1alpha1.PendingResizeStatus, instance string, now time.Time) {
+	restartedAt := metav1.NewTime(now)
+	for i := range pending {
+		if pending[i].Instance == instance {
+			pending[i].RestartedAt = &restartedAt
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+func resizePendingPVC(name, instance string, since time.Time) corev1.PersistentVolumeClaim {
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{cnpg.LabelInstanceName: instance}},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Conditions: []corev1.PersistentVolumeClaimCondition{{
+				Type:               corev1.PersistentVolumeClaimFileSystemResizePending,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(since),
+			}},
+		},
+	}
+}
+
+func TestFindPendingResizes(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	restartedAt := metav1.NewTime(now.Add(-10 * time.Minute))
+	pvcs := []corev1.PersistentVolumeClaim{
+		resizePendingPVC("pg-2", "pg-2", now.Add(-time.Hour)),
+		resizePendingPVC("pg-1", "pg-1", now.Add(-45*time.Minute)),
+		resizePendingPVC("pg-3", "pg-3", now.Add(-5*time.Minute)),
+		{ObjectMeta: metav1.ObjectMeta{Name: "pg-4"}},
+	}
+	previous := []cnpgv1alpha1.PendingResizeStatus{{PVC: "pg-2", RestartedAt: &restartedAt}}
+
+	pending := FindPendingResizes(pvcs, previous, 30*time.Minute, now)
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending resizes past the timeout, got %+v", pending)
+	}
+	if pending[0].PVC != "pg-1" || pending[1].PVC != "pg-2" {
+		t.Errorf("expected pg-1 and pg-2 sorted by name, got %s and %s", pending[0].PVC, pending[1].PVC)
+	}
+	if pending[0].Instance != "pg-1" || pending[0].RestartedAt != nil {
+		t.Errorf("unexpected status for pg-1: %+v", pending[0])
+	}
+	if pending[1].RestartedAt == nil || !pending[1].RestartedAt.Equal(&restartedAt) {
+		t.Errorf("expected the previous restart of pg-2 to be kept, got %+v", pending[1].RestartedAt)
+	}
+}
+
+func TestNextResizeRestart(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := metav1.NewTime(now.Add(-10 * time.Minute))
+	old := metav1.NewTime(now.Add(-time.Hour))
+	ready := cnpg.ClusterInfo{
+		Instances: 3,
+		Status:    cnpg.ClusterStatus{ReadyInstances: 3, CurrentPrimary: "pg-1"},
+	}
+	degraded := ready
+	degraded.Status.ReadyInstances = 2
+
+	tests := []struct {
+		name     string
+		pending  []cnpgv1alpha1.PendingResizeStatus
+		cluster  cnpg.ClusterInfo
+		expected string
+	}{
+		{
+			name:     "replicas before the primary",
+			pending:  []cnpgv1alpha1.PendingResizeStatus{{PVC: "pg-1", Instance: "pg-1"}, {PVC: "pg-3", Instance: "pg-3"}},
+			cluster:  ready,
+			expected: "pg-3",
+		},
+		{
+			name:     "primary last",
+			pending:  []cnpgv1alpha1.PendingResizeStatus{{PVC: "pg-1", Instance: "pg-1"}},
+			cluster:  ready,
+			expected: "pg-1",
+		},
+		{
+			name:    "not while an instance is not ready",
+			pending: []cnpgv1alpha1.PendingResizeStatus{{PVC: "pg-3", Instance: "pg-3"}},
+			cluster: degraded,
+		},
+		{
+			name: "not while a restart is recent",
+			pending: []cnpgv1alpha1.PendingResizeStatus{
+				{PVC: "pg-2", Instance: "pg-2", RestartedAt: &recent},
+				{PVC: "pg-3", Instance: "pg-3"},
+			},
+			cluster: ready,
+		},
+		{
+			name:     "again after the timeout",
+			pending:  []cnpgv1alpha1.PendingResizeStatus{{PVC: "pg-2", Instance: "pg-2", RestartedAt: &old}},
+			cluster:  ready,
+			expected: "pg-2",
+		},
+		{
+			name:    "unknown instance",
+			pending: []cnpgv1alpha1.PendingResizeStatus{{PVC: "pg-2"}},
+			cluster: ready,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if instance := NextResizeRestart(tt.pending, tt.cluster, 30*time.Minute, now); instance != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, instance)
+			}
+		})
+	}
+}
+
+func TestMarkResizeRestarted(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pending := []cnpgv1alpha1.PendingResizeStatus{
+		{PVC: "pg-2", Instance: "pg-2"},
+		{PVC: "pg-2-wal", Instance: "pg-2"},
+		{PVC: "pg-3", Instance: "pg-3"},
+	}
+	MarkResizeRestarted(pending, "pg-2", now)
+	if pending[0].RestartedAt == nil || pending[1].RestartedAt == nil || pending[2].RestartedAt != nil {
+		t.Errorf("expected only the PVCs of pg-2 to be marked, got %+v", pending)
+	}
+}
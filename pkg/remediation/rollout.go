@@ -0,0 +1,64 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+// DefaultRolloutHealthyTimeout is how long an instance of a rolling expansion may take to
+// report its new capacity and the cluster to be healthy again when the policy does not
+// set a timeout
+const DefaultRolloutHealthyTimeout = 30 * time.Minute
+
+// RolloutHealthyTimeout returns how long an instance of a rolling expansion may take
+func RolloutHealthyTimeout(rollout *cnpgv1alpha1.ExpansionRolloutConfig) time.Duration {
+	if rollout != nil && rollout.HealthyTimeoutMinutes > 0 {
+		return time.Duration(rollout.HealthyTimeoutMinutes) * time.Minute
+	}
+	return DefaultRolloutHealthyTimeout
+}
+
+// OrderByInstance sorts PVC statuses into the order a rolling expansion expands their
+// instances in: the replicas by name, with the primary before or after them. The PVCs
+// of an instance keep their order
+func OrderByInstance(statuses []cnpgv1alpha1.PVCStatus, primary string, order cnpgv1alpha1.ExpansionOrder) {
+	rank := func(instance string) int {
+		switch {
+		case instance != primary || primary == "":
+			return 1
+		case order == cnpgv1alpha1.ExpansionOrderPrimaryFirst:
+			return 0
+		default:
+			return 2
+		}
+	}
+	slices.SortStableFunc(statuses, func(a, b cnpgv1alpha1.PVCStatus) int {
+		return cmp.Or(cmp.Compare(rank(a.Instance), rank(b.Instance)), cmp.Compare(a.Instance, b.Instance))
+	})
+}
+
+// IsClusterHealthy returns true when CNPG reports the cluster healthy with every
+// instance ready
+func IsClusterHealthy(cluster cnpg.ClusterInfo) bool {
+	return cluster.Status.Phase == cnpg.ClusterPhaseHealthy && cluster.Status.ReadyInstances >= cluster.Instances
+}
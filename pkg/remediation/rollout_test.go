@@ -0,0 +1,77 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"testing"
+	"time"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+func TestOrderByInstance(t *testing.T) {
+	statuses := func() []cnpgv1alpha1.PVCStatus {
+		return []cnpgv1alpha1.PVCStatus{
+			{Name: "pg-1", Instance: "pg-1"},
+			{Name: "pg-3", Instance: "pg-3"},
+			{Name: "pg-1-wal", Instance: "pg-1"},
+			{Name: "pg-2", Instance: "pg-2"},
+			{Name: "pg-3-wal", Instance: "pg-3"},
+		}
+	}
+	tests := []struct {
+		order    cnpgv1alpha1.ExpansionOrder
+		expected []string
+	}{
+		{"", []string{"pg-1", "pg-1-wal", "pg-3", "pg-3-wal", "pg-2"}},
+		{cnpgv1alpha1.ExpansionOrderReplicasFirst, []string{"pg-1", "pg-1-wal", "pg-3", "pg-3-wal", "pg-2"}},
+		{cnpgv1alpha1.ExpansionOrderPrimaryFirst, []string{"pg-2", "pg-1", "pg-1-wal", "pg-3", "pg-3-wal"}},
+	}
+	for _, tt := range tests {
+		ordered := statuses()
+		OrderByInstance(ordered, "pg-2", tt.order)
+		for i, status := range ordered {
+			if status.Name != tt.expected[i] {
+				t.Errorf("order %q: expected %v, got %+v", tt.order, tt.expected, ordered)
+				break
+			}
+		}
+	}
+}
+
+func TestIsClusterHealthy(t *testing.T) {
+	cluster := cnpg.ClusterInfo{Instances: 3}
+	cluster.Status.Phase = cnpg.ClusterPhaseHealthy
+	cluster.Status.ReadyInstances = 3
+	if !IsClusterHealthy(cluster) {
+		t.Error("expected a healthy cluster with every instance ready to be healthy")
+	}
+	cluster.Status.ReadyInstances = 2
+	if IsClusterHealthy(cluster) {
+		t.Error("expected a cluster with an instance not ready to be unhealthy")
+	}
+}
+
+func TestRolloutHealthyTimeout(t *testing.T) {
+	if got := RolloutHealthyTimeout(nil); got != DefaultRolloutHealthyTimeout {
+		t.Errorf("expected the default timeout, got %s", got)
+	}
+	if got := RolloutHealthyTimeout(&cnpgv1alpha1.ExpansionRolloutConfig{HealthyTimeoutMinutes: 5}); got != 5*time.Minute {
+		t.Errorf("expected 5m, got %s", got)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// Remediation step names recorded in the StorageEvent transaction log
+const (
+	// StepPlan computes and records target sizes for each PVC
+	StepPlan = "plan"
+	// StepExpand issues the PVC resize requests
+	StepExpand = "expand"
+	// StepVerify waits for the storage layer to report the new capacity
+	StepVerify = "verify"
+	// StepRecommend publishes the planned sizes instead of resizing PVCs
+	StepRecommend = "recommend"
+	// StepLocatePrimary resolves the current primary pod
+	StepLocatePrimary = "locate-primary"
+	// StepCleanup removes archived WAL segments
+	StepCleanup = "cleanup"
+	// StepRestore creates the throwaway recovery cluster
+	StepRestore = "restore"
+	// StepWaitReady waits for the recovery cluster to finish recovery
+	StepWaitReady = "wait-ready"
+	// StepSmokeCheck runs the smoke SQL against the recovered database
+	StepSmokeCheck = "smoke-check"
+	// StepTeardown deletes the recovery cluster and the copied credentials
+	StepTeardown = "teardown"
+	// StepUpdateSpec sets the new StorageClass in the Cluster spec
+	StepUpdateSpec = "update-spec"
+	// StepMigrateInstances recreates the instances on the new StorageClass one at a time
+	StepMigrateInstances = "migrate-instances"
+	// StepModifyVolumes sets the new VolumeAttributesClass on the PVCs
+	StepModifyVolumes = "modify-volumes"
+	// StepVerifyModification waits for the CSI driver to modify the volumes
+	StepVerifyModification = "verify-modification"
+)
+
+// StepsForEvent returns the ordered steps executed for an event
+func StepsForEvent(event *cnpgv1alpha1.StorageEvent) []string {
+	switch event.Spec.EventType {
+	case cnpgv1alpha1.EventTypeExpansion:
+		if event.Spec.RecommendOnly {
+			return []string{StepPlan, StepRecommend}
+		}
+		return []string{StepPlan, StepExpand, StepVerify}
+	case cnpgv1alpha1.EventTypeWALCleanup:
+		return []string{StepLocatePrimary, StepCleanup}
+	case cnpgv1alpha1.EventTypeRestoreTest:
+		return []string{StepRestore, StepWaitReady, StepSmokeCheck, StepTeardown}
+	case cnpgv1alpha1.EventTypeStorageClassMigration:
+		return []string{StepUpdateSpec, StepMigrateInstances}
+	case cnpgv1alpha1.EventTypeVolumeAttributesChange:
+		return []string{StepModifyVolumes, StepVerifyModification}
+	default:
+		return nil
+	}
+}
+
+// InitSteps seeds the transaction log with Pending steps. Existing steps are
+// left untouched so a resumed event keeps its recorded progress.
+func InitSteps(event *cnpgv1alpha1.StorageEvent, names []string) {
+	if len(event.Status.Steps) > 0 {
+		return
+	}
+	for _, name := range names {
+		event.Status.Steps = append(event.Status.Steps, cnpgv1alpha1.RemediationStep{
+			Name:  name,
+			Phase: cnpgv1alpha1.StepPhasePending,
+		})
+	}
+}
+
+// NextStep returns the first step that has not completed, or nil if all steps are done
+func NextStep(event *cnpgv1alpha1.StorageEvent) *cnpgv1alpha1.RemediationStep {
+	for i := range event.Status.Steps {
+		if event.Status.Steps[i].Phase != cnpgv1alpha1.StepPhaseCompleted {
+			return &event.Status.Steps[i]
+		}
+	}
+	return nil
+}
+
+// FindStep returns the named step, or nil if it is not in the transaction log
+func FindStep(event *cnpgv1alpha1.StorageEvent, name string) *cnpgv1alpha1.RemediationStep {
+	for i := range event.Status.Steps {
+		if event.Status.Steps[i].Name == name {
+			return &event.Status.Steps[i]
+		}
+	}
+	return nil
+}
+
+// StartStep marks a step InProgress, preserving the original start time on resume
+func StartStep(event *cnpgv1alpha1.StorageEvent, step *cnpgv1alpha1.RemediationStep) {
+	if step.StartTime == nil {
+		now := metav1.Now()
+		step.StartTime = &now
+	}
+	step.Phase = cnpgv1alpha1.StepPhaseInProgress
+	event.Status.CurrentStep = step.Name
+}
+
+// CompleteStep marks a step Completed
+func CompleteStep(event *cnpgv1alpha1.StorageEvent, step *cnpgv1alpha1.RemediationStep, message string) {
+	now := metav1.Now()
+	step.Phase = cnpgv1alpha1.StepPhaseCompleted
+	step.CompletionTime = &now
+	step.Message = message
+	if NextStep(event) == nil {
+		event.Status.CurrentStep = ""
+	}
+}
+
+// FailStep marks a step Failed. The step is retried when the event is retried.
+func FailStep(step *cnpgv1alpha1.RemediationStep, message string) {
+	step.Phase = cnpgv1alpha1.StepPhaseFailed
+	step.Message = message
+}
+
+// StepSummary joins the messages of completed steps into a single status message
+func StepSummary(event *cnpgv1alpha1.StorageEvent) string {
+	parts := make([]string, 0, len(event.Status.Steps))
+	for _, step := range event.Status.Steps {
+		if step.Phase == cnpgv1alpha1.StepPhaseCompleted && step.Message != "" {
+			parts = append(parts, step.Name+": "+step.Message)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
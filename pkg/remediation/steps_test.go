@@ -0,0 +1,114 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"testing"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestStepsForEvent(t *testing.T) {
+	tests := []struct {
+		name          string
+		eventType     cnpgv1alpha1.EventType
+		recommendOnly bool
+		expected      []string
+	}{
+		{"expansion", cnpgv1alpha1.EventTypeExpansion, false, []string{StepPlan, StepExpand, StepVerify}},
+		{"recommendation", cnpgv1alpha1.EventTypeExpansion, true, []string{StepPlan, StepRecommend}},
+		{"wal cleanup", cnpgv1alpha1.EventTypeWALCleanup, false, []string{StepLocatePrimary, StepCleanup}},
+		{"restore test", cnpgv1alpha1.EventTypeRestoreTest, false,
+			[]string{StepRestore, StepWaitReady, StepSmokeCheck, StepTeardown}},
+		{"storage class migration", cnpgv1alpha1.EventTypeStorageClassMigration, false,
+			[]string{StepUpdateSpec, StepMigrateInstances}},
+		{"volume attributes change", cnpgv1alpha1.EventTypeVolumeAttributesChange, false,
+			[]string{StepModifyVolumes, StepVerifyModification}},
+		{"alert", cnpgv1alpha1.EventTypeAlert, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &cnpgv1alpha1.StorageEvent{
+				Spec: cnpgv1alpha1.StorageEventSpec{EventType: tt.eventType, RecommendOnly: tt.recommendOnly},
+			}
+			got := StepsForEvent(event)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("step %d: expected %s, got %s", i, tt.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStepLifecycle(t *testing.T) {
+	event := &cnpgv1alpha1.StorageEvent{Spec: cnpgv1alpha1.StorageEventSpec{EventType: cnpgv1alpha1.EventTypeExpansion}}
+	InitSteps(event, StepsForEvent(event))
+
+	if len(event.Status.Steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d", len(event.Status.Steps))
+	}
+
+	step := NextStep(event)
+	if step == nil || step.Name != StepPlan {
+		t.Fatalf("expected next step %s, got %v", StepPlan, step)
+	}
+	StartStep(event, step)
+	if event.Status.CurrentStep != StepPlan || step.Phase != cnpgv1alpha1.StepPhaseInProgress {
+		t.Errorf("expected plan step in progress, got current=%s phase=%s", event.Status.CurrentStep, step.Phase)
+	}
+	CompleteStep(event, step, "2 PVCs planned")
+
+	// A failed step remains the next step so a retry resumes from it
+	step = NextStep(event)
+	StartStep(event, step)
+	startTime := step.StartTime
+	FailStep(step, "boom")
+	if next := NextStep(event); next == nil || next.Name != StepExpand {
+		t.Fatalf("expected to resume at %s, got %v", StepExpand, next)
+	}
+	StartStep(event, step)
+	if step.StartTime != startTime {
+		t.Error("expected resumed step to keep its original start time")
+	}
+	CompleteStep(event, step, "2 PVCs resized")
+
+	// Re-initializing keeps recorded progress
+	InitSteps(event, StepsForEvent(event))
+	if next := NextStep(event); next == nil || next.Name != StepVerify {
+		t.Fatalf("expected to resume at %s after re-init, got %v", StepVerify, next)
+	}
+
+	step = NextStep(event)
+	StartStep(event, step)
+	CompleteStep(event, step, "")
+	if NextStep(event) != nil {
+		t.Error("expected all steps to be complete")
+	}
+	if event.Status.CurrentStep != "" {
+		t.Errorf("expected current step to be cleared, got %s", event.Status.CurrentStep)
+	}
+
+	expected := "plan: 2 PVCs planned; expand: 2 PVCs resized"
+	if got := StepSummary(event); got != expected {
+		t.Errorf("expected summary %q, got %q", expected, got)
+	}
+}
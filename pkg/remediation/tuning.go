@@ -0,0 +1,101 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+// TuningTarget is a PVC whose latency calls for performance tuning
+type TuningTarget struct {
+	PVC       string
+	Instance  string
+	LatencyMs float64
+}
+
+// LatencyQuery returns the policy's latency query for a cluster, with $cluster and
+// $namespace replaced
+func LatencyQuery(config *cnpgv1alpha1.PerformanceTuningConfig, cluster cnpg.ClusterInfo) string {
+	return strings.NewReplacer("$cluster", cluster.Name, "$namespace", cluster.Namespace).
+		Replace(config.LatencyQuery)
+}
+
+// IsPVCTuned reports whether a PVC already has the VolumeAttributesClass and annotations
+// of the policy's performance tuning
+func IsPVCTuned(pvc *corev1.PersistentVolumeClaim, config *cnpgv1alpha1.PerformanceTuningConfig) bool {
+	if config.VolumeAttributesClass != "" {
+		if pvc.Spec.VolumeAttributesClassName == nil || *pvc.Spec.VolumeAttributesClassName != config.VolumeAttributesClass {
+			return false
+		}
+	}
+	for key, value := range config.Annotations {
+		if current, found := pvc.Annotations[key]; !found || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// FindTuningTargets returns the PVCs whose latency reaches the policy's threshold and
+// that are not tuned yet, sorted by name. PVCs without a latency sample are skipped
+func FindTuningTargets(
+	config *cnpgv1alpha1.PerformanceTuningConfig,
+	pvcs []corev1.PersistentVolumeClaim,
+	latency map[string]float64,
+) []TuningTarget {
+	var targets []TuningTarget
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		value, found := latency[pvc.Name]
+		if !found || value < float64(config.LatencyThresholdMs) || IsPVCTuned(pvc, config) {
+			continue
+		}
+		targets = append(targets, TuningTarget{
+			PVC:       pvc.Name,
+			Instance:  pvc.Labels[cnpg.LabelInstanceName],
+			LatencyMs: value,
+		})
+	}
+	slices.SortFunc(targets, func(a, b TuningTarget) int {
+		return strings.Compare(a.PVC, b.PVC)
+	})
+	return targets
+}
+
+// ApplyTuning sets the VolumeAttributesClass and annotations of the policy's performance
+// tuning on a PVC
+func ApplyTuning(pvc *corev1.PersistentVolumeClaim, config *cnpgv1alpha1.PerformanceTuningConfig) {
+	if config.VolumeAttributesClass != "" {
+		className := config.VolumeAttributesClass
+		pvc.Spec.VolumeAttributesClassName = &className
+	}
+	if len(config.Annotations) == 0 {
+		return
+	}
+	if pvc.Annotations == nil {
+		pvc.Annotations = make(map[string]string, len(config.Annotations))
+	}
+	for key, value := range config.Annotations {
+		pvc.Annotations[key] = value
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/cnpg"
+)
+
+func tuningConfig() *cnpgv1alpha1.PerformanceTuningConfig {
+	return &cnpgv1alpha1.PerformanceTuningConfig{
+		PrometheusURL:         "http://prometheus:9090",
+		LatencyQuery:          `pvc_latency_ms{namespace="$namespace",cluster="$cluster"}`,
+		LatencyThresholdMs:    20,
+		VolumeAttributesClass: "gp3-fast",
+		Annotations:           map[string]string{"ebs.csi.aws.com/throughput": "500"},
+	}
+}
+
+func TestLatencyQuery(t *testing.T) {
+	cluster := cnpg.ClusterInfo{Name: "pg", Namespace: "db"}
+	expected := `pvc_latency_ms{namespace="db",cluster="pg"}`
+	if query := LatencyQuery(tuningConfig(), cluster); query != expected {
+		t.Errorf("LatencyQuery() = %s, expected %s", query, expected)
+	}
+}
+
+func TestIsPVCTuned(t *testing.T) {
+	fast := "gp3-fast"
+	slow := "gp3-default"
+	tests := []struct {
+		name        string
+		className   *string
+		annotations map[string]string
+		config      func(*cnpgv1alpha1.PerformanceTuningConfig)
+		expected    bool
+	}{
+		{
+			name:        "class and annotations set",
+			className:   &fast,
+			annotations: map[string]string{"ebs.csi.aws.com/throughput": "500"},
+			expected:    true,
+		},
+		{
+			name:        "other class",
+			className:   &slow,
+			annotations: map[string]string{"ebs.csi.aws.com/throughput": "500"},
+		},
+		{
+			name:        "no class",
+			annotations: map[string]string{"ebs.csi.aws.com/throughput": "500"},
+		},
+		{
+			name:        "other annotation value",
+			className:   &fast,
+			annotations: map[string]string{"ebs.csi.aws.com/throughput": "125"},
+		},
+		{
+			name:     "annotations only",
+			config:   func(c *cnpgv1alpha1.PerformanceTuningConfig) { c.Annotations = nil },
+			expected: false,
+		},
+		{
+			name:        "class not configured",
+			annotations: map[string]string{"ebs.csi.aws.com/throughput": "500"},
+			config:      func(c *cnpgv1alpha1.PerformanceTuningConfig) { c.VolumeAttributesClass = "" },
+			expected:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tuningConfig()
+			if tt.config != nil {
+				tt.config(config)
+			}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Annotations: tt.annotations},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeAttributesClassName: tt.className},
+			}
+			if tuned := IsPVCTuned(pvc, config); tuned != tt.expected {
+				t.Errorf("IsPVCTuned() = %v, expected %v", tuned, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindTuningTargets(t *testing.T) {
+	config := tuningConfig()
+	tuned := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pg-4"}}
+	ApplyTuning(tuned, config)
+	pvcs := []corev1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pg-3", Labels: map[string]string{cnpg.LabelInstanceName: "pg-3"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Labels: map[string]string{cnpg.LabelInstanceName: "pg-1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pg-2"}},
+		*tuned,
+		{ObjectMeta: metav1.ObjectMeta{Name: "pg-5"}},
+	}
+	latency := map[string]float64{"pg-1": 20, "pg-2": 19.9, "pg-3": 45, "pg-4": 80}
+
+	targets := FindTuningTargets(config, pvcs, latency)
+	if len(targets) != 2 {
+		t.Fatalf("expected pg-1 and pg-3 to need tuning, got %+v", targets)
+	}
+	if targets[0].PVC != "pg-1" || targets[1].PVC != "pg-3" {
+		t.Errorf("expected targets sorted by name, got %s and %s", targets[0].PVC, targets[1].PVC)
+	}
+	if targets[1].Instance != "pg-3" || targets[1].LatencyMs != 45 {
+		t.Errorf("unexpected target %+v", targets[1])
+	}
+}
+
+func TestApplyTuning(t *testing.T) {
+	config := tuningConfig()
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Annotations: map[string]string{"keep": "me"}},
+	}
+	ApplyTuning(pvc, config)
+
+	if pvc.Spec.VolumeAttributesClassName == nil || *pvc.Spec.VolumeAttributesClassName != "gp3-fast" {
+		t.Errorf("expected VolumeAttributesClass gp3-fast, got %v", pvc.Spec.VolumeAttributesClassName)
+	}
+	if pvc.Annotations["ebs.csi.aws.com/throughput"] != "500" || pvc.Annotations["keep"] != "me" {
+		t.Errorf("unexpected annotations %v", pvc.Annotations)
+	}
+	if !IsPVCTuned(pvc, config) {
+		t.Error("expected the PVC to be tuned")
+	}
+
+	config.VolumeAttributesClass = ""
+	bare := &corev1.PersistentVolumeClaim{}
+	ApplyTuning(bare, config)
+	if bare.Spec.VolumeAttributesClassName != nil {
+		t.Errorf("expected no VolumeAttributesClass, got %s", *bare.Spec.VolumeAttributesClassName)
+	}
+}
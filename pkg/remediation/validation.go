@@ -19,6 +19,7 @@ package remediation
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	storagev1 "k8s.io/api/storage/v1"
@@ -258,6 +259,33 @@ type PreflightCheck struct {
 	Message string
 }
 
+// SkipCode classifies why a PVC is not expanded. It is the reason label of the
+// expansion_skipped_total metric
+type SkipCode string
+
+const (
+	// SkipCodeNoStorageClass means the PVC has no storage class
+	SkipCodeNoStorageClass SkipCode = "no_storage_class"
+	// SkipCodeNonExpandableClass means the storage class does not allow volume expansion
+	SkipCodeNonExpandableClass SkipCode = "non_expandable_class"
+	// SkipCodeUnbound means the PVC is not bound
+	SkipCodeUnbound SkipCode = "unbound"
+	// SkipCodeAccessMode means the PVC has no access mode that can be expanded
+	SkipCodeAccessMode SkipCode = "access_mode"
+	// SkipCodeMaxSize means the PVC is already at the policy's maximum size
+	SkipCodeMaxSize SkipCode = "max_size"
+	// SkipCodePreflightError means the preflight checks of the PVC could not be run
+	SkipCodePreflightError SkipCode = "preflight_error"
+)
+
+// preflightSkipCodes maps preflight checks to the skip code of their failure
+var preflightSkipCodes = map[string]SkipCode{
+	"storage-class-set":       SkipCodeNoStorageClass,
+	"storage-class-expansion": SkipCodeNonExpandableClass,
+	"pvc-bound":               SkipCodeUnbound,
+	"access-mode":             SkipCodeAccessMode,
+}
+
 // FailedChecks returns the list of failed checks
 func (r *PreflightResult) FailedChecks() []PreflightCheck {
 	var failed []PreflightCheck
@@ -278,3 +306,17 @@ func (r *PreflightResult) Summary() string {
 	failed := r.FailedChecks()
 	return fmt.Sprintf("PVC %s/%s failed %d of %d preflight checks", r.Namespace, r.PVCName, len(failed), len(r.Checks))
 }
+
+// SkipReason returns the skip code of the first failed check and the messages of all
+// failed checks
+func (r *PreflightResult) SkipReason() (SkipCode, string) {
+	failed := r.FailedChecks()
+	if len(failed) == 0 {
+		return "", ""
+	}
+	messages := make([]string, 0, len(failed))
+	for _, check := range failed {
+		messages = append(messages, check.Message)
+	}
+	return preflightSkipCodes[failed[0].Name], fmt.Sprintf("%s: %s", r.Summary(), strings.Join(messages, "; "))
+}
@@ -296,6 +296,33 @@ func TestPreflightResult_FailedChecks(t *testing.T) {
 	}
 }
 
+func TestPreflightResult_SkipReason(t *testing.T) {
+	result := &PreflightResult{
+		PVCName:   "test-pvc",
+		Namespace: "default",
+		Checks: []PreflightCheck{
+			{Name: "storage-class-expansion", Passed: false, Message: "storage class standard does not allow expansion"},
+			{Name: "pvc-bound", Passed: false, Message: "PVC is not bound (phase: Pending)"},
+			{Name: "access-mode", Passed: true},
+		},
+	}
+
+	code, reason := result.SkipReason()
+	if code != SkipCodeNonExpandableClass {
+		t.Errorf("expected skip code %q, got %q", SkipCodeNonExpandableClass, code)
+	}
+	expected := "PVC default/test-pvc failed 2 of 3 preflight checks: " +
+		"storage class standard does not allow expansion; PVC is not bound (phase: Pending)"
+	if reason != expected {
+		t.Errorf("expected reason %q, got %q", expected, reason)
+	}
+
+	result.Checks = result.Checks[2:]
+	if code, reason := result.SkipReason(); code != "" || reason != "" {
+		t.Errorf("expected no skip reason when all checks pass, got %q, %q", code, reason)
+	}
+}
+
 func TestPreflightResult_Summary(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,154 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+// DefaultModifyVolumeTimeout is how long the CSI driver may take to modify a volume when
+// the policy does not set a timeout
+const DefaultModifyVolumeTimeout = 30 * time.Minute
+
+// ErrVolumeAttributesClassUnsupported is returned when the cluster does not serve the
+// VolumeAttributesClass API, e.g. before Kubernetes 1.31 or without its feature gate
+var ErrVolumeAttributesClassUnsupported = errors.New("the VolumeAttributesClass API is not served by this cluster")
+
+// volumeAttributesClassKind is served as storage.k8s.io/v1beta1 from Kubernetes 1.31 and
+// as storage.k8s.io/v1 from 1.34
+var volumeAttributesClassKind = schema.GroupKind{Group: "storage.k8s.io", Kind: "VolumeAttributesClass"}
+
+// thresholdLevels are the threshold levels a VolumeAttributesClass can be selected for,
+// from lowest to highest
+var thresholdLevels = []string{"normal", "warning", "critical", "expansion", "emergency"}
+
+// VolumeAttributesClassForLevel returns the class of the highest threshold level of the
+// policy's volumeAttributes reached by level, and that threshold. Both are empty when no
+// listed level is reached
+func VolumeAttributesClassForLevel(
+	config *cnpgv1alpha1.VolumeAttributesConfig,
+	level string,
+) (className, threshold string) {
+	reached := slices.Index(thresholdLevels, level)
+	best := -1
+	for _, target := range config.Classes {
+		index := slices.Index(thresholdLevels, target.Threshold)
+		if index < 0 || index > reached || index <= best {
+			continue
+		}
+		best = index
+		className, threshold = target.VolumeAttributesClass, target.Threshold
+	}
+	return className, threshold
+}
+
+// NewVolumeAttributesChangeDetails returns the details of a volume-attributes-change
+// event for a cluster at the given threshold level, or nil when the policy selects no
+// class for it
+func NewVolumeAttributesChangeDetails(
+	policy *cnpgv1alpha1.StoragePolicy,
+	level string,
+) *cnpgv1alpha1.VolumeAttributesChangeDetails {
+	config := policy.Spec.VolumeAttributes
+	if config == nil {
+		return nil
+	}
+	className, threshold := VolumeAttributesClassForLevel(config, level)
+	if className == "" {
+		return nil
+	}
+	return &cnpgv1alpha1.VolumeAttributesChangeDetails{
+		VolumeAttributesClass: className,
+		Threshold:             threshold,
+		ModifyTimeoutMinutes:  config.ModifyTimeoutMinutes,
+	}
+}
+
+// ModifyVolumeTimeout returns how long the volumes of an event may take to be modified
+func ModifyVolumeTimeout(details *cnpgv1alpha1.VolumeAttributesChangeDetails) time.Duration {
+	if details != nil && details.ModifyTimeoutMinutes > 0 {
+		return time.Duration(details.ModifyTimeoutMinutes) * time.Minute
+	}
+	return DefaultModifyVolumeTimeout
+}
+
+// PVCsNeedingVolumeAttributesClass returns the names of the PVCs whose spec does not
+// request the class, sorted
+func PVCsNeedingVolumeAttributesClass(pvcs []corev1.PersistentVolumeClaim, className string) []string {
+	var names []string
+	for i := range pvcs {
+		if current := pvcs[i].Spec.VolumeAttributesClassName; current == nil || *current != className {
+			names = append(names, pvcs[i].Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// VolumeModificationPhaseOf returns the state of the modification of a PVC to the class,
+// from its status. Until the external resizer reports on the modification it is Pending
+func VolumeModificationPhaseOf(
+	pvc *corev1.PersistentVolumeClaim,
+	className string,
+) cnpgv1alpha1.VolumeModificationPhase {
+	if status := pvc.Status.ModifyVolumeStatus; status != nil && status.TargetVolumeAttributesClassName == className {
+		switch status.Status {
+		case corev1.PersistentVolumeClaimModifyVolumeInProgress:
+			return cnpgv1alpha1.VolumeModificationInProgress
+		case corev1.PersistentVolumeClaimModifyVolumeInfeasible:
+			return cnpgv1alpha1.VolumeModificationInfeasible
+		default:
+			return cnpgv1alpha1.VolumeModificationPending
+		}
+	}
+	if current := pvc.Status.CurrentVolumeAttributesClassName; current != nil && *current == className {
+		return cnpgv1alpha1.VolumeModificationCompleted
+	}
+	return cnpgv1alpha1.VolumeModificationPending
+}
+
+// CheckVolumeAttributesClass returns ErrVolumeAttributesClassUnsupported when the cluster
+// does not serve the VolumeAttributesClass API in any version, and an error when the
+// named class does not exist
+func CheckVolumeAttributesClass(ctx context.Context, c client.Client, name string) error {
+	mapping, err := c.RESTMapper().RESTMapping(volumeAttributesClassKind)
+	if meta.IsNoMatchError(err) {
+		return ErrVolumeAttributesClassUnsupported
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up the VolumeAttributesClass API: %w", err)
+	}
+
+	class := &metav1.PartialObjectMetadata{}
+	class.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, class); err != nil {
+		return fmt.Errorf("failed to get VolumeAttributesClass %s: %w", name, err)
+	}
+	return nil
+}
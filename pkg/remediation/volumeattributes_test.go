@@ -0,0 +1,193 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+func TestVolumeAttributesClassForLevel(t *testing.T) {
+	config := &cnpgv1alpha1.VolumeAttributesConfig{
+		Classes: []cnpgv1alpha1.VolumeAttributesClassThreshold{
+			{Threshold: "critical", VolumeAttributesClass: "fast"},
+			{Threshold: "normal", VolumeAttributesClass: "standard"},
+			{Threshold: "emergency", VolumeAttributesClass: "fastest"},
+		},
+	}
+	tests := []struct {
+		level             string
+		expectedClass     string
+		expectedThreshold string
+	}{
+		{"normal", "standard", "normal"},
+		{"warning", "standard", "normal"},
+		{"critical", "fast", "critical"},
+		{"expansion", "fast", "critical"},
+		{"emergency", "fastest", "emergency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			className, threshold := VolumeAttributesClassForLevel(config, tt.level)
+			if className != tt.expectedClass || threshold != tt.expectedThreshold {
+				t.Errorf("VolumeAttributesClassForLevel(%s) = %s, %s, expected %s, %s",
+					tt.level, className, threshold, tt.expectedClass, tt.expectedThreshold)
+			}
+		})
+	}
+
+	config.Classes = config.Classes[:1]
+	if className, _ := VolumeAttributesClassForLevel(config, "warning"); className != "" {
+		t.Errorf("expected no class below the lowest listed level, got %s", className)
+	}
+}
+
+func TestNewVolumeAttributesChangeDetails(t *testing.T) {
+	policy := &cnpgv1alpha1.StoragePolicy{}
+	if details := NewVolumeAttributesChangeDetails(policy, "critical"); details != nil {
+		t.Errorf("expected no details without volumeAttributes, got %+v", details)
+	}
+
+	policy.Spec.VolumeAttributes = &cnpgv1alpha1.VolumeAttributesConfig{
+		Classes: []cnpgv1alpha1.VolumeAttributesClassThreshold{
+			{Threshold: "critical", VolumeAttributesClass: "fast"},
+		},
+		ModifyTimeoutMinutes: 10,
+	}
+	details := NewVolumeAttributesChangeDetails(policy, "expansion")
+	if details == nil || details.VolumeAttributesClass != "fast" || details.Threshold != "critical" {
+		t.Fatalf("expected details for class fast, got %+v", details)
+	}
+	if timeout := ModifyVolumeTimeout(details); timeout != 10*time.Minute {
+		t.Errorf("expected a 10m timeout, got %s", timeout)
+	}
+	timeout := ModifyVolumeTimeout(&cnpgv1alpha1.VolumeAttributesChangeDetails{})
+	if timeout != DefaultModifyVolumeTimeout {
+		t.Errorf("expected the default timeout, got %s", timeout)
+	}
+}
+
+func TestPVCsNeedingVolumeAttributesClass(t *testing.T) {
+	fast := "fast"
+	standard := "standard"
+	withClass := func(name string, className *string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeAttributesClassName: className},
+		}
+	}
+	pvcs := []corev1.PersistentVolumeClaim{
+		withClass("pg-3", &standard),
+		withClass("pg-2", &fast),
+		withClass("pg-1", nil),
+	}
+	expected := []string{"pg-1", "pg-3"}
+	if names := PVCsNeedingVolumeAttributesClass(pvcs, "fast"); !reflect.DeepEqual(names, expected) {
+		t.Errorf("PVCsNeedingVolumeAttributesClass() = %v, expected %v", names, expected)
+	}
+}
+
+func TestVolumeModificationPhaseOf(t *testing.T) {
+	fast := "fast"
+	standard := "standard"
+	tests := []struct {
+		name     string
+		status   corev1.PersistentVolumeClaimStatus
+		expected cnpgv1alpha1.VolumeModificationPhase
+	}{
+		{"not picked up", corev1.PersistentVolumeClaimStatus{CurrentVolumeAttributesClassName: &standard},
+			cnpgv1alpha1.VolumeModificationPending},
+		{"in progress", corev1.PersistentVolumeClaimStatus{
+			CurrentVolumeAttributesClassName: &standard,
+			ModifyVolumeStatus: &corev1.ModifyVolumeStatus{
+				TargetVolumeAttributesClassName: fast,
+				Status:                          corev1.PersistentVolumeClaimModifyVolumeInProgress,
+			},
+		}, cnpgv1alpha1.VolumeModificationInProgress},
+		{"infeasible", corev1.PersistentVolumeClaimStatus{
+			ModifyVolumeStatus: &corev1.ModifyVolumeStatus{
+				TargetVolumeAttributesClassName: fast,
+				Status:                          corev1.PersistentVolumeClaimModifyVolumeInfeasible,
+			},
+		}, cnpgv1alpha1.VolumeModificationInfeasible},
+		{"pending class", corev1.PersistentVolumeClaimStatus{
+			ModifyVolumeStatus: &corev1.ModifyVolumeStatus{
+				TargetVolumeAttributesClassName: fast,
+				Status:                          corev1.PersistentVolumeClaimModifyVolumePending,
+			},
+		}, cnpgv1alpha1.VolumeModificationPending},
+		{"other target", corev1.PersistentVolumeClaimStatus{
+			CurrentVolumeAttributesClassName: &fast,
+			ModifyVolumeStatus: &corev1.ModifyVolumeStatus{
+				TargetVolumeAttributesClassName: standard,
+				Status:                          corev1.PersistentVolumeClaimModifyVolumeInfeasible,
+			},
+		}, cnpgv1alpha1.VolumeModificationCompleted},
+		{"completed", corev1.PersistentVolumeClaimStatus{CurrentVolumeAttributesClassName: &fast},
+			cnpgv1alpha1.VolumeModificationCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvc := &corev1.PersistentVolumeClaim{Status: tt.status}
+			if phase := VolumeModificationPhaseOf(pvc, fast); phase != tt.expected {
+				t.Errorf("VolumeModificationPhaseOf() = %s, expected %s", phase, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckVolumeAttributesClass(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := storagev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	class := &storagev1.VolumeAttributesClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "fast"},
+		DriverName: "ebs.csi.aws.com",
+	}
+	restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{storagev1.SchemeGroupVersion})
+	restMapper.Add(storagev1.SchemeGroupVersion.WithKind("VolumeAttributesClass"), meta.RESTScopeRoot)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(restMapper).WithObjects(class).Build()
+
+	if err := CheckVolumeAttributesClass(context.Background(), c, "fast"); err != nil {
+		t.Errorf("expected class fast to be found, got %v", err)
+	}
+	if err := CheckVolumeAttributesClass(context.Background(), c, "missing"); err == nil {
+		t.Error("expected an error for a missing class")
+	}
+
+	unsupported := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(meta.NewDefaultRESTMapper(nil)).Build()
+	err := CheckVolumeAttributesClass(context.Background(), unsupported, "fast")
+	if !errors.Is(err, ErrVolumeAttributesClassUnsupported) {
+		t.Errorf("expected ErrVolumeAttributesClassUnsupported, got %v", err)
+	}
+}
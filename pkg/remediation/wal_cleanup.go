@@ -17,7 +17,6 @@ limitations under the License.
 package remediation
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"path/filepath"
@@ -29,46 +28,69 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
 	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+	"github.com/supporttools/cnpg-storage-manager/pkg/runner"
 )
 
+const (
+	// pgdataDir is the PGDATA directory of CNPG instances
+	pgdataDir = "/var/lib/postgresql/data/pgdata"
+	// walStorageDir is the WAL directory of CNPG instances with a dedicated WAL volume
+	walStorageDir = "/var/lib/postgresql/wal/pg_wal"
+	// walStorageVolume is the name of the dedicated WAL volume in CNPG instance pods
+	walStorageVolume = "pg-wal"
+	// walHorizonQuery returns the current WAL file and the WAL file holding the REDO
+	// location of the latest checkpoint
+	walHorizonQuery = "SELECT pg_walfile_name(pg_current_wal_lsn()), pg_walfile_name(redo_lsn) " +
+		"FROM pg_control_checkpoint()"
+)
+
+// walFileName matches WAL segment file names: timeline, log and segment as 24 hex digits
+var walFileName = regexp.MustCompile(`^[0-9A-F]{24}$`)
+
 // WALCleanupEngine handles WAL file cleanup operations
 type WALCleanupEngine struct {
-	client     client.Client
-	restConfig *rest.Config
-	clientset  kubernetes.Interface
+	client client.Client
+	runner runner.CommandRunner
 }
 
-// NewWALCleanupEngine creates a new WAL cleanup engine
+// NewWALCleanupEngine creates a new WAL cleanup engine that runs commands via pod exec
 func NewWALCleanupEngine(c client.Client, restConfig *rest.Config) (*WALCleanupEngine, error) {
-	clientset, err := kubernetes.NewForConfig(restConfig)
+	execRunner, err := runner.New(runner.Config{Mode: runner.ModeExec, OnTimeout: metrics.RecordExecTimeout}, restConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
+		return nil, err
 	}
 
+	return NewWALCleanupEngineWithRunner(c, execRunner), nil
+}
+
+// NewWALCleanupEngineWithRunner creates a new WAL cleanup engine using the given command runner
+func NewWALCleanupEngineWithRunner(c client.Client, commandRunner runner.CommandRunner) *WALCleanupEngine {
 	return &WALCleanupEngine{
-		client:     c,
-		restConfig: restConfig,
-		clientset:  clientset,
-	}, nil
+		client: c,
+		runner: commandRunner,
+	}
 }
 
 // WALCleanupRequest represents a request to cleanup WAL files
 type WALCleanupRequest struct {
 	ClusterName      string
 	ClusterNamespace string
-	PrimaryPod       *corev1.Pod
-	Policy           *cnpgv1alpha1.StoragePolicy
-	Reason           string
-	DryRun           bool
+	// Pod is the instance whose WAL is cleaned up
+	Pod *corev1.Pod
+	// Replica applies the replica safety checks: only segments older than the
+	// latest restartpoint are removed
+	Replica bool
+	Policy  *cnpgv1alpha1.StoragePolicy
+	Reason  string
+	DryRun  bool
+	// Event is the StorageEvent the cleanup runs for, recorded as a metrics exemplar
+	Event string
 }
 
 // WALCleanupResult contains the result of a WAL cleanup operation
@@ -102,21 +124,42 @@ func (e *WALCleanupEngine) CleanupClusterWAL(ctx context.Context, req *WALCleanu
 	result := &WALCleanupResult{
 		ClusterName:      req.ClusterName,
 		ClusterNamespace: req.ClusterNamespace,
-		PodName:          req.PrimaryPod.Name,
+		PodName:          req.Pod.Name,
+	}
+
+	// Commands outside the policy's allowlist fail instead of running
+	e = &WALCleanupEngine{
+		client: e.client,
+		runner: runner.WithAllowlist(e.runner, req.Policy.Spec.WALCleanup.AllowedCommands),
 	}
 
 	logger.Info("Starting WAL cleanup",
 		"cluster", req.ClusterName,
 		"namespace", req.ClusterNamespace,
-		"pod", req.PrimaryPod.Name,
+		"pod", req.Pod.Name,
+		"replica", req.Replica,
 		"dryRun", req.DryRun,
 	)
 
-	// Get WAL directory path (default CNPG path)
-	walDir := "/var/lib/postgresql/data/pgdata/pg_wal"
+	dataDir := e.dataDirectory(ctx, req.Pod)
+	walDir := req.Policy.Spec.WALCleanup.WALDirectory
+	if walDir == "" {
+		walDir = walDirectory(req.Pod, dataDir)
+	}
+	logger.Info("Resolved WAL directory", "pod", req.Pod.Name, "dataDirectory", dataDir, "walDirectory", walDir)
+
+	// Only segments before the horizon are removed; anything from the latest
+	// checkpoint or restartpoint onwards may still be needed for crash recovery
+	horizon, err := e.removalHorizon(ctx, req, dataDir)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to determine WAL removal horizon: %v", err)
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+	logger.Info("WAL removal horizon", "pod", req.Pod.Name, "horizon", horizon)
 
 	// List WAL files
-	walFiles, err := e.listWALFiles(ctx, req.PrimaryPod, walDir)
+	walFiles, err := e.listWALFiles(ctx, req.Pod, walDir)
 	if err != nil {
 		result.Error = fmt.Sprintf("failed to list WAL files: %v", err)
 		result.Duration = time.Since(startTime)
@@ -128,7 +171,7 @@ func (e *WALCleanupEngine) CleanupClusterWAL(ctx context.Context, req *WALCleanu
 
 	// Get archived WAL status if required
 	if req.Policy.Spec.WALCleanup.RequireArchived {
-		archivedFiles, err := e.getArchivedWALStatus(ctx, req.PrimaryPod)
+		archivedFiles, err := e.getArchivedWALStatus(ctx, req.Pod, walDir)
 		if err != nil {
 			logger.Error(err, "Failed to get archived WAL status, proceeding with caution")
 		} else {
@@ -169,6 +212,9 @@ func (e *WALCleanupEngine) CleanupClusterWAL(ctx context.Context, req *WALCleanu
 		cutoffIndex := len(walFiles) - retainCount
 		for i := 0; i < cutoffIndex; i++ {
 			file := walFiles[i]
+			if !segmentBefore(file.Name, horizon) {
+				continue
+			}
 			// Only remove archived files
 			if file.IsArchived || !req.Policy.Spec.WALCleanup.RequireArchived {
 				filesToRemove = append(filesToRemove, file)
@@ -205,8 +251,7 @@ func (e *WALCleanupEngine) CleanupClusterWAL(ctx context.Context, req *WALCleanu
 
 	// Remove files
 	for _, file := range filesToRemove {
-		filePath := filepath.Join(walDir, file.Name)
-		if err := e.removeFile(ctx, req.PrimaryPod, filePath); err != nil {
+		if err := e.removeWALFile(ctx, req.Pod, walDir, file.Name); err != nil {
 			logger.Error(err, "Failed to remove WAL file", "file", file.Name)
 			continue
 		}
@@ -219,10 +264,10 @@ func (e *WALCleanupEngine) CleanupClusterWAL(ctx context.Context, req *WALCleanu
 
 	// Record metrics
 	if result.Success {
-		metrics.RecordWALCleanup(req.ClusterName, req.ClusterNamespace, "success")
+		metrics.RecordWALCleanup(req.ClusterName, req.ClusterNamespace, "success", req.Event)
 		metrics.WALFilesRemoved.WithLabelValues(req.ClusterName, req.ClusterNamespace).Add(float64(result.FilesRemoved))
 	} else {
-		metrics.RecordWALCleanup(req.ClusterName, req.ClusterNamespace, "failure")
+		metrics.RecordWALCleanup(req.ClusterName, req.ClusterNamespace, "failure", req.Event)
 	}
 
 	logger.Info("WAL cleanup completed",
@@ -236,110 +281,215 @@ func (e *WALCleanupEngine) CleanupClusterWAL(ctx context.Context, req *WALCleanu
 
 // listWALFiles lists WAL files in the specified directory
 func (e *WALCleanupEngine) listWALFiles(ctx context.Context, pod *corev1.Pod, walDir string) ([]WALFileInfo, error) {
-	// Execute command to list WAL files with their sizes
-	cmd := fmt.Sprintf("ls -la %s 2>/dev/null | grep -E '^-' | awk '{print $5, $9}'", walDir)
-	output, err := e.execInPod(ctx, pod, "postgres", []string{"sh", "-c", cmd})
+	output, err := e.execInPod(ctx, pod, []string{"ls", "-la", "--", walDir})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list WAL files: %w", err)
 	}
+	return parseWALListing(output), nil
+}
 
+// parseWALListing parses `ls -la` output into the WAL segment files it lists
+func parseWALListing(output string) []WALFileInfo {
 	var files []WALFileInfo
-	walFilePattern := regexp.MustCompile(`^[0-9A-F]{24}$`)
-
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		// Regular files only: mode, links, owner, group, size, date (3 fields), name
 		parts := strings.Fields(line)
-		if len(parts) != 2 {
+		if len(parts) != 9 || !strings.HasPrefix(parts[0], "-") {
 			continue
 		}
 
-		size, err := strconv.ParseInt(parts[0], 10, 64)
+		size, err := strconv.ParseInt(parts[4], 10, 64)
 		if err != nil {
 			continue
 		}
 
-		name := parts[1]
+		name := parts[8]
 		// Only include actual WAL files (24 hex characters)
-		if walFilePattern.MatchString(name) {
+		if walFileName.MatchString(name) {
 			files = append(files, WALFileInfo{
 				Name: name,
 				Size: size,
 			})
 		}
 	}
-
-	return files, nil
+	return files
 }
 
 // getArchivedWALStatus gets the list of archived WAL files
 //
 //nolint:unparam // error return kept for future extensibility
-func (e *WALCleanupEngine) getArchivedWALStatus(ctx context.Context, pod *corev1.Pod) ([]string, error) {
-	// Query PostgreSQL for the last archived WAL segment
-	cmd := "psql -At -c \"SELECT file_name FROM pg_ls_archive_statusdir() WHERE name LIKE '%.done' ORDER BY name;\""
-	output, err := e.execInPod(ctx, pod, "postgres", []string{"sh", "-c", cmd})
+func (e *WALCleanupEngine) getArchivedWALStatus(ctx context.Context, pod *corev1.Pod, walDir string) ([]string, error) {
+	// Read the archiver's .done markers directly so this works without a database
+	// connection, including from a runner Job that only mounts the volume
+	command := []string{"ls", "--", filepath.Join(walDir, "archive_status")}
+	output, err := e.execInPod(ctx, pod, command)
 	if err != nil {
 		// This might fail on some configurations, so return empty list
 		return nil, nil
 	}
 
 	var archived []string
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	for _, line := range lines {
-		name := strings.TrimSuffix(strings.TrimSpace(line), ".done")
-		if name != "" {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		name, ok := strings.CutSuffix(strings.TrimSpace(line), ".done")
+		if ok && walFileName.MatchString(name) {
 			archived = append(archived, name)
 		}
 	}
+	sort.Strings(archived)
 
 	return archived, nil
 }
 
-// removeFile removes a file from the pod
-func (e *WALCleanupEngine) removeFile(ctx context.Context, pod *corev1.Pod, filePath string) error {
-	cmd := fmt.Sprintf("rm -f %s", filePath)
-	_, err := e.execInPod(ctx, pod, "postgres", []string{"sh", "-c", cmd})
-	return err
+// dataDirectory returns the PGDATA directory of an instance pod. The running server
+// is asked first; when it cannot be reached (or the runner has no database access)
+// the PGDATA environment of the PostgreSQL container is used, then the CNPG default.
+func (e *WALCleanupEngine) dataDirectory(ctx context.Context, pod *corev1.Pod) string {
+	command := []string{"psql", "-X", "-A", "-t", "-q", "-d", "postgres", "-c", "SHOW data_directory"}
+	if output, err := e.execInPod(ctx, pod, command); err == nil {
+		if dir := strings.TrimSpace(output); filepath.IsAbs(dir) {
+			return filepath.Clean(dir)
+		}
+	}
+
+	if container := runner.PostgresContainer(pod); container != nil {
+		for _, env := range container.Env {
+			if env.Name == "PGDATA" && filepath.IsAbs(env.Value) {
+				return filepath.Clean(env.Value)
+			}
+		}
+	}
+	return pgdataDir
 }
 
-// execInPod executes a command in a pod container
-func (e *WALCleanupEngine) execInPod(
-	ctx context.Context,
-	pod *corev1.Pod,
-	container string,
-	command []string,
-) (string, error) {
-	req := e.clientset.CoreV1().RESTClient().Post().
-		Resource("pods").
-		Name(pod.Name).
-		Namespace(pod.Namespace).
-		SubResource("exec").
-		VersionedParams(&corev1.PodExecOptions{
-			Container: container,
-			Command:   command,
-			Stdout:    true,
-			Stderr:    true,
-		}, scheme.ParameterCodec)
-
-	exec, err := remotecommand.NewSPDYExecutor(e.restConfig, "POST", req.URL())
+// walDirectory returns the WAL directory of an instance pod: pg_wal on the dedicated
+// WAL volume when the cluster uses walStorage, pg_wal inside PGDATA otherwise.
+// pg_wal in PGDATA is only a symlink with walStorage, so the volume must be listed
+// directly.
+func walDirectory(pod *corev1.Pod, dataDir string) string {
+	if container := runner.PostgresContainer(pod); container != nil {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == walStorageVolume {
+				return filepath.Join(mount.MountPath, "pg_wal")
+			}
+		}
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == walStorageVolume {
+			return walStorageDir
+		}
+	}
+	return filepath.Join(dataDir, "pg_wal")
+}
+
+// removalHorizon returns the oldest WAL segment that must be kept. On a primary a
+// CHECKPOINT and pg_switch_wal first move the REDO location as far forward as
+// possible, so the most segments become removable; the horizon is then the earlier
+// of the current WAL file and the checkpoint's REDO WAL file. Replicas cannot
+// switch WAL, so their horizon is the latest restartpoint from the control file,
+// which is also the fallback when the primary cannot be queried.
+func (e *WALCleanupEngine) removalHorizon(ctx context.Context, req *WALCleanupRequest, dataDir string) (string, error) {
+	logger := log.FromContext(ctx)
+	if req.Replica {
+		return e.restartpointWALFile(ctx, req.Pod, dataDir)
+	}
+
+	if !req.DryRun {
+		if err := e.checkpointAndSwitchWAL(ctx, req.Pod); err != nil {
+			logger.Error(err, "Failed to checkpoint and switch WAL, continuing with the current horizon", "pod", req.Pod.Name)
+		}
+	}
+
+	horizon, err := e.primaryWALHorizon(ctx, req.Pod)
 	if err != nil {
-		return "", fmt.Errorf("failed to create executor: %w", err)
+		logger.Error(err, "Failed to query the WAL horizon, falling back to the control file", "pod", req.Pod.Name)
+		return e.restartpointWALFile(ctx, req.Pod, dataDir)
 	}
+	return horizon, nil
+}
 
-	var stdout, stderr bytes.Buffer
-	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
-		Stdout: &stdout,
-		Stderr: &stderr,
-	})
+// checkpointAndSwitchWAL forces a checkpoint and closes the current WAL segment so
+// it can be archived
+func (e *WALCleanupEngine) checkpointAndSwitchWAL(ctx context.Context, pod *corev1.Pod) error {
+	command := []string{
+		"psql", "-X", "-A", "-t", "-q", "-v", "ON_ERROR_STOP=1", "-d", "postgres",
+		"-c", "CHECKPOINT", "-c", "SELECT pg_switch_wal()",
+	}
+	if _, err := e.execInPod(ctx, pod, command); err != nil {
+		return fmt.Errorf("failed to run CHECKPOINT and pg_switch_wal: %w", err)
+	}
+	return nil
+}
 
+// primaryWALHorizon queries the removal horizon of a primary
+func (e *WALCleanupEngine) primaryWALHorizon(ctx context.Context, pod *corev1.Pod) (string, error) {
+	command := []string{"psql", "-X", "-A", "-t", "-q", "-d", "postgres", "-F", "|", "-c", walHorizonQuery}
+	output, err := e.execInPod(ctx, pod, command)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute command: %w, stderr: %s", err, stderr.String())
+		return "", fmt.Errorf("failed to query WAL horizon: %w", err)
+	}
+	return parseWALHorizon(output)
+}
+
+// parseWALHorizon parses the unaligned psql output of walHorizonQuery and returns
+// the earlier of the two WAL files
+func parseWALHorizon(output string) (string, error) {
+	current, redo, ok := strings.Cut(strings.TrimSpace(output), "|")
+	if !ok || !walFileName.MatchString(current) || !walFileName.MatchString(redo) {
+		return "", fmt.Errorf("unexpected WAL horizon output %q", output)
 	}
+	if segmentBefore(redo, current) {
+		return redo, nil
+	}
+	return current, nil
+}
+
+// restartpointWALFile returns the WAL segment holding the REDO location of the
+// latest checkpoint or restartpoint, read from the control file so no database
+// connection is needed
+func (e *WALCleanupEngine) restartpointWALFile(ctx context.Context, pod *corev1.Pod, dataDir string) (string, error) {
+	command := []string{"env", "LC_ALL=C", "pg_controldata", "-D", dataDir}
+	output, err := e.execInPod(ctx, pod, command)
+	if err != nil {
+		return "", fmt.Errorf("failed to run pg_controldata: %w", err)
+	}
+	return parseRedoWALFile(output)
+}
+
+// parseRedoWALFile extracts the REDO WAL file from pg_controldata output
+func parseRedoWALFile(output string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		label, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(label) != "Latest checkpoint's REDO WAL file" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if !walFileName.MatchString(value) {
+			return "", fmt.Errorf("unexpected REDO WAL file %q", value)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("REDO WAL file not found in pg_controldata output")
+}
+
+// segmentBefore reports whether WAL segment name precedes horizon. The timeline
+// prefix is ignored so segments of older timelines compare by position.
+func segmentBefore(name, horizon string) bool {
+	return name[8:] < horizon[8:]
+}
+
+// removeWALFile removes a WAL segment from the pod. The name is validated so only
+// a segment directly inside walDir can ever be removed.
+func (e *WALCleanupEngine) removeWALFile(ctx context.Context, pod *corev1.Pod, walDir, name string) error {
+	if !walFileName.MatchString(name) {
+		return fmt.Errorf("refusing to remove %q: not a WAL segment name", name)
+	}
+	_, err := e.execInPod(ctx, pod, []string{"rm", "-f", "--", filepath.Join(walDir, name)})
+	return err
+}
 
-	return stdout.String(), nil
+// execInPod executes a command in the PostgreSQL container of a pod
+func (e *WALCleanupEngine) execInPod(ctx context.Context, pod *corev1.Pod, command []string) (string, error) {
+	return e.runner.Run(ctx, pod, runner.PreferredContainer(pod), command)
 }
 
 // CreateWALCleanupEvent creates a StorageEvent for a WAL cleanup operation
@@ -353,8 +503,8 @@ func (e *WALCleanupEngine) CreateWALCleanupEvent(
 			GenerateName: fmt.Sprintf("%s-wal-cleanup-", req.ClusterName),
 			Namespace:    req.ClusterNamespace,
 			Labels: map[string]string{
-				"cnpg.supporttools.io/cluster":    req.ClusterName,
-				"cnpg.supporttools.io/event-type": string(cnpgv1alpha1.EventTypeWALCleanup),
+				LabelCluster:   req.ClusterName,
+				LabelEventType: string(cnpgv1alpha1.EventTypeWALCleanup),
 			},
 		},
 		Spec: cnpgv1alpha1.StorageEventSpec{
@@ -402,7 +552,7 @@ func (e *WALCleanupEngine) UpdateWALCleanupEventStatus(
 
 	event.Status.CompletionTime = &now
 	event.Status.Message = fmt.Sprintf("WAL cleanup: %d files removed, %s freed",
-		result.FilesRemoved, formatBytes(result.BytesFreed))
+		result.FilesRemoved, FormatBytes(result.BytesFreed))
 
 	return e.client.Status().Update(ctx, event)
 }
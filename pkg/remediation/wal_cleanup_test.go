@@ -17,6 +17,9 @@ limitations under the License.
 package remediation
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -52,7 +55,7 @@ func TestWALCleanupRequest_Fields(t *testing.T) {
 	request := &WALCleanupRequest{
 		ClusterName:      "test-cluster",
 		ClusterNamespace: "default",
-		PrimaryPod:       pod,
+		Pod:              pod,
 		Policy:           policy,
 		Reason:           "Emergency threshold breach",
 		DryRun:           false,
@@ -64,7 +67,7 @@ func TestWALCleanupRequest_Fields(t *testing.T) {
 	if request.ClusterNamespace != "default" {
 		t.Errorf("expected namespace 'default', got '%s'", request.ClusterNamespace)
 	}
-	if request.PrimaryPod == nil {
+	if request.Pod == nil {
 		t.Error("expected primary pod to be set")
 	}
 	if request.Policy == nil {
@@ -321,3 +324,338 @@ func TestWALCleanupDuration(t *testing.T) {
 		t.Errorf("expected duration around 2.5 seconds, got %v", result.Duration)
 	}
 }
+
+// lsLine formats a 16MiB file as listed by `ls -la`
+const lsLine = "-rw------- 1 postgres postgres 16777216 Jun  1 10:00 %s\n"
+
+// scriptedRunner answers commands by the first matching substring and records them
+type scriptedRunner struct {
+	responses map[string]string
+	commands  []string
+}
+
+func (s *scriptedRunner) Run(_ context.Context, _ *corev1.Pod, _ string, command []string) (string, error) {
+	cmd := strings.Join(command, " ")
+	s.commands = append(s.commands, cmd)
+	for match, output := range s.responses {
+		if strings.Contains(cmd, match) {
+			return output, nil
+		}
+	}
+	return "", nil
+}
+
+func TestCleanupClusterWAL_Replica(t *testing.T) {
+	var listing strings.Builder
+	for seg := 1; seg <= 20; seg++ {
+		fmt.Fprintf(&listing, lsLine, fmt.Sprintf("0000000100000000000000%02X", seg))
+	}
+	scripted := &scriptedRunner{responses: map[string]string{
+		"pg_controldata": "Latest checkpoint location:           0/E000060\n" +
+			"Latest checkpoint's REDO WAL file:    00000001000000000000000E\n",
+		"ls -la": listing.String(),
+	}}
+	engine := NewWALCleanupEngineWithRunner(nil, scripted)
+
+	replica := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg-2", Namespace: "db"},
+		Spec:       corev1.PodSpec{Volumes: []corev1.Volume{{Name: walStorageVolume}}},
+	}
+	policy := &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{
+		WALCleanup: cnpgv1alpha1.WALCleanupConfig{Enabled: true, RetainCount: 2},
+	}}
+
+	result, err := engine.CleanupClusterWAL(context.Background(), &WALCleanupRequest{
+		ClusterName:      "pg",
+		ClusterNamespace: "db",
+		Pod:              replica,
+		Replica:          true,
+		Policy:           policy,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Segments 0x01-0x0D precede the restartpoint; retainCount alone would allow 18
+	if result.FilesRemoved != 13 {
+		t.Errorf("expected 13 files removed, got %d", result.FilesRemoved)
+	}
+	for _, cmd := range scripted.commands {
+		if strings.Contains(cmd, "rm -f") && !strings.Contains(cmd, walStorageDir) {
+			t.Errorf("expected removal from the WAL volume, got %q", cmd)
+		}
+		if strings.Contains(cmd, "rm -f") && strings.Contains(cmd, "00000001000000000000000E") {
+			t.Errorf("expected the restartpoint segment to be kept, got %q", cmd)
+		}
+	}
+}
+
+func TestCleanupClusterWAL_PrimaryHorizon(t *testing.T) {
+	var listing strings.Builder
+	for seg := 1; seg <= 20; seg++ {
+		fmt.Fprintf(&listing, lsLine, fmt.Sprintf("0000000100000000000000%02X", seg))
+	}
+	scripted := &scriptedRunner{responses: map[string]string{
+		"pg_walfile_name": "000000010000000000000012|00000001000000000000000C\n",
+		"ls -la":          listing.String(),
+	}}
+	engine := NewWALCleanupEngineWithRunner(nil, scripted)
+	policy := &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{
+		WALCleanup: cnpgv1alpha1.WALCleanupConfig{Enabled: true, RetainCount: 2},
+	}}
+
+	result, err := engine.CleanupClusterWAL(context.Background(), &WALCleanupRequest{
+		ClusterName:      "pg",
+		ClusterNamespace: "db",
+		Pod:              &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Namespace: "db"}},
+		Policy:           policy,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Segments 0x01-0x0B precede the checkpoint REDO file
+	if result.FilesRemoved != 11 {
+		t.Errorf("expected 11 files removed, got %d", result.FilesRemoved)
+	}
+
+	switched := -1
+	for i, cmd := range scripted.commands {
+		if strings.Contains(cmd, "CHECKPOINT") && strings.Contains(cmd, "pg_switch_wal") {
+			switched = i
+		}
+		if strings.Contains(cmd, "ls -la") && (switched < 0 || switched > i) {
+			t.Error("expected CHECKPOINT and pg_switch_wal before listing WAL files")
+		}
+	}
+}
+
+func TestCleanupClusterWAL_AllowedCommands(t *testing.T) {
+	scripted := &scriptedRunner{responses: map[string]string{
+		"ls -la": fmt.Sprintf(lsLine, "000000010000000000000001") +
+			fmt.Sprintf(lsLine, "000000010000000000000002"),
+		"pg_controldata": "Latest checkpoint's REDO WAL file:    000000010000000000000003\n",
+	}}
+	engine := NewWALCleanupEngineWithRunner(nil, scripted)
+	policy := &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{
+		WALCleanup: cnpgv1alpha1.WALCleanupConfig{
+			Enabled:         true,
+			RetainCount:     1,
+			AllowedCommands: []string{"ls", "pg_controldata"},
+		},
+	}}
+
+	result, err := engine.CleanupClusterWAL(context.Background(), &WALCleanupRequest{
+		ClusterName:      "pg",
+		ClusterNamespace: "db",
+		Pod:              &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Namespace: "db"}},
+		Policy:           policy,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.FilesRemoved != 0 || result.Success {
+		t.Errorf("expected no files removed without rm, got %d", result.FilesRemoved)
+	}
+	for _, cmd := range scripted.commands {
+		if strings.HasPrefix(cmd, "rm") || strings.HasPrefix(cmd, "psql") {
+			t.Errorf("expected only allowlisted commands to run, got %q", cmd)
+		}
+	}
+}
+
+func TestRemoveWALFile_RejectsInvalidNames(t *testing.T) {
+	scripted := &scriptedRunner{}
+	engine := NewWALCleanupEngineWithRunner(nil, scripted)
+
+	for _, name := range []string{"../../PG_VERSION", "000000010000000000000001; rm -rf /", "archive_status", ""} {
+		if err := engine.removeWALFile(context.Background(), &corev1.Pod{}, "/pgdata/pg_wal", name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+	if len(scripted.commands) != 0 {
+		t.Errorf("expected no commands, got %v", scripted.commands)
+	}
+
+	err := engine.removeWALFile(context.Background(), &corev1.Pod{}, "/pgdata/pg_wal", "000000010000000000000001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "rm -f -- /pgdata/pg_wal/000000010000000000000001"
+	if len(scripted.commands) != 1 || scripted.commands[0] != want {
+		t.Errorf("expected %q, got %v", want, scripted.commands)
+	}
+}
+
+func TestParseWALListing(t *testing.T) {
+	output := "total 32772\n" +
+		"drwx------ 3 postgres postgres     4096 Jun  1 10:00 .\n" +
+		"drwx------ 3 postgres postgres     4096 Jun  1 10:00 ..\n" +
+		"-rw------- 1 postgres postgres 16777216 Jun  1 10:00 000000010000000000000001\n" +
+		"-rw------- 1 postgres postgres      312 Jun  1 10:00 00000002.history\n" +
+		"drwx------ 2 postgres postgres     4096 Jun  1 10:00 archive_status\n" +
+		"-rw------- 1 postgres postgres  8388608 Jun  1 10:01 000000010000000000000002\n"
+
+	files := parseWALListing(output)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 WAL files, got %+v", files)
+	}
+	if files[0].Name != "000000010000000000000001" || files[0].Size != 16777216 {
+		t.Errorf("unexpected first file %+v", files[0])
+	}
+	if files[1].Name != "000000010000000000000002" || files[1].Size != 8388608 {
+		t.Errorf("unexpected second file %+v", files[1])
+	}
+}
+
+func TestParseWALHorizon(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "redo before current",
+			output: "000000010000000000000012|00000001000000000000000C\n",
+			want:   "00000001000000000000000C",
+		},
+		{name: "same file", output: "000000010000000000000003|000000010000000000000003", want: "000000010000000000000003"},
+		{name: "missing column", output: "000000010000000000000003\n", wantErr: true},
+		{name: "error output", output: "ERROR:  recovery is in progress", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWALHorizon(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseRedoWALFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "pg_controldata output",
+			output: "Latest checkpoint's REDO location:    0/3000028\n" +
+				"Latest checkpoint's REDO WAL file:    000000020000000A00000003\n",
+			want: "000000020000000A00000003",
+		},
+		{name: "missing line", output: "pg_control version number: 1300\n", wantErr: true},
+		{name: "malformed file", output: "Latest checkpoint's REDO WAL file:    garbage\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRedoWALFile(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWALDirectory(t *testing.T) {
+	if dir := walDirectory(&corev1.Pod{}, pgdataDir); dir != "/var/lib/postgresql/data/pgdata/pg_wal" {
+		t.Errorf("expected the PGDATA WAL directory, got %s", dir)
+	}
+	if dir := walDirectory(&corev1.Pod{}, "/srv/pgdata"); dir != "/srv/pgdata/pg_wal" {
+		t.Errorf("expected the custom PGDATA WAL directory, got %s", dir)
+	}
+	withWALStorage := &corev1.Pod{Spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "pgdata"}, {Name: "pg-wal"}}}}
+	if dir := walDirectory(withWALStorage, pgdataDir); dir != "/var/lib/postgresql/wal/pg_wal" {
+		t.Errorf("expected the WAL volume directory, got %s", dir)
+	}
+	withWALMount := &corev1.Pod{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name:         "postgres",
+			VolumeMounts: []corev1.VolumeMount{{Name: "pg-wal", MountPath: "/mnt/wal"}},
+		}},
+		Volumes: []corev1.Volume{{Name: "pg-wal"}},
+	}}
+	if dir := walDirectory(withWALMount, pgdataDir); dir != "/mnt/wal/pg_wal" {
+		t.Errorf("expected the mounted WAL volume directory, got %s", dir)
+	}
+}
+
+func TestDataDirectory(t *testing.T) {
+	withEnv := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name: "postgres",
+		Env:  []corev1.EnvVar{{Name: "PGDATA", Value: "/env/pgdata"}},
+	}}}}
+
+	tests := []struct {
+		name   string
+		output string
+		pod    *corev1.Pod
+		want   string
+	}{
+		{name: "server setting", output: "/srv/pgdata\n", pod: withEnv, want: "/srv/pgdata"},
+		{name: "PGDATA environment", output: "", pod: withEnv, want: "/env/pgdata"},
+		{name: "default", output: "psql: error: connection refused", pod: &corev1.Pod{}, want: pgdataDir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewWALCleanupEngineWithRunner(nil, &scriptedRunner{responses: map[string]string{
+				"SHOW data_directory": tt.output,
+			}})
+			if got := engine.dataDirectory(context.Background(), tt.pod); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCleanupClusterWAL_DirectoryOverride(t *testing.T) {
+	scripted := &scriptedRunner{responses: map[string]string{
+		"ls -la": fmt.Sprintf(lsLine, "000000010000000000000001") +
+			fmt.Sprintf(lsLine, "000000010000000000000002"),
+		"pg_walfile_name": "000000010000000000000003|000000010000000000000003\n",
+	}}
+	engine := NewWALCleanupEngineWithRunner(nil, scripted)
+	policy := &cnpgv1alpha1.StoragePolicy{Spec: cnpgv1alpha1.StoragePolicySpec{
+		WALCleanup: cnpgv1alpha1.WALCleanupConfig{Enabled: true, RetainCount: 1, WALDirectory: "/custom/pg_wal"},
+	}}
+
+	result, err := engine.CleanupClusterWAL(context.Background(), &WALCleanupRequest{
+		ClusterName:      "pg",
+		ClusterNamespace: "db",
+		Pod:              &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pg-1", Namespace: "db"}},
+		Policy:           policy,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.FilesRemoved != 1 {
+		t.Errorf("expected 1 file removed, got %d", result.FilesRemoved)
+	}
+	for _, cmd := range scripted.commands {
+		if (strings.Contains(cmd, "ls -la") || strings.Contains(cmd, "rm -f")) && !strings.Contains(cmd, "/custom/pg_wal") {
+			t.Errorf("expected the overridden WAL directory, got %q", cmd)
+		}
+	}
+}
+
+func TestSegmentBefore(t *testing.T) {
+	horizon := "00000002000000000000000E"
+	if !segmentBefore("00000001000000000000000D", horizon) {
+		t.Error("expected an older segment of an older timeline to precede the horizon")
+	}
+	if segmentBefore("00000001000000000000000E", horizon) {
+		t.Error("expected the horizon segment itself to be kept")
+	}
+}
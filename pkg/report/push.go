@@ -0,0 +1,218 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/identity"
+	"github.com/supporttools/cnpg-storage-manager/pkg/metrics"
+)
+
+const (
+	// DefaultPushInterval is the default interval between status pushes
+	DefaultPushInterval = 5 * time.Minute
+	// EnvPushToken is the environment variable holding the bearer token for status pushes
+	EnvPushToken = "STATUS_PUSH_TOKEN"
+	// eventSummaryWindow is how far back StorageEvents are counted in a fleet summary
+	eventSummaryWindow = 24 * time.Hour
+)
+
+// FleetSummary is the document pushed to a central dashboard: the report of every
+// managed cluster, fleet totals and the recent StorageEvents
+type FleetSummary struct {
+	ClusterID   string `json:"clusterID,omitempty"`
+	ClusterName string `json:"clusterName,omitempty"`
+	Report
+	Totals FleetTotals  `json:"totals"`
+	Events EventSummary `json:"events"`
+}
+
+// FleetTotals aggregates the clusters of a report
+type FleetTotals struct {
+	Clusters      int   `json:"clusters"`
+	CapacityBytes int64 `json:"capacityBytes"`
+	UsedBytes     int64 `json:"usedBytes"`
+	// ByStatus counts clusters by their StoragePolicy status
+	ByStatus map[string]int `json:"byStatus,omitempty"`
+	// ByBackupHealth counts clusters by their backup health
+	ByBackupHealth map[string]int `json:"byBackupHealth,omitempty"`
+}
+
+// EventSummary counts the StorageEvents created since a point in time
+type EventSummary struct {
+	Since   time.Time      `json:"since"`
+	ByType  map[string]int `json:"byType,omitempty"`
+	ByPhase map[string]int `json:"byPhase,omitempty"`
+}
+
+// PushConfig configures the status pusher
+type PushConfig struct {
+	// Endpoint is the URL fleet summaries are POSTed to
+	Endpoint string
+	// Token is sent as a bearer token when set
+	Token string
+	// Interval is the time between pushes. Defaults to DefaultPushInterval.
+	Interval time.Duration
+	// Identity identifies the Kubernetes cluster the summaries come from
+	Identity identity.ClusterIdentity
+}
+
+// Pusher periodically pushes fleet summaries to a central endpoint, so operators of
+// many Kubernetes clusters get a consolidated view without scraping each one
+type Pusher struct {
+	client     client.Reader
+	config     PushConfig
+	httpClient *http.Client
+}
+
+// NewPusher creates a status pusher reading policies and events through the client
+func NewPusher(c client.Reader, cfg PushConfig) *Pusher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultPushInterval
+	}
+	return &Pusher{
+		client:     c,
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Start pushes a summary immediately and then every interval until the context is
+// done. Failed pushes are logged and counted; the next push is attempted as scheduled.
+func (p *Pusher) Start(ctx context.Context) error {
+	logger := logf.FromContext(ctx).WithName("status-push")
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Push(ctx, time.Now()); err != nil {
+			logger.Error(err, "Failed to push fleet summary", "endpoint", p.config.Endpoint)
+			metrics.RecordStatusPush("failure")
+		} else {
+			metrics.RecordStatusPush("success")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Push builds a fleet summary and POSTs it to the endpoint
+func (p *Pusher) Push(ctx context.Context, now time.Time) error {
+	summary, err := p.Summarize(ctx, now)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fleet summary: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create status push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.Token)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send status push: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status push endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Summarize builds the fleet summary from the policies and StorageEvents
+func (p *Pusher) Summarize(ctx context.Context, now time.Time) (FleetSummary, error) {
+	report, err := Generate(ctx, p.client, now)
+	if err != nil {
+		return FleetSummary{}, err
+	}
+	var events cnpgv1alpha1.StorageEventList
+	if err := p.client.List(ctx, &events); err != nil {
+		return FleetSummary{}, fmt.Errorf("failed to list StorageEvents: %w", err)
+	}
+
+	return FleetSummary{
+		ClusterID:   p.config.Identity.ID,
+		ClusterName: p.config.Identity.Name,
+		Report:      report,
+		Totals:      totals(report),
+		Events:      summarizeEvents(events.Items, now.Add(-eventSummaryWindow)),
+	}, nil
+}
+
+// totals aggregates the clusters of a report
+func totals(report Report) FleetTotals {
+	t := FleetTotals{
+		Clusters:       len(report.Clusters),
+		ByStatus:       map[string]int{},
+		ByBackupHealth: map[string]int{},
+	}
+	for _, c := range report.Clusters {
+		t.CapacityBytes += c.CapacityBytes
+		t.UsedBytes += c.UsedBytes
+		if c.Status != "" {
+			t.ByStatus[c.Status]++
+		}
+		if c.BackupHealth != "" {
+			t.ByBackupHealth[c.BackupHealth]++
+		}
+	}
+	return t
+}
+
+// summarizeEvents counts the events created at or after since by type and phase
+func summarizeEvents(events []cnpgv1alpha1.StorageEvent, since time.Time) EventSummary {
+	summary := EventSummary{
+		Since:   since,
+		ByType:  map[string]int{},
+		ByPhase: map[string]int{},
+	}
+	for _, event := range events {
+		if event.CreationTimestamp.Time.Before(since) {
+			continue
+		}
+		summary.ByType[string(event.Spec.EventType)]++
+		phase := event.Status.Phase
+		if phase == "" {
+			phase = cnpgv1alpha1.EventPhasePending
+		}
+		summary.ByPhase[string(phase)]++
+	}
+	return summary
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+	"github.com/supporttools/cnpg-storage-manager/pkg/identity"
+)
+
+func testEvent(name string, age time.Duration, eventType cnpgv1alpha1.EventType,
+	phase cnpgv1alpha1.EventPhase) *cnpgv1alpha1.StorageEvent {
+	return &cnpgv1alpha1.StorageEvent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "db", CreationTimestamp: metav1.NewTime(now.Add(-age)),
+		},
+		Spec:   cnpgv1alpha1.StorageEventSpec{EventType: eventType},
+		Status: cnpgv1alpha1.StorageEventStatus{Phase: phase},
+	}
+}
+
+func TestPusher_Push(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := cnpgv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	storagePolicies, backupPolicies := testPolicies()
+	objects := []client.Object{
+		&storagePolicies[1],
+		&backupPolicies[0],
+		testEvent("expand", time.Hour, cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.EventPhaseCompleted),
+		testEvent("cleanup", 2*time.Hour, cnpgv1alpha1.EventTypeWALCleanup, cnpgv1alpha1.EventPhaseFailed),
+		testEvent("pending", time.Minute, cnpgv1alpha1.EventTypeExpansion, ""),
+		testEvent("old", 48*time.Hour, cnpgv1alpha1.EventTypeExpansion, cnpgv1alpha1.EventPhaseCompleted),
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+		WithStatusSubresource(&cnpgv1alpha1.StoragePolicy{}, &cnpgv1alpha1.BackupPolicy{}).Build()
+
+	var summary FleetSummary
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+			t.Errorf("failed to decode the pushed summary: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pusher := NewPusher(c, PushConfig{
+		Endpoint: server.URL,
+		Token:    "secret",
+		Identity: identity.ClusterIdentity{ID: "c-m-1234", Name: "customer-a"},
+	})
+	if err := pusher.Push(context.Background(), now); err != nil {
+		t.Fatalf("expected the push to succeed, got %v", err)
+	}
+
+	if authorization != "Bearer secret" {
+		t.Errorf("expected the bearer token, got %q", authorization)
+	}
+	if summary.ClusterID != "c-m-1234" || summary.ClusterName != "customer-a" {
+		t.Errorf("expected the cluster identity, got %q/%q", summary.ClusterID, summary.ClusterName)
+	}
+	totals := summary.Totals
+	if totals.Clusters != 3 || len(summary.Clusters) != 3 || totals.CapacityBytes != 10<<30 {
+		t.Errorf("expected 3 clusters with 10Gi of capacity, got %+v", totals)
+	}
+	if totals.ByBackupHealth["Critical"] != 1 || totals.ByStatus["Healthy"] != 2 {
+		t.Errorf("unexpected cluster counts, got %+v", totals)
+	}
+	events := summary.Events
+	if events.ByType["expansion"] != 2 || events.ByType["wal-cleanup"] != 1 {
+		t.Errorf("expected the events of the last 24 hours by type, got %v", events.ByType)
+	}
+	if events.ByPhase["Pending"] != 1 || events.ByPhase["Completed"] != 1 || events.ByPhase["Failed"] != 1 {
+		t.Errorf("expected the events of the last 24 hours by phase, got %v", events.ByPhase)
+	}
+}
+
+func TestPusher_PushError(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := cnpgv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if err := NewPusher(c, PushConfig{Endpoint: server.URL}).Push(context.Background(), now); err == nil {
+		t.Error("expected an error for a rejected push")
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package report builds a fleet-wide storage and backup report of the CNPG clusters
+// managed by StoragePolicies and BackupPolicies, for capacity planning.
+package report
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+const (
+	// FormatJSON renders the report as a JSON document
+	FormatJSON = "json"
+	// FormatCSV renders the report as CSV with one row per cluster
+	FormatCSV = "csv"
+)
+
+// Report is the storage and backup state of every managed cluster
+type Report struct {
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Clusters    []ClusterReport `json:"clusters"`
+}
+
+// ClusterReport is the storage and backup state of a single cluster
+type ClusterReport struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Connection is the ClusterConnection the cluster is reached through, empty for local clusters
+	Connection    string `json:"connection,omitempty"`
+	StoragePolicy string `json:"storagePolicy,omitempty"`
+	BackupPolicy  string `json:"backupPolicy,omitempty"`
+
+	Status        string `json:"status,omitempty"`
+	CapacityBytes int64  `json:"capacityBytes"`
+	UsedBytes     int64  `json:"usedBytes"`
+	UsagePercent  int32  `json:"usagePercent"`
+	// GrowthBytesPerHour is the baseline growth rate of anomaly detection, or the recent
+	// rate while the baseline window has too few samples
+	GrowthBytesPerHour   int64 `json:"growthBytesPerHour"`
+	ExpansionsLast30Days int32 `json:"expansionsLast30Days"`
+
+	BackupHealth   string     `json:"backupHealth,omitempty"`
+	LastBackupTime *time.Time `json:"lastBackupTime,omitempty"`
+
+	// HealthScore is the StoragePolicy's 0-100 storage health score of the cluster
+	HealthScore *int32 `json:"healthScore,omitempty"`
+
+	// PoolerVolumes are the PVCs mounted by the cluster's Pooler pods. They are not
+	// part of the cluster's capacity and usage
+	PoolerVolumes []cnpgv1alpha1.PoolerVolumeStatus `json:"poolerVolumes,omitempty"`
+}
+
+// csvHeader are the CSV columns, in the order of csvRow
+var csvHeader = []string{
+	"namespace", "name", "connection", "storage_policy", "backup_policy", "status",
+	"capacity_bytes", "used_bytes", "usage_percent", "growth_bytes_per_hour", "expansions_30d",
+	"backup_health", "last_backup_time", "health_score", "pooler_usage_percent",
+}
+
+// Build assembles a report from the status of StoragePolicies and BackupPolicies. A
+// cluster matched by several policies of a kind is reported with the first by
+// namespace and name.
+func Build(
+	storagePolicies []cnpgv1alpha1.StoragePolicy,
+	backupPolicies []cnpgv1alpha1.BackupPolicy,
+	now time.Time,
+) Report {
+	storagePolicies = slices.SortedFunc(slices.Values(storagePolicies), func(a, b cnpgv1alpha1.StoragePolicy) int {
+		return strings.Compare(policyKey(a.Namespace, a.Name), policyKey(b.Namespace, b.Name))
+	})
+	backupPolicies = slices.SortedFunc(slices.Values(backupPolicies), func(a, b cnpgv1alpha1.BackupPolicy) int {
+		return strings.Compare(policyKey(a.Namespace, a.Name), policyKey(b.Namespace, b.Name))
+	})
+
+	clusters := make(map[string]*ClusterReport)
+	get := func(connection, namespace, name string) *ClusterReport {
+		key := connection + "/" + namespace + "/" + name
+		if c, ok := clusters[key]; ok {
+			return c
+		}
+		c := &ClusterReport{Namespace: namespace, Name: name, Connection: connection}
+		clusters[key] = c
+		return c
+	}
+
+	for _, sp := range storagePolicies {
+		for _, mc := range sp.Status.ManagedClusters {
+			c := get(mc.Connection, mc.Namespace, mc.Name)
+			if c.StoragePolicy != "" {
+				continue
+			}
+			c.StoragePolicy = policyKey(sp.Namespace, sp.Name)
+			c.Status = mc.Status
+			c.CapacityBytes = mc.CapacityBytes
+			c.UsedBytes = mc.UsedBytes
+			c.UsagePercent = mc.UsagePercent
+			c.HealthScore = mc.HealthScore
+			c.PoolerVolumes = mc.PoolerVolumes
+			if mc.Growth != nil {
+				c.GrowthBytesPerHour = mc.Growth.BaselineBytesPerHour
+				if c.GrowthBytesPerHour == 0 {
+					c.GrowthBytesPerHour = mc.Growth.RecentBytesPerHour
+				}
+			}
+			if mc.ExpansionHistory != nil {
+				c.ExpansionsLast30Days = mc.ExpansionHistory.ExpansionsLast30Days
+			}
+			if mc.BackupStatus != nil {
+				c.BackupHealth = mc.BackupStatus.BackupHealthStatus
+				if mc.BackupStatus.LastBackupTime != nil {
+					t := mc.BackupStatus.LastBackupTime.UTC()
+					c.LastBackupTime = &t
+				}
+			}
+		}
+	}
+
+	// BackupPolicies evaluate backups in more depth than StoragePolicy backupMonitoring
+	for _, bp := range backupPolicies {
+		for _, health := range bp.Status.Clusters {
+			c := get("", health.Namespace, health.Name)
+			if c.BackupPolicy != "" {
+				continue
+			}
+			c.BackupPolicy = policyKey(bp.Namespace, bp.Name)
+			c.BackupHealth = string(health.Health)
+			c.LastBackupTime = nil
+			if health.LastBackupTime != nil {
+				t := health.LastBackupTime.UTC()
+				c.LastBackupTime = &t
+			}
+		}
+	}
+
+	report := Report{GeneratedAt: now.UTC(), Clusters: make([]ClusterReport, 0, len(clusters))}
+	for _, c := range clusters {
+		report.Clusters = append(report.Clusters, *c)
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		a, b := report.Clusters[i], report.Clusters[j]
+		if a.Connection != b.Connection {
+			return a.Connection < b.Connection
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return report
+}
+
+// Generate lists all StoragePolicies and BackupPolicies and builds a report from them
+func Generate(ctx context.Context, c client.Reader, now time.Time) (Report, error) {
+	var storagePolicies cnpgv1alpha1.StoragePolicyList
+	if err := c.List(ctx, &storagePolicies); err != nil {
+		return Report{}, fmt.Errorf("failed to list StoragePolicies: %w", err)
+	}
+	var backupPolicies cnpgv1alpha1.BackupPolicyList
+	if err := c.List(ctx, &backupPolicies); err != nil {
+		return Report{}, fmt.Errorf("failed to list BackupPolicies: %w", err)
+	}
+	return Build(storagePolicies.Items, backupPolicies.Items, now), nil
+}
+
+// Write renders the report in the given format
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, c := range r.Clusters {
+			if err := writer.Write(csvRow(c)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported report format %q, expected %s or %s", format, FormatJSON, FormatCSV)
+	}
+}
+
+// csvRow renders a cluster as a CSV row matching csvHeader
+func csvRow(c ClusterReport) []string {
+	lastBackup := ""
+	if c.LastBackupTime != nil {
+		lastBackup = c.LastBackupTime.Format(time.RFC3339)
+	}
+	healthScore := ""
+	if c.HealthScore != nil {
+		healthScore = strconv.Itoa(int(*c.HealthScore))
+	}
+	poolerUsage := ""
+	if len(c.PoolerVolumes) > 0 {
+		fullest := slices.MaxFunc(c.PoolerVolumes, func(a, b cnpgv1alpha1.PoolerVolumeStatus) int {
+			return cmp.Compare(a.UsagePercent, b.UsagePercent)
+		})
+		poolerUsage = strconv.Itoa(int(fullest.UsagePercent))
+	}
+	return []string{
+		c.Namespace, c.Name, c.Connection, c.StoragePolicy, c.BackupPolicy, c.Status,
+		strconv.FormatInt(c.CapacityBytes, 10),
+		strconv.FormatInt(c.UsedBytes, 10),
+		strconv.Itoa(int(c.UsagePercent)),
+		strconv.FormatInt(c.GrowthBytesPerHour, 10),
+		strconv.Itoa(int(c.ExpansionsLast30Days)),
+		c.BackupHealth, lastBackup, healthScore, poolerUsage,
+	}
+}
+
+// Handler serves the report, as JSON by default or as CSV with ?format=csv
+func Handler(c client.Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		format := req.URL.Query().Get("format")
+		if format == "" {
+			format = FormatJSON
+		}
+		contentType := "application/json"
+		switch format {
+		case FormatJSON:
+		case FormatCSV:
+			contentType = "text/csv"
+			w.Header().Set("Content-Disposition", `attachment; filename="cnpg-storage-report.csv"`)
+		default:
+			http.Error(w, fmt.Sprintf("unsupported format %q, expected json or csv", format), http.StatusBadRequest)
+			return
+		}
+
+		report, err := Generate(req.Context(), c, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		_ = report.Write(w, format)
+	})
+}
+
+func policyKey(namespace, name string) string {
+	return namespace + "/" + name
+}
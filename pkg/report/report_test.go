@@ -0,0 +1,195 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cnpgv1alpha1 "github.com/supporttools/cnpg-storage-manager/api/v1alpha1"
+)
+
+var now = time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+
+func testPolicies() ([]cnpgv1alpha1.StoragePolicy, []cnpgv1alpha1.BackupPolicy) {
+	lastBackup := metav1.NewTime(now.Add(-6 * time.Hour))
+	storagePolicies := []cnpgv1alpha1.StoragePolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "z-overlap", Namespace: "db"},
+			Status: cnpgv1alpha1.StoragePolicyStatus{ManagedClusters: []cnpgv1alpha1.ManagedCluster{
+				{Name: "pg-a", Namespace: "db", Status: "Critical", UsagePercent: 95},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "storage", Namespace: "db"},
+			Status: cnpgv1alpha1.StoragePolicyStatus{ManagedClusters: []cnpgv1alpha1.ManagedCluster{
+				{
+					Name: "pg-a", Namespace: "db", Status: "Healthy",
+					UsagePercent: 50, UsedBytes: 5 << 30, CapacityBytes: 10 << 30, HealthScore: ptr.To[int32](81),
+					Growth:           &cnpgv1alpha1.GrowthStatus{RecentBytesPerHour: 2048},
+					ExpansionHistory: &cnpgv1alpha1.ExpansionHistory{ExpansionsLast30Days: 2},
+					BackupStatus: &cnpgv1alpha1.ClusterBackupStatus{
+						BackupHealthStatus: "NoSuccessfulBackup",
+					},
+					PoolerVolumes: []cnpgv1alpha1.PoolerVolumeStatus{
+						{Pooler: "pg-a-rw", PVC: "pg-a-rw-logs", UsagePercent: 35, Status: "Healthy"},
+						{Pooler: "pg-a-ro", PVC: "pg-a-ro-logs", UsagePercent: 62, Status: "Healthy"},
+					},
+				},
+				{
+					Name: "pg-remote", Namespace: "db", Connection: "edge", Status: "Healthy",
+					Growth: &cnpgv1alpha1.GrowthStatus{BaselineBytesPerHour: 1024, RecentBytesPerHour: 4096},
+				},
+			}},
+		},
+	}
+	backupPolicies := []cnpgv1alpha1.BackupPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "backups", Namespace: "db"},
+			Status: cnpgv1alpha1.BackupPolicyStatus{Clusters: []cnpgv1alpha1.ClusterBackupHealth{
+				{Name: "pg-a", Namespace: "db", Health: cnpgv1alpha1.BackupHealthHealthy, LastBackupTime: &lastBackup},
+				{Name: "pg-backup-only", Namespace: "db", Health: cnpgv1alpha1.BackupHealthCritical},
+			}},
+		},
+	}
+	return storagePolicies, backupPolicies
+}
+
+func TestBuild(t *testing.T) {
+	storagePolicies, backupPolicies := testPolicies()
+	report := Build(storagePolicies, backupPolicies, now)
+
+	if len(report.Clusters) != 3 {
+		t.Fatalf("expected 3 clusters, got %+v", report.Clusters)
+	}
+	// Sorted by connection, namespace and name; local clusters first
+	a, backupOnly, remote := report.Clusters[0], report.Clusters[1], report.Clusters[2]
+
+	if a.Name != "pg-a" || a.StoragePolicy != "db/storage" || a.BackupPolicy != "db/backups" {
+		t.Errorf("expected pg-a from db/storage and db/backups, got %+v", a)
+	}
+	if a.CapacityBytes != 10<<30 || a.UsedBytes != 5<<30 || a.UsagePercent != 50 {
+		t.Errorf("unexpected pg-a capacity, got %+v", a)
+	}
+	if a.HealthScore == nil || *a.HealthScore != 81 {
+		t.Errorf("expected the health score of the StoragePolicy, got %v", a.HealthScore)
+	}
+	if len(a.PoolerVolumes) != 2 {
+		t.Errorf("expected the pooler volumes of the StoragePolicy, got %+v", a.PoolerVolumes)
+	}
+	if a.GrowthBytesPerHour != 2048 || a.ExpansionsLast30Days != 2 {
+		t.Errorf("expected the recent growth rate and 2 expansions, got %+v", a)
+	}
+	if a.BackupHealth != "Healthy" || a.LastBackupTime == nil || !a.LastBackupTime.Equal(now.Add(-6*time.Hour)) {
+		t.Errorf("expected the BackupPolicy health to win, got %+v", a)
+	}
+
+	if backupOnly.Name != "pg-backup-only" || backupOnly.StoragePolicy != "" || backupOnly.BackupHealth != "Critical" {
+		t.Errorf("expected a cluster covered by a BackupPolicy only, got %+v", backupOnly)
+	}
+	if remote.Connection != "edge" || remote.GrowthBytesPerHour != 1024 {
+		t.Errorf("expected the remote cluster with its baseline growth rate, got %+v", remote)
+	}
+
+	if storagePolicies[0].Name != "z-overlap" {
+		t.Error("expected the input policies to be left unsorted")
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	storagePolicies, backupPolicies := testPolicies()
+	report := Build(storagePolicies, backupPolicies, now)
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, FormatCSV); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 4 || len(rows[0]) != len(csvHeader) {
+		t.Fatalf("expected a header and 3 rows of %d columns, got %v", len(csvHeader), rows)
+	}
+	expected := []string{"db", "pg-a", "", "db/storage", "db/backups", "Healthy",
+		"10737418240", "5368709120", "50", "2048", "2", "Healthy", "2025-03-12T04:00:00Z", "81", "62"}
+	for i := range expected {
+		if rows[1][i] != expected[i] {
+			t.Errorf("column %s: expected %q, got %q", csvHeader[i], expected[i], rows[1][i])
+		}
+	}
+
+	if remote := rows[3]; remote[len(remote)-1] != "" {
+		t.Errorf("expected no pooler usage for a cluster without pooler volumes, got %q", remote[len(remote)-1])
+	}
+
+	buf.Reset()
+	if err := report.Write(&buf, FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Clusters) != 3 {
+		t.Errorf("expected a JSON report of 3 clusters, got %s (err=%v)", buf.String(), err)
+	}
+
+	if err := report.Write(&buf, "xml"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := cnpgv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	storagePolicies, _ := testPolicies()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&storagePolicies[1]).
+		WithStatusSubresource(&cnpgv1alpha1.StoragePolicy{}).Build()
+	handler := Handler(c)
+
+	tests := []struct {
+		query       string
+		status      int
+		contentType string
+	}{
+		{query: "", status: http.StatusOK, contentType: "application/json"},
+		{query: "?format=csv", status: http.StatusOK, contentType: "text/csv"},
+		{query: "?format=xml", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.contentType != "" && rec.Header().Get("Content-Type") != tt.contentType {
+				t.Errorf("expected content type %s, got %s", tt.contentType, rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
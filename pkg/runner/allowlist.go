@@ -0,0 +1,86 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ErrCommandNotAllowed is returned for commands whose executable is not allowlisted
+var ErrCommandNotAllowed = errors.New("command not allowed")
+
+// allowlistRunner refuses commands whose executable is not allowlisted
+type allowlistRunner struct {
+	runner  CommandRunner
+	allowed map[string]bool
+}
+
+// WithAllowlist wraps a runner so only the named executables may run. An empty
+// allowlist returns the runner unchanged.
+func WithAllowlist(r CommandRunner, allowed []string) CommandRunner {
+	if len(allowed) == 0 {
+		return r
+	}
+	set := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		set[name] = true
+	}
+	return &allowlistRunner{runner: r, allowed: set}
+}
+
+// Run runs the command if its executable is allowlisted
+func (a *allowlistRunner) Run(
+	ctx context.Context,
+	pod *corev1.Pod,
+	container string,
+	command []string,
+) (string, error) {
+	name := Executable(command)
+	if name == "" && len(command) > 0 {
+		return "", fmt.Errorf("%w: %q with options", ErrCommandNotAllowed, command[0])
+	}
+	if !a.allowed[name] {
+		return "", fmt.Errorf("%w: %q", ErrCommandNotAllowed, name)
+	}
+	return a.runner.Run(ctx, pod, container, command)
+}
+
+// Executable returns the base name of the executable a command runs, looking
+// through env(1) and its variable assignments. Options to env such as -S or -u
+// change which argument is run, so a command passing any has no executable.
+func Executable(command []string) string {
+	env := len(command) > 0 && filepath.Base(command[0]) == "env"
+	for i, arg := range command {
+		if i == 0 && env {
+			continue
+		}
+		if env && strings.HasPrefix(arg, "-") {
+			return ""
+		}
+		if env && strings.Contains(arg, "=") {
+			continue
+		}
+		return filepath.Base(arg)
+	}
+	return ""
+}
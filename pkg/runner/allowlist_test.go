@@ -0,0 +1,69 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+type recordingRunner struct {
+	calls int
+}
+
+func (r *recordingRunner) Run(context.Context, *corev1.Pod, string, []string) (string, error) {
+	r.calls++
+	return "ok", nil
+}
+
+func TestWithAllowlist(t *testing.T) {
+	inner := &recordingRunner{}
+	if WithAllowlist(inner, nil) != CommandRunner(inner) {
+		t.Error("expected an empty allowlist to return the runner unchanged")
+	}
+
+	guarded := WithAllowlist(inner, []string{"ls", "pg_controldata"})
+	tests := []struct {
+		command []string
+		allowed bool
+	}{
+		{command: []string{"ls", "-la", "--", "/var/lib/postgresql/wal/pg_wal"}, allowed: true},
+		{command: []string{"/usr/bin/ls", "/tmp"}, allowed: true},
+		{command: []string{"env", "LC_ALL=C", "pg_controldata", "-D", "/pgdata"}, allowed: true},
+		{command: []string{"rm", "-f", "--", "/pgdata/pg_wal/000000010000000000000001"}, allowed: false},
+		{command: []string{"env", "LC_ALL=C", "sh", "-c", "ls"}, allowed: false},
+		{command: []string{"env", "-i", "sh", "-c", "ls"}, allowed: false},
+		{command: []string{"env", "-u", "ls", "sh", "-c", "ls"}, allowed: false},
+		{command: []string{"env", "-S", "ls", "-la"}, allowed: false},
+		{command: []string{"env", "LC_ALL=C", "--", "ls"}, allowed: false},
+		{command: nil, allowed: false},
+	}
+
+	for _, tt := range tests {
+		inner.calls = 0
+		_, err := guarded.Run(context.Background(), &corev1.Pod{}, "postgres", tt.command)
+		if tt.allowed && (err != nil || inner.calls != 1) {
+			t.Errorf("%v: expected the command to run, got %v", tt.command, err)
+		}
+		if !tt.allowed && (!errors.Is(err, ErrCommandNotAllowed) || inner.calls != 0) {
+			t.Errorf("%v: expected ErrCommandNotAllowed, got %v", tt.command, err)
+		}
+	}
+}
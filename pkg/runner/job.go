@@ -0,0 +1,282 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+const (
+	// LabelTargetPod is set on runner Jobs to the name of the pod whose volumes they
+	// mount, shortened with a hash suffix when it exceeds the label value limit
+	LabelTargetPod = "cnpg.supporttools.io/target-pod"
+	// AnnotationTargetPod is set on runner Jobs to the full name of the target pod
+	AnnotationTargetPod = "cnpg.supporttools.io/target-pod"
+
+	// DefaultJobTimeout is the default time allowed for a runner Job to finish
+	DefaultJobTimeout = 2 * time.Minute
+	// DefaultJobPollInterval is the default interval between Job status checks
+	DefaultJobPollInterval = 2 * time.Second
+
+	// jobTTLSeconds lets Kubernetes garbage collect Jobs the runner failed to delete
+	jobTTLSeconds = 300
+	// maxJobNamePrefix keeps generated Job names within the label value limit
+	maxJobNamePrefix = 40
+	// maxLabelValueLength is the Kubernetes limit on label values
+	maxLabelValueLength = 63
+	// labelHashLength is the number of hex characters of the hash suffix of shortened label values
+	labelHashLength = 8
+)
+
+// JobConfig configures the Job-based command runner
+type JobConfig struct {
+	// Image overrides the runner image. Defaults to the target container's image so
+	// the same tools and user are available.
+	Image string
+	// ServiceAccountName is the service account for runner pods. Runner pods never
+	// receive an API token, so the account needs no permissions.
+	ServiceAccountName string
+	// Timeout bounds how long a Job may run
+	Timeout time.Duration
+	// PollInterval is the interval between Job status checks
+	PollInterval time.Duration
+}
+
+// JobRunner runs commands in short-lived Jobs pinned to the target pod's node and
+// mounting the same PVCs, so the operator does not need pods/exec
+type JobRunner struct {
+	client    client.Client
+	clientset kubernetes.Interface
+	config    JobConfig
+}
+
+// NewJobRunner creates a new Job-based command runner. It uses an uncached client
+// so the manager does not need to watch every Job and Pod in the cluster.
+func NewJobRunner(restConfig *rest.Config, cfg JobConfig) (*JobRunner, error) {
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return newJobRunner(c, clientset, cfg), nil
+}
+
+func newJobRunner(c client.Client, clientset kubernetes.Interface, cfg JobConfig) *JobRunner {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultJobTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultJobPollInterval
+	}
+	return &JobRunner{client: c, clientset: clientset, config: cfg}
+}
+
+// Run creates a Job for the command, waits for it to finish and returns its log output
+func (r *JobRunner) Run(
+	ctx context.Context,
+	pod *corev1.Pod,
+	container string,
+	command []string,
+) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "runner.Job", tracing.Pod(pod.Name, pod.Namespace, container)...)
+	defer func() { tracing.End(span, err) }()
+	logger := log.FromContext(ctx)
+
+	job, err := r.buildJob(pod, container, command)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.client.Create(ctx, job); err != nil {
+		return "", fmt.Errorf("failed to create runner job: %w", err)
+	}
+	logger.V(1).Info("Created runner job", "job", job.Name, "pod", pod.Name)
+
+	defer func() {
+		// Use a fresh context so cleanup still happens when ctx was cancelled
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := r.client.Delete(cleanupCtx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+			logger.Error(err, "Failed to delete runner job", "job", job.Name)
+		}
+	}()
+
+	var succeeded bool
+	err = wait.PollUntilContextTimeout(ctx, r.config.PollInterval, r.config.Timeout, false,
+		func(ctx context.Context) (bool, error) {
+			var current batchv1.Job
+			if err := r.client.Get(ctx, client.ObjectKeyFromObject(job), &current); err != nil {
+				return false, err
+			}
+			if current.Status.Succeeded > 0 {
+				succeeded = true
+				return true, nil
+			}
+			return current.Status.Failed > 0, nil
+		})
+	if err != nil {
+		return "", fmt.Errorf("runner job %s did not finish: %w", job.Name, err)
+	}
+
+	output, err := r.jobLogs(ctx, job)
+	if err != nil {
+		return "", err
+	}
+	if !succeeded {
+		return "", fmt.Errorf("runner job %s failed: %s", job.Name, strings.TrimSpace(output))
+	}
+
+	return output, nil
+}
+
+// buildJob builds a Job that runs the command with the target container's volume mounts
+func (r *JobRunner) buildJob(pod *corev1.Pod, containerName string, command []string) (*batchv1.Job, error) {
+	var target *corev1.Container
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == containerName {
+			target = &pod.Spec.Containers[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("container %s not found in pod %s/%s", containerName, pod.Namespace, pod.Name)
+	}
+	if pod.Spec.NodeName == "" {
+		return nil, fmt.Errorf("pod %s/%s is not scheduled to a node", pod.Namespace, pod.Name)
+	}
+
+	// Only PVC-backed volumes are carried over; projected tokens, secrets and
+	// emptyDirs of the target pod are not needed to inspect its storage
+	pvcVolumes := make(map[string]corev1.Volume)
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			pvcVolumes[volume.Name] = volume
+		}
+	}
+
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for _, mount := range target.VolumeMounts {
+		volume, ok := pvcVolumes[mount.Name]
+		if !ok {
+			continue
+		}
+		volumes = append(volumes, volume)
+		mounts = append(mounts, mount)
+	}
+
+	image := r.config.Image
+	if image == "" {
+		image = target.Image
+	}
+
+	prefix := pod.Name
+	if len(prefix) > maxJobNamePrefix {
+		prefix = prefix[:maxJobNamePrefix]
+	}
+
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "cnpg-storage-manager",
+		"app.kubernetes.io/component":  "command-runner",
+		LabelTargetPod:                 targetPodLabelValue(pod.Name),
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: prefix + "-storage-",
+			Namespace:    pod.Namespace,
+			Labels:       labels,
+			Annotations:  map[string]string{AnnotationTargetPod: pod.Name},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To[int32](0),
+			ActiveDeadlineSeconds:   ptr.To(int64(r.config.Timeout.Seconds())),
+			TTLSecondsAfterFinished: ptr.To[int32](jobTTLSeconds),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					NodeName:                     pod.Spec.NodeName,
+					ServiceAccountName:           r.config.ServiceAccountName,
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext:              pod.Spec.SecurityContext,
+					Tolerations:                  pod.Spec.Tolerations,
+					Volumes:                      volumes,
+					Containers: []corev1.Container{{
+						Name:            "runner",
+						Image:           image,
+						Command:         command,
+						VolumeMounts:    mounts,
+						SecurityContext: target.SecurityContext,
+					}},
+				},
+			},
+		},
+	}, nil
+}
+
+// targetPodLabelValue returns name when it fits in a label value, and otherwise a
+// prefix of name followed by a hash of the full name so distinct pods keep distinct values
+func targetPodLabelValue(name string) string {
+	if len(name) <= maxLabelValueLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	prefix := name[:maxLabelValueLength-labelHashLength-1]
+	return prefix + "-" + hex.EncodeToString(sum[:])[:labelHashLength]
+}
+
+// jobLogs returns the combined log output of the Job's pod
+func (r *JobRunner) jobLogs(ctx context.Context, job *batchv1.Job) (string, error) {
+	var pods corev1.PodList
+	if err := r.client.List(ctx, &pods,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{"job-name": job.Name},
+	); err != nil {
+		return "", fmt.Errorf("failed to list pods of runner job %s: %w", job.Name, err)
+	}
+	if len(pods.Items) == 0 {
+		return "", fmt.Errorf("no pods found for runner job %s", job.Name)
+	}
+
+	raw, err := r.clientset.CoreV1().Pods(job.Namespace).GetLogs(pods.Items[0].Name, &corev1.PodLogOptions{}).DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs of runner job %s: %w", job.Name, err)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg-cluster-with-a-rather-long-name-for-testing-1", Namespace: "db"},
+		Spec: corev1.PodSpec{
+			NodeName:    "node-a",
+			Tolerations: []corev1.Toleration{{Key: "dedicated", Value: "postgres"}},
+			Volumes: []corev1.Volume{
+				{Name: "pgdata", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pg-1"},
+				}},
+				{Name: "pg-wal", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pg-1-wal"},
+				}},
+				{Name: "app-secret", VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: "pg-app"},
+				}},
+			},
+			Containers: []corev1.Container{{
+				Name:  "postgres",
+				Image: "ghcr.io/cloudnative-pg/postgresql:16",
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "pgdata", MountPath: "/var/lib/postgresql/data"},
+					{Name: "pg-wal", MountPath: "/var/lib/postgresql/wal"},
+					{Name: "app-secret", MountPath: "/etc/secret"},
+				},
+			}},
+		},
+	}
+}
+
+func TestBuildJob(t *testing.T) {
+	r := newJobRunner(nil, nil, JobConfig{ServiceAccountName: "storage-runner"})
+	command := []string{"df", "-B1", "/var/lib/postgresql/data"}
+
+	job, err := r.buildJob(testPod(), "postgres", command)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.HasSuffix(job.GenerateName, "-storage-") || len(job.GenerateName) > maxJobNamePrefix+len("-storage-") {
+		t.Errorf("unexpected generate name %q", job.GenerateName)
+	}
+	if job.Labels[LabelTargetPod] != testPod().Name {
+		t.Errorf("expected target pod label, got %v", job.Labels)
+	}
+	if job.Annotations[AnnotationTargetPod] != testPod().Name {
+		t.Errorf("expected target pod annotation, got %v", job.Annotations)
+	}
+
+	spec := job.Spec.Template.Spec
+	if spec.NodeName != "node-a" {
+		t.Errorf("expected job pinned to node-a, got %q", spec.NodeName)
+	}
+	if spec.ServiceAccountName != "storage-runner" {
+		t.Errorf("expected service account storage-runner, got %q", spec.ServiceAccountName)
+	}
+	if spec.AutomountServiceAccountToken == nil || *spec.AutomountServiceAccountToken {
+		t.Error("expected service account token automount to be disabled")
+	}
+	if len(spec.Tolerations) != 1 {
+		t.Errorf("expected tolerations to be copied, got %v", spec.Tolerations)
+	}
+	if len(spec.Volumes) != 2 {
+		t.Errorf("expected only the 2 PVC volumes, got %d", len(spec.Volumes))
+	}
+
+	container := spec.Containers[0]
+	if container.Image != "ghcr.io/cloudnative-pg/postgresql:16" {
+		t.Errorf("expected image to default to the target container image, got %q", container.Image)
+	}
+	if len(container.VolumeMounts) != 2 {
+		t.Errorf("expected 2 volume mounts, got %d", len(container.VolumeMounts))
+	}
+	if strings.Join(container.Command, " ") != strings.Join(command, " ") {
+		t.Errorf("expected command %v, got %v", command, container.Command)
+	}
+}
+
+func TestBuildJob_LongPodName(t *testing.T) {
+	r := newJobRunner(nil, nil, JobConfig{})
+	pod := testPod()
+	pod.Name = strings.Repeat("a", 70) + "-1"
+	other := testPod()
+	other.Name = strings.Repeat("a", 70) + "-2"
+
+	job, err := r.buildJob(pod, "postgres", []string{"true"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	otherJob, err := r.buildJob(other, "postgres", []string{"true"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	value := job.Labels[LabelTargetPod]
+	if len(value) > maxLabelValueLength {
+		t.Errorf("expected label value within %d characters, got %d", maxLabelValueLength, len(value))
+	}
+	if value == otherJob.Labels[LabelTargetPod] {
+		t.Errorf("expected distinct label values for distinct pods, both got %q", value)
+	}
+	if job.Annotations[AnnotationTargetPod] != pod.Name {
+		t.Errorf("expected full pod name in annotation, got %q", job.Annotations[AnnotationTargetPod])
+	}
+}
+
+func TestBuildJob_Errors(t *testing.T) {
+	r := newJobRunner(nil, nil, JobConfig{Image: "busybox"})
+
+	if _, err := r.buildJob(testPod(), "missing", []string{"true"}); err == nil {
+		t.Error("expected error for unknown container")
+	}
+
+	unscheduled := testPod()
+	unscheduled.Spec.NodeName = ""
+	if _, err := r.buildJob(unscheduled, "postgres", []string{"true"}); err == nil {
+		t.Error("expected error for unscheduled pod")
+	}
+}
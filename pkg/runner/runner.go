@@ -0,0 +1,212 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package runner executes commands against the volumes of CNPG instance
+// pods, either through pod exec or through short-lived Jobs.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/supporttools/cnpg-storage-manager/pkg/tracing"
+)
+
+const (
+	// DefaultContainerAnnotation names the container kubectl exec and logs default
+	// to; CNPG sets it to the PostgreSQL container of instance pods
+	DefaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+	// postgresContainer is the name CNPG gives the PostgreSQL container
+	postgresContainer = "postgres"
+	// pgdataVolume is the volume holding PGDATA in CNPG instance pods
+	pgdataVolume = "pgdata"
+)
+
+// Mode selects how commands are executed
+type Mode string
+
+const (
+	// ModeExec runs commands with pod exec from the operator
+	ModeExec Mode = "exec"
+	// ModeJob runs commands in a Job that mounts the target pod's volumes
+	ModeJob Mode = "job"
+)
+
+// CommandRunner runs a command in the context of a container of a pod and returns stdout
+type CommandRunner interface {
+	Run(ctx context.Context, pod *corev1.Pod, container string, command []string) (string, error)
+}
+
+// Config configures the command runner
+type Config struct {
+	Mode Mode
+	Job  JobConfig
+	// ExecTimeout bounds each pod exec command. Defaults to DefaultExecTimeout; Jobs
+	// are bounded by Job.Timeout instead.
+	ExecTimeout time.Duration
+	// OnTimeout is called with the executable of each exec command that timed out
+	OnTimeout func(executable string)
+}
+
+// New creates the command runner selected by the config
+func New(cfg Config, restConfig *rest.Config) (CommandRunner, error) {
+	switch cfg.Mode {
+	case ModeExec, "":
+		execRunner, err := NewExecRunner(restConfig)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.ExecTimeout <= 0 {
+			cfg.ExecTimeout = DefaultExecTimeout
+		}
+		return WithTimeout(execRunner, cfg.ExecTimeout, cfg.OnTimeout), nil
+	case ModeJob:
+		return NewJobRunner(restConfig, cfg.Job)
+	default:
+		return nil, fmt.Errorf("unknown command runner mode %q", cfg.Mode)
+	}
+}
+
+// ExecRunner runs commands through the pods/exec subresource
+type ExecRunner struct {
+	restConfig *rest.Config
+	clientset  kubernetes.Interface
+}
+
+// NewExecRunner creates a new exec-based command runner
+func NewExecRunner(restConfig *rest.Config) (*ExecRunner, error) {
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return &ExecRunner{
+		restConfig: restConfig,
+		clientset:  clientset,
+	}, nil
+}
+
+// Run executes the command in the named container of the pod
+func (r *ExecRunner) Run(
+	ctx context.Context,
+	pod *corev1.Pod,
+	container string,
+	command []string,
+) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "runner.Exec", tracing.Pod(pod.Name, pod.Namespace, container)...)
+	defer func() { tracing.End(span, err) }()
+	req := r.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(r.restConfig, "POST", req.URL())
+	if err != nil {
+		return "", fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to execute command: %w, stderr: %s", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+// PreferredContainer returns the name of the PostgreSQL container of a pod, or ""
+// when the pod has no containers
+func PreferredContainer(pod *corev1.Pod) string {
+	if container := PostgresContainer(pod); container != nil {
+		return container.Name
+	}
+	return ""
+}
+
+// PostgresContainer returns the container of a pod that runs PostgreSQL, so
+// commands still reach it when sidecars are injected or the container is renamed.
+// In order of preference: the container named by the default-container
+// annotation, the container named "postgres", the container mounting the PGDATA
+// volume, the first container with a PostgreSQL image, then the first container.
+func PostgresContainer(pod *corev1.Pod) *corev1.Container {
+	containers := pod.Spec.Containers
+	if len(containers) == 0 {
+		return nil
+	}
+
+	defaultContainer := pod.Annotations[DefaultContainerAnnotation]
+	matchers := []func(*corev1.Container) bool{
+		func(c *corev1.Container) bool { return defaultContainer != "" && c.Name == defaultContainer },
+		func(c *corev1.Container) bool { return c.Name == postgresContainer },
+		mountsPGDATA,
+		func(c *corev1.Container) bool { return strings.Contains(imageRepository(c.Image), postgresContainer) },
+	}
+	for _, matches := range matchers {
+		if container := findContainer(containers, matches); container != nil {
+			return container
+		}
+	}
+	return &containers[0]
+}
+
+// findContainer returns the first container that matches
+func findContainer(containers []corev1.Container, matches func(*corev1.Container) bool) *corev1.Container {
+	for i := range containers {
+		if matches(&containers[i]) {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+// mountsPGDATA reports whether the container mounts the PGDATA volume
+func mountsPGDATA(container *corev1.Container) bool {
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == pgdataVolume {
+			return true
+		}
+	}
+	return false
+}
+
+// imageRepository returns the last path element of an image reference without
+// its tag or digest, e.g. "postgresql" for ghcr.io/cloudnative-pg/postgresql:16
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	name := path.Base(image)
+	name, _, _ = strings.Cut(name, ":")
+	return name
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreferredContainer(t *testing.T) {
+	pgdata := []corev1.VolumeMount{{Name: "pgdata", MountPath: "/var/lib/postgresql/data"}}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		containers  []corev1.Container
+		want        string
+	}{
+		{name: "no containers", want: ""},
+		{
+			name:       "postgres container after a sidecar",
+			containers: []corev1.Container{{Name: "istio-proxy"}, {Name: "postgres"}},
+			want:       "postgres",
+		},
+		{
+			name:        "default-container annotation",
+			annotations: map[string]string{DefaultContainerAnnotation: "database"},
+			containers:  []corev1.Container{{Name: "postgres"}, {Name: "database"}},
+			want:        "database",
+		},
+		{
+			name:        "annotation naming a missing container",
+			annotations: map[string]string{DefaultContainerAnnotation: "gone"},
+			containers:  []corev1.Container{{Name: "sidecar"}, {Name: "postgres"}},
+			want:        "postgres",
+		},
+		{
+			name:       "renamed container mounting PGDATA",
+			containers: []corev1.Container{{Name: "sidecar"}, {Name: "db", VolumeMounts: pgdata}},
+			want:       "db",
+		},
+		{
+			name: "renamed container with a PostgreSQL image",
+			containers: []corev1.Container{
+				{Name: "proxy", Image: "docker.io/envoyproxy/envoy:v1.31"},
+				{Name: "db", Image: "ghcr.io/cloudnative-pg/postgresql:16.4@sha256:abc"},
+			},
+			want: "db",
+		},
+		{
+			name: "registry port is not a tag",
+			containers: []corev1.Container{
+				{Name: "sidecar", Image: "registry.local:5000/tools/busybox"},
+				{Name: "db", Image: "registry.local:5000/images/postgresql"},
+			},
+			want: "db",
+		},
+		{
+			name:       "first container as a fallback",
+			containers: []corev1.Container{{Name: "main"}, {Name: "sidecar"}},
+			want:       "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec:       corev1.PodSpec{Containers: tt.containers},
+			}
+			if got := PreferredContainer(pod); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultExecTimeout is the default time allowed for a single pod exec command
+const DefaultExecTimeout = 30 * time.Second
+
+// ErrCommandTimeout is returned for commands that did not finish within the timeout
+var ErrCommandTimeout = errors.New("command timed out")
+
+// timeoutRunner bounds the run time of each command, so a hung exec stream cannot
+// block the caller indefinitely
+type timeoutRunner struct {
+	runner    CommandRunner
+	timeout   time.Duration
+	onTimeout func(executable string)
+}
+
+// WithTimeout wraps a runner so each command is cancelled after the timeout.
+// onTimeout, when set, is called with the executable of every command that timed
+// out. A timeout of zero or less returns the runner unchanged.
+func WithTimeout(r CommandRunner, timeout time.Duration, onTimeout func(executable string)) CommandRunner {
+	if timeout <= 0 {
+		return r
+	}
+	return &timeoutRunner{runner: r, timeout: timeout, onTimeout: onTimeout}
+}
+
+// Run runs the command with a deadline of the configured timeout
+func (t *timeoutRunner) Run(
+	ctx context.Context,
+	pod *corev1.Pod,
+	container string,
+	command []string,
+) (string, error) {
+	runCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	output, err := t.runner.Run(runCtx, pod, container, command)
+	// Only the command's own deadline counts; a cancelled or expired parent context
+	// is reported as is
+	if err != nil && ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		name := Executable(command)
+		if t.onTimeout != nil {
+			t.onTimeout(name)
+		}
+		return "", fmt.Errorf("%w: %q in pod %s/%s after %s", ErrCommandTimeout, name, pod.Namespace, pod.Name, t.timeout)
+	}
+	return output, err
+}
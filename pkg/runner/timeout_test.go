@@ -0,0 +1,71 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// blockingRunner blocks until its context is done
+type blockingRunner struct{}
+
+func (blockingRunner) Run(ctx context.Context, _ *corev1.Pod, _ string, _ []string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestWithTimeout(t *testing.T) {
+	inner := &recordingRunner{}
+	if WithTimeout(inner, 0, nil) != CommandRunner(inner) {
+		t.Error("expected a zero timeout to return the runner unchanged")
+	}
+
+	var timedOut []string
+	r := WithTimeout(blockingRunner{}, 10*time.Millisecond, func(executable string) {
+		timedOut = append(timedOut, executable)
+	})
+
+	_, err := r.Run(context.Background(), &corev1.Pod{}, "postgres", []string{"/usr/bin/df", "-B1", "-P"})
+	if !errors.Is(err, ErrCommandTimeout) {
+		t.Fatalf("expected ErrCommandTimeout, got %v", err)
+	}
+	if len(timedOut) != 1 || timedOut[0] != "df" {
+		t.Errorf("expected one timeout for df, got %v", timedOut)
+	}
+
+	// A cancelled caller is not a command timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = r.Run(ctx, &corev1.Pod{}, "postgres", []string{"ls"})
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrCommandTimeout) {
+		t.Errorf("expected the caller's cancellation, got %v", err)
+	}
+	if len(timedOut) != 1 {
+		t.Errorf("expected no timeout for a cancelled caller, got %v", timedOut)
+	}
+
+	r = WithTimeout(inner, time.Second, nil)
+	output, err := r.Run(context.Background(), &corev1.Pod{}, "postgres", []string{"ls"})
+	if err != nil || output != "ok" {
+		t.Errorf("expected the command to pass through, got %q, %v", output, err)
+	}
+}
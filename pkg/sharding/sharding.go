@@ -0,0 +1,89 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharding splits the policies of a large fleet between several manager
+// replicas. Each shard owns the policies whose namespace/name hashes to its index, so
+// every replica reconciles its share actively instead of a single leader doing all work.
+package sharding
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// EnvShardIndex is the environment variable the shard index is read from
+const EnvShardIndex = "SHARD_INDEX"
+
+// Shard identifies the share of policies a manager replica owns. The zero value and a
+// count of 1 own everything
+type Shard struct {
+	// Index is the shard of this replica, from 0 to Count-1
+	Index int
+	// Count is the number of shards
+	Count int
+}
+
+// Enabled reports whether the policies are split between several shards
+func (s Shard) Enabled() bool {
+	return s.Count > 1
+}
+
+// Validate returns an error when the index is not one of the count's shards
+func (s Shard) Validate() error {
+	if s.Count < 0 {
+		return fmt.Errorf("shard count must not be negative, got %d", s.Count)
+	}
+	if s.Enabled() && (s.Index < 0 || s.Index >= s.Count) {
+		return fmt.Errorf("shard index %d is out of range for %d shards", s.Index, s.Count)
+	}
+	return nil
+}
+
+// Owns reports whether the object with the given namespace and name belongs to this shard
+func (s Shard) Owns(namespace, name string) bool {
+	if !s.Enabled() {
+		return true
+	}
+	return For(namespace, name, s.Count) == s.Index
+}
+
+// Label returns the shard index as a metric label value
+func (s Shard) Label() string {
+	return strconv.Itoa(s.Index)
+}
+
+// For returns the shard owning the object with the given namespace and name
+func For(namespace, name string, count int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(namespace + "/" + name))
+	return int(h.Sum32() % uint32(count))
+}
+
+// IndexFromHostname returns the ordinal of a StatefulSet pod from its hostname, e.g. 2
+// for cnpg-storage-manager-2
+func IndexFromHostname(hostname string) (int, error) {
+	i := strings.LastIndex(hostname, "-")
+	if i < 0 {
+		return 0, fmt.Errorf("hostname %q has no StatefulSet ordinal", hostname)
+	}
+	index, err := strconv.Atoi(hostname[i+1:])
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("hostname %q has no StatefulSet ordinal", hostname)
+	}
+	return index, nil
+}
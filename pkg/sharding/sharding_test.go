@@ -0,0 +1,105 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestOwns(t *testing.T) {
+	const count = 4
+	owners := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprintf("policy-%d", i)
+		owned := 0
+		for index := 0; index < count; index++ {
+			if (Shard{Index: index, Count: count}).Owns("db", name) {
+				owned++
+				owners[name] = index
+			}
+		}
+		if owned != 1 {
+			t.Fatalf("expected exactly one shard to own %s, got %d", name, owned)
+		}
+	}
+
+	perShard := make(map[int]int)
+	for _, index := range owners {
+		perShard[index]++
+	}
+	if len(perShard) != count {
+		t.Errorf("expected policies on all %d shards, got %v", count, perShard)
+	}
+
+	if !(Shard{}).Owns("db", "policy-1") || !(Shard{Count: 1}).Owns("db", "policy-1") {
+		t.Error("expected an unsharded manager to own every policy")
+	}
+	if For("db", "policy-1", count) != For("db", "policy-1", count) {
+		t.Error("expected the shard of a policy to be stable")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		shard     Shard
+		expectErr bool
+	}{
+		{"disabled", Shard{}, false},
+		{"single shard ignores index", Shard{Index: 3, Count: 1}, false},
+		{"first shard", Shard{Index: 0, Count: 3}, false},
+		{"last shard", Shard{Index: 2, Count: 3}, false},
+		{"index too high", Shard{Index: 3, Count: 3}, true},
+		{"negative index", Shard{Index: -1, Count: 3}, true},
+		{"negative count", Shard{Count: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.shard.Validate(); (err != nil) != tt.expectErr {
+				t.Errorf("Validate() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestIndexFromHostname(t *testing.T) {
+	tests := []struct {
+		hostname  string
+		expected  int
+		expectErr bool
+	}{
+		{"cnpg-storage-manager-0", 0, false},
+		{"cnpg-storage-manager-12", 12, false},
+		{"cnpg-storage-manager-7d9f8c-x2x4z", 0, true},
+		{"manager", 0, true},
+		{"manager-", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hostname, func(t *testing.T) {
+			index, err := IndexFromHostname(tt.hostname)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("IndexFromHostname() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if index != tt.expected {
+				t.Errorf("IndexFromHostname() = %d, expected %d", index, tt.expected)
+			}
+		})
+	}
+}
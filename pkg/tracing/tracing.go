@@ -0,0 +1,164 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing sets up OpenTelemetry tracing and provides the helpers used to
+// instrument reconciles, metrics collection, remediation and alerting.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ServiceName is the service.name of exported spans
+	ServiceName = "cnpg-storage-manager"
+
+	// tracerName is the instrumentation scope of all spans
+	tracerName = "github.com/supporttools/cnpg-storage-manager"
+
+	// envOTLPEndpoint enables tracing with the exporter's standard environment configuration
+	envOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+)
+
+// Config configures the OTLP trace exporter
+type Config struct {
+	// Endpoint is the host:port of the OTLP gRPC receiver. When empty, tracing is enabled
+	// only if OTEL_EXPORTER_OTLP_ENDPOINT is set
+	Endpoint string
+	// Insecure disables TLS to the receiver
+	Insecure bool
+	// SampleRatio is the fraction of new traces that are recorded
+	SampleRatio float64
+}
+
+// Enabled reports whether spans are exported
+func (c Config) Enabled() bool {
+	return c.Endpoint != "" || os.Getenv(envOTLPEndpoint) != ""
+}
+
+// Setup installs the global tracer provider and returns a function that flushes and
+// stops it. When tracing is not enabled, spans are no-ops and the returned function
+// does nothing.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if !cfg.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracegrpc.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of any span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Cluster returns the span attributes identifying a CNPG cluster
+func Cluster(name, namespace string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("cnpg.cluster", name),
+		semconv.K8SNamespaceName(namespace),
+	}
+}
+
+// Pod returns the span attributes identifying a container of a pod
+func Pod(name, namespace, container string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.K8SPodName(name),
+		semconv.K8SNamespaceName(namespace),
+		semconv.K8SContainerName(container),
+	}
+}
+
+// Node returns the span attribute identifying a node
+func Node(name string) attribute.KeyValue {
+	return semconv.K8SNodeName(name)
+}
+
+// Step returns the span attributes identifying a remediation step of a StorageEvent
+func Step(event, eventType, step, cluster, namespace string) []attribute.KeyValue {
+	return append(Cluster(cluster, namespace),
+		attribute.String("storageevent.name", event),
+		attribute.String("storageevent.type", eventType),
+		attribute.String("storageevent.step", step),
+	)
+}
+
+// Reconciler runs every reconcile of r in a span named <controller>.Reconcile
+func Reconciler(controller string, r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		ctx, span := Start(ctx, controller+".Reconcile",
+			attribute.String("k8s.object.name", req.Name),
+			semconv.K8SNamespaceName(req.Namespace),
+		)
+		result, err := r.Reconcile(ctx, req)
+		if result.RequeueAfter > 0 {
+			span.SetAttributes(attribute.String("reconcile.requeue_after", result.RequeueAfter.String()))
+		}
+		End(span, err)
+		return result, err
+	})
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// recordSpans installs a tracer provider recording finished spans for the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	t.Setenv(envOTLPEndpoint, "")
+
+	shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected shutdown error: %v", err)
+	}
+}
+
+func TestConfig_Enabled(t *testing.T) {
+	t.Setenv(envOTLPEndpoint, "")
+	if (Config{}).Enabled() {
+		t.Error("expected tracing to be disabled without an endpoint")
+	}
+	if !(Config{Endpoint: "tempo:4317"}).Enabled() {
+		t.Error("expected tracing to be enabled with an endpoint")
+	}
+
+	t.Setenv(envOTLPEndpoint, "http://tempo:4317")
+	if !(Config{}).Enabled() {
+		t.Error("expected tracing to be enabled by OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+}
+
+func TestEnd(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := Start(context.Background(), "ok", Cluster("pg-main", "default")...)
+	End(span, nil)
+	_, span = Start(context.Background(), "failed")
+	End(span, errors.New("kubelet timeout"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Unset {
+		t.Errorf("expected unset status, got %v", spans[0].Status().Code)
+	}
+	if len(spans[0].Attributes()) != 2 {
+		t.Errorf("expected cluster attributes, got %v", spans[0].Attributes())
+	}
+	if spans[1].Status().Code != codes.Error || spans[1].Status().Description != "kubelet timeout" {
+		t.Errorf("expected error status, got %+v", spans[1].Status())
+	}
+	if len(spans[1].Events()) != 1 {
+		t.Errorf("expected the error to be recorded as an event, got %d events", len(spans[1].Events()))
+	}
+}
+
+func TestReconciler(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var innerHasSpan bool
+	inner := reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		_, child := Start(ctx, "child")
+		innerHasSpan = child.SpanContext().IsValid()
+		End(child, nil)
+		if req.Name == "broken" {
+			return reconcile.Result{}, errors.New("boom")
+		}
+		return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+	})
+	wrapped := Reconciler("StoragePolicy", inner)
+
+	req := reconcile.Request{}
+	req.Name, req.Namespace = "policy", "default"
+	result, err := wrapped.Reconcile(context.Background(), req)
+	if err != nil || result.RequeueAfter != 30*time.Second {
+		t.Fatalf("expected the inner result to be returned, got %+v, %v", result, err)
+	}
+	req.Name = "broken"
+	if _, err := wrapped.Reconcile(context.Background(), req); err == nil {
+		t.Fatal("expected the inner error to be returned")
+	}
+
+	if !innerHasSpan {
+		t.Error("expected spans started by the reconciler to be valid")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans, got %d", len(spans))
+	}
+	parent, child := spans[1], spans[0]
+	if parent.Name() != "StoragePolicy.Reconcile" {
+		t.Errorf("expected span StoragePolicy.Reconcile, got %s", parent.Name())
+	}
+	if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the child span to be parented to the reconcile span")
+	}
+	if spans[3].Status().Code != codes.Error {
+		t.Errorf("expected failed reconcile to have error status, got %v", spans[3].Status().Code)
+	}
+}
@@ -40,6 +40,11 @@ var (
 	// isCertManagerAlreadyInstalled will be set true when CertManager CRDs be found on the cluster
 	isCertManagerAlreadyInstalled = false
 
+	// - CNPG_INSTALL_SKIP=true: Skips the CloudNativePG operator installation during test setup.
+	skipCNPGInstall = os.Getenv("CNPG_INSTALL_SKIP") == "true"
+	// isCNPGAlreadyInstalled will be set true when the CloudNativePG CRDs be found on the cluster
+	isCNPGAlreadyInstalled = false
+
 	// projectImage is the name of the image which will be build and loaded
 	// with the code source changes to be tested.
 	projectImage = "example.com/cnpg-storage-manager:v0.0.1"
@@ -47,8 +52,8 @@ var (
 
 // TestE2E runs the end-to-end (e2e) test suite for the project. These tests execute in an isolated,
 // temporary environment to validate project changes with the purpose of being used in CI jobs.
-// The default setup requires Kind, builds/loads the Manager Docker image locally, and installs
-// CertManager.
+// The default setup requires Kind, builds/loads the Manager Docker image locally, installs
+// CertManager and the CloudNativePG operator, and deploys the controller-manager.
 func TestE2E(t *testing.T) {
 	RegisterFailHandler(Fail)
 	_, _ = fmt.Fprintf(GinkgoWriter, "Starting cnpg-storage-manager integration test suite\n")
@@ -81,9 +86,63 @@ var _ = BeforeSuite(func() {
 			_, _ = fmt.Fprintf(GinkgoWriter, "WARNING: CertManager is already installed. Skipping installation...\n")
 		}
 	}
+
+	// The storage scenarios manage real CNPG clusters, so the operator has to be running
+	// before the controller-manager starts watching them.
+	if !skipCNPGInstall {
+		By("checking if the CloudNativePG operator is installed already")
+		isCNPGAlreadyInstalled = utils.IsCNPGCRDsInstalled()
+		if !isCNPGAlreadyInstalled {
+			_, _ = fmt.Fprintf(GinkgoWriter, "Installing the CloudNativePG operator...\n")
+			Expect(utils.InstallCNPG()).To(Succeed(), "Failed to install the CloudNativePG operator")
+		} else {
+			_, _ = fmt.Fprintf(GinkgoWriter, "WARNING: CloudNativePG is already installed. Skipping installation...\n")
+		}
+	}
+
+	// Every Describe container shares the same deployment, so it is set up once for the suite
+	// rather than per container.
+	By("creating manager namespace")
+	cmd = exec.Command("kubectl", "create", "ns", namespace)
+	_, err = utils.Run(cmd)
+	Expect(err).NotTo(HaveOccurred(), "Failed to create namespace")
+
+	By("labeling the namespace to enforce the restricted security policy")
+	cmd = exec.Command("kubectl", "label", "--overwrite", "ns", namespace,
+		"pod-security.kubernetes.io/enforce=restricted")
+	_, err = utils.Run(cmd)
+	Expect(err).NotTo(HaveOccurred(), "Failed to label namespace with restricted policy")
+
+	By("installing CRDs")
+	cmd = exec.Command("make", "install")
+	_, err = utils.Run(cmd)
+	Expect(err).NotTo(HaveOccurred(), "Failed to install CRDs")
+
+	By("deploying the controller-manager")
+	cmd = exec.Command("make", "deploy", fmt.Sprintf("IMG=%s", projectImage))
+	_, err = utils.Run(cmd)
+	Expect(err).NotTo(HaveOccurred(), "Failed to deploy the controller-manager")
 })
 
 var _ = AfterSuite(func() {
+	By("undeploying the controller-manager")
+	cmd := exec.Command("make", "undeploy")
+	_, _ = utils.Run(cmd)
+
+	By("uninstalling CRDs")
+	cmd = exec.Command("make", "uninstall")
+	_, _ = utils.Run(cmd)
+
+	By("removing manager namespace")
+	cmd = exec.Command("kubectl", "delete", "ns", namespace)
+	_, _ = utils.Run(cmd)
+
+	// Teardown CloudNativePG after the suite if not skipped and if it was not already installed
+	if !skipCNPGInstall && !isCNPGAlreadyInstalled {
+		_, _ = fmt.Fprintf(GinkgoWriter, "Uninstalling the CloudNativePG operator...\n")
+		utils.UninstallCNPG()
+	}
+
 	// Teardown CertManager after the suite if not skipped and if it was not already installed
 	if !skipCertManagerInstall && !isCertManagerAlreadyInstalled {
 		_, _ = fmt.Fprintf(GinkgoWriter, "Uninstalling CertManager...\n")
@@ -0,0 +1,323 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2025 SupportTools.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/supporttools/cnpg-storage-manager/test/utils"
+)
+
+// storageNamespace holds the CNPG cluster and the alert receiver the storage scenarios run against
+const storageNamespace = "cnpg-storage-e2e"
+
+// storageClassName is an expandable StorageClass backed by the local-path provisioner Kind ships
+const storageClassName = "cnpg-e2e-expandable"
+
+// storageClusterName is the CNPG cluster the storage scenarios fill up
+const storageClusterName = "e2e-pg"
+
+// storagePolicyName is the StoragePolicy managing the CNPG cluster
+const storagePolicyName = "e2e-policy"
+
+// alertReceiverName is the pod and service that log the alerts the controller sends
+const alertReceiverName = "alert-receiver"
+
+var _ = Describe("Storage", Ordered, func() {
+	var initialUsage int
+
+	BeforeAll(func() {
+		By("creating an expandable local-path StorageClass")
+		Expect(kubectlApply(fmt.Sprintf(`apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+  name: %s
+provisioner: rancher.io/local-path
+reclaimPolicy: Delete
+volumeBindingMode: WaitForFirstConsumer
+allowVolumeExpansion: true
+`, storageClassName))).To(Succeed())
+
+		By("creating the storage test namespace")
+		cmd := exec.Command("kubectl", "create", "ns", storageNamespace)
+		_, err := utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "Failed to create namespace")
+
+		By("deploying the alert receiver")
+		Expect(kubectlApply(fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+  labels:
+    app: %[1]s
+spec:
+  containers:
+  - name: echo
+    image: mendhak/http-https-echo:31
+    env:
+    - name: HTTP_PORT
+      value: "8080"
+    ports:
+    - containerPort: 8080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+spec:
+  selector:
+    app: %[1]s
+  ports:
+  - port: 8080
+    targetPort: 8080
+`, alertReceiverName, storageNamespace))).To(Succeed())
+
+		By("creating a single-instance CNPG cluster")
+		Expect(kubectlApply(fmt.Sprintf(`apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    e2e: storage
+spec:
+  instances: 1
+  storage:
+    size: 1Gi
+    storageClass: %s
+`, storageClusterName, storageNamespace, storageClassName))).To(Succeed())
+
+		By("waiting for the CNPG cluster to be ready")
+		cmd = exec.Command("kubectl", "wait", "cluster.postgresql.cnpg.io/"+storageClusterName,
+			"--for", "condition=Ready", "-n", storageNamespace, "--timeout", "10m")
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "CNPG cluster did not become ready")
+	})
+
+	AfterAll(func() {
+		By("removing the storage test namespace")
+		cmd := exec.Command("kubectl", "delete", "ns", storageNamespace, "--wait=true", "--timeout=5m")
+		_, _ = utils.Run(cmd)
+
+		By("removing the expandable StorageClass")
+		cmd = exec.Command("kubectl", "delete", "storageclass", storageClassName, "--ignore-not-found")
+		_, _ = utils.Run(cmd)
+	})
+
+	AfterEach(func() {
+		specReport := CurrentSpecReport()
+		if specReport.Failed() {
+			By("Fetching StoragePolicy and StorageEvents")
+			cmd := exec.Command("kubectl", "get", "storagepolicies,storageevents", "-n", storageNamespace, "-o", "yaml")
+			output, err := utils.Run(cmd)
+			if err == nil {
+				_, _ = fmt.Fprintf(GinkgoWriter, "Storage resources:\n%s", output)
+			} else {
+				_, _ = fmt.Fprintf(GinkgoWriter, "Failed to get storage resources: %s", err)
+			}
+
+			By("Fetching controller manager logs")
+			cmd = exec.Command("kubectl", "logs", "-l", "control-plane=controller-manager", "-n", namespace, "--tail=-1")
+			output, err = utils.Run(cmd)
+			if err == nil {
+				_, _ = fmt.Fprintf(GinkgoWriter, "Controller logs:\n%s", output)
+			} else {
+				_, _ = fmt.Fprintf(GinkgoWriter, "Failed to get Controller logs: %s", err)
+			}
+		}
+	})
+
+	SetDefaultEventuallyTimeout(5 * time.Minute)
+	SetDefaultEventuallyPollingInterval(5 * time.Second)
+
+	It("should report the cluster usage collected through the exec path", func() {
+		// local-path volumes are host directories, so kubelet has no volume stats for them
+		// and df inside the instance pod is the only source of usage.
+		By("creating a monitor-only StoragePolicy")
+		Expect(applyStoragePolicy(thresholds{warning: 97, critical: 98, expansion: 99, emergency: 100}, false)).To(Succeed())
+
+		By("waiting for the policy to report usage")
+		Eventually(func(g Gomega) {
+			usage, err := managedClusterField("usagePercent")
+			g.Expect(err).NotTo(HaveOccurred())
+			initialUsage, err = strconv.Atoi(usage)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(initialUsage).To(BeNumerically(">", 0))
+		}).Should(Succeed())
+	})
+
+	It("should see the usage grow when the disk is filled", func() {
+		before, err := managedClusterField("usedBytes")
+		Expect(err).NotTo(HaveOccurred())
+		usedBefore, err := strconv.ParseInt(before, 10, 64)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("writing 256Mi to the data volume")
+		cmd := exec.Command("kubectl", "exec", storageClusterName+"-1", "-n", storageNamespace, "-c", "postgres", "--",
+			"dd", "if=/dev/zero", "of=/var/lib/postgresql/data/e2e-fill", "bs=1M", "count=256", "conv=fsync")
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "Failed to fill the data volume")
+
+		By("waiting for the policy to report the extra usage")
+		Eventually(func(g Gomega) {
+			after, err := managedClusterField("usedBytes")
+			g.Expect(err).NotTo(HaveOccurred())
+			usedAfter, err := strconv.ParseInt(after, 10, 64)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(usedAfter - usedBefore).To(BeNumerically(">=", 200<<20))
+		}).Should(Succeed())
+	})
+
+	It("should alert and expand the PVC when the expansion threshold is breached", func() {
+		// The usage reported for a local-path volume is the node filesystem's, so the
+		// thresholds are placed below whatever the node currently reports.
+		Expect(initialUsage).To(BeNumerically(">=", 4), "node filesystem usage too low to place thresholds under")
+		By("lowering the thresholds below the current usage")
+		Expect(applyStoragePolicy(thresholds{warning: 1, critical: 2, expansion: 3, emergency: 100}, false)).To(Succeed())
+
+		By("waiting for an expansion StorageEvent")
+		Eventually(func(g Gomega) {
+			names, err := storageEventNames("expansion")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(names).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		By("verifying the PVC request was raised")
+		Eventually(func(g Gomega) {
+			cmd := exec.Command("kubectl", "get", "pvc", storageClusterName+"-1", "-n", storageNamespace,
+				"-o", "jsonpath={.spec.resources.requests.storage}")
+			output, err := utils.Run(cmd)
+			g.Expect(err).NotTo(HaveOccurred())
+			size, err := resource.ParseQuantity(strings.TrimSpace(output))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(size.Cmp(resource.MustParse("1Gi"))).To(Equal(1), "PVC request was not raised")
+		}).Should(Succeed())
+
+		By("verifying the alert reached the receiver")
+		Eventually(func(g Gomega) {
+			cmd := exec.Command("kubectl", "logs", alertReceiverName, "-n", storageNamespace)
+			output, err := utils.Run(cmd)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(output).To(ContainSubstring("/api/v2/alerts"))
+			g.Expect(output).To(ContainSubstring("CNPGStorageAlert"))
+			g.Expect(output).To(ContainSubstring(storageClusterName))
+		}).Should(Succeed())
+	})
+
+	It("should clean up WAL when the emergency threshold is breached", func() {
+		By("lowering the emergency threshold and enabling WAL cleanup")
+		Expect(applyStoragePolicy(thresholds{warning: 1, critical: 2, expansion: 3, emergency: 4}, true)).To(Succeed())
+
+		By("waiting for a completed WAL cleanup StorageEvent")
+		Eventually(func(g Gomega) {
+			names, err := storageEventNames("wal-cleanup")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(names).NotTo(BeEmpty())
+
+			cmd := exec.Command("kubectl", "get", "storageevent", names[0], "-n", storageNamespace,
+				"-o", "jsonpath={.status.phase}")
+			output, err := utils.Run(cmd)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(output).To(Equal("Completed"))
+		}).Should(Succeed())
+	})
+})
+
+// thresholds are the StoragePolicy usage thresholds the scenarios step through
+type thresholds struct {
+	warning, critical, expansion, emergency int
+}
+
+// applyStoragePolicy creates or updates the StoragePolicy managing the e2e cluster
+func applyStoragePolicy(t thresholds, walCleanup bool) error {
+	return kubectlApply(fmt.Sprintf(`apiVersion: cnpg.supporttools.io/v1alpha1
+kind: StoragePolicy
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  selector:
+    matchLabels:
+      e2e: storage
+  metricsSource: exec
+  thresholds:
+    warning: %d
+    critical: %d
+    expansion: %d
+    emergency: %d
+  expansion:
+    enabled: true
+    percentage: 50
+    minIncrementGi: 1
+    maxSize: 5Gi
+  walCleanup:
+    enabled: %t
+    retainCount: 1
+  alerting:
+    channels:
+    - type: alertmanager
+      endpoint: http://%s.%s.svc:8080
+`, storagePolicyName, storageNamespace, t.warning, t.critical, t.expansion, t.emergency,
+		walCleanup, alertReceiverName, storageNamespace))
+}
+
+// managedClusterField returns a status field the StoragePolicy reports for the e2e cluster
+func managedClusterField(field string) (string, error) {
+	cmd := exec.Command("kubectl", "get", "storagepolicy", storagePolicyName, "-n", storageNamespace,
+		"-o", fmt.Sprintf("jsonpath={.status.managedClusters[?(@.name==%q)].%s}", storageClusterName, field))
+	output, err := utils.Run(cmd)
+	if err != nil {
+		return "", err
+	}
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return "", fmt.Errorf("%s not reported yet", field)
+	}
+	return output, nil
+}
+
+// storageEventNames returns the StorageEvents of the given type created for the e2e cluster
+func storageEventNames(eventType string) ([]string, error) {
+	cmd := exec.Command("kubectl", "get", "storageevents", "-n", storageNamespace,
+		"-o", fmt.Sprintf("jsonpath={range .items[?(@.spec.eventType==%q)]}{.metadata.name}{\"\\n\"}{end}", eventType))
+	output, err := utils.Run(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return utils.GetNonEmptyLines(output), nil
+}
+
+// kubectlApply applies the given manifest
+func kubectlApply(manifest string) error {
+	cmd := exec.Command("kubectl", "apply", "-f", "-")
+	cmd.Stdin = strings.NewReader(manifest)
+	_, err := utils.Run(cmd)
+	return err
+}
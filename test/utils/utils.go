@@ -31,6 +31,9 @@ const (
 	certmanagerVersion = "v1.19.1"
 	certmanagerURLTmpl = "https://github.com/cert-manager/cert-manager/releases/download/%s/cert-manager.yaml"
 
+	cnpgVersion = "1.25.1"
+	cnpgURLTmpl = "https://raw.githubusercontent.com/cloudnative-pg/cloudnative-pg/release-%s/releases/cnpg-%s.yaml"
+
 	defaultKindBinary  = "kind"
 	defaultKindCluster = "kind"
 )
@@ -133,6 +136,44 @@ func IsCertManagerCRDsInstalled() bool {
 	return false
 }
 
+// cnpgURL returns the manifest URL of the CloudNativePG operator release
+func cnpgURL() string {
+	minor := cnpgVersion[:strings.LastIndex(cnpgVersion, ".")]
+	return fmt.Sprintf(cnpgURLTmpl, minor, cnpgVersion)
+}
+
+// UninstallCNPG uninstalls the CloudNativePG operator
+func UninstallCNPG() {
+	cmd := exec.Command("kubectl", "delete", "-f", cnpgURL(), "--ignore-not-found")
+	if _, err := Run(cmd); err != nil {
+		warnError(err)
+	}
+}
+
+// InstallCNPG installs the CloudNativePG operator bundle.
+func InstallCNPG() error {
+	cmd := exec.Command("kubectl", "apply", "--server-side", "-f", cnpgURL())
+	if _, err := Run(cmd); err != nil {
+		return err
+	}
+	// The operator webhooks reject Cluster objects until the deployment is available.
+	cmd = exec.Command("kubectl", "wait", "deployment.apps/cnpg-controller-manager",
+		"--for", "condition=Available",
+		"--namespace", "cnpg-system",
+		"--timeout", "5m",
+	)
+
+	_, err := Run(cmd)
+	return err
+}
+
+// IsCNPGCRDsInstalled checks if the CloudNativePG Cluster CRD is installed
+func IsCNPGCRDsInstalled() bool {
+	cmd := exec.Command("kubectl", "get", "crd", "clusters.postgresql.cnpg.io")
+	_, err := Run(cmd)
+	return err == nil
+}
+
 // LoadImageToKindClusterWithName loads a local docker image to the kind cluster
 func LoadImageToKindClusterWithName(name string) error {
 	cluster := defaultKindCluster